@@ -9,6 +9,7 @@ import (
 
 var (
 	configFile string
+	ageKeyFile string
 )
 
 var rootCmd = &cobra.Command{
@@ -23,7 +24,9 @@ If no config file is specified, the agent will look for config files in the foll
   - ~/.config/thand/config.yaml`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Load configuration
-		cfg, err := config.Load(configFile)
+		cfg, err := config.LoadWithOptions(configFile, config.LoadOptions{
+			AgeKeyFile: ageKeyFile,
+		})
 		if err != nil {
 			logrus.Fatalf("Failed to load configuration: %v", err)
 		}
@@ -36,6 +39,7 @@ If no config file is specified, the agent will look for config files in the foll
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "Path to the configuration file (optional)")
+	rootCmd.PersistentFlags().StringVar(&ageKeyFile, "age-key-file", "", "age identity file to decrypt a .age config file (default is $THAND_AGE_KEY)")
 }
 
 func main() {
@@ -34,8 +34,11 @@ This will run the web service that handles authentication and authorization requ
 			logrus.WithError(err).Errorln("Failed to sync configuration with agent")
 		}
 
-		// Initialize providers
-		err = cfg.InitializeProviders()
+		// Initialize providers, cancelling them if startup is interrupted
+		ctx, cleanup := common.WithInterrupt(cmd.Context())
+		defer cleanup()
+
+		err = cfg.InitializeProviders(ctx)
 
 		if err != nil {
 			logrus.WithError(err).Errorln("Failed to initialize providers")
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// awsEnvironment maps the aws secrets engine's fields to the variables
+// the AWS CLI and SDKs read
+var awsEnvironment = map[string]string{
+	"access_key":     "AWS_ACCESS_KEY_ID",
+	"secret_key":     "AWS_SECRET_ACCESS_KEY",
+	"security_token": "AWS_SESSION_TOKEN",
+	"session_token":  "AWS_SESSION_TOKEN",
+}
+
+var invalidEnvironmentChars = regexp.MustCompile(`[^A-Z0-9_]+`)
+
+// displayCredentials decrypts and prints the credentials minted for the
+// request, with the export statements to use them in a shell
+func displayCredentials(request *models.ElevateRequest, workflowID string, identity *age.X25519Identity) error {
+
+	if identity == nil || request.Session == nil || len(workflowID) == 0 {
+		return nil
+	}
+
+	execution, err := newApiClient(request.Session).GetExecution(context.Background(), workflowID)
+
+	if err != nil {
+		return fmt.Errorf("failed to get credentials: %w", err)
+	}
+
+	if execution == nil || len(execution.Credentials) == 0 {
+		return nil
+	}
+
+	exports := []string{}
+
+	for _, artifact := range execution.Credentials {
+
+		credentials, err := artifact.Decrypt(identity)
+
+		// A retried request returns the workflow of the first request,
+		// whose credentials were encrypted for that request's key
+		if err != nil {
+			fmt.Println(warningStyle.Render(fmt.Sprintf(
+				"Unable to decrypt the credentials for %s: %s", artifact.Role, err.Error())))
+			continue
+		}
+
+		fmt.Println(headerStyle.Render(fmt.Sprintf("Credentials: %s (%s)", artifact.Role, artifact.Provider)))
+
+		// Service account keys requested without a duration don't expire
+		if !artifact.ExpiresAt.IsZero() {
+			fmt.Println("  " + infoStyle.Render(fmt.Sprintf("Expires: %s (in %s)",
+				artifact.ExpiresAt.Local().Format(time.RFC1123),
+				time.Until(artifact.ExpiresAt).Round(time.Second))))
+		}
+
+		for _, source := range slices.Sorted(maps.Keys(credentials)) {
+
+			// Each source is e.g. a Vault role with the secret's data
+			secret, _ := credentials[source].(map[string]any)
+			data, _ := secret["data"].(map[string]any)
+
+			fmt.Println()
+			fmt.Println("  " + source)
+
+			for _, key := range slices.Sorted(maps.Keys(data)) {
+				value := fmt.Sprint(data[key])
+				fmt.Printf("    %-20s %s\n", key, value)
+				exports = append(exports, fmt.Sprintf("export %s=%s",
+					getEnvironmentName(source, key, len(credentials) > 1), shellQuote(value)))
+			}
+		}
+
+		fmt.Println()
+	}
+
+	if len(exports) > 0 {
+		fmt.Println(infoStyle.Render("To use the credentials in your shell:"))
+		fmt.Println()
+		fmt.Println(strings.Join(exports, "\n"))
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// getEnvironmentName returns the variable to export a credential as e.g.
+// AWS_ACCESS_KEY_ID or READONLY_USERNAME. Only AWS credentials keep their
+// well known names when there is a single source.
+func getEnvironmentName(source string, key string, multiple bool) string {
+
+	if name, found := awsEnvironment[key]; found && !multiple {
+		return name
+	}
+
+	return invalidEnvironmentChars.ReplaceAllString(
+		strings.ToUpper(source+"_"+key), "_")
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
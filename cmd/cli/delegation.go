@@ -0,0 +1,275 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/spf13/cobra"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// delegationTimeLayouts are the formats accepted for --from and --until.
+// Times without a zone are in the local time zone.
+var delegationTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+var delegationCmd = &cobra.Command{
+	Use:   "delegation",
+	Short: "Delegate your approvals while you're away",
+	Long: `Hand your approvals to another approver for a period of time
+e.g. while you're on holiday. The delegate is notified instead of, or as
+well as, you and their approvals are recorded on your behalf.`,
+}
+
+var delegationSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Delegate your approvals",
+	Long: `Delegate your approvals to another approver until a time. The
+delegation starts now unless --from is set.
+
+Example:
+  thand delegation set --to bob@example.com --until 2026-08-15
+  thand delegation set --to bob@example.com --from 2026-08-01 --until 2026-08-15 --reason "Holiday"`,
+	PreRunE:      preAgentE,
+	SilenceUsage: true,
+	RunE:         runSetDelegation,
+}
+
+var delegationListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List delegations",
+	Long: `List your delegations and the delegations made to you. Use --all
+to list every delegation, which requires admin access on the server.
+
+Example:
+  thand delegation list
+  thand delegation list --all`,
+	PreRunE:      preAgentE,
+	SilenceUsage: true,
+	RunE:         runListDelegations,
+}
+
+var delegationRemoveCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "Remove a delegation",
+	Long: `Remove one of your delegations. Admins can remove any delegation.
+
+Example:
+  thand delegation remove 6f1c2a9e-4b1d-4c3e-9a57-0d2b7c1e8f3a`,
+	Args:         cobra.ExactArgs(1),
+	PreRunE:      preAgentE,
+	SilenceUsage: true,
+	RunE:         runRemoveDelegation,
+}
+
+func runSetDelegation(cmd *cobra.Command, args []string) error {
+
+	delegate, _ := cmd.Flags().GetString("to")
+	from, _ := cmd.Flags().GetString("from")
+	until, _ := cmd.Flags().GetString("until")
+	reason, _ := cmd.Flags().GetString("reason")
+
+	start := time.Now()
+
+	if len(from) > 0 {
+		parsed, err := parseDelegationTime(from)
+		if err != nil {
+			return fmt.Errorf("invalid --from: %w", err)
+		}
+		start = parsed
+	}
+
+	end, err := parseDelegationTime(until)
+
+	if err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	request, err := newDelegationRequest()
+
+	if err != nil {
+		return err
+	}
+
+	var delegation models.Delegation
+
+	res, err := request.
+		SetBody(models.DelegationRequest{
+			Delegate: delegate,
+			Start:    start,
+			End:      end,
+			Reason:   reason,
+		}).
+		SetResult(&delegation).
+		Post(getDelegationsUrl())
+
+	if err != nil {
+		return fmt.Errorf("failed to delegate approvals: %w", err)
+	}
+
+	if res.StatusCode() != http.StatusCreated {
+		return getDelegationError("failed to delegate approvals", res)
+	}
+
+	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Approvals delegated to %s", delegation.Delegate)))
+	fmt.Printf("  From:  %s\n", delegation.Start.Local().Format(time.RFC1123))
+	fmt.Printf("  Until: %s\n", delegation.End.Local().Format(time.RFC1123))
+	fmt.Printf("  ID:    %s\n", delegation.ID)
+
+	return nil
+}
+
+func runListDelegations(cmd *cobra.Command, args []string) error {
+
+	all, _ := cmd.Flags().GetBool("all")
+
+	renderer, err := newRenderer(cmd)
+	if err != nil {
+		return err
+	}
+
+	delegationsUrl := getDelegationsUrl()
+
+	if all {
+		delegationsUrl += "/all"
+	}
+
+	request, err := newDelegationRequest()
+
+	if err != nil {
+		return err
+	}
+
+	var response models.DelegationsResponse
+
+	res, err := request.
+		SetResult(&response).
+		Get(delegationsUrl)
+
+	if err != nil {
+		return fmt.Errorf("failed to list delegations: %w", err)
+	}
+
+	if res.StatusCode() != http.StatusOK {
+		return getDelegationError("failed to list delegations", res)
+	}
+
+	return renderer.Render(getDelegationsTable(response.Delegations))
+}
+
+func runRemoveDelegation(cmd *cobra.Command, args []string) error {
+
+	request, err := newDelegationRequest()
+
+	if err != nil {
+		return err
+	}
+
+	res, err := request.Delete(fmt.Sprintf("%s/%s", getDelegationsUrl(), args[0]))
+
+	if err != nil {
+		return fmt.Errorf("failed to remove delegation: %w", err)
+	}
+
+	if res.StatusCode() != http.StatusNoContent {
+		return getDelegationError("failed to remove delegation", res)
+	}
+
+	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Delegation %s removed", args[0])))
+
+	return nil
+}
+
+func getDelegationsTable(delegations []models.Delegation) *Table {
+
+	table := &Table{
+		Title: "Delegations",
+		Columns: []Column{
+			{Header: "ID"},
+			{Header: "APPROVER"},
+			{Header: "DELEGATE"},
+			{Header: "FROM"},
+			{Header: "UNTIL"},
+			{Header: "REASON"},
+		},
+		Items:  delegations,
+		Empty:  "ℹ️  No delegations found",
+		Footer: fmt.Sprintf("Total: %d delegations", len(delegations)),
+	}
+
+	if delegations == nil {
+		table.Items = []models.Delegation{}
+	}
+
+	for _, delegation := range delegations {
+		table.Rows = append(table.Rows, []string{
+			delegation.ID,
+			delegation.Approver,
+			delegation.Delegate,
+			delegation.Start.Local().Format("2006-01-02 15:04"),
+			delegation.End.Local().Format("2006-01-02 15:04"),
+			delegation.Reason,
+		})
+	}
+
+	return table
+}
+
+func newDelegationRequest() (*resty.Request, error) {
+
+	_, session, err := sessionManager.GetFirstActiveSession(cfg.GetLoginServerHostname())
+	if err != nil || session == nil {
+		return nil, fmt.Errorf("no active session to manage delegations")
+	}
+
+	return resty.New().R().SetAuthToken(session.GetEncodedLocalSession()), nil
+}
+
+func getDelegationsUrl() string {
+	baseUrl := fmt.Sprintf("%s/%s",
+		strings.TrimPrefix(cfg.GetLoginServerUrl(), "/"),
+		strings.TrimPrefix(cfg.GetApiBasePath(), "/"))
+	return fmt.Sprintf("%s/delegations", baseUrl)
+}
+
+func getDelegationError(message string, res *resty.Response) error {
+	var errorResponse models.ErrorResponse
+	if err := json.Unmarshal(res.Body(), &errorResponse); err == nil && len(errorResponse.Message) > 0 {
+		return fmt.Errorf("%s: %s", message, errorResponse.Message)
+	}
+	return fmt.Errorf("%s: %s", message, res.Status())
+}
+
+func parseDelegationTime(value string) (time.Time, error) {
+	for _, layout := range delegationTimeLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q isn't a date (2006-01-02), date and time (2006-01-02T15:04) or RFC3339 time", value)
+}
+
+func init() {
+	delegationSetCmd.Flags().String("to", "", "Approver to delegate to (e.g., bob@example.com)")
+	delegationSetCmd.Flags().String("from", "", "When the delegation starts (default now)")
+	delegationSetCmd.Flags().String("until", "", "When the delegation ends")
+	delegationSetCmd.Flags().String("reason", "", "Why approvals are delegated (e.g., Holiday)")
+	delegationSetCmd.MarkFlagRequired("to")
+	delegationSetCmd.MarkFlagRequired("until")
+
+	delegationListCmd.Flags().Bool("all", false, "List every delegation (requires admin access)")
+	addOutputFlags(delegationListCmd)
+
+	delegationCmd.AddCommand(delegationSetCmd)
+	delegationCmd.AddCommand(delegationListCmd)
+	delegationCmd.AddCommand(delegationRemoveCmd)
+	rootCmd.AddCommand(delegationCmd)
+}
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/spf13/cobra"
+	"github.com/thand-io/agent/internal/models"
+)
+
+var grantsCmd = &cobra.Command{
+	Use:   "grants",
+	Short: "Inspect active grants",
+	Long:  "Inspect who currently has access through approved elevations",
+}
+
+var grantsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List active grants",
+	Long: `List who currently has access, with the approvers, reason and
+the time remaining for each grant. Requires admin access on the server.
+
+Example:
+  thand grants list
+  thand grants list --provider aws-prod --role admin
+  thand grants list --user alice@example.com --csv > grants.csv
+  thand grants list --output yaml`,
+	PreRunE:      preAgentE,
+	SilenceUsage: true,
+	RunE:         runListGrants,
+}
+
+func runListGrants(cmd *cobra.Command, args []string) error {
+
+	provider, _ := cmd.Flags().GetString("provider")
+	role, _ := cmd.Flags().GetString("role")
+	user, _ := cmd.Flags().GetString("user")
+	pageSize, _ := cmd.Flags().GetInt("page-size")
+	asCSV, _ := cmd.Flags().GetBool("csv")
+
+	renderer, err := newRenderer(cmd)
+	if err != nil {
+		return err
+	}
+
+	_, session, err := sessionManager.GetFirstActiveSession(cfg.GetLoginServerHostname())
+	if err != nil || session == nil {
+		return fmt.Errorf("no active session to list grants")
+	}
+
+	baseUrl := fmt.Sprintf("%s/%s",
+		strings.TrimPrefix(cfg.GetLoginServerUrl(), "/"),
+		strings.TrimPrefix(cfg.GetApiBasePath(), "/"))
+	grantsUrl := fmt.Sprintf("%s/grants", baseUrl)
+
+	query := map[string]string{
+		"provider": provider,
+		"role":     role,
+		"user":     user,
+	}
+
+	if pageSize > 0 {
+		query["page_size"] = fmt.Sprintf("%d", pageSize)
+	}
+
+	client := resty.New()
+
+	var grants []*models.Grant
+	pageToken := ""
+
+	// Follow the page tokens until every grant has been fetched
+	for {
+
+		query["page_token"] = pageToken
+
+		request := client.R().
+			SetAuthToken(session.GetEncodedLocalSession()).
+			SetQueryParams(query)
+
+		if asCSV {
+			request.SetHeader("Accept", "text/csv")
+		}
+
+		res, err := request.Get(grantsUrl)
+
+		if err != nil {
+			return fmt.Errorf("failed to list grants: %w", err)
+		}
+
+		if res.StatusCode() != http.StatusOK {
+			var errorResponse models.ErrorResponse
+			if err := json.Unmarshal(res.Body(), &errorResponse); err == nil && len(errorResponse.Message) > 0 {
+				return fmt.Errorf("failed to list grants: %s", errorResponse.Message)
+			}
+			return fmt.Errorf("failed to list grants: %s", res.Status())
+		}
+
+		if asCSV {
+			body := res.Body()
+			// Only keep the header row of the first page
+			if len(pageToken) > 0 {
+				if index := strings.IndexByte(string(body), '\n'); index >= 0 {
+					body = body[index+1:]
+				}
+			}
+			os.Stdout.Write(body)
+			pageToken = res.Header().Get("X-Next-Page-Token")
+		} else {
+			var response models.GrantsResponse
+			if err := json.Unmarshal(res.Body(), &response); err != nil {
+				return fmt.Errorf("failed to parse grants: %w", err)
+			}
+			grants = append(grants, response.Grants...)
+			pageToken = response.NextPageToken
+		}
+
+		if len(pageToken) == 0 {
+			break
+		}
+	}
+
+	if asCSV {
+		return nil
+	}
+
+	return renderer.Render(getGrantsTable(grants, time.Now()))
+}
+
+// getGrantsTable lists the grants with the time remaining at now
+func getGrantsTable(grants []*models.Grant, now time.Time) *Table {
+
+	table := &Table{
+		Title: "Active Grants",
+		Columns: []Column{
+			{Header: "USER"},
+			{Header: "ROLE"},
+			{Header: "PROVIDERS"},
+			{Header: "REMAINING"},
+			{Header: "APPROVERS"},
+			{Header: "REASON"},
+		},
+		Items:  grants,
+		Empty:  "ℹ️  No active grants found",
+		Footer: fmt.Sprintf("Total: %d grants", len(grants)),
+	}
+
+	if grants == nil {
+		table.Items = []*models.Grant{}
+	}
+
+	for _, grant := range grants {
+
+		remaining := "-"
+		if grant.ExpiresAt != nil {
+			remaining = formatDuration(grant.ExpiresAt.Sub(now))
+		}
+
+		approvers := strings.Join(grant.Approvers, ",")
+		if len(approvers) == 0 {
+			approvers = "-"
+		}
+
+		// Simulated grants from read only providers gave no real access
+		role := grant.Role
+		if grant.Simulated {
+			role += " (simulated)"
+		}
+
+		table.Rows = append(table.Rows, []string{
+			grant.User,
+			role,
+			strings.Join(grant.Providers, ","),
+			remaining,
+			approvers,
+			grant.Reason,
+		})
+	}
+
+	return table
+}
+
+func init() {
+	grantsListCmd.Flags().String("provider", "", "Filter grants by provider (e.g., aws-prod)")
+	grantsListCmd.Flags().String("role", "", "Filter grants by role")
+	grantsListCmd.Flags().String("user", "", "Filter grants by user email")
+	grantsListCmd.Flags().Int("page-size", 0, "Number of grants to fetch per request")
+	grantsListCmd.Flags().Bool("csv", false, "Output the grants as CSV")
+	addOutputFlags(grantsListCmd)
+
+	grantsCmd.AddCommand(grantsListCmd)
+	rootCmd.AddCommand(grantsCmd)
+}
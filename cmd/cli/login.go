@@ -2,9 +2,17 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"net/http"
 	"net/url"
+	"slices"
+	"strings"
 
+	"github.com/charmbracelet/huh"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/models"
@@ -13,13 +21,27 @@ import (
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Authenticate with the login server",
-	Long:  "Opens a browser to authenticate with the login server and establishes a session",
+	Long: `Opens a browser to authenticate with the login server and establishes a session.
+
+On machines without a browser, such as a jump host over SSH, the device flow
+is used instead. It shows a code to approve in a browser on any machine. Use
+--device to force the device flow.
+
+When the login server supports more than one auth provider, use --provider
+to choose one or pick from the list shown. The choice is remembered for the
+next login.`,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
 
 		err := preRunClientConfigE(cmd, args)
 		if err != nil {
 			return err
 		}
+
+		// The device flow doesn't need the local callback server
+		if useDeviceFlow(cmd) {
+			return nil
+		}
+
 		err = preRunServerE(cmd, args)
 		if err != nil {
 			return err
@@ -30,10 +52,119 @@ var loginCmd = &cobra.Command{
 }
 
 func runLogin(cmd *cobra.Command, args []string) error {
-	return authKickStart()
+	if useDeviceFlow(cmd) {
+		return deviceKickStart()
+	}
+
+	provider, err := selectLoginProvider(cmd)
+	if err != nil {
+		return err
+	}
+
+	return authKickStart(provider)
+}
+
+// selectLoginProvider returns the auth provider to log in with. --provider
+// wins, then the provider chosen last time, otherwise the user picks from
+// the providers the login server offers. An empty provider leaves the
+// choice to the login server's auth page.
+func selectLoginProvider(cmd *cobra.Command) (string, error) {
+
+	provider, _ := cmd.Flags().GetString("provider")
+
+	authProviders, err := getLoginServerAuthProviders()
+
+	if err != nil {
+		// Older login servers don't list their auth providers
+		logrus.WithError(err).Debug("Failed to list auth providers")
+		return provider, nil
+	}
+
+	providerKeys := slices.Sorted(maps.Keys(authProviders))
+
+	if len(provider) > 0 {
+		if _, found := authProviders[provider]; !found {
+			return "", fmt.Errorf("auth provider '%s' is not available, expected one of: %s",
+				provider, strings.Join(providerKeys, ", "))
+		}
+		return provider, nil
+	}
+
+	if lastProvider := sessionManager.GetLoginProvider(cfg.GetLoginServerHostname()); len(lastProvider) > 0 {
+		if _, found := authProviders[lastProvider]; found {
+			fmt.Println("Using auth provider:", lastProvider)
+			return lastProvider, nil
+		}
+	}
+
+	if len(providerKeys) <= 1 {
+		return strings.Join(providerKeys, ""), nil
+	}
+
+	options := make([]huh.Option[string], 0, len(providerKeys))
+
+	for _, providerKey := range providerKeys {
+		authProvider := authProviders[providerKey]
+
+		label := authProvider.Name
+		if len(authProvider.Description) > 0 {
+			label = fmt.Sprintf("%s - %s", authProvider.Name, authProvider.Description)
+		}
+
+		options = append(options, huh.NewOption(label, providerKey))
+	}
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Select an auth provider:").
+				Description("The login server supports more than one way to log in").
+				Options(options...).
+				Value(&provider),
+		),
+	)
+
+	if err := runForm(form, "--provider"); err != nil {
+		return "", err
+	}
+
+	return provider, nil
 }
 
-func authKickStart() error {
+// getLoginServerAuthProviders returns the providers the login server can
+// log in with
+func getLoginServerAuthProviders() (map[string]models.ProviderResponse, error) {
+
+	apiUrl := strings.TrimSuffix(cfg.DiscoverLoginServerApiUrl(cfg.GetLoginServerUrl()), "/")
+
+	res, err := resty.New().R().
+		Get(fmt.Sprintf("%s/auth/providers", apiUrl))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list auth providers: %w", err)
+	}
+
+	if res.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("failed to list auth providers: %s", res.Status())
+	}
+
+	var response models.ProvidersResponse
+	if err := json.Unmarshal(res.Body(), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse auth providers response: %w", err)
+	}
+
+	return response.Providers, nil
+}
+
+// useDeviceFlow returns true if --device is set or there's no browser
+func useDeviceFlow(cmd *cobra.Command) bool {
+	device, _ := cmd.Flags().GetBool("device")
+	return device || isHeadless()
+}
+
+// authKickStart opens the login server's auth page, going straight to the
+// provider's login when one is given
+func authKickStart(provider string) error {
 	// Set up signal handling for graceful cancellation
 	ctx, cleanup := common.WithInterrupt(context.Background())
 	defer cleanup()
@@ -52,6 +183,10 @@ func authKickStart() error {
 		"code":     {createAuthCode()},
 	}
 
+	if len(provider) > 0 {
+		callbackUrl.Set("provider", provider)
+	}
+
 	// Use the configured login server if no override provided
 	authUrl := fmt.Sprintf("%s/auth?%s", cfg.GetLoginServerUrl(), callbackUrl.Encode())
 
@@ -79,6 +214,13 @@ func authKickStart() error {
 		return fmt.Errorf("authentication failed or timed out")
 	}
 
+	// Remember the provider for the next login
+	if len(provider) > 0 {
+		if err := sessionManager.SetLoginProvider(cfg.GetLoginServerHostname(), provider); err != nil {
+			logrus.WithError(err).Warn("Failed to save the auth provider")
+		}
+	}
+
 	fmt.Println()
 	fmt.Println(successStyle.Render("Login successful!"))
 	fmt.Printf("Login server: %s\n", session.Timestamp.Format("2006-01-02 15:04:05"))
@@ -90,6 +232,9 @@ func authKickStart() error {
 func init() {
 	// Add the command to the root
 	rootCmd.AddCommand(loginCmd)
+
+	loginCmd.Flags().Bool("device", false, "Use the device code flow instead of opening a browser")
+	loginCmd.Flags().String("provider", "", "Auth provider to log in with, when the login server supports more than one")
 }
 
 func createAuthCode() string {
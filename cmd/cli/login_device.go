@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// slowDownIncrement matches the interval increase the login server applies
+// when the CLI polls too quickly
+const slowDownIncrement = 5 * time.Second
+
+// isHeadless returns true when there is no browser to open e.g. on a jump
+// host over SSH
+func isHeadless() bool {
+
+	if len(os.Getenv("SSH_TTY")) > 0 || len(os.Getenv("SSH_CONNECTION")) > 0 {
+		return true
+	}
+
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		return false
+	default:
+		return len(os.Getenv("DISPLAY")) == 0 && len(os.Getenv("WAYLAND_DISPLAY")) == 0
+	}
+}
+
+// deviceKickStart logs in with the device code flow. The user approves the
+// code in a browser on any machine while the CLI polls the login server.
+func deviceKickStart() error {
+
+	ctx, cleanup := common.WithInterrupt(context.Background())
+	defer cleanup()
+
+	hostname := cfg.GetLoginServerHostname()
+	fmt.Println("Login server hostname:", hostname)
+
+	apiUrl := strings.TrimSuffix(cfg.DiscoverLoginServerApiUrl(cfg.GetLoginServerUrl()), "/")
+
+	client := resty.New()
+
+	res, err := client.R().
+		SetContext(ctx).
+		Post(fmt.Sprintf("%s/auth/device/code", apiUrl))
+
+	if err != nil {
+		return fmt.Errorf("failed to request device code: %w", err)
+	}
+
+	if res.StatusCode() != http.StatusOK {
+		return fmt.Errorf("failed to request device code: %s", res.Status())
+	}
+
+	var deviceCode models.DeviceCodeResponse
+	if err := json.Unmarshal(res.Body(), &deviceCode); err != nil {
+		return fmt.Errorf("failed to parse device code response: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Printf("To sign in, open %s and enter the code:\n\n", deviceCode.VerificationUri)
+	fmt.Println(successStyle.Render("    " + deviceCode.UserCode))
+	fmt.Println()
+	fmt.Printf("Or open: %s\n", deviceCode.VerificationUriComplete)
+	fmt.Println()
+	fmt.Println("Waiting for approval...")
+
+	token, err := pollDeviceToken(ctx, client, apiUrl, deviceCode)
+
+	if err != nil {
+		return err
+	}
+
+	session, err := models.DecodedLocalSession(token.Session)
+
+	if err != nil {
+		return fmt.Errorf("failed to decode session: %w", err)
+	}
+
+	// Store the session the same way the agent does for the browser flow
+	err = sessionManager.AddSession(hostname, token.Provider, *session)
+
+	if err != nil {
+		return fmt.Errorf("failed to store session: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Println(successStyle.Render("Login successful!"))
+	fmt.Printf("Provider: %s\n", token.Provider)
+	fmt.Printf("Session expires: %s\n", session.Expiry.Local().Format("2006-01-02 15:04:05"))
+	fmt.Println()
+
+	return nil
+}
+
+// pollDeviceToken polls the token endpoint at the interval set by the
+// login server until the code is approved, denied or expires
+func pollDeviceToken(
+	ctx context.Context,
+	client *resty.Client,
+	apiUrl string,
+	deviceCode models.DeviceCodeResponse,
+) (*models.DeviceTokenResponse, error) {
+
+	interval := time.Duration(deviceCode.Interval) * time.Second
+	expiresAt := time.Now().Add(time.Duration(deviceCode.ExpiresIn) * time.Second)
+
+	for {
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("login cancelled")
+		case <-time.After(interval):
+		}
+
+		if time.Now().After(expiresAt) {
+			return nil, fmt.Errorf("device code expired, run login again for a new code")
+		}
+
+		res, err := client.R().
+			SetContext(ctx).
+			SetBody(models.DeviceTokenRequest{DeviceCode: deviceCode.DeviceCode}).
+			Post(fmt.Sprintf("%s/auth/device/token", apiUrl))
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("login cancelled")
+			}
+			logrus.WithError(err).Debugln("Failed to poll for device token, retrying")
+			continue
+		}
+
+		if res.StatusCode() == http.StatusOK {
+			var token models.DeviceTokenResponse
+			if err := json.Unmarshal(res.Body(), &token); err != nil {
+				return nil, fmt.Errorf("failed to parse device token response: %w", err)
+			}
+			return &token, nil
+		}
+
+		var tokenError models.DeviceTokenError
+		if err := json.Unmarshal(res.Body(), &tokenError); err != nil {
+			return nil, fmt.Errorf("unexpected response from login server: %s", res.Status())
+		}
+
+		switch tokenError.Error {
+		case models.DeviceErrorAuthorizationPending:
+			continue
+		case models.DeviceErrorSlowDown:
+			interval += slowDownIncrement
+			if newInterval := time.Duration(tokenError.Interval) * time.Second; newInterval > interval {
+				interval = newInterval
+			}
+		case models.DeviceErrorAccessDenied:
+			return nil, fmt.Errorf("login was denied")
+		case models.DeviceErrorExpiredToken:
+			return nil, fmt.Errorf("device code expired, run login again for a new code")
+		default:
+			return nil, fmt.Errorf("login failed: %s", tokenError.ErrorDescription)
+		}
+	}
+}
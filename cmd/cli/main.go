@@ -28,7 +28,11 @@ func loadConfig(cmd *cobra.Command) (*config.Config, error) {
 		return nil, fmt.Errorf("failed to get config flag: %w", err)
 	}
 
-	return config.Load(configFile)
+	ageKeyFile, _ := cmd.Flags().GetString("age-key-file")
+
+	return config.LoadWithOptions(configFile, config.LoadOptions{
+		AgeKeyFile: ageKeyFile,
+	})
 }
 
 func loadUserSessionState(logonServer string) *sessions.SessionManager {
@@ -100,15 +104,24 @@ func preRunConfigE(cmd *cobra.Command, mode config.Mode) error {
 			logrus.WithError(err).Errorln("Failed to sync configuration with login server")
 		}
 
-		// Sync with thand server if configured to do so
-		err = cfg.RegisterWithThandServer()
+		if err := cfg.Sync.Validate(); err != nil {
+			return err
+		}
+
+		// Sync with thand server if configured to do so. The sync conflict
+		// policy decides if a failure should stop startup
+		err = cfg.HandleSyncFailure(cfg.RegisterWithThandServer())
 
 		if err != nil {
-			logrus.WithError(err).Errorln("Failed to register with Thand server")
+			return err
 		}
 
-		// Now we can initalize our providers
-		err = cfg.InitializeProviders()
+		// Now we can initalize our providers. Interrupting startup
+		// cancels any providers still initializing
+		ctx, cleanup := common.WithInterrupt(cmd.Context())
+		defer cleanup()
+
+		err = cfg.InitializeProviders(ctx)
 		if err != nil {
 			logrus.WithError(err).Errorln("Failed to initialize providers")
 			return fmt.Errorf("failed to initialize providers: %w", err)
@@ -146,6 +159,11 @@ func preRunServerE(cmd *cobra.Command, args []string) error {
 
 // promptAndLogin prompts the user if they want to login and handles the login process
 func promptAndLogin(cmd *cobra.Command) error {
+
+	if !isInteractive() {
+		return fmt.Errorf("%w, run thand login first", ErrNotInteractive)
+	}
+
 	fmt.Println()
 	fmt.Println(titleStyle.Render("Authentication Required"))
 	fmt.Println("No active login session found.")
@@ -162,7 +180,7 @@ func promptAndLogin(cmd *cobra.Command) error {
 		),
 	)
 
-	err := form.Run()
+	err := runForm(form)
 	if err != nil {
 		return fmt.Errorf("login prompt cancelled: %w", err)
 	}
@@ -178,7 +196,7 @@ func promptAndLogin(cmd *cobra.Command) error {
 		}
 
 		// After successful login, try to sync again
-		err = cfg.SyncWithLoginServer()
+		err = cfg.SyncWithLoginServer(cmd.Context())
 		if err != nil {
 			return fmt.Errorf("failed to sync configuration after login: %w", err)
 		}
@@ -192,7 +210,7 @@ func promptAndLogin(cmd *cobra.Command) error {
 func preAuthenticateE(cmd *cobra.Command, _ []string) error {
 
 	// Now we have our session sync the remote state
-	err := cfg.SyncWithLoginServer()
+	err := cfg.SyncWithLoginServer(cmd.Context())
 
 	if err != nil {
 		if errors.Is(err, config.ErrNoActiveLoginSession) {
@@ -208,6 +226,14 @@ func preAuthenticateE(cmd *cobra.Command, _ []string) error {
 
 func preAgentE(cmd *cobra.Command, args []string) error {
 
+	// Walk first time users through creating a config file
+	if needsSetup(cmd) {
+		err := runSetupWizard(cmd, args)
+		if err != nil {
+			return fmt.Errorf("%w. Run `thand setup` or create a config.yaml with login.endpoint", err)
+		}
+	}
+
 	logrus.Debug("Starting server")
 
 	// Server has to run first so we can get our callback
@@ -239,12 +265,10 @@ Complete documentation is available at https://docs.thand.io`,
 		// When nothing is specified. First check if a login-server is configured
 		// if not then start the setup.
 		if cfg == nil || len(cfg.Login.Endpoint) == 0 {
-			fmt.Println("No login server configured. Starting setup...")
-			fmt.Println("Please configure your login server endpoint in config.yaml")
-			fmt.Println("Example:")
-			fmt.Println("  login:")
-			fmt.Println("    endpoint: https://your-login-server.com")
-			return fmt.Errorf("no login server configured")
+			err := runSetupWizard(cmd, args)
+			if err != nil {
+				return err
+			}
 		}
 
 		// if a login-server has been configured then start the cli in interactive mode
@@ -289,6 +313,7 @@ func init() {
 	// Add global flags
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
 	rootCmd.PersistentFlags().String("config", "", "Config file (default is $HOME/.thand/config.yaml)")
+	rootCmd.PersistentFlags().String("age-key-file", "", "age identity file to decrypt a .age config file (default is $THAND_AGE_KEY)")
 	// Add the login-server flag
 	rootCmd.PersistentFlags().String("login-server", "", "Override the default login server URL (e.g., http://localhost:8080)")
 
@@ -0,0 +1,322 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFormat is how listing commands print their results
+type OutputFormat string
+
+const (
+	OutputFormatTable OutputFormat = "table"
+	OutputFormatJSON  OutputFormat = "json"
+	OutputFormatYAML  OutputFormat = "yaml"
+)
+
+// minColumnWidth is the narrowest a column is truncated to
+const minColumnWidth = 8
+
+// columnGap separates the columns of a table
+const columnGap = "  "
+
+// ErrNotInteractive is returned when input is needed but there's no
+// terminal to prompt on
+var ErrNotInteractive = errors.New("input required but the terminal is not interactive")
+
+// Column is a table column. Style renders a cell e.g. to color a status,
+// it's applied after the cell is padded and truncated
+type Column struct {
+	Header string
+	Style  func(value string) lipgloss.Style
+}
+
+// Table is a listing. Tables print the rows, JSON and YAML print the items
+type Table struct {
+	Title   string
+	Columns []Column
+	Rows    [][]string
+	Items   any
+	Empty   string // Shown instead of the table when there are no rows
+	Footer  string
+}
+
+// Renderer prints listings in the format chosen with --output. A width of
+// zero never truncates, e.g. when the output is piped.
+type Renderer struct {
+	Format OutputFormat
+	Width  int
+	Out    io.Writer
+}
+
+// addOutputFlags adds the flags used by newRenderer to a listing command
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", string(OutputFormatTable), "Output format, table, json or yaml")
+	cmd.Flags().Bool("wide", false, "Don't truncate tables to the terminal width")
+}
+
+// newRenderer returns the renderer for the command's output flags
+func newRenderer(cmd *cobra.Command) (*Renderer, error) {
+
+	output, _ := cmd.Flags().GetString("output")
+	wide, _ := cmd.Flags().GetBool("wide")
+
+	format := OutputFormat(strings.ToLower(output))
+
+	switch format {
+	case OutputFormatTable, OutputFormatJSON, OutputFormatYAML:
+	default:
+		return nil, fmt.Errorf("unknown output format '%s', expected %s, %s or %s",
+			output, OutputFormatTable, OutputFormatJSON, OutputFormatYAML)
+	}
+
+	renderer := &Renderer{
+		Format: format,
+		Out:    os.Stdout,
+	}
+
+	if !wide {
+		renderer.Width = getTerminalWidth()
+	}
+
+	return renderer, nil
+}
+
+// newTableRenderer returns a table renderer for commands without output
+// flags e.g. the interactive session manager
+func newTableRenderer() *Renderer {
+	return &Renderer{
+		Format: OutputFormatTable,
+		Width:  getTerminalWidth(),
+		Out:    os.Stdout,
+	}
+}
+
+// Render prints the table in the renderer's format
+func (r *Renderer) Render(table *Table) error {
+
+	switch r.Format {
+	case OutputFormatJSON:
+		data, err := json.MarshalIndent(table.Items, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+		_, err = fmt.Fprintln(r.Out, string(data))
+		return err
+	case OutputFormatYAML:
+		// Go through JSON so the keys match the JSON output
+		data, err := json.Marshal(table.Items)
+		if err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+		var values any
+		if err := json.Unmarshal(data, &values); err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+		data, err = yaml.Marshal(values)
+		if err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+		_, err = r.Out.Write(data)
+		return err
+	}
+
+	if len(table.Rows) == 0 {
+		fmt.Fprintln(r.Out, infoStyle.Render(table.Empty))
+		return nil
+	}
+
+	if len(table.Title) > 0 {
+		fmt.Fprintln(r.Out, headerStyle.Render(table.Title))
+		fmt.Fprintln(r.Out)
+	}
+
+	widths := r.getColumnWidths(table)
+
+	headers := make([]string, len(table.Columns))
+	underlines := make([]string, len(table.Columns))
+
+	for i, column := range table.Columns {
+		headers[i] = column.Header
+		underlines[i] = strings.Repeat("-", len(column.Header))
+	}
+
+	r.writeRow(table, widths, headers, false)
+	r.writeRow(table, widths, underlines, false)
+
+	for _, row := range table.Rows {
+		r.writeRow(table, widths, row, true)
+	}
+
+	if len(table.Footer) > 0 {
+		fmt.Fprintf(r.Out, "\n%s\n", table.Footer)
+	}
+
+	return nil
+}
+
+// writeRow pads and truncates the cells to the column widths. The last
+// column isn't padded so lines don't end in spaces.
+func (r *Renderer) writeRow(table *Table, widths []int, cells []string, styled bool) {
+
+	line := strings.Builder{}
+
+	for i, column := range table.Columns {
+
+		value := ""
+		if i < len(cells) {
+			value = cells[i]
+		}
+
+		cell := ansi.Truncate(value, widths[i], "...")
+
+		if i < len(table.Columns)-1 {
+			cell += strings.Repeat(" ", widths[i]-ansi.StringWidth(cell))
+		}
+
+		if styled && column.Style != nil {
+			cell = column.Style(value).Render(cell)
+		}
+
+		if i > 0 {
+			line.WriteString(columnGap)
+		}
+		line.WriteString(cell)
+	}
+
+	fmt.Fprintln(r.Out, strings.TrimRight(line.String(), " "))
+}
+
+// getColumnWidths returns the width of each column. When the table is
+// wider than the renderer, the widest columns are narrowed until it fits or
+// every column is at the minimum width.
+func (r *Renderer) getColumnWidths(table *Table) []int {
+
+	widths := make([]int, len(table.Columns))
+
+	for i, column := range table.Columns {
+		widths[i] = ansi.StringWidth(column.Header)
+		for _, row := range table.Rows {
+			if i < len(row) {
+				widths[i] = max(widths[i], ansi.StringWidth(row[i]))
+			}
+		}
+	}
+
+	if r.Width <= 0 {
+		return widths
+	}
+
+	total := len(columnGap) * (len(widths) - 1)
+	for _, width := range widths {
+		total += width
+	}
+
+	for total > r.Width {
+
+		widest := 0
+		for i, width := range widths {
+			if width > widths[widest] {
+				widest = i
+			}
+		}
+
+		if widths[widest] <= minColumnWidth {
+			break
+		}
+
+		widths[widest]--
+		total--
+	}
+
+	return widths
+}
+
+// configureOutput turns off colors and styling for --no-color, NO_COLOR
+// (https://no-color.org) or when the output isn't a terminal
+func configureOutput() {
+
+	noColor, _ := rootCmd.PersistentFlags().GetBool("no-color")
+
+	if noColor || len(os.Getenv("NO_COLOR")) > 0 || !isTerminal(os.Stdout) {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// isTerminal returns true if the file is a terminal
+func isTerminal(file *os.File) bool {
+	return term.IsTerminal(int(file.Fd()))
+}
+
+// isInteractive returns true if the user can be prompted for input
+func isInteractive() bool {
+	return isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+// getTerminalWidth returns the width of the terminal, or zero when the
+// output isn't a terminal
+func getTerminalWidth() int {
+
+	if !isTerminal(os.Stdout) {
+		return 0
+	}
+
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+
+	return width
+}
+
+// requireInteractive returns ErrNotInteractive, listing the flags to set
+// instead, when there's no terminal to prompt on
+func requireInteractive(flags ...string) error {
+
+	if isInteractive() {
+		return nil
+	}
+
+	if len(flags) == 0 {
+		return ErrNotInteractive
+	}
+
+	return fmt.Errorf("%w, set %s", ErrNotInteractive, strings.Join(flags, ", "))
+}
+
+// runForm runs the form, or returns ErrNotInteractive with the flags that
+// provide the same input. Setting ACCESSIBLE runs the form as plain
+// prompts for screen readers.
+func runForm(form *huh.Form, flags ...string) error {
+
+	if err := requireInteractive(flags...); err != nil {
+		return err
+	}
+
+	return form.
+		WithAccessible(len(os.Getenv("ACCESSIBLE")) > 0).
+		Run()
+}
+
+// runField runs a single field as a form, see runForm
+func runField(field huh.Field, flags ...string) error {
+	return runForm(huh.NewForm(huh.NewGroup(field)), flags...)
+}
+
+func init() {
+	rootCmd.PersistentFlags().Bool("no-color", false, "Disable colors and styling, also set with NO_COLOR")
+
+	cobra.OnInitialize(configureOutput)
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/sessions"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+func assertGolden(t *testing.T, name string, output string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "output", name+".golden")
+
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(path, []byte(output), 0644))
+	}
+
+	golden, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), output)
+}
+
+func render(t *testing.T, renderer *Renderer, table *Table) string {
+	t.Helper()
+
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	var out bytes.Buffer
+	renderer.Out = &out
+	require.NoError(t, renderer.Render(table))
+
+	return out.String()
+}
+
+func getTestSessions() []*sessions.Session {
+
+	expiry := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	return []*sessions.Session{
+		{
+			Provider: "aws-prod",
+			LocalSession: models.LocalSession{
+				Version: 1,
+				Expiry:  expiry,
+				Session: "secret-token",
+			},
+		},
+		{
+			Provider: "google-workspace",
+			LocalSession: models.LocalSession{
+				Version:  2,
+				Expiry:   expiry.Add(-3 * time.Hour),
+				Session:  "secret-token",
+				Endpoint: "https://thand.example.com",
+			},
+		},
+	}
+}
+
+func TestSessionsOutput(t *testing.T) {
+
+	now := time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		golden string
+		format OutputFormat
+	}{
+		{golden: "sessions.table", format: OutputFormatTable},
+		{golden: "sessions.json", format: OutputFormatJSON},
+		{golden: "sessions.yaml", format: OutputFormatYAML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			output := render(t, &Renderer{Format: tt.format}, getSessionsTable(getTestSessions(), now))
+			assertGolden(t, tt.golden, output)
+			assert.NotContains(t, output, "secret-token")
+		})
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		output := render(t, &Renderer{Format: OutputFormatJSON}, getSessionsTable(nil, now))
+		assert.Equal(t, "[]\n", output)
+
+		output = render(t, &Renderer{Format: OutputFormatTable}, getSessionsTable(nil, now))
+		assert.Equal(t, "ℹ️  No active sessions found\n", output)
+	})
+}
+
+func TestRendererTruncates(t *testing.T) {
+
+	table := &Table{
+		Columns: []Column{
+			{Header: "USER"},
+			{Header: "REASON"},
+		},
+		Rows: [][]string{
+			{"alice@example.com", "Investigating the failed deploy of the billing service"},
+		},
+	}
+
+	output := render(t, &Renderer{Format: OutputFormatTable, Width: 40}, table)
+
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		assert.LessOrEqual(t, len(line), 40, line)
+	}
+	assert.Contains(t, output, "alice@example.com  Investigating the ...")
+
+	// Wide tables, and output that isn't a terminal, aren't truncated
+	output = render(t, &Renderer{Format: OutputFormatTable}, table)
+	assert.Contains(t, output, "Investigating the failed deploy of the billing service")
+}
+
+func TestNewRendererRejectsUnknownFormats(t *testing.T) {
+
+	_, err := newRenderer(sessionListCmd)
+	require.NoError(t, err)
+
+	require.NoError(t, sessionListCmd.Flags().Set("output", "xml"))
+	defer sessionListCmd.Flags().Set("output", string(OutputFormatTable))
+
+	_, err = newRenderer(sessionListCmd)
+	assert.ErrorContains(t, err, "unknown output format 'xml'")
+}
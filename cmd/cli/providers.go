@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+)
+
+var providersCmd = &cobra.Command{
+	Use:               "providers",
+	Short:             "Manage configured providers",
+	Long:              "Inspect and test the providers configured for this server",
+	PersistentPreRunE: preRunProvidersConfigE,
+}
+
+var providersTestCmd = &cobra.Command{
+	Use:   "test [name...]",
+	Short: "Test provider credentials and connectivity",
+	Long: `Initialize the named providers, or all providers, and run a cheap
+read-only smoke check for each capability. Notifiers verify auth without
+sending anything, RBAC providers list one page of roles, identity providers
+fetch one page of identities and authorizers check their issuer is reachable.
+
+Exits with a non-zero code if any check fails.
+
+Example:
+  thand providers test
+  thand providers test aws-prod slack --output json`,
+	SilenceUsage: true,
+	RunE:         runProvidersTest,
+}
+
+// preRunProvidersConfigE loads the server configuration and providers
+// without initializing them or registering with the thand server
+func preRunProvidersConfigE(cmd *cobra.Command, _ []string) error {
+	var err error
+	cfg, err = loadConfig(cmd)
+
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	cfg.SetMode(config.ModeServer)
+
+	verbose, err := cmd.Flags().GetBool("verbose")
+	if err == nil && verbose {
+		logrus.SetLevel(logrus.DebugLevel)
+	}
+
+	providers, err := cfg.LoadProviders()
+
+	if err != nil {
+		return fmt.Errorf("failed to load providers: %w", err)
+	}
+
+	cfg.Providers.Definitions = providers
+
+	return nil
+}
+
+func runProvidersTest(cmd *cobra.Command, args []string) error {
+
+	jsonOutput, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to get json flag: %w", err)
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return fmt.Errorf("failed to get timeout flag: %w", err)
+	}
+
+	renderer, err := newRenderer(cmd)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		renderer.Format = OutputFormatJSON
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	results, err := cfg.TestProviders(ctx, args...)
+
+	if err != nil {
+		return err
+	}
+
+	if err := renderer.Render(getProviderTestTable(results)); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.Passed {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d provider checks failed", failed, len(results))
+	}
+
+	return nil
+}
+
+func getProviderTestTable(results []models.ProviderConnectionResult) *Table {
+
+	table := &Table{
+		Columns: []Column{
+			{Header: "PROVIDER"},
+			{Header: "CAPABILITY"},
+			{Header: "LATENCY"},
+			{Header: "RESULT", Style: getProviderTestResultStyle},
+			{Header: "ERROR"},
+		},
+		Items: results,
+		Empty: "No providers configured",
+	}
+
+	if results == nil {
+		table.Items = []models.ProviderConnectionResult{}
+	}
+
+	for _, result := range results {
+
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+		} else if result.Skipped {
+			status = "SKIP"
+		}
+
+		message := result.Error
+
+		if len(result.MissingPermissions) > 0 {
+			permissions := make([]string, 0, len(result.MissingPermissions))
+			for _, permission := range result.MissingPermissions {
+				permissions = append(permissions, permission.String())
+			}
+			if len(message) == 0 {
+				message = "missing permissions"
+			}
+			message = fmt.Sprintf("%s: %s", message, strings.Join(permissions, ", "))
+		}
+
+		table.Rows = append(table.Rows, []string{
+			result.Provider,
+			string(result.Capability),
+			result.Latency.Round(time.Millisecond).String(),
+			status,
+			message,
+		})
+	}
+
+	return table
+}
+
+func getProviderTestResultStyle(status string) lipgloss.Style {
+	switch status {
+	case "FAIL":
+		return errorStyle
+	case "SKIP":
+		return warningStyle
+	default:
+		return successStyle
+	}
+}
+
+func init() {
+	providersTestCmd.Flags().Bool("json", false, "Output results as JSON")
+	providersTestCmd.Flags().MarkDeprecated("json", "use --output json")
+	addOutputFlags(providersTestCmd)
+	providersTestCmd.Flags().Duration("timeout", 30*time.Second, "Timeout for all provider checks")
+
+	providersCmd.AddCommand(providersTestCmd)
+	rootCmd.AddCommand(providersCmd)
+}
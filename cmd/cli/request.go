@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -11,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"filippo.io/age"
 	"github.com/serverlessworkflow/sdk-go/v3/impl/ctx"
 	"github.com/sirupsen/logrus"
 
@@ -18,6 +20,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/pkg/client"
 )
 
 /*
@@ -30,14 +33,61 @@ access you need and requests it on your behalf.
 4. The response/status of the workflow workflow is returned to the user in the CLI.
 */
 var requestCmd = &cobra.Command{
-	Use:     "request",
-	Short:   "Request access to resources",
-	Long:    `Request just-in-time access to cloud infrastructure or SaaS applications`,
+	Use:   "request [template] [reason]",
+	Short: "Request access to resources",
+	Long: `Request just-in-time access to cloud infrastructure or SaaS applications.
+
+Use --save-as to save the request as a template, then pass the template name
+to make the same request again e.g. thand request prod-ir INC-42. Anything
+after the template name is added to its reason.`,
 	PreRunE: preAgentE,
 	Run: func(cmd *cobra.Command, args []string) {
 
+		saveAs, _ := cmd.Flags().GetString("save-as")
+
+		if len(saveAs) > 0 {
+			if err := models.ValidateRequestTemplateName(saveAs); err != nil {
+				fmt.Println(errorStyle.Render(err.Error()))
+				return
+			}
+		}
+
+		// Make the request from a saved template e.g. thand request prod-ir
+		if len(args) > 0 && len(saveAs) == 0 {
+
+			template, err := getTemplate(args[0])
+
+			if err != nil {
+				fmt.Println(errorStyle.Render(err.Error()))
+				return
+			}
+
+			if template != nil {
+				if err := runTemplateRequest(template, strings.Join(args[1:], " ")); err != nil {
+					fmt.Println(errorStyle.Render(err.Error()))
+				}
+				return
+			}
+		}
+
 		reason := strings.TrimSpace(strings.Join(args, " "))
 
+		// Build the request to save with the wizard
+		if len(reason) == 0 && len(saveAs) > 0 {
+
+			request, err := buildRequest(cfg)
+
+			if err != nil {
+				fmt.Println(errorStyle.Render(err.Error()))
+				return
+			}
+
+			if err := saveAndMakeElevationRequest(saveAs, request); err != nil {
+				logrus.Errorf("failed to make elevation request: %v", err)
+			}
+			return
+		}
+
 		if len(reason) == 0 {
 			fmt.Println(errorStyle.Render("Reason for request is required"))
 
@@ -120,7 +170,7 @@ var requestCmd = &cobra.Command{
 			return
 		}
 
-		err = MakeElevationRequest(&elevateRequest)
+		err = saveAndMakeElevationRequest(saveAs, &elevateRequest)
 
 		if err != nil {
 			logrus.Errorf("failed to make elevation request: %v", err)
@@ -129,6 +179,24 @@ var requestCmd = &cobra.Command{
 	},
 }
 
+// saveAndMakeElevationRequest saves the request as a template, if a name is
+// given, before making it. Only valid requests are saved.
+func saveAndMakeElevationRequest(saveAs string, request *models.ElevateRequest) error {
+
+	if len(saveAs) > 0 {
+
+		if err := validateElevationRequest(request); err != nil {
+			return err
+		}
+
+		if err := saveTemplate(saveAs, request); err != nil {
+			return err
+		}
+	}
+
+	return MakeElevationRequest(request)
+}
+
 func MakeElevationRequest(request *models.ElevateRequest) error {
 
 	if err := validateElevationRequest(request); err != nil {
@@ -162,31 +230,58 @@ func MakeElevationRequest(request *models.ElevateRequest) error {
 		return err
 	}
 
+	// Double submits and network retries within the same minute return
+	// the workflow the first request started
+	if len(request.IdempotencyKey) == 0 && request.Session != nil {
+		key, err := models.NewSessionIdempotencyKey(request.Session,
+			cfg.GetServices().GetEncryption(), request.Role.Name, time.Now())
+
+		if err != nil {
+			// The request still goes through, retries just aren't deduplicated
+			logrus.WithError(err).Debugln("Unable to create an idempotency key for the request")
+		} else {
+			request.IdempotencyKey = key
+		}
+	}
+
+	// Credentials minted for the request, such as Vault database
+	// credentials, are only returned encrypted for this key
+	identity, err := age.GenerateX25519Identity()
+
+	if err != nil {
+		return fmt.Errorf("failed to generate a key for the request: %w", err)
+	}
+
+	request.PublicKey = identity.Recipient().String()
+
 	response, err := sendElevationRequest(request)
 
 	if err != nil {
 		return err
 	}
 
-	return handleElevationResponse(request, response)
+	return handleElevationResponse(request, response, identity)
 }
 
 func validateElevationRequest(request *models.ElevateRequest) error {
 	if request == nil {
 		return fmt.Errorf("invalid request: nil")
 	}
-	if len(request.Reason) == 0 {
-		return fmt.Errorf("invalid request: empty reason")
-	}
 	if request.Role == nil {
 		return fmt.Errorf("invalid request: nil role")
 	}
+	if err := cfg.CheckReason(nil, request.Role, request.Reason); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
 	if len(request.Providers) == 0 {
 		return fmt.Errorf("invalid request: no providers")
 	}
 	if _, err := common.ValidateDuration(request.Duration); err != nil {
 		return fmt.Errorf("invalid request: duration must be greater than zero")
 	}
+	if err := cfg.CheckDuration(nil, request.Role, request.Duration); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
 	return nil
 }
 
@@ -268,46 +363,41 @@ func authenticateUser(request *models.ElevateRequest) error {
 	return nil
 }
 
-func sendElevationRequest(request *models.ElevateRequest) (*resty.Response, error) {
+// newApiClient creates an API client for the login server
+func newApiClient(session *models.LocalSession) *client.Client {
 	baseUrl := fmt.Sprintf("%s/%s",
 		strings.TrimPrefix(cfg.GetLoginServerUrl(), "/"),
 		strings.TrimPrefix(cfg.GetApiBasePath(), "/"))
-	elevateUrl := fmt.Sprintf("%s/elevate", baseUrl)
 
-	client := resty.New()
-	client.SetRedirectPolicy(logRedirectWorkflow())
+	return client.NewClient(baseUrl, session.GetEncodedLocalSession())
+}
 
-	res, err := client.R().
-		EnableTrace().
-		SetAuthToken(request.Session.GetEncodedLocalSession()).
-		SetBody(request).
-		Post(elevateUrl)
+func sendElevationRequest(request *models.ElevateRequest) (*models.ElevateResponse, error) {
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to send elevation request: %w", err)
-	}
+	apiClient := newApiClient(request.Session).
+		SetRedirectPolicy(logRedirectWorkflow())
 
-	return res, nil
-}
+	response, err := apiClient.Elevate(context.Background(), request)
 
-func handleElevationResponse(request *models.ElevateRequest, res *resty.Response) error {
-	if res.StatusCode() == http.StatusOK {
-		return handleSuccessResponse(request, res)
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		return nil, handleErrorResponse(request, apiErr)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to send elevation request: %w", err)
 	}
-	return handleErrorResponse(request, res)
-}
 
-func handleSuccessResponse(request *models.ElevateRequest, res *resty.Response) error {
-	var elevateResponse models.ElevateResponse
-	if err := json.Unmarshal(res.Body(), &elevateResponse); err != nil {
-		logrus.Errorf("failed to unmarshal elevation response: %v", err)
-		return err
-	}
+	return response, nil
+}
 
+func handleElevationResponse(
+	request *models.ElevateRequest,
+	elevateResponse *models.ElevateResponse,
+	identity *age.X25519Identity,
+) error {
 	fmt.Println()
-	displayStatusMessage(request, &elevateResponse)
+	displayStatusMessage(request, elevateResponse)
 	fmt.Println()
-	return nil
+	return displayCredentials(request, elevateResponse.WorkflowId, identity)
 }
 
 func displayStatusMessage(request *models.ElevateRequest, response *models.ElevateResponse) {
@@ -337,13 +427,14 @@ func displayStatusMessage(request *models.ElevateRequest, response *models.Eleva
 	}
 }
 
-func handleErrorResponse(request *models.ElevateRequest, res *resty.Response) error {
-	var errorResponse models.ErrorResponse
-	if err := json.Unmarshal(res.Body(), &errorResponse); err != nil {
-		logrus.Errorf("failed to unmarshal error response: %v", err)
-		return err
+func handleErrorResponse(request *models.ElevateRequest, apiErr *client.APIError) error {
+	if apiErr.Response == nil {
+		logrus.WithError(apiErr).Errorf("failed to unmarshal error response")
+		return apiErr
 	}
 
+	errorResponse := apiErr.Response
+
 	logrus.WithFields(logrus.Fields{
 		"request": request,
 		"error":   errorResponse,
@@ -407,15 +498,16 @@ func logRedirectWorkflow() resty.RedirectPolicy {
 }
 
 func getElevationStatus(request *models.ElevateRequest, response *models.ElevateResponse) error {
-	// Get server URL and auth token for the API call
-	baseUrl := fmt.Sprintf("%s/%s",
-		strings.TrimPrefix(cfg.GetLoginServerUrl(), "/"),
-		strings.TrimPrefix(cfg.GetApiBasePath(), "/"))
 
-	authToken := request.Session.GetEncodedLocalSession()
+	// The live status needs a terminal, otherwise say how to check on it
+	if !isInteractive() {
+		fmt.Printf("Elevation %s submitted, check its status with: thand sessions show %s\n",
+			response.WorkflowId, response.WorkflowId)
+		return nil
+	}
 
 	// Try to run the TUI for live status updates
-	err := runWorkflowStatusTUI(response.WorkflowId, baseUrl, authToken)
+	err := runWorkflowStatusTUI(response.WorkflowId, newApiClient(request.Session))
 	if err != nil {
 		return fmt.Errorf("failed to show live status: %w", err)
 	}
@@ -428,4 +520,6 @@ func init() {
 	// Add subcommands
 	rootCmd.AddCommand(requestCmd) // Request without access uses the LLM to figure out the role
 
+	requestCmd.Flags().String("save-as", "", "Save the request as a template with this name")
+
 }
@@ -2,10 +2,12 @@ package cli
 
 import (
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/thand-io/agent/internal/models"
 )
 
 var rolesCmd = &cobra.Command{
@@ -16,6 +18,14 @@ var rolesCmd = &cobra.Command{
 	RunE:    runListRoles,
 }
 
+var rolesListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List available roles",
+	Long:    "List all available roles from the remote login server",
+	PreRunE: preAgentE, // load agent
+	RunE:    runListRoles,
+}
+
 func runListRoles(cmd *cobra.Command, args []string) error {
 	// Get the provider filter from the flag
 	provider, err := cmd.Flags().GetString("provider")
@@ -23,57 +33,75 @@ func runListRoles(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get provider flag: %w", err)
 	}
 
-	// Display the roles
-	displayRoles(provider)
+	tagFilters, err := cmd.Flags().GetStringArray("tag")
+	if err != nil {
+		return fmt.Errorf("failed to get tag flag: %w", err)
+	}
 
-	return nil
-}
+	tags, err := models.ParseRoleTags(tagFilters, "=")
+	if err != nil {
+		return err
+	}
 
-func displayRoles(provider string) {
+	renderer, err := newRenderer(cmd)
+	if err != nil {
+		return err
+	}
 
-	roles := cfg.GetRoles().Definitions
+	// Display the roles
+	return renderer.Render(getRolesTable(cfg.GetRoles().Definitions, provider, tags))
+}
 
-	if len(roles) == 0 {
-		if len(provider) > 0 {
-			fmt.Printf("No roles found for provider: %s\n", provider)
-		} else {
-			fmt.Println("No roles found")
-		}
-		return
+// getRolesTable lists the roles, sorted by name, that match the provider
+// and tag filters
+func getRolesTable(roles map[string]models.Role, provider string, tags map[string]string) *Table {
+
+	table := &Table{
+		Title: "Available roles",
+		Columns: []Column{
+			{Header: "NAME"},
+			{Header: "PROVIDERS"},
+			{Header: "DESCRIPTION"},
+		},
+		Empty: "No roles found",
 	}
 
-	// Header
 	if len(provider) > 0 {
-		fmt.Printf("Available roles for provider '%s':\n\n", provider)
-	} else {
-		fmt.Println("Available roles:")
-		fmt.Println()
+		table.Title = fmt.Sprintf("Available roles for provider '%s'", provider)
+		table.Empty = fmt.Sprintf("No roles found for provider: %s", provider)
 	}
 
-	// Display roles in a table-like format
-	fmt.Printf("%-20s %-15s %s\n", "NAME", "PROVIDERS", "DESCRIPTION")
-	fmt.Printf("%-20s %-15s %s\n", "----", "---------", "-----------")
+	items := []models.Role{}
 
-	for roleName, role := range roles {
+	for _, roleName := range slices.Sorted(maps.Keys(roles)) {
+
+		role := roles[roleName]
 
 		if len(provider) > 0 && !hasAnyProvider(role.Providers, []string{provider}) {
 			continue
 		}
 
-		providers := strings.Join(role.Providers, ",")
-		if len(providers) > 13 {
-			providers = providers[:10] + "..."
+		if !role.HasTags(tags) {
+			continue
 		}
 
-		description := role.Description
-		if len(description) > 50 {
-			description = description[:47] + "..."
+		if len(role.Name) == 0 {
+			role.Name = roleName
 		}
 
-		fmt.Printf("%-20s %-15s %s\n", roleName, providers, description)
+		table.Rows = append(table.Rows, []string{
+			roleName,
+			strings.Join(role.Providers, ","),
+			role.Description,
+		})
+
+		items = append(items, role)
 	}
 
-	fmt.Printf("\nTotal: %d roles\n", len(roles))
+	table.Items = items
+	table.Footer = fmt.Sprintf("Total: %d roles", len(items))
+
+	return table
 }
 
 func hasAnyProvider(roleProviders []string, requestedProviders []string) bool {
@@ -86,9 +114,14 @@ func hasAnyProvider(roleProviders []string, requestedProviders []string) bool {
 }
 
 func init() {
-	// Add the provider flag
-	rolesCmd.Flags().String("provider", "", "Filter roles by provider (e.g., aws, gcp, azure)")
+	// Add the filter flags, shared with the list subcommand
+	rolesCmd.PersistentFlags().String("provider", "", "Filter roles by provider (e.g., aws, gcp, azure)")
+	rolesCmd.PersistentFlags().StringArray("tag", nil, "Filter roles by tag as key=value, repeat for more tags (e.g., --tag environment=production)")
+
+	addOutputFlags(rolesCmd)
+	addOutputFlags(rolesListCmd)
 
 	// Add the command to the root
+	rolesCmd.AddCommand(rolesListCmd)
 	rootCmd.AddCommand(rolesCmd)
 }
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/spf13/cobra"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+)
+
+var rolesExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the loaded roles",
+	Long: `Export the roles loaded from the remote login server, after validation
+and with defaults resolved, as YAML or JSON. The file can be loaded back with
+roles.path. The --provider and --tag filters limit which roles are exported.`,
+	PreRunE: preAgentE, // load agent
+	RunE:    runExportRoles,
+}
+
+func runExportRoles(cmd *cobra.Command, args []string) error {
+
+	provider, _ := cmd.Flags().GetString("provider")
+	tagFilters, _ := cmd.Flags().GetStringArray("tag")
+	file, _ := cmd.Flags().GetString("file")
+	format, _ := cmd.Flags().GetString("format")
+
+	tags, err := models.ParseRoleTags(tagFilters, "=")
+	if err != nil {
+		return err
+	}
+
+	definitions := &models.RoleDefinitions{
+		Version: version.Must(version.NewVersion("1.0")),
+		Roles:   map[string]models.Role{},
+	}
+
+	for roleName, role := range cfg.GetRoles().Definitions {
+
+		if len(provider) > 0 && !hasAnyProvider(role.Providers, []string{provider}) {
+			continue
+		}
+
+		if !role.HasTags(tags) {
+			continue
+		}
+
+		definitions.Roles[roleName] = role
+	}
+
+	if len(format) == 0 {
+		format = getRoleFileFormat(file, config.RoleExportFormatYAML)
+	}
+
+	data, err := config.MarshalRoleDefinitions(definitions, format)
+	if err != nil {
+		return err
+	}
+
+	if len(file) == 0 {
+		fmt.Print(string(data))
+		return nil
+	}
+
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write roles: %w", err)
+	}
+
+	fmt.Println(successStyle.Render(fmt.Sprintf("Exported %d roles to %s", len(definitions.Roles), file)))
+
+	return nil
+}
+
+// getRoleFileFormat returns the format from the file extension e.g. json,
+// or the fallback when it doesn't have one
+func getRoleFileFormat(file string, fallback string) string {
+
+	extension := strings.ToLower(strings.TrimPrefix(filepath.Ext(file), "."))
+
+	if len(extension) == 0 {
+		return fallback
+	}
+
+	return extension
+}
+
+func init() {
+	rolesExportCmd.Flags().StringP("file", "f", "", "File to write the roles to, defaults to stdout")
+	rolesExportCmd.Flags().String("format", "", "Format of the roles, yaml or json. Defaults to the file extension or yaml")
+
+	rolesCmd.AddCommand(rolesExportCmd)
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/thand-io/agent/internal/config"
+)
+
+var rolesImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import roles from a CSV or JSON file",
+	Long: `Import roles from a CSV or JSON file. Each role is validated the same way
+as when roles are loaded, along with its providers and inheritance, and a
+report of what would be created or rejected is printed. Roles that already
+exist are rejected as duplicates.
+
+The CSV needs a header row with the columns name, description, providers,
+allow, inherits, max_duration and scopes. Only name is required. List
+columns separate values with a semicolon and scopes are written as
+user:<email>, group:<name> or domain:<domain>.
+
+Unless --dry-run is set the valid roles are written to a roles YAML file.`,
+	PreRunE: preAgentE, // load agent
+	RunE:    runImportRoles,
+}
+
+func runImportRoles(cmd *cobra.Command, args []string) error {
+
+	file, _ := cmd.Flags().GetString("file")
+	format, _ := cmd.Flags().GetString("format")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	output, _ := cmd.Flags().GetString("output")
+
+	if len(format) == 0 {
+		format = getRoleFileFormat(file, "")
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read roles file: %w", err)
+	}
+
+	var imports []*config.RoleImport
+
+	switch format {
+	case "csv":
+		imports, err = config.ParseRoleImportCSV(bytes.NewReader(data))
+	case config.RoleExportFormatJSON:
+		imports, err = config.ParseRoleImportJSON(data)
+	default:
+		return fmt.Errorf("unknown roles format '%s', expected csv or json", format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	cfg.ValidateRoleImport(imports)
+
+	displayRoleImports(imports)
+
+	definitions := config.GetValidRoleImports(imports)
+
+	if dryRun {
+		fmt.Println(infoStyle.Render(fmt.Sprintf("Dry run, %d roles would be written to %s", len(definitions.Roles), output)))
+		return nil
+	}
+
+	if len(definitions.Roles) == 0 {
+		return fmt.Errorf("no valid roles to import")
+	}
+
+	roles, err := config.MarshalRoleDefinitions(definitions, config.RoleExportFormatYAML)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(output, roles, 0644); err != nil {
+		return fmt.Errorf("failed to write roles: %w", err)
+	}
+
+	fmt.Println(successStyle.Render(fmt.Sprintf("Wrote %d roles to %s", len(definitions.Roles), output)))
+
+	return nil
+}
+
+func displayRoleImports(imports []*config.RoleImport) {
+
+	fmt.Printf("%-5s %-25s %-10s %s\n", "ROW", "NAME", "RESULT", "DETAILS")
+	fmt.Printf("%-5s %-25s %-10s %s\n", "---", "----", "------", "-------")
+
+	created := 0
+
+	for _, roleImport := range imports {
+
+		result := "create"
+		details := ""
+
+		if roleImport.Duplicate {
+			result = "duplicate"
+			details = roleImport.Err.Error()
+		} else if !roleImport.IsValid() {
+			result = "reject"
+			details = roleImport.Err.Error()
+		} else {
+			created++
+		}
+
+		fmt.Printf("%-5d %-25s %-10s %s\n", roleImport.Row, roleImport.Role.Name, result, details)
+	}
+
+	fmt.Printf("\nTotal: %d roles, %d to create, %d rejected\n", len(imports), created, len(imports)-created)
+}
+
+func init() {
+	rolesImportCmd.Flags().StringP("file", "f", "", "CSV or JSON file to import roles from")
+	rolesImportCmd.Flags().String("format", "", "Format of the file, csv or json. Defaults to the file extension")
+	rolesImportCmd.Flags().Bool("dry-run", false, "Only report what would be created or rejected")
+	rolesImportCmd.Flags().StringP("output", "o", "roles.yaml", "Roles YAML file to write the imported roles to")
+	_ = rolesImportCmd.MarkFlagRequired("file")
+
+	rolesCmd.AddCommand(rolesImportCmd)
+}
@@ -23,6 +23,12 @@ This will run the web service that handles authentication and authorization requ
 			os.Exit(1)
 		}
 
+		// Serve metrics on a separate port if requested
+		metricsPort, err := cmd.Flags().GetInt("metrics-port")
+		if err == nil && metricsPort > 0 {
+			cfg.Server.Metrics.Port = metricsPort
+		}
+
 		// Print out environment information
 		fmt.Printf("Environment Name: %s\n", cfg.Environment.Name)
 		fmt.Printf("Environment Hostname: %s\n", cfg.Environment.Hostname)
@@ -59,5 +65,7 @@ This will run the web service that handles authentication and authorization requ
 }
 
 func init() {
+	serverCmd.Flags().Int("metrics-port", 0, "Serve Prometheus metrics on a separate port")
+
 	rootCmd.AddCommand(serverCmd) // Run server directly
 }
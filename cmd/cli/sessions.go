@@ -45,9 +45,11 @@ var sessionRegisterCmd = &cobra.Command{
 	Short: "Register a session from an encoded token",
 	Long: `Register a session by pasting an encoded session token.
 This allows you to import a session that was provided by another source.
+When stdin isn't a terminal the token is read from it instead.
 
 Example:
-  thand sessions register --provider thand`,
+  thand sessions register --provider thand
+  thand sessions register --provider thand < session.txt`,
 	PreRunE: preRunClientConfigE,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runSessionRegister(cmd)
@@ -64,10 +66,19 @@ Shows provider name, session status (active/expired), expiry time,
 and version information for each session.
 
 Example:
-  thand sessions list`,
-	PreRunE: preRunClientConfigE,
+  thand sessions list
+  thand sessions list --active --provider google
+  thand sessions list --output json`,
+	PreRunE:      preRunClientConfigE,
+	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return listSessions()
+		active, _ := cmd.Flags().GetBool("active")
+		providers, _ := cmd.Flags().GetStringSlice("provider")
+		renderer, err := newRenderer(cmd)
+		if err != nil {
+			return err
+		}
+		return listSessions(renderer, getSessionFilters(active, providers)...)
 	},
 }
 
@@ -122,6 +133,28 @@ Example:
 	},
 }
 
+// sessionShowCmd represents the session show command
+var sessionShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show an elevation and its notifications",
+	Long: `Display the status of an elevation and the delivery receipts
+of the notifications it sent.
+
+Each receipt shows when the notification was handed to the provider,
+the target and the provider's message identifier e.g. the Slack
+channel and timestamp or the SES message ID. Failed deliveries show
+the error instead.
+
+Example:
+  thand sessions show 0b3c4f8e-6d2a-4c1e-9f1a-2b7d5e8c9a10`,
+	Args:         cobra.ExactArgs(1),
+	PreRunE:      preAgentE,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showSession(args[0])
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(sessionCmd)
 	sessionCmd.AddCommand(sessionRegisterCmd)
@@ -129,13 +162,24 @@ func init() {
 	sessionCmd.AddCommand(sessionCreateCmd)
 	sessionCmd.AddCommand(sessionRemoveCmd)
 	sessionCmd.AddCommand(sessionRefreshCmd)
+	sessionCmd.AddCommand(sessionShowCmd)
 
 	// Add flags for register command
 	sessionRegisterCmd.Flags().String("provider", "", "Provider name (e.g., thand)")
+
+	// Add flags for list command
+	sessionListCmd.Flags().Bool("active", false, "Only show sessions that have not expired")
+	sessionListCmd.Flags().StringSlice("provider", nil, "Only show sessions for these providers")
+	addOutputFlags(sessionListCmd)
 }
 
 // runInteractiveSessionManager starts the interactive session management interface
 func runInteractiveSessionManager() error {
+
+	if !isInteractive() {
+		return fmt.Errorf("%w, use a sessions subcommand e.g. thand sessions list", ErrNotInteractive)
+	}
+
 	fmt.Println(titleStyle.Render("Interactive Session Manager"))
 	fmt.Println()
 
@@ -147,7 +191,7 @@ func runInteractiveSessionManager() error {
 
 		switch action {
 		case ActionListSessions:
-			if err := listSessions(); err != nil {
+			if err := listSessions(newTableRenderer()); err != nil {
 				fmt.Println(errorStyle.Render("Failed to list sessions: " + err.Error()))
 			}
 		case ActionCreateSession:
@@ -192,7 +236,7 @@ func promptForAction() (SessionAction, error) {
 		),
 	)
 
-	err := form.Run()
+	err := runForm(form)
 	if err != nil {
 		return ActionExit, err
 	}
@@ -221,35 +265,18 @@ func getAvailableProviders() []huh.Option[string] {
 	return options
 }
 
-// formatDuration formats a duration in a human-readable way
+// formatDuration formats the time left on a session e.g. 1h 23m
 func formatDuration(d time.Duration) string {
 	if d < 0 {
 		return "expired"
 	}
 
-	hours := int(d.Hours())
-	minutes := int(d.Minutes()) % 60
-
-	if hours > 24 {
-		days := hours / 24
-		hours = hours % 24
-		if days == 1 {
-			return fmt.Sprintf("%d day, %d hours", days, hours)
-		}
-		return fmt.Sprintf("%d days, %d hours", days, hours)
-	}
-
-	if hours > 0 {
-		if hours == 1 {
-			return fmt.Sprintf("%d hour, %d minutes", hours, minutes)
-		}
-		return fmt.Sprintf("%d hours, %d minutes", hours, minutes)
+	// Seconds only matter in the last minute
+	if d >= time.Minute {
+		d = d.Round(time.Minute)
 	}
 
-	if minutes == 1 {
-		return "1 minute"
-	}
-	return fmt.Sprintf("%d minutes", minutes)
+	return common.FormatDuration(d)
 }
 
 // authProviderKickStart initiates the authorization process for a given provider
@@ -19,7 +19,7 @@ func createNewSession() error {
 	if len(providers) == 0 {
 
 		// If there are no providers, then just kick off a general login
-		return authKickStart()
+		return authKickStart("")
 
 	}
 
@@ -35,7 +35,7 @@ func createNewSession() error {
 		),
 	)
 
-	err := form.Run()
+	err := runForm(form)
 	if err != nil {
 		return err
 	}
@@ -60,7 +60,7 @@ func createNewSession() error {
 				),
 			)
 
-			if err := overwriteForm.Run(); err != nil {
+			if err := runForm(overwriteForm); err != nil {
 				return err
 			}
 
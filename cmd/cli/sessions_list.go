@@ -3,59 +3,105 @@ package cli
 import (
 	"fmt"
 	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thand-io/agent/internal/sessions"
+)
+
+const (
+	sessionStatusActive  = "active"
+	sessionStatusExpired = "expired"
 )
 
+// sessionListItem is a session in the JSON and YAML output. The encoded
+// session token is left out so listing never prints credentials.
+type sessionListItem struct {
+	Provider string    `json:"provider"`
+	Status   string    `json:"status"`
+	Expiry   time.Time `json:"expiry"`
+	Version  int       `json:"version"`
+	Endpoint string    `json:"endpoint,omitempty"`
+}
+
+// getSessionFilters builds the session filters for the list command flags
+func getSessionFilters(active bool, providers []string) []sessions.SessionFilter {
+	var filters []sessions.SessionFilter
+	if active {
+		filters = append(filters, sessions.FilterByExpiry())
+	}
+	if len(providers) > 0 {
+		filters = append(filters, sessions.FilterByProvider(providers...))
+	}
+	return filters
+}
+
 // listSessions displays all current sessions with their status
-func listSessions() error {
-	fmt.Println(headerStyle.Render("Current Sessions"))
-	fmt.Println()
+func listSessions(renderer *Renderer, filters ...sessions.SessionFilter) error {
 
 	// Reload sessions to get the latest state
 	if err := sessionManager.Load(cfg.GetLoginServerHostname()); err != nil {
 		return fmt.Errorf("failed to load sessions: %w", err)
 	}
 
-	loginServer, err := sessionManager.GetLoginServer(cfg.GetLoginServerHostname())
+	foundSessions, err := sessionManager.ListSessions(cfg.GetLoginServerHostname(), filters...)
 
 	if err != nil {
 		return fmt.Errorf("failed to get sessions for logon server: %w", err)
 	}
 
-	sessions := loginServer.GetSessions()
+	return renderer.Render(getSessionsTable(foundSessions, time.Now().UTC()))
+}
+
+// getSessionsTable lists the sessions with the time remaining at now
+func getSessionsTable(foundSessions []*sessions.Session, now time.Time) *Table {
 
-	if len(sessions) == 0 {
-		fmt.Println(infoStyle.Render("ℹ️  No active sessions found"))
-		return nil
+	table := &Table{
+		Title: "Current Sessions",
+		Columns: []Column{
+			{Header: "PROVIDER"},
+			{Header: "STATUS", Style: getSessionStatusStyle},
+			{Header: "EXPIRES"},
+			{Header: "REMAINING"},
+			{Header: "VERSION"},
+		},
+		Empty:  "ℹ️  No active sessions found",
+		Footer: fmt.Sprintf("Total: %d sessions", len(foundSessions)),
 	}
 
-	currentTime := time.Now().UTC()
-
-	for provider, session := range sessions {
-		providerDisplay := headerStyle.Render(fmt.Sprintf("Provider: %s", provider))
+	items := []sessionListItem{}
 
-		var statusDisplay string
-		var expiryDisplay string
+	for _, session := range foundSessions {
 
-		sessionExpiryTime := session.Expiry.UTC()
-		if sessionExpiryTime.Before(currentTime) {
-			statusDisplay = expiredStyle.Render("EXPIRED")
-			expiryDisplay = expiredStyle.Render(fmt.Sprintf("Expired: %s", session.Expiry.Format("2006-01-02 15:04:05")))
-		} else {
-			statusDisplay = activeStyle.Render("ACTIVE")
-			timeUntilExpiry := time.Until(session.Expiry)
-			expiryDisplay = activeStyle.Render(fmt.Sprintf("Expires: %s (%s)",
-				session.Expiry.Format("2006-01-02 15:04:05"),
-				formatDuration(timeUntilExpiry)))
+		status := sessionStatusActive
+		if session.Expiry.Before(now) {
+			status = sessionStatusExpired
 		}
 
-		versionDisplay := infoStyle.Render(fmt.Sprintf("Version: %d", session.Version))
+		table.Rows = append(table.Rows, []string{
+			session.Provider,
+			status,
+			session.Expiry.Format("2006-01-02 15:04:05"),
+			formatDuration(session.Expiry.Sub(now)),
+			fmt.Sprintf("%d", session.Version),
+		})
 
-		fmt.Println(providerDisplay)
-		fmt.Println("  " + statusDisplay)
-		fmt.Println("  " + expiryDisplay)
-		fmt.Println("  " + versionDisplay)
-		fmt.Println()
+		items = append(items, sessionListItem{
+			Provider: session.Provider,
+			Status:   status,
+			Expiry:   session.Expiry,
+			Version:  session.Version,
+			Endpoint: session.Endpoint,
+		})
 	}
 
-	return nil
+	table.Items = items
+
+	return table
+}
+
+func getSessionStatusStyle(status string) lipgloss.Style {
+	if status == sessionStatusExpired {
+		return expiredStyle
+	}
+	return activeStyle
 }
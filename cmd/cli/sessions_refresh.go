@@ -58,7 +58,7 @@ func refreshSession() error {
 		),
 	)
 
-	err = form.Run()
+	err = runForm(form)
 	if err != nil {
 		return err
 	}
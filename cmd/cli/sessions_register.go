@@ -2,6 +2,8 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"time"
 
@@ -41,14 +43,24 @@ func runSessionRegister(cmd *cobra.Command) error {
 			),
 		)
 
-		if err := form.Run(); err != nil {
+		if err := runForm(form, "--provider"); err != nil {
 			return fmt.Errorf("failed to get provider: %w", err)
 		}
 	}
 
-	// Prompt for the session token
+	// Prompt for the session token, or read it from stdin when piped
 	var sessionToken string
-	form := huh.NewForm(
+
+	if !isTerminal(os.Stdin) {
+
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read session token: %w", err)
+		}
+
+		sessionToken = string(data)
+
+	} else if err := runForm(huh.NewForm(
 		huh.NewGroup(
 			huh.NewText().
 				Title("Session Token").
@@ -61,9 +73,7 @@ func runSessionRegister(cmd *cobra.Command) error {
 					return nil
 				}),
 		),
-	)
-
-	if err := form.Run(); err != nil {
+	)); err != nil {
 		return fmt.Errorf("failed to get session token: %w", err)
 	}
 
@@ -93,7 +103,7 @@ func runSessionRegister(cmd *cobra.Command) error {
 			),
 		)
 
-		if err := confirmForm.Run(); err != nil {
+		if err := runForm(confirmForm); err != nil {
 			return err
 		}
 
@@ -55,7 +55,7 @@ func removeSession() error {
 		),
 	)
 
-	err = form.Run()
+	err = runForm(form)
 	if err != nil {
 		return err
 	}
@@ -76,7 +76,7 @@ func removeSession() error {
 		),
 	)
 
-	if err := confirmForm.Run(); err != nil {
+	if err := runForm(confirmForm); err != nil {
 		return err
 	}
 
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/thand-io/agent/internal/models"
+)
+
+// showSession displays an elevation with the delivery receipts of the
+// notifications it sent
+func showSession(workflowID string) error {
+
+	_, session, err := sessionManager.GetFirstActiveSession(cfg.GetLoginServerHostname())
+	if err != nil || session == nil {
+		return fmt.Errorf("no active session to show the elevation")
+	}
+
+	execution, err := newApiClient(session).GetExecution(context.Background(), workflowID)
+	if err != nil {
+		return fmt.Errorf("failed to get elevation: %w", err)
+	}
+
+	if execution == nil {
+		return fmt.Errorf("elevation %s not found", workflowID)
+	}
+
+	fmt.Println(headerStyle.Render(fmt.Sprintf("Elevation: %s", execution.WorkflowID)))
+	fmt.Println("  " + infoStyle.Render(fmt.Sprintf("Status: %s", execution.Status)))
+	fmt.Println("  " + infoStyle.Render(fmt.Sprintf("Role: %s", execution.Role)))
+	fmt.Println("  " + infoStyle.Render(fmt.Sprintf("User: %s", execution.User)))
+	fmt.Println()
+
+	displayNotificationReceipts(execution.Notifications)
+
+	return nil
+}
+
+func displayNotificationReceipts(receipts []models.NotificationReceipt) {
+
+	if len(receipts) == 0 {
+		fmt.Println(infoStyle.Render("ℹ️  No notifications sent"))
+		return
+	}
+
+	fmt.Println(headerStyle.Render("Notifications"))
+	fmt.Println()
+
+	fmt.Printf("%-20s %-12s %-30s %-10s %s\n", "SENT", "PROVIDER", "TARGET", "STATUS", "MESSAGE")
+	fmt.Printf("%-20s %-12s %-30s %-10s %s\n", "----", "--------", "------", "------", "-------")
+
+	for _, receipt := range receipts {
+
+		// Failures show the error in place of the message identifier
+		message := receipt.MessageID
+		if len(receipt.Error) > 0 {
+			message = receipt.Error
+		} else if len(receipt.Response) > 0 {
+			message = strings.TrimSpace(fmt.Sprintf("%s %s", message, receipt.Response))
+		}
+		if len(message) == 0 {
+			message = "-"
+		}
+
+		fmt.Printf("%-20s %-12s %-30s %-10s %s\n",
+			receipt.Timestamp.Local().Format("2006-01-02 15:04:05"),
+			receipt.Provider,
+			receipt.Target,
+			receipt.Status,
+			message,
+		)
+	}
+
+	fmt.Printf("\nTotal: %d notifications\n", len(receipts))
+}
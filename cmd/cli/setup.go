@@ -0,0 +1,406 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/kardianos/service"
+	"github.com/spf13/cobra"
+	"github.com/thand-io/agent/internal/agent"
+	"github.com/thand-io/agent/internal/common"
+	"gopkg.in/yaml.v3"
+)
+
+// setupNoAuthProvider is selected when the login server provides the
+// auth providers e.g. when using thand.io
+const setupNoAuthProvider = ""
+
+// setupField is a provider config value the setup wizard asks for
+type setupField struct {
+	Key         string // Config key, nested keys are separated by dots e.g. client.id
+	Title       string
+	Description string
+	Default     string
+	Secret      bool
+	Optional    bool
+}
+
+// setupAuthProvider is an auth provider the setup wizard can configure
+type setupAuthProvider struct {
+	Name     string // Name of the provider in the config
+	Label    string
+	Provider string // Provider type
+	Fields   []setupField
+}
+
+var setupAuthProviders = []setupAuthProvider{
+	{
+		Name:     "thand",
+		Label:    "Thand",
+		Provider: "thand",
+		Fields: []setupField{
+			{Key: "endpoint", Title: "Thand endpoint", Default: "https://auth.thand.io"},
+		},
+	},
+	{
+		Name:     "google",
+		Label:    "Google",
+		Provider: "oauth2.google",
+		Fields: []setupField{
+			{Key: "client_id", Title: "Client ID", Description: "OAuth client ID from the Google Cloud console"},
+			{Key: "client_secret", Title: "Client secret", Secret: true},
+			{Key: "hosted_domain", Title: "Hosted domain", Description: "Only allow accounts from this Google Workspace domain", Optional: true},
+		},
+	},
+	{
+		Name:     "github",
+		Label:    "GitHub",
+		Provider: "github",
+		Fields: []setupField{
+			{Key: "client_id", Title: "Client ID", Description: "Client ID of the GitHub OAuth app"},
+			{Key: "client_secret", Title: "Client secret", Secret: true},
+			{Key: "organization", Title: "Organization", Description: "Only allow members of this organization", Optional: true},
+		},
+	},
+	{
+		Name:     "saml",
+		Label:    "SAML",
+		Provider: "saml",
+		Fields: []setupField{
+			{Key: "idp_metadata_url", Title: "IdP metadata URL"},
+			{Key: "entity_id", Title: "Entity ID", Description: "e.g. https://your-app.example.com/saml/metadata"},
+			{Key: "root_url", Title: "Root URL", Description: "The URL the agent is reached at"},
+			{Key: "cert_file", Title: "Certificate file", Description: "Path to the service provider certificate"},
+			{Key: "key_file", Title: "Key file", Description: "Path to the service provider private key"},
+		},
+	},
+	{
+		Name:     "oauth2",
+		Label:    "OAuth2",
+		Provider: "oauth2",
+		Fields: []setupField{
+			{Key: "authority", Title: "Authority", Description: "e.g. https://oauth.example.com"},
+			{Key: "client.id", Title: "Client ID"},
+			{Key: "client.secret", Title: "Client secret", Secret: true},
+			{Key: "endpoints.auth", Title: "Authorization endpoint", Default: "/oauth/authorize"},
+			{Key: "endpoints.token", Title: "Token endpoint", Default: "/oauth/token"},
+		},
+	},
+}
+
+// setupLoginServerEnv are the environment variables that configure the
+// login server without a config file
+var setupLoginServerEnv = []string{"THAND_LOGIN_ENDPOINT", "THAND_BASE_URL"}
+
+var setupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Configure the agent for the first time",
+	Long: `Interactively create a config file with the login server and an auth provider,
+then optionally install and start the agent service.`,
+	RunE: runSetupWizard,
+}
+
+// needsSetup returns true when the agent is run for the first time, with no
+// config file and no login server set by a flag or the environment
+func needsSetup(cmd *cobra.Command) bool {
+
+	if cfg == nil {
+		return true
+	}
+
+	if len(cfg.GetConfigFile()) > 0 || cmd.Flags().Changed("login-server") {
+		return false
+	}
+
+	for _, env := range setupLoginServerEnv {
+		if len(os.Getenv(env)) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// runSetupWizard asks for the login server and an auth provider, writes the
+// config file and reloads the configuration from it
+func runSetupWizard(cmd *cobra.Command, args []string) error {
+
+	fmt.Println(titleStyle.Render("Thand Agent - Setup"))
+	fmt.Println("Create a config file for the agent")
+	fmt.Println()
+
+	loginServer := common.DefaultLoginServerEndpoint
+	authProviderName := setupNoAuthProvider
+
+	providerOptions := []huh.Option[string]{
+		huh.NewOption("None, use the login server's providers", setupNoAuthProvider),
+	}
+
+	for _, provider := range setupAuthProviders {
+		providerOptions = append(providerOptions, huh.NewOption(provider.Label, provider.Name))
+	}
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Login server URL:").
+				Description("The server you login with and request access from").
+				Value(&loginServer).
+				Validate(validateSetupURL),
+			huh.NewSelect[string]().
+				Title("Auth provider:").
+				Description("How users authenticate with this agent").
+				Options(providerOptions...).
+				Value(&authProviderName),
+		),
+	)
+
+	if err := runForm(form, "--login-server"); err != nil {
+		return fmt.Errorf("setup cancelled: %w", err)
+	}
+
+	configValues := map[string]any{
+		"login": map[string]any{
+			"endpoint": strings.TrimSpace(loginServer),
+		},
+	}
+
+	if authProvider := getSetupAuthProvider(authProviderName); authProvider != nil {
+
+		providerConfig, err := askSetupProviderConfig(authProvider)
+		if err != nil {
+			return err
+		}
+
+		configValues["providers"] = map[string]any{
+			authProvider.Name: map[string]any{
+				"name":     authProvider.Label,
+				"provider": authProvider.Provider,
+				"enabled":  true,
+				"config":   providerConfig,
+			},
+		}
+	}
+
+	configFile, err := getSetupConfigFile(cmd)
+	if err != nil {
+		return err
+	}
+
+	written, err := writeSetupConfig(configFile, configValues)
+	if err != nil {
+		return err
+	}
+
+	if !written {
+		return fmt.Errorf("setup cancelled, %s was left unchanged", configFile)
+	}
+
+	fmt.Println(successStyle.Render("Configuration written to " + configFile))
+	fmt.Println()
+
+	// Reload so the rest of the command uses the new config
+	if err := cmd.Flags().Set("config", configFile); err != nil {
+		return fmt.Errorf("failed to set config file: %w", err)
+	}
+
+	if err := preRunClientConfigE(cmd, args); err != nil {
+		return err
+	}
+
+	return offerToStartService()
+}
+
+// validateSetupURL checks the login server is a http or https URL
+func validateSetupURL(value string) error {
+
+	parsed, err := url.Parse(strings.TrimSpace(value))
+
+	if err != nil || len(parsed.Host) == 0 {
+		return errors.New("enter a URL e.g. https://login.example.com")
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return errors.New("the URL must start with http:// or https://")
+	}
+
+	return nil
+}
+
+func getSetupAuthProvider(name string) *setupAuthProvider {
+	for i := range setupAuthProviders {
+		if setupAuthProviders[i].Name == name {
+			return &setupAuthProviders[i]
+		}
+	}
+	return nil
+}
+
+// askSetupProviderConfig asks for the config values of the auth provider
+// and returns them as the nested provider config
+func askSetupProviderConfig(provider *setupAuthProvider) (map[string]any, error) {
+
+	values := make([]string, len(provider.Fields))
+	fields := []huh.Field{}
+
+	for i, field := range provider.Fields {
+
+		values[i] = field.Default
+
+		input := huh.NewInput().
+			Title(field.Title + ":").
+			Description(field.Description).
+			Value(&values[i])
+
+		if field.Secret {
+			input = input.EchoMode(huh.EchoModePassword)
+		}
+
+		if !field.Optional {
+			title := field.Title
+			input = input.Validate(func(value string) error {
+				if len(strings.TrimSpace(value)) == 0 {
+					return fmt.Errorf("%s is required", strings.ToLower(title))
+				}
+				return nil
+			})
+		}
+
+		fields = append(fields, input)
+	}
+
+	form := huh.NewForm(
+		huh.NewGroup(fields...).
+			Title(fmt.Sprintf("%s settings", provider.Label)),
+	)
+
+	if err := runForm(form); err != nil {
+		return nil, fmt.Errorf("setup cancelled: %w", err)
+	}
+
+	providerConfig := map[string]any{}
+
+	for i, field := range provider.Fields {
+
+		value := strings.TrimSpace(values[i])
+
+		if len(value) == 0 {
+			continue
+		}
+
+		setNestedValue(providerConfig, strings.Split(field.Key, "."), value)
+	}
+
+	return providerConfig, nil
+}
+
+// setNestedValue sets the value at the path, creating the maps on the way
+func setNestedValue(values map[string]any, path []string, value any) {
+
+	for _, key := range path[:len(path)-1] {
+		next, ok := values[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			values[key] = next
+		}
+		values = next
+	}
+
+	values[path[len(path)-1]] = value
+}
+
+// getSetupConfigFile returns where to write the config. The --config flag
+// wins, otherwise it's written to ~/.config/thand/config.yaml which is one
+// of the paths the config is loaded from.
+func getSetupConfigFile(cmd *cobra.Command) (string, error) {
+
+	if configFile, err := cmd.Flags().GetString("config"); err == nil && len(configFile) > 0 {
+		return configFile, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".config", "thand", "config.yaml"), nil
+}
+
+// writeSetupConfig writes the config as YAML. An existing file is only
+// replaced if the user confirms. Returns false if the file was left as is.
+func writeSetupConfig(configFile string, values map[string]any) (bool, error) {
+
+	if _, err := os.Stat(configFile); err == nil {
+
+		overwrite := false
+
+		err := runField(huh.NewConfirm().
+			Title(fmt.Sprintf("%s already exists. Replace it?", configFile)).
+			Value(&overwrite))
+
+		if err != nil || !overwrite {
+			return false, nil
+		}
+	}
+
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configFile), 0700); err != nil {
+		return false, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	// The config can hold client secrets
+	if err := os.WriteFile(configFile, data, 0600); err != nil {
+		return false, fmt.Errorf("failed to write config: %w", err)
+	}
+
+	return true, nil
+}
+
+// offerToStartService asks to install and start the agent service so the
+// agent runs in the background
+func offerToStartService() error {
+
+	startService := false
+
+	err := runField(huh.NewConfirm().
+		Title("Start the agent service?").
+		Description("Installs the agent as a system service that starts on boot. This may need elevated privileges").
+		Value(&startService))
+
+	if err != nil || !startService {
+		return nil
+	}
+
+	s, err := agent.CreateService(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create service: %w", err)
+	}
+
+	if _, err := s.Status(); errors.Is(err, service.ErrNotInstalled) {
+		if err := s.Install(); err != nil {
+			printInstallInstructions()
+			return fmt.Errorf("failed to install service: %w", err)
+		}
+	}
+
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("failed to start service: %w", err)
+	}
+
+	fmt.Println(successStyle.Render("Thand Agent service started"))
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(setupCmd)
+}
@@ -1,7 +1,8 @@
 package cli
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -10,9 +11,9 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/go-resty/resty/v2"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/pkg/client"
 )
 
 type execInfo struct {
@@ -23,6 +24,17 @@ type errorMsg struct {
 	err error
 }
 
+type streamOpenedMsg struct {
+	events <-chan *models.ElevationEvent
+}
+
+type streamEventMsg struct {
+	event *models.ElevationEvent
+}
+
+// streamClosedMsg is sent when the event stream can't be opened or drops
+type streamClosedMsg struct{}
+
 type tuiModel struct {
 	workflowID  string
 	execution   *models.WorkflowExecutionInfo
@@ -33,27 +45,35 @@ type tuiModel struct {
 	approvedAt  *time.Time
 	quitting    bool
 	liveUpdates bool
-	serverUrl   string
-	authToken   string
+	client      *client.Client
+
+	// Status events streamed from the server. When the stream is not
+	// available the status is polled instead.
+	ctx    context.Context
+	cancel context.CancelFunc
+	events <-chan *models.ElevationEvent
 }
 
-func newTuiModel(workflowID, serverUrl, authToken string) tuiModel {
+func newTuiModel(workflowID string, apiClient *client.Client) tuiModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#3b82f6"))
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return tuiModel{
 		workflowID:  workflowID,
 		spinner:     s,
 		loading:     true,
 		liveUpdates: true,
-		serverUrl:   serverUrl,
-		authToken:   authToken,
+		client:      apiClient,
+		ctx:         ctx,
+		cancel:      cancel,
 	}
 }
 
 func (m tuiModel) Init() tea.Cmd {
-	return tea.Batch(m.spinner.Tick, m.fetchStatus)
+	return tea.Batch(m.spinner.Tick, m.subscribe)
 }
 
 func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -70,6 +90,30 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
 
+	case streamOpenedMsg:
+		m.events = msg.events
+		return m, waitForEvent(m.events)
+
+	case streamEventMsg:
+
+		if msg.event.Execution != nil {
+			m.setExecution(msg.event.Execution)
+		}
+
+		if msg.event.Status.IsTerminal() {
+			m.events = nil
+			return m, tea.Quit
+		}
+
+		return m, waitForEvent(m.events)
+
+	case streamClosedMsg:
+
+		// Fall back to polling if the stream drops before the
+		// elevation has finished
+		m.events = nil
+		return m, m.fetchStatus
+
 	case execInfo:
 
 		execution := msg.execution
@@ -79,14 +123,7 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		}
 
-		m.loading = false
-
-		m.execution = execution
-		m.lastUpdate = time.Now()
-
-		if execution.Approved != nil && *execution.Approved && m.approvedAt == nil {
-			m.approvedAt = execution.GetAuthorizationTime()
-		}
+		m.setExecution(execution)
 
 		// Continue polling if workflow is still running
 		if m.isWorkflowRunning() {
@@ -197,7 +234,6 @@ func (m tuiModel) renderStatusSection() string {
 	if m.execution.Duration > 0 {
 
 		totalDuration := time.Duration(m.execution.Duration) * time.Second
-		// Format duration in PT1H format
 		durationStr := common.FormatDuration(totalDuration)
 		section.WriteString(fmt.Sprintf("Duration:      %s", durationStr))
 
@@ -208,8 +244,7 @@ func (m tuiModel) renderStatusSection() string {
 			// Calculate remaining time until expiration
 			remaining := max(time.Until(expirationTime), 0)
 
-			// Format remaining time in human readable format
-			remainingStr := common.FormatDurationRemaining(remaining)
+			remainingStr := common.FormatDuration(remaining)
 
 			section.WriteString(fmt.Sprintf(" (%s remaining)", remainingStr))
 		}
@@ -254,6 +289,18 @@ func (m tuiModel) renderStatusSection() string {
 	return section.String()
 }
 
+func (m *tuiModel) setExecution(execution *models.WorkflowExecutionInfo) {
+
+	m.loading = false
+
+	m.execution = execution
+	m.lastUpdate = time.Now()
+
+	if execution.Approved != nil && *execution.Approved && m.approvedAt == nil {
+		m.approvedAt = execution.GetAuthorizationTime()
+	}
+}
+
 func (m tuiModel) isWorkflowRunning() bool {
 	if m.execution == nil {
 		return false
@@ -263,41 +310,51 @@ func (m tuiModel) isWorkflowRunning() bool {
 }
 
 func (m tuiModel) fetchStatus() tea.Msg {
-	client := resty.New()
-	url := fmt.Sprintf("%s/execution/%s", strings.TrimSuffix(m.serverUrl, "/"), m.workflowID)
+	execution, err := m.client.GetExecution(context.Background(), m.workflowID)
 
-	resp, err := client.R().
-		SetAuthToken(m.authToken).
-		SetHeader("Accept", "application/json").
-		Get(url)
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
 
-	if err != nil {
+		if apiErr.StatusCode == http.StatusNotImplemented {
+			// Check if this is a configuration error
+			return errorMsg{err: fmt.Errorf("live updates are not supported by the server")}
+		}
+
+		return errorMsg{err: fmt.Errorf("API error: %w", apiErr)}
+
+	} else if err != nil {
 		return errorMsg{err: fmt.Errorf("failed to fetch status: %w", err)}
 	}
 
-	if resp.StatusCode() == http.StatusNotImplemented {
-		// Check if this is a configuration error
-		return errorMsg{err: fmt.Errorf("live updates are not supported by the server")}
-	}
+	return execInfo{execution: execution}
+}
 
-	if resp.StatusCode() != http.StatusOK {
-		return errorMsg{err: fmt.Errorf("API error: %d - %s for: %s", resp.StatusCode(), string(resp.Body()), url)}
-	}
+// subscribe opens the status event stream for the workflow
+func (m tuiModel) subscribe() tea.Msg {
+	events, err := m.client.StreamEvents(m.ctx, m.workflowID)
 
-	var apiResponse struct {
-		Execution *models.WorkflowExecutionInfo `json:"execution"`
+	if err != nil {
+		return streamClosedMsg{}
 	}
 
-	if err := json.Unmarshal(resp.Body(), &apiResponse); err != nil {
-		return errorMsg{err: fmt.Errorf("failed to parse response: %w", err)}
-	}
+	return streamOpenedMsg{events: events}
+}
 
-	return execInfo{execution: apiResponse.Execution}
+func waitForEvent(events <-chan *models.ElevationEvent) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return streamClosedMsg{}
+		}
+		return streamEventMsg{event: event}
+	}
 }
 
 // runWorkflowStatusTUI starts the TUI for live workflow status updates
-func runWorkflowStatusTUI(workflowID, serverUrl, authToken string) error {
-	model := newTuiModel(workflowID, serverUrl, authToken)
+func runWorkflowStatusTUI(workflowID string, apiClient *client.Client) error {
+	model := newTuiModel(workflowID, apiClient)
+	defer model.cancel()
+
 	program := tea.NewProgram(model)
 
 	finalModel, err := program.Run()
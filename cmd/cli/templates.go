@@ -0,0 +1,310 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/sessions"
+)
+
+// newRequestOption is the wizard option to build a request from scratch
+const newRequestOption = ""
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Manage saved request templates",
+	Long:  "Manage the request templates saved with `thand request --save-as <name>`",
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved request templates",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		templates, err := sessions.NewTemplateStore().List()
+
+		if err != nil {
+			return err
+		}
+
+		if len(templates) == 0 {
+			fmt.Println(infoStyle.Render("No saved templates. Save one with `thand request --save-as <name>`"))
+			return nil
+		}
+
+		fmt.Printf("%-20s %-20s %-20s %-10s %s\n", "NAME", "ROLE", "PROVIDERS", "DURATION", "REASON PREFIX")
+		fmt.Printf("%-20s %-20s %-20s %-10s %s\n", "----", "----", "---------", "--------", "-------------")
+
+		for _, template := range templates {
+			fmt.Printf("%-20s %-20s %-20s %-10s %s\n",
+				template.Name,
+				template.Role,
+				strings.Join(template.Providers, ","),
+				template.Duration,
+				template.ReasonPrefix,
+			)
+		}
+
+		fmt.Printf("\nTotal: %d templates\n", len(templates))
+
+		return nil
+	},
+}
+
+var templatesDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a saved request template",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if err := sessions.NewTemplateStore().Delete(args[0]); err != nil {
+			return err
+		}
+
+		fmt.Println(successStyle.Render(fmt.Sprintf("Template %s deleted", args[0])))
+
+		return nil
+	},
+}
+
+// getTemplate returns the saved template with the name, or nil if there
+// isn't one
+func getTemplate(name string) (*models.RequestTemplate, error) {
+
+	template, err := sessions.NewTemplateStore().Get(name)
+
+	if errors.Is(err, sessions.ErrTemplateNotFound) {
+		return nil, nil
+	}
+
+	return template, err
+}
+
+// saveTemplate saves the request as a template. The reason is kept as the
+// reason prefix.
+func saveTemplate(name string, request *models.ElevateRequest) error {
+
+	if request.Role == nil {
+		return fmt.Errorf("the request has no role to save")
+	}
+
+	roleKey := getRoleKey(request.Role)
+
+	template := models.RequestTemplate{
+		Name:         name,
+		Role:         roleKey,
+		Providers:    request.Providers,
+		Duration:     request.Duration,
+		Workflow:     request.Workflow,
+		ReasonPrefix: request.Reason,
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	// Only keep the resources if the request narrowed the role's resources
+	if configuredRole, err := cfg.GetRoleByName(roleKey); err == nil &&
+		!slices.Equal(configuredRole.Resources.Allow, request.Role.Resources.Allow) {
+		template.Resources = request.Role.Resources.Allow
+	}
+
+	if err := sessions.NewTemplateStore().Save(template); err != nil {
+		return fmt.Errorf("failed to save template: %w", err)
+	}
+
+	fmt.Println(successStyle.Render(fmt.Sprintf("Saved template %s. Use it with `thand request %s`", name, name)))
+
+	return nil
+}
+
+// getRoleKey returns the key the role is configured under, which can
+// differ from its name
+func getRoleKey(role *models.Role) string {
+
+	if _, found := cfg.Roles.Definitions[role.Name]; found {
+		return role.Name
+	}
+
+	for key, configuredRole := range cfg.Roles.Definitions {
+		if configuredRole.Name == role.Name {
+			return key
+		}
+	}
+
+	return role.Name
+}
+
+// newRequestFromTemplate builds the request for a template, checking it is
+// still valid against the synced config
+func newRequestFromTemplate(template *models.RequestTemplate, reason string) (*models.ElevateRequest, error) {
+
+	if problems := cfg.CheckRequestTemplate(template); len(problems) > 0 {
+		displayTemplateProblems(template, problems)
+		return nil, fmt.Errorf("template %s is no longer valid", template.Name)
+	}
+
+	role, err := cfg.GetRoleByName(template.Role)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(template.Resources) > 0 {
+		scopedRole := *role
+		scopedRole.Resources.Allow = template.Resources
+		role = &scopedRole
+	}
+
+	return &models.ElevateRequest{
+		Role:      role,
+		Providers: template.Providers,
+		Workflow:  template.Workflow,
+		Duration:  template.Duration,
+		Reason:    reason,
+	}, nil
+}
+
+// displayTemplateProblems shows what changed since the template was saved
+func displayTemplateProblems(template *models.RequestTemplate, problems []string) {
+	fmt.Println()
+	fmt.Println(errorStyle.Render(fmt.Sprintf("Template %s no longer matches the server config:", template.Name)))
+	for _, problem := range problems {
+		fmt.Printf("  - %s\n", problem)
+	}
+	fmt.Println()
+	fmt.Printf("Save a new request with `thand request --save-as %s` or remove it with `thand templates delete %s`\n",
+		template.Name, template.Name)
+	fmt.Println()
+}
+
+// runTemplateRequest submits a request from a template after the user
+// confirms what will be requested
+func runTemplateRequest(template *models.RequestTemplate, details string) error {
+
+	request, err := newRequestFromTemplate(template, template.GetReason(details))
+
+	if err != nil {
+		return err
+	}
+
+	displayTemplateSummary(template, request)
+
+	confirmed := false
+
+	err = runField(huh.NewConfirm().
+		Title("Submit this request?").
+		Value(&confirmed))
+
+	if err != nil {
+		return fmt.Errorf("confirmation cancelled: %w", err)
+	}
+
+	if !confirmed {
+		fmt.Println(infoStyle.Render("Request cancelled"))
+		return nil
+	}
+
+	return MakeElevationRequest(request)
+}
+
+func displayTemplateSummary(template *models.RequestTemplate, request *models.ElevateRequest) {
+	fmt.Println()
+	fmt.Println(headerStyle.Render(fmt.Sprintf("Request from template %s", template.Name)))
+	fmt.Println()
+	fmt.Printf("Providers: %s\n", strings.Join(request.Providers, ", "))
+	fmt.Printf("Role: %s\n", request.Role.Name)
+	if len(template.Resources) > 0 {
+		fmt.Printf("Resources: %s\n", strings.Join(template.Resources, ", "))
+	}
+	fmt.Printf("Duration: %s\n", request.Duration)
+	fmt.Printf("Reason: %s\n", request.Reason)
+	fmt.Println()
+}
+
+// selectTemplate is the first screen of the wizard. It returns nil if the
+// user wants a new request or nothing has been saved.
+func selectTemplate() (*models.RequestTemplate, error) {
+
+	templates, err := sessions.NewTemplateStore().List()
+
+	if err != nil || len(templates) == 0 {
+		return nil, nil
+	}
+
+	options := []huh.Option[string]{
+		huh.NewOption("New request", newRequestOption),
+	}
+
+	for _, template := range templates {
+		options = append(options, huh.NewOption(
+			fmt.Sprintf("%s - %s on %s for %s",
+				template.Name, template.Role, strings.Join(template.Providers, ", "), template.Duration),
+			template.Name,
+		))
+	}
+
+	selected := newRequestOption
+
+	err = huh.NewSelect[string]().
+		Title("Start from a saved template?").
+		Options(options...).
+		Value(&selected).
+		Run()
+
+	if err != nil {
+		return nil, fmt.Errorf("template selection cancelled: %w", err)
+	}
+
+	if selected == newRequestOption {
+		return nil, nil
+	}
+
+	index := slices.IndexFunc(templates, func(t models.RequestTemplate) bool {
+		return t.Name == selected
+	})
+
+	return &templates[index], nil
+}
+
+// requestFromTemplateWizard prompts for the reason, starting from the
+// template's reason prefix
+func requestFromTemplateWizard(template *models.RequestTemplate) (*models.ElevateRequest, error) {
+
+	request, err := newRequestFromTemplate(template, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	reason := template.GetReason("")
+
+	err = runField(huh.NewText().
+		Title("Enter detailed reason for access:").
+		Description("Starts with the template's reason, add any details e.g. the incident").
+		Value(&reason).
+		Validate(func(val string) error {
+			return validateReason(request.Role, val)
+		}))
+
+	if err != nil {
+		return nil, fmt.Errorf("reason input cancelled: %w", err)
+	}
+
+	request.Reason = strings.TrimSpace(reason)
+
+	displayTemplateSummary(template, request)
+
+	return request, nil
+}
+
+func init() {
+
+	templatesCmd.AddCommand(templatesListCmd)
+	templatesCmd.AddCommand(templatesDeleteCmd)
+
+	rootCmd.AddCommand(templatesCmd)
+}
@@ -6,7 +6,10 @@ import (
 	"os"
 	"time"
 
+	"github.com/google/go-github/v57/github"
+	"github.com/kardianos/service"
 	"github.com/spf13/cobra"
+	"github.com/thand-io/agent/internal/agent"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/updater"
 )
@@ -18,85 +21,175 @@ var updateCmd = &cobra.Command{
 This command will check the GitHub repository for the latest release
 and automatically update the binary if a newer version is available.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Get current version
-		version, gitCommit, ok := common.GetModuleBuildInfo()
-		if !ok {
-			fmt.Println("Unable to determine current version")
-			os.Exit(1)
-		}
-
-		fmt.Printf("Current version: %s", version)
-		if len(gitCommit) > 0 {
-			fmt.Printf(" (commit: %s)", gitCommit[:8])
-		}
-		fmt.Println()
-
 		// Check if we should force update
 		force, _ := cmd.Flags().GetBool("force")
 		checkOnly, _ := cmd.Flags().GetBool("check")
 
-		// Create updater instance
-		u := updater.NewUpdater("thand-io", "agent", version)
-
-		// Create context with timeout
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-
-		fmt.Println("Checking for updates...")
-
-		// Check for updates
-		release, err := u.CheckForUpdate(ctx)
-		if err != nil {
-			fmt.Printf("Failed to check for updates: %v\n", err)
-			os.Exit(1)
-		}
+		u, release := checkForUpdate()
 
 		if release == nil {
-			fmt.Println("You are already running the latest version!")
 			return
 		}
 
-		fmt.Printf("🆕 New version available: %s\n", release.GetTagName())
-		if len(release.GetBody()) > 0 {
-			fmt.Printf("Release notes:\n%s\n\n", release.GetBody())
-		}
-
 		// If check-only flag is set, just show info and exit
 		if checkOnly {
-			fmt.Println("ℹ️  Use 'agent update' to install the update")
+			fmt.Println("ℹ️  Use 'agent update apply' to install the update")
 			return
 		}
 
-		// Ask for confirmation unless force flag is set
-		if !force {
-			fmt.Print("Do you want to update now? (y/N): ")
-			var response string
-			fmt.Scanln(&response)
-			if response != "y" && response != "Y" && response != "yes" {
-				fmt.Println("Update cancelled")
-				return
-			}
+		applyUpdate(u, release, force)
+	},
+}
+
+var updateCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check if a newer version is available",
+	Long:  `Check the GitHub releases for a newer version of the Thand Agent without installing it`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, release := checkForUpdate()
+
+		if release != nil {
+			fmt.Println("ℹ️  Use 'agent update apply' to install the update")
 		}
+	},
+}
+
+var updateApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Download and install the latest version",
+	Long: `Download the latest release, verify its SHA256 checksum against the
+published release checksums and replace the current binary. If the agent
+is running as a system service it is restarted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		force, _ := cmd.Flags().GetBool("force")
 
-		fmt.Printf("⬇️  Downloading and installing version %s...\n", release.GetTagName())
+		u, release := checkForUpdate()
 
-		// Perform the update
-		err = u.Update(ctx, release)
-		if err != nil {
-			fmt.Printf("Update failed: %v\n", err)
-			os.Exit(1)
+		if release == nil {
+			return
 		}
 
-		fmt.Printf("Successfully updated to version %s!\n", release.GetTagName())
-		fmt.Println("Please restart the agent to use the new version")
+		applyUpdate(u, release, force)
 	},
 }
 
+// checkForUpdate prints the current version and returns the latest release
+// if it is newer
+func checkForUpdate() (*updater.Updater, *github.RepositoryRelease) {
+	// Get current version
+	version, gitCommit, ok := common.GetModuleBuildInfo()
+	if !ok {
+		fmt.Println("Unable to determine current version")
+		os.Exit(1)
+	}
+
+	fmt.Printf("Current version: %s", version)
+	if len(gitCommit) > 8 {
+		fmt.Printf(" (commit: %s)", gitCommit[:8])
+	}
+	fmt.Println()
+
+	// Create updater instance
+	u := updater.NewUpdater("thand-io", "agent", version)
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	fmt.Println("Checking for updates...")
+
+	// Check for updates
+	release, err := u.CheckForUpdate(ctx)
+	if err != nil {
+		fmt.Printf("Failed to check for updates: %v\n", err)
+		os.Exit(1)
+	}
+
+	if release == nil {
+		fmt.Println("You are already running the latest version!")
+		return u, nil
+	}
+
+	fmt.Printf("🆕 New version available: %s\n", release.GetTagName())
+	if len(release.GetBody()) > 0 {
+		fmt.Printf("Release notes:\n%s\n\n", release.GetBody())
+	}
+
+	return u, release
+}
+
+// applyUpdate replaces the current binary with the release and restarts
+// the system service if one is running
+func applyUpdate(u *updater.Updater, release *github.RepositoryRelease, force bool) {
+
+	// Ask for confirmation unless force flag is set
+	if !force {
+		fmt.Print("Do you want to update now? (y/N): ")
+		var response string
+		fmt.Scanln(&response)
+		if response != "y" && response != "Y" && response != "yes" {
+			fmt.Println("Update cancelled")
+			return
+		}
+	}
+
+	fmt.Printf("⬇️  Downloading and installing version %s...\n", release.GetTagName())
+
+	// Allow longer for the binary download than the release lookup
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// Perform the update
+	err := u.Update(ctx, release)
+	if err != nil {
+		fmt.Printf("Update failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Successfully updated to version %s!\n", release.GetTagName())
+
+	if restartServiceIfRunning() {
+		return
+	}
+
+	fmt.Println("Please restart the agent to use the new version")
+}
+
+// restartServiceIfRunning restarts the agent system service so it picks up
+// the new binary. Returns true if the service was restarted.
+func restartServiceIfRunning() bool {
+	s, err := agent.CreateService(cfg)
+	if err != nil {
+		return false
+	}
+
+	status, err := s.Status()
+	if err != nil || status != service.StatusRunning {
+		return false
+	}
+
+	fmt.Println("Restarting Thand Agent service...")
+
+	if err := s.Restart(); err != nil {
+		fmt.Printf("Failed to restart service: %v\n", err)
+		fmt.Println("   Use 'thand service stop' and 'thand service start' to restart it manually")
+		return false
+	}
+
+	fmt.Println("Thand Agent service restarted successfully")
+	return true
+}
+
 func init() {
 	// Add flags
 	updateCmd.Flags().BoolP("force", "f", false, "Force update without confirmation")
 	updateCmd.Flags().BoolP("check", "c", false, "Only check for updates, don't install")
 
+	updateApplyCmd.Flags().BoolP("force", "f", false, "Update without confirmation")
+
+	updateCmd.AddCommand(updateCheckCmd)
+	updateCmd.AddCommand(updateApplyCmd)
+
 	// Add command to root
 	rootCmd.AddCommand(updateCmd)
 }
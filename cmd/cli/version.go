@@ -50,7 +50,7 @@ var versionCmd = &cobra.Command{
 			fmt.Println("✅ You're running the latest version!")
 		} else {
 			fmt.Printf("🆕 New version available: %s\n", release.GetTagName())
-			fmt.Println("   Run 'agent update' to upgrade")
+			fmt.Println("   Run 'agent update apply' to upgrade")
 		}
 	},
 }
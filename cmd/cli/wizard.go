@@ -2,12 +2,17 @@ package cli
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"regexp"
 	"slices"
 	"strings"
 
 	"github.com/charmbracelet/huh"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config"
@@ -16,10 +21,31 @@ import (
 
 // RunRequestWizard runs the interactive wizard and returns the collected data
 func RunRequestWizard(config *config.Config) (*models.ElevateRequest, error) {
+
+	if !isInteractive() {
+		return nil, fmt.Errorf("%w, use thand request access with --provider, --role, --duration and --reason", ErrNotInteractive)
+	}
+
 	fmt.Println(titleStyle.Render("Thand Agent - Access Request Wizard"))
 	fmt.Println("Configure your elevation request interactively")
 	fmt.Println()
 
+	// Start from a saved template if the user has any
+	template, err := selectTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	if template != nil {
+		return requestFromTemplateWizard(template)
+	}
+
+	return buildRequest(config)
+}
+
+// buildRequest walks the user through building a new request
+func buildRequest(config *config.Config) (*models.ElevateRequest, error) {
+
 	// TODO: If the wizard is using thand.io then default to LLM request
 	// Otherwise use the request builder
 
@@ -45,15 +71,28 @@ func RunRequestWizard(config *config.Config) (*models.ElevateRequest, error) {
 
 	data.Role = foundRole
 
-	// Step 3: Select Duration
+	// Step 3: Narrow resources if the role is scoped to any
+	if len(foundRole.Resources.Allow) > 0 {
+		resources, err := selectResources(provider, foundRole)
+		if err != nil {
+			return nil, err
+		}
+		if len(resources) > 0 {
+			scopedRole := *foundRole
+			scopedRole.Resources.Allow = resources
+			data.Role = &scopedRole
+		}
+	}
+
+	// Step 4: Select Duration
 	duration, err := selectDuration()
 	if err != nil {
 		return nil, err
 	}
 	data.Duration = duration
 
-	// Step 4: Enter Reason
-	reason, err := selectReason()
+	// Step 5: Enter Reason
+	reason, err := selectReason(data.Role)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +131,7 @@ func selectProvider(config *config.Config) (string, error) {
 		),
 	)
 
-	err := form.Run()
+	err := runForm(form)
 	if err != nil {
 		return "", fmt.Errorf("provider selection cancelled: %w", err)
 	}
@@ -127,7 +166,7 @@ func selectRole(config *config.Config, provider string) (string, error) {
 		),
 	)
 
-	err := form.Run()
+	err := runForm(form)
 	if err != nil {
 		return "", fmt.Errorf("role selection cancelled: %w", err)
 	}
@@ -135,6 +174,101 @@ func selectRole(config *config.Config, provider string) (string, error) {
 	return selectedRole, nil
 }
 
+// selectResources lists the live resources of the provider that the role
+// allows and lets the user pick which ones they need. If the provider does
+// not support resource discovery the role resources are left unchanged.
+func selectResources(provider string, role *models.Role) ([]string, error) {
+
+	resources, err := listProviderResources(provider)
+	if err != nil {
+		logrus.WithError(err).Debugln("Skipping resource selection")
+		return nil, nil
+	}
+
+	var options []huh.Option[string]
+	for _, resource := range resources {
+		if !isResourceAllowed(role.Resources.Allow, resource.Result) {
+			continue
+		}
+		label := resource.Result.Name
+		if len(resource.Result.Type) > 0 {
+			label = fmt.Sprintf("%s (%s)", resource.Result.Name, resource.Result.Type)
+		}
+		options = append(options, huh.NewOption(label, resource.Result.ID))
+	}
+
+	if len(options) == 0 {
+		return nil, nil
+	}
+
+	var selectedResources []string
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title(fmt.Sprintf("Select Resources for %s:", role.GetName())).
+				Description("Choose the resources you need access to, leave empty for all allowed resources").
+				Options(options...).
+				Value(&selectedResources),
+		),
+	)
+
+	err = runForm(form)
+	if err != nil {
+		return nil, fmt.Errorf("resource selection cancelled: %w", err)
+	}
+
+	return selectedResources, nil
+}
+
+// listProviderResources fetches the discovered resources for a provider
+// from the login server using the first active session
+func listProviderResources(provider string) ([]models.SearchResult[models.ProviderResource], error) {
+
+	_, session, err := sessionManager.GetFirstActiveSession(cfg.GetLoginServerHostname())
+	if err != nil || session == nil {
+		return nil, fmt.Errorf("no active session to list resources")
+	}
+
+	baseUrl := fmt.Sprintf("%s/%s",
+		strings.TrimPrefix(cfg.GetLoginServerUrl(), "/"),
+		strings.TrimPrefix(cfg.GetApiBasePath(), "/"))
+	resourcesUrl := fmt.Sprintf("%s/provider/%s/resources", baseUrl, url.PathEscape(provider))
+
+	var response models.ProviderResourcesResponse
+
+	res, err := resty.New().R().
+		SetAuthToken(session.GetEncodedLocalSession()).
+		SetResult(&response).
+		Get(resourcesUrl)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	if res.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("failed to list resources: %s", res.Status())
+	}
+
+	return response.Resources, nil
+}
+
+// isResourceAllowed checks a resource against the role allow patterns
+func isResourceAllowed(allow []string, resource models.ProviderResource) bool {
+	for _, pattern := range allow {
+		if pattern == "*" || pattern == resource.ID || pattern == resource.Name {
+			return true
+		}
+		if matched, _ := path.Match(pattern, resource.ID); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, resource.Name); matched {
+			return true
+		}
+	}
+	return false
+}
+
 // getProviderOptions returns provider options from configuration
 func getProviderOptions(config *config.Config) []huh.Option[string] {
 	var options []huh.Option[string]
@@ -259,7 +393,7 @@ func selectDuration() (string, error) {
 		),
 	)
 
-	err := form.Run()
+	err := runForm(form)
 	if err != nil {
 		return "", fmt.Errorf("duration selection cancelled: %w", err)
 	}
@@ -271,13 +405,13 @@ func selectDuration() (string, error) {
 			huh.NewGroup(
 				huh.NewInput().
 					Title("Enter custom duration:").
-					Description("Format: pt30m, pt1h30m, t2h (15m minimum, 8h maximum)").
+					Description("e.g. 90m, PT1H30M or 2 hours (15m minimum, 8h maximum)").
 					Value(&customDuration).
 					Validate(validateDuration),
 			),
 		)
 
-		err = customForm.Run()
+		err = runForm(customForm)
 		if err != nil {
 			return "", fmt.Errorf("custom duration input cancelled: %w", err)
 		}
@@ -287,21 +421,33 @@ func selectDuration() (string, error) {
 	return selectedDuration, nil
 }
 
-// selectReason prompts for reason input
-func selectReason() (string, error) {
+// selectReason prompts for reason input. The reason is checked against the
+// role's synced reason policy so problems show before submitting, and the
+// policy's rules are shown as a hint e.g. the ticket pattern to include.
+func selectReason(role *models.Role) (string, error) {
 	var reason string
 
+	description := "Provide specific justification (minimum 10 characters)"
+
+	if reasonPolicy, err := cfg.GetReasonPolicy(nil, role); err == nil {
+		if hint := reasonPolicy.GetHint(); len(hint) > 0 {
+			description = fmt.Sprintf("Provide specific justification (%s)", hint)
+		}
+	}
+
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewText().
 				Title("Enter detailed reason for access:").
-				Description("Provide specific justification (minimum 10 characters)").
+				Description(description).
 				Value(&reason).
-				Validate(validateReason),
+				Validate(func(val string) error {
+					return validateReason(role, val)
+				}),
 		),
 	)
 
-	err := form.Run()
+	err := runForm(form)
 	if err != nil {
 		return "", fmt.Errorf("reason input cancelled: %w", err)
 	}
@@ -316,10 +462,16 @@ func validateDuration(val string) error {
 }
 
 // validateReason validates reason input
-func validateReason(val string) error {
+func validateReason(role *models.Role, val string) error {
 	reason := strings.TrimSpace(val)
+
+	if err := cfg.CheckReason(nil, role, reason); err != nil {
+		return err
+	}
+
+	// The reason policy allows an empty reason
 	if len(reason) == 0 {
-		return fmt.Errorf("reason cannot be empty")
+		return nil
 	}
 
 	if len(reason) < 10 {
@@ -0,0 +1,286 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	workflowtesting "github.com/thand-io/agent/internal/workflows/testing"
+)
+
+var workflowsCmd = &cobra.Command{
+	Use:               "workflows",
+	Short:             "Manage configured workflows",
+	Long:              "Inspect and test the workflows configured for this server",
+	PersistentPreRunE: preRunWorkflowsConfigE,
+}
+
+var workflowsTestCmd = &cobra.Command{
+	Use:   "test <name>",
+	Short: "Simulate a workflow against mock providers",
+	Long: `Run a workflow locally against mock providers. Timers run in simulated
+time so approvals, waits and monitors complete straight away.
+
+Approvals are approved after --approval-delay, notifiers are recorded
+instead of sent and authorize and revoke only log. A denial or timer
+expiry can be injected at a named task.
+
+The request is read from a JSON or YAML fixture with --input, the
+--role, --reason and --duration flags override the fixture.
+
+Exits with a non-zero code if the workflow fails.
+
+Example:
+  thand workflows test slack_approval --input request.yaml
+  thand workflows test slack_approval --role admin --reason "Incident" --deny approvals
+  thand workflows test slack_approval --input request.yaml --expire monitor --json`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runWorkflowsTest,
+}
+
+// preRunWorkflowsConfigE loads the server configuration, roles and
+// workflows. Providers are replaced with mocks and nothing is registered
+// with the thand server.
+func preRunWorkflowsConfigE(cmd *cobra.Command, _ []string) error {
+	var err error
+	cfg, err = loadConfig(cmd)
+
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	cfg.SetMode(config.ModeServer)
+
+	// The simulation logs every task at info so only show it when asked
+	logrus.SetLevel(logrus.WarnLevel)
+
+	verbose, err := cmd.Flags().GetBool("verbose")
+	if err == nil && verbose {
+		logrus.SetLevel(logrus.DebugLevel)
+	}
+
+	providers, err := cfg.LoadProviders()
+
+	if err != nil {
+		return fmt.Errorf("failed to load providers: %w", err)
+	}
+
+	cfg.Providers.Definitions = providers
+
+	if err := workflowtesting.InitializeMockProviders(cfg); err != nil {
+		return fmt.Errorf("failed to initialize mock providers: %w", err)
+	}
+
+	roles, err := cfg.LoadRoles()
+
+	if err != nil {
+		return fmt.Errorf("failed to load roles: %w", err)
+	}
+
+	cfg.Roles.Definitions = roles
+
+	workflows, err := cfg.LoadWorkflows()
+
+	if err != nil {
+		return fmt.Errorf("failed to load workflows: %w", err)
+	}
+
+	cfg.Workflows.Definitions = workflows
+
+	if err := cfg.ApplyWorkflowTemplates(); err != nil {
+		return fmt.Errorf("failed to expand workflow templates: %w", err)
+	}
+
+	return nil
+}
+
+func runWorkflowsTest(cmd *cobra.Command, args []string) error {
+
+	workflowName := args[0]
+
+	request, err := getWorkflowTestRequest(cmd)
+	if err != nil {
+		return err
+	}
+
+	jsonOutput, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to get json flag: %w", err)
+	}
+
+	approvalDelay, err := cmd.Flags().GetDuration("approval-delay")
+	if err != nil {
+		return fmt.Errorf("failed to get approval-delay flag: %w", err)
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return fmt.Errorf("failed to get timeout flag: %w", err)
+	}
+
+	denyAt, err := cmd.Flags().GetString("deny")
+	if err != nil {
+		return fmt.Errorf("failed to get deny flag: %w", err)
+	}
+
+	expireAt, err := cmd.Flags().GetString("expire")
+	if err != nil {
+		return fmt.Errorf("failed to get expire flag: %w", err)
+	}
+
+	simulator := workflowtesting.NewSimulator(cfg, workflowtesting.Options{
+		ApprovalDelay: approvalDelay,
+		DenyAt:        denyAt,
+		ExpireAt:      expireAt,
+		Timeout:       timeout,
+	})
+
+	result, err := simulator.Run(workflowName, request)
+
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal result: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		displayWorkflowTestResult(result)
+	}
+
+	if !result.Succeeded() {
+		return fmt.Errorf("workflow %s did not complete: %s", workflowName, result.Error)
+	}
+
+	return nil
+}
+
+// getWorkflowTestRequest reads the request fixture and applies the
+// request flags on top of it
+func getWorkflowTestRequest(cmd *cobra.Command) (*models.ElevateRequestInternal, error) {
+
+	request := &models.ElevateRequestInternal{}
+
+	input, err := cmd.Flags().GetString("input")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get input flag: %w", err)
+	}
+
+	if len(input) > 0 {
+		request, err = workflowtesting.LoadRequest(input)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if role, _ := cmd.Flags().GetString("role"); len(role) > 0 {
+		request.Role = &models.Role{Name: role}
+	}
+
+	if reason, _ := cmd.Flags().GetString("reason"); len(reason) > 0 {
+		request.Reason = reason
+	}
+
+	if duration, _ := cmd.Flags().GetString("duration"); len(duration) > 0 {
+		request.Duration = duration
+	}
+
+	if request.Role == nil {
+		return nil, errors.New("a role is required, set it in the --input fixture or with --role")
+	}
+
+	return request, nil
+}
+
+func displayWorkflowTestResult(result *workflowtesting.Result) {
+
+	fmt.Printf("Workflow: %s\n\n", result.Workflow)
+
+	fmt.Printf("%-10s %-20s %-20s %s\n", "AT", "TASK", "TYPE", "CHANGES")
+	fmt.Printf("%-10s %-20s %-20s %s\n", "--", "----", "----", "-------")
+
+	for _, step := range result.Steps {
+
+		taskType := step.Type
+		if step.Mocked {
+			taskType += " (mock)"
+		}
+
+		changes := make([]string, 0, len(step.Changes))
+		for _, change := range step.Changes {
+			switch change.Action {
+			case workflowtesting.ContextChangeAdded:
+				changes = append(changes, "+"+change.Key)
+			case workflowtesting.ContextChangeRemoved:
+				changes = append(changes, "-"+change.Key)
+			default:
+				changes = append(changes, "~"+change.Key)
+			}
+		}
+
+		fmt.Printf("%-10s %-20s %-20s %s\n",
+			step.At.Round(time.Second),
+			step.Task,
+			taskType,
+			strings.Join(changes, " "),
+		)
+	}
+
+	if len(result.Notifications) > 0 {
+
+		fmt.Printf("\nNotifications:\n")
+
+		for _, notification := range result.Notifications {
+			fmt.Printf("  %-10s %-12s %-10s %-10s %s\n",
+				notification.At.Round(time.Second),
+				notification.Task,
+				notification.Kind,
+				notification.Provider,
+				strings.Join(notification.To, ","),
+			)
+		}
+	}
+
+	approved := "-"
+	if result.Approved != nil {
+		approved = fmt.Sprintf("%t", *result.Approved)
+	}
+
+	status := successStyle.Render(strings.ToUpper(string(result.Status)))
+	if !result.Succeeded() {
+		status = errorStyle.Render(strings.ToUpper(string(result.Status)))
+	}
+
+	fmt.Printf("\nOutcome:  %s\n", status)
+	fmt.Printf("Approved: %s\n", approved)
+	fmt.Printf("Elapsed:  %s (simulated)\n", result.Elapsed.Round(time.Second))
+
+	if len(result.Error) > 0 {
+		fmt.Printf("Error:    %s\n", errorStyle.Render(result.Error))
+	}
+}
+
+func init() {
+	workflowsTestCmd.Flags().StringP("input", "i", "", "JSON or YAML elevation request fixture")
+	workflowsTestCmd.Flags().String("role", "", "Role to request, overrides the fixture")
+	workflowsTestCmd.Flags().String("reason", "", "Reason for the request, overrides the fixture")
+	workflowsTestCmd.Flags().String("duration", "", "Duration to request e.g. 1h, overrides the fixture")
+	workflowsTestCmd.Flags().Duration("approval-delay", workflowtesting.DefaultApprovalDelay, "Simulated time before approvals arrive")
+	workflowsTestCmd.Flags().Duration("timeout", workflowtesting.DefaultTimeout, "Simulated time after which a waiting workflow is cancelled")
+	workflowsTestCmd.Flags().String("deny", "", "Deny the request at the named task")
+	workflowsTestCmd.Flags().String("expire", "", "Expire the timer of the named task")
+	workflowsTestCmd.Flags().Bool("json", false, "Output the result as JSON")
+
+	workflowsCmd.AddCommand(workflowsTestCmd)
+	rootCmd.AddCommand(workflowsCmd)
+}
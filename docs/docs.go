@@ -137,13 +137,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/auth/logout": {
-            "get": {
-                "description": "Clear the user session and logout",
+            },
+            "post": {
+                "description": "Handle a callback posted by the provider e.g. a SAML response using the HTTP-POST binding. The state is read from the RelayState",
                 "consumes": [
-                    "application/json"
+                    "application/x-www-form-urlencoded"
                 ],
                 "produces": [
                     "application/json"
@@ -151,17 +149,60 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Logout",
+                "summary": "Authentication callback (POST)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State",
+                        "name": "RelayState",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "SAML response",
+                        "name": "SAMLResponse",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "Logged out successfully",
+                        "description": "Authentication successful"
+                    },
+                    "400": {
+                        "description": "Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    },
-                    "307": {
-                        "description": "Redirect to home page"
+                    }
+                }
+            }
+        },
+        "/auth/device/code": {
+            "post": {
+                "description": "Start the device login flow for CLIs that can't open a browser. The user approves the returned user code at the verification URI while the CLI polls the token endpoint",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request a device code",
+                "responses": {
+                    "200": {
+                        "description": "Device code issued",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeviceCodeResponse"
+                        }
                     },
                     "500": {
                         "description": "Internal server error",
@@ -173,9 +214,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/logout/{provider}": {
-            "get": {
-                "description": "Clear the user session and logout",
+        "/auth/device/token": {
+            "post": {
+                "description": "Poll with the device code until the user approves or denies it. Codes are single use, and polling faster than the interval returns slow_down",
                 "consumes": [
                     "application/json"
                 ],
@@ -185,39 +226,43 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Logout",
+                "summary": "Poll for a device session",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Provider name",
-                        "name": "provider",
-                        "in": "path"
+                        "description": "Device code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DeviceTokenRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Logged out successfully",
+                        "description": "Session for the approved device code",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.DeviceTokenResponse"
                         }
                     },
-                    "307": {
-                        "description": "Redirect to home page"
+                    "400": {
+                        "description": "Pending, denied or expired",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeviceTokenError"
+                        }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "429": {
+                        "description": "Polling too quickly",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.DeviceTokenError"
                         }
                     }
                 }
             }
         },
-        "/auth/request/{provider}": {
+        "/auth/logout": {
             "get": {
-                "description": "Start the OAuth2 authentication flow for a provider",
+                "description": "Clear the user session and logout",
                 "consumes": [
                     "application/json"
                 ],
@@ -227,39 +272,17 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Initiate authentication",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Provider name",
-                        "name": "provider",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Callback URL",
-                        "name": "callback",
-                        "in": "query"
-                    }
-                ],
+                "summary": "Logout",
                 "responses": {
-                    "307": {
-                        "description": "Redirect to provider authentication"
-                    },
-                    "400": {
-                        "description": "Bad request",
+                    "200": {
+                        "description": "Logged out successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Provider not found",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                    "307": {
+                        "description": "Redirect to home page"
                     },
                     "500": {
                         "description": "Internal server error",
@@ -271,9 +294,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/elevate": {
+        "/auth/logout/{provider}": {
             "get": {
-                "description": "Request elevation to a specific role with static parameters",
+                "description": "Clear the user session and logout",
                 "consumes": [
                     "application/json"
                 ],
@@ -281,113 +304,64 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "elevate"
+                    "auth"
                 ],
-                "summary": "Request role elevation",
+                "summary": "Logout",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Role name",
-                        "name": "role",
-                        "in": "query",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "description": "Provider name",
                         "name": "provider",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Reason for elevation",
-                        "name": "reason",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Duration of elevation",
-                        "name": "duration",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Workflow name",
-                        "name": "workflow",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Identity filter",
-                        "name": "identities",
-                        "in": "query"
+                        "in": "path"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Elevation request submitted",
+                        "description": "Logged out successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "307": {
+                        "description": "Redirect to home page"
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Submit an elevation request with dynamic or static parameters",
+            }
+        },
+        "/auth/providers": {
+            "get": {
+                "description": "Get the providers that can be used to log in, so clients can choose one when more than one is configured",
                 "consumes": [
-                    "application/json",
-                    "application/x-www-form-urlencoded",
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "elevate"
-                ],
-                "summary": "Submit elevation request",
-                "parameters": [
-                    {
-                        "description": "Elevation request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.ElevateRequest"
-                        }
-                    }
+                    "auth"
                 ],
+                "summary": "List authentication providers",
                 "responses": {
                     "200": {
-                        "description": "Elevation request submitted",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "Bad request",
+                        "description": "Authentication providers",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.ProvidersResponse"
                         }
                     }
                 }
             }
         },
-        "/elevate/llm": {
-            "get": {
-                "description": "Request elevation using natural language reasoning with LLM",
+        "/auth/refresh": {
+            "post": {
+                "description": "Renew the session cookie with the provider's refresh token. The session may already have expired. Returns 401 when there is no refresh token and the user must login again",
                 "consumes": [
                     "application/json"
                 ],
@@ -395,37 +369,53 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "elevate"
+                    "auth"
                 ],
-                "summary": "LLM-based elevation",
+                "summary": "Refresh session",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Natural language reason for elevation",
-                        "name": "reason",
-                        "in": "query",
-                        "required": true
+                        "description": "Provider and refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuthRefreshRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "LLM response with suggested role",
+                        "description": "Session renewed",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuthRefreshResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid provider",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "401": {
+                        "description": "Session can't be renewed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/renew": {
             "post": {
-                "description": "Request elevation using natural language with POST request",
+                "description": "Renew the session presented as the bearer token and return it for the CLI to store. Returns 501 when the provider doesn't support renewal and the user must login again",
                 "consumes": [
                     "application/json"
                 ],
@@ -433,63 +423,88 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "elevate"
+                    "auth"
                 ],
-                "summary": "LLM-based elevation (POST)",
+                "summary": "Renew session",
                 "parameters": [
                     {
-                        "description": "LLM elevation request",
+                        "description": "Provider of the session",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ElevateLLMRequest"
+                            "$ref": "#/definitions/models.AuthRenewRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "LLM response with suggested role",
+                        "description": "Session renewed",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuthRenewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid provider",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "401": {
+                        "description": "Session can't be renewed",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/elevate/resume": {
-            "get": {
-                "description": "Resume a paused or interrupted elevation workflow",
-                "consumes": [
-                    "application/json"
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "501": {
+                        "description": "Renewal unsupported",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/request/{provider}": {
+            "get": {
+                "description": "Start the OAuth2 authentication flow for a provider",
+                "consumes": [
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "elevate"
+                    "auth"
                 ],
-                "summary": "Resume elevation workflow",
+                "summary": "Initiate authentication",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Workflow state token",
-                        "name": "state",
-                        "in": "query",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Callback URL",
+                        "name": "callback",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "307": {
-                        "description": "Redirect to next workflow step"
+                        "description": "Redirect to provider authentication"
                     },
                     "400": {
                         "description": "Bad request",
@@ -497,39 +512,16 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            },
-            "post": {
-                "description": "Resume a paused elevation workflow with POST data",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "elevate"
-                ],
-                "summary": "Resume elevation workflow (POST)",
-                "parameters": [
-                    {
-                        "description": "Resume request data",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                    },
+                    "404": {
+                        "description": "Provider not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                ],
-                "responses": {
-                    "307": {
-                        "description": "Redirect to next workflow step"
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -538,14 +530,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/execution": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Create and start a new workflow execution",
+        "/capabilities": {
+            "get": {
+                "description": "Get the configured providers that support each capability, with optional capability filtering",
                 "consumes": [
                     "application/json"
                 ],
@@ -553,47 +540,49 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "executions"
+                    "providers"
                 ],
-                "summary": "Create workflow execution",
+                "summary": "List provider capabilities",
                 "parameters": [
                     {
-                        "description": "Workflow creation request",
-                        "name": "workflow",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                        "type": "string",
+                        "description": "Comma-separated list of capabilities to return",
+                        "name": "type",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Workflow created",
+                    "200": {
+                        "description": "Providers by capability",
+                        "schema": {
+                            "$ref": "#/definitions/models.CapabilitiesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown capability",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/execution/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Retrieve detailed information about a specific workflow execution",
+                ]
+            }
+        },
+        "/delegations": {
+            "get": {
+                "description": "List the delegations that haven't expired where the caller is the approver or the delegate",
                 "consumes": [
                     "application/json"
                 ],
@@ -601,30 +590,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "executions"
-                ],
-                "summary": "Get workflow execution",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Workflow execution ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "delegations"
                 ],
+                "summary": "List delegations",
                 "responses": {
                     "200": {
-                        "description": "Workflow execution details",
+                        "description": "Delegations",
                         "schema": {
-                            "$ref": "#/definitions/daemon.ExecutionStatePageData"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.DelegationsResponse"
                         }
                     },
                     "401": {
@@ -634,24 +607,22 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "501": {
+                        "description": "Storage service is not configured",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/execution/{id}/cancel": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Gracefully cancel a running workflow execution",
+                ]
+            },
+            "post": {
+                "description": "Delegate the caller's approvals to another approver between start and end. The delegate is notified instead of, or as well as, the caller and their approvals are recorded on the caller's behalf",
                 "consumes": [
                     "application/json"
                 ],
@@ -659,65 +630,59 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "executions"
+                    "delegations"
                 ],
-                "summary": "Cancel workflow execution",
+                "summary": "Delegate approvals",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Workflow execution ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Delegation",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DelegationRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Workflow cancelled",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                    "201": {
+                        "description": "Delegation created",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.Delegation"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "400": {
+                        "description": "Invalid delegation",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Workflow not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "501": {
+                        "description": "Storage service is not configured",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/execution/{id}/signal": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Send a signal event to a running workflow execution",
+                ]
+            }
+        },
+        "/delegations/all": {
+            "get": {
+                "description": "List every delegation that hasn't expired. Expired delegations are removed. Requires admin access",
                 "consumes": [
                     "application/json"
                 ],
@@ -725,38 +690,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "executions"
-                ],
-                "summary": "Signal workflow execution",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Workflow execution ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Encoded signal data",
-                        "name": "input",
-                        "in": "query",
-                        "required": true
-                    }
+                    "delegations"
                 ],
+                "summary": "List all delegations",
                 "responses": {
                     "200": {
-                        "description": "Signal sent successfully",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "Bad request",
+                        "description": "Delegations",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.DelegationsResponse"
                         }
                     },
                     "401": {
@@ -773,24 +714,24 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "501": {
+                        "description": "Storage service is not configured",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/execution/{id}/terminate": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Forcefully terminate a running workflow execution",
+                ]
+            }
+        },
+        "/delegations/{id}": {
+            "delete": {
+                "description": "Remove one of the caller's delegations. Admins can remove any delegation",
                 "consumes": [
                     "application/json"
                 ],
@@ -798,25 +739,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "executions"
+                    "delegations"
                 ],
-                "summary": "Terminate workflow execution",
+                "summary": "Remove delegation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Workflow execution ID",
+                        "description": "Delegation ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Workflow terminated",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                    "204": {
+                        "description": "Delegation removed"
                     },
                     "401": {
                         "description": "Unauthorized",
@@ -825,110 +762,97 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
                     "404": {
-                        "description": "Workflow not found",
+                        "description": "Delegation not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "501": {
+                        "description": "Storage service is not configured",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/executions": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Get a list of all running workflow executions for the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/device": {
+            "get": {
+                "description": "Display the page where a signed in user approves a device code",
                 "produces": [
-                    "application/json"
+                    "text/html"
                 ],
                 "tags": [
-                    "executions"
+                    "auth"
+                ],
+                "summary": "Device approval page",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User code shown by the CLI",
+                        "name": "user_code",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List workflow executions",
                 "responses": {
                     "200": {
-                        "description": "List of workflow executions",
-                        "schema": {
-                            "$ref": "#/definitions/daemon.ExecutionsPageData"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                        "description": "Device approval page"
                     }
                 }
-            }
-        },
-        "/health": {
-            "get": {
-                "description": "Get the health status of the service and its dependencies",
+            },
+            "post": {
+                "description": "Approve or deny a device code. Approving shares the signed in user's session with the CLI",
                 "consumes": [
-                    "application/json"
+                    "application/x-www-form-urlencoded"
                 ],
                 "produces": [
-                    "application/json"
+                    "text/html"
                 ],
                 "tags": [
-                    "health"
+                    "auth"
+                ],
+                "summary": "Approve device code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User code shown by the CLI",
+                        "name": "user_code",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "approve or deny",
+                        "name": "action",
+                        "in": "formData",
+                        "required": true
+                    }
                 ],
-                "summary": "Health check",
                 "responses": {
                     "200": {
-                        "description": "Health status",
+                        "description": "Result page"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/models.HealthResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 }
             }
         },
-        "/identities": {
+        "/elevate": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get a list of available identities from all identity providers",
+                "description": "Request elevation to a specific role with static parameters",
                 "consumes": [
                     "application/json"
                 ],
@@ -936,80 +860,113 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "identities"
+                    "elevate"
                 ],
-                "summary": "List identities",
+                "summary": "Request role elevation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter query",
-                        "name": "q",
+                        "description": "Role name",
+                        "name": "role",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reason for elevation",
+                        "name": "reason",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Duration of elevation",
+                        "name": "duration",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Workflow name",
+                        "name": "workflow",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Identity filter",
+                        "name": "identities",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of identities",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "403": {
-                        "description": "Forbidden",
+                        "description": "Elevation request submitted",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "400": {
+                        "description": "Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            }
-        },
-        "/metrics": {
-            "get": {
-                "description": "Get service metrics including uptime, request counts, and resource counts",
+            },
+            "post": {
+                "description": "Submit an elevation request with dynamic or static parameters",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-www-form-urlencoded",
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "metrics"
+                    "elevate"
+                ],
+                "summary": "Submit elevation request",
+                "parameters": [
+                    {
+                        "description": "Elevation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ElevateRequest"
+                        }
+                    }
                 ],
-                "summary": "Service metrics",
                 "responses": {
                     "200": {
-                        "description": "Service metrics",
+                        "description": "Elevation request submitted",
                         "schema": {
-                            "$ref": "#/definitions/models.MetricsInfo"
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 }
             }
         },
-        "/provider/{provider}": {
+        "/elevate/llm": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Retrieve detailed information about a specific provider",
+                "description": "Request elevation using natural language reasoning with LLM",
                 "consumes": [
                     "application/json"
                 ],
@@ -1017,43 +974,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "providers"
+                    "elevate"
                 ],
-                "summary": "Get provider by name",
+                "summary": "LLM-based elevation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Provider name",
-                        "name": "provider",
-                        "in": "path",
+                        "description": "Natural language reason for elevation",
+                        "name": "reason",
+                        "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Provider details",
+                        "description": "LLM response with suggested role",
                         "schema": {
-                            "$ref": "#/definitions/models.ProviderResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Provider not found",
+                    "400": {
+                        "description": "Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            }
-        },
-        "/provider/{provider}/authorizeSession": {
+            },
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Authorize a session with a specific provider",
+                "description": "Request elevation using natural language with POST request",
                 "consumes": [
                     "application/json"
                 ],
@@ -1061,30 +1012,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "providers"
+                    "elevate"
                 ],
-                "summary": "Authorize provider session",
+                "summary": "LLM-based elevation (POST)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Provider name",
-                        "name": "provider",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Authorization request",
-                        "name": "user",
+                        "description": "LLM elevation request",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.AuthorizeUser"
+                            "$ref": "#/definitions/models.ElevateLLMRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Authorization response",
+                        "description": "LLM response with suggested role",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1096,32 +1040,13 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
-                    },
-                    "404": {
-                        "description": "Provider not found",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
                     }
                 }
             }
         },
-        "/provider/{provider}/identities": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get a list of identities available in a specific provider",
+        "/elevate/plan": {
+            "post": {
+                "description": "Validate an elevation request and evaluate policies without starting the workflow",
                 "consumes": [
                     "application/json"
                 ],
@@ -1129,56 +1054,62 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "providers"
+                    "elevate"
                 ],
-                "summary": "List provider identities",
+                "summary": "Plan elevation request",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Provider name",
-                        "name": "provider",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter query",
-                        "name": "q",
-                        "in": "query"
+                        "description": "Elevation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ElevateRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Provider roles",
+                        "description": "Elevation plan",
                         "schema": {
-                            "$ref": "#/definitions/models.ProviderIdentitiesResponse"
+                            "$ref": "#/definitions/models.ElevatePlanResponse"
                         }
                     },
-                    "404": {
-                        "description": "Provider not found",
+                    "400": {
+                        "description": "Bad request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/provider/{provider}/permissions": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Get a list of permissions available in a specific provider",
+                ]
+            }
+        },
+        "/elevate/resume": {
+            "get": {
+                "description": "Resume a paused or interrupted elevation workflow",
                 "consumes": [
                     "application/json"
                 ],
@@ -1186,56 +1117,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "providers"
+                    "elevate"
                 ],
-                "summary": "List provider permissions",
+                "summary": "Resume elevation workflow",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Provider name",
-                        "name": "provider",
-                        "in": "path",
+                        "description": "Workflow state token",
+                        "name": "state",
+                        "in": "query",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter query",
-                        "name": "q",
-                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Provider permissions",
-                        "schema": {
-                            "$ref": "#/definitions/models.ProviderPermissionsResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Provider not found",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                    "307": {
+                        "description": "Redirect to next workflow step"
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "400": {
+                        "description": "Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            }
-        },
-        "/provider/{provider}/roles": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get a list of roles available in a specific provider",
+            },
+            "post": {
+                "description": "Resume a paused elevation workflow with POST data",
                 "consumes": [
                     "application/json"
                 ],
@@ -1243,40 +1151,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "providers"
+                    "elevate"
                 ],
-                "summary": "List provider roles",
+                "summary": "Resume elevation workflow (POST)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Provider name",
-                        "name": "provider",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter query",
-                        "name": "q",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Provider roles",
-                        "schema": {
-                            "$ref": "#/definitions/models.ProviderRolesResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Provider not found",
+                        "description": "Resume request data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
+                    }
+                ],
+                "responses": {
+                    "307": {
+                        "description": "Redirect to next workflow step"
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "400": {
+                        "description": "Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1285,76 +1180,67 @@ const docTemplate = `{
                 }
             }
         },
-        "/providers": {
+        "/elevate/{id}/events": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get a list of all available providers with optional capability filtering",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Stream status transitions (pending, approved, granted, revoked or denied) of an elevation as server-sent events. Each change is sent as a ` + "`" + `status` + "`" + ` event and a ` + "`" + `heartbeat` + "`" + ` event is sent periodically. The stream closes once the elevation reaches a terminal state. Only the requester, its approvers and admins may subscribe.",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "providers"
+                    "elevate"
                 ],
-                "summary": "List providers",
+                "summary": "Stream elevation status",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Comma-separated list of capabilities to filter by",
-                        "name": "capability",
-                        "in": "query"
+                        "description": "Workflow execution ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of providers",
+                        "description": "Stream of status events",
                         "schema": {
-                            "$ref": "#/definitions/models.ProvidersResponse"
+                            "$ref": "#/definitions/models.ElevationEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/ready": {
-            "get": {
-                "description": "Check if the service is ready to accept requests",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "health"
-                ],
-                "summary": "Readiness check",
-                "responses": {
-                    "200": {
-                        "description": "Ready status",
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Elevation not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/register": {
+        "/execution": {
             "post": {
-                "description": "Register a new agent with the server",
+                "description": "Create and start a new workflow execution",
                 "consumes": [
                     "application/json"
                 ],
@@ -1362,25 +1248,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "registration"
+                    "executions"
                 ],
-                "summary": "Register agent",
+                "summary": "Create workflow execution",
                 "parameters": [
                     {
-                        "description": "Registration request",
-                        "name": "registration",
+                        "description": "Workflow creation request",
+                        "name": "workflow",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_thand-io_agent_internal_config.RegistrationRequest"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Registration successful",
+                    "201": {
+                        "description": "Workflow created",
                         "schema": {
-                            "$ref": "#/definitions/github_com_thand-io_agent_internal_config.RegistrationResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
@@ -1390,17 +1278,17 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/role/{role}": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Retrieve detailed information about a specific role",
+                ]
+            }
+        },
+        "/execution/{id}": {
+            "get": {
+                "description": "Retrieve detailed information about a specific workflow execution",
                 "consumes": [
                     "application/json"
                 ],
@@ -1408,23 +1296,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "roles"
+                    "executions"
                 ],
-                "summary": "Get role by name",
+                "summary": "Get workflow execution",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Role name",
-                        "name": "role",
+                        "description": "Workflow execution ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Role details",
+                        "description": "Workflow execution details",
                         "schema": {
-                            "$ref": "#/definitions/models.RoleResponse"
+                            "$ref": "#/definitions/daemon.ExecutionStatePageData"
                         }
                     },
                     "400": {
@@ -1434,67 +1322,31 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Role not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/roles": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get a list of all available roles with optional provider filtering",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "roles"
-                ],
-                "summary": "List roles",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Comma-separated list of providers to filter by",
-                        "name": "provider",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "List of roles",
-                        "schema": {
-                            "$ref": "#/definitions/models.RolesResponse"
-                        }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/roles/evaluate": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Evaluate a role against an identity to get the composite role with all inherited permissions resolved",
+                ]
+            }
+        },
+        "/execution/{id}/cancel": {
+            "get": {
+                "description": "Gracefully cancel a running workflow execution",
                 "consumes": [
                     "application/json"
                 ],
@@ -1502,43 +1354,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "roles"
+                    "executions"
                 ],
-                "summary": "Evaluate composite role",
+                "summary": "Cancel workflow execution",
                 "parameters": [
                     {
-                        "description": "Role evaluation request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/daemon.EvaluateRoleRequest"
-                        }
+                        "type": "string",
+                        "description": "Workflow execution ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Evaluated composite role",
+                        "description": "Workflow cancelled",
                         "schema": {
-                            "$ref": "#/definitions/daemon.EvaluateRoleResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "404": {
-                        "description": "Role or identity not found",
+                        "description": "Workflow not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1551,12 +1402,17 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/session/{provider}": {
-            "get": {
-                "description": "Retrieve session information for a specific provider",
+        "/execution/{id}/revoke": {
+            "post": {
+                "description": "Revoke an elevation by cancelling its workflow, any granted access is removed by the workflow cleanup",
                 "consumes": [
                     "application/json"
                 ],
@@ -1564,51 +1420,61 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sessions"
+                    "executions"
                 ],
-                "summary": "Get session by provider",
+                "summary": "Revoke elevation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Provider name",
-                        "name": "provider",
+                        "description": "Workflow execution ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Session information",
+                        "description": "Revocation requested",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Session not found",
+                        "description": "Workflow not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
-                }
-            },
-            "delete": {
-                "description": "Remove a session for a specific provider",
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/execution/{id}/signal": {
+            "get": {
+                "description": "Send a signal event to a running workflow execution",
                 "consumes": [
                     "application/json"
                 ],
@@ -1616,21 +1482,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sessions"
+                    "executions"
                 ],
-                "summary": "Delete session",
+                "summary": "Signal workflow execution",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Provider name",
-                        "name": "provider",
+                        "description": "Workflow execution ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoded signal data",
+                        "name": "input",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key to retry the signal with, retries return the original result",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Session deleted successfully",
+                        "description": "Signal sent successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1643,38 +1522,22 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/sessions": {
-            "get": {
-                "description": "Retrieve all active sessions for the user",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "sessions"
-                ],
-                "summary": "Get all sessions",
-                "responses": {
-                    "200": {
-                        "description": "List of sessions",
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/sessions.LoginServer"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "422": {
+                        "description": "Idempotency key used for a different signal",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1687,64 +1550,98 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             },
             "post": {
-                "description": "Create a new session with the provided session token",
+                "description": "Send a signal event such as an approval or denial to a running workflow execution",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-www-form-urlencoded"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "sessions"
+                    "executions"
                 ],
-                "summary": "Create a new session",
+                "summary": "Signal workflow execution (POST)",
                 "parameters": [
                     {
-                        "description": "Session creation request",
-                        "name": "session",
+                        "type": "string",
+                        "description": "Workflow execution ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Encoded signal data",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.SessionCreateRequest"
+                            "$ref": "#/definitions/models.WorkflowSignalRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key to retry the signal with, retries return the original result",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Session created successfully",
+                        "description": "Signal sent successfully",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/daemon.ExecutionStatePageResponse"
                         }
                     },
                     "400": {
                         "description": "Bad request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency key used for a different signal",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/sync": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Get the current sync status and version information",
+                ]
+            }
+        },
+        "/execution/{id}/terminate": {
+            "get": {
+                "description": "Forcefully terminate a running workflow execution",
                 "consumes": [
                     "application/json"
                 ],
@@ -1752,53 +1649,162 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sync"
+                    "executions"
                 ],
-                "summary": "Sync status",
-                "responses": {
-                    "200": {
-                        "description": "Sync status",
+                "summary": "Terminate workflow execution",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workflow execution ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Workflow terminated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Workflow not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/workflow/{name}": {
+        "/executions": {
             "get": {
+                "description": "Get a list of all running workflow executions for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "executions"
+                ],
+                "summary": "List workflow executions",
+                "responses": {
+                    "200": {
+                        "description": "List of workflow executions",
+                        "schema": {
+                            "$ref": "#/definitions/models.WorkflowExecutionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Retrieve detailed information about a specific workflow",
+                ]
+            }
+        },
+        "/grants": {
+            "get": {
+                "description": "List who currently has access from the running, approved elevations. Set Accept to text/csv to export as CSV.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "workflows"
+                    "grants"
                 ],
-                "summary": "Get workflow by name",
+                "summary": "List active grants",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Workflow name",
-                        "name": "name",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter by provider",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by user email",
+                        "name": "user",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of grants per page",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token for the next page",
+                        "name": "page_token",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Workflow details",
+                        "description": "Active grants",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.GrantsResponse"
                         }
                     },
                     "400": {
@@ -1808,24 +1814,131 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Workflow not found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/workflows": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
+                ]
+            }
+        },
+            "/grants/reconcile": {
+                "post": {
+                    "description": "Run the reconciliation sweep now. Grants created by thand that no running elevation holds are revoked, or reported, based on reconcile.action.",
+                    "consumes": [
+                        "application/json"
+                    ],
+                    "produces": [
+                        "application/json"
+                    ],
+                    "tags": [
+                        "grants"
+                    ],
+                    "summary": "Reconcile grants",
+                    "responses": {
+                        "200": {
+                            "description": "Sweep result",
+                            "schema": {
+                                "$ref": "#/definitions/models.ReconcileResult"
+                            }
+                        },
+                        "400": {
+                            "description": "Bad request",
+                            "schema": {
+                                "type": "object",
+                                "additionalProperties": true
+                            }
+                        },
+                        "401": {
+                            "description": "Unauthorized",
+                            "schema": {
+                                "type": "object",
+                                "additionalProperties": true
+                            }
+                        },
+                        "403": {
+                            "description": "Forbidden",
+                            "schema": {
+                                "type": "object",
+                                "additionalProperties": true
+                            }
+                        },
+                        "409": {
+                            "description": "A sweep is already running",
+                            "schema": {
+                                "type": "object",
+                                "additionalProperties": true
+                            }
+                        },
+                        "429": {
+                            "description": "A sweep ran too recently",
+                            "schema": {
+                                "type": "object",
+                                "additionalProperties": true
+                            }
+                        },
+                        "500": {
+                            "description": "Internal server error",
+                            "schema": {
+                                "type": "object",
+                                "additionalProperties": true
+                            }
+                        }
+                    },
+                    "security": [
+                        {
+                            "BearerAuth": []
+                        }
+                    ]
+                }
+            },
+        "/health": {
+            "get": {
+                "description": "Get the health status of the service and its dependencies",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Get a list of all available workflows",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "Health status",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/identities": {
+            "get": {
+                "description": "Get a list of available identities from all identity providers",
                 "consumes": [
                     "application/json"
                 ],
@@ -1833,14 +1946,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "workflows"
+                    "identities"
+                ],
+                "summary": "List identities",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter query",
+                        "name": "q",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List workflows",
                 "responses": {
                     "200": {
-                        "description": "List of workflows",
+                        "description": "List of identities",
                         "schema": {
-                            "$ref": "#/definitions/models.WorkflowsResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "401": {
@@ -1849,1702 +1971,3026 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "config.ProviderPlugin": {
-            "type": "object"
-        },
-        "config.ProviderPluginConfig": {
-            "type": "object",
-            "properties": {
-                "definitions": {
-                    "description": "Load providers directly from config using mapstructure:\",remain\"",
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/config.ProviderPlugin"
-                    }
-                },
-                "path": {
-                    "type": "string"
                 },
-                "url": {
-                    "type": "string"
-                }
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "config.WorkflowPlugin": {
-            "type": "object"
-        },
-        "config.WorkflowPluginConfig": {
-            "type": "object",
-            "properties": {
-                "definitions": {
-                    "description": "Store everything in memory",
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/config.WorkflowPlugin"
-                    }
-                },
+        "/metrics": {
+            "get": {
+                "description": "Get Prometheus metrics for auth attempts, role grants, workflows, approvals and provider health. Use format=json for the service summary",
+                "produces": [
+                    "text/plain",
+                    "application/json"
+                ],
+                "tags": [
+                    "metrics"
+                ],
+                "summary": "Service metrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Set to json for the service summary",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Service metrics",
+                        "schema": {
+                            "$ref": "#/definitions/models.MetricsInfo"
+                        }
+                    }
+                }
+            }
+        },
+        "/openapi.json": {
+            "get": {
+                "description": "Get the OpenAPI 3 specification generated from the API handlers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discovery"
+                ],
+                "summary": "OpenAPI specification",
+                "responses": {
+                    "200": {
+                        "description": "OpenAPI 3 specification",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/provider/{provider}": {
+            "get": {
+                "description": "Retrieve detailed information about a specific provider including its capabilities and health",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "providers"
+                ],
+                "summary": "Get provider by name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Provider details",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProviderResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Provider not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/provider/{provider}/authorizeSession": {
+            "post": {
+                "description": "Authorize a session with a specific provider",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "providers"
+                ],
+                "summary": "Authorize provider session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Authorization request",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AuthorizeUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Authorization response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Provider not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/provider/{provider}/identities": {
+            "get": {
+                "description": "Get a list of identities available in a specific provider",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "providers"
+                ],
+                "summary": "List provider identities",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter query",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Provider identities",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProviderIdentitiesResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Provider not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/provider/{provider}/permissions": {
+            "get": {
+                "description": "Get a list of permissions available in a specific provider",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "providers"
+                ],
+                "summary": "List provider permissions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter query",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Provider permissions",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProviderPermissionsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Provider not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/provider/{provider}/resources": {
+            "get": {
+                "description": "Get a list of live resources a role can be scoped to in a specific provider",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "providers"
+                ],
+                "summary": "List provider resources",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter query",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Provider resources",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProviderResourcesResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Provider not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/provider/{provider}/roles": {
+            "get": {
+                "description": "Get a list of roles available in a specific provider",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "providers"
+                ],
+                "summary": "List provider roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter query",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Provider roles",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProviderRolesResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Provider not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/providers": {
+            "get": {
+                "description": "Get a list of all available providers with their capabilities and health, with optional capability filtering. Health checks are cached for 30 seconds",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "providers"
+                ],
+                "summary": "List providers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated list of capabilities to filter by",
+                        "name": "capability",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of providers",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProvidersResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/ready": {
+            "get": {
+                "description": "Check if the service is ready to accept requests. Providers whose agent credentials are missing permissions are reported as warning conditions without failing readiness",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness check",
+                "responses": {
+                    "200": {
+                        "description": "Ready status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Register a new agent with the server",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registration"
+                ],
+                "summary": "Register agent",
+                "parameters": [
+                    {
+                        "description": "Registration request",
+                        "name": "registration",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_thand-io_agent_internal_config.RegistrationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Registration successful",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_thand-io_agent_internal_config.RegistrationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/role/{role}": {
+            "get": {
+                "description": "Retrieve detailed information about a specific role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Get role by name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role name",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role details",
+                        "schema": {
+                            "$ref": "#/definitions/models.RoleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/roles": {
+            "get": {
+                "description": "Get a list of all available roles with optional provider and tag filtering",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "List roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated list of providers to filter by",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Tags the roles must have as key:value, repeat for more tags",
+                        "name": "tag",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of roles",
+                        "schema": {
+                            "$ref": "#/definitions/models.RolesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/roles/evaluate": {
+            "post": {
+                "description": "Evaluate a role against an identity to get the composite role with all inherited permissions resolved",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Evaluate composite role",
+                "parameters": [
+                    {
+                        "description": "Role evaluation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/daemon.EvaluateRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Evaluated composite role",
+                        "schema": {
+                            "$ref": "#/definitions/daemon.EvaluateRoleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Role or identity not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/session/{provider}": {
+            "get": {
+                "description": "Retrieve session information for a specific provider",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Get session by provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session information",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a session for a specific provider",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Delete session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session deleted successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "get": {
+                "description": "Retrieve all active sessions for the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Get all sessions",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only return sessions that have not expired",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated list of providers to filter by",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of sessions",
+                        "schema": {
+                            "$ref": "#/definitions/sessions.LoginServer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new session with the provided session token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Create a new session",
+                "parameters": [
+                    {
+                        "description": "Session creation request",
+                        "name": "session",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SessionCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session created successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/sync": {
+            "get": {
+                "description": "Get the current sync status and version information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sync"
+                ],
+                "summary": "Sync status",
+                "responses": {
+                    "200": {
+                        "description": "Sync status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/workflow/{name}": {
+            "get": {
+                "description": "Retrieve detailed information about a specific workflow",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workflows"
+                ],
+                "summary": "Get workflow by name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workflow name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Workflow details",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Workflow not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/workflows": {
+            "get": {
+                "description": "Get a list of all available workflows",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workflows"
+                ],
+                "summary": "List workflows",
+                "responses": {
+                    "200": {
+                        "description": "List of workflows",
+                        "schema": {
+                            "$ref": "#/definitions/models.WorkflowsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/workflows/{id}/audit": {
+            "get": {
+                "description": "List the calls the user made with the providers of an elevation during its access window e.g. from AWS CloudTrail. Providers without an audit trail are skipped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "executions"
+                ],
+                "summary": "Elevation audit trail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workflow execution ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Audit trail",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuditTrailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Workflow not found or access was never granted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "501": {
+                        "description": "No provider of the elevation has an audit trail",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        }
+    },
+    "definitions": {
+        "config.ProviderPlugin": {
+            "type": "object"
+        },
+        "config.ProviderPluginConfig": {
+            "type": "object",
+            "properties": {
+                "definitions": {
+                    "description": "Load providers directly from config using mapstructure:\",remain\"",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/config.ProviderPlugin"
+                    }
+                },
+                "path": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "config.WorkflowPlugin": {
+            "type": "object"
+        },
+        "config.WorkflowPluginConfig": {
+            "type": "object",
+            "properties": {
+                "definitions": {
+                    "description": "Store everything in memory",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/config.WorkflowPlugin"
+                    }
+                },
+                "path": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "daemon.EvaluateRoleRequest": {
+            "type": "object",
+            "required": [
+                "identity",
+                "role"
+            ],
+            "properties": {
+                "identity": {
+                    "description": "Identity ID to evaluate against",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role name to evaluate",
+                    "type": "string"
+                }
+            }
+        },
+        "daemon.EvaluateRoleResponse": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "description": "The evaluated composite role",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Role"
+                        }
+                    ]
+                }
+            }
+        },
+        "daemon.ExecutionStatePageData": {
+            "type": "object",
+            "properties": {
+                "execution": {
+                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.WorkflowExecutionInfo"
+                },
+                "workflow": {
+                    "$ref": "#/definitions/model.Workflow"
+                }
+            }
+        },
+        "daemon.ExecutionStatePageResponse": {
+            "type": "object",
+            "properties": {
+                "execution": {
+                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.WorkflowExecutionInfo"
+                },
+                "workflow": {
+                    "$ref": "#/definitions/model.Workflow"
+                }
+            }
+        },
+        "github_com_serverlessworkflow_sdk-go_v3_model.Duration": {
+            "type": "object"
+        },
+        "github_com_thand-io_agent_internal_config.Mode": {
+            "type": "string",
+            "enum": [
+                "server",
+                "agent",
+                "client"
+            ],
+            "x-enum-varnames": [
+                "ModeServer",
+                "ModeAgent",
+                "ModeClient"
+            ]
+        },
+        "github_com_thand-io_agent_internal_config.ProviderConfig": {
+            "type": "object",
+            "properties": {
+                "definitions": {
+                    "description": "Load providers directly from config using mapstructure:\",remain\"",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Provider"
+                    }
+                },
+                "path": {
+                    "type": "string"
+                },
+                "plugins": {
+                    "description": "Load dynamic provider configs",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/config.ProviderPluginConfig"
+                        }
+                    ]
+                },
+                "url": {
+                    "$ref": "#/definitions/model.Endpoint"
+                },
+                "vault": {
+                    "description": "vault secret / path to use",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_thand-io_agent_internal_config.RegistrationRequest": {
+            "type": "object",
+            "properties": {
+                "commit": {
+                    "type": "string"
+                },
+                "environment": {
+                    "$ref": "#/definitions/models.EnvironmentConfig"
+                },
+                "identifier": {
+                    "type": "string"
+                },
+                "mode": {
+                    "$ref": "#/definitions/github_com_thand-io_agent_internal_config.Mode"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_thand-io_agent_internal_config.RegistrationResponse": {
+            "type": "object",
+            "properties": {
+                "logging": {
+                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.LoggingConfig"
+                },
+                "providers": {
+                    "$ref": "#/definitions/github_com_thand-io_agent_internal_config.ProviderConfig"
+                },
+                "roles": {
+                    "$ref": "#/definitions/github_com_thand-io_agent_internal_config.RoleConfig"
+                },
+                "services": {
+                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.ServicesConfig"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "workflows": {
+                    "$ref": "#/definitions/github_com_thand-io_agent_internal_config.WorkflowConfig"
+                }
+            }
+        },
+        "github_com_thand-io_agent_internal_config.RoleConfig": {
+            "type": "object",
+            "properties": {
+                "definitions": {
+                    "description": "Store everything in memory",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Role"
+                    }
+                },
+                "path": {
+                    "type": "string"
+                },
+                "reason_policy": {
+                    "description": "Default policy for reasons, roles can override it",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ReasonPolicy"
+                        }
+                    ]
+                },
+                "url": {
+                    "$ref": "#/definitions/model.Endpoint"
+                },
+                "vault": {
+                    "description": "vault secret / path to use",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_thand-io_agent_internal_config.WorkflowConfig": {
+            "type": "object",
+            "properties": {
+                "definitions": {
+                    "description": "Store everything in memory",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Workflow"
+                    }
+                },
                 "path": {
                     "type": "string"
                 },
-                "url": {
+                "plugins": {
+                    "description": "Load dynamic plugin registry for custom call tools",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/config.WorkflowPluginConfig"
+                        }
+                    ]
+                },
+                "url": {
+                    "$ref": "#/definitions/model.Endpoint"
+                },
+                "vault": {
+                    "description": "vault secret / path to use",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_thand-io_agent_internal_models.BasicConfig": {
+            "type": "object",
+            "additionalProperties": {}
+        },
+        "github_com_thand-io_agent_internal_models.CredentialArtifact": {
+            "type": "object",
+            "properties": {
+                "encrypted": {
+                    "description": "ASCII armored age encryption of the credentials as JSON",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_thand-io_agent_internal_models.Group": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Email is the email address associated with the group (e.g., a mailing list).",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the unique identifier for the group.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name is the human-readable name of the group.",
                     "type": "string"
                 }
             }
         },
-        "daemon.EvaluateRoleRequest": {
+        "github_com_thand-io_agent_internal_models.Groups": {
             "type": "object",
-            "required": [
-                "identity",
-                "role"
+            "properties": {
+                "allow": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "deny": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_thand-io_agent_internal_models.Identity": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "description": "Group contains the group details if this identity represents a group.\nWill be nil if this identity represents a user.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Group"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "ID is the unique identifier for this identity. This will most likely be an email for users\nor a group name for groups. This will be used to tie identities across providers.",
+                    "type": "string"
+                },
+                "label": {
+                    "description": "Label is a human-readable name or description for this identity.",
+                    "type": "string"
+                },
+                "providers": {
+                    "description": "The providers this identity is associated with\nFormat is map[provider_name]provider_type",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "user": {
+                    "description": "User contains the user details if this identity represents a user.\nWill be nil if this identity represents a group.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.User"
+                        }
+                    ]
+                }
+            }
+        },
+        "github_com_thand-io_agent_internal_models.LocalSession": {
+            "type": "object",
+            "properties": {
+                "endpoint": {
+                    "description": "Optional endpoint associated with the session",
+                    "type": "string"
+                },
+                "expiry": {
+                    "description": "Expiry time of the session",
+                    "type": "string"
+                },
+                "session": {
+                    "description": "Encoded session token",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version of the session config",
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_thand-io_agent_internal_models.LoggingConfig": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "type": "string",
+                    "default": "text"
+                },
+                "level": {
+                    "type": "string",
+                    "default": "info"
+                },
+                "open_telemetry": {
+                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.OpenTelemetryConfig"
+                },
+                "output": {
+                    "type": "string"
+                },
+                "redact_emails": {
+                    "description": "Mask email addresses in log lines. Tokens and secrets are always masked",
+                    "type": "boolean",
+                    "default": true
+                }
+            }
+        },
+        "github_com_thand-io_agent_internal_models.NotificationDeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "delivered",
+                "failed",
+                "digested"
+            ],
+            "x-enum-comments": {
+                "NotificationDeliveryDigested": "Buffered into a digest, sent later"
+            },
+            "x-enum-descriptions": [
+                "",
+                "",
+                "Buffered into a digest, sent later"
             ],
+            "x-enum-varnames": [
+                "NotificationDeliveryDelivered",
+                "NotificationDeliveryFailed",
+                "NotificationDeliveryDigested"
+            ]
+        },
+        "github_com_thand-io_agent_internal_models.NotificationReceipt": {
+            "type": "object",
             "properties": {
-                "identity": {
-                    "description": "Identity ID to evaluate against",
+                "error": {
                     "type": "string"
                 },
-                "role": {
-                    "description": "Role name to evaluate",
+                "message_id": {
+                    "description": "Provider message identifier",
+                    "type": "string"
+                },
+                "notification_id": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "recipients": {
+                    "description": "One per recipient when each was sent their own message",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_thand-io_agent_internal_models.NotificationReceipt"
+                    }
+                },
+                "response": {
+                    "description": "Provider response e.g. the SMTP reply",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.NotificationDeliveryStatus"
+                },
+                "target": {
+                    "type": "string"
+                },
+                "timestamp": {
                     "type": "string"
                 }
             }
         },
-        "daemon.EvaluateRoleResponse": {
+        "github_com_thand-io_agent_internal_models.OpenTelemetryConfig": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "endpoint": {
+                    "description": "Endpoint specifies the OTLP endpoint for remote logging.\nThe structure of model.Endpoint may include fields such as URL, protocol, and authentication.\nExample YAML:\n  endpoint:\n    url: \"https://otel-collector.example.com:4317\"\n    protocol: \"grpc\"\n    auth:\n      type: \"basic\"\n      username: \"user\"\n      password: \"pass\"\nRefer to serverlessworkflow/sdk-go/model.Endpoint documentation for full details.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Endpoint"
+                        }
+                    ]
+                }
+            }
+        },
+        "github_com_thand-io_agent_internal_models.Permissions": {
+            "type": "object",
+            "properties": {
+                "allow": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "deny": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_thand-io_agent_internal_models.Provider": {
             "type": "object",
             "properties": {
+                "config": {
+                    "description": "Provider-specific configuration",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.BasicConfig"
+                        }
+                    ]
+                },
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "Whether this provider is enabled",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "provider": {
+                    "description": "e.g. aws, gcp, azure",
+                    "type": "string"
+                },
                 "role": {
-                    "description": "The evaluated composite role",
+                    "description": "The base role for this provider",
                     "allOf": [
                         {
                             "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Role"
                         }
                     ]
+                },
+                "version": {
+                    "$ref": "#/definitions/version.Version"
                 }
             }
         },
-        "daemon.ExecutionStatePageData": {
+        "github_com_thand-io_agent_internal_models.ProviderCapability": {
+            "type": "string",
+            "enum": [
+                "rbac",
+                "authorizor",
+                "notifier",
+                "identities",
+                "resources",
+                "initialize"
+            ],
+            "x-enum-comments": {
+                "ProviderCapabilityIdentities": "Provider can return users, groups, etc.",
+                "ProviderCapabilityResourceDiscovery": "Provider can list live resources e.g. zones, buckets, projects"
+            },
+            "x-enum-descriptions": [
+                "",
+                "",
+                "",
+                "Provider can return users, groups, etc.",
+                "Provider can list live resources e.g. zones, buckets, projects",
+                ""
+            ],
+            "x-enum-varnames": [
+                "ProviderCapabilityRBAC",
+                "ProviderCapabilityAuthorizer",
+                "ProviderCapabilityNotifier",
+                "ProviderCapabilityIdentities",
+                "ProviderCapabilityResourceDiscovery",
+                "ProviderConnectionInitialize"
+            ]
+        },
+        "github_com_thand-io_agent_internal_models.Resources": {
             "type": "object",
             "properties": {
-                "execution": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.WorkflowExecutionInfo"
+                "allow": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "workflow": {
-                    "$ref": "#/definitions/model.Workflow"
+                "deny": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "daemon.ExecutionsPageData": {
+        "github_com_thand-io_agent_internal_models.Role": {
             "type": "object",
             "properties": {
-                "config": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_config.Config"
+                "authenticators": {
+                    "description": "All the auth providers that the role can use. If empty then any provider can be used",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "environment": {
-                    "$ref": "#/definitions/models.EnvironmentConfig"
+                "default_effect": {
+                    "description": "DefaultEffect is the effect of actions not listed in the permissions.\nWith deny only actions in permissions.allow can be requested.",
+                    "type": "string"
                 },
-                "executions": {
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "By default enable the role",
+                    "type": "boolean",
+                    "default": true
+                },
+                "groups": {
+                    "description": "groups to add the user to",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Groups"
+                        }
+                    ]
+                },
+                "inherits": {
+                    "description": "roles to inherit from or provider specific roles/policies etc",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/github_com_thand-io_agent_internal_models.WorkflowExecutionInfo"
+                        "type": "string"
                     }
                 },
-                "provider": {
-                    "type": "string"
-                },
-                "serviceName": {
-                    "type": "string"
-                },
-                "status": {
+                "max_duration": {
+                    "description": "MaxDuration limits how long the role can be requested for e.g. 4h",
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.User"
-                },
-                "version": {
+                "name": {
                     "type": "string"
-                }
-            }
-        },
-        "github_com_thand-io_agent_internal_config.Config": {
-            "type": "object",
-            "properties": {
-                "api": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.APIConfig"
                 },
-                "environment": {
-                    "description": "Environment configuration and core services",
+                "permissions": {
+                    "description": "granular permissions for the role",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/models.EnvironmentConfig"
+                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Permissions"
                         }
                     ]
                 },
-                "logging": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.LoggingConfig"
+                "providers": {
+                    "description": "providers that can assign this role",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "login": {
-                    "description": "System configuration",
+                "reason_policy": {
+                    "description": "ReasonPolicy overrides the default rules for the reason given when\nrequesting the role",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.LoginConfig"
+                            "$ref": "#/definitions/models.ReasonPolicy"
                         }
                     ]
                 },
-                "providers": {
-                    "description": "These are integration providers like AWS, GCP, etc.",
+                "requires_approval": {
+                    "description": "RequiresApproval sends every request for the role through approval,\neven when the policies allow it",
+                    "type": "boolean"
+                },
+                "resources": {
+                    "description": "resource access rules, apis, files, systems etc",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/github_com_thand-io_agent_internal_config.ProviderConfig"
+                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Resources"
                         }
                     ]
                 },
-                "roles": {
-                    "description": "Workflow engine config",
+                "scopes": {
+                    "description": "scope of who can be assigned this role",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/github_com_thand-io_agent_internal_config.RoleConfig"
+                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.RoleScopes"
                         }
                     ]
                 },
-                "secret": {
-                    "description": "Secret used for signing cookies and tokens",
-                    "type": "string"
+                "tags": {
+                    "description": "Tags are free form metadata e.g. team, environment or risk level\nused to filter and display roles",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "vars": {
+                    "description": "Vars are merged into the workflow context, see VarsContextVars.\nThey override provider and global vars of the same name.",
+                    "type": "object",
+                    "additionalProperties": true
                 },
-                "server": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.ServerConfig"
+                "version": {
+                    "$ref": "#/definitions/version.Version"
                 },
-                "services": {
-                    "description": "External services / non-core services",
+                "workflow_template": {
+                    "description": "WorkflowTemplate expands a built in template into the role's first\nworkflow, see WorkflowTemplates",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.ServicesConfig"
+                            "$ref": "#/definitions/models.WorkflowTemplate"
                         }
                     ]
                 },
                 "workflows": {
-                    "description": "These are workflows to run for role associated workflows",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/github_com_thand-io_agent_internal_config.WorkflowConfig"
-                        }
-                    ]
+                    "description": "The workflows to execute",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "github_com_thand-io_agent_internal_config.Mode": {
-            "type": "string",
-            "enum": [
-                "server",
-                "agent",
-                "client"
-            ],
-            "x-enum-varnames": [
-                "ModeServer",
-                "ModeAgent",
-                "ModeClient"
-            ]
-        },
-        "github_com_thand-io_agent_internal_config.ProviderConfig": {
+        "github_com_thand-io_agent_internal_models.RoleScopes": {
             "type": "object",
             "properties": {
-                "definitions": {
-                    "description": "Load providers directly from config using mapstructure:\",remain\"",
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Provider"
+                "domains": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
                 },
-                "path": {
-                    "type": "string"
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "plugins": {
-                    "description": "Load dynamic provider configs",
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_thand-io_agent_internal_models.ServicesConfig": {
+            "type": "object",
+            "properties": {
+                "encryption": {
+                    "description": "Encryption - used for encrypting sensitive data",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/config.ProviderPluginConfig"
+                            "$ref": "#/definitions/models.ServiceConfig"
                         }
                     ]
                 },
-                "url": {
-                    "$ref": "#/definitions/model.Endpoint"
+                "largeLanguageModel": {
+                    "description": "LLM - used for large language model interactions",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.LargeLanguageModelConfig"
+                        }
+                    ]
+                },
+                "scheduler": {
+                    "description": "Scheduler - used for scheduling tasks",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ServiceConfig"
+                        }
+                    ]
+                },
+                "temporal": {
+                    "description": "Temporal - used for workflow processing and orchestration",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TemporalConfig"
+                        }
+                    ]
                 },
                 "vault": {
-                    "description": "vault secret / path to use",
-                    "type": "string"
+                    "description": "Vault - used for storing sensitive data",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ServiceConfig"
+                        }
+                    ]
                 }
             }
         },
-        "github_com_thand-io_agent_internal_config.RegistrationRequest": {
+        "github_com_thand-io_agent_internal_models.User": {
             "type": "object",
             "properties": {
-                "commit": {
+                "email": {
+                    "description": "Email is the user's email address, often used as a primary identifier.",
                     "type": "string"
                 },
-                "environment": {
-                    "$ref": "#/definitions/models.EnvironmentConfig"
+                "groups": {
+                    "description": "Groups is a list of group names or IDs that this user belongs to.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "identifier": {
+                "id": {
+                    "description": "ID is the unique identifier for the user.",
                     "type": "string"
                 },
-                "mode": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_config.Mode"
-                },
-                "version": {
+                "locale": {
+                    "description": "Locale is the user's preferred language e.g. en or de-AT, if known.",
                     "type": "string"
-                }
-            }
-        },
-        "github_com_thand-io_agent_internal_config.RegistrationResponse": {
-            "type": "object",
-            "properties": {
-                "logging": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.LoggingConfig"
-                },
-                "providers": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_config.ProviderConfig"
-                },
-                "roles": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_config.RoleConfig"
-                },
-                "services": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.ServicesConfig"
-                },
-                "success": {
-                    "type": "boolean"
-                },
-                "workflows": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_config.WorkflowConfig"
-                }
-            }
-        },
-        "github_com_thand-io_agent_internal_config.RoleConfig": {
-            "type": "object",
-            "properties": {
-                "definitions": {
-                    "description": "Store everything in memory",
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Role"
-                    }
                 },
-                "path": {
+                "name": {
+                    "description": "Name is the user's full display name.",
                     "type": "string"
                 },
-                "url": {
-                    "$ref": "#/definitions/model.Endpoint"
+                "source": {
+                    "description": "Source identifies the identity provider or system where this user originated.",
+                    "type": "string"
                 },
-                "vault": {
-                    "description": "vault secret / path to use",
+                "username": {
+                    "description": "Username is the user's login name or handle.",
                     "type": "string"
+                },
+                "verified": {
+                    "description": "Verified indicates whether the user's identity has been verified.",
+                    "type": "boolean"
                 }
             }
         },
-        "github_com_thand-io_agent_internal_config.WorkflowConfig": {
+        "github_com_thand-io_agent_internal_models.Workflow": {
             "type": "object",
             "properties": {
-                "definitions": {
-                    "description": "Store everything in memory",
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Workflow"
-                    }
-                },
-                "path": {
+                "description": {
                     "type": "string"
                 },
-                "plugins": {
-                    "description": "Load dynamic plugin registry for custom call tools",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/config.WorkflowPluginConfig"
-                        }
-                    ]
-                },
-                "url": {
-                    "$ref": "#/definitions/model.Endpoint"
+                "enabled": {
+                    "description": "By default enable the workflow",
+                    "type": "boolean",
+                    "default": true
                 },
-                "vault": {
-                    "description": "vault secret / path to use",
+                "name": {
                     "type": "string"
-                }
-            }
-        },
-        "github_com_thand-io_agent_internal_models.APIConfig": {
-            "type": "object",
-            "properties": {
-                "rate_limit": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.RateLimitConfig"
                 },
                 "version": {
-                    "type": "string",
-                    "default": "v1"
+                    "$ref": "#/definitions/version.Version"
+                },
+                "workflow": {
+                    "$ref": "#/definitions/model.Workflow"
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.BasicConfig": {
-            "type": "object",
-            "additionalProperties": {}
-        },
-        "github_com_thand-io_agent_internal_models.CORSConfig": {
+        "github_com_thand-io_agent_internal_models.WorkflowExecutionInfo": {
             "type": "object",
             "properties": {
-                "allow_credentials": {
+                "approved": {
+                    "description": "nil = pending approval, true = approved, false = denied",
                     "type": "boolean"
                 },
-                "allowed_headers": {
+                "context": {},
+                "credentials": {
+                    "description": "Credentials minted for the requester, encrypted for the public key\nsent with the request",
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/github_com_thand-io_agent_internal_models.CredentialArtifact"
                     }
                 },
-                "allowed_methods": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "duration": {
+                    "description": "Duration in seconds",
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
                 },
-                "allowed_origins": {
+                "history": {
+                    "description": "History of state transitions",
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "expose_headers": {
+                "id": {
+                    "type": "string"
+                },
+                "identities": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Identity"
                     }
                 },
-                "max_age": {
-                    "type": "integer"
-                }
-            }
-        },
-        "github_com_thand-io_agent_internal_models.Group": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "description": "Email is the email address associated with the group (e.g., a mailing list).",
-                    "type": "string"
-                },
-                "id": {
-                    "description": "ID is the unique identifier for the group.",
-                    "type": "string"
+                "input": {
+                    "description": "Context"
                 },
                 "name": {
-                    "description": "Name is the human-readable name of the group.",
+                    "description": "SearchAttributes are the custom search attributes associated with the workflow",
                     "type": "string"
-                }
-            }
-        },
-        "github_com_thand-io_agent_internal_models.Groups": {
-            "type": "object",
-            "properties": {
-                "allow": {
+                },
+                "notifications": {
+                    "description": "Notifications are the delivery receipts of the notifications sent",
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/github_com_thand-io_agent_internal_models.NotificationReceipt"
                     }
                 },
-                "deny": {
+                "output": {},
+                "providers": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
-                }
-            }
-        },
-        "github_com_thand-io_agent_internal_models.HealthConfig": {
-            "type": "object",
-            "properties": {
-                "enabled": {
-                    "type": "boolean",
-                    "default": true
                 },
-                "path": {
-                    "description": "Don't use /healthz as it conflicts with google k8s health checks",
-                    "type": "string",
-                    "default": "/health"
-                }
-            }
-        },
-        "github_com_thand-io_agent_internal_models.Identity": {
-            "type": "object",
-            "properties": {
-                "group": {
-                    "description": "Group contains the group details if this identity represents a group.\nWill be nil if this identity represents a user.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Group"
-                        }
-                    ]
+                "reason": {
+                    "type": "string"
                 },
-                "id": {
-                    "description": "ID is the unique identifier for this identity. This will most likely be an email for users\nor a group name for groups. This will be used to tie identities across providers.",
+                "role": {
                     "type": "string"
                 },
-                "label": {
-                    "description": "Label is a human-readable name or description for this identity.",
+                "run": {
                     "type": "string"
                 },
-                "providers": {
-                    "description": "The providers this identity is associated with\nFormat is map[provider_name]provider_type",
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "task": {
+                    "type": "string"
                 },
                 "user": {
-                    "description": "User contains the user details if this identity represents a user.\nWill be nil if this identity represents a group.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.User"
-                        }
-                    ]
+                    "type": "string"
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.LocalSession": {
+        "model.AuthenticationPolicy": {
             "type": "object",
             "properties": {
-                "endpoint": {
-                    "description": "Optional endpoint associated with the session",
-                    "type": "string"
+                "basic": {
+                    "$ref": "#/definitions/model.BasicAuthenticationPolicy"
                 },
-                "expiry": {
-                    "description": "Expiry time of the session",
-                    "type": "string"
+                "bearer": {
+                    "$ref": "#/definitions/model.BearerAuthenticationPolicy"
                 },
-                "session": {
-                    "description": "Encoded session token",
-                    "type": "string"
+                "digest": {
+                    "$ref": "#/definitions/model.DigestAuthenticationPolicy"
                 },
-                "version": {
-                    "description": "Version of the session config",
-                    "type": "integer"
+                "oauth2": {
+                    "$ref": "#/definitions/model.OAuth2AuthenticationPolicy"
+                },
+                "oidc": {
+                    "$ref": "#/definitions/model.OpenIdConnectAuthenticationPolicy"
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.LoggingConfig": {
+        "model.BackoffDefinition": {
             "type": "object",
             "properties": {
-                "format": {
-                    "type": "string",
-                    "default": "text"
-                },
-                "level": {
-                    "type": "string",
-                    "default": "info"
-                },
-                "open_telemetry": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.OpenTelemetryConfig"
-                },
-                "output": {
-                    "type": "string"
+                "definition": {
+                    "type": "object",
+                    "additionalProperties": true
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.LoginConfig": {
+        "model.BasicAuthenticationPolicy": {
             "type": "object",
             "properties": {
-                "api_key": {
-                    "description": "API key for authenticating with the login server",
+                "password": {
                     "type": "string"
                 },
-                "base": {
-                    "description": "Base path for login endpoint e.g. /",
-                    "type": "string",
-                    "default": "/"
+                "use": {
+                    "type": "string"
                 },
-                "endpoint": {
-                    "type": "string",
-                    "default": "https://auth.thand.io/"
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.MetricsConfig": {
+        "model.BearerAuthenticationPolicy": {
             "type": "object",
             "properties": {
-                "enabled": {
-                    "type": "boolean",
-                    "default": true
-                },
-                "namespace": {
+                "token": {
                     "type": "string"
                 },
-                "path": {
-                    "type": "string",
-                    "default": "/metrics"
+                "use": {
+                    "type": "string"
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.OpenTelemetryConfig": {
+        "model.Catalog": {
             "type": "object",
+            "required": [
+                "endpoint"
+            ],
             "properties": {
-                "enabled": {
-                    "type": "boolean",
-                    "default": false
-                },
                 "endpoint": {
-                    "description": "Endpoint specifies the OTLP endpoint for remote logging.\nThe structure of model.Endpoint may include fields such as URL, protocol, and authentication.\nExample YAML:\n  endpoint:\n    url: \"https://otel-collector.example.com:4317\"\n    protocol: \"grpc\"\n    auth:\n      type: \"basic\"\n      username: \"user\"\n      password: \"pass\"\nRefer to serverlessworkflow/sdk-go/model.Endpoint documentation for full details.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.Endpoint"
-                        }
-                    ]
+                    "$ref": "#/definitions/model.Endpoint"
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.Permissions": {
+        "model.Correlation": {
             "type": "object",
+            "required": [
+                "from"
+            ],
             "properties": {
-                "allow": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "expect": {
+                    "description": "Expected value or runtime expression",
+                    "type": "string"
                 },
-                "deny": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "from": {
+                    "description": "Runtime expression to extract the correlation value",
+                    "type": "string"
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.Provider": {
+        "model.DigestAuthenticationPolicy": {
             "type": "object",
             "properties": {
-                "config": {
-                    "description": "Provider-specific configuration",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.BasicConfig"
-                        }
-                    ]
-                },
-                "description": {
+                "password": {
                     "type": "string"
                 },
-                "enabled": {
-                    "description": "Whether this provider is enabled",
-                    "type": "boolean"
-                },
-                "name": {
+                "use": {
                     "type": "string"
                 },
-                "provider": {
-                    "description": "e.g. aws, gcp, azure",
+                "username": {
                     "type": "string"
-                },
-                "role": {
-                    "description": "The base role for this provider",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Role"
-                        }
-                    ]
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.RateLimitConfig": {
+        "model.Document": {
             "type": "object",
+            "required": [
+                "dsl",
+                "name",
+                "namespace",
+                "version"
+            ],
             "properties": {
-                "burst": {
-                    "type": "integer"
+                "dsl": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 },
-                "requests_per_minute": {
-                    "type": "integer"
-                }
-            }
-        },
-        "github_com_thand-io_agent_internal_models.ReadyConfig": {
-            "type": "object",
-            "properties": {
-                "enabled": {
-                    "type": "boolean",
-                    "default": true
+                "title": {
+                    "type": "string"
                 },
-                "path": {
-                    "type": "string",
-                    "default": "/ready"
+                "version": {
+                    "type": "string"
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.Role": {
+        "model.Endpoint": {
+            "type": "object"
+        },
+        "model.Error": {
             "type": "object",
+            "required": [
+                "status",
+                "type"
+            ],
             "properties": {
-                "authenticators": {
-                    "description": "All the auth providers that the role can use. If empty then any provider can be used",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "description": {
-                    "type": "string"
-                },
-                "enabled": {
-                    "description": "By default enable the role",
-                    "type": "boolean",
-                    "default": true
-                },
-                "groups": {
-                    "description": "groups to add the user to",
+                "detail": {
+                    "description": "A human-readable explanation specific to this occurrence of the error.",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Groups"
+                            "$ref": "#/definitions/model.StringOrRuntimeExpr"
                         }
                     ]
                 },
-                "inherits": {
-                    "description": "roles to inherit from or provider specific roles/policies etc",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "name": {
-                    "type": "string"
-                },
-                "permissions": {
-                    "description": "granular permissions for the role",
+                "instance": {
+                    "description": "A JSON Pointer used to reference the component the error originates from.\nRuntimes MUST set the property when raising or escalating the error. Otherwise ignore.",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Permissions"
+                            "$ref": "#/definitions/model.JsonPointerOrRuntimeExpression"
                         }
                     ]
                 },
-                "providers": {
-                    "description": "providers that can assign this role",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "status": {
+                    "description": "The status code generated by the origin for this occurrence of the error.\nFor cross-compatibility concerns, it is strongly recommended to use HTTP Status Codes whenever possible.\nRuntimes MUST ensure that the property has been set when raising or escalating the error.",
+                    "type": "integer"
                 },
-                "resources": {
-                    "description": "resource access rules, apis, files, systems etc",
+                "title": {
+                    "description": "A short, human-readable summary of the error.",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/models.Resources"
+                            "$ref": "#/definitions/model.StringOrRuntimeExpr"
                         }
                     ]
                 },
-                "scopes": {
-                    "description": "scope of who can be assigned this role",
+                "type": {
+                    "description": "A URI reference that identifies the error type.\nFor cross-compatibility concerns, it is strongly recommended to use Standard Error Types whenever possible.\nRuntimes MUST ensure that the property has been set when raising or escalating the error.",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.RoleScopes"
+                            "$ref": "#/definitions/model.URITemplateOrRuntimeExpr"
                         }
                     ]
-                },
-                "workflows": {
-                    "description": "The workflows to execute",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.RoleScopes": {
+        "model.EventConsumptionStrategy": {
             "type": "object",
             "properties": {
-                "domains": {
+                "all": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/model.EventFilter"
                     }
                 },
-                "groups": {
+                "any": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/model.EventFilter"
                     }
                 },
-                "users": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "one": {
+                    "$ref": "#/definitions/model.EventFilter"
+                },
+                "until": {
+                    "$ref": "#/definitions/model.EventConsumptionUntil"
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.SecurityConfig": {
-            "type": "object",
-            "properties": {
-                "cors": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.CORSConfig"
-                }
-            }
+        "model.EventConsumptionUntil": {
+            "type": "object"
         },
-        "github_com_thand-io_agent_internal_models.ServerConfig": {
+        "model.EventFilter": {
             "type": "object",
+            "required": [
+                "with"
+            ],
             "properties": {
-                "health": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.HealthConfig"
-                },
-                "host": {
-                    "type": "string"
-                },
-                "limits": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.ServerLimitsConfig"
-                },
-                "metrics": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.MetricsConfig"
-                },
-                "port": {
-                    "type": "integer"
-                },
-                "ready": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.ReadyConfig"
+                "correlate": {
+                    "description": "Keyed correlation filters",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.Correlation"
+                    }
                 },
-                "security": {
-                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.SecurityConfig"
+                "with": {
+                    "$ref": "#/definitions/model.EventProperties"
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.ServerLimitsConfig": {
+        "model.EventProperties": {
             "type": "object",
             "properties": {
-                "burst": {
-                    "type": "integer"
-                },
-                "idle_timeout": {
-                    "$ref": "#/definitions/time.Duration"
-                },
-                "read_timeout": {
-                    "$ref": "#/definitions/time.Duration"
-                },
-                "requests_per_minute": {
-                    "type": "integer"
+                "datacontenttype": {
+                    "type": "string"
                 },
-                "write_timeout": {
-                    "$ref": "#/definitions/time.Duration"
-                }
-            }
-        },
-        "github_com_thand-io_agent_internal_models.ServicesConfig": {
-            "type": "object",
-            "properties": {
-                "encryption": {
-                    "description": "Encryption - used for encrypting sensitive data",
+                "dataschema": {
+                    "description": "URI template or runtime expression",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/models.ServiceConfig"
+                            "$ref": "#/definitions/model.URITemplateOrRuntimeExpr"
                         }
                     ]
                 },
-                "largeLanguageModel": {
-                    "description": "LLM - used for large language model interactions",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.LargeLanguageModelConfig"
-                        }
-                    ]
+                "id": {
+                    "type": "string"
                 },
-                "scheduler": {
-                    "description": "Scheduler - used for scheduling tasks",
+                "source": {
+                    "description": "URI template or runtime expression",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/models.ServiceConfig"
+                            "$ref": "#/definitions/model.URITemplateOrRuntimeExpr"
                         }
                     ]
                 },
-                "temporal": {
-                    "description": "Temporal - used for workflow processing and orchestration",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.TemporalConfig"
-                        }
-                    ]
+                "subject": {
+                    "type": "string"
                 },
-                "vault": {
-                    "description": "Vault - used for storing sensitive data",
+                "time": {
+                    "description": "ISO 8601 date-time string or runtime expression",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/models.ServiceConfig"
+                            "$ref": "#/definitions/model.StringOrRuntimeExpr"
                         }
                     ]
+                },
+                "type": {
+                    "type": "string"
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.User": {
+        "model.ExtensionItem": {
+            "type": "object"
+        },
+        "model.ExternalResource": {
             "type": "object",
+            "required": [
+                "endpoint"
+            ],
             "properties": {
-                "email": {
-                    "description": "Email is the user's email address, often used as a primary identifier.",
-                    "type": "string"
-                },
-                "groups": {
-                    "description": "Groups is a list of group names or IDs that this user belongs to.",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "id": {
-                    "description": "ID is the unique identifier for the user.",
-                    "type": "string"
+                "endpoint": {
+                    "$ref": "#/definitions/model.Endpoint"
                 },
                 "name": {
-                    "description": "Name is the user's full display name.",
                     "type": "string"
+                }
+            }
+        },
+        "model.Input": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "$ref": "#/definitions/model.ObjectOrRuntimeExpr"
                 },
-                "source": {
-                    "description": "Source identifies the identity provider or system where this user originated.",
+                "schema": {
+                    "$ref": "#/definitions/model.Schema"
+                }
+            }
+        },
+        "model.JsonPointerOrRuntimeExpression": {
+            "type": "object"
+        },
+        "model.NamedTaskMap": {
+            "type": "object",
+            "additionalProperties": {}
+        },
+        "model.OAuth2AutenthicationDataClient": {
+            "type": "object",
+            "properties": {
+                "assertion": {
                     "type": "string"
                 },
-                "username": {
-                    "description": "Username is the user's login name or handle.",
+                "authentication": {
+                    "$ref": "#/definitions/model.OAuthClientAuthenticationType"
+                },
+                "id": {
                     "type": "string"
                 },
-                "verified": {
-                    "description": "Verified indicates whether the user's identity has been verified.",
-                    "type": "boolean"
+                "secret": {
+                    "type": "string"
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.Workflow": {
+        "model.OAuth2AuthenticationDataGrant": {
+            "type": "string",
+            "enum": [
+                "authorization_code",
+                "client_credentials",
+                "password",
+                "refresh_token",
+                "urn:ietf:params:oauth:grant-type:token-exchange"
+            ],
+            "x-enum-comments": {
+                "TokenExchangeGrant": "#nosec G101"
+            },
+            "x-enum-descriptions": [
+                "",
+                "",
+                "",
+                "",
+                "#nosec G101"
+            ],
+            "x-enum-varnames": [
+                "AuthorizationCodeGrant",
+                "ClientCredentialsGrant",
+                "PasswordGrant",
+                "RefreshTokenGrant",
+                "TokenExchangeGrant"
+            ]
+        },
+        "model.OAuth2AuthenticationPolicy": {
             "type": "object",
             "properties": {
-                "description": {
-                    "type": "string"
+                "endpoints": {
+                    "$ref": "#/definitions/model.OAuth2Endpoints"
                 },
-                "enabled": {
-                    "description": "By default enable the workflow",
-                    "type": "boolean",
-                    "default": true
+                "properties": {
+                    "$ref": "#/definitions/model.OAuth2AuthenticationProperties"
                 },
-                "name": {
+                "use": {
                     "type": "string"
-                },
-                "workflow": {
-                    "$ref": "#/definitions/model.Workflow"
                 }
             }
         },
-        "github_com_thand-io_agent_internal_models.WorkflowExecutionInfo": {
+        "model.OAuth2AuthenticationProperties": {
             "type": "object",
             "properties": {
-                "approved": {
-                    "description": "nil = pending approval, true = approved, false = denied",
-                    "type": "boolean"
-                },
-                "context": {},
-                "duration": {
-                    "description": "Duration in seconds",
-                    "type": "integer"
-                },
-                "finished_at": {
-                    "type": "string"
+                "actor": {
+                    "$ref": "#/definitions/model.OAuth2Token"
                 },
-                "history": {
-                    "description": "History of state transitions",
+                "audiences": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "id": {
-                    "type": "string"
+                "authority": {},
+                "client": {
+                    "$ref": "#/definitions/model.OAuth2AutenthicationDataClient"
                 },
-                "identities": {
+                "grant": {
+                    "enum": [
+                        "authorization_code",
+                        "client_credentials",
+                        "password",
+                        "refresh_token",
+                        "urn:ietf:params:oauth:grant-type:token-exchange"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.OAuth2AuthenticationDataGrant"
+                        }
+                    ]
+                },
+                "issuers": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "input": {
-                    "description": "Context"
-                },
-                "name": {
-                    "description": "SearchAttributes are the custom search attributes associated with the workflow",
+                "password": {
                     "type": "string"
                 },
-                "output": {},
-                "providers": {
+                "request": {
+                    "$ref": "#/definitions/model.OAuth2TokenRequest"
+                },
+                "scopes": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "reason": {
-                    "type": "string"
-                },
-                "role": {
-                    "type": "string"
-                },
-                "run": {
-                    "type": "string"
-                },
-                "started_at": {
-                    "type": "string"
-                },
-                "status": {
-                    "type": "string"
-                },
-                "task": {
-                    "type": "string"
+                "subject": {
+                    "$ref": "#/definitions/model.OAuth2Token"
                 },
-                "user": {
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "model.AuthenticationPolicy": {
-            "type": "object",
-            "properties": {
-                "basic": {
-                    "$ref": "#/definitions/model.BasicAuthenticationPolicy"
-                },
-                "bearer": {
-                    "$ref": "#/definitions/model.BearerAuthenticationPolicy"
-                },
-                "digest": {
-                    "$ref": "#/definitions/model.DigestAuthenticationPolicy"
-                },
-                "oauth2": {
-                    "$ref": "#/definitions/model.OAuth2AuthenticationPolicy"
-                },
-                "oidc": {
-                    "$ref": "#/definitions/model.OpenIdConnectAuthenticationPolicy"
-                }
-            }
-        },
-        "model.BackoffDefinition": {
-            "type": "object",
-            "properties": {
-                "definition": {
-                    "type": "object",
-                    "additionalProperties": true
-                }
-            }
-        },
-        "model.BasicAuthenticationPolicy": {
+        "model.OAuth2Endpoints": {
             "type": "object",
             "properties": {
-                "password": {
+                "introspection": {
                     "type": "string"
                 },
-                "use": {
+                "revocation": {
                     "type": "string"
                 },
-                "username": {
+                "token": {
                     "type": "string"
                 }
             }
         },
-        "model.BearerAuthenticationPolicy": {
+        "model.OAuth2Token": {
             "type": "object",
             "properties": {
                 "token": {
+                    "description": "Token The security token to use",
                     "type": "string"
                 },
-                "use": {
+                "type": {
+                    "description": "Type The type of the security token to use.",
                     "type": "string"
                 }
             }
         },
-        "model.Catalog": {
+        "model.OAuth2TokenRequest": {
             "type": "object",
-            "required": [
-                "endpoint"
-            ],
             "properties": {
-                "endpoint": {
-                    "$ref": "#/definitions/model.Endpoint"
+                "encoding": {
+                    "$ref": "#/definitions/model.OAuth2TokenRequestEncodingType"
                 }
             }
         },
-        "model.Correlation": {
-            "type": "object",
-            "required": [
-                "from"
+        "model.OAuth2TokenRequestEncodingType": {
+            "type": "string",
+            "enum": [
+                "application/x-www-form-urlencoded",
+                "application/json"
             ],
-            "properties": {
-                "expect": {
-                    "description": "Expected value or runtime expression",
-                    "type": "string"
-                },
-                "from": {
-                    "description": "Runtime expression to extract the correlation value",
-                    "type": "string"
-                }
-            }
+            "x-enum-varnames": [
+                "EncodingTypeFormUrlEncoded",
+                "EncodingTypeApplicationJson"
+            ]
         },
-        "model.DigestAuthenticationPolicy": {
+        "model.OAuthClientAuthenticationType": {
+            "type": "string",
+            "enum": [
+                "client_secret_basic",
+                "client_secret_post",
+                "client_secret_jwt",
+                "private_key_jwt",
+                "none"
+            ],
+            "x-enum-varnames": [
+                "OAuthClientAuthClientSecretBasic",
+                "OAuthClientAuthClientSecretPost",
+                "OAuthClientAuthClientSecretJWT",
+                "OAuthClientAuthPrivateKeyJWT",
+                "OAuthClientAuthNone"
+            ]
+        },
+        "model.ObjectOrRuntimeExpr": {
+            "type": "object"
+        },
+        "model.OpenIdConnectAuthenticationPolicy": {
             "type": "object",
             "properties": {
-                "password": {
-                    "type": "string"
+                "properties": {
+                    "$ref": "#/definitions/model.OAuth2AuthenticationProperties"
                 },
                 "use": {
                     "type": "string"
-                },
-                "username": {
-                    "type": "string"
                 }
             }
         },
-        "model.Document": {
+        "model.Output": {
             "type": "object",
-            "required": [
-                "dsl",
-                "name",
-                "namespace",
-                "version"
-            ],
             "properties": {
-                "dsl": {
-                    "type": "string"
-                },
-                "metadata": {
-                    "type": "object",
-                    "additionalProperties": true
-                },
-                "name": {
-                    "type": "string"
-                },
-                "namespace": {
-                    "type": "string"
-                },
-                "summary": {
-                    "type": "string"
-                },
-                "tags": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
-                },
-                "title": {
-                    "type": "string"
+                "as": {
+                    "$ref": "#/definitions/model.ObjectOrRuntimeExpr"
                 },
-                "version": {
-                    "type": "string"
+                "schema": {
+                    "$ref": "#/definitions/model.Schema"
                 }
             }
         },
-        "model.Duration": {
-            "type": "object"
-        },
-        "model.Endpoint": {
-            "type": "object"
-        },
-        "model.Error": {
+        "model.RetryBackoff": {
             "type": "object",
-            "required": [
-                "status",
-                "type"
-            ],
             "properties": {
-                "detail": {
-                    "description": "A human-readable explanation specific to this occurrence of the error.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.StringOrRuntimeExpr"
-                        }
-                    ]
-                },
-                "instance": {
-                    "description": "A JSON Pointer used to reference the component the error originates from.\nRuntimes MUST set the property when raising or escalating the error. Otherwise ignore.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.JsonPointerOrRuntimeExpression"
-                        }
-                    ]
-                },
-                "status": {
-                    "description": "The status code generated by the origin for this occurrence of the error.\nFor cross-compatibility concerns, it is strongly recommended to use HTTP Status Codes whenever possible.\nRuntimes MUST ensure that the property has been set when raising or escalating the error.",
-                    "type": "integer"
+                "constant": {
+                    "$ref": "#/definitions/model.BackoffDefinition"
                 },
-                "title": {
-                    "description": "A short, human-readable summary of the error.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.StringOrRuntimeExpr"
-                        }
-                    ]
+                "exponential": {
+                    "$ref": "#/definitions/model.BackoffDefinition"
                 },
-                "type": {
-                    "description": "A URI reference that identifies the error type.\nFor cross-compatibility concerns, it is strongly recommended to use Standard Error Types whenever possible.\nRuntimes MUST ensure that the property has been set when raising or escalating the error.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.URITemplateOrRuntimeExpr"
-                        }
-                    ]
+                "linear": {
+                    "$ref": "#/definitions/model.BackoffDefinition"
                 }
             }
         },
-        "model.EventConsumptionStrategy": {
+        "model.RetryLimit": {
             "type": "object",
             "properties": {
-                "all": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.EventFilter"
-                    }
-                },
-                "any": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.EventFilter"
-                    }
-                },
-                "one": {
-                    "$ref": "#/definitions/model.EventFilter"
+                "attempt": {
+                    "$ref": "#/definitions/model.RetryLimitAttempt"
                 },
-                "until": {
-                    "$ref": "#/definitions/model.EventConsumptionUntil"
+                "duration": {
+                    "$ref": "#/definitions/github_com_serverlessworkflow_sdk-go_v3_model.Duration"
                 }
             }
         },
-        "model.EventConsumptionUntil": {
-            "type": "object"
-        },
-        "model.EventFilter": {
+        "model.RetryLimitAttempt": {
             "type": "object",
-            "required": [
-                "with"
-            ],
             "properties": {
-                "correlate": {
-                    "description": "Keyed correlation filters",
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.Correlation"
-                    }
+                "count": {
+                    "type": "integer"
                 },
-                "with": {
-                    "$ref": "#/definitions/model.EventProperties"
+                "duration": {
+                    "$ref": "#/definitions/github_com_serverlessworkflow_sdk-go_v3_model.Duration"
                 }
             }
         },
-        "model.EventProperties": {
+        "model.RetryPolicy": {
             "type": "object",
             "properties": {
-                "datacontenttype": {
-                    "type": "string"
+                "backoff": {
+                    "$ref": "#/definitions/model.RetryBackoff"
                 },
-                "dataschema": {
-                    "description": "URI template or runtime expression",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.URITemplateOrRuntimeExpr"
-                        }
-                    ]
+                "delay": {
+                    "$ref": "#/definitions/github_com_serverlessworkflow_sdk-go_v3_model.Duration"
                 },
-                "id": {
-                    "type": "string"
+                "exceptWhen": {
+                    "$ref": "#/definitions/model.RuntimeExpression"
                 },
-                "source": {
-                    "description": "URI template or runtime expression",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.URITemplateOrRuntimeExpr"
-                        }
-                    ]
+                "jitter": {
+                    "$ref": "#/definitions/model.RetryPolicyJitter"
                 },
-                "subject": {
-                    "type": "string"
+                "limit": {
+                    "$ref": "#/definitions/model.RetryLimit"
                 },
-                "time": {
-                    "description": "ISO 8601 date-time string or runtime expression",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.StringOrRuntimeExpr"
-                        }
-                    ]
+                "when": {
+                    "$ref": "#/definitions/model.RuntimeExpression"
+                }
+            }
+        },
+        "model.RetryPolicyJitter": {
+            "type": "object",
+            "required": [
+                "from",
+                "to"
+            ],
+            "properties": {
+                "from": {
+                    "$ref": "#/definitions/github_com_serverlessworkflow_sdk-go_v3_model.Duration"
                 },
-                "type": {
-                    "type": "string"
+                "to": {
+                    "$ref": "#/definitions/github_com_serverlessworkflow_sdk-go_v3_model.Duration"
                 }
             }
         },
-        "model.ExtensionItem": {
+        "model.RuntimeExpression": {
             "type": "object"
         },
-        "model.ExternalResource": {
+        "model.Schedule": {
             "type": "object",
-            "required": [
-                "endpoint"
-            ],
             "properties": {
-                "endpoint": {
-                    "$ref": "#/definitions/model.Endpoint"
+                "after": {
+                    "$ref": "#/definitions/github_com_serverlessworkflow_sdk-go_v3_model.Duration"
                 },
-                "name": {
+                "cron": {
                     "type": "string"
+                },
+                "every": {
+                    "$ref": "#/definitions/github_com_serverlessworkflow_sdk-go_v3_model.Duration"
+                },
+                "on": {
+                    "$ref": "#/definitions/model.EventConsumptionStrategy"
                 }
             }
         },
-        "model.Input": {
+        "model.Schema": {
             "type": "object",
             "properties": {
-                "from": {
-                    "$ref": "#/definitions/model.ObjectOrRuntimeExpr"
+                "document": {},
+                "format": {
+                    "type": "string"
                 },
-                "schema": {
-                    "$ref": "#/definitions/model.Schema"
+                "resource": {
+                    "$ref": "#/definitions/model.ExternalResource"
                 }
             }
         },
-        "model.JsonPointerOrRuntimeExpression": {
+        "model.StringOrRuntimeExpr": {
             "type": "object"
         },
-        "model.NamedTaskMap": {
+        "model.TaskItem": {
+            "type": "object"
+        },
+        "model.Timeout": {
             "type": "object",
-            "additionalProperties": {}
+            "required": [
+                "after"
+            ],
+            "properties": {
+                "after": {
+                    "description": "After The duration after which to timeout",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_serverlessworkflow_sdk-go_v3_model.Duration"
+                        }
+                    ]
+                }
+            }
         },
-        "model.OAuth2AutenthicationDataClient": {
+        "model.TimeoutOrReference": {
+            "type": "object"
+        },
+        "model.URITemplateOrRuntimeExpr": {
+            "type": "object"
+        },
+        "model.Use": {
             "type": "object",
             "properties": {
-                "assertion": {
-                    "type": "string"
+                "authentications": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.AuthenticationPolicy"
+                    }
                 },
-                "authentication": {
-                    "$ref": "#/definitions/model.OAuthClientAuthenticationType"
+                "catalogs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.Catalog"
+                    }
                 },
-                "id": {
-                    "type": "string"
+                "errors": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.Error"
+                    }
                 },
-                "secret": {
-                    "type": "string"
-                }
-            }
-        },
-        "model.OAuth2AuthenticationDataGrant": {
-            "type": "string",
-            "enum": [
-                "authorization_code",
-                "client_credentials",
-                "password",
-                "refresh_token",
-                "urn:ietf:params:oauth:grant-type:token-exchange"
-            ],
-            "x-enum-comments": {
-                "TokenExchangeGrant": "#nosec G101"
-            },
-            "x-enum-descriptions": [
-                "",
-                "",
-                "",
-                "",
-                "#nosec G101"
-            ],
-            "x-enum-varnames": [
-                "AuthorizationCodeGrant",
-                "ClientCredentialsGrant",
-                "PasswordGrant",
-                "RefreshTokenGrant",
-                "TokenExchangeGrant"
-            ]
+                "extensions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ExtensionItem"
+                    }
+                },
+                "functions": {
+                    "$ref": "#/definitions/model.NamedTaskMap"
+                },
+                "retries": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.RetryPolicy"
+                    }
+                },
+                "secrets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "timeouts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.Timeout"
+                    }
+                }
+            }
         },
-        "model.OAuth2AuthenticationPolicy": {
+        "model.Workflow": {
             "type": "object",
+            "required": [
+                "do",
+                "document"
+            ],
             "properties": {
-                "endpoints": {
-                    "$ref": "#/definitions/model.OAuth2Endpoints"
+                "do": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TaskItem"
+                    }
                 },
-                "properties": {
-                    "$ref": "#/definitions/model.OAuth2AuthenticationProperties"
+                "document": {
+                    "$ref": "#/definitions/model.Document"
+                },
+                "input": {
+                    "$ref": "#/definitions/model.Input"
+                },
+                "output": {
+                    "$ref": "#/definitions/model.Output"
+                },
+                "schedule": {
+                    "$ref": "#/definitions/model.Schedule"
+                },
+                "timeout": {
+                    "$ref": "#/definitions/model.TimeoutOrReference"
                 },
                 "use": {
-                    "type": "string"
+                    "$ref": "#/definitions/model.Use"
                 }
             }
         },
-        "model.OAuth2AuthenticationProperties": {
+        "models.AuditEvent": {
             "type": "object",
             "properties": {
-                "actor": {
-                    "$ref": "#/definitions/model.OAuth2Token"
+                "error": {
+                    "description": "The error code if the call failed",
+                    "type": "string"
                 },
-                "audiences": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "id": {
+                    "type": "string"
                 },
-                "authority": {},
-                "client": {
-                    "$ref": "#/definitions/model.OAuth2AutenthicationDataClient"
+                "name": {
+                    "description": "e.g. TerminateInstances",
+                    "type": "string"
                 },
-                "grant": {
-                    "enum": [
-                        "authorization_code",
-                        "client_credentials",
-                        "password",
-                        "refresh_token",
-                        "urn:ietf:params:oauth:grant-type:token-exchange"
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.OAuth2AuthenticationDataGrant"
-                        }
-                    ]
+                "read_only": {
+                    "type": "boolean"
                 },
-                "issuers": {
+                "resources": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "password": {
+                "source": {
+                    "description": "e.g. ec2.amazonaws.com",
                     "type": "string"
                 },
-                "request": {
-                    "$ref": "#/definitions/model.OAuth2TokenRequest"
-                },
-                "scopes": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "source_ip": {
+                    "type": "string"
                 },
-                "subject": {
-                    "$ref": "#/definitions/model.OAuth2Token"
+                "time": {
+                    "type": "string"
                 },
                 "username": {
                     "type": "string"
                 }
             }
         },
-        "model.OAuth2Endpoints": {
+        "models.AuditTrail": {
             "type": "object",
             "properties": {
-                "introspection": {
+                "error": {
                     "type": "string"
                 },
-                "revocation": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditEvent"
+                    }
+                },
+                "principal": {
                     "type": "string"
                 },
-                "token": {
+                "provider": {
                     "type": "string"
                 }
             }
         },
-        "model.OAuth2Token": {
+        "models.AuditTrailResponse": {
             "type": "object",
             "properties": {
-                "token": {
-                    "description": "Token The security token to use",
+                "end_time": {
                     "type": "string"
                 },
-                "type": {
-                    "description": "Type The type of the security token to use.",
+                "id": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "trails": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditTrail"
+                    }
+                },
+                "user": {
                     "type": "string"
                 }
             }
         },
-        "model.OAuth2TokenRequest": {
+        "models.AuthRefreshRequest": {
             "type": "object",
             "properties": {
-                "encoding": {
-                    "$ref": "#/definitions/model.OAuth2TokenRequestEncodingType"
+                "provider": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
                 }
             }
         },
-        "model.OAuth2TokenRequestEncodingType": {
-            "type": "string",
-            "enum": [
-                "application/x-www-form-urlencoded",
-                "application/json"
-            ],
-            "x-enum-varnames": [
-                "EncodingTypeFormUrlEncoded",
-                "EncodingTypeApplicationJson"
-            ]
-        },
-        "model.OAuthClientAuthenticationType": {
-            "type": "string",
-            "enum": [
-                "client_secret_basic",
-                "client_secret_post",
-                "client_secret_jwt",
-                "private_key_jwt",
-                "none"
-            ],
-            "x-enum-varnames": [
-                "OAuthClientAuthClientSecretBasic",
-                "OAuthClientAuthClientSecretPost",
-                "OAuthClientAuthClientSecretJWT",
-                "OAuthClientAuthPrivateKeyJWT",
-                "OAuthClientAuthNone"
-            ]
-        },
-        "model.ObjectOrRuntimeExpr": {
-            "type": "object"
-        },
-        "model.OpenIdConnectAuthenticationPolicy": {
+        "models.AuthRefreshResponse": {
             "type": "object",
             "properties": {
-                "properties": {
-                    "$ref": "#/definitions/model.OAuth2AuthenticationProperties"
+                "expiry": {
+                    "type": "string"
                 },
-                "use": {
+                "provider": {
                     "type": "string"
                 }
             }
         },
-        "model.Output": {
+        "models.AuthRenewRequest": {
             "type": "object",
             "properties": {
-                "as": {
-                    "$ref": "#/definitions/model.ObjectOrRuntimeExpr"
-                },
-                "schema": {
-                    "$ref": "#/definitions/model.Schema"
+                "provider": {
+                    "type": "string"
                 }
             }
         },
-        "model.RetryBackoff": {
+        "models.AuthRenewResponse": {
             "type": "object",
             "properties": {
-                "constant": {
-                    "$ref": "#/definitions/model.BackoffDefinition"
+                "expiry": {
+                    "type": "string"
                 },
-                "exponential": {
-                    "$ref": "#/definitions/model.BackoffDefinition"
+                "provider": {
+                    "type": "string"
                 },
-                "linear": {
-                    "$ref": "#/definitions/model.BackoffDefinition"
+                "session": {
+                    "description": "encoded local session",
+                    "type": "string"
                 }
             }
         },
-        "model.RetryLimit": {
+        "models.AuthorizeUser": {
             "type": "object",
             "properties": {
-                "attempt": {
-                    "$ref": "#/definitions/model.RetryLimitAttempt"
+                "code": {
+                    "type": "string"
                 },
-                "duration": {
-                    "$ref": "#/definitions/model.Duration"
+                "redirect_uri": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "state": {
+                    "type": "string"
                 }
             }
         },
-        "model.RetryLimitAttempt": {
+        "models.CapabilitiesResponse": {
             "type": "object",
             "properties": {
-                "count": {
-                    "type": "integer"
+                "capabilities": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
                 },
-                "duration": {
-                    "$ref": "#/definitions/model.Duration"
+                "version": {
+                    "type": "string"
                 }
             }
         },
-        "model.RetryPolicy": {
+        "models.Delegation": {
             "type": "object",
             "properties": {
-                "backoff": {
-                    "$ref": "#/definitions/model.RetryBackoff"
+                "approver": {
+                    "type": "string"
                 },
-                "delay": {
-                    "$ref": "#/definitions/model.Duration"
+                "created_at": {
+                    "type": "string"
                 },
-                "exceptWhen": {
-                    "$ref": "#/definitions/model.RuntimeExpression"
+                "delegate": {
+                    "type": "string"
                 },
-                "jitter": {
-                    "$ref": "#/definitions/model.RetryPolicyJitter"
+                "end": {
+                    "type": "string"
                 },
-                "limit": {
-                    "$ref": "#/definitions/model.RetryLimit"
+                "id": {
+                    "type": "string"
                 },
-                "when": {
-                    "$ref": "#/definitions/model.RuntimeExpression"
+                "reason": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
                 }
             }
         },
-        "model.RetryPolicyJitter": {
+        "models.DelegationRequest": {
             "type": "object",
             "required": [
-                "from",
-                "to"
+                "delegate",
+                "end",
+                "start"
             ],
             "properties": {
-                "from": {
-                    "$ref": "#/definitions/model.Duration"
+                "delegate": {
+                    "type": "string"
                 },
-                "to": {
-                    "$ref": "#/definitions/model.Duration"
+                "end": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
                 }
             }
         },
-        "model.RuntimeExpression": {
-            "type": "object"
+        "models.DelegationsResponse": {
+            "type": "object",
+            "properties": {
+                "delegations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Delegation"
+                    }
+                }
+            }
         },
-        "model.Schedule": {
+        "models.DeviceCodeResponse": {
             "type": "object",
             "properties": {
-                "after": {
-                    "$ref": "#/definitions/model.Duration"
+                "device_code": {
+                    "type": "string"
                 },
-                "cron": {
+                "expires_in": {
+                    "description": "Seconds until the codes expire",
+                    "type": "integer"
+                },
+                "interval": {
+                    "description": "Seconds to wait between polls",
+                    "type": "integer"
+                },
+                "user_code": {
                     "type": "string"
                 },
-                "every": {
-                    "$ref": "#/definitions/model.Duration"
+                "verification_uri": {
+                    "type": "string"
                 },
-                "on": {
-                    "$ref": "#/definitions/model.EventConsumptionStrategy"
+                "verification_uri_complete": {
+                    "type": "string"
                 }
             }
         },
-        "model.Schema": {
+        "models.DeviceTokenError": {
             "type": "object",
             "properties": {
-                "document": {},
-                "format": {
+                "error": {
                     "type": "string"
                 },
-                "resource": {
-                    "$ref": "#/definitions/model.ExternalResource"
+                "error_description": {
+                    "type": "string"
+                },
+                "interval": {
+                    "description": "New poll interval after slow_down",
+                    "type": "integer"
                 }
             }
         },
-        "model.StringOrRuntimeExpr": {
-            "type": "object"
-        },
-        "model.TaskItem": {
-            "type": "object"
-        },
-        "model.Timeout": {
+        "models.DeviceTokenRequest": {
             "type": "object",
             "required": [
-                "after"
+                "device_code"
             ],
             "properties": {
-                "after": {
-                    "description": "After The duration after which to timeout",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.Duration"
-                        }
-                    ]
+                "device_code": {
+                    "type": "string"
                 }
             }
         },
-        "model.TimeoutOrReference": {
-            "type": "object"
-        },
-        "model.URITemplateOrRuntimeExpr": {
-            "type": "object"
-        },
-        "model.Use": {
+        "models.DeviceTokenResponse": {
             "type": "object",
             "properties": {
-                "authentications": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.AuthenticationPolicy"
-                    }
-                },
-                "catalogs": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.Catalog"
-                    }
-                },
-                "errors": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.Error"
-                    }
-                },
-                "extensions": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.ExtensionItem"
-                    }
-                },
-                "functions": {
-                    "$ref": "#/definitions/model.NamedTaskMap"
-                },
-                "retries": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.RetryPolicy"
-                    }
+                "expiry": {
+                    "type": "string"
                 },
-                "secrets": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "provider": {
+                    "type": "string"
                 },
-                "timeouts": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.Timeout"
-                    }
+                "session": {
+                    "type": "string"
                 }
             }
         },
-        "model.Workflow": {
+        "models.ElevateLLMRequest": {
             "type": "object",
-            "required": [
-                "do",
-                "document"
-            ],
             "properties": {
-                "do": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.TaskItem"
-                    }
-                },
-                "document": {
-                    "$ref": "#/definitions/model.Document"
-                },
-                "input": {
-                    "$ref": "#/definitions/model.Input"
-                },
-                "output": {
-                    "$ref": "#/definitions/model.Output"
-                },
-                "schedule": {
-                    "$ref": "#/definitions/model.Schedule"
-                },
-                "timeout": {
-                    "$ref": "#/definitions/model.TimeoutOrReference"
-                },
-                "use": {
-                    "$ref": "#/definitions/model.Use"
+                "reason": {
+                    "type": "string"
                 }
             }
         },
-        "models.AuthorizeUser": {
+        "models.ElevatePlanResponse": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "string"
+                "allowed": {
+                    "type": "boolean"
                 },
-                "redirect_uri": {
+                "decision": {
+                    "$ref": "#/definitions/models.PolicyDecision"
+                },
+                "duration": {
                     "type": "string"
                 },
-                "scopes": {
+                "identities": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "state": {
-                    "type": "string"
-                }
-            }
-        },
-        "models.ElevateLLMRequest": {
-            "type": "object",
-            "properties": {
-                "reason": {
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role": {
+                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Role"
+                },
+                "workflow": {
                     "type": "string"
                 }
             }
@@ -3557,7 +5003,7 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "duration": {
-                    "description": "Duration in ISO 8601 format",
+                    "description": "Duration e.g. PT1H, 90m or 2 hours",
                     "type": "string"
                 },
                 "identities": {
@@ -3567,6 +5013,10 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "idempotency_key": {
+                    "description": "IdempotencyKey identifies retries of the same request so a double submit doesn't start a second workflow, see NewIdempotencyKey",
+                    "type": "string"
+                },
                 "providers": {
                     "description": "A role can be applied to multiple providers",
                     "type": "array",
@@ -3574,9 +5024,21 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "public_key": {
+                    "description": "PublicKey is an age recipient e.g. age1... generated by the client\nfor this request. Credentials handed to the requester, such as Vault\ndatabase credentials, are only returned encrypted for it.",
+                    "type": "string"
+                },
                 "reason": {
                     "type": "string"
                 },
+                "risk_flags": {
+                    "description": "RiskFlags are evaluated by the server when the request is made, any\nflags sent by the client are replaced",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.RiskFlags"
+                        }
+                    ]
+                },
                 "role": {
                     "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Role"
                 },
@@ -3588,6 +5050,47 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ElevationEvent": {
+            "type": "object",
+            "properties": {
+                "execution": {
+                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.WorkflowExecutionInfo"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.ElevationStatus"
+                },
+                "task": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ElevationStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "granted",
+                "revoked",
+                "denied",
+                "failed",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "ElevationStatusPending",
+                "ElevationStatusApproved",
+                "ElevationStatusGranted",
+                "ElevationStatusRevoked",
+                "ElevationStatusDenied",
+                "ElevationStatusFailed",
+                "ElevationStatusCancelled"
+            ]
+        },
         "models.EnvironmentConfig": {
             "type": "object",
             "properties": {
@@ -3636,6 +5139,13 @@ const docTemplate = `{
                     "type": "string",
                     "default": "latest"
                 },
+                "overrides": {
+                    "description": "Overrides change roles by name for this environment e.g. to require\napproval for admin in production",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.RoleOverride"
+                    }
+                },
                 "platform": {
                     "description": "AWS, GCP, Kubernetes, Local",
                     "default": "aws",
@@ -3664,6 +5174,86 @@ const docTemplate = `{
                 "Local"
             ]
         },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Grant": {
+            "type": "object",
+            "properties": {
+                "approvers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "authorized_at": {
+                    "type": "string"
+                },
+                "duration": {
+                    "description": "Duration in seconds",
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "identities": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "remaining": {
+                    "description": "Remaining time in seconds",
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "user": {
+                    "type": "string"
+                },
+                "workflow": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GrantsResponse": {
+            "type": "object",
+            "properties": {
+                "grants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Grant"
+                    }
+                },
+                "next_page_token": {
+                    "type": "string"
+                }
+            }
+        },
         "models.HealthResponse": {
             "type": "object",
             "properties": {
@@ -3748,13 +5338,101 @@ const docTemplate = `{
                 }
             }
         },
+        "models.PolicyDecision": {
+            "type": "object",
+            "properties": {
+                "approvers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "result": {
+                    "$ref": "#/definitions/models.PolicyResult"
+                }
+            }
+        },
+        "models.PolicyResult": {
+            "type": "string",
+            "enum": [
+                "allow",
+                "deny",
+                "require_approval"
+            ],
+            "x-enum-varnames": [
+                "PolicyResultAllow",
+                "PolicyResultDeny",
+                "PolicyResultRequireApproval"
+            ]
+        },
+        "models.ProviderConnectionResult": {
+            "type": "object",
+            "properties": {
+                "capability": {
+                    "$ref": "#/definitions/github_com_thand-io_agent_internal_models.ProviderCapability"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "passed": {
+                    "type": "boolean"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "skipped": {
+                    "description": "No smoke check, only initialization was tested",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.ProviderHealth": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProviderConnectionResult"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/models.HealthState"
+                }
+            }
+        },
         "models.ProviderIdentitiesResponse": {
             "type": "object",
             "properties": {
                 "identities": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Identity"
+                        "type": "object",
+                        "properties": {
+                            "_id": {
+                                "type": "string"
+                            },
+                            "_reason": {
+                                "type": "string"
+                            },
+                            "_score": {
+                                "type": "number"
+                            },
+                            "_source": {
+                                "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Identity"
+                            }
+                        }
                     }
                 },
                 "provider": {
@@ -3771,6 +5449,9 @@ const docTemplate = `{
                 "description": {
                     "type": "string"
                 },
+                "id": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
@@ -3785,7 +5466,21 @@ const docTemplate = `{
                 "permissions": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.ProviderPermission"
+                        "type": "object",
+                        "properties": {
+                            "_id": {
+                                "type": "string"
+                            },
+                            "_reason": {
+                                "type": "string"
+                            },
+                            "_score": {
+                                "type": "number"
+                            },
+                            "_source": {
+                                "$ref": "#/definitions/models.ProviderPermission"
+                            }
+                        }
                     }
                 },
                 "provider": {
@@ -3796,15 +5491,80 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ProviderResource": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "description": "Store additional metadata if needed",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProviderResourcesResponse": {
+            "type": "object",
+            "properties": {
+                "provider": {
+                    "type": "string"
+                },
+                "resources": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "_id": {
+                                "type": "string"
+                            },
+                            "_reason": {
+                                "type": "string"
+                            },
+                            "_score": {
+                                "type": "number"
+                            },
+                            "_source": {
+                                "$ref": "#/definitions/models.ProviderResource"
+                            }
+                        }
+                    }
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
         "models.ProviderResponse": {
             "type": "object",
             "properties": {
+                "capabilities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_thand-io_agent_internal_models.ProviderCapability"
+                    }
+                },
                 "description": {
                     "type": "string"
                 },
                 "enabled": {
                     "type": "boolean"
                 },
+                "health": {
+                    "$ref": "#/definitions/models.ProviderHealth"
+                },
+                "id": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
@@ -3840,7 +5600,21 @@ const docTemplate = `{
                 "roles": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.ProviderRole"
+                        "type": "object",
+                        "properties": {
+                            "_id": {
+                                "type": "string"
+                            },
+                            "_reason": {
+                                "type": "string"
+                            },
+                            "_score": {
+                                "type": "number"
+                            },
+                            "_source": {
+                                "$ref": "#/definitions/models.ProviderRole"
+                            }
+                        }
                     }
                 },
                 "version": {
@@ -3862,20 +5636,184 @@ const docTemplate = `{
                 }
             }
         },
-        "models.Resources": {
+        "models.ReasonPolicy": {
             "type": "object",
             "properties": {
-                "allow": {
+                "min_length": {
+                    "type": "integer"
+                },
+                "must_match": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "deny": {
+                "must_not_match": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
+                },
+                "required": {
+                    "description": "Required defaults to true. Set to false to allow empty reasons.",
+                    "type": "boolean"
+                }
+            }
+        },
+            "models.ReconcileAction": {
+                "type": "string",
+                "enum": [
+                    "revoke",
+                    "notify"
+                ],
+                "x-enum-varnames": [
+                    "ReconcileActionRevoke",
+                    "ReconcileActionNotify"
+                ]
+            },
+            "models.ReconcileFinding": {
+                "type": "object",
+                "properties": {
+                    "error": {
+                        "type": "string"
+                    },
+                    "first_seen": {
+                        "type": "string"
+                    },
+                    "identity": {
+                        "type": "string"
+                    },
+                    "ids": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    },
+                    "kind": {
+                        "type": "string"
+                    },
+                    "provider": {
+                        "type": "string"
+                    },
+                    "reference": {
+                        "type": "string"
+                    },
+                    "role": {
+                        "type": "string"
+                    },
+                    "status": {
+                        "$ref": "#/definitions/models.ReconcileStatus"
+                    }
+                }
+            },
+            "models.ReconcileResult": {
+                "type": "object",
+                "properties": {
+                    "action": {
+                        "$ref": "#/definitions/models.ReconcileAction"
+                    },
+                    "completed_at": {
+                        "type": "string"
+                    },
+                    "errors": {
+                        "description": "Providers that couldn't be listed",
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    },
+                    "findings": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/models.ReconcileFinding"
+                        }
+                    },
+                    "held": {
+                        "description": "Grants recorded by running elevations",
+                        "type": "integer"
+                    },
+                    "providers": {
+                        "description": "Providers whose grants were listed",
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    },
+                    "started_at": {
+                        "type": "string"
+                    }
+                }
+            },
+            "models.ReconcileStatus": {
+                "type": "string",
+                "enum": [
+                    "pending",
+                    "revoked",
+                    "deferred",
+                    "notified",
+                    "reported",
+                    "failed"
+                ],
+                "x-enum-comments": {
+                    "ReconcileStatusDeferred": "Over the sweep's revocation limit",
+                    "ReconcileStatusNotified": "Reported to the notifier",
+                    "ReconcileStatusPending": "Within the grace period",
+                    "ReconcileStatusReported": "Already reported, or nowhere to report it",
+                    "ReconcileStatusRevoked": "Revoked by the sweep"
+                },
+                "x-enum-descriptions": [
+                    "Within the grace period",
+                    "Revoked by the sweep",
+                    "Over the sweep's revocation limit",
+                    "Reported to the notifier",
+                    "Already reported, or nowhere to report it",
+                    ""
+                ],
+                "x-enum-varnames": [
+                    "ReconcileStatusPending",
+                    "ReconcileStatusRevoked",
+                    "ReconcileStatusDeferred",
+                    "ReconcileStatusNotified",
+                    "ReconcileStatusReported",
+                    "ReconcileStatusFailed"
+                ]
+            },
+        "models.RiskFlags": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "description": "Country the request was made from",
+                    "type": "string"
+                },
+                "first_time_role": {
+                    "description": "The user hasn't been granted the role before",
+                    "type": "boolean"
+                },
+                "geo_mismatch": {
+                    "description": "Requested from a different country to the last request",
+                    "type": "boolean"
+                },
+                "previous_country": {
+                    "description": "Country the last request was made from",
+                    "type": "string"
+                },
+                "unusual_hour": {
+                    "description": "Requested outside the user's typical hours",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.RoleOverride": {
+            "type": "object",
+            "properties": {
+                "disabled": {
+                    "type": "boolean"
+                },
+                "max_duration": {
+                    "type": "string"
+                },
+                "requires_approval": {
+                    "type": "boolean"
                 }
             }
         },
@@ -3889,6 +5827,10 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "default_effect": {
+                    "description": "DefaultEffect is the effect of actions not listed in the permissions.\nWith deny only actions in permissions.allow can be requested.",
+                    "type": "string"
+                },
                 "description": {
                     "type": "string"
                 },
@@ -3912,6 +5854,10 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "max_duration": {
+                    "description": "MaxDuration limits how long the role can be requested for e.g. 4h",
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
@@ -3930,11 +5876,23 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "reason_policy": {
+                    "description": "ReasonPolicy overrides the default rules for the reason given when\nrequesting the role",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ReasonPolicy"
+                        }
+                    ]
+                },
+                "requires_approval": {
+                    "description": "RequiresApproval sends every request for the role through approval,\neven when the policies allow it",
+                    "type": "boolean"
+                },
                 "resources": {
                     "description": "resource access rules, apis, files, systems etc",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/models.Resources"
+                            "$ref": "#/definitions/github_com_thand-io_agent_internal_models.Resources"
                         }
                     ]
                 },
@@ -3946,6 +5904,9 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "version": {
+                    "$ref": "#/definitions/version.Version"
+                },
                 "workflows": {
                     "description": "The workflows to execute",
                     "type": "array",
@@ -3958,6 +5919,14 @@ const docTemplate = `{
         "models.RolesResponse": {
             "type": "object",
             "properties": {
+                "reason_policy": {
+                    "description": "default reason policy",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ReasonPolicy"
+                        }
+                    ]
+                },
                 "roles": {
                     "type": "object",
                     "additionalProperties": {
@@ -4010,6 +5979,16 @@ const docTemplate = `{
                 "apiKey": {
                     "type": "string"
                 },
+                "continueAsNewHistoryLength": {
+                    "description": "ContinueAsNewHistoryLength is the number of history events after\nwhich a workflow continues as new",
+                    "type": "integer",
+                    "default": 10000
+                },
+                "continueAsNewHistorySize": {
+                    "description": "ContinueAsNewHistorySize is the history size in bytes after which a\nworkflow continues as new",
+                    "type": "integer",
+                    "default": 10485760
+                },
                 "disableVersioning": {
                     "description": "DisableVersioning disables worker versioning/deployments for testing",
                     "type": "boolean",
@@ -4035,6 +6014,17 @@ const docTemplate = `{
                 }
             }
         },
+        "models.WorkflowExecutionsResponse": {
+            "type": "object",
+            "properties": {
+                "executions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_thand-io_agent_internal_models.WorkflowExecutionInfo"
+                    }
+                }
+            }
+        },
         "models.WorkflowResponse": {
             "type": "object",
             "properties": {
@@ -4049,6 +6039,61 @@ const docTemplate = `{
                 }
             }
         },
+        "models.WorkflowSignalRequest": {
+            "type": "object",
+            "required": [
+                "input"
+            ],
+            "properties": {
+                "input": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WorkflowTemplate": {
+            "type": "object",
+            "properties": {
+                "approvals": {
+                    "description": "Approvals is the number of approvals needed, defaults to 1",
+                    "type": "integer"
+                },
+                "duration": {
+                    "description": "Duration overrides the requested duration e.g. 1h, otherwise the\nrole's max duration caps it",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "notifiers": {
+                    "description": "Notifiers are sent approval requests and grant updates, keyed by name",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.WorkflowTemplateNotifier"
+                    }
+                },
+                "self_approve": {
+                    "type": "boolean"
+                },
+                "ticket_pattern": {
+                    "description": "TicketPattern is the regular expression the reason must match for\njit_with_ticket, defaults to a ticket reference such as OPS-1234",
+                    "type": "string"
+                }
+            }
+        },
+        "models.WorkflowTemplateNotifier": {
+            "type": "object",
+            "properties": {
+                "provider": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "models.WorkflowsResponse": {
             "type": "object",
             "properties": {
@@ -4082,29 +6127,8 @@ const docTemplate = `{
                 }
             }
         },
-        "time.Duration": {
-            "type": "integer",
-            "format": "int64",
-            "enum": [
-                -9223372036854775808,
-                9223372036854775807,
-                1,
-                1000,
-                1000000,
-                1000000000,
-                60000000000,
-                3600000000000
-            ],
-            "x-enum-varnames": [
-                "minDuration",
-                "maxDuration",
-                "Nanosecond",
-                "Microsecond",
-                "Millisecond",
-                "Second",
-                "Minute",
-                "Hour"
-            ]
+        "version.Version": {
+            "type": "object"
         }
     },
     "securityDefinitions": {
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"os"
+	"path/filepath"
 
 	"github.com/kardianos/service"
 	"github.com/sirupsen/logrus"
@@ -40,7 +41,7 @@ func (p *ServiceProgram) Stop(s service.Service) error {
 
 // createService creates a new service instance
 func CreateService(cfg *config.Config) (service.Service, error) {
-	svcConfig := getServiceConfig()
+	svcConfig := getServiceConfig(cfg)
 
 	prg := &ServiceProgram{
 		exit:   make(chan struct{}),
@@ -50,21 +51,31 @@ func CreateService(cfg *config.Config) (service.Service, error) {
 	return service.New(prg, svcConfig)
 }
 
-// getServiceConfig returns the service configuration
-func getServiceConfig() *service.Config {
+// getServiceConfig returns the service configuration. The service is
+// pointed at the config file the agent was installed with, as the service
+// user may not search the same paths.
+func getServiceConfig(cfg *config.Config) *service.Config {
 	exePath, err := os.Executable()
 
 	if err != nil {
 		logrus.Fatal(err)
 	}
 
+	arguments := []string{
+		"agent", // Runs the web server
+	}
+
+	if cfg != nil && len(cfg.GetConfigFile()) > 0 {
+		if configFile, err := filepath.Abs(cfg.GetConfigFile()); err == nil {
+			arguments = append(arguments, "--config", configFile)
+		}
+	}
+
 	return &service.Config{
 		Name:        "thand",
 		DisplayName: "Thand Agent Service",
 		Description: "Thand Agent - Just-in-time access to cloud infrastructure and SaaS applications",
 		Executable:  exePath,
-		Arguments: []string{
-			"agent", // Runs the web server
-		},
+		Arguments:   arguments,
 	}
 }
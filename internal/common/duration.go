@@ -2,14 +2,76 @@ package common
 
 import (
 	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/go-viper/mapstructure/v2"
 	iso8601 "github.com/senseyeio/duration"
 )
 
+// DurationFormats describes the duration formats accepted by ParseDuration
+const DurationFormats = "expected a Go duration (90m, 1h30m), ISO 8601 (PT2H, P1D) or words (2 days, 90 minutes)"
+
+const day = 24 * time.Hour
+
+var durationUnits = map[string]time.Duration{
+	"s":       time.Second,
+	"sec":     time.Second,
+	"secs":    time.Second,
+	"second":  time.Second,
+	"seconds": time.Second,
+	"m":       time.Minute,
+	"min":     time.Minute,
+	"mins":    time.Minute,
+	"minute":  time.Minute,
+	"minutes": time.Minute,
+	"h":       time.Hour,
+	"hr":      time.Hour,
+	"hrs":     time.Hour,
+	"hour":    time.Hour,
+	"hours":   time.Hour,
+	"d":       day,
+	"day":     day,
+	"days":    day,
+	"w":       7 * day,
+	"wk":      7 * day,
+	"wks":     7 * day,
+	"week":    7 * day,
+	"weeks":   7 * day,
+}
+
+var (
+	humanDurationPattern = regexp.MustCompile(`^(\s*\d+(\.\d+)?\s*[a-z]+)+\s*$`)
+	humanDurationPart    = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*([a-z]+)`)
+)
+
+// ParseDuration parses a positive duration written as a Go duration (90m),
+// ISO 8601 (PT2H, P1D) or in words (2 days, 1 hour and 30 minutes)
+func ParseDuration(duration string) (time.Duration, error) {
+
+	duration = strings.TrimSpace(duration)
+
+	parsed, err := parseDuration(duration)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if parsed <= 0 {
+		return 0, fmt.Errorf("duration must be greater than zero: %s", duration)
+	}
+
+	return parsed, nil
+}
+
+// ValidateDuration parses a duration for an elevation which must be at
+// least a minute long
 func ValidateDuration(duration string) (time.Duration, error) {
-	w, err := validateDuration(duration)
+	w, err := ParseDuration(duration)
 	if err != nil {
 		return 0, err
 	}
@@ -19,9 +81,7 @@ func ValidateDuration(duration string) (time.Duration, error) {
 	return w, nil
 }
 
-func validateDuration(duration string) (time.Duration, error) {
-
-	duration = strings.TrimSpace(duration)
+func parseDuration(duration string) (time.Duration, error) {
 
 	if parsedDuration, err := time.ParseDuration(duration); err == nil {
 		return parsedDuration, nil
@@ -29,81 +89,104 @@ func validateDuration(duration string) (time.Duration, error) {
 		referenceTime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
 		shiftedTime := isoDuration.Shift(referenceTime)
 		return shiftedTime.Sub(referenceTime), nil
+	} else if humanDuration, ok := parseHumanDuration(duration); ok {
+		return humanDuration, nil
 	}
 
-	return 0, fmt.Errorf("invalid duration format: %s. Expect ISO 8601 or duration string", duration)
+	return 0, fmt.Errorf("invalid duration %q, %s", duration, DurationFormats)
 }
 
-// FormatDuration formats a duration in ISO 8601 format (PT1H30M20S)
-func FormatDuration(d time.Duration) string {
-	if d == 0 {
-		return "PT0S"
+// parseHumanDuration parses durations such as "2 days", "1 hour, 30 minutes"
+// or "1h 30m"
+func parseHumanDuration(duration string) (time.Duration, bool) {
+
+	duration = strings.ToLower(duration)
+	duration = strings.ReplaceAll(duration, ",", " ")
+	duration = strings.ReplaceAll(duration, " and ", " ")
+
+	if !humanDurationPattern.MatchString(duration) {
+		return 0, false
 	}
 
-	var result strings.Builder
-	result.WriteString("PT")
+	var total float64
 
-	hours := int(d.Hours())
-	minutes := int(d.Minutes()) % 60
-	seconds := int(d.Seconds()) % 60
+	for _, part := range humanDurationPart.FindAllStringSubmatch(duration, -1) {
 
-	if hours > 0 {
-		result.WriteString(fmt.Sprintf("%dH", hours))
-	}
-	if minutes > 0 {
-		result.WriteString(fmt.Sprintf("%dM", minutes))
+		unit, found := durationUnits[part[2]]
+
+		if !found {
+			return 0, false
+		}
+
+		value, err := strconv.ParseFloat(part[1], 64)
+
+		if err != nil {
+			return 0, false
+		}
+
+		total += value * float64(unit)
 	}
-	if seconds > 0 || (hours == 0 && minutes == 0) {
-		result.WriteString(fmt.Sprintf("%dS", seconds))
+
+	if total > math.MaxInt64 {
+		return 0, false
 	}
 
-	return result.String()
+	return time.Duration(total), true
 }
 
-// FormatHumanDuration formats a duration in human readable format (1 day, 2 hours, 3 minutes, 4 seconds)
-func FormatDurationRemaining(d time.Duration) string {
+// FormatDuration formats a duration for people to read e.g. 1h 23m or
+// 2d 4h. Durations are truncated to the second and the result can be
+// parsed by ParseDuration.
+func FormatDuration(d time.Duration) string {
+
+	if d < 0 {
+		return "-" + FormatDuration(-d)
+	}
+
+	d = d.Truncate(time.Second)
+
 	if d == 0 {
-		return "0 seconds"
+		return "0s"
 	}
 
-	days := int(d.Hours()) / 24
-	hours := int(d.Hours()) % 24
-	minutes := int(d.Minutes()) % 60
-	seconds := int(d.Seconds()) % 60
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"d", day},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
 
 	var parts []string
 
-	if days > 0 {
-		if days == 1 {
-			parts = append(parts, "1 day")
-		} else {
-			parts = append(parts, fmt.Sprintf("%d days", days))
+	for _, unit := range units {
+		if d >= unit.size {
+			parts = append(parts, fmt.Sprintf("%d%s", d/unit.size, unit.suffix))
+			d = d % unit.size
 		}
 	}
 
-	if hours > 0 {
-		if hours == 1 {
-			parts = append(parts, "1 hour")
-		} else {
-			parts = append(parts, fmt.Sprintf("%d hours", hours))
-		}
-	}
+	return strings.Join(parts, " ")
+}
 
-	if minutes > 0 {
-		if minutes == 1 {
-			parts = append(parts, "1 minute")
-		} else {
-			parts = append(parts, fmt.Sprintf("%d minutes", minutes))
+// StringToDurationHookFunc decodes configuration strings into durations
+// using ParseDuration. Empty strings and "0" decode to zero so timeouts can
+// still be disabled.
+func StringToDurationHookFunc() mapstructure.DecodeHookFunc {
+	return func(f reflect.Type, t reflect.Type, data any) (any, error) {
+
+		if f.Kind() != reflect.String || t != reflect.TypeOf(time.Duration(0)) {
+			return data, nil
 		}
-	}
 
-	if seconds > 0 {
-		if seconds == 1 {
-			parts = append(parts, "1 second")
-		} else {
-			parts = append(parts, fmt.Sprintf("%d seconds", seconds))
+		raw := strings.TrimSpace(data.(string))
+
+		if len(raw) == 0 || raw == "0" {
+			return time.Duration(0), nil
 		}
-	}
 
-	return strings.Join(parts, ", ")
+		return ParseDuration(raw)
+	}
 }
@@ -3,6 +3,8 @@ package common
 import (
 	"testing"
 	"time"
+
+	"github.com/go-viper/mapstructure/v2"
 )
 
 func TestValidateDuration(t *testing.T) {
@@ -209,17 +211,17 @@ func TestValidateDurationErrorMessages(t *testing.T) {
 		{
 			name:    "empty string error message",
 			input:   "",
-			wantErr: "invalid duration format: . Expect ISO 8601 or duration string",
+			wantErr: `invalid duration "", ` + DurationFormats,
 		},
 		{
 			name:    "invalid format error message",
 			input:   "invalid",
-			wantErr: "invalid duration format: invalid. Expect ISO 8601 or duration string",
+			wantErr: `invalid duration "invalid", ` + DurationFormats,
 		},
 		{
 			name:    "number without unit error message",
 			input:   "123",
-			wantErr: "invalid duration format: 123. Expect ISO 8601 or duration string",
+			wantErr: `invalid duration "123", ` + DurationFormats,
 		},
 		{
 			name:    "duration too short error message",
@@ -293,6 +295,171 @@ func TestValidateDurationWhitespaceHandling(t *testing.T) {
 	}
 }
 
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+	}{
+		// Go durations
+		{"30s", 30 * time.Second},
+		{"90m", 90 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"1.5h", 90 * time.Minute},
+		// ISO 8601
+		{"PT30S", 30 * time.Second},
+		{"PT2H", 2 * time.Hour},
+		{"P1D", 24 * time.Hour},
+		{"P1DT12H", 36 * time.Hour},
+		// Words
+		{"2 days", 48 * time.Hour},
+		{"90 minutes", 90 * time.Minute},
+		{"1 hour", time.Hour},
+		{"1 Hour 30 Minutes", 90 * time.Minute},
+		{"1 hour, 30 minutes", 90 * time.Minute},
+		{"1 hour and 30 minutes", 90 * time.Minute},
+		{"1.5 hours", 90 * time.Minute},
+		{"2 weeks", 14 * 24 * time.Hour},
+		{"45 secs", 45 * time.Second},
+		{"10 mins", 10 * time.Minute},
+		{"3 hrs", 3 * time.Hour},
+		{"1d 2h", 26 * time.Hour},
+		{"1h 23m", 83 * time.Minute},
+		{"  2 days  ", 48 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseDuration(tt.input)
+			if err != nil {
+				t.Fatalf("ParseDuration(%q) unexpected error: %v", tt.input, err)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseDuration(%q) = %v, expected %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseDurationRejectsZeroAndNegative(t *testing.T) {
+	inputs := []string{
+		"0", "0s", "0m", "PT0S", "PT", "P0D", "0 days", "0 hours 0 minutes",
+		"-5m", "-1h30m", "-2 days", "-PT1H",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			if result, err := ParseDuration(input); err == nil {
+				t.Errorf("ParseDuration(%q) = %v, expected error", input, result)
+			}
+		})
+	}
+}
+
+func TestParseDurationInvalid(t *testing.T) {
+	inputs := []string{
+		"", "   ", "invalid", "123", "10x", "P1", "2 fortnights", "days 2", "1 hour 30",
+		"99999999999999999999 weeks",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			if result, err := ParseDuration(input); err == nil {
+				t.Errorf("ParseDuration(%q) = %v, expected error", input, result)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		input    time.Duration
+		expected string
+	}{
+		{0, "0s"},
+		{500 * time.Millisecond, "0s"},
+		{45 * time.Second, "45s"},
+		{5 * time.Minute, "5m"},
+		{83 * time.Minute, "1h 23m"},
+		{83*time.Minute + 12*time.Second, "1h 23m 12s"},
+		{2 * time.Hour, "2h"},
+		{26 * time.Hour, "1d 2h"},
+		{10*24*time.Hour + time.Second, "10d 1s"},
+		{-90 * time.Minute, "-1h 30m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if result := FormatDuration(tt.input); result != tt.expected {
+				t.Errorf("FormatDuration(%v) = %q, expected %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatDurationRoundTrip(t *testing.T) {
+	inputs := []string{
+		// Go durations
+		"45s", "90m", "1h30m45s", "36h",
+		// ISO 8601
+		"PT5M", "PT1H30M45S", "P1D", "P1W", "P1DT12H30M",
+		// Words
+		"2 days", "90 minutes", "1 hour and 30 minutes", "1.5 hours", "2 weeks, 3 days",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			parsed, err := ParseDuration(input)
+			if err != nil {
+				t.Fatalf("ParseDuration(%q) unexpected error: %v", input, err)
+			}
+
+			formatted := FormatDuration(parsed)
+
+			reparsed, err := ParseDuration(formatted)
+			if err != nil {
+				t.Fatalf("ParseDuration(%q) of FormatDuration(%q) unexpected error: %v", formatted, input, err)
+			}
+
+			if reparsed != parsed {
+				t.Errorf("round trip of %q via %q = %v, expected %v", input, formatted, reparsed, parsed)
+			}
+		})
+	}
+}
+
+func TestStringToDurationHookFunc(t *testing.T) {
+	var config struct {
+		Timeout  time.Duration `mapstructure:"timeout"`
+		Disabled time.Duration `mapstructure:"disabled"`
+		Name     string        `mapstructure:"name"`
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: StringToDurationHookFunc(),
+		Result:     &config,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = decoder.Decode(map[string]any{
+		"timeout":  "2 minutes",
+		"disabled": "0",
+		"name":     "5m",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.Timeout != 2*time.Minute || config.Disabled != 0 || config.Name != "5m" {
+		t.Errorf("unexpected decoded config: %+v", config)
+	}
+
+	if err := decoder.Decode(map[string]any{"timeout": "soon"}); err == nil {
+		t.Error("expected error decoding invalid duration")
+	}
+}
+
 // Benchmark tests
 func BenchmarkValidateDurationGoFormat(b *testing.B) {
 	for i := 0; i < b.N; i++ {
@@ -0,0 +1,93 @@
+package common
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Fields every provider and task log line for a grant carries so the lines
+// for one workflow, and one operation within it, can be correlated
+const (
+	LogFieldProvider   = "provider"
+	LogFieldWorkflowID = "workflow_id"
+	LogFieldRequestID  = "request_id"
+)
+
+type logContextKey struct{}
+
+type logContext struct {
+	workflowID string
+	requestID  string
+}
+
+// NewRequestID returns an ID for a single operation e.g. authorizing a role
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// WithLogContext returns a context carrying the workflow and request IDs.
+// Empty values keep the IDs already in the context.
+func WithLogContext(ctx context.Context, workflowID, requestID string) context.Context {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	existingWorkflowID, existingRequestID := GetLogContext(ctx)
+
+	if len(workflowID) == 0 {
+		workflowID = existingWorkflowID
+	}
+
+	if len(requestID) == 0 {
+		requestID = existingRequestID
+	}
+
+	return context.WithValue(ctx, logContextKey{}, logContext{
+		workflowID: workflowID,
+		requestID:  requestID,
+	})
+}
+
+// GetLogContext returns the workflow and request IDs set with WithLogContext
+func GetLogContext(ctx context.Context) (workflowID string, requestID string) {
+
+	if ctx == nil {
+		return "", ""
+	}
+
+	if value, ok := ctx.Value(logContextKey{}).(logContext); ok {
+		return value.workflowID, value.requestID
+	}
+
+	return "", ""
+}
+
+// WithProviderContext returns a log entry for a provider operation. Every
+// provider and task executor should log through it so the lines for a grant
+// carry the provider, workflow and request IDs. A nil logger uses the
+// standard logger and empty IDs are left out.
+func WithProviderContext(logger logrus.FieldLogger, providerName, workflowID, requestID string) *logrus.Entry {
+
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+
+	fields := logrus.Fields{}
+
+	if len(providerName) > 0 {
+		fields[LogFieldProvider] = providerName
+	}
+
+	if len(workflowID) > 0 {
+		fields[LogFieldWorkflowID] = workflowID
+	}
+
+	if len(requestID) > 0 {
+		fields[LogFieldRequestID] = requestID
+	}
+
+	return logger.WithFields(fields)
+}
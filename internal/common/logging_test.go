@@ -0,0 +1,35 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLogContext(t *testing.T) {
+	ctx := WithLogContext(context.Background(), "wf-1", "req-1")
+
+	workflowID, requestID := GetLogContext(ctx)
+	assert.Equal(t, "wf-1", workflowID)
+	assert.Equal(t, "req-1", requestID)
+
+	// Empty values keep the existing IDs
+	ctx = WithLogContext(ctx, "", "req-2")
+
+	workflowID, requestID = GetLogContext(ctx)
+	assert.Equal(t, "wf-1", workflowID)
+	assert.Equal(t, "req-2", requestID)
+
+	workflowID, requestID = GetLogContext(context.Background())
+	assert.Empty(t, workflowID)
+	assert.Empty(t, requestID)
+}
+
+func TestWithProviderContext(t *testing.T) {
+	entry := WithProviderContext(nil, "aws", "wf-1", "")
+
+	assert.Equal(t, "aws", entry.Data[LogFieldProvider])
+	assert.Equal(t, "wf-1", entry.Data[LogFieldWorkflowID])
+	assert.NotContains(t, entry.Data, LogFieldRequestID)
+}
@@ -0,0 +1,209 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const RedactedValue = "[REDACTED]"
+
+// minSecretLength stops short config values e.g. "true" being masked
+// everywhere they appear
+const minSecretLength = 6
+
+// secretKeyPatterns mark config keys whose values are secrets
+var secretKeyPatterns = []string{
+	"token",
+	"secret",
+	"password",
+	"passphrase",
+	"private_key",
+	"account_key",
+	"api_key",
+	"apikey",
+	"credential",
+}
+
+// tokenPatterns match well known token formats wherever they appear
+var tokenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`xox[abposre]-[A-Za-z0-9-]+`),                         // Slack
+	regexp.MustCompile(`xapp-[A-Za-z0-9-]+`),                                 // Slack app
+	regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`),                 // Authorization headers
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),  // JWT
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[A-Z0-9]{16}\b`),                      // AWS access keys
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),                     // GitHub
+	regexp.MustCompile(`ya29\.[A-Za-z0-9_-]+`),                               // Google OAuth
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END`), // PEM keys
+}
+
+var emailPattern = regexp.MustCompile(`([A-Za-z0-9._%+-])[A-Za-z0-9._%+-]*@([A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+
+// Redactor masks tokens, secrets and optionally email addresses before
+// they are logged
+type Redactor struct {
+	mu           sync.RWMutex
+	redactEmails bool
+	secrets      map[string]struct{}
+	// Longest first so a secret containing another is masked whole
+	sortedSecrets []string
+}
+
+func NewRedactor(redactEmails bool) *Redactor {
+	return &Redactor{
+		redactEmails: redactEmails,
+		secrets:      map[string]struct{}{},
+	}
+}
+
+var defaultRedactor = NewRedactor(true)
+
+// GetRedactor returns the redactor used by the logging hook
+func GetRedactor() *Redactor {
+	return defaultRedactor
+}
+
+func (r *Redactor) SetRedactEmails(redactEmails bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.redactEmails = redactEmails
+}
+
+// RegisterSecret masks the values wherever they are logged
+func (r *Redactor) RegisterSecret(values ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if len(value) < minSecretLength {
+			continue
+		}
+		if _, found := r.secrets[value]; found {
+			continue
+		}
+		r.secrets[value] = struct{}{}
+		r.sortedSecrets = append(r.sortedSecrets, value)
+	}
+
+	sort.Slice(r.sortedSecrets, func(i, j int) bool {
+		return len(r.sortedSecrets[i]) > len(r.sortedSecrets[j])
+	})
+}
+
+// RegisterSecretsFromConfig registers the values of secret keys e.g.
+// bot_token or client_secret, from a config map and any nested maps
+func (r *Redactor) RegisterSecretsFromConfig(config map[string]any) {
+	for key, value := range config {
+		switch typed := value.(type) {
+		case map[string]any:
+			r.RegisterSecretsFromConfig(typed)
+		case string:
+			if IsSecretKey(key) {
+				r.RegisterSecret(typed)
+			}
+		}
+	}
+}
+
+// Redact masks the tokens, registered secrets and email addresses in value
+func (r *Redactor) Redact(value string) string {
+
+	if len(value) == 0 {
+		return value
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, secret := range r.sortedSecrets {
+		value = strings.ReplaceAll(value, secret, RedactedValue)
+	}
+
+	for _, pattern := range tokenPatterns {
+		value = pattern.ReplaceAllString(value, RedactedValue)
+	}
+
+	if r.redactEmails {
+		value = emailPattern.ReplaceAllString(value, "$1***@$2")
+	}
+
+	return value
+}
+
+// RedactFields returns a copy of the fields with secret keys masked and
+// string and error values redacted
+func (r *Redactor) RedactFields(fields logrus.Fields) logrus.Fields {
+
+	redacted := make(logrus.Fields, len(fields))
+
+	for key, value := range fields {
+
+		if IsSecretKey(key) {
+			redacted[key] = RedactedValue
+			continue
+		}
+
+		switch typed := value.(type) {
+		case string:
+			redacted[key] = r.Redact(typed)
+		case []string:
+			values := make([]string, len(typed))
+			for i, item := range typed {
+				values[i] = r.Redact(item)
+			}
+			redacted[key] = values
+		case error:
+			// Only replace errors that contain something to mask so error
+			// fields keep their type otherwise
+			if message := typed.Error(); r.Redact(message) != message {
+				redacted[key] = fmt.Errorf("%s", r.Redact(message))
+			} else {
+				redacted[key] = value
+			}
+		default:
+			redacted[key] = value
+		}
+	}
+
+	return redacted
+}
+
+// IsSecretKey returns true if values under the config key are secrets
+func IsSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range secretKeyPatterns {
+		if strings.Contains(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact masks value with the default redactor
+func Redact(value string) string {
+	return defaultRedactor.Redact(value)
+}
+
+// RedactionHook redacts every log entry before it is formatted or exported
+type RedactionHook struct {
+	Redactor *Redactor
+}
+
+func NewRedactionHook() *RedactionHook {
+	return &RedactionHook{Redactor: defaultRedactor}
+}
+
+func (h *RedactionHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *RedactionHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.Redactor.Redact(entry.Message)
+	entry.Data = h.Redactor.RedactFields(entry.Data)
+	return nil
+}
@@ -1,8 +1,31 @@
 package common
 
-import "strings"
+import (
+	"strings"
+	"unicode/utf8"
+)
 
 // Helper function to check if a string contains a substring (case-insensitive)
 func ContainsInsensitive(s, substr string) bool {
 	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
 }
+
+// TruncateBytes shortens s to at most maxBytes bytes without splitting a
+// multi-byte character, which APIs reject as invalid UTF-8
+func TruncateBytes(s string, maxBytes int) string {
+
+	if len(s) <= maxBytes {
+		return s
+	}
+
+	end := 0
+	for end < len(s) {
+		_, size := utf8.DecodeRuneInString(s[end:])
+		if end+size > maxBytes {
+			break
+		}
+		end += size
+	}
+
+	return s[:end]
+}
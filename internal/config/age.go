@@ -0,0 +1,104 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/spf13/viper"
+)
+
+// AgeConfigExtension marks a config file encrypted with age
+const AgeConfigExtension = ".age"
+
+// AgeKeyEnv holds the age identities used to decrypt the config file when
+// no key file is given
+const AgeKeyEnv = "THAND_AGE_KEY"
+
+// isAgeConfigFile returns true if the config file is age encrypted
+func isAgeConfigFile(configFile string) bool {
+	return strings.EqualFold(filepath.Ext(configFile), AgeConfigExtension)
+}
+
+// readAgeConfig decrypts the config file and reads the YAML inside it
+func readAgeConfig(v *viper.Viper, configFile string, keyFile string) error {
+
+	identities, err := getAgeIdentities(keyFile)
+
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(configFile)
+
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+	defer file.Close()
+
+	plaintext, err := decryptAge(file, identities...)
+
+	if err != nil {
+		return fmt.Errorf("failed to decrypt config file %s: %w", configFile, err)
+	}
+
+	v.SetConfigType("yaml")
+
+	if err := v.ReadConfig(plaintext); err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+
+	return nil
+}
+
+// getAgeIdentities parses the identities from the key file, or from the
+// THAND_AGE_KEY environment variable
+func getAgeIdentities(keyFile string) ([]age.Identity, error) {
+
+	var keys io.Reader
+
+	if len(keyFile) > 0 {
+
+		file, err := os.Open(keyFile)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to open age key file: %w", err)
+		}
+		defer file.Close()
+
+		keys = file
+
+	} else if key := os.Getenv(AgeKeyEnv); len(key) > 0 {
+
+		keys = strings.NewReader(key)
+
+	} else {
+		return nil, fmt.Errorf(
+			"config file is age encrypted, set %s or --age-key-file to decrypt it", AgeKeyEnv)
+	}
+
+	identities, err := age.ParseIdentities(keys)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse age key: %w", err)
+	}
+
+	return identities, nil
+}
+
+// decryptAge decrypts binary or ASCII armored age files
+func decryptAge(src io.Reader, identities ...age.Identity) (io.Reader, error) {
+
+	buffered := bufio.NewReader(src)
+
+	if header, _ := buffered.Peek(len(armor.Header)); string(header) == armor.Header {
+		return age.Decrypt(armor.NewReader(buffered), identities...)
+	}
+
+	return age.Decrypt(buffered, identities...)
+}
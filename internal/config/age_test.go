@@ -0,0 +1,83 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAgeConfig(t *testing.T) {
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	encrypt := func(t *testing.T, armored bool) string {
+		path := filepath.Join(t.TempDir(), "config.yaml.age")
+
+		file, err := os.Create(path)
+		require.NoError(t, err)
+		defer file.Close()
+
+		var out io.WriteCloser = file
+		if armored {
+			out = armor.NewWriter(file)
+			defer out.Close()
+		}
+
+		w, err := age.Encrypt(out, identity.Recipient())
+		require.NoError(t, err)
+		_, err = io.WriteString(w, "logging:\n  level: debug\n")
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		return path
+	}
+
+	load := func(path string, options LoadOptions) (*Config, error) {
+		v := newViper()
+		setDefaults(v)
+		v.SetConfigFile(path)
+		return readAndUnmarshalConfig(v, options)
+	}
+
+	t.Run("key from environment", func(t *testing.T) {
+		t.Setenv(AgeKeyEnv, identity.String())
+
+		config, err := load(encrypt(t, false), LoadOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "debug", config.Logging.Level)
+	})
+
+	t.Run("key file", func(t *testing.T) {
+		t.Setenv(AgeKeyEnv, "")
+
+		keyFile := filepath.Join(t.TempDir(), "thand.key")
+		require.NoError(t, os.WriteFile(keyFile, []byte(identity.String()+"\n"), 0o600))
+
+		config, err := load(encrypt(t, true), LoadOptions{AgeKeyFile: keyFile})
+		require.NoError(t, err)
+		assert.Equal(t, "debug", config.Logging.Level)
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		t.Setenv(AgeKeyEnv, "")
+
+		_, err := load(encrypt(t, false), LoadOptions{})
+		assert.ErrorContains(t, err, AgeKeyEnv)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		other, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+		t.Setenv(AgeKeyEnv, other.String())
+
+		_, err = load(encrypt(t, false), LoadOptions{})
+		assert.ErrorContains(t, err, "failed to decrypt config file")
+	})
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// CheckAgentPermissions validates the agent's own permissions for every
+// initialized RBAC provider, logs a summary and keeps the results for the
+// readiness check
+func (c *Config) CheckAgentPermissions(ctx context.Context) map[string]*models.AgentPermissionsResult {
+
+	defs := c.GetProviders().Definitions
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	results := map[string]*models.AgentPermissionsResult{}
+
+	for providerKey, provider := range defs {
+
+		client := provider.GetClient()
+
+		if client == nil || !client.HasCapability(models.ProviderCapabilityRBAC) {
+			continue
+		}
+
+		wg.Go(func() {
+			permissions, err := client.ValidateAgentPermissions(ctx)
+			result := models.NewAgentPermissionsResult(providerKey, permissions, err)
+
+			mu.Lock()
+			results[providerKey] = result
+			mu.Unlock()
+		})
+	}
+
+	wg.Wait()
+
+	counts := map[models.AgentPermissionsStatus]int{}
+
+	for _, providerKey := range slices.Sorted(maps.Keys(results)) {
+
+		result := results[providerKey]
+		counts[result.Status]++
+
+		if result.HasProblem() {
+			logrus.WithFields(logrus.Fields{
+				"provider": providerKey,
+				"status":   result.Status,
+			}).Warnln("Agent permissions check failed:", result.GetSummary())
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"ok":          counts[models.AgentPermissionsStatusOK],
+		"missing":     counts[models.AgentPermissionsStatusMissing],
+		"error":       counts[models.AgentPermissionsStatusError],
+		"unsupported": counts[models.AgentPermissionsStatusUnsupported],
+	}).Infoln("Checked agent permissions for", len(results), "providers")
+
+	c.agentPermissionsMu.Lock()
+	c.agentPermissions = results
+	c.agentPermissionsMu.Unlock()
+
+	return results
+}
+
+// GetAgentPermissions returns the results of the last agent permissions
+// check ordered by provider, or nil if it hasn't finished
+func (c *Config) GetAgentPermissions() []*models.AgentPermissionsResult {
+
+	c.agentPermissionsMu.Lock()
+	defer c.agentPermissionsMu.Unlock()
+
+	if c.agentPermissions == nil {
+		return nil
+	}
+
+	results := make([]*models.AgentPermissionsResult, 0, len(c.agentPermissions))
+
+	for _, providerKey := range slices.Sorted(maps.Keys(c.agentPermissions)) {
+		results = append(results, c.agentPermissions[providerKey])
+	}
+
+	return results
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+type agentPermissionsProvider struct {
+	*models.BaseProvider
+	permissions []models.AgentPermission
+	err         error
+}
+
+func (p *agentPermissionsProvider) ValidateAgentPermissions(ctx context.Context) ([]models.AgentPermission, error) {
+	return p.permissions, p.err
+}
+
+func TestCheckAgentPermissions(t *testing.T) {
+
+	newProvider := func(name string, client *agentPermissionsProvider, capabilities ...models.ProviderCapability) models.Provider {
+		provider := models.Provider{Name: name, Provider: name, Enabled: true}
+		client.BaseProvider = models.NewBaseProvider(name, provider, capabilities...)
+		provider.SetClient(client)
+		return provider
+	}
+
+	cfg := &Config{Providers: ProviderConfig{
+		Definitions: map[string]models.Provider{
+			"aws": newProvider("aws", &agentPermissionsProvider{
+				permissions: []models.AgentPermission{
+					{Permission: "iam:GetRole", Allowed: true},
+					{Permission: "iam:CreateRole", Allowed: false},
+				},
+			}, models.ProviderCapabilityRBAC),
+			"gcp": newProvider("gcp", &agentPermissionsProvider{
+				err: errors.New("permission denied"),
+			}, models.ProviderCapabilityRBAC),
+			"okta": newProvider("okta", &agentPermissionsProvider{
+				err: models.ErrNotImplemented,
+			}, models.ProviderCapabilityRBAC),
+			// Providers that can't grant roles aren't checked
+			"slack": newProvider("slack", &agentPermissionsProvider{
+				err: errors.New("unexpected check"),
+			}, models.ProviderCapabilityNotifier),
+		},
+	}}
+
+	assert.Nil(t, cfg.GetAgentPermissions())
+
+	results := cfg.CheckAgentPermissions(context.Background())
+	require.Len(t, results, 3)
+
+	assert.Equal(t, models.AgentPermissionsStatusMissing, results["aws"].Status)
+	assert.Equal(t, models.AgentPermissionsStatusError, results["gcp"].Status)
+	assert.Equal(t, models.AgentPermissionsStatusUnsupported, results["okta"].Status)
+
+	stored := cfg.GetAgentPermissions()
+	require.Len(t, stored, 3)
+	assert.Equal(t, "aws", stored[0].Provider)
+	assert.Equal(t, "gcp", stored[1].Provider)
+	assert.Equal(t, "okta", stored[2].Provider)
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+const (
+	// authorizationHookFunction is the global the hook script must define
+	authorizationHookFunction = "authorize"
+
+	// authorizationHookTimeout stops a hook that doesn't return holding up
+	// the grant
+	authorizationHookTimeout = 5 * time.Second
+)
+
+// ErrAuthorizationHookDenied is returned when the authorization hook
+// denies a grant
+var ErrAuthorizationHookDenied = errors.New("denied by the authorization hook")
+
+// LoadAuthorizationHook reads and compiles the Lua authorization hook so a
+// script that doesn't parse is caught when the config is loaded
+func (c *Config) LoadAuthorizationHook() (*lua.FunctionProto, error) {
+
+	if len(c.AuthorizationHook) == 0 {
+		return nil, nil
+	}
+
+	file, err := os.Open(c.AuthorizationHook)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", c.AuthorizationHook, err)
+	}
+
+	defer file.Close()
+
+	chunk, err := parse.Parse(file, c.AuthorizationHook)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", c.AuthorizationHook, err)
+	}
+
+	proto, err := lua.Compile(chunk, c.AuthorizationHook)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile %s: %w", c.AuthorizationHook, err)
+	}
+
+	return proto, nil
+}
+
+// RunAuthorizationHook calls the hook's authorize function with the user,
+// role and request before a role is granted. The function returns true to
+// allow the grant, or false and a message to deny it. A hook that fails to
+// run also denies the grant. Without a hook every grant is allowed.
+func (c *Config) RunAuthorizationHook(
+	ctx context.Context,
+	provider string,
+	req *models.AuthorizeRoleRequest,
+) error {
+
+	// Take the hook under the lock as reloads replace it, but don't hold the
+	// lock while the script runs
+	c.mu.RLock()
+	hook := c.authorizationHook
+	c.mu.RUnlock()
+
+	if hook == nil {
+		return nil
+	}
+
+	if !req.IsValid() {
+		return fmt.Errorf("user and role must be provided to run the authorization hook")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, authorizationHookTimeout)
+	defer cancel()
+
+	L := newAuthorizationHookState()
+	defer L.Close()
+
+	L.SetContext(ctx)
+
+	L.Push(L.NewFunctionFromProto(hook))
+
+	if err := L.PCall(0, lua.MultRet, nil); err != nil {
+		return fmt.Errorf("authorization hook failed to load: %w", err)
+	}
+
+	authorize, ok := L.GetGlobal(authorizationHookFunction).(*lua.LFunction)
+
+	if !ok {
+		return fmt.Errorf("authorization hook must define the %s function", authorizationHookFunction)
+	}
+
+	user, err := toLuaTable(L, req.GetUser())
+
+	if err != nil {
+		return fmt.Errorf("failed to convert user for the authorization hook: %w", err)
+	}
+
+	role, err := toLuaTable(L, req.GetRole())
+
+	if err != nil {
+		return fmt.Errorf("failed to convert role for the authorization hook: %w", err)
+	}
+
+	// The os library isn't available so the time is passed in
+	now, err := toLuaTable(L, models.NewPolicyTime(time.Now()))
+
+	if err != nil {
+		return fmt.Errorf("failed to convert time for the authorization hook: %w", err)
+	}
+
+	request := L.NewTable()
+	request.RawSetString("time", now)
+	request.RawSetString("provider", lua.LString(provider))
+	request.RawSetString("identity", lua.LString(req.GetUser().GetIdentity()))
+
+	if req.Duration != nil {
+		request.RawSetString("duration", lua.LNumber(req.Duration.Seconds()))
+	}
+
+	if audit := req.GetAudit(); audit != nil {
+		request.RawSetString("requester", lua.LString(audit.Requester))
+		request.RawSetString("workflow_id", lua.LString(audit.WorkflowID))
+		request.RawSetString("reason", lua.LString(audit.Reason))
+	}
+
+	err = L.CallByParam(lua.P{
+		Fn:      authorize,
+		NRet:    2,
+		Protect: true,
+	}, user, role, request)
+
+	if err != nil {
+		return fmt.Errorf("authorization hook failed: %w", err)
+	}
+
+	allowed, message := L.Get(-2), L.Get(-1)
+	L.Pop(2)
+
+	logrus.WithFields(logrus.Fields{
+		"provider": provider,
+		"identity": req.GetUser().GetIdentity(),
+		"role":     req.GetRole().GetName(),
+		"allowed":  lua.LVAsBool(allowed),
+	}).Debugln("Ran authorization hook")
+
+	if lua.LVAsBool(allowed) {
+		return nil
+	}
+
+	if message.Type() == lua.LTString && len(strings.TrimSpace(message.String())) > 0 {
+		return fmt.Errorf("%w: %s", ErrAuthorizationHookDenied, message.String())
+	}
+
+	return ErrAuthorizationHookDenied
+}
+
+// newAuthorizationHookState returns a Lua state with the base, table,
+// string and math libraries. The os and io libraries aren't opened and
+// files can't be loaded so hooks can only use what they're passed.
+func newAuthorizationHookState() *lua.LState {
+
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+
+	for _, name := range []string{"dofile", "loadfile"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+
+	return L
+}
+
+// toLuaTable converts the value to a Lua table using its JSON fields
+func toLuaTable(L *lua.LState, value any) (*lua.LTable, error) {
+
+	fields, err := common.ConvertInterfaceToMap(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return toLuaValue(L, fields).(*lua.LTable), nil
+}
+
+// toLuaValue converts a decoded JSON value to its Lua equivalent
+func toLuaValue(L *lua.LState, value any) lua.LValue {
+
+	switch v := value.(type) {
+	case nil:
+		return lua.LNil
+	case bool:
+		return lua.LBool(v)
+	case string:
+		return lua.LString(v)
+	case float64:
+		return lua.LNumber(v)
+	case int:
+		return lua.LNumber(v)
+	case int64:
+		return lua.LNumber(v)
+	case []any:
+		table := L.NewTable()
+		for _, item := range v {
+			table.Append(toLuaValue(L, item))
+		}
+		return table
+	case []string:
+		table := L.NewTable()
+		for _, item := range v {
+			table.Append(lua.LString(item))
+		}
+		return table
+	case map[string]any:
+		table := L.NewTable()
+		for key, item := range v {
+			table.RawSetString(key, toLuaValue(L, item))
+		}
+		return table
+	default:
+		return lua.LString(fmt.Sprintf("%v", v))
+	}
+}
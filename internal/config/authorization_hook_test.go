@@ -0,0 +1,152 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func newAuthorizationHookConfig(t *testing.T, script string) *Config {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "hook.lua")
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o600))
+
+	config := &Config{AuthorizationHook: path}
+
+	hook, err := config.LoadAuthorizationHook()
+	require.NoError(t, err)
+
+	config.authorizationHook = hook
+
+	return config
+}
+
+func TestRunAuthorizationHook(t *testing.T) {
+
+	duration := time.Hour
+
+	request := &models.AuthorizeRoleRequest{
+		RoleRequest: &models.RoleRequest{
+			User: &models.User{
+				Email:  "alice@example.com",
+				Groups: []string{"sre", "oncall"},
+			},
+			Role:     &models.Role{Name: "prod-admin"},
+			Duration: &duration,
+			Audit:    &models.AuditContext{Reason: "INC-42 database failover"},
+		},
+	}
+
+	t.Run("no hook allows every grant", func(t *testing.T) {
+		assert.NoError(t, (&Config{}).RunAuthorizationHook(context.Background(), "aws-prod", request))
+	})
+
+	t.Run("hook allows the grant", func(t *testing.T) {
+		config := newAuthorizationHookConfig(t, `
+function authorize(user, role, request)
+  local oncall = false
+  for _, group in ipairs(user.groups) do
+    if group == "oncall" then oncall = true end
+  end
+  return oncall and role.name == "prod-admin" and request.provider == "aws-prod"
+    and request.duration == 3600 and string.find(request.reason, "INC%-") ~= nil
+    and request.time.hour >= 0 and request.time.weekday ~= nil
+end
+`)
+		assert.NoError(t, config.RunAuthorizationHook(context.Background(), "aws-prod", request))
+	})
+
+	t.Run("hook denies the grant with a message", func(t *testing.T) {
+		config := newAuthorizationHookConfig(t, `
+function authorize(user, role, request)
+  return false, role.name .. " can't be granted outside the deployment window"
+end
+`)
+		err := config.RunAuthorizationHook(context.Background(), "aws-prod", request)
+		assert.ErrorIs(t, err, ErrAuthorizationHookDenied)
+		assert.EqualError(t, err, "denied by the authorization hook: prod-admin can't be granted outside the deployment window")
+	})
+
+	t.Run("hook denies the grant without a message", func(t *testing.T) {
+		config := newAuthorizationHookConfig(t, `function authorize() return nil end`)
+		err := config.RunAuthorizationHook(context.Background(), "aws-prod", request)
+		assert.Equal(t, ErrAuthorizationHookDenied, err)
+	})
+
+	t.Run("hook errors deny the grant", func(t *testing.T) {
+		config := newAuthorizationHookConfig(t, `function authorize() error("lookup failed") end`)
+		err := config.RunAuthorizationHook(context.Background(), "aws-prod", request)
+		assert.ErrorContains(t, err, "authorization hook failed")
+		assert.ErrorContains(t, err, "lookup failed")
+	})
+
+	t.Run("hook must define authorize", func(t *testing.T) {
+		config := newAuthorizationHookConfig(t, `local allowed = true`)
+		err := config.RunAuthorizationHook(context.Background(), "aws-prod", request)
+		assert.EqualError(t, err, "authorization hook must define the authorize function")
+	})
+
+	t.Run("hook can't use the os library", func(t *testing.T) {
+		config := newAuthorizationHookConfig(t, `function authorize() return os.getenv("HOME") ~= nil end`)
+		err := config.RunAuthorizationHook(context.Background(), "aws-prod", request)
+		assert.ErrorContains(t, err, "authorization hook failed")
+	})
+
+	t.Run("hook is stopped when the context is done", func(t *testing.T) {
+		config := newAuthorizationHookConfig(t, `function authorize() while true do end end`)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := config.RunAuthorizationHook(ctx, "aws-prod", request)
+		assert.ErrorContains(t, err, "authorization hook failed")
+	})
+
+	// Run with -race, reloads replace the hook while grants are running
+	t.Run("hook can be reloaded while it runs", func(t *testing.T) {
+		config := newAuthorizationHookConfig(t, `function authorize() return true end`)
+
+		reloaded, err := config.LoadAuthorizationHook()
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+
+		wg.Go(func() {
+			for range 50 {
+				config.mu.Lock()
+				config.authorizationHook = reloaded
+				config.mu.Unlock()
+			}
+		})
+
+		for range 50 {
+			assert.NoError(t, config.RunAuthorizationHook(context.Background(), "aws-prod", request))
+		}
+
+		wg.Wait()
+	})
+}
+
+func TestLoadAuthorizationHook(t *testing.T) {
+
+	hook, err := (&Config{}).LoadAuthorizationHook()
+	require.NoError(t, err)
+	assert.Nil(t, hook)
+
+	path := filepath.Join(t.TempDir(), "hook.lua")
+	require.NoError(t, os.WriteFile(path, []byte(`function authorize( return true end`), 0o600))
+
+	_, err = (&Config{AuthorizationHook: path}).LoadAuthorizationHook()
+	assert.ErrorContains(t, err, "failed to parse")
+
+	_, err = (&Config{AuthorizationHook: filepath.Join(t.TempDir(), "missing.lua")}).LoadAuthorizationHook()
+	assert.ErrorContains(t, err, "failed to read")
+}
@@ -118,6 +118,70 @@ func TestReadData_JSONInput(t *testing.T) {
 	assert.Equal(t, "Test", workflow.Name)
 }
 
+// TestReadData_RoleYAMLAnchors tests roles can share definitions with YAML
+// anchors, aliases and merge keys
+func TestReadData_RoleYAMLAnchors(t *testing.T) {
+	yamlInput := `version: "1.0"
+x-permissions:
+  read-only: &read-only
+    - s3:GetObject
+    - s3:ListBucket
+roles:
+  base: &base-role
+    name: Base
+    description: Shared settings
+    authenticators: [google]
+    workflows: [slack_approval]
+    providers: [aws-prod]
+    max_duration: 4h
+    permissions:
+      allow: *read-only
+    enabled: true
+  developer:
+    <<: *base-role
+    name: Developer
+  operator:
+    <<: [*base-role]
+    name: Operator
+    providers: [aws-dev]
+    permissions:
+      allow: [ec2:StartInstances]
+      deny: *read-only`
+
+	var definition models.RoleDefinitions
+	result, err := common.ReadDataToInterface([]byte(yamlInput), definition)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+
+	assert.Len(t, result.Roles, 3)
+
+	developer, exists := result.Roles["developer"]
+	require.True(t, exists)
+	assert.Equal(t, "Developer", developer.Name)
+	assert.Equal(t, "Shared settings", developer.Description)
+	assert.Equal(t, []string{"google"}, developer.Authenticators)
+	assert.Equal(t, []string{"slack_approval"}, developer.Workflows)
+	assert.Equal(t, []string{"aws-prod"}, developer.Providers)
+	assert.Equal(t, "4h", developer.MaxDuration)
+	assert.Equal(t, []string{"s3:GetObject", "s3:ListBucket"}, developer.Permissions.Allow)
+	assert.True(t, developer.Enabled)
+
+	// Keys set on the role override the merged ones, nested maps are
+	// replaced rather than merged
+	operator, exists := result.Roles["operator"]
+	require.True(t, exists)
+	assert.Equal(t, "Operator", operator.Name)
+	assert.Equal(t, "Shared settings", operator.Description)
+	assert.Equal(t, []string{"aws-dev"}, operator.Providers)
+	assert.Equal(t, []string{"ec2:StartInstances"}, operator.Permissions.Allow)
+	assert.Equal(t, []string{"s3:GetObject", "s3:ListBucket"}, operator.Permissions.Deny)
+
+	// The anchored role is still a role of its own
+	base, exists := result.Roles["base"]
+	require.True(t, exists)
+	assert.Equal(t, "Base", base.Name)
+}
+
 func TestReadData_InvalidYAML(t *testing.T) {
 	invalidYAML := `on:
   test: true
@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,12 +11,15 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"runtime/debug"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/go-resty/resty/v2"
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/serverlessworkflow/sdk-go/v3/model"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -23,7 +27,10 @@ import (
 
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config/environment"
+	"github.com/thand-io/agent/internal/metrics"
+	"github.com/thand-io/agent/internal/models"
 	"github.com/thand-io/agent/internal/sessions"
+	"github.com/thand-io/agent/internal/siem"
 )
 
 var ErrNoActiveLoginSession = fmt.Errorf(
@@ -31,7 +38,7 @@ var ErrNoActiveLoginSession = fmt.Errorf(
 
 func DefaultConfig() *Config {
 
-	v := viper.New()
+	v := newViper()
 
 	// Set default values
 	setDefaults(v)
@@ -44,13 +51,72 @@ func DefaultConfig() *Config {
 	return &config
 }
 
+// newViper returns a viper instance that decodes durations with
+// common.ParseDuration so config accepts the same formats as requests
+func newViper() *viper.Viper {
+	return viper.NewWithOptions(viper.WithDecodeHook(
+		mapstructure.ComposeDecodeHookFunc(
+			common.StringToDurationHookFunc(),
+			stringToSliceHookFunc(","),
+			timeToStringHookFunc(),
+		),
+	))
+}
+
+// timeToStringHookFunc turns YAML timestamps back into strings for string
+// fields e.g. freeze windows. Unquoted dates are parsed as midnight UTC so
+// are returned as dates.
+func timeToStringHookFunc() mapstructure.DecodeHookFunc {
+	return func(f reflect.Type, t reflect.Type, data any) (any, error) {
+		value, ok := data.(time.Time)
+		if !ok || t.Kind() != reflect.String {
+			return data, nil
+		}
+
+		if value.Location() == time.UTC && value.Equal(value.Truncate(24*time.Hour)) {
+			return value.Format("2006-01-02"), nil
+		}
+
+		return value.Format(time.RFC3339), nil
+	}
+}
+
+// stringToSliceHookFunc splits strings into slices, matching the default
+// viper decode hook
+func stringToSliceHookFunc(sep string) mapstructure.DecodeHookFunc {
+	return func(f reflect.Type, t reflect.Type, data any) (any, error) {
+		if f.Kind() != reflect.String || t.Kind() != reflect.Slice {
+			return data, nil
+		}
+
+		raw := data.(string)
+		if len(raw) == 0 {
+			return []string{}, nil
+		}
+
+		return strings.Split(raw, sep), nil
+	}
+}
+
+// LoadOptions are the optional settings for loading the configuration
+type LoadOptions struct {
+	AgeKeyFile string // Identity file to decrypt .age config files with
+}
+
 // Load loads the configuration from various sources
 func Load(configFile string) (*Config, error) {
+	return LoadWithOptions(configFile, LoadOptions{})
+}
+
+// LoadWithOptions loads the configuration from various sources. Config
+// files ending in .age are decrypted with the age key file, or the key in
+// THAND_AGE_KEY, before they are parsed.
+func LoadWithOptions(configFile string, options LoadOptions) (*Config, error) {
 	if err := loadEnvFile(); err != nil {
 		return nil, err
 	}
 
-	v := viper.New()
+	v := newViper()
 
 	if err := setupViperConfig(v, configFile); err != nil {
 		return nil, err
@@ -58,7 +124,7 @@ func Load(configFile string) (*Config, error) {
 
 	bindEnvironmentVariables(v)
 
-	config, err := readAndUnmarshalConfig(v)
+	config, err := readAndUnmarshalConfig(v, options)
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +133,12 @@ func Load(configFile string) (*Config, error) {
 		return nil, err
 	}
 
+	metrics.Configure(config.Server.Metrics.Namespace)
+
+	if err := siem.Configure(&config.Logging.Syslog); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
@@ -145,6 +217,7 @@ func bindEnvironmentVariables(v *viper.Viper) {
 	v.BindEnv("thand.endpoint", "THAND_ENDPOINT")
 	v.BindEnv("thand.base", "THAND_BASE_PATH")
 	v.BindEnv("thand.api_key", "THAND_API_KEY")
+	v.BindEnv("sync.conflict_policy", "THAND_SYNC_CONFLICT_POLICY")
 
 	// Set base environment variables
 	v.BindEnv("login.endpoint", "THAND_LOGIN_ENDPOINT")
@@ -200,6 +273,8 @@ func bindLoggingEnvVars(v *viper.Viper) {
 	v.BindEnv("logging.level", "THAND_LOGGING_LEVEL")
 	v.BindEnv("logging.format", "THAND_LOGGING_FORMAT")
 	v.BindEnv("logging.output", "THAND_LOGGING_OUTPUT")
+	v.BindEnv("audit_log.stream", "THAND_AUDIT_LOG_STREAM")
+	v.BindEnv("audit_log.path", "THAND_AUDIT_LOG_PATH")
 }
 
 // bindServiceEnvVars binds service configuration environment variables
@@ -219,23 +294,44 @@ func bindServiceEnvVars(v *viper.Viper) {
 }
 
 // readAndUnmarshalConfig reads the configuration file and unmarshals it
-func readAndUnmarshalConfig(v *viper.Viper) (*Config, error) {
+func readAndUnmarshalConfig(v *viper.Viper, options LoadOptions) (*Config, error) {
 	// Read configuration file
-	if err := v.ReadInConfig(); err != nil {
+	if configFile := v.ConfigFileUsed(); isAgeConfigFile(configFile) {
+		if err := readAgeConfig(v, configFile, options.AgeKeyFile); err != nil {
+			return nil, err
+		}
+	} else if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, fmt.Errorf("error reading config file: %w", err)
 		}
 		// Config file not found; proceed with defaults and environment variables
 	}
 
+	applyLogShorthand(v)
+
 	var config Config
 	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 
+	config.configFile = v.ConfigFileUsed()
+
 	return &config, nil
 }
 
+// applyLogShorthand accepts log.level and log.format as a shorthand for
+// the logging section. The logging keys and environment variables win.
+func applyLogShorthand(v *viper.Viper) {
+	for _, key := range []string{"level", "format"} {
+		if v.IsSet("log." + key) {
+			v.SetDefault("logging."+key, v.Get("log."+key))
+		}
+	}
+}
+
+// addRedactionHook adds the hook once as the config can be loaded again
+var addRedactionHook sync.Once
+
 // setupLogging configures the logging system based on the config
 func setupLogging(config *Config, v *viper.Viper) error {
 	// Set logging level
@@ -245,6 +341,16 @@ func setupLogging(config *Config, v *viper.Viper) error {
 	}
 
 	logrus.SetLevel(logrusLevel)
+
+	// Mask tokens, secrets and emails before the entry is formatted or
+	// exported, so the redaction hook must be added first
+	common.GetRedactor().SetRedactEmails(config.Logging.RedactEmails)
+	common.GetRedactor().RegisterSecretsFromConfig(v.AllSettings())
+
+	addRedactionHook.Do(func() {
+		logrus.AddHook(common.NewRedactionHook())
+	})
+
 	config.logger = *NewThandLogger()
 	logrus.AddHook(&config.logger)
 
@@ -276,6 +382,9 @@ func (c *Config) ReloadConfig() error {
 	var wg sync.WaitGroup
 	var foundErrors []error
 
+	// Secrets may have been rotated so read them again
+	models.ClearSecretCache()
+
 	// Load roles in parallel
 	wg.Go(func() {
 		roles, err := c.LoadRoles()
@@ -330,9 +439,59 @@ func (c *Config) ReloadConfig() error {
 		}
 	})
 
+	// Compile policies in parallel
+	wg.Go(func() {
+		policies, err := c.LoadPolicies()
+		if err != nil {
+			logrus.WithError(err).Errorln("Error loading policies")
+			c.mu.Lock()
+			foundErrors = append(foundErrors, fmt.Errorf("loading policies: %w", err))
+			c.mu.Unlock()
+		} else if len(policies) > 0 {
+			logrus.Infoln("Loaded policies:", len(policies))
+			c.mu.Lock()
+			c.Policies.prepared = policies
+			c.mu.Unlock()
+		}
+	})
+
+	// Compile the authorization hook in parallel
+	wg.Go(func() {
+		hook, err := c.LoadAuthorizationHook()
+		if err != nil {
+			logrus.WithError(err).Errorln("Error loading authorization hook")
+			c.mu.Lock()
+			foundErrors = append(foundErrors, fmt.Errorf("loading authorization hook: %w", err))
+			c.mu.Unlock()
+		} else {
+			if hook != nil {
+				logrus.Infoln("Loaded authorization hook:", c.AuthorizationHook)
+			}
+			c.mu.Lock()
+			c.authorizationHook = hook
+			c.mu.Unlock()
+		}
+	})
+
 	// Wait for all goroutines to complete
 	wg.Wait()
 
+	if err := c.Freeze.Validate(); err != nil {
+		foundErrors = append(foundErrors, fmt.Errorf("loading freeze calendars: %w", err))
+	}
+
+	if err := c.Reconcile.Validate(); err != nil {
+		foundErrors = append(foundErrors, err)
+	}
+
+	if err := c.ApplyWorkflowTemplates(); err != nil {
+		foundErrors = append(foundErrors, fmt.Errorf("expanding workflow templates: %w", err))
+	}
+
+	if err := c.ValidateWorkflowVars(); err != nil {
+		foundErrors = append(foundErrors, err)
+	}
+
 	// Return first error if any occurred
 	if len(foundErrors) > 0 {
 		return errors.Join(foundErrors...)
@@ -356,7 +515,7 @@ func (c *Config) HasLoginServer() bool {
 	return len(c.Login.Endpoint) > 0
 }
 
-func (c *Config) SyncWithLoginServer() error {
+func (c *Config) SyncWithLoginServer(ctx context.Context) error {
 
 	if len(c.Login.Endpoint) == 0 {
 		return fmt.Errorf("no login server endpoint configured")
@@ -382,12 +541,23 @@ func (c *Config) SyncWithLoginServer() error {
 
 	} else {
 
+		// Renew sessions that are about to expire before picking one
+		unrenewable := c.renewLoginSessions(ctx, loginServer)
+
+		loginServer, err = sessionManager.GetLoginServer(c.GetLoginServerHostname())
+
+		if err != nil {
+			return fmt.Errorf("failed to get login server session: %w", err)
+		}
+
 		logrus.Debugf("Looking for valid session to sync with login server at: %s", c.GetLoginServerUrl())
 		localSessions := loginServer.GetSessions()
 
-		// Find the first non-expired session token
+		// Find the first non-expired session token. Sessions that can't be
+		// renewed are skipped so the user logs in now rather than when the
+		// session expires part way through a command.
 		for providerName, session := range localSessions {
-			if !session.IsExpired() {
+			if !session.IsExpired() && !unrenewable[providerName] {
 
 				logrus.Debugf("Found valid session for provider '%s'", providerName)
 				localToken = session.GetEncodedLocalSession()
@@ -473,7 +643,7 @@ func (c *Config) SyncWithLoginServer() error {
 	}
 
 	// Now lets initialize our providers
-	err = c.InitializeProviders()
+	err = c.InitializeProviders(ctx)
 
 	if err != nil {
 		logrus.WithError(err).Errorln("Failed to initialize providers after login server sync")
@@ -525,10 +695,15 @@ func (c *Config) RegisterWithThandServer() error {
 	// with the remote config and update if needed. We need to compare the version
 	// numbers
 
+	if c.Sync.GetConflictPolicy() == models.SyncConflictPolicyUseLocal {
+		logrus.Infoln("Sync conflict policy is use_local, ignoring configuration from thand server")
+		return nil
+	}
+
 	err = c.MergeConfiguration(registration)
 
 	if err != nil {
-		logrus.WithError(err).Errorln("Failed to merge configuration from thand server")
+		return fmt.Errorf("failed to merge configuration from thand server: %w", err)
 	}
 
 	return nil
@@ -568,8 +743,14 @@ func (c *Config) syncWithEndpoint(loginUrl, localToken string) (*RegistrationRes
 		},
 	}
 
+	client, err := c.getRegistrationClient()
+
+	if err != nil {
+		return nil, err
+	}
+
 	// Pre-flight check
-	preflightRes, err := common.InvokeHttpRequest(&model.HTTPArguments{
+	preflightRes, err := common.InvokeHttpRequestWithClient(client, &model.HTTPArguments{
 		Method: http.MethodPost,
 		Endpoint: &model.Endpoint{
 			EndpointConfig: &model.EndpointConfiguration{
@@ -602,7 +783,7 @@ func (c *Config) syncWithEndpoint(loginUrl, localToken string) (*RegistrationRes
 
 	// No need for an API key we need to use the session
 	// info
-	registerRes, err := common.InvokeHttpRequest(&model.HTTPArguments{
+	registerRes, err := common.InvokeHttpRequestWithClient(client, &model.HTTPArguments{
 		Method: http.MethodPost,
 		Endpoint: &model.Endpoint{
 			EndpointConfig: &model.EndpointConfiguration{
@@ -670,6 +851,25 @@ func (c *Config) syncWithEndpoint(loginUrl, localToken string) (*RegistrationRes
 	return &registrationResponse, nil
 }
 
+// getRegistrationClient returns the client used to register with the
+// server, presenting the client certificate if the server requires mTLS
+func (c *Config) getRegistrationClient() (*resty.Client, error) {
+
+	client := resty.New()
+
+	if !c.Thand.HasClientCert() {
+		return client, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.Thand.ClientCertFile, c.Thand.ClientKeyFile)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load thand client certificate: %w", err)
+	}
+
+	return client.SetCertificates(cert), nil
+}
+
 // setDefaults sets default configuration values
 func setDefaults(v *viper.Viper) {
 
@@ -692,10 +892,15 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("thand.endpoint", common.DefaultThandEndpoint)
 	v.SetDefault("thand.base", "/")
 	v.SetDefault("thand.api_key", "")
+	v.SetDefault("sync.conflict_policy", string(models.SyncConflictPolicyWarn))
 
 	// Login server defaults
 	v.SetDefault("login.endpoint", common.DefaultLoginServerEndpoint)
 	v.SetDefault("login.base", "/")
+	v.SetDefault("login.renew_before", "10m")
+
+	// GeoIP defaults, empty skips country lookups
+	v.SetDefault("geo.maxmind_db_path", "")
 
 	// Server defaults
 	v.SetDefault("server.host", "0.0.0.0")
@@ -707,7 +912,15 @@ func setDefaults(v *viper.Viper) {
 	// Metrics defaults
 	v.SetDefault("server.metrics.enabled", true)
 	v.SetDefault("server.metrics.path", "/metrics")
-	v.SetDefault("server.metrics.namespace", "thand")
+	v.SetDefault("server.metrics.namespace", metrics.DefaultNamespace)
+	v.SetDefault("server.metrics.port", 0)
+
+	// TLS defaults
+	v.SetDefault("server.tls.cert_file", "")
+	v.SetDefault("server.tls.key_file", "")
+	v.SetDefault("server.tls.min_version", "1.2")
+	v.SetDefault("server.tls.client_ca_file", "")
+	v.SetDefault("server.tls.client_cert_routes", []string{})
 
 	// Health defaults
 	v.SetDefault("server.health.enabled", true)
@@ -718,10 +931,13 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.ready.path", "/ready")
 
 	// Security defaults
-	v.SetDefault("server.security.cors.allowed_origins", []string{"https://thand.io", "https://*.thand.io", "https://app.thand.io", "https://*.app.thand.io"})
+	// Only the agent's own origin and the login server are allowed by default
+	v.SetDefault("server.security.cors.allowed_origins", []string{})
+	v.SetDefault("server.security.cors.allow_all", false)
 	v.SetDefault("server.security.cors.allowed_methods", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"})
 	v.SetDefault("server.security.cors.allowed_headers", []string{"Authorization", "Content-Type", "X-Requested-With"})
 	v.SetDefault("server.security.cors.max_age", 86400)
+	v.SetDefault("server.security.auth_state_expiry", "10m")
 
 	// API defaults
 	v.SetDefault("server.limits.read_timeout", "30s")
@@ -729,6 +945,11 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.limits.idle_timeout", "120s")
 	v.SetDefault("server.limits.requests_per_minute", 100)
 	v.SetDefault("server.limits.burst", 10)
+	v.SetDefault("server.device_code.expiry", "10m")
+	v.SetDefault("server.device_code.interval", "5s")
+	v.SetDefault("server.forms.max_blocks", 50)
+	v.SetDefault("server.forms.max_payload_size", 65536)
+	v.SetDefault("server.signals.idempotency_window", "24h")
 
 	// OIDC defaults
 	v.SetDefault("oidc.scopes", []string{"openid", "profile", "email"})
@@ -740,11 +961,29 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("logging.level", "info")
 	v.SetDefault("logging.format", "json")
 	v.SetDefault("logging.output", "stdout")
+	v.SetDefault("logging.redact_emails", true)
+	v.SetDefault("logging.syslog.protocol", "udp")
+	v.SetDefault("logging.syslog.facility", "local0")
+	v.SetDefault("logging.syslog.format", "rfc5424")
+	v.SetDefault("logging.syslog.app_name", "thand")
+	v.SetDefault("logging.syslog.queue_size", 1000)
 
 	// Where to load in roles and workflows from
 	v.SetDefault("workflows.path", "./examples/workflows") // load any json or yaml files from this directory
 	v.SetDefault("roles.path", "./examples/roles")         // load any json or yaml files from this directory
 	v.SetDefault("providers.path", "./examples/providers") // load any json or yaml files from this directory
+	v.SetDefault("providers.initialize_timeout", "2m")
+
+	// Change freeze calendars don't block requests when they can't be read
+	v.SetDefault("freeze.on_error", string(models.FreezeOnErrorOpen))
+	v.SetDefault("freeze.cache_ttl", "15m")
+
+	// The reconciliation sweep is off until enabled, then revokes orphans
+	v.SetDefault("reconcile.enabled", false)
+	v.SetDefault("reconcile.interval", "1h")
+	v.SetDefault("reconcile.action", string(models.ReconcileActionRevoke))
+	v.SetDefault("reconcile.grace_period", "15m")
+	v.SetDefault("reconcile.max_revocations", models.DefaultReconcileMaxRevocations)
 
 	// Allow a url to pull in roles and workflows
 	// v.SetDefault("roles.url", "https://raw.githubusercontent.com/thand-io/agent/refs/heads/main/examples/roles/roles.yaml")
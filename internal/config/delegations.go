@@ -0,0 +1,217 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// delegationsObjectKey is where delegations are kept in the storage service
+const delegationsObjectKey = "delegations/delegations.json"
+
+// ErrDelegationNotFound is returned when no delegation has the ID
+var ErrDelegationNotFound = errors.New("delegation not found")
+
+// Delegations are read, changed and written back as one object so changes
+// from concurrent requests are serialized
+var delegationsLock sync.Mutex
+
+type delegationsFile struct {
+	Version     string              `json:"version"`
+	Delegations []models.Delegation `json:"delegations"`
+}
+
+// DelegationStore keeps approvers' delegations in the storage service so
+// every server and worker sees the same delegations
+type DelegationStore struct {
+	storage models.StorageImpl
+}
+
+// NewDelegationStore returns a store backed by the storage service
+func NewDelegationStore(storage models.StorageImpl) *DelegationStore {
+	return &DelegationStore{storage: storage}
+}
+
+// GetDelegationStore returns the delegation store, or an error if the
+// storage service isn't configured
+func (c *Config) GetDelegationStore() (*DelegationStore, error) {
+
+	services := c.GetServices()
+
+	if !services.HasStorage() {
+		return nil, fmt.Errorf("delegations need the storage service to be configured")
+	}
+
+	return NewDelegationStore(services.GetStorage()), nil
+}
+
+// List returns the delegations that haven't expired, ordered by start
+func (d *DelegationStore) List(ctx context.Context, now time.Time) ([]models.Delegation, error) {
+
+	delegationsLock.Lock()
+	defer delegationsLock.Unlock()
+
+	delegations, err := d.load(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pruneDelegations(delegations, now), nil
+}
+
+// Add saves the delegation. An approver can only have one delegation at a
+// time so windows that overlap an existing delegation are rejected.
+func (d *DelegationStore) Add(ctx context.Context, delegation models.Delegation, now time.Time) (*models.Delegation, error) {
+
+	if err := delegation.Validate(); err != nil {
+		return nil, err
+	}
+
+	if delegation.IsExpired(now) {
+		return nil, fmt.Errorf("delegation has already ended")
+	}
+
+	delegationsLock.Lock()
+	defer delegationsLock.Unlock()
+
+	delegations, err := d.load(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	delegations = pruneDelegations(delegations, now)
+
+	for _, existing := range delegations {
+		if strings.EqualFold(existing.Approver, delegation.Approver) && existing.Overlaps(&delegation) {
+			return nil, fmt.Errorf("delegation overlaps delegation %s to %s from %s until %s",
+				existing.ID, existing.Delegate,
+				existing.Start.Format(time.RFC3339), existing.End.Format(time.RFC3339))
+		}
+	}
+
+	delegation.ID = uuid.New().String()
+	delegation.CreatedAt = now
+
+	delegations = append(delegations, delegation)
+
+	if err := d.save(ctx, delegations); err != nil {
+		return nil, err
+	}
+
+	return &delegation, nil
+}
+
+// Remove deletes the delegation. Unless approver is empty only the
+// approver's own delegations can be removed.
+func (d *DelegationStore) Remove(ctx context.Context, id string, approver string, now time.Time) error {
+
+	delegationsLock.Lock()
+	defer delegationsLock.Unlock()
+
+	delegations, err := d.load(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	index := slices.IndexFunc(delegations, func(delegation models.Delegation) bool {
+		return delegation.ID == id &&
+			(len(approver) == 0 || strings.EqualFold(delegation.Approver, approver))
+	})
+
+	if index < 0 {
+		return ErrDelegationNotFound
+	}
+
+	delegations = slices.Delete(delegations, index, index+1)
+
+	return d.save(ctx, pruneDelegations(delegations, now))
+}
+
+// Prune removes expired delegations and returns how many were removed
+func (d *DelegationStore) Prune(ctx context.Context, now time.Time) (int, error) {
+
+	delegationsLock.Lock()
+	defer delegationsLock.Unlock()
+
+	delegations, err := d.load(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	pruned := pruneDelegations(delegations, now)
+	removed := len(delegations) - len(pruned)
+
+	if removed == 0 {
+		return 0, nil
+	}
+
+	return removed, d.save(ctx, pruned)
+}
+
+func (d *DelegationStore) load(ctx context.Context) ([]models.Delegation, error) {
+
+	data, err := d.storage.GetObject(ctx, delegationsObjectKey)
+
+	if errors.Is(err, models.ErrObjectNotFound) {
+		return []models.Delegation{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load delegations: %w", err)
+	}
+
+	var file delegationsFile
+
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse delegations: %w", err)
+	}
+
+	return file.Delegations, nil
+}
+
+func (d *DelegationStore) save(ctx context.Context, delegations []models.Delegation) error {
+
+	data, err := json.Marshal(delegationsFile{
+		Version:     "1.0",
+		Delegations: delegations,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to encode delegations: %w", err)
+	}
+
+	if err := d.storage.PutObject(ctx, delegationsObjectKey, "application/json", data); err != nil {
+		return fmt.Errorf("failed to save delegations: %w", err)
+	}
+
+	return nil
+}
+
+// pruneDelegations returns the delegations that haven't expired ordered
+// by start
+func pruneDelegations(delegations []models.Delegation, now time.Time) []models.Delegation {
+
+	active := []models.Delegation{}
+
+	for _, delegation := range delegations {
+		if !delegation.IsExpired(now) {
+			active = append(active, delegation)
+		}
+	}
+
+	slices.SortFunc(active, func(a, b models.Delegation) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	return active
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config/services/storage"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func newTestDelegationStore(t *testing.T) *DelegationStore {
+	t.Helper()
+
+	localStorage := storage.NewLocalStorageFromConfig(&models.BasicConfig{"path": t.TempDir()}, "test-secret")
+	require.NoError(t, localStorage.Initialize())
+
+	return NewDelegationStore(localStorage)
+}
+
+func TestDelegationStore(t *testing.T) {
+
+	ctx := context.Background()
+	store := newTestDelegationStore(t)
+
+	now := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	delegations, err := store.List(ctx, now)
+	require.NoError(t, err)
+	assert.Empty(t, delegations)
+
+	added, err := store.Add(ctx, models.Delegation{
+		Approver: "alice@example.com",
+		Delegate: "bob@example.com",
+		Start:    now,
+		End:      now.Add(14 * 24 * time.Hour),
+		Reason:   "Holiday",
+	}, now)
+	require.NoError(t, err)
+	assert.NotEmpty(t, added.ID)
+	assert.Equal(t, now, added.CreatedAt)
+
+	t.Run("rejects overlapping windows for the same approver", func(t *testing.T) {
+		_, err := store.Add(ctx, models.Delegation{
+			Approver: "Alice@example.com",
+			Delegate: "carol@example.com",
+			Start:    now.Add(7 * 24 * time.Hour),
+			End:      now.Add(21 * 24 * time.Hour),
+		}, now)
+		assert.ErrorContains(t, err, "overlaps")
+	})
+
+	t.Run("rejects delegations that have ended", func(t *testing.T) {
+		_, err := store.Add(ctx, models.Delegation{
+			Approver: "carol@example.com",
+			Delegate: "dave@example.com",
+			Start:    now.Add(-48 * time.Hour),
+			End:      now.Add(-24 * time.Hour),
+		}, now)
+		assert.ErrorContains(t, err, "already ended")
+	})
+
+	t.Run("only the approver or an admin can remove", func(t *testing.T) {
+		err := store.Remove(ctx, added.ID, "bob@example.com", now)
+		assert.ErrorIs(t, err, ErrDelegationNotFound)
+
+		delegations, err := store.List(ctx, now)
+		require.NoError(t, err)
+		assert.Len(t, delegations, 1)
+	})
+
+	t.Run("prunes expired delegations", func(t *testing.T) {
+		later := now.Add(14 * 24 * time.Hour)
+
+		delegations, err := store.List(ctx, later)
+		require.NoError(t, err)
+		assert.Empty(t, delegations)
+
+		removed, err := store.Prune(ctx, later)
+		require.NoError(t, err)
+		assert.Equal(t, 1, removed)
+
+		delegations, err = store.List(ctx, now)
+		require.NoError(t, err)
+		assert.Empty(t, delegations)
+	})
+
+	t.Run("removes delegations", func(t *testing.T) {
+		added, err := store.Add(ctx, models.Delegation{
+			Approver: "alice@example.com",
+			Delegate: "bob@example.com",
+			Start:    now,
+			End:      now.Add(24 * time.Hour),
+		}, now)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Remove(ctx, added.ID, "alice@example.com", now))
+		assert.ErrorIs(t, store.Remove(ctx, added.ID, "", now), ErrDelegationNotFound)
+	})
+}
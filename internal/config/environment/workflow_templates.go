@@ -0,0 +1,112 @@
+package environment
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"text/template"
+
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// This file contains the built in workflow templates roles can reference
+
+//go:embed templates/*.yaml
+var workflowTemplatesFS embed.FS
+
+// WorkflowTemplateValues are substituted into a workflow template
+type WorkflowTemplateValues struct {
+	Workflow    string // Name of the expanded workflow
+	Role        string
+	Approvals   int
+	SelfApprove bool
+	Duration    string
+	Notifiers   map[string]models.WorkflowTemplateNotifier
+}
+
+// workflowTemplateMessages are the subject and message sent to the
+// template notifiers for each step of the workflow
+var workflowTemplateMessages = map[string][2]string{
+	"requested": {
+		"Access Request Approval Required",
+		`${ "\($context.user.name) is requesting the \($context.role.name) role.\n\nReason: \($context.reason)\nDuration: \($context.duration)" }`,
+	},
+	"granted": {
+		"Access Granted",
+		`${ "\($context.user.name) was granted the \($context.role.name) role for \($context.duration)." }`,
+	},
+	"revoked": {
+		"Access Revoked",
+		`${ "The \($context.role.name) role granted to \($context.user.name) has been revoked." }`,
+	},
+	"denied": {
+		"Access Request Denied",
+		`${ "\($context.user.name) was denied the \($context.role.name) role." }`,
+	},
+}
+
+var workflowTemplateFuncs = template.FuncMap{
+	// json writes a value as JSON, which is also valid YAML
+	"json": func(value any) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+	// notifiers writes the notifiers for a step of the workflow
+	"notifiers": func(notifiers map[string]models.WorkflowTemplateNotifier, step string) (string, error) {
+
+		message, found := workflowTemplateMessages[step]
+
+		if !found {
+			return "", fmt.Errorf("unknown workflow template step '%s'", step)
+		}
+
+		requests := make(map[string]map[string]any, len(notifiers))
+
+		for name, notifier := range notifiers {
+			requests[name] = map[string]any{
+				"provider": notifier.Provider,
+				"to":       notifier.To,
+				"subject":  message[0],
+				"message":  message[1],
+			}
+		}
+
+		data, err := json.Marshal(requests)
+		return string(data), err
+	},
+}
+
+// GetWorkflowTemplate expands a built in workflow template with the values
+func GetWorkflowTemplate(name string, values WorkflowTemplateValues) (*models.Workflow, error) {
+
+	data, err := workflowTemplatesFS.ReadFile("templates/" + name + ".yaml")
+
+	if err != nil {
+		return nil, fmt.Errorf("unknown workflow template: %s", name)
+	}
+
+	tmpl, err := template.New(name).
+		Funcs(workflowTemplateFuncs).
+		Option("missingkey=error").
+		Parse(string(data))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse workflow template '%s': %w", name, err)
+	}
+
+	var rendered bytes.Buffer
+
+	if err := tmpl.Execute(&rendered, values); err != nil {
+		return nil, fmt.Errorf("failed to render workflow template '%s': %w", name, err)
+	}
+
+	workflow, err := common.ReadDataToInterface(rendered.Bytes(), models.Workflow{})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workflow template '%s': %w", name, err)
+	}
+
+	return workflow, nil
+}
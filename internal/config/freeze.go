@@ -0,0 +1,254 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// freezeFetchTimeout limits how long a calendar fetch can hold up a request
+const freezeFetchTimeout = 10 * time.Second
+
+// freezeCacheEntry is a fetched calendar's freeze periods
+type freezeCacheEntry struct {
+	periods   []models.FreezePeriod
+	fetchedAt time.Time
+}
+
+// EvaluateFreeze returns whether a change freeze is active and what the
+// request's role and workflow declare should happen during it. Calendars
+// that can't be read freeze requests when freeze.on_error is closed.
+func (c *Config) EvaluateFreeze(
+	ctx context.Context,
+	user *models.User,
+	role *models.Role,
+	workflowName string,
+	now time.Time,
+) (*models.FreezeStatus, error) {
+
+	periods := []models.FreezePeriod{}
+	errors := []string{}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Freeze.Calendars)) {
+
+		calendarPeriods, err := c.getFreezePeriods(ctx, name, now)
+
+		if err != nil {
+			logrus.WithError(err).WithField("calendar", name).Warnln("Failed to read freeze calendar")
+			errors = append(errors, fmt.Sprintf("%s: %s", name, err.Error()))
+			continue
+		}
+
+		periods = append(periods, calendarPeriods...)
+	}
+
+	status := models.NewFreezeStatus(periods, now)
+	status.Errors = errors
+
+	if len(errors) > 0 && c.Freeze.IsFailClosed() {
+		status.Active = true
+	}
+
+	if !status.Active {
+		return status, nil
+	}
+
+	action, approvers, err := c.getFreezeRule(user, role, workflowName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	status.Action = action
+	status.Approvers = approvers
+
+	return status, nil
+}
+
+// getFreezeRule returns the strictest during_freeze of the role and
+// workflow and their combined freeze approvers
+func (c *Config) getFreezeRule(user *models.User, role *models.Role, workflowName string) (models.FreezeAction, []string, error) {
+
+	var action models.FreezeAction
+	approvers := []string{}
+
+	addApprovers := func(extra []string) {
+		for _, approver := range extra {
+			if !slices.Contains(approvers, approver) {
+				approvers = append(approvers, approver)
+			}
+		}
+	}
+
+	if role != nil {
+
+		compositeRole, err := c.getConfiguredCompositeRole(newUserIdentity(user), role)
+
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to resolve role: %w", err)
+		}
+
+		action = compositeRole.DuringFreeze
+		addApprovers(compositeRole.FreezeApprovers)
+	}
+
+	if len(workflowName) > 0 {
+		if workflow, err := c.GetWorkflowByName(workflowName); err == nil {
+			action = action.Stricter(workflow.DuringFreeze)
+			addApprovers(workflow.FreezeApprovers)
+		}
+	}
+
+	return action, approvers, nil
+}
+
+// getFreezePeriods returns a calendar's freeze periods, fetching feeds
+// again once the cache expires. Failures aren't cached so the next request
+// tries again.
+func (c *Config) getFreezePeriods(ctx context.Context, name string, now time.Time) ([]models.FreezePeriod, error) {
+
+	calendar := c.Freeze.Calendars[name]
+
+	if len(calendar.Windows) > 0 {
+		return resolveFreezeWindows(name, &calendar)
+	}
+
+	c.freezeCacheMu.Lock()
+	cached, found := c.freezeCache[name]
+	c.freezeCacheMu.Unlock()
+
+	if found && now.Sub(cached.fetchedAt) < c.Freeze.GetCacheTTL() {
+		return cached.periods, nil
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, freezeFetchTimeout)
+	defer cancel()
+
+	var events []models.CalendarEvent
+	var err error
+
+	if len(calendar.ICal) > 0 {
+		events, err = fetchICalEvents(fetchCtx, calendar.ICal, calendar.Timezone)
+	} else {
+		events, err = c.fetchGoogleCalendarEvents(fetchCtx, &calendar, now)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := resolveCalendarEvents(name, &calendar, events)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.freezeCacheMu.Lock()
+	if c.freezeCache == nil {
+		c.freezeCache = map[string]*freezeCacheEntry{}
+	}
+	c.freezeCache[name] = &freezeCacheEntry{periods: periods, fetchedAt: now}
+	c.freezeCacheMu.Unlock()
+
+	return periods, nil
+}
+
+func resolveFreezeWindows(name string, calendar *models.FreezeCalendar) ([]models.FreezePeriod, error) {
+
+	location, err := calendar.GetLocation("")
+
+	if err != nil {
+		return nil, err
+	}
+
+	periods := make([]models.FreezePeriod, 0, len(calendar.Windows))
+
+	for _, window := range calendar.Windows {
+
+		period, err := window.Resolve(location)
+
+		if err != nil {
+			return nil, err
+		}
+
+		period.Calendar = name
+		periods = append(periods, period)
+	}
+
+	return periods, nil
+}
+
+// resolveCalendarEvents resolves the events' times. Events with invalid
+// times are skipped rather than failing the whole calendar.
+func resolveCalendarEvents(name string, calendar *models.FreezeCalendar, events []models.CalendarEvent) ([]models.FreezePeriod, error) {
+
+	periods := make([]models.FreezePeriod, 0, len(events))
+
+	for _, event := range events {
+
+		location, err := calendar.GetLocation(event.TimeZone)
+
+		if err != nil {
+			return nil, err
+		}
+
+		period, err := event.Resolve(location)
+
+		if err != nil {
+			logrus.WithError(err).WithField("calendar", name).Warnln("Skipping freeze calendar event")
+			continue
+		}
+
+		period.Calendar = name
+		periods = append(periods, period)
+	}
+
+	return periods, nil
+}
+
+func fetchICalEvents(ctx context.Context, url string, timezone string) ([]models.CalendarEvent, error) {
+
+	resp, err := resty.New().R().SetContext(ctx).Get(url)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: status %d", url, resp.StatusCode())
+	}
+
+	return parseICalEvents(resp.Body(), timezone)
+}
+
+// fetchGoogleCalendarEvents reads the events around now with the
+// credentials of the configured gsuite provider
+func (c *Config) fetchGoogleCalendarEvents(
+	ctx context.Context,
+	calendar *models.FreezeCalendar,
+	now time.Time,
+) ([]models.CalendarEvent, error) {
+
+	provider, err := c.GetProviderByName(calendar.Provider)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reader, ok := provider.GetClient().(models.ProviderCalendarReader)
+
+	if !ok {
+		return nil, fmt.Errorf("provider %s can't read calendars", calendar.Provider)
+	}
+
+	// Fetch enough to cover every evaluation until the cache expires
+	return reader.ListCalendarEvents(ctx, calendar.GoogleCalendar,
+		now.Add(-24*time.Hour), now.Add(c.Freeze.GetCacheTTL()+24*time.Hour))
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thand-io/agent/internal/models"
+)
+
+const (
+	icalDateLayout     = "20060102"
+	icalDateTimeLayout = "20060102T150405"
+)
+
+// icalProperty is a content line e.g. DTSTART;TZID=Europe/London:20261224T090000
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseICalEvents reads the events of an iCal feed. Only the properties
+// needed for freeze windows are read and recurring events aren't
+// expanded, only their first occurrence is used. Times without a zone are
+// in the timezone, if set, otherwise the calendar's own zone.
+func parseICalEvents(data []byte, timezone string) ([]models.CalendarEvent, error) {
+
+	lines := unfoldICalLines(data)
+
+	if len(lines) == 0 || !strings.EqualFold(strings.TrimSpace(lines[0]), "BEGIN:VCALENDAR") {
+		return nil, fmt.Errorf("not an iCal calendar")
+	}
+
+	events := []models.CalendarEvent{}
+	calendarZone := ""
+
+	var event *models.CalendarEvent
+	var cancelled bool
+
+	for _, line := range lines {
+
+		property, ok := parseICalProperty(line)
+
+		if !ok {
+			continue
+		}
+
+		switch {
+		case property.name == "BEGIN" && strings.EqualFold(property.value, "VEVENT"):
+			event = &models.CalendarEvent{TimeZone: calendarZone}
+			if len(timezone) > 0 {
+				event.TimeZone = timezone
+			}
+			cancelled = false
+
+		case property.name == "END" && strings.EqualFold(property.value, "VEVENT"):
+			if event != nil && !cancelled {
+				events = append(events, *event)
+			}
+			event = nil
+
+		case event == nil:
+			// X-WR-TIMEZONE is the calendar's zone in Google and Outlook exports
+			if property.name == "X-WR-TIMEZONE" {
+				calendarZone = property.value
+			}
+
+		case property.name == "SUMMARY":
+			event.Summary = unescapeICalText(property.value)
+
+		case property.name == "STATUS":
+			cancelled = strings.EqualFold(property.value, "CANCELLED")
+
+		case property.name == "DTSTART":
+			if err := setICalEventTime(event, property, true); err != nil {
+				return nil, err
+			}
+
+		case property.name == "DTEND":
+			if err := setICalEventTime(event, property, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// setICalEventTime sets the event's start or end. Dates are all day.
+// Times in UTC end in Z, others are in their TZID or, without one, in the
+// calendar's zone.
+func setICalEventTime(event *models.CalendarEvent, property icalProperty, start bool) error {
+
+	if strings.EqualFold(property.params["VALUE"], "DATE") || len(property.value) == len(icalDateLayout) {
+
+		date, err := time.Parse(icalDateLayout, property.value)
+
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", property.name, property.value, err)
+		}
+
+		event.AllDay = true
+
+		if start {
+			event.StartDate = date.Format("2006-01-02")
+		} else {
+			event.EndDate = date.Format("2006-01-02")
+		}
+
+		return nil
+	}
+
+	location := time.UTC
+	value := property.value
+
+	if strings.HasSuffix(value, "Z") {
+		value = strings.TrimSuffix(value, "Z")
+	} else if zone, found := property.params["TZID"]; found {
+		loaded, err := time.LoadLocation(strings.Trim(zone, `"`))
+		if err != nil {
+			return fmt.Errorf("unknown TZID %q: %w", zone, err)
+		}
+		location = loaded
+	} else if len(event.TimeZone) > 0 {
+		loaded, err := time.LoadLocation(event.TimeZone)
+		if err != nil {
+			return fmt.Errorf("unknown timezone %q: %w", event.TimeZone, err)
+		}
+		location = loaded
+	}
+
+	parsed, err := time.ParseInLocation(icalDateTimeLayout, value, location)
+
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", property.name, property.value, err)
+	}
+
+	if start {
+		event.Start = parsed
+	} else {
+		event.End = parsed
+	}
+
+	return nil
+}
+
+// unfoldICalLines joins lines that were folded onto continuation lines
+// starting with a space or tab
+func unfoldICalLines(data []byte) []string {
+
+	lines := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+func parseICalProperty(line string) (icalProperty, bool) {
+
+	nameAndParams, value, found := strings.Cut(line, ":")
+
+	if !found {
+		return icalProperty{}, false
+	}
+
+	parts := strings.Split(nameAndParams, ";")
+
+	property := icalProperty{
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  value,
+	}
+
+	for _, param := range parts[1:] {
+		if key, paramValue, found := strings.Cut(param, "="); found {
+			property.params[strings.ToUpper(key)] = paramValue
+		}
+	}
+
+	return property, true
+}
+
+func unescapeICalText(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+const freezeTestCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"X-WR-TIMEZONE:America/New_York\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Holiday\r\n" +
+	"  freeze\r\n" +
+	"DTSTART;VALUE=DATE:20261224\r\n" +
+	"DTEND;VALUE=DATE:20261226\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Release\\, v2\r\n" +
+	"DTSTART;TZID=Europe/London:20261201T090000\r\n" +
+	"DTEND;TZID=Europe/London:20261201T170000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Migration\r\n" +
+	"DTSTART:20261110T220000Z\r\n" +
+	"DTEND:20261111T020000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Cancelled\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"DTSTART;VALUE=DATE:20261101\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICalEvents(t *testing.T) {
+
+	events, err := parseICalEvents([]byte(freezeTestCalendar), "")
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	assert.Equal(t, models.CalendarEvent{
+		Summary:   "Holiday freeze",
+		AllDay:    true,
+		StartDate: "2026-12-24",
+		EndDate:   "2026-12-26",
+		TimeZone:  "America/New_York",
+	}, events[0])
+
+	assert.Equal(t, "Release, v2", events[1].Summary)
+	assert.Equal(t, time.Date(2026, 12, 1, 9, 0, 0, 0, time.UTC), events[1].Start.UTC())
+	assert.Equal(t, time.Date(2026, 12, 1, 17, 0, 0, 0, time.UTC), events[1].End.UTC())
+
+	assert.Equal(t, time.Date(2026, 11, 10, 22, 0, 0, 0, time.UTC), events[2].Start.UTC())
+
+	// The configured timezone overrides the calendar's
+	events, err = parseICalEvents([]byte(freezeTestCalendar), "Asia/Tokyo")
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", events[0].TimeZone)
+
+	_, err = parseICalEvents([]byte("<html></html>"), "")
+	assert.Error(t, err)
+}
+
+func TestEvaluateFreeze_AllDayTimezone(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(freezeTestCalendar))
+	}))
+	defer server.Close()
+
+	c := &Config{Freeze: models.FreezeConfig{Calendars: map[string]models.FreezeCalendar{
+		"holidays": {ICal: server.URL},
+	}}}
+
+	// 03:00 UTC on the 24th is still the 23rd in New York and 03:00 UTC on
+	// the 26th is still the 25th, the last day as DTEND is exclusive
+	status, err := c.EvaluateFreeze(context.Background(), nil, nil, "", time.Date(2026, 12, 24, 3, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.False(t, status.Active)
+
+	status, err = c.EvaluateFreeze(context.Background(), nil, nil, "", time.Date(2026, 12, 26, 3, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.True(t, status.Active)
+	assert.Equal(t, []string{"Holiday freeze"}, status.Labels)
+	assert.Equal(t, time.Date(2026, 12, 26, 5, 0, 0, 0, time.UTC), status.Periods[0].End.UTC())
+}
+
+func TestEvaluateFreeze_OverlappingCalendars(t *testing.T) {
+
+	c := &Config{Freeze: models.FreezeConfig{Calendars: map[string]models.FreezeCalendar{
+		"release": {Windows: []models.FreezeWindow{
+			{Label: "Year end", Start: "2026-12-15", End: "2026-12-31"},
+		}},
+		"holidays": {Timezone: "Europe/London", Windows: []models.FreezeWindow{
+			{Label: "Christmas", Start: "2026-12-24", End: "2026-12-26"},
+			{Label: "New year", Start: "2026-12-31T18:00:00Z", End: "2027-01-02T00:00:00Z"},
+		}},
+	}}}
+
+	status, err := c.EvaluateFreeze(context.Background(), nil, nil, "", time.Date(2026, 12, 24, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.True(t, status.Active)
+	assert.Equal(t, []string{"Year end", "Christmas"}, status.Labels)
+	assert.Empty(t, status.Action)
+
+	status, err = c.EvaluateFreeze(context.Background(), nil, nil, "", time.Date(2026, 12, 31, 20, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Year end", "New year"}, status.Labels)
+
+	status, err = c.EvaluateFreeze(context.Background(), nil, nil, "", time.Date(2027, 1, 5, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.False(t, status.Active)
+}
+
+func TestEvaluateFreeze_ICalFetchFails(t *testing.T) {
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 12, 1, 12, 0, 0, 0, time.UTC)
+	calendars := map[string]models.FreezeCalendar{"release": {ICal: server.URL}}
+
+	t.Run("open", func(t *testing.T) {
+		c := &Config{Freeze: models.FreezeConfig{Calendars: calendars, OnError: models.FreezeOnErrorOpen}}
+
+		status, err := c.EvaluateFreeze(context.Background(), nil, nil, "", now)
+		require.NoError(t, err)
+		assert.False(t, status.Active)
+		require.Len(t, status.Errors, 1)
+		assert.Contains(t, status.Errors[0], "status 500")
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		c := &Config{
+			Freeze: models.FreezeConfig{Calendars: calendars, OnError: models.FreezeOnErrorClosed},
+			Roles: RoleConfig{Definitions: map[string]models.Role{
+				"admin": {Name: "admin", DuringFreeze: models.FreezeActionDeny},
+			}},
+		}
+
+		status, err := c.EvaluateFreeze(context.Background(), nil, &models.Role{Name: "admin"}, "", now)
+		require.NoError(t, err)
+		assert.True(t, status.Active)
+		assert.True(t, status.IsDenied())
+		assert.Equal(t, "freeze calendar unavailable", status.GetDescription())
+	})
+
+	// Failures aren't cached
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestEvaluateFreeze_CachesCalendars(t *testing.T) {
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(freezeTestCalendar))
+	}))
+	defer server.Close()
+
+	c := &Config{Freeze: models.FreezeConfig{
+		Calendars: map[string]models.FreezeCalendar{"holidays": {ICal: server.URL}},
+		CacheTTL:  "10m",
+	}}
+
+	now := time.Date(2026, 12, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, offset := range []time.Duration{0, 5 * time.Minute, 9 * time.Minute} {
+		_, err := c.EvaluateFreeze(context.Background(), nil, nil, "", now.Add(offset))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), requests.Load())
+
+	_, err := c.EvaluateFreeze(context.Background(), nil, nil, "", now.Add(11*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestEvaluateFreeze_GoogleCalendarRequiresReader(t *testing.T) {
+
+	provider := models.Provider{Name: "slack", Provider: "slack", Enabled: true}
+	provider.SetClient(models.NewBaseProvider("slack", provider, models.ProviderCapabilityNotifier))
+
+	c := &Config{
+		Providers: ProviderConfig{Definitions: map[string]models.Provider{"slack": provider}},
+		Freeze: models.FreezeConfig{Calendars: map[string]models.FreezeCalendar{
+			"google": {GoogleCalendar: "freeze@example.com", Provider: "slack"},
+		}},
+	}
+
+	status, err := c.EvaluateFreeze(context.Background(), nil, nil, "", time.Now())
+	require.NoError(t, err)
+	require.Len(t, status.Errors, 1)
+	assert.Contains(t, status.Errors[0], "can't read calendars")
+}
+
+func TestEvaluatePolicies_Freeze(t *testing.T) {
+
+	c := &Config{
+		Freeze: models.FreezeConfig{Calendars: map[string]models.FreezeCalendar{
+			"release": {Windows: []models.FreezeWindow{{Label: "Year end", Start: "2026-12-15", End: "2026-12-31"}}},
+		}},
+		Roles: RoleConfig{Definitions: map[string]models.Role{
+			"prod-admin": {Name: "prod-admin", DuringFreeze: models.FreezeActionDeny},
+			"readonly": {
+				Name:            "readonly",
+				DuringFreeze:    models.FreezeActionExtraApproval,
+				FreezeApprovers: []string{"cab@example.com"},
+			},
+			"viewer": {Name: "viewer"},
+		}},
+		Workflows: WorkflowConfig{Definitions: map[string]models.Workflow{
+			"release": {
+				Name:            "release",
+				DuringFreeze:    models.FreezeActionExtraApproval,
+				FreezeApprovers: []string{"release@example.com"},
+			},
+		}},
+	}
+
+	ctx := context.Background()
+	during := time.Date(2026, 12, 20, 12, 0, 0, 0, time.UTC)
+	after := time.Date(2027, 1, 5, 12, 0, 0, 0, time.UTC)
+
+	decision, err := c.EvaluatePolicies(ctx, models.NewPolicyInput(newPolicyTestRequest("prod-admin", "PT1H"), during))
+	require.NoError(t, err)
+	assert.True(t, decision.IsDenied())
+	assert.Equal(t, []string{"requests are blocked during the change freeze: Year end"}, decision.Messages)
+	require.NotNil(t, decision.Freeze)
+	assert.True(t, decision.Freeze.Active)
+
+	decision, err = c.EvaluatePolicies(ctx, models.NewPolicyInput(newPolicyTestRequest("readonly", "PT1H"), during))
+	require.NoError(t, err)
+	assert.True(t, decision.RequiresApproval())
+	assert.Equal(t, []string{"cab@example.com"}, decision.Approvers)
+
+	// Roles without during_freeze are unaffected
+	decision, err = c.EvaluatePolicies(ctx, models.NewPolicyInput(newPolicyTestRequest("viewer", "PT1H"), during))
+	require.NoError(t, err)
+	assert.Equal(t, models.PolicyResultAllow, decision.Result)
+	assert.True(t, decision.Freeze.Active)
+
+	// The workflow's rule applies too
+	request := newPolicyTestRequest("viewer", "PT1H")
+	request.Workflow = "release"
+	decision, err = c.EvaluatePolicies(ctx, models.NewPolicyInput(request, during))
+	require.NoError(t, err)
+	assert.True(t, decision.RequiresApproval())
+	assert.Equal(t, []string{"release@example.com"}, decision.Approvers)
+
+	request = newPolicyTestRequest("readonly", "PT1H")
+	request.Workflow = "release"
+	decision, err = c.EvaluatePolicies(ctx, models.NewPolicyInput(request, during))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cab@example.com", "release@example.com"}, decision.Approvers)
+
+	decision, err = c.EvaluatePolicies(ctx, models.NewPolicyInput(newPolicyTestRequest("prod-admin", "PT1H"), after))
+	require.NoError(t, err)
+	assert.Equal(t, models.PolicyResultAllow, decision.Result)
+	assert.False(t, decision.Freeze.Active)
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"net"
+
+	"github.com/oschwald/geoip2-golang"
+	"github.com/sirupsen/logrus"
+)
+
+// LookupCountry returns the ISO country code of an IP address. It's empty
+// when no GeoIP database is configured or the address isn't in it.
+func (c *Config) LookupCountry(ip string) string {
+
+	reader := c.getGeoReader()
+
+	if reader == nil {
+		return ""
+	}
+
+	addr := net.ParseIP(ip)
+
+	if addr == nil {
+		return ""
+	}
+
+	record, err := reader.Country(addr)
+
+	if err != nil {
+		logrus.WithError(err).WithField("ip", ip).Debugln("Failed to look up country")
+		return ""
+	}
+
+	return record.Country.IsoCode
+}
+
+// getGeoReader opens the GeoIP database the first time it's used
+func (c *Config) getGeoReader() *geoip2.Reader {
+
+	c.geoReaderOnce.Do(func() {
+
+		if len(c.Geo.MaxMindDBPath) == 0 {
+			return
+		}
+
+		reader, err := geoip2.Open(c.Geo.MaxMindDBPath)
+
+		if err != nil {
+			logrus.WithError(err).Errorln("Failed to open GeoIP database, sessions won't record their country")
+			return
+		}
+
+		c.geoReader = reader
+	})
+
+	return c.geoReader
+}
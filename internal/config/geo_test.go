@@ -0,0 +1,27 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestLookupCountry(t *testing.T) {
+
+	t.Run("no database", func(t *testing.T) {
+		config := &Config{}
+		assert.Empty(t, config.LookupCountry("203.0.113.10"))
+	})
+
+	t.Run("missing database", func(t *testing.T) {
+		config := &Config{
+			Geo: models.GeoConfig{
+				MaxMindDBPath: filepath.Join(t.TempDir(), "GeoLite2-Country.mmdb"),
+			},
+		}
+		assert.Empty(t, config.LookupCountry("203.0.113.10"))
+		assert.Nil(t, config.geoReader)
+	})
+}
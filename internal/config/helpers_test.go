@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"testing"
 
 	"github.com/thand-io/agent/internal/models"
@@ -26,7 +27,7 @@ func newTestConfig(t *testing.T, roles map[string]models.Role, providers map[str
 
 	// Initialize providers - will use mock implementations registered in internal/testing/mocks
 	if len(providers) > 0 {
-		err := config.InitializeProviders()
+		err := config.InitializeProviders(context.Background())
 		if err != nil {
 			t.Fatalf("Failed to initialize mock providers: %v", err)
 		}
@@ -34,7 +34,7 @@ func NewMockIdentityProvider(name string, identities []models.Identity) *MockIde
 	return mk
 }
 
-func (m *MockIdentityProvider) Initialize(identifier string, provider models.Provider) error {
+func (m *MockIdentityProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	return nil
 }
 
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyLogShorthand(t *testing.T) {
+
+	load := func(t *testing.T, data string) *Config {
+		v := newViper()
+		setDefaults(v)
+		v.SetConfigType("yaml")
+		require.NoError(t, v.ReadConfig(strings.NewReader(data)))
+
+		applyLogShorthand(v)
+
+		var config Config
+		require.NoError(t, v.Unmarshal(&config))
+		return &config
+	}
+
+	t.Run("log shorthand", func(t *testing.T) {
+		config := load(t, "log:\n  level: debug\n  format: text\n")
+		assert.Equal(t, "debug", config.Logging.Level)
+		assert.Equal(t, "text", config.Logging.Format)
+		assert.True(t, config.Logging.RedactEmails)
+	})
+
+	t.Run("logging wins", func(t *testing.T) {
+		config := load(t, "log:\n  level: debug\nlogging:\n  level: warn\n  redact_emails: false\n")
+		assert.Equal(t, "warn", config.Logging.Level)
+		assert.Equal(t, "json", config.Logging.Format)
+		assert.False(t, config.Logging.RedactEmails)
+	})
+}
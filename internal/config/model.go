@@ -4,19 +4,24 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/url"
 	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/blevesearch/bleve/v2"
 	"github.com/google/uuid"
+	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/oschwald/geoip2-golang"
 	"github.com/serverlessworkflow/sdk-go/v3/model"
 	"github.com/sirupsen/logrus"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config/services"
 	"github.com/thand-io/agent/internal/models"
+	lua "github.com/yuin/gopher-lua"
 )
 
 type Mode string
@@ -50,24 +55,75 @@ type Config struct {
 	Logging models.LoggingConfig `mapstructure:"logging"`
 	API     models.APIConfig     `mapstructure:"api"`
 	Secret  string               `mapstructure:"secret"` // Secret used for signing cookies and tokens
+	Geo     models.GeoConfig     `mapstructure:"geo"`    // GeoIP database for session audit logs
 
 	// Workflow engine config
-	Roles     RoleConfig     `mapstructure:"roles"`
-	Workflows WorkflowConfig `mapstructure:"workflows"` // These are workflows to run for role associated workflows
-	Providers ProviderConfig `mapstructure:"providers"` // These are integration providers like AWS, GCP, etc.
+	Roles     RoleConfig          `mapstructure:"roles"`
+	Workflows WorkflowConfig      `mapstructure:"workflows"` // These are workflows to run for role associated workflows
+	Providers ProviderConfig      `mapstructure:"providers"` // These are integration providers like AWS, GCP, etc.
+	Policies  PolicyConfig        `mapstructure:"policies"`  // Rego policies evaluated against elevation requests
+	Approvers ApproverConfig      `mapstructure:"approvers"` // Working hours used to route approvals
+	Freeze    models.FreezeConfig `mapstructure:"freeze"`    // Change freeze calendars
+
+	// Lua script whose authorize function is called before each grant, for
+	// logic that doesn't fit the roles and policies
+	AuthorizationHook string `mapstructure:"authorization_hook"`
+
+	// Sweep revoking grants created by thand that no elevation holds
+	Reconcile models.ReconcileConfig `mapstructure:"reconcile"`
+
+	// Security events e.g. failed logins, kept apart from the access logs
+	AuditLog models.AuditLogConfig `mapstructure:"audit_log"`
+
+	// Values workflows can reference e.g. ${ $context.vars.jira_project },
+	// overridden by provider and role vars
+	Vars map[string]any `mapstructure:"vars"`
 
 	// This is ONLY if the agent is running in server mode
 	// and you want to use https://www.thand.io hosted services
 	Thand models.ThandConfig `mapstructure:"thand"`
+	Sync  models.SyncConfig  `mapstructure:"sync"` // How to handle failures syncing with the thand server
 
 	// Internal mode of operation
-	mode   Mode
-	logger thandLogger
-	mu     sync.RWMutex
+	mode       Mode
+	logger     thandLogger
+	mu         sync.RWMutex
+	configFile string // The config file loaded, empty if none was found
 
 	// Cached services client
 	initializeServiceClientOnce sync.Once
 	servicesClient              models.ServicesClientImpl
+
+	// Cached provider health checks
+	providerHealthMu sync.Mutex
+	providerHealth   map[string]*models.ProviderHealth
+
+	// Change freeze calendars fetched by name
+	freezeCacheMu sync.Mutex
+	freezeCache   map[string]*freezeCacheEntry
+
+	// Agent permission checks from startup
+	agentPermissionsMu sync.Mutex
+	agentPermissions   map[string]*models.AgentPermissionsResult
+
+	// Cached permission alias expansions by alias and provider type
+	permissionAliasesMu sync.Mutex
+	permissionAliases   map[permissionAliasKey][]string
+
+	// GeoIP database opened on first lookup
+	geoReaderOnce sync.Once
+	geoReader     *geoip2.Reader
+
+	// Buffered notifications for targets in digest mode
+	notificationDigestsOnce sync.Once
+	notificationDigests     *NotificationDigests
+
+	// Sweep reconciling provider grants with running elevations
+	grantReconcilerOnce sync.Once
+	grantReconciler     *GrantReconciler
+
+	// Compiled authorization hook, nil without one
+	authorizationHook *lua.FunctionProto
 }
 
 func (c *Config) GetSecret() string {
@@ -120,6 +176,12 @@ func (c *Config) GetServices() models.ServicesClientImpl {
 			return
 		}
 		c.servicesClient = newClient
+
+		// Config values and workflow vars can reference vault secrets
+		if newClient.HasVault() {
+			models.RegisterSecretResolver(
+				VaultSecretScheme, newVaultSecretResolver(newClient.GetVault()))
+		}
 	})
 
 	return c.servicesClient
@@ -145,6 +207,45 @@ func (c *Config) GetProviderByName(name string) (*models.Provider, error) {
 	return nil, fmt.Errorf("provider not found: %s", name)
 }
 
+// GetRBAC returns the role management of the named provider, or an error
+// if it isn't configured or can't grant roles
+func (c *Config) GetRBAC(name string) (models.ProviderRoleBasedAccessControl, error) {
+
+	provider, err := c.GetProviderByName(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.GetRBAC()
+}
+
+// GetAuthorizor returns the authentication of the named provider, or an
+// error if it isn't configured or can't sign users in
+func (c *Config) GetAuthorizor(name string) (models.ProviderAuthorizor, error) {
+
+	provider, err := c.GetProviderByName(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.GetAuthorizor()
+}
+
+// GetNotifier returns the notifications of the named provider, or an error
+// if it isn't configured or can't send them
+func (c *Config) GetNotifier(name string) (models.ProviderNotifier, error) {
+
+	provider, err := c.GetProviderByName(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.GetNotifier()
+}
+
 func (c *Config) GetProvidersByCapability(capability ...models.ProviderCapability) map[string]models.Provider {
 	return c.GetProvidersByCapabilityWithUser(nil, capability...)
 }
@@ -178,6 +279,61 @@ func (c *Config) GetProvidersByCapabilityWithUser(user *models.User, capability
 	return providers
 }
 
+// GetProviderCapabilities returns the enabled providers, with a client,
+// that support each capability. All capabilities are returned when none
+// are given.
+func (c *Config) GetProviderCapabilities(user *models.User, capabilities ...models.ProviderCapability) map[models.ProviderCapability][]string {
+
+	if len(capabilities) == 0 {
+		capabilities = models.ProviderCapabilities
+	}
+
+	result := make(map[models.ProviderCapability][]string, len(capabilities))
+
+	for _, capability := range capabilities {
+		providers := slices.Sorted(maps.Keys(
+			c.GetProvidersByCapabilityWithUser(user, capability)))
+
+		if providers == nil {
+			providers = []string{}
+		}
+
+		result[capability] = providers
+	}
+
+	return result
+}
+
+// ValidateProviderCapability checks that a provider, referenced by its key,
+// name or provider type, is configured and supports the capability
+func (c *Config) ValidateProviderCapability(name string, capability models.ProviderCapability) error {
+
+	configured := false
+
+	for key, provider := range c.Providers.Definitions {
+
+		if key != name && provider.Name != name && provider.Provider != name {
+			continue
+		}
+
+		if provider.GetClient() == nil || !provider.Enabled {
+			continue
+		}
+
+		configured = true
+
+		if provider.GetClient().HasCapability(capability) {
+			return nil
+		}
+	}
+
+	if !configured {
+		return fmt.Errorf("provider not configured: %s", name)
+	}
+
+	return fmt.Errorf("provider %s does not support the %s capability", name, capability)
+}
+
 func (c *Config) GetWorkflowByName(name string) (*models.Workflow, error) {
 	if workflow, exists := c.Workflows.Definitions[name]; exists {
 		return &workflow, nil
@@ -222,6 +378,12 @@ type RoleConfig struct {
 	URL   *model.Endpoint `mapstructure:"url" json:"url"`
 	Vault string          `mapstructure:"vault" json:"vault"` // vault secret / path to use
 
+	// Default policy for reasons, roles can override it
+	ReasonPolicy *models.ReasonPolicy `mapstructure:"reason_policy" json:"reason_policy,omitempty"`
+
+	// Abstract permissions roles can reference as alias:<name>
+	Aliases map[string]models.PermissionAlias `mapstructure:"aliases" json:"aliases,omitempty"`
+
 	// Store everything in memory
 	Definitions map[string]models.Role `mapstructure:",remain" json:"definitions"`
 
@@ -282,6 +444,13 @@ type ProviderConfig struct {
 	// Load dynamic provider configs
 	Plugins ProviderPluginConfig `mapstructure:"plugins" json:"plugins"`
 
+	// How long each provider has to initialize before it's skipped
+	InitializeTimeout time.Duration `mapstructure:"initialize_timeout" json:"initialize_timeout"`
+
+	// Config shared by every provider e.g. region. Each provider's own
+	// config takes precedence
+	Defaults *models.BasicConfig `mapstructure:"defaults" json:"defaults,omitempty"`
+
 	// Load providers directly from config using mapstructure:",remain"
 	Definitions map[string]models.Provider `mapstructure:",remain" json:"definitions"`
 }
@@ -290,6 +459,70 @@ func (p *ProviderConfig) GetDefinitions() map[string]models.Provider {
 	return p.Definitions
 }
 
+// GetRedacted returns a copy of the provider config with every secret
+// replaced by a placeholder so it can leave the agent
+func (p ProviderConfig) GetRedacted() ProviderConfig {
+
+	p.Defaults = p.Defaults.Redact()
+
+	definitions := make(map[string]models.Provider, len(p.Definitions))
+
+	for name, provider := range p.Definitions {
+		definitions[name] = provider.Redact(getProviderSecretKeys(provider.Provider)...)
+	}
+
+	p.Definitions = definitions
+
+	return p
+}
+
+// GetConfigWithDefaults returns the provider's config layered over the
+// shared defaults
+func (p *ProviderConfig) GetConfigWithDefaults(provider *models.Provider) *models.BasicConfig {
+	if p.Defaults == nil || len(*p.Defaults) == 0 {
+		return provider.Config
+	}
+	return provider.Config.WithDefaults(p.Defaults)
+}
+
+// DefaultProviderInitializeTimeout is used when no initialize timeout is
+// configured
+const DefaultProviderInitializeTimeout = 2 * time.Minute
+
+func (p *ProviderConfig) GetInitializeTimeout() time.Duration {
+	if p.InitializeTimeout <= 0 {
+		return DefaultProviderInitializeTimeout
+	}
+	return p.InitializeTimeout
+}
+
+type PolicyConfig struct {
+	// Load policies directly from config using mapstructure:",remain"
+	Definitions map[string]models.Policy `mapstructure:",remain" json:"definitions"`
+
+	// Compiled policies ready for evaluation
+	prepared map[string]rego.PreparedEvalQuery
+}
+
+func (p *PolicyConfig) HasPolicies() bool {
+	return len(p.Definitions) > 0
+}
+
+type ApproverConfig struct {
+	// Working hours of approvers or groups of approvers keyed by name
+	Schedules map[string]models.ApproverSchedule `mapstructure:"schedules" json:"schedules"`
+	// Delegation decides how approvers' delegations are applied
+	Delegation models.DelegationConfig `mapstructure:"delegation" json:"delegation"`
+}
+
+func (c *Config) GetApproverSchedules() map[string]models.ApproverSchedule {
+	return c.Approvers.Schedules
+}
+
+func (c *Config) GetDelegationConfig() *models.DelegationConfig {
+	return &c.Approvers.Delegation
+}
+
 func (p *ProviderConfig) GetProviderByName(name string) (*models.Provider, error) {
 	if provider, exists := p.Definitions[name]; exists {
 		return &provider, nil
@@ -320,7 +553,11 @@ func (c *Config) GetLocalServerUrl() string {
 	if hostname == "0.0.0.0" {
 		hostname = "localhost"
 	}
-	return fmt.Sprintf("http://%s:%d", hostname, c.Server.Port)
+	scheme := "http"
+	if c.Server.TLS.IsEnabled() {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, hostname, c.Server.Port)
 }
 
 func (c *Config) GetLoginServerUrl() string {
@@ -331,6 +568,15 @@ func (c *Config) GetLoginServerUrl() string {
 		"/")
 }
 
+// GetRootUrl returns the public URL of the server. Defaults to the login
+// server URL.
+func (c *Config) GetRootUrl() string {
+	if len(c.Server.RootUrl) > 0 {
+		return strings.TrimSuffix(c.Server.RootUrl, "/")
+	}
+	return c.GetLoginServerUrl()
+}
+
 func (c *Config) GetThandServerUrl() string {
 	return strings.TrimSuffix(fmt.Sprintf(
 		"%s/%s",
@@ -411,6 +657,12 @@ func (c *Config) GetLoginServerHostname() string {
 	return hostname.Hostname()
 }
 
+// GetConfigFile returns the path of the config file that was loaded, or an
+// empty string if the config only came from defaults and the environment
+func (c *Config) GetConfigFile() string {
+	return c.configFile
+}
+
 func (c *Config) SetLoginServer(loginServer string) error {
 	// parse url
 	parsedUrl, err := url.Parse(loginServer)
@@ -643,6 +895,9 @@ type TemplateData struct {
 	User        *models.User
 	Version     string
 	Status      string
+	// StaticVersion is a hash of the embedded static assets appended to
+	// their URLs so browsers fetch them again after an upgrade
+	StaticVersion string
 }
 
 type PreflightRequest struct {
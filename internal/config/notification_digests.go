@@ -0,0 +1,391 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	emailProvider "github.com/thand-io/agent/internal/providers/email"
+	slackProvider "github.com/thand-io/agent/internal/providers/slack"
+)
+
+// notificationDigestInterval is how often buffered digests are checked
+const notificationDigestInterval = 15 * time.Second
+
+// maxSlackDigestEntries keeps digests under Slack's 50 block limit, larger
+// digests are split across messages
+const maxSlackDigestEntries = 15
+
+// NotificationDigests buffers notifications for targets in digest mode.
+// The buffer is held in memory by the server sending the notifications and
+// is flushed on shutdown, so workflows are never kept waiting on it.
+type NotificationDigests struct {
+	send func(ctx context.Context, provider string, notification models.NotificationRequest) error
+	now  func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]*notificationDigest
+
+	startOnce sync.Once
+	stop      chan struct{}
+}
+
+// notificationDigest is the buffer for a single provider and recipient
+type notificationDigest struct {
+	provider      string
+	digest        *models.NotificationDigest
+	started       time.Time
+	notifications []models.NotificationRequest
+	entries       []*models.NotificationDigestEntry
+}
+
+// GetNotificationDigests returns the digest buffer for the notifications
+// sent by this server
+func (c *Config) GetNotificationDigests() *NotificationDigests {
+
+	c.notificationDigestsOnce.Do(func() {
+		c.notificationDigests = newNotificationDigests(c.sendNotification)
+	})
+
+	return c.notificationDigests
+}
+
+// sendNotification sends the notification through the named provider
+func (c *Config) sendNotification(ctx context.Context, provider string, notification models.NotificationRequest) error {
+
+	providerConfig, err := c.Providers.GetProviderByName(provider)
+
+	if err != nil {
+		return fmt.Errorf("failed to get provider: %w", err)
+	}
+
+	if providerConfig.GetClient() == nil {
+		return fmt.Errorf("provider %s is not initialized", provider)
+	}
+
+	notifier, err := providerConfig.GetNotifier()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = notifier.SendNotification(ctx, notification)
+
+	return err
+}
+
+func newNotificationDigests(
+	send func(ctx context.Context, provider string, notification models.NotificationRequest) error,
+) *NotificationDigests {
+	return &NotificationDigests{
+		send:    send,
+		now:     time.Now,
+		pending: map[string]*notificationDigest{},
+		stop:    make(chan struct{}),
+	}
+}
+
+// Add buffers the notification if its target is in digest mode. Returns
+// false if the notification must be sent immediately.
+func (d *NotificationDigests) Add(provider string, notification models.NotificationRequest) bool {
+
+	digest, entry := notification.GetDigest()
+
+	if digest == nil || entry == nil {
+		return false
+	}
+
+	key := strings.Join([]string{provider, entry.Recipient}, "\x00")
+	notificationID := notification.GetNotificationID()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	pending, found := d.pending[key]
+
+	if !found {
+		pending = &notificationDigest{
+			provider: provider,
+			digest:   digest,
+			started:  d.now(),
+		}
+		d.pending[key] = pending
+	}
+
+	// A retried delivery is already in the digest
+	for _, buffered := range pending.notifications {
+		if len(notificationID) > 0 && buffered.GetNotificationID() == notificationID {
+			return true
+		}
+	}
+
+	pending.notifications = append(pending.notifications, notification)
+	pending.entries = append(pending.entries, entry)
+
+	logrus.WithFields(logrus.Fields{
+		"provider":    provider,
+		"recipient":   entry.Recipient,
+		"workflow_id": entry.WorkflowID,
+		"buffered":    len(pending.notifications),
+	}).Debug("Added notification to digest")
+
+	d.startOnce.Do(func() {
+		go d.run()
+	})
+
+	return true
+}
+
+// run flushes the digests whose window has passed until stopped
+func (d *NotificationDigests) run() {
+
+	ticker := time.NewTicker(notificationDigestInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			d.flush(context.Background(), false)
+		}
+	}
+}
+
+// Flush sends every buffered digest, regardless of its window. Called on
+// shutdown so buffered notifications are not lost.
+func (d *NotificationDigests) Flush(ctx context.Context) error {
+	return d.flush(ctx, true)
+}
+
+// Stop flushes the buffered digests and stops the background flush
+func (d *NotificationDigests) Stop(ctx context.Context) error {
+
+	d.mu.Lock()
+	select {
+	case <-d.stop:
+	default:
+		close(d.stop)
+	}
+	d.mu.Unlock()
+
+	return d.Flush(ctx)
+}
+
+func (d *NotificationDigests) flush(ctx context.Context, all bool) error {
+
+	now := d.now()
+
+	// Take the due digests so sends happen outside the lock
+	d.mu.Lock()
+	due := []*notificationDigest{}
+	for key, pending := range d.pending {
+		if all || now.Sub(pending.started) >= pending.digest.GetWindow() {
+			due = append(due, pending)
+			delete(d.pending, key)
+		}
+	}
+	d.mu.Unlock()
+
+	var sendErrors []error
+
+	for _, pending := range due {
+		if err := d.sendDigest(ctx, pending); err != nil {
+			logrus.WithError(err).WithField("provider", pending.provider).
+				Error("Failed to send notification digest")
+			sendErrors = append(sendErrors, err)
+		}
+	}
+
+	return errors.Join(sendErrors...)
+}
+
+// sendDigest sends the buffered notifications as a single message, or
+// individually if there are too few of them
+func (d *NotificationDigests) sendDigest(ctx context.Context, pending *notificationDigest) error {
+
+	if len(pending.notifications) < pending.digest.GetMinBatch() {
+		var sendErrors []error
+		for _, notification := range pending.notifications {
+			if err := d.send(ctx, pending.provider, notification); err != nil {
+				sendErrors = append(sendErrors, err)
+			}
+		}
+		return errors.Join(sendErrors...)
+	}
+
+	messages, err := renderNotificationDigest(pending)
+
+	if err != nil {
+		return err
+	}
+
+	var sendErrors []error
+	for _, message := range messages {
+		if err := d.send(ctx, pending.provider, message); err != nil {
+			sendErrors = append(sendErrors, err)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"provider":      pending.provider,
+		"notifications": len(pending.notifications),
+	}).Info("Sent notification digest")
+
+	return errors.Join(sendErrors...)
+}
+
+// renderNotificationDigest combines the buffered notifications into the
+// provider's message format. The message is sent to the same destination
+// as the buffered notifications.
+func renderNotificationDigest(pending *notificationDigest) ([]models.NotificationRequest, error) {
+
+	first := pending.notifications[0]
+
+	switch {
+	case strings.Compare(pending.provider, slackProvider.SlackProviderName) == 0:
+
+		var messages []models.NotificationRequest
+
+		for start := 0; start < len(pending.entries); start += maxSlackDigestEntries {
+			end := min(start+maxSlackDigestEntries, len(pending.entries))
+
+			message := models.NotificationRequest{}
+			err := common.ConvertInterfaceToInterface(slackProvider.SlackNotificationRequest{
+				Text:   getDigestTitle(pending.entries),
+				Blocks: slack.Blocks{BlockSet: createDigestSlackBlocks(pending.entries, start, end)},
+			}, &message)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert slack digest: %w", err)
+			}
+
+			message["channel"] = first["channel"]
+			message["recipients"] = first["recipients"]
+			messages = append(messages, message)
+		}
+
+		return messages, nil
+
+	case strings.HasPrefix(pending.provider, emailProvider.EmailProviderName):
+
+		plainText, htmlBody := createDigestEmailBody(pending.entries)
+
+		message := models.NotificationRequest{}
+		err := common.ConvertInterfaceToInterface(models.EmailNotificationRequest{
+			Subject: getDigestTitle(pending.entries),
+			Body: models.EmailNotificationBody{
+				Text: plainText,
+				HTML: htmlBody,
+			},
+		}, &message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert email digest: %w", err)
+		}
+
+		message["To"] = first["To"]
+
+		return []models.NotificationRequest{message}, nil
+	}
+
+	return nil, fmt.Errorf("notification digests are not supported for provider: %s", pending.provider)
+}
+
+func getDigestTitle(entries []*models.NotificationDigestEntry) string {
+	return fmt.Sprintf("%d access requests are waiting for you", len(entries))
+}
+
+// createDigestSlackBlocks lists the entries with their own approval links
+func createDigestSlackBlocks(entries []*models.NotificationDigestEntry, start int, end int) []slack.Block {
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(
+			slack.NewTextBlockObject(slack.PlainTextType, getDigestTitle(entries), false, false),
+		),
+	}
+
+	for _, entry := range entries[start:end] {
+
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, entry.Summary, false, false),
+			nil, nil,
+		))
+
+		var buttons []slack.BlockElement
+
+		if len(entry.ApproveURL) > 0 {
+			buttons = append(buttons, slack.NewButtonBlockElement(
+				fmt.Sprintf("%s-%s", entry.WorkflowID, "approve"), "Approve",
+				slack.NewTextBlockObject(slack.PlainTextType, "Approve", false, false),
+			).WithURL(entry.ApproveURL).WithStyle(slack.StylePrimary))
+		}
+
+		if len(entry.DenyURL) > 0 {
+			buttons = append(buttons, slack.NewButtonBlockElement(
+				fmt.Sprintf("%s-%s", entry.WorkflowID, "deny"), "Deny",
+				slack.NewTextBlockObject(slack.PlainTextType, "Deny", false, false),
+			).WithURL(entry.DenyURL).WithStyle(slack.StyleDanger))
+		}
+
+		if len(entry.ViewURL) > 0 {
+			buttons = append(buttons, slack.NewButtonBlockElement(
+				fmt.Sprintf("%s-%s", entry.WorkflowID, "view_request"), "View Request",
+				slack.NewTextBlockObject(slack.PlainTextType, "View Request", false, false),
+			).WithURL(entry.ViewURL))
+		}
+
+		if len(buttons) > 0 {
+			// Block IDs must be unique in a message, the workflow ID
+			// ties each set of actions to its request
+			blocks = append(blocks, slack.NewActionBlock(
+				fmt.Sprintf("digest_%s", entry.WorkflowID), buttons...))
+		}
+
+		blocks = append(blocks, slack.NewDividerBlock())
+	}
+
+	return blocks
+}
+
+// createDigestEmailBody lists the entries with their own approval links
+func createDigestEmailBody(entries []*models.NotificationDigestEntry) (string, string) {
+
+	var plainText strings.Builder
+	var htmlBody strings.Builder
+
+	plainText.WriteString(getDigestTitle(entries) + "\n\n")
+	htmlBody.WriteString(fmt.Sprintf("<h2>%s</h2><ul>", html.EscapeString(getDigestTitle(entries))))
+
+	for _, entry := range entries {
+
+		plainText.WriteString(fmt.Sprintf("- %s\n", entry.Summary))
+		htmlBody.WriteString(fmt.Sprintf("<li><p>%s</p><p>", html.EscapeString(entry.Summary)))
+
+		for _, link := range []struct{ label, url string }{
+			{"Approve", entry.ApproveURL},
+			{"Deny", entry.DenyURL},
+			{"View Request", entry.ViewURL},
+		} {
+			if len(link.url) == 0 {
+				continue
+			}
+			plainText.WriteString(fmt.Sprintf("  %s: %s\n", link.label, link.url))
+			htmlBody.WriteString(fmt.Sprintf(`<a href="%s">%s</a> `,
+				html.EscapeString(link.url), link.label))
+		}
+
+		plainText.WriteString("\n")
+		htmlBody.WriteString("</p></li>")
+	}
+
+	htmlBody.WriteString("</ul>")
+
+	return plainText.String(), htmlBody.String()
+}
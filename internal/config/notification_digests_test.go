@@ -0,0 +1,136 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	slackProvider "github.com/thand-io/agent/internal/providers/slack"
+)
+
+type sentNotification struct {
+	provider     string
+	notification models.NotificationRequest
+}
+
+func newTestNotificationDigests(now *time.Time) (*NotificationDigests, func() []sentNotification) {
+
+	var mu sync.Mutex
+	var sent []sentNotification
+
+	digests := newNotificationDigests(func(ctx context.Context, provider string, notification models.NotificationRequest) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, sentNotification{provider: provider, notification: notification})
+		return nil
+	})
+	digests.now = func() time.Time { return *now }
+
+	return digests, func() []sentNotification {
+		mu.Lock()
+		defer mu.Unlock()
+		return sent
+	}
+}
+
+func newDigestNotification(workflowID string, digest *models.NotificationDigest) models.NotificationRequest {
+	return models.NotificationRequest{
+		"channel":                    "#access-approvals",
+		models.NotificationIDKey:     models.NewNotificationID(workflowID, "approvals", "slack", "#access-approvals"),
+		models.NotificationDigestKey: digest,
+		models.NotificationDigestEntryKey: &models.NotificationDigestEntry{
+			WorkflowID: workflowID,
+			Recipient:  "#access-approvals",
+			Summary:    "*Alice* requested *admin*",
+			ApproveURL: "https://thand.example.com/approve/" + workflowID,
+			DenyURL:    "https://thand.example.com/deny/" + workflowID,
+		},
+	}
+}
+
+func TestNotificationDigests(t *testing.T) {
+
+	digest := &models.NotificationDigest{Window: "5m", MinBatch: 3}
+
+	t.Run("notifications without a digest are sent immediately", func(t *testing.T) {
+		now := time.Now()
+		digests, _ := newTestNotificationDigests(&now)
+
+		assert.False(t, digests.Add("slack", models.NotificationRequest{"channel": "#access-approvals"}))
+	})
+
+	t.Run("high priority notifications are sent immediately", func(t *testing.T) {
+		now := time.Now()
+		digests, _ := newTestNotificationDigests(&now)
+
+		notification := newDigestNotification("wf-1", digest)
+		notification[models.NotificationPriorityKey] = models.NotificationPriorityHigh
+
+		assert.False(t, digests.Add("slack", notification))
+	})
+
+	t.Run("notifications are combined after the window", func(t *testing.T) {
+		now := time.Now()
+		digests, sent := newTestNotificationDigests(&now)
+
+		for _, workflowID := range []string{"wf-1", "wf-2", "wf-3"} {
+			require.True(t, digests.Add("slack", newDigestNotification(workflowID, digest)))
+		}
+
+		// A retried delivery is not added twice
+		require.True(t, digests.Add("slack", newDigestNotification("wf-1", digest)))
+
+		require.NoError(t, digests.flush(context.Background(), false))
+		assert.Empty(t, sent(), "digest must wait for the window")
+
+		now = now.Add(5 * time.Minute)
+		require.NoError(t, digests.flush(context.Background(), false))
+		require.Len(t, sent(), 1)
+
+		var message slackProvider.SlackNotificationRequest
+		require.NoError(t, common.ConvertMapToInterface(sent()[0].notification, &message))
+
+		assert.Equal(t, "#access-approvals", message.To)
+		assert.Equal(t, "3 access requests are waiting for you", message.Text)
+
+		// Each request keeps the approval links for its own workflow
+		data, err := message.Blocks.MarshalJSON()
+		require.NoError(t, err)
+		for _, workflowID := range []string{"wf-1", "wf-2", "wf-3"} {
+			assert.Contains(t, string(data), "https://thand.example.com/approve/"+workflowID)
+			assert.Contains(t, string(data), "https://thand.example.com/deny/"+workflowID)
+		}
+	})
+
+	t.Run("small batches are sent individually", func(t *testing.T) {
+		now := time.Now()
+		digests, sent := newTestNotificationDigests(&now)
+
+		first := newDigestNotification("wf-1", digest)
+		second := newDigestNotification("wf-2", digest)
+		require.True(t, digests.Add("slack", first))
+		require.True(t, digests.Add("slack", second))
+
+		now = now.Add(5 * time.Minute)
+		require.NoError(t, digests.flush(context.Background(), false))
+
+		require.Len(t, sent(), 2)
+		assert.Equal(t, first.GetNotificationID(), sent()[0].notification.GetNotificationID())
+		assert.Equal(t, second.GetNotificationID(), sent()[1].notification.GetNotificationID())
+	})
+
+	t.Run("stop flushes before the window", func(t *testing.T) {
+		now := time.Now()
+		digests, sent := newTestNotificationDigests(&now)
+
+		require.True(t, digests.Add("slack", newDigestNotification("wf-1", digest)))
+
+		require.NoError(t, digests.Stop(context.Background()))
+		assert.Len(t, sent(), 1)
+	})
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// LoadPolicies reads and compiles the configured rego policies. Any policy
+// that cannot be loaded or fails to compile is returned as an error so
+// invalid guardrails are caught when the config is loaded.
+func (c *Config) LoadPolicies() (map[string]rego.PreparedEvalQuery, error) {
+
+	prepared := make(map[string]rego.PreparedEvalQuery)
+
+	for policyKey, policy := range c.Policies.Definitions {
+
+		module, err := loadPolicyModule(&policy)
+
+		if err != nil {
+			return nil, fmt.Errorf("policy '%s': %w", policyKey, err)
+		}
+
+		query, err := rego.New(
+			rego.Query(policy.GetEntrypoint()),
+			rego.Module(fmt.Sprintf("%s.rego", policyKey), module),
+		).PrepareForEval(context.Background())
+
+		if err != nil {
+			return nil, fmt.Errorf("policy '%s': failed to compile: %w", policyKey, err)
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"policy":     policyKey,
+			"entrypoint": policy.GetEntrypoint(),
+		}).Debugln("Compiled policy")
+
+		prepared[policyKey] = query
+	}
+
+	return prepared, nil
+}
+
+// loadPolicyModule returns the rego source for a policy from either the
+// embedded module, a file path or a url
+func loadPolicyModule(policy *models.Policy) (string, error) {
+
+	if len(policy.Rego) > 0 {
+		return policy.Rego, nil
+	} else if len(policy.Path) > 0 {
+
+		data, err := os.ReadFile(policy.Path)
+
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", policy.Path, err)
+		}
+
+		return string(data), nil
+
+	} else if policy.URL != nil {
+
+		resp, err := common.InvokeHttpRequest(&model.HTTPArguments{
+			Method:   http.MethodGet,
+			Endpoint: policy.URL,
+		})
+
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch from URL %s: %w", policy.URL.String(), err)
+		}
+
+		if resp.StatusCode() != http.StatusOK {
+			return "", fmt.Errorf("failed to fetch from URL %s: status %d", policy.URL.String(), resp.StatusCode())
+		}
+
+		return string(resp.Body()), nil
+	}
+
+	return "", fmt.Errorf("one of rego, path or url must be provided")
+}
+
+// EvaluatePolicies evaluates the input against every configured policy and
+// returns the combined decision. Policies are evaluated in name order so
+// the messages are stable for the same input.
+func (c *Config) EvaluatePolicies(ctx context.Context, input *models.PolicyInput) (*models.PolicyDecision, error) {
+
+	decision := models.NewPolicyDecision()
+
+	requiresApproval, err := c.RoleRequiresApproval(input.Requester, input.Role)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if requiresApproval {
+		decision.Result = models.PolicyResultRequireApproval
+		decision.Messages = append(decision.Messages, fmt.Sprintf(
+			"role '%s' requires approval in the %s environment", input.Role.Name, c.Environment.Name))
+	}
+
+	// Evaluate the freeze before the policies so they can use input.freeze
+	if input.Freeze == nil && c.Freeze.IsEnabled() {
+
+		freeze, err := c.EvaluateFreeze(ctx, input.Requester, input.Role, input.Workflow, time.Unix(input.Time.Unix, 0))
+
+		if err != nil {
+			return nil, err
+		}
+
+		input.Freeze = freeze
+	}
+
+	decision.Merge(newFreezePolicyDecision(input.Freeze))
+	decision.Freeze = input.Freeze
+
+	if len(c.Policies.prepared) == 0 {
+		return decision, nil
+	}
+
+	inputMap, err := common.ConvertInterfaceToMap(input)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert policy input: %w", err)
+	}
+
+	policyKeys := make([]string, 0, len(c.Policies.prepared))
+	for policyKey := range c.Policies.prepared {
+		policyKeys = append(policyKeys, policyKey)
+	}
+	slices.Sort(policyKeys)
+
+	for _, policyKey := range policyKeys {
+
+		query := c.Policies.prepared[policyKey]
+
+		results, err := query.Eval(ctx, rego.EvalInput(inputMap))
+
+		if err != nil {
+			return nil, fmt.Errorf("policy '%s': failed to evaluate: %w", policyKey, err)
+		}
+
+		policyDecision, err := parsePolicyResults(results)
+
+		if err != nil {
+			return nil, fmt.Errorf("policy '%s': %w", policyKey, err)
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"policy": policyKey,
+			"result": policyDecision.Result,
+		}).Debugln("Evaluated policy")
+
+		decision.Merge(policyDecision)
+	}
+
+	return decision, nil
+}
+
+// newFreezePolicyDecision applies the role or workflow's during_freeze
+// while a freeze is active
+func newFreezePolicyDecision(freeze *models.FreezeStatus) *models.PolicyDecision {
+
+	switch {
+	case freeze.IsDenied():
+		return &models.PolicyDecision{
+			Result:   models.PolicyResultDeny,
+			Messages: []string{fmt.Sprintf("requests are blocked during the change freeze: %s", freeze.GetDescription())},
+		}
+	case freeze.RequiresApproval():
+		return &models.PolicyDecision{
+			Result:    models.PolicyResultRequireApproval,
+			Messages:  []string{fmt.Sprintf("requests require extra approval during the change freeze: %s", freeze.GetDescription())},
+			Approvers: freeze.Approvers,
+		}
+	}
+
+	return nil
+}
+
+// parsePolicyResults converts the entrypoint value into a decision. The
+// entrypoint can return a result string e.g. "deny" or an object with
+// result, messages and approvers. An undefined entrypoint allows the request.
+func parsePolicyResults(results rego.ResultSet) (*models.PolicyDecision, error) {
+
+	decision := models.NewPolicyDecision()
+
+	if len(results) == 0 || len(results[0].Expressions) == 0 {
+		return decision, nil
+	}
+
+	switch value := results[0].Expressions[0].Value.(type) {
+	case string:
+		decision.Result = models.PolicyResult(strings.ToLower(value))
+	case map[string]any:
+		if err := common.ConvertInterfaceToInterface(value, decision); err != nil {
+			return nil, fmt.Errorf("invalid decision: %w", err)
+		}
+		decision.Result = models.PolicyResult(strings.ToLower(string(decision.Result)))
+	default:
+		return nil, fmt.Errorf("decision must be a string or object, got %T", value)
+	}
+
+	switch decision.Result {
+	case "":
+		decision.Result = models.PolicyResultAllow
+	case models.PolicyResultAllow, models.PolicyResultDeny, models.PolicyResultRequireApproval:
+	default:
+		return nil, fmt.Errorf("unknown decision result: %s", decision.Result)
+	}
+
+	return decision, nil
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+const businessHoursPolicy = `package thand
+
+import rego.v1
+
+default decision := {"result": "allow"}
+
+decision := {"result": "deny", "messages": ["prod admin is only available during business hours"]} if {
+	input.role.name == "prod-admin"
+	input.time.hour < 9
+}
+
+decision := {"result": "require_approval", "messages": ["long elevation"], "approvers": ["security@example.com"]} if {
+	input.duration.seconds > 14400
+}
+`
+
+func newPolicyTestConfig(t *testing.T, policies map[string]models.Policy) *Config {
+	c := &Config{
+		Policies: PolicyConfig{Definitions: policies},
+	}
+	prepared, err := c.LoadPolicies()
+	require.NoError(t, err)
+	c.Policies.prepared = prepared
+	return c
+}
+
+func newPolicyTestRequest(role string, duration string) *models.ElevateRequestInternal {
+	return &models.ElevateRequestInternal{
+		ElevateRequest: models.ElevateRequest{
+			Role:      &models.Role{Name: role},
+			Providers: []string{"aws-prod"},
+			Reason:    "Investigating incident",
+			Duration:  duration,
+		},
+		User: &models.User{Email: "alice@example.com"},
+	}
+}
+
+func TestEvaluatePolicies(t *testing.T) {
+	c := newPolicyTestConfig(t, map[string]models.Policy{
+		"business-hours": {Rego: businessHoursPolicy},
+	})
+
+	ctx := context.Background()
+	morning := time.Date(2025, 1, 6, 7, 0, 0, 0, time.UTC)
+	afternoon := time.Date(2025, 1, 6, 14, 0, 0, 0, time.UTC)
+
+	decision, err := c.EvaluatePolicies(ctx, models.NewPolicyInput(
+		newPolicyTestRequest("prod-admin", "PT1H"), morning))
+	require.NoError(t, err)
+	assert.True(t, decision.IsDenied())
+	assert.Equal(t, []string{"prod admin is only available during business hours"}, decision.Messages)
+
+	decision, err = c.EvaluatePolicies(ctx, models.NewPolicyInput(
+		newPolicyTestRequest("prod-admin", "PT1H"), afternoon))
+	require.NoError(t, err)
+	assert.Equal(t, models.PolicyResultAllow, decision.Result)
+
+	decision, err = c.EvaluatePolicies(ctx, models.NewPolicyInput(
+		newPolicyTestRequest("readonly", "PT8H"), afternoon))
+	require.NoError(t, err)
+	assert.True(t, decision.RequiresApproval())
+	assert.Equal(t, []string{"security@example.com"}, decision.Approvers)
+}
+
+func TestEvaluatePolicies_StringDecisionAndPath(t *testing.T) {
+	policyPath := filepath.Join(t.TempDir(), "deny.rego")
+	require.NoError(t, os.WriteFile(policyPath, []byte(`package guardrails
+
+import rego.v1
+
+verdict := "deny" if input.reason == ""
+`), 0o600))
+
+	c := newPolicyTestConfig(t, map[string]models.Policy{
+		"guardrails": {Path: policyPath, Entrypoint: "data.guardrails.verdict"},
+	})
+
+	request := newPolicyTestRequest("readonly", "PT1H")
+	request.Reason = ""
+
+	decision, err := c.EvaluatePolicies(context.Background(), models.NewPolicyInput(request, time.Now()))
+	require.NoError(t, err)
+	assert.True(t, decision.IsDenied())
+
+	// Undefined decisions allow the request
+	request.Reason = "Deploying a fix"
+	decision, err = c.EvaluatePolicies(context.Background(), models.NewPolicyInput(request, time.Now()))
+	require.NoError(t, err)
+	assert.Equal(t, models.PolicyResultAllow, decision.Result)
+}
+
+func TestLoadPolicies_Errors(t *testing.T) {
+	c := &Config{Policies: PolicyConfig{Definitions: map[string]models.Policy{
+		"broken": {Rego: "package thand\n\ndecision := {"},
+	}}}
+	_, err := c.LoadPolicies()
+	assert.ErrorContains(t, err, "broken")
+
+	c = &Config{Policies: PolicyConfig{Definitions: map[string]models.Policy{
+		"empty": {},
+	}}}
+	_, err = c.LoadPolicies()
+	assert.ErrorContains(t, err, "one of rego, path or url must be provided")
+}
@@ -1,13 +1,20 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/go-version"
 	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config/environment"
+	"github.com/thand-io/agent/internal/metrics"
 	"github.com/thand-io/agent/internal/models"
 	"github.com/thand-io/agent/internal/providers"
 
@@ -17,14 +24,18 @@ import (
 	_ "github.com/thand-io/agent/internal/providers/email"
 	_ "github.com/thand-io/agent/internal/providers/gcp"
 	_ "github.com/thand-io/agent/internal/providers/github"
+	_ "github.com/thand-io/agent/internal/providers/jira"
 	_ "github.com/thand-io/agent/internal/providers/kubernetes"
 	_ "github.com/thand-io/agent/internal/providers/oauth2"
 	_ "github.com/thand-io/agent/internal/providers/oauth2.google"
 	_ "github.com/thand-io/agent/internal/providers/okta"
+	_ "github.com/thand-io/agent/internal/providers/opsgenie"
 	_ "github.com/thand-io/agent/internal/providers/salesforce"
 	_ "github.com/thand-io/agent/internal/providers/slack"
 	_ "github.com/thand-io/agent/internal/providers/terraform"
 	_ "github.com/thand-io/agent/internal/providers/thand"
+	_ "github.com/thand-io/agent/internal/providers/vault"
+	_ "github.com/thand-io/agent/internal/providers/zendesk"
 )
 
 // LoadProviders loads providers from a file or URL and maps them to their implementations
@@ -162,10 +173,19 @@ type initResult struct {
 	err      error
 }
 
-// InitializeProviders initializes all providers in parallel using channels
-func (c *Config) InitializeProviders() error {
+// ErrProviderInitializationCancelled is returned when startup is cancelled
+// before all the providers have initialized
+var ErrProviderInitializationCancelled = errors.New("provider initialization cancelled")
 
-	defs := c.GetProviders().Definitions
+// InitializeProviders initializes all providers in parallel using channels.
+// Each provider gets its own deadline derived from ctx, so a hung upstream
+// only holds up its own provider. If ctx is cancelled no providers are
+// applied and ErrProviderInitializationCancelled is returned.
+func (c *Config) InitializeProviders(ctx context.Context) error {
+
+	providerConfig := c.GetProviders()
+	defs := providerConfig.Definitions
+	timeout := providerConfig.GetInitializeTimeout()
 
 	logrus.Debugln("Initializing providers: ", len(defs))
 
@@ -174,7 +194,14 @@ func (c *Config) InitializeProviders() error {
 	// Start goroutines for each provider
 	for providerKey, p := range defs {
 		go func(providerKey string, provider models.Provider) {
-			err := c.initializeSingleProvider(providerKey, &provider)
+			initCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			err := c.initializeSingleProvider(initCtx, providerKey, &provider)
+			if err != nil && errors.Is(initCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+				err = fmt.Errorf("provider did not initialize within %s: %w", timeout, err)
+			}
+
 			resultChan <- initResult{
 				key:      providerKey,
 				provider: &provider,
@@ -183,22 +210,37 @@ func (c *Config) InitializeProviders() error {
 		}(providerKey, p)
 	}
 
+	// Wait for every provider to finish, even when cancelled, so none are
+	// left initializing in the background
+	initialized := make([]initResult, 0, len(defs))
+	for range len(defs) {
+		initialized = append(initialized, <-resultChan)
+	}
+
+	if err := ctx.Err(); err != nil {
+		logrus.WithError(err).Warnln("Provider initialization cancelled")
+		return fmt.Errorf("%w: %w", ErrProviderInitializationCancelled, err)
+	}
+
 	// Collect results from all goroutines
 	results := make(map[string]models.Provider)
-	for i := 0; i < len(defs); i++ {
-		result := <-resultChan
+	for _, result := range initialized {
 		if result.err != nil {
 			logrus.WithError(result.err).Errorln("Failed to initialize provider:", result.key)
+			metrics.SetProviderHealth(result.key, false)
 			// Skip failed providers - don't add to results
 			continue
 		}
 
 		if result.provider.GetClient() == nil {
 			logrus.Errorln("Provider client is nil after initialization:", result.key)
+			metrics.SetProviderHealth(result.key, false)
 			// Skip providers with nil client
 			continue
 		}
 
+		metrics.SetProviderHealth(result.key, true)
+
 		// Check for capabilities for RBAC and Identities
 		if result.provider.GetClient().HasAnyCapability(
 			models.ProviderCapabilityIdentities,
@@ -245,12 +287,22 @@ func (c *Config) InitializeProviders() error {
 	c.Providers.Definitions = results
 	c.mu.Unlock()
 
+	// Missing permissions only fail the first elevation that needs them so
+	// check them up front, without holding up startup
+	if c.IsServer() || c.IsAgent() {
+		go c.CheckAgentPermissions(context.WithoutCancel(ctx))
+	}
+
 	logrus.Debugln("All providers initialized successfully")
 	return nil
 }
 
 // initializeSingleProvider initializes a single provider
-func (c *Config) initializeSingleProvider(providerKey string, p *models.Provider) error {
+func (c *Config) initializeSingleProvider(ctx context.Context, providerKey string, p *models.Provider) error {
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	impl, err := c.getProviderImplementation(providerKey, p.Provider)
 
@@ -258,6 +310,11 @@ func (c *Config) initializeSingleProvider(providerKey string, p *models.Provider
 		return err
 	}
 
+	// Layer the provider's config over the shared defaults before the
+	// references are resolved so the defaults can use them too
+	providerConfig := c.GetProviders()
+	p.Config = providerConfig.GetConfigWithDefaults(p)
+
 	// Before we initialize, we need to check if any of the provider's
 	// config has any environment variable references and resolve them
 	err = p.ResolveConfig(
@@ -268,15 +325,196 @@ func (c *Config) initializeSingleProvider(providerKey string, p *models.Provider
 		return fmt.Errorf("failed to resolve environment variables for provider %s: %w", providerKey, err)
 	}
 
-	if err := impl.Initialize(providerKey, *p); err != nil {
+	// Mask the provider's secrets e.g. tokens and client secrets in logs
+	if p.Config != nil {
+		common.GetRedactor().RegisterSecretsFromConfig(*p.Config)
+
+		for _, secretKey := range getProviderSecretKeys(p.Provider) {
+			if secret, found := p.Config.GetString(secretKey); found {
+				common.GetRedactor().RegisterSecret(secret)
+			}
+		}
+	}
+
+	// Report every problem with the config before initialization stops at
+	// the first one
+	if err := impl.ValidateConfig(p.Config); err != nil {
+		return err
+	}
+
+	if err := impl.Initialize(ctx, providerKey, *p); err != nil {
 		return err
 	}
 
+	// Read only providers are wrapped here, rather than in each provider,
+	// so no implementation can grant access while piloting a provider
+	if p.ReadOnly {
+		logrus.WithField("provider", providerKey).Warnln("Provider is read only, grants will be simulated")
+		impl = providers.NewReadOnlyProvider(impl)
+	}
+
 	p.SetClient(impl)
 	return nil
 }
 
+// TestProviders initializes the named providers, or all providers when no
+// names are given, and runs a smoke check for each of their capabilities.
+// Providers are tested without registering them with temporal.
+func (c *Config) TestProviders(ctx context.Context, names ...string) ([]models.ProviderConnectionResult, error) {
+
+	defs := c.GetProviders().Definitions
+
+	if len(names) == 0 {
+		names = slices.Sorted(maps.Keys(defs))
+	}
+
+	for _, name := range names {
+		if _, exists := defs[name]; !exists {
+			return nil, fmt.Errorf("provider not found: %s", name)
+		}
+	}
+
+	providerResults := make([][]models.ProviderConnectionResult, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		provider := defs[name]
+		wg.Go(func() {
+			providerResults[i] = c.testSingleProvider(ctx, name, &provider)
+		})
+	}
+	wg.Wait()
+
+	var results []models.ProviderConnectionResult
+	for i, providerResult := range providerResults {
+		healthy := true
+		for _, result := range providerResult {
+			healthy = healthy && result.Passed
+		}
+		metrics.SetProviderHealth(names[i], healthy)
+		results = append(results, providerResult...)
+	}
+
+	return results, nil
+}
+
+// ProviderHealthCacheTTL is how long a provider health check is reused
+const ProviderHealthCacheTTL = 30 * time.Second
+
+// GetProviderHealth smoke checks each capability of an initialized
+// provider. Results are cached for ProviderHealthCacheTTL so listing
+// providers doesn't call every provider's API on each request.
+func (c *Config) GetProviderHealth(ctx context.Context, providerKey string) (*models.ProviderHealth, error) {
+
+	provider, exists := c.GetProviders().Definitions[providerKey]
+
+	if !exists {
+		return nil, fmt.Errorf("provider not found: %s", providerKey)
+	}
+
+	client := provider.GetClient()
+
+	if client == nil {
+		return nil, fmt.Errorf("provider not initialized: %s", providerKey)
+	}
+
+	c.providerHealthMu.Lock()
+	cached, found := c.providerHealth[providerKey]
+	c.providerHealthMu.Unlock()
+
+	if found && time.Since(cached.CheckedAt) < ProviderHealthCacheTTL {
+		return cached, nil
+	}
+
+	capabilities := client.GetCapabilities()
+	checks := make([]models.ProviderConnectionResult, 0, len(capabilities))
+
+	for _, capability := range capabilities {
+		start := time.Now()
+		err := client.TestConnection(ctx, capability)
+		checks = append(checks, models.NewProviderConnectionResult(
+			providerKey, capability, time.Since(start), err))
+	}
+
+	health := models.NewProviderHealth(checks)
+
+	// Don't cache checks that failed because the request was cancelled
+	if ctx.Err() != nil {
+		return health, nil
+	}
+
+	metrics.SetProviderHealth(providerKey, health.IsHealthy())
+
+	c.providerHealthMu.Lock()
+	if c.providerHealth == nil {
+		c.providerHealth = map[string]*models.ProviderHealth{}
+	}
+	c.providerHealth[providerKey] = health
+	c.providerHealthMu.Unlock()
+
+	return health, nil
+}
+
+// testSingleProvider initializes a provider and smoke checks each capability
+func (c *Config) testSingleProvider(ctx context.Context, providerKey string, p *models.Provider) []models.ProviderConnectionResult {
+
+	start := time.Now()
+
+	if err := c.initializeSingleProvider(ctx, providerKey, p); err != nil {
+		return []models.ProviderConnectionResult{
+			models.NewProviderConnectionResult(
+				providerKey, models.ProviderConnectionInitialize, time.Since(start), err),
+		}
+	}
+
+	client := p.GetClient()
+	capabilities := client.GetCapabilities()
+
+	if len(capabilities) == 0 {
+		return []models.ProviderConnectionResult{
+			models.NewProviderConnectionResult(
+				providerKey, models.ProviderConnectionInitialize, time.Since(start), nil),
+		}
+	}
+
+	results := make([]models.ProviderConnectionResult, 0, len(capabilities))
+
+	for _, capability := range capabilities {
+		start := time.Now()
+		err := client.TestConnection(ctx, capability)
+		results = append(results, models.NewProviderConnectionResult(
+			providerKey, capability, time.Since(start), err))
+	}
+
+	// Validate the agent can grant access, not just connect
+	if client.HasCapability(models.ProviderCapabilityRBAC) {
+		start := time.Now()
+		permissions, err := client.ValidateAgentPermissions(ctx)
+		results = append(results, models.NewAgentPermissionsResult(
+			providerKey, permissions, err).ToConnectionResult(time.Since(start)))
+	}
+
+	return results
+}
+
 // getProviderImplementation returns the appropriate provider implementation based on config mode
+// getProviderSecretKeys returns the config keys the provider declares as
+// secrets on top of the common names e.g. token or password
+func getProviderSecretKeys(providerName string) []string {
+
+	impl, err := providers.Get(providerName)
+
+	if err != nil {
+		return nil
+	}
+
+	if secretKeys, ok := impl.(models.ProviderSecretKeys); ok {
+		return secretKeys.GetSecretKeys()
+	}
+
+	return nil
+}
+
 func (c *Config) getProviderImplementation(providerKey string, providerName string) (models.ProviderImpl, error) {
 
 	if c.IsServer() || c.IsAgent() {
@@ -0,0 +1,297 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/providers"
+	"go.uber.org/goleak"
+)
+
+func TestTestProviders(t *testing.T) {
+	cfg := &Config{
+		Providers: ProviderConfig{
+			Definitions: map[string]models.Provider{
+				"oauth": {
+					Name:     "oauth",
+					Provider: "oauth2",
+					Enabled:  true,
+				},
+				"slack": {
+					Name:     "slack",
+					Provider: "slack",
+					Enabled:  true,
+					Config:   &models.BasicConfig{},
+				},
+			},
+		},
+	}
+	cfg.SetMode(ModeServer)
+
+	t.Run("all providers", func(t *testing.T) {
+		results, err := cfg.TestProviders(context.Background())
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+
+		// Providers without a smoke check fall back to initialization
+		assert.Equal(t, "oauth", results[0].Provider)
+		assert.Equal(t, models.ProviderCapabilityAuthorizer, results[0].Capability)
+		assert.True(t, results[0].Passed)
+		assert.True(t, results[0].Skipped)
+
+		// Initialization failures are reported against the provider
+		assert.Equal(t, "slack", results[1].Provider)
+		assert.Equal(t, models.ProviderConnectionInitialize, results[1].Capability)
+		assert.False(t, results[1].Passed)
+		assert.Contains(t, results[1].Error, "bot_token")
+	})
+
+	t.Run("named provider", func(t *testing.T) {
+		results, err := cfg.TestProviders(context.Background(), "oauth")
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "oauth", results[0].Provider)
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		_, err := cfg.TestProviders(context.Background(), "missing")
+		assert.ErrorContains(t, err, "provider not found")
+	})
+}
+
+type healthCheckProvider struct {
+	*models.BaseProvider
+	calls int
+	err   error
+}
+
+func (p *healthCheckProvider) TestConnection(ctx context.Context, capability models.ProviderCapability) error {
+	p.calls++
+	if capability == models.ProviderCapabilityIdentities {
+		return p.err
+	}
+	return nil
+}
+
+func TestGetProviderHealth(t *testing.T) {
+
+	newConfig := func(client *healthCheckProvider) *Config {
+		provider := models.Provider{Name: "aws", Provider: "aws", Enabled: true}
+		client.BaseProvider = models.NewBaseProvider("aws", provider,
+			models.ProviderCapabilityRBAC, models.ProviderCapabilityIdentities)
+		provider.SetClient(client)
+
+		return &Config{Providers: ProviderConfig{
+			Definitions: map[string]models.Provider{"aws": provider},
+		}}
+	}
+
+	t.Run("healthy checks are cached", func(t *testing.T) {
+		client := &healthCheckProvider{}
+		cfg := newConfig(client)
+
+		health, err := cfg.GetProviderHealth(context.Background(), "aws")
+		require.NoError(t, err)
+		assert.Equal(t, models.HealthStatusHealthy, health.Status)
+		assert.Len(t, health.Checks, 2)
+		assert.Equal(t, 2, client.calls)
+
+		cached, err := cfg.GetProviderHealth(context.Background(), "aws")
+		require.NoError(t, err)
+		assert.Same(t, health, cached)
+		assert.Equal(t, 2, client.calls)
+
+		// Expired checks run again
+		health.CheckedAt = health.CheckedAt.Add(-ProviderHealthCacheTTL)
+		_, err = cfg.GetProviderHealth(context.Background(), "aws")
+		require.NoError(t, err)
+		assert.Equal(t, 4, client.calls)
+	})
+
+	t.Run("failed capability degrades the provider", func(t *testing.T) {
+		cfg := newConfig(&healthCheckProvider{err: errors.New("access denied")})
+
+		health, err := cfg.GetProviderHealth(context.Background(), "aws")
+		require.NoError(t, err)
+		assert.Equal(t, models.HealthStatusDegraded, health.Status)
+		assert.False(t, health.IsHealthy())
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		_, err := newConfig(&healthCheckProvider{}).GetProviderHealth(context.Background(), "missing")
+		assert.ErrorContains(t, err, "provider not found")
+	})
+}
+
+func TestProviderCapabilities(t *testing.T) {
+
+	newProvider := func(name, providerType string, enabled bool, capabilities ...models.ProviderCapability) models.Provider {
+		provider := models.Provider{Name: name, Provider: providerType, Enabled: enabled}
+		provider.SetClient(&healthCheckProvider{
+			BaseProvider: models.NewBaseProvider(name, provider, capabilities...),
+		})
+		return provider
+	}
+
+	cfg := &Config{Providers: ProviderConfig{
+		Definitions: map[string]models.Provider{
+			"aws":      newProvider("aws", "aws", true, models.ProviderCapabilityRBAC),
+			"slack":    newProvider("Slack", "slack", true, models.ProviderCapabilityNotifier),
+			"email":    newProvider("email", "email", false, models.ProviderCapabilityNotifier),
+			"no-setup": {Name: "no-setup", Provider: "teams", Enabled: true},
+		},
+	}}
+
+	t.Run("get capabilities", func(t *testing.T) {
+		capabilities := cfg.GetProviderCapabilities(nil)
+
+		assert.Len(t, capabilities, len(models.ProviderCapabilities))
+		assert.Equal(t, []string{"aws"}, capabilities[models.ProviderCapabilityRBAC])
+		assert.Equal(t, []string{"slack"}, capabilities[models.ProviderCapabilityNotifier])
+		assert.Empty(t, capabilities[models.ProviderCapabilityIdentities])
+	})
+
+	t.Run("filter capabilities", func(t *testing.T) {
+		capabilities := cfg.GetProviderCapabilities(nil, models.ProviderCapabilityNotifier)
+
+		assert.Equal(t, map[models.ProviderCapability][]string{
+			models.ProviderCapabilityNotifier: {"slack"},
+		}, capabilities)
+	})
+
+	t.Run("validate capability", func(t *testing.T) {
+		assert.NoError(t, cfg.ValidateProviderCapability("slack", models.ProviderCapabilityNotifier))
+		assert.NoError(t, cfg.ValidateProviderCapability("Slack", models.ProviderCapabilityNotifier))
+
+		assert.EqualError(t, cfg.ValidateProviderCapability("aws", models.ProviderCapabilityNotifier),
+			"provider aws does not support the notifier capability")
+		assert.EqualError(t, cfg.ValidateProviderCapability("email", models.ProviderCapabilityNotifier),
+			"provider not configured: email")
+		assert.EqualError(t, cfg.ValidateProviderCapability("teams", models.ProviderCapabilityNotifier),
+			"provider not configured: teams")
+	})
+}
+
+// blockingProvider never finishes initializing until its context is done
+type blockingProvider struct {
+	*models.BaseProvider
+}
+
+func (p *blockingProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
+	p.BaseProvider = models.NewBaseProvider(identifier, provider, models.ProviderCapabilityRBAC)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func newBlockingProvidersConfig(timeout time.Duration) *Config {
+	providers.Set("blocking", &blockingProvider{})
+
+	cfg := &Config{
+		Providers: ProviderConfig{
+			InitializeTimeout: timeout,
+			Definitions: map[string]models.Provider{
+				"first": {
+					Name:     "first",
+					Provider: "blocking",
+					Enabled:  true,
+				},
+				"second": {
+					Name:     "second",
+					Provider: "blocking",
+					Enabled:  true,
+				},
+			},
+		},
+	}
+	cfg.SetMode(ModeServer)
+
+	return cfg
+}
+
+func TestInitializeProvidersCancelled(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	cfg := newBlockingProvidersConfig(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := cfg.InitializeProviders(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrProviderInitializationCancelled)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Nothing is applied when startup is cancelled
+	assert.Len(t, cfg.Providers.Definitions, 2)
+	for _, provider := range cfg.Providers.Definitions {
+		assert.Nil(t, provider.GetClient())
+	}
+}
+
+func TestInitializeProvidersTimeout(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	cfg := newBlockingProvidersConfig(50 * time.Millisecond)
+
+	// Providers that don't initialize in time are skipped rather than
+	// holding up startup
+	err := cfg.InitializeProviders(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Providers.Definitions)
+}
+
+func TestProviderConfigDefaults(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+defaults:
+  region: us-east-1
+  access_key_id: shared-key
+  tags:
+    team: platform
+    env: prod
+aws-prod:
+  provider: aws
+  enabled: true
+aws-dev:
+  provider: aws
+  enabled: true
+  config:
+    region: us-west-2
+    tags:
+      env: dev
+`)))
+
+	var providerConfig ProviderConfig
+	require.NoError(t, v.Unmarshal(&providerConfig))
+
+	// The defaults aren't a provider
+	require.Len(t, providerConfig.Definitions, 2)
+	assert.NotContains(t, providerConfig.Definitions, "defaults")
+
+	prod := providerConfig.Definitions["aws-prod"]
+	assert.Equal(t, &models.BasicConfig{
+		"region":        "us-east-1",
+		"access_key_id": "shared-key",
+		"tags":          map[string]any{"team": "platform", "env": "prod"},
+	}, providerConfig.GetConfigWithDefaults(&prod))
+
+	// Provider values take precedence, nested maps are merged
+	dev := providerConfig.Definitions["aws-dev"]
+	assert.Equal(t, &models.BasicConfig{
+		"region":        "us-west-2",
+		"access_key_id": "shared-key",
+		"tags":          map[string]any{"team": "platform", "env": "dev"},
+	}, providerConfig.GetConfigWithDefaults(&dev))
+
+	// The defaults are left untouched
+	assert.Equal(t, "us-east-1", (*providerConfig.Defaults)["region"])
+	assert.Equal(t, map[string]any{"team": "platform", "env": "prod"}, (*providerConfig.Defaults)["tags"])
+}
@@ -0,0 +1,633 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	emailProvider "github.com/thand-io/agent/internal/providers/email"
+	slackProvider "github.com/thand-io/agent/internal/providers/slack"
+	"github.com/thand-io/agent/internal/siem"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+// minReconcileInterval is the least time between sweeps, including those
+// triggered on demand, so the providers' APIs aren't hammered
+const minReconcileInterval = time.Minute
+
+var (
+	ErrReconcileInProgress = errors.New("a reconciliation sweep is already running")
+	ErrReconcileTooSoon    = errors.New("a reconciliation sweep ran less than a minute ago")
+)
+
+// GrantReconciler periodically lists the grants each RBAC provider created
+// and revokes, or reports, those no running elevation holds. Orphans are
+// only acted on once they've been seen for the grace period, so a grant
+// authorized but not yet recorded in its workflow's memo is left alone.
+type GrantReconciler struct {
+	config    models.ReconcileConfig
+	listHeld  func(ctx context.Context) (*heldGrants, error)
+	providers func() map[string]models.ProviderImpl
+	notify    func(ctx context.Context, findings []models.ReconcileFinding) error
+	now       func() time.Time
+
+	mu      sync.Mutex
+	running bool
+	lastRun time.Time
+	orphans map[string]*reconcileOrphan
+
+	startOnce sync.Once
+	stop      chan struct{}
+}
+
+// reconcileOrphan tracks an orphaned grant between sweeps
+type reconcileOrphan struct {
+	firstSeen time.Time
+	notified  bool
+}
+
+// heldGrants are the grants recorded by the running elevations
+type heldGrants struct {
+	grants []*models.GrantRef
+	// Set when a running elevation was authorized before grants were
+	// recorded in the memo, so what it holds is unknown
+	incomplete bool
+}
+
+func (h *heldGrants) holds(managed *models.ManagedGrant) bool {
+	for _, grant := range h.grants {
+		if managed.IsHeldBy(grant) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetGrantReconciler returns the reconciliation sweep for this server
+func (c *Config) GetGrantReconciler() *GrantReconciler {
+
+	c.grantReconcilerOnce.Do(func() {
+		c.grantReconciler = newGrantReconciler(
+			c.Reconcile,
+			c.listHeldGrants,
+			c.getReconcileProviders,
+			c.notifyOrphanedGrants,
+		)
+	})
+
+	return c.grantReconciler
+}
+
+func newGrantReconciler(
+	config models.ReconcileConfig,
+	listHeld func(ctx context.Context) (*heldGrants, error),
+	providers func() map[string]models.ProviderImpl,
+	notify func(ctx context.Context, findings []models.ReconcileFinding) error,
+) *GrantReconciler {
+	return &GrantReconciler{
+		config:    config,
+		listHeld:  listHeld,
+		providers: providers,
+		notify:    notify,
+		now:       time.Now,
+		orphans:   map[string]*reconcileOrphan{},
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start runs the sweep every interval, if enabled, until stopped
+func (r *GrantReconciler) Start() {
+
+	if !r.config.Enabled {
+		return
+	}
+
+	r.startOnce.Do(func() {
+
+		logrus.WithFields(logrus.Fields{
+			"interval": r.config.GetInterval(),
+			"action":   r.config.GetAction(),
+		}).Info("Starting grant reconciliation sweep")
+
+		go r.run()
+	})
+}
+
+// Stop stops the periodic sweep
+func (r *GrantReconciler) Stop() {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	select {
+	case <-r.stop:
+	default:
+		close(r.stop)
+	}
+}
+
+func (r *GrantReconciler) run() {
+
+	ticker := time.NewTicker(max(r.config.GetInterval(), minReconcileInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			if _, err := r.Reconcile(context.Background()); err != nil &&
+				!errors.Is(err, ErrReconcileInProgress) && !errors.Is(err, ErrReconcileTooSoon) {
+				logrus.WithError(err).Error("Grant reconciliation sweep failed")
+			}
+		}
+	}
+}
+
+// Reconcile runs a sweep. Only one sweep runs at a time and sweeps are at
+// least a minute apart.
+func (r *GrantReconciler) Reconcile(ctx context.Context) (*models.ReconcileResult, error) {
+
+	r.mu.Lock()
+
+	if r.running {
+		r.mu.Unlock()
+		return nil, ErrReconcileInProgress
+	}
+
+	now := r.now()
+
+	if !r.lastRun.IsZero() && now.Sub(r.lastRun) < minReconcileInterval {
+		r.mu.Unlock()
+		return nil, ErrReconcileTooSoon
+	}
+
+	r.running = true
+	r.lastRun = now
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		r.running = false
+		r.mu.Unlock()
+	}()
+
+	return r.sweep(ctx, now)
+}
+
+func (r *GrantReconciler) sweep(ctx context.Context, now time.Time) (*models.ReconcileResult, error) {
+
+	// Without the running grants nothing can be told apart from an orphan
+	held, err := r.listHeld(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list running grants: %w", err)
+	}
+
+	result := &models.ReconcileResult{
+		StartedAt: now,
+		Action:    r.config.GetAction(),
+		Held:      len(held.grants),
+		Providers: []string{},
+		Findings:  []models.ReconcileFinding{},
+	}
+
+	if held.incomplete && result.Action == models.ReconcileActionRevoke {
+		logrus.Warn("Running elevations predate recorded grants, reporting orphaned grants rather than revoking them")
+		result.Action = models.ReconcileActionNotify
+	}
+
+	gracePeriod := r.config.GetGracePeriod()
+	seen := map[string]bool{}
+	keys := []string{} // The tracking key of each finding
+	actionable := []int{}
+
+	providers := r.providers()
+
+	for _, name := range slices.Sorted(maps.Keys(providers)) {
+
+		provider := providers[name]
+
+		rbac, err := models.AsRoleBasedAccessControl(provider)
+
+		if err != nil {
+			continue
+		}
+
+		reconciler, ok := provider.(models.ProviderGrantReconciler)
+
+		if !ok {
+			continue
+		}
+
+		managedGrants, err := reconciler.ListManagedGrants(ctx)
+
+		if errors.Is(err, models.ErrNotImplemented) {
+			continue
+		} else if err != nil {
+			logrus.WithError(err).WithField("provider", name).Error("Failed to list managed grants")
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+
+		result.Providers = append(result.Providers, name)
+
+		for _, managed := range managedGrants {
+
+			if held.holds(&managed) {
+				continue
+			}
+
+			key := managed.GetKey()
+			seen[key] = true
+
+			orphan := r.getOrphan(key, now)
+
+			finding := models.ReconcileFinding{
+				Provider:  managed.Grant.Provider,
+				Reference: managed.Grant.Reference,
+				Kind:      managed.Grant.Kind,
+				IDs:       managed.Grant.IDs,
+				Identity:  managed.GetIdentity(),
+				Role:      managed.Role,
+				FirstSeen: orphan.firstSeen,
+				Status:    models.ReconcileStatusPending,
+			}
+
+			result.Findings = append(result.Findings, finding)
+			keys = append(keys, key)
+
+			if now.Sub(orphan.firstSeen) < gracePeriod {
+				continue
+			}
+
+			index := len(result.Findings) - 1
+			actionable = append(actionable, index)
+
+			if result.Action == models.ReconcileActionRevoke && len(actionable) <= r.config.GetMaxRevocations() {
+				r.revoke(ctx, rbac, managed, &result.Findings[index])
+			} else if result.Action == models.ReconcileActionRevoke {
+				result.Findings[index].Status = models.ReconcileStatusDeferred
+			}
+		}
+	}
+
+	if result.Action == models.ReconcileActionNotify {
+		r.report(ctx, result, keys, actionable)
+	}
+
+	r.forget(seen, result, keys)
+
+	for _, finding := range result.Findings {
+		if finding.Status != models.ReconcileStatusPending {
+			auditFinding(finding)
+		}
+	}
+
+	result.CompletedAt = r.now()
+
+	logrus.WithFields(logrus.Fields{
+		"action":    result.Action,
+		"held":      result.Held,
+		"providers": result.Providers,
+		"orphaned":  len(result.Findings),
+		"revoked":   result.GetCount(models.ReconcileStatusRevoked),
+		"deferred":  result.GetCount(models.ReconcileStatusDeferred),
+		"failed":    result.GetCount(models.ReconcileStatusFailed),
+	}).Info("Completed grant reconciliation sweep")
+
+	return result, nil
+}
+
+// getOrphan returns the orphan tracked for the key, tracking it from now
+// if it's new
+func (r *GrantReconciler) getOrphan(key string, now time.Time) *reconcileOrphan {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	orphan, found := r.orphans[key]
+
+	if !found {
+		orphan = &reconcileOrphan{firstSeen: now}
+		r.orphans[key] = orphan
+	}
+
+	return orphan
+}
+
+// forget stops tracking orphans that are no longer found or were revoked
+func (r *GrantReconciler) forget(seen map[string]bool, result *models.ReconcileResult, keys []string) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key := range r.orphans {
+		if !seen[key] {
+			delete(r.orphans, key)
+		}
+	}
+
+	for index, finding := range result.Findings {
+		if finding.Status == models.ReconcileStatusRevoked {
+			delete(r.orphans, keys[index])
+		}
+	}
+}
+
+// revoke revokes the orphaned grant with the grant ref the provider found
+func (r *GrantReconciler) revoke(
+	ctx context.Context,
+	rbac models.ProviderRoleBasedAccessControl,
+	managed models.ManagedGrant,
+	finding *models.ReconcileFinding,
+) {
+
+	_, err := rbac.RevokeRole(ctx, managed.NewRevokeRoleRequest())
+
+	if err != nil {
+		finding.Status = models.ReconcileStatusFailed
+		finding.Error = err.Error()
+		return
+	}
+
+	finding.Status = models.ReconcileStatusRevoked
+}
+
+// report sends a high priority notification for the orphans that haven't
+// been reported yet
+func (r *GrantReconciler) report(ctx context.Context, result *models.ReconcileResult, keys []string, actionable []int) {
+
+	unreported := []int{}
+
+	r.mu.Lock()
+	for _, index := range actionable {
+		result.Findings[index].Status = models.ReconcileStatusReported
+		if orphan := r.orphans[keys[index]]; orphan != nil && !orphan.notified {
+			unreported = append(unreported, index)
+		}
+	}
+	r.mu.Unlock()
+
+	if len(unreported) == 0 || r.notify == nil {
+		return
+	}
+
+	findings := make([]models.ReconcileFinding, 0, len(unreported))
+	for _, index := range unreported {
+		findings = append(findings, result.Findings[index])
+	}
+
+	if err := r.notify(ctx, findings); err != nil {
+		logrus.WithError(err).Error("Failed to send orphaned grants notification")
+		result.Errors = append(result.Errors, fmt.Sprintf("notify: %v", err))
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, index := range unreported {
+		result.Findings[index].Status = models.ReconcileStatusNotified
+		if orphan := r.orphans[keys[index]]; orphan != nil {
+			orphan.notified = true
+		}
+	}
+}
+
+// auditFinding records the orphaned grant and what was done about it in
+// the audit log and the SIEM
+func auditFinding(finding models.ReconcileFinding) {
+
+	fields := logrus.Fields{
+		"audit":      true,
+		"provider":   finding.Provider,
+		"reference":  finding.Reference,
+		"kind":       finding.Kind,
+		"ids":        finding.IDs,
+		"identity":   finding.Identity,
+		"role":       finding.Role,
+		"first_seen": finding.FirstSeen,
+		"status":     finding.Status,
+	}
+
+	event := siem.Event{
+		Type:      siem.EventGrantOrphaned,
+		Timestamp: time.Now().UTC(),
+		Role:      finding.Role,
+		Outcome:   siem.OutcomeSuccess,
+		Reason:    string(finding.Status),
+	}.WithProvider(finding.Provider, finding.Identity)
+
+	if len(finding.Error) > 0 {
+		event = event.WithError(errors.New(finding.Error))
+		logrus.WithFields(fields).WithField("error", finding.Error).Error("Failed to revoke orphaned grant")
+	} else {
+		logrus.WithFields(fields).Warn("Orphaned grant found")
+	}
+
+	siem.Record(event)
+}
+
+// listHeldGrants lists the grants recorded by every running, approved
+// elevation. Grants made by other task queues in the namespace are
+// included so one deployment never revokes another's grants.
+func (c *Config) listHeldGrants(ctx context.Context) (*heldGrants, error) {
+
+	services := c.GetServices()
+
+	if services == nil || !services.HasTemporal() || !services.GetTemporal().HasClient() {
+		return nil, fmt.Errorf("temporal is required to reconcile grants")
+	}
+
+	temporalService := services.GetTemporal()
+	held := &heldGrants{}
+
+	var pageToken []byte
+
+	for {
+
+		resp, err := temporalService.GetClient().ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+			Namespace:     temporalService.GetNamespace(),
+			PageSize:      1000,
+			Query:         fmt.Sprintf("ExecutionStatus='Running' AND %s=true", models.VarsContextApproved),
+			NextPageToken: pageToken,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, exec := range resp.Executions {
+
+			grant := models.GetGrantMemo(exec.GetMemo())
+
+			// Grants recorded before the memo listed them
+			if grant == nil || grant.GrantRefs == nil {
+				held.incomplete = true
+				continue
+			}
+
+			held.grants = append(held.grants, grant.GrantRefs...)
+		}
+
+		pageToken = resp.GetNextPageToken()
+
+		if len(pageToken) == 0 {
+			return held, nil
+		}
+	}
+}
+
+// getReconcileProviders returns the initialized RBAC providers
+func (c *Config) getReconcileProviders() map[string]models.ProviderImpl {
+
+	providers := map[string]models.ProviderImpl{}
+
+	for name, provider := range c.GetProvidersByCapability(models.ProviderCapabilityRBAC) {
+		providers[name] = provider.GetClient()
+	}
+
+	return providers
+}
+
+// notifyOrphanedGrants sends the orphaned grants to the reconcile notifier
+// as a high priority notification
+func (c *Config) notifyOrphanedGrants(ctx context.Context, findings []models.ReconcileFinding) error {
+
+	if len(c.Reconcile.Notifier) == 0 {
+		return nil
+	}
+
+	providerConfig, err := c.Providers.GetProviderByName(c.Reconcile.Notifier)
+
+	if err != nil {
+		return fmt.Errorf("failed to get provider: %w", err)
+	}
+
+	message, err := renderOrphanedGrants(providerConfig.Provider, c.Reconcile.To, findings)
+
+	if err != nil {
+		return err
+	}
+
+	parts := []string{"reconcile"}
+	for _, finding := range findings {
+		parts = append(parts, finding.Provider, finding.Reference, strings.Join(finding.IDs, ","))
+	}
+
+	message[models.NotificationIDKey] = models.NewNotificationID(parts...)
+	message[models.NotificationPriorityKey] = models.NotificationPriorityHigh
+
+	return c.sendNotification(ctx, c.Reconcile.Notifier, message)
+}
+
+// renderOrphanedGrants lists the orphaned grants in the notifier's
+// message format
+func renderOrphanedGrants(
+	providerType string,
+	to []string,
+	findings []models.ReconcileFinding,
+) (models.NotificationRequest, error) {
+
+	title := fmt.Sprintf("%d orphaned grants found by the reconciliation sweep", len(findings))
+	if len(findings) == 1 {
+		title = "1 orphaned grant found by the reconciliation sweep"
+	}
+
+	lines := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		lines = append(lines, getOrphanedGrantSummary(finding))
+	}
+
+	message := models.NotificationRequest{}
+
+	switch strings.ToLower(providerType) {
+	case slackProvider.SlackProviderName:
+
+		if len(to) == 0 {
+			return nil, fmt.Errorf("reconcile.to must name the slack channel to notify")
+		}
+
+		err := common.ConvertInterfaceToInterface(slackProvider.SlackNotificationRequest{
+			To:         to[0],
+			Recipients: to[1:],
+			Text:       title,
+			Blocks: slack.Blocks{BlockSet: []slack.Block{
+				slack.NewHeaderBlock(
+					slack.NewTextBlockObject(slack.PlainTextType, title, false, false),
+				),
+				slack.NewSectionBlock(
+					slack.NewTextBlockObject(slack.MarkdownType, "• "+strings.Join(lines, "\n• "), false, false),
+					nil, nil,
+				),
+			}},
+		}, &message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert slack notification: %w", err)
+		}
+
+	case emailProvider.EmailProviderName:
+
+		if len(to) == 0 {
+			return nil, fmt.Errorf("reconcile.to must list the addresses to notify")
+		}
+
+		htmlBody := strings.Builder{}
+		htmlBody.WriteString(fmt.Sprintf("<h2>%s</h2><ul>", html.EscapeString(title)))
+		for _, line := range lines {
+			htmlBody.WriteString(fmt.Sprintf("<li>%s</li>", html.EscapeString(line)))
+		}
+		htmlBody.WriteString("</ul>")
+
+		err := common.ConvertInterfaceToInterface(models.EmailNotificationRequest{
+			To:      to,
+			Subject: title,
+			Body: models.EmailNotificationBody{
+				Text: title + "\n\n- " + strings.Join(lines, "\n- ") + "\n",
+				HTML: htmlBody.String(),
+			},
+		}, &message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert email notification: %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("orphaned grant notifications are not supported for provider: %s", providerType)
+	}
+
+	return message, nil
+}
+
+// getOrphanedGrantSummary describes the orphaned grant in a line
+func getOrphanedGrantSummary(finding models.ReconcileFinding) string {
+
+	identity := finding.Identity
+	if len(identity) == 0 {
+		identity = "unknown identity"
+	}
+
+	summary := fmt.Sprintf("%s: %s", finding.Provider, identity)
+
+	if len(finding.Role) > 0 {
+		summary = fmt.Sprintf("%s has %s", summary, finding.Role)
+	}
+
+	if len(finding.Reference) > 0 {
+		summary = fmt.Sprintf("%s (grant %s)", summary, finding.Reference)
+	} else if len(finding.IDs) > 0 {
+		summary = fmt.Sprintf("%s (%s)", summary, strings.Join(finding.IDs, ", "))
+	}
+
+	return summary
+}
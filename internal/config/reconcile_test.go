@@ -0,0 +1,260 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// reconcileProvider lists fixed managed grants and records revocations
+type reconcileProvider struct {
+	models.ProviderImpl
+	grants    []models.ManagedGrant
+	listErr   error
+	revokeErr error
+	revoked   []string
+}
+
+func (p *reconcileProvider) ListManagedGrants(ctx context.Context) ([]models.ManagedGrant, error) {
+	return p.grants, p.listErr
+}
+
+func (p *reconcileProvider) AuthorizeRole(ctx context.Context, req *models.AuthorizeRoleRequest) (*models.AuthorizeRoleResponse, error) {
+	return nil, models.ErrNotImplemented
+}
+
+func (p *reconcileProvider) RevokeRole(ctx context.Context, req *models.RevokeRoleRequest) (*models.RevokeRoleResponse, error) {
+	if p.revokeErr != nil {
+		return nil, p.revokeErr
+	}
+	p.revoked = append(p.revoked, req.GetGrantRef().Reference)
+	return &models.RevokeRoleResponse{}, nil
+}
+
+func (p *reconcileProvider) GetAuthorizedAccessUrl(ctx context.Context, req *models.AuthorizeRoleRequest, resp *models.AuthorizeRoleResponse) string {
+	return ""
+}
+
+func newManagedGrant(reference string) models.ManagedGrant {
+	return models.ManagedGrant{
+		Grant: &models.GrantRef{
+			Provider:  "aws",
+			Reference: reference,
+			Kind:      "trust_policy_statement",
+			IDs:       []string{"admin"},
+		},
+		User: &models.User{Username: "alice"},
+		Role: "admin",
+	}
+}
+
+type reconcileTest struct {
+	reconciler *GrantReconciler
+	provider   *reconcileProvider
+	held       *heldGrants
+	notified   [][]models.ReconcileFinding
+	now        time.Time
+}
+
+func newReconcileTest(config models.ReconcileConfig, grants ...models.ManagedGrant) *reconcileTest {
+
+	test := &reconcileTest{
+		provider: &reconcileProvider{grants: grants},
+		held:     &heldGrants{},
+		now:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	test.reconciler = newGrantReconciler(
+		config,
+		func(ctx context.Context) (*heldGrants, error) {
+			return test.held, nil
+		},
+		func() map[string]models.ProviderImpl {
+			return map[string]models.ProviderImpl{"aws": test.provider}
+		},
+		func(ctx context.Context, findings []models.ReconcileFinding) error {
+			test.notified = append(test.notified, findings)
+			return nil
+		},
+	)
+	test.reconciler.now = func() time.Time { return test.now }
+
+	return test
+}
+
+// sweep runs the sweep after the given time has passed
+func (r *reconcileTest) sweep(t *testing.T, after time.Duration) *models.ReconcileResult {
+	r.now = r.now.Add(after)
+	result, err := r.reconciler.Reconcile(context.Background())
+	require.NoError(t, err)
+	return result
+}
+
+func getStatuses(result *models.ReconcileResult) []models.ReconcileStatus {
+	statuses := []models.ReconcileStatus{}
+	for _, finding := range result.Findings {
+		statuses = append(statuses, finding.Status)
+	}
+	return statuses
+}
+
+func TestGrantReconciler(t *testing.T) {
+
+	config := models.ReconcileConfig{GracePeriod: "10m"}
+
+	t.Run("held grants are left alone", func(t *testing.T) {
+		test := newReconcileTest(config, newManagedGrant("thandheld"))
+		test.held.grants = []*models.GrantRef{{Provider: "aws", Reference: "thandheld"}}
+
+		result := test.sweep(t, 0)
+		assert.Empty(t, result.Findings)
+		assert.Equal(t, []string{"aws"}, result.Providers)
+
+		result = test.sweep(t, time.Hour)
+		assert.Empty(t, result.Findings)
+		assert.Empty(t, test.provider.revoked)
+	})
+
+	t.Run("orphans are revoked after the grace period", func(t *testing.T) {
+		test := newReconcileTest(config, newManagedGrant("thandorphan"))
+
+		result := test.sweep(t, 0)
+		assert.Equal(t, []models.ReconcileStatus{models.ReconcileStatusPending}, getStatuses(result))
+		assert.Empty(t, test.provider.revoked)
+
+		result = test.sweep(t, 10*time.Minute)
+		assert.Equal(t, []models.ReconcileStatus{models.ReconcileStatusRevoked}, getStatuses(result))
+		assert.Equal(t, []string{"thandorphan"}, test.provider.revoked)
+		assert.Equal(t, "alice", result.Findings[0].Identity)
+	})
+
+	t.Run("revocations are limited per sweep", func(t *testing.T) {
+		limited := config
+		limited.MaxRevocations = 1
+		test := newReconcileTest(limited, newManagedGrant("thandfirst"), newManagedGrant("thandsecond"))
+
+		test.sweep(t, 0)
+		result := test.sweep(t, 10*time.Minute)
+		assert.Equal(t, []models.ReconcileStatus{
+			models.ReconcileStatusRevoked,
+			models.ReconcileStatusDeferred,
+		}, getStatuses(result))
+		assert.Equal(t, []string{"thandfirst"}, test.provider.revoked)
+	})
+
+	t.Run("failed revocations are recorded", func(t *testing.T) {
+		test := newReconcileTest(models.ReconcileConfig{GracePeriod: "0s"}, newManagedGrant("thandorphan"))
+		test.provider.revokeErr = errors.New("access denied")
+
+		result := test.sweep(t, 0)
+		require.Len(t, result.Findings, 1)
+		assert.Equal(t, models.ReconcileStatusFailed, result.Findings[0].Status)
+		assert.Equal(t, "access denied", result.Findings[0].Error)
+	})
+
+	t.Run("notify reports orphans once", func(t *testing.T) {
+		notify := config
+		notify.Action = models.ReconcileActionNotify
+		test := newReconcileTest(notify, newManagedGrant("thandorphan"))
+
+		test.sweep(t, 0)
+		assert.Empty(t, test.notified)
+
+		result := test.sweep(t, 10*time.Minute)
+		assert.Equal(t, []models.ReconcileStatus{models.ReconcileStatusNotified}, getStatuses(result))
+		require.Len(t, test.notified, 1)
+		assert.Equal(t, "thandorphan", test.notified[0][0].Reference)
+
+		result = test.sweep(t, time.Hour)
+		assert.Equal(t, []models.ReconcileStatus{models.ReconcileStatusReported}, getStatuses(result))
+		assert.Len(t, test.notified, 1)
+		assert.Empty(t, test.provider.revoked)
+	})
+
+	t.Run("unknown running grants only report", func(t *testing.T) {
+		test := newReconcileTest(config, newManagedGrant("thandorphan"))
+		test.held.incomplete = true
+
+		test.sweep(t, 0)
+		result := test.sweep(t, 10*time.Minute)
+		assert.Equal(t, models.ReconcileActionNotify, result.Action)
+		assert.Empty(t, test.provider.revoked)
+		assert.Len(t, test.notified, 1)
+	})
+
+	t.Run("orphans that disappear are forgotten", func(t *testing.T) {
+		test := newReconcileTest(config, newManagedGrant("thandorphan"))
+
+		test.sweep(t, 0)
+		grants := test.provider.grants
+		test.provider.grants = nil
+		test.sweep(t, 5*time.Minute)
+
+		test.provider.grants = grants
+		result := test.sweep(t, 5*time.Minute)
+		assert.Equal(t, []models.ReconcileStatus{models.ReconcileStatusPending}, getStatuses(result))
+	})
+
+	t.Run("provider errors don't stop the sweep", func(t *testing.T) {
+		test := newReconcileTest(config)
+		test.provider.listErr = errors.New("throttled")
+
+		result := test.sweep(t, 0)
+		assert.Empty(t, result.Providers)
+		assert.Equal(t, []string{"aws: throttled"}, result.Errors)
+	})
+
+	t.Run("sweeps are rate limited", func(t *testing.T) {
+		test := newReconcileTest(config)
+
+		test.sweep(t, 0)
+		test.now = test.now.Add(30 * time.Second)
+		_, err := test.reconciler.Reconcile(context.Background())
+		assert.ErrorIs(t, err, ErrReconcileTooSoon)
+
+		test.sweep(t, time.Minute)
+	})
+
+	t.Run("nothing is swept without the running grants", func(t *testing.T) {
+		test := newReconcileTest(config, newManagedGrant("thandorphan"))
+		test.reconciler.listHeld = func(ctx context.Context) (*heldGrants, error) {
+			return nil, errors.New("temporal unavailable")
+		}
+
+		_, err := test.reconciler.Reconcile(context.Background())
+		assert.ErrorContains(t, err, "temporal unavailable")
+		assert.Empty(t, test.provider.revoked)
+	})
+}
+
+func TestRenderOrphanedGrants(t *testing.T) {
+
+	findings := []models.ReconcileFinding{{
+		Provider:  "aws",
+		Reference: "thandorphan",
+		Identity:  "alice",
+		Role:      "admin",
+	}}
+
+	message, err := renderOrphanedGrants("slack", []string{"#security"}, findings)
+	require.NoError(t, err)
+	assert.Equal(t, "#security", message["channel"])
+	assert.Equal(t, "1 orphaned grant found by the reconciliation sweep", message["text"])
+
+	message, err = renderOrphanedGrants("email", []string{"security@example.com"}, findings)
+	require.NoError(t, err)
+	assert.Equal(t, "1 orphaned grant found by the reconciliation sweep", message["Subject"])
+
+	_, err = renderOrphanedGrants("slack", nil, findings)
+	assert.Error(t, err)
+
+	_, err = renderOrphanedGrants("okta", []string{"#security"}, findings)
+	assert.ErrorContains(t, err, "not supported")
+
+	assert.Equal(t, "aws: alice has admin (grant thandorphan)", getOrphanedGrantSummary(findings[0]))
+}
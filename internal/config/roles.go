@@ -4,13 +4,16 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/blevesearch/bleve/v2"
 	"github.com/hashicorp/go-version"
 	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config/environment"
 	"github.com/thand-io/agent/internal/models"
 )
@@ -42,50 +45,58 @@ const (
 	MaxInheritanceDepth = 10
 )
 
-// validateRoleLimits validates that a role does not exceed configured limits.
-// Returns an error describing the first limit violation found.
-func validateRoleLimits(roleKey string, role *models.Role) error {
-	// Check permissions limit
-	permCount := len(role.Permissions.Allow) + len(role.Permissions.Deny)
-	if permCount > MaxPermissions {
-		return fmt.Errorf("role '%s' exceeds maximum permissions limit: %d > %d", roleKey, permCount, MaxPermissions)
-	}
+// RoleLimitError is returned for roles over one of the limits
+type RoleLimitError struct {
+	Role  string
+	Limit string // What's limited e.g. permissions
+	Count int
+	Max   int
+}
 
-	// Check resources limit
-	resCount := len(role.Resources.Allow) + len(role.Resources.Deny)
-	if resCount > MaxResources {
-		return fmt.Errorf("role '%s' exceeds maximum resources limit: %d > %d", roleKey, resCount, MaxResources)
-	}
+func (e *RoleLimitError) Error() string {
+	return fmt.Sprintf("role '%s' exceeds maximum %s limit: %d > %d", e.Role, e.Limit, e.Count, e.Max)
+}
 
-	// Check groups limit
-	groupCount := len(role.Groups.Allow) + len(role.Groups.Deny)
-	if groupCount > MaxGroups {
-		return fmt.Errorf("role '%s' exceeds maximum groups limit: %d > %d", roleKey, groupCount, MaxGroups)
-	}
+// ValidateRoleLimits validates that a role e.g. a dynamic role from a
+// request does not exceed the configured limits
+func ValidateRoleLimits(role *models.Role) error {
+	return validateRoleLimits(role.Name, role)
+}
+
+// validateRoleLimits validates that a role does not exceed configured limits.
+// Returns a RoleLimitError describing the first limit violation found.
+func validateRoleLimits(roleKey string, role *models.Role) error {
 
-	// Check scopes limit
+	scopeCount := 0
 	if role.Scopes != nil {
-		scopeCount := len(role.Scopes.Users) + len(role.Scopes.Groups) + len(role.Scopes.Domains)
-		if scopeCount > MaxScopes {
-			return fmt.Errorf("role '%s' exceeds maximum scopes limit: %d > %d", roleKey, scopeCount, MaxScopes)
+		scopeCount = len(role.Scopes.Users) + len(role.Scopes.Groups) + len(role.Scopes.Domains)
+	}
+
+	limits := []struct {
+		limit string
+		count int
+		max   int
+	}{
+		{"permissions", len(role.Permissions.Allow) + len(role.Permissions.Deny), MaxPermissions},
+		{"resources", len(role.Resources.Allow) + len(role.Resources.Deny), MaxResources},
+		{"groups", len(role.Groups.Allow) + len(role.Groups.Deny), MaxGroups},
+		{"scopes", scopeCount, MaxScopes},
+		{"inherits", len(role.Inherits), MaxInherits},
+		{"providers", len(role.Providers), MaxProviders},
+		{"workflows", len(role.Workflows), MaxWorkflows},
+	}
+
+	for _, limit := range limits {
+		if limit.count > limit.max {
+			return &RoleLimitError{
+				Role:  roleKey,
+				Limit: limit.limit,
+				Count: limit.count,
+				Max:   limit.max,
+			}
 		}
 	}
 
-	// Check inherits limit
-	if len(role.Inherits) > MaxInherits {
-		return fmt.Errorf("role '%s' exceeds maximum inherits limit: %d > %d", roleKey, len(role.Inherits), MaxInherits)
-	}
-
-	// Check providers limit
-	if len(role.Providers) > MaxProviders {
-		return fmt.Errorf("role '%s' exceeds maximum providers limit: %d > %d", roleKey, len(role.Providers), MaxProviders)
-	}
-
-	// Check workflows limit
-	if len(role.Workflows) > MaxWorkflows {
-		return fmt.Errorf("role '%s' exceeds maximum workflows limit: %d > %d", roleKey, len(role.Workflows), MaxWorkflows)
-	}
-
 	return nil
 }
 
@@ -126,6 +137,20 @@ func (c *Config) LoadRoles() (map[string]models.Role, error) {
 
 func (c *Config) ApplyRoles(foundRoles []*models.RoleDefinitions) (map[string]models.Role, error) {
 
+	if err := c.Roles.ReasonPolicy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid default reason policy: %w", err)
+	}
+
+	if err := c.validatePermissionAliasDefinitions(); err != nil {
+		return nil, fmt.Errorf("invalid permission aliases: %w", err)
+	}
+
+	for roleKey, override := range c.Environment.Overrides {
+		if err := override.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid environment override for role '%s': %w", roleKey, err)
+		}
+	}
+
 	// Add roles defined directly in config
 	if len(c.Roles.Definitions) > 0 {
 		logrus.Debugln("Adding roles defined directly in config: ", len(c.Roles.Definitions))
@@ -143,7 +168,21 @@ func (c *Config) ApplyRoles(foundRoles []*models.RoleDefinitions) (map[string]mo
 	logrus.Debugln("Processing loaded roles: ", len(foundRoles))
 
 	for _, role := range foundRoles {
+
+		// The configured default reason policy takes precedence over
+		// one loaded with the roles e.g. synced from the login server
+		if c.Roles.ReasonPolicy == nil && role.ReasonPolicy != nil {
+			if err := role.ReasonPolicy.Validate(); err != nil {
+				logrus.WithError(err).Warnln("Ignoring invalid default reason policy")
+			} else {
+				c.Roles.ReasonPolicy = role.ReasonPolicy
+			}
+		}
+
 		for roleKey, r := range role.Roles {
+
+			c.applyRoleOverride(roleKey, &r)
+
 			if !r.Enabled {
 				logrus.Infoln("Role disabled:", roleKey)
 				continue
@@ -162,9 +201,8 @@ func (c *Config) ApplyRoles(foundRoles []*models.RoleDefinitions) (map[string]mo
 				r.Name = roleKey
 			}
 
-			// Validate role limits
-			if err := validateRoleLimits(roleKey, &r); err != nil {
-				logrus.WithError(err).Warnln("Role exceeds limits, skipping:", roleKey)
+			if err := c.validateRole(roleKey, &r); err != nil {
+				logrus.WithError(err).Warnln("Role is invalid, skipping:", roleKey)
 				continue
 			}
 			defs[roleKey] = r
@@ -174,6 +212,41 @@ func (c *Config) ApplyRoles(foundRoles []*models.RoleDefinitions) (map[string]mo
 	return defs, nil
 }
 
+// validateRole checks a loaded role is within the limits and only uses a
+// valid reason policy and known permission aliases
+func (c *Config) validateRole(roleKey string, role *models.Role) error {
+
+	if err := validateRoleLimits(roleKey, role); err != nil {
+		return err
+	}
+
+	if err := role.GetReasonPolicy().Validate(); err != nil {
+		return fmt.Errorf("role '%s' has an invalid reason policy: %w", roleKey, err)
+	}
+
+	if err := role.DuringFreeze.Validate(); err != nil {
+		return fmt.Errorf("role '%s': %w", roleKey, err)
+	}
+
+	if err := role.ValidateDefaultEffect(); err != nil {
+		return fmt.Errorf("role '%s': %w", roleKey, err)
+	}
+
+	if err := role.Scopes.ValidateCondition(); err != nil {
+		return fmt.Errorf("role '%s': %w", roleKey, err)
+	}
+
+	if err := role.WorkflowTemplate.Validate(); err != nil {
+		return fmt.Errorf("role '%s': %w", roleKey, err)
+	}
+
+	if err := c.validatePermissionAliases(roleKey, role); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (c *Config) ReloadRoleIndexes() error {
 
 	// Create bleve index for roles
@@ -252,10 +325,12 @@ func (c *Config) GetRoleByName(name string) (*models.Role, error) {
 //
 // The resolution process:
 //  1. Detects cyclic inheritance to prevent infinite loops
-//  2. Filters permissions/resources/groups by the role's allowed providers
+//  2. Expands permission aliases for the role's providers, then filters
+//     permissions/resources/groups by the role's allowed providers
 //  3. Recursively resolves inherited roles (both local and provider roles)
 //  4. Merges permissions with conflict resolution (parent overrides child)
 //  5. Condenses permissions back to efficient format
+//  6. Expands the role's workflow template, if any, into its first workflow
 //
 // Returns an error if:
 //   - baseRole is nil
@@ -266,7 +341,47 @@ func (c *Config) GetCompositeRole(identity *models.Identity, baseRole *models.Ro
 		return nil, fmt.Errorf("cannot resolve composite role: base role is nil")
 	}
 	// Pre-allocate visited map with reasonable capacity to reduce allocations
-	return c.resolveCompositeRole(identity, baseRole, make(map[string]bool, 8))
+	compositeRole, err := c.resolveCompositeRole(identity, baseRole, make(map[string]bool, 8))
+	if err != nil {
+		return nil, err
+	}
+
+	// Aliases left by roles without providers stand for every provider
+	if hasPermissionAliases(compositeRole) {
+		if err := c.expandRoleAliases(compositeRole, compositeRole.Providers); err != nil {
+			return nil, fmt.Errorf("failed to expand permission aliases for role '%s': %w", compositeRole.Name, err)
+		}
+		c.resolvePermissionConflicts(compositeRole)
+	}
+
+	// Environment overrides replace whatever was inherited
+	c.applyRoleOverride(compositeRole.Name, compositeRole)
+
+	// The justification pattern is checked by the reason policy
+	compositeRole.ReasonPolicy = compositeRole.GetReasonPolicy()
+
+	if err := c.applyWorkflowTemplate(compositeRole); err != nil {
+		return nil, fmt.Errorf("failed to expand workflow template for role '%s': %w", compositeRole.Name, err)
+	}
+
+	return compositeRole, nil
+}
+
+// applyRoleOverride applies the environment override for the role, if any
+func (c *Config) applyRoleOverride(roleKey string, role *models.Role) {
+
+	override, found := c.Environment.Overrides[roleKey]
+
+	if !found {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"role":        roleKey,
+		"environment": c.Environment.Name,
+	}).Debugln("Applying environment role override")
+
+	override.Apply(role)
 }
 
 func (c *Config) GetCompositeRoleByName(identity *models.Identity, roleName string) (*models.Role, error) {
@@ -280,6 +395,161 @@ func (c *Config) GetCompositeRoleByName(identity *models.Identity, roleName stri
 	return c.GetCompositeRole(identity, baseRole)
 }
 
+// GetReasonPolicy returns the reason policy for a role. The configured
+// definition of the role is used when there is one so requests can't drop
+// the policy. A role's policy, merged with the roles it inherits, replaces
+// the default policy.
+func (c *Config) GetReasonPolicy(identity *models.Identity, role *models.Role) (*models.ReasonPolicy, error) {
+
+	if role == nil {
+		return c.Roles.ReasonPolicy, nil
+	}
+
+	compositeRole, err := c.getConfiguredCompositeRole(identity, role)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if compositeRole.ReasonPolicy != nil {
+		return compositeRole.ReasonPolicy, nil
+	}
+
+	return c.Roles.ReasonPolicy, nil
+}
+
+// CheckReason returns the reason policy rule the reason breaks, if any
+func (c *Config) CheckReason(user *models.User, role *models.Role, reason string) error {
+
+	reasonPolicy, err := c.GetReasonPolicy(newUserIdentity(user), role)
+
+	if err != nil {
+		return fmt.Errorf("failed to resolve reason policy: %w", err)
+	}
+
+	return reasonPolicy.Check(reason)
+}
+
+// CheckDuration returns an error if the duration is longer than the max
+// duration of the role. An empty duration is left to the workflow default.
+func (c *Config) CheckDuration(user *models.User, role *models.Role, duration string) error {
+
+	if role == nil || len(duration) == 0 {
+		return nil
+	}
+
+	compositeRole, err := c.getConfiguredCompositeRole(newUserIdentity(user), role)
+
+	if err != nil {
+		return fmt.Errorf("failed to resolve role: %w", err)
+	}
+
+	if len(compositeRole.MaxDuration) == 0 {
+		return nil
+	}
+
+	maxDuration, err := common.ValidateDuration(compositeRole.MaxDuration)
+
+	if err != nil {
+		return fmt.Errorf("role '%s' has an invalid max_duration: %w", compositeRole.Name, err)
+	}
+
+	requested, err := common.ValidateDuration(duration)
+
+	if err != nil {
+		return err
+	}
+
+	if requested > maxDuration {
+		return fmt.Errorf("duration must not be longer than %s for role '%s'",
+			common.FormatDuration(maxDuration), compositeRole.Name)
+	}
+
+	return nil
+}
+
+// CheckPermissions returns an error if the role requests actions the
+// configured role doesn't allow. It only applies to roles that deny by
+// default, as requests could otherwise add any action to the role.
+func (c *Config) CheckPermissions(user *models.User, role *models.Role) error {
+
+	if role == nil {
+		return nil
+	}
+
+	identity := newUserIdentity(user)
+
+	compositeRole, err := c.getConfiguredCompositeRole(identity, role)
+
+	if err != nil {
+		return fmt.Errorf("failed to resolve role: %w", err)
+	}
+
+	if !compositeRole.IsDenyByDefault() {
+		return nil
+	}
+
+	// Resolve the requested role as given so its actions include the
+	// roles it inherits
+	requestedRole, err := c.GetCompositeRole(identity, role)
+
+	if err != nil {
+		return fmt.Errorf("failed to resolve requested role: %w", err)
+	}
+
+	for _, permission := range requestedRole.Permissions.Allow {
+		for _, action := range expandCondensedActions(permission) {
+			if !compositeRole.IsActionAllowed(action) {
+				return fmt.Errorf("action '%s' is not allowed by role '%s'", action, compositeRole.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RoleRequiresApproval returns true if requests for the role must be
+// approved, whatever the policies decide
+func (c *Config) RoleRequiresApproval(user *models.User, role *models.Role) (bool, error) {
+
+	if role == nil {
+		return false, nil
+	}
+
+	compositeRole, err := c.getConfiguredCompositeRole(newUserIdentity(user), role)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve role: %w", err)
+	}
+
+	return compositeRole.RequiresApproval, nil
+}
+
+// getConfiguredCompositeRole resolves the composite of a role. The
+// configured definition of the role is used when there is one so requests
+// can't drop its rules.
+func (c *Config) getConfiguredCompositeRole(identity *models.Identity, role *models.Role) (*models.Role, error) {
+
+	if configuredRole, err := c.GetRoleByName(role.Name); err == nil {
+		role = configuredRole
+	}
+
+	return c.GetCompositeRole(identity, role)
+}
+
+func newUserIdentity(user *models.User) *models.Identity {
+
+	if user == nil {
+		return nil
+	}
+
+	return &models.Identity{
+		ID:    user.GetIdentity(),
+		Label: user.GetName(),
+		User:  user,
+	}
+}
+
 func (c *Config) resolveCompositeRoleByName(identity *models.Identity, roleName string, visited map[string]bool) (*models.Role, error) {
 	baseRole, err := c.GetRoleByName(roleName)
 	if err != nil {
@@ -314,6 +584,15 @@ func (c *Config) resolveCompositeRole(identity *models.Identity, baseRole *model
 
 	// Create composite role with provider-filtered permissions/resources/groups
 	compositeRole := *baseRole
+
+	// Aliases are expanded for the providers the role targets. Roles without
+	// providers keep them for the roles inheriting them to expand.
+	if len(compositeRole.Providers) > 0 {
+		if err := c.expandRoleAliases(&compositeRole, compositeRole.Providers); err != nil {
+			return nil, fmt.Errorf("failed to expand permission aliases for role '%s': %w", baseRole.Name, err)
+		}
+	}
+
 	c.filterRoleByProviders(&compositeRole)
 
 	// Pre-allocate with expected capacity to reduce allocations
@@ -382,6 +661,12 @@ func (c *Config) resolveCompositeRole(identity *models.Identity, baseRole *model
 			return nil, fmt.Errorf("failed to resolve inherited role '%s' for role '%s': %w", inheritedRoleName, baseRole.Name, err)
 		}
 
+		if len(compositeRole.Providers) > 0 {
+			if err := c.expandRoleAliases(inheritedRole, compositeRole.Providers); err != nil {
+				return nil, fmt.Errorf("failed to expand permission aliases of role '%s' for role '%s': %w", inheritedRoleName, baseRole.Name, err)
+			}
+		}
+
 		// Merge inherited role into composite
 		c.mergeRole(&compositeRole, inheritedRole)
 	}
@@ -416,6 +701,8 @@ func (c *Config) resolveInheritedRole(identity *models.Identity, roleName string
 //   - Identity matches any group scope (for group identities)
 //   - User belongs to any allowed group
 //   - User's domain matches any allowed domain
+//
+// A scopes condition must also be true for the identity at the current time.
 func (c *Config) isRoleApplicableToIdentity(role *models.Role, identity *models.Identity) bool {
 	// No scopes means open to all
 	if role.Scopes == nil {
@@ -427,6 +714,10 @@ func (c *Config) isRoleApplicableToIdentity(role *models.Role, identity *models.
 		return false
 	}
 
+	if !role.Scopes.MatchesCondition(identity, time.Now()) {
+		return false
+	}
+
 	hasAnyScope := len(role.Scopes.Users) > 0 || len(role.Scopes.Groups) > 0 || len(role.Scopes.Domains) > 0
 	if !hasAnyScope {
 		return true
@@ -531,6 +822,56 @@ func (c *Config) mergeRole(composite *models.Role, inherited *models.Role) {
 		&composite.Groups.Allow, &composite.Groups.Deny,
 		inheritedAllowGroups, inheritedDenyGroups,
 	)
+
+	// The most restrictive reason policy wins
+	composite.ReasonPolicy = composite.ReasonPolicy.Merge(inherited.GetReasonPolicy())
+
+	// As do the shortest max duration and any approval requirement
+	composite.MaxDuration = shorterDuration(composite.MaxDuration, inherited.MaxDuration)
+	composite.RequiresApproval = composite.RequiresApproval || inherited.RequiresApproval
+
+	// The strictest freeze action wins and every freeze approver is asked
+	composite.DuringFreeze = composite.DuringFreeze.Stricter(inherited.DuringFreeze)
+	if len(inherited.FreezeApprovers) > 0 {
+		approvers := slices.Clone(composite.FreezeApprovers)
+		for _, approver := range inherited.FreezeApprovers {
+			if !slices.Contains(approvers, approver) {
+				approvers = append(approvers, approver)
+			}
+		}
+		composite.FreezeApprovers = approvers
+	}
+
+	// Tags are the union of the inheritance chain, the parent's value wins
+	if len(inherited.Tags) > 0 {
+		tags := maps.Clone(inherited.Tags)
+		maps.Copy(tags, composite.Tags)
+		composite.Tags = tags
+	}
+
+	// Deny by default is kept if any inherited role denies by default
+	if inherited.IsDenyByDefault() {
+		composite.DefaultEffect = models.RoleEffectDeny
+	}
+}
+
+// shorterDuration returns the shorter of two durations, ignoring any that
+// are empty or can't be parsed
+func shorterDuration(a string, b string) string {
+
+	durationA, errA := common.ParseDuration(a)
+	durationB, errB := common.ParseDuration(b)
+
+	switch {
+	case errB != nil:
+		return a
+	case errA != nil:
+		return b
+	case durationB < durationA:
+		return b
+	default:
+		return a
+	}
 }
 
 // mergePermissionsWithConflictResolution merges permissions with proper conflict resolution.
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/thand-io/agent/internal/models"
+)
+
+// PermissionAliasPrefix marks a permission as a reference to an alias
+// defined in roles.aliases e.g. alias:storage.read
+const PermissionAliasPrefix = "alias:"
+
+type permissionAliasKey struct {
+	alias        string
+	providerType string
+}
+
+// isPermissionAlias returns true if the permission references an alias
+func isPermissionAlias(permission string) bool {
+	return strings.HasPrefix(permission, PermissionAliasPrefix)
+}
+
+// hasPermissionAliases returns true if the role references any alias
+func hasPermissionAliases(role *models.Role) bool {
+	return slices.ContainsFunc(role.Permissions.Allow, isPermissionAlias) ||
+		slices.ContainsFunc(role.Permissions.Deny, isPermissionAlias)
+}
+
+// validatePermissionAliasDefinitions checks the configured aliases can be
+// expanded within the permission limits
+func (c *Config) validatePermissionAliasDefinitions() error {
+
+	for aliasName, alias := range c.Roles.Aliases {
+
+		if len(alias) == 0 {
+			return fmt.Errorf("permission alias '%s' has no permissions", aliasName)
+		}
+
+		for providerType, permissions := range alias {
+			if len(permissions) > MaxPermissions {
+				return fmt.Errorf("permission alias '%s' exceeds maximum permissions limit for %s: %d > %d",
+					aliasName, providerType, len(permissions), MaxPermissions)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validatePermissionAliases returns an error if the role references an
+// alias that isn't configured
+func (c *Config) validatePermissionAliases(roleKey string, role *models.Role) error {
+
+	for _, permission := range slices.Concat(role.Permissions.Allow, role.Permissions.Deny) {
+
+		_, remainder, _ := c.parseProviderPrefix(permission)
+
+		for _, action := range expandCondensedActions(remainder) {
+
+			if !isPermissionAlias(action) {
+				continue
+			}
+
+			aliasName := strings.TrimPrefix(action, PermissionAliasPrefix)
+
+			if _, found := c.Roles.Aliases[aliasName]; !found {
+				return fmt.Errorf("role '%s' references unknown permission alias '%s'", roleKey, aliasName)
+			}
+		}
+	}
+
+	return nil
+}
+
+// expandRoleAliases replaces the aliases in the role's permissions with the
+// permissions they stand for in the given providers. Without providers an
+// alias expands to its permissions in every provider.
+func (c *Config) expandRoleAliases(role *models.Role, providers []string) error {
+
+	if !hasPermissionAliases(role) {
+		return nil
+	}
+
+	allow, err := c.expandPermissionAliases(role.Permissions.Allow, providers)
+	if err != nil {
+		return err
+	}
+
+	deny, err := c.expandPermissionAliases(role.Permissions.Deny, providers)
+	if err != nil {
+		return err
+	}
+
+	if permCount := len(allow) + len(deny); permCount > MaxPermissions {
+		return fmt.Errorf("role '%s' exceeds maximum permissions limit after expanding aliases: %d > %d",
+			role.Name, permCount, MaxPermissions)
+	}
+
+	role.Permissions.Allow = allow
+	role.Permissions.Deny = deny
+
+	return nil
+}
+
+// expandPermissionAliases expands the aliases in a list of permissions for
+// the provider types of the given providers
+func (c *Config) expandPermissionAliases(permissions []string, providers []string) ([]string, error) {
+
+	if !slices.ContainsFunc(permissions, isPermissionAlias) {
+		return permissions, nil
+	}
+
+	// Providers of the same type share an expansion
+	providerTypes := make([]string, 0, len(providers))
+	for _, providerName := range providers {
+		providerType := c.getProviderType(providerName)
+		if !slices.Contains(providerTypes, providerType) {
+			providerTypes = append(providerTypes, providerType)
+		}
+	}
+
+	if len(providerTypes) == 0 {
+		providerTypes = append(providerTypes, "")
+	}
+
+	result := make([]string, 0, len(permissions))
+	seen := make(map[string]bool, len(permissions))
+
+	add := func(permission string) {
+		if !seen[permission] {
+			seen[permission] = true
+			result = append(result, permission)
+		}
+	}
+
+	for _, permission := range permissions {
+
+		if !isPermissionAlias(permission) {
+			add(permission)
+			continue
+		}
+
+		// Aliases may have been condensed e.g. alias:read,write
+		for _, action := range expandCondensedActions(permission) {
+
+			aliasName := strings.TrimPrefix(action, PermissionAliasPrefix)
+
+			for _, providerType := range providerTypes {
+
+				expanded, err := c.getPermissionAlias(aliasName, providerType)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, expandedPermission := range expanded {
+					add(expandedPermission)
+				}
+			}
+		}
+
+		if len(result) > MaxPermissions {
+			return nil, fmt.Errorf("expanding permission aliases exceeds maximum permissions limit: %d > %d",
+				len(result), MaxPermissions)
+		}
+	}
+
+	return result, nil
+}
+
+// getPermissionAlias returns the permissions an alias stands for in a
+// provider type, or in every provider type when it's empty. Roles are
+// resolved on every request so expansions are cached.
+func (c *Config) getPermissionAlias(aliasName string, providerType string) ([]string, error) {
+
+	key := permissionAliasKey{alias: aliasName, providerType: providerType}
+
+	c.permissionAliasesMu.Lock()
+	cached, found := c.permissionAliases[key]
+	c.permissionAliasesMu.Unlock()
+
+	if found {
+		return cached, nil
+	}
+
+	alias, found := c.Roles.Aliases[aliasName]
+
+	if !found {
+		return nil, fmt.Errorf("unknown permission alias: %s", aliasName)
+	}
+
+	var permissions []string
+
+	if len(providerType) != 0 {
+		permissions = slices.Clone(alias[providerType])
+	} else {
+		providerTypes := make([]string, 0, len(alias))
+		for aliasProviderType := range alias {
+			providerTypes = append(providerTypes, aliasProviderType)
+		}
+		slices.Sort(providerTypes)
+
+		for _, aliasProviderType := range providerTypes {
+			permissions = append(permissions, alias[aliasProviderType]...)
+		}
+	}
+
+	c.permissionAliasesMu.Lock()
+	if c.permissionAliases == nil {
+		c.permissionAliases = map[permissionAliasKey][]string{}
+	}
+	c.permissionAliases[key] = permissions
+	c.permissionAliasesMu.Unlock()
+
+	return permissions, nil
+}
+
+// getProviderType returns the engine type of a provider e.g. aws. Names
+// that aren't configured providers are treated as the type itself.
+func (c *Config) getProviderType(providerName string) string {
+	if provider, err := c.GetProviderByName(providerName); err == nil && len(provider.Provider) != 0 {
+		return provider.Provider
+	}
+	return providerName
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func newAliasTestConfig(t *testing.T, roles map[string]models.Role) *Config {
+	t.Helper()
+
+	config := newTestConfig(t, roles, map[string]models.Provider{
+		"aws-prod": {
+			Name:     "AWS Production",
+			Provider: "aws",
+			Enabled:  true,
+		},
+		"gcp-prod": {
+			Name:     "GCP Production",
+			Provider: "gcp",
+			Enabled:  true,
+		},
+	})
+
+	config.Roles.Aliases = map[string]models.PermissionAlias{
+		"storage.read": {
+			"aws":   {"s3:Get*", "s3:List*"},
+			"gcp":   {"storage.objects.get", "storage.objects.list"},
+			"azure": {"Microsoft.Storage/storageAccounts/blobServices/containers/blobs/read"},
+		},
+		"storage.list": {
+			"aws": {"s3:List*"},
+			"gcp": {"storage.objects.list"},
+		},
+	}
+
+	return config
+}
+
+func TestPermissionAliases(t *testing.T) {
+
+	identity := &models.Identity{
+		ID: "user1",
+		User: &models.User{
+			Username: "testuser",
+			Email:    "testuser@example.com",
+		},
+	}
+
+	t.Run("expands for the role's provider", func(t *testing.T) {
+		config := newAliasTestConfig(t, map[string]models.Role{
+			"reader": {
+				Name:      "reader",
+				Providers: []string{"gcp-prod"},
+				Permissions: models.Permissions{
+					Allow: []string{"alias:storage.read"},
+				},
+				Enabled: true,
+			},
+		})
+
+		result, err := config.GetCompositeRoleByName(identity, "reader")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"storage.objects.get", "storage.objects.list"}, result.Permissions.Allow)
+	})
+
+	t.Run("inherited role without providers", func(t *testing.T) {
+		config := newAliasTestConfig(t, map[string]models.Role{
+			"storage-reader": {
+				Name: "storage-reader",
+				Permissions: models.Permissions{
+					Allow: []string{"alias:storage.read"},
+				},
+				Enabled: true,
+			},
+			"aws-auditor": {
+				Name:      "aws-auditor",
+				Inherits:  []string{"storage-reader"},
+				Providers: []string{"aws-prod"},
+				Permissions: models.Permissions{
+					Allow: []string{"ec2:DescribeInstances"},
+				},
+				Enabled: true,
+			},
+			"gcp-auditor": {
+				Name:      "gcp-auditor",
+				Inherits:  []string{"storage-reader"},
+				Providers: []string{"gcp-prod"},
+				Enabled:   true,
+			},
+		})
+
+		result, err := config.GetCompositeRoleByName(identity, "aws-auditor")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"ec2:DescribeInstances", "s3:Get*,List*"}, result.Permissions.Allow)
+
+		result, err = config.GetCompositeRoleByName(identity, "gcp-auditor")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"storage.objects.get", "storage.objects.list"}, result.Permissions.Allow)
+
+		// On its own the role stands for every provider
+		result, err = config.GetCompositeRoleByName(identity, "storage-reader")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{
+			"Microsoft.Storage/storageAccounts/blobServices/containers/blobs/read",
+			"s3:Get*,List*",
+			"storage.objects.get",
+			"storage.objects.list",
+		}, result.Permissions.Allow)
+	})
+
+	t.Run("deny wins over an overlapping alias", func(t *testing.T) {
+		config := newAliasTestConfig(t, map[string]models.Role{
+			"reader": {
+				Name:      "reader",
+				Providers: []string{"aws-prod"},
+				Permissions: models.Permissions{
+					Allow: []string{"alias:storage.read"},
+					Deny:  []string{"alias:storage.list"},
+				},
+				Enabled: true,
+			},
+		})
+
+		result, err := config.GetCompositeRoleByName(identity, "reader")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"s3:Get*"}, result.Permissions.Allow)
+	})
+
+	t.Run("parent deny wins over an inherited alias", func(t *testing.T) {
+		config := newAliasTestConfig(t, map[string]models.Role{
+			"storage-reader": {
+				Name: "storage-reader",
+				Permissions: models.Permissions{
+					Allow: []string{"alias:storage.read"},
+				},
+				Enabled: true,
+			},
+			"restricted-reader": {
+				Name:      "restricted-reader",
+				Inherits:  []string{"storage-reader"},
+				Providers: []string{"gcp-prod"},
+				Permissions: models.Permissions{
+					Deny: []string{"storage.objects.list"},
+				},
+				Enabled: true,
+			},
+		})
+
+		result, err := config.GetCompositeRoleByName(identity, "restricted-reader")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"storage.objects.get"}, result.Permissions.Allow)
+		assert.NotContains(t, result.Permissions.Allow, "storage.objects.list")
+	})
+
+	t.Run("unknown alias fails validation", func(t *testing.T) {
+		config := newAliasTestConfig(t, nil)
+
+		roles, err := config.ApplyRoles([]*models.RoleDefinitions{{
+			Roles: map[string]models.Role{
+				"reader": {
+					Permissions: models.Permissions{
+						Allow: []string{"alias:storage.read"},
+					},
+					Enabled: true,
+				},
+				"writer": {
+					Permissions: models.Permissions{
+						Allow: []string{"alias:storage.write"},
+					},
+					Enabled: true,
+				},
+			},
+		}})
+		require.NoError(t, err)
+		assert.Contains(t, roles, "reader")
+		assert.NotContains(t, roles, "writer")
+
+		_, err = config.GetCompositeRole(identity, &models.Role{
+			Name:      "writer",
+			Providers: []string{"aws-prod"},
+			Permissions: models.Permissions{
+				Allow: []string{"alias:storage.write"},
+			},
+		})
+		assert.ErrorContains(t, err, "unknown permission alias")
+	})
+
+	t.Run("expansion respects max permissions", func(t *testing.T) {
+		config := newAliasTestConfig(t, nil)
+
+		permissions := make([]string, 0, MaxPermissions)
+		for i := range MaxPermissions {
+			permissions = append(permissions, fmt.Sprintf("storage.buckets%d.get", i))
+		}
+		config.Roles.Aliases["storage.all"] = models.PermissionAlias{"gcp": permissions}
+
+		_, err := config.GetCompositeRole(identity, &models.Role{
+			Name:      "all",
+			Providers: []string{"gcp-prod"},
+			Permissions: models.Permissions{
+				Allow: []string{"alias:storage.all", "alias:storage.read"},
+			},
+		})
+		assert.ErrorContains(t, err, "maximum permissions limit")
+	})
+
+	t.Run("expansions are cached", func(t *testing.T) {
+		config := newAliasTestConfig(t, nil)
+
+		first, err := config.getPermissionAlias("storage.read", "aws")
+		require.NoError(t, err)
+
+		// Changing the definition doesn't change the cached expansion
+		config.Roles.Aliases["storage.read"]["aws"] = []string{"s3:*"}
+
+		second, err := config.getPermissionAlias("storage.read", "aws")
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+		assert.Len(t, config.permissionAliases, 1)
+	})
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestCheckPermissions_DenyByDefault(t *testing.T) {
+
+	config := &Config{}
+
+	roles, err := config.ApplyRoles([]*models.RoleDefinitions{{
+		Version: version.Must(version.NewVersion("1.0")),
+		Roles: map[string]models.Role{
+			"s3-reader": {
+				Name:          "s3-reader",
+				DefaultEffect: models.RoleEffectDeny,
+				Inherits:      []string{"logs-reader"},
+				Permissions: models.Permissions{
+					Allow: []string{"s3:GetObject,ListBucket", "kms:*"},
+					Deny:  []string{"kms:Decrypt"},
+				},
+				Enabled: true,
+			},
+			"logs-reader": {
+				Name: "logs-reader",
+				Permissions: models.Permissions{
+					Allow: []string{"logs:GetLogEvents"},
+				},
+				Enabled: true,
+			},
+			"open": {
+				Name: "open",
+				Permissions: models.Permissions{
+					Allow: []string{"s3:GetObject"},
+				},
+				Enabled: true,
+			},
+		},
+	}})
+	require.NoError(t, err)
+
+	config.Roles.Definitions = roles
+
+	requested := func(name string, allow ...string) *models.Role {
+		return &models.Role{
+			Name:        name,
+			Permissions: models.Permissions{Allow: allow},
+			Enabled:     true,
+		}
+	}
+
+	t.Run("allows the configured role", func(t *testing.T) {
+		role := roles["s3-reader"]
+		assert.NoError(t, config.CheckPermissions(nil, &role))
+	})
+
+	t.Run("allows actions in the allow list", func(t *testing.T) {
+		assert.NoError(t, config.CheckPermissions(nil,
+			requested("s3-reader", "s3:GetObject", "kms:DescribeKey", "logs:GetLogEvents")))
+	})
+
+	t.Run("denies actions not in the allow list", func(t *testing.T) {
+		assert.ErrorContains(t, config.CheckPermissions(nil,
+			requested("s3-reader", "s3:GetObject,PutObject")), "s3:PutObject")
+	})
+
+	t.Run("deny list takes precedence", func(t *testing.T) {
+		assert.ErrorContains(t, config.CheckPermissions(nil,
+			requested("s3-reader", "kms:Decrypt")), "kms:Decrypt")
+	})
+
+	t.Run("roles that allow by default are not checked", func(t *testing.T) {
+		assert.NoError(t, config.CheckPermissions(nil, requested("open", "s3:PutObject")))
+	})
+
+	t.Run("inheriting a deny by default role denies by default", func(t *testing.T) {
+		composite, err := config.GetCompositeRole(nil, &models.Role{
+			Name:     "custom",
+			Inherits: []string{"s3-reader"},
+			Enabled:  true,
+		})
+		require.NoError(t, err)
+		assert.True(t, composite.IsDenyByDefault())
+	})
+}
+
+func TestApplyRoles_InvalidDefaultEffect(t *testing.T) {
+
+	config := &Config{}
+
+	roles, err := config.ApplyRoles([]*models.RoleDefinitions{{
+		Version: version.Must(version.NewVersion("1.0")),
+		Roles: map[string]models.Role{
+			"s3-reader": {
+				Name:          "s3-reader",
+				DefaultEffect: "denny",
+				Permissions: models.Permissions{
+					Allow: []string{"s3:GetObject"},
+				},
+				Enabled: true,
+			},
+		},
+	}})
+	require.NoError(t, err)
+
+	// A typo of deny mustn't leave a role that allows every action
+	assert.NotContains(t, roles, "s3-reader")
+	assert.ErrorContains(t, config.validateRole("s3-reader", &models.Role{DefaultEffect: "block"}),
+		`role 's3-reader': invalid default_effect "block", expected allow or deny`)
+}
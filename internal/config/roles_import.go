@@ -0,0 +1,353 @@
+package config
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Columns of a role import CSV. Only name is required, the other columns
+// can be left out or in any order.
+const (
+	RoleImportColumnName        = "name"
+	RoleImportColumnDescription = "description"
+	RoleImportColumnProviders   = "providers"
+	RoleImportColumnAllow       = "allow"
+	RoleImportColumnInherits    = "inherits"
+	RoleImportColumnMaxDuration = "max_duration"
+	RoleImportColumnScopes      = "scopes"
+)
+
+// RoleImportListSeparator separates the values of list columns e.g.
+// providers or allow, as permissions can contain commas
+const RoleImportListSeparator = ";"
+
+var roleImportColumns = []string{
+	RoleImportColumnName,
+	RoleImportColumnDescription,
+	RoleImportColumnProviders,
+	RoleImportColumnAllow,
+	RoleImportColumnInherits,
+	RoleImportColumnMaxDuration,
+	RoleImportColumnScopes,
+}
+
+// RoleImport is a role read from an import file and the outcome of
+// validating it
+type RoleImport struct {
+	Row       int         // Line of the CSV or position in the JSON
+	Role      models.Role // The role that would be created
+	Duplicate bool        // A role with the same name already exists
+	Err       error       // Why the role would be rejected
+}
+
+// IsValid returns true if the role would be created
+func (r *RoleImport) IsValid() bool {
+	return r.Err == nil
+}
+
+// ParseRoleImportCSV reads roles from a CSV with a header row naming the
+// columns. List columns separate values with a semicolon and scopes are
+// written as user:, group: or domain: followed by the value.
+func ParseRoleImportCSV(reader io.Reader) ([]*RoleImport, error) {
+
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("roles CSV is empty")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read roles CSV header: %w", err)
+	}
+
+	columns := map[string]int{}
+
+	for index, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !slices.Contains(roleImportColumns, column) {
+			return nil, fmt.Errorf("unknown roles CSV column '%s', expected one of %s",
+				column, strings.Join(roleImportColumns, ", "))
+		}
+		columns[column] = index
+	}
+
+	if _, found := columns[RoleImportColumnName]; !found {
+		return nil, fmt.Errorf("roles CSV is missing the '%s' column", RoleImportColumnName)
+	}
+
+	imports := []*RoleImport{}
+
+	for {
+		record, err := csvReader.Read()
+
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read roles CSV: %w", err)
+		}
+
+		line, _ := csvReader.FieldPos(0)
+
+		value := func(column string) string {
+			index, found := columns[column]
+			if !found || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+
+		role := models.Role{
+			Name:        value(RoleImportColumnName),
+			Description: value(RoleImportColumnDescription),
+			Providers:   splitRoleImportList(value(RoleImportColumnProviders)),
+			Inherits:    splitRoleImportList(value(RoleImportColumnInherits)),
+			Permissions: models.Permissions{
+				Allow: splitRoleImportList(value(RoleImportColumnAllow)),
+			},
+			MaxDuration: value(RoleImportColumnMaxDuration),
+			Enabled:     true,
+		}
+
+		roleImport := &RoleImport{Row: line, Role: role}
+
+		if scopes := splitRoleImportList(value(RoleImportColumnScopes)); len(scopes) > 0 {
+			roleImport.Role.Scopes, roleImport.Err = parseRoleImportScopes(scopes)
+		}
+
+		imports = append(imports, roleImport)
+	}
+
+	return imports, nil
+}
+
+// ParseRoleImportJSON reads roles from a JSON list of roles or from roles
+// definitions, the same format as an export
+func ParseRoleImportJSON(data []byte) ([]*RoleImport, error) {
+
+	var roles []models.Role
+
+	if err := json.Unmarshal(data, &roles); err != nil {
+
+		var definitions models.RoleDefinitions
+
+		if defErr := json.Unmarshal(data, &definitions); defErr != nil {
+			return nil, fmt.Errorf("failed to parse roles JSON: %w", err)
+		}
+
+		// Sort the keys so the rows are the same each time
+		for _, roleKey := range slices.Sorted(maps.Keys(definitions.Roles)) {
+			role := definitions.Roles[roleKey]
+			if len(role.Name) == 0 {
+				role.Name = roleKey
+			}
+			roles = append(roles, role)
+		}
+	}
+
+	imports := make([]*RoleImport, 0, len(roles))
+
+	for index, role := range roles {
+		imports = append(imports, &RoleImport{Row: index + 1, Role: role})
+	}
+
+	return imports, nil
+}
+
+func splitRoleImportList(value string) []string {
+
+	values := []string{}
+
+	for item := range strings.SplitSeq(value, RoleImportListSeparator) {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			values = append(values, item)
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	return values
+}
+
+func parseRoleImportScopes(scopes []string) (*models.RoleScopes, error) {
+
+	roleScopes := &models.RoleScopes{}
+
+	for _, scope := range scopes {
+
+		kind, value, found := strings.Cut(scope, ":")
+		value = strings.TrimSpace(value)
+
+		if !found || len(value) == 0 {
+			return nil, fmt.Errorf("invalid scope '%s', expected user:, group: or domain: followed by a value", scope)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(kind)) {
+		case "user":
+			roleScopes.Users = append(roleScopes.Users, value)
+		case "group":
+			roleScopes.Groups = append(roleScopes.Groups, value)
+		case "domain":
+			roleScopes.Domains = append(roleScopes.Domains, value)
+		default:
+			return nil, fmt.Errorf("invalid scope '%s', expected user:, group: or domain: followed by a value", scope)
+		}
+	}
+
+	return roleScopes, nil
+}
+
+// ValidateRoleImport runs the same validation as loading roles over the
+// imported roles, then checks their providers exist and their inheritance
+// resolves against the loaded roles. Roles already loaded are flagged as
+// duplicates and rejected.
+func (c *Config) ValidateRoleImport(imports []*RoleImport) {
+
+	existing := c.GetRoles().Definitions
+	seen := map[string]int{}
+
+	for _, roleImport := range imports {
+
+		if roleImport.Err != nil {
+			continue
+		}
+
+		role := &roleImport.Role
+
+		if len(role.Name) == 0 {
+			roleImport.Err = fmt.Errorf("role has no name")
+			continue
+		}
+
+		if _, found := existing[role.Name]; found {
+			roleImport.Duplicate = true
+			roleImport.Err = fmt.Errorf("role '%s' already exists", role.Name)
+			continue
+		}
+
+		if row, found := seen[role.Name]; found {
+			roleImport.Duplicate = true
+			roleImport.Err = fmt.Errorf("role '%s' is already imported by row %d", role.Name, row)
+			continue
+		}
+
+		seen[role.Name] = roleImport.Row
+
+		if len(role.MaxDuration) > 0 {
+			if _, err := common.ValidateDuration(role.MaxDuration); err != nil {
+				roleImport.Err = fmt.Errorf("role '%s' has an invalid max duration: %w", role.Name, err)
+				continue
+			}
+		}
+
+		if err := c.validateRole(role.Name, role); err != nil {
+			roleImport.Err = err
+			continue
+		}
+
+		for _, providerName := range role.Providers {
+			if _, err := c.GetProviderByName(providerName); err != nil {
+				roleImport.Err = fmt.Errorf("role '%s' uses unknown provider '%s'", role.Name, providerName)
+				break
+			}
+		}
+	}
+
+	// Inheritance is resolved against the loaded roles along with the
+	// valid imported roles, as they can inherit each other
+	definitions := maps.Clone(existing)
+
+	if definitions == nil {
+		definitions = map[string]models.Role{}
+	}
+
+	for _, roleImport := range imports {
+		if roleImport.IsValid() {
+			definitions[roleImport.Role.Name] = roleImport.Role
+		}
+	}
+
+	resolver := &Config{
+		Environment: c.Environment,
+		Providers:   c.Providers,
+		Roles: RoleConfig{
+			ReasonPolicy: c.Roles.ReasonPolicy,
+			Aliases:      c.Roles.Aliases,
+			Definitions:  definitions,
+		},
+	}
+
+	for _, roleImport := range imports {
+
+		if !roleImport.IsValid() {
+			continue
+		}
+
+		if _, err := resolver.GetCompositeRole(nil, &roleImport.Role); err != nil {
+			roleImport.Err = err
+		}
+	}
+}
+
+// GetValidRoleImports returns the roles that would be created as role
+// definitions ready to be written to a roles file
+func GetValidRoleImports(imports []*RoleImport) *models.RoleDefinitions {
+
+	definitions := &models.RoleDefinitions{
+		Version: version.Must(version.NewVersion("1.0")),
+		Roles:   map[string]models.Role{},
+	}
+
+	for _, roleImport := range imports {
+		if roleImport.IsValid() {
+			definitions.Roles[roleImport.Role.Name] = roleImport.Role
+		}
+	}
+
+	return definitions
+}
+
+// Role export formats
+const (
+	RoleExportFormatYAML = "yaml"
+	RoleExportFormatJSON = "json"
+)
+
+// MarshalRoleDefinitions encodes role definitions as YAML or JSON. YAML uses
+// the same keys as JSON so either can be loaded back as roles.
+func MarshalRoleDefinitions(definitions *models.RoleDefinitions, format string) ([]byte, error) {
+
+	data, err := json.MarshalIndent(definitions, "", "  ")
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode roles: %w", err)
+	}
+
+	switch strings.ToLower(format) {
+	case RoleExportFormatJSON:
+		return append(data, '\n'), nil
+	case RoleExportFormatYAML, "yml", "":
+		var values any
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("failed to encode roles: %w", err)
+		}
+		return yaml.Marshal(values)
+	default:
+		return nil, fmt.Errorf("unknown roles format '%s', expected %s or %s",
+			format, RoleExportFormatYAML, RoleExportFormatJSON)
+	}
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+)
+
+const roleImportCSV = `name,providers,allow,inherits,max_duration,scopes
+reader,aws,"ec2:Describe*;s3:GetObject",,4h,group:engineering;domain:example.com
+writer,aws,s3:PutObject,reader,,
+existing,aws,,,,
+broken,aws,,missing,,
+unknown-provider,gcp,,,,
+bad-duration,aws,,,forever,
+bad-scope,aws,,,,team:platform
+reader,aws,,,,
+`
+
+func newRoleImportConfig() *Config {
+	return &Config{
+		Providers: ProviderConfig{
+			Definitions: map[string]models.Provider{
+				"aws": {Name: "aws", Provider: "aws", Enabled: true},
+			},
+		},
+		Roles: RoleConfig{
+			Definitions: map[string]models.Role{
+				"existing": {Name: "existing", Enabled: true},
+			},
+		},
+	}
+}
+
+func TestParseRoleImportCSV(t *testing.T) {
+
+	imports, err := ParseRoleImportCSV(strings.NewReader(roleImportCSV))
+	require.NoError(t, err)
+	require.Len(t, imports, 8)
+
+	reader := imports[0]
+	assert.Equal(t, 2, reader.Row)
+	assert.Equal(t, "reader", reader.Role.Name)
+	assert.Equal(t, []string{"aws"}, reader.Role.Providers)
+	assert.Equal(t, []string{"ec2:Describe*", "s3:GetObject"}, reader.Role.Permissions.Allow)
+	assert.Equal(t, "4h", reader.Role.MaxDuration)
+	assert.Equal(t, &models.RoleScopes{
+		Groups:  []string{"engineering"},
+		Domains: []string{"example.com"},
+	}, reader.Role.Scopes)
+	assert.True(t, reader.Role.Enabled)
+
+	assert.Equal(t, []string{"reader"}, imports[1].Role.Inherits)
+	assert.ErrorContains(t, imports[6].Err, "invalid scope 'team:platform'")
+
+	t.Run("unknown column", func(t *testing.T) {
+		_, err := ParseRoleImportCSV(strings.NewReader("name,owner\nreader,alice\n"))
+		assert.ErrorContains(t, err, "unknown roles CSV column 'owner'")
+	})
+
+	t.Run("missing name column", func(t *testing.T) {
+		_, err := ParseRoleImportCSV(strings.NewReader("providers\naws\n"))
+		assert.ErrorContains(t, err, "missing the 'name' column")
+	})
+}
+
+func TestParseRoleImportJSON(t *testing.T) {
+
+	t.Run("list of roles", func(t *testing.T) {
+		imports, err := ParseRoleImportJSON([]byte(`[{"name": "reader", "providers": ["aws"], "enabled": true}]`))
+		require.NoError(t, err)
+		require.Len(t, imports, 1)
+		assert.Equal(t, 1, imports[0].Row)
+		assert.Equal(t, "reader", imports[0].Role.Name)
+	})
+
+	t.Run("role definitions", func(t *testing.T) {
+		imports, err := ParseRoleImportJSON([]byte(`{"version": "1.0", "roles": {"writer": {"enabled": true}, "reader": {"enabled": true}}}`))
+		require.NoError(t, err)
+		require.Len(t, imports, 2)
+		assert.Equal(t, "reader", imports[0].Role.Name)
+		assert.Equal(t, "writer", imports[1].Role.Name)
+	})
+}
+
+func TestValidateRoleImport(t *testing.T) {
+
+	cfg := newRoleImportConfig()
+
+	imports, err := ParseRoleImportCSV(strings.NewReader(roleImportCSV))
+	require.NoError(t, err)
+
+	cfg.ValidateRoleImport(imports)
+
+	results := map[int]*RoleImport{}
+	for _, roleImport := range imports {
+		results[roleImport.Row] = roleImport
+	}
+
+	assert.NoError(t, results[2].Err)
+	assert.NoError(t, results[3].Err, "can inherit another imported role")
+
+	assert.True(t, results[4].Duplicate)
+	assert.ErrorContains(t, results[4].Err, "role 'existing' already exists")
+
+	assert.ErrorContains(t, results[5].Err, "inherited role 'missing' not found")
+	assert.ErrorContains(t, results[6].Err, "unknown provider 'gcp'")
+	assert.ErrorContains(t, results[7].Err, "invalid max duration")
+	assert.ErrorContains(t, results[8].Err, "invalid scope")
+
+	assert.True(t, results[9].Duplicate)
+	assert.ErrorContains(t, results[9].Err, "already imported by row 2")
+
+	definitions := GetValidRoleImports(imports)
+	assert.Len(t, definitions.Roles, 2)
+	assert.Contains(t, definitions.Roles, "reader")
+	assert.Contains(t, definitions.Roles, "writer")
+}
+
+func TestValidateRoleImport_Limits(t *testing.T) {
+
+	cfg := newRoleImportConfig()
+
+	imports := []*RoleImport{{
+		Row: 1,
+		Role: models.Role{
+			Name:      "too-many-providers",
+			Providers: []string{"aws", "aws", "aws", "aws", "aws", "aws"},
+			Enabled:   true,
+		},
+	}}
+
+	cfg.ValidateRoleImport(imports)
+
+	assert.ErrorContains(t, imports[0].Err, "exceeds maximum providers limit")
+}
+
+func TestMarshalRoleDefinitions(t *testing.T) {
+
+	definitions := GetValidRoleImports([]*RoleImport{{
+		Role: models.Role{
+			Name:        "reader",
+			Providers:   []string{"aws"},
+			MaxDuration: "4h",
+			Enabled:     true,
+		},
+	}})
+
+	data, err := MarshalRoleDefinitions(definitions, RoleExportFormatYAML)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "max_duration: 4h")
+
+	// The export loads back as the same roles
+	loaded, err := common.ReadDataToInterface(data, models.RoleDefinitions{})
+	require.NoError(t, err)
+	assert.Equal(t, definitions.Roles["reader"].MaxDuration, loaded.Roles["reader"].MaxDuration)
+	assert.Equal(t, "1.0.0", loaded.Version.String())
+
+	data, err = MarshalRoleDefinitions(definitions, RoleExportFormatJSON)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"max_duration": "4h"`)
+
+	_, err = MarshalRoleDefinitions(definitions, "xml")
+	assert.ErrorContains(t, err, "unknown roles format")
+}
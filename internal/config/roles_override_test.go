@@ -0,0 +1,124 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestEnvironmentRoleOverrides(t *testing.T) {
+
+	requiresApproval := true
+	disabled := true
+
+	config := &Config{
+		Environment: models.EnvironmentConfig{
+			Name: "production",
+			Overrides: map[string]models.RoleOverride{
+				"admin": {
+					MaxDuration:      "1h",
+					RequiresApproval: &requiresApproval,
+				},
+				"sandbox": {
+					Disabled: &disabled,
+				},
+			},
+		},
+	}
+
+	roles, err := config.ApplyRoles([]*models.RoleDefinitions{{
+		Version: version.Must(version.NewVersion("1.0")),
+		Roles: map[string]models.Role{
+			"admin": {
+				Name:        "admin",
+				Inherits:    []string{"viewer"},
+				MaxDuration: "8h",
+				Enabled:     true,
+			},
+			"viewer": {
+				Name:        "viewer",
+				MaxDuration: "4h",
+				Enabled:     true,
+			},
+			"sandbox": {
+				Name:    "sandbox",
+				Enabled: true,
+			},
+		},
+	}})
+	require.NoError(t, err)
+
+	config.Roles.Definitions = roles
+
+	t.Run("disabled roles are not loaded", func(t *testing.T) {
+		assert.NotContains(t, roles, "sandbox")
+		assert.Contains(t, roles, "viewer")
+	})
+
+	t.Run("overrides are applied after loading", func(t *testing.T) {
+		assert.Equal(t, "1h", roles["admin"].MaxDuration)
+		assert.True(t, roles["admin"].RequiresApproval)
+	})
+
+	t.Run("overrides replace inherited values", func(t *testing.T) {
+		compositeRole, err := config.GetCompositeRole(nil, &models.Role{
+			Name:     "admin",
+			Inherits: []string{"viewer"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "1h", compositeRole.MaxDuration)
+		assert.True(t, compositeRole.RequiresApproval)
+	})
+
+	t.Run("max duration is enforced", func(t *testing.T) {
+		admin := &models.Role{Name: "admin"}
+
+		assert.NoError(t, config.CheckDuration(nil, admin, "30m"))
+		assert.ErrorContains(t, config.CheckDuration(nil, admin, "2h"), "must not be longer than")
+
+		viewer := &models.Role{Name: "viewer"}
+
+		assert.NoError(t, config.CheckDuration(nil, viewer, "4h"))
+		assert.Error(t, config.CheckDuration(nil, viewer, "5h"))
+	})
+
+	t.Run("approval is required by the policy decision", func(t *testing.T) {
+		decision, err := config.EvaluatePolicies(context.Background(), &models.PolicyInput{
+			Role: &models.Role{Name: "admin"},
+		})
+		require.NoError(t, err)
+		assert.True(t, decision.RequiresApproval())
+
+		decision, err = config.EvaluatePolicies(context.Background(), &models.PolicyInput{
+			Role: &models.Role{Name: "viewer"},
+		})
+		require.NoError(t, err)
+		assert.False(t, decision.RequiresApproval())
+	})
+
+	t.Run("invalid overrides are rejected", func(t *testing.T) {
+		invalid := &Config{
+			Environment: models.EnvironmentConfig{
+				Overrides: map[string]models.RoleOverride{
+					"admin": {MaxDuration: "forever"},
+				},
+			},
+		}
+
+		_, err := invalid.ApplyRoles(nil)
+		assert.ErrorContains(t, err, "invalid environment override for role 'admin'")
+	})
+}
+
+func TestShorterDuration(t *testing.T) {
+	assert.Equal(t, "1h", shorterDuration("1h", "PT2H"))
+	assert.Equal(t, "30m", shorterDuration("1h", "30m"))
+	assert.Equal(t, "1h", shorterDuration("1h", ""))
+	assert.Equal(t, "2h", shorterDuration("", "2h"))
+	assert.Equal(t, "", shorterDuration("", ""))
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestReasonPolicyResolution(t *testing.T) {
+
+	config := &Config{
+		Roles: RoleConfig{
+			ReasonPolicy: &models.ReasonPolicy{MinLength: 10},
+			Definitions: map[string]models.Role{
+				"viewer": {
+					Name:    "viewer",
+					Enabled: true,
+				},
+				"incident": {
+					Name: "incident",
+					ReasonPolicy: &models.ReasonPolicy{
+						MustMatch: []string{`INC-\d+`},
+					},
+					Enabled: true,
+				},
+				"jira": {
+					Name:                 "jira",
+					JustificationPattern: `JIRA-\d+`,
+					Enabled:              true,
+				},
+				"jira-admin": {
+					Name:     "jira-admin",
+					Inherits: []string{"jira"},
+					ReasonPolicy: &models.ReasonPolicy{
+						MustMatch: []string{`(?i)deploy`},
+					},
+					Enabled: true,
+				},
+				"admin": {
+					Name:     "admin",
+					Inherits: []string{"incident"},
+					ReasonPolicy: &models.ReasonPolicy{
+						MinLength:    30,
+						MustNotMatch: []string{`(?i)test`},
+					},
+					Enabled: true,
+				},
+			},
+		},
+	}
+
+	t.Run("roles without a policy use the default", func(t *testing.T) {
+		role, err := config.GetRoleByName("viewer")
+		require.NoError(t, err)
+
+		policy, err := config.GetReasonPolicy(nil, role)
+		require.NoError(t, err)
+		assert.Equal(t, 10, policy.MinLength)
+	})
+
+	t.Run("role policy overrides the default", func(t *testing.T) {
+		role, err := config.GetRoleByName("incident")
+		require.NoError(t, err)
+
+		assert.NoError(t, config.CheckReason(nil, role, "INC-1"))
+		assert.ErrorContains(t, config.CheckReason(nil, role, "fixing prod"), `must match INC-\d+`)
+	})
+
+	t.Run("inherited policies are combined", func(t *testing.T) {
+		role, err := config.GetRoleByName("admin")
+		require.NoError(t, err)
+
+		policy, err := config.GetReasonPolicy(nil, role)
+		require.NoError(t, err)
+		assert.Equal(t, 30, policy.MinLength)
+		assert.Equal(t, []string{`INC-\d+`}, policy.MustMatch)
+		assert.Equal(t, []string{`(?i)test`}, policy.MustNotMatch)
+
+		assert.ErrorContains(t, config.CheckReason(nil, role, "INC-42 short"), "at least 30 characters")
+		assert.ErrorContains(t, config.CheckReason(nil, role, "INC-42 testing the database failover"), "must not match")
+		assert.NoError(t, config.CheckReason(nil, role, "INC-42 restarting the primary database"))
+	})
+
+	t.Run("justification pattern is added to the policy", func(t *testing.T) {
+		role, err := config.GetRoleByName("jira")
+		require.NoError(t, err)
+
+		policy, err := config.GetReasonPolicy(nil, role)
+		require.NoError(t, err)
+		assert.Equal(t, []string{`JIRA-\d+`}, policy.MustMatch)
+		assert.Equal(t, `matching JIRA-\d+`, policy.GetHint())
+
+		assert.NoError(t, config.CheckReason(nil, role, "JIRA-123"))
+		assert.ErrorContains(t, config.CheckReason(nil, role, "fixing prod"), `must match JIRA-\d+`)
+	})
+
+	t.Run("inherited justification patterns are combined", func(t *testing.T) {
+		role, err := config.GetRoleByName("jira-admin")
+		require.NoError(t, err)
+
+		policy, err := config.GetReasonPolicy(nil, role)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{`(?i)deploy`, `JIRA-\d+`}, policy.MustMatch)
+
+		assert.ErrorContains(t, config.CheckReason(nil, role, "deploying the hotfix"), `must match JIRA-\d+`)
+		assert.NoError(t, config.CheckReason(nil, role, "JIRA-7 deploying the hotfix"))
+	})
+
+	t.Run("requests can't drop the configured policy", func(t *testing.T) {
+		assert.Error(t, config.CheckReason(nil, &models.Role{Name: "incident"}, "no ticket"))
+	})
+
+	t.Run("unknown roles use the default", func(t *testing.T) {
+		dynamic := &models.Role{Name: "dynamic-role"}
+		assert.ErrorContains(t, config.CheckReason(nil, dynamic, "short"), "at least 10 characters")
+		assert.NoError(t, config.CheckReason(nil, dynamic, "long enough reason"))
+	})
+}
+
+func TestApplyRolesReasonPolicy(t *testing.T) {
+
+	t.Run("invalid role policies are skipped", func(t *testing.T) {
+		config := &Config{}
+
+		roles, err := config.ApplyRoles([]*models.RoleDefinitions{{
+			Roles: map[string]models.Role{
+				"valid":   {Enabled: true},
+				"invalid": {Enabled: true, ReasonPolicy: &models.ReasonPolicy{MustMatch: []string{"("}}},
+				"pattern": {Enabled: true, JustificationPattern: "JIRA-("},
+			},
+		}})
+		require.NoError(t, err)
+		assert.Contains(t, roles, "valid")
+		assert.NotContains(t, roles, "invalid")
+		assert.NotContains(t, roles, "pattern")
+	})
+
+	t.Run("default policy is loaded with the roles", func(t *testing.T) {
+		config := &Config{}
+
+		_, err := config.ApplyRoles([]*models.RoleDefinitions{{
+			ReasonPolicy: &models.ReasonPolicy{MinLength: 15},
+		}})
+		require.NoError(t, err)
+		require.NotNil(t, config.Roles.ReasonPolicy)
+		assert.Equal(t, 15, config.Roles.ReasonPolicy.MinLength)
+	})
+
+	t.Run("invalid default policy is an error", func(t *testing.T) {
+		config := &Config{Roles: RoleConfig{
+			ReasonPolicy: &models.ReasonPolicy{MustNotMatch: []string{"["}},
+		}}
+
+		_, err := config.ApplyRoles(nil)
+		assert.Error(t, err)
+	})
+}
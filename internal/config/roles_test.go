@@ -452,6 +452,45 @@ func TestGetCompositeRole_ProviderSpecificInheritance(t *testing.T) {
 	assert.ElementsMatch(t, []string{"base:read"}, result.Permissions.Allow)
 }
 
+func TestGetCompositeRole_Tags(t *testing.T) {
+	config := &Config{
+		Roles: RoleConfig{
+			Definitions: map[string]models.Role{
+				"base": {
+					Name:    "base",
+					Enabled: true,
+					Tags:    map[string]string{"team": "platform", "risk": "low"},
+				},
+				"admin": {
+					Name:     "admin",
+					Inherits: []string{"base"},
+					Enabled:  true,
+					Tags:     map[string]string{"risk": "high", "environment": "production"},
+				},
+			},
+		},
+	}
+
+	identity := &models.Identity{
+		ID:   "user1",
+		User: &models.User{Username: "testuser"},
+	}
+
+	result, err := config.GetCompositeRoleByName(identity, "admin")
+	require.NoError(t, err)
+
+	// The inheriting role's value wins
+	assert.Equal(t, map[string]string{
+		"team":        "platform",
+		"risk":        "high",
+		"environment": "production",
+	}, result.Tags)
+
+	// The configured roles are left alone
+	assert.Len(t, config.Roles.Definitions["admin"].Tags, 2)
+	assert.Len(t, config.Roles.Definitions["base"].Tags, 2)
+}
+
 func TestIsRoleApplicableToIdentity(t *testing.T) {
 	config := &Config{}
 
@@ -575,6 +614,72 @@ func TestIsRoleApplicableToIdentity(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "condition only - true",
+			role: &models.Role{
+				Name: "test",
+				Scopes: &models.RoleScopes{
+					Condition: `"oncall" in groups`,
+				},
+			},
+			identity: &models.Identity{
+				ID: "user1",
+				User: &models.User{
+					Username: "testuser",
+					Groups:   []string{"developers", "oncall"},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "condition only - false",
+			role: &models.Role{
+				Name: "test",
+				Scopes: &models.RoleScopes{
+					Condition: `"oncall" in groups`,
+				},
+			},
+			identity: &models.Identity{
+				ID: "user1",
+				User: &models.User{
+					Username: "testuser",
+					Groups:   []string{"developers"},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "condition must hold as well as the scopes",
+			role: &models.Role{
+				Name: "test",
+				Scopes: &models.RoleScopes{
+					Groups:    []string{"developers"},
+					Condition: `user.domain == "example.com"`,
+				},
+			},
+			identity: &models.Identity{
+				ID: "user1",
+				User: &models.User{
+					Email:  "test@other.com",
+					Groups: []string{"developers"},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "condition that fails to evaluate doesn't match",
+			role: &models.Role{
+				Name: "test",
+				Scopes: &models.RoleScopes{
+					Condition: `user.missing == "x"`,
+				},
+			},
+			identity: &models.Identity{
+				ID:   "user1",
+				User: &models.User{Username: "testuser"},
+			},
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -585,6 +690,22 @@ func TestIsRoleApplicableToIdentity(t *testing.T) {
 	}
 }
 
+// TestValidateRoleCondition tests scope conditions are type checked when roles are loaded
+func TestValidateRoleCondition(t *testing.T) {
+	config := &Config{}
+
+	assert.NoError(t, config.validateRole("oncall", &models.Role{
+		Name:   "oncall",
+		Scopes: &models.RoleScopes{Condition: `"oncall" in groups`},
+	}))
+
+	err := config.validateRole("oncall", &models.Role{
+		Name:   "oncall",
+		Scopes: &models.RoleScopes{Condition: `groups.size()`},
+	})
+	assert.EqualError(t, err, "role 'oncall': invalid scopes condition: expression must return a bool, not int")
+}
+
 // TestAllowDenyConflictResolution tests how Allow/Deny conflicts are resolved during role inheritance
 func TestAllowDenyConflictResolution(t *testing.T) {
 	t.Run("parent allow overrides child deny", func(t *testing.T) {
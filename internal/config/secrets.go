@@ -0,0 +1,55 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/thand-io/agent/internal/models"
+)
+
+// VaultSecretScheme is the URI scheme used to reference secrets in the
+// vault service e.g. vault://thand/slack#bot_token
+const VaultSecretScheme = "vault"
+
+// newVaultSecretResolver resolves vault:// references with the vault
+// service. When a #field is given the secret is expected to be a JSON
+// object and only that key is returned.
+func newVaultSecretResolver(vault models.VaultImpl) models.SecretResolverFunc {
+	return func(_ *models.BasicConfig, reference string) (string, error) {
+
+		path, field, _ := strings.Cut(reference[len(VaultSecretScheme+"://"):], "#")
+
+		if len(path) == 0 {
+			return "", fmt.Errorf("vault reference is missing a secret path: %s", reference)
+		}
+
+		data, err := vault.GetSecret(path)
+
+		if err != nil {
+			return "", fmt.Errorf("failed to get secret %s from vault: %w", path, err)
+		}
+
+		if len(field) == 0 {
+			return string(data), nil
+		}
+
+		var values map[string]any
+		if err := json.Unmarshal(data, &values); err != nil {
+			return "", fmt.Errorf("secret %s is not a JSON object: %w", path, err)
+		}
+
+		value, found := values[field]
+
+		if !found {
+			return "", fmt.Errorf("secret %s does not contain field %s", path, field)
+		}
+
+		if str, ok := value.(string); ok {
+			return str, nil
+		}
+
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	}
+}
@@ -17,6 +17,7 @@ type localClient struct {
 	encrypt   models.EncryptionImpl
 	vault     models.VaultImpl
 	scheduler models.SchedulerImpl
+	storage   models.StorageImpl
 	llm       models.LargeLanguageModelImpl
 	temporal  models.TemporalImpl
 }
@@ -62,6 +63,7 @@ func (e *localClient) Initialize() error {
 	e.encrypt = e.configureEncryption()
 	e.vault = e.configureVault()
 	e.scheduler = e.configureScheduler()
+	e.storage = e.configureStorage()
 
 	// Lets in parallel initialise all the internal services we need
 	var wg sync.WaitGroup
@@ -102,6 +104,18 @@ func (e *localClient) Initialize() error {
 		}
 	})
 
+	wg.Go(func() {
+
+		logrus.Infof("Initializing storage...")
+
+		if e.storage != nil {
+			if err := e.storage.Initialize(); err != nil {
+				logrus.Errorf("Error initializing storage: %v", err)
+				e.storage = nil // Disable storage if initialization fails
+			}
+		}
+	})
+
 	if e.config.LargeLanguageModel != nil {
 
 		wg.Go(func() {
@@ -186,11 +200,11 @@ func (e *localClient) HasVault() bool {
 }
 
 func (e *localClient) GetStorage() models.StorageImpl {
-	return nil
+	return e.storage
 }
 
 func (e *localClient) HasStorage() bool {
-	return false
+	return e.storage != nil
 }
 
 func (e *localClient) GetScheduler() models.SchedulerImpl {
@@ -0,0 +1,35 @@
+package services
+
+import (
+	storage "github.com/thand-io/agent/internal/config/services/storage"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func (e *localClient) configureStorage() models.StorageImpl {
+
+	// Cloud storage needs a bucket so, unlike the other services, the
+	// platform isn't used to pick the provider
+	provider := "local"
+	storageConfig := e.GetServicesConfig().GetStorageConfig()
+
+	if storageConfig != nil && len(storageConfig.Provider) > 0 {
+		provider = storageConfig.GetProvider()
+	}
+
+	// This allows us to pass in any config values defined in the environment
+	configValues := e.config.GetStorageConfigWithDefaults(e.GetEnvironmentConfig().Config)
+
+	switch provider {
+	case string(models.AWS):
+		// AWS Storage - S3
+		return storage.NewAwsStorageFromConfig(configValues)
+	case string(models.GCP):
+		// GCP Storage - GCS
+		return storage.NewGcpStorageFromConfig(configValues)
+	case string(models.Local):
+		fallthrough
+	default:
+		return storage.NewLocalStorageFromConfig(configValues, e.GetSecret())
+	}
+
+}
@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thand-io/agent/internal/models"
+)
+
+// LocalStoragePath is the route, relative to the API base path, that serves
+// objects from local storage
+const LocalStoragePath = "storage"
+
+type localStorage struct {
+	config *models.BasicConfig
+	path   string
+	secret []byte
+}
+
+// NewLocalStorageFromConfig stores objects in a local directory, by default
+// in the temp dir. Signed URLs are signed with the server secret and served
+// by the agent.
+func NewLocalStorageFromConfig(config *models.BasicConfig, secret string) *localStorage {
+	return &localStorage{
+		config: config,
+		secret: []byte(secret),
+	}
+}
+
+func (l *localStorage) Initialize() error {
+
+	l.path = l.config.GetStringWithDefault("path", filepath.Join(os.TempDir(), "thand-storage"))
+
+	// Only allow access to the owner
+	if err := os.MkdirAll(l.path, 0700); err != nil {
+		return fmt.Errorf("failed to create storage directory %s: %w", l.path, err)
+	}
+
+	return nil
+}
+
+func (l *localStorage) Shutdown() error {
+	return nil
+}
+
+func (l *localStorage) PutObject(ctx context.Context, key string, contentType string, data []byte) error {
+
+	objectPath, err := l.getObjectPath(key)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(objectPath), 0700); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	if err := os.WriteFile(objectPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to store object %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (l *localStorage) GetObject(ctx context.Context, key string) ([]byte, error) {
+
+	objectPath, err := l.getObjectPath(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(objectPath)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, models.ErrObjectNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", key, err)
+	}
+
+	return data, nil
+}
+
+func (l *localStorage) GetSignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+
+	if _, err := l.getObjectPath(key); err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+
+	query := url.Values{
+		"expires":   {expires},
+		"signature": {l.sign(key, expires)},
+	}
+
+	return fmt.Sprintf("%s/%s?%s", LocalStoragePath, escapeObjectKey(key), query.Encode()), nil
+}
+
+func (l *localStorage) GetSignedObject(ctx context.Context, key string, query url.Values) ([]byte, string, error) {
+
+	expires := query.Get("expires")
+	signature := query.Get("signature")
+
+	if !hmac.Equal([]byte(signature), []byte(l.sign(key, expires))) {
+		return nil, "", fmt.Errorf("invalid signature")
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+
+	if err != nil || time.Now().Unix() > expiresAt {
+		return nil, "", fmt.Errorf("signed URL has expired")
+	}
+
+	data, err := l.GetObject(ctx, key)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+
+	if len(contentType) == 0 {
+		contentType = http.DetectContentType(data)
+	}
+
+	return data, contentType, nil
+}
+
+// getObjectPath returns the path of the object, keys can't escape the
+// storage directory
+func (l *localStorage) getObjectPath(key string) (string, error) {
+
+	cleaned := path.Clean("/" + key)
+
+	if len(key) == 0 || cleaned == "/" || cleaned != "/"+key {
+		return "", fmt.Errorf("invalid object key: %s", key)
+	}
+
+	return filepath.Join(l.path, filepath.FromSlash(strings.TrimPrefix(cleaned, "/"))), nil
+}
+
+func (l *localStorage) sign(key string, expires string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// escapeObjectKey escapes each segment of the key for use in a URL path
+func escapeObjectKey(key string) string {
+
+	segments := strings.Split(key, "/")
+
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func newTestLocalStorage(t *testing.T) *localStorage {
+	t.Helper()
+
+	storage := NewLocalStorageFromConfig(&models.BasicConfig{"path": t.TempDir()}, "test-secret")
+	require.NoError(t, storage.Initialize())
+
+	return storage
+}
+
+func getSignedQuery(t *testing.T, signedURL string) (string, url.Values) {
+	t.Helper()
+
+	parsed, err := url.Parse(signedURL)
+	require.NoError(t, err)
+
+	return strings.TrimPrefix(parsed.Path, LocalStoragePath+"/"), parsed.Query()
+}
+
+func TestLocalStorage_SignedURL(t *testing.T) {
+
+	ctx := context.Background()
+	storage := newTestLocalStorage(t)
+
+	key := "forms/workflow-1/upload/report.pdf"
+	require.NoError(t, storage.PutObject(ctx, key, "application/pdf", []byte("%PDF-1.4")))
+
+	signedURL, err := storage.GetSignedURL(ctx, key, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(signedURL, LocalStoragePath+"/forms/workflow-1/upload/report.pdf?"))
+
+	t.Run("round trip", func(t *testing.T) {
+		urlKey, query := getSignedQuery(t, signedURL)
+
+		data, contentType, err := storage.GetSignedObject(ctx, urlKey, query)
+
+		require.NoError(t, err)
+		assert.Equal(t, []byte("%PDF-1.4"), data)
+		assert.Equal(t, "application/pdf", contentType)
+	})
+
+	t.Run("signature is for one key", func(t *testing.T) {
+		_, query := getSignedQuery(t, signedURL)
+
+		_, _, err := storage.GetSignedObject(ctx, "forms/workflow-1/upload/other.pdf", query)
+
+		assert.ErrorContains(t, err, "invalid signature")
+	})
+
+	t.Run("tampered expiry", func(t *testing.T) {
+		urlKey, query := getSignedQuery(t, signedURL)
+		query.Set("expires", "9999999999")
+
+		_, _, err := storage.GetSignedObject(ctx, urlKey, query)
+
+		assert.ErrorContains(t, err, "invalid signature")
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expiredURL, err := storage.GetSignedURL(ctx, key, -time.Minute)
+		require.NoError(t, err)
+
+		urlKey, query := getSignedQuery(t, expiredURL)
+
+		_, _, err = storage.GetSignedObject(ctx, urlKey, query)
+
+		assert.ErrorContains(t, err, "expired")
+	})
+
+	t.Run("other secret", func(t *testing.T) {
+		other := NewLocalStorageFromConfig(&models.BasicConfig{"path": storage.path}, "other-secret")
+		require.NoError(t, other.Initialize())
+
+		urlKey, query := getSignedQuery(t, signedURL)
+
+		_, _, err := other.GetSignedObject(ctx, urlKey, query)
+
+		assert.ErrorContains(t, err, "invalid signature")
+	})
+}
+
+func TestLocalStorage_InvalidKeys(t *testing.T) {
+
+	ctx := context.Background()
+	storage := newTestLocalStorage(t)
+
+	for _, key := range []string{"", "/", "../escape.txt", "forms/../../escape.txt", "/absolute.txt", "forms//double.txt"} {
+		t.Run(key, func(t *testing.T) {
+			assert.Error(t, storage.PutObject(ctx, key, "text/plain", []byte("data")))
+
+			_, err := storage.GetSignedURL(ctx, key, time.Hour)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLocalStorage_GetObject(t *testing.T) {
+
+	ctx := context.Background()
+	storage := newTestLocalStorage(t)
+
+	_, err := storage.GetObject(ctx, "delegations/delegations.json")
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+
+	require.NoError(t, storage.PutObject(ctx, "delegations/delegations.json", "application/json", []byte("{}")))
+
+	data, err := storage.GetObject(ctx, "delegations/delegations.json")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("{}"), data)
+}
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/thand-io/agent/internal/models"
+	awsProvider "github.com/thand-io/agent/internal/providers/aws"
+)
+
+// maxSignedURLExpiry is the longest a SigV4 presigned URL can be valid for
+const maxSignedURLExpiry = 7 * 24 * time.Hour
+
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// objectStorage stores objects in an S3 compatible bucket. Requests are
+// SigV4 signed directly as uploading and presigning are the only calls the
+// agent makes.
+type objectStorage struct {
+	config     *models.BasicConfig
+	initialize func(o *objectStorage) error
+
+	bucket      string
+	region      string
+	endpoint    string
+	pathStyle   bool
+	credentials aws.CredentialsProvider
+	httpClient  *http.Client
+}
+
+// NewAwsStorageFromConfig stores objects in an S3 bucket
+func NewAwsStorageFromConfig(config *models.BasicConfig) models.StorageImpl {
+	return &objectStorage{
+		config:     config,
+		initialize: initializeAwsStorage,
+	}
+}
+
+// NewGcpStorageFromConfig stores objects in a GCS bucket through its S3
+// compatible XML API, using an HMAC key for the service account
+func NewGcpStorageFromConfig(config *models.BasicConfig) models.StorageImpl {
+	return &objectStorage{
+		config:     config,
+		initialize: initializeGcpStorage,
+	}
+}
+
+func initializeAwsStorage(o *objectStorage) error {
+
+	sdkConfig, err := awsProvider.CreateAwsConfig(o.config)
+
+	if err != nil {
+		return fmt.Errorf("failed to create AWS config: %w", err)
+	}
+
+	o.region = sdkConfig.Config.Region
+	o.credentials = sdkConfig.Config.Credentials
+
+	// A custom endpoint e.g. LocalStack or MinIO is addressed by path
+	if endpoint, found := o.config.GetString("endpoint"); found && len(endpoint) > 0 {
+		o.endpoint = strings.TrimSuffix(endpoint, "/")
+		o.pathStyle = true
+	} else {
+		o.endpoint = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", o.bucket, o.region)
+	}
+
+	return nil
+}
+
+func initializeGcpStorage(o *objectStorage) error {
+
+	accessKeyId, foundAccessKeyId := o.config.GetString("access_key_id")
+	secretAccessKey, foundSecretAccessKey := o.config.GetString("secret_access_key")
+
+	if !foundAccessKeyId || !foundSecretAccessKey {
+		return fmt.Errorf("GCS storage requires an HMAC key as access_key_id and secret_access_key")
+	}
+
+	o.region = "auto"
+	o.credentials = credentials.NewStaticCredentialsProvider(accessKeyId, secretAccessKey, "")
+	o.endpoint = strings.TrimSuffix(o.config.GetStringWithDefault("endpoint", "https://storage.googleapis.com"), "/")
+	o.pathStyle = true
+
+	return nil
+}
+
+func (o *objectStorage) Initialize() error {
+
+	bucket, found := o.config.GetString("bucket")
+
+	if !found || len(bucket) == 0 {
+		return fmt.Errorf("storage bucket is required")
+	}
+
+	o.bucket = bucket
+	o.httpClient = &http.Client{Timeout: 60 * time.Second}
+
+	return o.initialize(o)
+}
+
+func (o *objectStorage) Shutdown() error {
+	return nil
+}
+
+func (o *objectStorage) PutObject(ctx context.Context, key string, contentType string, data []byte) error {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, o.getObjectURL(key), bytes.NewReader(data))
+
+	if err != nil {
+		return err
+	}
+
+	payloadHash := sha256.Sum256(data)
+	payloadHashHex := hex.EncodeToString(payloadHash[:])
+
+	req.ContentLength = int64(len(data))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHashHex)
+
+	creds, err := o.credentials.Retrieve(ctx)
+
+	if err != nil {
+		return fmt.Errorf("failed to retrieve storage credentials: %w", err)
+	}
+
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, payloadHashHex, "s3", o.region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	resp, err := o.httpClient.Do(req)
+
+	if err != nil {
+		return fmt.Errorf("failed to store object %s: %w", key, err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("failed to store object %s, storage returned %d: %s",
+			key, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+func (o *objectStorage) GetObject(ctx context.Context, key string) ([]byte, error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.getObjectURL(key), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	creds, err := o.credentials.Retrieve(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve storage credentials: %w", err)
+	}
+
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, unsignedPayload, "s3", o.region, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	resp, err := o.httpClient.Do(req)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, models.ErrObjectNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("failed to get object %s, storage returned %d: %s",
+			key, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func (o *objectStorage) GetSignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+
+	expiry = min(expiry, maxSignedURLExpiry)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.getObjectURL(key), nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	query := req.URL.Query()
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	req.URL.RawQuery = query.Encode()
+
+	creds, err := o.credentials.Retrieve(ctx)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve storage credentials: %w", err)
+	}
+
+	signedURL, _, err := v4.NewSigner().PresignHTTP(ctx, creds, req, unsignedPayload, "s3", o.region, time.Now())
+
+	if err != nil {
+		return "", fmt.Errorf("failed to sign URL: %w", err)
+	}
+
+	return signedURL, nil
+}
+
+func (o *objectStorage) getObjectURL(key string) string {
+	if o.pathStyle {
+		return fmt.Sprintf("%s/%s/%s", o.endpoint, o.bucket, escapeObjectKey(key))
+	}
+	return fmt.Sprintf("%s/%s", o.endpoint, escapeObjectKey(key))
+}
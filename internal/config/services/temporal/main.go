@@ -4,10 +4,12 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"slices"
 
 	"github.com/sirupsen/logrus"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/models"
+	"go.temporal.io/api/enums/v1"
 	"go.temporal.io/api/workflowservice/v1"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/worker"
@@ -19,13 +21,19 @@ type TemporalClient struct {
 	client   client.Client
 	worker   worker.Worker
 	identity string
+
+	// Names registered by plugins, checked before registering another
+	pluginWorkflows  map[string]struct{}
+	pluginActivities map[string]struct{}
 }
 
 func NewTemporalClient(config *models.TemporalConfig, identity string) *TemporalClient {
 
 	return &TemporalClient{
-		config:   config,
-		identity: identity,
+		config:           config,
+		identity:         identity,
+		pluginWorkflows:  map[string]struct{}{},
+		pluginActivities: map[string]struct{}{},
 	}
 }
 
@@ -103,12 +111,38 @@ func (a *TemporalClient) Initialize() error {
 		}
 	}
 
-	// Create worker with configured options
-	a.worker = worker.New(
+	// Create worker with configured options. Workers for additional task
+	// queues, e.g. a region, share the options so they join the same
+	// deployment version as the agent's own queue.
+	additionalWorkers := []worker.Worker{}
+
+	for _, taskQueue := range a.config.TaskQueues {
+
+		if len(taskQueue) == 0 || taskQueue == a.GetTaskQueue() {
+			continue
+		}
+
+		logrus.Infof("Polling additional Temporal task queue: %s", taskQueue)
+
+		additionalWorkers = append(additionalWorkers, worker.New(
+			temporalClient,
+			taskQueue,
+			workerOptions,
+		))
+	}
+
+	a.worker = newQueueWorkers(worker.New(
 		temporalClient,
 		a.GetTaskQueue(),
 		workerOptions,
-	)
+	), additionalWorkers...)
+
+	// Plugins are registered before the worker starts polling
+	for _, plugin := range a.config.Plugins {
+		if err := a.RegisterWorkflow(plugin.Name, plugin.Path); err != nil {
+			return fmt.Errorf("failed to register workflow plugin '%s': %w", plugin.Name, err)
+		}
+	}
 
 	go func() {
 		logrus.Infof("Starting Temporal worker with Build ID: %s", buildID)
@@ -153,6 +187,38 @@ func (c *TemporalClient) GetTaskQueue() string {
 	return c.identity
 }
 
+// GetTaskQueues returns every task queue this agent polls, its own first
+func (c *TemporalClient) GetTaskQueues() []string {
+
+	taskQueues := []string{c.GetTaskQueue()}
+
+	for _, taskQueue := range c.config.TaskQueues {
+		if len(taskQueue) > 0 && !slices.Contains(taskQueues, taskQueue) {
+			taskQueues = append(taskQueues, taskQueue)
+		}
+	}
+
+	return taskQueues
+}
+
+// HasTaskQueuePollers returns true if a worker is polling the task queue
+// for activities. Activities scheduled on a queue without pollers stay
+// pending until a worker starts.
+func (c *TemporalClient) HasTaskQueuePollers(ctx context.Context, taskQueue string) (bool, error) {
+
+	if c.client == nil {
+		return false, fmt.Errorf("temporal client is not initialized")
+	}
+
+	response, err := c.client.DescribeTaskQueue(ctx, taskQueue, enums.TASK_QUEUE_TYPE_ACTIVITY)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to describe task queue '%s': %w", taskQueue, err)
+	}
+
+	return len(response.GetPollers()) > 0, nil
+}
+
 func (c *TemporalClient) GetIdentity() string {
 	return c.identity
 }
@@ -208,6 +274,7 @@ func (c *TemporalClient) validateTemporalNamespace() error {
 		models.TypedSearchAttributeDuration,
 		models.TypedSearchAttributeIdentities,
 		models.TypedSearchAttributeApproved,
+		models.TypedSearchAttributeRiskFlags,
 	}
 
 	// Check if all required search attributes are defined
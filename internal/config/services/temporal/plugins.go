@@ -0,0 +1,179 @@
+package temporal
+
+import (
+	"fmt"
+	"plugin"
+	"reflect"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/workflow"
+)
+
+// PluginWorkflowSymbol is the exported func a plugin must provide
+const PluginWorkflowSymbol = "Workflow"
+
+// PluginActivitiesSymbol is an optional exported map[string]any of
+// activities the plugin's workflow calls, keyed by activity name
+const PluginActivitiesSymbol = "Activities"
+
+var (
+	workflowContextType = reflect.TypeOf((*workflow.Context)(nil)).Elem()
+	errorType           = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// RegisterWorkflow loads the Go plugin at path and registers its exported
+// Workflow func, and any exported Activities, with the worker under name.
+// Plugins must be built with -buildmode=plugin against the same version of
+// the agent and its dependencies.
+func (c *TemporalClient) RegisterWorkflow(name string, path string) error {
+
+	if len(name) == 0 {
+		return fmt.Errorf("workflow name cannot be empty")
+	}
+
+	if len(path) == 0 {
+		return fmt.Errorf("plugin path cannot be empty")
+	}
+
+	if c.worker == nil {
+		return fmt.Errorf("temporal worker is not initialized")
+	}
+
+	lib, err := plugin.Open(path)
+
+	if err != nil {
+		return fmt.Errorf("failed to open plugin %s: %w", path, err)
+	}
+
+	workflowSymbol, err := lib.Lookup(PluginWorkflowSymbol)
+
+	if err != nil {
+		return fmt.Errorf("plugin %s does not export %s: %w", path, PluginWorkflowSymbol, err)
+	}
+
+	activities := map[string]any{}
+
+	if activitiesSymbol, err := lib.Lookup(PluginActivitiesSymbol); err == nil {
+
+		// Exported variables are looked up as pointers
+		exported, ok := activitiesSymbol.(*map[string]any)
+
+		if !ok || exported == nil {
+			return fmt.Errorf("plugin %s exports %s as %T, expected map[string]any",
+				path, PluginActivitiesSymbol, activitiesSymbol)
+		}
+
+		activities = *exported
+	}
+
+	if err := c.registerPlugin(name, workflowSymbol, activities); err != nil {
+		return fmt.Errorf("plugin %s: %w", path, err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"workflow":   name,
+		"path":       path,
+		"activities": len(activities),
+	}).Info("Registered workflow plugin")
+
+	return nil
+}
+
+// registerPlugin registers the plugin's workflow and activities with the
+// worker. The functions and names are all checked before anything is
+// registered so a bad plugin is never left half registered. Any panic from
+// the worker is still returned as an error so a plugin can't crash the agent.
+func (c *TemporalClient) registerPlugin(name string, workflowFunc any, activities map[string]any) (err error) {
+
+	if err := validatePluginWorkflow(workflowFunc); err != nil {
+		return err
+	}
+
+	// The agent's own workflow is registered after plugins are loaded
+	if name == models.TemporalExecuteElevationWorkflowName {
+		return fmt.Errorf("workflow name %s is reserved by the agent", name)
+	}
+
+	if _, found := c.pluginWorkflows[name]; found {
+		return fmt.Errorf("workflow %s is already registered", name)
+	}
+
+	for activityName, activityFunc := range activities {
+
+		if err := validatePluginActivity(activityName, activityFunc); err != nil {
+			return err
+		}
+
+		if _, found := c.pluginActivities[activityName]; found {
+			return fmt.Errorf("activity %s is already registered", activityName)
+		}
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("failed to register %s: %v", name, recovered)
+		}
+	}()
+
+	c.worker.RegisterWorkflowWithOptions(workflowFunc, workflow.RegisterOptions{
+		Name: name,
+	})
+
+	c.pluginWorkflows[name] = struct{}{}
+
+	for activityName, activityFunc := range activities {
+		c.worker.RegisterActivityWithOptions(activityFunc, activity.RegisterOptions{
+			Name: activityName,
+		})
+
+		c.pluginActivities[activityName] = struct{}{}
+	}
+
+	return nil
+}
+
+// validatePluginWorkflow checks the symbol is a func taking a
+// workflow.Context first, as temporal would otherwise panic on register
+func validatePluginWorkflow(symbol any) error {
+
+	workflowType := reflect.TypeOf(symbol)
+
+	if workflowType == nil || workflowType.Kind() != reflect.Func {
+		return fmt.Errorf("%s is a %T, not a func", PluginWorkflowSymbol, symbol)
+	}
+
+	if workflowType.NumIn() == 0 || workflowType.In(0) != workflowContextType {
+		return fmt.Errorf("%s must take a workflow.Context as its first argument", PluginWorkflowSymbol)
+	}
+
+	if workflowType.NumOut() == 0 || !workflowType.Out(workflowType.NumOut()-1).Implements(errorType) {
+		return fmt.Errorf("%s must return an error as its last result", PluginWorkflowSymbol)
+	}
+
+	return nil
+}
+
+// validatePluginActivity checks the activity is a func returning an error
+// last, optionally after a single result, as temporal would otherwise panic
+// on register
+func validatePluginActivity(name string, symbol any) error {
+
+	if len(name) == 0 {
+		return fmt.Errorf("activity name cannot be empty")
+	}
+
+	activityType := reflect.TypeOf(symbol)
+
+	if activityType == nil || activityType.Kind() != reflect.Func {
+		return fmt.Errorf("activity %s is a %T, not a func", name, symbol)
+	}
+
+	if activityType.NumOut() == 0 || activityType.NumOut() > 2 ||
+		!activityType.Out(activityType.NumOut()-1).Implements(errorType) {
+		return fmt.Errorf("activity %s must return an error, optionally after a single result", name)
+	}
+
+	return nil
+}
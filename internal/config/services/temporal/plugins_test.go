@@ -0,0 +1,148 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/workflow"
+)
+
+// registryWorker panics on duplicate names like temporal's registry
+type registryWorker struct {
+	recordingWorker
+}
+
+func (w *registryWorker) RegisterWorkflowWithOptions(wf any, options workflow.RegisterOptions) {
+	if slices.Contains(w.workflows, options.Name) {
+		panic(fmt.Sprintf("workflow name \"%s\" is already registered", options.Name))
+	}
+	w.recordingWorker.RegisterWorkflowWithOptions(wf, options)
+}
+
+func (w *registryWorker) RegisterActivityWithOptions(a any, options activity.RegisterOptions) {
+	if slices.Contains(w.activities, options.Name) {
+		panic(fmt.Sprintf("activity type \"%s\" is already registered", options.Name))
+	}
+	w.recordingWorker.RegisterActivityWithOptions(a, options)
+}
+
+func TestValidatePluginWorkflow(t *testing.T) {
+
+	assert.NoError(t, validatePluginWorkflow(func(ctx workflow.Context) error { return nil }))
+	assert.NoError(t, validatePluginWorkflow(func(ctx workflow.Context, input string) (string, error) { return input, nil }))
+
+	assert.Error(t, validatePluginWorkflow(nil))
+	assert.Error(t, validatePluginWorkflow("Workflow"))
+	assert.Error(t, validatePluginWorkflow(func() error { return nil }))
+	assert.Error(t, validatePluginWorkflow(func(input string) error { return nil }))
+	assert.Error(t, validatePluginWorkflow(func(ctx workflow.Context) {}))
+	assert.Error(t, validatePluginWorkflow(func(ctx workflow.Context) string { return "" }))
+}
+
+func TestRegisterWorkflow(t *testing.T) {
+
+	client := NewTemporalClient(&models.TemporalConfig{}, "test")
+
+	assert.ErrorContains(t, client.RegisterWorkflow("", "plugin.so"), "name cannot be empty")
+	assert.ErrorContains(t, client.RegisterWorkflow("custom", ""), "path cannot be empty")
+	assert.ErrorContains(t, client.RegisterWorkflow("custom", "plugin.so"), "worker is not initialized")
+}
+
+func TestValidatePluginActivity(t *testing.T) {
+
+	assert.NoError(t, validatePluginActivity("notify", func(ctx context.Context) error { return nil }))
+	assert.NoError(t, validatePluginActivity("lookup", func(ctx context.Context, id string) (string, error) { return id, nil }))
+
+	assert.Error(t, validatePluginActivity("", func() error { return nil }))
+	assert.Error(t, validatePluginActivity("notify", "notify"))
+	assert.Error(t, validatePluginActivity("notify", func(ctx context.Context) {}))
+	assert.Error(t, validatePluginActivity("notify", func(ctx context.Context) string { return "" }))
+	assert.Error(t, validatePluginActivity("notify", func(ctx context.Context) (string, string, error) { return "", "", nil }))
+}
+
+func TestRegisterPlugin(t *testing.T) {
+
+	pluginWorkflow := func(ctx workflow.Context) error { return nil }
+	pluginActivity := func(ctx context.Context) error { return nil }
+
+	newClient := func() (*TemporalClient, *registryWorker) {
+		worker := &registryWorker{}
+		client := NewTemporalClient(&models.TemporalConfig{}, "test")
+		client.worker = worker
+		return client, worker
+	}
+
+	t.Run("registers the workflow and activities", func(t *testing.T) {
+		client, worker := newClient()
+
+		require.NoError(t, client.registerPlugin("custom", pluginWorkflow,
+			map[string]any{"notify": pluginActivity}))
+
+		assert.Equal(t, []string{"custom"}, worker.workflows)
+		assert.Equal(t, []string{"notify"}, worker.activities)
+	})
+
+	t.Run("duplicate workflow names are an error", func(t *testing.T) {
+		client, worker := newClient()
+
+		require.NoError(t, client.registerPlugin("custom", pluginWorkflow, nil))
+
+		err := client.registerPlugin("custom", pluginWorkflow, nil)
+		assert.ErrorContains(t, err, "workflow custom is already registered")
+		assert.Equal(t, []string{"custom"}, worker.workflows)
+	})
+
+	t.Run("duplicate activity names register nothing", func(t *testing.T) {
+		client, worker := newClient()
+
+		require.NoError(t, client.registerPlugin("first", pluginWorkflow,
+			map[string]any{"notify": pluginActivity}))
+
+		err := client.registerPlugin("second", pluginWorkflow,
+			map[string]any{"lookup": pluginActivity, "notify": pluginActivity})
+		assert.ErrorContains(t, err, "activity notify is already registered")
+
+		// The second plugin's workflow isn't left registered without its
+		// activities
+		assert.Equal(t, []string{"first"}, worker.workflows)
+		assert.Equal(t, []string{"notify"}, worker.activities)
+	})
+
+	t.Run("the agent's workflow name is reserved", func(t *testing.T) {
+		client, worker := newClient()
+
+		err := client.registerPlugin(models.TemporalExecuteElevationWorkflowName, pluginWorkflow, nil)
+		assert.ErrorContains(t, err, "reserved")
+		assert.Empty(t, worker.workflows)
+	})
+
+	t.Run("worker panics are returned as errors", func(t *testing.T) {
+		client, worker := newClient()
+
+		// Registered outside of plugins
+		worker.workflows = []string{"custom"}
+
+		assert.NotPanics(t, func() {
+			err := client.registerPlugin("custom", pluginWorkflow, nil)
+			assert.ErrorContains(t, err, "already registered")
+		})
+	})
+
+	t.Run("bad activity signatures register nothing", func(t *testing.T) {
+		client, worker := newClient()
+
+		err := client.registerPlugin("custom", pluginWorkflow, map[string]any{
+			"notify": func(ctx context.Context) string { return "" },
+		})
+		assert.ErrorContains(t, err, "activity notify must return an error")
+
+		assert.Empty(t, worker.workflows)
+		assert.Empty(t, worker.activities)
+	})
+}
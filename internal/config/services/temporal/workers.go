@@ -0,0 +1,116 @@
+package temporal
+
+import (
+	"github.com/nexus-rpc/sdk-go/nexus"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+// queueWorkers polls several task queues from one process. Workflows and
+// activities are registered with every worker, so provider activities
+// routed to a regional queue run with the same registrations as the
+// agent's own queue.
+type queueWorkers struct {
+	worker.Worker // the agent's own task queue
+	additional    []worker.Worker
+}
+
+func newQueueWorkers(primary worker.Worker, additional ...worker.Worker) worker.Worker {
+
+	if len(additional) == 0 {
+		return primary
+	}
+
+	return &queueWorkers{
+		Worker:     primary,
+		additional: additional,
+	}
+}
+
+func (w *queueWorkers) all() []worker.Worker {
+	return append([]worker.Worker{w.Worker}, w.additional...)
+}
+
+func (w *queueWorkers) RegisterWorkflow(wf any) {
+	for _, queueWorker := range w.all() {
+		queueWorker.RegisterWorkflow(wf)
+	}
+}
+
+func (w *queueWorkers) RegisterWorkflowWithOptions(wf any, options workflow.RegisterOptions) {
+	for _, queueWorker := range w.all() {
+		queueWorker.RegisterWorkflowWithOptions(wf, options)
+	}
+}
+
+func (w *queueWorkers) RegisterDynamicWorkflow(wf any, options workflow.DynamicRegisterOptions) {
+	for _, queueWorker := range w.all() {
+		queueWorker.RegisterDynamicWorkflow(wf, options)
+	}
+}
+
+func (w *queueWorkers) RegisterActivity(a any) {
+	for _, queueWorker := range w.all() {
+		queueWorker.RegisterActivity(a)
+	}
+}
+
+func (w *queueWorkers) RegisterActivityWithOptions(a any, options activity.RegisterOptions) {
+	for _, queueWorker := range w.all() {
+		queueWorker.RegisterActivityWithOptions(a, options)
+	}
+}
+
+func (w *queueWorkers) RegisterDynamicActivity(a any, options activity.DynamicRegisterOptions) {
+	for _, queueWorker := range w.all() {
+		queueWorker.RegisterDynamicActivity(a, options)
+	}
+}
+
+func (w *queueWorkers) RegisterNexusService(service *nexus.Service) {
+	for _, queueWorker := range w.all() {
+		queueWorker.RegisterNexusService(service)
+	}
+}
+
+// Start starts every worker, stopping those already started if one fails
+func (w *queueWorkers) Start() error {
+
+	started := []worker.Worker{}
+
+	for _, queueWorker := range w.all() {
+		if err := queueWorker.Start(); err != nil {
+			for _, startedWorker := range started {
+				startedWorker.Stop()
+			}
+			return err
+		}
+		started = append(started, queueWorker)
+	}
+
+	return nil
+}
+
+// Run starts the additional workers and blocks on the agent's own worker
+// until interrupted. Stop stops them all on shutdown.
+func (w *queueWorkers) Run(interruptCh <-chan any) error {
+
+	for i, queueWorker := range w.additional {
+		if err := queueWorker.Start(); err != nil {
+			for _, startedWorker := range w.additional[:i] {
+				startedWorker.Stop()
+			}
+			return err
+		}
+	}
+
+	return w.Worker.Run(interruptCh)
+}
+
+func (w *queueWorkers) Stop() {
+	for _, queueWorker := range w.additional {
+		queueWorker.Stop()
+	}
+	w.Worker.Stop()
+}
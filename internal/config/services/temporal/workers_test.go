@@ -0,0 +1,96 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+type recordingWorker struct {
+	worker.Worker
+	workflows  []string
+	activities []string
+	startErr   error
+	started    bool
+	stopped    bool
+}
+
+func (w *recordingWorker) RegisterWorkflowWithOptions(wf any, options workflow.RegisterOptions) {
+	w.workflows = append(w.workflows, options.Name)
+}
+
+func (w *recordingWorker) RegisterActivityWithOptions(a any, options activity.RegisterOptions) {
+	w.activities = append(w.activities, options.Name)
+}
+
+func (w *recordingWorker) Start() error {
+	w.started = w.startErr == nil
+	return w.startErr
+}
+
+func (w *recordingWorker) Run(interruptCh <-chan any) error {
+	w.started = true
+	return nil
+}
+
+func (w *recordingWorker) Stop() {
+	w.stopped = true
+}
+
+func TestQueueWorkers(t *testing.T) {
+
+	t.Run("single queue", func(t *testing.T) {
+		primary := &recordingWorker{}
+		assert.Same(t, primary, newQueueWorkers(primary))
+	})
+
+	t.Run("registrations fan out", func(t *testing.T) {
+		primary := &recordingWorker{}
+		eu := &recordingWorker{}
+		workers := newQueueWorkers(primary, eu)
+
+		workers.RegisterWorkflowWithOptions(func(ctx workflow.Context) error { return nil },
+			workflow.RegisterOptions{Name: "elevate"})
+		workers.RegisterActivityWithOptions(func(ctx context.Context) error { return nil },
+			activity.RegisterOptions{Name: "authorize"})
+
+		for _, queueWorker := range []*recordingWorker{primary, eu} {
+			assert.Equal(t, []string{"elevate"}, queueWorker.workflows)
+			assert.Equal(t, []string{"authorize"}, queueWorker.activities)
+		}
+
+		require.NoError(t, workers.Run(nil))
+		assert.True(t, primary.started)
+		assert.True(t, eu.started)
+
+		workers.Stop()
+		assert.True(t, primary.stopped)
+		assert.True(t, eu.stopped)
+	})
+
+	t.Run("failed start stops started workers", func(t *testing.T) {
+		primary := &recordingWorker{}
+		eu := &recordingWorker{}
+		us := &recordingWorker{startErr: errors.New("unavailable")}
+
+		require.Error(t, newQueueWorkers(primary, eu, us).Run(nil))
+		assert.True(t, eu.stopped)
+		assert.False(t, primary.started)
+	})
+}
+
+func TestGetTaskQueues(t *testing.T) {
+
+	client := NewTemporalClient(&models.TemporalConfig{
+		TaskQueues: []string{"eu", "", "agent", "eu", "us"},
+	}, "agent")
+
+	assert.Equal(t, []string{"agent", "eu", "us"}, client.GetTaskQueues())
+}
@@ -50,9 +50,11 @@ func (h *hashicorpProvider) Initialize() error {
 
 	// Set timeout
 	if timeout, foundTimeout := h.config.GetString("timeout"); foundTimeout {
-		if duration, err := common.ValidateDuration(timeout); err == nil {
-			config.Timeout = duration
+		duration, err := common.ParseDuration(timeout)
+		if err != nil {
+			return fmt.Errorf("invalid vault timeout: %w", err)
 		}
+		config.Timeout = duration
 	}
 
 	// Create the client
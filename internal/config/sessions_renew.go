@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/sessions"
+	"github.com/thand-io/agent/pkg/client"
+)
+
+// renewLoginSessions renews the sessions for the login server that expire
+// within login.renew_before, so they don't expire part way through a
+// command. Returns the providers whose sessions are about to expire and
+// can't be renewed, the user has to login again for those.
+func (c *Config) renewLoginSessions(ctx context.Context, loginServer *sessions.LoginServer) map[string]bool {
+
+	unrenewable := map[string]bool{}
+	threshold := c.Login.RenewBefore
+
+	if threshold <= 0 {
+		return unrenewable
+	}
+
+	sessionManager := sessions.GetSessionManager()
+	hostname := c.GetLoginServerHostname()
+	apiUrl := ""
+
+	for providerName, session := range loginServer.GetSessions() {
+
+		if !sessions.ExpiresWithin(&session, threshold) {
+			continue
+		}
+
+		// Only discover the API when a session needs renewing
+		if len(apiUrl) == 0 {
+			apiUrl = c.DiscoverLoginServerApiUrl(c.GetLoginServerUrl())
+		}
+
+		renewed, err := sessionManager.RenewSession(ctx, hostname, providerName, threshold,
+			func(ctx context.Context, provider string, current *models.LocalSession) (*models.LocalSession, error) {
+				return renewLoginSession(ctx, apiUrl, provider, current)
+			})
+
+		log := logrus.WithField("provider", providerName)
+
+		switch {
+		case err == nil:
+			log.WithField("expiry", renewed.Expiry).Debugln("Renewed session")
+		case errors.Is(err, client.ErrRenewalUnsupported):
+			log.WithError(err).Infoln("Session expires soon and can't be renewed, please login again")
+			unrenewable[providerName] = true
+		default:
+			// Keep using the session until it expires
+			log.WithError(err).Warnln("Failed to renew session")
+		}
+	}
+
+	return unrenewable
+}
+
+// renewLoginSession asks the login server to renew a session
+func renewLoginSession(ctx context.Context, apiUrl string, provider string, session *models.LocalSession) (*models.LocalSession, error) {
+
+	response, err := client.NewClient(apiUrl, session.GetEncodedLocalSession()).
+		RenewSession(ctx, provider)
+
+	if err != nil {
+		return nil, err
+	}
+
+	renewed, err := models.DecodedLocalSession(response.Session)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode renewed session: %w", err)
+	}
+
+	return renewed, nil
+}
@@ -18,6 +18,27 @@ type ConfigPatchRequest struct {
 	ProviderConfig *ProviderConfig `json:"providers,omitempty"`
 }
 
+// HandleSyncFailure applies the sync conflict policy to a failed sync with
+// the thand server. Only the fail policy returns the error, otherwise the
+// agent continues with its local configuration.
+func (c *Config) HandleSyncFailure(err error) error {
+
+	if err == nil {
+		return nil
+	}
+
+	switch c.Sync.GetConflictPolicy() {
+	case models.SyncConflictPolicyFail:
+		return fmt.Errorf("failed to sync with thand server: %w", err)
+	case models.SyncConflictPolicyUseLocal:
+		logrus.WithError(err).Warnln("Failed to sync with Thand server, using local configuration")
+	default:
+		logrus.WithError(err).Errorln("Failed to register with Thand server")
+	}
+
+	return nil
+}
+
 func (c *Config) MergeConfiguration(config *RegistrationResponse) error {
 
 	incoming := ConfigPatchRequest{
@@ -35,7 +56,8 @@ func (c *Config) MergeConfiguration(config *RegistrationResponse) error {
 
 	roles := c.GetRoles()
 	workflows := c.GetWorkflows()
-	providers := c.GetProviders()
+	// Secrets never leave the agent, the server only sees placeholders
+	providers := c.GetProviders().GetRedacted()
 
 	existing := ConfigPatchRequest{
 		RoleConfig:     &roles,
@@ -184,8 +206,22 @@ func (c *Config) updateWorkflows(workflowConfig *WorkflowConfig) error {
 }
 
 func (c *Config) updateProviders(providerConfig *ProviderConfig) error {
+
+	definitions := make(map[string]models.Provider, len(providerConfig.Definitions))
+
+	// A placeholder sent back by the server is never taken as the secret,
+	// the local value is kept instead
+	for name, provider := range providerConfig.Definitions {
+		if existing, err := c.GetProviderByName(name); err == nil {
+			provider.Config = provider.Config.RestoreRedacted(existing.Config)
+		} else {
+			provider.Config = provider.Config.RestoreRedacted(nil)
+		}
+		definitions[name] = provider
+	}
+
 	_, err := c.ApplyProviders([]*models.ProviderDefinitions{{
-		Providers: providerConfig.Definitions,
+		Providers: definitions,
 	}})
 	return err
 }
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// CheckRequestTemplate returns what in a saved request template is no
+// longer valid against the synced config e.g. a role that was removed or a
+// duration now longer than the role allows. Nothing is returned if the
+// template can still be used.
+func (c *Config) CheckRequestTemplate(template *models.RequestTemplate) []string {
+
+	problems := []string{}
+
+	role, err := c.GetRoleByName(template.Role)
+
+	if err != nil {
+		return append(problems, fmt.Sprintf("role %s no longer exists", template.Role))
+	}
+
+	if !role.Enabled {
+		problems = append(problems, fmt.Sprintf("role %s is disabled", template.Role))
+	}
+
+	if len(template.Providers) == 0 {
+		problems = append(problems, "no providers are set")
+	}
+
+	for _, provider := range template.Providers {
+		if _, found := c.Providers.Definitions[provider]; !found {
+			problems = append(problems, fmt.Sprintf("provider %s no longer exists", provider))
+		} else if !slices.Contains(role.Providers, provider) {
+			problems = append(problems, fmt.Sprintf("role %s no longer includes provider %s", template.Role, provider))
+		}
+	}
+
+	if len(template.Duration) > 0 {
+		if _, err := common.ValidateDuration(template.Duration); err != nil {
+			problems = append(problems, fmt.Sprintf("duration %s is invalid: %s", template.Duration, err))
+		} else if err := c.CheckDuration(nil, role, template.Duration); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	for _, resource := range template.Resources {
+		if !isResourcePatternAllowed(role.Resources.Allow, resource) {
+			problems = append(problems, fmt.Sprintf("role %s no longer allows resource %s", template.Role, resource))
+		}
+	}
+
+	if len(template.Workflow) > 0 && !slices.Contains(role.Workflows, template.Workflow) {
+		problems = append(problems, fmt.Sprintf("role %s no longer uses workflow %s", template.Role, template.Workflow))
+	}
+
+	return problems
+}
+
+// isResourcePatternAllowed checks a resource against the role's allow
+// patterns
+func isResourcePatternAllowed(allow []string, resource string) bool {
+	for _, pattern := range allow {
+		if pattern == "*" || pattern == resource {
+			return true
+		}
+		if matched, _ := path.Match(pattern, resource); matched {
+			return true
+		}
+	}
+	return false
+}
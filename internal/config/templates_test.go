@@ -0,0 +1,69 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestCheckRequestTemplate(t *testing.T) {
+	cfg := &Config{
+		Roles: RoleConfig{
+			Definitions: map[string]models.Role{
+				"incident-responder": {
+					Name:        "incident-responder",
+					Providers:   []string{"aws-prod"},
+					Workflows:   []string{"incident"},
+					Resources:   models.Resources{Allow: []string{"arn:aws:ec2:*"}},
+					MaxDuration: "4h",
+					Enabled:     true,
+				},
+			},
+		},
+		Providers: ProviderConfig{
+			Definitions: map[string]models.Provider{
+				"aws-prod": {Name: "aws-prod", Provider: "aws", Enabled: true},
+				"aws-dev":  {Name: "aws-dev", Provider: "aws", Enabled: true},
+			},
+		},
+	}
+
+	valid := models.RequestTemplate{
+		Name:      "prod-ir",
+		Role:      "incident-responder",
+		Providers: []string{"aws-prod"},
+		Duration:  "2h",
+		Resources: []string{"arn:aws:ec2:instance"},
+		Workflow:  "incident",
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		assert.Empty(t, cfg.CheckRequestTemplate(&valid))
+	})
+
+	t.Run("role removed", func(t *testing.T) {
+		template := valid
+		template.Role = "break-glass"
+
+		assert.Equal(t, []string{"role break-glass no longer exists"}, cfg.CheckRequestTemplate(&template))
+	})
+
+	t.Run("everything changed", func(t *testing.T) {
+		template := valid
+		template.Providers = []string{"aws-dev", "gcp-prod"}
+		template.Duration = "8h"
+		template.Resources = []string{"arn:aws:s3:bucket"}
+		template.Workflow = "legacy"
+
+		problems := cfg.CheckRequestTemplate(&template)
+
+		assert.Equal(t, []string{
+			"role incident-responder no longer includes provider aws-dev",
+			"provider gcp-prod no longer exists",
+			"duration must not be longer than 4h for role 'incident-responder'",
+			"role incident-responder no longer allows resource arn:aws:s3:bucket",
+			"role incident-responder no longer uses workflow legacy",
+		}, problems)
+	})
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/sirupsen/logrus"
@@ -88,3 +89,77 @@ func (c *Config) registerTemporalActivities() error {
 	return nil
 
 }
+
+// GetProviderTaskQueue returns the task queue the provider's grants and
+// revocations are scheduled on. Providers without a task queue use the
+// agent's own.
+func (c *Config) GetProviderTaskQueue(providerName string) string {
+
+	defaultTaskQueue := ""
+
+	if services := c.GetServices(); services != nil && services.HasTemporal() {
+		defaultTaskQueue = services.GetTemporal().GetTaskQueue()
+	}
+
+	provider, err := c.GetProviderByName(providerName)
+
+	if err != nil {
+		return defaultTaskQueue
+	}
+
+	return provider.GetTaskQueue(defaultTaskQueue)
+}
+
+// ValidateTaskQueues checks a worker is polling the task queue of each
+// provider routed to another agent. Without one the grant would stay
+// pending until an agent for that queue starts.
+func (c *Config) ValidateTaskQueues(ctx context.Context, providerNames []string) error {
+
+	services := c.GetServices()
+
+	if services == nil || !services.HasTemporal() {
+		return nil
+	}
+
+	providers := []*models.Provider{}
+
+	for _, providerName := range providerNames {
+		// Unknown providers are reported when the workflow runs
+		if provider, err := c.GetProviderByName(providerName); err == nil {
+			providers = append(providers, provider)
+		}
+	}
+
+	return validateTaskQueues(ctx, services.GetTemporal(), providers)
+}
+
+func validateTaskQueues(ctx context.Context, temporalService models.TemporalImpl, providers []*models.Provider) error {
+
+	defaultTaskQueue := temporalService.GetTaskQueue()
+	checked := map[string]bool{}
+
+	for _, provider := range providers {
+
+		taskQueue := provider.GetTaskQueue(defaultTaskQueue)
+
+		if taskQueue == defaultTaskQueue || checked[taskQueue] {
+			continue
+		}
+
+		hasPollers, err := temporalService.HasTaskQueuePollers(ctx, taskQueue)
+
+		if err != nil {
+			return fmt.Errorf("failed to check task queue '%s' for provider '%s': %w",
+				taskQueue, provider.Name, err)
+		}
+
+		if !hasPollers {
+			return fmt.Errorf("no agent is polling task queue '%s' for provider '%s'",
+				taskQueue, provider.Name)
+		}
+
+		checked[taskQueue] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+type taskQueueTemporal struct {
+	models.TemporalImpl
+	pollers map[string]bool
+	err     error
+	checked []string
+}
+
+func (t *taskQueueTemporal) GetTaskQueue() string {
+	return "agent"
+}
+
+func (t *taskQueueTemporal) HasTaskQueuePollers(ctx context.Context, taskQueue string) (bool, error) {
+	t.checked = append(t.checked, taskQueue)
+	return t.pollers[taskQueue], t.err
+}
+
+func TestValidateTaskQueues(t *testing.T) {
+
+	ctx := context.Background()
+
+	central := &models.Provider{Name: "okta"}
+	eu := &models.Provider{Name: "aws-eu", TaskQueue: "eu"}
+	euGCP := &models.Provider{Name: "gcp-eu", TaskQueue: "eu"}
+	us := &models.Provider{Name: "aws-us", TaskQueue: "us"}
+
+	t.Run("providers on the agent's queue aren't checked", func(t *testing.T) {
+		temporal := &taskQueueTemporal{}
+		require.NoError(t, validateTaskQueues(ctx, temporal, []*models.Provider{central}))
+		assert.Empty(t, temporal.checked)
+	})
+
+	t.Run("routed providers need a worker", func(t *testing.T) {
+		temporal := &taskQueueTemporal{pollers: map[string]bool{"eu": true}}
+		require.NoError(t, validateTaskQueues(ctx, temporal, []*models.Provider{central, eu, euGCP}))
+		assert.Equal(t, []string{"eu"}, temporal.checked)
+
+		err := validateTaskQueues(ctx, temporal, []*models.Provider{eu, us})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no agent is polling task queue 'us' for provider 'aws-us'")
+	})
+
+	t.Run("describe failures", func(t *testing.T) {
+		temporal := &taskQueueTemporal{err: errors.New("unavailable")}
+		assert.ErrorContains(t, validateTaskQueues(ctx, temporal, []*models.Provider{eu}), "unavailable")
+	})
+
+	t.Run("provider task queue", func(t *testing.T) {
+		assert.Equal(t, "eu", eu.GetTaskQueue("agent"))
+		assert.Equal(t, "agent", central.GetTaskQueue("agent"))
+	})
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/config/environment"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// ApplyWorkflowTemplates expands the workflow template of every role once
+// the roles and workflows are loaded. The expanded workflows replace those
+// from an earlier load so changes to a role's template are picked up.
+func (c *Config) ApplyWorkflowTemplates() error {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for roleKey, role := range c.Roles.Definitions {
+
+		if role.WorkflowTemplate == nil {
+			continue
+		}
+
+		if err := c.expandWorkflowTemplate(&role, true); err != nil {
+			return fmt.Errorf("role '%s': %w", roleKey, err)
+		}
+
+		c.Roles.Definitions[roleKey] = role
+	}
+
+	return nil
+}
+
+// applyWorkflowTemplate expands the workflow template of a composite role.
+// Templates expanded when the roles were loaded are kept as they are.
+func (c *Config) applyWorkflowTemplate(role *models.Role) error {
+
+	if role.WorkflowTemplate == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.expandWorkflowTemplate(role, false); err != nil {
+		return err
+	}
+
+	// Ticket references are checked by the reason policy
+	if role.WorkflowTemplate.Name == models.WorkflowTemplateJITWithTicket {
+		role.ReasonPolicy = role.ReasonPolicy.Merge(&models.ReasonPolicy{
+			MustMatch: []string{role.WorkflowTemplate.GetTicketPattern()},
+		})
+	}
+
+	return nil
+}
+
+// expandWorkflowTemplate registers the workflow expanded from the role's
+// template and makes it the role's default workflow. The caller must hold
+// the config lock.
+func (c *Config) expandWorkflowTemplate(role *models.Role, replace bool) error {
+
+	workflowTemplate := role.WorkflowTemplate
+
+	if err := workflowTemplate.Validate(); err != nil {
+		return err
+	}
+
+	workflowName := workflowTemplate.GetWorkflowName(role.Name)
+
+	if _, exists := c.Workflows.Definitions[workflowName]; !exists || replace {
+
+		workflow, err := environment.GetWorkflowTemplate(workflowTemplate.Name, environment.WorkflowTemplateValues{
+			Workflow:    workflowName,
+			Role:        role.Name,
+			Approvals:   workflowTemplate.GetApprovals(),
+			SelfApprove: workflowTemplate.SelfApprove,
+			Duration:    workflowTemplate.Duration,
+			Notifiers:   workflowTemplate.Notifiers,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if c.Workflows.Definitions == nil {
+			c.Workflows.Definitions = make(map[string]models.Workflow)
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"role":     role.Name,
+			"template": workflowTemplate.Name,
+			"workflow": workflowName,
+		}).Debugln("Expanded workflow template")
+
+		c.Workflows.Definitions[workflowName] = *workflow
+	}
+
+	if !slices.Contains(role.Workflows, workflowName) {
+		role.Workflows = append([]string{workflowName}, role.Workflows...)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+
+	// Registers the thand task type used by the templates
+	_ "github.com/thand-io/agent/internal/workflows/tasks/model"
+)
+
+const workflowTemplateRoles = `
+version: "1.0"
+roles:
+  prod-admin:
+    providers: [aws]
+    enabled: true
+    max_duration: 4h
+    workflow_template:
+      name: jit_with_approval
+      approvals: 2
+      duration: 1h
+      notifiers:
+        slack:
+          provider: slack
+          to: ["#prod-access"]
+  sandbox:
+    providers: [aws]
+    enabled: true
+    workflow_template: jit_no_approval
+  oncall:
+    providers: [aws]
+    enabled: true
+    workflows: [aws_simple_elevation]
+    workflow_template: jit_with_ticket
+  broken:
+    providers: [aws]
+    enabled: true
+    workflow_template: jit_with_approval
+`
+
+func newWorkflowTemplateConfig(t *testing.T) *Config {
+	t.Helper()
+
+	definitions, err := common.ReadDataToInterface([]byte(workflowTemplateRoles), models.RoleDefinitions{})
+	require.NoError(t, err)
+
+	config := &Config{}
+
+	roles, err := config.ApplyRoles([]*models.RoleDefinitions{definitions})
+	require.NoError(t, err)
+
+	config.Roles.Definitions = roles
+
+	require.NoError(t, config.ApplyWorkflowTemplates())
+
+	return config
+}
+
+func getWorkflowTaskNames(workflow *models.Workflow) []string {
+	names := []string{}
+	for _, task := range *workflow.Workflow.Do {
+		names = append(names, task.Key)
+	}
+	return names
+}
+
+func getWorkflowTaskWith(t *testing.T, workflow *models.Workflow, index int) map[string]any {
+	t.Helper()
+
+	var task struct {
+		With map[string]any `json:"with"`
+	}
+	require.NoError(t, common.ConvertInterfaceToInterface((*workflow.Workflow.Do)[index].Task, &task))
+
+	return task.With
+}
+
+func TestWorkflowTemplates(t *testing.T) {
+
+	config := newWorkflowTemplateConfig(t)
+
+	t.Run("roles without notifiers for approvals are skipped", func(t *testing.T) {
+		assert.NotContains(t, config.Roles.Definitions, "broken")
+	})
+
+	t.Run("templates expand into the role's first workflow", func(t *testing.T) {
+
+		role, err := config.GetRoleByName("prod-admin")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"prod-admin_jit_with_approval"}, role.Workflows)
+
+		workflow, err := config.GetWorkflowFromElevationRequest(&models.ElevateRequest{
+			Role:      role,
+			Providers: []string{"aws"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "prod-admin_jit_with_approval", workflow.Name)
+		assert.True(t, workflow.Enabled)
+		assert.Equal(t, []string{"validate", "approvals", "authorize", "auto_revoke", "denied"}, getWorkflowTaskNames(workflow))
+
+		approvals := getWorkflowTaskWith(t, workflow, 1)
+		assert.EqualValues(t, 2, approvals["approvals"])
+		assert.Contains(t, approvals["notifiers"], "slack")
+
+		autoRevoke := getWorkflowTaskWith(t, workflow, 3)
+		assert.Equal(t, "1h", autoRevoke["duration"])
+	})
+
+	t.Run("templates without approval grant straight away", func(t *testing.T) {
+
+		workflow, err := config.GetWorkflowByName("sandbox_jit_no_approval")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"validate", "authorize", "auto_revoke"}, getWorkflowTaskNames(workflow))
+	})
+
+	t.Run("template workflows come before the role's workflows", func(t *testing.T) {
+
+		role, err := config.GetRoleByName("oncall")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"oncall_jit_with_ticket", "aws_simple_elevation"}, role.Workflows)
+	})
+
+	t.Run("ticket templates require a ticket in the reason", func(t *testing.T) {
+
+		role, err := config.GetRoleByName("oncall")
+		require.NoError(t, err)
+
+		user := &models.User{Username: "alice"}
+		assert.Error(t, config.CheckReason(user, role, "fixing the outage"))
+		assert.NoError(t, config.CheckReason(user, role, "fixing the outage in OPS-1234"))
+	})
+
+	t.Run("composite roles expand their template", func(t *testing.T) {
+
+		config := &Config{}
+
+		compositeRole, err := config.GetCompositeRole(nil, &models.Role{
+			Name:             "Break Glass",
+			WorkflowTemplate: &models.WorkflowTemplate{Name: models.WorkflowTemplateJITNoApproval},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"break_glass_jit_no_approval"}, compositeRole.Workflows)
+		assert.Contains(t, config.Workflows.Definitions, "break_glass_jit_no_approval")
+	})
+
+	t.Run("unknown templates are rejected", func(t *testing.T) {
+
+		err := (&models.WorkflowTemplate{Name: "jit_with_magic"}).Validate()
+		assert.ErrorContains(t, err, "unknown workflow template 'jit_with_magic'")
+
+		_, err = (&Config{}).GetCompositeRole(nil, &models.Role{
+			Name:             "magic",
+			WorkflowTemplate: &models.WorkflowTemplate{Name: "jit_with_magic"},
+		})
+		assert.Error(t, err)
+	})
+}
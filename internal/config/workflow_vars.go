@@ -0,0 +1,157 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// workflowVarReference matches references to workflow vars in expressions
+// e.g. $context.vars.jira_project or $context.vars["jira-project"]. The
+// definitions are scanned as JSON so quotes may be escaped.
+var workflowVarReference = regexp.MustCompile(
+	`\$context\s*\.\s*vars\s*(?:\.\s*([A-Za-z_][A-Za-z0-9_]*)|\[\s*\\?"([^"\\]+)\\?"\s*\])`)
+
+// GetWorkflowVars merges the global vars with the vars of the providers
+// and role of an elevation. Providers override the global vars in the
+// order they were requested and the role overrides them all. Secret
+// references are returned as they are, the activity using them resolves
+// them so they aren't recorded in the workflow history.
+func (c *Config) GetWorkflowVars(role *models.Role, providerNames []string) map[string]any {
+
+	vars := map[string]any{}
+	sources := map[string]string{}
+
+	merge := func(source string, values map[string]any) {
+		// Sorted so collisions are logged in the same order every time
+		for _, key := range slices.Sorted(maps.Keys(values)) {
+			if previous, exists := sources[key]; exists {
+				logrus.WithFields(logrus.Fields{
+					"var":        key,
+					"overridden": previous,
+					"by":         source,
+				}).Debugln("Workflow var overridden")
+			}
+			vars[key] = values[key]
+			sources[key] = source
+		}
+	}
+
+	merge("config", c.Vars)
+
+	for _, providerName := range providerNames {
+		if provider, err := c.GetProviderByName(providerName); err == nil {
+			merge("provider:"+providerName, provider.Vars)
+		}
+	}
+
+	if role != nil {
+		merge("role:"+role.Name, role.Vars)
+	}
+
+	return vars
+}
+
+// getDefinedWorkflowVars returns the name of every var set in the config,
+// by a provider or by a role
+func (c *Config) getDefinedWorkflowVars() map[string]bool {
+
+	defined := map[string]bool{}
+
+	for key := range c.Vars {
+		defined[key] = true
+	}
+
+	for _, provider := range c.Providers.Definitions {
+		for key := range provider.Vars {
+			defined[key] = true
+		}
+	}
+
+	for _, role := range c.Roles.Definitions {
+		for key := range role.Vars {
+			defined[key] = true
+		}
+	}
+
+	return defined
+}
+
+// getWorkflowVarReferences returns the vars referenced by expressions in
+// the workflow definition
+func getWorkflowVarReferences(workflow *models.Workflow) ([]string, error) {
+
+	if workflow.Workflow == nil {
+		return nil, nil
+	}
+
+	definition, err := json.Marshal(workflow.Workflow)
+
+	if err != nil {
+		return nil, err
+	}
+
+	references := []string{}
+
+	for _, match := range workflowVarReference.FindAllStringSubmatch(string(definition), -1) {
+		name := match[1]
+		if len(name) == 0 {
+			name = match[2]
+		}
+		if !slices.Contains(references, name) {
+			references = append(references, name)
+		}
+	}
+
+	return references, nil
+}
+
+// ValidateWorkflowVars checks that the vars referenced by each workflow are
+// set in the config, by a provider or by a role. Only references written
+// out in full e.g. $context.vars.jira_project can be checked.
+func (c *Config) ValidateWorkflowVars() error {
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	defined := c.getDefinedWorkflowVars()
+
+	var errs []string
+
+	for _, workflowKey := range slices.Sorted(maps.Keys(c.Workflows.Definitions)) {
+
+		workflow := c.Workflows.Definitions[workflowKey]
+
+		references, err := getWorkflowVarReferences(&workflow)
+
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("workflow '%s': %v", workflowKey, err))
+			continue
+		}
+
+		var missing []string
+
+		for _, name := range references {
+			if !defined[name] {
+				missing = append(missing, name)
+			}
+		}
+
+		if len(missing) > 0 {
+			errs = append(errs, fmt.Sprintf("workflow '%s' references undefined vars: %s",
+				workflowKey, strings.Join(missing, ", ")))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("validating workflow vars: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+
+	// Registers the thand task type used by the workflow
+	_ "github.com/thand-io/agent/internal/workflows/tasks/model"
+)
+
+const workflowVarsWorkflow = `
+name: notify_team
+enabled: true
+workflow:
+  document:
+    dsl: "1.0.0-alpha5"
+    namespace: "thand"
+    name: notify_team
+    version: "1.0.0"
+  do:
+    - notify:
+        thand: notify
+        with:
+          provider: slack
+          to: ${ $context.vars.approvals_channel }
+          message: ${ "Ticket in " + $context.vars["jira-project"] + " for " + $context.vars.team }
+        then: end
+`
+
+func newWorkflowVarsConfig(t *testing.T) *Config {
+	t.Helper()
+
+	workflow, err := common.ReadDataToInterface([]byte(workflowVarsWorkflow), models.Workflow{})
+	require.NoError(t, err)
+
+	return &Config{
+		Vars: map[string]any{
+			"approvals_channel": "#access",
+			"jira-project":      "OPS",
+			"team":              "platform",
+		},
+		Providers: ProviderConfig{
+			Definitions: map[string]models.Provider{
+				"aws-prod": {
+					Name: "aws-prod",
+					Vars: map[string]any{"approvals_channel": "#aws-access", "account": "prod"},
+				},
+				"aws-dev": {
+					Name: "aws-dev",
+					Vars: map[string]any{"account": "dev"},
+				},
+			},
+		},
+		Roles: RoleConfig{
+			Definitions: map[string]models.Role{
+				"oncall": {
+					Name: "oncall",
+					Vars: map[string]any{"team": "sre"},
+				},
+			},
+		},
+		Workflows: WorkflowConfig{
+			Definitions: map[string]models.Workflow{
+				"notify_team": *workflow,
+			},
+		},
+	}
+}
+
+func TestGetWorkflowVars(t *testing.T) {
+
+	config := newWorkflowVarsConfig(t)
+
+	t.Run("role overrides provider overrides global", func(t *testing.T) {
+		role := config.Roles.Definitions["oncall"]
+
+		vars := config.GetWorkflowVars(&role, []string{"aws-prod"})
+
+		assert.Equal(t, map[string]any{
+			"approvals_channel": "#aws-access",
+			"jira-project":      "OPS",
+			"team":              "sre",
+			"account":           "prod",
+		}, vars)
+	})
+
+	t.Run("later providers override earlier ones", func(t *testing.T) {
+		assert.Equal(t, "dev", config.GetWorkflowVars(nil, []string{"aws-prod", "aws-dev"})["account"])
+		assert.Equal(t, "prod", config.GetWorkflowVars(nil, []string{"aws-dev", "aws-prod"})["account"])
+	})
+
+	t.Run("global vars without a role or providers", func(t *testing.T) {
+		assert.Equal(t, config.Vars, config.GetWorkflowVars(nil, nil))
+	})
+}
+
+func TestValidateWorkflowVars(t *testing.T) {
+
+	t.Run("referenced vars are defined", func(t *testing.T) {
+		assert.NoError(t, newWorkflowVarsConfig(t).ValidateWorkflowVars())
+	})
+
+	t.Run("vars set by a provider or role count as defined", func(t *testing.T) {
+		config := newWorkflowVarsConfig(t)
+		delete(config.Vars, "approvals_channel")
+		delete(config.Vars, "team")
+
+		assert.NoError(t, config.ValidateWorkflowVars())
+	})
+
+	t.Run("undefined vars are reported", func(t *testing.T) {
+		config := newWorkflowVarsConfig(t)
+		delete(config.Vars, "jira-project")
+		delete(config.Roles.Definitions, "oncall")
+		delete(config.Vars, "team")
+
+		err := config.ValidateWorkflowVars()
+		assert.ErrorContains(t, err, "workflow 'notify_team' references undefined vars: jira-project, team")
+	})
+}
+
+type testVault struct {
+	secrets map[string]string
+}
+
+func (v *testVault) Initialize() error { return nil }
+func (v *testVault) Shutdown() error   { return nil }
+
+func (v *testVault) GetSecret(key string) ([]byte, error) {
+	if secret, found := v.secrets[key]; found {
+		return []byte(secret), nil
+	}
+	return nil, errors.New("secret not found")
+}
+
+func (v *testVault) StoreSecret(key string, value []byte) error { return nil }
+
+func TestVaultSecretResolver(t *testing.T) {
+
+	resolver := newVaultSecretResolver(&testVault{secrets: map[string]string{
+		"thand/slack": `{"bot_token":"xoxb-123"}`,
+		"thand/jira":  "jira-token",
+	}})
+
+	value, err := resolver(nil, "vault://thand/slack#bot_token")
+	require.NoError(t, err)
+	assert.Equal(t, "xoxb-123", value)
+
+	value, err = resolver(nil, "vault://thand/jira")
+	require.NoError(t, err)
+	assert.Equal(t, "jira-token", value)
+
+	_, err = resolver(nil, "vault://thand/slack#missing")
+	assert.ErrorContains(t, err, "does not contain field missing")
+
+	_, err = resolver(nil, "vault://thand/unknown")
+	assert.Error(t, err)
+}
@@ -94,6 +94,11 @@ func (c *Config) ApplyWorkflows(foundWorkflows []*models.WorkflowDefinitions) (m
 				continue
 			}
 
+			if err := p.DuringFreeze.Validate(); err != nil {
+				logrus.WithError(err).Warnln("Workflow is invalid, skipping:", workflowKey)
+				continue
+			}
+
 			defs[workflowKey] = p
 		}
 	}
@@ -0,0 +1,152 @@
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// parseAllowedCIDRs parses CIDR blocks and single IPv4 or IPv6 addresses
+func parseAllowedCIDRs(values []string) ([]netip.Prefix, error) {
+
+	prefixes := make([]netip.Prefix, 0, len(values))
+
+	for _, value := range values {
+
+		value = strings.TrimSpace(value)
+
+		if strings.Contains(value, "/") {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %w", value, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP %q: %w", value, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+
+	return prefixes, nil
+}
+
+// isAllowedIP returns true if the IP is within one of the prefixes
+func isAllowedIP(prefixes []netip.Prefix, ip string) bool {
+
+	addr, err := netip.ParseAddr(ip)
+
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowedCIDRsMiddleware rejects clients outside of the allowed CIDR blocks.
+// The client IP is only read from X-Forwarded-For when trusted proxies are
+// configured, otherwise the address of the connection is used so the header
+// can't be spoofed. onRejected, if set, is called for each rejected client.
+func AllowedCIDRsMiddleware(prefixes []netip.Prefix, trustProxies bool, onRejected func(c *gin.Context, clientIP string)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		clientIP := c.RemoteIP()
+
+		if trustProxies {
+			clientIP = c.ClientIP()
+		}
+
+		if !isAllowedIP(prefixes, clientIP) {
+			logrus.WithFields(logrus.Fields{
+				"ip":     clientIP,
+				"method": c.Request.Method,
+				"path":   c.Request.URL.Path,
+			}).Warnln("Rejected request from IP outside of allowed CIDRs")
+
+			if onRejected != nil {
+				onRejected(c, clientIP)
+			}
+
+			abortForbidden(c)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// setTrustedProxies only trusts forwarded client IPs from the configured
+// proxies, otherwise rate limits, allowlists and audit logs use the address
+// of the proxy
+func (s *Server) setTrustedProxies(router *gin.Engine) error {
+	if err := router.SetTrustedProxies(s.Config.Server.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid server.trusted_proxies: %w", err)
+	}
+	return nil
+}
+
+// RequireAdminIP rejects clients outside of the admin allowlist from the
+// admin endpoints. Nothing is restricted when the allowlist is empty.
+func (s *Server) RequireAdminIP() gin.HandlerFunc {
+
+	if len(s.adminCIDRs) == 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return AllowedCIDRsMiddleware(
+		s.adminCIDRs, len(s.Config.Server.TrustedProxies) > 0,
+		s.recordIPBlocked("admin_allowlist"))
+}
+
+// ClientCertMiddleware requires a verified client certificate for the
+// route group when mTLS is limited to some routes
+func (s *Server) ClientCertMiddleware(group string) gin.HandlerFunc {
+
+	tlsConfig := &s.Config.Server.TLS
+
+	return func(c *gin.Context) {
+
+		if !tlsConfig.RequiresClientCert(group) {
+			c.Next()
+			return
+		}
+
+		// The TLS config verifies certificates against the client CA when
+		// they're presented, so a verified chain means a trusted client
+		if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+			logrus.WithFields(logrus.Fields{
+				"ip":     c.ClientIP(),
+				"method": c.Request.Method,
+				"path":   c.Request.URL.Path,
+				"group":  group,
+			}).Warnln("Rejected request without a client certificate")
+
+			abortForbidden(c)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// abortForbidden rejects the request without revealing whether the route exists
+func abortForbidden(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": http.StatusText(http.StatusForbidden)})
+}
@@ -0,0 +1,492 @@
+package daemon
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/siem"
+)
+
+func TestParseAllowedCIDRs(t *testing.T) {
+
+	prefixes, err := parseAllowedCIDRs([]string{
+		"10.0.0.0/8",
+		"192.168.1.10",
+		"2001:db8::/32",
+		"::1",
+		" 172.16.0.0/12 ",
+	})
+	require.NoError(t, err)
+	require.Len(t, prefixes, 5)
+
+	tests := []struct {
+		ip      string
+		allowed bool
+	}{
+		{"10.1.2.3", true},
+		{"192.168.1.10", true},
+		{"192.168.1.11", false},
+		{"172.20.0.1", true},
+		{"2001:db8::1", true},
+		{"2001:db9::1", false},
+		{"::1", true},
+		{"::ffff:10.1.2.3", true}, // IPv4 mapped IPv6
+		{"8.8.8.8", false},
+		{"not-an-ip", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, isAllowedIP(prefixes, tt.ip))
+		})
+	}
+
+	t.Run("rejects invalid entries", func(t *testing.T) {
+		_, err := parseAllowedCIDRs([]string{"10.0.0.0/33"})
+		assert.Error(t, err)
+
+		_, err = parseAllowedCIDRs([]string{"corp.example.com"})
+		assert.Error(t, err)
+	})
+}
+
+func TestAllowedCIDRsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	prefixes, err := parseAllowedCIDRs([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	newRouter := func(trustedProxies []string) *gin.Engine {
+		router := gin.New()
+		require.NoError(t, router.SetTrustedProxies(trustedProxies))
+		router.Use(AllowedCIDRsMiddleware(prefixes, len(trustedProxies) > 0, nil))
+		router.GET("/api/v1/roles", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return router
+	}
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		expectedStatus int
+	}{
+		{
+			name:           "allows clients in the CIDR",
+			remoteAddr:     "10.1.2.3:40000",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "rejects clients outside the CIDR",
+			remoteAddr:     "203.0.113.5:40000",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "ignores spoofed forwarded header without trusted proxies",
+			remoteAddr:     "203.0.113.5:40000",
+			forwardedFor:   "10.1.2.3",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "ignores forwarded header from untrusted proxies",
+			trustedProxies: []string{"192.0.2.0/24"},
+			remoteAddr:     "203.0.113.5:40000",
+			forwardedFor:   "10.1.2.3",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "uses forwarded header from trusted proxies",
+			trustedProxies: []string{"192.0.2.0/24"},
+			remoteAddr:     "192.0.2.10:40000",
+			forwardedFor:   "10.1.2.3",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "rejects forwarded clients outside the CIDR",
+			trustedProxies: []string{"192.0.2.0/24"},
+			remoteAddr:     "192.0.2.10:40000",
+			forwardedFor:   "203.0.113.5",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/roles", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if len(tt.forwardedFor) > 0 {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+
+			w := httptest.NewRecorder()
+			newRouter(tt.trustedProxies).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	t.Run("rejections do not reveal the route", func(t *testing.T) {
+		router := newRouter(nil)
+
+		for _, path := range []string{"/api/v1/roles", "/api/v1/missing"} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.RemoteAddr = "203.0.113.5:40000"
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.JSONEq(t, `{"error":"Forbidden"}`, w.Body.String())
+		}
+	})
+}
+
+func TestRequireAdminIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(allowlist []string) *gin.Engine {
+		adminCIDRs, err := parseAllowedCIDRs(allowlist)
+		require.NoError(t, err)
+
+		s := &Server{Config: &config.Config{}, adminCIDRs: adminCIDRs}
+
+		router := gin.New()
+		require.NoError(t, router.SetTrustedProxies(nil))
+		router.GET("/api/v1/roles", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		admin := router.Group("/api/v1", s.RequireAdminIP())
+		admin.POST("/execution/:id/revoke", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return router
+	}
+
+	request := func(router *gin.Engine, method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = "203.0.113.5:40000"
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("empty allowlist allows any client", func(t *testing.T) {
+		router := newRouter(nil)
+		assert.Equal(t, http.StatusOK, request(router, http.MethodPost, "/api/v1/execution/wf-1/revoke"))
+	})
+
+	t.Run("allowlisted clients", func(t *testing.T) {
+		router := newRouter([]string{"203.0.113.0/24"})
+		assert.Equal(t, http.StatusOK, request(router, http.MethodPost, "/api/v1/execution/wf-1/revoke"))
+	})
+
+	t.Run("other clients are forbidden from admin routes only", func(t *testing.T) {
+		router := newRouter([]string{"10.0.0.0/8"})
+		assert.Equal(t, http.StatusForbidden, request(router, http.MethodPost, "/api/v1/execution/wf-1/revoke"))
+		assert.Equal(t, http.StatusOK, request(router, http.MethodGet, "/api/v1/roles"))
+	})
+
+	t.Run("blocked clients are written to the audit log", func(t *testing.T) {
+		adminCIDRs, err := parseAllowedCIDRs([]string{"10.0.0.0/8"})
+		require.NoError(t, err)
+
+		var auditLog bytes.Buffer
+		s := &Server{
+			Config:      &config.Config{},
+			adminCIDRs:  adminCIDRs,
+			auditLogger: siem.NewJSONAuditLogger(&auditLog),
+		}
+
+		router := gin.New()
+		require.NoError(t, router.SetTrustedProxies(nil))
+		router.POST("/api/v1/execution/:id/revoke", s.RequireAdminIP(), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		assert.Equal(t, http.StatusForbidden, request(router, http.MethodPost, "/api/v1/execution/wf-1/revoke"))
+
+		var event siem.SecurityEvent
+		require.NoError(t, json.Unmarshal(auditLog.Bytes(), &event))
+		assert.Equal(t, siem.SecurityEventIPBlocked, event.EventType)
+		assert.Equal(t, "203.0.113.5", event.ActorIP)
+		assert.Equal(t, "POST /api/v1/execution/wf-1/revoke", event.Resource)
+		assert.Equal(t, siem.OutcomeDenied, event.Outcome)
+		assert.Equal(t, "admin_allowlist", event.Details["allowlist"])
+	})
+}
+
+func TestSetTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	adminCIDRs, err := parseAllowedCIDRs([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	newRouter := func(trustedProxies []string) *gin.Engine {
+		s := &Server{Config: &config.Config{}, adminCIDRs: adminCIDRs}
+		s.Config.Server.TrustedProxies = trustedProxies
+
+		router := gin.New()
+		require.NoError(t, s.setTrustedProxies(router))
+		router.POST("/api/v1/execution/:id/revoke", s.RequireAdminIP(), func(c *gin.Context) {
+			c.String(http.StatusOK, c.ClientIP())
+		})
+		return router
+	}
+
+	request := func(router *gin.Engine, remoteAddr string, forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/execution/wf-1/revoke", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("forwarded header is ignored without trusted proxies", func(t *testing.T) {
+		w := request(newRouter(nil), "192.0.2.10:40000", "10.1.2.3")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("forwarded header from an untrusted peer is ignored", func(t *testing.T) {
+		w := request(newRouter([]string{"192.0.2.0/24"}), "203.0.113.5:40000", "10.1.2.3")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("forwarded header from a trusted proxy is honoured", func(t *testing.T) {
+		w := request(newRouter([]string{"192.0.2.0/24"}), "192.0.2.10:40000", "10.1.2.3")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "10.1.2.3", w.Body.String())
+	})
+
+	t.Run("trusted proxies can't vouch for clients outside the allowlist", func(t *testing.T) {
+		w := request(newRouter([]string{"192.0.2.10"}), "192.0.2.10:40000", "203.0.113.5")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("rejects invalid proxies", func(t *testing.T) {
+		s := &Server{Config: &config.Config{}}
+		s.Config.Server.TrustedProxies = []string{"proxy.example.com"}
+
+		assert.ErrorContains(t, s.setTrustedProxies(gin.New()), "server.trusted_proxies")
+	})
+}
+
+// newSignalTestRouter registers the server's routes for an approver with a
+// previous approval of wf-1, which is replayed so signals succeed without
+// Temporal
+func newSignalTestRouter(t *testing.T, server *Server) *gin.Engine {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	server.signalResults = newSignalResults(time.Hour)
+	server.signalResults.Store("alice@example.com", "wf-1", "approve-1", "approve", &ExecutionStatePageData{
+		ExecutionStatePageResponse: ExecutionStatePageResponse{
+			Execution: &models.WorkflowExecutionInfo{WorkflowID: "wf-1"},
+		},
+	})
+
+	router := gin.New()
+	require.NoError(t, router.SetTrustedProxies(nil))
+	router.Use(sessions.SessionsMany([]string{ThandCookieName}, getSessionStore("secret")))
+	router.Use(func(c *gin.Context) {
+		c.Set(SessionContextKey, map[string]*models.Session{
+			"google": {
+				User:        &models.User{Email: "alice@example.com"},
+				AccessToken: "token",
+				Expiry:      time.Now().Add(time.Hour),
+			},
+		})
+	})
+	server.setupRoutes(router)
+
+	return router
+}
+
+// newSignalTestConfig returns a server mode config that has been set up, so
+// SetupMiddleware lets requests through to the signal routes
+func newSignalTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	cfg := config.DefaultConfig()
+	cfg.SetMode(config.ModeServer)
+	cfg.Login.Endpoint = "https://thand.example.com"
+	cfg.Secret = "signal-test-secret"
+
+	// Initializes the default local encryption service
+	require.True(t, cfg.GetServices().HasEncryption())
+
+	return cfg
+}
+
+func sendSignalTestRequest(router *gin.Engine, server *Server, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, server.Config.GetApiBasePath()+path, nil)
+	req.RemoteAddr = "203.0.113.5:40000"
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(idempotencyKeyHeader, "approve-1")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSignalRoutesOutsideAdminAllowlist(t *testing.T) {
+
+	adminCIDRs, err := parseAllowedCIDRs([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	cfg := newSignalTestConfig(t)
+
+	server := &Server{Config: cfg, adminCIDRs: adminCIDRs}
+	router := newSignalTestRouter(t, server)
+
+	t.Run("approvers can signal from outside the allowlist", func(t *testing.T) {
+		w := sendSignalTestRequest(router, server, http.MethodGet, "/execution/wf-1/signal?input=approve")
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"wf-1"`)
+	})
+
+	t.Run("admin endpoints stay restricted", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, sendSignalTestRequest(router, server, http.MethodPost, "/execution/wf-1/revoke").Code)
+		assert.Equal(t, http.StatusForbidden, sendSignalTestRequest(router, server, http.MethodGet, "/execution/wf-1/cancel").Code)
+		assert.Equal(t, http.StatusForbidden, sendSignalTestRequest(router, server, http.MethodGet, "/grants").Code)
+	})
+}
+
+func TestSignalRoutesWithoutClientCert(t *testing.T) {
+
+	cfg := newSignalTestConfig(t)
+	cfg.Server.TLS = models.TLSConfig{
+		CertFile:         "server.pem",
+		KeyFile:          "server-key.pem",
+		ClientCAFile:     "ca.pem",
+		ClientCertRoutes: []string{models.ClientCertRouteAdmin},
+	}
+
+	server := &Server{Config: cfg}
+	router := newSignalTestRouter(t, server)
+
+	t.Run("approvers can signal from a browser without a certificate", func(t *testing.T) {
+		w := sendSignalTestRequest(router, server, http.MethodGet, "/execution/wf-1/signal?input=approve")
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"wf-1"`)
+	})
+
+	t.Run("admin endpoints still need a certificate", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, sendSignalTestRequest(router, server, http.MethodPost, "/execution/wf-1/revoke").Code)
+		assert.Equal(t, http.StatusForbidden, sendSignalTestRequest(router, server, http.MethodGet, "/execution/wf-1/terminate").Code)
+	})
+}
+
+func TestClientCertMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	ca := newTestCertificate(t, dir, "ca", nil)
+	server := newTestCertificate(t, dir, "server", ca)
+	client := newTestCertificate(t, dir, "client", ca)
+	untrusted := newTestCertificate(t, dir, "untrusted", nil)
+
+	tlsSettings := models.TLSConfig{
+		CertFile:         server.certFile,
+		KeyFile:          server.keyFile,
+		ClientCAFile:     ca.certFile,
+		ClientCertRoutes: []string{models.ClientCertRouteAdmin},
+	}
+
+	tlsConfig, _, err := newTLSConfig(&tlsSettings)
+	require.NoError(t, err)
+	assert.Equal(t, tls.VerifyClientCertIfGiven, tlsConfig.ClientAuth)
+
+	s := &Server{Config: &config.Config{}}
+	s.Config.Server.TLS = tlsSettings
+
+	router := gin.New()
+	router.GET("/auth", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.POST("/api/v1/execution/:id/revoke",
+		s.ClientCertMiddleware(models.ClientCertRouteAdmin),
+		func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	require.NoError(t, err)
+
+	httpServer := &http.Server{Handler: router, ReadHeaderTimeout: time.Second}
+	go httpServer.Serve(listener)
+	t.Cleanup(func() { httpServer.Close() })
+
+	serverURL := "https://" + listener.Addr().String()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ca.cert)
+
+	newClient := func(cert *testCertificate) *http.Client {
+		clientConfig := &tls.Config{RootCAs: rootCAs}
+		if cert != nil {
+			keyPair, err := tls.LoadX509KeyPair(cert.certFile, cert.keyFile)
+			require.NoError(t, err)
+			clientConfig.Certificates = []tls.Certificate{keyPair}
+		}
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig}}
+	}
+
+	t.Run("browser routes don't need a certificate", func(t *testing.T) {
+		res, err := newClient(nil).Get(serverURL + "/auth")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("admin routes reject clients without a certificate", func(t *testing.T) {
+		res, err := newClient(nil).Post(serverURL+"/api/v1/execution/wf-1/revoke", "application/json", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("admin routes accept clients signed by the CA", func(t *testing.T) {
+		res, err := newClient(client).Post(serverURL+"/api/v1/execution/wf-1/revoke", "application/json", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("admin routes reject certificates from other CAs", func(t *testing.T) {
+		res, err := newClient(untrusted).Post(serverURL+"/api/v1/execution/wf-1/revoke", "application/json", nil)
+		if err != nil {
+			// The handshake fails if the certificate is presented
+			return
+		}
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("rejects unknown route groups", func(t *testing.T) {
+		_, _, err := newTLSConfig(&models.TLSConfig{
+			CertFile:         server.certFile,
+			KeyFile:          server.keyFile,
+			ClientCAFile:     ca.certFile,
+			ClientCertRoutes: []string{"everything"},
+		})
+		assert.ErrorContains(t, err, "tls.client_cert_routes")
+	})
+}
@@ -0,0 +1,196 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// getWorkflowAuditTrail returns what the user did with the access granted
+// by an elevation, from the audit trails of its providers
+//
+//	@Summary		Elevation audit trail
+//	@Description	List the calls the user made with the providers of an elevation during its access window e.g. from AWS CloudTrail. Providers without an audit trail are skipped.
+//	@Tags			executions
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string						true	"Workflow execution ID"
+//	@Success		200	{object}	models.AuditTrailResponse	"Audit trail"
+//	@Failure		400	{object}	map[string]any				"Bad request"
+//	@Failure		401	{object}	map[string]any				"Unauthorized"
+//	@Failure		403	{object}	map[string]any				"Forbidden"
+//	@Failure		404	{object}	map[string]any				"Workflow not found or access was never granted"
+//	@Failure		501	{object}	map[string]any				"No provider of the elevation has an audit trail"
+//	@Router			/workflows/{id}/audit [get]
+//	@Security		BearerAuth
+func (s *Server) getWorkflowAuditTrail(c *gin.Context) {
+
+	workflowId := c.Param("id")
+
+	temporalService := s.Config.GetServices().GetTemporal()
+
+	if temporalService == nil || !temporalService.HasClient() {
+		s.getErrorPage(c, http.StatusBadRequest, "Temporal service is not configured")
+		return
+	}
+
+	if !s.Config.IsServer() {
+		s.getErrorPage(c, http.StatusBadRequest, "Audit trails are only available in server mode")
+		return
+	}
+
+	_, foundUser, err := s.getUser(c)
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusUnauthorized, "Unauthorized: unable to get user for audit trail", err)
+		return
+	}
+
+	if foundUser == nil || foundUser.User == nil {
+		s.getErrorPage(c, http.StatusUnauthorized, "Unauthorized: user information is incomplete", nil)
+		return
+	}
+
+	if !s.Config.Server.Security.IsAdminUser(foundUser.User) {
+		s.getErrorPage(c, http.StatusForbidden, "Forbidden: audit trails require admin access")
+		return
+	}
+
+	workflowRun, err := temporalService.GetClient().DescribeWorkflowExecution(
+		c.Request.Context(), workflowId, models.TemporalEmptyRunId)
+
+	if err != nil || workflowRun.GetWorkflowExecutionInfo() == nil {
+		s.getErrorPage(c, http.StatusNotFound, "Failed to find workflow", err)
+		return
+	}
+
+	workflowInfo := workflowRun.GetWorkflowExecutionInfo()
+
+	grant := models.GetGrantMemo(workflowInfo.GetMemo())
+
+	if grant == nil {
+		s.getErrorPage(c, http.StatusNotFound, "Access was never granted by this workflow")
+		return
+	}
+
+	execution := s.workflowExecutionInfo(workflowInfo)
+	startTime, endTime := getAuditWindow(grant, execution.CloseTime, time.Now().UTC())
+
+	trails := s.getAuditTrails(c.Request.Context(), execution, startTime, endTime)
+
+	if len(trails) == 0 {
+		s.getErrorPage(c, http.StatusNotImplemented, "None of the providers of this elevation have an audit trail")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuditTrailResponse{
+		WorkflowID: execution.WorkflowID,
+		User:       execution.User,
+		StartTime:  startTime,
+		EndTime:    endTime,
+		Trails:     trails,
+	})
+}
+
+// getAuditWindow returns when the user had access. The window ends at the
+// scheduled revocation, or earlier if the workflow finished first e.g. the
+// elevation was revoked, or now if access hasn't been revoked yet.
+func getAuditWindow(grant *models.GrantMemo, closeTime *time.Time, now time.Time) (time.Time, time.Time) {
+
+	endTime := grant.RevocationAt
+
+	if closeTime != nil && closeTime.Before(endTime) {
+		endTime = *closeTime
+	}
+
+	if now.Before(endTime) {
+		endTime = now
+	}
+
+	return grant.AuthorizedAt, endTime
+}
+
+// getAuditTrails looks up the audit trail of each user granted access, for
+// each provider of the elevation that has one
+func (s *Server) getAuditTrails(
+	ctx context.Context,
+	execution *models.WorkflowExecutionInfo,
+	startTime time.Time,
+	endTime time.Time,
+) []models.AuditTrail {
+
+	users := []*models.User{}
+
+	for _, identity := range execution.Identities {
+		if identity != nil && identity.User != nil {
+			users = append(users, identity.User)
+		}
+	}
+
+	// Fall back to the requester if the identities couldn't be resolved
+	if len(users) == 0 && len(execution.User) > 0 {
+		users = append(users, &models.User{Email: execution.User})
+	}
+
+	trails := []models.AuditTrail{}
+
+	for _, providerName := range execution.Providers {
+
+		provider, err := s.Config.GetProviderByName(providerName)
+
+		if err != nil {
+			logrus.WithError(err).WithField("provider", providerName).
+				Warnln("Failed to find provider for audit trail")
+			continue
+		}
+
+		auditTrail, ok := provider.GetClient().(models.ProviderAuditTrail)
+
+		if !ok {
+			continue
+		}
+
+		for _, user := range users {
+
+			trail := models.AuditTrail{
+				Provider: providerName,
+				Events:   []models.AuditEvent{},
+			}
+
+			principal, err := auditTrail.GetAuditPrincipal(user)
+
+			if errors.Is(err, models.ErrNotImplemented) {
+				break
+			} else if err != nil {
+				trail.Error = err.Error()
+				trails = append(trails, trail)
+				continue
+			}
+
+			trail.Principal = principal
+
+			events, err := auditTrail.GetAccessAuditTrail(ctx, principal, startTime, endTime)
+
+			if errors.Is(err, models.ErrNotImplemented) {
+				break
+			} else if err != nil {
+				logrus.WithError(err).WithFields(logrus.Fields{
+					"provider":  providerName,
+					"principal": principal,
+				}).Warnln("Failed to get audit trail")
+				trail.Error = err.Error()
+			} else {
+				trail.Events = events
+			}
+
+			trails = append(trails, trail)
+		}
+	}
+
+	return trails
+}
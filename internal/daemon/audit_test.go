@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+)
+
+type auditTrailProvider struct {
+	*models.BaseProvider
+	err error
+}
+
+func (p *auditTrailProvider) GetAuditPrincipal(user *models.User) (string, error) {
+	return "user/" + user.Email, nil
+}
+
+func (p *auditTrailProvider) GetAccessAuditTrail(ctx context.Context, principal string, startTime time.Time, endTime time.Time) ([]models.AuditEvent, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []models.AuditEvent{{ID: "1", Name: "TerminateInstances", Time: startTime}}, nil
+}
+
+func TestGetAuditWindow(t *testing.T) {
+
+	authorizedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	grant := &models.GrantMemo{
+		AuthorizedAt: authorizedAt,
+		RevocationAt: authorizedAt.Add(time.Hour),
+	}
+
+	t.Run("expired access", func(t *testing.T) {
+		start, end := getAuditWindow(grant, nil, authorizedAt.Add(2*time.Hour))
+		assert.Equal(t, authorizedAt, start)
+		assert.Equal(t, grant.RevocationAt, end)
+	})
+
+	t.Run("revoked early", func(t *testing.T) {
+		closeTime := authorizedAt.Add(10 * time.Minute)
+		_, end := getAuditWindow(grant, &closeTime, authorizedAt.Add(2*time.Hour))
+		assert.Equal(t, closeTime, end)
+	})
+
+	t.Run("active access", func(t *testing.T) {
+		now := authorizedAt.Add(30 * time.Minute)
+		_, end := getAuditWindow(grant, nil, now)
+		assert.Equal(t, now, end)
+	})
+}
+
+func TestGetAuditTrails(t *testing.T) {
+
+	cfg := config.DefaultConfig()
+
+	newProvider := func(name string, client models.ProviderImpl) models.Provider {
+		provider := models.Provider{Name: name, Provider: name, Enabled: true}
+		provider.SetClient(client)
+		return provider
+	}
+
+	newAuditProvider := func(name string, err error) models.Provider {
+		return newProvider(name, &auditTrailProvider{
+			BaseProvider: models.NewBaseProvider(name, models.Provider{Name: name, Provider: name}),
+			err:          err,
+		})
+	}
+
+	cfg.Providers.Definitions = map[string]models.Provider{
+		"aws":    newAuditProvider("aws", nil),
+		"broken": newAuditProvider("broken", errors.New("access denied")),
+		"slack": newProvider("slack", models.NewBaseProvider("slack", models.Provider{
+			Name: "slack", Provider: "slack",
+		})),
+	}
+
+	server := &Server{Config: cfg}
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	trails := server.getAuditTrails(context.Background(), &models.WorkflowExecutionInfo{
+		User:      "alice@example.com",
+		Providers: []string{"aws", "slack", "broken", "missing"},
+	}, start, start.Add(time.Hour))
+
+	require.Len(t, trails, 2, "providers without an audit trail are skipped")
+
+	assert.Equal(t, "aws", trails[0].Provider)
+	assert.Equal(t, "user/alice@example.com", trails[0].Principal)
+	require.Len(t, trails[0].Events, 1)
+	assert.Equal(t, "TerminateInstances", trails[0].Events[0].Name)
+
+	assert.Equal(t, "broken", trails[1].Provider)
+	assert.Equal(t, "access denied", trails[1].Error)
+	assert.Empty(t, trails[1].Events)
+}
@@ -2,6 +2,7 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -12,6 +13,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/metrics"
 	"github.com/thand-io/agent/internal/models"
 )
 
@@ -47,6 +49,15 @@ func (s *Server) getAuthRequest(c *gin.Context) {
 		return
 	}
 
+	// Only return to URLs we own, otherwise the session could be sent
+	// to another host
+	if len(callback) > 0 {
+		if err := s.validateAuthRedirect(callback); err != nil {
+			s.rejectAuthRedirect(c, provider, callback, err)
+			return
+		}
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"provider":    provider,
 		"callback":    callback,
@@ -61,24 +72,33 @@ func (s *Server) getAuthRequest(c *gin.Context) {
 		return
 	}
 
+	authorizor, err := providerConfig.GetAuthorizor()
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusBadRequest, "Provider does not support authentication", err)
+		return
+	}
+
 	client := common.GetClientIdentifier()
 
-	authResponse, err := providerConfig.GetClient().AuthorizeSession(
+	// This creates the state payload for the auth request
+	state, err := s.newAuthState(c, models.NewAuthWrapper(
+		callback,        // where are we returning to
+		client.String(), // server identifier
+		provider,        // provider name
+		code,            // the code sent by the client
+	))
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusInternalServerError, "Failed to create state", err)
+		return
+	}
+
+	authResponse, err := authorizor.AuthorizeSession(
 		context.Background(),
-		// This creates the state payload for the auth request
 		&models.AuthorizeUser{
-			Scopes: []string{"email", "profile"},
-			State: models.EncodingWrapper{
-				Type: models.ENCODED_AUTH,
-				Data: models.NewAuthWrapper(
-					callback,        // where are we returning to
-					client.String(), // server identifier
-					provider,        // provider name
-					code,            // the code sent by the client
-				),
-			}.EncodeAndEncrypt(
-				s.Config.GetServices().GetEncryption(),
-			),
+			Scopes:      []string{"email", "profile"},
+			State:       state,
 			RedirectUri: s.GetConfig().GetAuthCallbackUrl(provider),
 		},
 	)
@@ -114,7 +134,7 @@ func (s *Server) getAuthCallback(c *gin.Context) {
 	// Check if the callback is a workflow resumption or
 	// a local callback response
 
-	state := c.Query("state")
+	state, code := getAuthCallbackParams(c)
 
 	if len(state) == 0 {
 		s.getErrorPage(c, http.StatusBadRequest, "State is required")
@@ -127,13 +147,14 @@ func (s *Server) getAuthCallback(c *gin.Context) {
 	)
 
 	if err != nil {
+		s.recordAuthFailure(c, c.Param("provider"), "invalid_state", err)
 		s.getErrorPage(c, http.StatusBadRequest, "Invalid state", err)
 		return
 	}
 
 	switch decoded.Type {
 	case models.ENCODED_WORKFLOW_TASK:
-		s.getElevateAuthOAuth2(c)
+		s.getElevateAuthOAuth2(c, state, code)
 	case models.ENCODED_AUTH:
 
 		authWrapper := models.AuthWrapper{}
@@ -145,13 +166,59 @@ func (s *Server) getAuthCallback(c *gin.Context) {
 			return
 		}
 
-		s.getAuthCallbackPage(c, authWrapper)
+		if err := s.consumeAuthState(c, authWrapper); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"event":    "auth_state_rejected",
+				"provider": authWrapper.Provider,
+				"ip":       c.ClientIP(),
+			}).Warnln("Rejected authentication state")
+
+			s.recordAuthFailure(c, authWrapper.Provider, "state_rejected", err)
+			s.getErrorPage(c, http.StatusBadRequest, "Invalid state", err)
+			return
+		}
+
+		// The allowed redirects may have changed since the login started
+		if len(authWrapper.Callback) > 0 {
+			if err := s.validateAuthRedirect(authWrapper.Callback); err != nil {
+				s.rejectAuthRedirect(c, authWrapper.Provider, authWrapper.Callback, err)
+				return
+			}
+		}
+
+		s.getAuthCallbackPage(c, authWrapper, state, code)
 
 	default:
 		s.getErrorPage(c, http.StatusBadRequest, "Invalid state type")
 	}
 }
 
+// postAuthCallback handles callbacks posted by the provider
+//
+//	@Summary		Authentication callback (POST)
+//	@Description	Handle a callback posted by the provider e.g. a SAML response using the HTTP-POST binding. The state is read from the RelayState
+//	@Tags			auth
+//	@Accept			x-www-form-urlencoded
+//	@Produce		json
+//	@Param			provider		path		string	true	"Provider name"
+//	@Param			RelayState		formData	string	true	"State"
+//	@Param			SAMLResponse	formData	string	true	"SAML response"
+//	@Success		200				"Authentication successful"
+//	@Failure		400				{object}	map[string]any	"Bad request"
+//	@Router			/auth/callback/{provider} [post]
+func (s *Server) postAuthCallback(c *gin.Context) {
+	s.getAuthCallback(c)
+}
+
+// getAuthCallbackParams returns the state and code of the callback. SAML
+// IdPs post the response with the state as the RelayState.
+func getAuthCallbackParams(c *gin.Context) (string, string) {
+	if c.Request.Method == http.MethodPost {
+		return c.PostForm("RelayState"), c.PostForm("SAMLResponse")
+	}
+	return c.Query("state"), c.Query("code")
+}
+
 type AuthPageData struct {
 	config.TemplateData
 	Providers map[string]models.ProviderResponse
@@ -185,6 +252,9 @@ func (s *Server) getAuthPage(c *gin.Context) {
 
 	if !foundCallback || len(callback) == 0 {
 		logrus.Debug("Using local server URL as callback")
+	} else if err := s.validateAuthRedirect(callback); err != nil {
+		s.rejectAuthRedirect(c, c.Query("provider"), callback, err)
+		return
 	}
 
 	// Has a provider been specified
@@ -209,7 +279,7 @@ func (s *Server) getAuthPage(c *gin.Context) {
 			"callback": {callback},
 		}
 
-		c.Redirect(http.StatusTemporaryRedirect, fmt.Sprintf("%s/auth/request/%s?callback=%s",
+		c.Redirect(http.StatusTemporaryRedirect, fmt.Sprintf("%s/auth/request/%s?%s",
 			s.Config.GetApiBasePath(),
 			provider,
 			params.Encode(),
@@ -232,6 +302,33 @@ func (s *Server) getAuthPage(c *gin.Context) {
 	}
 }
 
+// getAuthProviders lists the providers users can log in with. It doesn't
+// need a session as it's used to choose how to log in.
+//
+//	@Summary		List authentication providers
+//	@Description	Get the providers that can be used to log in, so clients can choose one when more than one is configured
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	models.ProvidersResponse	"Authentication providers"
+//	@Router			/auth/providers [get]
+func (s *Server) getAuthProviders(c *gin.Context) {
+
+	authProviders := s.getAuthProvidersAsProviderResponse(nil)
+
+	// Only identify the providers, their health and capabilities are
+	// left to the authenticated providers endpoint
+	for providerKey, provider := range authProviders {
+		provider.Capabilities = nil
+		authProviders[providerKey] = provider
+	}
+
+	c.JSON(http.StatusOK, models.ProvidersResponse{
+		Version:   "1.0",
+		Providers: authProviders,
+	})
+}
+
 type AuthCallbackPageData struct {
 	config.TemplateData
 	Auth        models.AuthWrapper
@@ -239,28 +336,29 @@ type AuthCallbackPageData struct {
 	LoginServer string
 }
 
-func (s *Server) getAuthCallbackPage(c *gin.Context, auth models.AuthWrapper) {
+func (s *Server) getAuthCallbackPage(c *gin.Context, auth models.AuthWrapper, state string, code string) {
 
 	// Get the provider and pull back the user session into
 	// the context
 
-	provider, err := s.Config.GetProviderByName(auth.Provider)
+	authorizor, err := s.Config.GetAuthorizor(auth.Provider)
 
 	if err != nil {
 		s.getErrorPage(c, http.StatusBadRequest, "Invalid provider", err)
 		return
 	}
 
-	state := c.Query("state")
-	code := c.Query("code") // This is the code from the provider - not the client
-
-	session, err := provider.GetClient().CreateSession(c, &models.AuthorizeUser{
+	// The code is from the provider - not the client
+	session, err := authorizor.CreateSession(c, &models.AuthorizeUser{
 		State:       state,
 		Code:        code,
 		RedirectUri: s.GetConfig().GetAuthCallbackUrl(auth.Provider),
 	})
 
+	metrics.RecordAuthAttempt(auth.Provider, err == nil && session != nil)
+
 	if err != nil {
+		s.recordAuthFailure(c, auth.Provider, "create_session", err)
 		s.getErrorPage(c, http.StatusBadRequest, "Failed to create session", err)
 		return
 	}
@@ -270,6 +368,8 @@ func (s *Server) getAuthCallbackPage(c *gin.Context, auth models.AuthWrapper) {
 		return
 	}
 
+	s.setSessionOrigin(c, auth.Provider, session)
+
 	exportableSession := &models.ExportableSession{
 		Session:  session,
 		Provider: auth.Provider,
@@ -298,6 +398,175 @@ func (s *Server) getAuthCallbackPage(c *gin.Context, auth models.AuthWrapper) {
 	}
 }
 
+// setSessionOrigin records where a session was created and adds it to the
+// audit log
+func (s *Server) setSessionOrigin(c *gin.Context, provider string, session *models.Session) {
+
+	session.DeviceFingerprint = models.NewDeviceFingerprint(
+		c.GetHeader("User-Agent"), c.GetHeader("Accept-Language"))
+	session.CreatedFromIP = c.ClientIP()
+	session.CreatedFromCountry = s.Config.LookupCountry(session.CreatedFromIP)
+
+	fields := logrus.Fields{
+		"provider":    provider,
+		"ip":          session.CreatedFromIP,
+		"country":     session.CreatedFromCountry,
+		"fingerprint": session.DeviceFingerprint,
+	}
+
+	if session.User != nil {
+		fields["user"] = session.User.GetIdentity()
+	}
+
+	logrus.WithFields(fields).Infoln("Session created")
+}
+
+// postAuthRefresh renews an expiring session without a new login
+//
+//	@Summary		Refresh session
+//	@Description	Renew the session cookie with the provider's refresh token. The session may already have expired. Returns 401 when there is no refresh token and the user must login again
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		models.AuthRefreshRequest	false	"Provider and refresh token"
+//	@Success		200		{object}	models.AuthRefreshResponse	"Session renewed"
+//	@Failure		400		{object}	map[string]any				"Invalid provider"
+//	@Failure		401		{object}	map[string]any				"Session can't be renewed"
+//	@Failure		500		{object}	map[string]any				"Internal server error"
+//	@Router			/auth/refresh [post]
+func (s *Server) postAuthRefresh(c *gin.Context) {
+
+	var request models.AuthRefreshRequest
+
+	// The body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			s.getErrorPage(c, http.StatusBadRequest, "Invalid refresh request", err)
+			return
+		}
+	}
+
+	providerName, renewedSession, err := s.renewSession(c, request.Provider, request.RefreshToken, true)
+
+	if err != nil {
+		s.getStatusErrorPage(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuthRefreshResponse{
+		Provider: providerName,
+		Expiry:   renewedSession.Expiry.UTC(),
+	})
+}
+
+// postAuthRenew renews the session the CLI presents before it expires
+//
+//	@Summary		Renew session
+//	@Description	Renew the session presented as the bearer token and return it for the CLI to store. Returns 501 when the provider doesn't support renewal and the user must login again
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		models.AuthRenewRequest		false	"Provider of the session"
+//	@Success		200		{object}	models.AuthRenewResponse	"Session renewed"
+//	@Failure		400		{object}	map[string]any				"Invalid provider"
+//	@Failure		401		{object}	map[string]any				"Session can't be renewed"
+//	@Failure		501		{object}	map[string]any				"Renewal unsupported"
+//	@Failure		500		{object}	map[string]any				"Internal server error"
+//	@Router			/auth/renew [post]
+func (s *Server) postAuthRenew(c *gin.Context) {
+
+	var request models.AuthRenewRequest
+
+	// The body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			s.getErrorPage(c, http.StatusBadRequest, "Invalid renew request", err)
+			return
+		}
+	}
+
+	providerName, renewedSession, err := s.renewSession(c, request.Provider, "", false)
+
+	if errors.Is(err, models.ErrNotImplemented) {
+		s.getErrorPage(c, http.StatusNotImplemented, "Session renewal unsupported, please login again", err)
+		return
+	} else if err != nil {
+		s.getStatusErrorPage(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuthRenewResponse{
+		Provider: providerName,
+		Session:  renewedSession.GetEncodedLocalSession(),
+		Expiry:   renewedSession.Expiry.UTC(),
+	})
+}
+
+// renewSession renews the user's session for the provider, or their first
+// session, and rewrites its cookie. The session may already have expired.
+// A refresh token replaces the session's own when set. Errors from the
+// provider wrap the provider's error e.g. ErrNotImplemented.
+func (s *Server) renewSession(
+	c *gin.Context,
+	provider string,
+	refreshToken string,
+	requireRefreshToken bool,
+) (string, *models.LocalSession, error) {
+
+	authProviders := []string{}
+
+	if len(provider) > 0 {
+		authProviders = append(authProviders, provider)
+	}
+
+	// The session may have expired, getUser still returns it
+	providerName, session, err := s.getUser(c, authProviders...)
+
+	if err != nil {
+		return "", nil, newStatusError(http.StatusUnauthorized, "No session to renew", err)
+	}
+
+	if len(refreshToken) > 0 {
+		session.RefreshToken = refreshToken
+	}
+
+	if requireRefreshToken && len(session.RefreshToken) == 0 {
+		return "", nil, newStatusError(http.StatusUnauthorized, "No refresh token available, please login again", nil)
+	}
+
+	authorizor, err := s.Config.GetAuthorizor(providerName)
+
+	if err != nil {
+		return "", nil, newStatusError(http.StatusBadRequest, "Invalid provider", err)
+	}
+
+	renewedSession, err := authorizor.RenewSession(c, session)
+
+	if err != nil {
+		return "", nil, newStatusError(http.StatusUnauthorized, "Failed to renew session, please login again", err)
+	}
+
+	if renewedSession == nil {
+		return "", nil, newStatusError(http.StatusInternalServerError, "Session is nil", nil)
+	}
+
+	renewedSession.KeepOrigin(session)
+
+	exportableSession := &models.ExportableSession{
+		Session:  renewedSession,
+		Provider: providerName,
+	}
+
+	localSession := exportableSession.ToLocalSession(
+		s.Config.GetServices().GetEncryption())
+
+	if err := s.setAuthCookie(c, providerName, localSession); err != nil {
+		return "", nil, newStatusError(http.StatusInternalServerError, "Failed to set auth cookie", err)
+	}
+
+	return providerName, localSession, nil
+}
+
 // getLogoutPage handles user logout
 //
 //	@Summary		Logout
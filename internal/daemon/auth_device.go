@@ -0,0 +1,199 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/metrics"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// postDeviceCode starts the device login flow
+//
+//	@Summary		Request a device code
+//	@Description	Start the device login flow for CLIs that can't open a browser. The user approves the returned user code at the verification URI while the CLI polls the token endpoint
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200	{object}	models.DeviceCodeResponse	"Device code issued"
+//	@Failure		500	{object}	map[string]any				"Internal server error"
+//	@Router			/auth/device/code [post]
+func (s *Server) postDeviceCode(c *gin.Context) {
+
+	authorization, err := s.deviceCodes.Issue()
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusInternalServerError, "Failed to create device code", err)
+		return
+	}
+
+	verificationUri := fmt.Sprintf("%s/device", s.Config.GetLoginServerUrl())
+
+	c.JSON(http.StatusOK, models.DeviceCodeResponse{
+		DeviceCode:      authorization.DeviceCode,
+		UserCode:        formatUserCode(authorization.UserCode),
+		VerificationUri: verificationUri,
+		VerificationUriComplete: fmt.Sprintf("%s?%s", verificationUri, url.Values{
+			"user_code": {formatUserCode(authorization.UserCode)},
+		}.Encode()),
+		ExpiresIn: int(s.deviceCodes.expiry.Seconds()),
+		Interval:  int(authorization.Interval.Seconds()),
+	})
+}
+
+// postDeviceToken returns the session once the device code is approved
+//
+//	@Summary		Poll for a device session
+//	@Description	Poll with the device code until the user approves or denies it. Codes are single use, and polling faster than the interval returns slow_down
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		models.DeviceTokenRequest	true	"Device code"
+//	@Success		200		{object}	models.DeviceTokenResponse	"Session for the approved device code"
+//	@Failure		400		{object}	models.DeviceTokenError		"Pending, denied or expired"
+//	@Failure		429		{object}	models.DeviceTokenError		"Polling too quickly"
+//	@Router			/auth/device/token [post]
+func (s *Server) postDeviceToken(c *gin.Context) {
+
+	var request models.DeviceTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.DeviceTokenError{
+			Error:            models.DeviceErrorInvalidRequest,
+			ErrorDescription: "device_code is required",
+		})
+		return
+	}
+
+	provider, session, err := s.deviceCodes.Poll(request.DeviceCode)
+
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, models.DeviceTokenResponse{
+			Provider: provider,
+			Session:  session.GetEncodedLocalSession(),
+			Expiry:   session.Expiry.UTC(),
+		})
+	case errors.Is(err, errDeviceSlowDown):
+		c.JSON(http.StatusTooManyRequests, models.DeviceTokenError{
+			Error:            models.DeviceErrorSlowDown,
+			ErrorDescription: "polling too quickly",
+			Interval:         int(s.deviceCodes.GetInterval(request.DeviceCode).Seconds()),
+		})
+	case errors.Is(err, errDeviceAuthorizationPending):
+		c.JSON(http.StatusBadRequest, models.DeviceTokenError{
+			Error:            models.DeviceErrorAuthorizationPending,
+			ErrorDescription: "waiting for the user to approve the code",
+		})
+	case errors.Is(err, errDeviceAccessDenied):
+		c.JSON(http.StatusBadRequest, models.DeviceTokenError{
+			Error:            models.DeviceErrorAccessDenied,
+			ErrorDescription: "the code was denied",
+		})
+	default:
+		c.JSON(http.StatusBadRequest, models.DeviceTokenError{
+			Error:            models.DeviceErrorExpiredToken,
+			ErrorDescription: "the code is invalid or has expired",
+		})
+	}
+}
+
+type DevicePageData struct {
+	config.TemplateData
+	UserCode string
+	Result   string // approved or denied once the form is submitted
+	Error    string
+}
+
+// getDevicePage displays the page to approve a device code
+//
+//	@Summary		Device approval page
+//	@Description	Display the page where a signed in user approves a device code
+//	@Tags			auth
+//	@Produce		html
+//	@Param			user_code	query	string	false	"User code shown by the CLI"
+//	@Success		200			"Device approval page"
+//	@Router			/device [get]
+func (s *Server) getDevicePage(c *gin.Context) {
+
+	data := DevicePageData{
+		TemplateData: s.GetTemplateData(c),
+		UserCode:     c.Query("user_code"),
+	}
+
+	if data.User != nil && len(data.UserCode) > 0 {
+		if _, err := s.deviceCodes.Lookup(data.UserCode); err != nil {
+			data.Error = "This code is invalid or has expired. Run the login command again for a new code."
+		}
+	}
+
+	s.renderHtml(c, "device.html", data)
+}
+
+// postDevicePage approves or denies a device code with the user's session
+//
+//	@Summary		Approve device code
+//	@Description	Approve or deny a device code. Approving shares the signed in user's session with the CLI
+//	@Tags			auth
+//	@Accept			x-www-form-urlencoded
+//	@Produce		html
+//	@Param			user_code	formData	string	true	"User code shown by the CLI"
+//	@Param			action		formData	string	true	"approve or deny"
+//	@Success		200			"Result page"
+//	@Failure		401			{object}	map[string]any	"Unauthorized"
+//	@Router			/device [post]
+func (s *Server) postDevicePage(c *gin.Context) {
+
+	provider, session, err := s.getUser(c)
+
+	if err == nil && session.User == nil {
+		err = errors.New("session has no user")
+	}
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusUnauthorized, "Sign in to approve the device", err)
+		return
+	}
+
+	data := DevicePageData{
+		TemplateData: s.GetTemplateData(c),
+		UserCode:     c.PostForm("user_code"),
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"provider": provider,
+		"user":     session.User.GetIdentity(),
+	})
+
+	if c.PostForm("action") == "deny" {
+
+		err = s.deviceCodes.Deny(data.UserCode)
+		data.Result = "denied"
+
+	} else {
+
+		exportableSession := &models.ExportableSession{
+			Session:  session,
+			Provider: provider,
+		}
+
+		err = s.deviceCodes.Approve(data.UserCode, provider,
+			exportableSession.ToLocalSession(s.Config.GetServices().GetEncryption()))
+		data.Result = "approved"
+
+		metrics.RecordAuthAttempt(provider, err == nil)
+	}
+
+	if err != nil {
+		logger.WithError(err).Warnln("Failed to complete device code")
+		data.Result = ""
+		data.Error = "This code is invalid or has expired. Run the login command again for a new code."
+	} else {
+		logger.WithField("result", data.Result).Infoln("Completed device code")
+	}
+
+	s.renderHtml(c, "device.html", data)
+}
@@ -0,0 +1,316 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// ThandStateCookieName holds the logins the browser has started but not
+// yet completed
+var ThandStateCookieName = "_thand_state_v1"
+
+const (
+	authStateCookieKey   = "states"
+	authStateNonceLength = 43 // 256 bits
+	maxPendingAuthStates = 10
+)
+
+var (
+	ErrAuthStateExpired = errors.New("the login has expired, please try again")
+	ErrAuthStateUnknown = errors.New("the login was already completed or wasn't started in this browser")
+)
+
+// validateAuthRedirect checks the URL an auth flow returns to is one the
+// server owns. Loopback addresses are always allowed so the CLI can receive
+// the session on its local server.
+func (s *Server) validateAuthRedirect(redirect string) error {
+
+	parsed, err := url.Parse(redirect)
+
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("scheme %q is not allowed", parsed.Scheme)
+	}
+
+	if len(parsed.Host) == 0 {
+		return errors.New("the URL must be absolute")
+	}
+
+	if parsed.User != nil {
+		return errors.New("the URL must not contain credentials")
+	}
+
+	if strings.Contains(parsed.Path, "..") {
+		return errors.New("the URL must not contain relative path segments")
+	}
+
+	if isLoopbackHost(parsed.Hostname()) {
+		return nil
+	}
+
+	rules := append([]string{s.Config.GetRootUrl()}, s.Config.Server.Security.AllowedRedirects...)
+
+	for _, rule := range rules {
+		if matchRedirectRule(rule, parsed) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s is not an allowed redirect", parsed.Redacted())
+}
+
+// matchRedirectRule returns true if the redirect matches the rule. Rules
+// ending in * are prefixes, rules without a path match the whole origin and
+// anything else must match exactly.
+func matchRedirectRule(rule string, redirect *url.URL) bool {
+
+	rule = strings.TrimSpace(rule)
+	prefix := strings.HasSuffix(rule, "*")
+
+	allowed, err := url.Parse(strings.TrimSuffix(rule, "*"))
+
+	if err != nil || len(allowed.Host) == 0 {
+		return false
+	}
+
+	if !strings.EqualFold(allowed.Scheme, redirect.Scheme) ||
+		!strings.EqualFold(allowed.Host, redirect.Host) {
+		return false
+	}
+
+	switch {
+	case prefix:
+		return strings.HasPrefix(redirect.Path, allowed.Path)
+	case len(allowed.Path) == 0:
+		return true
+	default:
+		return redirect.Path == allowed.Path
+	}
+}
+
+// isLoopbackHost returns true for localhost and loopback IPs
+func isLoopbackHost(host string) bool {
+
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// rejectAuthRedirect logs the rejected redirect as a security event and
+// returns an error page
+func (s *Server) rejectAuthRedirect(c *gin.Context, provider string, redirect string, err error) {
+
+	logrus.WithError(err).WithFields(logrus.Fields{
+		"event":    "auth_redirect_rejected",
+		"provider": provider,
+		"redirect": redirect,
+		"ip":       c.ClientIP(),
+		"path":     c.Request.URL.Path,
+	}).Warnln("Rejected authentication redirect")
+
+	s.getErrorPage(c, http.StatusBadRequest, "Invalid redirect", err)
+}
+
+// newAuthState creates the encrypted state for a login
+func (s *Server) newAuthState(c *gin.Context, auth models.AuthWrapper) (string, error) {
+
+	auth, err := s.startAuthState(c, auth)
+
+	if err != nil {
+		return "", err
+	}
+
+	return models.EncodingWrapper{
+		Type: models.ENCODED_AUTH,
+		Data: auth,
+	}.EncodeAndEncrypt(
+		s.Config.GetServices().GetEncryption(),
+	), nil
+}
+
+// startAuthState adds a random nonce and expiry to the state. The nonce is
+// stored in the state cookie so the callback is only accepted from the
+// browser that started the login and before the state expires.
+func (s *Server) startAuthState(c *gin.Context, auth models.AuthWrapper) (models.AuthWrapper, error) {
+
+	nonce, err := common.GenerateSecureRandomString(authStateNonceLength)
+
+	if err != nil {
+		return auth, fmt.Errorf("failed to generate state: %w", err)
+	}
+
+	expiry := s.Config.Server.Security.GetAuthStateExpiry()
+
+	auth.Nonce = nonce
+	auth.ExpiresAt = time.Now().UTC().Add(expiry)
+
+	cookie := sessions.DefaultMany(c, ThandStateCookieName)
+	states := getPendingAuthStates(cookie)
+
+	// Keep the newest states so the cookie doesn't grow without bound
+	for len(states) >= maxPendingAuthStates {
+		delete(states, getOldestAuthState(states))
+	}
+
+	states[nonce] = auth.ExpiresAt.Unix()
+
+	if err := savePendingAuthStates(cookie, states, expiry); err != nil {
+		return auth, err
+	}
+
+	return auth, nil
+}
+
+// consumeAuthState checks the state was started by this browser and hasn't
+// expired, then marks it as used so it can't be replayed
+func (s *Server) consumeAuthState(c *gin.Context, auth models.AuthWrapper) error {
+
+	if auth.IsExpired() {
+		return ErrAuthStateExpired
+	}
+
+	cookie := sessions.DefaultMany(c, ThandStateCookieName)
+	states := getPendingAuthStates(cookie)
+
+	expiresAt, found := states[auth.Nonce]
+
+	if len(auth.Nonce) == 0 || !found {
+		return ErrAuthStateUnknown
+	}
+
+	delete(states, auth.Nonce)
+
+	if err := savePendingAuthStates(cookie, states, s.Config.Server.Security.GetAuthStateExpiry()); err != nil {
+		return err
+	}
+
+	if time.Now().UTC().Unix() > expiresAt {
+		return ErrAuthStateExpired
+	}
+
+	// The cookie is kept by the browser so an old copy of it could be
+	// presented again. Remember the nonce until it expires.
+	if !s.usedAuthStates.Use(auth.Nonce, auth.ExpiresAt) {
+		return ErrAuthStateUnknown
+	}
+
+	return nil
+}
+
+// getPendingAuthStates returns the nonces of the pending logins and when
+// they expire, dropping any that have expired
+func getPendingAuthStates(cookie sessions.Session) map[string]int64 {
+
+	states := map[string]int64{}
+
+	if encoded, ok := cookie.Get(authStateCookieKey).(string); ok {
+		if err := json.Unmarshal([]byte(encoded), &states); err != nil {
+			logrus.WithError(err).Warnln("Failed to decode auth states from cookie")
+		}
+	}
+
+	now := time.Now().UTC().Unix()
+
+	for nonce, expiresAt := range states {
+		if now > expiresAt {
+			delete(states, nonce)
+		}
+	}
+
+	return states
+}
+
+func savePendingAuthStates(cookie sessions.Session, states map[string]int64, expiry time.Duration) error {
+
+	encoded, err := json.Marshal(states)
+
+	if err != nil {
+		return fmt.Errorf("failed to encode auth states: %w", err)
+	}
+
+	// SAML IdPs post the response back cross-site, so the cookie must be
+	// sent on cross-site requests
+	cookie.Options(sessions.Options{
+		Path:     "/",
+		MaxAge:   int(expiry.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+	cookie.Set(authStateCookieKey, string(encoded))
+
+	if err := cookie.Save(); err != nil {
+		return fmt.Errorf("failed to save auth state cookie: %w", err)
+	}
+
+	return nil
+}
+
+func getOldestAuthState(states map[string]int64) string {
+
+	oldest := ""
+
+	for nonce, expiresAt := range states {
+		if len(oldest) == 0 || expiresAt < states[oldest] {
+			oldest = nonce
+		}
+	}
+
+	return oldest
+}
+
+// usedAuthStates holds the nonces of completed logins in memory until
+// they expire
+type usedAuthStates struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+func newUsedAuthStates() *usedAuthStates {
+	return &usedAuthStates{
+		nonces: map[string]time.Time{},
+	}
+}
+
+// Use marks the nonce as used. Returns false if it was already used.
+func (u *usedAuthStates) Use(nonce string, expiresAt time.Time) bool {
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	for used, usedExpiresAt := range u.nonces {
+		if now.After(usedExpiresAt) {
+			delete(u.nonces, used)
+		}
+	}
+
+	if _, found := u.nonces[nonce]; found {
+		return false
+	}
+
+	u.nonces[nonce] = expiresAt
+
+	return true
+}
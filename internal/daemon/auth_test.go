@@ -0,0 +1,431 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestPostAuthRefresh(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.DefaultConfig()
+	cfg.SetMode(config.ModeServer)
+
+	server := &Server{Config: cfg}
+
+	refresh := func(userSessions map[string]*models.Session) int {
+		router := gin.New()
+		router.Use(sessions.SessionsMany([]string{ThandCookieName}, getSessionStore("secret")))
+		router.POST("/auth/refresh", func(c *gin.Context) {
+			if userSessions != nil {
+				c.Set(SessionContextKey, userSessions)
+			}
+		}, server.postAuthRefresh)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/auth/refresh", nil)
+		req.Header.Set("Accept", "application/json")
+		router.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	t.Run("no session", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, refresh(nil))
+	})
+
+	t.Run("no refresh token requires login", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, refresh(map[string]*models.Session{
+			"google": {
+				User:        &models.User{Email: "alice@example.com"},
+				AccessToken: "expired",
+				Expiry:      time.Now().Add(-time.Hour),
+			},
+		}))
+	})
+}
+
+type renewProvider struct {
+	*models.BaseProvider
+	err     error
+	renewed *models.Session
+}
+
+func (p *renewProvider) RenewSession(ctx context.Context, session *models.Session) (*models.Session, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.renewed != nil {
+		return p.renewed, nil
+	}
+	return nil, models.ErrNotImplemented
+}
+
+func (p *renewProvider) AuthorizeSession(ctx context.Context, user *models.AuthorizeUser) (*models.AuthorizeSessionResponse, error) {
+	return nil, models.ErrNotImplemented
+}
+
+func (p *renewProvider) CreateSession(ctx context.Context, user *models.AuthorizeUser) (*models.Session, error) {
+	return nil, models.ErrNotImplemented
+}
+
+func (p *renewProvider) ValidateSession(ctx context.Context, session *models.Session) error {
+	return models.ErrNotImplemented
+}
+
+func TestPostAuthRenew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.DefaultConfig()
+	cfg.SetMode(config.ModeServer)
+
+	newProvider := func(name string, err error) models.Provider {
+		provider := models.Provider{Name: name, Provider: name, Enabled: true}
+		provider.SetClient(&renewProvider{
+			BaseProvider: models.NewBaseProvider(name, provider),
+			err:          err,
+		})
+		return provider
+	}
+
+	cfg.Providers.Definitions = map[string]models.Provider{
+		"saml":   newProvider("saml", nil),
+		"google": newProvider("google", errors.New("refresh token revoked")),
+	}
+
+	server := &Server{Config: cfg}
+
+	renew := func(userSessions map[string]*models.Session) int {
+		router := gin.New()
+		router.Use(sessions.SessionsMany([]string{ThandCookieName}, getSessionStore("secret")))
+		router.POST("/auth/renew", func(c *gin.Context) {
+			if userSessions != nil {
+				c.Set(SessionContextKey, userSessions)
+			}
+		}, server.postAuthRenew)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/auth/renew", nil)
+		req.Header.Set("Accept", "application/json")
+		router.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	session := &models.Session{
+		User:        &models.User{Email: "alice@example.com"},
+		AccessToken: "token",
+		Expiry:      time.Now().Add(5 * time.Minute),
+	}
+
+	t.Run("no session", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, renew(nil))
+	})
+
+	t.Run("renewal unsupported", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotImplemented, renew(map[string]*models.Session{"saml": session}))
+	})
+
+	t.Run("renewal failure requires login", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, renew(map[string]*models.Session{"google": session}))
+	})
+}
+
+func TestRenewSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.DefaultConfig()
+	cfg.SetMode(config.ModeServer)
+
+	expiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	provider := models.Provider{Name: "okta", Provider: "okta", Enabled: true}
+	provider.SetClient(&renewProvider{
+		BaseProvider: models.NewBaseProvider("okta", provider),
+		renewed: &models.Session{
+			User:         &models.User{Email: "alice@example.com"},
+			AccessToken:  "renewed",
+			RefreshToken: "refresh",
+			Expiry:       expiry,
+		},
+	})
+
+	cfg.Providers.Definitions = map[string]models.Provider{"okta": provider}
+
+	server := &Server{Config: cfg}
+
+	send := func(path string, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(sessions.SessionsMany(
+			[]string{ThandCookieName, CreateCookieName("okta")}, getSessionStore("secret")))
+		router.POST(path, func(c *gin.Context) {
+			c.Set(SessionContextKey, map[string]*models.Session{
+				"okta": {
+					User:         &models.User{Email: "alice@example.com"},
+					AccessToken:  "expiring",
+					RefreshToken: "refresh",
+					Expiry:       time.Now().Add(time.Minute),
+				},
+			})
+		}, handler)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Accept", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("refresh rewrites the session cookie", func(t *testing.T) {
+		w := send("/auth/refresh", server.postAuthRefresh)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response models.AuthRefreshResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "okta", response.Provider)
+		assert.True(t, expiry.Equal(response.Expiry))
+		assert.Contains(t, w.Header().Get("Set-Cookie"), CreateCookieName("okta"))
+	})
+
+	t.Run("renew returns the session for the CLI", func(t *testing.T) {
+		w := send("/auth/renew", server.postAuthRenew)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response models.AuthRenewResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "okta", response.Provider)
+		assert.True(t, expiry.Equal(response.Expiry))
+		assert.NotEmpty(t, response.Session)
+	})
+}
+
+func TestGetAuthProviders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.DefaultConfig()
+	cfg.SetMode(config.ModeServer)
+
+	newProvider := func(name string, capabilities ...models.ProviderCapability) models.Provider {
+		provider := models.Provider{Name: name, Description: name + " login", Provider: name, Enabled: true}
+		provider.SetClient(models.NewBaseProvider(name, provider, capabilities...))
+		return provider
+	}
+
+	cfg.Providers.Definitions = map[string]models.Provider{
+		"google": newProvider("google", models.ProviderCapabilityAuthorizer),
+		"okta":   newProvider("okta", models.ProviderCapabilityAuthorizer, models.ProviderCapabilityRBAC),
+		"aws":    newProvider("aws", models.ProviderCapabilityRBAC),
+	}
+
+	server := &Server{Config: cfg}
+
+	router := gin.New()
+	router.GET("/auth/providers", server.getAuthProviders)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/providers", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ProvidersResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	assert.ElementsMatch(t, []string{"google", "okta"}, slices.Collect(maps.Keys(response.Providers)))
+	assert.Equal(t, "okta login", response.Providers["okta"].Description)
+	assert.Empty(t, response.Providers["okta"].Capabilities)
+}
+
+func TestSetSessionOrigin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := &Server{Config: config.DefaultConfig()}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/auth/callback/google", nil)
+	c.Request.RemoteAddr = "203.0.113.10:52000"
+	c.Request.Header.Set("User-Agent", "Mozilla/5.0")
+	c.Request.Header.Set("Accept-Language", "en-GB")
+
+	session := &models.Session{User: &models.User{Email: "alice@example.com"}}
+	server.setSessionOrigin(c, "google", session)
+
+	assert.Equal(t, models.NewDeviceFingerprint("Mozilla/5.0", "en-GB"), session.DeviceFingerprint)
+	assert.Equal(t, "203.0.113.10", session.CreatedFromIP)
+	assert.Empty(t, session.CreatedFromCountry, "no GeoIP database is configured")
+}
+
+func TestValidateAuthRedirect(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Server.RootUrl = "https://thand.example.com"
+	cfg.Server.Security.AllowedRedirects = []string{
+		"https://app.example.com/auth/callback",
+		"https://portal.example.com/thand/*",
+	}
+
+	server := &Server{Config: cfg}
+
+	tests := []struct {
+		name     string
+		redirect string
+		allowed  bool
+	}{
+		{"root url", "https://thand.example.com/sessions", true},
+		{"cli loopback", "http://localhost:5225", true},
+		{"cli loopback ip", "http://127.0.0.1:5225", true},
+		{"cli loopback ipv6", "http://[::1]:5225", true},
+		{"exact match", "https://app.example.com/auth/callback", true},
+		{"exact match other path", "https://app.example.com/other", false},
+		{"prefix match", "https://portal.example.com/thand/callback", true},
+		{"prefix other path", "https://portal.example.com/other", false},
+		{"other host", "https://evil.example.net", false},
+		{"suffixed host", "https://thand.example.com.evil.example.net", false},
+		{"credentials", "https://thand.example.com@evil.example.net", false},
+		{"other scheme", "javascript:alert(1)", false},
+		{"scheme relative", "//evil.example.net", false},
+		{"http downgrade", "http://thand.example.com", false},
+		{"path traversal", "https://portal.example.com/thand/../other", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := server.validateAuthRedirect(tt.redirect)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestGetAuthRequestRejectsOpenRedirect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.DefaultConfig()
+	cfg.Server.RootUrl = "https://thand.example.com"
+
+	server := &Server{Config: cfg}
+
+	router := gin.New()
+	router.GET("/auth/request/:provider", server.getAuthRequest)
+	router.POST("/provider/:provider/authorizeSession", server.postProviderAuthorizeSession)
+
+	t.Run("auth request callback", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet,
+			"/auth/request/google?callback=https%3A%2F%2Fevil.example.net", nil)
+		req.Header.Set("Accept", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "not an allowed redirect")
+	})
+
+	t.Run("authorize session redirect uri", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/provider/google/authorizeSession",
+			strings.NewReader(`{"redirect_uri": "https://evil.example.net/callback"}`))
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "not an allowed redirect")
+	})
+}
+
+func TestAuthState(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := &Server{
+		Config:         config.DefaultConfig(),
+		usedAuthStates: newUsedAuthStates(),
+	}
+
+	var started models.AuthWrapper
+
+	router := gin.New()
+	router.Use(sessions.SessionsMany([]string{ThandStateCookieName}, getSessionStore("secret")))
+	router.GET("/start", func(c *gin.Context) {
+		auth, err := server.startAuthState(c, models.NewAuthWrapper("", "client", "google", ""))
+		require.NoError(t, err)
+		started = auth
+	})
+	router.GET("/callback", func(c *gin.Context) {
+		if err := server.consumeAuthState(c, started); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(path string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	start := func() []*http.Cookie {
+		return serve("/start", nil).Result().Cookies()
+	}
+
+	t.Run("valid state", func(t *testing.T) {
+		cookies := start()
+		assert.NotEmpty(t, started.Nonce)
+		assert.False(t, started.IsExpired())
+		assert.Equal(t, http.StatusOK, serve("/callback", cookies).Code)
+	})
+
+	t.Run("replayed state", func(t *testing.T) {
+		cookies := start()
+		assert.Equal(t, http.StatusOK, serve("/callback", cookies).Code)
+
+		// The same browser presenting an old copy of the state cookie
+		w := serve("/callback", cookies)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, ErrAuthStateUnknown.Error(), w.Body.String())
+	})
+
+	t.Run("state from another browser", func(t *testing.T) {
+		start()
+		w := serve("/callback", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, ErrAuthStateUnknown.Error(), w.Body.String())
+	})
+
+	t.Run("expired state", func(t *testing.T) {
+		cookies := start()
+		started.ExpiresAt = time.Now().UTC().Add(-time.Minute)
+
+		w := serve("/callback", cookies)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, ErrAuthStateExpired.Error(), w.Body.String())
+	})
+
+	t.Run("state without a nonce", func(t *testing.T) {
+		cookies := start()
+		started.Nonce = ""
+
+		assert.Equal(t, http.StatusBadRequest, serve("/callback", cookies).Code)
+	})
+}
@@ -10,3 +10,8 @@ func (s *Server) canAcceptHtml(c *gin.Context) bool {
 	accept := c.GetHeader("Accept")
 	return strings.Contains(accept, "text/html")
 }
+
+func canAcceptCsv(c *gin.Context) bool {
+	accept := c.GetHeader("Accept")
+	return strings.Contains(accept, "text/csv")
+}
@@ -0,0 +1,221 @@
+package daemon
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// getDelegationUser returns the authenticated user and the delegation
+// store, or writes the error response
+func (s *Server) getDelegationUser(c *gin.Context) (*models.User, *config.DelegationStore, bool) {
+
+	if !s.Config.IsServer() {
+		s.getErrorPage(c, http.StatusBadRequest, "Delegations are only available in server mode")
+		return nil, nil, false
+	}
+
+	_, foundUser, err := s.getUser(c)
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusUnauthorized, "Unauthorized: unable to get user for delegations", err)
+		return nil, nil, false
+	}
+
+	if foundUser == nil || foundUser.User == nil {
+		s.getErrorPage(c, http.StatusUnauthorized, "Unauthorized: user information is incomplete", nil)
+		return nil, nil, false
+	}
+
+	store, err := s.Config.GetDelegationStore()
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusNotImplemented, "Delegations are not available", err)
+		return nil, nil, false
+	}
+
+	return foundUser.User, store, true
+}
+
+// getDelegations lists the caller's delegations
+//
+//	@Summary		List delegations
+//	@Description	List the delegations that haven't expired where the caller is the approver or the delegate
+//	@Tags			delegations
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	models.DelegationsResponse	"Delegations"
+//	@Failure		401	{object}	map[string]any				"Unauthorized"
+//	@Failure		501	{object}	map[string]any				"Storage service is not configured"
+//	@Router			/delegations [get]
+//	@Security		BearerAuth
+func (s *Server) getDelegations(c *gin.Context) {
+
+	user, store, ok := s.getDelegationUser(c)
+
+	if !ok {
+		return
+	}
+
+	delegations, err := store.List(c.Request.Context(), time.Now())
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusInternalServerError, "Failed to list delegations", err)
+		return
+	}
+
+	identity := user.GetIdentity()
+	response := models.DelegationsResponse{Delegations: []models.Delegation{}}
+
+	for _, delegation := range delegations {
+		if strings.EqualFold(delegation.Approver, identity) || strings.EqualFold(delegation.Delegate, identity) {
+			response.Delegations = append(response.Delegations, delegation)
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// getAllDelegations lists every delegation and removes the expired ones
+//
+//	@Summary		List all delegations
+//	@Description	List every delegation that hasn't expired. Expired delegations are removed. Requires admin access
+//	@Tags			delegations
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	models.DelegationsResponse	"Delegations"
+//	@Failure		401	{object}	map[string]any				"Unauthorized"
+//	@Failure		403	{object}	map[string]any				"Forbidden"
+//	@Failure		501	{object}	map[string]any				"Storage service is not configured"
+//	@Router			/delegations/all [get]
+//	@Security		BearerAuth
+func (s *Server) getAllDelegations(c *gin.Context) {
+
+	user, store, ok := s.getDelegationUser(c)
+
+	if !ok {
+		return
+	}
+
+	if !s.Config.Server.Security.IsAdminUser(user) {
+		s.getErrorPage(c, http.StatusForbidden, "Forbidden: listing all delegations requires admin access")
+		return
+	}
+
+	now := time.Now()
+
+	if removed, err := store.Prune(c.Request.Context(), now); err != nil {
+		logrus.WithError(err).Warn("Failed to remove expired delegations")
+	} else if removed > 0 {
+		logrus.WithField("removed", removed).Info("Removed expired delegations")
+	}
+
+	delegations, err := store.List(c.Request.Context(), now)
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusInternalServerError, "Failed to list delegations", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DelegationsResponse{Delegations: delegations})
+}
+
+// postDelegation delegates the caller's approvals
+//
+//	@Summary		Delegate approvals
+//	@Description	Delegate the caller's approvals to another approver between start and end. The delegate is notified instead of, or as well as, the caller and their approvals are recorded on the caller's behalf
+//	@Tags			delegations
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		models.DelegationRequest	true	"Delegation"
+//	@Success		201		{object}	models.Delegation			"Delegation created"
+//	@Failure		400		{object}	map[string]any				"Invalid delegation"
+//	@Failure		401		{object}	map[string]any				"Unauthorized"
+//	@Failure		501		{object}	map[string]any				"Storage service is not configured"
+//	@Router			/delegations [post]
+//	@Security		BearerAuth
+func (s *Server) postDelegation(c *gin.Context) {
+
+	user, store, ok := s.getDelegationUser(c)
+
+	if !ok {
+		return
+	}
+
+	var request models.DelegationRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		s.getErrorPage(c, http.StatusBadRequest, "Invalid delegation", err)
+		return
+	}
+
+	delegation, err := store.Add(c.Request.Context(), models.Delegation{
+		Approver: user.GetIdentity(),
+		Delegate: strings.TrimSpace(request.Delegate),
+		Start:    request.Start.UTC(),
+		End:      request.End.UTC(),
+		Reason:   request.Reason,
+	}, time.Now())
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusBadRequest, "Invalid delegation", err)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"approver": delegation.Approver,
+		"delegate": delegation.Delegate,
+		"start":    delegation.Start,
+		"end":      delegation.End,
+	}).Info("Approvals delegated")
+
+	c.JSON(http.StatusCreated, delegation)
+}
+
+// deleteDelegation removes one of the caller's delegations. Admins can
+// remove any delegation.
+//
+//	@Summary		Remove delegation
+//	@Description	Remove one of the caller's delegations. Admins can remove any delegation
+//	@Tags			delegations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	string	true	"Delegation ID"
+//	@Success		204	"Delegation removed"
+//	@Failure		401	{object}	map[string]any	"Unauthorized"
+//	@Failure		404	{object}	map[string]any	"Delegation not found"
+//	@Failure		501	{object}	map[string]any	"Storage service is not configured"
+//	@Router			/delegations/{id} [delete]
+//	@Security		BearerAuth
+func (s *Server) deleteDelegation(c *gin.Context) {
+
+	user, store, ok := s.getDelegationUser(c)
+
+	if !ok {
+		return
+	}
+
+	approver := user.GetIdentity()
+
+	if s.Config.Server.Security.IsAdminUser(user) {
+		approver = ""
+	}
+
+	err := store.Remove(c.Request.Context(), c.Param("id"), approver, time.Now())
+
+	if errors.Is(err, config.ErrDelegationNotFound) {
+		s.getErrorPage(c, http.StatusNotFound, "Delegation not found", err)
+		return
+	} else if err != nil {
+		s.getErrorPage(c, http.StatusInternalServerError, "Failed to remove delegation", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,296 @@
+package daemon
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thand-io/agent/internal/models"
+)
+
+const (
+	// Consonants only so codes can't spell words and can't be misread
+	// e.g. 0 and O
+	userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength   = 8
+
+	defaultDeviceCodeExpiry   = 10 * time.Minute
+	defaultDeviceCodeInterval = 5 * time.Second
+
+	// slowDownIncrement is added to the poll interval each time a client
+	// polls too quickly
+	slowDownIncrement = 5 * time.Second
+)
+
+var (
+	errDeviceAuthorizationPending = errors.New(models.DeviceErrorAuthorizationPending)
+	errDeviceSlowDown             = errors.New(models.DeviceErrorSlowDown)
+	errDeviceAccessDenied         = errors.New(models.DeviceErrorAccessDenied)
+	errDeviceExpiredToken         = errors.New(models.DeviceErrorExpiredToken)
+)
+
+type deviceAuthorizationStatus int
+
+const (
+	deviceAuthorizationPending deviceAuthorizationStatus = iota
+	deviceAuthorizationApproved
+	deviceAuthorizationDenied
+)
+
+// deviceAuthorization is a device code waiting for a user to approve it
+// in their browser
+type deviceAuthorization struct {
+	DeviceCode string
+	UserCode   string
+	ExpiresAt  time.Time
+	Interval   time.Duration
+
+	status     deviceAuthorizationStatus
+	lastPolled time.Time
+	provider   string
+	session    *models.LocalSession
+}
+
+// deviceCodes holds the issued device codes in memory. Codes are single
+// use and are removed once the CLI collects the result or they expire.
+type deviceCodes struct {
+	expiry   time.Duration
+	interval time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	byDevice map[string]*deviceAuthorization
+	byUser   map[string]*deviceAuthorization
+}
+
+func newDeviceCodes(expiry time.Duration, interval time.Duration) *deviceCodes {
+
+	if expiry <= 0 {
+		expiry = defaultDeviceCodeExpiry
+	}
+
+	if interval <= 0 {
+		interval = defaultDeviceCodeInterval
+	}
+
+	return &deviceCodes{
+		expiry:   expiry,
+		interval: interval,
+		now:      time.Now,
+		byDevice: map[string]*deviceAuthorization{},
+		byUser:   map[string]*deviceAuthorization{},
+	}
+}
+
+// Issue creates a new device and user code pair
+func (d *deviceCodes) Issue() (deviceAuthorization, error) {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.removeExpired()
+
+	deviceCode, err := newDeviceCode()
+
+	if err != nil {
+		return deviceAuthorization{}, err
+	}
+
+	userCode, err := newUserCode()
+
+	// Retry on the unlikely chance the user code is already in use
+	for err == nil && d.byUser[userCode] != nil {
+		userCode, err = newUserCode()
+	}
+
+	if err != nil {
+		return deviceAuthorization{}, err
+	}
+
+	authorization := &deviceAuthorization{
+		DeviceCode: deviceCode,
+		UserCode:   userCode,
+		ExpiresAt:  d.now().Add(d.expiry),
+		Interval:   d.interval,
+	}
+
+	d.byDevice[deviceCode] = authorization
+	d.byUser[userCode] = authorization
+
+	return *authorization, nil
+}
+
+// Lookup returns the pending authorization for a user code
+func (d *deviceCodes) Lookup(userCode string) (deviceAuthorization, error) {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	authorization, err := d.getPending(userCode)
+
+	if err != nil {
+		return deviceAuthorization{}, err
+	}
+
+	return *authorization, nil
+}
+
+// Approve completes the authorization with the user's session
+func (d *deviceCodes) Approve(userCode string, provider string, session *models.LocalSession) error {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	authorization, err := d.getPending(userCode)
+
+	if err != nil {
+		return err
+	}
+
+	authorization.status = deviceAuthorizationApproved
+	authorization.provider = provider
+	authorization.session = session
+
+	return nil
+}
+
+// Deny rejects the authorization so the CLI stops polling
+func (d *deviceCodes) Deny(userCode string) error {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	authorization, err := d.getPending(userCode)
+
+	if err != nil {
+		return err
+	}
+
+	authorization.status = deviceAuthorizationDenied
+
+	return nil
+}
+
+// Poll returns the session once the user has approved the device code.
+// Polling faster than the interval returns errDeviceSlowDown and
+// increases the interval.
+func (d *deviceCodes) Poll(deviceCode string) (string, *models.LocalSession, error) {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	authorization, found := d.byDevice[deviceCode]
+	now := d.now()
+
+	if !found {
+		return "", nil, errDeviceExpiredToken
+	}
+
+	if now.After(authorization.ExpiresAt) {
+		d.remove(authorization)
+		return "", nil, errDeviceExpiredToken
+	}
+
+	lastPolled := authorization.lastPolled
+	authorization.lastPolled = now
+
+	if !lastPolled.IsZero() && now.Sub(lastPolled) < authorization.Interval {
+		authorization.Interval += slowDownIncrement
+		return "", nil, errDeviceSlowDown
+	}
+
+	switch authorization.status {
+	case deviceAuthorizationApproved:
+		d.remove(authorization)
+		return authorization.provider, authorization.session, nil
+	case deviceAuthorizationDenied:
+		d.remove(authorization)
+		return "", nil, errDeviceAccessDenied
+	default:
+		return "", nil, errDeviceAuthorizationPending
+	}
+}
+
+// GetInterval returns the current poll interval for a device code
+func (d *deviceCodes) GetInterval(deviceCode string) time.Duration {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if authorization, found := d.byDevice[deviceCode]; found {
+		return authorization.Interval
+	}
+
+	return d.interval
+}
+
+func (d *deviceCodes) getPending(userCode string) (*deviceAuthorization, error) {
+
+	authorization, found := d.byUser[normalizeUserCode(userCode)]
+
+	if !found || d.now().After(authorization.ExpiresAt) {
+		return nil, errDeviceExpiredToken
+	}
+
+	if authorization.status != deviceAuthorizationPending {
+		return nil, errors.New("device code has already been used")
+	}
+
+	return authorization, nil
+}
+
+func (d *deviceCodes) remove(authorization *deviceAuthorization) {
+	delete(d.byDevice, authorization.DeviceCode)
+	delete(d.byUser, authorization.UserCode)
+}
+
+func (d *deviceCodes) removeExpired() {
+	now := d.now()
+	for _, authorization := range d.byDevice {
+		if now.After(authorization.ExpiresAt) {
+			d.remove(authorization)
+		}
+	}
+}
+
+func newDeviceCode() (string, error) {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func newUserCode() (string, error) {
+
+	var code strings.Builder
+
+	for range userCodeLength {
+		index, err := rand.Int(rand.Reader, big.NewInt(int64(len(userCodeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code.WriteByte(userCodeAlphabet[index.Int64()])
+	}
+
+	return code.String(), nil
+}
+
+// normalizeUserCode accepts codes typed in lower case or with the
+// separator e.g. bcdf-ghjk
+func normalizeUserCode(userCode string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(userCode))
+}
+
+// formatUserCode splits the user code in half to make it easier to read
+// e.g. BCDF-GHJK
+func formatUserCode(userCode string) string {
+	if len(userCode) != userCodeLength {
+		return userCode
+	}
+	return userCode[:userCodeLength/2] + "-" + userCode[userCodeLength/2:]
+}
@@ -0,0 +1,137 @@
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestDeviceCodes(t *testing.T) {
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newCodes := func() *deviceCodes {
+		codes := newDeviceCodes(time.Minute, 5*time.Second)
+		codes.now = func() time.Time { return now }
+		return codes
+	}
+
+	session := &models.LocalSession{Version: 1, Session: "encoded"}
+
+	t.Run("approved code is single use", func(t *testing.T) {
+		codes := newCodes()
+
+		authorization, err := codes.Issue()
+		require.NoError(t, err)
+		assert.Len(t, authorization.UserCode, userCodeLength)
+
+		_, _, err = codes.Poll(authorization.DeviceCode)
+		assert.ErrorIs(t, err, errDeviceAuthorizationPending)
+
+		// Codes can be entered in lower case with the separator
+		userCode := strings.ToLower(formatUserCode(authorization.UserCode))
+		require.NoError(t, codes.Approve(userCode, "google", session))
+		assert.Error(t, codes.Approve(userCode, "google", session))
+
+		now = now.Add(5 * time.Second)
+		provider, polledSession, err := codes.Poll(authorization.DeviceCode)
+		require.NoError(t, err)
+		assert.Equal(t, "google", provider)
+		assert.Same(t, session, polledSession)
+
+		now = now.Add(5 * time.Second)
+		_, _, err = codes.Poll(authorization.DeviceCode)
+		assert.ErrorIs(t, err, errDeviceExpiredToken)
+	})
+
+	t.Run("polling too quickly slows down", func(t *testing.T) {
+		codes := newCodes()
+
+		authorization, err := codes.Issue()
+		require.NoError(t, err)
+
+		_, _, err = codes.Poll(authorization.DeviceCode)
+		assert.ErrorIs(t, err, errDeviceAuthorizationPending)
+
+		now = now.Add(time.Second)
+		_, _, err = codes.Poll(authorization.DeviceCode)
+		assert.ErrorIs(t, err, errDeviceSlowDown)
+		assert.Equal(t, 10*time.Second, codes.GetInterval(authorization.DeviceCode))
+
+		now = now.Add(10 * time.Second)
+		_, _, err = codes.Poll(authorization.DeviceCode)
+		assert.ErrorIs(t, err, errDeviceAuthorizationPending)
+	})
+
+	t.Run("denied code", func(t *testing.T) {
+		codes := newCodes()
+
+		authorization, err := codes.Issue()
+		require.NoError(t, err)
+		require.NoError(t, codes.Deny(authorization.UserCode))
+
+		_, _, err = codes.Poll(authorization.DeviceCode)
+		assert.ErrorIs(t, err, errDeviceAccessDenied)
+	})
+
+	t.Run("expired code", func(t *testing.T) {
+		codes := newCodes()
+
+		authorization, err := codes.Issue()
+		require.NoError(t, err)
+
+		now = now.Add(2 * time.Minute)
+
+		_, err = codes.Lookup(authorization.UserCode)
+		assert.ErrorIs(t, err, errDeviceExpiredToken)
+		assert.ErrorIs(t, codes.Approve(authorization.UserCode, "google", session), errDeviceExpiredToken)
+
+		_, _, err = codes.Poll(authorization.DeviceCode)
+		assert.ErrorIs(t, err, errDeviceExpiredToken)
+	})
+}
+
+func TestPostDeviceToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := &Server{deviceCodes: newDeviceCodes(time.Minute, time.Hour)}
+
+	router := gin.New()
+	router.POST("/auth/device/token", server.postDeviceToken)
+
+	poll := func(deviceCode string) (int, models.DeviceTokenError) {
+		body, _ := json.Marshal(models.DeviceTokenRequest{DeviceCode: deviceCode})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/auth/device/token", strings.NewReader(string(body)))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		var response models.DeviceTokenError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	authorization, err := server.deviceCodes.Issue()
+	require.NoError(t, err)
+
+	code, response := poll(authorization.DeviceCode)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, models.DeviceErrorAuthorizationPending, response.Error)
+
+	code, response = poll(authorization.DeviceCode)
+	assert.Equal(t, http.StatusTooManyRequests, code)
+	assert.Equal(t, models.DeviceErrorSlowDown, response.Error)
+	assert.Equal(t, int((time.Hour + slowDownIncrement).Seconds()), response.Interval)
+
+	code, response = poll("unknown")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, models.DeviceErrorExpiredToken, response.Error)
+}
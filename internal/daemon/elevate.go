@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -13,7 +14,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/serverlessworkflow/sdk-go/v3/impl/ctx"
 	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/config"
 	"github.com/thand-io/agent/internal/daemon/elevate/llm"
+	"github.com/thand-io/agent/internal/metrics"
 	"github.com/thand-io/agent/internal/models"
 	"github.com/thand-io/agent/internal/workflows/manager"
 )
@@ -60,8 +63,9 @@ func (s *Server) getElevate(c *gin.Context) {
 	}
 
 	s.elevate(c, models.ElevateRequest{
-		Role:       role,
-		Providers:  []string{request.Provider},
+		Role: role,
+		// Multiple providers can be requested at once as a comma separated list
+		Providers:  strings.Split(request.Provider, ","),
 		Identities: request.Identities,
 		Workflow:   primaryWorkflow,
 		Reason:     request.Reason,
@@ -149,12 +153,7 @@ func (s *Server) postElevateJSON(c *gin.Context) {
 
 func (s *Server) handleDynamicRequest(c *gin.Context, dynamicRequest models.ElevateDynamicRequest) {
 
-	// Validate required fields
-	if len(dynamicRequest.Reason) == 0 {
-		s.getErrorPage(c, http.StatusBadRequest, "Reason is required")
-		return
-	}
-
+	// Validate required fields, the reason is checked when elevating
 	if len(dynamicRequest.Providers) == 0 {
 		s.getErrorPage(c, http.StatusBadRequest, "At least one provider must be selected")
 		return
@@ -190,6 +189,12 @@ func (s *Server) handleDynamicRequest(c *gin.Context, dynamicRequest models.Elev
 		Enabled: true,
 	}
 
+	if err := config.ValidateRoleLimits(dynamicRole); err != nil {
+		s.recordRoleLimitExceeded(c, s.getSessionIdentity(c), err)
+		s.getErrorPage(c, http.StatusBadRequest, "Dynamic role exceeds the role limits", err)
+		return
+	}
+
 	// TODO: Convert ElevateDynamicRequest to ElevateRequest
 	// For now, let's create a basic ElevateRequest to integrate with existing workflow
 
@@ -197,7 +202,7 @@ func (s *Server) handleDynamicRequest(c *gin.Context, dynamicRequest models.Elev
 	elevateRequest := models.ElevateRequest{
 		Role:       dynamicRole,
 		Identities: dynamicRequest.Identities,
-		Providers:  dynamicRequest.Providers, // Use first provider for now
+		Providers:  dynamicRequest.Providers,
 		Workflow:   dynamicRequest.Workflow,
 		Reason:     dynamicRequest.Reason,
 		Duration:   dynamicRequest.Duration,
@@ -221,11 +226,6 @@ func (s *Server) elevate(c *gin.Context, request models.ElevateRequest) {
 		return
 	}
 
-	if len(request.Workflow) == 0 {
-		s.getErrorPage(c, http.StatusBadRequest, "No workflow specified for elevation request")
-		return
-	}
-
 	authProvider, foundUser, err := s.getUserFromElevationRequest(c, request)
 
 	if err != nil {
@@ -233,8 +233,12 @@ func (s *Server) elevate(c *gin.Context, request models.ElevateRequest) {
 		return
 	}
 
+	var requestUser *models.User
+
 	if foundUser != nil {
 
+		requestUser = foundUser.User
+
 		exportableSession := &models.ExportableSession{
 			Session:  foundUser,
 			Provider: authProvider,
@@ -250,6 +254,24 @@ func (s *Server) elevate(c *gin.Context, request models.ElevateRequest) {
 		}
 	}
 
+	existing, err := s.prepareElevation(ctx, c, &request, requestUser)
+
+	if err != nil {
+		s.getStatusErrorPage(c, err)
+		return
+	}
+
+	if existing != nil {
+
+		if s.canAcceptHtml(c) {
+			c.Redirect(http.StatusSeeOther, fmt.Sprintf("/execution/%s", url.PathEscape(existing.WorkflowId)))
+		} else {
+			c.JSON(http.StatusOK, existing)
+		}
+
+		return
+	}
+
 	workflowTask, err := s.Workflows.CreateWorkflow(ctx, request)
 
 	if err != nil {
@@ -263,6 +285,203 @@ func (s *Server) elevate(c *gin.Context, request models.ElevateRequest) {
 	)
 }
 
+// prepareElevation validates an elevation request for the user, evaluates
+// its risk flags and policies and checks for a duplicate. Every way of
+// requesting an elevation goes through here before the workflow is created.
+// The running workflow is returned when the request is a duplicate. The
+// gin context is nil when the request didn't come from the API.
+func (s *Server) prepareElevation(
+	ctx context.Context,
+	c *gin.Context,
+	request *models.ElevateRequest,
+	user *models.User,
+) (*models.ElevateResponse, error) {
+
+	decision, err := s.validateElevation(ctx, c, request, user)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if decision.IsDenied() {
+		return nil, newStatusError(http.StatusForbidden, fmt.Sprintf(
+			"Elevation request denied by policy: %s", strings.Join(decision.Messages, "; ")), nil)
+	}
+
+	// A retried or double submitted request returns the workflow the
+	// first one started
+	existing, err := s.Workflows.FindIdempotentWorkflow(ctx, *request)
+
+	if err != nil {
+		return nil, newStatusError(http.StatusInternalServerError, "Failed to check for a duplicate elevation request", err)
+	}
+
+	return existing, nil
+}
+
+// validateElevation checks an elevation request for the user, sets its
+// risk flags and returns the policy decision. The plan endpoint uses it so
+// a plan is checked the same way as the request it previews.
+func (s *Server) validateElevation(
+	ctx context.Context,
+	c *gin.Context,
+	request *models.ElevateRequest,
+	user *models.User,
+) (*models.PolicyDecision, error) {
+
+	if len(request.Workflow) == 0 {
+		return nil, newStatusError(http.StatusBadRequest, "No workflow specified for elevation request", nil)
+	}
+
+	if err := request.ValidateProviders(); err != nil {
+		return nil, newStatusError(http.StatusBadRequest, "Invalid providers for elevation request", err)
+	}
+
+	// Grants routed to another agent's task queue would otherwise wait
+	// until an agent polling it starts
+	if err := s.Config.ValidateTaskQueues(ctx, request.Providers); err != nil {
+		return nil, newStatusError(http.StatusBadRequest, "Providers for elevation request can't be reached", err)
+	}
+
+	if len(request.PublicKey) > 0 {
+		if err := models.ValidatePublicKey(request.PublicKey); err != nil {
+			return nil, newStatusError(http.StatusBadRequest, "Invalid public key for elevation request", err)
+		}
+	}
+
+	// Evaluate guardrail policies before starting the workflow so denied
+	// requests never reach approvers
+	if err := s.Config.CheckReason(user, request.Role, request.Reason); err != nil {
+		s.recordRoleLimitExceeded(c, getUserIdentity(user), err)
+		return nil, newStatusError(http.StatusBadRequest, "Reason does not meet the reason policy", err)
+	}
+
+	if err := s.Config.CheckDuration(user, request.Role, request.Duration); err != nil {
+		s.recordRoleLimitExceeded(c, getUserIdentity(user), err)
+		return nil, newStatusError(http.StatusBadRequest, "Duration is longer than the role allows", err)
+	}
+
+	if err := s.Config.CheckPermissions(user, request.Role); err != nil {
+		s.recordRoleLimitExceeded(c, getUserIdentity(user), err)
+		return nil, newStatusError(http.StatusForbidden, "Role requests actions that are not allowed", err)
+	}
+
+	// Flag anything unusual for approvers and the policies, flags sent by
+	// the client are replaced
+	request.RiskFlags = s.evaluateRiskFlags(ctx, getClientIP(c), *request, user)
+
+	decision, err := s.evaluateElevationPolicies(ctx, *request, user)
+
+	if err != nil {
+		return nil, newStatusError(http.StatusInternalServerError, "Failed to evaluate policies", err)
+	}
+
+	return decision, nil
+}
+
+// evaluateElevationPolicies runs the guardrail policies against a request.
+// The decision is nil when no policies or freeze calendars are configured
+// and the role doesn't require approval.
+func (s *Server) evaluateElevationPolicies(
+	ctx context.Context,
+	request models.ElevateRequest,
+	user *models.User,
+) (*models.PolicyDecision, error) {
+
+	// The change freeze is evaluated with the policies
+	if !s.Config.Policies.HasPolicies() && !s.Config.Freeze.IsEnabled() {
+
+		requiresApproval, err := s.Config.RoleRequiresApproval(user, request.Role)
+
+		if err != nil || !requiresApproval {
+			return nil, err
+		}
+	}
+
+	internalRequest := &models.ElevateRequestInternal{
+		ElevateRequest: request,
+		User:           user,
+	}
+
+	return s.Config.EvaluatePolicies(
+		ctx, models.NewPolicyInput(internalRequest, time.Now()))
+}
+
+// postElevatePlan previews an elevation request without starting a workflow
+//
+//	@Summary		Plan elevation request
+//	@Description	Validate an elevation request and evaluate policies without starting the workflow
+//	@Tags			elevate
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		models.ElevateRequest		true	"Elevation request"
+//	@Success		200		{object}	models.ElevatePlanResponse	"Elevation plan"
+//	@Failure		400		{object}	models.ErrorResponse		"Bad request"
+//	@Failure		401		{object}	models.ErrorResponse		"Unauthorized"
+//	@Failure		403		{object}	models.ErrorResponse		"Forbidden"
+//	@Failure		500		{object}	models.ErrorResponse		"Internal server error"
+//	@Router			/elevate/plan [post]
+//	@Security		BearerAuth
+func (s *Server) postElevatePlan(c *gin.Context) {
+
+	var request models.ElevateRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		s.getErrorPage(c, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	if !request.IsValid() {
+		s.getErrorPage(c, http.StatusBadRequest, "Role and providers are required")
+		return
+	}
+
+	request.Workflow = request.GetWorkflow()
+
+	if len(request.Duration) > 0 {
+		if _, err := request.AsDuration(); err != nil {
+			s.getErrorPage(c, http.StatusBadRequest, "Invalid duration for elevation request", err)
+			return
+		}
+	}
+
+	if len(request.Workflow) > 0 {
+		if _, err := s.Config.GetWorkflowFromElevationRequest(&request); err != nil {
+			s.getErrorPage(c, http.StatusBadRequest, "Invalid workflow for elevation request", err)
+			return
+		}
+	}
+
+	_, foundUser, err := s.getUserFromElevationRequest(c, request)
+
+	if err != nil || foundUser == nil || foundUser.User == nil {
+		s.getErrorPage(c, http.StatusUnauthorized, "Unauthorized: unable to get user for elevation plan", err)
+		return
+	}
+
+	// Self elevate when no identities were set
+	if len(request.Identities) == 0 && len(foundUser.User.Email) > 0 {
+		request.Identities = []string{foundUser.User.Email}
+	}
+
+	decision, err := s.validateElevation(c.Request.Context(), c, &request, foundUser.User)
+
+	if err != nil {
+		s.getStatusErrorPage(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ElevatePlanResponse{
+		Role:       request.Role,
+		Providers:  request.Providers,
+		Workflow:   request.Workflow,
+		Duration:   request.Duration,
+		Identities: request.Identities,
+		Allowed:    !decision.IsDenied(),
+		Decision:   decision,
+	})
+}
+
 // getElevateResume resumes a workflow from a saved state
 //
 //	@Summary		Resume elevation workflow
@@ -344,16 +563,13 @@ func (s *Server) postElevateResume(c *gin.Context) {
 
 }
 
-func (s *Server) getElevateAuthOAuth2(c *gin.Context) {
+func (s *Server) getElevateAuthOAuth2(c *gin.Context, state string, code string) {
 
-	// Ok lets grab the state from the query and then
+	// Ok lets take the state from the callback and then
 	// call the authority to get the user information.
 
 	ctx := context.Background()
 
-	state := c.Query("state")
-	code := c.Query("code")
-
 	if len(state) == 0 {
 		s.getErrorPage(c, http.StatusBadRequest, "State parameter is required")
 		return
@@ -374,19 +590,21 @@ func (s *Server) getElevateAuthOAuth2(c *gin.Context) {
 		return
 	}
 
-	authProviderInstance, err := s.Config.GetProviderByName(authProvider)
+	authorizor, err := s.Config.GetAuthorizor(authProvider)
 
 	if err != nil {
 		s.getErrorPage(c, http.StatusInternalServerError, "Failed to get auth provider", err)
 		return
 	}
 
-	session, err := authProviderInstance.GetClient().CreateSession(ctx, &models.AuthorizeUser{
+	session, err := authorizor.CreateSession(ctx, &models.AuthorizeUser{
 		Code:        code,
 		State:       state,
 		RedirectUri: s.Config.GetAuthCallbackUrl(authProvider),
 	})
 
+	metrics.RecordAuthAttempt(authProvider, err == nil && session != nil && session.User != nil)
+
 	if err != nil {
 		s.getErrorPage(c, http.StatusInternalServerError,
 			"Failed to create session for elevation request", err)
@@ -399,6 +617,8 @@ func (s *Server) getElevateAuthOAuth2(c *gin.Context) {
 		return
 	}
 
+	s.setSessionOrigin(c, authProvider, session)
+
 	// Get the users identity information and role info.
 	fmt.Println("Resuming workflow with state:", state)
 
@@ -413,6 +633,7 @@ func (s *Server) getElevateAuthOAuth2(c *gin.Context) {
 	}, workflowTask.GetRole())
 
 	if err != nil {
+		s.recordRoleLimitExceeded(c, session.User.GetIdentity(), err)
 		s.getErrorPage(c, http.StatusInternalServerError,
 			"Failed to evaluate composite role for elevation request", err)
 		return
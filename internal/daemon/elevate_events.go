@@ -0,0 +1,280 @@
+package daemon
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+const (
+	elevationEventsPollInterval = 2 * time.Second
+	elevationEventsHeartbeat    = 15 * time.Second
+)
+
+// executionFetcher returns the current state of a workflow execution
+type executionFetcher func(ctx context.Context, workflowID string) (*models.WorkflowExecutionInfo, error)
+
+// elevationEvents polls Temporal once per watched elevation and fans the
+// status transitions out to every connected client
+type elevationEvents struct {
+	fetch    executionFetcher
+	interval time.Duration
+
+	mu       sync.Mutex
+	watchers map[string]*elevationWatcher
+}
+
+type elevationWatcher struct {
+	cancel      context.CancelFunc
+	last        *models.ElevationEvent
+	subscribers map[chan *models.ElevationEvent]struct{}
+}
+
+func newElevationEvents(fetch executionFetcher, interval time.Duration) *elevationEvents {
+	return &elevationEvents{
+		fetch:    fetch,
+		interval: interval,
+		watchers: map[string]*elevationWatcher{},
+	}
+}
+
+// Subscribe returns the events for an elevation starting with its current
+// state. The channel is closed once the elevation reaches a terminal state.
+// Call the returned function to unsubscribe.
+func (e *elevationEvents) Subscribe(workflowID string) (<-chan *models.ElevationEvent, func()) {
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	watcher, found := e.watchers[workflowID]
+
+	if !found {
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		watcher = &elevationWatcher{
+			cancel:      cancel,
+			subscribers: map[chan *models.ElevationEvent]struct{}{},
+		}
+		e.watchers[workflowID] = watcher
+
+		go e.watch(ctx, workflowID, watcher)
+	}
+
+	events := make(chan *models.ElevationEvent, 8)
+	watcher.subscribers[events] = struct{}{}
+
+	if watcher.last != nil {
+		events <- watcher.last
+	}
+
+	var once sync.Once
+
+	unsubscribe := func() {
+		once.Do(func() {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+
+			if _, ok := watcher.subscribers[events]; !ok {
+				// Already closed by a terminal event
+				return
+			}
+
+			delete(watcher.subscribers, events)
+			close(events)
+
+			// Stop polling once nobody is listening
+			if len(watcher.subscribers) == 0 && e.watchers[workflowID] == watcher {
+				watcher.cancel()
+				delete(e.watchers, workflowID)
+			}
+		})
+	}
+
+	return events, unsubscribe
+}
+
+func (e *elevationEvents) watch(ctx context.Context, workflowID string, watcher *elevationWatcher) {
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		execution, err := e.fetch(ctx, workflowID)
+
+		if err != nil {
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			logrus.WithError(err).WithField("workflowId", workflowID).
+				Warnln("Failed to get elevation status for event stream")
+
+		} else if e.publish(workflowID, watcher, models.NewElevationEvent(execution)) {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publish sends the event to every subscriber if the state has changed.
+// Returns true once the elevation has reached a terminal state and the
+// subscribers have been closed.
+func (e *elevationEvents) publish(workflowID string, watcher *elevationWatcher, event *models.ElevationEvent) bool {
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if event.IsSameState(watcher.last) {
+		return false
+	}
+
+	watcher.last = event
+
+	for subscriber := range watcher.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			// Slow clients only need the latest state so drop their
+			// oldest event to make room
+			select {
+			case <-subscriber:
+			default:
+			}
+			subscriber <- event
+		}
+	}
+
+	if !event.Status.IsTerminal() {
+		return false
+	}
+
+	for subscriber := range watcher.subscribers {
+		close(subscriber)
+	}
+
+	watcher.subscribers = map[chan *models.ElevationEvent]struct{}{}
+	watcher.cancel()
+
+	if e.watchers[workflowID] == watcher {
+		delete(e.watchers, workflowID)
+	}
+
+	return true
+}
+
+// getElevateEvents streams the status of an elevation as server-sent events
+//
+//	@Summary		Stream elevation status
+//	@Description	Stream status transitions (pending, approved, granted, revoked or denied) of an elevation as server-sent events. Each change is sent as a `status` event and a `heartbeat` event is sent periodically. The stream closes once the elevation reaches a terminal state. Only the requester, its approvers and admins may subscribe.
+//	@Tags			elevate
+//	@Produce		text/event-stream
+//	@Param			id	path		string					true	"Workflow execution ID"
+//	@Success		200	{object}	models.ElevationEvent	"Stream of status events"
+//	@Failure		400	{object}	models.ErrorResponse	"Bad request"
+//	@Failure		401	{object}	models.ErrorResponse	"Unauthorized"
+//	@Failure		403	{object}	models.ErrorResponse	"Forbidden"
+//	@Failure		404	{object}	models.ErrorResponse	"Elevation not found"
+//	@Router			/elevate/{id}/events [get]
+//	@Security		BearerAuth
+func (s *Server) getElevateEvents(c *gin.Context) {
+
+	workflowID := c.Param("id")
+
+	if len(workflowID) == 0 {
+		s.getErrorPage(c, http.StatusBadRequest, "Workflow ID is required")
+		return
+	}
+
+	if !s.Config.GetServices().HasTemporal() {
+		s.getErrorPage(c, http.StatusBadRequest, "Temporal service is not configured")
+		return
+	}
+
+	_, foundUser, err := s.getUser(c)
+
+	if err != nil || foundUser == nil {
+		s.getErrorPage(c, http.StatusUnauthorized, "Unauthorized: unable to get user for elevation events", err)
+		return
+	}
+
+	execution, _, err := s.getWorkflowExecution(c, workflowID)
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusNotFound, "Failed to find elevation", err)
+		return
+	}
+
+	if !s.canFollowElevation(foundUser.User, execution) {
+		s.getErrorPage(c, http.StatusForbidden, "You do not have permission to follow this elevation")
+		return
+	}
+
+	// Streams outlive the server write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logrus.WithError(err).Debugln("Unable to clear write deadline for event stream")
+	}
+
+	events, unsubscribe := s.elevationEvents.Subscribe(workflowID)
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(elevationEventsHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent("status", event)
+			return !event.Status.IsTerminal()
+		case <-heartbeat.C:
+			c.SSEvent("heartbeat", time.Now().UTC())
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// canFollowElevation returns true if the user requested the elevation, was
+// asked to approve it or is an admin
+func (s *Server) canFollowElevation(user *models.User, execution *models.WorkflowExecutionInfo) bool {
+
+	if user == nil || execution == nil {
+		return false
+	}
+
+	if len(user.Email) > 0 && strings.EqualFold(execution.User, user.Email) {
+		return true
+	}
+
+	if s.Config.Server.Security.IsAdminUser(user) {
+		return true
+	}
+
+	return execution.IsApprover(user.Email, user.GetIdentity())
+}
+
+func (s *Server) fetchElevationExecution(ctx context.Context, workflowID string) (*models.WorkflowExecutionInfo, error) {
+	execution, _, err := s.getWorkflowExecution(ctx, workflowID)
+	return execution, err
+}
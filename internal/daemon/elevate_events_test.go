@@ -0,0 +1,155 @@
+package daemon
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// fakeExecutions steps through a sequence of execution states, repeating
+// the last one
+type fakeExecutions struct {
+	mu     sync.Mutex
+	states []*models.WorkflowExecutionInfo
+	calls  int
+}
+
+func (f *fakeExecutions) fetch(ctx context.Context, workflowID string) (*models.WorkflowExecutionInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	state := f.states[min(f.calls, len(f.states)-1)]
+	f.calls++
+	return state, nil
+}
+
+func (f *fakeExecutions) getCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func collectEvents(t *testing.T, events <-chan *models.ElevationEvent) []models.ElevationStatus {
+	t.Helper()
+
+	var statuses []models.ElevationStatus
+	timeout := time.After(2 * time.Second)
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return statuses
+			}
+			statuses = append(statuses, event.Status)
+		case <-timeout:
+			t.Fatalf("event stream did not close, received %v", statuses)
+		}
+	}
+}
+
+func TestElevationEventsFanOut(t *testing.T) {
+	approved := true
+	closed := time.Now()
+
+	pending := &models.WorkflowExecutionInfo{WorkflowID: "wf", Status: "RUNNING"}
+	granted := &models.WorkflowExecutionInfo{WorkflowID: "wf", Status: "RUNNING", Approved: &approved,
+		Context: map[string]any{models.VarsContextProviderAuthorizations: map[string]any{"aws": true}}}
+	revoked := &models.WorkflowExecutionInfo{WorkflowID: "wf", Status: "COMPLETED", Approved: &approved, CloseTime: &closed}
+
+	executions := &fakeExecutions{states: []*models.WorkflowExecutionInfo{
+		pending, pending, granted, granted, revoked,
+	}}
+
+	events := newElevationEvents(executions.fetch, 5*time.Millisecond)
+
+	first, unsubscribeFirst := events.Subscribe("wf")
+	defer unsubscribeFirst()
+	second, unsubscribeSecond := events.Subscribe("wf")
+	defer unsubscribeSecond()
+
+	expected := []models.ElevationStatus{
+		models.ElevationStatusPending,
+		models.ElevationStatusGranted,
+		models.ElevationStatusRevoked,
+	}
+
+	// Both clients share a single poller and only see transitions
+	assert.Equal(t, expected, collectEvents(t, first))
+	assert.Equal(t, expected, collectEvents(t, second))
+	assert.Equal(t, 5, executions.getCalls())
+
+	events.mu.Lock()
+	assert.Empty(t, events.watchers)
+	events.mu.Unlock()
+}
+
+func TestElevationEventsStopPollingWithoutSubscribers(t *testing.T) {
+	executions := &fakeExecutions{states: []*models.WorkflowExecutionInfo{
+		{WorkflowID: "wf", Status: "RUNNING"},
+	}}
+
+	events := newElevationEvents(executions.fetch, 5*time.Millisecond)
+
+	stream, unsubscribe := events.Subscribe("wf")
+
+	select {
+	case event := <-stream:
+		assert.Equal(t, models.ElevationStatusPending, event.Status)
+	case <-time.After(time.Second):
+		t.Fatal("expected the current state")
+	}
+
+	unsubscribe()
+	unsubscribe()
+
+	_, ok := <-stream
+	assert.False(t, ok)
+
+	events.mu.Lock()
+	assert.Empty(t, events.watchers)
+	events.mu.Unlock()
+
+	calls := executions.getCalls()
+	time.Sleep(30 * time.Millisecond)
+	assert.LessOrEqual(t, executions.getCalls(), calls+1)
+}
+
+func TestCanFollowElevation(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Server.Security.Admins = []string{"admin@example.com"}
+
+	server := &Server{Config: cfg}
+
+	execution := &models.WorkflowExecutionInfo{
+		User: "requester@example.com",
+		Context: map[string]any{
+			models.VarsContextApprovers: []any{"approver@example.com"},
+		},
+	}
+
+	tests := []struct {
+		email    string
+		expected bool
+	}{
+		{"requester@example.com", true},
+		{"Requester@Example.com", true},
+		{"approver@example.com", true},
+		{"admin@example.com", true},
+		{"someone@example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			require.Equal(t, tt.expected, server.canFollowElevation(&models.User{Email: tt.email}, execution))
+		})
+	}
+
+	assert.False(t, server.canFollowElevation(nil, execution))
+}
@@ -0,0 +1,148 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/converter"
+)
+
+// riskHistoryTimeout bounds how long the request history query can delay
+// an elevation request
+const riskHistoryTimeout = 5 * time.Second
+
+// riskHistorySize is the number of past requests the flags are based on
+const riskHistorySize = 100
+
+// evaluateRiskFlags flags requests that look unusual for the user. It's best
+// effort, flags that can't be worked out are left unknown and never block
+// the request.
+func (s *Server) evaluateRiskFlags(
+	ctx context.Context,
+	clientIP string,
+	request models.ElevateRequest,
+	user *models.User,
+) *models.RiskFlags {
+
+	country := s.Config.LookupCountry(clientIP)
+
+	var history []models.RequestHistoryEntry
+
+	if user != nil && len(user.Email) > 0 {
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, riskHistoryTimeout)
+		defer cancel()
+
+		entries, err := s.listRequestHistory(timeoutCtx, user.Email)
+
+		if err != nil {
+			logrus.WithError(err).WithField("user", user.Email).
+				Warn("Failed to list request history, risk flags are unknown")
+		} else {
+			history = entries
+		}
+	}
+
+	roleName := ""
+
+	if request.Role != nil {
+		roleName = request.Role.Name
+	}
+
+	flags := models.EvaluateRiskFlags(history, roleName, country, time.Now())
+
+	if flags.IsRaised() {
+		logrus.WithFields(logrus.Fields{
+			"role":  roleName,
+			"flags": flags.GetRaised(),
+		}).Info("Elevation request raised risk flags")
+	}
+
+	return flags
+}
+
+// listRequestHistory lists the user's past elevation requests, most recent
+// first
+func (s *Server) listRequestHistory(ctx context.Context, email string) ([]models.RequestHistoryEntry, error) {
+
+	temporalService := s.Config.GetServices().GetTemporal()
+
+	if temporalService == nil || !temporalService.HasClient() {
+		return nil, fmt.Errorf("temporal service is not configured")
+	}
+
+	if strings.ContainsAny(email, `'"\`) {
+		return nil, fmt.Errorf("%s contains invalid characters", models.VarsContextUser)
+	}
+
+	resp, err := temporalService.GetClient().ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+		Namespace: temporalService.GetNamespace(),
+		PageSize:  riskHistorySize,
+		Query: fmt.Sprintf(
+			"TaskQueue='%s' AND %s='%s'",
+			temporalService.GetTaskQueue(), models.VarsContextUser, email),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	dataConverter := converter.GetDefaultDataConverter()
+	history := make([]models.RequestHistoryEntry, 0, len(resp.Executions))
+
+	for _, exec := range resp.Executions {
+
+		searchAttributes := exec.GetSearchAttributes().GetIndexedFields()
+
+		entry := models.RequestHistoryEntry{
+			StartTime: exec.GetStartTime().AsTime(),
+		}
+
+		if roleAttr, exists := searchAttributes[models.VarsContextRole]; exists && roleAttr != nil {
+			_ = dataConverter.FromPayload(roleAttr, &entry.Role)
+		}
+
+		if approvedAttr, exists := searchAttributes[models.VarsContextApproved]; exists && approvedAttr != nil {
+			_ = dataConverter.FromPayload(approvedAttr, &entry.Approved)
+		}
+
+		if riskFlags := getRiskFlagsFromMemo(exec.GetMemo()); riskFlags != nil {
+			entry.Country = riskFlags.Country
+		}
+
+		history = append(history, entry)
+	}
+
+	// Not every visibility store supports ORDER BY so sort here
+	slices.SortFunc(history, func(a, b models.RequestHistoryEntry) int {
+		return b.StartTime.Compare(a.StartTime)
+	})
+
+	return history, nil
+}
+
+// getRiskFlagsFromMemo returns the risk flags stored in the workflow memo,
+// or nil for requests made before they were recorded
+func getRiskFlagsFromMemo(memo *commonpb.Memo) *models.RiskFlags {
+
+	payload, exists := memo.GetFields()[models.VarsContextRiskFlags]
+
+	if !exists || payload == nil {
+		return nil
+	}
+
+	var riskFlags models.RiskFlags
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &riskFlags); err != nil {
+		logrus.WithError(err).Warn("Failed to decode risk flags from memo")
+		return nil
+	}
+
+	return &riskFlags
+}
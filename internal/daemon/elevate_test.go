@@ -0,0 +1,143 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// newElevateTestConfig returns a server config without policies and with a
+// change freeze that is active now
+func newElevateTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	cfg := config.DefaultConfig()
+	cfg.SetMode(config.ModeServer)
+
+	now := time.Now().UTC()
+	cfg.Freeze = models.FreezeConfig{Calendars: map[string]models.FreezeCalendar{
+		"release": {Windows: []models.FreezeWindow{{
+			Label: "Release",
+			Start: now.AddDate(0, 0, -1).Format(time.DateOnly),
+			End:   now.AddDate(0, 0, 1).Format(time.DateOnly),
+		}}},
+	}}
+	cfg.Workflows.Definitions = map[string]models.Workflow{
+		"default": {Name: "default"},
+	}
+	require.False(t, cfg.Policies.HasPolicies())
+
+	return cfg
+}
+
+func newElevateTestRequest(action models.FreezeAction) models.ElevateRequest {
+	return models.ElevateRequest{
+		Role: &models.Role{
+			Name:           "prod-admin",
+			Workflows:      []string{"default"},
+			Providers:      []string{"aws"},
+			Authenticators: []string{"okta"},
+			Enabled:        true,
+			DuringFreeze:   action,
+		},
+		Providers: []string{"aws"},
+		Workflow:  "default",
+		Reason:    "Deploying a hotfix",
+	}
+}
+
+func TestPrepareElevation_Freeze(t *testing.T) {
+
+	server := &Server{Config: newElevateTestConfig(t)}
+	user := &models.User{Email: "alice@example.com"}
+
+	t.Run("deny blocks the request", func(t *testing.T) {
+		request := newElevateTestRequest(models.FreezeActionDeny)
+
+		_, err := server.prepareElevation(context.Background(), nil, &request, user)
+
+		var statusErr *statusError
+		require.True(t, errors.As(err, &statusErr), err)
+		assert.Equal(t, http.StatusForbidden, statusErr.status)
+		assert.Contains(t, statusErr.message, "requests are blocked during the change freeze: Release")
+	})
+
+	t.Run("roles without during_freeze are allowed", func(t *testing.T) {
+		decision, err := server.evaluateElevationPolicies(
+			context.Background(), newElevateTestRequest(""), user)
+
+		require.NoError(t, err)
+		require.NotNil(t, decision)
+		assert.False(t, decision.IsDenied())
+		assert.True(t, decision.Freeze.Active)
+	})
+}
+
+func TestPostElevatePlan(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := &Server{Config: newElevateTestConfig(t)}
+
+	router := gin.New()
+	router.POST("/elevate/plan", func(c *gin.Context) {
+		c.Set(SessionContextKey, map[string]*models.Session{
+			"okta": {
+				User:   &models.User{Email: "alice@example.com"},
+				Expiry: time.Now().Add(time.Hour),
+			},
+		})
+		server.postElevatePlan(c)
+	})
+
+	plan := func(request models.ElevateRequest) *httptest.ResponseRecorder {
+		body, err := json.Marshal(request)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/elevate/plan", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("allowed", func(t *testing.T) {
+		w := plan(newElevateTestRequest(""))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response models.ElevatePlanResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.True(t, response.Allowed)
+		assert.Equal(t, []string{"alice@example.com"}, response.Identities)
+	})
+
+	t.Run("denied by the freeze", func(t *testing.T) {
+		w := plan(newElevateTestRequest(models.FreezeActionDeny))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response models.ElevatePlanResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.False(t, response.Allowed)
+		require.NotNil(t, response.Decision)
+		assert.Contains(t, response.Decision.Messages, "requests are blocked during the change freeze: Release")
+	})
+
+	t.Run("rejected like the elevate endpoint", func(t *testing.T) {
+		request := newElevateTestRequest("")
+		request.PublicKey = "not a public key"
+
+		w := plan(request)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid public key for elevation request")
+	})
+}
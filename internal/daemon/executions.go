@@ -2,6 +2,7 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -9,10 +10,12 @@ import (
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/gin-gonic/gin"
+	"github.com/serverlessworkflow/sdk-go/v3/model"
 	"github.com/sirupsen/logrus"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config"
 	"github.com/thand-io/agent/internal/models"
+	thandProvider "github.com/thand-io/agent/internal/workflows/tasks/providers/thand"
 
 	"go.temporal.io/api/enums/v1"
 	failurepb "go.temporal.io/api/failure/v1"
@@ -58,7 +61,7 @@ func (t *TemporalFailureInfo) Error() string {
 //	@Tags			executions
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	ExecutionsPageData		"List of workflow executions"
+//	@Success		200	{object}	models.WorkflowExecutionsResponse	"List of workflow executions"
 //	@Failure		400	{object}	map[string]any	"Bad request"
 //	@Failure		401	{object}	map[string]any	"Unauthorized"
 //	@Failure		500	{object}	map[string]any	"Internal server error"
@@ -94,27 +97,13 @@ func (s *Server) listRunningWorkflows(c *gin.Context) {
 		return
 	}
 
-	temporalClient := temporalService.GetClient()
-
-	resp, err := temporalClient.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
-		Namespace: temporalService.GetNamespace(),
-		PageSize:  100,
-		Query:     fmt.Sprintf("TaskQueue='%s' AND user='%s'", temporalService.GetTaskQueue(), foundUser.User.Email),
-		//NextPageToken: nextPageToken,
-	})
+	runningWorkflows, err := s.listUserWorkflows(ctx, foundUser.User.Email, "")
 
 	if err != nil {
 		s.getErrorPage(c, http.StatusInternalServerError, "Failed to list workflows", err)
 		return
 	}
 
-	runningWorkflows := []*models.WorkflowExecutionInfo{}
-
-	for _, exec := range resp.Executions {
-		runningWorkflows = append(
-			runningWorkflows, s.workflowExecutionInfo(exec))
-	}
-
 	if s.canAcceptHtml(c) {
 
 		response := ExecutionsPageData{
@@ -126,15 +115,49 @@ func (s *Server) listRunningWorkflows(c *gin.Context) {
 
 	} else {
 
-		data := struct {
-			Executions []*models.WorkflowExecutionInfo `json:"executions"`
-		}{
+		c.JSON(http.StatusOK, models.WorkflowExecutionsResponse{
 			Executions: runningWorkflows,
-		}
+		})
+	}
+
+}
+
+// listUserWorkflows lists the workflow executions requested by a user. An
+// optional filter is appended to the visibility query
+// e.g. ExecutionStatus='Running'
+func (s *Server) listUserWorkflows(ctx context.Context, email string, filter string) ([]*models.WorkflowExecutionInfo, error) {
+
+	temporalService := s.Config.GetServices().GetTemporal()
+
+	if temporalService == nil || !temporalService.HasClient() {
+		return nil, fmt.Errorf("temporal service is not configured")
+	}
+
+	query := fmt.Sprintf("TaskQueue='%s' AND user='%s'", temporalService.GetTaskQueue(), email)
+
+	if len(filter) > 0 {
+		query = fmt.Sprintf("%s AND %s", query, filter)
+	}
+
+	resp, err := temporalService.GetClient().ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+		Namespace: temporalService.GetNamespace(),
+		PageSize:  100,
+		Query:     query,
+		//NextPageToken: nextPageToken,
+	})
 
-		c.JSON(http.StatusOK, data)
+	if err != nil {
+		return nil, err
+	}
+
+	workflows := []*models.WorkflowExecutionInfo{}
+
+	for _, exec := range resp.Executions {
+		workflows = append(
+			workflows, s.workflowExecutionInfo(exec))
 	}
 
+	return workflows, nil
 }
 
 // createWorkflow creates a new workflow execution
@@ -220,12 +243,32 @@ func (s *Server) getExecutionsPage(c *gin.Context) {
 // getWorkflowExecutionState retrieves the current state of a workflow execution
 // and returns it as ExecutionStatePageData ready for rendering or JSON response
 func (s *Server) getWorkflowExecutionState(c *gin.Context, workflowID string) (*ExecutionStatePageData, error) {
-	ctx := context.Background()
+
+	workflowExecInfo, workflowDef, err := s.getWorkflowExecution(context.Background(), workflowID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data := &ExecutionStatePageData{
+		TemplateData: s.GetTemplateData(c),
+		ExecutionStatePageResponse: ExecutionStatePageResponse{
+			Execution: workflowExecInfo,
+			Workflow:  workflowDef,
+		},
+	}
+
+	return data, nil
+}
+
+// getWorkflowExecution retrieves the current state of a workflow execution
+// along with the workflow definition it was started with
+func (s *Server) getWorkflowExecution(ctx context.Context, workflowID string) (*models.WorkflowExecutionInfo, *model.Workflow, error) {
 
 	temporal := s.Config.GetServices().GetTemporal()
 
 	if temporal == nil || !temporal.HasClient() {
-		return nil, fmt.Errorf("temporal service is not configured")
+		return nil, nil, fmt.Errorf("temporal service is not configured")
 	}
 
 	temporalClient := temporal.GetClient()
@@ -234,13 +277,13 @@ func (s *Server) getWorkflowExecutionState(c *gin.Context, workflowID string) (*
 	wkflw, err := temporalClient.DescribeWorkflowExecution(ctx, workflowID, models.TemporalEmptyRunId)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get workflow state: %w", err)
+		return nil, nil, fmt.Errorf("failed to get workflow state: %w", err)
 	}
 
 	wklwInfo := wkflw.GetWorkflowExecutionInfo()
 
 	if wklwInfo == nil {
-		return nil, fmt.Errorf("workflow execution not found")
+		return nil, nil, fmt.Errorf("workflow execution not found")
 	}
 
 	workflowExecInfo := s.workflowExecutionInfo(wklwInfo)
@@ -267,7 +310,7 @@ func (s *Server) getWorkflowExecutionState(c *gin.Context, workflowID string) (*
 			err = queryResponse.QueryResult.Get(&workflowTask)
 
 			if err != nil {
-				return nil, fmt.Errorf("failed to get workflow state: %w", err)
+				return nil, nil, fmt.Errorf("failed to get workflow state: %w", err)
 			}
 
 			workflowName := workflowTask.WorkflowName
@@ -282,13 +325,13 @@ func (s *Server) getWorkflowExecutionState(c *gin.Context, workflowID string) (*
 
 			}
 
-			// Get the workflow template name if available
-			foundWorkflow, err := s.GetConfig().GetWorkflowByName(workflowName)
+			// Get the workflow definition the workflow was started with
+			workflowDef, err := s.resolveWorkflowDefinition(workflowName, workflowTask.Snapshot)
 
 			if err != nil {
 				logrus.WithError(err).Warn("Failed to get workflow definition")
 			} else {
-				workflowTask.Workflow = foundWorkflow.GetWorkflow()
+				workflowTask.Workflow = workflowDef
 			}
 
 			// Copy over task status phases to the response
@@ -359,19 +402,17 @@ func (s *Server) getWorkflowExecutionState(c *gin.Context, workflowID string) (*
 		if err != nil {
 			logrus.WithError(err).Warnln("Failed to get workflow output")
 			workflowExecInfo.Output = err.Error()
+		} else {
+			workflowExecInfo.Context = workflowTask.Context
 		}
 
 	}
 
-	data := &ExecutionStatePageData{
-		TemplateData: s.GetTemplateData(c),
-		ExecutionStatePageResponse: ExecutionStatePageResponse{
-			Execution: workflowExecInfo,
-			Workflow:  workflowTask.Workflow,
-		},
-	}
+	// Delivery receipts let approvers check a notification was sent
+	workflowExecInfo.Notifications = workflowTask.GetNotificationReceipts()
+	workflowExecInfo.Credentials = workflowTask.GetCredentialArtifacts()
 
-	return data, nil
+	return workflowExecInfo, workflowTask.Workflow, nil
 }
 
 func (s *Server) workflowExecutionInfo(
@@ -497,18 +538,53 @@ func (s *Server) workflowExecutionInfo(
 //	@Produce		json
 //	@Param			id		path		string					true	"Workflow execution ID"
 //	@Param			input	query		string					true	"Encoded signal data"
+//	@Param			Idempotency-Key	header	string			false	"Key to retry the signal with, retries return the original result"
 //	@Success		200		{object}	map[string]any	"Signal sent successfully"
 //	@Failure		400		{object}	map[string]any	"Bad request"
 //	@Failure		401		{object}	map[string]any	"Unauthorized"
 //	@Failure		403		{object}	map[string]any	"Forbidden"
+//	@Failure		422		{object}	map[string]any	"Idempotency key used for a different signal"
 //	@Failure		500		{object}	map[string]any	"Internal server error"
 //	@Router			/execution/{id}/signal [get]
 //	@Security		BearerAuth
 func (s *Server) signalRunningWorkflow(c *gin.Context) {
-
-	workflowId := c.Param("id")
 	// get input from the query parameters
-	input := c.Query("input")
+	s.signalWorkflow(c, c.Param("id"), c.Query("input"))
+}
+
+// postSignalRunningWorkflow sends a signal to a running workflow e.g. an
+// approval or denial using the signed input from an approval notification.
+// Integrations that retry should send an Idempotency-Key header.
+//
+//	@Summary		Signal workflow execution (POST)
+//	@Description	Send a signal event such as an approval or denial to a running workflow execution
+//	@Tags			executions
+//	@Accept			json,x-www-form-urlencoded
+//	@Produce		json
+//	@Param			id		path		string						true	"Workflow execution ID"
+//	@Param			request	body		models.WorkflowSignalRequest	true	"Encoded signal data"
+//	@Param			Idempotency-Key	header	string					false	"Key to retry the signal with, retries return the original result"
+//	@Success		200		{object}	ExecutionStatePageResponse	"Signal sent successfully"
+//	@Failure		400		{object}	models.ErrorResponse		"Bad request"
+//	@Failure		401		{object}	models.ErrorResponse		"Unauthorized"
+//	@Failure		403		{object}	models.ErrorResponse		"Forbidden"
+//	@Failure		422		{object}	models.ErrorResponse		"Idempotency key used for a different signal"
+//	@Failure		500		{object}	models.ErrorResponse		"Internal server error"
+//	@Router			/execution/{id}/signal [post]
+//	@Security		BearerAuth
+func (s *Server) postSignalRunningWorkflow(c *gin.Context) {
+
+	var request models.WorkflowSignalRequest
+
+	if err := c.ShouldBind(&request); err != nil {
+		s.getErrorPage(c, http.StatusBadRequest, "Invalid signal payload", err)
+		return
+	}
+
+	s.signalWorkflow(c, c.Param("id"), request.Input)
+}
+
+func (s *Server) signalWorkflow(c *gin.Context, workflowId string, input string) {
 
 	if len(input) == 0 {
 		s.getErrorPage(c, http.StatusBadRequest, "Input parameter is required")
@@ -520,11 +596,6 @@ func (s *Server) signalRunningWorkflow(c *gin.Context) {
 		return
 	}
 
-	if !s.Config.GetServices().HasTemporal() {
-		s.getErrorPage(c, http.StatusInternalServerError, "Temporal service is not configured", nil)
-		return
-	}
-
 	_, foundUser, err := s.getUser(c)
 
 	if err != nil {
@@ -532,6 +603,24 @@ func (s *Server) signalRunningWorkflow(c *gin.Context) {
 		return
 	}
 
+	userIdentity := foundUser.User.GetIdentity()
+	idempotencyKey := strings.TrimSpace(c.GetHeader(idempotencyKeyHeader))
+
+	// Retries answer with the original result without signalling again
+	reservation, replayed := s.reserveSignal(c, userIdentity, workflowId, idempotencyKey, input)
+
+	if replayed {
+		return
+	}
+
+	// Frees the key for a retry unless the signal is sent
+	defer reservation.Release()
+
+	if !s.Config.GetServices().HasTemporal() {
+		s.getErrorPage(c, http.StatusInternalServerError, "Temporal service is not configured", nil)
+		return
+	}
+
 	// Convert state to cloudevent Signal
 	// Tasks may contain sensitive information, ensure encryption is used
 	decodedTask, err := models.EncodingWrapper{}.DecodeAndDecrypt(input, s.Config.GetServices().GetEncryption())
@@ -559,8 +648,15 @@ func (s *Server) signalRunningWorkflow(c *gin.Context) {
 		return
 	}
 
+	if len(idempotencyKey) > 0 && signal.Type() == thandProvider.ThandApprovalEventType {
+		if err := setApprovalIdempotencyKey(&signal, idempotencyKey); err != nil {
+			s.getErrorPage(c, http.StatusBadRequest, "Failed to set idempotency key on approval", err)
+			return
+		}
+	}
+
 	// Extensions only support basic types so we need to set the user identity as a string
-	signal.SetExtension(models.VarsContextUser, foundUser.User.GetIdentity())
+	signal.SetExtension(models.VarsContextUser, userIdentity)
 
 	if len(signal.FieldErrors) > 0 {
 		logrus.WithField("errors", signal.FieldErrors).
@@ -593,6 +689,54 @@ func (s *Server) signalRunningWorkflow(c *gin.Context) {
 		return
 	}
 
+	reservation.Complete(data)
+
+	s.renderSignalResult(c, data)
+}
+
+// reserveSignal answers a retry of a signal sent with the same idempotency
+// key, waiting for the first request if it's still sending the signal.
+// Otherwise the key is reserved until the signal has been sent. Returns
+// true if the request has been answered.
+func (s *Server) reserveSignal(
+	c *gin.Context,
+	userIdentity string,
+	workflowId string,
+	idempotencyKey string,
+	input string,
+) (*signalReservation, bool) {
+
+	if len(idempotencyKey) == 0 {
+		return nil, false
+	}
+
+	data, reservation, err := s.signalResults.Reserve(
+		c.Request.Context(), userIdentity, workflowId, idempotencyKey, input)
+
+	if errors.Is(err, errIdempotencyKeyReused) {
+		s.getErrorPage(c, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different signal", err)
+		return nil, true
+	} else if err != nil {
+		s.getErrorPage(c, http.StatusConflict, "A signal with this Idempotency-Key is still being sent", err)
+		return nil, true
+	}
+
+	if reservation != nil {
+		return reservation, false
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"workflowId": workflowId,
+		"user":       userIdentity,
+	}).Info("Replaying the result of a signal sent with the same idempotency key")
+
+	s.renderSignalResult(c, data)
+
+	return nil, true
+}
+
+func (s *Server) renderSignalResult(c *gin.Context, data *ExecutionStatePageData) {
+
 	if s.canAcceptHtml(c) {
 
 		s.renderHtml(c, "execution.html", data)
@@ -601,7 +745,27 @@ func (s *Server) signalRunningWorkflow(c *gin.Context) {
 
 		c.JSON(http.StatusOK, data.ExecutionStatePageResponse)
 	}
+}
+
+// setApprovalIdempotencyKey adds the idempotency key to the approval so
+// the approvals task ignores retries that reach the workflow
+func setApprovalIdempotencyKey(signal *cloudevents.Event, idempotencyKey string) error {
+
+	data := map[string]any{}
+
+	if len(signal.Data()) > 0 {
+		if err := signal.DataAs(&data); err != nil {
+			return err
+		}
+	}
+
+	if key, _ := data[models.ApprovalIdempotencyKeyField].(string); len(key) > 0 {
+		return nil
+	}
+
+	data[models.ApprovalIdempotencyKeyField] = idempotencyKey
 
+	return signal.SetData(cloudevents.ApplicationJSON, data)
 }
 
 // extractFailureMessage extracts a human-readable error message from a Temporal Failure
@@ -2,9 +2,12 @@ package daemon
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
@@ -18,7 +21,9 @@ import (
 	"github.com/thand-io/agent/internal/models"
 	taskModel "github.com/thand-io/agent/internal/workflows/tasks/model"
 	thandProvider "github.com/thand-io/agent/internal/workflows/tasks/providers/thand"
+	commonpb "go.temporal.io/api/common/v1"
 	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/converter"
 )
 
 // FormPageData contains data for rendering the form page
@@ -54,6 +59,7 @@ type FormBlock struct {
 type FormTextObject struct {
 	Type     string `json:"type"` // "plain_text" or "mrkdwn"
 	Text     string `json:"text"`
+	HTML     string `json:"html,omitempty"` // Sanitized rendering of mrkdwn text
 	Emoji    bool   `json:"emoji,omitempty"`
 	Verbatim bool   `json:"verbatim,omitempty"`
 }
@@ -82,6 +88,8 @@ type FormElement struct {
 	URL              string              `json:"url,omitempty"`
 	Value            string              `json:"value,omitempty"`
 	Style            string              `json:"style,omitempty"`
+	FileTypes        []string            `json:"filetypes,omitempty"`
+	MaxFiles         int                 `json:"max_files,omitempty"`
 }
 
 // FormOptionObject represents a selectable option
@@ -102,6 +110,9 @@ type FormSubmission struct {
 	WorkflowID string            `json:"workflow_id"`
 	TaskName   string            `json:"task_name"`
 	Values     map[string]string `json:"values"`
+
+	// Files uploaded for file_input elements keyed by action ID
+	Files map[string][]*multipart.FileHeader `json:"-"`
 }
 
 // FormValidationError represents a validation error
@@ -197,8 +208,19 @@ func (s *Server) submitForm(c *gin.Context) {
 	}
 
 	// Parse the form submission
+	maxSize := s.getFormSubmissionLimit(c)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
+
 	var submission FormSubmission
-	if err := c.ShouldBindJSON(&submission); err != nil {
+	if err := s.bindFormSubmission(c, &submission); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, FormSubmissionResponse{
+				Success: false,
+				Message: fmt.Sprintf("Form submission is larger than the %d byte limit", maxSize),
+			})
+			return
+		}
 		c.JSON(http.StatusBadRequest, FormSubmissionResponse{
 			Success: false,
 			Message: "Invalid form data: " + err.Error(),
@@ -229,6 +251,30 @@ func (s *Server) submitForm(c *gin.Context) {
 		return
 	}
 
+	// Store uploaded files, the workflow gets signed URLs rather than the
+	// file contents
+	files, err := s.storeFormFiles(c.Request.Context(), formData, &submission)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to store form files")
+		c.JSON(http.StatusInternalServerError, FormSubmissionResponse{
+			Success: false,
+			Message: "Failed to store uploaded files: " + err.Error(),
+		})
+		return
+	}
+
+	if submission.Values == nil {
+		submission.Values = map[string]string{}
+	}
+
+	for actionID, actionFiles := range files {
+		urls := make([]string, 0, len(actionFiles))
+		for _, file := range actionFiles {
+			urls = append(urls, file.URL)
+		}
+		submission.Values[actionID] = strings.Join(urls, ",")
+	}
+
 	// Create CloudEvent for signaling the workflow
 	event := cloudevents.NewEvent()
 	event.SetID(uuid.New().String())
@@ -250,6 +296,10 @@ func (s *Server) submitForm(c *gin.Context) {
 		"submitted_at": time.Now().UTC().Format(time.RFC3339),
 	}
 
+	if len(files) > 0 {
+		formDataPayload["files"] = files
+	}
+
 	if foundUser != nil && foundUser.User != nil {
 		formDataPayload["submitted_by"] = map[string]string{
 			"email": foundUser.User.Email,
@@ -319,15 +369,11 @@ func (s *Server) getFormDataFromWorkflow(c *gin.Context, workflowID string) (*Fo
 		return nil, fmt.Errorf("no active task in workflow")
 	}
 
-	// Get the workflow definition from config
-	foundWorkflow, err := s.Config.GetWorkflowByName(workflowName)
+	// Get the workflow definition the workflow was started with
+	workflowDef, err := s.resolveWorkflowDefinition(
+		workflowName, getWorkflowSnapshotFromMemo(workflowRun.Memo))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get workflow definition: %w", err)
-	}
-
-	workflowDef := foundWorkflow.GetWorkflow()
-	if workflowDef == nil {
-		return nil, fmt.Errorf("workflow definition is nil")
+		return nil, err
 	}
 
 	// Get form configuration from the workflow definition task
@@ -337,10 +383,16 @@ func (s *Server) getFormDataFromWorkflow(c *gin.Context, workflowID string) (*Fo
 	}
 
 	// Convert Slack blocks to form blocks
-	formBlocks := s.convertSlackBlocksToFormBlocks(formConfig.Blocks)
+	formBlocks, err := s.convertSlackBlocksToFormBlocks(formConfig.Blocks)
+	if err != nil {
+		return nil, err
+	}
 
 	// Marshal blocks to JSON for Alpine.js
-	blocksJSON, _ := json.Marshal(formBlocks)
+	blocksJSON, err := s.getFormSanitizer().marshalBlocks(formBlocks)
+	if err != nil {
+		return nil, err
+	}
 
 	formData := &FormPageData{
 		TemplateData: s.GetTemplateData(c),
@@ -363,6 +415,54 @@ func (s *Server) getFormDataFromWorkflow(c *gin.Context, workflowID string) (*Fo
 	return formData, nil
 }
 
+// getWorkflowSnapshotFromMemo returns the workflow snapshot stored in the
+// workflow memo, or nil for workflows started without one
+func getWorkflowSnapshotFromMemo(memo *commonpb.Memo) *models.WorkflowSnapshot {
+
+	payload, exists := memo.GetFields()[models.TemporalMemoWorkflowSnapshot]
+
+	if !exists || payload == nil {
+		return nil
+	}
+
+	var snapshot models.WorkflowSnapshot
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &snapshot); err != nil {
+		logrus.WithError(err).Warn("Failed to decode workflow snapshot from memo")
+		return nil
+	}
+
+	return &snapshot
+}
+
+// resolveWorkflowDefinition returns the definition pinned by the snapshot,
+// falling back to the current config for workflows started without one
+func (s *Server) resolveWorkflowDefinition(
+	workflowName string,
+	snapshot *models.WorkflowSnapshot,
+) (*model.Workflow, error) {
+
+	if snapshot != nil {
+		workflowDef, err := snapshot.GetWorkflow()
+		if err == nil {
+			return workflowDef, nil
+		}
+		logrus.WithError(err).WithField("workflow", workflowName).
+			Warn("Failed to load workflow snapshot, using current definition")
+	}
+
+	foundWorkflow, err := s.Config.GetWorkflowByName(workflowName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow definition: %w", err)
+	}
+
+	workflowDef := foundWorkflow.GetWorkflow()
+	if workflowDef == nil {
+		return nil, fmt.Errorf("workflow definition is nil")
+	}
+
+	return workflowDef, nil
+}
+
 // FormConfig represents the form configuration from the workflow
 type FormConfig struct {
 	Title       string        `json:"title"`
@@ -421,8 +521,17 @@ func (s *Server) extractFormConfig(workflowDef *model.Workflow, currentTaskName
 	}, nil
 }
 
-// convertSlackBlocksToFormBlocks converts Slack blocks to HTML-friendly form blocks
-func (s *Server) convertSlackBlocksToFormBlocks(blocks []slack.Block) []FormBlock {
+// convertSlackBlocksToFormBlocks converts Slack blocks to HTML-friendly form
+// blocks. Block content is untrusted so it is sanitized, and forms with
+// more blocks than allowed are rejected.
+func (s *Server) convertSlackBlocksToFormBlocks(blocks []slack.Block) ([]FormBlock, error) {
+
+	sanitizer := s.getFormSanitizer()
+
+	if err := sanitizer.checkBlockCount(len(blocks)); err != nil {
+		return nil, err
+	}
+
 	var formBlocks []FormBlock
 
 	for _, block := range blocks {
@@ -432,7 +541,15 @@ func (s *Server) convertSlackBlocksToFormBlocks(blocks []slack.Block) []FormBloc
 		}
 	}
 
-	return formBlocks
+	return sanitizer.sanitizeBlocks(formBlocks), nil
+}
+
+// getFormSanitizer returns the sanitizer for the configured form limits
+func (s *Server) getFormSanitizer() *formSanitizer {
+	if s.Config == nil {
+		return newFormSanitizer(nil)
+	}
+	return newFormSanitizer(&s.Config.Server.Forms)
 }
 
 // convertSlackBlock converts a single Slack block to a FormBlock
@@ -602,6 +719,11 @@ func convertBlockElement(elem slack.BlockElement) *FormElement {
 		formElem.Placeholder = convertTextBlockObject(e.Placeholder)
 		formElem.InitialValue = e.InitialValue
 
+	case *slack.FileInputBlockElement:
+		formElem.ActionID = e.ActionID
+		formElem.FileTypes = e.FileTypes
+		formElem.MaxFiles = e.MaxFiles
+
 	case *slack.ButtonBlockElement:
 		formElem.ActionID = e.ActionID
 		formElem.Text = convertTextBlockObject(e.Text)
@@ -751,6 +873,22 @@ func (s *Server) validateFormSubmission(formData *FormPageData, submission *Form
 			continue
 		}
 
+		// Files are validated separately, the value is set from the stored
+		// files so anything submitted for it is dropped
+		if block.Element.Type == formFileElementType {
+			delete(submission.Values, actionID)
+			files := submission.Files[actionID]
+			if !block.Optional && len(files) == 0 {
+				errors = append(errors, FormValidationError{
+					Field:   actionID,
+					Message: fmt.Sprintf("%s is required", getFormBlockLabel(block)),
+				})
+				continue
+			}
+			errors = append(errors, s.validateFormFiles(block.Element, files)...)
+			continue
+		}
+
 		value, exists := submission.Values[actionID]
 
 		// Check required fields
@@ -796,7 +934,7 @@ func (s *Server) validateFormSubmission(formData *FormPageData, submission *Form
 			}
 
 		case "url_text_input":
-			if !common.IsValidURL(value) {
+			if !isWebURL(value) {
 				errors = append(errors, FormValidationError{
 					Field:   actionID,
 					Message: "Please enter a valid URL",
@@ -820,3 +958,17 @@ func (s *Server) validateFormSubmission(formData *FormPageData, submission *Form
 
 	return errors
 }
+
+// isWebURL returns true for http and https URLs. Other schemes such as
+// javascript: are rejected as they can run when the value is shown as a link.
+func isWebURL(value string) bool {
+	if !common.IsValidURL(value) {
+		return false
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	scheme := strings.ToLower(parsed.Scheme)
+	return (scheme == "http" || scheme == "https") && len(parsed.Host) > 0
+}
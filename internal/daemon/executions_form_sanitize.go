@@ -0,0 +1,282 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// Form blocks come from workflow definitions, which can be loaded from URLs
+// or vaults and edited by more people than run the server. Everything in
+// them is treated as untrusted before it reaches the form page.
+
+var (
+	// Script and style elements are removed with their content
+	formScriptPattern  = regexp.MustCompile(`(?is)<(script|style)\b[^>]*>.*?</(script|style)\s*>`)
+	formCommentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+	// HTML tags but not Slack's <url|text>, <@user> or <!here> syntax
+	formTagPattern = regexp.MustCompile(`</?[a-zA-Z][a-zA-Z0-9-]*(\s[^>]*)?/?>`)
+
+	mrkdwnLinkPattern   = regexp.MustCompile(`<([^<>|\s]+)(?:\|([^<>]+))?>`)
+	mrkdwnCodePattern   = regexp.MustCompile("`([^`\n]+)`")
+	mrkdwnBoldPattern   = regexp.MustCompile(`\*([^*\n]+)\*`)
+	mrkdwnItalicPattern = regexp.MustCompile(`\b_([^_\n]+)_\b`)
+	mrkdwnStrikePattern = regexp.MustCompile(`~([^~\n]+)~`)
+)
+
+// formSanitizer strips markup from form blocks and drops links and images
+// that aren't https or aren't from an allowed host
+type formSanitizer struct {
+	config *models.FormsConfig
+}
+
+func newFormSanitizer(config *models.FormsConfig) *formSanitizer {
+	if config == nil {
+		config = &models.FormsConfig{}
+	}
+	return &formSanitizer{config: config}
+}
+
+// checkBlockCount returns an error if the form has more blocks than allowed
+func (f *formSanitizer) checkBlockCount(count int) error {
+	if maxBlocks := f.config.GetMaxBlocks(); count > maxBlocks {
+		return fmt.Errorf("form has %d blocks, the limit is %d", count, maxBlocks)
+	}
+	return nil
+}
+
+// marshalBlocks returns the blocks as JSON for the page, or an error if
+// they are larger than allowed
+func (f *formSanitizer) marshalBlocks(blocks []FormBlock) ([]byte, error) {
+
+	blocksJSON, err := json.Marshal(blocks)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode form: %w", err)
+	}
+
+	if maxSize := f.config.GetMaxPayloadSize(); len(blocksJSON) > maxSize {
+		return nil, fmt.Errorf("form is %d bytes, the limit is %d bytes", len(blocksJSON), maxSize)
+	}
+
+	return blocksJSON, nil
+}
+
+// sanitizeBlocks cleans every block, dropping any that can't be shown safely
+func (f *formSanitizer) sanitizeBlocks(blocks []FormBlock) []FormBlock {
+
+	sanitized := make([]FormBlock, 0, len(blocks))
+
+	for _, block := range blocks {
+		if f.sanitizeBlock(&block) {
+			sanitized = append(sanitized, block)
+		}
+	}
+
+	return sanitized
+}
+
+func (f *formSanitizer) sanitizeBlock(block *FormBlock) bool {
+
+	f.sanitizeText(block.Text)
+	f.sanitizeText(block.Label)
+	f.sanitizeText(block.Hint)
+	f.sanitizeText(block.Title)
+
+	for _, field := range block.Fields {
+		f.sanitizeText(field)
+	}
+
+	block.AltText = stripHTML(block.AltText)
+
+	if len(block.ImageURL) > 0 {
+		if !f.isSafeURL(block.ImageURL) {
+			logrus.WithField("block_id", block.BlockID).Warn("Dropping form image with an unsafe URL")
+			return false
+		}
+	}
+
+	if block.Element != nil && !f.sanitizeElement(block.Element) {
+		block.Element = nil
+	}
+
+	if block.Accessory != nil && !f.sanitizeElement(block.Accessory) {
+		block.Accessory = nil
+	}
+
+	if block.Elements != nil {
+		elements := make([]*FormElement, 0, len(block.Elements))
+		for _, element := range block.Elements {
+			if element != nil && f.sanitizeElement(element) {
+				elements = append(elements, element)
+			}
+		}
+		block.Elements = elements
+	}
+
+	return true
+}
+
+// sanitizeElement cleans the element, returning false if it should be
+// dropped. Buttons with unsafe links are dropped rather than turned into
+// submit buttons.
+func (f *formSanitizer) sanitizeElement(element *FormElement) bool {
+
+	f.sanitizeText(element.Placeholder)
+	f.sanitizeText(element.Text)
+
+	element.AltText = stripHTML(element.AltText)
+
+	f.sanitizeOption(element.InitialOption)
+
+	for _, option := range element.InitialOptions {
+		f.sanitizeOption(option)
+	}
+
+	for _, option := range element.Options {
+		f.sanitizeOption(option)
+	}
+
+	for _, group := range element.OptionGroups {
+		if group == nil {
+			continue
+		}
+		f.sanitizeText(group.Label)
+		for _, option := range group.Options {
+			f.sanitizeOption(option)
+		}
+	}
+
+	if len(element.ImageURL) > 0 && !f.isSafeURL(element.ImageURL) {
+		logrus.WithField("action_id", element.ActionID).Warn("Dropping form image with an unsafe URL")
+		return false
+	}
+
+	if len(element.URL) > 0 && !f.isSafeURL(element.URL) {
+		logrus.WithField("action_id", element.ActionID).Warn("Dropping form button with an unsafe URL")
+		return false
+	}
+
+	return true
+}
+
+func (f *formSanitizer) sanitizeOption(option *FormOptionObject) {
+	if option == nil {
+		return
+	}
+	f.sanitizeText(option.Text)
+	f.sanitizeText(option.Description)
+}
+
+// sanitizeText strips HTML from the text and renders mrkdwn to the HTML
+// the page shows
+func (f *formSanitizer) sanitizeText(text *FormTextObject) {
+
+	if text == nil {
+		return
+	}
+
+	text.Text = stripHTML(text.Text)
+	text.HTML = ""
+
+	if text.Type == slack.MarkdownType {
+		text.HTML = f.renderMrkdwn(text.Text)
+	}
+}
+
+// isSafeURL returns true for https URLs from an allowed host
+func (f *formSanitizer) isSafeURL(rawURL string) bool {
+
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+
+	if err != nil || !strings.EqualFold(parsed.Scheme, "https") || len(parsed.Hostname()) == 0 {
+		return false
+	}
+
+	return f.config.IsAllowedHost(parsed.Hostname())
+}
+
+// renderMrkdwn renders Slack mrkdwn to HTML using only strong, em, del,
+// code, br and https links. Everything else is escaped.
+func (f *formSanitizer) renderMrkdwn(text string) string {
+
+	var rendered strings.Builder
+
+	last := 0
+
+	for _, match := range mrkdwnLinkPattern.FindAllStringSubmatchIndex(text, -1) {
+
+		rendered.WriteString(renderMrkdwnText(text[last:match[0]]))
+		last = match[1]
+
+		target := text[match[2]:match[3]]
+		label := target
+		if match[4] >= 0 {
+			label = text[match[4]:match[5]]
+		}
+
+		if !f.isSafeURL(target) {
+			// Mentions, channels and unsafe links are shown as text
+			rendered.WriteString(html.EscapeString(label))
+			continue
+		}
+
+		fmt.Fprintf(&rendered, `<a href="%s" target="_blank" rel="noopener noreferrer">%s</a>`,
+			html.EscapeString(target), html.EscapeString(label))
+	}
+
+	rendered.WriteString(renderMrkdwnText(text[last:]))
+
+	return rendered.String()
+}
+
+// renderMrkdwnText renders the text between links, leaving code spans
+// unformatted
+func renderMrkdwnText(text string) string {
+
+	var rendered strings.Builder
+
+	last := 0
+
+	for _, match := range mrkdwnCodePattern.FindAllStringSubmatchIndex(text, -1) {
+		rendered.WriteString(renderMrkdwnFormatting(text[last:match[0]]))
+		rendered.WriteString("<code>" + html.EscapeString(text[match[2]:match[3]]) + "</code>")
+		last = match[1]
+	}
+
+	rendered.WriteString(renderMrkdwnFormatting(text[last:]))
+
+	return rendered.String()
+}
+
+// renderMrkdwnFormatting escapes the text before adding the formatting tags
+// so nothing in the text can become markup
+func renderMrkdwnFormatting(text string) string {
+	escaped := html.EscapeString(text)
+	escaped = mrkdwnBoldPattern.ReplaceAllString(escaped, "<strong>$1</strong>")
+	escaped = mrkdwnItalicPattern.ReplaceAllString(escaped, "<em>$1</em>")
+	escaped = mrkdwnStrikePattern.ReplaceAllString(escaped, "<del>$1</del>")
+	return strings.ReplaceAll(escaped, "\n", "<br>")
+}
+
+// stripHTML removes HTML tags, comments and script content from the text.
+// Stripping repeats so nested tags like <scr<script>ipt> can't rebuild a
+// tag.
+func stripHTML(text string) string {
+	for {
+		stripped := formScriptPattern.ReplaceAllString(text, "")
+		stripped = formCommentPattern.ReplaceAllString(stripped, "")
+		stripped = formTagPattern.ReplaceAllString(stripped, "")
+		if stripped == text {
+			return stripped
+		}
+		text = stripped
+	}
+}
@@ -0,0 +1,368 @@
+package daemon
+
+import (
+	"bytes"
+	"maps"
+	"mime/multipart"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func newFormTestServer(forms models.FormsConfig) *Server {
+	cfg := &config.Config{}
+	cfg.Server.Forms = forms
+	return &Server{Config: cfg}
+}
+
+func TestStripHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"plain text", "Approve access?", "Approve access?"},
+		{"script removed with content", "Hi<script>alert(1)</script> there", "Hi there"},
+		{"event handler", `<img src=x onerror="alert(1)">Hello`, "Hello"},
+		{"nested tags", "<scr<script>ipt>alert(1)</script>", "<scr"},
+		{"comment", "a<!-- <script> -->b", "ab"},
+		{"slack link kept", "<https://example.com|docs>", "<https://example.com|docs>"},
+		{"slack mention kept", "<@U123> and <!here>", "<@U123> and <!here>"},
+		{"comparison kept", "1 < 2 > 0", "1 < 2 > 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, stripHTML(tt.text))
+		})
+	}
+}
+
+func TestRenderMrkdwn(t *testing.T) {
+	sanitizer := newFormSanitizer(&models.FormsConfig{})
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"formatting", "*bold* _italic_ ~gone~", "<strong>bold</strong> <em>italic</em> <del>gone</del>"},
+		{"code is not formatted", "run `rm *.tmp*` now", "run <code>rm *.tmp*</code> now"},
+		{"newlines", "one\ntwo", "one<br>two"},
+		{"snake case", "use snake_case_names", "use snake_case_names"},
+		{"escapes markup", `<b onclick="x">&`, "&lt;b onclick=&#34;x&#34;&gt;&amp;"},
+		{
+			"https link",
+			"<https://example.com/docs?a=1&b=2|the docs>",
+			`<a href="https://example.com/docs?a=1&amp;b=2" target="_blank" rel="noopener noreferrer">the docs</a>`,
+		},
+		{"javascript link", "<javascript:alert(1)|click me>", "click me"},
+		{"http link", "<http://example.com|insecure>", "insecure"},
+		{"mention", "<@U123>", "@U123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sanitizer.renderMrkdwn(tt.text))
+		})
+	}
+}
+
+func TestFormsConfigIsAllowedHost(t *testing.T) {
+	forms := models.FormsConfig{AllowedHosts: []string{"cdn.example.com", "*.thand.io"}}
+
+	assert.True(t, forms.IsAllowedHost("cdn.example.com"))
+	assert.True(t, forms.IsAllowedHost("CDN.example.com"))
+	assert.True(t, forms.IsAllowedHost("images.thand.io"))
+	assert.False(t, forms.IsAllowedHost("thand.io"))
+	assert.False(t, forms.IsAllowedHost("evil.com"))
+	assert.False(t, forms.IsAllowedHost("cdn.example.com.evil.com"))
+
+	assert.True(t, (&models.FormsConfig{}).IsAllowedHost("anything.example.com"))
+}
+
+func TestFormsConfigIsAllowedFileType(t *testing.T) {
+	forms := models.FormsConfig{AllowedFileTypes: []string{"application/pdf", "image/*"}}
+
+	assert.True(t, forms.IsAllowedFileType("application/pdf"))
+	assert.True(t, forms.IsAllowedFileType("image/webp"))
+	assert.True(t, forms.IsAllowedFileType("IMAGE/PNG"))
+	assert.False(t, forms.IsAllowedFileType("text/html"))
+	assert.False(t, forms.IsAllowedFileType("imagex/png"))
+
+	defaults := models.FormsConfig{}
+	assert.True(t, defaults.IsAllowedFileType("text/plain"))
+	assert.False(t, defaults.IsAllowedFileType("text/html"))
+}
+
+func TestConvertSlackBlocksToFormBlocks(t *testing.T) {
+
+	t.Run("text is sanitized", func(t *testing.T) {
+		server := newFormTestServer(models.FormsConfig{})
+
+		blocks, err := server.convertSlackBlocksToFormBlocks([]slack.Block{
+			slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Access <script>alert(1)</script>", false, false)),
+			slack.NewSectionBlock(
+				slack.NewTextBlockObject(slack.MarkdownType, "*Why?* <img src=x onerror=alert(1)><javascript:alert(1)|docs>", false, false),
+				[]*slack.TextBlockObject{slack.NewTextBlockObject(slack.PlainTextType, "<b>field</b>", false, false)},
+				nil,
+			),
+			slack.NewInputBlock("reason",
+				slack.NewTextBlockObject(slack.PlainTextType, "<i>Reason</i>", false, false),
+				nil,
+				slack.NewURLTextInputBlockElement(
+					slack.NewTextBlockObject(slack.PlainTextType, `"><script>x</script>`, false, false), "ticket")),
+		})
+		require.NoError(t, err)
+		require.Len(t, blocks, 3)
+
+		assert.Equal(t, "Access ", blocks[0].Text.Text)
+		assert.Empty(t, blocks[0].Text.HTML)
+
+		assert.Equal(t, "*Why?* <javascript:alert(1)|docs>", blocks[1].Text.Text)
+		assert.Equal(t, "<strong>Why?</strong> docs", blocks[1].Text.HTML)
+		assert.Equal(t, "field", blocks[1].Fields[0].Text)
+
+		assert.Equal(t, "Reason", blocks[2].Label.Text)
+		assert.Equal(t, `">`, blocks[2].Element.Placeholder.Text)
+	})
+
+	t.Run("unsafe urls are dropped", func(t *testing.T) {
+		server := newFormTestServer(models.FormsConfig{AllowedHosts: []string{"cdn.example.com"}})
+
+		button := func(actionID, url string) *slack.ButtonBlockElement {
+			return slack.NewButtonBlockElement(actionID, "", slack.NewTextBlockObject(slack.PlainTextType, "Open", false, false)).WithURL(url)
+		}
+
+		blocks, err := server.convertSlackBlocksToFormBlocks([]slack.Block{
+			slack.NewImageBlock("javascript:alert(1)", "bad", "script", nil),
+			slack.NewImageBlock("http://cdn.example.com/logo.png", "insecure", "http", nil),
+			slack.NewImageBlock("https://evil.com/logo.png", "other host", "host", nil),
+			slack.NewImageBlock("https://cdn.example.com/logo.png", "logo", "allowed", nil),
+			slack.NewSectionBlock(
+				slack.NewTextBlockObject(slack.PlainTextType, "Runbook", false, false), nil,
+				slack.NewAccessory(button("runbook", "javascript:alert(1)")),
+			),
+			slack.NewActionBlock("actions",
+				button("data", "data:text/html,<script>alert(1)</script>"),
+				button("docs", "https://cdn.example.com/docs"),
+				slack.NewButtonBlockElement("approve", "yes", slack.NewTextBlockObject(slack.PlainTextType, "Approve", false, false)),
+			),
+		})
+		require.NoError(t, err)
+		require.Len(t, blocks, 3)
+
+		assert.Equal(t, "allowed", blocks[0].BlockID)
+		assert.Nil(t, blocks[1].Accessory)
+
+		actionIDs := []string{}
+		for _, element := range blocks[2].Elements {
+			actionIDs = append(actionIDs, element.ActionID)
+		}
+		assert.Equal(t, []string{"docs", "approve"}, actionIDs)
+	})
+
+	t.Run("file input", func(t *testing.T) {
+		server := newFormTestServer(models.FormsConfig{})
+
+		blocks, err := server.convertSlackBlocksToFormBlocks([]slack.Block{
+			slack.NewInputBlock("evidence",
+				slack.NewTextBlockObject(slack.PlainTextType, "Evidence", false, false),
+				nil,
+				slack.NewFileInputBlockElement("evidence_files").WithFileTypes("pdf", "png").WithMaxFiles(2)),
+		})
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+
+		assert.Equal(t, "file_input", blocks[0].Element.Type)
+		assert.Equal(t, "evidence_files", blocks[0].Element.ActionID)
+		assert.Equal(t, []string{"pdf", "png"}, blocks[0].Element.FileTypes)
+		assert.Equal(t, 2, blocks[0].Element.MaxFiles)
+	})
+
+	t.Run("too many blocks", func(t *testing.T) {
+		server := newFormTestServer(models.FormsConfig{MaxBlocks: 2})
+
+		_, err := server.convertSlackBlocksToFormBlocks([]slack.Block{
+			slack.NewDividerBlock(), slack.NewDividerBlock(), slack.NewDividerBlock(),
+		})
+		assert.EqualError(t, err, "form has 3 blocks, the limit is 2")
+	})
+}
+
+func TestFormSanitizerMarshalBlocks(t *testing.T) {
+	sanitizer := newFormSanitizer(&models.FormsConfig{MaxPayloadSize: 1024})
+
+	_, err := sanitizer.marshalBlocks([]FormBlock{{Type: "section", Text: &FormTextObject{Type: "plain_text", Text: "ok"}}})
+	assert.NoError(t, err)
+
+	_, err = sanitizer.marshalBlocks([]FormBlock{{Type: "section", Text: &FormTextObject{Type: "plain_text", Text: strings.Repeat("a", 2048)}}})
+	assert.ErrorContains(t, err, "the limit is 1024 bytes")
+}
+
+func TestValidateFormSubmissionURLs(t *testing.T) {
+	server := newFormTestServer(models.FormsConfig{})
+
+	formData := &FormPageData{
+		TaskName: "ticket",
+		Blocks: []FormBlock{{
+			Type:    "input",
+			Label:   &FormTextObject{Type: "plain_text", Text: "Ticket"},
+			Element: &FormElement{Type: "url_text_input", ActionID: "ticket_url"},
+		}},
+	}
+
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"https://tickets.example.com/123", true},
+		{"http://tickets.example.com/123", true},
+		{"javascript:alert(1)", false},
+		{"data:text/html,<script>alert(1)</script>", false},
+		{"/relative/path", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			errors := server.validateFormSubmission(formData, &FormSubmission{
+				TaskName: "ticket",
+				Values:   map[string]string{"ticket_url": tt.value},
+			})
+			if tt.valid {
+				assert.Empty(t, errors)
+			} else {
+				require.Len(t, errors, 1)
+				assert.Equal(t, "Please enter a valid URL", errors[0].Message)
+			}
+		})
+	}
+}
+
+// newFormFiles returns the files as they're read from a multipart submission
+func newFormFiles(t *testing.T, field string, files map[string][]byte) []*multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		part, err := writer.CreateFormFile(field, name)
+		require.NoError(t, err)
+		_, err = part.Write(files[name])
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	t.Cleanup(func() { form.RemoveAll() })
+
+	return form.File[field]
+}
+
+func TestValidateFormSubmissionFiles(t *testing.T) {
+	server := newFormTestServer(models.FormsConfig{MaxUploadSize: 1024})
+
+	pdf := []byte("%PDF-1.4 evidence")
+	png := []byte("\x89PNG\r\n\x1a\n evidence")
+	html := []byte("<html><script>alert(1)</script></html>")
+
+	newFormData := func(element *FormElement, optional bool) *FormPageData {
+		return &FormPageData{
+			TaskName: "evidence",
+			Blocks: []FormBlock{{
+				Type:     "input",
+				Label:    &FormTextObject{Type: "plain_text", Text: "Evidence"},
+				Optional: optional,
+				Element:  element,
+			}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		element  *FormElement
+		optional bool
+		files    map[string][]byte
+		message  string
+	}{
+		{
+			name:    "allowed files",
+			element: &FormElement{Type: "file_input", ActionID: "evidence"},
+			files:   map[string][]byte{"report.pdf": pdf, "screenshot.png": png},
+		},
+		{
+			name:    "required",
+			element: &FormElement{Type: "file_input", ActionID: "evidence"},
+			message: "Evidence is required",
+		},
+		{
+			name:     "optional",
+			element:  &FormElement{Type: "file_input", ActionID: "evidence"},
+			optional: true,
+		},
+		{
+			name:    "too large",
+			element: &FormElement{Type: "file_input", ActionID: "evidence"},
+			files:   map[string][]byte{"large.pdf": append(pdf, bytes.Repeat([]byte("a"), 1024)...)},
+			message: "large.pdf is larger than the 1024 byte limit",
+		},
+		{
+			name:    "type is read from the content",
+			element: &FormElement{Type: "file_input", ActionID: "evidence"},
+			files:   map[string][]byte{"page.pdf": html},
+			message: "page.pdf is not an allowed file type",
+		},
+		{
+			name:    "element file types",
+			element: &FormElement{Type: "file_input", ActionID: "evidence", FileTypes: []string{"pdf"}},
+			files:   map[string][]byte{"screenshot.png": png},
+			message: "screenshot.png must be one of pdf",
+		},
+		{
+			name:    "element max files",
+			element: &FormElement{Type: "file_input", ActionID: "evidence", MaxFiles: 1},
+			files:   map[string][]byte{"report.pdf": pdf, "screenshot.png": png},
+			message: "At most 1 files can be uploaded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			submission := &FormSubmission{
+				TaskName: "evidence",
+				Values:   map[string]string{"evidence": "https://evil.example.com/forged.pdf"},
+				Files:    map[string][]*multipart.FileHeader{},
+			}
+			if len(tt.files) > 0 {
+				submission.Files["evidence"] = newFormFiles(t, "evidence", tt.files)
+			}
+
+			errors := server.validateFormSubmission(newFormData(tt.element, tt.optional), submission)
+
+			if len(tt.message) == 0 {
+				assert.Empty(t, errors)
+			} else {
+				require.Len(t, errors, 1)
+				assert.Equal(t, tt.message, errors[0].Message)
+			}
+
+			// Values for file inputs only come from stored files
+			assert.NotContains(t, submission.Values, "evidence")
+		})
+	}
+}
+
+func TestGetSafeFileName(t *testing.T) {
+	assert.Equal(t, "report.pdf", getSafeFileName("report.pdf"))
+	assert.Equal(t, "passwd", getSafeFileName("../../etc/passwd"))
+	assert.Equal(t, "my_report_v2_.pdf", getSafeFileName("my report (v2).pdf"))
+	assert.Equal(t, "file", getSafeFileName(".."))
+}
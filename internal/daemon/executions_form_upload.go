@@ -0,0 +1,324 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// formFileElementType is the Slack block element for file uploads. Slack
+// only supports it in modals, the HTML form supports it everywhere.
+const formFileElementType = "file_input"
+
+// formSubmissionPayloadField is the multipart field holding the JSON
+// submission, files are sent under the action ID of their element
+const formSubmissionPayloadField = "payload"
+
+var unsafeFileNameCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// FormFile is a file uploaded with a form. It's passed to the workflow with
+// a signed URL the file can be downloaded from.
+type FormFile struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Key         string `json:"key"`
+	URL         string `json:"url"`
+}
+
+// getFormsConfig returns the configured form limits
+func (s *Server) getFormsConfig() *models.FormsConfig {
+	if s.Config == nil {
+		return &models.FormsConfig{}
+	}
+	return &s.Config.Server.Forms
+}
+
+// isMultipartForm returns true if the form was submitted with files
+func isMultipartForm(c *gin.Context) bool {
+	return strings.HasPrefix(c.ContentType(), "multipart/form-data")
+}
+
+// getFormSubmissionLimit returns the largest request body allowed for the
+// submission, multipart submissions can also carry files
+func (s *Server) getFormSubmissionLimit(c *gin.Context) int64 {
+
+	forms := s.getFormsConfig()
+	limit := int64(forms.GetMaxPayloadSize())
+
+	if isMultipartForm(c) {
+		limit += int64(forms.GetMaxUploadSize()) * models.FormMaxUploadFiles
+	}
+
+	return limit
+}
+
+// bindFormSubmission reads a JSON submission, or a multipart submission
+// with the JSON in the payload field and the uploaded files
+func (s *Server) bindFormSubmission(c *gin.Context, submission *FormSubmission) error {
+
+	if !isMultipartForm(c) {
+		return c.ShouldBindJSON(submission)
+	}
+
+	// Files larger than the payload limit are kept on disk while parsing
+	if err := c.Request.ParseMultipartForm(int64(s.getFormsConfig().GetMaxPayloadSize())); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(c.Request.FormValue(formSubmissionPayloadField)), submission); err != nil {
+		return fmt.Errorf("invalid %s field: %w", formSubmissionPayloadField, err)
+	}
+
+	submission.Files = c.Request.MultipartForm.File
+
+	return nil
+}
+
+// validateFormFiles checks the files uploaded for a file_input element are
+// within the count and size limits and of an allowed type
+func (s *Server) validateFormFiles(element *FormElement, files []*multipart.FileHeader) []FormValidationError {
+
+	var errors []FormValidationError
+
+	forms := s.getFormsConfig()
+
+	maxFiles := models.FormMaxUploadFiles
+	if element.MaxFiles > 0 {
+		maxFiles = min(element.MaxFiles, maxFiles)
+	}
+
+	if len(files) > maxFiles {
+		errors = append(errors, FormValidationError{
+			Field:   element.ActionID,
+			Message: fmt.Sprintf("At most %d files can be uploaded", maxFiles),
+		})
+		return errors
+	}
+
+	for _, file := range files {
+
+		if file.Size > int64(forms.GetMaxUploadSize()) {
+			errors = append(errors, FormValidationError{
+				Field:   element.ActionID,
+				Message: fmt.Sprintf("%s is larger than the %d byte limit", file.Filename, forms.GetMaxUploadSize()),
+			})
+			continue
+		}
+
+		if len(element.FileTypes) > 0 {
+			extension := strings.ToLower(strings.TrimPrefix(filepath.Ext(file.Filename), "."))
+			if !slices.ContainsFunc(element.FileTypes, func(fileType string) bool {
+				return strings.EqualFold(strings.TrimPrefix(fileType, "."), extension)
+			}) {
+				errors = append(errors, FormValidationError{
+					Field:   element.ActionID,
+					Message: fmt.Sprintf("%s must be one of %s", file.Filename, strings.Join(element.FileTypes, ", ")),
+				})
+				continue
+			}
+		}
+
+		contentType, err := detectFormFileType(file)
+
+		if err != nil || !forms.IsAllowedFileType(contentType) {
+			errors = append(errors, FormValidationError{
+				Field:   element.ActionID,
+				Message: fmt.Sprintf("%s is not an allowed file type", file.Filename),
+			})
+		}
+	}
+
+	return errors
+}
+
+// detectFormFileType returns the MIME type from the file's content, the
+// type sent by the browser isn't trusted
+func detectFormFileType(file *multipart.FileHeader) (string, error) {
+
+	data, err := readFormFile(file, 512)
+
+	if err != nil {
+		return "", err
+	}
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+
+	if err != nil {
+		return "", err
+	}
+
+	return mediaType, nil
+}
+
+func readFormFile(file *multipart.FileHeader, limit int64) ([]byte, error) {
+
+	opened, err := file.Open()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer opened.Close()
+
+	return io.ReadAll(io.LimitReader(opened, limit))
+}
+
+// storeFormFiles uploads the validated files of each file_input element to
+// the storage service and returns them with their signed URLs
+func (s *Server) storeFormFiles(
+	ctx context.Context,
+	formData *FormPageData,
+	submission *FormSubmission,
+) (map[string][]FormFile, error) {
+
+	stored := map[string][]FormFile{}
+
+	if len(submission.Files) == 0 {
+		return stored, nil
+	}
+
+	services := s.Config.GetServices()
+
+	if !services.HasStorage() {
+		return nil, fmt.Errorf("file uploads need the storage service to be configured")
+	}
+
+	storage := services.GetStorage()
+	forms := s.getFormsConfig()
+
+	for _, block := range formData.Blocks {
+
+		if block.Type != "input" || block.Element == nil || block.Element.Type != formFileElementType {
+			continue
+		}
+
+		actionID := block.Element.ActionID
+
+		for _, file := range submission.Files[actionID] {
+
+			data, err := readFormFile(file, int64(forms.GetMaxUploadSize()))
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", file.Filename, err)
+			}
+
+			contentType, err := detectFormFileType(file)
+
+			if err != nil {
+				return nil, err
+			}
+
+			key := path.Join("forms", submission.WorkflowID, uuid.New().String(), getSafeFileName(file.Filename))
+
+			if err := storage.PutObject(ctx, key, contentType, data); err != nil {
+				return nil, err
+			}
+
+			signedURL, err := storage.GetSignedURL(ctx, key, forms.GetUploadURLExpiry())
+
+			if err != nil {
+				return nil, err
+			}
+
+			stored[actionID] = append(stored[actionID], FormFile{
+				Name:        file.Filename,
+				ContentType: contentType,
+				Size:        file.Size,
+				Key:         key,
+				URL:         s.getStorageURL(signedURL),
+			})
+		}
+	}
+
+	return stored, nil
+}
+
+// getStorageURL makes URLs served by the agent, rather than the store,
+// absolute
+func (s *Server) getStorageURL(signedURL string) string {
+
+	if strings.Contains(signedURL, "://") {
+		return signedURL
+	}
+
+	return fmt.Sprintf("%s/%s/%s",
+		s.Config.GetRootUrl(),
+		strings.TrimPrefix(s.Config.GetApiBasePath(), "/"),
+		signedURL,
+	)
+}
+
+// getSafeFileName keeps the file name readable in the object key without
+// letting it add path segments
+func getSafeFileName(fileName string) string {
+
+	fileName = unsafeFileNameCharacters.ReplaceAllString(filepath.Base(fileName), "_")
+	fileName = strings.Trim(fileName, "._")
+
+	if len(fileName) == 0 {
+		return "file"
+	}
+
+	return fileName
+}
+
+// getStorageObject serves objects from stores whose signed URLs are served
+// by the agent e.g. local storage. The signature is the authorization.
+func (s *Server) getStorageObject(c *gin.Context) {
+
+	services := s.Config.GetServices()
+
+	if !services.HasStorage() {
+		s.getErrorPage(c, http.StatusNotFound, "Storage is not configured")
+		return
+	}
+
+	objectServer, ok := services.GetStorage().(models.StorageObjectServer)
+
+	if !ok {
+		s.getErrorPage(c, http.StatusNotFound, "Storage objects are not served by the agent")
+		return
+	}
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	data, contentType, err := objectServer.GetSignedObject(c.Request.Context(), key, c.Request.URL.Query())
+
+	if err != nil {
+		logrus.WithError(err).WithField("key", key).Warn("Rejected storage object request")
+		s.getErrorPage(c, http.StatusForbidden, "Invalid or expired link")
+		return
+	}
+
+	// Uploaded files are downloaded rather than rendered so they can't run
+	// scripts on the agent's origin
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": path.Base(key),
+	}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", "default-src 'none'; sandbox")
+
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func getFormBlockLabel(block FormBlock) string {
+	if block.Label != nil {
+		return block.Label.Text
+	}
+	return "Field"
+}
@@ -0,0 +1,337 @@
+package daemon
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+const (
+	defaultGrantsPageSize = 100
+	maxGrantsPageSize     = 1000
+)
+
+// grantsCSVHeader is the header row of the CSV export
+var grantsCSVHeader = []string{
+	"id", "user", "role", "workflow", "providers", "identities", "reason",
+	"approvers", "authorized_at", "expires_at", "remaining", "simulated",
+}
+
+// GrantsFilter narrows the active grants listed
+type GrantsFilter struct {
+	Provider string
+	Role     string
+	User     string
+}
+
+// getGrants lists the active grants
+//
+//	@Summary		List active grants
+//	@Description	List who currently has access from the running, approved elevations. Set Accept to text/csv to export as CSV.
+//	@Tags			grants
+//	@Accept			json
+//	@Produce		json
+//	@Produce		text/csv
+//	@Param			provider	query		string					false	"Filter by provider"
+//	@Param			role		query		string					false	"Filter by role"
+//	@Param			user		query		string					false	"Filter by user email"
+//	@Param			page_size	query		int						false	"Number of grants per page"
+//	@Param			page_token	query		string					false	"Token for the next page"
+//	@Success		200			{object}	models.GrantsResponse	"Active grants"
+//	@Failure		400			{object}	map[string]any			"Bad request"
+//	@Failure		401			{object}	map[string]any			"Unauthorized"
+//	@Failure		403			{object}	map[string]any			"Forbidden"
+//	@Failure		500			{object}	map[string]any			"Internal server error"
+//	@Router			/grants [get]
+//	@Security		BearerAuth
+func (s *Server) getGrants(c *gin.Context) {
+
+	temporalService := s.Config.GetServices().GetTemporal()
+
+	if temporalService == nil || !temporalService.HasClient() {
+		s.getErrorPage(c, http.StatusBadRequest, "Temporal service is not configured")
+		return
+	}
+
+	if !s.Config.IsServer() {
+		s.getErrorPage(c, http.StatusBadRequest, "Grants are only available in server mode")
+		return
+	}
+
+	_, foundUser, err := s.getUser(c)
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusUnauthorized, "Unauthorized: unable to get user for list of grants", err)
+		return
+	}
+
+	if foundUser == nil || foundUser.User == nil {
+		s.getErrorPage(c, http.StatusUnauthorized, "Unauthorized: user information is incomplete", nil)
+		return
+	}
+
+	if !s.Config.Server.Security.IsAdminUser(foundUser.User) {
+		s.getErrorPage(c, http.StatusForbidden, "Forbidden: listing grants requires admin access")
+		return
+	}
+
+	pageSize := defaultGrantsPageSize
+
+	if value := c.Query("page_size"); len(value) > 0 {
+		pageSize, err = strconv.Atoi(value)
+		if err != nil || pageSize <= 0 {
+			s.getErrorPage(c, http.StatusBadRequest, "Invalid page size", err)
+			return
+		}
+		pageSize = min(pageSize, maxGrantsPageSize)
+	}
+
+	pageToken, err := base64.RawURLEncoding.DecodeString(c.Query("page_token"))
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusBadRequest, "Invalid page token", err)
+		return
+	}
+
+	query, err := grantsQuery(temporalService.GetTaskQueue(), GrantsFilter{
+		Provider: c.Query("provider"),
+		Role:     c.Query("role"),
+		User:     c.Query("user"),
+	})
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusBadRequest, "Invalid grants filter", err)
+		return
+	}
+
+	resp, err := temporalService.GetClient().ListWorkflow(context.Background(), &workflowservice.ListWorkflowExecutionsRequest{
+		Namespace:     temporalService.GetNamespace(),
+		PageSize:      int32(pageSize),
+		Query:         query,
+		NextPageToken: pageToken,
+	})
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusInternalServerError, "Failed to list grants", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	response := models.GrantsResponse{
+		Grants:        make([]*models.Grant, 0, len(resp.Executions)),
+		NextPageToken: base64.RawURLEncoding.EncodeToString(resp.GetNextPageToken()),
+	}
+
+	for _, exec := range resp.Executions {
+		response.Grants = append(response.Grants, s.grantFromExecution(exec, now))
+	}
+
+	if canAcceptCsv(c) {
+		if len(response.NextPageToken) > 0 {
+			c.Header("X-Next-Page-Token", response.NextPageToken)
+		}
+		c.Header("Content-Disposition", `attachment; filename="grants.csv"`)
+		c.Header("Content-Type", "text/csv")
+		c.Status(http.StatusOK)
+
+		if err := writeGrantsCSV(c.Writer, response.Grants); err != nil {
+			logrus.WithError(err).Error("Failed to write grants CSV")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// grantsQuery builds the visibility query for running, approved elevations
+func grantsQuery(taskQueue string, filter GrantsFilter) (string, error) {
+
+	query := fmt.Sprintf(
+		"TaskQueue='%s' AND ExecutionStatus='Running' AND %s=true",
+		taskQueue, models.VarsContextApproved)
+
+	for _, attribute := range []struct {
+		key   string
+		value string
+	}{
+		{models.VarsContextProviders, filter.Provider},
+		{models.VarsContextRole, filter.Role},
+		{models.VarsContextUser, filter.User},
+	} {
+
+		if len(attribute.value) == 0 {
+			continue
+		}
+
+		if strings.ContainsAny(attribute.value, `'"\`) {
+			return "", fmt.Errorf("%s contains invalid characters", attribute.key)
+		}
+
+		query = fmt.Sprintf("%s AND %s='%s'", query, attribute.key, attribute.value)
+	}
+
+	return query, nil
+}
+
+// grantFromExecution builds the grant from the search attributes and the
+// grant recorded in the memo by the authorize task
+func (s *Server) grantFromExecution(exec *workflow.WorkflowExecutionInfo, now time.Time) *models.Grant {
+
+	info := s.workflowExecutionInfo(exec)
+
+	grant := models.Grant{
+		WorkflowID: info.WorkflowID,
+		User:       info.User,
+		Role:       info.Role,
+		Workflow:   info.Workflow,
+		Providers:  info.Providers,
+		Reason:     info.Reason,
+		Duration:   info.Duration,
+	}
+
+	for _, identity := range info.Identities {
+		grant.Identities = append(grant.Identities, identity.ID)
+	}
+
+	grantMemo := models.GetGrantMemo(exec.GetMemo())
+
+	if grantMemo != nil {
+		grant.AuthorizedAt = &grantMemo.AuthorizedAt
+		grant.ExpiresAt = &grantMemo.RevocationAt
+		grant.Approvers = grantMemo.Approvers
+		grant.Simulated = grantMemo.Simulated
+	} else if info.Duration > 0 {
+		// Elevations authorized before grants were recorded only have the
+		// start time so the expiry is an upper bound
+		expiresAt := info.StartTime.Add(time.Duration(info.Duration) * time.Second)
+		grant.ExpiresAt = &expiresAt
+	}
+
+	if grant.ExpiresAt != nil {
+		grant.Remaining = max(int64(grant.ExpiresAt.Sub(now).Seconds()), 0)
+	}
+
+	return &grant
+}
+
+// writeGrantsCSV writes the grants as CSV with a header row
+func writeGrantsCSV(w io.Writer, grants []*models.Grant) error {
+
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(grantsCSVHeader); err != nil {
+		return err
+	}
+
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	for _, grant := range grants {
+
+		remaining := ""
+		if grant.ExpiresAt != nil {
+			remaining = (time.Duration(grant.Remaining) * time.Second).String()
+		}
+
+		if err := writer.Write([]string{
+			grant.WorkflowID,
+			grant.User,
+			grant.Role,
+			grant.Workflow,
+			strings.Join(grant.Providers, ";"),
+			strings.Join(grant.Identities, ";"),
+			grant.Reason,
+			strings.Join(grant.Approvers, ";"),
+			formatTime(grant.AuthorizedAt),
+			formatTime(grant.ExpiresAt),
+			remaining,
+			strconv.FormatBool(grant.Simulated),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// postReconcileGrants runs the reconciliation sweep
+//
+//	@Summary		Reconcile grants
+//	@Description	Run the reconciliation sweep now. Grants created by thand that no running elevation holds are revoked, or reported, based on reconcile.action.
+//	@Tags			grants
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	models.ReconcileResult	"Sweep result"
+//	@Failure		400	{object}	map[string]any			"Bad request"
+//	@Failure		401	{object}	map[string]any			"Unauthorized"
+//	@Failure		403	{object}	map[string]any			"Forbidden"
+//	@Failure		409	{object}	map[string]any			"A sweep is already running"
+//	@Failure		429	{object}	map[string]any			"A sweep ran too recently"
+//	@Failure		500	{object}	map[string]any			"Internal server error"
+//	@Router			/grants/reconcile [post]
+//	@Security		BearerAuth
+func (s *Server) postReconcileGrants(c *gin.Context) {
+
+	if !s.Config.IsServer() {
+		s.getErrorPage(c, http.StatusBadRequest, "Reconciliation is only available in server mode")
+		return
+	}
+
+	_, foundUser, err := s.getUser(c)
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusUnauthorized, "Unauthorized: unable to get user for reconciliation", err)
+		return
+	}
+
+	if foundUser == nil || foundUser.User == nil {
+		s.getErrorPage(c, http.StatusUnauthorized, "Unauthorized: user information is incomplete", nil)
+		return
+	}
+
+	if !s.Config.Server.Security.IsAdminUser(foundUser.User) {
+		s.getErrorPage(c, http.StatusForbidden, "Forbidden: reconciling grants requires admin access")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"audit": true,
+		"user":  foundUser.User.GetIdentity(),
+	}).Info("Reconciliation sweep requested")
+
+	result, err := s.Config.GetGrantReconciler().Reconcile(c.Request.Context())
+
+	switch {
+	case errors.Is(err, config.ErrReconcileInProgress):
+		s.getErrorPage(c, http.StatusConflict, "A reconciliation sweep is already running", err)
+		return
+	case errors.Is(err, config.ErrReconcileTooSoon):
+		s.getErrorPage(c, http.StatusTooManyRequests, "A reconciliation sweep ran too recently", err)
+		return
+	case err != nil:
+		s.getErrorPage(c, http.StatusInternalServerError, "Failed to reconcile grants", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
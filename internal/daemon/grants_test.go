@@ -0,0 +1,149 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/sdk/converter"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestGrantsQuery(t *testing.T) {
+
+	query, err := grantsQuery("thand", GrantsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, "TaskQueue='thand' AND ExecutionStatus='Running' AND approved=true", query)
+
+	query, err = grantsQuery("thand", GrantsFilter{
+		Provider: "aws-prod",
+		Role:     "admin",
+		User:     "alice@example.com",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "TaskQueue='thand' AND ExecutionStatus='Running' AND approved=true"+
+		" AND providers='aws-prod' AND role='admin' AND user='alice@example.com'", query)
+
+	_, err = grantsQuery("thand", GrantsFilter{User: "' OR 1=1 --"})
+	assert.Error(t, err)
+}
+
+func TestGrantFromExecution(t *testing.T) {
+
+	server := &Server{Config: config.DefaultConfig()}
+
+	dataConverter := converter.GetDefaultDataConverter()
+	payload := func(value any) *commonpb.Payload {
+		p, err := dataConverter.ToPayload(value)
+		require.NoError(t, err)
+		return p
+	}
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	startedAt := now.Add(-2 * time.Hour)
+
+	newExecution := func(memo *commonpb.Memo) *workflow.WorkflowExecutionInfo {
+		return &workflow.WorkflowExecutionInfo{
+			Execution: &commonpb.WorkflowExecution{WorkflowId: "wf-1", RunId: "run-1"},
+			StartTime: timestamppb.New(startedAt),
+			Status:    enums.WORKFLOW_EXECUTION_STATUS_RUNNING,
+			Memo:      memo,
+			SearchAttributes: &commonpb.SearchAttributes{
+				IndexedFields: map[string]*commonpb.Payload{
+					models.VarsContextUser:      payload("alice@example.com"),
+					models.VarsContextRole:      payload("admin"),
+					models.VarsContextProviders: payload([]string{"aws-prod"}),
+					models.VarsContextApproved:  payload(true),
+					"reason":                    payload("Incident 42"),
+					"duration":                  payload(int64(4 * 60 * 60)),
+				},
+			},
+		}
+	}
+
+	t.Run("uses the grant from the memo", func(t *testing.T) {
+
+		authorizedAt := now.Add(-time.Hour)
+
+		grant := server.grantFromExecution(newExecution(&commonpb.Memo{
+			Fields: map[string]*commonpb.Payload{
+				models.TemporalMemoGrant: payload(models.GrantMemo{
+					AuthorizedAt: authorizedAt,
+					RevocationAt: authorizedAt.Add(4 * time.Hour),
+					Approvers:    []string{"bob@example.com"},
+				}),
+			},
+		}), now)
+
+		assert.Equal(t, "wf-1", grant.WorkflowID)
+		assert.Equal(t, "alice@example.com", grant.User)
+		assert.Equal(t, "admin", grant.Role)
+		assert.Equal(t, []string{"aws-prod"}, grant.Providers)
+		assert.Equal(t, "Incident 42", grant.Reason)
+		assert.Equal(t, []string{"bob@example.com"}, grant.Approvers)
+		require.NotNil(t, grant.AuthorizedAt)
+		assert.True(t, authorizedAt.Equal(*grant.AuthorizedAt))
+		assert.Equal(t, int64(3*60*60), grant.Remaining)
+	})
+
+	t.Run("falls back to the start time without a memo", func(t *testing.T) {
+
+		grant := server.grantFromExecution(newExecution(nil), now)
+
+		assert.Nil(t, grant.AuthorizedAt)
+		require.NotNil(t, grant.ExpiresAt)
+		assert.True(t, startedAt.Add(4*time.Hour).Equal(*grant.ExpiresAt))
+		assert.Equal(t, int64(2*60*60), grant.Remaining)
+	})
+}
+
+func TestWriteGrantsCSV(t *testing.T) {
+
+	authorizedAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	expiresAt := authorizedAt.Add(4 * time.Hour)
+
+	var buffer bytes.Buffer
+	require.NoError(t, writeGrantsCSV(&buffer, []*models.Grant{
+		{
+			WorkflowID:   "wf-1",
+			User:         "alice@example.com",
+			Role:         "admin",
+			Workflow:     "slack_approval",
+			Providers:    []string{"aws-prod", "gcp-prod"},
+			Identities:   []string{"alice@example.com"},
+			Reason:       "Incident, sev 1",
+			Approvers:    []string{"bob@example.com", "carol@example.com"},
+			AuthorizedAt: &authorizedAt,
+			ExpiresAt:    &expiresAt,
+			Remaining:    90 * 60,
+		},
+	}))
+
+	records, err := csv.NewReader(&buffer).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, grantsCSVHeader, records[0])
+	assert.Equal(t, []string{
+		"wf-1",
+		"alice@example.com",
+		"admin",
+		"slack_approval",
+		"aws-prod;gcp-prod",
+		"alice@example.com",
+		"Incident, sev 1",
+		"bob@example.com;carol@example.com",
+		"2025-01-01T10:00:00Z",
+		"2025-01-01T14:00:00Z",
+		"1h30m0s",
+		"false",
+	}, records[1])
+}
@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"slices"
 	"strings"
+	"time"
 
+	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -20,81 +22,55 @@ const (
 	ProviderContextKey = "provider"
 )
 
-// CORSMiddleware creates a CORS middleware that supports wildcard patterns in origins
-// Standard CORS only allows exact origins or "*", this middleware extends that to support
-// patterns like "https://*.example.com"
+// CORSMiddleware creates the CORS middleware for the API. Origins are
+// matched with matchOrigin so patterns like "https://*.example.com" only
+// match subdomains. Preflights from other origins are rejected, other
+// requests from them are handled without the CORS headers.
 func CORSMiddleware(cfg models.CORSConfig) gin.HandlerFunc {
 	// Apply defaults for any unset values
 	corsConfig := cfg.WithDefaults()
-	return func(c *gin.Context) {
-		origin := c.GetHeader("Origin")
 
-		if len(origin) == 0 {
-			// No origin header, skip CORS processing
-			c.Next()
-			return
+	// Allowing all origins is development only, the server refuses it in
+	// production. The origin is echoed back rather than "*" so credentials
+	// can still be sent.
+	allowOrigin := func(origin string) bool {
+		if corsConfig.AllowAll {
+			return true
 		}
-
-		logrus.WithFields(logrus.Fields{
-			"origin":         origin,
-			"method":         c.Request.Method,
-			"path":           c.Request.URL.Path,
-			"allowedOrigins": corsConfig.AllowedOrigins,
-		}).Debugln("CORS middleware processing request")
-
-		// Check if the origin matches any allowed pattern
-		allowedOrigin := ""
 		for _, pattern := range corsConfig.AllowedOrigins {
 			if matchOrigin(origin, pattern) {
-				allowedOrigin = origin
-				logrus.WithFields(logrus.Fields{
-					"origin":  origin,
-					"pattern": pattern,
-				}).Debugln("CORS origin matched pattern")
-				break
+				return true
 			}
 		}
+		return false
+	}
+
+	handler := cors.New(cors.Config{
+		AllowOriginFunc:  allowOrigin,
+		AllowMethods:     corsConfig.AllowedMethods,
+		AllowHeaders:     corsConfig.AllowedHeaders,
+		ExposeHeaders:    corsConfig.ExposeHeaders,
+		AllowCredentials: corsConfig.AllowCredentials,
+		MaxAge:           time.Duration(corsConfig.MaxAge) * time.Second,
+	})
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
 
-		// If no match found, don't set CORS headers
-		if len(allowedOrigin) == 0 {
+		// cors rejects every request from an origin that isn't allowed.
+		// Cross origin form posts e.g. a SAML IdP posting to the auth
+		// callback must still reach the handler, the browser just won't
+		// expose the response to the other origin.
+		if len(origin) > 0 && c.Request.Method != http.MethodOptions && !allowOrigin(origin) {
 			logrus.WithFields(logrus.Fields{
 				"origin":         origin,
 				"allowedOrigins": corsConfig.AllowedOrigins,
 			}).Warnln("CORS origin not matched - no Access-Control-Allow-Origin header will be set")
-			// For preflight requests with no matching origin, return 403
-			if c.Request.Method == "OPTIONS" {
-				c.AbortWithStatus(http.StatusForbidden)
-				return
-			}
 			c.Next()
 			return
 		}
 
-		// Set CORS headers
-		c.Header("Access-Control-Allow-Origin", allowedOrigin)
-
-		if corsConfig.AllowCredentials {
-			c.Header("Access-Control-Allow-Credentials", "true")
-		}
-
-		if len(corsConfig.ExposeHeaders) > 0 {
-			c.Header("Access-Control-Expose-Headers", strings.Join(corsConfig.ExposeHeaders, ", "))
-		}
-
-		// Handle preflight request
-		if c.Request.Method == "OPTIONS" {
-			c.Header("Access-Control-Allow-Methods", strings.Join(corsConfig.AllowedMethods, ", "))
-			c.Header("Access-Control-Allow-Headers", strings.Join(corsConfig.AllowedHeaders, ", "))
-
-			if corsConfig.MaxAge > 0 {
-				c.Header("Access-Control-Max-Age", fmt.Sprintf("%d", corsConfig.MaxAge))
-			}
-
-			c.AbortWithStatus(http.StatusNoContent)
-			return
-		}
-
-		c.Next()
+		handler(c)
 	}
 }
 
@@ -347,6 +323,7 @@ func (s *Server) processBearerToken(
 	decodedSession, err := getDecodedSession(encryptionServer, token)
 	if err != nil {
 		logrus.WithError(err).Warnln("Failed to decode bearer token from Authorization header")
+		s.recordAuthFailure(c, "", "invalid_bearer_token", err)
 		return
 	}
 
@@ -372,6 +349,7 @@ func (s *Server) processAPIKey(
 	decodedSession, err := getDecodedSession(encryptionServer, apiHeader)
 	if err != nil {
 		logrus.WithError(err).Warnln("Failed to decode API key from X-API-Key header")
+		s.recordAuthFailure(c, "", "invalid_api_key", err)
 		return
 	}
 
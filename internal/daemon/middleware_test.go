@@ -3,6 +3,8 @@ package daemon
 import (
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -137,6 +139,7 @@ func TestCORSMiddleware(t *testing.T) {
 		origin              string
 		method              string
 		allowedOrigins      []string
+		allowAll            bool
 		allowCredentials    bool
 		expectedAllowOrigin string
 		expectedStatus      int
@@ -172,6 +175,15 @@ func TestCORSMiddleware(t *testing.T) {
 			expectedStatus:      http.StatusOK,
 			expectCORSHeaders:   false,
 		},
+		{
+			// httptest requests are sent to example.com
+			name:                "same origin request with origin header",
+			origin:              "http://example.com",
+			method:              "GET",
+			expectedAllowOrigin: "",
+			expectedStatus:      http.StatusOK,
+			expectCORSHeaders:   false,
+		},
 		{
 			name:                "preflight request - valid origin",
 			origin:              "https://test.app.thand.io",
@@ -222,12 +234,40 @@ func TestCORSMiddleware(t *testing.T) {
 			expectedStatus:      http.StatusOK,
 			expectCORSHeaders:   true,
 		},
+		{
+			name:                "allow all - any origin",
+			origin:              "https://ui.cdn.example.com",
+			method:              "GET",
+			allowAll:            true,
+			allowCredentials:    true,
+			expectedAllowOrigin: "https://ui.cdn.example.com",
+			expectedStatus:      http.StatusOK,
+			expectCORSHeaders:   true,
+		},
+		{
+			name:                "allow all - preflight",
+			origin:              "https://ui.cdn.example.com",
+			method:              "OPTIONS",
+			allowAll:            true,
+			expectedAllowOrigin: "https://ui.cdn.example.com",
+			expectedStatus:      http.StatusNoContent,
+			expectCORSHeaders:   true,
+		},
+		{
+			name:                "same origin only by default",
+			origin:              "https://ui.cdn.example.com",
+			method:              "OPTIONS",
+			expectedAllowOrigin: "",
+			expectedStatus:      http.StatusForbidden,
+			expectCORSHeaders:   false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			corsConfig := models.CORSConfig{
 				AllowedOrigins:   tt.allowedOrigins,
+				AllowAll:         tt.allowAll,
 				AllowCredentials: tt.allowCredentials,
 			}
 
@@ -265,3 +305,33 @@ func TestCORSMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestCORSMiddlewareAuthCallback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reached := false
+
+	router := gin.New()
+	router.Use(CORSMiddleware(models.CORSConfig{
+		AllowedOrigins:   []string{"https://thand.example.com"},
+		AllowCredentials: true,
+	}))
+	router.POST("/api/v1/auth/callback/:provider", func(c *gin.Context) {
+		reached = true
+		assert.Equal(t, "state", c.PostForm("RelayState"))
+		c.String(http.StatusOK, "OK")
+	})
+
+	// SAML IdPs using the HTTP-POST binding post the response cross origin
+	form := url.Values{"RelayState": {"state"}, "SAMLResponse": {"response"}}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/callback/saml", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Origin", "https://idp.example.org")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.True(t, reached, "the callback should be handled")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,93 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/swaggo/swag"
+)
+
+var openAPIPathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// getOpenAPISpec serves the OpenAPI 3 spec for the API
+//
+//	@Summary		OpenAPI specification
+//	@Description	Get the OpenAPI 3 specification generated from the API handlers
+//	@Tags			discovery
+//	@Produce		json
+//	@Success		200	{object}	map[string]any	"OpenAPI 3 specification"
+//	@Router			/openapi.json [get]
+func (s *Server) getOpenAPISpec(c *gin.Context) {
+
+	// Routes don't change once the server is running so build the spec once
+	s.openAPIOnce.Do(func() {
+		spec, err := buildOpenAPISpec(s.Config.GetApiBasePath(), s.router.Routes())
+
+		if err != nil {
+			s.openAPIErr = err
+			return
+		}
+
+		s.openAPISpec, s.openAPIErr = spec.MarshalJSON()
+	})
+
+	if s.openAPIErr != nil {
+		s.getErrorPage(c, http.StatusInternalServerError, "Failed to build OpenAPI spec", s.openAPIErr)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", s.openAPISpec)
+}
+
+// buildOpenAPISpec converts the swagger doc generated from the handler
+// annotations into an OpenAPI 3 spec. Documented paths that are served
+// outside of the API base path e.g. /health get their own server.
+func buildOpenAPISpec(basePath string, routes gin.RoutesInfo) (*openapi3.T, error) {
+
+	doc, err := swag.ReadDoc()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read swagger doc: %w", err)
+	}
+
+	var swaggerDoc openapi2.T
+
+	if err := json.Unmarshal([]byte(doc), &swaggerDoc); err != nil {
+		return nil, fmt.Errorf("failed to parse swagger doc: %w", err)
+	}
+
+	spec, err := openapi2conv.ToV3(&swaggerDoc)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert swagger doc: %w", err)
+	}
+
+	// Use relative servers so the spec works from any hostname
+	spec.Servers = openapi3.Servers{{URL: basePath}}
+
+	registered := map[string]bool{}
+	for _, route := range routes {
+		registered[route.Path] = true
+	}
+
+	for path, pathItem := range spec.Paths.Map() {
+		ginPath := openAPIPathToGin(path)
+
+		if !registered[basePath+ginPath] && registered[ginPath] {
+			pathItem.Servers = openapi3.Servers{{URL: "/"}}
+		}
+	}
+
+	return spec, nil
+}
+
+// openAPIPathToGin converts path parameters from {id} to :id
+func openAPIPathToGin(path string) string {
+	return openAPIPathParam.ReplaceAllString(path, ":$1")
+}
@@ -0,0 +1,152 @@
+package daemon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config"
+)
+
+// undocumentedRoutes are API routes that are deliberately left out of the
+// spec as they are internal to the agent and server
+var undocumentedRoutes = map[string]bool{
+	"GET /logs":                true,
+	"POST /logs":               true,
+	"POST /preflight":          true,
+	"POST /postflight":         true,
+	"GET /execution/:id/form":  true,
+	"POST /execution/:id/form": true,
+	"GET /storage/*key":        true,
+	"GET /docs/*any":           true,
+}
+
+func newRoutesForMode(t *testing.T, mode config.Mode) (*Server, *gin.Engine) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.DefaultConfig()
+	cfg.SetMode(mode)
+
+	server := &Server{Config: cfg}
+	router := gin.New()
+	server.setupRoutes(router)
+
+	return server, router
+}
+
+func newTestOpenAPISpec(t *testing.T) (*openapi3.T, string) {
+	t.Helper()
+
+	server, router := newRoutesForMode(t, config.ModeServer)
+	basePath := server.Config.GetApiBasePath()
+
+	spec, err := buildOpenAPISpec(basePath, router.Routes())
+	require.NoError(t, err)
+
+	return spec, basePath
+}
+
+func TestOpenAPISpecIsValid(t *testing.T) {
+	spec, _ := newTestOpenAPISpec(t)
+
+	assert.True(t, strings.HasPrefix(spec.OpenAPI, "3."))
+	require.NoError(t, spec.Validate(context.Background()))
+}
+
+// TestOpenAPISpecMatchesRoutes round trips the spec against the registered
+// gin routes so the documented API can't drift from the handlers
+func TestOpenAPISpecMatchesRoutes(t *testing.T) {
+	spec, basePath := newTestOpenAPISpec(t)
+
+	// Some endpoints are only served by agents
+	registered := map[string]bool{}
+	for _, mode := range []config.Mode{config.ModeServer, config.ModeAgent} {
+		_, router := newRoutesForMode(t, mode)
+		for _, route := range router.Routes() {
+			registered[route.Method+" "+route.Path] = true
+		}
+	}
+
+	documented := map[string]bool{}
+
+	for path, pathItem := range spec.Paths.Map() {
+		ginPath := openAPIPathToGin(path)
+
+		for method := range pathItem.Operations() {
+			documented[method+" "+ginPath] = true
+
+			assert.True(t,
+				registered[method+" "+basePath+ginPath] || registered[method+" "+ginPath],
+				"documented operation %s %s has no registered route", method, path)
+		}
+	}
+
+	_, router := newRoutesForMode(t, config.ModeServer)
+
+	for _, route := range router.Routes() {
+		if !strings.HasPrefix(route.Path, basePath+"/") {
+			continue
+		}
+
+		key := route.Method + " " + strings.TrimPrefix(route.Path, basePath)
+
+		if undocumentedRoutes[key] {
+			continue
+		}
+
+		assert.True(t, documented[key], "route %s %s is not documented", route.Method, route.Path)
+	}
+}
+
+func TestOpenAPISpecElevationLifecycle(t *testing.T) {
+	spec, _ := newTestOpenAPISpec(t)
+
+	lifecycle := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/elevate"},
+		{http.MethodPost, "/elevate/plan"},
+		{http.MethodGet, "/elevate/{id}/events"},
+		{http.MethodGet, "/execution/{id}"},
+		{http.MethodGet, "/executions"},
+		{http.MethodPost, "/execution/{id}/signal"},
+		{http.MethodPost, "/execution/{id}/revoke"},
+	}
+
+	for _, op := range lifecycle {
+		pathItem := spec.Paths.Find(op.path)
+		require.NotNil(t, pathItem, op.path)
+		assert.NotNil(t, pathItem.GetOperation(op.method), "%s %s", op.method, op.path)
+	}
+
+	// Paths outside of the API base path keep their own server
+	health := spec.Paths.Find("/health")
+	require.NotNil(t, health)
+	require.Len(t, health.Servers, 1)
+	assert.Equal(t, "/", health.Servers[0].URL)
+}
+
+func TestGetOpenAPISpec(t *testing.T) {
+	server, _ := newRoutesForMode(t, config.ModeServer)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, server.Config.GetApiBasePath()+"/openapi.json", nil)
+
+	server.getOpenAPISpec(c)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	spec, err := openapi3.NewLoader().LoadFromData(w.Body.Bytes())
+	require.NoError(t, err)
+	assert.NotNil(t, spec.Paths.Find("/elevate/plan"))
+}
@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -17,6 +18,47 @@ type ErrorPageData struct {
 	Error models.ErrorResponse
 }
 
+// statusError is a failed request with the status and message to show
+// the user, for helpers shared by more than one handler
+type statusError struct {
+	status  int
+	message string
+	err     error
+}
+
+func newStatusError(status int, message string, err error) *statusError {
+	return &statusError{status: status, message: message, err: err}
+}
+
+func (e *statusError) Error() string {
+	if e.err == nil {
+		return e.message
+	}
+	return fmt.Sprintf("%s: %v", e.message, e.err)
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+// getStatusErrorPage shows the error returned by a shared helper
+func (s *Server) getStatusErrorPage(c *gin.Context, err error) {
+
+	var statusErr *statusError
+
+	if !errors.As(err, &statusErr) {
+		s.getErrorPage(c, http.StatusInternalServerError, "Failed to process request", err)
+		return
+	}
+
+	if statusErr.err == nil {
+		s.getErrorPage(c, statusErr.status, statusErr.message)
+		return
+	}
+
+	s.getErrorPage(c, statusErr.status, statusErr.message, statusErr.err)
+}
+
 // getErrorPage handles the request for the error page
 func (s *Server) getErrorPage(c *gin.Context, code int, message string, err ...error) {
 
@@ -3,10 +3,14 @@ package daemon
 import (
 	"context"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 	"github.com/thand-io/agent/internal/config"
 	"github.com/thand-io/agent/internal/models"
 )
@@ -130,7 +134,7 @@ func (s *Server) getProviderRoles(c *gin.Context) {
 // getProviderByName retrieves a provider by name
 //
 //	@Summary		Get provider by name
-//	@Description	Retrieve detailed information about a specific provider
+//	@Description	Retrieve detailed information about a specific provider including its capabilities and health
 //	@Tags			providers
 //	@Accept			json
 //	@Produce		json
@@ -149,11 +153,20 @@ func (s *Server) getProviderByName(c *gin.Context) {
 		return
 	}
 
+	health, err := s.Config.GetProviderHealth(c.Request.Context(), providerName)
+
+	if err != nil {
+		logrus.WithError(err).WithField("provider", providerName).Warn("Failed to check provider health")
+	}
+
 	c.JSON(http.StatusOK, models.ProviderResponse{
-		Name:        provider.Name,
-		Description: provider.Description,
-		Provider:    provider.Provider,
-		Enabled:     true,
+		ID:           providerName,
+		Name:         provider.Name,
+		Description:  provider.Description,
+		Provider:     provider.Provider,
+		Enabled:      true,
+		Capabilities: provider.GetClient().GetCapabilities(),
+		Health:       health,
 	})
 }
 
@@ -221,6 +234,68 @@ func (s *Server) getProviderPermissions(c *gin.Context) {
 	})
 }
 
+// getProviderResources lists resources discovered by a provider
+//
+//	@Summary		List provider resources
+//	@Description	Get a list of live resources a role can be scoped to in a specific provider
+//	@Tags			providers
+//	@Accept			json
+//	@Produce		json
+//	@Param			provider	path		string								true	"Provider name"
+//	@Param			q			query		string								false	"Filter query"
+//	@Success		200			{object}	models.ProviderResourcesResponse	"Provider resources"
+//	@Failure		404			{object}	map[string]any				"Provider not found"
+//	@Failure		500			{object}	map[string]any				"Internal server error"
+//	@Router			/provider/{provider}/resources [get]
+//	@Security		BearerAuth
+func (s *Server) getProviderResources(c *gin.Context) {
+
+	providerName := c.Param("provider")
+
+	provider, foundProvider := s.Config.Providers.Definitions[providerName]
+
+	if !foundProvider {
+		s.getErrorPage(c, http.StatusNotFound, "Provider not found")
+		return
+	}
+
+	if provider.GetClient() == nil {
+		s.getErrorPage(c, http.StatusNotFound, "Provider has no client defined")
+		return
+	}
+
+	if !provider.GetClient().HasCapability(models.ProviderCapabilityResourceDiscovery) {
+		s.getErrorPage(c, http.StatusNotImplemented, "The provider does not implement resource discovery")
+		return
+	}
+
+	query := c.Query("q")
+
+	searchRequest := &models.SearchRequest{}
+
+	if len(query) > 0 {
+		searchRequest.Terms = []string{query}
+		if !strings.HasSuffix(query, "*") {
+			searchRequest.Query = query + "*"
+		} else {
+			searchRequest.Query = query
+		}
+	}
+
+	resources, err := provider.GetClient().ListResources(context.Background(), searchRequest)
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusInternalServerError, "Failed to list resources", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ProviderResourcesResponse{
+		Version:   "1.0",
+		Provider:  providerName,
+		Resources: resources,
+	})
+}
+
 func (s *Server) getAuthProvidersAsProviderResponse(authenticatedUser *models.Session) map[string]models.ProviderResponse {
 	return s.getProvidersAsProviderResponse(
 		authenticatedUser,
@@ -256,20 +331,48 @@ func (s *Server) getProvidersAsProviderResponse(
 		}
 
 		providerResponse[providerKey] = models.ProviderResponse{
-			ID:          providerKey,
-			Name:        providerName,
-			Description: provider.Description,
-			Provider:    provider.Provider,
-			Enabled:     true,
+			ID:           providerKey,
+			Name:         providerName,
+			Description:  provider.Description,
+			Provider:     provider.Provider,
+			Enabled:      true,
+			Capabilities: provider.GetClient().GetCapabilities(),
 		}
 	}
 	return providerResponse
 }
 
+// addProviderHealth checks the health of the providers in parallel. Checks
+// are cached so this only calls the providers every ProviderHealthCacheTTL.
+func (s *Server) addProviderHealth(ctx context.Context, providers map[string]models.ProviderResponse) {
+
+	keys := slices.Collect(maps.Keys(providers))
+	results := make([]*models.ProviderHealth, len(keys))
+
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Go(func() {
+			health, err := s.Config.GetProviderHealth(ctx, key)
+			if err != nil {
+				logrus.WithError(err).WithField("provider", key).Warn("Failed to check provider health")
+				return
+			}
+			results[i] = health
+		})
+	}
+	wg.Wait()
+
+	for i, key := range keys {
+		provider := providers[key]
+		provider.Health = results[i]
+		providers[key] = provider
+	}
+}
+
 // getProviders handles GET /api/v1/providers
 //
 //	@Summary		List providers
-//	@Description	Get a list of all available providers with optional capability filtering
+//	@Description	Get a list of all available providers with their capabilities and health, with optional capability filtering. Health checks are cached for 30 seconds
 //	@Tags			providers
 //	@Accept			json
 //	@Produce		json
@@ -313,6 +416,8 @@ func (s *Server) getProviders(c *gin.Context) {
 		Providers: s.getProvidersAsProviderResponse(authenticatedUser, capabilities...),
 	}
 
+	s.addProviderHealth(c.Request.Context(), response.Providers)
+
 	if s.canAcceptHtml(c) {
 
 		data := struct {
@@ -330,6 +435,52 @@ func (s *Server) getProviders(c *gin.Context) {
 	}
 }
 
+// getCapabilities handles GET /api/v1/capabilities
+//
+//	@Summary		List provider capabilities
+//	@Description	Get the configured providers that support each capability, with optional capability filtering
+//	@Tags			providers
+//	@Accept			json
+//	@Produce		json
+//	@Param			type	query		string						false	"Comma-separated list of capabilities to return"
+//	@Success		200		{object}	models.CapabilitiesResponse	"Providers by capability"
+//	@Failure		400		{object}	map[string]any				"Unknown capability"
+//	@Failure		401		{object}	map[string]any				"Unauthorized"
+//	@Router			/capabilities [get]
+//	@Security		BearerAuth
+func (s *Server) getCapabilities(c *gin.Context) {
+
+	var user *models.User
+
+	// Only return the providers the user is allowed to see
+	if s.Config.IsServer() {
+		_, foundUser, err := s.getUser(c)
+		if err != nil {
+			s.getErrorPage(c, http.StatusUnauthorized, "Unauthorized: unable to get user for list of capabilities", err)
+			return
+		}
+		user = foundUser.User
+	}
+
+	capabilities := []models.ProviderCapability{}
+
+	if capabilityType := c.Query("type"); len(capabilityType) > 0 {
+		for cap := range strings.SplitSeq(capabilityType, ",") {
+			parsedCap, err := models.GetCapabilityFromString(strings.TrimSpace(cap))
+			if err != nil {
+				s.getErrorPage(c, http.StatusBadRequest, "Invalid capability type", err)
+				return
+			}
+			capabilities = append(capabilities, parsedCap)
+		}
+	}
+
+	c.JSON(http.StatusOK, models.CapabilitiesResponse{
+		Version:      "1.0",
+		Capabilities: s.Config.GetProviderCapabilities(user, capabilities...),
+	})
+}
+
 // postProviderAuthorizeSession authorizes a session with a provider
 //
 //	@Summary		Authorize provider session
@@ -354,6 +505,15 @@ func (s *Server) postProviderAuthorizeSession(c *gin.Context) {
 		return
 	}
 
+	// The provider sends the code to the redirect URI, so it must be one
+	// we own
+	if len(user.RedirectUri) > 0 {
+		if err := s.validateAuthRedirect(user.RedirectUri); err != nil {
+			s.rejectAuthRedirect(c, c.Param("provider"), user.RedirectUri, err)
+			return
+		}
+	}
+
 	provider, err := s.getProvider(c.Param("provider"))
 
 	if err != nil {
@@ -361,7 +521,14 @@ func (s *Server) postProviderAuthorizeSession(c *gin.Context) {
 		return
 	}
 
-	authResponse, err := provider.GetClient().AuthorizeSession(context.Background(), &user)
+	authorizor, err := provider.GetAuthorizor()
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusBadRequest, "Provider does not support authentication", err)
+		return
+	}
+
+	authResponse, err := authorizor.AuthorizeSession(context.Background(), &user)
 
 	if err != nil {
 		s.getErrorPage(c, http.StatusInternalServerError, "Failed to authorize session", err)
@@ -37,8 +37,9 @@ func (s *Server) postRegister(c *gin.Context) {
 	cfg := s.GetConfig()
 
 	c.JSON(http.StatusOK, config.RegistrationResponse{
-		Success:  true,
-		Services: &cfg.Services,
+		Success: true,
+		// Only the shape of the services is shared, never their secrets
+		Services: cfg.Services.Redact(),
 		//Roles:     &cfg.Roles,
 		//Providers: &cfg.Providers,
 		//Workflows: &cfg.Workflows,
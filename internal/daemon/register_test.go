@@ -0,0 +1,56 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestPostRegister_RedactsSecrets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.DefaultConfig()
+	cfg.Services.LargeLanguageModel = &models.LargeLanguageModelConfig{
+		Provider: "openai",
+		APIKey:   "sk-llm-api-key",
+	}
+	cfg.Services.Temporal = &models.TemporalConfig{
+		Host:   "temporal.example.com",
+		ApiKey: "temporal-api-key",
+	}
+	cfg.Services.Vault = &models.ServiceConfig{
+		Provider: "azure",
+		Config:   &models.BasicConfig{"client_secret": "azure-client-secret"},
+	}
+
+	server := &Server{Config: cfg}
+	router := gin.New()
+	router.POST("/register", server.postRegister)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/register",
+		strings.NewReader(`{"environment": {"name": "test"}}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	assert.NotContains(t, body, "sk-llm-api-key")
+	assert.NotContains(t, body, "temporal-api-key")
+	assert.NotContains(t, body, "azure-client-secret")
+	assert.Contains(t, body, common.RedactedValue)
+	assert.Contains(t, body, "temporal.example.com")
+
+	// The running config keeps its secrets
+	assert.Equal(t, "temporal-api-key", cfg.Services.Temporal.ApiKey)
+}
@@ -24,12 +24,14 @@ type EvaluateRoleResponse struct {
 // getRoles handles GET /api/v1/roles
 //
 //	@Summary		List roles
-//	@Description	Get a list of all available roles with optional provider filtering
+//	@Description	Get a list of all available roles with optional provider and tag filtering
 //	@Tags			roles
 //	@Accept			json
 //	@Produce		json
 //	@Param			provider	query		string					false	"Comma-separated list of providers to filter by"
+//	@Param			tag			query		[]string				false	"Tags the roles must have as key:value, repeat for more tags"	collectionFormat(multi)
 //	@Success		200			{object}	models.RolesResponse	"List of roles"
+//	@Failure		400			{object}	map[string]any	"Bad request"
 //	@Failure		401			{object}	map[string]any	"Unauthorized"
 //	@Router			/roles [get]
 //	@Security		BearerAuth
@@ -61,12 +63,23 @@ func (s *Server) getRoles(c *gin.Context) {
 		providers = append(providers, foundProviders...)
 	}
 
+	// Roles must have every requested tag e.g. ?tag=environment:production
+	tags, err := models.ParseRoleTags(c.QueryArray("tag"), ":")
+
+	if err != nil {
+		s.getErrorPage(c, http.StatusBadRequest, "Invalid tag filter", err)
+		return
+	}
+
 	// Filter out roles that are not in the requested providers
 	filteredRoles := make(map[string]models.RoleResponse)
 	for roleName, role := range s.Config.GetRoles().Definitions {
 		if len(providers) > 0 && !hasAnyProvider(role.Providers, providers) {
 			continue
 		}
+		if !role.HasTags(tags) {
+			continue
+		}
 		if authenticatedUser != nil && !role.HasPermission(authenticatedUser.User) {
 			continue
 		}
@@ -83,8 +96,9 @@ func (s *Server) getRoles(c *gin.Context) {
 	// composite role for the user to match against.
 
 	response := models.RolesResponse{
-		Version: "1.0",
-		Roles:   filteredRoles,
+		Version:      "1.0",
+		Roles:        filteredRoles,
+		ReasonPolicy: s.Config.GetRoles().ReasonPolicy,
 	}
 
 	if s.canAcceptHtml(c) {
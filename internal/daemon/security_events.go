@@ -0,0 +1,126 @@
+package daemon
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/siem"
+)
+
+// recordSecurityEvent writes the event to the audit log. The client IP and
+// request path are used when the event doesn't set the actor or resource.
+func (s *Server) recordSecurityEvent(c *gin.Context, event siem.SecurityEvent) {
+
+	if s.auditLogger == nil {
+		return
+	}
+
+	if len(event.ActorIP) == 0 {
+		event.ActorIP = getClientIP(c)
+	}
+
+	if len(event.Resource) == 0 && c != nil {
+		event.Resource = c.Request.Method + " " + c.Request.URL.Path
+	}
+
+	if err := s.auditLogger.Log(event); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"event": event.EventType,
+		}).Warnln("Failed to write security event to the audit log")
+	}
+}
+
+// recordAuthFailure records a failed login. Failures handling a SAML
+// response are recorded as invalid SAML responses.
+func (s *Server) recordAuthFailure(c *gin.Context, provider string, reason string, err error) {
+
+	eventType := siem.SecurityEventAuthFailed
+
+	if len(c.PostForm("SAMLResponse")) > 0 {
+		eventType = siem.SecurityEventSAMLInvalid
+	}
+
+	event := siem.NewSecurityEvent(eventType, siem.OutcomeFailure).
+		WithDetail("provider", provider).
+		WithDetail("reason", reason)
+
+	if err != nil {
+		event = event.WithDetail("error", err.Error())
+	}
+
+	s.recordSecurityEvent(c, event)
+}
+
+// recordIPBlocked returns the callback recording requests rejected by an
+// IP allowlist
+func (s *Server) recordIPBlocked(allowlist string) func(c *gin.Context, clientIP string) {
+	return func(c *gin.Context, clientIP string) {
+
+		event := siem.NewSecurityEvent(siem.SecurityEventIPBlocked, siem.OutcomeDenied).
+			WithDetail("allowlist", allowlist)
+
+		event.ActorIP = clientIP
+
+		s.recordSecurityEvent(c, event)
+	}
+}
+
+// recordRoleLimitExceeded records requests for roles over the role limits,
+// other errors are ignored
+func (s *Server) recordRoleLimitExceeded(c *gin.Context, identity string, err error) {
+
+	var limitErr *config.RoleLimitError
+
+	if !errors.As(err, &limitErr) {
+		return
+	}
+
+	event := siem.NewSecurityEvent(siem.SecurityEventRoleLimitExceeded, siem.OutcomeDenied).
+		WithDetail("limit", limitErr.Limit).
+		WithDetail("count", limitErr.Count).
+		WithDetail("max", limitErr.Max)
+
+	event.ActorIdentity = identity
+	event.Resource = limitErr.Role
+
+	s.recordSecurityEvent(c, event)
+}
+
+// getUserIdentity returns the identity of the user, if there's one
+func getUserIdentity(user *models.User) string {
+	if user == nil {
+		return ""
+	}
+	return user.GetIdentity()
+}
+
+// getClientIP returns the client IP of the request. Requests made outside
+// the API, e.g. from the Slack app home, have no gin context or client IP.
+func getClientIP(c *gin.Context) string {
+	if c == nil {
+		return ""
+	}
+	return c.ClientIP()
+}
+
+// getSessionIdentity returns the identity of the logged in user, if any
+func (s *Server) getSessionIdentity(c *gin.Context) string {
+	_, session, err := s.getUser(c)
+	if err != nil || session == nil {
+		return ""
+	}
+	return getUserIdentity(session.User)
+}
+
+// closeAuditLogger writes any queued security events and closes the log
+func (s *Server) closeAuditLogger() {
+	if closer, ok := s.auditLogger.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logrus.WithError(err).Error("Failed to close the audit log")
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// selfServiceHandler exposes the server to providers with their own request
+// surface e.g. the Slack app home. Requests are prepared the same way as the
+// elevate endpoint before the workflow is created.
+type selfServiceHandler struct {
+	server *Server
+}
+
+// registerSelfServiceProviders hands the self service handler to any
+// provider that supports it
+func (s *Server) registerSelfServiceProviders() {
+
+	if !s.Config.IsServer() {
+		return
+	}
+
+	handler := &selfServiceHandler{server: s}
+
+	for providerName, provider := range s.Config.GetProviders().Definitions {
+
+		selfService, ok := provider.GetClient().(models.ProviderSelfService)
+
+		if !ok {
+			continue
+		}
+
+		err := selfService.SetSelfServiceHandler(handler)
+
+		if errors.Is(err, models.ErrNotImplemented) {
+			continue
+		} else if err != nil {
+			logrus.WithError(err).WithField("provider", providerName).
+				Errorln("Failed to register self service handler")
+			continue
+		}
+
+		logrus.WithField("provider", providerName).Infoln("Registered self service handler")
+	}
+}
+
+func (h *selfServiceHandler) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+
+	if len(email) == 0 {
+		return nil, fmt.Errorf("email is required to resolve a user")
+	}
+
+	identity, err := h.server.Config.GetIdentity(email)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve identity for %s: %w", email, err)
+	}
+
+	if identity == nil || identity.User == nil {
+		return nil, fmt.Errorf("no user found for %s", email)
+	}
+
+	return identity.User, nil
+}
+
+func (h *selfServiceHandler) ListElevations(ctx context.Context, user *models.User) ([]*models.WorkflowExecutionInfo, error) {
+
+	if user == nil || len(user.Email) == 0 {
+		return nil, fmt.Errorf("user email is required to list elevations")
+	}
+
+	return h.server.listUserWorkflows(ctx, user.Email, "ExecutionStatus='Running'")
+}
+
+func (h *selfServiceHandler) ListRoles(user *models.User) map[string]models.Role {
+
+	roles := map[string]models.Role{}
+
+	for roleName, role := range h.server.Config.GetRoles().Definitions {
+		if !role.Enabled || !role.HasPermission(user) {
+			continue
+		}
+		roles[roleName] = role
+	}
+
+	return roles
+}
+
+func (h *selfServiceHandler) Elevate(
+	ctx context.Context,
+	user *models.User,
+	request models.ElevateRequest,
+) (*models.WorkflowRequest, error) {
+
+	s := h.server
+
+	atomic.AddInt64(&s.ElevateRequests, 1)
+
+	if user == nil || len(user.Email) == 0 {
+		return nil, fmt.Errorf("user email is required to request an elevation")
+	}
+
+	if !request.IsValid() {
+		return nil, fmt.Errorf("role and providers are required")
+	}
+
+	if !request.Role.HasPermission(user) {
+		return nil, fmt.Errorf("user %s is not allowed to request role %s", user.Email, request.Role.Name)
+	}
+
+	request.Workflow = request.GetWorkflow()
+
+	// Self elevate
+	if len(request.Identities) == 0 {
+		request.Identities = []string{user.Email}
+	}
+
+	// There is no browser session to take the authenticator from, so the
+	// user is sent to the role's authenticator before the workflow starts
+	if len(request.Authenticator) == 0 {
+		request.Authenticator = h.getAuthenticator(request.Role)
+	}
+
+	existing, err := s.prepareElevation(ctx, nil, &request, user)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the user to the running workflow instead of starting another
+	if existing != nil {
+		return &models.WorkflowRequest{
+			Url: fmt.Sprintf("%s/execution/%s",
+				s.Config.GetRootUrl(), url.PathEscape(existing.WorkflowId)),
+		}, nil
+	}
+
+	return s.Workflows.CreateWorkflow(ctx, request)
+}
+
+// getAuthenticator returns the role's first authenticator or the first
+// configured authorizer provider
+func (h *selfServiceHandler) getAuthenticator(role *models.Role) string {
+
+	if role != nil && len(role.Authenticators) > 0 {
+		return role.Authenticators[0]
+	}
+
+	authorizers := h.server.Config.GetProvidersByCapability(
+		models.ProviderCapabilityAuthorizer)
+
+	if len(authorizers) == 0 {
+		return ""
+	}
+
+	return slices.Sorted(maps.Keys(authorizers))[0]
+}
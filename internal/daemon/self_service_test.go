@@ -0,0 +1,60 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestSelfServiceElevate(t *testing.T) {
+
+	cfg := config.DefaultConfig()
+	cfg.SetMode(config.ModeServer)
+
+	handler := &selfServiceHandler{server: &Server{Config: cfg}}
+	user := &models.User{Email: "alice@example.com"}
+
+	newRequest := func() models.ElevateRequest {
+		return models.ElevateRequest{
+			Role: &models.Role{
+				Name:      "admin",
+				Workflows: []string{"default"},
+				Providers: []string{"aws"},
+				Enabled:   true,
+			},
+			Providers: []string{"aws"},
+			Reason:    "Investigating an incident",
+		}
+	}
+
+	t.Run("requests are checked like the elevate endpoint", func(t *testing.T) {
+		request := newRequest()
+		request.PublicKey = "not a public key"
+
+		_, err := handler.Elevate(context.Background(), user, request)
+
+		var statusErr *statusError
+		require.True(t, errors.As(err, &statusErr), err)
+		assert.Equal(t, http.StatusBadRequest, statusErr.status)
+		assert.Equal(t, "Invalid public key for elevation request", statusErr.message)
+	})
+
+	t.Run("rejects providers the role can't use", func(t *testing.T) {
+		request := newRequest()
+		request.Providers = []string{"gcp"}
+
+		_, err := handler.Elevate(context.Background(), user, request)
+		assert.ErrorContains(t, err, "Invalid providers for elevation request")
+	})
+
+	t.Run("requires a user", func(t *testing.T) {
+		_, err := handler.Elevate(context.Background(), nil, newRequest())
+		assert.ErrorContains(t, err, "user email is required")
+	})
+}
@@ -20,13 +20,18 @@ package daemon
 
 import (
 	"context"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -39,7 +44,9 @@ import (
 	_ "github.com/thand-io/agent/docs" // Import generated swagger docs
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/metrics"
 	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/siem"
 	"github.com/thand-io/agent/internal/workflows/manager"
 	"go.temporal.io/api/workflowservice/v1"
 )
@@ -76,6 +83,16 @@ func NewServer(cfg *config.Config) *Server {
 		StartTime:      time.Now().UTC(),
 	}
 
+	server.elevationEvents = newElevationEvents(
+		server.fetchElevationExecution, elevationEventsPollInterval)
+
+	server.deviceCodes = newDeviceCodes(
+		cfg.Server.DeviceCode.Expiry, cfg.Server.DeviceCode.Interval)
+
+	server.usedAuthStates = newUsedAuthStates()
+
+	server.signalResults = newSignalResults(cfg.Server.Signals.IdempotencyWindow)
+
 	return server
 }
 
@@ -88,6 +105,20 @@ type Server struct {
 	TotalRequests   int64
 	ElevateRequests int64
 	server          *http.Server
+	metricsServer   *http.Server
+	router          *gin.Engine
+	stopCertReload  func()
+	elevationEvents *elevationEvents
+	deviceCodes     *deviceCodes
+	usedAuthStates  *usedAuthStates
+	signalResults   *signalResults
+	allowedCIDRs    []netip.Prefix
+	adminCIDRs      []netip.Prefix
+	auditLogger     siem.SecurityAuditLogger
+
+	openAPIOnce sync.Once
+	openAPISpec []byte
+	openAPIErr  error
 }
 
 func (s *Server) GetConfig() *config.Config {
@@ -134,6 +165,8 @@ func (s *Server) GetTemplateData(c *gin.Context) config.TemplateData {
 		User:        foundUser,
 		Version:     s.GetVersion(),
 		Status:      "Online",
+
+		StaticVersion: getStaticVersion(),
 	}
 }
 
@@ -144,6 +177,28 @@ func (s *Server) Start() error {
 
 	router := gin.New()
 
+	if err := s.setTrustedProxies(router); err != nil {
+		return err
+	}
+
+	allowedCIDRs, err := parseAllowedCIDRs(s.Config.Server.Security.AllowedCIDRs)
+	if err != nil {
+		return fmt.Errorf("invalid server.security.allowed_cidrs: %w", err)
+	}
+	s.allowedCIDRs = allowedCIDRs
+
+	adminCIDRs, err := parseAllowedCIDRs(s.Config.Server.AdminAllowlist)
+	if err != nil {
+		return fmt.Errorf("invalid server.admin_allowlist: %w", err)
+	}
+	s.adminCIDRs = adminCIDRs
+
+	auditLogger, err := siem.NewSecurityAuditLogger(&s.Config.AuditLog, &s.Config.Logging.Syslog)
+	if err != nil {
+		return fmt.Errorf("invalid audit_log: %w", err)
+	}
+	s.auditLogger = auditLogger
+
 	// Add middleware
 	router.Use(gin.Logger())
 	router.Use(gin.CustomRecovery(
@@ -173,13 +228,20 @@ func (s *Server) Start() error {
 		corsConfig.AddOrigins(s.Config.GetLoginServerUrl())
 	}
 
+	if corsConfig.AllowAll {
+		if s.Config.Environment.IsProduction() {
+			return fmt.Errorf("server.security.cors.allow_all can't be used in production")
+		}
+		logrus.Warnln("CORS allows requests from any origin, server.security.cors.allow_all is only for development")
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"allowedOrigins": corsConfig.AllowedOrigins,
 	}).Debugln("CORS configuration")
 
 	router.Use(CORSMiddleware(corsConfig))
 
-	cookieNames := []string{ThandCookieName}
+	cookieNames := []string{ThandCookieName, ThandStateCookieName}
 
 	foundProviders := s.Config.GetProvidersByCapability(
 		models.ProviderCapabilityAuthorizer,
@@ -213,15 +275,48 @@ func (s *Server) Start() error {
 		IdleTimeout:  s.Config.Server.Limits.IdleTimeout,
 	}
 
+	tlsEnabled := s.Config.Server.TLS.IsEnabled()
+
+	if tlsEnabled {
+
+		tlsConfig, reloader, err := newTLSConfig(&s.Config.Server.TLS)
+
+		if err != nil {
+			return fmt.Errorf("failed to configure TLS: %w", err)
+		}
+
+		server.TLSConfig = tlsConfig
+		s.stopCertReload = reloader.watchReloadSignal()
+	}
+
 	// Store server reference for shutdown
 	s.server = server
 
+	if s.Config.Server.Metrics.HasSeparatePort() {
+		s.startMetricsServer()
+	}
+
+	// Let providers such as slack serve their own request surfaces
+	s.registerSelfServiceProviders()
+
+	// Revoke grants left behind by elevations that never revoked them
+	if s.Config.IsServer() {
+		s.Config.GetGrantReconciler().Start()
+	}
+
 	// Channel to capture startup errors
 	errChan := make(chan error, 1)
 
 	// Start server in goroutine
 	go func() {
-		if err := server.ListenAndServe(); err != nil {
+		var err error
+		if tlsEnabled {
+			// The certificate is served by the TLS config so it can be reloaded
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil {
 			errChan <- err
 		}
 	}()
@@ -241,6 +336,30 @@ func (s *Server) Start() error {
 	}
 }
 
+// startMetricsServer serves the metrics endpoint on its own port so it
+// can be scraped without exposing the main server
+func (s *Server) startMetricsServer() {
+
+	mux := http.NewServeMux()
+	mux.Handle(s.Config.Server.Metrics.Path, metrics.Handler())
+
+	addr := fmt.Sprintf("%s:%d", s.Config.Server.Host, s.Config.Server.Metrics.Port)
+
+	s.metricsServer = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := s.metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logrus.WithError(err).Errorln("Metrics server failed")
+		}
+	}()
+
+	fmt.Printf("Metrics available on %s%s\n", addr, s.Config.Server.Metrics.Path)
+}
+
 func (s *Server) Stop() {
 	if s.server == nil {
 		logrus.Warning("Server is not running")
@@ -249,9 +368,26 @@ func (s *Server) Stop() {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+
+	if s.metricsServer != nil {
+		if err := s.metricsServer.Shutdown(ctx); err != nil {
+			logrus.WithError(err).Error("Metrics server Shutdown")
+		}
+	}
 	if err := s.server.Shutdown(ctx); err != nil {
 		logrus.WithError(err).Error("Server Shutdown")
 	}
+	if s.stopCertReload != nil {
+		s.stopCertReload()
+	}
+	s.Config.GetGrantReconciler().Stop()
+	// Send any buffered digests rather than losing them
+	if err := s.Config.GetNotificationDigests().Stop(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to flush notification digests")
+	}
+	// Send any queued events to the SIEM
+	siem.Close()
+	s.closeAuditLogger()
 	logrus.Info("Server exiting")
 }
 
@@ -265,6 +401,9 @@ func (s *Server) requestCounterMiddleware() gin.HandlerFunc {
 
 // setupRoutes configures all the HTTP routes
 func (s *Server) setupRoutes(router *gin.Engine) {
+
+	s.router = router
+
 	// Serve static files and landing page
 	// router.StaticFS("/static", http.FS(staticFiles))
 
@@ -287,11 +426,19 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 		router.GET(s.Config.Server.Ready.Path, s.readyHandler)
 	}
 
-	// Metrics endpoint
-	if s.Config.Server.Metrics.Enabled {
+	// Metrics endpoint, unless served on its own port
+	if s.Config.Server.Metrics.Enabled && !s.Config.Server.Metrics.HasSeparatePort() {
 		router.GET(s.Config.Server.Metrics.Path, s.metricsHandler)
 	}
 
+	// Limit the remaining routes to the allowed networks. Health, ready
+	// and metrics stay reachable for probes
+	if len(s.allowedCIDRs) > 0 {
+		router.Use(AllowedCIDRsMiddleware(
+			s.allowedCIDRs, len(s.Config.Server.TrustedProxies) > 0,
+			s.recordIPBlocked("allowed_cidrs")))
+	}
+
 	// Now enable auth
 	router.Use(s.AuthMiddleware())
 
@@ -312,10 +459,14 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 		router.GET("/auth", s.getAuthPage)
 		router.GET("/logout", s.getLogoutPage)
 
+		// Device login for CLIs that can't open a browser
+		router.GET("/device", s.getDevicePage)
+		router.POST("/device", s.postDevicePage)
+
 		router.GET("/executions", s.getExecutionsPage)
 		router.GET("/execution/:id", s.getRunningWorkflow)
-		router.GET("/execution/:id/cancel", s.cancelRunningWorkflow)       // Graceful cancellation
-		router.GET("/execution/:id/terminate", s.terminateRunningWorkflow) // Forceful termination
+		router.GET("/execution/:id/cancel", s.RequireAdminIP(), s.ClientCertMiddleware(models.ClientCertRouteAdmin), s.cancelRunningWorkflow)       // Graceful cancellation
+		router.GET("/execution/:id/terminate", s.RequireAdminIP(), s.ClientCertMiddleware(models.ClientCertRouteAdmin), s.terminateRunningWorkflow) // Forceful termination
 
 		// Form routes for workflow task forms
 		router.GET("/execution/:id/form", s.getFormPage)
@@ -394,14 +545,14 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 		} else if s.Config.IsServer() {
 
 			// Register handlers
-			api.POST("/preflight", func(c *gin.Context) {
+			api.POST("/preflight", s.ClientCertMiddleware(models.ClientCertRouteRegistration), func(c *gin.Context) {
 				// Just a stub for now
 				c.JSON(http.StatusOK, config.PreflightResponse{
 					Success: true,
 				})
 			})
-			api.POST("/register", s.postRegister)
-			api.POST("/postflight", func(c *gin.Context) {
+			api.POST("/register", s.ClientCertMiddleware(models.ClientCertRouteRegistration), s.postRegister)
+			api.POST("/postflight", s.ClientCertMiddleware(models.ClientCertRouteRegistration), func(c *gin.Context) {
 
 				// Just a stub for now
 				c.JSON(http.StatusOK, config.PostflightResponse{
@@ -409,17 +560,28 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 				})
 			})
 
+			// OpenAPI 3 spec and Swagger UI for the public API
+			api.GET("/openapi.json", s.getOpenAPISpec)
+			api.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler,
+				ginSwagger.URL(s.Config.GetApiBasePath()+"/openapi.json")))
+
 			api.POST("/logs", func(c *gin.Context) {
 
 				// Just a stub for now
 				c.JSON(http.StatusOK, gin.H{})
 			})
 
+			// Admin endpoints are limited to the admin allowlist and, when
+			// configured, clients presenting a certificate
+			admin := api.Group("", s.RequireAdminIP(), s.ClientCertMiddleware(models.ClientCertRouteAdmin))
+
 			// Server endpoints
 			api.GET("/roles", s.getRoles)
 			api.POST("/roles/evaluate", s.postEvaluateRole)
 			api.GET("/workflows", s.getWorkflows)
+			admin.GET("/workflows/:id/audit", s.getWorkflowAuditTrail)
 			api.GET("/providers", s.getProviders)
+			api.GET("/capabilities", s.getCapabilities)
 
 			api.GET("/role/:role", s.getRoleByName)
 			api.GET("/workflow/:name", s.getWorkflowByName)
@@ -427,21 +589,30 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 			api.GET("/provider/:provider/permissions", s.getProviderPermissions)
 			api.GET("/provider/:provider/roles", s.getProviderRoles)
 			api.GET("/provider/:provider/identities", s.getProviderIdentities)
+			api.GET("/provider/:provider/resources", s.getProviderResources)
 			api.POST("/provider/:provider/authorizeSession", s.postProviderAuthorizeSession)
 
 			api.GET("/identities", s.getIdentities)
 
 			// Sync endpoints
-			api.GET("/sync", s.getSync)
+			api.GET("/sync", s.ClientCertMiddleware(models.ClientCertRouteRegistration), s.getSync)
 
+			api.GET("/auth/providers", s.getAuthProviders)
+			api.POST("/auth/device/code", s.postDeviceCode)
+			api.POST("/auth/device/token", s.postDeviceToken)
 			api.GET("/auth/request/:provider", s.getAuthRequest)
 			api.GET("/auth/callback/:provider", s.getAuthCallback)
+			api.POST("/auth/callback/:provider", s.postAuthCallback)
+			api.POST("/auth/refresh", s.postAuthRefresh)
+			api.POST("/auth/renew", s.postAuthRenew)
 			api.GET("/auth/logout/:provider", s.getLogoutPage)
 			api.GET("/auth/logout", s.getLogoutPage)
 
 			// /elevate?role=admin&provider=server&reason=maintenance&duration=1h
 			api.GET("/elevate", s.getElevate)
 			api.POST("/elevate", s.postElevate)
+			api.POST("/elevate/plan", s.postElevatePlan)
+			api.GET("/elevate/:id/events", s.getElevateEvents)
 			api.GET("/elevate/llm", s.getElevateLLM)
 			api.POST("/elevate/llm", s.postElevateLLM)
 
@@ -451,17 +622,34 @@ func (s *Server) setupRoutes(router *gin.Engine) {
 
 			// get workflow info
 			api.GET("/executions", s.listRunningWorkflows)
+			admin.GET("/grants", s.getGrants)
+			admin.POST("/grants/reconcile", s.postReconcileGrants)
+
+			// Approvers hand their approvals to a delegate while they're away
+			api.GET("/delegations", s.getDelegations)
+			admin.GET("/delegations/all", s.getAllDelegations)
+			api.POST("/delegations", s.postDelegation)
+			api.DELETE("/delegations/:id", s.deleteDelegation)
+
 			api.POST("/execution", s.createWorkflow)
 
 			api.GET("/execution/:id", s.getRunningWorkflow)
-			api.GET("/execution/:id/cancel", s.cancelRunningWorkflow)
-			api.GET("/execution/:id/terminate", s.terminateRunningWorkflow)
+			admin.GET("/execution/:id/cancel", s.cancelRunningWorkflow)
+			admin.GET("/execution/:id/terminate", s.terminateRunningWorkflow)
+			admin.POST("/execution/:id/revoke", s.revokeRunningWorkflow)
+
+			// Approvers follow the links in notifications from anywhere, so
+			// signals aren't admin endpoints
 			api.GET("/execution/:id/signal", s.signalRunningWorkflow)
+			api.POST("/execution/:id/signal", s.postSignalRunningWorkflow)
 
 			// Form API endpoints
 			api.GET("/execution/:id/form", s.getFormPage)
 			api.POST("/execution/:id/form", s.submitForm)
 
+			// Files uploaded with forms, authorized by the signed URL
+			api.GET("/storage/*key", s.getStorageObject)
+
 		}
 	}
 }
@@ -603,13 +791,19 @@ func (s *Server) apiConfigurationHandler(c *gin.Context) {
 		"loginServer": s.Config.GetLoginServerUrl(),
 	}
 
+	// Servers publish the OpenAPI 3 spec for the public API
+	if s.Config.IsServer() {
+		response["docsUrl"] = s.Config.GetLocalServerUrl() + s.Config.GetApiBasePath() + "/docs/index.html"
+		response["openApiSpec"] = s.Config.GetLocalServerUrl() + s.Config.GetApiBasePath() + "/openapi.json"
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
 // readyHandler handles the readiness check endpoint
 //
 //	@Summary		Readiness check
-//	@Description	Check if the service is ready to accept requests
+//	@Description	Check if the service is ready to accept requests. Providers whose agent credentials are missing permissions are reported as warning conditions without failing readiness
 //	@Tags			health
 //	@Accept			json
 //	@Produce		json
@@ -622,19 +816,52 @@ func (s *Server) readyHandler(c *gin.Context) {
 		"version":   s.GetVersion(),
 	}
 
+	if conditions := s.getReadyConditions(); len(conditions) > 0 {
+		response["conditions"] = conditions
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
+// getReadyConditions warns about providers that can't grant access. They
+// don't stop the service being ready as other providers still work.
+func (s *Server) getReadyConditions() []gin.H {
+
+	conditions := []gin.H{}
+
+	for _, result := range s.Config.GetAgentPermissions() {
+		if !result.HasProblem() {
+			continue
+		}
+		conditions = append(conditions, gin.H{
+			"type":     "AgentPermissions",
+			"severity": "warning",
+			"provider": result.Provider,
+			"status":   result.Status,
+			"message":  result.GetSummary(),
+		})
+	}
+
+	return conditions
+}
+
 // metricsHandler handles the metrics endpoint
 //
 //	@Summary		Service metrics
-//	@Description	Get service metrics including uptime, request counts, and resource counts
+//	@Description	Get Prometheus metrics for auth attempts, role grants, workflows, approvals and provider health. Use format=json for the service summary
 //	@Tags			metrics
-//	@Accept			json
+//	@Produce		plain
 //	@Produce		json
-//	@Success		200	{object}	models.MetricsInfo	"Service metrics"
+//	@Param			format	query		string				false	"Set to json for the service summary"
+//	@Success		200		{object}	models.MetricsInfo	"Service metrics"
 //	@Router			/metrics [get]
 func (s *Server) metricsHandler(c *gin.Context) {
+
+	if !strings.EqualFold(c.Query("format"), "json") {
+		metrics.Handler().ServeHTTP(c.Writer, c.Request)
+		return
+	}
+
 	uptime := time.Since(s.StartTime)
 
 	metrics := models.MetricsInfo{
@@ -650,13 +877,52 @@ func (s *Server) metricsHandler(c *gin.Context) {
 }
 
 func (s *Server) getFavicon(c *gin.Context) {
+	setStaticCacheControl(c)
 	c.FileFromFS("static/favicon.ico", http.FS(staticFiles))
 }
 
 func (s *Server) getStyle(c *gin.Context) {
+	setStaticCacheControl(c)
 	c.FileFromFS("static/styles.css", http.FS(staticFiles))
 }
 
+// getStaticVersion hashes the embedded static files once. The files are
+// compiled into the binary so the hash only changes between builds.
+var getStaticVersion = sync.OnceValue(func() string {
+
+	hash := sha256.New()
+
+	err := fs.WalkDir(staticFiles, "static", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := staticFiles.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		hash.Write([]byte(path))
+		hash.Write(content)
+		return nil
+	})
+
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to hash static files")
+		return ""
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))[:12]
+})
+
+// setStaticCacheControl lets browsers cache static assets requested with
+// the current version indefinitely, and revalidate anything else
+func setStaticCacheControl(c *gin.Context) {
+	if version := getStaticVersion(); len(version) > 0 && c.Query("v") == version {
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		c.Header("Cache-Control", "no-cache")
+	}
+}
+
 // In your server setup
 func getSessionStore(secret string) sessions.Store {
 	store := cookie.NewStore([]byte(secret))
@@ -2,6 +2,7 @@ package daemon
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -146,6 +147,8 @@ func (s *Server) postSession(c *gin.Context) {
 //	@Tags			sessions
 //	@Accept			json
 //	@Produce		json
+//	@Param			active		query		bool	false	"Only return sessions that have not expired"
+//	@Param			provider	query		string	false	"Comma-separated list of providers to filter by"
 //	@Success		200	{object}	sessions.LoginServer		"List of sessions"
 //	@Failure		400	{object}	map[string]any	"Bad request"
 //	@Failure		500	{object}	map[string]any	"Internal server error"
@@ -190,13 +193,34 @@ func (s *Server) getSessions(c *gin.Context) {
 
 		sessionManager := sessions.GetSessionManager()
 		sessionManager.Load(loginServer)
-		sessionsList, err := sessionManager.GetLoginServer(loginServer)
+
+		var filters []sessions.SessionFilter
+
+		if c.Query("active") == "true" {
+			filters = append(filters, sessions.FilterByExpiry())
+		}
+
+		if provider := c.Query("provider"); len(provider) > 0 {
+			filters = append(filters, sessions.FilterByProvider(strings.Split(provider, ",")...))
+		}
+
+		foundSessions, err := sessionManager.ListSessions(loginServer, filters...)
 
 		if err != nil {
 			s.getErrorPage(c, http.StatusInternalServerError, "Failed to list sessions", err)
 			return
 		}
 
+		sessionsList := sessions.LoginServer{
+			Version:   "1",
+			Timestamp: time.Now(),
+			Sessions:  map[string]models.LocalSession{},
+		}
+
+		for _, session := range foundSessions {
+			sessionsList.Sessions[session.Provider] = session.LocalSession
+		}
+
 		c.JSON(http.StatusOK, sessionsList)
 		return
 
@@ -0,0 +1,209 @@
+package daemon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// idempotencyKeyHeader lets integrations retry a signal without it
+	// being sent to the workflow twice
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	defaultSignalIdempotencyWindow = 24 * time.Hour
+)
+
+var errIdempotencyKeyReused = errors.New("idempotency key was already used for a different signal")
+
+// signalResult is the response to a signal kept to answer retries of it.
+// The result is pending while the signal is being sent.
+type signalResult struct {
+	inputHash string
+	data      *ExecutionStatePageData
+	expiresAt time.Time
+
+	pending bool
+	// done is closed once the signal has been sent or has failed
+	done chan struct{}
+}
+
+// signalResults holds the responses to signals sent with an idempotency
+// key in memory until the retention window passes
+type signalResults struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	results map[string]*signalResult
+}
+
+func newSignalResults(window time.Duration) *signalResults {
+
+	if window <= 0 {
+		window = defaultSignalIdempotencyWindow
+	}
+
+	return &signalResults{
+		window:  window,
+		now:     time.Now,
+		results: map[string]*signalResult{},
+	}
+}
+
+// Reserve returns the response to an earlier signal with the same key or
+// reserves the key for the caller to send the signal. Callers with a key
+// that's already reserved wait until the signal has been sent. Keys are
+// scoped to the user and workflow so they can't be used to read another
+// user's result. Reusing a key for a different signal is an error.
+func (r *signalResults) Reserve(
+	ctx context.Context,
+	user string,
+	workflowID string,
+	key string,
+	input string,
+) (*ExecutionStatePageData, *signalReservation, error) {
+
+	resultKey := signalResultKey(user, workflowID, key)
+	inputHash := hashSignalInput(input)
+
+	for {
+
+		r.mu.Lock()
+
+		r.removeExpired()
+
+		result, found := r.results[resultKey]
+
+		if !found {
+
+			result = &signalResult{
+				inputHash: inputHash,
+				pending:   true,
+				done:      make(chan struct{}),
+			}
+
+			r.results[resultKey] = result
+
+			r.mu.Unlock()
+
+			return nil, &signalReservation{results: r, key: resultKey, result: result}, nil
+		}
+
+		resultHash, data, pending, done := result.inputHash, result.data, result.pending, result.done
+
+		r.mu.Unlock()
+
+		if resultHash != inputHash {
+			return nil, nil, errIdempotencyKeyReused
+		}
+
+		if !pending {
+			return data, nil, nil
+		}
+
+		// The signal is being sent, once it has the result is replayed
+		// or, if it failed, the key can be reserved again
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+// Store keeps the response to a signal for the retention window
+func (r *signalResults) Store(user string, workflowID string, key string, input string, data *ExecutionStatePageData) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	done := make(chan struct{})
+	close(done)
+
+	r.results[signalResultKey(user, workflowID, key)] = &signalResult{
+		inputHash: hashSignalInput(input),
+		data:      data,
+		expiresAt: r.now().Add(r.window),
+		done:      done,
+	}
+}
+
+// removeExpired must be called with the lock held
+func (r *signalResults) removeExpired() {
+
+	now := r.now()
+
+	for key, result := range r.results {
+		if !result.pending && now.After(result.expiresAt) {
+			delete(r.results, key)
+		}
+	}
+}
+
+// signalReservation is held by the request sending a signal with an
+// idempotency key. It's nil for signals without a key.
+type signalReservation struct {
+	results *signalResults
+	key     string
+	result  *signalResult
+}
+
+// Complete keeps the response to the signal and replays it to the
+// requests waiting on the reservation
+func (s *signalReservation) Complete(data *ExecutionStatePageData) {
+
+	if s == nil {
+		return
+	}
+
+	s.results.mu.Lock()
+	defer s.results.mu.Unlock()
+
+	if !s.result.pending {
+		return
+	}
+
+	s.result.data = data
+	s.result.expiresAt = s.results.now().Add(s.results.window)
+	s.result.pending = false
+
+	close(s.result.done)
+}
+
+// Release frees the key when the signal failed so a retry can send it. It
+// does nothing once the reservation has been completed.
+func (s *signalReservation) Release() {
+
+	if s == nil {
+		return
+	}
+
+	s.results.mu.Lock()
+	defer s.results.mu.Unlock()
+
+	if !s.result.pending {
+		return
+	}
+
+	if s.results.results[s.key] == s.result {
+		delete(s.results.results, s.key)
+	}
+
+	s.result.pending = false
+
+	close(s.result.done)
+}
+
+func signalResultKey(user string, workflowID string, key string) string {
+	return fmt.Sprintf("%s|%s|%s", user, workflowID, key)
+}
+
+func hashSignalInput(input string) string {
+	sum := sha256.Sum256([]byte(input))
+	return hex.EncodeToString(sum[:])
+}
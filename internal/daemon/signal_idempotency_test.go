@@ -0,0 +1,250 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestSignalResults(t *testing.T) {
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	results := newSignalResults(time.Hour)
+	results.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	data := &ExecutionStatePageData{}
+
+	found, reservation, err := results.Reserve(ctx, "alice@example.com", "wf-1", "key-1", "input")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+	require.NotNil(t, reservation)
+
+	reservation.Complete(data)
+
+	found, reservation, err = results.Reserve(ctx, "alice@example.com", "wf-1", "key-1", "input")
+	require.NoError(t, err)
+	assert.Same(t, data, found)
+	assert.Nil(t, reservation)
+
+	// Keys are scoped to the user and workflow
+	found, reservation, err = results.Reserve(ctx, "bob@example.com", "wf-1", "key-1", "input")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+	assert.NotNil(t, reservation)
+
+	found, reservation, err = results.Reserve(ctx, "alice@example.com", "wf-2", "key-1", "input")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+	assert.NotNil(t, reservation)
+
+	_, _, err = results.Reserve(ctx, "alice@example.com", "wf-1", "key-1", "other-input")
+	assert.ErrorIs(t, err, errIdempotencyKeyReused)
+
+	now = now.Add(time.Hour + time.Second)
+
+	found, reservation, err = results.Reserve(ctx, "alice@example.com", "wf-1", "key-1", "input")
+	require.NoError(t, err)
+	assert.Nil(t, found, "Results should expire after the window")
+	assert.NotNil(t, reservation)
+}
+
+func TestSignalResultsConcurrent(t *testing.T) {
+
+	t.Run("concurrent requests send the signal once", func(t *testing.T) {
+
+		results := newSignalResults(time.Hour)
+
+		var signals atomic.Int32
+		var wg sync.WaitGroup
+
+		responses := make([]*ExecutionStatePageData, 20)
+		start := make(chan struct{})
+
+		for i := range responses {
+			wg.Go(func() {
+
+				<-start
+
+				found, reservation, err := results.Reserve(
+					context.Background(), "alice@example.com", "wf-1", "key-1", "input")
+
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				if reservation == nil {
+					responses[i] = found
+					return
+				}
+
+				// Stands in for signalling the workflow
+				signals.Add(1)
+				time.Sleep(10 * time.Millisecond)
+
+				responses[i] = &ExecutionStatePageData{}
+				reservation.Complete(responses[i])
+			})
+		}
+
+		close(start)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), signals.Load())
+
+		for _, response := range responses {
+			assert.Same(t, responses[0], response)
+		}
+	})
+
+	t.Run("a failed signal can be retried", func(t *testing.T) {
+
+		results := newSignalResults(time.Hour)
+
+		_, first, err := results.Reserve(
+			context.Background(), "alice@example.com", "wf-1", "key-1", "input")
+		require.NoError(t, err)
+		require.NotNil(t, first)
+
+		retried := make(chan *signalReservation)
+
+		go func() {
+			_, reservation, err := results.Reserve(
+				context.Background(), "alice@example.com", "wf-1", "key-1", "input")
+			assert.NoError(t, err)
+			retried <- reservation
+		}()
+
+		first.Release()
+
+		second := <-retried
+		require.NotNil(t, second, "The retry should reserve the key once the first signal fails")
+
+		// Releasing a completed reservation keeps its result
+		data := &ExecutionStatePageData{}
+		second.Complete(data)
+		second.Release()
+
+		found, reservation, err := results.Reserve(
+			context.Background(), "alice@example.com", "wf-1", "key-1", "input")
+		require.NoError(t, err)
+		assert.Same(t, data, found)
+		assert.Nil(t, reservation)
+	})
+
+	t.Run("waiting stops when the request is cancelled", func(t *testing.T) {
+
+		results := newSignalResults(time.Hour)
+
+		_, reservation, err := results.Reserve(
+			context.Background(), "alice@example.com", "wf-1", "key-1", "input")
+		require.NoError(t, err)
+		defer reservation.Release()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, _, err = results.Reserve(ctx, "alice@example.com", "wf-1", "key-1", "input")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestReplaySignal(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	server := &Server{
+		Config:        &config.Config{},
+		signalResults: newSignalResults(time.Hour),
+	}
+
+	signals := 0
+
+	router := gin.New()
+	router.POST("/execution/:id/signal", func(c *gin.Context) {
+
+		input := c.PostForm("input")
+		key := c.GetHeader(idempotencyKeyHeader)
+
+		reservation, replayed := server.reserveSignal(c, "alice@example.com", c.Param("id"), key, input)
+
+		if replayed {
+			return
+		}
+
+		// Stands in for signalling the workflow
+		signals++
+
+		data := &ExecutionStatePageData{
+			ExecutionStatePageResponse: ExecutionStatePageResponse{
+				Execution: &models.WorkflowExecutionInfo{WorkflowID: c.Param("id")},
+			},
+		}
+
+		reservation.Complete(data)
+
+		server.renderSignalResult(c, data)
+	})
+
+	send := func(key string, input string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/execution/wf-1/signal", nil)
+		req.PostForm = map[string][]string{"input": {input}}
+		req.Header.Set("Accept", "application/json")
+		if len(key) > 0 {
+			req.Header.Set(idempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := send("incident-bot-1", "approve")
+	require.Equal(t, http.StatusOK, first.Code)
+
+	replayed := send("incident-bot-1", "approve")
+	require.Equal(t, http.StatusOK, replayed.Code)
+	assert.JSONEq(t, first.Body.String(), replayed.Body.String(), "A replay should return the original result")
+	assert.Equal(t, 1, signals, "A replay shouldn't signal the workflow again")
+
+	reused := send("incident-bot-1", "deny")
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+	assert.Equal(t, 1, signals)
+
+	// Without a key every request is sent
+	send("", "approve")
+	send("", "approve")
+	assert.Equal(t, 3, signals)
+}
+
+func TestSetApprovalIdempotencyKey(t *testing.T) {
+
+	signal := cloudevents.NewEvent()
+	require.NoError(t, signal.SetData(cloudevents.ApplicationJSON, map[string]any{"approved": true}))
+
+	require.NoError(t, setApprovalIdempotencyKey(&signal, "incident-bot-1"))
+
+	var data map[string]any
+	require.NoError(t, json.Unmarshal(signal.Data(), &data))
+	assert.Equal(t, map[string]any{
+		"approved":                         true,
+		models.ApprovalIdempotencyKeyField: "incident-bot-1",
+	}, data)
+
+	// A key already in the signal is kept
+	require.NoError(t, setApprovalIdempotencyKey(&signal, "incident-bot-2"))
+	require.NoError(t, json.Unmarshal(signal.Data(), &data))
+	assert.Equal(t, "incident-bot-1", data[models.ApprovalIdempotencyKeyField])
+}
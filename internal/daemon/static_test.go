@@ -0,0 +1,60 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStaticVersion(t *testing.T) {
+	version := getStaticVersion()
+	assert.Len(t, version, 12)
+	assert.Equal(t, version, getStaticVersion())
+}
+
+func TestStaticCacheControl(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := &Server{}
+	router := gin.New()
+	router.GET("/styles.css", server.getStyle)
+
+	tests := []struct {
+		name         string
+		url          string
+		cacheControl string
+	}{
+		{
+			name:         "current version is immutable",
+			url:          "/styles.css?v=" + getStaticVersion(),
+			cacheControl: "public, max-age=31536000, immutable",
+		},
+		{
+			name:         "stale version is revalidated",
+			url:          "/styles.css?v=stale",
+			cacheControl: "no-cache",
+		},
+		{
+			name:         "no version is revalidated",
+			url:          "/styles.css",
+			cacheControl: "no-cache",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			require.NoError(t, err)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.cacheControl, w.Header().Get("Cache-Control"))
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package daemon
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// certificateReloader serves the configured certificate and swaps it for
+// the one on disk when reloaded so certificates can be rotated without
+// restarting the server
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+func newCertificateReloader(certFile string, keyFile string) (*certificateReloader, error) {
+
+	reloader := &certificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	if err := reloader.Reload(); err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+// Reload loads the certificate from disk. The current certificate is kept
+// if the new one fails to load.
+func (r *certificateReloader) Reload() error {
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+
+	return nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (r *certificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// watchReloadSignal reloads the certificate whenever the process receives
+// a SIGHUP. Call the returned function to stop watching.
+func (r *certificateReloader) watchReloadSignal() func() {
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				if err := r.Reload(); err != nil {
+					logrus.WithError(err).Errorln("Failed to reload TLS certificate, keeping the current certificate")
+					continue
+				}
+				logrus.WithField("certFile", r.certFile).Infoln("Reloaded TLS certificate")
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// newTLSConfig builds the tls.Config for the server from the configuration
+func newTLSConfig(cfg *models.TLSConfig) (*tls.Config, *certificateReloader, error) {
+
+	if len(cfg.CertFile) == 0 || len(cfg.KeyFile) == 0 {
+		return nil, nil, fmt.Errorf("both tls.cert_file and tls.key_file must be set")
+	}
+
+	minVersion, err := parseTLSVersion(cfg.MinVersion)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reloader, err := newCertificateReloader(cfg.CertFile, cfg.KeyFile)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     minVersion,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if cfg.HasClientCA() {
+
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+
+		clientCAs := x509.NewCertPool()
+
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return nil, nil, fmt.Errorf("no certificates found in client CA file: %s", cfg.ClientCAFile)
+		}
+
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+
+		if cfg.HasClientCertRoutes() {
+
+			for _, group := range cfg.ClientCertRoutes {
+				if !slices.Contains(models.ClientCertRouteGroups, group) {
+					return nil, nil, fmt.Errorf("unsupported tls.client_cert_routes: %s, must be one of %s",
+						group, strings.Join(models.ClientCertRouteGroups, ", "))
+				}
+			}
+
+			// Certificates are verified when presented and required by the
+			// route groups, so browsers can still use the other routes
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	return tlsConfig, reloader, nil
+}
+
+// parseTLSVersion converts a version such as 1.2 to its tls constant.
+// Defaults to TLS 1.2 when no version is set.
+func parseTLSVersion(version string) (uint16, error) {
+
+	if len(version) == 0 {
+		return tls.VersionTLS12, nil
+	}
+
+	found, ok := tlsVersions[version]
+
+	if !ok {
+		return 0, fmt.Errorf("unsupported tls.min_version: %s, must be one of 1.0, 1.1, 1.2 or 1.3", version)
+	}
+
+	return found, nil
+}
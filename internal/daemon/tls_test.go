@@ -0,0 +1,243 @@
+package daemon
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+type testCertificate struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// newTestCertificate writes a certificate for localhost to dir, signed by
+// parent or self signed when parent is nil
+func newTestCertificate(t *testing.T, dir string, name string, parent *testCertificate) *testCertificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+
+	return &testCertificate{cert: cert, key: key, certFile: certFile, keyFile: keyFile}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected uint16
+		wantErr  bool
+	}{
+		{"", tls.VersionTLS12, false},
+		{"1.2", tls.VersionTLS12, false},
+		{"1.3", tls.VersionTLS13, false},
+		{"1.0", tls.VersionTLS10, false},
+		{"1.4", 0, true},
+		{"TLS1.2", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			version, err := parseTLSVersion(tt.version)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, version)
+		})
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCertificate(t, dir, "ca", nil)
+	server := newTestCertificate(t, dir, "server", ca)
+
+	t.Run("requires both cert and key", func(t *testing.T) {
+		_, _, err := newTLSConfig(&models.TLSConfig{CertFile: server.certFile})
+		assert.ErrorContains(t, err, "tls.key_file")
+	})
+
+	t.Run("rejects unknown min version", func(t *testing.T) {
+		_, _, err := newTLSConfig(&models.TLSConfig{
+			CertFile:   server.certFile,
+			KeyFile:    server.keyFile,
+			MinVersion: "2.0",
+		})
+		assert.ErrorContains(t, err, "tls.min_version")
+	})
+
+	t.Run("client CA enables mTLS", func(t *testing.T) {
+		tlsConfig, _, err := newTLSConfig(&models.TLSConfig{
+			CertFile:     server.certFile,
+			KeyFile:      server.keyFile,
+			MinVersion:   "1.3",
+			ClientCAFile: ca.certFile,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+		assert.Equal(t, tls.RequireAndVerifyClientCert, tlsConfig.ClientAuth)
+		assert.NotNil(t, tlsConfig.ClientCAs)
+	})
+
+	t.Run("client CA must contain certificates", func(t *testing.T) {
+		_, _, err := newTLSConfig(&models.TLSConfig{
+			CertFile:     server.certFile,
+			KeyFile:      server.keyFile,
+			ClientCAFile: server.keyFile,
+		})
+		assert.ErrorContains(t, err, "no certificates found")
+	})
+}
+
+func TestCertificateReloader(t *testing.T) {
+	dir := t.TempDir()
+	first := newTestCertificate(t, dir, "first", nil)
+	second := newTestCertificate(t, dir, "second", nil)
+
+	reloader, err := newCertificateReloader(first.certFile, first.keyFile)
+	require.NoError(t, err)
+
+	current, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, first.cert.Raw, current.Certificate[0])
+
+	// Rotate the certificate on disk
+	copyFile(t, second.certFile, first.certFile)
+	copyFile(t, second.keyFile, first.keyFile)
+
+	require.NoError(t, reloader.Reload())
+
+	current, err = reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second.cert.Raw, current.Certificate[0])
+
+	// A broken certificate keeps the current one
+	require.NoError(t, os.WriteFile(first.certFile, []byte("invalid"), 0600))
+	assert.Error(t, reloader.Reload())
+
+	current, err = reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second.cert.Raw, current.Certificate[0])
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCertificate(t, dir, "ca", nil)
+	server := newTestCertificate(t, dir, "server", ca)
+	client := newTestCertificate(t, dir, "client", ca)
+
+	tlsConfig, _, err := newTLSConfig(&models.TLSConfig{
+		CertFile:     server.certFile,
+		KeyFile:      server.keyFile,
+		ClientCAFile: ca.certFile,
+	})
+	require.NoError(t, err)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	require.NoError(t, err)
+
+	httpServer := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+		}),
+		ReadHeaderTimeout: time.Second,
+	}
+	go httpServer.Serve(listener)
+	t.Cleanup(func() { httpServer.Close() })
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ca.cert)
+
+	url := "https://" + listener.Addr().String()
+
+	t.Run("rejects clients without a certificate", func(t *testing.T) {
+		httpClient := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: rootCAs},
+		}}
+		_, err := httpClient.Get(url)
+		assert.Error(t, err)
+	})
+
+	t.Run("accepts clients signed by the CA", func(t *testing.T) {
+		clientCert, err := tls.LoadX509KeyPair(client.certFile, client.keyFile)
+		require.NoError(t, err)
+
+		httpClient := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:      rootCAs,
+				Certificates: []tls.Certificate{clientCert},
+			},
+		}}
+		res, err := httpClient.Get(url)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "client", string(body))
+	})
+}
+
+func copyFile(t *testing.T, src string, dst string) {
+	t.Helper()
+	data, err := os.ReadFile(src)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dst, data, 0600))
+}
@@ -186,6 +186,26 @@ func (s *Server) terminateRunningWorkflow(c *gin.Context) {
 	s.cancelRunningWorkflow(c)
 }
 
+// revokeRunningWorkflow ends an elevation early. Cancelling the workflow
+// runs its cleanup which revokes any granted access.
+//
+//	@Summary		Revoke elevation
+//	@Description	Revoke an elevation by cancelling its workflow, any granted access is removed by the workflow cleanup
+//	@Tags			executions
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string					true	"Workflow execution ID"
+//	@Success		200	{object}	map[string]any			"Revocation requested"
+//	@Failure		401	{object}	models.ErrorResponse	"Unauthorized"
+//	@Failure		403	{object}	models.ErrorResponse	"Forbidden"
+//	@Failure		404	{object}	models.ErrorResponse	"Workflow not found"
+//	@Failure		500	{object}	models.ErrorResponse	"Internal server error"
+//	@Router			/execution/{id}/revoke [post]
+//	@Security		BearerAuth
+func (s *Server) revokeRunningWorkflow(c *gin.Context) {
+	s.cancelRunningWorkflow(c)
+}
+
 // cancelRunningWorkflow gracefully cancels a workflow execution
 //
 //	@Summary		Cancel workflow execution
@@ -207,6 +227,7 @@ func (s *Server) cancelRunningWorkflow(c *gin.Context) {
 
 	if !s.Config.IsServer() {
 		s.getErrorPage(c, http.StatusUnauthorized, "Unauthorized: unable to cancel workflow", nil)
+		return
 	}
 
 	_, authenticatedUser, err := s.getUser(c)
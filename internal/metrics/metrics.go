@@ -0,0 +1,215 @@
+// Package metrics exposes Prometheus metrics for authentication, role
+// grants, workflows, approvals, provider health and the syslog sink.
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const DefaultNamespace = "thand"
+
+// Result label values
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Metrics holds the collectors registered against a dedicated registry so
+// tests and multiple servers don't collide with the global registry
+type Metrics struct {
+	registry *prometheus.Registry
+
+	authAttempts       *prometheus.CounterVec
+	roleGrants         *prometheus.CounterVec
+	roleRevokes        *prometheus.CounterVec
+	workflowsStarted   *prometheus.CounterVec
+	workflowsCompleted *prometheus.CounterVec
+	workflowsTimedOut  *prometheus.CounterVec
+	approvalLatency    *prometheus.HistogramVec
+	providerHealth     *prometheus.GaugeVec
+	syslogDropped      prometheus.Counter
+}
+
+// New creates the collectors under the given namespace
+func New(namespace string) *Metrics {
+
+	if len(namespace) == 0 {
+		namespace = DefaultNamespace
+	}
+
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		authAttempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_attempts_total",
+			Help:      "Authentication attempts by provider and result",
+		}, []string{"provider", "result"}),
+
+		roleGrants: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "role_grants_total",
+			Help:      "Role grants by provider and result",
+		}, []string{"provider", "result"}),
+
+		roleRevokes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "role_revokes_total",
+			Help:      "Role revocations by provider and result",
+		}, []string{"provider", "result"}),
+
+		workflowsStarted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "workflows_started_total",
+			Help:      "Workflows started by workflow name",
+		}, []string{"workflow"}),
+
+		workflowsCompleted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "workflows_completed_total",
+			Help:      "Workflows completed by workflow name and final status",
+		}, []string{"workflow", "status"}),
+
+		workflowsTimedOut: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "workflows_timed_out_total",
+			Help:      "Workflows that timed out by workflow name",
+		}, []string{"workflow"}),
+
+		approvalLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "approval_latency_seconds",
+			Help:      "Time from elevation request to approval decision",
+			// 10s up to ~2 days
+			Buckets: prometheus.ExponentialBuckets(10, 3, 10),
+		}, []string{"workflow", "decision"}),
+
+		providerHealth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "provider_healthy",
+			Help:      "Provider health, 1 is healthy and 0 is unhealthy",
+		}, []string{"provider"}),
+
+		syslogDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "syslog_events_dropped_total",
+			Help:      "Events dropped because the syslog queue was full",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.authAttempts,
+		m.roleGrants,
+		m.roleRevokes,
+		m.workflowsStarted,
+		m.workflowsCompleted,
+		m.workflowsTimedOut,
+		m.approvalLatency,
+		m.providerHealth,
+		m.syslogDropped,
+	)
+
+	return m
+}
+
+var (
+	mu             sync.RWMutex
+	defaultMetrics = New(DefaultNamespace)
+)
+
+// Configure replaces the default metrics with a new namespace. This should
+// be called once at startup before anything is recorded.
+func Configure(namespace string) {
+	if len(namespace) == 0 {
+		namespace = DefaultNamespace
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	defaultMetrics = New(namespace)
+}
+
+// Default returns the metrics used by the package level helpers
+func Default() *Metrics {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultMetrics
+}
+
+// Registry returns the prometheus registry the collectors are registered in
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler returns the http handler serving the default metrics
+func Handler() http.Handler {
+	registry := Default().Registry()
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		Registry: registry,
+	})
+}
+
+func resultLabel(success bool) string {
+	if success {
+		return ResultSuccess
+	}
+	return ResultFailure
+}
+
+// RecordAuthAttempt counts an authentication attempt against a provider
+func RecordAuthAttempt(provider string, success bool) {
+	Default().authAttempts.WithLabelValues(provider, resultLabel(success)).Inc()
+}
+
+// RecordRoleGrant counts a role grant against a provider
+func RecordRoleGrant(provider string, success bool) {
+	Default().roleGrants.WithLabelValues(provider, resultLabel(success)).Inc()
+}
+
+// RecordRoleRevoke counts a role revocation against a provider
+func RecordRoleRevoke(provider string, success bool) {
+	Default().roleRevokes.WithLabelValues(provider, resultLabel(success)).Inc()
+}
+
+// RecordWorkflowStarted counts a new workflow execution
+func RecordWorkflowStarted(workflow string) {
+	Default().workflowsStarted.WithLabelValues(workflow).Inc()
+}
+
+// RecordWorkflowCompleted counts a workflow reaching a final status
+func RecordWorkflowCompleted(workflow string, status string) {
+	Default().workflowsCompleted.WithLabelValues(workflow, status).Inc()
+}
+
+// RecordWorkflowTimeout counts a workflow that ended because it timed out
+func RecordWorkflowTimeout(workflow string) {
+	Default().workflowsTimedOut.WithLabelValues(workflow).Inc()
+}
+
+// ObserveApprovalLatency records the time from request to an approval
+// decision e.g. approved or denied
+func ObserveApprovalLatency(workflow string, decision string, latency time.Duration) {
+	Default().approvalLatency.WithLabelValues(workflow, decision).Observe(latency.Seconds())
+}
+
+// SetProviderHealth sets the provider health gauge
+func SetProviderHealth(provider string, healthy bool) {
+	value := 0.0
+	if healthy {
+		value = 1
+	}
+	Default().providerHealth.WithLabelValues(provider).Set(value)
+}
+
+// RecordSyslogDropped counts an event dropped by the syslog sink
+func RecordSyslogDropped() {
+	Default().syslogDropped.Inc()
+}
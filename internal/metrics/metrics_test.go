@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findMetric(t *testing.T, m *Metrics, name string, labels map[string]string) *dto.Metric {
+	t.Helper()
+
+	families, err := m.Registry().Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			matched := 0
+			for _, label := range metric.GetLabel() {
+				if value, ok := labels[label.GetName()]; ok && value == label.GetValue() {
+					matched++
+				}
+			}
+			if matched == len(labels) {
+				return metric
+			}
+		}
+	}
+
+	return nil
+}
+
+func TestRecordHelpers(t *testing.T) {
+	Configure("test")
+	defer Configure(DefaultNamespace)
+
+	m := Default()
+
+	RecordAuthAttempt("google", true)
+	RecordAuthAttempt("google", false)
+	RecordAuthAttempt("google", false)
+	RecordRoleGrant("aws-prod", true)
+	RecordRoleRevoke("aws-prod", false)
+	RecordWorkflowStarted("slack_approval")
+	RecordWorkflowCompleted("slack_approval", "completed")
+	RecordWorkflowTimeout("slack_approval")
+	ObserveApprovalLatency("slack_approval", "approved", 90*time.Second)
+	SetProviderHealth("aws-prod", true)
+	SetProviderHealth("slack", false)
+	RecordSyslogDropped()
+
+	metric := findMetric(t, m, "test_auth_attempts_total", map[string]string{"provider": "google", "result": ResultFailure})
+	require.NotNil(t, metric)
+	assert.Equal(t, 2.0, metric.GetCounter().GetValue())
+
+	metric = findMetric(t, m, "test_role_grants_total", map[string]string{"provider": "aws-prod", "result": ResultSuccess})
+	require.NotNil(t, metric)
+	assert.Equal(t, 1.0, metric.GetCounter().GetValue())
+
+	metric = findMetric(t, m, "test_role_revokes_total", map[string]string{"provider": "aws-prod", "result": ResultFailure})
+	require.NotNil(t, metric)
+	assert.Equal(t, 1.0, metric.GetCounter().GetValue())
+
+	metric = findMetric(t, m, "test_workflows_completed_total", map[string]string{"workflow": "slack_approval", "status": "completed"})
+	require.NotNil(t, metric)
+	assert.Equal(t, 1.0, metric.GetCounter().GetValue())
+
+	metric = findMetric(t, m, "test_workflows_timed_out_total", map[string]string{"workflow": "slack_approval"})
+	require.NotNil(t, metric)
+	assert.Equal(t, 1.0, metric.GetCounter().GetValue())
+
+	metric = findMetric(t, m, "test_approval_latency_seconds", map[string]string{"workflow": "slack_approval", "decision": "approved"})
+	require.NotNil(t, metric)
+	assert.Equal(t, uint64(1), metric.GetHistogram().GetSampleCount())
+	assert.Equal(t, 90.0, metric.GetHistogram().GetSampleSum())
+
+	metric = findMetric(t, m, "test_provider_healthy", map[string]string{"provider": "aws-prod"})
+	require.NotNil(t, metric)
+	assert.Equal(t, 1.0, metric.GetGauge().GetValue())
+
+	metric = findMetric(t, m, "test_provider_healthy", map[string]string{"provider": "slack"})
+	require.NotNil(t, metric)
+	assert.Equal(t, 0.0, metric.GetGauge().GetValue())
+
+	metric = findMetric(t, m, "test_syslog_events_dropped_total", nil)
+	require.NotNil(t, metric)
+	assert.Equal(t, 1.0, metric.GetCounter().GetValue())
+}
+
+func TestHandler(t *testing.T) {
+	Configure("")
+	RecordWorkflowStarted("default")
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	body := recorder.Body.String()
+	assert.True(t, strings.Contains(body, `thand_workflows_started_total{workflow="default"} 1`))
+	assert.True(t, strings.Contains(body, "go_goroutines"))
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// VarsContextApprovalChanges records approvers that changed their decision
+// while the approvals task was waiting
+const VarsContextApprovalChanges = "approval_changes"
+
+// ApprovalIdempotencyKeyField is the field of the approval signal data
+// holding its idempotency key
+const ApprovalIdempotencyKeyField = "idempotency_key"
+
+// ApprovalChange is recorded when an approver replaces their decision. The
+// latest decision is the one that counts.
+type ApprovalChange struct {
+	Approver       string `json:"approver"`
+	Previous       bool   `json:"previous"`
+	Approved       bool   `json:"approved"`
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	Timestamp      string `json:"timestamp"`
+}
+
+// NormalizeApproverIdentity returns the identity used to tell approvers
+// apart so the same approver isn't counted twice e.g. when an integration
+// changes the case of their email
+func NormalizeApproverIdentity(identity string) string {
+	return strings.ToLower(strings.TrimSpace(identity))
+}
+
+// NewApprovalIdempotencyKey returns the key for an approver's decision on
+// a workflow. Signals without a key use this so resending the same
+// decision is ignored.
+func NewApprovalIdempotencyKey(approver string, workflowID string, approved bool) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s|%s|%t",
+		NormalizeApproverIdentity(approver), workflowID, approved))
+	return hex.EncodeToString(sum[:])
+}
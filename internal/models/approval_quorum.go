@@ -0,0 +1,280 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// VarsContextApprovalQuorum records the progress of the quorum policy of
+// the last approvals task so the unmet requirements can be displayed
+const VarsContextApprovalQuorum = "approval_quorum"
+
+const (
+	ApprovalQuorumOperatorAnd = "and"
+	ApprovalQuorumOperatorOr  = "or"
+)
+
+// ApprovalQuorum requires approvals from sets of approvers rather than a
+// simple count e.g.
+//
+//	quorum:
+//	  operator: and
+//	  distinct_approvers: true
+//	  requirements:
+//	    - name: security
+//	      groups: [security]
+//	    - name: owners
+//	      approvers: [alice@example.com, bob@example.com]
+//	      count: 1
+//
+// Requirements are combined with AND by default. When distinct_approvers is
+// set an approver in more than one requirement only counts towards one.
+type ApprovalQuorum struct {
+	Operator          string                `json:"operator,omitempty"`
+	DistinctApprovers bool                  `json:"distinct_approvers,omitempty"`
+	Requirements      []ApprovalRequirement `json:"requirements"`
+}
+
+// ApprovalRequirement is a bucket of approvers, selected by group
+// membership or identity, and the minimum number that must approve
+type ApprovalRequirement struct {
+	Name      string   `json:"name"`
+	Groups    []string `json:"groups,omitempty"`
+	Approvers []string `json:"approvers,omitempty"`
+	Count     int      `json:"count,omitempty" default:"1"`
+}
+
+// ApprovalQuorumStatus is the progress of each requirement of a quorum
+type ApprovalQuorumStatus struct {
+	Satisfied    bool                        `json:"satisfied"`
+	Requirements []ApprovalRequirementStatus `json:"requirements"`
+}
+
+// ApprovalRequirementStatus is the progress of a single requirement
+type ApprovalRequirementStatus struct {
+	Name     string `json:"name"`
+	Required int    `json:"required"`
+	Received int    `json:"received"`
+	Met      bool   `json:"met"`
+}
+
+// Validate checks the operator and that every requirement can be met
+func (q *ApprovalQuorum) Validate() error {
+
+	switch strings.ToLower(q.Operator) {
+	case "", ApprovalQuorumOperatorAnd, ApprovalQuorumOperatorOr:
+	default:
+		return fmt.Errorf("unsupported quorum operator: %s", q.Operator)
+	}
+
+	if len(q.Requirements) == 0 {
+		return fmt.Errorf("quorum must have at least one requirement")
+	}
+
+	names := map[string]bool{}
+
+	for _, requirement := range q.Requirements {
+
+		if len(requirement.Name) == 0 {
+			return fmt.Errorf("quorum requirements must be named")
+		}
+
+		if names[requirement.Name] {
+			return fmt.Errorf("duplicate quorum requirement: %s", requirement.Name)
+		}
+		names[requirement.Name] = true
+
+		if len(requirement.Groups) == 0 && len(requirement.Approvers) == 0 {
+			return fmt.Errorf("quorum requirement %s must select groups or approvers", requirement.Name)
+		}
+
+		if requirement.Count < 0 {
+			return fmt.Errorf("quorum requirement %s has a negative count", requirement.Name)
+		}
+	}
+
+	return nil
+}
+
+// IsAny returns true if meeting any one requirement satisfies the quorum
+func (q *ApprovalQuorum) IsAny() bool {
+	return strings.EqualFold(q.Operator, ApprovalQuorumOperatorOr)
+}
+
+// GetRequired returns the minimum number of approvals, defaulting to one
+func (r *ApprovalRequirement) GetRequired() int {
+	if r.Count <= 0 {
+		return 1
+	}
+	return r.Count
+}
+
+// Matches returns true if the approver is selected by the requirement,
+// either directly or through one of the user's groups
+func (r *ApprovalRequirement) Matches(identity string, user *User) bool {
+
+	candidates := []string{identity}
+
+	if user != nil {
+		candidates = append(candidates, user.Email, user.Username)
+	}
+
+	for _, approver := range r.Approvers {
+		for _, candidate := range candidates {
+			if len(candidate) > 0 && strings.EqualFold(approver, candidate) {
+				return true
+			}
+		}
+	}
+
+	if user == nil {
+		return false
+	}
+
+	for _, group := range r.Groups {
+		if slices.ContainsFunc(user.Groups, func(userGroup string) bool {
+			return strings.EqualFold(group, userGroup)
+		}) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchRequirements returns the names of the requirements the approver
+// satisfies. These are recorded with the approval when it arrives.
+func (q *ApprovalQuorum) MatchRequirements(identity string, user *User) []string {
+
+	matched := []string{}
+
+	for _, requirement := range q.Requirements {
+		if requirement.Matches(identity, user) {
+			matched = append(matched, requirement.Name)
+		}
+	}
+
+	return matched
+}
+
+// Evaluate works out which requirements are met by the approvers and the
+// requirements each of them satisfied. With distinct approvers each
+// approver is assigned to at most one requirement, picking the assignment
+// that fills the most approvals.
+func (q *ApprovalQuorum) Evaluate(approvers map[string][]string) *ApprovalQuorumStatus {
+
+	received := make([]int, len(q.Requirements))
+
+	if q.DistinctApprovers && !q.IsAny() {
+		received = q.assignDistinctApprovers(approvers)
+	} else {
+		for i, requirement := range q.Requirements {
+			for _, satisfied := range approvers {
+				if slices.Contains(satisfied, requirement.Name) {
+					received[i]++
+				}
+			}
+		}
+	}
+
+	status := &ApprovalQuorumStatus{
+		Satisfied:    !q.IsAny(),
+		Requirements: make([]ApprovalRequirementStatus, 0, len(q.Requirements)),
+	}
+
+	for i, requirement := range q.Requirements {
+
+		required := requirement.GetRequired()
+		met := received[i] >= required
+
+		status.Requirements = append(status.Requirements, ApprovalRequirementStatus{
+			Name:     requirement.Name,
+			Required: required,
+			Received: received[i],
+			Met:      met,
+		})
+
+		if q.IsAny() {
+			status.Satisfied = status.Satisfied || met
+		} else {
+			status.Satisfied = status.Satisfied && met
+		}
+	}
+
+	return status
+}
+
+// assignDistinctApprovers matches approvers to the open slots of each
+// requirement using augmenting paths so one approver fills one slot at most
+func (q *ApprovalQuorum) assignDistinctApprovers(approvers map[string][]string) []int {
+
+	// Each requirement has as many slots as approvals it needs
+	var slots []int
+	for i, requirement := range q.Requirements {
+		for range requirement.GetRequired() {
+			slots = append(slots, i)
+		}
+	}
+
+	// Sorted so the assignment is the same on replay
+	names := make([]string, 0, len(approvers))
+	for name := range approvers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	slotOwner := make([]int, len(slots))
+	for i := range slotOwner {
+		slotOwner[i] = -1
+	}
+
+	var assign func(approver int, visited []bool) bool
+	assign = func(approver int, visited []bool) bool {
+		for slot, requirement := range slots {
+			if visited[slot] || !slices.Contains(approvers[names[approver]], q.Requirements[requirement].Name) {
+				continue
+			}
+			visited[slot] = true
+			if slotOwner[slot] < 0 || assign(slotOwner[slot], visited) {
+				slotOwner[slot] = approver
+				return true
+			}
+		}
+		return false
+	}
+
+	for approver := range names {
+		assign(approver, make([]bool, len(slots)))
+	}
+
+	received := make([]int, len(q.Requirements))
+	for slot, owner := range slotOwner {
+		if owner >= 0 {
+			received[slots[slot]]++
+		}
+	}
+
+	return received
+}
+
+// GetUnmet returns the requirements that still need approvals
+func (s *ApprovalQuorumStatus) GetUnmet() []ApprovalRequirementStatus {
+	var unmet []ApprovalRequirementStatus
+	for _, requirement := range s.Requirements {
+		if !requirement.Met {
+			unmet = append(unmet, requirement)
+		}
+	}
+	return unmet
+}
+
+// String summarises the unmet requirements e.g. "security (0 of 1)"
+func (s *ApprovalQuorumStatus) String() string {
+	var unmet []string
+	for _, requirement := range s.GetUnmet() {
+		unmet = append(unmet, fmt.Sprintf("%s (%d of %d)",
+			requirement.Name, requirement.Received, requirement.Required))
+	}
+	return strings.Join(unmet, ", ")
+}
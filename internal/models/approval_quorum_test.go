@@ -0,0 +1,157 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSecurityOwnersQuorum(distinct bool) *ApprovalQuorum {
+	return &ApprovalQuorum{
+		DistinctApprovers: distinct,
+		Requirements: []ApprovalRequirement{
+			{Name: "security", Groups: []string{"security"}},
+			{Name: "owners", Approvers: []string{"alice@example.com", "bob@example.com"}},
+		},
+	}
+}
+
+func TestApprovalQuorum_Validate(t *testing.T) {
+	assert.NoError(t, newSecurityOwnersQuorum(false).Validate())
+
+	assert.ErrorContains(t, (&ApprovalQuorum{}).Validate(), "at least one requirement")
+	assert.ErrorContains(t, (&ApprovalQuorum{Operator: "xor", Requirements: []ApprovalRequirement{
+		{Name: "security", Groups: []string{"security"}},
+	}}).Validate(), "unsupported quorum operator")
+	assert.ErrorContains(t, (&ApprovalQuorum{Requirements: []ApprovalRequirement{
+		{Name: "security"},
+	}}).Validate(), "must select groups or approvers")
+	assert.ErrorContains(t, (&ApprovalQuorum{Requirements: []ApprovalRequirement{
+		{Name: "security", Groups: []string{"security"}},
+		{Name: "security", Groups: []string{"sre"}},
+	}}).Validate(), "duplicate quorum requirement")
+}
+
+func TestApprovalQuorum_MatchRequirements(t *testing.T) {
+	quorum := newSecurityOwnersQuorum(false)
+
+	assert.Equal(t, []string{"security"}, quorum.MatchRequirements(
+		"carol@example.com", &User{Email: "carol@example.com", Groups: []string{"Security"}}))
+	assert.Equal(t, []string{"owners"}, quorum.MatchRequirements(
+		"alice@example.com", nil))
+	assert.Equal(t, []string{"security", "owners"}, quorum.MatchRequirements(
+		"bob@example.com", &User{Email: "bob@example.com", Groups: []string{"security"}}))
+	assert.Empty(t, quorum.MatchRequirements(
+		"dave@example.com", &User{Email: "dave@example.com", Groups: []string{"engineering"}}))
+}
+
+func TestApprovalQuorum_Evaluate(t *testing.T) {
+	tests := []struct {
+		name              string
+		quorum            *ApprovalQuorum
+		approvers         map[string][]string
+		expectedMet       []bool
+		expectedSatisfied bool
+	}{
+		{
+			name:        "no approvals",
+			quorum:      newSecurityOwnersQuorum(false),
+			approvers:   map[string][]string{},
+			expectedMet: []bool{false, false},
+		},
+		{
+			name:   "only security approved",
+			quorum: newSecurityOwnersQuorum(false),
+			approvers: map[string][]string{
+				"carol@example.com": {"security"},
+			},
+			expectedMet: []bool{true, false},
+		},
+		{
+			name:   "both requirements approved",
+			quorum: newSecurityOwnersQuorum(false),
+			approvers: map[string][]string{
+				"carol@example.com": {"security"},
+				"alice@example.com": {"owners"},
+			},
+			expectedMet:       []bool{true, true},
+			expectedSatisfied: true,
+		},
+		{
+			name:   "overlapping approver counts for both",
+			quorum: newSecurityOwnersQuorum(false),
+			approvers: map[string][]string{
+				"bob@example.com": {"security", "owners"},
+			},
+			expectedMet:       []bool{true, true},
+			expectedSatisfied: true,
+		},
+		{
+			name:   "overlapping approver counts for one when distinct",
+			quorum: newSecurityOwnersQuorum(true),
+			approvers: map[string][]string{
+				"bob@example.com": {"security", "owners"},
+			},
+			expectedMet: []bool{true, false},
+		},
+		{
+			name:   "overlapping approver is reassigned when distinct",
+			quorum: newSecurityOwnersQuorum(true),
+			approvers: map[string][]string{
+				// Sorted first so bob takes security before carol arrives
+				"bob@example.com":   {"security", "owners"},
+				"carol@example.com": {"security"},
+			},
+			expectedMet:       []bool{true, true},
+			expectedSatisfied: true,
+		},
+		{
+			name: "or needs any requirement",
+			quorum: &ApprovalQuorum{
+				Operator:     ApprovalQuorumOperatorOr,
+				Requirements: newSecurityOwnersQuorum(false).Requirements,
+			},
+			approvers: map[string][]string{
+				"alice@example.com": {"owners"},
+			},
+			expectedMet:       []bool{false, true},
+			expectedSatisfied: true,
+		},
+		{
+			name: "count needs more than one approver",
+			quorum: &ApprovalQuorum{
+				Requirements: []ApprovalRequirement{
+					{Name: "security", Groups: []string{"security"}, Count: 2},
+				},
+			},
+			approvers: map[string][]string{
+				"carol@example.com": {"security"},
+				"alice@example.com": {},
+			},
+			expectedMet: []bool{false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.quorum.Evaluate(tt.approvers)
+
+			assert.Equal(t, tt.expectedSatisfied, status.Satisfied)
+
+			met := make([]bool, 0, len(status.Requirements))
+			for _, requirement := range status.Requirements {
+				met = append(met, requirement.Met)
+			}
+			assert.Equal(t, tt.expectedMet, met)
+		})
+	}
+}
+
+func TestApprovalQuorumStatus_String(t *testing.T) {
+	status := newSecurityOwnersQuorum(false).Evaluate(map[string][]string{
+		"carol@example.com": {"security"},
+	})
+
+	assert.Equal(t, "owners (0 of 1)", status.String())
+	assert.Len(t, status.GetUnmet(), 1)
+}
@@ -0,0 +1,230 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ApprovalRoutingFollowTheSun notifies the approvers that are currently
+// within their working hours
+const ApprovalRoutingFollowTheSun = "follow_the_sun"
+
+// VarsContextApprovalRouting records the routing decision of the last
+// approvals task for audit
+const VarsContextApprovalRouting = "approval_routing"
+
+var defaultWorkingDays = []string{"mon", "tue", "wed", "thu", "fri"}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ApproverSchedule describes the working hours of an approver or a group
+// of approvers e.g.
+//
+//	eu:
+//	  timezone: Europe/London
+//	  start: "09:00"
+//	  end: "17:30"
+//	  days: [mon, tue, wed, thu, fri]
+//	  members: [alice@example.com, bob@example.com]
+//
+// Shifts that end before they start run overnight into the next day.
+type ApproverSchedule struct {
+	Timezone string   `json:"timezone" yaml:"timezone" mapstructure:"timezone"`
+	Start    string   `json:"start" yaml:"start" mapstructure:"start" default:"09:00"`
+	End      string   `json:"end" yaml:"end" mapstructure:"end" default:"17:00"`
+	Days     []string `json:"days,omitempty" yaml:"days,omitempty" mapstructure:"days"`
+	Members  []string `json:"members" yaml:"members" mapstructure:"members"`
+}
+
+// IsWorking returns true if the time is within the schedule's working
+// hours. Hours are compared in the schedule's timezone so daylight saving
+// changes move with the local clock.
+func (s *ApproverSchedule) IsWorking(now time.Time) (bool, error) {
+
+	location, err := time.LoadLocation(s.Timezone)
+
+	if err != nil {
+		return false, fmt.Errorf("invalid timezone %q: %w", s.Timezone, err)
+	}
+
+	start, err := parseClock(s.Start, "09:00")
+
+	if err != nil {
+		return false, err
+	}
+
+	end, err := parseClock(s.End, "17:00")
+
+	if err != nil {
+		return false, err
+	}
+
+	days, err := s.getWorkingDays()
+
+	if err != nil {
+		return false, err
+	}
+
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+
+	switch {
+	case start == end:
+		// Working all day
+		return slices.Contains(days, local.Weekday()), nil
+	case start < end:
+		return slices.Contains(days, local.Weekday()) &&
+			minute >= start && minute < end, nil
+	case minute >= start:
+		// Overnight shift that started today
+		return slices.Contains(days, local.Weekday()), nil
+	case minute < end:
+		// Overnight shift that started yesterday
+		return slices.Contains(days, local.AddDate(0, 0, -1).Weekday()), nil
+	}
+
+	return false, nil
+}
+
+func (s *ApproverSchedule) getWorkingDays() ([]time.Weekday, error) {
+
+	names := s.Days
+
+	if len(names) == 0 {
+		names = defaultWorkingDays
+	}
+
+	days := make([]time.Weekday, 0, len(names))
+
+	for _, name := range names {
+
+		day, found := weekdays[strings.ToLower(name)[:min(3, len(name))]]
+
+		if !found {
+			return nil, fmt.Errorf("invalid working day: %s", name)
+		}
+
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+// parseClock converts a 24 hour clock time e.g. 09:30 to minutes since
+// midnight
+func parseClock(clock string, defaultClock string) (int, error) {
+
+	if len(clock) == 0 {
+		clock = defaultClock
+	}
+
+	parsed, err := time.Parse("15:04", clock)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid working hours time %q, expected HH:MM: %w", clock, err)
+	}
+
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// ApprovalRouting records which approvers were notified and why
+type ApprovalRouting struct {
+	Mode        string    `json:"mode"`
+	EvaluatedAt time.Time `json:"evaluated_at"`
+
+	// Recipients that were notified, approvers on duty first
+	Recipients []string `json:"recipients"`
+	// OnDuty and OffDuty are the scheduled approvers within and outside
+	// of their working hours
+	OnDuty  []string `json:"on_duty,omitempty"`
+	OffDuty []string `json:"off_duty,omitempty"`
+	// Schedules that were on duty
+	Schedules []string `json:"schedules,omitempty"`
+	// Fallback is true if nobody was on duty so everyone was notified
+	Fallback bool `json:"fallback"`
+	// Errors from schedules that could not be evaluated. Their members
+	// are treated as unscheduled.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// RouteFollowTheSun orders and filters the recipients by who is currently
+// within their working hours. Recipients without a schedule are always
+// notified. If none of the scheduled recipients are working everyone is
+// notified.
+func RouteFollowTheSun(
+	recipients []string,
+	schedules map[string]ApproverSchedule,
+	now time.Time,
+) *ApprovalRouting {
+
+	routing := &ApprovalRouting{
+		Mode:        ApprovalRoutingFollowTheSun,
+		EvaluatedAt: now.UTC(),
+	}
+
+	// Evaluate schedules in name order so the decision is stable
+	names := make([]string, 0, len(schedules))
+	for name := range schedules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// A recipient in several schedules is on duty if any of them are
+	scheduled := map[string]bool{}
+
+	for _, name := range names {
+
+		schedule := schedules[name]
+		working, err := schedule.IsWorking(now)
+
+		if err != nil {
+			routing.Errors = append(routing.Errors, fmt.Sprintf("%s: %s", name, err.Error()))
+			continue
+		}
+
+		if working {
+			routing.Schedules = append(routing.Schedules, name)
+		}
+
+		for _, member := range schedule.Members {
+			scheduled[member] = scheduled[member] || working
+		}
+	}
+
+	var unscheduled []string
+
+	for _, recipient := range recipients {
+
+		working, found := scheduled[recipient]
+
+		switch {
+		case !found:
+			unscheduled = append(unscheduled, recipient)
+		case working:
+			routing.OnDuty = append(routing.OnDuty, recipient)
+		default:
+			routing.OffDuty = append(routing.OffDuty, recipient)
+		}
+	}
+
+	routing.Recipients = append(routing.Recipients, routing.OnDuty...)
+	routing.Recipients = append(routing.Recipients, unscheduled...)
+
+	if len(routing.OnDuty) == 0 && len(routing.OffDuty) > 0 {
+		routing.Fallback = true
+		routing.Recipients = append(routing.Recipients, routing.OffDuty...)
+	}
+
+	return routing
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	require.NoError(t, err)
+	return parsed
+}
+
+func TestApproverScheduleIsWorking(t *testing.T) {
+	newYork := ApproverSchedule{Timezone: "America/New_York", Start: "09:00", End: "17:00"}
+	overnight := ApproverSchedule{Timezone: "Asia/Singapore", Start: "22:00", End: "06:00", Days: []string{"monday"}}
+
+	tests := []struct {
+		name     string
+		schedule ApproverSchedule
+		now      string
+		expected bool
+	}{
+		{"within hours", newYork, "2025-01-15T15:00:00Z", true},
+		{"before start", newYork, "2025-01-15T13:59:00Z", false},
+		{"end is exclusive", newYork, "2025-01-15T22:00:00Z", false},
+		{"weekend", newYork, "2025-01-18T15:00:00Z", false},
+
+		// New York moves from UTC-5 to UTC-4 on 9 March 2025 so 09:00
+		// local is 14:00 UTC the week before and 13:00 UTC after
+		{"before spring forward", newYork, "2025-03-07T13:30:00Z", false},
+		{"after spring forward", newYork, "2025-03-10T13:30:00Z", true},
+		{"before fall back", newYork, "2025-10-31T20:30:00Z", true},
+		{"after fall back", newYork, "2025-11-03T21:30:00Z", true},
+		{"after fall back end", newYork, "2025-11-03T22:00:00Z", false},
+
+		{"overnight shift start", overnight, "2025-01-13T14:30:00Z", true},        // Mon 22:30
+		{"overnight shift carries over", overnight, "2025-01-13T21:00:00Z", true}, // Tue 05:00
+		{"overnight shift ended", overnight, "2025-01-13T23:00:00Z", false},       // Tue 07:00
+		{"overnight shift not started", overnight, "2025-01-14T14:30:00Z", false}, // Tue 22:30
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			working, err := tt.schedule.IsWorking(mustTime(t, tt.now))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, working)
+		})
+	}
+}
+
+func TestApproverScheduleErrors(t *testing.T) {
+	now := time.Now()
+
+	_, err := (&ApproverSchedule{Timezone: "Mars/Olympus"}).IsWorking(now)
+	assert.ErrorContains(t, err, "invalid timezone")
+
+	_, err = (&ApproverSchedule{Timezone: "UTC", Start: "9am"}).IsWorking(now)
+	assert.ErrorContains(t, err, "expected HH:MM")
+
+	_, err = (&ApproverSchedule{Timezone: "UTC", Days: []string{"someday"}}).IsWorking(now)
+	assert.ErrorContains(t, err, "invalid working day")
+}
+
+func TestRouteFollowTheSun(t *testing.T) {
+	schedules := map[string]ApproverSchedule{
+		"us":   {Timezone: "America/New_York", Members: []string{"us@example.com"}},
+		"eu":   {Timezone: "Europe/Berlin", Members: []string{"eu@example.com"}},
+		"apac": {Timezone: "Australia/Sydney", Members: []string{"apac@example.com"}},
+	}
+
+	recipients := []string{"us@example.com", "eu@example.com", "apac@example.com", "oncall@example.com"}
+
+	t.Run("notifies the region on duty", func(t *testing.T) {
+		// Wednesday 10:00 in Berlin
+		routing := RouteFollowTheSun(recipients, schedules, mustTime(t, "2025-01-15T09:00:00Z"))
+
+		assert.Equal(t, ApprovalRoutingFollowTheSun, routing.Mode)
+		assert.Equal(t, []string{"eu@example.com"}, routing.OnDuty)
+		assert.Equal(t, []string{"eu"}, routing.Schedules)
+		assert.ElementsMatch(t, []string{"us@example.com", "apac@example.com"}, routing.OffDuty)
+		assert.False(t, routing.Fallback)
+
+		// Unscheduled recipients are always notified after those on duty
+		assert.Equal(t, []string{"eu@example.com", "oncall@example.com"}, routing.Recipients)
+	})
+
+	t.Run("falls back to everyone when no region is on duty", func(t *testing.T) {
+		// Saturday, nobody works weekends
+		routing := RouteFollowTheSun(recipients, schedules, mustTime(t, "2025-01-18T12:00:00Z"))
+
+		assert.Empty(t, routing.OnDuty)
+		assert.True(t, routing.Fallback)
+		assert.ElementsMatch(t, recipients, routing.Recipients)
+	})
+
+	t.Run("invalid schedules are treated as unscheduled", func(t *testing.T) {
+		routing := RouteFollowTheSun(
+			[]string{"broken@example.com"},
+			map[string]ApproverSchedule{
+				"broken": {Timezone: "Nowhere/Nowhere", Members: []string{"broken@example.com"}},
+			},
+			time.Now(),
+		)
+
+		assert.Len(t, routing.Errors, 1)
+		assert.Equal(t, []string{"broken@example.com"}, routing.Recipients)
+		assert.False(t, routing.Fallback)
+	})
+}
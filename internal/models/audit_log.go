@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Audit log streams
+const (
+	AuditLogStreamStdout = "stdout"
+	AuditLogStreamFile   = "file"
+	AuditLogStreamSyslog = "syslog" // Uses the logging.syslog server
+)
+
+// AuditLogConfig sends security events e.g. failed logins or requests from
+// blocked IPs to their own stream, separate from the access logs. Nothing
+// is written unless a stream or path is set.
+type AuditLogConfig struct {
+	Stream string `mapstructure:"stream" json:"stream,omitempty"` // stdout, file or syslog
+	Path   string `mapstructure:"path" json:"path,omitempty"`     // File to append to, implies the file stream
+}
+
+// GetStream returns the stream, the file stream when only a path is set
+func (a *AuditLogConfig) GetStream() string {
+	if len(a.Stream) == 0 && len(a.Path) > 0 {
+		return AuditLogStreamFile
+	}
+	return strings.ToLower(a.Stream)
+}
+
+// IsEnabled returns true if security events are written anywhere
+func (a *AuditLogConfig) IsEnabled() bool {
+	return len(a.GetStream()) > 0
+}
+
+// Validate checks the stream is known and the file stream has a path
+func (a *AuditLogConfig) Validate() error {
+	switch a.GetStream() {
+	case "", AuditLogStreamStdout, AuditLogStreamSyslog:
+		return nil
+	case AuditLogStreamFile:
+		if len(a.Path) == 0 {
+			return fmt.Errorf("audit_log.path is required for the file stream")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown audit_log.stream %q, expected %s, %s or %s",
+		a.Stream, AuditLogStreamStdout, AuditLogStreamFile, AuditLogStreamSyslog)
+}
@@ -13,6 +13,11 @@ type AuthWrapper struct {
 	Client   string `json:"client"`
 	Provider string `json:"provider"`
 	Code     string `json:"code,omitempty"` // Optional code if coming from client/cli
+
+	// Nonce binds the state to the browser that started the login. It's
+	// checked against the state cookie on callback and can only be used once.
+	Nonce     string    `json:"nonce,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
 }
 
 func NewAuthWrapper(
@@ -29,6 +34,12 @@ func NewAuthWrapper(
 	}
 }
 
+// IsExpired returns true if the login wasn't completed in time. States
+// without an expiry are treated as expired.
+func (aw AuthWrapper) IsExpired() bool {
+	return aw.ExpiresAt.IsZero() || time.Now().UTC().After(aw.ExpiresAt)
+}
+
 // Only to be used agent/client side. The code is to provide
 // what client request was made to create the session.
 type CodeWrapper struct {
@@ -54,3 +65,71 @@ func (cw CodeWrapper) IsValid(loginEndpoint string) bool {
 
 	return time.Now().UTC().Before(cw.ExpiresAt)
 }
+
+// Device code errors returned by the token endpoint while the CLI polls
+const (
+	DeviceErrorAuthorizationPending = "authorization_pending"
+	DeviceErrorSlowDown             = "slow_down"
+	DeviceErrorAccessDenied         = "access_denied"
+	DeviceErrorExpiredToken         = "expired_token"
+	DeviceErrorInvalidRequest       = "invalid_request"
+)
+
+// DeviceCodeResponse starts the device login flow. The user enters the
+// user code at the verification URI while the CLI polls with the device
+// code.
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationUri         string `json:"verification_uri"`
+	VerificationUriComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"` // Seconds until the codes expire
+	Interval                int    `json:"interval"`   // Seconds to wait between polls
+}
+
+type DeviceTokenRequest struct {
+	DeviceCode string `json:"device_code" binding:"required"`
+}
+
+// DeviceTokenResponse is returned once the user approves the device code.
+// The session is an encoded local session, as posted to the agent by the
+// browser login.
+type DeviceTokenResponse struct {
+	Provider string    `json:"provider"`
+	Session  string    `json:"session"`
+	Expiry   time.Time `json:"expiry"`
+}
+
+type DeviceTokenError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+	Interval         int    `json:"interval,omitempty"` // New poll interval after slow_down
+}
+
+// AuthRefreshRequest renews the session of a provider. Without a provider
+// the active session is renewed, and without a refresh token the one held
+// in the session is used.
+type AuthRefreshRequest struct {
+	Provider     string `json:"provider,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// AuthRefreshResponse is returned once the session cookie is renewed
+type AuthRefreshResponse struct {
+	Provider string    `json:"provider"`
+	Expiry   time.Time `json:"expiry"`
+}
+
+// AuthRenewRequest renews the session presented as the bearer token.
+// Without a provider the active session is renewed.
+type AuthRenewRequest struct {
+	Provider string `json:"provider,omitempty"`
+}
+
+// AuthRenewResponse is the renewed session for the CLI to store in place
+// of the one it presented
+type AuthRenewResponse struct {
+	Provider string    `json:"provider"`
+	Session  string    `json:"session"` // encoded local session
+	Expiry   time.Time `json:"expiry"`
+}
@@ -157,7 +157,7 @@ func (pc *BasicConfig) GetString(key string) (string, bool) {
 	}
 	if value, ok := (*pc)[key]; ok {
 		if strValue, ok := value.(string); ok {
-			return strValue, true
+			return pc.resolveString(key, strValue)
 		}
 	}
 	return "", false
@@ -235,7 +235,9 @@ func (pc *BasicConfig) GetStringWithDefault(key string, defaultValue string) str
 	}
 	if value, ok := (*pc)[key]; ok {
 		if strValue, ok := value.(string); ok {
-			return strValue
+			if resolved, ok := pc.resolveString(key, strValue); ok {
+				return resolved
+			}
 		}
 	}
 	return defaultValue
@@ -258,8 +260,20 @@ func (pc *BasicConfig) GetStringSlice(key string) ([]string, bool) {
 		return nil, false
 	}
 	if value, ok := (*pc)[key]; ok {
-		if sliceValue, ok := value.([]string); ok {
+		switch sliceValue := value.(type) {
+		case []string:
 			return sliceValue, true
+		case []any:
+			// Lists decoded from config files
+			values := make([]string, 0, len(sliceValue))
+			for _, item := range sliceValue {
+				str, ok := item.(string)
+				if !ok {
+					return nil, false
+				}
+				values = append(values, str)
+			}
+			return values, true
 		}
 	}
 	return nil, false
@@ -292,6 +306,35 @@ func (pc *BasicConfig) Update(updateMap map[string]any) {
 	maps.Copy((*pc), updateMap)
 }
 
+// WithDefaults returns a new config with the defaults merged underneath.
+// Nested maps are merged key by key and values set in this config always
+// take precedence, so the defaults are never modified.
+func (pc *BasicConfig) WithDefaults(defaults *BasicConfig) *BasicConfig {
+	merged := BasicConfig(mergeConfigMaps(defaults.AsMap(), pc.AsMap()))
+	return &merged
+}
+
+func mergeConfigMaps(defaults map[string]any, overrides map[string]any) map[string]any {
+
+	merged := make(map[string]any, len(defaults)+len(overrides))
+	maps.Copy(merged, defaults)
+
+	for key, value := range overrides {
+
+		defaultMap, defaultIsMap := merged[key].(map[string]any)
+		valueMap, valueIsMap := value.(map[string]any)
+
+		if defaultIsMap && valueIsMap {
+			merged[key] = mergeConfigMaps(defaultMap, valueMap)
+			continue
+		}
+
+		merged[key] = value
+	}
+
+	return merged
+}
+
 // ConvertVersionToString converts a version field from any type to a string.
 // This is a helper function for UnmarshalJSON and UnmarshalYAML methods
 // to handle version fields that may be parsed as different types (string, int, float64, etc.)
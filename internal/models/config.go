@@ -1,19 +1,223 @@
 package models
 
 import (
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/serverlessworkflow/sdk-go/v3/model"
 )
 
 type ServerConfig struct {
-	Host     string             `json:"host" yaml:"host" mapstructure:"host"`
-	Port     int                `json:"port" yaml:"port" mapstructure:"port"`
+	Host string `json:"host" yaml:"host" mapstructure:"host"`
+	Port int    `json:"port" yaml:"port" mapstructure:"port"`
+	// RootUrl is the public URL the server is reached at. Auth flows may
+	// only return to this URL, loopback addresses or the allowed redirects.
+	// Defaults to the login endpoint.
+	RootUrl  string             `json:"root_url" yaml:"root_url" mapstructure:"root_url"`
 	Limits   ServerLimitsConfig `json:"limits" yaml:"limits" mapstructure:"limits"`
 	Metrics  MetricsConfig      `json:"metrics" yaml:"metrics" mapstructure:"metrics"`
 	Health   HealthConfig       `json:"health" yaml:"health" mapstructure:"health"`
 	Ready    ReadyConfig        `json:"ready" yaml:"ready" mapstructure:"ready"`
 	Security SecurityConfig     `json:"security" yaml:"security" mapstructure:"security"`
+	TLS      TLSConfig          `json:"tls" yaml:"tls" mapstructure:"tls"`
+
+	// CIDR blocks or IPs of the load balancers and proxies in front of the
+	// server. Client IPs are only read from X-Forwarded-For when the request
+	// comes from one of these. Empty trusts no proxies.
+	TrustedProxies []string `json:"trusted_proxies" yaml:"trusted_proxies" mapstructure:"trusted_proxies"`
+
+	// CIDR blocks or IPs allowed to use the admin endpoints e.g. revoking
+	// or terminating workflows. Empty allows any client.
+	AdminAllowlist []string `json:"admin_allowlist" yaml:"admin_allowlist" mapstructure:"admin_allowlist"`
+
+	// Device code login for CLIs that can't open a browser
+	DeviceCode DeviceCodeConfig `json:"device_code" yaml:"device_code" mapstructure:"device_code"`
+
+	// Forms limits the form pages rendered for workflow form tasks
+	Forms FormsConfig `json:"forms" yaml:"forms" mapstructure:"forms"`
+
+	// Signals controls how signals to running workflows e.g. approvals are
+	// received
+	Signals SignalsConfig `json:"signals" yaml:"signals" mapstructure:"signals"`
+}
+
+// DeviceCodeConfig controls how long device codes are valid for and how
+// often the CLI can poll for the session
+type DeviceCodeConfig struct {
+	Expiry   time.Duration `json:"expiry" yaml:"expiry" mapstructure:"expiry" default:"10m"`
+	Interval time.Duration `json:"interval" yaml:"interval" mapstructure:"interval" default:"5s"`
+}
+
+// SignalsConfig controls how long the result of a signal sent with an
+// Idempotency-Key header is kept to answer retries of it
+type SignalsConfig struct {
+	IdempotencyWindow time.Duration `json:"idempotency_window" yaml:"idempotency_window" mapstructure:"idempotency_window" default:"24h"`
+}
+
+// FormsConfig limits what workflow form tasks can render. Form blocks come
+// from workflow definitions, which can be loaded from URLs or vaults, so
+// they are treated as untrusted.
+type FormsConfig struct {
+	MaxBlocks      int `json:"max_blocks" yaml:"max_blocks" mapstructure:"max_blocks" default:"50"`
+	MaxPayloadSize int `json:"max_payload_size" yaml:"max_payload_size" mapstructure:"max_payload_size" default:"65536"`
+
+	// AllowedHosts limits image and link URLs to these hosts. Entries
+	// starting with *. match any subdomain. Empty allows any https URL.
+	AllowedHosts []string `json:"allowed_hosts" yaml:"allowed_hosts" mapstructure:"allowed_hosts"`
+
+	// Files uploaded with file_input elements are kept in the storage
+	// service and passed to the workflow as signed URLs
+	MaxUploadSize    int           `json:"max_upload_size" yaml:"max_upload_size" mapstructure:"max_upload_size" default:"10485760"`
+	AllowedFileTypes []string      `json:"allowed_file_types" yaml:"allowed_file_types" mapstructure:"allowed_file_types"`
+	UploadURLExpiry  time.Duration `json:"upload_url_expiry" yaml:"upload_url_expiry" mapstructure:"upload_url_expiry" default:"168h"`
+}
+
+// Form limits used when none are configured
+const (
+	DefaultFormMaxBlocks       = 50
+	DefaultFormMaxPayloadSize  = 64 * 1024
+	DefaultFormMaxUploadSize   = 10 * 1024 * 1024
+	DefaultFormUploadURLExpiry = 7 * 24 * time.Hour
+	// FormMaxUploadFiles is the most files a file_input element accepts,
+	// the same as Slack
+	FormMaxUploadFiles = 10
+)
+
+// DefaultFormAllowedFileTypes are the MIME types that can be uploaded when
+// none are configured
+var DefaultFormAllowedFileTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"application/pdf",
+	"text/plain",
+}
+
+// GetMaxBlocks returns the most blocks a form can have
+func (f *FormsConfig) GetMaxBlocks() int {
+	if f.MaxBlocks <= 0 {
+		return DefaultFormMaxBlocks
+	}
+	return f.MaxBlocks
+}
+
+// GetMaxPayloadSize returns the largest form, and form submission, in bytes
+func (f *FormsConfig) GetMaxPayloadSize() int {
+	if f.MaxPayloadSize <= 0 {
+		return DefaultFormMaxPayloadSize
+	}
+	return f.MaxPayloadSize
+}
+
+// GetMaxUploadSize returns the largest file that can be uploaded in bytes
+func (f *FormsConfig) GetMaxUploadSize() int {
+	if f.MaxUploadSize <= 0 {
+		return DefaultFormMaxUploadSize
+	}
+	return f.MaxUploadSize
+}
+
+// GetUploadURLExpiry returns how long the signed URLs of uploaded files
+// are valid for
+func (f *FormsConfig) GetUploadURLExpiry() time.Duration {
+	if f.UploadURLExpiry <= 0 {
+		return DefaultFormUploadURLExpiry
+	}
+	return f.UploadURLExpiry
+}
+
+// IsAllowedFileType returns true if files of the MIME type can be uploaded.
+// Entries ending in /* match any subtype e.g. image/*.
+func (f *FormsConfig) IsAllowedFileType(contentType string) bool {
+
+	allowedTypes := f.AllowedFileTypes
+
+	if len(allowedTypes) == 0 {
+		allowedTypes = DefaultFormAllowedFileTypes
+	}
+
+	contentType = strings.ToLower(contentType)
+
+	return slices.ContainsFunc(allowedTypes, func(allowed string) bool {
+		allowed = strings.ToLower(allowed)
+		if prefix, found := strings.CutSuffix(allowed, "/*"); found {
+			return strings.HasPrefix(contentType, prefix+"/")
+		}
+		return contentType == allowed
+	})
+}
+
+// IsAllowedHost returns true if links and images can be loaded from the
+// host
+func (f *FormsConfig) IsAllowedHost(host string) bool {
+
+	if len(f.AllowedHosts) == 0 {
+		return true
+	}
+
+	host = strings.ToLower(host)
+
+	return slices.ContainsFunc(f.AllowedHosts, func(allowed string) bool {
+		allowed = strings.ToLower(allowed)
+		if suffix, found := strings.CutPrefix(allowed, "*."); found {
+			return strings.HasSuffix(host, "."+suffix)
+		}
+		return host == allowed
+	})
+}
+
+// TLSConfig terminates TLS in the server without a reverse proxy. Setting
+// a client CA enables mTLS and requires clients to present a certificate.
+type TLSConfig struct {
+	CertFile     string `json:"cert_file" yaml:"cert_file" mapstructure:"cert_file"`
+	KeyFile      string `json:"key_file" yaml:"key_file" mapstructure:"key_file"`
+	MinVersion   string `json:"min_version" yaml:"min_version" mapstructure:"min_version" default:"1.2"`
+	ClientCAFile string `json:"client_ca_file" yaml:"client_ca_file" mapstructure:"client_ca_file"`
+
+	// ClientCertRoutes limits mTLS to these route groups so browser facing
+	// routes stay on regular TLS. Empty requires a certificate for all routes.
+	ClientCertRoutes []string `json:"client_cert_routes" yaml:"client_cert_routes" mapstructure:"client_cert_routes"`
+}
+
+// Route groups that can require a client certificate
+const (
+	// ClientCertRouteRegistration covers agent registration and sync
+	ClientCertRouteRegistration = "registration"
+	// ClientCertRouteAdmin covers cancelling, terminating and revoking
+	// running workflows. Approval signals come from browsers so aren't included.
+	ClientCertRouteAdmin = "admin"
+)
+
+// ClientCertRouteGroups are the route groups that can require a client certificate
+var ClientCertRouteGroups = []string{
+	ClientCertRouteRegistration,
+	ClientCertRouteAdmin,
+}
+
+// IsEnabled returns true if a certificate has been configured
+func (t *TLSConfig) IsEnabled() bool {
+	return len(t.CertFile) > 0 || len(t.KeyFile) > 0
+}
+
+// HasClientCA returns true if client certificates should be verified
+func (t *TLSConfig) HasClientCA() bool {
+	return len(t.ClientCAFile) > 0
+}
+
+// HasClientCertRoutes returns true if client certificates are only
+// required for some route groups
+func (t *TLSConfig) HasClientCertRoutes() bool {
+	return t.HasClientCA() && len(t.ClientCertRoutes) > 0
+}
+
+// RequiresClientCert returns true if the route group requires a client
+// certificate
+func (t *TLSConfig) RequiresClientCert(group string) bool {
+	if !t.IsEnabled() || !t.HasClientCA() {
+		return false
+	}
+	return len(t.ClientCertRoutes) == 0 || slices.Contains(t.ClientCertRoutes, group)
 }
 
 type ServerLimitsConfig struct {
@@ -27,6 +231,16 @@ type ServerLimitsConfig struct {
 type LoginConfig struct {
 	Endpoint string `json:"endpoint" yaml:"endpoint" mapstructure:"endpoint" default:"https://auth.thand.io/"`
 	Base     string `json:"base" yaml:"base" mapstructure:"base" default:"/"` // Base path for login endpoint e.g. /
+
+	// RenewBefore renews sessions that expire within this long before
+	// calling the login server. Zero disables renewal.
+	RenewBefore time.Duration `json:"renew_before" yaml:"renew_before" mapstructure:"renew_before" default:"10m"`
+}
+
+// GeoConfig looks up the country sessions are created from
+type GeoConfig struct {
+	// MaxMindDBPath is a MaxMind GeoIP2 or GeoLite2 country or city database
+	MaxMindDBPath string `json:"maxmind_db_path" yaml:"maxmind_db_path" mapstructure:"maxmind_db_path"`
 }
 
 type LoggingConfig struct {
@@ -34,7 +248,50 @@ type LoggingConfig struct {
 	Format string `json:"format" yaml:"format" mapstructure:"format" default:"text"`
 	Output string `json:"output" yaml:"output" mapstructure:"output"`
 
+	// Mask email addresses in log lines. Tokens and secrets are always masked
+	RedactEmails bool `json:"redact_emails" yaml:"redact_emails" mapstructure:"redact_emails" default:"true"`
+
 	OpenTelemetry OpenTelemetryConfig `json:"open_telemetry" yaml:"open_telemetry" mapstructure:"open_telemetry"`
+
+	// Syslog sends elevation events to a syslog server e.g. for a SIEM
+	Syslog SyslogConfig `json:"syslog" yaml:"syslog" mapstructure:"syslog"`
+}
+
+// Syslog protocols
+const (
+	SyslogProtocolUDP    = "udp"
+	SyslogProtocolTCP    = "tcp"
+	SyslogProtocolTCPTLS = "tcp+tls"
+)
+
+// Syslog message formats
+const (
+	SyslogFormatRFC5424 = "rfc5424" // RFC 5424 message with a JSON payload
+	SyslogFormatCEF     = "cef"     // RFC 5424 message with a CEF payload
+)
+
+type SyslogConfig struct {
+	Enabled  bool   `json:"enabled" yaml:"enabled" mapstructure:"enabled" default:"false"`
+	Protocol string `json:"protocol" yaml:"protocol" mapstructure:"protocol" default:"udp"` // udp, tcp or tcp+tls
+	Address  string `json:"address" yaml:"address" mapstructure:"address"`                  // host:port of the syslog server
+	Facility string `json:"facility" yaml:"facility" mapstructure:"facility" default:"local0"`
+	Format   string `json:"format" yaml:"format" mapstructure:"format" default:"rfc5424"` // rfc5424 or cef
+	AppName  string `json:"app_name" yaml:"app_name" mapstructure:"app_name" default:"thand"`
+
+	// QueueSize is how many events are buffered while the server is
+	// unreachable. Events are dropped once the queue is full.
+	QueueSize int `json:"queue_size" yaml:"queue_size" mapstructure:"queue_size" default:"1000"`
+
+	TLS SyslogTLSConfig `json:"tls" yaml:"tls" mapstructure:"tls"`
+}
+
+// SyslogTLSConfig verifies the syslog server for tcp+tls. The system roots
+// are used when no CA file is set.
+type SyslogTLSConfig struct {
+	CAFile     string `json:"ca_file" yaml:"ca_file" mapstructure:"ca_file"`
+	ServerName string `json:"server_name" yaml:"server_name" mapstructure:"server_name"` // Defaults to the host of the address
+	CertFile   string `json:"cert_file" yaml:"cert_file" mapstructure:"cert_file"`       // Optional client certificate
+	KeyFile    string `json:"key_file" yaml:"key_file" mapstructure:"key_file"`
 }
 
 type OpenTelemetryConfig struct {
@@ -57,6 +314,12 @@ type MetricsConfig struct {
 	Enabled   bool   `json:"enabled" yaml:"enabled" mapstructure:"enabled" default:"true"`
 	Path      string `json:"path" yaml:"path" mapstructure:"path" default:"/metrics"`
 	Namespace string `json:"namespace" yaml:"namespace" mapstructure:"namespace"`
+	Port      int    `json:"port" yaml:"port" mapstructure:"port"` // Serve metrics on a separate port, 0 uses the main server
+}
+
+// HasSeparatePort returns true if metrics are served on their own port
+func (m *MetricsConfig) HasSeparatePort() bool {
+	return m.Enabled && m.Port > 0
 }
 
 type HealthConfig struct {
@@ -72,6 +335,62 @@ type ReadyConfig struct {
 
 type SecurityConfig struct {
 	CORS CORSConfig `json:"cors" yaml:"cors" mapstructure:"cors"`
+
+	// Admins are the emails of users that can follow any elevation
+	Admins []string `json:"admins" yaml:"admins" mapstructure:"admins"`
+
+	// AllowedCIDRs limits the server to clients from these CIDR blocks or
+	// IPs. Empty allows all clients.
+	AllowedCIDRs []string `json:"allowed_cidrs" yaml:"allowed_cidrs" mapstructure:"allowed_cidrs"`
+
+	// AdminScope grants admin access to users matching the users, groups
+	// or domains. It is matched like role scopes.
+	AdminScope *RoleScopes `json:"admin_scope,omitempty" yaml:"admin_scope,omitempty" mapstructure:"admin_scope"`
+
+	// AllowedRedirects are the other URLs auth flows can return to. Entries
+	// ending in * match any URL with that prefix, otherwise the URL must
+	// match exactly. An entry without a path matches the whole origin.
+	AllowedRedirects []string `json:"allowed_redirects" yaml:"allowed_redirects" mapstructure:"allowed_redirects"`
+
+	// AuthStateExpiry is how long a user has to complete a login
+	AuthStateExpiry time.Duration `json:"auth_state_expiry" yaml:"auth_state_expiry" mapstructure:"auth_state_expiry" default:"10m"`
+}
+
+// DefaultAuthStateExpiry is used when no auth state expiry is configured
+const DefaultAuthStateExpiry = 10 * time.Minute
+
+// GetAuthStateExpiry returns how long a login has to complete
+func (s *SecurityConfig) GetAuthStateExpiry() time.Duration {
+	if s.AuthStateExpiry <= 0 {
+		return DefaultAuthStateExpiry
+	}
+	return s.AuthStateExpiry
+}
+
+// IsAdmin returns true if the email belongs to an admin
+func (s *SecurityConfig) IsAdmin(email string) bool {
+	return len(email) > 0 && slices.ContainsFunc(s.Admins, func(admin string) bool {
+		return strings.EqualFold(admin, email)
+	})
+}
+
+// IsAdminUser returns true if the user is an admin or matches the admin scope
+func (s *SecurityConfig) IsAdminUser(user *User) bool {
+
+	if user == nil {
+		return false
+	}
+
+	if s.IsAdmin(user.Email) {
+		return true
+	}
+
+	// An empty scope would match everyone
+	if s.AdminScope == nil || s.AdminScope.IsEmpty() {
+		return false
+	}
+
+	return (&Role{Scopes: s.AdminScope}).HasPermission(user)
 }
 
 type CORSConfig struct {
@@ -81,6 +400,10 @@ type CORSConfig struct {
 	ExposeHeaders    []string `json:"expose_headers" yaml:"expose_headers" mapstructure:"expose_headers"`
 	AllowCredentials bool     `json:"allow_credentials" yaml:"allow_credentials" mapstructure:"allow_credentials"`
 	MaxAge           int      `json:"max_age" yaml:"max_age" mapstructure:"max_age"`
+
+	// AllowAll accepts requests from any origin. Only for development, the
+	// server won't start with it in production.
+	AllowAll bool `json:"allow_all" yaml:"allow_all" mapstructure:"allow_all"`
 }
 
 // WithDefaults returns a CORSConfig with default values applied for any unset fields
@@ -217,3 +217,37 @@ func TestCORSConfig_AddOrigins_ModifiesInPlace(t *testing.T) {
 	// Verify the config itself was modified
 	assert.Equal(t, []string{"https://example.com", "https://other.com"}, config.AllowedOrigins)
 }
+
+func TestSecurityConfig_IsAdminUser(t *testing.T) {
+
+	security := SecurityConfig{
+		Admins: []string{"root@example.com"},
+		AdminScope: &RoleScopes{
+			Groups:  []string{"security"},
+			Domains: []string{"audit.example.com"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		user     *User
+		expected bool
+	}{
+		{"admin email", &User{Email: "Root@example.com"}, true},
+		{"scoped group", &User{Email: "alice@example.com", Groups: []string{"Security"}}, true},
+		{"scoped domain", &User{Email: "bob@audit.example.com"}, true},
+		{"not scoped", &User{Email: "eve@example.com", Groups: []string{"engineering"}}, false},
+		{"nil user", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, security.IsAdminUser(tt.user))
+		})
+	}
+
+	t.Run("empty scope matches nobody", func(t *testing.T) {
+		security := SecurityConfig{AdminScope: &RoleScopes{}}
+		assert.False(t, security.IsAdminUser(&User{Email: "eve@example.com"}))
+	})
+}
@@ -0,0 +1,229 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// VarsContextApprovalDelegations records the delegations applied to the
+// approvers of the last approvals task
+const VarsContextApprovalDelegations = "approval_delegations"
+
+// DelegationMode decides what happens to an approver while they have
+// delegated their approvals
+type DelegationMode string
+
+const (
+	// DelegationModeSubstitute notifies the delegate instead of the approver
+	DelegationModeSubstitute DelegationMode = "substitute"
+	// DelegationModeAdd notifies the delegate as well as the approver
+	DelegationModeAdd DelegationMode = "add"
+)
+
+// DelegationConfig configures how approver delegations are applied
+type DelegationConfig struct {
+	Mode DelegationMode `json:"mode,omitempty" yaml:"mode,omitempty" mapstructure:"mode" default:"substitute"`
+}
+
+// GetMode returns the delegation mode, substitute by default
+func (d *DelegationConfig) GetMode() DelegationMode {
+	if strings.EqualFold(string(d.Mode), string(DelegationModeAdd)) {
+		return DelegationModeAdd
+	}
+	return DelegationModeSubstitute
+}
+
+// Validate checks the delegation mode is known
+func (d *DelegationConfig) Validate() error {
+	switch strings.ToLower(string(d.Mode)) {
+	case "", string(DelegationModeSubstitute), string(DelegationModeAdd):
+		return nil
+	}
+	return fmt.Errorf("invalid delegation mode %q, expected %s or %s",
+		d.Mode, DelegationModeSubstitute, DelegationModeAdd)
+}
+
+// Delegation hands an approver's approvals to a delegate between Start and
+// End e.g. while the approver is on holiday
+type Delegation struct {
+	ID        string    `json:"id"`
+	Approver  string    `json:"approver"`
+	Delegate  string    `json:"delegate"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsActive returns true if the time is within the delegation window. The
+// window includes its start but not its end.
+func (d *Delegation) IsActive(now time.Time) bool {
+	return !now.Before(d.Start) && now.Before(d.End)
+}
+
+// IsExpired returns true once the delegation window has ended
+func (d *Delegation) IsExpired(now time.Time) bool {
+	return !now.Before(d.End)
+}
+
+// Overlaps returns true if the windows of the delegations overlap
+func (d *Delegation) Overlaps(other *Delegation) bool {
+	return d.Start.Before(other.End) && other.Start.Before(d.End)
+}
+
+// Validate checks the delegation can be saved
+func (d *Delegation) Validate() error {
+
+	if len(d.Approver) == 0 {
+		return fmt.Errorf("delegation approver is required")
+	}
+
+	if len(d.Delegate) == 0 {
+		return fmt.Errorf("delegate is required")
+	}
+
+	if strings.EqualFold(d.Approver, d.Delegate) {
+		return fmt.Errorf("approvals can't be delegated to yourself")
+	}
+
+	if d.Start.IsZero() || d.End.IsZero() {
+		return fmt.Errorf("delegation start and end are required")
+	}
+
+	if !d.End.After(d.Start) {
+		return fmt.Errorf("delegation must end after it starts")
+	}
+
+	return nil
+}
+
+// ApprovalDelegations are the delegations of an approvals task's approvers
+// that hadn't expired when the task started. They're kept in the workflow
+// context so approvals that arrive later are matched against the same
+// windows.
+type ApprovalDelegations struct {
+	Mode        DelegationMode `json:"mode"`
+	EvaluatedAt time.Time      `json:"evaluated_at"`
+	Delegations []Delegation   `json:"delegations"`
+}
+
+// HasDelegations returns true if any approver has delegated their approvals
+func (a *ApprovalDelegations) HasDelegations() bool {
+	return a != nil && len(a.Delegations) > 0
+}
+
+// Apply returns the recipients to notify at the time. Approvers with an
+// active delegation are replaced by, or joined by, their delegate.
+// Delegates aren't delegated again so chains stop after one step.
+func (a *ApprovalDelegations) Apply(recipients []string, now time.Time) []string {
+
+	if !a.HasDelegations() {
+		return recipients
+	}
+
+	delegated := []string{}
+
+	add := func(recipient string) {
+		if !slices.Contains(delegated, recipient) {
+			delegated = append(delegated, recipient)
+		}
+	}
+
+	for _, recipient := range recipients {
+
+		delegation := a.getActiveDelegation(now, func(d *Delegation) bool {
+			return strings.EqualFold(d.Approver, recipient)
+		})
+
+		if delegation == nil || a.Mode == DelegationModeAdd {
+			add(recipient)
+		}
+
+		if delegation != nil {
+			add(delegation.Delegate)
+		}
+	}
+
+	return delegated
+}
+
+// GetOnBehalfOf returns the approver the delegate is acting for at the
+// time, or an empty string if they aren't acting for anyone
+func (a *ApprovalDelegations) GetOnBehalfOf(delegate string, now time.Time) string {
+
+	if !a.HasDelegations() {
+		return ""
+	}
+
+	delegation := a.getActiveDelegation(now, func(d *Delegation) bool {
+		return strings.EqualFold(d.Delegate, delegate)
+	})
+
+	if delegation == nil {
+		return ""
+	}
+
+	return delegation.Approver
+}
+
+func (a *ApprovalDelegations) getActiveDelegation(now time.Time, match func(*Delegation) bool) *Delegation {
+	for i := range a.Delegations {
+		delegation := &a.Delegations[i]
+		if delegation.IsActive(now) && match(delegation) {
+			return delegation
+		}
+	}
+	return nil
+}
+
+// NewApprovalDelegations returns the delegations of the approvers that
+// haven't expired, ordered by start so the earliest window wins
+func NewApprovalDelegations(
+	approvers []string,
+	delegations []Delegation,
+	mode DelegationMode,
+	now time.Time,
+) *ApprovalDelegations {
+
+	approvalDelegations := &ApprovalDelegations{
+		Mode:        mode,
+		EvaluatedAt: now,
+		Delegations: []Delegation{},
+	}
+
+	for _, delegation := range delegations {
+
+		if delegation.IsExpired(now) {
+			continue
+		}
+
+		if !slices.ContainsFunc(approvers, func(approver string) bool {
+			return strings.EqualFold(approver, delegation.Approver)
+		}) {
+			continue
+		}
+
+		approvalDelegations.Delegations = append(approvalDelegations.Delegations, delegation)
+	}
+
+	slices.SortFunc(approvalDelegations.Delegations, func(a, b Delegation) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	return approvalDelegations
+}
+
+// DelegationRequest delegates the caller's approvals to another approver
+type DelegationRequest struct {
+	Delegate string    `json:"delegate" binding:"required"`
+	Start    time.Time `json:"start" binding:"required"`
+	End      time.Time `json:"end" binding:"required"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// DelegationsResponse lists delegations that haven't expired
+type DelegationsResponse struct {
+	Delegations []Delegation `json:"delegations"`
+}
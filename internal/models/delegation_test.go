@@ -0,0 +1,161 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDelegation(t *testing.T, approver, delegate, start, end string) Delegation {
+	t.Helper()
+	return Delegation{
+		Approver: approver,
+		Delegate: delegate,
+		Start:    mustTime(t, start),
+		End:      mustTime(t, end),
+	}
+}
+
+func TestDelegationIsActive(t *testing.T) {
+	delegation := newTestDelegation(t, "alice@example.com", "bob@example.com",
+		"2025-08-01T00:00:00Z", "2025-08-15T00:00:00Z")
+
+	tests := []struct {
+		name    string
+		now     string
+		active  bool
+		expired bool
+	}{
+		{"before start", "2025-07-31T23:59:59Z", false, false},
+		{"start is inclusive", "2025-08-01T00:00:00Z", true, false},
+		{"within window", "2025-08-07T12:00:00Z", true, false},
+		{"just before end", "2025-08-14T23:59:59Z", true, false},
+		{"end is exclusive", "2025-08-15T00:00:00Z", false, true},
+		{"after end", "2025-08-16T00:00:00Z", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := mustTime(t, tt.now)
+			assert.Equal(t, tt.active, delegation.IsActive(now))
+			assert.Equal(t, tt.expired, delegation.IsExpired(now))
+		})
+	}
+}
+
+func TestDelegationValidate(t *testing.T) {
+	valid := newTestDelegation(t, "alice@example.com", "bob@example.com",
+		"2025-08-01T00:00:00Z", "2025-08-15T00:00:00Z")
+	require.NoError(t, valid.Validate())
+
+	self := valid
+	self.Delegate = "Alice@example.com"
+	assert.ErrorContains(t, self.Validate(), "yourself")
+
+	noDelegate := valid
+	noDelegate.Delegate = ""
+	assert.Error(t, noDelegate.Validate())
+
+	noStart := valid
+	noStart.Start = time.Time{}
+	assert.Error(t, noStart.Validate())
+
+	backwards := valid
+	backwards.End = backwards.Start
+	assert.ErrorContains(t, backwards.Validate(), "end after it starts")
+}
+
+func TestDelegationOverlaps(t *testing.T) {
+	august := newTestDelegation(t, "alice@example.com", "bob@example.com",
+		"2025-08-01T00:00:00Z", "2025-08-15T00:00:00Z")
+	overlapping := newTestDelegation(t, "alice@example.com", "carol@example.com",
+		"2025-08-14T00:00:00Z", "2025-08-20T00:00:00Z")
+	following := newTestDelegation(t, "alice@example.com", "carol@example.com",
+		"2025-08-15T00:00:00Z", "2025-08-20T00:00:00Z")
+
+	assert.True(t, august.Overlaps(&overlapping))
+	assert.True(t, overlapping.Overlaps(&august))
+	assert.False(t, august.Overlaps(&following), "windows that meet don't overlap")
+}
+
+func TestDelegationConfigMode(t *testing.T) {
+	assert.Equal(t, DelegationModeSubstitute, (&DelegationConfig{}).GetMode())
+	assert.Equal(t, DelegationModeAdd, (&DelegationConfig{Mode: "Add"}).GetMode())
+	assert.NoError(t, (&DelegationConfig{}).Validate())
+	assert.NoError(t, (&DelegationConfig{Mode: DelegationModeAdd}).Validate())
+	assert.Error(t, (&DelegationConfig{Mode: "forward"}).Validate())
+}
+
+func TestNewApprovalDelegations(t *testing.T) {
+	now := mustTime(t, "2025-08-10T00:00:00Z")
+
+	delegations := []Delegation{
+		newTestDelegation(t, "alice@example.com", "bob@example.com",
+			"2025-08-20T00:00:00Z", "2025-08-25T00:00:00Z"),
+		newTestDelegation(t, "alice@example.com", "carol@example.com",
+			"2025-08-01T00:00:00Z", "2025-08-15T00:00:00Z"),
+		// Expired
+		newTestDelegation(t, "alice@example.com", "dave@example.com",
+			"2025-07-01T00:00:00Z", "2025-07-15T00:00:00Z"),
+		// Not an approver of the request
+		newTestDelegation(t, "erin@example.com", "frank@example.com",
+			"2025-08-01T00:00:00Z", "2025-08-15T00:00:00Z"),
+	}
+
+	result := NewApprovalDelegations([]string{"Alice@example.com"}, delegations, DelegationModeAdd, now)
+
+	require.True(t, result.HasDelegations())
+	require.Len(t, result.Delegations, 2)
+	assert.Equal(t, "carol@example.com", result.Delegations[0].Delegate, "ordered by start")
+	assert.Equal(t, "bob@example.com", result.Delegations[1].Delegate)
+	assert.Equal(t, DelegationModeAdd, result.Mode)
+	assert.Equal(t, now, result.EvaluatedAt)
+}
+
+func TestApprovalDelegationsApply(t *testing.T) {
+	delegations := []Delegation{
+		newTestDelegation(t, "alice@example.com", "bob@example.com",
+			"2025-08-01T00:00:00Z", "2025-08-15T00:00:00Z"),
+		// Bob has delegated too but delegations only go one step
+		newTestDelegation(t, "bob@example.com", "carol@example.com",
+			"2025-08-01T00:00:00Z", "2025-08-15T00:00:00Z"),
+	}
+
+	recipients := []string{"alice@example.com", "dave@example.com"}
+	during := mustTime(t, "2025-08-07T00:00:00Z")
+	after := mustTime(t, "2025-08-15T00:00:00Z")
+
+	substitute := &ApprovalDelegations{Mode: DelegationModeSubstitute, Delegations: delegations}
+	assert.Equal(t, []string{"bob@example.com", "dave@example.com"}, substitute.Apply(recipients, during))
+	assert.Equal(t, recipients, substitute.Apply(recipients, after))
+
+	add := &ApprovalDelegations{Mode: DelegationModeAdd, Delegations: delegations}
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com", "dave@example.com"}, add.Apply(recipients, during))
+
+	// A delegate who is already an approver isn't notified twice
+	assert.Equal(t, []string{"bob@example.com", "carol@example.com"},
+		substitute.Apply([]string{"alice@example.com", "bob@example.com"}, during))
+
+	var none *ApprovalDelegations
+	assert.Equal(t, recipients, none.Apply(recipients, during))
+}
+
+func TestApprovalDelegationsGetOnBehalfOf(t *testing.T) {
+	delegations := &ApprovalDelegations{
+		Mode: DelegationModeSubstitute,
+		Delegations: []Delegation{
+			newTestDelegation(t, "alice@example.com", "bob@example.com",
+				"2025-08-01T00:00:00Z", "2025-08-15T00:00:00Z"),
+		},
+	}
+
+	assert.Empty(t, delegations.GetOnBehalfOf("bob@example.com", mustTime(t, "2025-07-31T23:59:59Z")))
+	assert.Equal(t, "alice@example.com", delegations.GetOnBehalfOf("Bob@example.com", mustTime(t, "2025-08-01T00:00:00Z")))
+	assert.Empty(t, delegations.GetOnBehalfOf("bob@example.com", mustTime(t, "2025-08-15T00:00:00Z")))
+	assert.Empty(t, delegations.GetOnBehalfOf("alice@example.com", mustTime(t, "2025-08-07T00:00:00Z")))
+
+	var none *ApprovalDelegations
+	assert.Empty(t, none.GetOnBehalfOf("bob@example.com", mustTime(t, "2025-08-07T00:00:00Z")))
+}
@@ -2,7 +2,11 @@ package models
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,7 +22,7 @@ type ElevateStaticRequest struct {
 	Provider   string   `json:"provider" form:"provider"`
 	Workflow   string   `json:"workflow" form:"workflow"`
 	Reason     string   `json:"reason" form:"reason" binding:"required"`
-	Duration   string   `json:"duration,omitempty" form:"duration,omitempty"`     // Duration in ISO 8601 format
+	Duration   string   `json:"duration,omitempty" form:"duration,omitempty"`     // Duration e.g. PT1H, 90m or 2 hours
 	Identities []string `json:"identities,omitempty" form:"identities,omitempty"` // Optional identities to elevate, if empty the requesting user is used
 
 	// Protected session
@@ -53,25 +57,149 @@ type ElevateResponse struct {
 	Output     map[string]any  `json:"output,omitempty"`
 }
 
+// ElevatePlanResponse describes what an elevation request would grant
+// without starting its workflow
+type ElevatePlanResponse struct {
+	Role       *Role           `json:"role"`
+	Providers  []string        `json:"providers"`
+	Workflow   string          `json:"workflow"`
+	Duration   string          `json:"duration,omitempty"`
+	Identities []string        `json:"identities,omitempty"`
+	Allowed    bool            `json:"allowed"`
+	Decision   *PolicyDecision `json:"decision,omitempty"`
+}
+
 type ElevateRequest struct {
 	Role          *Role         `json:"role"`
 	Providers     []string      `json:"providers"`     // A role can be applied to multiple providers
 	Authenticator string        `json:"authenticator"` // Which provider to use for authentication
 	Workflow      string        `json:"workflow"`
 	Reason        string        `json:"reason"`
-	Duration      string        `json:"duration,omitempty"`   // Duration in ISO 8601 format
+	Duration      string        `json:"duration,omitempty"`   // Duration e.g. PT1H, 90m or 2 hours
 	Identities    []string      `json:"identities,omitempty"` // Optional identities to elevate, if empty the requesting user is used
 	Session       *LocalSession `json:"session,omitempty"`
+
+	// IdempotencyKey identifies retries of the same request so a double
+	// submit doesn't start a second workflow, see NewIdempotencyKey
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+
+	// PublicKey is an age recipient e.g. age1... generated by the client
+	// for this request. Credentials handed to the requester, such as Vault
+	// database credentials, are only returned encrypted for it.
+	PublicKey string `json:"public_key,omitempty"`
+
+	// RiskFlags are evaluated by the server when the request is made, any
+	// flags sent by the client are replaced
+	RiskFlags *RiskFlags `json:"risk_flags,omitempty"`
+}
+
+// NewIdempotencyKey returns a key for a user's request for a role. Requests
+// made within the same minute share a key.
+func NewIdempotencyKey(user string, role string, at time.Time) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s|%s|%d",
+		strings.ToLower(user), strings.ToLower(role), at.UTC().Truncate(time.Minute).Unix()))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewSessionIdempotencyKey returns the idempotency key for the user the
+// session belongs to. The session's token isn't used so a refreshed session
+// keeps the same key.
+func NewSessionIdempotencyKey(session *LocalSession, decryptor EncryptionImpl, role string, at time.Time) (string, error) {
+
+	decoded, err := session.GetDecodedSession(decryptor)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to decode session: %w", err)
+	}
+
+	if decoded == nil || decoded.Session == nil || decoded.User == nil {
+		return "", fmt.Errorf("session has no user")
+	}
+
+	return NewIdempotencyKey(decoded.User.GetIdentity(), role, at), nil
+}
+
+// GetIdempotentWorkflowID returns the workflow ID for requests with an
+// idempotency key, or an empty string. The requester, role, providers and
+// identities are included so a key can't be used to find another user's
+// workflow or a workflow for a different role.
+func (e *ElevateRequest) GetIdempotentWorkflowID(requester string) string {
+
+	if len(e.IdempotencyKey) == 0 {
+		return ""
+	}
+
+	identities := slices.Clone(e.Identities)
+	slices.Sort(identities)
+
+	providers := slices.Clone(e.Providers)
+	slices.Sort(providers)
+
+	role := ""
+	if e.Role != nil {
+		role = e.Role.Name
+	}
+
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s|%s|%s|%s|%s",
+		strings.ToLower(requester), role, strings.Join(providers, ","),
+		strings.Join(identities, ","), e.IdempotencyKey))
+
+	return fmt.Sprintf("wf_%s", hex.EncodeToString(sum[:16]))
 }
 
 func (e *ElevateRequest) IsValid() bool {
-	return !(e.Role == nil || len(e.Providers) == 0 || len(e.Reason) == 0)
+	// The reason is checked against the role's reason policy
+	return !(e.Role == nil || len(e.Providers) == 0)
+}
+
+// ValidateProviders de-duplicates the requested providers and checks each
+// one is allowed to assign the role. A single request can target several
+// providers at once e.g. the same role in aws-prod, gcp-prod and kubernetes.
+func (e *ElevateRequest) ValidateProviders() error {
+
+	if len(e.Providers) == 0 {
+		return fmt.Errorf("at least one provider must be specified")
+	}
+
+	var providers []string
+
+	for _, provider := range e.Providers {
+		provider = strings.TrimSpace(provider)
+
+		if len(provider) == 0 || slices.Contains(providers, provider) {
+			continue
+		}
+
+		if e.Role != nil && len(e.Role.Providers) > 0 && !slices.Contains(e.Role.Providers, provider) {
+			return fmt.Errorf("provider '%s' cannot assign the role '%s'", provider, e.Role.GetName())
+		}
+
+		providers = append(providers, provider)
+	}
+
+	if len(providers) == 0 {
+		return fmt.Errorf("at least one provider must be specified")
+	}
+
+	e.Providers = providers
+
+	return nil
 }
 
 func (e *ElevateRequest) AsDuration() (time.Duration, error) {
 	return common.ValidateDuration(e.Duration)
 }
 
+// GetFormattedDuration returns the requested duration for people to read
+// e.g. 1h 30m. Falls back to the requested value if it can't be parsed.
+func (e *ElevateRequest) GetFormattedDuration() string {
+	duration, err := common.ParseDuration(e.Duration)
+	if err != nil {
+		return e.Duration
+	}
+	return common.FormatDuration(duration)
+}
+
 func (e *ElevateRequest) AsMap() map[string]any {
 	return map[string]any{
 		"role":          e.Role, // get role
@@ -160,7 +288,7 @@ type ElevateDynamicRequest struct {
 	Authenticator string   `form:"authenticator" json:"authenticator"` // If not provided, use the users default auth context
 	Workflow      string   `form:"workflow" json:"workflow" binding:"required"`
 	Reason        string   `form:"reason" json:"reason" binding:"required"`
-	Duration      string   `form:"duration" json:"duration" binding:"required"` // Duration in ISO 8601 format
+	Duration      string   `form:"duration" json:"duration" binding:"required"` // Duration e.g. PT1H, 90m or 2 hours
 	Identities    []string `form:"identities" json:"identities"`
 	Providers     []string `form:"providers" json:"providers" binding:"required"`
 	Inherits      []string `form:"inherits" json:"inherits"`
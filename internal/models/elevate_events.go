@@ -0,0 +1,156 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// ElevationStatus is the lifecycle state of an elevation as seen by the
+// requester rather than the underlying workflow status
+type ElevationStatus string
+
+const (
+	ElevationStatusPending   ElevationStatus = "pending"
+	ElevationStatusApproved  ElevationStatus = "approved"
+	ElevationStatusGranted   ElevationStatus = "granted"
+	ElevationStatusRevoked   ElevationStatus = "revoked"
+	ElevationStatusDenied    ElevationStatus = "denied"
+	ElevationStatusFailed    ElevationStatus = "failed"
+	ElevationStatusCancelled ElevationStatus = "cancelled"
+)
+
+// IsTerminal returns true once the elevation can no longer change
+func (s ElevationStatus) IsTerminal() bool {
+	switch s {
+	case ElevationStatusRevoked,
+		ElevationStatusDenied,
+		ElevationStatusFailed,
+		ElevationStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// ElevationEvent is sent to subscribers of an elevation's event stream
+// whenever its status or current task changes
+type ElevationEvent struct {
+	WorkflowID string                 `json:"id"`
+	Status     ElevationStatus        `json:"status"`
+	Task       string                 `json:"task,omitempty"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Execution  *WorkflowExecutionInfo `json:"execution,omitempty"`
+}
+
+// NewElevationEvent creates an event for the current state of an execution
+func NewElevationEvent(execution *WorkflowExecutionInfo) *ElevationEvent {
+	return &ElevationEvent{
+		WorkflowID: execution.WorkflowID,
+		Status:     execution.GetElevationStatus(),
+		Task:       execution.Task,
+		Timestamp:  time.Now().UTC(),
+		Execution:  execution,
+	}
+}
+
+// IsSameState returns true if both events describe the same transition
+func (e *ElevationEvent) IsSameState(other *ElevationEvent) bool {
+	return other != nil && e.Status == other.Status && e.Task == other.Task
+}
+
+// GetElevationStatus derives the elevation status from the workflow status,
+// approval and the grants recorded in the workflow context
+func (w *WorkflowExecutionInfo) GetElevationStatus() ElevationStatus {
+
+	denied := w.Approved != nil && !*w.Approved
+	approved := w.Approved != nil && *w.Approved
+
+	if denied {
+		return ElevationStatusDenied
+	}
+
+	if w.CloseTime != nil {
+		switch strings.ToUpper(w.Status) {
+		case "FAILED", "TIMED_OUT":
+			return ElevationStatusFailed
+		case "CANCELED", "TERMINATED":
+			if approved {
+				return ElevationStatusRevoked
+			}
+			return ElevationStatusCancelled
+		}
+
+		// Completed workflows that were approved have had their access
+		// revoked, otherwise they never got past approval
+		if approved {
+			return ElevationStatusRevoked
+		}
+		return ElevationStatusDenied
+	}
+
+	if approved {
+		if w.hasContextValue(VarsContextProviderAuthorizations) {
+			return ElevationStatusGranted
+		}
+		return ElevationStatusApproved
+	}
+
+	return ElevationStatusPending
+}
+
+// GetApprovers returns the recipients that were asked to approve the
+// elevation
+func (w *WorkflowExecutionInfo) GetApprovers() []string {
+
+	context, ok := w.Context.(map[string]any)
+
+	if !ok {
+		return nil
+	}
+
+	switch approvers := context[VarsContextApprovers].(type) {
+	case []string:
+		return approvers
+	case []any:
+		found := make([]string, 0, len(approvers))
+		for _, approver := range approvers {
+			if value, ok := approver.(string); ok {
+				found = append(found, value)
+			}
+		}
+		return found
+	}
+
+	return nil
+}
+
+// IsApprover returns true if the identity was asked to approve the elevation
+func (w *WorkflowExecutionInfo) IsApprover(identities ...string) bool {
+	approvers := w.GetApprovers()
+	for _, identity := range identities {
+		if len(identity) > 0 && slices.Contains(approvers, identity) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *WorkflowExecutionInfo) hasContextValue(key string) bool {
+
+	context, ok := w.Context.(map[string]any)
+
+	if !ok {
+		return false
+	}
+
+	switch value := context[key].(type) {
+	case nil:
+		return false
+	case map[string]any:
+		return len(value) > 0
+	case []any:
+		return len(value) > 0
+	}
+
+	return true
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetElevationStatus(t *testing.T) {
+	approved := true
+	denied := false
+	closed := time.Now()
+
+	granted := map[string]any{
+		VarsContextProviderAuthorizations: map[string]any{"aws-prod": map[string]any{}},
+	}
+
+	tests := []struct {
+		name      string
+		execution WorkflowExecutionInfo
+		expected  ElevationStatus
+	}{
+		{"awaiting approval", WorkflowExecutionInfo{Status: "RUNNING"}, ElevationStatusPending},
+		{"approved", WorkflowExecutionInfo{Status: "RUNNING", Approved: &approved}, ElevationStatusApproved},
+		{"granted", WorkflowExecutionInfo{Status: "RUNNING", Approved: &approved, Context: granted}, ElevationStatusGranted},
+		{"denied while running", WorkflowExecutionInfo{Status: "RUNNING", Approved: &denied}, ElevationStatusDenied},
+		{"revoked", WorkflowExecutionInfo{Status: "COMPLETED", Approved: &approved, CloseTime: &closed}, ElevationStatusRevoked},
+		{"revoked early", WorkflowExecutionInfo{Status: "CANCELED", Approved: &approved, CloseTime: &closed}, ElevationStatusRevoked},
+		{"cancelled before approval", WorkflowExecutionInfo{Status: "CANCELED", CloseTime: &closed}, ElevationStatusCancelled},
+		{"completed without approval", WorkflowExecutionInfo{Status: "COMPLETED", CloseTime: &closed}, ElevationStatusDenied},
+		{"failed", WorkflowExecutionInfo{Status: "FAILED", Approved: &approved, CloseTime: &closed}, ElevationStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.execution.GetElevationStatus())
+		})
+	}
+}
+
+func TestElevationStatusIsTerminal(t *testing.T) {
+	assert.False(t, ElevationStatusPending.IsTerminal())
+	assert.False(t, ElevationStatusGranted.IsTerminal())
+	assert.True(t, ElevationStatusRevoked.IsTerminal())
+	assert.True(t, ElevationStatusDenied.IsTerminal())
+}
+
+func TestIsApprover(t *testing.T) {
+	execution := WorkflowExecutionInfo{
+		Context: map[string]any{
+			VarsContextApprovers: []any{"security@example.com", "C123"},
+		},
+	}
+
+	assert.True(t, execution.IsApprover("security@example.com"))
+	assert.True(t, execution.IsApprover("someone@example.com", "C123"))
+	assert.False(t, execution.IsApprover("someone@example.com", ""))
+	assert.False(t, (&WorkflowExecutionInfo{}).IsApprover("security@example.com"))
+}
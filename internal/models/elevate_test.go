@@ -3,8 +3,10 @@ package models
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // MockProvider implements ProviderImpl for testing
@@ -158,3 +160,125 @@ func TestResolveIdentities(t *testing.T) {
 		})
 	}
 }
+
+func TestElevateRequest_ValidateProviders(t *testing.T) {
+	role := &Role{
+		Name:      "incident-responder",
+		Providers: []string{"aws-prod", "gcp-prod", "kubernetes"},
+	}
+
+	t.Run("multiple allowed providers", func(t *testing.T) {
+		req := &ElevateRequest{
+			Role:      role,
+			Providers: []string{"aws-prod", " gcp-prod", "aws-prod", "kubernetes"},
+		}
+		assert.NoError(t, req.ValidateProviders())
+		assert.Equal(t, []string{"aws-prod", "gcp-prod", "kubernetes"}, req.Providers)
+	})
+
+	t.Run("provider not allowed by role", func(t *testing.T) {
+		req := &ElevateRequest{
+			Role:      role,
+			Providers: []string{"aws-prod", "azure-prod"},
+		}
+		assert.Error(t, req.ValidateProviders())
+	})
+
+	t.Run("no providers", func(t *testing.T) {
+		req := &ElevateRequest{
+			Role:      role,
+			Providers: []string{"", " "},
+		}
+		assert.Error(t, req.ValidateProviders())
+	})
+
+	t.Run("role without provider restrictions", func(t *testing.T) {
+		req := &ElevateRequest{
+			Role:      &Role{Name: "open"},
+			Providers: []string{"anything"},
+		}
+		assert.NoError(t, req.ValidateProviders())
+	})
+}
+
+func TestElevateRequest_IdempotentWorkflowID(t *testing.T) {
+	at := time.Date(2025, 6, 1, 12, 30, 10, 0, time.UTC)
+
+	t.Run("keys are shared within a minute", func(t *testing.T) {
+		key := NewIdempotencyKey("alice@example.com", "admin", at)
+		assert.Equal(t, key, NewIdempotencyKey("Alice@example.com", "admin", at.Add(40*time.Second)))
+		assert.NotEqual(t, key, NewIdempotencyKey("alice@example.com", "admin", at.Add(time.Minute)))
+		assert.NotEqual(t, key, NewIdempotencyKey("alice@example.com", "reader", at))
+	})
+
+	t.Run("keys are shared across a token refresh", func(t *testing.T) {
+		newSession := func(token string) *LocalSession {
+			return (&ExportableSession{
+				Session: &Session{
+					User:        &User{Email: "alice@example.com"},
+					AccessToken: token,
+				},
+				Provider: "oidc",
+			}).ToLocalSession(nil)
+		}
+
+		key, err := NewSessionIdempotencyKey(newSession("first-token"), nil, "admin", at)
+		require.NoError(t, err)
+		assert.Equal(t, NewIdempotencyKey("alice@example.com", "admin", at), key)
+
+		refreshed, err := NewSessionIdempotencyKey(newSession("refreshed-token"), nil, "admin", at.Add(30*time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, key, refreshed)
+
+		_, err = NewSessionIdempotencyKey(&LocalSession{Session: "not-a-session"}, nil, "admin", at)
+		assert.Error(t, err)
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		req := &ElevateRequest{Identities: []string{"alice@example.com"}}
+		assert.Empty(t, req.GetIdempotentWorkflowID("alice@example.com"))
+	})
+
+	newRequest := func(role string, providers []string, identities []string) *ElevateRequest {
+		return &ElevateRequest{
+			Role:           &Role{Name: role},
+			Providers:      providers,
+			Identities:     identities,
+			IdempotencyKey: "key",
+		}
+	}
+
+	t.Run("workflow id is scoped to the identities", func(t *testing.T) {
+		req := newRequest("admin", []string{"aws-prod"}, []string{"alice@example.com", "bob@example.com"})
+		workflowID := req.GetIdempotentWorkflowID("alice@example.com")
+		assert.Regexp(t, `^wf_[0-9a-f]{32}$`, workflowID)
+
+		reordered := newRequest("admin", []string{"aws-prod"}, []string{"bob@example.com", "alice@example.com"})
+		assert.Equal(t, workflowID, reordered.GetIdempotentWorkflowID("alice@example.com"))
+
+		other := newRequest("admin", []string{"aws-prod"}, []string{"mallory@example.com"})
+		assert.NotEqual(t, workflowID, other.GetIdempotentWorkflowID("alice@example.com"))
+	})
+
+	t.Run("workflow id is scoped to the requester", func(t *testing.T) {
+		req := newRequest("admin", []string{"aws-prod"}, []string{"alice@example.com"})
+		workflowID := req.GetIdempotentWorkflowID("alice@example.com")
+
+		assert.Equal(t, workflowID, req.GetIdempotentWorkflowID("Alice@example.com"))
+		assert.NotEqual(t, workflowID, req.GetIdempotentWorkflowID("mallory@example.com"))
+	})
+
+	t.Run("workflow id is scoped to the role and providers", func(t *testing.T) {
+		req := newRequest("admin", []string{"aws-prod", "gcp-prod"}, []string{"alice@example.com"})
+		workflowID := req.GetIdempotentWorkflowID("alice@example.com")
+
+		reordered := newRequest("admin", []string{"gcp-prod", "aws-prod"}, []string{"alice@example.com"})
+		assert.Equal(t, workflowID, reordered.GetIdempotentWorkflowID("alice@example.com"))
+
+		otherRole := newRequest("reader", []string{"aws-prod", "gcp-prod"}, []string{"alice@example.com"})
+		assert.NotEqual(t, workflowID, otherRole.GetIdempotentWorkflowID("alice@example.com"))
+
+		otherProvider := newRequest("admin", []string{"aws-prod"}, []string{"alice@example.com"})
+		assert.NotEqual(t, workflowID, otherProvider.GetIdempotentWorkflowID("alice@example.com"))
+	})
+}
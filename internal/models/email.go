@@ -1,14 +1,136 @@
 package models
 
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
 type EmailNotificationRequest struct {
 	From    string
 	To      []string
 	Subject string
 	Body    EmailNotificationBody
 	Headers map[string][]string
+
+	// Individual sends each recipient their own message rather than a
+	// single message with everyone in To
+	Individual bool
+	// Recipients overrides the subject and body for a recipient, keyed by
+	// address. Only used for individual messages.
+	Recipients map[string]EmailNotificationContent
 }
 
 type EmailNotificationBody struct {
 	Text string
 	HTML string
 }
+
+// EmailNotificationContent is the content rendered for a single recipient
+type EmailNotificationContent struct {
+	Subject string
+	Body    EmailNotificationBody
+}
+
+// EmailMessage is a single message to send
+type EmailMessage struct {
+	To      []string
+	Subject string
+	Body    EmailNotificationBody
+}
+
+// GetMessages returns the messages to send for the request. Individual
+// requests have a message for each recipient, with that recipient's content
+// if it has any.
+func (r *EmailNotificationRequest) GetMessages() []EmailMessage {
+
+	if !r.Individual {
+		return []EmailMessage{{
+			To:      r.To,
+			Subject: r.Subject,
+			Body:    r.Body,
+		}}
+	}
+
+	messages := make([]EmailMessage, 0, len(r.To))
+
+	for _, to := range r.To {
+
+		message := EmailMessage{
+			To:      []string{to},
+			Subject: r.Subject,
+			Body:    r.Body,
+		}
+
+		if content, found := r.Recipients[to]; found {
+			if len(content.Subject) > 0 {
+				message.Subject = content.Subject
+			}
+			if len(content.Body.Text) > 0 || len(content.Body.HTML) > 0 {
+				message.Body = content.Body
+			}
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages
+}
+
+// SendEmailMessages sends every message of the request with the platform's
+// send function. A failed message doesn't stop the rest being sent. The
+// receipt has a receipt for each message, and the error lists the
+// recipients that failed.
+func SendEmailMessages(
+	ctx context.Context,
+	request *EmailNotificationRequest,
+	send func(ctx context.Context, message EmailMessage) (*NotificationReceipt, error),
+) (*NotificationReceipt, error) {
+
+	if len(request.To) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+
+	messages := request.GetMessages()
+
+	// A single message is reported as is
+	if len(messages) == 1 {
+		receipt, err := send(ctx, messages[0])
+		if err != nil {
+			return nil, err
+		}
+		if receipt != nil && len(receipt.Target) == 0 {
+			receipt.Target = strings.Join(messages[0].To, ", ")
+		}
+		return receipt, nil
+	}
+
+	result := &NotificationReceipt{
+		Target: strings.Join(request.To, ", "),
+	}
+
+	var failed []string
+	var lastErr error
+
+	for _, message := range messages {
+
+		receipt, err := send(ctx, message)
+
+		if err != nil {
+			failed = append(failed, message.To...)
+			lastErr = err
+		}
+
+		result.Recipients = append(result.Recipients, NewNotificationReceipt(
+			"", "", strings.Join(message.To, ", "), receipt, err, time.Now()))
+	}
+
+	if len(failed) == len(request.To) {
+		return result, fmt.Errorf("failed to send email to all recipients: %w", lastErr)
+	} else if len(failed) > 0 {
+		return result, fmt.Errorf("failed to send email to: %s: %w", strings.Join(failed, ", "), lastErr)
+	}
+
+	return result, nil
+}
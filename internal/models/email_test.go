@@ -0,0 +1,94 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailNotificationRequestGetMessages(t *testing.T) {
+
+	request := EmailNotificationRequest{
+		To:      []string{"alice@example.com", "bob@example.com"},
+		Subject: "Access granted",
+		Body:    EmailNotificationBody{Text: "Hello"},
+		Recipients: map[string]EmailNotificationContent{
+			"alice@example.com": {
+				Subject: "Zugriff gewährt",
+				Body:    EmailNotificationBody{Text: "Hallo Alice"},
+			},
+		},
+	}
+
+	t.Run("single message", func(t *testing.T) {
+		messages := request.GetMessages()
+		require.Len(t, messages, 1)
+		assert.Equal(t, request.To, messages[0].To)
+		assert.Equal(t, "Access granted", messages[0].Subject)
+	})
+
+	t.Run("individual messages", func(t *testing.T) {
+		individual := request
+		individual.Individual = true
+
+		messages := individual.GetMessages()
+		require.Len(t, messages, 2)
+
+		assert.Equal(t, []string{"alice@example.com"}, messages[0].To)
+		assert.Equal(t, "Zugriff gewährt", messages[0].Subject)
+		assert.Equal(t, "Hallo Alice", messages[0].Body.Text)
+
+		assert.Equal(t, []string{"bob@example.com"}, messages[1].To)
+		assert.Equal(t, "Access granted", messages[1].Subject)
+		assert.Equal(t, "Hello", messages[1].Body.Text)
+	})
+}
+
+func TestSendEmailMessages(t *testing.T) {
+
+	request := &EmailNotificationRequest{
+		To:         []string{"alice@example.com", "broken@example.com", "bob@example.com"},
+		Subject:    "Access granted",
+		Individual: true,
+	}
+
+	send := func(ctx context.Context, message EmailMessage) (*NotificationReceipt, error) {
+		if message.To[0] == "broken@example.com" {
+			return nil, errors.New("mailbox unavailable")
+		}
+		return &NotificationReceipt{MessageID: "id-" + message.To[0]}, nil
+	}
+
+	t.Run("a failed recipient doesn't stop the rest", func(t *testing.T) {
+		receipt, err := SendEmailMessages(context.Background(), request, send)
+		require.ErrorContains(t, err, "failed to send email to: broken@example.com: mailbox unavailable")
+		require.NotNil(t, receipt)
+		require.Len(t, receipt.Recipients, 3)
+
+		assert.Equal(t, "alice@example.com", receipt.Recipients[0].Target)
+		assert.Equal(t, NotificationDeliveryDelivered, receipt.Recipients[0].Status)
+		assert.Equal(t, "id-alice@example.com", receipt.Recipients[0].MessageID)
+
+		assert.Equal(t, NotificationDeliveryFailed, receipt.Recipients[1].Status)
+		assert.Equal(t, "mailbox unavailable", receipt.Recipients[1].Error)
+
+		assert.Equal(t, NotificationDeliveryDelivered, receipt.Recipients[2].Status)
+	})
+
+	t.Run("single message", func(t *testing.T) {
+		receipt, err := SendEmailMessages(context.Background(), &EmailNotificationRequest{
+			To: []string{"alice@example.com", "bob@example.com"},
+		}, send)
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com, bob@example.com", receipt.Target)
+		assert.Empty(t, receipt.Recipients)
+	})
+
+	t.Run("no recipients", func(t *testing.T) {
+		_, err := SendEmailMessages(context.Background(), &EmailNotificationRequest{}, send)
+		assert.EqualError(t, err, "at least one recipient is required")
+	})
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/thand-io/agent/internal/common"
 )
@@ -36,6 +37,14 @@ type EnvironmentConfig struct {
 	Config   *BasicConfig `mapstructure:"config"`   // Additional environment-specific config
 	MetaData *BasicConfig `mapstructure:"metadata"` // Metadata for the environment
 
+	// Overrides change roles by name for this environment e.g. to require
+	// approval for admin in production
+	Overrides map[string]RoleOverride `mapstructure:"overrides"`
+}
+
+// IsProduction returns true if the environment is named production or prod
+func (e *EnvironmentConfig) IsProduction() bool {
+	return strings.EqualFold(e.Name, "production") || strings.EqualFold(e.Name, "prod")
 }
 
 func (e *EnvironmentConfig) GetIdentifier() string {
@@ -0,0 +1,375 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// VarsContextFreeze records the change freeze evaluated by the last
+// validate, route or policy task e.g. ${ $context.freeze.active }
+const VarsContextFreeze = "freeze"
+
+// DefaultFreezeCacheTTL is how long fetched calendars are reused
+const DefaultFreezeCacheTTL = 15 * time.Minute
+
+// FreezeAction decides what happens to requests for a role or workflow
+// while a change freeze is active
+type FreezeAction string
+
+const (
+	// FreezeActionDeny blocks requests during a freeze
+	FreezeActionDeny FreezeAction = "deny"
+	// FreezeActionExtraApproval requires approval, from the freeze
+	// approvers as well, during a freeze
+	FreezeActionExtraApproval FreezeAction = "extra_approval"
+)
+
+// Validate checks the action is known
+func (a FreezeAction) Validate() error {
+	switch a {
+	case "", FreezeActionDeny, FreezeActionExtraApproval:
+		return nil
+	}
+	return fmt.Errorf("invalid during_freeze %q, expected %s or %s",
+		a, FreezeActionDeny, FreezeActionExtraApproval)
+}
+
+// Stricter returns the more restrictive of the actions. Deny is stricter
+// than extra approval, which is stricter than no action.
+func (a FreezeAction) Stricter(other FreezeAction) FreezeAction {
+	if freezeActionWeight(other) > freezeActionWeight(a) {
+		return other
+	}
+	return a
+}
+
+func freezeActionWeight(action FreezeAction) int {
+	switch action {
+	case FreezeActionDeny:
+		return 2
+	case FreezeActionExtraApproval:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// FreezeOnError decides whether a calendar that can't be read freezes
+// requests (closed) or not (open)
+type FreezeOnError string
+
+const (
+	FreezeOnErrorOpen   FreezeOnError = "open"
+	FreezeOnErrorClosed FreezeOnError = "closed"
+)
+
+// FreezeConfig lists the change freeze calendars
+type FreezeConfig struct {
+	Calendars map[string]FreezeCalendar `mapstructure:"calendars" json:"calendars,omitempty"`
+	OnError   FreezeOnError             `mapstructure:"on_error" json:"on_error,omitempty" default:"open"`
+	CacheTTL  string                    `mapstructure:"cache_ttl" json:"cache_ttl,omitempty" default:"15m"`
+}
+
+// IsEnabled returns true if any calendar is configured
+func (f *FreezeConfig) IsEnabled() bool {
+	return f != nil && len(f.Calendars) > 0
+}
+
+// IsFailClosed returns true if calendars that can't be read freeze requests
+func (f *FreezeConfig) IsFailClosed() bool {
+	return strings.EqualFold(string(f.OnError), string(FreezeOnErrorClosed))
+}
+
+// GetCacheTTL returns how long fetched calendars are reused
+func (f *FreezeConfig) GetCacheTTL() time.Duration {
+	if ttl, err := time.ParseDuration(f.CacheTTL); err == nil && ttl > 0 {
+		return ttl
+	}
+	return DefaultFreezeCacheTTL
+}
+
+// Validate checks the error handling and every calendar
+func (f *FreezeConfig) Validate() error {
+
+	switch strings.ToLower(string(f.OnError)) {
+	case "", string(FreezeOnErrorOpen), string(FreezeOnErrorClosed):
+	default:
+		return fmt.Errorf("invalid freeze on_error %q, expected %s or %s",
+			f.OnError, FreezeOnErrorOpen, FreezeOnErrorClosed)
+	}
+
+	if len(f.CacheTTL) > 0 {
+		if _, err := time.ParseDuration(f.CacheTTL); err != nil {
+			return fmt.Errorf("invalid freeze cache_ttl %q: %w", f.CacheTTL, err)
+		}
+	}
+
+	for name, calendar := range f.Calendars {
+		if err := calendar.Validate(); err != nil {
+			return fmt.Errorf("freeze calendar '%s': %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// FreezeCalendar is one source of freeze windows: a list of windows, an
+// iCal URL or a Google Calendar read with a gsuite provider's credentials
+type FreezeCalendar struct {
+	Windows        []FreezeWindow `mapstructure:"windows" json:"windows,omitempty"`
+	ICal           string         `mapstructure:"ical" json:"ical,omitempty"`                       // URL of an iCal feed
+	GoogleCalendar string         `mapstructure:"google_calendar" json:"google_calendar,omitempty"` // Google Calendar ID
+	Provider       string         `mapstructure:"provider" json:"provider,omitempty"`               // gsuite provider to read the Google Calendar with
+	// Timezone of dates and of times without a zone. Defaults to the
+	// Google Calendar's zone, otherwise UTC.
+	Timezone string `mapstructure:"timezone" json:"timezone,omitempty"`
+}
+
+// Validate checks the calendar has exactly one source and a known zone
+func (c *FreezeCalendar) Validate() error {
+
+	sources := 0
+	for _, configured := range []bool{len(c.Windows) > 0, len(c.ICal) > 0, len(c.GoogleCalendar) > 0} {
+		if configured {
+			sources++
+		}
+	}
+
+	if sources != 1 {
+		return fmt.Errorf("exactly one of windows, ical or google_calendar is required")
+	}
+
+	if len(c.GoogleCalendar) > 0 && len(c.Provider) == 0 {
+		return fmt.Errorf("provider is required to read a Google Calendar")
+	}
+
+	location, err := c.GetLocation("")
+
+	if err != nil {
+		return err
+	}
+
+	for index, window := range c.Windows {
+		if _, err := window.Resolve(location); err != nil {
+			return fmt.Errorf("window %d: %w", index, err)
+		}
+	}
+
+	return nil
+}
+
+// GetLocation returns the calendar's configured zone, otherwise the
+// fallback e.g. the Google Calendar's own zone, otherwise UTC
+func (c *FreezeCalendar) GetLocation(fallback string) (*time.Location, error) {
+
+	name := c.Timezone
+
+	if len(name) == 0 {
+		name = fallback
+	}
+
+	if len(name) == 0 {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(name)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+
+	return location, nil
+}
+
+// freezeDateLayout is the layout of all day dates
+const freezeDateLayout = "2006-01-02"
+
+// FreezeWindow is a freeze configured directly. Start and end are dates,
+// which include the whole of the end date, or RFC 3339 times.
+type FreezeWindow struct {
+	Label string `mapstructure:"label" json:"label"`
+	Start string `mapstructure:"start" json:"start"`
+	End   string `mapstructure:"end" json:"end"`
+}
+
+// Resolve returns the window's times. Dates start at midnight in the
+// location.
+func (w *FreezeWindow) Resolve(location *time.Location) (FreezePeriod, error) {
+
+	start, _, err := parseFreezeTime(w.Start, location)
+
+	if err != nil {
+		return FreezePeriod{}, fmt.Errorf("invalid start: %w", err)
+	}
+
+	end, endIsDate, err := parseFreezeTime(w.End, location)
+
+	if err != nil {
+		return FreezePeriod{}, fmt.Errorf("invalid end: %w", err)
+	}
+
+	// An end date includes the whole day
+	if endIsDate {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	if !end.After(start) {
+		return FreezePeriod{}, fmt.Errorf("end must be after start")
+	}
+
+	return FreezePeriod{Label: w.Label, Start: start, End: end}, nil
+}
+
+func parseFreezeTime(value string, location *time.Location) (time.Time, bool, error) {
+
+	if parsed, err := time.ParseInLocation(freezeDateLayout, value, location); err == nil {
+		return parsed, true, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%q isn't a date (2006-01-02) or RFC 3339 time", value)
+	}
+
+	return parsed, false, nil
+}
+
+// CalendarEvent is an event read from an iCal feed or Google Calendar
+type CalendarEvent struct {
+	Summary string
+	Start   time.Time // start of a timed event
+	End     time.Time // end of a timed event
+	AllDay  bool
+	// StartDate and EndDate of an all day event. The end date is
+	// exclusive, as in iCal and Google Calendar.
+	StartDate string
+	EndDate   string
+	// TimeZone of the calendar the event was read from, if known
+	TimeZone string
+}
+
+// Resolve returns the event's times. All day events start and end at
+// midnight in the location, so a freeze on the 24th in New York starts
+// at 05:00 UTC.
+func (e *CalendarEvent) Resolve(location *time.Location) (FreezePeriod, error) {
+
+	period := FreezePeriod{Label: e.Summary, Start: e.Start, End: e.End}
+
+	if e.AllDay {
+
+		start, err := time.ParseInLocation(freezeDateLayout, e.StartDate, location)
+
+		if err != nil {
+			return FreezePeriod{}, fmt.Errorf("invalid start date %q: %w", e.StartDate, err)
+		}
+
+		// An all day event without an end lasts one day
+		end := start.AddDate(0, 0, 1)
+
+		if len(e.EndDate) > 0 {
+			end, err = time.ParseInLocation(freezeDateLayout, e.EndDate, location)
+			if err != nil {
+				return FreezePeriod{}, fmt.Errorf("invalid end date %q: %w", e.EndDate, err)
+			}
+		}
+
+		period.Start, period.End = start, end
+	}
+
+	if !period.End.After(period.Start) {
+		return FreezePeriod{}, fmt.Errorf("event %q ends before it starts", e.Summary)
+	}
+
+	return period, nil
+}
+
+// ProviderCalendarReader is implemented by providers that can read events
+// from a calendar e.g. gsuite with Google Calendar
+type ProviderCalendarReader interface {
+	// ListCalendarEvents returns the events that overlap start and end
+	ListCalendarEvents(ctx context.Context, calendarID string, start time.Time, end time.Time) ([]CalendarEvent, error)
+}
+
+// FreezePeriod is a resolved freeze window from a calendar
+type FreezePeriod struct {
+	Calendar string    `json:"calendar"`
+	Label    string    `json:"label"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+}
+
+// IsActive returns true if the time is within the period. The period
+// includes its start but not its end.
+func (p *FreezePeriod) IsActive(now time.Time) bool {
+	return !now.Before(p.Start) && now.Before(p.End)
+}
+
+// FreezeStatus is whether a change freeze is active and, if it is, what
+// the request's role or workflow declares should happen
+type FreezeStatus struct {
+	Active      bool           `json:"active"`
+	Labels      []string       `json:"labels,omitempty"`
+	Periods     []FreezePeriod `json:"periods,omitempty"`
+	Errors      []string       `json:"errors,omitempty"` // calendars that couldn't be read
+	EvaluatedAt time.Time      `json:"evaluated_at"`
+
+	Action    FreezeAction `json:"action,omitempty"`    // set while active
+	Approvers []string     `json:"approvers,omitempty"` // extra approvers while active
+}
+
+// NewFreezeStatus returns the periods active at the time, ordered by start.
+// Overlapping periods are all active and their labels are combined.
+func NewFreezeStatus(periods []FreezePeriod, now time.Time) *FreezeStatus {
+
+	status := &FreezeStatus{
+		EvaluatedAt: now.UTC(),
+	}
+
+	for _, period := range periods {
+		if period.IsActive(now) {
+			status.Periods = append(status.Periods, period)
+		}
+	}
+
+	slices.SortStableFunc(status.Periods, func(a, b FreezePeriod) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	for _, period := range status.Periods {
+		if len(period.Label) > 0 && !slices.Contains(status.Labels, period.Label) {
+			status.Labels = append(status.Labels, period.Label)
+		}
+	}
+
+	status.Active = len(status.Periods) > 0
+
+	return status
+}
+
+// IsDenied returns true if the request is blocked by an active freeze
+func (s *FreezeStatus) IsDenied() bool {
+	return s != nil && s.Active && s.Action == FreezeActionDeny
+}
+
+// RequiresApproval returns true if an active freeze requires approval
+func (s *FreezeStatus) RequiresApproval() bool {
+	return s != nil && s.Active && s.Action == FreezeActionExtraApproval
+}
+
+// GetDescription describes the active freeze for messages
+func (s *FreezeStatus) GetDescription() string {
+
+	if len(s.Labels) > 0 {
+		return strings.Join(s.Labels, ", ")
+	}
+
+	if len(s.Errors) > 0 {
+		return "freeze calendar unavailable"
+	}
+
+	return "change freeze"
+}
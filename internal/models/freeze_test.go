@@ -0,0 +1,128 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreezeAction_Stricter(t *testing.T) {
+	assert.Equal(t, FreezeActionDeny, FreezeActionExtraApproval.Stricter(FreezeActionDeny))
+	assert.Equal(t, FreezeActionDeny, FreezeActionDeny.Stricter(FreezeActionExtraApproval))
+	assert.Equal(t, FreezeActionExtraApproval, FreezeAction("").Stricter(FreezeActionExtraApproval))
+	assert.Equal(t, FreezeAction(""), FreezeAction("").Stricter(""))
+
+	assert.NoError(t, FreezeAction("").Validate())
+	assert.Error(t, FreezeAction("block").Validate())
+}
+
+func TestFreezeWindow_Resolve(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// Dates are midnight in the location and the end date is inclusive
+	window := FreezeWindow{Label: "Holidays", Start: "2026-12-24", End: "2026-12-26"}
+	period, err := window.Resolve(newYork)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 12, 24, 5, 0, 0, 0, time.UTC), period.Start.UTC())
+	assert.Equal(t, time.Date(2026, 12, 27, 5, 0, 0, 0, time.UTC), period.End.UTC())
+
+	// RFC 3339 times keep their own zone
+	window = FreezeWindow{Start: "2026-11-01T18:00:00Z", End: "2026-11-02T06:00:00Z"}
+	period, err = window.Resolve(newYork)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 11, 1, 18, 0, 0, 0, time.UTC), period.Start.UTC())
+
+	_, err = (&FreezeWindow{Start: "2026-12-26", End: "2026-12-24"}).Resolve(time.UTC)
+	assert.Error(t, err)
+
+	_, err = (&FreezeWindow{Start: "next week", End: "2026-12-24"}).Resolve(time.UTC)
+	assert.Error(t, err)
+}
+
+func TestCalendarEvent_ResolveAllDay(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// The end date is exclusive, so this is the 24th only
+	event := CalendarEvent{Summary: "Christmas Eve", AllDay: true, StartDate: "2026-12-24", EndDate: "2026-12-25"}
+	period, err := event.Resolve(newYork)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 12, 24, 5, 0, 0, 0, time.UTC), period.Start.UTC())
+	assert.Equal(t, time.Date(2026, 12, 25, 5, 0, 0, 0, time.UTC), period.End.UTC())
+
+	// Late on the 24th in New York is already the 25th in UTC
+	assert.True(t, period.IsActive(time.Date(2026, 12, 25, 3, 0, 0, 0, time.UTC)))
+	assert.False(t, period.IsActive(time.Date(2026, 12, 24, 3, 0, 0, 0, time.UTC)))
+
+	// Without an end the event lasts one day
+	event.EndDate = ""
+	period, err = event.Resolve(time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, period.End.Sub(period.Start))
+
+	_, err = (&CalendarEvent{AllDay: true, StartDate: "2026-12-25", EndDate: "2026-12-24"}).Resolve(time.UTC)
+	assert.Error(t, err)
+}
+
+func TestNewFreezeStatus_OverlappingPeriods(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 12, d, 0, 0, 0, 0, time.UTC) }
+
+	periods := []FreezePeriod{
+		{Calendar: "release", Label: "Year end", Start: day(20), End: day(31)},
+		{Calendar: "holidays", Label: "Christmas", Start: day(24), End: day(27)},
+		{Calendar: "release", Label: "Year end", Start: day(22), End: day(23)},
+		{Calendar: "holidays", Label: "Thanksgiving", Start: day(1), End: day(2)},
+	}
+
+	status := NewFreezeStatus(periods, day(24).Add(time.Hour))
+	assert.True(t, status.Active)
+	require.Len(t, status.Periods, 2)
+	assert.Equal(t, []string{"Year end", "Christmas"}, status.Labels)
+	assert.Equal(t, "Year end, Christmas", status.GetDescription())
+
+	// The end isn't included
+	status = NewFreezeStatus(periods, day(31))
+	assert.False(t, status.Active)
+	assert.Empty(t, status.Labels)
+}
+
+func TestFreezeStatus_Action(t *testing.T) {
+	status := &FreezeStatus{Active: true, Action: FreezeActionDeny}
+	assert.True(t, status.IsDenied())
+	assert.False(t, status.RequiresApproval())
+
+	status.Action = FreezeActionExtraApproval
+	assert.True(t, status.RequiresApproval())
+
+	status.Active = false
+	assert.False(t, status.RequiresApproval())
+
+	var missing *FreezeStatus
+	assert.False(t, missing.IsDenied())
+}
+
+func TestFreezeConfig_Validate(t *testing.T) {
+	valid := FreezeConfig{Calendars: map[string]FreezeCalendar{
+		"holidays": {Windows: []FreezeWindow{{Start: "2026-12-24", End: "2026-12-26"}}, Timezone: "Europe/London"},
+		"release":  {ICal: "https://example.com/freeze.ics"},
+		"google":   {GoogleCalendar: "freeze@example.com", Provider: "gsuite"},
+	}}
+	assert.NoError(t, valid.Validate())
+	assert.True(t, valid.IsEnabled())
+	assert.Equal(t, DefaultFreezeCacheTTL, valid.GetCacheTTL())
+
+	for name, config := range map[string]FreezeConfig{
+		"no source":       {Calendars: map[string]FreezeCalendar{"a": {}}},
+		"two sources":     {Calendars: map[string]FreezeCalendar{"a": {ICal: "https://example.com", GoogleCalendar: "b", Provider: "c"}}},
+		"no provider":     {Calendars: map[string]FreezeCalendar{"a": {GoogleCalendar: "b"}}},
+		"unknown zone":    {Calendars: map[string]FreezeCalendar{"a": {ICal: "https://example.com", Timezone: "Mars/Olympus"}}},
+		"invalid window":  {Calendars: map[string]FreezeCalendar{"a": {Windows: []FreezeWindow{{Start: "soon", End: "later"}}}}},
+		"invalid onerror": {OnError: "maybe"},
+		"invalid ttl":     {CacheTTL: "forever"},
+	} {
+		assert.Error(t, config.Validate(), name)
+	}
+}
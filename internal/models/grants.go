@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/converter"
+)
+
+// TemporalMemoGrant is the memo key the grant is recorded under once the
+// authorize task has granted access
+const TemporalMemoGrant = "grant"
+
+// GrantMemo records when access was granted and who approved it so active
+// grants can be listed from Temporal visibility without querying each
+// workflow
+type GrantMemo struct {
+	AuthorizedAt time.Time `json:"authorized_at"`
+	RevocationAt time.Time `json:"revocation_at"`
+	Approvers    []string  `json:"approvers,omitempty"`
+	Simulated    bool      `json:"simulated,omitempty"` // The grant was simulated by a read only provider
+	// What each provider created, so the reconciliation sweep can tell
+	// which thand grants still belong to a running elevation
+	GrantRefs []*GrantRef `json:"grant_refs"`
+}
+
+// GetGrantMemo returns the grant stored in the workflow memo, or nil when
+// the elevation hasn't been authorized
+func GetGrantMemo(memo *commonpb.Memo) *GrantMemo {
+
+	payload, exists := memo.GetFields()[TemporalMemoGrant]
+
+	if !exists || payload == nil {
+		return nil
+	}
+
+	var grant GrantMemo
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &grant); err != nil {
+		logrus.WithError(err).Warn("Failed to decode grant from memo")
+		return nil
+	}
+
+	return &grant
+}
+
+// Grant is an active grant of a role to a user
+type Grant struct {
+	WorkflowID string   `json:"id"`
+	User       string   `json:"user"`
+	Role       string   `json:"role"`
+	Workflow   string   `json:"workflow,omitempty"`
+	Providers  []string `json:"providers,omitempty"`
+	Identities []string `json:"identities,omitempty"`
+	Reason     string   `json:"reason,omitempty"`
+	Approvers  []string `json:"approvers,omitempty"`
+	Duration   int64    `json:"duration,omitempty"`  // Duration in seconds
+	Simulated  bool     `json:"simulated,omitempty"` // The grant was simulated by a read only provider
+
+	AuthorizedAt *time.Time `json:"authorized_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	Remaining    int64      `json:"remaining,omitempty"` // Remaining time in seconds
+}
+
+// GrantsResponse lists the active grants
+type GrantsResponse struct {
+	Grants        []*Grant `json:"grants"`
+	NextPageToken string   `json:"next_page_token,omitempty"`
+}
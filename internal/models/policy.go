@@ -0,0 +1,172 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+)
+
+// DefaultPolicyEntrypoint is the rego rule evaluated when a policy does
+// not specify its own entrypoint
+const DefaultPolicyEntrypoint = "data.thand.decision"
+
+type PolicyResult string
+
+const (
+	PolicyResultAllow           PolicyResult = "allow"
+	PolicyResultDeny            PolicyResult = "deny"
+	PolicyResultRequireApproval PolicyResult = "require_approval"
+)
+
+// Policy is a Rego module evaluated against every elevation request.
+// The module can be embedded, loaded from a file path or fetched from a url.
+type Policy struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Rego        string          `json:"rego,omitempty"`       // embedded rego module
+	Path        string          `json:"path,omitempty"`       // path to a .rego file
+	URL         *model.Endpoint `json:"url,omitempty"`        // url to fetch the rego module from
+	Entrypoint  string          `json:"entrypoint,omitempty"` // defaults to data.thand.decision
+}
+
+func (p *Policy) GetEntrypoint() string {
+	if len(p.Entrypoint) > 0 {
+		return p.Entrypoint
+	}
+	return DefaultPolicyEntrypoint
+}
+
+// PolicyInput is the input document passed to each policy
+type PolicyInput struct {
+	Requester  *User             `json:"requester,omitempty"`
+	Role       *Role             `json:"role,omitempty"` // resolved composite role
+	Providers  []string          `json:"providers"`
+	Resources  Resources         `json:"resources"`
+	Identities []string          `json:"identities,omitempty"`
+	Reason     string            `json:"reason"`
+	Workflow   string            `json:"workflow,omitempty"`
+	Duration   PolicyDuration    `json:"duration"`
+	Time       PolicyTime        `json:"time"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	Risk       *RiskFlags        `json:"risk,omitempty"`   // unset flags are unknown
+	Freeze     *FreezeStatus     `json:"freeze,omitempty"` // set when freeze calendars are configured
+}
+
+type PolicyDuration struct {
+	Value   string `json:"value"`   // duration as requested e.g. PT1H or 2 hours
+	Seconds int64  `json:"seconds"` // parsed duration in seconds
+}
+
+// PolicyTime is pre-computed so policies don't need to call time.now_ns
+// and evaluations stay deterministic for a given input
+type PolicyTime struct {
+	Timestamp string `json:"timestamp"` // RFC 3339 in UTC
+	Unix      int64  `json:"unix"`
+	Weekday   string `json:"weekday"`
+	Hour      int    `json:"hour"`
+	Minute    int    `json:"minute"`
+}
+
+// NewPolicyTime returns the time in UTC as seen by policies
+func NewPolicyTime(now time.Time) PolicyTime {
+
+	now = now.UTC()
+
+	return PolicyTime{
+		Timestamp: now.Format(time.RFC3339),
+		Unix:      now.Unix(),
+		Weekday:   now.Weekday().String(),
+		Hour:      now.Hour(),
+		Minute:    now.Minute(),
+	}
+}
+
+// NewPolicyInput builds the policy input document for an elevation request
+// evaluated at the given time
+func NewPolicyInput(request *ElevateRequestInternal, now time.Time) *PolicyInput {
+
+	now = now.UTC()
+
+	input := &PolicyInput{
+		Requester:  request.User,
+		Role:       request.Role,
+		Providers:  request.Providers,
+		Identities: request.Identities,
+		Reason:     request.Reason,
+		Workflow:   request.GetWorkflow(),
+		Duration: PolicyDuration{
+			Value: request.Duration,
+		},
+		Time: NewPolicyTime(now),
+		Metadata: map[string]string{
+			"authenticator": request.Authenticator,
+		},
+		Risk: request.RiskFlags,
+	}
+
+	if request.Role != nil {
+		input.Resources = request.Role.Resources
+	}
+
+	if duration, err := request.AsDuration(); err == nil {
+		input.Duration.Seconds = int64(duration.Seconds())
+	}
+
+	return input
+}
+
+// PolicyDecision is the combined result of evaluating all policies
+type PolicyDecision struct {
+	Result    PolicyResult `json:"result"`
+	Messages  []string     `json:"messages,omitempty"`
+	Approvers []string     `json:"approvers,omitempty"`
+	// Freeze is the change freeze the policies were evaluated with
+	Freeze *FreezeStatus `json:"freeze,omitempty"`
+}
+
+func NewPolicyDecision() *PolicyDecision {
+	return &PolicyDecision{
+		Result: PolicyResultAllow,
+	}
+}
+
+func (d *PolicyDecision) IsDenied() bool {
+	return d != nil && d.Result == PolicyResultDeny
+}
+
+func (d *PolicyDecision) RequiresApproval() bool {
+	return d != nil && d.Result == PolicyResultRequireApproval
+}
+
+// Merge combines another decision into this one. Deny takes precedence
+// over require_approval, which takes precedence over allow.
+func (d *PolicyDecision) Merge(other *PolicyDecision) {
+
+	if other == nil {
+		return
+	}
+
+	if policyResultWeight(other.Result) > policyResultWeight(d.Result) {
+		d.Result = other.Result
+	}
+
+	d.Messages = append(d.Messages, other.Messages...)
+
+	for _, approver := range other.Approvers {
+		if !slices.Contains(d.Approvers, approver) {
+			d.Approvers = append(d.Approvers, approver)
+		}
+	}
+}
+
+func policyResultWeight(result PolicyResult) int {
+	switch result {
+	case PolicyResultDeny:
+		return 2
+	case PolicyResultRequireApproval:
+		return 1
+	default:
+		return 0
+	}
+}
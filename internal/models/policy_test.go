@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyDecision_Merge(t *testing.T) {
+	decision := NewPolicyDecision()
+
+	decision.Merge(&PolicyDecision{
+		Result:    PolicyResultRequireApproval,
+		Messages:  []string{"needs approval"},
+		Approvers: []string{"security@example.com"},
+	})
+	assert.True(t, decision.RequiresApproval())
+
+	decision.Merge(&PolicyDecision{Result: PolicyResultAllow})
+	assert.True(t, decision.RequiresApproval(), "allow should not downgrade require_approval")
+
+	decision.Merge(&PolicyDecision{
+		Result:    PolicyResultDeny,
+		Messages:  []string{"denied"},
+		Approvers: []string{"security@example.com"},
+	})
+	assert.True(t, decision.IsDenied())
+	assert.Equal(t, []string{"needs approval", "denied"}, decision.Messages)
+	assert.Equal(t, []string{"security@example.com"}, decision.Approvers)
+}
+
+func TestNewPolicyInput(t *testing.T) {
+	now := time.Date(2025, 3, 7, 18, 30, 0, 0, time.UTC)
+
+	input := NewPolicyInput(&ElevateRequestInternal{
+		ElevateRequest: ElevateRequest{
+			Role: &Role{
+				Name:      "prod-admin",
+				Workflows: []string{"approval"},
+				Resources: Resources{Allow: []string{"zone:example.com"}},
+			},
+			Providers: []string{"aws-prod"},
+			Duration:  "PT2H",
+		},
+	}, now)
+
+	assert.Equal(t, "approval", input.Workflow)
+	assert.Equal(t, int64(7200), input.Duration.Seconds)
+	assert.Equal(t, "Friday", input.Time.Weekday)
+	assert.Equal(t, 18, input.Time.Hour)
+	assert.Equal(t, []string{"zone:example.com"}, input.Resources.Allow)
+}
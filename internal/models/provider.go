@@ -31,14 +31,33 @@ type Provider struct {
 	Version     *version.Version `json:"version,omitempty"`
 	Name        string           `json:"name"`
 	Description string           `json:"description"`
-	Provider    string           `json:"provider"`         // e.g. aws, gcp, azure
-	Config      *BasicConfig     `json:"config,omitempty"` // Provider-specific configuration
-	Role        *Role            `json:"role,omitempty"`   // The base role for this provider
-	Enabled     bool             `json:"enabled"`          // Whether this provider is enabled
+	Provider    string           `json:"provider"`            // e.g. aws, gcp, azure
+	Config      *BasicConfig     `json:"config,omitempty"`    // Provider-specific configuration
+	Role        *Role            `json:"role,omitempty"`      // The base role for this provider
+	Enabled     bool             `json:"enabled"`             // Whether this provider is enabled
+	ReadOnly    bool             `json:"read_only,omitempty"` // Simulate grants and revocations rather than making them
+
+	// TaskQueue routes the provider's grants and revocations to the agents
+	// polling it, e.g. a region for data residency. Empty uses the queue of
+	// the workflow.
+	TaskQueue string `json:"task_queue,omitempty"`
+
+	// Vars are merged into the workflow context of elevations using the
+	// provider, see VarsContextVars. They override global vars.
+	Vars map[string]any `json:"vars,omitempty"`
 
 	client ProviderImpl `json:"-" yaml:"-"`
 }
 
+// GetTaskQueue returns the task queue the provider's activities are
+// scheduled on, or the default queue if it isn't routed elsewhere
+func (p *Provider) GetTaskQueue(defaultTaskQueue string) string {
+	if p == nil || len(p.TaskQueue) == 0 {
+		return defaultTaskQueue
+	}
+	return p.TaskQueue
+}
+
 func (p *Provider) GetClient() ProviderImpl {
 	return p.client
 }
@@ -109,12 +128,22 @@ type ProvidersResponse struct {
 	Providers map[string]ProviderResponse `json:"providers"`
 }
 
+// CapabilitiesResponse lists the configured providers that support each
+// capability
+type CapabilitiesResponse struct {
+	Version      string                          `json:"version"`
+	Capabilities map[ProviderCapability][]string `json:"capabilities"`
+}
+
 type ProviderResponse struct {
 	ID          string `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Provider    string `json:"provider"` // e.g. aws, gcp, azure
 	Enabled     bool   `json:"enabled"`
+
+	Capabilities []ProviderCapability `json:"capabilities,omitempty"`
+	Health       *ProviderHealth      `json:"health,omitempty"`
 }
 
 type ProviderCapability string
@@ -124,8 +153,19 @@ const (
 	ProviderCapabilityAuthorizer ProviderCapability = "authorizor"
 	ProviderCapabilityNotifier   ProviderCapability = "notifier"
 	ProviderCapabilityIdentities ProviderCapability = "identities" // Provider can return users, groups, etc.
+
+	ProviderCapabilityResourceDiscovery ProviderCapability = "resources" // Provider can list live resources e.g. zones, buckets, projects
 )
 
+// ProviderCapabilities lists every capability a provider can support
+var ProviderCapabilities = []ProviderCapability{
+	ProviderCapabilityRBAC,
+	ProviderCapabilityAuthorizer,
+	ProviderCapabilityNotifier,
+	ProviderCapabilityIdentities,
+	ProviderCapabilityResourceDiscovery,
+}
+
 func GetCapabilityFromString(cap string) (ProviderCapability, error) {
 	switch strings.ToLower(cap) {
 	case string(ProviderCapabilityRBAC):
@@ -134,6 +174,10 @@ func GetCapabilityFromString(cap string) (ProviderCapability, error) {
 		return ProviderCapabilityAuthorizer, nil
 	case string(ProviderCapabilityNotifier):
 		return ProviderCapabilityNotifier, nil
+	case string(ProviderCapabilityIdentities):
+		return ProviderCapabilityIdentities, nil
+	case string(ProviderCapabilityResourceDiscovery):
+		return ProviderCapabilityResourceDiscovery, nil
 	default:
 		return "", fmt.Errorf("unknown capability: %s", cap)
 	}
@@ -145,9 +189,11 @@ This role has associated permissions (e.g., "approve reports," "view employee da
 These permissions, along with access to specific resources (e.g., "company financial reports"), constitute the user's entitlements.
 */
 
-// Interface for provider implementations
+// Interface for provider implementations. Providers only implement the
+// capability interfaces, e.g. ProviderRoleBasedAccessControl, for what they
+// support.
 type ProviderImpl interface {
-	Initialize(identifier string, provider Provider) error
+	Initialize(ctx context.Context, identifier string, provider Provider) error
 
 	// Form base provider
 	GetConfig() *BasicConfig
@@ -175,10 +221,14 @@ type ProviderImpl interface {
 	CanSynchronizeGroups() bool
 
 	// Sub-interfaces
-	ProviderNotifier
-	ProviderAuthorizor
-	ProviderRoleBasedAccessControl
+	ProviderConfigValidator
+	ProviderConnectionTester
+	ProviderAgentPermissionsValidator
+	ProviderRoleCatalog
 	ProviderIdentities
+
+	// Capabilities are implemented separately, see AsRoleBasedAccessControl,
+	// AsAuthorizor and AsNotifier
 }
 
 type AuthorizeSessionResponse struct {
@@ -189,6 +239,50 @@ type RoleRequest struct {
 	User     *User          `json:"user"`
 	Role     *Role          `json:"role"`
 	Duration *time.Duration `json:"duration,omitempty"` // Optional duration for temporary access
+	Audit    *AuditContext  `json:"audit,omitempty"`    // Optional context about who is acting, for cloud-side audit trails
+	// PublicKey is the requester's age recipient that credentials minted
+	// for the request are encrypted for
+	PublicKey string `json:"public_key,omitempty"`
+}
+
+// AuditContext describes who a grant is being made on behalf of. Providers
+// propagate this into their own audit trails (CloudTrail source identity,
+// GCP IAM condition descriptions etc) so the grant can be traced back to
+// the requester without cross-referencing thand.
+type AuditContext struct {
+	Requester  string `json:"requester,omitempty"`   // The identity of the user who requested the elevation
+	WorkflowID string `json:"workflow_id,omitempty"` // The workflow that is performing the grant
+	Reason     string `json:"reason,omitempty"`      // The justification given for the request
+}
+
+// GetJustification returns a human readable summary of the audit context
+// e.g. "Requested by alice@example.com (workflow abc123): fix prod outage"
+func (a *AuditContext) GetJustification() string {
+	if a == nil {
+		return ""
+	}
+
+	var justification strings.Builder
+
+	if len(a.Requester) > 0 {
+		justification.WriteString("Requested by ")
+		justification.WriteString(a.Requester)
+	} else {
+		justification.WriteString("Requested via thand")
+	}
+
+	if len(a.WorkflowID) > 0 {
+		justification.WriteString(" (workflow ")
+		justification.WriteString(a.WorkflowID)
+		justification.WriteString(")")
+	}
+
+	if len(a.Reason) > 0 {
+		justification.WriteString(": ")
+		justification.WriteString(a.Reason)
+	}
+
+	return justification.String()
 }
 
 // IsValid checks if any of the fields are nil
@@ -209,6 +303,14 @@ func (r *RoleRequest) GetDuration() *time.Duration {
 	return r.Duration
 }
 
+func (r *RoleRequest) GetAudit() *AuditContext {
+	return r.Audit
+}
+
+func (r *RoleRequest) GetPublicKey() string {
+	return r.PublicKey
+}
+
 // ProviderDefinitions represents a collection of provider configurations loaded from a file or other source.
 type ProviderDefinitions struct {
 	Version   *version.Version    `yaml:"version" json:"version"`
@@ -29,7 +29,14 @@ func (a *ProviderActivities) AuthorizeRole(
 ) (*AuthorizeRoleResponse, error) {
 
 	logrus.Infoln("Starting AuthorizeRole activity")
-	return handleNotImplementedError(a.provider.AuthorizeRole(ctx, req))
+
+	rbac, err := AsRoleBasedAccessControl(a.provider)
+
+	if err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), "NotImplementedError", err)
+	}
+
+	return handleNotImplementedError(rbac.AuthorizeRole(ctx, req))
 
 }
 
@@ -39,7 +46,14 @@ func (a *ProviderActivities) RevokeRole(
 ) (*RevokeRoleResponse, error) {
 
 	logrus.Infoln("Starting RevokeRole activity")
-	return handleNotImplementedError(a.provider.RevokeRole(ctx, req))
+
+	rbac, err := AsRoleBasedAccessControl(a.provider)
+
+	if err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), "NotImplementedError", err)
+	}
+
+	return handleNotImplementedError(rbac.RevokeRole(ctx, req))
 
 }
 
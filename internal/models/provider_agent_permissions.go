@@ -0,0 +1,144 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProviderAgentPermissionsValidator checks, without changing anything, that
+// the agent's own credentials have the permissions the provider needs to
+// grant and revoke access. Grants otherwise fail part way through the
+// first elevation that needs the missing permission.
+type ProviderAgentPermissionsValidator interface {
+	ValidateAgentPermissions(ctx context.Context) ([]AgentPermission, error)
+}
+
+// ValidateAgentPermissions is the default for providers whose platform has
+// no way to check permissions without using them
+func (p *BaseProvider) ValidateAgentPermissions(ctx context.Context) ([]AgentPermission, error) {
+	return nil, ErrNotImplemented
+}
+
+// AgentPermission is a permission the provider needs and whether the
+// agent's credentials have it
+type AgentPermission struct {
+	Permission string `json:"permission"`         // e.g. iam:CreateRole
+	Resource   string `json:"resource,omitempty"` // The resource it was checked on e.g. projects/acme-prod
+	Allowed    bool   `json:"allowed"`
+	Reason     string `json:"reason,omitempty"` // Why it was denied, if the platform says
+}
+
+func (p AgentPermission) String() string {
+	if len(p.Resource) == 0 {
+		return p.Permission
+	}
+	return fmt.Sprintf("%s on %s", p.Permission, p.Resource)
+}
+
+// AgentPermissionsStatus is the outcome of validating a provider's
+// permissions
+type AgentPermissionsStatus string
+
+const (
+	AgentPermissionsStatusOK          AgentPermissionsStatus = "ok"
+	AgentPermissionsStatusMissing     AgentPermissionsStatus = "missing"
+	AgentPermissionsStatusUnsupported AgentPermissionsStatus = "unsupported"
+	AgentPermissionsStatusError       AgentPermissionsStatus = "error"
+)
+
+// AgentPermissionsResult summarizes the permissions a provider is missing
+type AgentPermissionsResult struct {
+	Provider  string                 `json:"provider"`
+	Status    AgentPermissionsStatus `json:"status"`
+	Checked   int                    `json:"checked"`
+	Missing   []AgentPermission      `json:"missing,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	CheckedAt time.Time              `json:"checked_at"`
+}
+
+// NewAgentPermissionsResult builds a result from a provider's permission
+// checks
+func NewAgentPermissionsResult(provider string, permissions []AgentPermission, err error) *AgentPermissionsResult {
+
+	result := &AgentPermissionsResult{
+		Provider:  provider,
+		Status:    AgentPermissionsStatusOK,
+		Checked:   len(permissions),
+		CheckedAt: time.Now().UTC(),
+	}
+
+	if errors.Is(err, ErrNotImplemented) {
+		result.Status = AgentPermissionsStatusUnsupported
+		return result
+	} else if err != nil {
+		result.Status = AgentPermissionsStatusError
+		result.Error = err.Error()
+		return result
+	}
+
+	for _, permission := range permissions {
+		if !permission.Allowed {
+			result.Missing = append(result.Missing, permission)
+		}
+	}
+
+	if len(result.Missing) > 0 {
+		result.Status = AgentPermissionsStatusMissing
+	}
+
+	return result
+}
+
+// HasProblem returns true if permissions are missing or couldn't be checked
+func (r *AgentPermissionsResult) HasProblem() bool {
+	return r != nil && (r.Status == AgentPermissionsStatusMissing || r.Status == AgentPermissionsStatusError)
+}
+
+// GetSummary describes the missing permissions, or why they couldn't be
+// checked
+func (r *AgentPermissionsResult) GetSummary() string {
+
+	switch r.Status {
+	case AgentPermissionsStatusMissing:
+		missing := make([]string, 0, len(r.Missing))
+		for _, permission := range r.Missing {
+			missing = append(missing, permission.String())
+		}
+		return fmt.Sprintf("missing %d of %d permissions: %s",
+			len(r.Missing), r.Checked, strings.Join(missing, ", "))
+	case AgentPermissionsStatusError:
+		return fmt.Sprintf("failed to check permissions: %s", r.Error)
+	case AgentPermissionsStatusUnsupported:
+		return "permissions can't be checked for this provider"
+	}
+
+	return fmt.Sprintf("all %d permissions granted", r.Checked)
+}
+
+// ToConnectionResult reports the permissions check alongside the smoke
+// checks. Providers that can't be checked are skipped.
+func (r *AgentPermissionsResult) ToConnectionResult(latency time.Duration) ProviderConnectionResult {
+
+	result := ProviderConnectionResult{
+		Provider:   r.Provider,
+		Capability: ProviderConnectionPermissions,
+		Latency:    latency,
+		LatencyMs:  latency.Milliseconds(),
+		Passed:     !r.HasProblem(),
+		Skipped:    r.Status == AgentPermissionsStatusUnsupported,
+	}
+
+	// Missing permissions are listed separately as there can be many
+	switch r.Status {
+	case AgentPermissionsStatusMissing:
+		result.Error = fmt.Sprintf("missing %d of %d permissions", len(r.Missing), r.Checked)
+		result.MissingPermissions = r.Missing
+	case AgentPermissionsStatusError:
+		result.Error = r.GetSummary()
+	}
+
+	return result
+}
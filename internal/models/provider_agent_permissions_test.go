@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAgentPermissionsResult(t *testing.T) {
+
+	permissions := []AgentPermission{
+		{Permission: "iam:GetRole", Allowed: true},
+		{Permission: "iam:CreateRole", Allowed: false, Reason: "implicitDeny"},
+		{Permission: "iam.roles.create", Resource: "projects/acme", Allowed: false},
+	}
+
+	t.Run("missing permissions", func(t *testing.T) {
+		result := NewAgentPermissionsResult("aws", permissions, nil)
+
+		assert.Equal(t, AgentPermissionsStatusMissing, result.Status)
+		assert.Equal(t, 3, result.Checked)
+		assert.Len(t, result.Missing, 2)
+		assert.True(t, result.HasProblem())
+		assert.Equal(t, "missing 2 of 3 permissions: iam:CreateRole, iam.roles.create on projects/acme",
+			result.GetSummary())
+	})
+
+	t.Run("all granted", func(t *testing.T) {
+		result := NewAgentPermissionsResult("aws", permissions[:1], nil)
+
+		assert.Equal(t, AgentPermissionsStatusOK, result.Status)
+		assert.False(t, result.HasProblem())
+		assert.Equal(t, "all 1 permissions granted", result.GetSummary())
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		result := NewAgentPermissionsResult("okta", nil,
+			fmt.Errorf("%w: federated user", ErrNotImplemented))
+
+		assert.Equal(t, AgentPermissionsStatusUnsupported, result.Status)
+		assert.False(t, result.HasProblem())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		result := NewAgentPermissionsResult("gcp", nil, errors.New("permission denied"))
+
+		assert.Equal(t, AgentPermissionsStatusError, result.Status)
+		assert.True(t, result.HasProblem())
+		assert.Equal(t, "failed to check permissions: permission denied", result.GetSummary())
+	})
+}
+
+func TestAgentPermissionsResultToConnectionResult(t *testing.T) {
+
+	missing := NewAgentPermissionsResult("aws", []AgentPermission{
+		{Permission: "iam:CreateRole", Allowed: false},
+	}, nil).ToConnectionResult(time.Second)
+
+	assert.Equal(t, ProviderConnectionPermissions, missing.Capability)
+	assert.False(t, missing.Passed)
+	assert.Equal(t, int64(1000), missing.LatencyMs)
+	assert.Len(t, missing.MissingPermissions, 1)
+	assert.Equal(t, "missing 1 of 1 permissions", missing.Error)
+
+	unsupported := NewAgentPermissionsResult("okta", nil, ErrNotImplemented).ToConnectionResult(0)
+
+	assert.True(t, unsupported.Passed)
+	assert.True(t, unsupported.Skipped)
+	assert.Empty(t, unsupported.Error)
+}
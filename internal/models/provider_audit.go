@@ -0,0 +1,47 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// ProviderAuditTrail is implemented by providers that can look up what a
+// user did while they had access e.g. from AWS CloudTrail. Ops teams use
+// this after a grant is revoked to review the access window.
+type ProviderAuditTrail interface {
+	// GetAuditPrincipal returns the provider's identifier for the user that
+	// its audit trail is recorded against e.g. an AWS IAM user ARN
+	GetAuditPrincipal(user *User) (string, error)
+	GetAccessAuditTrail(ctx context.Context, principal string, startTime time.Time, endTime time.Time) ([]AuditEvent, error)
+}
+
+// AuditEvent is an API call made by a user as recorded by the provider
+type AuditEvent struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	Name      string    `json:"name"`             // e.g. TerminateInstances
+	Source    string    `json:"source,omitempty"` // e.g. ec2.amazonaws.com
+	Username  string    `json:"username,omitempty"`
+	SourceIP  string    `json:"source_ip,omitempty"`
+	ReadOnly  bool      `json:"read_only"`
+	Error     string    `json:"error,omitempty"` // The error code if the call failed
+	Resources []string  `json:"resources,omitempty"`
+}
+
+// AuditTrail is the audit trail of a single provider for an elevation
+type AuditTrail struct {
+	Provider  string       `json:"provider"`
+	Principal string       `json:"principal,omitempty"`
+	Events    []AuditEvent `json:"events"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// AuditTrailResponse is what the user did across the providers of an
+// elevation during its access window
+type AuditTrailResponse struct {
+	WorkflowID string       `json:"id"`
+	User       string       `json:"user"`
+	StartTime  time.Time    `json:"start_time"`
+	EndTime    time.Time    `json:"end_time"`
+	Trails     []AuditTrail `json:"trails"`
+}
@@ -2,9 +2,10 @@ package models
 
 import (
 	"context"
-	"fmt"
 )
 
+// ProviderAuthorizor is implemented by providers that sign users in,
+// advertised with ProviderCapabilityAuthorizer. Use AsAuthorizor to get it.
 type ProviderAuthorizor interface {
 
 	// Allow this provider to authorize a user
@@ -13,25 +14,3 @@ type ProviderAuthorizor interface {
 	ValidateSession(ctx context.Context, session *Session) error
 	RenewSession(ctx context.Context, session *Session) (*Session, error)
 }
-
-/* Default implementations for authorizers */
-
-func (p *BaseProvider) AuthorizeSession(ctx context.Context, auth *AuthorizeUser) (*AuthorizeSessionResponse, error) {
-	// Default implementation does nothing
-	return nil, fmt.Errorf("the provider '%s' does not implement AuthorizeSession", p.GetProvider())
-}
-
-func (p *BaseProvider) CreateSession(ctx context.Context, auth *AuthorizeUser) (*Session, error) {
-	// Default implementation does nothing
-	return nil, fmt.Errorf("the provider '%s' does not implement CreateSession", p.GetProvider())
-}
-
-func (p *BaseProvider) ValidateSession(ctx context.Context, session *Session) error {
-	// Default implementation does nothing
-	return fmt.Errorf("the provider '%s' does not implement ValidateSession", p.GetProvider())
-}
-
-func (p *BaseProvider) RenewSession(ctx context.Context, session *Session) (*Session, error) {
-	// Default implementation does nothing
-	return nil, fmt.Errorf("the provider '%s' does not implement RenewSession", p.GetProvider())
-}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"slices"
 	"strings"
 	"sync"
@@ -30,6 +33,7 @@ type IdentitySupport struct {
 	identities      []Identity
 	identitiesMap   map[string]*Identity
 	identitiesIndex bleve.Index
+	identitiesBuild context.CancelFunc
 }
 
 type RBACSupport struct {
@@ -39,16 +43,19 @@ type RBACSupport struct {
 	permissions      []ProviderPermission
 	permissionsMap   map[string]*ProviderPermission // map is a pointer back to the permissions list
 	permissionsIndex bleve.Index
+	permissionsBuild context.CancelFunc
 
 	// Role management
 	roles      []ProviderRole
 	rolesMap   map[string]*ProviderRole // map is a pointer back to the roles list
 	rolesIndex bleve.Index
+	rolesBuild context.CancelFunc
 
 	// Resource management
 	resources      []ProviderResource
 	resourcesMap   map[string]*ProviderResource // map is a pointer back to the resources list
 	resourcesIndex bleve.Index
+	resourcesBuild context.CancelFunc
 }
 
 func NewBaseProvider(identifier string, provider Provider, capabilities ...ProviderCapability) *BaseProvider {
@@ -70,8 +77,9 @@ func NewBaseProvider(identifier string, provider Provider, capabilities ...Provi
 		}
 	}
 
-	if base.HasCapability(ProviderCapabilityRBAC) {
-		// Initialize RBAC structures if needed
+	if base.HasAnyCapability(ProviderCapabilityRBAC, ProviderCapabilityResourceDiscovery) {
+		// Initialize RBAC structures if needed. Resources are stored
+		// alongside roles and permissions.
 		base.rbac = &RBACSupport{
 			permissions:    make([]ProviderPermission, 0),
 			permissionsMap: make(map[string]*ProviderPermission),
@@ -124,10 +132,14 @@ func (p *BaseProvider) SetPermissionsWithKey(
 		p.rbac.permissionsMap[strings.ToLower(keyName)] = perm
 	}
 
-	// Trigger reindex
+	// Trigger reindex, cancelling any build of the previous permissions
+	ctx := restartIndexBuild(&p.rbac.permissionsBuild)
 	go func() {
-		err := p.buildPermissionIndices()
-		if err != nil {
+		err := p.buildPermissionIndices(ctx, permissions)
+		if errors.Is(err, context.Canceled) {
+			logrus.Debugln("Superseded permission search index build cancelled")
+			return
+		} else if err != nil {
 			logrus.WithError(err).Error("Failed to build rbac search indices")
 			return
 		}
@@ -192,10 +204,14 @@ func (p *BaseProvider) SetRolesWithKey(
 		}
 	}
 
-	// Trigger reindex
+	// Trigger reindex, cancelling any build of the previous roles
+	ctx := restartIndexBuild(&p.rbac.rolesBuild)
 	go func() {
-		err := p.buildRoleIndices()
-		if err != nil {
+		err := p.buildRoleIndices(ctx, roles)
+		if errors.Is(err, context.Canceled) {
+			logrus.Debugln("Superseded role search index build cancelled")
+			return
+		} else if err != nil {
 			logrus.WithError(err).Error("Failed to build role search indices")
 			return
 		}
@@ -258,10 +274,14 @@ func (p *BaseProvider) SetResourcesWithKey(
 		}
 	}
 
-	// Trigger reindex
+	// Trigger reindex, cancelling any build of the previous resources
+	ctx := restartIndexBuild(&p.rbac.resourcesBuild)
 	go func() {
-		err := p.buildResourceIndices()
-		if err != nil {
+		err := p.buildResourceIndices(ctx, resources)
+		if errors.Is(err, context.Canceled) {
+			logrus.Debugln("Superseded resource search index build cancelled")
+			return
+		} else if err != nil {
 			logrus.WithError(err).Error("Failed to build resources search indices")
 			return
 		}
@@ -367,10 +387,14 @@ func (p *BaseProvider) SetIdentitiesWithKey(
 		}
 	}
 
-	// Trigger reindex
+	// Trigger reindex, cancelling any build of the previous identities
+	ctx := restartIndexBuild(&p.identity.identitiesBuild)
 	go func() {
-		err := p.buildIdentitiyIndices()
-		if err != nil {
+		err := p.buildIdentitiyIndices(ctx, identities)
+		if errors.Is(err, context.Canceled) {
+			logrus.Debugln("Superseded identity search index build cancelled")
+			return
+		} else if err != nil {
 			logrus.WithError(err).Error("Failed to build identity search indices")
 			return
 		}
@@ -396,6 +420,58 @@ func (p *BaseProvider) AddIdentities(identities ...Identity) {
 	p.SetIdentities(combined)
 }
 
+// restartIndexBuild cancels the in-flight build of an index, if any, and
+// returns the context for its replacement. The caller must hold the lock
+// guarding the cancel func.
+func restartIndexBuild(cancel *context.CancelFunc) context.Context {
+	if *cancel != nil {
+		(*cancel)()
+	}
+	ctx, newCancel := context.WithCancel(context.Background())
+	*cancel = newCancel
+	return ctx
+}
+
+// indexBatchSize is how many documents are indexed between cancellation
+// checks
+const indexBatchSize = 500
+
+// indexDocuments indexes the items in batches, stopping early when the
+// context is cancelled
+func indexDocuments[T any](ctx context.Context, index bleve.Index, items []T, idFunc func(item *T) string) error {
+
+	batch := index.NewBatch()
+
+	for i := range items {
+
+		item := &items[i]
+
+		if err := batch.Index(idFunc(item), *item); err != nil {
+			return fmt.Errorf("failed to index %s: %w", idFunc(item), err)
+		}
+
+		if batch.Size() < indexBatchSize {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := index.Batch(batch); err != nil {
+			return err
+		}
+
+		batch.Reset()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return index.Batch(batch)
+}
+
 func (p *BaseProvider) GetIdentifier() string {
 	return p.identifier
 }
@@ -436,7 +512,7 @@ func (p *BaseProvider) DisableCapability(capability ProviderCapability) {
 	})
 }
 
-func (p *BaseProvider) Initialize(identifier string, provider Provider) error {
+func (p *BaseProvider) Initialize(ctx context.Context, identifier string, provider Provider) error {
 	// Initialize the provider
 	return nil
 }
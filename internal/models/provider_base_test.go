@@ -1,9 +1,13 @@
 package models
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
+	"github.com/blevesearch/bleve/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBaseProvider_Permissions(t *testing.T) {
@@ -118,3 +122,54 @@ func TestBaseProvider_Identities(t *testing.T) {
 	p.AddIdentities(id1)
 	assert.Len(t, p.identity.identities, 2, "Should not add duplicate identity")
 }
+
+func TestBaseProvider_ResourceDiscovery(t *testing.T) {
+	ctx := context.Background()
+
+	p := NewBaseProvider("cloudflare", Provider{Provider: "cloudflare"}, ProviderCapabilityResourceDiscovery)
+	p.SetResources([]ProviderResource{
+		{ID: "zone-1", Type: "zone", Name: "example.com"},
+		{ID: "account-1", Type: "account", Name: "Example"},
+	})
+
+	resources, err := p.ListResources(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, resources, 2)
+
+	resource, err := p.GetResource(ctx, "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "zone-1", resource.ID)
+
+	// Providers without the capability have no resources
+	other := NewBaseProvider("slack", Provider{Provider: "slack"}, ProviderCapabilityNotifier)
+	_, err = other.ListResources(ctx, nil)
+	assert.Error(t, err)
+
+	capability, err := GetCapabilityFromString("resources")
+	require.NoError(t, err)
+	assert.Equal(t, ProviderCapabilityResourceDiscovery, capability)
+}
+
+func TestBaseProvider_IndexBuildCancelled(t *testing.T) {
+	permissions := make([]ProviderPermission, indexBatchSize*3)
+	for i := range permissions {
+		permissions[i] = ProviderPermission{Name: fmt.Sprintf("perm%d", i)}
+	}
+
+	index, err := bleve.NewMemOnly(bleve.NewIndexMapping())
+	require.NoError(t, err)
+	defer index.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = indexDocuments(ctx, index, permissions, func(perm *ProviderPermission) string {
+		return perm.Name
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// The build stops at the first batch boundary
+	count, err := index.DocCount()
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
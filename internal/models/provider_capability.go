@@ -0,0 +1,165 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCapabilityNotSupported is returned when a provider is used for a
+// capability it doesn't implement
+var ErrCapabilityNotSupported = errors.New("capability not supported")
+
+// CapabilityError names the provider and the capability it doesn't support
+type CapabilityError struct {
+	Provider   string
+	Capability ProviderCapability
+}
+
+func (e *CapabilityError) Error() string {
+	return fmt.Sprintf("provider %s does not support %s", e.Provider, e.Capability.GetDescription())
+}
+
+func (e *CapabilityError) Unwrap() error {
+	return ErrCapabilityNotSupported
+}
+
+// GetDescription returns what the capability lets a provider do
+func (c ProviderCapability) GetDescription() string {
+	switch c {
+	case ProviderCapabilityRBAC:
+		return "role management"
+	case ProviderCapabilityAuthorizer:
+		return "authentication"
+	case ProviderCapabilityNotifier:
+		return "notifications"
+	case ProviderCapabilityIdentities:
+		return "identities"
+	case ProviderCapabilityResourceDiscovery:
+		return "resource discovery"
+	}
+	return fmt.Sprintf("the %s capability", string(c))
+}
+
+// ProviderWrapper is implemented by providers that wrap another, e.g. read
+// only providers. Wrappers implement every capability interface so the
+// capability lookups check the provider they wrap.
+type ProviderWrapper interface {
+	Unwrap() ProviderImpl
+}
+
+// AsRoleBasedAccessControl returns the provider's role management, or an
+// error naming the provider if it can't grant roles
+func AsRoleBasedAccessControl(provider ProviderImpl) (ProviderRoleBasedAccessControl, error) {
+	return asCapability[ProviderRoleBasedAccessControl](provider, ProviderCapabilityRBAC)
+}
+
+// AsAuthorizor returns the provider's authentication, or an error naming
+// the provider if it can't sign users in
+func AsAuthorizor(provider ProviderImpl) (ProviderAuthorizor, error) {
+	return asCapability[ProviderAuthorizor](provider, ProviderCapabilityAuthorizer)
+}
+
+// AsNotifier returns the provider's notifications, or an error naming the
+// provider if it can't send them
+func AsNotifier(provider ProviderImpl) (ProviderNotifier, error) {
+	return asCapability[ProviderNotifier](provider, ProviderCapabilityNotifier)
+}
+
+// AsIdentities returns the provider's identities. Every provider can hold
+// identities, through BaseProvider, so only those advertising
+// ProviderCapabilityIdentities are returned.
+func AsIdentities(provider ProviderImpl) (ProviderIdentities, error) {
+
+	if err := checkCapability(provider, ProviderCapabilityIdentities); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// AsResourceDiscoverer returns the provider's live resources. As with
+// identities they are held by BaseProvider, so only providers advertising
+// ProviderCapabilityResourceDiscovery or ProviderCapabilityRBAC are returned.
+func AsResourceDiscoverer(provider ProviderImpl) (ResourceDiscoverer, error) {
+
+	if err := checkCapability(provider,
+		ProviderCapabilityResourceDiscovery, ProviderCapabilityRBAC); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+func asCapability[T any](provider ProviderImpl, capability ProviderCapability) (T, error) {
+
+	var none T
+
+	if provider == nil {
+		return none, fmt.Errorf("provider implementation is nil. Ensure the provider is initialized")
+	}
+
+	if wrapper, ok := provider.(ProviderWrapper); ok {
+		if _, err := asCapability[T](wrapper.Unwrap(), capability); err != nil {
+			return none, err
+		}
+	}
+
+	implementation, ok := provider.(T)
+
+	if !ok {
+		return none, &CapabilityError{
+			Provider:   provider.GetIdentifier(),
+			Capability: capability,
+		}
+	}
+
+	return implementation, nil
+}
+
+func checkCapability(provider ProviderImpl, capabilities ...ProviderCapability) error {
+
+	if provider == nil {
+		return fmt.Errorf("provider implementation is nil. Ensure the provider is initialized")
+	}
+
+	if !provider.HasAnyCapability(capabilities...) {
+		return &CapabilityError{
+			Provider:   provider.GetIdentifier(),
+			Capability: capabilities[0],
+		}
+	}
+
+	return nil
+}
+
+// GetRBAC returns the provider's role management, see AsRoleBasedAccessControl
+func (p *Provider) GetRBAC() (ProviderRoleBasedAccessControl, error) {
+	return AsRoleBasedAccessControl(p.getClient())
+}
+
+// GetAuthorizor returns the provider's authentication, see AsAuthorizor
+func (p *Provider) GetAuthorizor() (ProviderAuthorizor, error) {
+	return AsAuthorizor(p.getClient())
+}
+
+// GetNotifier returns the provider's notifications, see AsNotifier
+func (p *Provider) GetNotifier() (ProviderNotifier, error) {
+	return AsNotifier(p.getClient())
+}
+
+// GetIdentities returns the provider's identities, see AsIdentities
+func (p *Provider) GetIdentities() (ProviderIdentities, error) {
+	return AsIdentities(p.getClient())
+}
+
+// GetResourceDiscoverer returns the provider's resources, see AsResourceDiscoverer
+func (p *Provider) GetResourceDiscoverer() (ResourceDiscoverer, error) {
+	return AsResourceDiscoverer(p.getClient())
+}
+
+func (p *Provider) getClient() ProviderImpl {
+	if p == nil {
+		return nil
+	}
+	return p.client
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type capabilityRBACProvider struct {
+	*BaseProvider
+}
+
+func (p *capabilityRBACProvider) AuthorizeRole(
+	ctx context.Context, req *AuthorizeRoleRequest) (*AuthorizeRoleResponse, error) {
+	return &AuthorizeRoleResponse{}, nil
+}
+
+func (p *capabilityRBACProvider) RevokeRole(
+	ctx context.Context, req *RevokeRoleRequest) (*RevokeRoleResponse, error) {
+	return &RevokeRoleResponse{}, nil
+}
+
+// capabilityWrapper implements role management itself but defers to the
+// provider it wraps for what is supported
+type capabilityWrapper struct {
+	capabilityRBACProvider
+	wrapped ProviderImpl
+}
+
+func (p *capabilityWrapper) Unwrap() ProviderImpl {
+	return p.wrapped
+}
+
+func TestProviderCapabilities(t *testing.T) {
+
+	base := NewBaseProvider("login", Provider{Name: "login"}, ProviderCapabilityAuthorizer)
+	rbac := &capabilityRBACProvider{
+		BaseProvider: NewBaseProvider("aws", Provider{Name: "aws"}, ProviderCapabilityRBAC),
+	}
+
+	t.Run("supported", func(t *testing.T) {
+		impl, err := AsRoleBasedAccessControl(rbac)
+		require.NoError(t, err)
+		assert.Same(t, rbac, impl)
+
+		_, err = AsResourceDiscoverer(rbac)
+		assert.NoError(t, err)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := AsRoleBasedAccessControl(base)
+		require.ErrorIs(t, err, ErrCapabilityNotSupported)
+		assert.EqualError(t, err, "provider login does not support role management")
+
+		_, err = AsNotifier(rbac)
+		assert.ErrorIs(t, err, ErrCapabilityNotSupported)
+
+		_, err = AsAuthorizor(rbac)
+		assert.ErrorIs(t, err, ErrCapabilityNotSupported)
+
+		_, err = AsIdentities(base)
+		assert.ErrorIs(t, err, ErrCapabilityNotSupported)
+	})
+
+	t.Run("nil provider", func(t *testing.T) {
+		_, err := AsRoleBasedAccessControl(nil)
+		assert.Error(t, err)
+
+		var provider *Provider
+		_, err = provider.GetRBAC()
+		assert.Error(t, err)
+
+		_, err = (&Provider{Name: "uninitialized"}).GetNotifier()
+		assert.Error(t, err)
+	})
+
+	t.Run("wrapped", func(t *testing.T) {
+		wrapper := &capabilityWrapper{capabilityRBACProvider: *rbac, wrapped: rbac}
+		impl, err := AsRoleBasedAccessControl(wrapper)
+		require.NoError(t, err)
+		assert.Same(t, wrapper, impl)
+
+		// The wrapper can grant roles, but the provider it wraps can't
+		wrapper.wrapped = base
+		_, err = AsRoleBasedAccessControl(wrapper)
+		assert.ErrorIs(t, err, ErrCapabilityNotSupported)
+	})
+
+	t.Run("provider", func(t *testing.T) {
+		provider := Provider{Name: "aws"}
+		provider.SetClient(rbac)
+
+		_, err := provider.GetRBAC()
+		assert.NoError(t, err)
+
+		_, err = provider.GetAuthorizor()
+		assert.ErrorIs(t, err, ErrCapabilityNotSupported)
+	})
+}
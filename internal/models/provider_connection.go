@@ -0,0 +1,110 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ProviderConnectionTester runs a cheap, read-only smoke check against the
+// provider for a single capability e.g. verifying notifier auth or listing
+// one page of roles. It is used by `thand providers test` to catch bad
+// credentials before the first elevation fails.
+type ProviderConnectionTester interface {
+	TestConnection(ctx context.Context, capability ProviderCapability) error
+}
+
+// TestConnection is the default fallback for providers without a smoke
+// check. Initialization succeeding is the only signal we have.
+func (p *BaseProvider) TestConnection(ctx context.Context, capability ProviderCapability) error {
+	return ErrNotImplemented
+}
+
+// ProviderConnectionInitialize is reported as the capability when the
+// provider fails to initialize and no smoke checks could run
+const ProviderConnectionInitialize ProviderCapability = "initialize"
+
+// ProviderConnectionPermissions is reported as the capability when the
+// agent's own permissions are validated
+const ProviderConnectionPermissions ProviderCapability = "permissions"
+
+// ProviderConnectionResult is the outcome of a smoke check for one
+// provider capability
+type ProviderConnectionResult struct {
+	Provider   string             `json:"provider"`
+	Capability ProviderCapability `json:"capability"`
+	Latency    time.Duration      `json:"-"`
+	LatencyMs  int64              `json:"latency_ms"`
+	Passed     bool               `json:"passed"`
+	Skipped    bool               `json:"skipped,omitempty"` // No smoke check, only initialization was tested
+	Error      string             `json:"error,omitempty"`
+
+	MissingPermissions []AgentPermission `json:"missing_permissions,omitempty"`
+}
+
+// NewProviderConnectionResult builds a result from a smoke check error
+func NewProviderConnectionResult(
+	provider string,
+	capability ProviderCapability,
+	latency time.Duration,
+	err error,
+) ProviderConnectionResult {
+
+	result := ProviderConnectionResult{
+		Provider:   provider,
+		Capability: capability,
+		Latency:    latency,
+		LatencyMs:  latency.Milliseconds(),
+		Passed:     err == nil,
+	}
+
+	if errors.Is(err, ErrNotImplemented) {
+		result.Passed = true
+		result.Skipped = true
+	} else if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// ProviderHealth is the combined result of smoke checking every capability
+// of an initialized provider
+type ProviderHealth struct {
+	Status    HealthState                `json:"status"`
+	CheckedAt time.Time                  `json:"checked_at"`
+	Checks    []ProviderConnectionResult `json:"checks,omitempty"`
+}
+
+// NewProviderHealth is healthy if every check passed, unhealthy if every
+// check failed and degraded otherwise
+func NewProviderHealth(checks []ProviderConnectionResult) *ProviderHealth {
+
+	passed := 0
+
+	for _, check := range checks {
+		if check.Passed {
+			passed++
+		}
+	}
+
+	health := &ProviderHealth{
+		Status:    HealthStatusDegraded,
+		CheckedAt: time.Now().UTC(),
+		Checks:    checks,
+	}
+
+	switch passed {
+	case len(checks):
+		health.Status = HealthStatusHealthy
+	case 0:
+		health.Status = HealthStatusUnhealthy
+	}
+
+	return health
+}
+
+// IsHealthy returns true if every check passed
+func (h *ProviderHealth) IsHealthy() bool {
+	return h != nil && h.Status == HealthStatusHealthy
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// CredentialArtifact is secret material minted for the requester e.g.
+// database credentials from Vault. The credentials are only ever held
+// encrypted for the public key sent with the request, so they never appear
+// in plaintext in the workflow history or the execution API.
+type CredentialArtifact struct {
+	Provider  string    `json:"provider"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Encrypted string    `json:"encrypted"` // ASCII armored age encryption of the credentials as JSON
+}
+
+// ValidatePublicKey checks the key is an age X25519 recipient e.g. age1...
+func ValidatePublicKey(publicKey string) error {
+
+	if _, err := age.ParseX25519Recipient(publicKey); err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+
+	return nil
+}
+
+// NewCredentialArtifact encrypts the credentials for the public key
+func NewCredentialArtifact(
+	provider string,
+	role string,
+	publicKey string,
+	credentials map[string]any,
+	expiresAt time.Time,
+) (*CredentialArtifact, error) {
+
+	if len(publicKey) == 0 {
+		return nil, fmt.Errorf("a public key is required to return credentials to the requester")
+	}
+
+	recipient, err := age.ParseX25519Recipient(publicKey)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	plaintext, err := json.Marshal(credentials)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode credentials: %w", err)
+	}
+
+	var encrypted bytes.Buffer
+
+	armored := armor.NewWriter(&encrypted)
+
+	writer, err := age.Encrypt(armored, recipient)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+
+	if _, err := writer.Write(plaintext); err != nil {
+		return nil, fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+
+	if err := armored.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+
+	return &CredentialArtifact{
+		Provider:  provider,
+		Role:      role,
+		ExpiresAt: expiresAt.UTC(),
+		Encrypted: encrypted.String(),
+	}, nil
+}
+
+// Decrypt returns the credentials with the identity the public key belongs to
+func (a *CredentialArtifact) Decrypt(identity age.Identity) (map[string]any, error) {
+
+	reader, err := age.Decrypt(armor.NewReader(strings.NewReader(a.Encrypted)), identity)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credentials: %w", err)
+	}
+
+	plaintext, err := io.ReadAll(reader)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credentials: %w", err)
+	}
+
+	credentials := map[string]any{}
+
+	if err := json.Unmarshal(plaintext, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// GetCredentialArtifacts returns the credentials minted by the authorize
+// task, ordered by provider and role
+func (r *WorkflowTask) GetCredentialArtifacts() []*CredentialArtifact {
+
+	providerAuthorizations, err := GetContextAs[map[string]map[string]*AuthorizeRoleResponse](
+		r, VarsContextProviderAuthorizations)
+
+	if err != nil {
+		return nil
+	}
+
+	artifacts := []*CredentialArtifact{}
+
+	for _, authorizations := range providerAuthorizations {
+		for _, authorization := range authorizations {
+			if authorization != nil && authorization.Credentials != nil {
+				artifacts = append(artifacts, authorization.Credentials)
+			}
+		}
+	}
+
+	slices.SortStableFunc(artifacts, func(a, b *CredentialArtifact) int {
+		if order := strings.Compare(a.Provider, b.Provider); order != 0 {
+			return order
+		}
+		return strings.Compare(a.Role, b.Role)
+	})
+
+	return artifacts
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialArtifact(t *testing.T) {
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	expiresAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	artifact, err := NewCredentialArtifact("vault", "db-readonly", identity.Recipient().String(),
+		map[string]any{"username": "v-readonly", "password": "hunter2"}, expiresAt)
+	require.NoError(t, err)
+
+	assert.Contains(t, artifact.Encrypted, "BEGIN AGE ENCRYPTED FILE")
+	assert.NotContains(t, artifact.Encrypted, "hunter2")
+	assert.Equal(t, expiresAt, artifact.ExpiresAt)
+
+	credentials, err := artifact.Decrypt(identity)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"username": "v-readonly", "password": "hunter2"}, credentials)
+
+	// Only the requester's key can decrypt them
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	_, err = artifact.Decrypt(other)
+	assert.Error(t, err)
+
+	_, err = NewCredentialArtifact("vault", "db-readonly", "", nil, expiresAt)
+	assert.Error(t, err)
+
+	assert.NoError(t, ValidatePublicKey(identity.Recipient().String()))
+	assert.Error(t, ValidatePublicKey("ssh-ed25519 AAAA"))
+}
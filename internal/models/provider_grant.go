@@ -0,0 +1,109 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+)
+
+// GrantReferencePrefix marks artifacts created by thand. References only
+// use letters and digits so they fit every provider's naming rules e.g.
+// IAM statement IDs and Kubernetes label values.
+const GrantReferencePrefix = "thand"
+
+// GrantRef records what a provider created when authorizing a role. The
+// workflow stores it with the authorize response and passes it back on
+// revoke, so a retried authorize finds the existing grant rather than
+// creating a second one, and revoke removes exactly what was granted.
+type GrantRef struct {
+	Provider   string            `json:"provider"`             // The provider that created the grant
+	Reference  string            `json:"reference"`            // Deterministic reference derived from the workflow
+	Kind       string            `json:"kind,omitempty"`       // What was created e.g. role_assignment, iam_binding
+	IDs        []string          `json:"ids,omitempty"`        // Provider identifiers of what was created
+	Attributes map[string]string `json:"attributes,omitempty"` // Any other identifiers needed to revoke e.g. namespace
+	Simulated  bool              `json:"simulated,omitempty"`  // Nothing was created as the provider is read only
+}
+
+// NewGrantRef creates a grant reference for the request
+func NewGrantRef(provider string, kind string, req *RoleRequest) *GrantRef {
+	return &GrantRef{
+		Provider:  provider,
+		Reference: req.GetGrantReference(),
+		Kind:      kind,
+	}
+}
+
+// AddID records an identifier of something the grant created
+func (g *GrantRef) AddID(id string) {
+	if len(id) > 0 && !slices.Contains(g.IDs, id) {
+		g.IDs = append(g.IDs, id)
+	}
+}
+
+// SetAttribute records an identifier needed to revoke the grant
+func (g *GrantRef) SetAttribute(key string, value string) {
+	if g.Attributes == nil {
+		g.Attributes = map[string]string{}
+	}
+	g.Attributes[key] = value
+}
+
+// GetAttribute returns an identifier recorded against the grant
+func (g *GrantRef) GetAttribute(key string) string {
+	if g == nil {
+		return ""
+	}
+	return g.Attributes[key]
+}
+
+// IsSimulated returns true when the grant was only simulated
+func (g *GrantRef) IsSimulated() bool {
+	return g != nil && g.Simulated
+}
+
+// HasIDs returns true when the grant recorded what it created
+func (g *GrantRef) HasIDs() bool {
+	return g != nil && len(g.IDs) > 0
+}
+
+// GetGrantReference returns the deterministic reference for the grant. The
+// same workflow granting the same role to the same user always gets the
+// same reference, so retries can find the grant they already created.
+func (r *RoleRequest) GetGrantReference() string {
+
+	var workflowID, identity, roleName string
+
+	if audit := r.GetAudit(); audit != nil {
+		workflowID = audit.WorkflowID
+	}
+
+	if r.User != nil {
+		identity = r.User.GetIdentity()
+	}
+
+	if r.Role != nil {
+		roleName = r.Role.Name
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join([]string{workflowID, identity, roleName}, "\x00")))
+
+	return GrantReferencePrefix + hex.EncodeToString(hash[:])[:24]
+}
+
+// GetGrantRef returns the grant recorded by the authorize response, if the
+// provider recorded one
+func (r *RevokeRoleRequest) GetGrantRef() *GrantRef {
+	if r == nil {
+		return nil
+	}
+	return r.AuthorizeRoleResponse.GetGrantRef()
+}
+
+// GetGrantRef returns the grant the provider recorded, if any
+func (r *AuthorizeRoleResponse) GetGrantRef() *GrantRef {
+	if r == nil {
+		return nil
+	}
+	return r.GrantRef
+}
@@ -120,7 +120,7 @@ func (p *BaseProvider) ListIdentities(ctx context.Context, searchRequest *Search
 	return ReturnSearchResults(filtered), nil
 }
 
-func (p *BaseProvider) buildIdentitiyIndices() error {
+func (p *BaseProvider) buildIdentitiyIndices(ctx context.Context, identities []Identity) error {
 	// Placeholder for building indices
 	startTime := time.Now()
 	defer func() {
@@ -135,10 +135,10 @@ func (p *BaseProvider) buildIdentitiyIndices() error {
 	}
 
 	// Index identities
-	for _, identity := range p.identity.identities {
-		if err := identityIndex.Index(identity.ID, identity); err != nil {
-			return fmt.Errorf("failed to index identity %s: %v", identity.ID, err)
-		}
+	if err := indexDocuments(ctx, identityIndex, identities, func(identity *Identity) string {
+		return identity.ID
+	}); err != nil {
+		return err
 	}
 
 	p.identity.mu.Lock()
@@ -146,7 +146,7 @@ func (p *BaseProvider) buildIdentitiyIndices() error {
 	p.identity.mu.Unlock()
 
 	logrus.WithFields(logrus.Fields{
-		"identities": len(p.identity.identities),
+		"identities": len(identities),
 	}).Debug("Identity search indices ready")
 
 	return nil
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
+	"go.temporal.io/sdk/activity"
+)
+
+// GetLogger returns the log entry for a provider operation. A request ID is
+// generated when the context doesn't carry one, so get the logger once per
+// operation rather than for every line.
+func (p *BaseProvider) GetLogger(ctx context.Context) *logrus.Entry {
+
+	workflowID, requestID := GetLogContext(ctx)
+
+	if len(requestID) == 0 {
+		requestID = common.NewRequestID()
+	}
+
+	identifier := ""
+	if p != nil {
+		identifier = p.GetIdentifier()
+	}
+
+	return common.WithProviderContext(
+		logrus.StandardLogger(), identifier, workflowID, requestID)
+}
+
+// GetLogContext returns the workflow and request IDs set by the task
+// executor. Temporal activities fall back to the activity's workflow and
+// activity IDs.
+func GetLogContext(ctx context.Context) (workflowID string, requestID string) {
+
+	workflowID, requestID = common.GetLogContext(ctx)
+
+	if ctx != nil && activity.IsActivity(ctx) {
+		info := activity.GetInfo(ctx)
+		if len(workflowID) == 0 {
+			workflowID = info.WorkflowExecution.ID
+		}
+		if len(requestID) == 0 {
+			requestID = info.ActivityID
+		}
+	}
+
+	return workflowID, requestID
+}
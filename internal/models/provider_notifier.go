@@ -2,20 +2,235 @@ package models
 
 import (
 	"context"
-	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thand-io/agent/internal/common"
 )
 
 type NotificationRequest map[string]any
 
+// NotificationIDKey is the payload key carrying the deterministic
+// notification ID. Providers that support idempotency keys should pass it
+// on so retried deliveries are not sent twice.
+const NotificationIDKey = "notification_id"
+
+// GetNotificationID returns the notification ID if one was set
+func (n NotificationRequest) GetNotificationID() string {
+	if id, ok := n[NotificationIDKey].(string); ok {
+		return id
+	}
+	return ""
+}
+
+// ProviderNotifier is implemented by providers that send notifications,
+// advertised with ProviderCapabilityNotifier. Use AsNotifier to get it.
 type ProviderNotifier interface {
 
-	// Allow this provider to send notifications
-	SendNotification(ctx context.Context, notification NotificationRequest) error
+	// Allow this provider to send notifications. The receipt carries the
+	// target and the provider's message identifier, if it has one.
+	SendNotification(ctx context.Context, notification NotificationRequest) (*NotificationReceipt, error)
+}
+
+// VarsContextNotifications holds the per target delivery status for notify
+// tasks, keyed by notification ID
+const VarsContextNotifications = "notifications"
+
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliveryDelivered NotificationDeliveryStatus = "delivered"
+	NotificationDeliveryFailed    NotificationDeliveryStatus = "failed"
+	NotificationDeliveryDigested  NotificationDeliveryStatus = "digested" // Buffered into a digest, sent later
+)
+
+// NotificationDelivery records the outcome of sending a notification to a
+// single target
+type NotificationDelivery struct {
+	Provider  string                     `json:"provider"`
+	Recipient string                     `json:"recipient"`
+	Status    NotificationDeliveryStatus `json:"status"`
+	Attempts  int                        `json:"attempts"`
+	Error     string                     `json:"error,omitempty"`
+	Receipts  []NotificationReceipt      `json:"receipts,omitempty"` // One per attempt
+}
+
+func (d *NotificationDelivery) IsDelivered() bool {
+	return d != nil && d.Status == NotificationDeliveryDelivered
+}
+
+// NotificationReceipt is the record of handing a notification to a provider
+// e.g. the Slack channel and message timestamp or the SES message ID, so a
+// delivery can be proven later
+type NotificationReceipt struct {
+	NotificationID string                     `json:"notification_id"`
+	Provider       string                     `json:"provider"`
+	Target         string                     `json:"target"`
+	Timestamp      time.Time                  `json:"timestamp"`
+	MessageID      string                     `json:"message_id,omitempty"` // Provider message identifier
+	Response       string                     `json:"response,omitempty"`   // Provider response e.g. the SMTP reply
+	Status         NotificationDeliveryStatus `json:"status"`
+	Error          string                     `json:"error,omitempty"`
+	Recipients     []NotificationReceipt      `json:"recipients,omitempty"` // One per recipient when each was sent their own message
+}
+
+// NewNotificationReceipt completes the receipt returned by the provider, if
+// any, with the details only the caller knows. Failures record the error.
+func NewNotificationReceipt(
+	notificationID string,
+	provider string,
+	target string,
+	receipt *NotificationReceipt,
+	err error,
+	timestamp time.Time,
+) NotificationReceipt {
+
+	var result NotificationReceipt
+
+	if receipt != nil {
+		result = *receipt
+	}
+
+	result.NotificationID = notificationID
+
+	if len(result.Provider) == 0 {
+		result.Provider = provider
+	}
+	if len(result.Target) == 0 {
+		result.Target = target
+	}
+	if result.Timestamp.IsZero() {
+		result.Timestamp = timestamp.UTC()
+	}
+
+	if err != nil {
+		result.Status = NotificationDeliveryFailed
+		result.Error = err.Error()
+	} else if len(result.Status) == 0 {
+		result.Status = NotificationDeliveryDelivered
+	}
+
+	return result
+}
+
+// GetNotificationReceipts returns the receipts of every notification sent by
+// the workflow, oldest first
+func (r *WorkflowTask) GetNotificationReceipts() []NotificationReceipt {
+
+	deliveries, err := GetContextAs[map[string]NotificationDelivery](r, VarsContextNotifications)
+
+	if err != nil {
+		return nil
+	}
+
+	receipts := []NotificationReceipt{}
+
+	for _, delivery := range deliveries {
+		receipts = append(receipts, delivery.Receipts...)
+	}
+
+	slices.SortStableFunc(receipts, func(a, b NotificationReceipt) int {
+		if order := a.Timestamp.Compare(b.Timestamp); order != 0 {
+			return order
+		}
+		return strings.Compare(a.NotificationID, b.NotificationID)
+	})
+
+	return receipts
+}
+
+// NewNotificationID creates a deterministic ID for a notification target
+// e.g. the workflow, task, provider and recipient. The same parts always
+// produce the same ID so retries can be detected. The ID is a UUID as some
+// providers require one for their idempotency keys.
+func NewNotificationID(parts ...string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(strings.Join(parts, "\x00"))).String()
 }
 
-/* Default implementations for notifiers */
+// NotificationPriorityKey is the payload key carrying the notification
+// priority. High priority notifications e.g. break-glass requests are always
+// delivered immediately, even for targets in digest mode.
+const NotificationPriorityKey = "priority"
+
+const NotificationPriorityHigh = "high"
+
+// NotificationDigestKey and NotificationDigestEntryKey are the payload keys
+// carrying the target's digest settings and the line the notification adds
+// to the digest
+const (
+	NotificationDigestKey      = "digest"
+	NotificationDigestEntryKey = "digest_entry"
+)
+
+const (
+	DefaultNotificationDigestWindow   = 5 * time.Minute
+	DefaultNotificationDigestMinBatch = 2
+)
+
+// NotificationDigest buffers the notifications for a target and sends them
+// as a single message once the window has passed. If fewer than MinBatch
+// notifications were buffered they are sent individually instead.
+type NotificationDigest struct {
+	Window   string `json:"window,omitempty"`    // e.g. 5m
+	MinBatch int    `json:"min_batch,omitempty"` // Smallest batch sent as a digest
+}
+
+// GetWindow returns how long notifications are buffered for
+func (d *NotificationDigest) GetWindow() time.Duration {
+	if d == nil || len(d.Window) == 0 {
+		return DefaultNotificationDigestWindow
+	}
+	window, err := common.ParseDuration(d.Window)
+	if err != nil {
+		return DefaultNotificationDigestWindow
+	}
+	return window
+}
+
+// GetMinBatch returns the smallest number of notifications sent as a digest
+func (d *NotificationDigest) GetMinBatch() int {
+	if d == nil || d.MinBatch <= 0 {
+		return DefaultNotificationDigestMinBatch
+	}
+	return d.MinBatch
+}
+
+// NotificationDigestEntry is the line a notification adds to a digest. The
+// approval links point at the workflow the notification was sent for.
+type NotificationDigestEntry struct {
+	WorkflowID string `json:"workflow_id"`
+	Recipient  string `json:"recipient"`
+	Summary    string `json:"summary"`
+	ApproveURL string `json:"approve_url,omitempty"`
+	DenyURL    string `json:"deny_url,omitempty"`
+	ViewURL    string `json:"view_url,omitempty"`
+}
+
+// GetDigest returns the digest settings and entry if the notification can
+// be buffered into a digest
+func (n NotificationRequest) GetDigest() (*NotificationDigest, *NotificationDigestEntry) {
+
+	if priority, ok := n[NotificationPriorityKey].(string); ok &&
+		strings.EqualFold(priority, NotificationPriorityHigh) {
+		return nil, nil
+	}
+
+	if n[NotificationDigestKey] == nil || n[NotificationDigestEntryKey] == nil {
+		return nil, nil
+	}
+
+	var digest NotificationDigest
+	var entry NotificationDigestEntry
+
+	// After a Temporal round trip these are plain maps
+	if err := common.ConvertInterfaceToInterface(n[NotificationDigestKey], &digest); err != nil {
+		return nil, nil
+	}
+	if err := common.ConvertInterfaceToInterface(n[NotificationDigestEntryKey], &entry); err != nil {
+		return nil, nil
+	}
 
-func (p *BaseProvider) SendNotification(ctx context.Context, notification NotificationRequest) error {
-	// Default implementation does nothing
-	return fmt.Errorf("the provider '%s' does not implement SendNotification", p.GetProvider())
+	return &digest, &entry
 }
@@ -23,6 +23,14 @@ type AuthorizeRoleResponse struct {
 	Groups      []string       `json:"groups,omitempty"`      // The groups that were authorized
 	Resources   []string       `json:"resources,omitempty"`   // The resources that were authorized
 	Metadata    map[string]any `json:"metadata,omitempty"`    // Any metadata returned from the provider
+	GrantRef    *GrantRef      `json:"grant_ref,omitempty"`   // What the provider created, used to revoke exactly that grant
+	// Credentials minted for the requester, encrypted for their public key
+	Credentials *CredentialArtifact `json:"credentials,omitempty"`
+}
+
+// IsSimulated returns true when the provider only simulated the grant
+func (r *AuthorizeRoleResponse) IsSimulated() bool {
+	return r != nil && r.GrantRef.IsSimulated()
 }
 
 type RevokeRoleRequest struct {
@@ -120,7 +128,10 @@ func (r *SynchronizeIdentitiesRequest) SetPagination(p *PaginationOptions) { r.P
 func (r SynchronizeIdentitiesResponse) GetPagination() *PaginationOptions  { return r.Pagination }
 
 // ProviderRoleBasedAccessControl defines the interface for providers that support RBAC
-type ProviderRoleBasedAccessControl interface {
+// ProviderRoleCatalog is the roles, permissions and resources a provider
+// knows about. BaseProvider holds them for every provider, so this is part
+// of ProviderImpl rather than a capability.
+type ProviderRoleCatalog interface {
 
 	// Sync or Async load the roles, permissions, resources and identities
 	SynchronizeRoles(ctx context.Context, req *SynchronizeRolesRequest) (*SynchronizeRolesResponse, error)
@@ -147,8 +158,7 @@ type ProviderRoleBasedAccessControl interface {
 	ListPermissions(ctx context.Context, searchRequest *SearchRequest) ([]SearchResult[ProviderPermission], error)
 
 	// Resources are things that permissions can be applied to
-	GetResource(ctx context.Context, resource string) (*ProviderResource, error)
-	ListResources(ctx context.Context, searchRequest *SearchRequest) ([]SearchResult[ProviderResource], error)
+	ResourceDiscoverer
 
 	// Role is a collection of permissions or whatever the provider defines as a role
 	GetRole(ctx context.Context, role string) (*ProviderRole, error)
@@ -156,6 +166,12 @@ type ProviderRoleBasedAccessControl interface {
 
 	// Validate a role for a user
 	ValidateRole(ctx context.Context, identity *Identity, role *Role) (map[string]any, error)
+}
+
+// ProviderRoleBasedAccessControl is implemented by providers that grant
+// and revoke roles, advertised with ProviderCapabilityRBAC. Use
+// AsRoleBasedAccessControl to get it.
+type ProviderRoleBasedAccessControl interface {
 
 	// Authorize a role for a user (Bind a user to a role)
 	AuthorizeRole(
@@ -180,22 +196,8 @@ type ProviderRoleBasedAccessControl interface {
 	) string
 }
 
-func (p *BaseProvider) AuthorizeRole(
-	ctx context.Context,
-	req *AuthorizeRoleRequest,
-) (*AuthorizeRoleResponse, error) {
-	// Default implementation does nothing
-	return nil, fmt.Errorf("the provider '%s' does not implement AuthorizeRole", p.GetProvider())
-}
-
-func (p *BaseProvider) RevokeRole(
-	ctx context.Context,
-	req *RevokeRoleRequest,
-) (*RevokeRoleResponse, error) {
-	// Default implementation does nothing
-	return nil, fmt.Errorf("the provider '%s' does not implement RevokeRole", p.GetProvider())
-}
-
+// GetAuthorizedAccessUrl is the default for role based access control
+// providers without somewhere to send the user once authorized
 func (p *BaseProvider) GetAuthorizedAccessUrl(
 	ctx context.Context,
 	req *AuthorizeRoleRequest,
@@ -465,7 +467,7 @@ func getCondensedActions(permission string) []string {
 	return permissions
 }
 
-func (p *BaseProvider) buildPermissionIndices() error {
+func (p *BaseProvider) buildPermissionIndices(ctx context.Context, permissions []ProviderPermission) error {
 	// Placeholder for building indices
 	startTime := time.Now()
 	defer func() {
@@ -481,14 +483,14 @@ func (p *BaseProvider) buildPermissionIndices() error {
 	}
 
 	// Index permissions
-	for _, perm := range p.rbac.permissions {
-		if err := permissionsIndex.Index(perm.Name, perm); err != nil {
-			return fmt.Errorf("failed to index permission %s: %v", perm.Name, err)
-		}
+	if err := indexDocuments(ctx, permissionsIndex, permissions, func(perm *ProviderPermission) string {
+		return perm.Name
+	}); err != nil {
+		return err
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"permissions": len(p.rbac.permissions),
+		"permissions": len(permissions),
 		"roles":       len(p.rbac.roles),
 	}).Debug("RBAC search indices ready")
 
@@ -499,7 +501,7 @@ func (p *BaseProvider) buildPermissionIndices() error {
 	return nil
 }
 
-func (p *BaseProvider) buildRoleIndices() error {
+func (p *BaseProvider) buildRoleIndices(ctx context.Context, roles []ProviderRole) error {
 	// Placeholder for building indices
 	startTime := time.Now()
 	defer func() {
@@ -530,10 +532,10 @@ func (p *BaseProvider) buildRoleIndices() error {
 	}
 
 	// Index roles
-	for _, role := range p.rbac.roles {
-		if err := rolesIndex.Index(role.Name, role); err != nil {
-			return fmt.Errorf("failed to index role %s: %v", role.Name, err)
-		}
+	if err := indexDocuments(ctx, rolesIndex, roles, func(role *ProviderRole) string {
+		return role.Name
+	}); err != nil {
+		return err
 	}
 
 	p.rbac.mu.Lock()
@@ -542,7 +544,7 @@ func (p *BaseProvider) buildRoleIndices() error {
 
 	logrus.WithFields(logrus.Fields{
 		"permissions": len(p.rbac.permissions),
-		"roles":       len(p.rbac.roles),
+		"roles":       len(roles),
 	}).Debug("RBAC search indices ready")
 
 	return nil
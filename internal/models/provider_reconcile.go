@@ -0,0 +1,112 @@
+package models
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ProviderGrantReconciler is implemented by RBAC providers that can list
+// the grants thand created, from the markers they stamp on them e.g. IAM
+// statement IDs, condition titles or labels. The reconciliation sweep
+// revokes those no running elevation holds.
+type ProviderGrantReconciler interface {
+	ListManagedGrants(ctx context.Context) ([]ManagedGrant, error)
+}
+
+// ManagedGrant is a grant found in the provider carrying thand's marker
+type ManagedGrant struct {
+	// What was created, as recorded when it was authorized. The reference
+	// is empty when the provider can't recover it from the marker.
+	Grant *GrantRef `json:"grant"`
+	// Who the grant gives access to, as the provider knows them
+	User *User `json:"user,omitempty"`
+	// The provider's name for what was granted e.g. a role name
+	Role string `json:"role,omitempty"`
+	// Shared is set when grants from several elevations can reuse what was
+	// created, so it's held while any running grant records it
+	Shared bool `json:"shared,omitempty"`
+}
+
+// GetKey identifies the grant between sweeps
+func (m *ManagedGrant) GetKey() string {
+
+	if m == nil || m.Grant == nil {
+		return ""
+	}
+
+	parts := []string{m.Grant.Provider, m.Grant.Kind, m.Grant.Reference}
+	parts = append(parts, m.Grant.IDs...)
+
+	for _, key := range slices.Sorted(maps.Keys(m.Grant.Attributes)) {
+		parts = append(parts, key+"="+m.Grant.Attributes[key])
+	}
+
+	return strings.Join(parts, "\x00")
+}
+
+// GetIdentity returns who the grant gives access to
+func (m *ManagedGrant) GetIdentity() string {
+	if m == nil || m.User == nil {
+		return ""
+	}
+	return m.User.GetIdentity()
+}
+
+// IsHeldBy returns true if the grant recorded by a running elevation
+// covers this one. Grants match on their reference, or for shared grants
+// on what was created.
+func (m *ManagedGrant) IsHeldBy(grant *GrantRef) bool {
+
+	if m == nil || m.Grant == nil || grant == nil {
+		return false
+	}
+
+	if m.Grant.Provider != grant.Provider {
+		return false
+	}
+
+	if len(m.Grant.Reference) > 0 && m.Grant.Reference == grant.Reference {
+		return true
+	}
+
+	if !m.Shared || m.Grant.Kind != grant.Kind {
+		return false
+	}
+
+	for key, value := range m.Grant.Attributes {
+		if grant.GetAttribute(key) != value {
+			return false
+		}
+	}
+
+	for _, id := range m.Grant.IDs {
+		if slices.Contains(grant.IDs, id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewRevokeRoleRequest builds the request revoking the grant. Providers
+// revoke exactly what the grant ref records, the role is only named.
+func (m *ManagedGrant) NewRevokeRoleRequest() *RevokeRoleRequest {
+
+	user := m.User
+
+	if user == nil {
+		user = &User{}
+	}
+
+	return &RevokeRoleRequest{
+		RoleRequest: &RoleRequest{
+			User: user,
+			Role: &Role{Name: m.Role},
+		},
+		AuthorizeRoleResponse: &AuthorizeRoleResponse{
+			GrantRef: m.Grant,
+		},
+	}
+}
@@ -11,15 +11,35 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+type ProviderResourcesResponse struct {
+	Version   string                           `json:"version"`
+	Provider  string                           `json:"provider"`
+	Resources []SearchResult[ProviderResource] `json:"resources"`
+}
+
+// ResourceDiscoverer is implemented by providers that can list the live
+// resources a role can be scoped to, e.g. Cloudflare zones or GCP projects.
+// Providers advertise this with ProviderCapabilityResourceDiscovery.
+type ResourceDiscoverer interface {
+	ListResources(ctx context.Context, searchRequest *SearchRequest) ([]SearchResult[ProviderResource], error)
+	GetResource(ctx context.Context, resource string) (*ProviderResource, error)
+}
+
+// hasResources returns true if the provider holds a resource list
+func (p *BaseProvider) hasResources() bool {
+	return p.rbac != nil && p.HasAnyCapability(
+		ProviderCapabilityRBAC,
+		ProviderCapabilityResourceDiscovery,
+	)
+}
+
 func (p *BaseProvider) SynchronizeResources(ctx context.Context, req *SynchronizeResourcesRequest) (*SynchronizeResourcesResponse, error) {
 	return nil, ErrNotImplemented
 }
 
 func (p *BaseProvider) GetResource(ctx context.Context, resource string) (*ProviderResource, error) {
 
-	if p.rbac == nil || !p.HasCapability(
-		ProviderCapabilityRBAC,
-	) {
+	if !p.hasResources() {
 		logrus.Warningln("provider has no resources")
 		return nil, fmt.Errorf("provider has no resources")
 	}
@@ -38,9 +58,7 @@ func (p *BaseProvider) GetResource(ctx context.Context, resource string) (*Provi
 
 func (p *BaseProvider) ListResources(ctx context.Context, searchRequest *SearchRequest) ([]SearchResult[ProviderResource], error) {
 
-	if p.rbac == nil || !p.HasCapability(
-		ProviderCapabilityRBAC,
-	) {
+	if !p.hasResources() {
 		logrus.Warningln("provider has no resources")
 		return nil, fmt.Errorf("provider has no resources")
 	}
@@ -76,7 +94,7 @@ func (p *BaseProvider) ListResources(ctx context.Context, searchRequest *SearchR
 	return ReturnSearchResults(filtered), nil
 }
 
-func (p *BaseProvider) buildResourceIndices() error {
+func (p *BaseProvider) buildResourceIndices(ctx context.Context, resources []ProviderResource) error {
 	// Placeholder for building indices
 	startTime := time.Now()
 	defer func() {
@@ -91,10 +109,10 @@ func (p *BaseProvider) buildResourceIndices() error {
 	}
 
 	// Index resources
-	for _, resource := range p.rbac.resources {
-		if err := resourceIndex.Index(resource.ID, resource); err != nil {
-			return fmt.Errorf("failed to index resource %s: %v", resource.ID, err)
-		}
+	if err := indexDocuments(ctx, resourceIndex, resources, func(resource *ProviderResource) string {
+		return resource.ID
+	}); err != nil {
+		return err
 	}
 
 	p.rbac.mu.Lock()
@@ -102,7 +120,7 @@ func (p *BaseProvider) buildResourceIndices() error {
 	p.rbac.mu.Unlock()
 
 	logrus.WithFields(logrus.Fields{
-		"resources": len(p.rbac.resources),
+		"resources": len(resources),
 	}).Debug("Resource search indices ready")
 
 	return nil
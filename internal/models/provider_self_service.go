@@ -0,0 +1,30 @@
+package models
+
+import "context"
+
+// SelfServiceHandler is implemented by the server and handed to providers
+// that offer their own request surface e.g. the Slack app home and the
+// /thand command. It lets them look up and request elevations for a user
+// without going through the web UI.
+type SelfServiceHandler interface {
+	// GetUserByEmail resolves a thand identity from an email address
+	GetUserByEmail(ctx context.Context, email string) (*User, error)
+
+	// ListElevations returns the running elevations requested by the user
+	ListElevations(ctx context.Context, user *User) ([]*WorkflowExecutionInfo, error)
+
+	// ListRoles returns the roles the user is allowed to request
+	ListRoles(user *User) map[string]Role
+
+	// Elevate submits an elevation request on behalf of the user. The
+	// returned url is where the user continues the request, usually to
+	// authenticate before the workflow starts.
+	Elevate(ctx context.Context, user *User, request ElevateRequest) (*WorkflowRequest, error)
+}
+
+// ProviderSelfService is optionally implemented by providers that can serve
+// a self service surface. The server hands over its handler once it has
+// started, providers without one configured should return ErrNotImplemented.
+type ProviderSelfService interface {
+	SetSelfServiceHandler(handler SelfServiceHandler) error
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ProviderConfigValidator checks a provider's config before it is
+// initialized so every missing or invalid field is reported at once,
+// rather than one at a time as initialization reaches them.
+type ProviderConfigValidator interface {
+	ValidateConfig(config *BasicConfig) error
+}
+
+// ValidateConfig is the default for providers without required config
+func (p *BaseProvider) ValidateConfig(config *BasicConfig) error {
+	return nil
+}
+
+// ConfigFieldError is a single missing or invalid config field
+type ConfigFieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e ConfigFieldError) String() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+// ConfigValidationError lists every problem found in a provider's config
+type ConfigValidationError struct {
+	Provider string             `json:"provider"`
+	Fields   []ConfigFieldError `json:"fields"`
+}
+
+// NewConfigValidationError starts collecting the problems with the config
+// for the provider type e.g. aws
+func NewConfigValidationError(provider string) *ConfigValidationError {
+	return &ConfigValidationError{
+		Provider: provider,
+		Fields:   []ConfigFieldError{},
+	}
+}
+
+func (e *ConfigValidationError) Error() string {
+
+	problems := make([]string, 0, len(e.Fields))
+
+	for _, field := range e.Fields {
+		problems = append(problems, field.String())
+	}
+
+	return fmt.Sprintf("invalid %s provider config: %s", e.Provider, strings.Join(problems, "; "))
+}
+
+// Add records a problem with the field
+func (e *ConfigValidationError) Add(field string, format string, args ...any) {
+	e.Fields = append(e.Fields, ConfigFieldError{
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Require records each field that is missing or an empty string
+func (e *ConfigValidationError) Require(config *BasicConfig, fields ...string) {
+	for _, field := range fields {
+		if !hasConfigValue(config, field) {
+			e.Add(field, "is required")
+		}
+	}
+}
+
+// RequireTogether records the fields that are missing when only some of
+// them are set e.g. a client ID without its secret
+func (e *ConfigValidationError) RequireTogether(config *BasicConfig, fields ...string) {
+
+	missing := []string{}
+
+	for _, field := range fields {
+		if !hasConfigValue(config, field) {
+			missing = append(missing, field)
+		}
+	}
+
+	if len(missing) == 0 || len(missing) == len(fields) {
+		return
+	}
+
+	for _, field := range missing {
+		e.Add(field, "is required with %s", strings.Join(fields, ", "))
+	}
+}
+
+// OneOf records a problem when the field is set to a value not in the
+// allowed list
+func (e *ConfigValidationError) OneOf(config *BasicConfig, field string, allowed ...string) {
+
+	value, found := config.GetString(field)
+
+	if !found || len(value) == 0 {
+		return
+	}
+
+	if slices.Contains(allowed, value) {
+		return
+	}
+
+	e.Add(field, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
+
+// ErrorOrNil returns the error if any problems were recorded
+func (e *ConfigValidationError) ErrorOrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// hasConfigValue returns true if the field is set and, for strings, isn't
+// empty
+func hasConfigValue(config *BasicConfig, field string) bool {
+
+	if config == nil {
+		return false
+	}
+
+	value, found := (*config)[field]
+
+	if !found || value == nil {
+		return false
+	}
+
+	if str, ok := value.(string); ok {
+		return len(strings.TrimSpace(str)) > 0
+	}
+
+	return true
+}
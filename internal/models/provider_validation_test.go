@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidationError(t *testing.T) {
+
+	t.Run("no problems", func(t *testing.T) {
+		validation := NewConfigValidationError("example")
+		validation.Require(&BasicConfig{"token": "abc"}, "token")
+		assert.NoError(t, validation.ErrorOrNil())
+	})
+
+	t.Run("lists every problem", func(t *testing.T) {
+		config := &BasicConfig{
+			"client_id": "id",
+			"api_key":   "  ",
+			"region":    "mars",
+		}
+
+		validation := NewConfigValidationError("example")
+		validation.Require(config, "api_key", "endpoint")
+		validation.RequireTogether(config, "client_id", "client_secret")
+		validation.OneOf(config, "region", "us", "eu")
+
+		err := validation.ErrorOrNil()
+		require.Error(t, err)
+
+		var validationErr *ConfigValidationError
+		require.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, []ConfigFieldError{
+			{Field: "api_key", Message: "is required"},
+			{Field: "endpoint", Message: "is required"},
+			{Field: "client_secret", Message: "is required with client_id, client_secret"},
+			{Field: "region", Message: `must be one of us, eu, got "mars"`},
+		}, validationErr.Fields)
+
+		assert.Equal(t, `invalid example provider config: api_key is required; endpoint is required; `+
+			`client_secret is required with client_id, client_secret; region must be one of us, eu, got "mars"`, err.Error())
+	})
+
+	t.Run("fields required together may all be omitted", func(t *testing.T) {
+		validation := NewConfigValidationError("example")
+		validation.RequireTogether(&BasicConfig{}, "client_id", "client_secret")
+		validation.OneOf(&BasicConfig{}, "region", "us", "eu")
+		assert.NoError(t, validation.ErrorOrNil())
+	})
+
+	t.Run("nil config", func(t *testing.T) {
+		validation := NewConfigValidationError("example")
+		validation.Require(nil, "token")
+		assert.EqualError(t, validation.ErrorOrNil(), "invalid example provider config: token is required")
+	})
+}
+
+func TestBaseProviderValidateConfig(t *testing.T) {
+	var provider *BaseProvider
+	assert.NoError(t, provider.ValidateConfig(nil))
+}
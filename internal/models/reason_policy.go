@@ -0,0 +1,166 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ReasonPolicy describes what a reason for an elevation must look like e.g.
+//
+//	reason_policy:
+//	  min_length: 20
+//	  must_match: ["INC-\\d+", "[A-Z]+-\\d+"]
+//	  must_not_match: ["(?i)^test"]
+//
+// A reason must match every must_match pattern and none of the
+// must_not_match patterns.
+type ReasonPolicy struct {
+	// Required defaults to true. Set to false to allow empty reasons.
+	Required     *bool    `json:"required,omitempty" yaml:"required,omitempty" mapstructure:"required"`
+	MinLength    int      `json:"min_length,omitempty" yaml:"min_length,omitempty" mapstructure:"min_length"`
+	MustMatch    []string `json:"must_match,omitempty" yaml:"must_match,omitempty" mapstructure:"must_match"`
+	MustNotMatch []string `json:"must_not_match,omitempty" yaml:"must_not_match,omitempty" mapstructure:"must_not_match"`
+}
+
+// IsRequired returns true if a reason must be given
+func (p *ReasonPolicy) IsRequired() bool {
+	if p == nil || p.Required == nil {
+		return true
+	}
+	return *p.Required
+}
+
+// Merge combines two policies keeping the most restrictive rules of each
+func (p *ReasonPolicy) Merge(other *ReasonPolicy) *ReasonPolicy {
+
+	if p == nil && other == nil {
+		return nil
+	}
+
+	merged := &ReasonPolicy{}
+
+	for _, policy := range []*ReasonPolicy{p, other} {
+
+		if policy == nil {
+			continue
+		}
+
+		if policy.Required != nil {
+			required := *policy.Required || (merged.Required != nil && *merged.Required)
+			merged.Required = &required
+		}
+
+		merged.MinLength = max(merged.MinLength, policy.MinLength)
+
+		for _, pattern := range policy.MustMatch {
+			if !slices.Contains(merged.MustMatch, pattern) {
+				merged.MustMatch = append(merged.MustMatch, pattern)
+			}
+		}
+
+		for _, pattern := range policy.MustNotMatch {
+			if !slices.Contains(merged.MustNotMatch, pattern) {
+				merged.MustNotMatch = append(merged.MustNotMatch, pattern)
+			}
+		}
+	}
+
+	return merged
+}
+
+// Validate checks the patterns of the policy compile
+func (p *ReasonPolicy) Validate() error {
+
+	if p == nil {
+		return nil
+	}
+
+	if p.MinLength < 0 {
+		return fmt.Errorf("reason_policy min_length must not be negative")
+	}
+
+	for _, pattern := range slices.Concat(p.MustMatch, p.MustNotMatch) {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid reason_policy pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// GetHint describes the rules of the policy for prompts e.g.
+// "at least 20 characters, matching INC-\d+". It is empty when the policy
+// has no rules.
+func (p *ReasonPolicy) GetHint() string {
+
+	if p == nil {
+		return ""
+	}
+
+	hints := []string{}
+
+	if p.MinLength > 0 {
+		hints = append(hints, fmt.Sprintf("at least %d characters", p.MinLength))
+	}
+
+	if len(p.MustMatch) > 0 {
+		hints = append(hints, fmt.Sprintf("matching %s", strings.Join(p.MustMatch, " and ")))
+	}
+
+	if len(p.MustNotMatch) > 0 {
+		hints = append(hints, fmt.Sprintf("not matching %s", strings.Join(p.MustNotMatch, " or ")))
+	}
+
+	return strings.Join(hints, ", ")
+}
+
+// Check returns an error describing the first rule the reason breaks
+func (p *ReasonPolicy) Check(reason string) error {
+
+	reason = strings.TrimSpace(reason)
+
+	if len(reason) == 0 {
+		if p.IsRequired() {
+			return fmt.Errorf("a reason is required")
+		}
+		return nil
+	}
+
+	if p == nil {
+		return nil
+	}
+
+	if len(reason) < p.MinLength {
+		return fmt.Errorf("reason must be at least %d characters long", p.MinLength)
+	}
+
+	for _, pattern := range p.MustMatch {
+
+		expression, err := regexp.Compile(pattern)
+
+		if err != nil {
+			return fmt.Errorf("invalid reason_policy pattern %q: %w", pattern, err)
+		}
+
+		if !expression.MatchString(reason) {
+			return fmt.Errorf("reason must match %s", pattern)
+		}
+	}
+
+	for _, pattern := range p.MustNotMatch {
+
+		expression, err := regexp.Compile(pattern)
+
+		if err != nil {
+			return fmt.Errorf("invalid reason_policy pattern %q: %w", pattern, err)
+		}
+
+		if expression.MatchString(reason) {
+			return fmt.Errorf("reason must not match %s", pattern)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReasonPolicyGetHint(t *testing.T) {
+
+	var missing *ReasonPolicy
+	assert.Empty(t, missing.GetHint())
+	assert.Empty(t, (&ReasonPolicy{}).GetHint())
+
+	assert.Equal(t, `at least 20 characters, matching JIRA-\d+`,
+		(&ReasonPolicy{MinLength: 20, MustMatch: []string{`JIRA-\d+`}}).GetHint())
+
+	assert.Equal(t, `matching INC-\d+ and prod, not matching (?i)^test`,
+		(&ReasonPolicy{MustMatch: []string{`INC-\d+`, "prod"}, MustNotMatch: []string{`(?i)^test`}}).GetHint())
+}
+
+func TestReasonPolicyCheck(t *testing.T) {
+
+	optional := false
+
+	tests := []struct {
+		name    string
+		policy  *ReasonPolicy
+		reason  string
+		wantErr string
+	}{
+		{"no policy requires a reason", nil, "", "a reason is required"},
+		{"no policy accepts any reason", nil, "test", ""},
+		{"optional reason", &ReasonPolicy{Required: &optional, MinLength: 20}, "  ", ""},
+		{"too short", &ReasonPolicy{MinLength: 10}, "test", "at least 10 characters"},
+		{"long enough", &ReasonPolicy{MinLength: 4}, " test ", ""},
+		{"missing ticket", &ReasonPolicy{MustMatch: []string{`INC-\d+`}}, "fixing prod", `must match INC-\d+`},
+		{"has ticket", &ReasonPolicy{MustMatch: []string{`INC-\d+`}}, "fixing INC-123", ""},
+		{"all patterns must match", &ReasonPolicy{MustMatch: []string{`INC-\d+`, `prod`}}, "fixing INC-123", "must match prod"},
+		{"rejected pattern", &ReasonPolicy{MustNotMatch: []string{`(?i)^test`}}, "Testing things", "must not match"},
+		{"rejected url", &ReasonPolicy{MustNotMatch: []string{`https?://`}}, "see https://example.com", "must not match"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.reason)
+			if len(tt.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestReasonPolicyMerge(t *testing.T) {
+
+	optional := false
+	required := true
+
+	assert.Nil(t, (*ReasonPolicy)(nil).Merge(nil))
+
+	merged := (&ReasonPolicy{
+		Required:     &optional,
+		MinLength:    10,
+		MustMatch:    []string{`INC-\d+`},
+		MustNotMatch: []string{`^test`},
+	}).Merge(&ReasonPolicy{
+		Required:     &required,
+		MinLength:    20,
+		MustMatch:    []string{`INC-\d+`, `[A-Z]+-\d+`},
+		MustNotMatch: []string{`https?://`},
+	})
+
+	require.NotNil(t, merged)
+	assert.True(t, merged.IsRequired())
+	assert.Equal(t, 20, merged.MinLength)
+	assert.Equal(t, []string{`INC-\d+`, `[A-Z]+-\d+`}, merged.MustMatch)
+	assert.Equal(t, []string{`^test`, `https?://`}, merged.MustNotMatch)
+
+	// An optional reason stays optional when the other policy has no opinion
+	merged = (&ReasonPolicy{Required: &optional}).Merge(&ReasonPolicy{MinLength: 5})
+	assert.False(t, merged.IsRequired())
+}
+
+func TestReasonPolicyValidate(t *testing.T) {
+	assert.NoError(t, (*ReasonPolicy)(nil).Validate())
+	assert.NoError(t, (&ReasonPolicy{MustMatch: []string{`INC-\d+`}}).Validate())
+	assert.Error(t, (&ReasonPolicy{MustMatch: []string{`INC-(`}}).Validate())
+	assert.Error(t, (&ReasonPolicy{MustNotMatch: []string{`[`}}).Validate())
+	assert.Error(t, (&ReasonPolicy{MinLength: -1}).Validate())
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultReconcileInterval       = time.Hour
+	DefaultReconcileGracePeriod    = 15 * time.Minute
+	DefaultReconcileMaxRevocations = 25
+)
+
+// ReconcileAction decides what happens to orphaned grants
+type ReconcileAction string
+
+const (
+	// ReconcileActionRevoke revokes orphaned grants
+	ReconcileActionRevoke ReconcileAction = "revoke"
+	// ReconcileActionNotify raises a high priority notification for them
+	ReconcileActionNotify ReconcileAction = "notify"
+)
+
+// ReconcileConfig configures the sweep revoking grants created by thand
+// that no running elevation holds e.g. after a workflow was terminated or
+// its revoke activities exhausted their retries
+type ReconcileConfig struct {
+	Enabled  bool            `mapstructure:"enabled" json:"enabled"`
+	Interval string          `mapstructure:"interval" json:"interval,omitempty" default:"1h"`
+	Action   ReconcileAction `mapstructure:"action" json:"action,omitempty" default:"revoke"`
+	// Orphans are only acted on once they've been seen for this long, so
+	// grants still being recorded by their workflow are left alone
+	GracePeriod string `mapstructure:"grace_period" json:"grace_period,omitempty" default:"15m"`
+	// Most grants revoked by a sweep, the rest wait for the next one
+	MaxRevocations int `mapstructure:"max_revocations" json:"max_revocations,omitempty" default:"25"`
+	// Notifier provider and its targets e.g. a Slack channel or email
+	// addresses, for the notify action
+	Notifier string   `mapstructure:"notifier" json:"notifier,omitempty"`
+	To       []string `mapstructure:"to" json:"to,omitempty"`
+}
+
+// GetAction returns the action, defaulting to revoke
+func (r *ReconcileConfig) GetAction() ReconcileAction {
+	if len(r.Action) == 0 {
+		return ReconcileActionRevoke
+	}
+	return ReconcileAction(strings.ToLower(string(r.Action)))
+}
+
+// GetInterval returns how often the sweep runs
+func (r *ReconcileConfig) GetInterval() time.Duration {
+	if interval, err := time.ParseDuration(r.Interval); err == nil && interval > 0 {
+		return interval
+	}
+	return DefaultReconcileInterval
+}
+
+// GetGracePeriod returns how long an orphan must be seen before it's acted on
+func (r *ReconcileConfig) GetGracePeriod() time.Duration {
+	if gracePeriod, err := time.ParseDuration(r.GracePeriod); err == nil && gracePeriod >= 0 {
+		return gracePeriod
+	}
+	return DefaultReconcileGracePeriod
+}
+
+// GetMaxRevocations returns the most grants revoked by a sweep
+func (r *ReconcileConfig) GetMaxRevocations() int {
+	if r.MaxRevocations > 0 {
+		return r.MaxRevocations
+	}
+	return DefaultReconcileMaxRevocations
+}
+
+// Validate checks the action and durations
+func (r *ReconcileConfig) Validate() error {
+
+	switch r.GetAction() {
+	case ReconcileActionRevoke:
+	case ReconcileActionNotify:
+		if r.Enabled && len(r.Notifier) == 0 {
+			return fmt.Errorf("reconcile.notifier is required for the %s action", ReconcileActionNotify)
+		}
+	default:
+		return fmt.Errorf("invalid reconcile action %q, expected %s or %s",
+			r.Action, ReconcileActionRevoke, ReconcileActionNotify)
+	}
+
+	for key, value := range map[string]string{
+		"interval":     r.Interval,
+		"grace_period": r.GracePeriod,
+	} {
+		if len(value) == 0 {
+			continue
+		}
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("invalid reconcile %s %q: %w", key, value, err)
+		}
+	}
+
+	return nil
+}
+
+// ReconcileStatus is what the sweep did with an orphaned grant
+type ReconcileStatus string
+
+const (
+	ReconcileStatusPending  ReconcileStatus = "pending"  // Within the grace period
+	ReconcileStatusRevoked  ReconcileStatus = "revoked"  // Revoked by the sweep
+	ReconcileStatusDeferred ReconcileStatus = "deferred" // Over the sweep's revocation limit
+	ReconcileStatusNotified ReconcileStatus = "notified" // Reported to the notifier
+	ReconcileStatusReported ReconcileStatus = "reported" // Already reported, or nowhere to report it
+	ReconcileStatusFailed   ReconcileStatus = "failed"
+)
+
+// ReconcileFinding is an orphaned grant found by the sweep
+type ReconcileFinding struct {
+	Provider  string          `json:"provider"`
+	Reference string          `json:"reference,omitempty"`
+	Kind      string          `json:"kind,omitempty"`
+	IDs       []string        `json:"ids,omitempty"`
+	Identity  string          `json:"identity,omitempty"`
+	Role      string          `json:"role,omitempty"`
+	FirstSeen time.Time       `json:"first_seen"`
+	Status    ReconcileStatus `json:"status"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// ReconcileResult is the outcome of a sweep
+type ReconcileResult struct {
+	StartedAt   time.Time          `json:"started_at"`
+	CompletedAt time.Time          `json:"completed_at"`
+	Action      ReconcileAction    `json:"action"`
+	Held        int                `json:"held"`      // Grants recorded by running elevations
+	Providers   []string           `json:"providers"` // Providers whose grants were listed
+	Findings    []ReconcileFinding `json:"findings"`
+	Errors      []string           `json:"errors,omitempty"` // Providers that couldn't be listed
+}
+
+// GetCount returns the number of findings with the status
+func (r *ReconcileResult) GetCount(status ReconcileStatus) int {
+	count := 0
+	for _, finding := range r.Findings {
+		if finding.Status == status {
+			count++
+		}
+	}
+	return count
+}
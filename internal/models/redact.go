@@ -0,0 +1,191 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/thand-io/agent/internal/common"
+)
+
+// ProviderSecretKeys is implemented by providers with secret config keys
+// that the common names e.g. token, secret or password don't cover
+type ProviderSecretKeys interface {
+	GetSecretKeys() []string
+}
+
+// IsSecretConfigKey returns true if values under the key are secrets,
+// either by name or because the provider declares them
+func IsSecretConfigKey(key string, secretKeys []string) bool {
+
+	if common.IsSecretKey(key) {
+		return true
+	}
+
+	for _, secretKey := range secretKeys {
+		if strings.EqualFold(key, secretKey) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsRedactedValue returns true if the value is the redacted placeholder
+func IsRedactedValue(value any) bool {
+	strValue, ok := value.(string)
+	return ok && strValue == common.RedactedValue
+}
+
+// Redact returns a copy of the config with secret values replaced by a
+// placeholder, including those in nested maps. Other keys are kept so
+// clients still see how the provider is set up.
+func (pc *BasicConfig) Redact(secretKeys ...string) *BasicConfig {
+
+	if pc == nil {
+		return nil
+	}
+
+	redacted := BasicConfig(redactConfigMap(*pc, secretKeys))
+	return &redacted
+}
+
+func redactConfigMap(config map[string]any, secretKeys []string) map[string]any {
+
+	redacted := make(map[string]any, len(config))
+
+	for key, value := range config {
+		if IsSecretConfigKey(key, secretKeys) && !isEmptyConfigValue(value) {
+			redacted[key] = common.RedactedValue
+			continue
+		}
+		redacted[key] = redactConfigValue(value, secretKeys)
+	}
+
+	return redacted
+}
+
+func redactConfigValue(value any, secretKeys []string) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		return redactConfigMap(typed, secretKeys)
+	case BasicConfig:
+		return BasicConfig(redactConfigMap(typed, secretKeys))
+	case []any:
+		values := make([]any, len(typed))
+		for i, item := range typed {
+			values[i] = redactConfigValue(item, secretKeys)
+		}
+		return values
+	default:
+		return value
+	}
+}
+
+func isEmptyConfigValue(value any) bool {
+	if value == nil {
+		return true
+	}
+	strValue, ok := value.(string)
+	return ok && len(strValue) == 0
+}
+
+// RestoreRedacted returns a copy of the config where redacted placeholders
+// are replaced with the value from the previous config, or removed when
+// there isn't one. A placeholder sent back is never taken as the secret.
+func (pc *BasicConfig) RestoreRedacted(previous *BasicConfig) *BasicConfig {
+
+	if pc == nil {
+		return nil
+	}
+
+	var previousConfig map[string]any
+
+	if previous != nil {
+		previousConfig = *previous
+	}
+
+	restored := BasicConfig(restoreConfigMap(*pc, previousConfig))
+	return &restored
+}
+
+func restoreConfigMap(config map[string]any, previous map[string]any) map[string]any {
+
+	restored := make(map[string]any, len(config))
+
+	for key, value := range config {
+
+		previousValue, found := previous[key]
+
+		if IsRedactedValue(value) {
+			if found && !IsRedactedValue(previousValue) {
+				restored[key] = previousValue
+			}
+			continue
+		}
+
+		switch typed := value.(type) {
+		case map[string]any:
+			previousMap, _ := previousValue.(map[string]any)
+			restored[key] = restoreConfigMap(typed, previousMap)
+		default:
+			restored[key] = value
+		}
+	}
+
+	return restored
+}
+
+// Redact returns a copy of the provider with the secrets in its config
+// replaced by a placeholder
+func (p Provider) Redact(secretKeys ...string) Provider {
+	p.Config = p.Config.Redact(secretKeys...)
+	return p
+}
+
+// Redact returns a copy of the services config with credentials, keys and
+// secrets replaced by a placeholder
+func (e *ServicesConfig) Redact() *ServicesConfig {
+
+	if e == nil {
+		return nil
+	}
+
+	redacted := *e
+
+	redacted.Encryption = e.Encryption.redact()
+	redacted.Vault = e.Vault.redact()
+	redacted.Scheduler = e.Scheduler.redact()
+	redacted.Storage = e.Storage.redact()
+
+	if e.LargeLanguageModel != nil {
+		llm := *e.LargeLanguageModel
+		llm.APIKey = redactString(llm.APIKey)
+		redacted.LargeLanguageModel = &llm
+	}
+
+	if e.Temporal != nil {
+		temporal := *e.Temporal
+		temporal.ApiKey = redactString(temporal.ApiKey)
+		redacted.Temporal = &temporal
+	}
+
+	return &redacted
+}
+
+func (e *ServiceConfig) redact() *ServiceConfig {
+
+	if e == nil {
+		return nil
+	}
+
+	redacted := *e
+	redacted.Config = e.Config.Redact()
+
+	return &redacted
+}
+
+func redactString(value string) string {
+	if len(value) == 0 {
+		return value
+	}
+	return common.RedactedValue
+}
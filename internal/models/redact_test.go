@@ -0,0 +1,129 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/common"
+)
+
+func TestBasicConfig_Redact(t *testing.T) {
+
+	config := &BasicConfig{
+		"host":          "smtp.example.com",
+		"pass":          "hunter22",
+		"client_secret": "s3cr3t-value",
+		"bot_token":     "",
+		"port":          587,
+		"nested": map[string]any{
+			"api_key": "nested-key",
+			"region":  "us-east-1",
+		},
+		"accounts": []any{
+			map[string]any{"password": "list-password", "name": "admin"},
+		},
+	}
+
+	t.Run("redacts secrets by name and declared keys", func(t *testing.T) {
+		redacted := config.Redact("pass")
+
+		assert.Equal(t, "smtp.example.com", (*redacted)["host"])
+		assert.Equal(t, 587, (*redacted)["port"])
+		assert.Equal(t, common.RedactedValue, (*redacted)["pass"])
+		assert.Equal(t, common.RedactedValue, (*redacted)["client_secret"])
+		assert.Equal(t, "", (*redacted)["bot_token"], "empty values show the secret isn't set")
+
+		nested := (*redacted)["nested"].(map[string]any)
+		assert.Equal(t, common.RedactedValue, nested["api_key"])
+		assert.Equal(t, "us-east-1", nested["region"])
+
+		account := (*redacted)["accounts"].([]any)[0].(map[string]any)
+		assert.Equal(t, common.RedactedValue, account["password"])
+		assert.Equal(t, "admin", account["name"])
+	})
+
+	t.Run("undeclared keys are kept", func(t *testing.T) {
+		redacted := config.Redact()
+		assert.Equal(t, "hunter22", (*redacted)["pass"])
+	})
+
+	t.Run("original is unchanged", func(t *testing.T) {
+		config.Redact("pass")
+		assert.Equal(t, "hunter22", (*config)["pass"])
+		assert.Equal(t, "nested-key", (*config)["nested"].(map[string]any)["api_key"])
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var nilConfig *BasicConfig
+		assert.Nil(t, nilConfig.Redact())
+	})
+}
+
+func TestBasicConfig_RestoreRedacted(t *testing.T) {
+
+	previous := &BasicConfig{
+		"client_secret": "s3cr3t-value",
+		"nested": map[string]any{
+			"api_key": "nested-key",
+		},
+	}
+
+	t.Run("round trip keeps the secrets", func(t *testing.T) {
+		restored := previous.Redact().RestoreRedacted(previous)
+		assert.Equal(t, previous, restored)
+	})
+
+	t.Run("placeholders are never taken as values", func(t *testing.T) {
+		incoming := &BasicConfig{
+			"client_secret": common.RedactedValue,
+			"bot_token":     common.RedactedValue,
+			"region":        "eu-west-1",
+		}
+
+		restored := incoming.RestoreRedacted(previous)
+
+		assert.Equal(t, "s3cr3t-value", (*restored)["client_secret"])
+		assert.NotContains(t, *restored, "bot_token")
+		assert.Equal(t, "eu-west-1", (*restored)["region"])
+	})
+
+	t.Run("new secrets are accepted", func(t *testing.T) {
+		incoming := &BasicConfig{"client_secret": "rotated-value"}
+		restored := incoming.RestoreRedacted(previous)
+		assert.Equal(t, "rotated-value", (*restored)["client_secret"])
+	})
+
+	t.Run("without a previous config", func(t *testing.T) {
+		incoming := &BasicConfig{"client_secret": common.RedactedValue}
+		restored := incoming.RestoreRedacted(nil)
+		assert.Empty(t, *restored)
+	})
+}
+
+func TestServicesConfig_Redact(t *testing.T) {
+
+	services := &ServicesConfig{
+		LargeLanguageModel: &LargeLanguageModelConfig{APIKey: "llm-api-key"},
+		Temporal:           &TemporalConfig{Host: "localhost", ApiKey: "temporal-api-key"},
+		Encryption: &ServiceConfig{
+			Provider: "aws",
+			Config:   &BasicConfig{"secret_access_key": "aws-secret", "region": "us-east-1"},
+		},
+	}
+
+	redacted := services.Redact()
+
+	require.NotNil(t, redacted)
+	assert.Equal(t, common.RedactedValue, redacted.LargeLanguageModel.APIKey)
+	assert.Equal(t, common.RedactedValue, redacted.Temporal.ApiKey)
+	assert.Equal(t, "localhost", redacted.Temporal.Host)
+	assert.Equal(t, common.RedactedValue, (*redacted.Encryption.Config)["secret_access_key"])
+	assert.Equal(t, "us-east-1", (*redacted.Encryption.Config)["region"])
+	assert.Nil(t, redacted.Vault)
+
+	// The running config keeps its secrets
+	assert.Equal(t, "llm-api-key", services.LargeLanguageModel.APIKey)
+	assert.Equal(t, "temporal-api-key", services.Temporal.ApiKey)
+	assert.Equal(t, "aws-secret", (*services.Encryption.Config)["secret_access_key"])
+}
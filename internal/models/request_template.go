@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Request template sources
+const (
+	// RequestTemplateSourceLocal templates are saved in the user's config
+	// directory. Team templates shared from the login server can be added
+	// as another source.
+	RequestTemplateSourceLocal = "local"
+)
+
+var requestTemplateNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// RequestTemplate is a saved elevation request so the same request can be
+// made again without picking everything each time
+// e.g. aws-prod / incident-responder / 2h
+type RequestTemplate struct {
+	Name         string    `json:"name" yaml:"name"`
+	Role         string    `json:"role" yaml:"role"`
+	Providers    []string  `json:"providers" yaml:"providers"`
+	Duration     string    `json:"duration,omitempty" yaml:"duration,omitempty"`
+	Resources    []string  `json:"resources,omitempty" yaml:"resources,omitempty"` // Narrows the role's resources, empty for all
+	Workflow     string    `json:"workflow,omitempty" yaml:"workflow,omitempty"`
+	ReasonPrefix string    `json:"reason_prefix,omitempty" yaml:"reason_prefix,omitempty"`
+	Source       string    `json:"source,omitempty" yaml:"-"`
+	CreatedAt    time.Time `json:"created_at" yaml:"created_at"`
+}
+
+// ValidateRequestTemplateName returns an error if the name can't be used
+// for a template
+func ValidateRequestTemplateName(name string) error {
+	if !requestTemplateNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid template name %q, use letters, numbers, dots, dashes and underscores", name)
+	}
+	return nil
+}
+
+// GetReason returns the reason for a request made from the template, the
+// reason prefix followed by any details e.g. the incident number
+func (t *RequestTemplate) GetReason(details string) string {
+	return strings.TrimSpace(strings.Join([]string{
+		strings.TrimSpace(t.ReasonPrefix),
+		strings.TrimSpace(details),
+	}, " "))
+}
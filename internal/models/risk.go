@@ -0,0 +1,162 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// VarsContextRiskFlags is the context key of the risk flags of the request,
+// it's also the search attribute and memo key they're recorded under
+const VarsContextRiskFlags = "risk_flags"
+
+const (
+	RiskFlagFirstTimeRole = "first_time_role"
+	RiskFlagUnusualHour   = "unusual_hour"
+	RiskFlagGeoMismatch   = "geo_mismatch"
+)
+
+const (
+	// RiskMinimumHistory is the number of past requests needed before a
+	// request hour can be called unusual
+	RiskMinimumHistory = 5
+	// RiskUsualHourWindow is how many hours either side of a past request
+	// are considered usual
+	RiskUsualHourWindow = 2
+)
+
+// RiskFlags mark requests that look unusual so approvers take a closer
+// look. A nil flag is unknown e.g. the request history couldn't be queried.
+type RiskFlags struct {
+	FirstTimeRole *bool `json:"first_time_role"` // The user hasn't been granted the role before
+	UnusualHour   *bool `json:"unusual_hour"`    // Requested outside the user's typical hours
+	GeoMismatch   *bool `json:"geo_mismatch"`    // Requested from a different country to the last request
+
+	Country         string `json:"country,omitempty"`          // Country the request was made from
+	PreviousCountry string `json:"previous_country,omitempty"` // Country the last request was made from
+}
+
+// RequestHistoryEntry is a past elevation request of the user
+type RequestHistoryEntry struct {
+	Role      string
+	Approved  bool
+	StartTime time.Time
+	Country   string
+}
+
+// EvaluateRiskFlags flags a request for the role from the country at the
+// given time. The history is the user's past requests, most recent first.
+// A nil history couldn't be queried so the flags based on it are unknown.
+func EvaluateRiskFlags(history []RequestHistoryEntry, role string, country string, now time.Time) *RiskFlags {
+
+	flags := &RiskFlags{
+		Country: strings.ToUpper(country),
+	}
+
+	if history == nil {
+		return flags
+	}
+
+	// Only approved requests show the role was granted before
+	firstTime := true
+
+	for _, entry := range history {
+		if entry.Approved && strings.EqualFold(entry.Role, role) {
+			firstTime = false
+			break
+		}
+	}
+
+	flags.FirstTimeRole = &firstTime
+
+	if len(history) >= RiskMinimumHistory {
+		unusual := isUnusualHour(history, now)
+		flags.UnusualHour = &unusual
+	}
+
+	for _, entry := range history {
+		if len(entry.Country) > 0 {
+			flags.PreviousCountry = strings.ToUpper(entry.Country)
+			break
+		}
+	}
+
+	if len(flags.Country) > 0 && len(flags.PreviousCountry) > 0 {
+		mismatch := flags.Country != flags.PreviousCountry
+		flags.GeoMismatch = &mismatch
+	}
+
+	return flags
+}
+
+// isUnusualHour returns true if no past request was made within the usual
+// hour window of now. Hours wrap around midnight.
+func isUnusualHour(history []RequestHistoryEntry, now time.Time) bool {
+
+	hour := now.UTC().Hour()
+
+	for _, entry := range history {
+
+		distance := hour - entry.StartTime.UTC().Hour()
+
+		if distance < 0 {
+			distance = -distance
+		}
+
+		if min(distance, 24-distance) <= RiskUsualHourWindow {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetRaised returns the names of the flags that are set e.g. unusual_hour
+func (r *RiskFlags) GetRaised() []string {
+
+	raised := []string{}
+
+	if r == nil {
+		return raised
+	}
+
+	for _, flag := range []struct {
+		name  string
+		value *bool
+	}{
+		{RiskFlagFirstTimeRole, r.FirstTimeRole},
+		{RiskFlagUnusualHour, r.UnusualHour},
+		{RiskFlagGeoMismatch, r.GeoMismatch},
+	} {
+		if flag.value != nil && *flag.value {
+			raised = append(raised, flag.name)
+		}
+	}
+
+	return raised
+}
+
+// IsRaised returns true if any flag is set
+func (r *RiskFlags) IsRaised() bool {
+	return len(r.GetRaised()) > 0
+}
+
+// GetDescriptions describes each raised flag for approvers
+func (r *RiskFlags) GetDescriptions() []string {
+
+	descriptions := []string{}
+
+	for _, flag := range r.GetRaised() {
+		switch flag {
+		case RiskFlagFirstTimeRole:
+			descriptions = append(descriptions, "First time requesting this role")
+		case RiskFlagUnusualHour:
+			descriptions = append(descriptions, "Requested outside the user's typical hours")
+		case RiskFlagGeoMismatch:
+			descriptions = append(descriptions, fmt.Sprintf(
+				"Requested from %s, the last request was from %s", r.Country, r.PreviousCountry))
+		}
+	}
+
+	return descriptions
+}
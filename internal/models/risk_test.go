@@ -0,0 +1,94 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateRiskFlags(t *testing.T) {
+	now := time.Date(2025, 3, 7, 3, 0, 0, 0, time.UTC)
+
+	// Five past requests made during the working day from the US
+	history := []RequestHistoryEntry{}
+	for day := 1; day <= 5; day++ {
+		history = append(history, RequestHistoryEntry{
+			Role:      "reader",
+			Approved:  true,
+			StartTime: time.Date(2025, 3, 7-day, 14, 0, 0, 0, time.UTC),
+			Country:   "US",
+		})
+	}
+	history = append([]RequestHistoryEntry{{
+		Role:      "admin",
+		Approved:  false,
+		StartTime: time.Date(2025, 3, 6, 15, 0, 0, 0, time.UTC),
+	}}, history...)
+
+	t.Run("unknown without history", func(t *testing.T) {
+		flags := EvaluateRiskFlags(nil, "admin", "us", now)
+		assert.Nil(t, flags.FirstTimeRole)
+		assert.Nil(t, flags.UnusualHour)
+		assert.Nil(t, flags.GeoMismatch)
+		assert.Equal(t, "US", flags.Country)
+		assert.False(t, flags.IsRaised())
+	})
+
+	t.Run("first time role", func(t *testing.T) {
+		flags := EvaluateRiskFlags(history, "admin", "", now)
+		require.NotNil(t, flags.FirstTimeRole)
+		assert.True(t, *flags.FirstTimeRole, "a denied request doesn't count")
+
+		flags = EvaluateRiskFlags(history, "Reader", "", now)
+		assert.False(t, *flags.FirstTimeRole)
+	})
+
+	t.Run("unusual hour", func(t *testing.T) {
+		flags := EvaluateRiskFlags(history, "reader", "", now)
+		require.NotNil(t, flags.UnusualHour)
+		assert.True(t, *flags.UnusualHour)
+
+		flags = EvaluateRiskFlags(history, "reader", "", now.Add(10*time.Hour))
+		assert.False(t, *flags.UnusualHour)
+
+		flags = EvaluateRiskFlags(history[:3], "reader", "", now)
+		assert.Nil(t, flags.UnusualHour, "too little history")
+	})
+
+	t.Run("unusual hour wraps midnight", func(t *testing.T) {
+		late := []RequestHistoryEntry{}
+		for range RiskMinimumHistory {
+			late = append(late, RequestHistoryEntry{
+				StartTime: time.Date(2025, 3, 1, 23, 0, 0, 0, time.UTC),
+			})
+		}
+
+		flags := EvaluateRiskFlags(late, "reader", "", now.Add(-2*time.Hour))
+		assert.False(t, *flags.UnusualHour)
+	})
+
+	t.Run("geo mismatch", func(t *testing.T) {
+		flags := EvaluateRiskFlags(history, "reader", "DE", now)
+		require.NotNil(t, flags.GeoMismatch)
+		assert.True(t, *flags.GeoMismatch)
+		assert.Equal(t, "US", flags.PreviousCountry)
+
+		flags = EvaluateRiskFlags(history, "reader", "us", now)
+		assert.False(t, *flags.GeoMismatch)
+
+		flags = EvaluateRiskFlags(history, "reader", "", now)
+		assert.Nil(t, flags.GeoMismatch, "unknown without a country")
+	})
+
+	t.Run("descriptions", func(t *testing.T) {
+		flags := EvaluateRiskFlags(history, "admin", "DE", now)
+		assert.Equal(t, []string{RiskFlagFirstTimeRole, RiskFlagUnusualHour, RiskFlagGeoMismatch}, flags.GetRaised())
+		assert.Equal(t, []string{
+			"First time requesting this role",
+			"Requested outside the user's typical hours",
+			"Requested from DE, the last request was from US",
+		}, flags.GetDescriptions())
+	})
+}
@@ -2,7 +2,9 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-version"
 	"github.com/sirupsen/logrus"
@@ -22,6 +24,68 @@ type Role struct {
 	Scopes         *RoleScopes      `json:"scopes,omitempty"`       // scope of who can be assigned this role
 	Providers      []string         `json:"providers"`              // providers that can assign this role
 	Enabled        bool             `json:"enabled" default:"true"` // By default enable the role
+
+	// ReasonPolicy overrides the default rules for the reason given when
+	// requesting the role
+	ReasonPolicy *ReasonPolicy `json:"reason_policy,omitempty"`
+	// JustificationPattern is a regex the reason must match e.g. JIRA-\d+.
+	// It is added to the reason policy's must_match.
+	JustificationPattern string `json:"justification_pattern,omitempty"`
+
+	// MaxDuration limits how long the role can be requested for e.g. 4h
+	MaxDuration string `json:"max_duration,omitempty"`
+	// RequiresApproval sends every request for the role through approval,
+	// even when the policies allow it
+	RequiresApproval bool `json:"requires_approval,omitempty"`
+	// DuringFreeze denies requests, or requires extra approval from the
+	// FreezeApprovers, while a change freeze is active
+	DuringFreeze    FreezeAction `json:"during_freeze,omitempty"`
+	FreezeApprovers []string     `json:"freeze_approvers,omitempty"`
+	// DefaultEffect is the effect of actions not listed in the permissions.
+	// With deny only actions in permissions.allow can be requested.
+	DefaultEffect string `json:"default_effect,omitempty"`
+	// WorkflowTemplate expands a built in template into the role's first
+	// workflow, see WorkflowTemplates
+	WorkflowTemplate *WorkflowTemplate `json:"workflow_template,omitempty"`
+	// Tags are free form metadata e.g. team, environment or risk level
+	// used to filter and display roles
+	Tags map[string]string `json:"tags,omitempty"`
+	// Vars are merged into the workflow context, see VarsContextVars.
+	// They override provider and global vars of the same name.
+	Vars map[string]any `json:"vars,omitempty"`
+}
+
+const (
+	RoleEffectAllow = "allow"
+	RoleEffectDeny  = "deny"
+)
+
+// IsDenyByDefault returns true if actions not explicitly allowed are denied
+func (r *Role) IsDenyByDefault() bool {
+	return strings.EqualFold(r.DefaultEffect, RoleEffectDeny)
+}
+
+// GetReasonPolicy returns the role's reason policy with the justification
+// pattern, if any, added to its must_match patterns
+func (r *Role) GetReasonPolicy() *ReasonPolicy {
+	if len(r.JustificationPattern) == 0 {
+		return r.ReasonPolicy
+	}
+	return r.ReasonPolicy.Merge(&ReasonPolicy{
+		MustMatch: []string{r.JustificationPattern},
+	})
+}
+
+// ValidateDefaultEffect checks the default effect is allow or deny. Any
+// other value is an error rather than allow, so a typo of deny doesn't
+// allow every action.
+func (r *Role) ValidateDefaultEffect() error {
+	switch strings.ToLower(r.DefaultEffect) {
+	case "", RoleEffectAllow, RoleEffectDeny:
+		return nil
+	}
+	return fmt.Errorf("invalid default_effect %q, expected %s or %s",
+		r.DefaultEffect, RoleEffectAllow, RoleEffectDeny)
 }
 
 func (r *Role) HasPermission(user *User) bool {
@@ -36,6 +100,12 @@ func (r *Role) HasPermission(user *User) bool {
 		return true
 	}
 
+	identity := &Identity{ID: user.GetIdentity(), Label: user.GetName(), User: user}
+
+	if !r.Scopes.MatchesCondition(identity, time.Now()) {
+		return false
+	}
+
 	// Check user scopes (case-insensitive)
 	if len(r.Scopes.Users) > 0 {
 		for _, allowedUser := range r.Scopes.Users {
@@ -77,6 +147,36 @@ func (r *Role) HasPermission(user *User) bool {
 	return true
 }
 
+// HasTags returns true if the role has every tag with the same value
+func (r *Role) HasTags(tags map[string]string) bool {
+	for key, value := range tags {
+		if tagValue, found := r.Tags[key]; !found || tagValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseRoleTags parses tag filters e.g. environment=production into a map,
+// each filter is split on the first separator
+func ParseRoleTags(filters []string, separator string) (map[string]string, error) {
+
+	tags := make(map[string]string, len(filters))
+
+	for _, filter := range filters {
+
+		key, value, found := strings.Cut(filter, separator)
+
+		if !found || len(key) == 0 {
+			return nil, fmt.Errorf("invalid tag '%s', expected key%svalue", filter, separator)
+		}
+
+		tags[key] = value
+	}
+
+	return tags, nil
+}
+
 func (r *Role) AsMap() map[string]any {
 
 	role, err := common.ConvertInterfaceToMap(r)
@@ -116,6 +216,59 @@ type Permissions struct {
 	Deny  []string `json:"deny,omitempty"`
 }
 
+// IsActionAllowed returns true if the action can be granted. The deny list
+// takes precedence, then the allow list. Unlisted actions follow the
+// default effect of the role.
+func (r *Role) IsActionAllowed(action string) bool {
+
+	if permissionsMatch(r.Permissions.Deny, action) {
+		return false
+	}
+
+	if permissionsMatch(r.Permissions.Allow, action) {
+		return true
+	}
+
+	return !r.IsDenyByDefault()
+}
+
+// permissionsMatch returns true if any permission matches the action. The
+// permissions can use wildcards e.g. s3:* and condensed actions e.g.
+// k8s:pods:get,list
+func permissionsMatch(permissions []string, action string) bool {
+
+	for _, permission := range permissions {
+
+		expanded := []string{permission}
+		if strings.Contains(permission, ",") {
+			expanded = getCondensedActions(permission)
+		}
+
+		for _, pattern := range expanded {
+			if permissionMatches(pattern, action) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func permissionMatches(pattern string, action string) bool {
+
+	if pattern == "*" || strings.EqualFold(pattern, action) {
+		return true
+	}
+
+	if strings.HasSuffix(pattern, ":*") || strings.HasSuffix(pattern, ".*") {
+		prefix := strings.TrimSuffix(pattern, "*")
+		return len(action) > len(prefix) &&
+			strings.EqualFold(action[:len(prefix)], prefix)
+	}
+
+	return false
+}
+
 // RoleScopes defines the scope of a role in terms of users, groups, and domains (identities).
 // Only the specified users, groups, or users belonging to the specified domains can be assigned this role.
 // The Domains field allows restricting role assignment to users from particular domains (e.g., email domains or organizational domains),
@@ -124,12 +277,21 @@ type RoleScopes struct {
 	Groups  []string `json:"groups,omitempty"`
 	Users   []string `json:"users,omitempty"`
 	Domains []string `json:"domains,omitempty"`
+	// Condition is a CEL expression that must also be true e.g.
+	// "oncall" in groups && now.getHours() >= 9
+	Condition string `json:"condition,omitempty"`
+}
+
+// IsEmpty returns true if no users, groups or domains are scoped
+func (s *RoleScopes) IsEmpty() bool {
+	return len(s.Users) == 0 && len(s.Groups) == 0 && len(s.Domains) == 0
 }
 
 // RolesResponse represents the response for /roles endpoint
 type RolesResponse struct {
-	Version string                  `json:"version"`
-	Roles   map[string]RoleResponse `json:"roles"`
+	Version      string                  `json:"version"`
+	Roles        map[string]RoleResponse `json:"roles"`
+	ReasonPolicy *ReasonPolicy           `json:"reason_policy,omitempty"` // default reason policy
 }
 
 type RoleResponse struct {
@@ -142,16 +304,22 @@ type Resources struct {
 }
 
 // RoleDefinitions represents the structure for roles YAML/JSON
+// PermissionAlias maps a provider type e.g. aws, gcp or azure to the
+// permissions an abstract permission stands for in that provider
+type PermissionAlias map[string][]string
+
 type RoleDefinitions struct {
-	Version *version.Version `yaml:"version" json:"version"`
-	Roles   map[string]Role  `yaml:"roles" json:"roles"`
+	Version      *version.Version `yaml:"version" json:"version"`
+	Roles        map[string]Role  `yaml:"roles" json:"roles"`
+	ReasonPolicy *ReasonPolicy    `yaml:"reason_policy,omitempty" json:"reason_policy,omitempty"` // default reason policy
 }
 
 // UnmarshalJSON converts Version to string from any type
 func (h *RoleDefinitions) UnmarshalJSON(data []byte) error {
 	aux := &struct {
-		Version any             `json:"version"`
-		Roles   map[string]Role `json:"roles"`
+		Version      any             `json:"version"`
+		Roles        map[string]Role `json:"roles"`
+		ReasonPolicy *ReasonPolicy   `json:"reason_policy"`
 	}{
 		Roles: make(map[string]Role),
 	}
@@ -174,8 +342,9 @@ func (h *RoleDefinitions) UnmarshalJSON(data []byte) error {
 // UnmarshalYAML converts Version to string from any type
 func (h *RoleDefinitions) UnmarshalYAML(unmarshal func(any) error) error {
 	aux := &struct {
-		Version any             `yaml:"version"`
-		Roles   map[string]Role `yaml:"roles"`
+		Version      any             `yaml:"version"`
+		Roles        map[string]Role `yaml:"roles"`
+		ReasonPolicy *ReasonPolicy   `yaml:"reason_policy"`
 	}{
 		Roles: make(map[string]Role),
 	}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/sirupsen/logrus"
+)
+
+// roleConditionEnv declares the variables a role scope condition can use
+var roleConditionEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("identity", cel.StringType),
+		cel.Variable("user", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("group", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("groups", cel.ListType(cel.StringType)),
+		cel.Variable("now", cel.TimestampType),
+	)
+})
+
+// Compiled conditions by expression so they're only checked once
+var (
+	roleConditionProgramsMu sync.RWMutex
+	roleConditionPrograms   = map[string]cel.Program{}
+)
+
+// compileRoleCondition type checks the CEL expression and returns the
+// program to evaluate it. Expressions must return a boolean.
+func compileRoleCondition(expression string) (cel.Program, error) {
+
+	roleConditionProgramsMu.RLock()
+	program, found := roleConditionPrograms[expression]
+	roleConditionProgramsMu.RUnlock()
+
+	if found {
+		return program, nil
+	}
+
+	env, err := roleConditionEnv()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the CEL environment: %w", err)
+	}
+
+	ast, issues := env.Compile(expression)
+
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	if !ast.OutputType().IsExactType(cel.BoolType) {
+		return nil, fmt.Errorf("expression must return a bool, not %s", ast.OutputType())
+	}
+
+	program, err = env.Program(ast)
+
+	if err != nil {
+		return nil, err
+	}
+
+	roleConditionProgramsMu.Lock()
+	roleConditionPrograms[expression] = program
+	roleConditionProgramsMu.Unlock()
+
+	return program, nil
+}
+
+// newRoleConditionVars returns the variables of a condition for an
+// identity evaluated at the given time. Every key of user and group is
+// always set, to an empty string when the identity isn't a user or group,
+// so conditions don't fail on missing keys.
+func newRoleConditionVars(identity *Identity, now time.Time) map[string]any {
+
+	user := map[string]string{
+		"email":    "",
+		"name":     "",
+		"username": "",
+		"source":   "",
+		"domain":   "",
+	}
+
+	group := map[string]string{
+		"id":   "",
+		"name": "",
+	}
+
+	groups := []string{}
+
+	vars := map[string]any{
+		"identity": "",
+		"user":     user,
+		"group":    group,
+		"groups":   groups,
+		"now":      now.UTC(),
+	}
+
+	if identity == nil {
+		return vars
+	}
+
+	vars["identity"] = identity.GetId()
+
+	if identityUser := identity.GetUser(); identityUser != nil {
+		user["email"] = identityUser.Email
+		user["name"] = identityUser.GetName()
+		user["username"] = identityUser.GetUsername()
+		user["source"] = identityUser.Source
+		user["domain"] = identityUser.GetDomain()
+		groups = append(groups, identityUser.GetGroups()...)
+	}
+
+	if identityGroup := identity.GetGroup(); identityGroup != nil {
+		group["id"] = identityGroup.ID
+		group["name"] = identityGroup.Name
+		groups = append(groups, identityGroup.Name)
+	}
+
+	vars["groups"] = groups
+
+	return vars
+}
+
+// HasCondition returns true if the scopes have a condition
+func (s *RoleScopes) HasCondition() bool {
+	return s != nil && len(strings.TrimSpace(s.Condition)) > 0
+}
+
+// ValidateCondition checks the condition is a CEL expression that compiles
+// and returns a boolean
+func (s *RoleScopes) ValidateCondition() error {
+
+	if !s.HasCondition() {
+		return nil
+	}
+
+	if _, err := compileRoleCondition(s.Condition); err != nil {
+		return fmt.Errorf("invalid scopes condition: %w", err)
+	}
+
+	return nil
+}
+
+// MatchesCondition returns true if there is no condition or the condition
+// is true for the identity at the given time. Conditions that fail to
+// compile or evaluate don't match.
+func (s *RoleScopes) MatchesCondition(identity *Identity, now time.Time) bool {
+
+	if !s.HasCondition() {
+		return true
+	}
+
+	program, err := compileRoleCondition(s.Condition)
+
+	if err != nil {
+		logrus.WithError(err).WithField("condition", s.Condition).
+			Debugln("Scopes condition failed to compile, treating as no match")
+		return false
+	}
+
+	result, _, err := program.Eval(newRoleConditionVars(identity, now))
+
+	if err != nil {
+		logrus.WithError(err).WithField("condition", s.Condition).
+			Debugln("Scopes condition failed to evaluate, treating as no match")
+		return false
+	}
+
+	matched, ok := result.Value().(bool)
+
+	if !ok {
+		logrus.WithFields(logrus.Fields{
+			"condition": s.Condition,
+			"result":    result.Value(),
+		}).Debugln("Scopes condition is not a boolean, treating as no match")
+		return false
+	}
+
+	return matched
+}
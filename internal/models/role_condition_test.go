@@ -0,0 +1,71 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleScopesMatchesCondition(t *testing.T) {
+
+	alice := &Identity{
+		ID: "alice@example.com",
+		User: &User{
+			Email:  "alice@example.com",
+			Groups: []string{"engineering", "oncall"},
+		},
+	}
+
+	morning := time.Date(2025, 1, 6, 9, 30, 0, 0, time.UTC)
+	evening := time.Date(2025, 1, 6, 22, 0, 0, 0, time.UTC)
+
+	scopes := &RoleScopes{
+		Condition: `"oncall" in groups && now.getHours() >= 9 && now.getHours() < 18`,
+	}
+
+	assert.True(t, scopes.MatchesCondition(alice, morning))
+	assert.False(t, scopes.MatchesCondition(alice, evening))
+	assert.False(t, scopes.MatchesCondition(nil, morning))
+
+	// No condition always matches
+	var missing *RoleScopes
+	assert.True(t, missing.MatchesCondition(alice, morning))
+	assert.True(t, (&RoleScopes{Users: []string{"bob"}}).MatchesCondition(alice, morning))
+
+	// Conditions that don't compile or aren't a boolean don't match
+	assert.False(t, (&RoleScopes{Condition: `user.email`}).MatchesCondition(alice, morning))
+	assert.False(t, (&RoleScopes{Condition: `user.missing == "x"`}).MatchesCondition(alice, morning))
+
+	group := &Identity{ID: "oncall", Group: &Group{ID: "g1", Name: "oncall"}}
+	assert.True(t, (&RoleScopes{Condition: `group.name == "oncall" && user.email == ""`}).MatchesCondition(group, morning))
+
+	// The CEL standard library is available e.g. timestamp and string functions
+	assert.True(t, (&RoleScopes{Condition: `now.getDayOfWeek() == 1 && identity.endsWith("@example.com")`}).MatchesCondition(alice, morning))
+}
+
+func TestRoleHasPermissionCondition(t *testing.T) {
+
+	role := &Role{
+		Scopes: &RoleScopes{
+			Domains:   []string{"example.com"},
+			Condition: `user.domain == "example.com" && "sre" in groups`,
+		},
+	}
+
+	assert.True(t, role.HasPermission(&User{Email: "alice@example.com", Groups: []string{"sre"}}))
+	assert.False(t, role.HasPermission(&User{Email: "bob@example.com"}))
+}
+
+func TestRoleScopesValidateCondition(t *testing.T) {
+	require.NoError(t, (&RoleScopes{}).ValidateCondition())
+	require.NoError(t, (&RoleScopes{Condition: `now.getDayOfWeek() != 0`}).ValidateCondition())
+
+	// Conditions are type checked when they're loaded
+	assert.ErrorContains(t, (&RoleScopes{Condition: `now.getHours() >`}).ValidateCondition(), "Syntax error")
+	assert.ErrorContains(t, (&RoleScopes{Condition: `mfa == true`}).ValidateCondition(), "undeclared reference to 'mfa'")
+	assert.ErrorContains(t, (&RoleScopes{Condition: `now.getHours() > "9"`}).ValidateCondition(), "no matching overload")
+	assert.EqualError(t, (&RoleScopes{Condition: `user.email`}).ValidateCondition(),
+		"invalid scopes condition: expression must return a bool, not string")
+}
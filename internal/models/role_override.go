@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/thand-io/agent/internal/common"
+)
+
+// RoleOverride changes a named role for the environment the agent runs in
+// e.g.
+//
+//	environment:
+//	  overrides:
+//	    admin:
+//	      max_duration: 1h
+//	      requires_approval: true
+//	    sandbox:
+//	      disabled: true
+//
+// Only the fields that are set are applied to the role.
+type RoleOverride struct {
+	MaxDuration      string `json:"max_duration,omitempty" yaml:"max_duration,omitempty" mapstructure:"max_duration"`
+	RequiresApproval *bool  `json:"requires_approval,omitempty" yaml:"requires_approval,omitempty" mapstructure:"requires_approval"`
+	Disabled         *bool  `json:"disabled,omitempty" yaml:"disabled,omitempty" mapstructure:"disabled"`
+}
+
+// Validate checks the max duration of the override can be parsed
+func (o *RoleOverride) Validate() error {
+
+	if o == nil || len(o.MaxDuration) == 0 {
+		return nil
+	}
+
+	if _, err := common.ValidateDuration(o.MaxDuration); err != nil {
+		return fmt.Errorf("invalid max_duration: %w", err)
+	}
+
+	return nil
+}
+
+// Apply sets the overridden fields on the role
+func (o *RoleOverride) Apply(role *Role) {
+
+	if o == nil || role == nil {
+		return
+	}
+
+	if len(o.MaxDuration) > 0 {
+		role.MaxDuration = o.MaxDuration
+	}
+
+	if o.RequiresApproval != nil {
+		role.RequiresApproval = *o.RequiresApproval
+	}
+
+	if o.Disabled != nil {
+		role.Enabled = !*o.Disabled
+	}
+}
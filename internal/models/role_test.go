@@ -1,9 +1,11 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRole_HasPermission(t *testing.T) {
@@ -516,3 +518,98 @@ func TestRole_AsMap(t *testing.T) {
 	assert.Equal(t, "Administrator role", result["description"])
 	assert.Equal(t, true, result["enabled"])
 }
+
+func TestRole_IsActionAllowed(t *testing.T) {
+
+	role := &Role{
+		Name: "reader",
+		Permissions: Permissions{
+			Allow: []string{"s3:GetObject", "ec2:*", "k8s:pods:get,list", "compute.instances.*"},
+			Deny:  []string{"ec2:TerminateInstances"},
+		},
+	}
+
+	tests := []struct {
+		action        string
+		allowed       bool
+		denyByDefault bool
+	}{
+		{"s3:GetObject", true, true},
+		{"S3:getobject", true, true},
+		{"ec2:DescribeInstances", true, true},
+		{"ec2:TerminateInstances", false, false},
+		{"k8s:pods:list", true, true},
+		{"k8s:pods:delete", true, false},
+		{"compute.instances.get", true, true},
+		{"s3:PutObject", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			role.DefaultEffect = RoleEffectAllow
+			assert.Equal(t, tt.allowed, role.IsActionAllowed(tt.action))
+
+			role.DefaultEffect = RoleEffectDeny
+			assert.Equal(t, tt.denyByDefault, role.IsActionAllowed(tt.action))
+		})
+	}
+}
+
+func TestRole_ValidateDefaultEffect(t *testing.T) {
+	for _, effect := range []string{"", "allow", "deny", "Deny"} {
+		assert.NoError(t, (&Role{DefaultEffect: effect}).ValidateDefaultEffect(), effect)
+	}
+
+	for _, effect := range []string{"denny", "block", "allow-all"} {
+		assert.ErrorContains(t, (&Role{DefaultEffect: effect}).ValidateDefaultEffect(), "invalid default_effect", effect)
+	}
+}
+
+func TestRole_GetReasonPolicy(t *testing.T) {
+	var role Role
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"name": "prod-admin",
+		"justification_pattern": "JIRA-\\d+",
+		"reason_policy": {"min_length": 10}
+	}`), &role))
+
+	policy := role.GetReasonPolicy()
+	require.NotNil(t, policy)
+	assert.Equal(t, 10, policy.MinLength)
+	assert.Equal(t, []string{`JIRA-\d+`}, policy.MustMatch)
+
+	// The role's own policy is left as configured
+	assert.Empty(t, role.ReasonPolicy.MustMatch)
+
+	assert.Nil(t, (&Role{}).GetReasonPolicy())
+}
+
+func TestRole_HasTags(t *testing.T) {
+	role := Role{Tags: map[string]string{"environment": "production", "team": "data"}}
+
+	assert.True(t, role.HasTags(nil))
+	assert.True(t, role.HasTags(map[string]string{"environment": "production"}))
+	assert.True(t, role.HasTags(map[string]string{"environment": "production", "team": "data"}))
+	assert.False(t, role.HasTags(map[string]string{"environment": "staging"}))
+	assert.False(t, role.HasTags(map[string]string{"risk": "high"}))
+	assert.False(t, (&Role{}).HasTags(map[string]string{"environment": "production"}))
+}
+
+func TestParseRoleTags(t *testing.T) {
+	tags, err := ParseRoleTags([]string{"environment:production", "url:https://example.com"}, ":")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"environment": "production",
+		"url":         "https://example.com",
+	}, tags)
+
+	tags, err = ParseRoleTags([]string{"environment=production"}, "=")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"environment": "production"}, tags)
+
+	_, err = ParseRoleTags([]string{"environment"}, "=")
+	assert.EqualError(t, err, "invalid tag 'environment', expected key=value")
+
+	_, err = ParseRoleTags([]string{"=production"}, "=")
+	assert.Error(t, err)
+}
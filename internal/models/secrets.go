@@ -0,0 +1,232 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SecretResolverFunc resolves a secret reference such as awssm://name#field
+// into its plain text value. The config the reference was found in is passed
+// through so resolvers can reuse its credentials (region, profile etc).
+type SecretResolverFunc func(config *BasicConfig, reference string) (string, error)
+
+// secretCacheTTL is how long a resolved secret reference is reused before
+// it is read again, so config reads don't each call the secret store
+const secretCacheTTL = 5 * time.Minute
+
+var (
+	secretResolvers   = map[string]SecretResolverFunc{}
+	secretResolversMu sync.RWMutex
+
+	secretCache    = map[string]cachedSecret{}
+	secretCacheMu  sync.Mutex
+	secretCacheNow = time.Now
+)
+
+type cachedSecret struct {
+	value     string
+	expiresAt time.Time
+}
+
+// RegisterSecretResolver registers a resolver for config values using the
+// given URI scheme, e.g. "awssm" for values like awssm://my-secret
+func RegisterSecretResolver(scheme string, resolver SecretResolverFunc) {
+	secretResolversMu.Lock()
+	defer secretResolversMu.Unlock()
+	secretResolvers[strings.ToLower(scheme)] = resolver
+}
+
+// IsSecretReference returns true if the value uses a registered secret scheme
+func IsSecretReference(value string) bool {
+	_, found := getSecretResolver(value)
+	return found
+}
+
+func getSecretResolver(value string) (SecretResolverFunc, bool) {
+	scheme, _, found := strings.Cut(value, "://")
+	if !found || len(scheme) == 0 {
+		return nil, false
+	}
+	secretResolversMu.RLock()
+	defer secretResolversMu.RUnlock()
+	resolver, ok := secretResolvers[strings.ToLower(scheme)]
+	return resolver, ok
+}
+
+// ClearSecretCache forgets every resolved secret reference so they are
+// read again e.g. when the config is reloaded
+func ClearSecretCache() {
+	secretCacheMu.Lock()
+	defer secretCacheMu.Unlock()
+	clear(secretCache)
+}
+
+// resolveSecretReference resolves the reference, reusing the value from an
+// earlier resolution until it expires. References are cached per config as
+// the config holds the credentials used to read them. Failures aren't
+// cached so they are retried.
+func resolveSecretReference(resolver SecretResolverFunc, config *BasicConfig, reference string) (string, error) {
+
+	cacheKey := getSecretCacheKey(config, reference)
+
+	secretCacheMu.Lock()
+	cached, found := secretCache[cacheKey]
+	secretCacheMu.Unlock()
+
+	now := secretCacheNow()
+
+	if found && now.Before(cached.expiresAt) {
+		return cached.value, nil
+	}
+
+	resolved, err := resolver(config, reference)
+
+	if err != nil {
+		return "", err
+	}
+
+	secretCacheMu.Lock()
+	defer secretCacheMu.Unlock()
+
+	for key, entry := range secretCache {
+		if !now.Before(entry.expiresAt) {
+			delete(secretCache, key)
+		}
+	}
+
+	secretCache[cacheKey] = cachedSecret{
+		value:     resolved,
+		expiresAt: now.Add(secretCacheTTL),
+	}
+
+	return resolved, nil
+}
+
+// getSecretCacheKey combines the reference with the plain values of the
+// config, which are encoded with sorted keys
+func getSecretCacheKey(config *BasicConfig, reference string) string {
+
+	if config == nil {
+		return reference
+	}
+
+	encoded, err := json.Marshal(config.WithoutSecretReferences())
+
+	if err != nil {
+		// Not cached alongside other configs if it can't be encoded
+		return fmt.Sprintf("%s|%p", reference, config)
+	}
+
+	sum := sha256.Sum256(encoded)
+
+	return reference + "|" + hex.EncodeToString(sum[:])
+}
+
+// resolveString resolves the value if it is a secret reference, otherwise
+// the value is returned as is
+func (pc *BasicConfig) resolveString(key string, value string) (string, bool) {
+	resolver, found := getSecretResolver(value)
+	if !found {
+		return value, true
+	}
+	resolved, err := resolveSecretReference(resolver, pc, value)
+	if err != nil {
+		logrus.WithError(err).WithField("key", key).Error("Failed to resolve secret reference")
+		return "", false
+	}
+	return resolved, true
+}
+
+// GetRawString returns the value for the key without resolving any
+// secret references
+func (pc *BasicConfig) GetRawString(key string) (string, bool) {
+	if pc == nil {
+		return "", false
+	}
+	if value, ok := (*pc)[key]; ok {
+		if strValue, ok := value.(string); ok {
+			return strValue, true
+		}
+	}
+	return "", false
+}
+
+// WithoutSecretReferences returns a copy of the config with any
+// unresolved secret references removed
+func (pc *BasicConfig) WithoutSecretReferences() *BasicConfig {
+	filtered := BasicConfig{}
+	if pc == nil {
+		return &filtered
+	}
+	for key, value := range *pc {
+		if strValue, ok := value.(string); ok && IsSecretReference(strValue) {
+			continue
+		}
+		filtered[key] = value
+	}
+	return &filtered
+}
+
+// ResolveSecretReferences returns a copy of the value with any strings,
+// including those in maps and slices, that are secret references resolved.
+// Activities use this so secrets referenced by workflow vars are only read
+// when they are used and never recorded in the workflow history.
+func ResolveSecretReferences(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		resolver, found := getSecretResolver(v)
+		if !found {
+			return v, nil
+		}
+		scheme, _, _ := strings.Cut(v, "://")
+		resolved, err := resolveSecretReference(resolver, nil, v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s secret reference: %w", scheme, err)
+		}
+		return resolved, nil
+	case map[string]any:
+		resolved := make(map[string]any, len(v))
+		for key, item := range v {
+			resolvedItem, err := ResolveSecretReferences(item)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = resolvedItem
+		}
+		return resolved, nil
+	case NotificationRequest:
+		resolved, err := ResolveSecretReferences(map[string]any(v))
+		if err != nil {
+			return nil, err
+		}
+		return NotificationRequest(resolved.(map[string]any)), nil
+	case []any:
+		resolved := make([]any, len(v))
+		for i, item := range v {
+			resolvedItem, err := ResolveSecretReferences(item)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = resolvedItem
+		}
+		return resolved, nil
+	case []string:
+		resolved := make([]string, len(v))
+		for i, item := range v {
+			resolvedItem, err := ResolveSecretReferences(item)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = resolvedItem.(string)
+		}
+		return resolved, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicConfig_SecretReferences(t *testing.T) {
+
+	RegisterSecretResolver("testsecret", func(config *BasicConfig, reference string) (string, error) {
+		if reference == "testsecret://missing" {
+			return "", fmt.Errorf("secret not found")
+		}
+		return "resolved:" + reference, nil
+	})
+
+	config := &BasicConfig{
+		"plain":   "value",
+		"secret":  "testsecret://my-secret#password",
+		"missing": "testsecret://missing",
+		"other":   "https://example.com",
+	}
+
+	t.Run("plain values are untouched", func(t *testing.T) {
+		value, found := config.GetString("plain")
+		assert.True(t, found)
+		assert.Equal(t, "value", value)
+
+		value, found = config.GetString("other")
+		assert.True(t, found)
+		assert.Equal(t, "https://example.com", value)
+	})
+
+	t.Run("secret references are resolved", func(t *testing.T) {
+		value, found := config.GetString("secret")
+		assert.True(t, found)
+		assert.Equal(t, "resolved:testsecret://my-secret#password", value)
+		assert.Equal(t, "resolved:testsecret://my-secret#password", config.GetStringWithDefault("secret", "default"))
+	})
+
+	t.Run("failed resolution", func(t *testing.T) {
+		_, found := config.GetString("missing")
+		assert.False(t, found)
+		assert.Equal(t, "default", config.GetStringWithDefault("missing", "default"))
+	})
+
+	t.Run("raw values and filtering", func(t *testing.T) {
+		value, found := config.GetRawString("secret")
+		assert.True(t, found)
+		assert.Equal(t, "testsecret://my-secret#password", value)
+
+		filtered := config.WithoutSecretReferences()
+		assert.False(t, filtered.HasString("secret"))
+		assert.False(t, filtered.HasString("missing"))
+		assert.True(t, filtered.HasString("plain"))
+		assert.True(t, filtered.HasString("other"))
+	})
+}
+
+func TestResolveSecretReferences(t *testing.T) {
+
+	RegisterSecretResolver("testsecret", func(config *BasicConfig, reference string) (string, error) {
+		if reference == "testsecret://missing" {
+			return "", fmt.Errorf("secret not found")
+		}
+		return "resolved:" + reference, nil
+	})
+
+	payload := NotificationRequest{
+		"text":    "testsecret://token",
+		"channel": "#access",
+		"blocks":  []any{map[string]any{"value": "testsecret://block"}},
+		"to":      []string{"testsecret://to"},
+		"count":   2,
+	}
+
+	resolved, err := ResolveSecretReferences(payload)
+	assert.NoError(t, err)
+
+	assert.Equal(t, NotificationRequest{
+		"text":    "resolved:testsecret://token",
+		"channel": "#access",
+		"blocks":  []any{map[string]any{"value": "resolved:testsecret://block"}},
+		"to":      []string{"resolved:testsecret://to"},
+		"count":   2,
+	}, resolved)
+
+	// The original is left as it was
+	assert.Equal(t, "testsecret://token", payload["text"])
+
+	_, err = ResolveSecretReferences(map[string]any{"key": "testsecret://missing"})
+	assert.ErrorContains(t, err, "failed to resolve testsecret secret reference")
+}
+
+func TestSecretCache(t *testing.T) {
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	secretCacheNow = func() time.Time { return now }
+	ClearSecretCache()
+
+	t.Cleanup(func() {
+		secretCacheNow = time.Now
+		ClearSecretCache()
+	})
+
+	calls := 0
+	failing := false
+
+	RegisterSecretResolver("cachedsecret", func(config *BasicConfig, reference string) (string, error) {
+		calls++
+		if failing {
+			return "", fmt.Errorf("secret store unavailable")
+		}
+		return fmt.Sprintf("%s@%d", config.GetStringWithDefault("region", "none"), calls), nil
+	})
+
+	config := &BasicConfig{
+		"region": "us-east-1",
+		"token":  "cachedsecret://token",
+	}
+
+	t.Run("repeated reads are cached", func(t *testing.T) {
+		assert.Equal(t, "us-east-1@1", config.GetStringWithDefault("token", ""))
+		assert.Equal(t, "us-east-1@1", config.GetStringWithDefault("token", ""))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("configs with other credentials are resolved separately", func(t *testing.T) {
+		other := &BasicConfig{
+			"region": "eu-west-1",
+			"token":  "cachedsecret://token",
+		}
+		assert.Equal(t, "eu-west-1@2", other.GetStringWithDefault("token", ""))
+		assert.Equal(t, "us-east-1@1", config.GetStringWithDefault("token", ""))
+	})
+
+	t.Run("values are read again once expired", func(t *testing.T) {
+		now = now.Add(secretCacheTTL)
+		assert.Equal(t, "us-east-1@3", config.GetStringWithDefault("token", ""))
+		assert.Equal(t, "us-east-1@3", config.GetStringWithDefault("token", ""))
+	})
+
+	t.Run("clearing the cache reads them again", func(t *testing.T) {
+		ClearSecretCache()
+		assert.Equal(t, "us-east-1@4", config.GetStringWithDefault("token", ""))
+	})
+
+	t.Run("failures aren't cached", func(t *testing.T) {
+		ClearSecretCache()
+		failing = true
+		_, found := config.GetString("token")
+		assert.False(t, found)
+
+		failing = false
+		assert.Equal(t, "us-east-1@6", config.GetStringWithDefault("token", ""))
+	})
+}
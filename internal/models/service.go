@@ -26,6 +26,9 @@ type ServicesConfig struct {
 	// Scheduler - used for scheduling tasks
 	Scheduler *ServiceConfig `mapstructure:"scheduler"`
 
+	// Storage - used for storing files e.g. form uploads
+	Storage *ServiceConfig `mapstructure:"storage"`
+
 	// LLM - used for large language model interactions
 	LargeLanguageModel *LargeLanguageModelConfig `mapstructure:"llm"`
 
@@ -78,6 +81,17 @@ func (e *ServicesConfig) GetSchedulerConfigWithDefaults(defaults *BasicConfig) *
 	return e.getConfigWithDefaults(e.Scheduler, defaults)
 }
 
+// GetStorageConfigWithDefaults provides a new BasicConfig that merges the provided defaults
+// with any config values set in the ServicesConfig Storage config.
+// If there are conflicts, the values in the ServicesConfig take precedence.
+func (e *ServicesConfig) GetStorageConfigWithDefaults(defaults *BasicConfig) *BasicConfig {
+	return e.getConfigWithDefaults(e.Storage, defaults)
+}
+
+func (e *ServicesConfig) GetStorageConfig() *ServiceConfig {
+	return e.Storage
+}
+
 func (e *ServicesConfig) GetVaultConfig() *ServiceConfig {
 	return e.Vault
 }
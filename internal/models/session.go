@@ -1,6 +1,8 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -23,12 +25,35 @@ type Session struct {
 	AccessToken  string    `json:"token"`
 	RefreshToken string    `json:"refresh_token"`
 	Expiry       time.Time `json:"expiry"`
+
+	// Where the session was created, for audit logs
+	DeviceFingerprint  string `json:"device_fingerprint,omitempty"`   // hash of the User-Agent and Accept-Language headers
+	CreatedFromIP      string `json:"created_from_ip,omitempty"`      // client IP
+	CreatedFromCountry string `json:"created_from_country,omitempty"` // ISO country code of the client IP
 }
 
 func (s *Session) IsExpired() bool {
 	return time.Now().After(s.Expiry)
 }
 
+// KeepOrigin copies where a session was created from the session it
+// renews, as providers create a new session when renewing
+func (s *Session) KeepOrigin(previous *Session) {
+	if previous == nil || len(s.DeviceFingerprint) != 0 || len(s.CreatedFromIP) != 0 {
+		return
+	}
+	s.DeviceFingerprint = previous.DeviceFingerprint
+	s.CreatedFromIP = previous.CreatedFromIP
+	s.CreatedFromCountry = previous.CreatedFromCountry
+}
+
+// NewDeviceFingerprint hashes the headers identifying the browser or client
+// a session is created from
+func NewDeviceFingerprint(userAgent string, acceptLanguage string) string {
+	hash := sha256.Sum256([]byte(userAgent + "\n" + acceptLanguage))
+	return hex.EncodeToString(hash[:])
+}
+
 type ExportableSession struct {
 	*Session
 	Provider string `json:"provider"`
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDeviceFingerprint(t *testing.T) {
+
+	fingerprint := NewDeviceFingerprint("Mozilla/5.0", "en-GB,en;q=0.9")
+
+	assert.Len(t, fingerprint, 64)
+	assert.Equal(t, fingerprint, NewDeviceFingerprint("Mozilla/5.0", "en-GB,en;q=0.9"))
+	assert.NotEqual(t, fingerprint, NewDeviceFingerprint("Mozilla/5.0", "fr-FR"))
+	assert.NotEqual(t, fingerprint, NewDeviceFingerprint("curl/8.0", "en-GB,en;q=0.9"))
+}
+
+func TestSessionKeepOrigin(t *testing.T) {
+
+	previous := &Session{
+		DeviceFingerprint:  "fingerprint",
+		CreatedFromIP:      "203.0.113.10",
+		CreatedFromCountry: "GB",
+	}
+
+	t.Run("copies the origin to a renewed session", func(t *testing.T) {
+		renewed := &Session{AccessToken: "renewed"}
+		renewed.KeepOrigin(previous)
+
+		assert.Equal(t, "fingerprint", renewed.DeviceFingerprint)
+		assert.Equal(t, "203.0.113.10", renewed.CreatedFromIP)
+		assert.Equal(t, "GB", renewed.CreatedFromCountry)
+	})
+
+	t.Run("keeps an origin set by the provider", func(t *testing.T) {
+		renewed := &Session{CreatedFromIP: "198.51.100.7"}
+		renewed.KeepOrigin(previous)
+
+		assert.Equal(t, "198.51.100.7", renewed.CreatedFromIP)
+		assert.Empty(t, renewed.CreatedFromCountry)
+	})
+}
@@ -1,4 +1,34 @@
 package models
 
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+)
+
+// ErrObjectNotFound is returned when no object is stored under the key
+var ErrObjectNotFound = errors.New("object not found")
+
+// StorageImpl stores files e.g. form uploads in an object store
 type StorageImpl interface {
+	Initialize() error
+	Shutdown() error
+
+	// PutObject stores the data under the key
+	PutObject(ctx context.Context, key string, contentType string, data []byte) error
+	// GetObject returns the data stored under the key, or ErrObjectNotFound
+	GetObject(ctx context.Context, key string) ([]byte, error)
+	// GetSignedURL returns a URL the object can be downloaded from without
+	// credentials until it expires
+	GetSignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// StorageObjectServer is implemented by stores whose signed URLs are served
+// by the agent rather than the store itself. Their signed URLs are relative
+// to the API base path.
+type StorageObjectServer interface {
+	// GetSignedObject returns the object if the signature in the query is
+	// valid and hasn't expired
+	GetSignedObject(ctx context.Context, key string, query url.Values) ([]byte, string, error)
 }
@@ -1,11 +1,13 @@
 package models
 
 import (
+	"context"
 	"time"
 
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
 )
 
 const TemporalDeploymentName = "thand-agent"
@@ -24,6 +26,16 @@ const TemporalResumeSignalName = "resume"
 const TemporalEventSignalName = "event"
 const TemporalTerminateSignalName = "terminate"
 
+// Long running workflows, e.g. monitoring an elevation, continue as new
+// once their history reaches either of these limits. Temporal terminates
+// workflows at 51,200 events or 50MB.
+const DefaultContinueAsNewHistoryLength = 10000
+const DefaultContinueAsNewHistorySize = 10 * 1024 * 1024
+
+// TemporalContinueAsNewChangeID versions capturing the continue as new
+// thresholds, runs started before only continue when Temporal suggests it
+const TemporalContinueAsNewChangeID = "continue-as-new-thresholds"
+
 const TemporalIsApprovedQueryName = "isApproved"
 const TemporalGetWorkflowTaskQueryName = "getWorkflowTask"
 
@@ -37,6 +49,7 @@ var TypedSearchAttributeReason = temporal.NewSearchAttributeKeyString("reason")
 var TypedSearchAttributeDuration = temporal.NewSearchAttributeKeyInt64("duration")
 var TypedSearchAttributeIdentities = temporal.NewSearchAttributeKeyKeywordList("identities")
 var TypedSearchAttributeApproved = temporal.NewSearchAttributeKeyBool(VarsContextApproved)
+var TypedSearchAttributeRiskFlags = temporal.NewSearchAttributeKeyKeywordList(VarsContextRiskFlags) // Raised risk flags e.g. unusual_hour
 
 type TemporalConfig struct {
 	Host      string `mapstructure:"host" default:"localhost"`
@@ -49,6 +62,73 @@ type TemporalConfig struct {
 
 	// DisableVersioning disables worker versioning/deployments for testing
 	DisableVersioning bool `mapstructure:"disable_versioning" default:"false"`
+
+	// ContinueAsNewHistoryLength is the number of history events after
+	// which a workflow continues as new
+	ContinueAsNewHistoryLength int `mapstructure:"continue_as_new_history_length" default:"10000"`
+	// ContinueAsNewHistorySize is the history size in bytes after which a
+	// workflow continues as new
+	ContinueAsNewHistorySize int `mapstructure:"continue_as_new_history_size" default:"10485760"`
+
+	// Plugins are Go plugins whose exported Workflow func is registered
+	// with the worker on startup
+	Plugins []TemporalPluginConfig `mapstructure:"plugins"`
+
+	// TaskQueues are polled in addition to the agent's own, e.g. the
+	// region label of providers whose grants must run on this agent
+	TaskQueues []string `mapstructure:"task_queues"`
+}
+
+// TemporalPluginConfig registers a workflow loaded from a shared library
+type TemporalPluginConfig struct {
+	// Name the workflow is registered under
+	Name string `mapstructure:"name"`
+	// Path to the shared library built with -buildmode=plugin
+	Path string `mapstructure:"path"`
+}
+
+func (c *TemporalConfig) GetContinueAsNewHistoryLength() int {
+	if c == nil || c.ContinueAsNewHistoryLength <= 0 {
+		return DefaultContinueAsNewHistoryLength
+	}
+	return c.ContinueAsNewHistoryLength
+}
+
+func (c *TemporalConfig) GetContinueAsNewHistorySize() int {
+	if c == nil || c.ContinueAsNewHistorySize <= 0 {
+		return DefaultContinueAsNewHistorySize
+	}
+	return c.ContinueAsNewHistorySize
+}
+
+// GetContinueAsNewThresholds returns the configured limits for a workflow
+// to capture when it starts
+func (c *TemporalConfig) GetContinueAsNewThresholds() *ContinueAsNewThresholds {
+	return &ContinueAsNewThresholds{
+		HistoryLength: c.GetContinueAsNewHistoryLength(),
+		HistorySize:   c.GetContinueAsNewHistorySize(),
+	}
+}
+
+// ContinueAsNewThresholds are the history limits a workflow continues as
+// new at. They're captured once when the workflow starts and carried over
+// to each new run so replays don't depend on the worker's config.
+type ContinueAsNewThresholds struct {
+	HistoryLength int `json:"history_length"`
+	HistorySize   int `json:"history_size"`
+}
+
+// ShouldContinueAsNew returns true if the workflow's history has reached
+// the limits or Temporal suggests continuing as new
+func (t *ContinueAsNewThresholds) ShouldContinueAsNew(info *workflow.Info) bool {
+
+	if t == nil || info == nil {
+		return false
+	}
+
+	return info.GetContinueAsNewSuggested() ||
+		info.GetCurrentHistoryLength() >= t.HistoryLength ||
+		info.GetCurrentHistorySize() >= t.HistorySize
 }
 
 type TemporalImpl interface {
@@ -64,6 +144,8 @@ type TemporalImpl interface {
 	GetHostPort() string
 	GetNamespace() string
 	GetTaskQueue() string
+	GetTaskQueues() []string
+	HasTaskQueuePollers(ctx context.Context, taskQueue string) (bool, error)
 
 	IsVersioningDisabled() bool
 }
@@ -1,8 +1,63 @@
 package models
 
+import (
+	"fmt"
+	"strings"
+)
+
 type ThandConfig struct {
 	Endpoint string `json:"endpoint" yaml:"endpoint" mapstructure:"endpoint" default:"https://app.thand.io/"`
 	Base     string `json:"base" yaml:"base" mapstructure:"base" default:"/"`    // Base path for login endpoint e.g. /
 	ApiKey   string `json:"api_key" yaml:"api_key" mapstructure:"api_key"`       // The API key for authenticating with Thand.io
 	Sync     bool   `json:"sync" yaml:"sync" mapstructure:"sync" default:"true"` // Whether to enable synchronization with Thand.io
+
+	// Client certificate presented when registering with a server that
+	// requires mTLS
+	ClientCertFile string `json:"client_cert_file" yaml:"client_cert_file" mapstructure:"client_cert_file"`
+	ClientKeyFile  string `json:"client_key_file" yaml:"client_key_file" mapstructure:"client_key_file"`
+}
+
+// HasClientCert returns true if a client certificate has been configured
+func (t *ThandConfig) HasClientCert() bool {
+	return len(t.ClientCertFile) > 0 && len(t.ClientKeyFile) > 0
+}
+
+// SyncConflictPolicy controls what happens at startup when the agent
+// can't sync its configuration with the thand server
+type SyncConflictPolicy string
+
+const (
+	// SyncConflictPolicyFail stops startup when the sync fails
+	SyncConflictPolicyFail SyncConflictPolicy = "fail"
+	// SyncConflictPolicyWarn logs the failure and continues
+	SyncConflictPolicyWarn SyncConflictPolicy = "warn"
+	// SyncConflictPolicyUseLocal ignores the remote config and only
+	// uses the local config
+	SyncConflictPolicyUseLocal SyncConflictPolicy = "use_local"
+)
+
+type SyncConfig struct {
+	ConflictPolicy SyncConflictPolicy `json:"conflict_policy" yaml:"conflict_policy" mapstructure:"conflict_policy" default:"warn"`
+}
+
+// GetConflictPolicy returns the conflict policy, defaulting to warn
+func (s *SyncConfig) GetConflictPolicy() SyncConflictPolicy {
+	if len(s.ConflictPolicy) == 0 {
+		return SyncConflictPolicyWarn
+	}
+	return SyncConflictPolicy(strings.ToLower(string(s.ConflictPolicy)))
+}
+
+// Validate checks the conflict policy is a known value
+func (s *SyncConfig) Validate() error {
+	switch s.GetConflictPolicy() {
+	case SyncConflictPolicyFail, SyncConflictPolicyWarn, SyncConflictPolicyUseLocal:
+		return nil
+	}
+	return fmt.Errorf("invalid sync conflict policy: %s (expected %s, %s or %s)",
+		s.ConflictPolicy,
+		SyncConflictPolicyFail,
+		SyncConflictPolicyWarn,
+		SyncConflictPolicyUseLocal,
+	)
 }
@@ -25,6 +25,8 @@ type User struct {
 	Source string `json:"source,omitempty"`
 	// Groups is a list of group names or IDs that this user belongs to.
 	Groups []string `json:"groups,omitempty"`
+	// Locale is the user's preferred language e.g. en or de-AT, if known.
+	Locale string `json:"locale,omitempty"`
 }
 
 func (u *User) String() string {
@@ -15,6 +15,11 @@ type Workflow struct {
 	Description string           `json:"description"`
 	Workflow    *model.Workflow  `json:"workflow,omitempty"`
 	Enabled     bool             `json:"enabled" default:"true"` // By default enable the workflow
+
+	// DuringFreeze denies requests, or requires extra approval from the
+	// FreezeApprovers, while a change freeze is active
+	DuringFreeze    FreezeAction `json:"during_freeze,omitempty"`
+	FreezeApprovers []string     `json:"freeze_approvers,omitempty"`
 }
 
 func (r *Workflow) HasPermission(user *User) bool {
@@ -107,12 +112,30 @@ type WorkflowExecutionInfo struct {
 	Providers  []string    `json:"providers,omitempty"`
 	Identities []*Identity `json:"identities,omitempty"`
 
+	// Notifications are the delivery receipts of the notifications sent
+	Notifications []NotificationReceipt `json:"notifications,omitempty"`
+
+	// Credentials minted for the requester, encrypted for the public key
+	// sent with the request
+	Credentials []*CredentialArtifact `json:"credentials,omitempty"`
+
 	// Context
 	Input   any `json:"input,omitempty"`
 	Output  any `json:"output,omitempty"`
 	Context any `json:"context,omitempty"`
 }
 
+// WorkflowSignalRequest carries an encoded signal e.g. the approval token
+// from an approval notification
+type WorkflowSignalRequest struct {
+	Input string `json:"input" form:"input" binding:"required"`
+}
+
+// WorkflowExecutionsResponse lists the executions requested by a user
+type WorkflowExecutionsResponse struct {
+	Executions []*WorkflowExecutionInfo `json:"executions"`
+}
+
 // TaskHandler defines the signature for task execution functions
 type TaskHandler func(
 	workflowTask *WorkflowTask,
@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"maps"
 	"time"
@@ -23,6 +24,7 @@ func NewWorkflowContext(workflow *Workflow) (*WorkflowTask, error) {
 		Status:          swctx.PendingStatus,
 		WorkflowID:      workflowID,
 		WorkflowName:    workflow.GetName(),
+		CreatedAt:       time.Now().UTC(),
 		Workflow:        workflow.Workflow,
 		Context:         map[string]any{},
 		internalContext: context.Background(),
@@ -63,7 +65,10 @@ func (ctx *WorkflowTask) Clone() swctx.WorkflowContext {
 
 		// Copy read-only/shared fields
 		WorkflowID:      ctx.WorkflowID,
+		CreatedAt:       ctx.CreatedAt,
 		Workflow:        ctx.Workflow,
+		Snapshot:        ctx.Snapshot,
+		ContinueAsNew:   ctx.ContinueAsNew,
 		internalContext: ctx.internalContext,
 	}
 }
@@ -282,6 +287,33 @@ func (ctx *WorkflowTask) GetContextAsMap() map[string]any {
 	return map[string]any{"context": ctx.Context}
 }
 
+// ErrContextKeyNotFound is returned by GetContextAs when the key is not set
+var ErrContextKeyNotFound = errors.New("workflow context key not found")
+
+// GetContextAs returns the context value for the key as T. Values that
+// have been through Temporal are plain maps and slices so anything that
+// isn't already a T is converted with a JSON round trip.
+func GetContextAs[T any](ctx *WorkflowTask, key string) (T, error) {
+
+	var result T
+
+	value, found := ctx.GetContextAsMap()[key]
+
+	if !found || value == nil {
+		return result, fmt.Errorf("%w: %s", ErrContextKeyNotFound, key)
+	}
+
+	if typed, ok := value.(T); ok {
+		return typed, nil
+	}
+
+	if err := common.ConvertInterfaceToInterface(value, &result); err != nil {
+		return result, fmt.Errorf("failed to decode workflow context key %s as %T: %w", key, result, err)
+	}
+
+	return result, nil
+}
+
 // GetOutputAsMap safely retrieves the output as a map[string]any.
 // If output is not a map, it wraps it in a map with "output" as the key.
 func (ctx *WorkflowTask) GetOutputAsMap() map[string]any {
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
+)
+
+// RequestContext is a snapshot of the elevation request placed in the
+// workflow context under $context.request so workflows can branch on it
+// without a task putting the values there first e.g.
+//
+//	${ $context.request.duration.seconds > 14400 }
+//	${ $context.request.role.permissions | any(startswith("iam:")) }
+//
+// Lists are never null so expressions can iterate them safely.
+type RequestContext struct {
+	Role          RequestRoleContext `json:"role"`
+	Requester     RequestUserContext `json:"requester"`
+	Providers     []string           `json:"providers"`  // providers the role is requested in
+	Identities    []string           `json:"identities"` // identities being elevated, empty for the requester
+	Reason        string             `json:"reason"`
+	Workflow      string             `json:"workflow"`
+	Authenticator string             `json:"authenticator"`
+	Duration      PolicyDuration     `json:"duration"`
+}
+
+// RequestRoleContext is the resolved composite role of the request
+type RequestRoleContext struct {
+	Name              string   `json:"name"`
+	Permissions       []string `json:"permissions"`        // allowed permissions
+	DeniedPermissions []string `json:"denied_permissions"` // explicitly denied permissions
+	Resources         []string `json:"resources"`          // allowed resources
+	Inherits          []string `json:"inherits"`
+	Providers         []string `json:"providers"` // providers that can assign the role
+}
+
+// RequestUserContext is the requester identity
+type RequestUserContext struct {
+	Identity string   `json:"identity"`
+	Email    string   `json:"email"`
+	Name     string   `json:"name"`
+	Username string   `json:"username"`
+	Source   string   `json:"source"`
+	Groups   []string `json:"groups"`
+}
+
+// NewRequestContext builds the request snapshot for an elevation request
+func NewRequestContext(request *ElevateRequestInternal) *RequestContext {
+
+	requestContext := &RequestContext{
+		Role: RequestRoleContext{
+			Permissions:       []string{},
+			DeniedPermissions: []string{},
+			Resources:         []string{},
+			Inherits:          []string{},
+			Providers:         []string{},
+		},
+		Requester: RequestUserContext{
+			Groups: []string{},
+		},
+		Providers:     nonNilStrings(request.Providers),
+		Identities:    nonNilStrings(request.Identities),
+		Reason:        request.Reason,
+		Workflow:      request.GetWorkflow(),
+		Authenticator: request.Authenticator,
+		Duration: PolicyDuration{
+			Value: request.Duration,
+		},
+	}
+
+	if request.Role != nil {
+		requestContext.Role = RequestRoleContext{
+			Name:              request.Role.GetName(),
+			Permissions:       nonNilStrings(request.Role.Permissions.Allow),
+			DeniedPermissions: nonNilStrings(request.Role.Permissions.Deny),
+			Resources:         nonNilStrings(request.Role.Resources.Allow),
+			Inherits:          nonNilStrings(request.Role.Inherits),
+			Providers:         nonNilStrings(request.Role.Providers),
+		}
+	}
+
+	if request.User != nil {
+		requestContext.Requester = RequestUserContext{
+			Identity: request.User.GetIdentity(),
+			Email:    request.User.Email,
+			Name:     request.User.Name,
+			Username: request.User.Username,
+			Source:   request.User.Source,
+			Groups:   nonNilStrings(request.User.GetGroups()),
+		}
+	}
+
+	if duration, err := request.AsDuration(); err == nil {
+		requestContext.Duration.Seconds = int64(duration.Seconds())
+	}
+
+	return requestContext
+}
+
+// SetRequestContext refreshes the request snapshot in the workflow context
+// from the current elevation request, so it reflects the composite role
+// once the requester has authenticated
+func (r *WorkflowTask) SetRequestContext() {
+
+	elevationRequest, err := r.GetContextAsElevationRequest()
+
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to get elevation request for request context")
+		return
+	}
+
+	requestContext, err := common.ConvertInterfaceToMap(NewRequestContext(elevationRequest))
+
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to convert request context")
+		return
+	}
+
+	r.SetContextKeyValue(VarsContextRequest, requestContext)
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRequestContext(t *testing.T) {
+
+	request := &ElevateRequestInternal{
+		ElevateRequest: ElevateRequest{
+			Role: &Role{
+				Name:        "admin",
+				Permissions: Permissions{Allow: []string{"iam:PassRole"}},
+				Resources:   Resources{Allow: []string{"arn:aws:s3:::logs"}},
+				Providers:   []string{"aws-prod"},
+			},
+			Providers:     []string{"aws-prod"},
+			Authenticator: "google",
+			Reason:        "incident",
+			Duration:      "PT2H",
+		},
+		User: &User{
+			Email:  "alice@example.com",
+			Name:   "Alice",
+			Groups: []string{"engineering"},
+		},
+	}
+
+	requestContext := NewRequestContext(request)
+
+	assert.Equal(t, "admin", requestContext.Role.Name)
+	assert.Equal(t, []string{"iam:PassRole"}, requestContext.Role.Permissions)
+	assert.Equal(t, []string{"arn:aws:s3:::logs"}, requestContext.Role.Resources)
+	assert.Equal(t, "alice@example.com", requestContext.Requester.Identity)
+	assert.Equal(t, []string{"engineering"}, requestContext.Requester.Groups)
+	assert.Equal(t, int64(7200), requestContext.Duration.Seconds)
+	assert.Equal(t, "google", requestContext.Authenticator)
+
+	t.Run("lists are never null", func(t *testing.T) {
+		requestContext := NewRequestContext(&ElevateRequestInternal{})
+
+		assert.NotNil(t, requestContext.Role.Permissions)
+		assert.NotNil(t, requestContext.Requester.Groups)
+		assert.NotNil(t, requestContext.Providers)
+		assert.NotNil(t, requestContext.Identities)
+	})
+
+	t.Run("set in the workflow context", func(t *testing.T) {
+		workflowTask := &WorkflowTask{}
+		workflowTask.SetContext(request.AsMap())
+		workflowTask.SetUser(request.User)
+		workflowTask.SetRequestContext()
+
+		requestMap, ok := workflowTask.GetContextAsMap()[VarsContextRequest].(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, float64(7200), requestMap["duration"].(map[string]any)["seconds"])
+	})
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+)
+
+// TemporalMemoWorkflowSnapshot is the memo key the workflow snapshot is
+// stored under when an elevation is started on Temporal
+const TemporalMemoWorkflowSnapshot = "workflow_snapshot"
+
+// WorkflowSnapshot pins the workflow definition an elevation was started
+// with. In-flight elevations resolve their definition from the snapshot so
+// config reloads that edit or rename tasks don't affect them.
+type WorkflowSnapshot struct {
+	Hash       string `json:"hash"`       // sha256 of the JSON definition
+	Definition string `json:"definition"` // gzipped and base64 encoded JSON definition
+}
+
+// NewWorkflowSnapshot serializes the workflow definition into a snapshot
+func NewWorkflowSnapshot(workflow *model.Workflow) (*WorkflowSnapshot, error) {
+
+	if workflow == nil {
+		return nil, fmt.Errorf("workflow definition not provided")
+	}
+
+	data, err := json.Marshal(workflow)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal workflow definition: %w", err)
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress workflow definition: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress workflow definition: %w", err)
+	}
+
+	hash := sha256.Sum256(data)
+
+	return &WorkflowSnapshot{
+		Hash:       hex.EncodeToString(hash[:]),
+		Definition: base64.StdEncoding.EncodeToString(compressed.Bytes()),
+	}, nil
+}
+
+// GetWorkflow decodes the snapshot and verifies it against its hash. Each
+// call returns a new copy of the definition.
+func (s *WorkflowSnapshot) GetWorkflow() (*model.Workflow, error) {
+
+	compressed, err := base64.StdEncoding.DecodeString(s.Definition)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode workflow snapshot: %w", err)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress workflow snapshot: %w", err)
+	}
+
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress workflow snapshot: %w", err)
+	}
+
+	hash := sha256.Sum256(data)
+
+	if hex.EncodeToString(hash[:]) != s.Hash {
+		return nil, fmt.Errorf("workflow snapshot hash mismatch")
+	}
+
+	workflow := &model.Workflow{}
+
+	if err := json.Unmarshal(data, workflow); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal workflow snapshot: %w", err)
+	}
+
+	return workflow, nil
+}
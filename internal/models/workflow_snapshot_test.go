@@ -0,0 +1,59 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/serverlessworkflow/sdk-go/v3/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const snapshotTestWorkflow = `
+document:
+  dsl: '1.0.0'
+  namespace: test
+  name: snapshot
+  version: '1.0.0'
+do:
+  - approve:
+      set:
+        approved: true
+`
+
+func TestWorkflowSnapshot(t *testing.T) {
+	workflow, err := parser.FromYAMLSource([]byte(snapshotTestWorkflow))
+	require.NoError(t, err)
+
+	t.Run("round trip", func(t *testing.T) {
+		snapshot, err := NewWorkflowSnapshot(workflow)
+		require.NoError(t, err)
+		assert.Len(t, snapshot.Hash, 64)
+
+		restored, err := snapshot.GetWorkflow()
+		require.NoError(t, err)
+		assert.Equal(t, "snapshot", restored.Document.Name)
+		assert.NotNil(t, restored.Do.Key("approve"))
+	})
+
+	t.Run("hash is stable", func(t *testing.T) {
+		first, err := NewWorkflowSnapshot(workflow)
+		require.NoError(t, err)
+		second, err := NewWorkflowSnapshot(workflow)
+		require.NoError(t, err)
+		assert.Equal(t, first.Hash, second.Hash)
+	})
+
+	t.Run("hash mismatch", func(t *testing.T) {
+		snapshot, err := NewWorkflowSnapshot(workflow)
+		require.NoError(t, err)
+
+		snapshot.Hash = "invalid"
+		_, err = snapshot.GetWorkflow()
+		assert.ErrorContains(t, err, "hash mismatch")
+	})
+
+	t.Run("nil workflow", func(t *testing.T) {
+		_, err := NewWorkflowSnapshot(nil)
+		assert.Error(t, err)
+	})
+}
@@ -38,6 +38,24 @@ const (
 	VarsContextRole      = "role"
 	VarsContextApproved  = "approved"
 
+	// Per provider grant results for multi-provider elevations
+	VarsContextProviderAuthorizations = "provider_authorizations"
+	VarsContextAuthorizationFailures  = "authorization_failures"
+
+	// Providers whose grants were simulated as they are read only
+	VarsContextSimulatedProviders = "simulated_providers"
+
+	// Decision from the last policy task
+	VarsContextPolicy = "policy"
+
+	// Recipients that were asked to approve the request
+	VarsContextApprovers = "approvers"
+
+	// Workflow vars from the config, providers and role e.g.
+	// ${ $context.vars.approvals_channel }. Secret references are left
+	// as they are for the activity that uses them to resolve.
+	VarsContextVars = "vars"
+
 	runnerCtxKey   ctxKey = "wfRunnerContext"
 	temporalCtxKey ctxKey = "wfTemporalContext"
 
@@ -70,11 +88,18 @@ type WorkflowTask struct {
 	Entrypoint string          `json:"entrypoint,omitempty"` // The entrypoint of the workflow - allows for resumption
 	Status     ctx.StatusPhase `json:"status,omitempty"`
 	StartedAt  time.Time       `json:"started_at,omitempty"`
+	CreatedAt  time.Time       `json:"created_at,omitempty"` // When the elevation was requested
 
 	// Never store the actual workflow workflow. We can just load it from the
 	// workflow engine
 	Workflow *model.Workflow `json:"-"` //  The workflow definition - no need to store this we can get it from the engine
 
+	// Snapshot of the workflow definition the task was started with
+	Snapshot *WorkflowSnapshot `json:"snapshot,omitempty"`
+
+	// Limits the workflow continues as new at, captured when it starts
+	ContinueAsNew *ContinueAsNewThresholds `json:"continue_as_new,omitempty"`
+
 	// Store the global context input/output state
 	Context any `json:"context,omitempty"` // Use a map to allow serialization
 	Input   any `json:"input,omitempty"`
@@ -156,6 +181,20 @@ func (r *WorkflowTask) WithTemporalContext(ctx workflow.Context) *WorkflowTask {
 	return r
 }
 
+// WithoutTemporalContext detaches the task from temporal so tasks execute
+// in process, restore it with SetInternalContext
+func (r *WorkflowTask) WithoutTemporalContext() *WorkflowTask {
+
+	intlCtx := r.internalContext
+
+	if intlCtx == nil {
+		intlCtx = context.Background()
+	}
+
+	r.internalContext = context.WithValue(intlCtx, temporalCtxKey, nil)
+	return r
+}
+
 func (r *WorkflowTask) HasTemporalContext() bool {
 
 	if r.internalContext == nil {
@@ -165,6 +204,19 @@ func (r *WorkflowTask) HasTemporalContext() bool {
 	return r.internalContext.Value(temporalCtxKey) != nil
 }
 
+// IsReplaying returns true when temporal is replaying workflow history.
+// Side effects such as metrics should be skipped while replaying.
+func (r *WorkflowTask) IsReplaying() bool {
+
+	temporalContext := r.GetTemporalContext()
+
+	if temporalContext == nil {
+		return false
+	}
+
+	return workflow.IsReplaying(temporalContext)
+}
+
 func (wr *WorkflowTask) SetTaskReferenceFromName(taskName string) error {
 	ref, err := impl.GenerateJSONPointer(wr.Workflow, taskName)
 	if err != nil {
@@ -337,6 +389,18 @@ func (ctx *WorkflowTask) GetEntrypoint() string {
 	return ctx.Entrypoint
 }
 
+func (ctx *WorkflowTask) GetContinueAsNewThresholds() *ContinueAsNewThresholds {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	return ctx.ContinueAsNew
+}
+
+func (ctx *WorkflowTask) SetContinueAsNewThresholds(thresholds *ContinueAsNewThresholds) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.ContinueAsNew = thresholds
+}
+
 func (ctx *WorkflowTask) HasEntrypoint() bool {
 	ctx.mu.Lock()
 	defer ctx.mu.Unlock()
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/serverlessworkflow/sdk-go/v3/model"
@@ -537,3 +538,60 @@ func TestWorkflowTask_WorkflowExampleCompatibility(t *testing.T) {
 		t.Errorf("JSON round-trip failed. Got: %s, Expected: %s", unmarshalledResult["message"], expectedMessage)
 	}
 }
+
+func TestGetContextAs(t *testing.T) {
+
+	workflowTask := &WorkflowTask{}
+	workflowTask.SetContextKeyValue("approvals", map[string]any{
+		"alice@example.com": map[string]any{"approved": true},
+	})
+	// As it would look after a Temporal round trip
+	workflowTask.SetContextKeyValue(VarsContextPolicy, map[string]any{
+		"decision":  "require_approval",
+		"approvers": []any{"bob@example.com"},
+	})
+
+	t.Run("value of the same type", func(t *testing.T) {
+		approvals, err := GetContextAs[map[string]any](workflowTask, "approvals")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, ok := approvals["alice@example.com"]; !ok {
+			t.Errorf("Expected approval from alice@example.com, got %v", approvals)
+		}
+	})
+
+	t.Run("nested maps are converted", func(t *testing.T) {
+		approvals, err := GetContextAs[map[string]struct {
+			Approved bool `json:"approved"`
+		}](workflowTask, "approvals")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !approvals["alice@example.com"].Approved {
+			t.Errorf("Expected alice@example.com to have approved, got %v", approvals)
+		}
+
+		decision, err := GetContextAs[PolicyDecision](workflowTask, VarsContextPolicy)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(decision.Approvers) != 1 || decision.Approvers[0] != "bob@example.com" {
+			t.Errorf("Expected approvers [bob@example.com], got %v", decision.Approvers)
+		}
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, err := GetContextAs[string](workflowTask, "missing")
+		if !errors.Is(err, ErrContextKeyNotFound) {
+			t.Errorf("Expected ErrContextKeyNotFound, got %v", err)
+		}
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		_, err := GetContextAs[[]string](workflowTask, "approvals")
+		if err == nil || errors.Is(err, ErrContextKeyNotFound) {
+			t.Errorf("Expected a decode error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/thand-io/agent/internal/common"
+)
+
+// Built in workflow templates a role can reference instead of a workflow
+const (
+	// WorkflowTemplateJITWithApproval grants access once it's approved
+	WorkflowTemplateJITWithApproval = "jit_with_approval"
+	// WorkflowTemplateJITNoApproval grants access straight away
+	WorkflowTemplateJITNoApproval = "jit_no_approval"
+	// WorkflowTemplateJITWithTicket grants access straight away when the
+	// reason references a ticket
+	WorkflowTemplateJITWithTicket = "jit_with_ticket"
+)
+
+// DefaultTicketPattern matches ticket references such as OPS-1234
+const DefaultTicketPattern = `\b[A-Z][A-Z0-9]+-[0-9]+\b`
+
+// WorkflowTemplates lists the built in workflow templates
+var WorkflowTemplates = []string{
+	WorkflowTemplateJITWithApproval,
+	WorkflowTemplateJITNoApproval,
+	WorkflowTemplateJITWithTicket,
+}
+
+// WorkflowTemplate expands into a workflow for the role. It can be set to
+// just the template name e.g. workflow_template: jit_no_approval
+type WorkflowTemplate struct {
+	Name string `json:"name"`
+	// Approvals is the number of approvals needed, defaults to 1
+	Approvals   int  `json:"approvals,omitempty"`
+	SelfApprove bool `json:"self_approve,omitempty"`
+	// Duration overrides the requested duration e.g. 1h, otherwise the
+	// role's max duration caps it
+	Duration string `json:"duration,omitempty"`
+	// TicketPattern is the regular expression the reason must match for
+	// jit_with_ticket, defaults to a ticket reference such as OPS-1234
+	TicketPattern string `json:"ticket_pattern,omitempty"`
+	// Notifiers are sent approval requests and grant updates, keyed by name
+	Notifiers map[string]WorkflowTemplateNotifier `json:"notifiers,omitempty"`
+}
+
+// WorkflowTemplateNotifier is a notification provider and the channels,
+// emails or users to notify
+type WorkflowTemplateNotifier struct {
+	Provider string   `json:"provider"`
+	To       []string `json:"to"`
+}
+
+func (t *WorkflowTemplate) UnmarshalJSON(data []byte) error {
+
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = WorkflowTemplate{Name: name}
+		return nil
+	}
+
+	type workflowTemplate WorkflowTemplate
+	return json.Unmarshal(data, (*workflowTemplate)(t))
+}
+
+// GetApprovals returns the number of approvals needed
+func (t *WorkflowTemplate) GetApprovals() int {
+	if t.Approvals <= 0 {
+		return 1
+	}
+	return t.Approvals
+}
+
+// GetTicketPattern returns the pattern reasons must match for
+// jit_with_ticket
+func (t *WorkflowTemplate) GetTicketPattern() string {
+	if len(t.TicketPattern) == 0 {
+		return DefaultTicketPattern
+	}
+	return t.TicketPattern
+}
+
+// Validate checks the template exists and has the values it needs
+func (t *WorkflowTemplate) Validate() error {
+
+	if t == nil {
+		return nil
+	}
+
+	switch t.Name {
+	case WorkflowTemplateJITWithApproval:
+		if len(t.Notifiers) == 0 {
+			return fmt.Errorf("workflow template '%s' needs notifiers to send approval requests to", t.Name)
+		}
+	case WorkflowTemplateJITNoApproval:
+	case WorkflowTemplateJITWithTicket:
+		if _, err := regexp.Compile(t.GetTicketPattern()); err != nil {
+			return fmt.Errorf("invalid ticket_pattern for workflow template '%s': %w", t.Name, err)
+		}
+	default:
+		return fmt.Errorf("unknown workflow template '%s', expected %s",
+			t.Name, strings.Join(WorkflowTemplates, ", "))
+	}
+
+	if len(t.Duration) > 0 {
+		if _, err := common.ValidateDuration(t.Duration); err != nil {
+			return fmt.Errorf("invalid duration for workflow template '%s': %w", t.Name, err)
+		}
+	}
+
+	for name, notifier := range t.Notifiers {
+		if len(notifier.Provider) == 0 || len(notifier.To) == 0 {
+			return fmt.Errorf("notifier '%s' of workflow template '%s' needs a provider and who to notify", name, t.Name)
+		}
+	}
+
+	return nil
+}
+
+// GetWorkflowName returns the name the role's expanded workflow is
+// registered as e.g. prod_admin_jit_with_approval
+func (t *WorkflowTemplate) GetWorkflowName(roleName string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+			return r
+		}
+		return '_'
+	}, roleName)
+	return strings.ToLower(name + "_" + t.Name)
+}
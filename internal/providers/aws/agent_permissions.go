@@ -0,0 +1,133 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// agentActions are the actions the IAM and Identity Center grant paths use
+var agentActions = []string{
+	// IAM roles
+	"iam:GetRole",
+	"iam:GetUser",
+	"iam:CreateRole",
+	"iam:PutRolePolicy",
+	"iam:UpdateAssumeRolePolicy",
+
+	// Identity Center permission sets and account assignments
+	"sso:ListInstances",
+	"sso:ListPermissionSets",
+	"sso:DescribePermissionSet",
+	"sso:CreatePermissionSet",
+	"sso:PutInlinePolicyToPermissionSet",
+	"sso:AttachManagedPolicyToPermissionSet",
+	"sso:AttachCustomerManagedPolicyReferenceToPermissionSet",
+	"sso:ListManagedPoliciesInPermissionSet",
+	"sso:ListCustomerManagedPolicyReferencesInPermissionSet",
+	"sso:CreateAccountAssignment",
+	"sso:DeleteAccountAssignment",
+	"identitystore:ListUsers",
+}
+
+// ValidateAgentPermissions simulates the agent's IAM policies against the
+// actions it grants with. SimulatePrincipalPolicy only evaluates
+// policies so nothing is changed.
+func (p *awsProvider) ValidateAgentPermissions(ctx context.Context) ([]models.AgentPermission, error) {
+
+	callerIdentity, err := p.stsService.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the agent's AWS identity: %w", err)
+	}
+
+	principalArn := aws.ToString(callerIdentity.Arn)
+
+	// The root user isn't restricted by IAM policies
+	if strings.HasSuffix(principalArn, ":root") {
+		permissions := make([]models.AgentPermission, 0, len(agentActions))
+		for _, action := range agentActions {
+			permissions = append(permissions, models.AgentPermission{Permission: action, Allowed: true})
+		}
+		return permissions, nil
+	}
+
+	policySourceArn, err := p.getPolicySourceArn(ctx, principalArn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make([]models.AgentPermission, 0, len(agentActions))
+
+	paginator := iam.NewSimulatePrincipalPolicyPaginator(p.service, &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(policySourceArn),
+		ActionNames:     agentActions,
+	})
+
+	for paginator.HasMorePages() {
+
+		page, err := paginator.NextPage(ctx)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate the policies of %s: %w", policySourceArn, err)
+		}
+
+		for _, result := range page.EvaluationResults {
+
+			permission := models.AgentPermission{
+				Permission: aws.ToString(result.EvalActionName),
+				Allowed:    result.EvalDecision == types.PolicyEvaluationDecisionTypeAllowed,
+			}
+
+			if !permission.Allowed {
+				permission.Reason = string(result.EvalDecision)
+			}
+
+			permissions = append(permissions, permission)
+		}
+	}
+
+	return permissions, nil
+}
+
+// getPolicySourceArn returns the IAM user or role whose policies apply to
+// the caller. Assumed role sessions are simulated as their role, which is
+// looked up as the session ARN doesn't include the role's path.
+func (p *awsProvider) getPolicySourceArn(ctx context.Context, principalArn string) (string, error) {
+
+	parts := strings.Split(principalArn, ":")
+
+	if len(parts) < 6 {
+		return "", fmt.Errorf("unexpected AWS identity %s", principalArn)
+	}
+
+	resource := parts[5]
+
+	switch {
+	case strings.HasPrefix(resource, "user/"):
+		return principalArn, nil
+
+	case strings.HasPrefix(resource, "assumed-role/"):
+		roleName := strings.Split(strings.TrimPrefix(resource, "assumed-role/"), "/")[0]
+
+		role, err := p.service.GetRole(ctx, &iam.GetRoleInput{
+			RoleName: aws.String(roleName),
+		})
+
+		if err != nil {
+			return "", fmt.Errorf("failed to get the agent's IAM role %s: %w", roleName, err)
+		}
+
+		return aws.ToString(role.Role.Arn), nil
+	}
+
+	// Federated users have no IAM policies of their own to simulate
+	return "", fmt.Errorf("%w: permissions of %s can't be simulated", models.ErrNotImplemented, principalArn)
+}
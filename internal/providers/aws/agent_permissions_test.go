@@ -0,0 +1,24 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestGetPolicySourceArn(t *testing.T) {
+
+	provider := &awsProvider{}
+
+	arn, err := provider.getPolicySourceArn(context.Background(), "arn:aws:iam::123456789012:user/thand/agent")
+	assert.NoError(t, err)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/thand/agent", arn)
+
+	_, err = provider.getPolicySourceArn(context.Background(), "arn:aws:sts::123456789012:federated-user/alice")
+	assert.ErrorIs(t, err, models.ErrNotImplemented)
+
+	_, err = provider.getPolicySourceArn(context.Background(), "not-an-arn")
+	assert.ErrorContains(t, err, "unexpected AWS identity")
+}
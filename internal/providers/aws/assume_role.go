@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+const defaultRoleSessionName = "thand-agent"
+
+// parseRoleArnChain splits a comma-separated role_arn value into the
+// ordered list of roles to assume
+func parseRoleArnChain(roleArns string) []string {
+	var chain []string
+	for roleArn := range strings.SplitSeq(roleArns, ",") {
+		roleArn = strings.TrimSpace(roleArn)
+		if len(roleArn) > 0 {
+			chain = append(chain, roleArn)
+		}
+	}
+	return chain
+}
+
+// withAssumeRoleChain wraps the base credentials in the configured
+// role_arn chain. Each role is assumed using the credentials of the
+// previous hop, so role_arn: "arn:a,arn:b" assumes a and then b from a.
+// The external_id is only sent when assuming the final role, which is
+// the role owned by the target account.
+func withAssumeRoleChain(sdkConfig aws.Config, awsConfig *models.BasicConfig) aws.Config {
+
+	roleArns, found := awsConfig.GetString("role_arn")
+
+	if !found {
+		return sdkConfig
+	}
+
+	chain := parseRoleArnChain(roleArns)
+
+	if len(chain) == 0 {
+		return sdkConfig
+	}
+
+	externalId, _ := awsConfig.GetString("external_id")
+	sessionName := awsConfig.GetStringWithDefault("role_session_name", defaultRoleSessionName)
+
+	for i, roleArn := range chain {
+
+		logrus.WithFields(logrus.Fields{
+			"role_arn": roleArn,
+			"hop":      i + 1,
+		}).Debug("Assuming AWS role")
+
+		isLastHop := i == len(chain)-1
+
+		credentialsProvider := stscreds.NewAssumeRoleProvider(
+			sts.NewFromConfig(sdkConfig),
+			roleArn,
+			func(o *stscreds.AssumeRoleOptions) {
+				o.RoleSessionName = sessionName
+				if isLastHop && len(externalId) > 0 {
+					o.ExternalID = aws.String(externalId)
+				}
+			},
+		)
+
+		sdkConfig = sdkConfig.Copy()
+		sdkConfig.Credentials = aws.NewCredentialsCache(credentialsProvider)
+	}
+
+	return sdkConfig
+}
@@ -0,0 +1,37 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestParseRoleArnChain(t *testing.T) {
+	assert.Equal(t, []string{
+		"arn:aws:iam::111111111111:role/hub",
+		"arn:aws:iam::222222222222:role/spoke",
+	}, parseRoleArnChain(" arn:aws:iam::111111111111:role/hub, ,arn:aws:iam::222222222222:role/spoke "))
+
+	assert.Empty(t, parseRoleArnChain(""))
+}
+
+func TestWithAssumeRoleChain(t *testing.T) {
+	base := aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("id", "secret", ""),
+	}
+
+	// No role_arn leaves the base credentials untouched
+	unchanged := withAssumeRoleChain(base, &models.BasicConfig{})
+	assert.Equal(t, base.Credentials, unchanged.Credentials)
+
+	chained := withAssumeRoleChain(base, &models.BasicConfig{
+		"role_arn":    "arn:aws:iam::111111111111:role/hub,arn:aws:iam::222222222222:role/spoke",
+		"external_id": "example",
+	})
+	assert.IsType(t, &aws.CredentialsCache{}, chained.Credentials)
+	assert.Equal(t, base.Region, chained.Region)
+}
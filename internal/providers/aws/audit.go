@@ -0,0 +1,140 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/identitystore"
+	"github.com/aws/aws-sdk-go-v2/service/ssoadmin"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+const (
+	auditTagRequester = "thand:requester"
+	auditTagWorkflow  = "thand:workflow"
+)
+
+var (
+	// SourceIdentity only allows [\w+=,.@-] and 2-64 characters
+	invalidSourceIdentityChars = regexp.MustCompile(`[^\w+=,.@-]`)
+	// Session tag values only allow [\p{L}\p{Z}\p{N}_.:/=+\-@] and 256 characters
+	invalidSessionTagChars = regexp.MustCompile(`[^\p{L}\p{Z}\p{N}_.:/=+\-@]`)
+)
+
+// withAuditContext returns a copy of the provider whose clients act via the
+// configured grant_role_arn. The role is assumed with the requester as the
+// STS SourceIdentity and session tags for the requester and workflow, so
+// CloudTrail attributes the grant to the user it was made for. If no grant
+// role is configured or there is no audit context the provider is returned
+// unchanged.
+func (p *awsProvider) withAuditContext(ctx context.Context, audit *models.AuditContext) *awsProvider {
+
+	grantRoleArn, found := p.GetConfig().GetString("grant_role_arn")
+
+	if !found || len(grantRoleArn) == 0 || audit == nil {
+		return p
+	}
+
+	sourceIdentity := sanitizeSourceIdentity(audit.Requester)
+
+	credentialsProvider := stscreds.NewAssumeRoleProvider(
+		p.stsService,
+		grantRoleArn,
+		func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = buildRoleSessionName(audit)
+			if len(sourceIdentity) > 0 {
+				o.SourceIdentity = aws.String(sourceIdentity)
+			}
+			o.Tags = buildSessionTags(audit)
+		},
+	)
+
+	credentials := aws.NewCredentialsCache(credentialsProvider)
+
+	log := p.GetLogger(ctx)
+
+	// Make sure we can actually assume the role before swapping clients
+	if _, err := credentials.Retrieve(ctx); err != nil {
+		log.WithError(err).WithFields(logrus.Fields{
+			"grant_role_arn": grantRoleArn,
+			"requester":      audit.Requester,
+		}).Warn("Failed to assume grant role with source identity, using agent credentials")
+		return p
+	}
+
+	log.WithFields(logrus.Fields{
+		"grant_role_arn":  grantRoleArn,
+		"source_identity": sourceIdentity,
+		"workflow_id":     audit.WorkflowID,
+	}).Debug("Assumed grant role with requester source identity")
+
+	scoped := *p
+	scoped.service = iam.New(p.service.Options(), func(o *iam.Options) {
+		o.Credentials = credentials
+	})
+	scoped.ssoAdminService = ssoadmin.New(p.ssoAdminService.Options(), func(o *ssoadmin.Options) {
+		o.Credentials = credentials
+	})
+	scoped.identityStoreClient = identitystore.New(p.identityStoreClient.Options(), func(o *identitystore.Options) {
+		o.Credentials = credentials
+	})
+
+	return &scoped
+}
+
+// buildRoleSessionName builds a role session name (2-64 chars) that also
+// shows up in CloudTrail alongside the source identity
+func buildRoleSessionName(audit *models.AuditContext) string {
+	name := "thand"
+	if len(audit.WorkflowID) > 0 {
+		name = fmt.Sprintf("thand-%s", audit.WorkflowID)
+	}
+	name = invalidSourceIdentityChars.ReplaceAllString(name, "-")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+func sanitizeSourceIdentity(requester string) string {
+	identity := invalidSourceIdentityChars.ReplaceAllString(requester, "_")
+	if len(identity) > 64 {
+		identity = identity[:64]
+	}
+	if len(identity) < 2 {
+		return ""
+	}
+	return identity
+}
+
+func buildSessionTags(audit *models.AuditContext) []ststypes.Tag {
+	var tags []ststypes.Tag
+	if len(audit.Requester) > 0 {
+		tags = append(tags, ststypes.Tag{
+			Key:   aws.String(auditTagRequester),
+			Value: aws.String(sanitizeSessionTagValue(audit.Requester)),
+		})
+	}
+	if len(audit.WorkflowID) > 0 {
+		tags = append(tags, ststypes.Tag{
+			Key:   aws.String(auditTagWorkflow),
+			Value: aws.String(sanitizeSessionTagValue(audit.WorkflowID)),
+		})
+	}
+	return tags
+}
+
+func sanitizeSessionTagValue(value string) string {
+	value = strings.TrimSpace(invalidSessionTagChars.ReplaceAllString(value, "_"))
+	if len(value) > 256 {
+		value = value[:256]
+	}
+	return value
+}
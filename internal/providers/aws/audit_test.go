@@ -0,0 +1,34 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestSanitizeSourceIdentity(t *testing.T) {
+	assert.Equal(t, "alice@example.com", sanitizeSourceIdentity("alice@example.com"))
+	assert.Equal(t, "Alice_Smith", sanitizeSourceIdentity("Alice Smith"))
+	assert.Empty(t, sanitizeSourceIdentity("a"))
+	assert.Len(t, sanitizeSourceIdentity(string(make([]byte, 100))), 64)
+}
+
+func TestBuildSessionTags(t *testing.T) {
+	tags := buildSessionTags(&models.AuditContext{
+		Requester:  "alice@example.com",
+		WorkflowID: "wf-123",
+	})
+	assert.Len(t, tags, 2)
+	assert.Equal(t, auditTagRequester, *tags[0].Key)
+	assert.Equal(t, "alice@example.com", *tags[0].Value)
+	assert.Equal(t, auditTagWorkflow, *tags[1].Key)
+	assert.Equal(t, "wf-123", *tags[1].Value)
+
+	assert.Empty(t, buildSessionTags(&models.AuditContext{}))
+}
+
+func TestBuildRoleSessionName(t *testing.T) {
+	assert.Equal(t, "thand", buildRoleSessionName(&models.AuditContext{}))
+	assert.Equal(t, "thand-wf-123", buildRoleSessionName(&models.AuditContext{WorkflowID: "wf-123"}))
+}
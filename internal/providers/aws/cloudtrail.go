@@ -0,0 +1,185 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cloudtrailtypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// maxAuditEvents bounds the events returned for a single access window.
+// LookupEvents is limited to 2 requests per second so a busy user could
+// otherwise hold the request open for minutes.
+const maxAuditEvents = 1000
+
+// cloudTrailClient lazily creates the CloudTrail client the first time an
+// audit trail is requested, most agents never use it
+type cloudTrailClient struct {
+	once   sync.Once
+	config aws.Config
+	client cloudtrail.LookupEventsAPIClient
+}
+
+func (c *cloudTrailClient) get() cloudtrail.LookupEventsAPIClient {
+	c.once.Do(func() {
+		if c.client == nil {
+			c.client = cloudtrail.NewFromConfig(c.config)
+		}
+	})
+	return c.client
+}
+
+// cloudTrailEventDetails is the part of the raw CloudTrail event that
+// isn't returned as a field of the lookup
+type cloudTrailEventDetails struct {
+	UserIdentity struct {
+		Arn string `json:"arn"`
+	} `json:"userIdentity"`
+	SourceIPAddress string `json:"sourceIPAddress"`
+	ErrorCode       string `json:"errorCode"`
+}
+
+// GetAuditPrincipal returns the ARN of the IAM user that grants are bound to
+func (p *awsProvider) GetAuditPrincipal(user *models.User) (string, error) {
+
+	if user == nil {
+		return "", fmt.Errorf("user is required to look up the audit trail")
+	}
+
+	username := p.getUsernameForIAM(user)
+
+	if len(username) == 0 {
+		return "", fmt.Errorf("failed to determine username for user")
+	}
+
+	return fmt.Sprintf("arn:aws:iam::%s:user/%s", p.GetAccountID(), username), nil
+}
+
+// GetAccessAuditTrail looks up the CloudTrail events of the user between the
+// start and end time. Events are looked up by the username from the ARN and
+// then matched against the full ARN, so users with the same name in other
+// accounts or role sessions aren't included. Events are returned oldest
+// first.
+func (p *awsProvider) GetAccessAuditTrail(
+	ctx context.Context,
+	userARN string,
+	startTime time.Time,
+	endTime time.Time,
+) ([]models.AuditEvent, error) {
+
+	if p.cloudTrail == nil {
+		return nil, fmt.Errorf("CloudTrail is not configured: %w", models.ErrNotImplemented)
+	}
+
+	username := getUsernameFromARN(userARN)
+
+	if len(username) == 0 {
+		return nil, fmt.Errorf("invalid user ARN: %s", userARN)
+	}
+
+	paginator := cloudtrail.NewLookupEventsPaginator(p.cloudTrail.get(), &cloudtrail.LookupEventsInput{
+		StartTime: aws.Time(startTime),
+		EndTime:   aws.Time(endTime),
+		LookupAttributes: []cloudtrailtypes.LookupAttribute{{
+			AttributeKey:   cloudtrailtypes.LookupAttributeKeyUsername,
+			AttributeValue: aws.String(username),
+		}},
+	})
+
+	events := []models.AuditEvent{}
+
+	for paginator.HasMorePages() && len(events) < maxAuditEvents {
+
+		output, err := paginator.NextPage(ctx)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up CloudTrail events: %w", err)
+		}
+
+		for _, event := range output.Events {
+
+			auditEvent, details := newAuditEvent(event)
+
+			if strings.HasPrefix(userARN, "arn:") &&
+				len(details.UserIdentity.Arn) > 0 &&
+				details.UserIdentity.Arn != userARN {
+				continue
+			}
+
+			events = append(events, auditEvent)
+		}
+	}
+
+	if len(events) >= maxAuditEvents {
+		p.GetLogger(ctx).WithField("user", userARN).
+			Warnf("Audit trail truncated to %d events", maxAuditEvents)
+		events = events[:maxAuditEvents]
+	}
+
+	slices.SortStableFunc(events, func(a, b models.AuditEvent) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	return events, nil
+}
+
+// newAuditEvent converts a CloudTrail event, also returning the details
+// parsed from the raw event
+func newAuditEvent(event cloudtrailtypes.Event) (models.AuditEvent, cloudTrailEventDetails) {
+
+	auditEvent := models.AuditEvent{
+		ID:       aws.ToString(event.EventId),
+		Time:     aws.ToTime(event.EventTime),
+		Name:     aws.ToString(event.EventName),
+		Source:   aws.ToString(event.EventSource),
+		Username: aws.ToString(event.Username),
+		ReadOnly: strings.EqualFold(aws.ToString(event.ReadOnly), "true"),
+	}
+
+	for _, resource := range event.Resources {
+		if name := aws.ToString(resource.ResourceName); len(name) > 0 {
+			auditEvent.Resources = append(auditEvent.Resources, name)
+		}
+	}
+
+	var details cloudTrailEventDetails
+
+	if raw := aws.ToString(event.CloudTrailEvent); len(raw) > 0 {
+		// The raw event only adds detail, the lookup fields are enough
+		// if it can't be parsed
+		if err := json.Unmarshal([]byte(raw), &details); err == nil {
+			auditEvent.SourceIP = details.SourceIPAddress
+			auditEvent.Error = details.ErrorCode
+		}
+	}
+
+	return auditEvent, details
+}
+
+// getUsernameFromARN returns the name CloudTrail records calls against e.g.
+// alice for arn:aws:iam::123456789012:user/alice or the session name of an
+// assumed role. Anything that isn't an ARN is used as the username.
+func getUsernameFromARN(arn string) string {
+
+	if !strings.HasPrefix(arn, "arn:") {
+		return arn
+	}
+
+	parts := strings.SplitN(arn, ":", 6)
+
+	if len(parts) != 6 {
+		return ""
+	}
+
+	resource := parts[5]
+
+	return resource[strings.LastIndex(resource, "/")+1:]
+}
@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cloudtrailtypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+type fakeCloudTrail struct {
+	pages  [][]cloudtrailtypes.Event
+	inputs []*cloudtrail.LookupEventsInput
+}
+
+func (f *fakeCloudTrail) LookupEvents(
+	ctx context.Context,
+	params *cloudtrail.LookupEventsInput,
+	optFns ...func(*cloudtrail.Options),
+) (*cloudtrail.LookupEventsOutput, error) {
+
+	f.inputs = append(f.inputs, params)
+
+	output := &cloudtrail.LookupEventsOutput{Events: f.pages[len(f.inputs)-1]}
+
+	if len(f.inputs) < len(f.pages) {
+		output.NextToken = aws.String("next")
+	}
+
+	return output, nil
+}
+
+func newCloudTrailEvent(id string, at time.Time, arn string) cloudtrailtypes.Event {
+	return cloudtrailtypes.Event{
+		EventId:         aws.String(id),
+		EventTime:       aws.Time(at),
+		EventName:       aws.String("TerminateInstances"),
+		EventSource:     aws.String("ec2.amazonaws.com"),
+		Username:        aws.String("alice"),
+		ReadOnly:        aws.String("false"),
+		Resources:       []cloudtrailtypes.Resource{{ResourceName: aws.String("i-0123456789")}},
+		CloudTrailEvent: aws.String(`{"userIdentity":{"arn":"` + arn + `"},"sourceIPAddress":"203.0.113.10","errorCode":"UnauthorizedOperation"}`),
+	}
+}
+
+func TestGetAccessAuditTrail(t *testing.T) {
+
+	userARN := "arn:aws:iam::123456789012:user/alice"
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	fake := &fakeCloudTrail{pages: [][]cloudtrailtypes.Event{
+		{
+			newCloudTrailEvent("2", start.Add(20*time.Minute), userARN),
+			newCloudTrailEvent("other", start.Add(15*time.Minute), "arn:aws:iam::210987654321:user/alice"),
+		},
+		{newCloudTrailEvent("1", start.Add(10*time.Minute), userARN)},
+	}}
+
+	provider := &awsProvider{
+		BaseProvider: models.NewBaseProvider("aws", models.Provider{Name: "aws", Provider: "aws"}),
+		cloudTrail:   &cloudTrailClient{client: fake},
+	}
+
+	events, err := provider.GetAccessAuditTrail(context.Background(), userARN, start, end)
+	require.NoError(t, err)
+
+	require.Len(t, events, 2)
+	assert.Equal(t, "1", events[0].ID, "events are returned oldest first")
+	assert.Equal(t, "2", events[1].ID)
+	assert.Equal(t, "TerminateInstances", events[1].Name)
+	assert.Equal(t, "ec2.amazonaws.com", events[1].Source)
+	assert.Equal(t, "203.0.113.10", events[1].SourceIP)
+	assert.Equal(t, "UnauthorizedOperation", events[1].Error)
+	assert.Equal(t, []string{"i-0123456789"}, events[1].Resources)
+	assert.False(t, events[1].ReadOnly)
+
+	require.Len(t, fake.inputs, 2)
+	assert.Equal(t, start, aws.ToTime(fake.inputs[0].StartTime))
+	assert.Equal(t, end, aws.ToTime(fake.inputs[0].EndTime))
+	assert.Equal(t, cloudtrailtypes.LookupAttributeKeyUsername, fake.inputs[0].LookupAttributes[0].AttributeKey)
+	assert.Equal(t, "alice", aws.ToString(fake.inputs[0].LookupAttributes[0].AttributeValue))
+
+	t.Run("not configured", func(t *testing.T) {
+		_, err := (&awsProvider{}).GetAccessAuditTrail(context.Background(), userARN, start, end)
+		assert.ErrorIs(t, err, models.ErrNotImplemented)
+	})
+}
+
+func TestGetUsernameFromARN(t *testing.T) {
+	assert.Equal(t, "alice", getUsernameFromARN("arn:aws:iam::123456789012:user/alice"))
+	assert.Equal(t, "alice", getUsernameFromARN("arn:aws:iam::123456789012:user/engineering/alice"))
+	assert.Equal(t, "alice@example.com", getUsernameFromARN("arn:aws:sts::123456789012:assumed-role/admin/alice@example.com"))
+	assert.Equal(t, "alice", getUsernameFromARN("alice"))
+	assert.Empty(t, getUsernameFromARN("arn:aws:iam"))
+}
+
+func TestGetAuditPrincipal(t *testing.T) {
+	provider := &awsProvider{accountID: "123456789012"}
+
+	principal, err := provider.GetAuditPrincipal(&models.User{Email: "alice@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/alice", principal)
+
+	_, err = provider.GetAuditPrincipal(&models.User{})
+	assert.Error(t, err)
+}
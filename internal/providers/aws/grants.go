@@ -0,0 +1,96 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// ListManagedGrants lists the trust policy statements added for IAM users,
+// found by their grant reference statement IDs. Identity Center account
+// assignments carry no marker so they can't be listed.
+func (p *awsProvider) ListManagedGrants(ctx context.Context) ([]models.ManagedGrant, error) {
+
+	grants := []models.ManagedGrant{}
+
+	paginator := iam.NewListRolesPaginator(p.service, &iam.ListRolesInput{})
+
+	for paginator.HasMorePages() {
+
+		page, err := paginator.NextPage(ctx)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list roles: %w", err)
+		}
+
+		for _, role := range page.Roles {
+
+			roleName := aws.ToString(role.RoleName)
+
+			// IAM returns policy documents URL encoded
+			document, err := url.QueryUnescape(aws.ToString(role.AssumeRolePolicyDocument))
+			if err != nil {
+				logrus.WithError(err).WithField("role", roleName).Warn("Failed to decode assume role policy")
+				continue
+			}
+
+			var policy PolicyDocument
+			if err := json.Unmarshal([]byte(document), &policy); err != nil {
+				logrus.WithError(err).WithField("role", roleName).Warn("Failed to parse assume role policy")
+				continue
+			}
+
+			for _, stmt := range policy.Statement {
+
+				if !strings.HasPrefix(stmt.Sid, models.GrantReferencePrefix) {
+					continue
+				}
+
+				grants = append(grants, models.ManagedGrant{
+					Grant: &models.GrantRef{
+						Provider:  p.GetIdentifier(),
+						Reference: stmt.Sid,
+						Kind:      GrantKindTrustPolicyStatement,
+						IDs:       []string{roleName},
+					},
+					User: getStatementUser(stmt),
+					Role: roleName,
+				})
+			}
+		}
+	}
+
+	return grants, nil
+}
+
+// getStatementUser returns the IAM user the statement lets assume the role
+// e.g. arn:aws:iam::123456789012:user/alice
+func getStatementUser(stmt Statement) *models.User {
+
+	principal, ok := stmt.Principal.(map[string]any)
+
+	if !ok {
+		return nil
+	}
+
+	userArn, ok := principal["AWS"].(string)
+
+	if !ok {
+		return nil
+	}
+
+	_, username, found := strings.Cut(userArn, ":user/")
+
+	if !found {
+		return nil
+	}
+
+	return &models.User{Username: username}
+}
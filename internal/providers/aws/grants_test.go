@@ -0,0 +1,145 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/testing/contract"
+)
+
+// fakeIAM serves role trust policies from memory using the IAM query API
+type fakeIAM struct {
+	mu       sync.Mutex
+	policies map[string]string
+}
+
+func (f *fakeIAM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	action := r.Form.Get("Action")
+	roleName := r.Form.Get("RoleName")
+
+	if action == "ListRoles" {
+		roles := strings.Builder{}
+		for name, policy := range f.policies {
+			fmt.Fprintf(&roles, `<member><RoleName>%s</RoleName><Path>/</Path><RoleId>AROA1</RoleId>`+
+				`<Arn>arn:aws:iam::000000000000:role/%s</Arn>`+
+				`<CreateDate>2024-01-01T00:00:00Z</CreateDate>`+
+				`<AssumeRolePolicyDocument>%s</AssumeRolePolicyDocument></member>`,
+				name, name, url.QueryEscape(policy))
+		}
+		fmt.Fprintf(w, `<ListRolesResponse><ListRolesResult><Roles>%s</Roles>`+
+			`<IsTruncated>false</IsTruncated></ListRolesResult></ListRolesResponse>`, roles.String())
+		return
+	}
+
+	policy, exists := f.policies[roleName]
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `<ErrorResponse><Error><Type>Sender</Type><Code>NoSuchEntity</Code>`+
+			`<Message>role %s not found</Message></Error><RequestId>1</RequestId></ErrorResponse>`, roleName)
+		return
+	}
+
+	switch action {
+	case "GetRole":
+		fmt.Fprintf(w, `<GetRoleResponse><GetRoleResult><Role>`+
+			`<RoleName>%s</RoleName><Path>/</Path><RoleId>AROA1</RoleId>`+
+			`<Arn>arn:aws:iam::000000000000:role/%s</Arn>`+
+			`<CreateDate>2024-01-01T00:00:00Z</CreateDate>`+
+			`<AssumeRolePolicyDocument>%s</AssumeRolePolicyDocument>`+
+			`</Role></GetRoleResult></GetRoleResponse>`,
+			roleName, roleName, url.QueryEscape(policy))
+	case "UpdateAssumeRolePolicy":
+		f.policies[roleName] = r.Form.Get("PolicyDocument")
+		fmt.Fprint(w, `<UpdateAssumeRolePolicyResponse></UpdateAssumeRolePolicyResponse>`)
+	case "PutRolePolicy":
+		fmt.Fprint(w, `<PutRolePolicyResponse></PutRolePolicyResponse>`)
+	default:
+		http.Error(w, "unsupported action "+action, http.StatusBadRequest)
+	}
+}
+
+// countStatements returns the number of grant statements across the roles
+func (f *fakeIAM) countStatements() int {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	count := 0
+	for _, document := range f.policies {
+		var policy PolicyDocument
+		if err := json.Unmarshal([]byte(document), &policy); err != nil {
+			continue
+		}
+		for _, stmt := range policy.Statement {
+			if strings.HasPrefix(stmt.Sid, models.GrantReferencePrefix) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestAwsGrantContract(t *testing.T) {
+
+	fake := &fakeIAM{
+		policies: map[string]string{
+			"read_only": `{"Version":"2012-10-17","Statement":[{"Effect":"Allow",` +
+				`"Principal":{"AWS":"arn:aws:iam::000000000000:root"},"Action":"sts:AssumeRole"}]}`,
+		},
+	}
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	provider := &awsProvider{
+		BaseProvider: models.NewBaseProvider("aws", models.Provider{
+			Name:     "aws",
+			Provider: AwsProviderName,
+		}, models.ProviderCapabilityRBAC),
+		accountID: "000000000000",
+		service: iam.New(iam.Options{
+			Region:       "us-east-1",
+			Credentials:  credentials.NewStaticCredentialsProvider("test", "test", ""),
+			BaseEndpoint: aws.String(server.URL),
+		}),
+	}
+
+	contract.RunGrantContract(t, contract.GrantContract{
+		Provider: provider,
+		NewRequest: func(workflowID string) *models.RoleRequest {
+			return &models.RoleRequest{
+				// No source uses traditional IAM rather than Identity Center
+				User: &models.User{Email: "alice@example.com"},
+				Role: &models.Role{
+					Name: "Read Only",
+					Permissions: models.Permissions{
+						Allow: []string{"s3:GetObject"},
+					},
+				},
+				Audit: &models.AuditContext{
+					WorkflowID: workflowID,
+				},
+			}
+		},
+		CountGrants: fake.countStatements,
+	})
+}
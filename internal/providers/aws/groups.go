@@ -2,12 +2,12 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/identitystore"
-	"github.com/aws/aws-sdk-go-v2/service/ssoadmin"
 	"github.com/sirupsen/logrus"
 	"github.com/thand-io/agent/internal/models"
 	"go.temporal.io/sdk/temporal"
@@ -18,15 +18,21 @@ func (p *awsProvider) CanSynchronizeGroups() bool {
 }
 
 func (p *awsProvider) SynchronizeGroups(ctx context.Context, req *models.SynchronizeGroupsRequest) (*models.SynchronizeGroupsResponse, error) {
+	log := p.GetLogger(ctx)
+
 	startTime := time.Now()
 	defer func() {
 		elapsed := time.Since(startTime)
-		logrus.Debugf("Refreshed AWS group identities in %s", elapsed)
+		log.Debugf("Refreshed AWS group identities in %s", elapsed)
 	}()
 
 	// 1. Get Identity Store ID
-	resp, err := p.ssoAdminService.ListInstances(ctx, &ssoadmin.ListInstancesInput{})
-	if err != nil {
+	identityStoreId, err := p.getIdentityStoreId(ctx)
+
+	if errors.Is(err, errNoIdentityCenter) {
+		log.Warn("No SSO instances found, skipping group synchronization")
+		return &models.SynchronizeGroupsResponse{}, nil
+	} else if err != nil {
 
 		if req.Pagination == nil {
 
@@ -40,21 +46,7 @@ func (p *awsProvider) SynchronizeGroups(ctx context.Context, req *models.Synchro
 			)
 		}
 
-		return nil, fmt.Errorf("failed to list SSO instances: %w", err)
-	}
-
-	if len(resp.Instances) == 0 {
-		logrus.Warn("No SSO instances found, skipping group synchronization")
-		return &models.SynchronizeGroupsResponse{}, nil
-	}
-
-	identityStoreId := resp.Instances[0].IdentityStoreId
-	if identityStoreId == nil {
-		return nil, temporal.NewNonRetryableApplicationError(
-			"identity store ID not found in SSO instance",
-			"IdentityCenterRequest",
-			fmt.Errorf("identity store ID is nil"),
-		)
+		return nil, err
 	}
 
 	if req.Pagination == nil {
@@ -64,7 +56,7 @@ func (p *awsProvider) SynchronizeGroups(ctx context.Context, req *models.Synchro
 	}
 
 	input := &identitystore.ListGroupsInput{
-		IdentityStoreId: identityStoreId,
+		IdentityStoreId: aws.String(identityStoreId),
 		MaxResults:      aws.Int32(int32(req.Pagination.PageSize)),
 	}
 
@@ -115,7 +107,7 @@ func (p *awsProvider) SynchronizeGroups(ctx context.Context, req *models.Synchro
 		}
 	}
 
-	logrus.WithFields(logrus.Fields{
+	log.WithFields(logrus.Fields{
 		"count": len(identities),
 	}).Debug("Refreshed AWS group identities")
 
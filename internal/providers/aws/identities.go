@@ -2,24 +2,41 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
-	"github.com/sirupsen/logrus"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/identitystore"
+	identitystoretypes "github.com/aws/aws-sdk-go-v2/service/identitystore/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssoadmin"
 	"github.com/thand-io/agent/internal/models"
 	"go.temporal.io/sdk/temporal"
 )
 
+const (
+	IdentitySourceIam            = "iam"
+	IdentitySourceIdentityCenter = "aws-identity-center"
+)
+
+// errNoIdentityCenter is returned when the account has no Identity Center
+// instance and no identity_store_id is configured
+var errNoIdentityCenter = errors.New("no SSO instances found")
+
 func (p *awsProvider) CanSynchronizeIdentities() bool {
 	return true
 }
 
 func (p *awsProvider) SynchronizeIdentities(ctx context.Context, req *models.SynchronizeIdentitiesRequest) (*models.SynchronizeIdentitiesResponse, error) {
+	log := p.GetLogger(ctx)
+
 	startTime := time.Now()
 	defer func() {
 		elapsed := time.Since(startTime)
-		logrus.Debugf("Refreshed AWS IAM users in %s", elapsed)
+		log.Debugf("Refreshed AWS IAM users in %s", elapsed)
 	}()
 
 	input := &iam.ListUsersInput{}
@@ -64,33 +81,9 @@ func (p *awsProvider) SynchronizeIdentities(ctx context.Context, req *models.Syn
 
 	var identities []models.Identity
 	for _, user := range output.Users {
-		var userId string
-		var userName string
-
-		if user.Arn != nil && len(*user.Arn) > 0 {
-			userId = *user.Arn
-		} else if user.UserId != nil && len(*user.UserId) > 0 {
-			userId = *user.UserId
-		} else {
-			continue
-		}
-
-		if user.UserName != nil && len(*user.UserName) > 0 {
-			userName = *user.UserName
-		}
-
-		// IAM users don't have emails
-		identity := models.Identity{
-			ID:    userId,
-			Label: userName,
-			User: &models.User{
-				ID:       userId,
-				Username: userName,
-				Name:     userName,
-				Source:   "iam",
-			},
+		if identity := newIamUserIdentity(user); identity != nil {
+			identities = append(identities, *identity)
 		}
-		identities = append(identities, identity)
 	}
 
 	response := &models.SynchronizeIdentitiesResponse{
@@ -106,3 +99,335 @@ func (p *awsProvider) SynchronizeIdentities(ctx context.Context, req *models.Syn
 
 	return response, nil
 }
+
+// ListIdentities returns the synchronized identities. If nothing has been
+// synchronized yet the IAM and Identity Center users are fetched directly.
+func (p *awsProvider) ListIdentities(ctx context.Context, searchRequest *models.SearchRequest) ([]models.SearchResult[models.Identity], error) {
+
+	cached, err := p.BaseProvider.ListIdentities(ctx, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cached) == 0 {
+
+		identities, err := p.listUserIdentities(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		p.AddIdentities(identities...)
+	}
+
+	return p.BaseProvider.ListIdentities(ctx, searchRequest)
+}
+
+// GetIdentity returns an identity by IAM user ARN, user name or email. If
+// the identity hasn't been synchronized it is looked up in IAM and
+// Identity Center.
+func (p *awsProvider) GetIdentity(ctx context.Context, identity string) (*models.Identity, error) {
+
+	if found, err := p.BaseProvider.GetIdentity(ctx, identity); err == nil {
+		return found, nil
+	} else if !p.HasCapability(models.ProviderCapabilityIdentities) {
+		return nil, err
+	}
+
+	found, err := p.lookupIdentity(ctx, identity)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.AddIdentities(*found)
+
+	return found, nil
+}
+
+func (p *awsProvider) lookupIdentity(ctx context.Context, identity string) (*models.Identity, error) {
+
+	log := p.GetLogger(ctx)
+
+	identity = strings.TrimSpace(identity)
+
+	if len(identity) == 0 {
+		return nil, fmt.Errorf("identity not found: %s", identity)
+	}
+
+	// IAM user ARNs can only be IAM users
+	if strings.HasPrefix(identity, "arn:") {
+
+		userName, err := iamUserNameFromArn(identity)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return p.getIamUserIdentity(ctx, userName)
+	}
+
+	// Identity Center user names are often emails so check both attributes
+	attributes := []string{"userName"}
+
+	if strings.Contains(identity, "@") {
+		attributes = append(attributes, "emails.value")
+	}
+
+	found, err := p.findIdentityCenterUserIdentity(ctx, identity, attributes...)
+
+	if err == nil {
+		return found, nil
+	}
+
+	if !errors.Is(err, errNoIdentityCenter) {
+		log.WithError(err).WithField("identity", identity).
+			Debug("Identity not found in Identity Center")
+	}
+
+	// IAM users don't have emails
+	if strings.Contains(identity, "@") {
+		return nil, fmt.Errorf("identity not found: %s", identity)
+	}
+
+	return p.getIamUserIdentity(ctx, identity)
+}
+
+// listUserIdentities fetches every IAM and Identity Center user
+func (p *awsProvider) listUserIdentities(ctx context.Context) ([]models.Identity, error) {
+
+	log := p.GetLogger(ctx)
+
+	var identities []models.Identity
+
+	paginator := iam.NewListUsersPaginator(p.service, &iam.ListUsersInput{})
+
+	for paginator.HasMorePages() {
+
+		output, err := paginator.NextPage(ctx)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list IAM users: %w", err)
+		}
+
+		for _, user := range output.Users {
+			if identity := newIamUserIdentity(user); identity != nil {
+				identities = append(identities, *identity)
+			}
+		}
+	}
+
+	identityStoreId, err := p.getIdentityStoreId(ctx)
+
+	if errors.Is(err, errNoIdentityCenter) {
+		log.Debug("No SSO instances found, listing IAM users only")
+		return identities, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	usersPaginator := identitystore.NewListUsersPaginator(p.identityStoreClient, &identitystore.ListUsersInput{
+		IdentityStoreId: aws.String(identityStoreId),
+	})
+
+	for usersPaginator.HasMorePages() {
+
+		output, err := usersPaginator.NextPage(ctx)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Identity Center users: %w", err)
+		}
+
+		for _, user := range output.Users {
+			if identity := newIdentityCenterUserIdentity(user); identity != nil {
+				identities = append(identities, *identity)
+			}
+		}
+	}
+
+	return identities, nil
+}
+
+func (p *awsProvider) getIamUserIdentity(ctx context.Context, userName string) (*models.Identity, error) {
+
+	output, err := p.service.GetUser(ctx, &iam.GetUserInput{
+		UserName: aws.String(userName),
+	})
+
+	if err != nil {
+		var notFound *iamtypes.NoSuchEntityException
+		if errors.As(err, &notFound) {
+			return nil, fmt.Errorf("identity not found: %s", userName)
+		}
+		return nil, fmt.Errorf("failed to get IAM user %s: %w", userName, err)
+	}
+
+	identity := newIamUserIdentity(*output.User)
+
+	if identity == nil {
+		return nil, fmt.Errorf("identity not found: %s", userName)
+	}
+
+	return identity, nil
+}
+
+func (p *awsProvider) findIdentityCenterUserIdentity(
+	ctx context.Context,
+	value string,
+	attributes ...string,
+) (*models.Identity, error) {
+
+	identityStoreId, err := p.getIdentityStoreId(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attribute := range attributes {
+
+		output, err := p.identityStoreClient.ListUsers(ctx, &identitystore.ListUsersInput{
+			IdentityStoreId: aws.String(identityStoreId),
+			Filters: []identitystoretypes.Filter{
+				{
+					AttributePath:  aws.String(attribute),
+					AttributeValue: aws.String(value),
+				},
+			},
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to search Identity Center users by %s: %w", attribute, err)
+		}
+
+		for _, user := range output.Users {
+			if identity := newIdentityCenterUserIdentity(user); identity != nil {
+				return identity, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("identity not found: %s", value)
+}
+
+// getIdentityStoreId returns the configured identity_store_id or the
+// identity store of the first Identity Center instance
+func (p *awsProvider) getIdentityStoreId(ctx context.Context) (string, error) {
+
+	if identityStoreId, found := p.GetConfig().GetString("identity_store_id"); found && len(identityStoreId) > 0 {
+		return identityStoreId, nil
+	}
+
+	resp, err := p.ssoAdminService.ListInstances(ctx, &ssoadmin.ListInstancesInput{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list SSO instances: %w", err)
+	}
+
+	if len(resp.Instances) == 0 {
+		return "", errNoIdentityCenter
+	}
+
+	identityStoreId := resp.Instances[0].IdentityStoreId
+	if identityStoreId == nil || len(*identityStoreId) == 0 {
+		return "", fmt.Errorf("identity store ID not found in SSO instance")
+	}
+
+	return *identityStoreId, nil
+}
+
+// iamUserNameFromArn returns the user name from an IAM user ARN e.g.
+// arn:aws:iam::123456789012:user/division/alice
+func iamUserNameFromArn(arn string) (string, error) {
+
+	parts := strings.SplitN(arn, ":", 6)
+
+	if len(parts) != 6 || parts[2] != "iam" || !strings.HasPrefix(parts[5], "user/") {
+		return "", fmt.Errorf("invalid IAM user ARN: %s", arn)
+	}
+
+	path := strings.TrimPrefix(parts[5], "user/")
+	userName := path[strings.LastIndex(path, "/")+1:]
+
+	if len(userName) == 0 {
+		return "", fmt.Errorf("invalid IAM user ARN: %s", arn)
+	}
+
+	return userName, nil
+}
+
+func newIamUserIdentity(user iamtypes.User) *models.Identity {
+
+	var userId string
+	var userName string
+
+	if user.Arn != nil && len(*user.Arn) > 0 {
+		userId = *user.Arn
+	} else if user.UserId != nil && len(*user.UserId) > 0 {
+		userId = *user.UserId
+	} else {
+		return nil
+	}
+
+	if user.UserName != nil && len(*user.UserName) > 0 {
+		userName = *user.UserName
+	}
+
+	// IAM users don't have emails
+	return &models.Identity{
+		ID:    userId,
+		Label: userName,
+		User: &models.User{
+			ID:       userId,
+			Username: userName,
+			Name:     userName,
+			Source:   IdentitySourceIam,
+		},
+	}
+}
+
+func newIdentityCenterUserIdentity(user identitystoretypes.User) *models.Identity {
+
+	var userId string
+	var userName string
+	var email string
+
+	if user.UserId != nil && len(*user.UserId) > 0 {
+		userId = *user.UserId
+	} else {
+		return nil
+	}
+
+	if user.UserName != nil && len(*user.UserName) > 0 {
+		userName = *user.UserName
+	}
+
+	// Prefer the primary email
+	for _, userEmail := range user.Emails {
+		if userEmail.Value == nil {
+			continue
+		}
+		if len(email) == 0 || userEmail.Primary {
+			email = *userEmail.Value
+		}
+		if userEmail.Primary {
+			break
+		}
+	}
+
+	displayName := userName
+	if user.DisplayName != nil {
+		displayName = *user.DisplayName
+	}
+
+	return &models.Identity{
+		ID:    userId,
+		Label: displayName,
+		User: &models.User{
+			ID:       userId,
+			Username: userName,
+			Email:    email,
+			Name:     displayName,
+			Source:   IdentitySourceIdentityCenter,
+		},
+	}
+}
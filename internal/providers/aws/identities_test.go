@@ -0,0 +1,73 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	identitystoretypes "github.com/aws/aws-sdk-go-v2/service/identitystore/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIamUserNameFromArn(t *testing.T) {
+	userName, err := iamUserNameFromArn("arn:aws:iam::123456789012:user/alice")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", userName)
+
+	userName, err = iamUserNameFromArn("arn:aws:iam::123456789012:user/division/team/bob")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", userName)
+
+	_, err = iamUserNameFromArn("arn:aws:iam::123456789012:role/admin")
+	assert.Error(t, err)
+
+	_, err = iamUserNameFromArn("arn:aws:s3:::bucket")
+	assert.Error(t, err)
+
+	_, err = iamUserNameFromArn("arn:aws:iam::123456789012:user/")
+	assert.Error(t, err)
+}
+
+func TestNewIamUserIdentity(t *testing.T) {
+	identity := newIamUserIdentity(iamtypes.User{
+		Arn:      aws.String("arn:aws:iam::123456789012:user/alice"),
+		UserId:   aws.String("AIDAEXAMPLE"),
+		UserName: aws.String("alice"),
+	})
+	require.NotNil(t, identity)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/alice", identity.ID)
+	assert.Equal(t, "alice", identity.User.Username)
+	assert.Equal(t, IdentitySourceIam, identity.User.Source)
+
+	assert.Nil(t, newIamUserIdentity(iamtypes.User{UserName: aws.String("nobody")}))
+}
+
+func TestNewIdentityCenterUserIdentity(t *testing.T) {
+	identity := newIdentityCenterUserIdentity(identitystoretypes.User{
+		UserId:      aws.String("9067-user"),
+		UserName:    aws.String("bob"),
+		DisplayName: aws.String("Bob Smith"),
+		Emails: []identitystoretypes.Email{
+			{Value: aws.String("bob@personal.example.com")},
+			{Value: aws.String("bob@example.com"), Primary: true},
+		},
+	})
+	require.NotNil(t, identity)
+	assert.Equal(t, "9067-user", identity.ID)
+	assert.Equal(t, "Bob Smith", identity.Label)
+	assert.Equal(t, "bob@example.com", identity.User.Email)
+	assert.Equal(t, IdentitySourceIdentityCenter, identity.User.Source)
+
+	// Falls back to the user name and first email
+	identity = newIdentityCenterUserIdentity(identitystoretypes.User{
+		UserId:   aws.String("1234-user"),
+		UserName: aws.String("carol"),
+		Emails:   []identitystoretypes.Email{{Value: aws.String("carol@example.com")}},
+	})
+	require.NotNil(t, identity)
+	assert.Equal(t, "carol", identity.Label)
+	assert.Equal(t, "carol@example.com", identity.User.Email)
+
+	assert.Nil(t, newIdentityCenterUserIdentity(identitystoretypes.User{UserName: aws.String("nobody")}))
+}
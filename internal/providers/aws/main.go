@@ -3,6 +3,8 @@ package aws
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 
@@ -27,18 +29,59 @@ type awsProvider struct {
 	*models.BaseProvider
 	region              string
 	accountID           string
+	sdkConfig           aws.Config
 	service             *iam.Client
 	stsService          *sts.Client
 	ssoAdminService     *ssoadmin.Client
 	identityStoreClient *identitystore.Client
+	cloudTrail          *cloudTrailClient
+	resources           *resourceCache
 }
 
-func (p *awsProvider) Initialize(identifier string, provider models.Provider) error {
+// ValidateConfig checks static credentials are complete and the account
+// ID, if set, is valid
+func (p *awsProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(AwsProviderName)
+	validation.RequireTogether(config, "access_key_id", "secret_access_key")
+
+	if ttl, found := config.GetString("resources_cache_ttl"); found && len(ttl) > 0 {
+		if _, err := common.ValidateDuration(ttl); err != nil {
+			validation.Add("resources_cache_ttl", "%s", err)
+		}
+	}
+
+	resourceTypes, _ := config.GetStringSlice("resource_types")
+
+	for _, resourceType := range resourceTypes {
+		if !slices.Contains(optionalResourceTypes, strings.ToLower(resourceType)) {
+			validation.Add("resource_types", "must be one of %s, got %q",
+				strings.Join(optionalResourceTypes, ", "), resourceType)
+		}
+	}
+
+	if accountId, found := config.GetString("account_id"); found && len(accountId) > 0 {
+		if len(accountId) != 12 || !common.IsAllDigits(accountId) {
+			validation.Add("account_id", "must be 12 digits, got %q", accountId)
+		}
+	}
+
+	return validation.ErrorOrNil()
+}
+
+// GetSecretKeys marks the external ID shared with the trusting account as
+// a secret
+func (p *awsProvider) GetSecretKeys() []string {
+	return []string{"external_id"}
+}
+
+func (p *awsProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
 		provider,
 		models.ProviderCapabilityRBAC,
 		models.ProviderCapabilityIdentities,
+		models.ProviderCapabilityResourceDiscovery,
 	)
 
 	// Right lets figure out how to initialize the AWS SDK
@@ -51,13 +94,21 @@ func (p *awsProvider) Initialize(identifier string, provider models.Provider) er
 	}
 
 	p.region = awsConfig.GetStringWithDefault("region", "us-east-1")
+	p.sdkConfig = sdkConfig.Config
 	p.service = iam.NewFromConfig(sdkConfig.Config)
 	p.stsService = sts.NewFromConfig(sdkConfig.Config)
 	p.ssoAdminService = ssoadmin.NewFromConfig(sdkConfig.Config)
 	p.identityStoreClient = identitystore.NewFromConfig(sdkConfig.Config)
+	p.cloudTrail = &cloudTrailClient{config: sdkConfig.Config}
+
+	p.resources, err = newResourceCache(awsConfig)
+
+	if err != nil {
+		return err
+	}
 
 	// Set the account ID from config or retrieve it via STS
-	err = p.GetAccountId(awsConfig)
+	err = p.GetAccountId(ctx, awsConfig)
 
 	if err != nil {
 		return fmt.Errorf("failed to set account ID: %w", err)
@@ -71,6 +122,29 @@ func (p *awsProvider) Initialize(identifier string, provider models.Provider) er
 	return nil
 }
 
+// TestConnection performs a read-only list call for the capability
+func (p *awsProvider) TestConnection(ctx context.Context, capability models.ProviderCapability) error {
+	switch capability {
+	case models.ProviderCapabilityRBAC:
+		_, err := p.service.ListRoles(ctx, &iam.ListRolesInput{
+			MaxItems: aws.Int32(1),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list IAM roles: %w", err)
+		}
+		return nil
+	case models.ProviderCapabilityIdentities:
+		_, err := p.service.ListUsers(ctx, &iam.ListUsersInput{
+			MaxItems: aws.Int32(1),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list IAM users: %w", err)
+		}
+		return nil
+	}
+	return models.ErrNotImplemented
+}
+
 func CreateAwsConfig(awsConfig *models.BasicConfig) (*AwsConfigurationProvider, error) {
 
 	awsOptions := []func(*config.LoadOptions) error{}
@@ -81,20 +155,20 @@ func CreateAwsConfig(awsConfig *models.BasicConfig) (*AwsConfigurationProvider,
 	awsAccountSecret, foundAccountSecret := awsConfig.GetString("secret_access_key")
 
 	if foundProfile {
-		logrus.Info("Using shared AWS config profile")
+		logrus.Debug("Using shared AWS config profile")
 		awsOptions = append(awsOptions, config.WithSharedConfigProfile(awsProfile))
 	} else if foundAccountId && foundAccountSecret {
-		logrus.Info("Using static AWS credentials")
+		logrus.Debug("Using static AWS credentials")
 		awsOptions = append(awsOptions, config.WithCredentialsProvider(
 			credentials.NewStaticCredentialsProvider(awsAccountId, awsAccountSecret, ""),
 		))
 	} else {
-		logrus.Info("No AWS credentials provided, using IAM role or default profile")
+		logrus.Debug("No AWS credentials provided, using IAM role or default profile")
 	}
 
 	configRegion := awsConfig.GetStringWithDefault("region", "us-east-1")
 
-	logrus.WithField("region", configRegion).Info("Setting AWS region")
+	logrus.WithField("region", configRegion).Debug("Setting AWS region")
 
 	awsOptions = append(awsOptions,
 		config.WithRegion(
@@ -103,12 +177,12 @@ func CreateAwsConfig(awsConfig *models.BasicConfig) (*AwsConfigurationProvider,
 
 	// Support custom endpoint for testing (e.g., LocalStack)
 	if endpoint, found := awsConfig.GetString("endpoint"); found {
-		logrus.WithField("endpoint", endpoint).Info("Using custom AWS endpoint")
+		logrus.WithField("endpoint", endpoint).Debug("Using custom AWS endpoint")
 		awsOptions = append(awsOptions, config.WithBaseEndpoint(endpoint))
 	}
 
 	if imdsDisable, found := awsConfig.GetBool("imds_disable"); found && imdsDisable {
-		logrus.Info("Disabling IMDSv2 for AWS credentials")
+		logrus.Debug("Disabling IMDSv2 for AWS credentials")
 		awsOptions = append(awsOptions, config.WithEC2IMDSClientEnableState(imds.ClientDisabled))
 	}
 
@@ -124,6 +198,9 @@ func CreateAwsConfig(awsConfig *models.BasicConfig) (*AwsConfigurationProvider,
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
+	// Support cross-account access by assuming one or more roles
+	awsSdkConfig = withAssumeRoleChain(awsSdkConfig, awsConfig)
+
 	return &AwsConfigurationProvider{
 		Config: awsSdkConfig,
 	}, nil
@@ -139,15 +216,15 @@ func (p *awsProvider) GetRegion() string {
 }
 
 // GetAccountId sets the AWS account ID from config or retrieves it via STS
-func (p *awsProvider) GetAccountId(config *models.BasicConfig) error {
+func (p *awsProvider) GetAccountId(ctx context.Context, config *models.BasicConfig) error {
 
-	ctx := context.Background()
+	log := p.GetLogger(ctx)
 
 	accountId, found := config.GetString("account_id")
 
 	if found && len(accountId) > 0 {
 		p.accountID = accountId
-		logrus.WithField("account_id", p.accountID).Info("Using configured AWS account ID")
+		log.WithField("account_id", p.accountID).Debug("Using configured AWS account ID")
 		return nil
 	}
 
@@ -164,7 +241,7 @@ func (p *awsProvider) GetAccountId(config *models.BasicConfig) error {
 	}
 
 	p.accountID = *foundAccount
-	logrus.WithField("account_id", p.accountID).Info("Retrieved account ID via STS")
+	log.WithField("account_id", p.accountID).Debug("Retrieved account ID via STS")
 	return nil
 }
 
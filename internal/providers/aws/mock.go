@@ -19,7 +19,7 @@ func NewMockAwsProvider() *awsProviderMock {
 	}
 }
 
-func (p *awsProviderMock) Initialize(identifier string, provider models.Provider) error {
+func (p *awsProviderMock) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	// Initialize the embedded awsProvider struct first
 	p.awsProvider = &awsProvider{}
 	p.awsProvider.BaseProvider = models.NewBaseProvider(
@@ -29,7 +29,7 @@ func (p *awsProviderMock) Initialize(identifier string, provider models.Provider
 	)
 
 	// Load AWS Permissions and Roles from shared singleton
-	if err := p.Synchronize(context.Background(), nil, nil); err != nil {
+	if err := p.Synchronize(ctx, nil, nil); err != nil {
 		return err
 	}
 
@@ -43,3 +43,20 @@ func (p *awsProviderMock) Synchronize(
 ) error {
 	return PreSynchronizeActivities(ctx, temporalService, p)
 }
+
+// ValidateConfig accepts any config as the mock doesn't connect to AWS.
+// Providers are created with reflection so the embedded provider is nil
+// until Initialize.
+func (p *awsProviderMock) ValidateConfig(config *models.BasicConfig) error {
+	return nil
+}
+
+// TestConnection skips the smoke check as the mock has no AWS clients
+func (p *awsProviderMock) TestConnection(ctx context.Context, capability models.ProviderCapability) error {
+	return models.ErrNotImplemented
+}
+
+// ValidateAgentPermissions skips the check as the mock has no AWS clients
+func (p *awsProviderMock) ValidateAgentPermissions(ctx context.Context) ([]models.AgentPermission, error) {
+	return nil, models.ErrNotImplemented
+}
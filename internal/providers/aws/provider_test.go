@@ -29,7 +29,7 @@ func TestAWSProviderPermissions(t *testing.T) {
 
 	// Initialize the provider
 	provider := NewMockAwsProvider()
-	err := provider.Initialize("aws", testConfig)
+	err := provider.Initialize(context.Background(), "aws", testConfig)
 	require.NoError(t, err, "Failed to initialize AWS provider")
 
 	ctx := context.Background()
@@ -132,7 +132,7 @@ func TestAWSProviderRoles(t *testing.T) {
 
 	// Initialize the provider
 	provider := NewMockAwsProvider()
-	err := provider.Initialize("aws", testConfig)
+	err := provider.Initialize(context.Background(), "aws", testConfig)
 	require.NoError(t, err, "Failed to initialize AWS provider")
 
 	ctx := context.Background()
@@ -19,7 +19,9 @@ func (p *awsProvider) AuthorizeRole(
 		return nil, fmt.Errorf("user and role must be provided to authorize aws role")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	log := p.GetLogger(ctx)
+
+	log.WithFields(logrus.Fields{
 		"req_user_email":    req.User.Email,
 		"req_user_source":   req.User.Source,
 		"req_user_username": req.User.Username,
@@ -27,12 +29,15 @@ func (p *awsProvider) AuthorizeRole(
 
 	// Determine if we should use IAM Identity Center or traditional IAM
 	// For now, detect based on the user's source or configuration
-	useIdentityCenter := p.shouldUseIdentityCenter(req.GetUser())
+	useIdentityCenter := p.shouldUseIdentityCenter(log, req.GetUser())
+
+	// Act on behalf of the requester so CloudTrail records who the grant is for
+	scoped := p.withAuditContext(ctx, req.GetAudit())
 
 	if useIdentityCenter {
-		return p.authorizeRoleIdentityCenter(ctx, req)
+		return scoped.authorizeRoleIdentityCenter(ctx, req)
 	} else {
-		return p.authorizeRoleTraditionalIAM(ctx, req)
+		return scoped.authorizeRoleTraditionalIAM(ctx, req)
 	}
 }
 
@@ -48,18 +53,25 @@ func (p *awsProvider) RevokeRole(
 
 	user := req.GetUser()
 	role := req.GetRole()
+	grant := req.GetGrantRef()
 
 	// Determine if we should use IAM Identity Center or traditional IAM
-	useIdentityCenter := p.shouldUseIdentityCenter(user)
+	useIdentityCenter := p.shouldUseIdentityCenter(p.GetLogger(ctx), user)
+
+	if grant.HasIDs() {
+		useIdentityCenter = grant.Kind == GrantKindAccountAssignment
+	}
+
+	scoped := p.withAuditContext(ctx, req.GetAudit())
 
 	if useIdentityCenter {
-		err := p.revokeRoleIdentityCenter(ctx, user, role)
+		err := scoped.revokeRoleIdentityCenter(ctx, user, role, grant)
 		if err != nil {
 			return nil, fmt.Errorf("failed to revoke Identity Center role: %w", err)
 		}
 		return nil, nil
 	} else {
-		return p.revokeRoleTraditionalIAM(ctx, user, role)
+		return scoped.revokeRoleTraditionalIAM(ctx, user, role, grant)
 	}
 }
 
@@ -75,7 +87,7 @@ func (p *awsProvider) GetAuthorizedAccessUrl(
 }
 
 // shouldUseIdentityCenter determines if we should use Identity Center based on user context
-func (p *awsProvider) shouldUseIdentityCenter(user *models.User) bool {
+func (p *awsProvider) shouldUseIdentityCenter(log *logrus.Entry, user *models.User) bool {
 	// For now, assume Identity Center if user source suggests SSO
 	// You could also check for specific configuration flags
 	useIC := true
@@ -86,12 +98,12 @@ func (p *awsProvider) shouldUseIdentityCenter(user *models.User) bool {
 		// If the source is 'iam' or empty (default to traditional IAM)
 		useIC = false
 	}
-	logrus.WithFields(logrus.Fields{
+	log.WithFields(logrus.Fields{
 		"user_source":         user.Source,
 		"user_email":          user.Email,
 		"user_username":       user.Username,
 		"use_identity_center": useIC,
-	}).Info("Determining whether to use Identity Center")
+	}).Debug("Determining whether to use Identity Center")
 	return useIC
 }
 
@@ -103,6 +115,7 @@ type PolicyDocument struct {
 
 // Statement represents a policy statement
 type Statement struct {
+	Sid       string `json:"Sid,omitempty"` // Set to the grant reference for statements added by a grant
 	Effect    string `json:"Effect"`
 	Action    any    `json:"Action,omitempty"`    // Can be string or []string
 	Resource  any    `json:"Resource,omitempty"`  // Can be string or []string
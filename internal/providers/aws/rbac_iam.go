@@ -3,7 +3,9 @@ package aws
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -13,6 +15,10 @@ import (
 	"github.com/thand-io/agent/internal/models"
 )
 
+// GrantKindTrustPolicyStatement is the grant kind for IAM users, the grant
+// IDs are the role names and the statement ID is the grant reference
+const GrantKindTrustPolicyStatement = "trust_policy_statement"
+
 // authorizeRoleTraditionalIAM handles role authorization for traditional IAM users
 func (p *awsProvider) authorizeRoleTraditionalIAM(
 	ctx context.Context,
@@ -38,17 +44,41 @@ func (p *awsProvider) authorizeRoleTraditionalIAM(
 		return nil, fmt.Errorf("failed to attach policies to role: %w", err)
 	}
 
+	// Check the organization allows what the role grants before binding
+	err = p.checkSCPCompliance(ctx, aws.ToString(existingRole.Arn))
+	if err != nil {
+		return nil, err
+	}
+
+	grant := models.NewGrantRef(p.GetIdentifier(), GrantKindTrustPolicyStatement, req.RoleRequest)
+
 	// Bind the user to the role (assuming user will assume this role)
-	err = p.bindUserToRole(ctx, user, existingRole.RoleName)
+	err = p.bindUserToRole(ctx, user, existingRole.RoleName, grant.Reference)
 	if err != nil {
 		return nil, fmt.Errorf("failed to bind user to role: %w", err)
 	}
 
-	return nil, nil
+	grant.AddID(*existingRole.RoleName)
+
+	return &models.AuthorizeRoleResponse{
+		Roles:    []string{*existingRole.RoleName},
+		GrantRef: grant,
+	}, nil
 }
 
 // revokeRoleTraditionalIAM handles role revocation for traditional IAM users
-func (p *awsProvider) revokeRoleTraditionalIAM(ctx context.Context, user *models.User, role *models.Role) (*models.RevokeRoleResponse, error) {
+func (p *awsProvider) revokeRoleTraditionalIAM(ctx context.Context, user *models.User, role *models.Role, grant *models.GrantRef) (*models.RevokeRoleResponse, error) {
+
+	// Remove exactly the trust policy statements recorded by the grant
+	if grant.HasIDs() {
+		for _, roleName := range grant.IDs {
+			err := p.removeTrustPolicyStatement(ctx, aws.String(roleName), grant.Reference)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unbind user from role: %w", err)
+			}
+		}
+		return nil, nil
+	}
 
 	// Check if the role exists
 	existingRole, err := p.getRole(ctx, role)
@@ -158,14 +188,13 @@ func (p *awsProvider) attachPoliciesToRole(ctx context.Context, roleName *string
 	return nil
 }
 
-// bindUserToRole creates or updates the assume role policy to allow the user to assume the role
-func (p *awsProvider) bindUserToRole(ctx context.Context, user *models.User, roleName *string) error {
+// bindUserToRole adds a statement to the assume role policy allowing the user
+// to assume the role. The statement ID is the grant reference so a retry finds
+// the statement it already added, and revoke removes only that statement.
+func (p *awsProvider) bindUserToRole(ctx context.Context, user *models.User, roleName *string, reference string) error {
 	// Use the cached account ID
 	accountID := p.GetAccountID()
 
-	// Create a basic assume role policy that allows the user to assume the role
-	var assumeRolePolicy PolicyDocument
-
 	// Determine the username to use for the IAM user ARN
 	username := p.getUsernameForIAM(user)
 
@@ -173,58 +202,137 @@ func (p *awsProvider) bindUserToRole(ctx context.Context, user *models.User, rol
 		return fmt.Errorf("failed to determine username for user")
 	}
 
+	currentPolicy, err := p.getAssumeRolePolicy(ctx, roleName)
+	if err != nil {
+		return err
+	}
+
+	// Keep the statements of other grants, replacing the statements added
+	// when the role was created or the last grant was revoked
+	statements := []Statement{}
+	for _, stmt := range currentPolicy.Statement {
+		if stmt.Sid == reference {
+			// The grant already added its statement
+			return nil
+		}
+		if strings.HasPrefix(stmt.Sid, models.GrantReferencePrefix) {
+			statements = append(statements, stmt)
+		}
+	}
+
 	// Create policy allowing specific user with proper account ID
-	assumeRolePolicy = PolicyDocument{
+	assumeRolePolicy := PolicyDocument{
 		Version: "2012-10-17",
-		Statement: []Statement{
+		Statement: append(statements, Statement{
+			Sid:    reference,
+			Effect: "Allow",
+			Principal: map[string]string{
+				"AWS": fmt.Sprintf("arn:aws:iam::%s:user/%s", accountID, username),
+			},
+			Action: "sts:AssumeRole",
+		}),
+	}
+
+	return p.updateAssumeRolePolicy(ctx, roleName, assumeRolePolicy)
+}
+
+// removeTrustPolicyStatement removes the statement added for the grant from
+// the assume role policy. A statement that no longer exists is considered
+// already revoked.
+func (p *awsProvider) removeTrustPolicyStatement(ctx context.Context, roleName *string, reference string) error {
+
+	currentPolicy, err := p.getAssumeRolePolicy(ctx, roleName)
+	if err != nil {
+		var notFound *types.NoSuchEntityException
+		if errors.As(err, &notFound) {
+			return nil
+		}
+		return err
+	}
+
+	statements := []Statement{}
+	for _, stmt := range currentPolicy.Statement {
+		if stmt.Sid != reference {
+			statements = append(statements, stmt)
+		}
+	}
+
+	if len(statements) == len(currentPolicy.Statement) {
+		return nil
+	}
+
+	return p.updateAssumeRolePolicy(ctx, roleName, PolicyDocument{
+		Version:   "2012-10-17",
+		Statement: statements,
+	})
+}
+
+// getAssumeRolePolicy returns the role's current assume role policy
+func (p *awsProvider) getAssumeRolePolicy(ctx context.Context, roleName *string) (*PolicyDocument, error) {
+
+	roleOutput, err := p.service.GetRole(ctx, &iam.GetRoleInput{
+		RoleName: roleName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role %s: %w", *roleName, err)
+	}
+
+	var currentPolicy PolicyDocument
+	if roleOutput.Role.AssumeRolePolicyDocument != nil {
+		// IAM returns policy documents URL encoded
+		document, err := url.QueryUnescape(*roleOutput.Role.AssumeRolePolicyDocument)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode assume role policy: %w", err)
+		}
+		if err := json.Unmarshal([]byte(document), &currentPolicy); err != nil {
+			return nil, fmt.Errorf("failed to parse assume role policy: %w", err)
+		}
+	}
+
+	return &currentPolicy, nil
+}
+
+// updateAssumeRolePolicy replaces the role's assume role policy. A policy
+// without statements denies everyone so the role is never left open.
+func (p *awsProvider) updateAssumeRolePolicy(ctx context.Context, roleName *string, policy PolicyDocument) error {
+
+	if len(policy.Statement) == 0 {
+		policy.Statement = []Statement{
 			{
-				Effect: "Allow",
+				Effect: "Deny",
 				Principal: map[string]string{
-					"AWS": fmt.Sprintf("arn:aws:iam::%s:user/%s", accountID, username),
+					"AWS": "*",
 				},
 				Action: "sts:AssumeRole",
 			},
-		},
+		}
 	}
 
-	assumeRolePolicyJSON, err := json.Marshal(assumeRolePolicy)
+	policyJSON, err := json.Marshal(policy)
 	if err != nil {
 		return fmt.Errorf("failed to marshal assume role policy: %w", err)
 	}
 
-	// Update the role's assume role policy
-	updateInput := &iam.UpdateAssumeRolePolicyInput{
+	_, err = p.service.UpdateAssumeRolePolicy(ctx, &iam.UpdateAssumeRolePolicyInput{
 		RoleName:       roleName,
-		PolicyDocument: aws.String(string(assumeRolePolicyJSON)),
-	}
-
-	_, err = p.service.UpdateAssumeRolePolicy(ctx, updateInput)
+		PolicyDocument: aws.String(string(policyJSON)),
+	})
 	if err != nil {
-		return fmt.Errorf("failed to update assume role policy: %w", err)
+		return fmt.Errorf("failed to update assume role policy for role %s: %w", *roleName, err)
 	}
 
 	return nil
 }
 
-// unbindUserFromRole removes the user from the assume role policy
+// unbindUserFromRole removes the user from the assume role policy. Used for
+// grants made before grant references were recorded.
 func (p *awsProvider) unbindUserFromRole(ctx context.Context, user *models.User, roleName *string) error {
 	// Use the cached account ID
 	accountID := p.GetAccountID()
 
-	// Get current assume role policy
-	roleOutput, err := p.service.GetRole(ctx, &iam.GetRoleInput{
-		RoleName: roleName,
-	})
+	currentPolicy, err := p.getAssumeRolePolicy(ctx, roleName)
 	if err != nil {
-		return fmt.Errorf("failed to get role %s: %w", *roleName, err)
-	}
-
-	// Parse the current policy document
-	var currentPolicy PolicyDocument
-	if roleOutput.Role.AssumeRolePolicyDocument != nil {
-		if err := json.Unmarshal([]byte(*roleOutput.Role.AssumeRolePolicyDocument), &currentPolicy); err != nil {
-			return fmt.Errorf("failed to parse assume role policy: %w", err)
-		}
+		return err
 	}
 
 	// Extract username from email
@@ -251,40 +359,11 @@ func (p *awsProvider) unbindUserFromRole(ctx context.Context, user *models.User,
 		newStatements = append(newStatements, stmt)
 	}
 
-	// If no statements remain, create a minimal deny-all policy to prevent open access
-	if len(newStatements) == 0 {
-		newStatements = []Statement{
-			{
-				Effect: "Deny",
-				Principal: map[string]string{
-					"AWS": "*",
-				},
-				Action: "sts:AssumeRole",
-			},
-		}
-	}
-
-	// Create new policy document
-	newPolicy := PolicyDocument{
+	// If no statements remain, a deny-all policy prevents open access
+	return p.updateAssumeRolePolicy(ctx, roleName, PolicyDocument{
 		Version:   "2012-10-17",
 		Statement: newStatements,
-	}
-
-	// Update the assume role policy
-	newPolicyJSON, err := json.Marshal(newPolicy)
-	if err != nil {
-		return fmt.Errorf("failed to marshal new policy: %w", err)
-	}
-
-	_, err = p.service.UpdateAssumeRolePolicy(ctx, &iam.UpdateAssumeRolePolicyInput{
-		RoleName:       roleName,
-		PolicyDocument: aws.String(string(newPolicyJSON)),
 	})
-	if err != nil {
-		return fmt.Errorf("failed to update assume role policy for role %s: %w", *roleName, err)
-	}
-
-	return nil
 }
 
 // getUsernameForIAM determines the appropriate username for AWS IAM user ARN
@@ -3,6 +3,7 @@ package aws
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -15,6 +16,10 @@ import (
 	"github.com/thand-io/agent/internal/models"
 )
 
+// GrantKindAccountAssignment is the grant kind for Identity Center users,
+// the grant IDs are the permission set ARNs
+const GrantKindAccountAssignment = "account_assignment"
+
 // authorizeRoleIdentityCenter handles role authorization for Identity Center users
 func (p *awsProvider) authorizeRoleIdentityCenter(
 	ctx context.Context,
@@ -48,6 +53,14 @@ func (p *awsProvider) authorizeRoleIdentityCenter(
 		return nil, fmt.Errorf("failed to create account assignment: %w", err)
 	}
 
+	// Account assignments have no ID of their own, they are identified by
+	// the permission set, principal and account
+	grant := models.NewGrantRef(p.GetIdentifier(), GrantKindAccountAssignment, req.RoleRequest)
+	grant.AddID(permissionSetArn)
+	grant.SetAttribute("instance_arn", instanceArn)
+	grant.SetAttribute("principal_id", principalId)
+	grant.SetAttribute("account_id", p.GetAccountID())
+
 	return &models.AuthorizeRoleResponse{
 		Metadata: map[string]any{
 			"instanceArn":      instanceArn,
@@ -55,6 +68,7 @@ func (p *awsProvider) authorizeRoleIdentityCenter(
 			"principalId":      principalId,
 			"accountId":        p.GetAccountID(),
 		},
+		GrantRef: grant,
 	}, nil
 }
 
@@ -183,13 +197,15 @@ func (p *awsProvider) attachPermissionsToPermissionSet(ctx context.Context, inst
 
 // attachManagedPoliciesToPermissionSet attaches AWS managed policies to the permission set
 func (p *awsProvider) attachManagedPoliciesToPermissionSet(ctx context.Context, instanceArn, permissionSetArn string, inherits []string) error {
+	log := p.GetLogger(ctx)
+
 	for _, arnOrPolicy := range inherits {
 		// Handle different types of ARNs that could be in role.inherits
 		if strings.HasPrefix(arnOrPolicy, "arn:aws:iam::") {
 			if strings.Contains(arnOrPolicy, ":role/") {
 				// This is a role ARN - we cannot directly attach roles to permission sets
 				// Log a warning and skip this entry
-				logrus.WithField("roleArn", arnOrPolicy).Warn("Cannot attach IAM role directly to permission set - skipping. Consider using managed policy ARNs instead.")
+				log.WithField("roleArn", arnOrPolicy).Warn("Cannot attach IAM role directly to permission set - skipping. Consider using managed policy ARNs instead.")
 				continue
 			} else if strings.Contains(arnOrPolicy, ":policy/") {
 				// This is a policy ARN - proceed with attachment
@@ -215,6 +231,8 @@ func (p *awsProvider) attachManagedPoliciesToPermissionSet(ctx context.Context,
 
 // attachPolicyToPermissionSet attaches a single policy ARN to the permission set
 func (p *awsProvider) attachPolicyToPermissionSet(ctx context.Context, instanceArn, permissionSetArn, policyArn string) error {
+	log := p.GetLogger(ctx)
+
 	// Validate that the ARN looks like a valid AWS policy ARN
 	if !strings.HasPrefix(policyArn, "arn:aws:iam::") || !strings.Contains(policyArn, ":policy/") {
 		return fmt.Errorf("invalid AWS policy ARN format: %s", policyArn)
@@ -229,7 +247,7 @@ func (p *awsProvider) attachPolicyToPermissionSet(ctx context.Context, instanceA
 		}
 
 		if isAlreadyAttached {
-			logrus.WithFields(logrus.Fields{
+			log.WithFields(logrus.Fields{
 				"policyArn":        policyArn,
 				"permissionSetArn": permissionSetArn,
 			}).Info("AWS managed policy is already attached to permission set - skipping")
@@ -245,7 +263,7 @@ func (p *awsProvider) attachPolicyToPermissionSet(ctx context.Context, instanceA
 			return fmt.Errorf("failed to attach AWS managed policy %s: %w", policyArn, err)
 		}
 
-		logrus.WithFields(logrus.Fields{
+		log.WithFields(logrus.Fields{
 			"policyArn":        policyArn,
 			"permissionSetArn": permissionSetArn,
 		}).Info("Successfully attached AWS managed policy to permission set")
@@ -269,7 +287,7 @@ func (p *awsProvider) attachPolicyToPermissionSet(ctx context.Context, instanceA
 		}
 
 		if isAlreadyAttached {
-			logrus.WithFields(logrus.Fields{
+			log.WithFields(logrus.Fields{
 				"policyName":       policyName,
 				"accountId":        accountId,
 				"permissionSetArn": permissionSetArn,
@@ -289,7 +307,7 @@ func (p *awsProvider) attachPolicyToPermissionSet(ctx context.Context, instanceA
 			return fmt.Errorf("failed to attach customer managed policy %s (account: %s): %w", policyName, accountId, err)
 		}
 
-		logrus.WithFields(logrus.Fields{
+		log.WithFields(logrus.Fields{
 			"policyName":       policyName,
 			"accountId":        accountId,
 			"permissionSetArn": permissionSetArn,
@@ -344,24 +362,15 @@ func (p *awsProvider) isCustomerManagedPolicyAttached(ctx context.Context, insta
 // findIdentityCenterUser finds a user in Identity Center by email
 func (p *awsProvider) findIdentityCenterUser(ctx context.Context, email string) (string, error) {
 
-	// First, get the identity store ID from the SSO instance
-	resp, err := p.ssoAdminService.ListInstances(ctx, &ssoadmin.ListInstancesInput{})
+	// First, get the identity store ID from the config or SSO instance
+	identityStoreId, err := p.getIdentityStoreId(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to list SSO instances: %w", err)
-	}
-
-	if len(resp.Instances) == 0 {
-		return "", fmt.Errorf("no SSO instances found")
-	}
-
-	identityStoreId := resp.Instances[0].IdentityStoreId
-	if identityStoreId == nil {
-		return "", fmt.Errorf("identity store ID not found in SSO instance")
+		return "", err
 	}
 
 	// Search for user by email
 	usersResp, err := p.identityStoreClient.ListUsers(ctx, &identitystore.ListUsersInput{
-		IdentityStoreId: identityStoreId,
+		IdentityStoreId: aws.String(identityStoreId),
 		Filters: []identitystoretypes.Filter{
 			{
 				AttributePath:  aws.String("userName"),
@@ -376,7 +385,7 @@ func (p *awsProvider) findIdentityCenterUser(ctx context.Context, email string)
 	if len(usersResp.Users) == 0 {
 		// Try searching by email attribute as well
 		usersResp, err = p.identityStoreClient.ListUsers(ctx, &identitystore.ListUsersInput{
-			IdentityStoreId: identityStoreId,
+			IdentityStoreId: aws.String(identityStoreId),
 			Filters: []identitystoretypes.Filter{
 				{
 					AttributePath:  aws.String("emails.value"),
@@ -398,6 +407,8 @@ func (p *awsProvider) findIdentityCenterUser(ctx context.Context, email string)
 // createAccountAssignment assigns a permission set to a user for the current account
 func (p *awsProvider) createAccountAssignment(ctx context.Context, instanceArn, permissionSetArn, principalId string) error {
 
+	log := p.GetLogger(ctx)
+
 	assignmentOutput, err := p.ssoAdminService.CreateAccountAssignment(ctx, &ssoadmin.CreateAccountAssignmentInput{
 		InstanceArn:      aws.String(instanceArn),
 		PermissionSetArn: aws.String(permissionSetArn),
@@ -415,7 +426,7 @@ func (p *awsProvider) createAccountAssignment(ctx context.Context, instanceArn,
 		return fmt.Errorf("failed to create account assignment: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	log.WithFields(logrus.Fields{
 		"principalId": *assignmentOutput.AccountAssignmentCreationStatus.PrincipalId,
 	}).Info("Created account assignment")
 
@@ -423,7 +434,24 @@ func (p *awsProvider) createAccountAssignment(ctx context.Context, instanceArn,
 }
 
 // revokeRoleIdentityCenter removes role authorization for Identity Center users
-func (p *awsProvider) revokeRoleIdentityCenter(ctx context.Context, user *models.User, role *models.Role) error {
+func (p *awsProvider) revokeRoleIdentityCenter(ctx context.Context, user *models.User, role *models.Role, grant *models.GrantRef) error {
+
+	// Remove exactly the account assignment recorded by the grant
+	if grant.HasIDs() {
+		for _, permissionSetArn := range grant.IDs {
+			err := p.deleteAccountAssignment(ctx,
+				grant.GetAttribute("instance_arn"),
+				permissionSetArn,
+				grant.GetAttribute("principal_id"),
+				grant.GetAttribute("account_id"),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// 1. Find the Identity Center instance
 	instanceArn, err := p.getIdentityCenterInstance(ctx)
 	if err != nil {
@@ -443,15 +471,27 @@ func (p *awsProvider) revokeRoleIdentityCenter(ctx context.Context, user *models
 	}
 
 	// 4. Delete the Account Assignment
-	_, err = p.ssoAdminService.DeleteAccountAssignment(ctx, &ssoadmin.DeleteAccountAssignmentInput{
+	return p.deleteAccountAssignment(ctx, instanceArn, permissionSetArn, principalId, p.GetAccountID())
+}
+
+// deleteAccountAssignment removes the permission set from the user for the
+// account. An assignment that no longer exists is considered already revoked.
+func (p *awsProvider) deleteAccountAssignment(ctx context.Context, instanceArn, permissionSetArn, principalId, accountId string) error {
+
+	_, err := p.ssoAdminService.DeleteAccountAssignment(ctx, &ssoadmin.DeleteAccountAssignmentInput{
 		InstanceArn:      aws.String(instanceArn),
 		PermissionSetArn: aws.String(permissionSetArn),
 		PrincipalId:      aws.String(principalId),
 		PrincipalType:    types.PrincipalTypeUser,
-		TargetId:         aws.String(p.GetAccountID()),
+		TargetId:         aws.String(accountId),
 		TargetType:       types.TargetTypeAwsAccount,
 	})
 
+	var notFound *types.ResourceNotFoundException
+	if errors.As(err, &notFound) {
+		return nil
+	}
+
 	if err != nil {
 		return fmt.Errorf("failed to delete account assignment: %w", err)
 	}
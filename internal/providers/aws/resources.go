@@ -0,0 +1,484 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/ssoadmin"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+)
+
+const (
+	ResourceTypeAccount       = "account"
+	ResourceTypeIAMRole       = "iam_role"
+	ResourceTypePermissionSet = "permission_set"
+	ResourceTypeS3Bucket      = "s3"
+	ResourceTypeEKSCluster    = "eks"
+
+	// DefaultResourcesCacheTTL is how long the listed resources are cached
+	// before they are listed from AWS again
+	DefaultResourcesCacheTTL = 15 * time.Minute
+
+	// emptyPayloadHash is the SHA-256 of an empty body, used to sign GETs
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// optionalResourceTypes are the types that can be listed by adding them to
+// resource_types, accounts, IAM roles and permission sets are always listed
+var optionalResourceTypes = []string{
+	ResourceTypeS3Bucket,
+	ResourceTypeEKSCluster,
+}
+
+// resourceLister lists the resources of a single type
+type resourceLister struct {
+	resourceType string
+	list         func(ctx context.Context) ([]models.ProviderResource, error)
+}
+
+// resourceCache tracks when the resources were last listed
+type resourceCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	fetchedAt time.Time
+}
+
+func (c *resourceCache) isFresh() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < c.ttl
+}
+
+func (c *resourceCache) markFetched() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fetchedAt = time.Now()
+}
+
+// newResourceCache reads resources_cache_ttl from the config
+func newResourceCache(awsConfig *models.BasicConfig) (*resourceCache, error) {
+
+	ttl := DefaultResourcesCacheTTL
+
+	if configuredTTL, found := awsConfig.GetString("resources_cache_ttl"); found && len(configuredTTL) > 0 {
+		parsed, err := common.ValidateDuration(configuredTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AWS resources_cache_ttl configuration: %w", err)
+		}
+		ttl = parsed
+	}
+
+	return &resourceCache{ttl: ttl}, nil
+}
+
+// ListResources returns the account, IAM roles, permission sets and any
+// configured resource types. The resources are listed from AWS when the
+// cache has expired and the search is matched locally.
+func (p *awsProvider) ListResources(ctx context.Context, searchRequest *models.SearchRequest) ([]models.SearchResult[models.ProviderResource], error) {
+
+	if p.resources == nil {
+		return p.BaseProvider.ListResources(ctx, searchRequest)
+	}
+
+	if err := p.refreshResources(ctx); err != nil {
+		return nil, err
+	}
+
+	resources, err := p.BaseProvider.ListResources(ctx, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if searchRequest == nil || searchRequest.IsEmpty() {
+		return resources, nil
+	}
+
+	filterText := strings.ToLower(strings.Join(searchRequest.Terms, " "))
+
+	filtered := []models.ProviderResource{}
+
+	for _, resource := range resources {
+		if matchesResource(&resource.Result, filterText) {
+			filtered = append(filtered, resource.Result)
+		}
+	}
+
+	return models.ReturnSearchResults(filtered), nil
+}
+
+// GetResource returns a resource by ARN, ID or name
+func (p *awsProvider) GetResource(ctx context.Context, resource string) (*models.ProviderResource, error) {
+
+	if p.resources == nil {
+		return p.BaseProvider.GetResource(ctx, resource)
+	}
+
+	if err := p.refreshResources(ctx); err != nil {
+		return nil, err
+	}
+
+	return p.BaseProvider.GetResource(ctx, resource)
+}
+
+// matchesResource returns true if the ID, name or type contains the filter
+func matchesResource(resource *models.ProviderResource, filterText string) bool {
+	return strings.Contains(strings.ToLower(resource.ID), filterText) ||
+		strings.Contains(strings.ToLower(resource.Name), filterText) ||
+		strings.Contains(strings.ToLower(resource.Type), filterText)
+}
+
+// refreshResources replaces the cached resources if the cache has expired
+func (p *awsProvider) refreshResources(ctx context.Context) error {
+
+	if p.resources.isFresh() {
+		return nil
+	}
+
+	startTime := time.Now()
+
+	resources, err := p.loadResources(ctx, p.getResourceListers())
+
+	if err != nil {
+		return err
+	}
+
+	p.SetResources(resources)
+	p.resources.markFetched()
+
+	p.GetLogger(ctx).WithFields(logrus.Fields{
+		"resources": len(resources),
+	}).Debugf("Listed AWS resources in %s", time.Since(startTime))
+
+	return nil
+}
+
+// loadResources lists each resource type. A type that fails to list, most
+// often because the agent lacks the permission, is logged and skipped so
+// the other types are still returned.
+func (p *awsProvider) loadResources(ctx context.Context, listers []resourceLister) ([]models.ProviderResource, error) {
+
+	log := p.GetLogger(ctx)
+
+	resources := []models.ProviderResource{}
+	failed := 0
+
+	for _, lister := range listers {
+
+		listed, err := lister.list(ctx)
+
+		if err != nil {
+			failed++
+			log.WithError(err).WithField("type", lister.resourceType).
+				Warn("Failed to list AWS resources, skipping")
+			continue
+		}
+
+		resources = append(resources, listed...)
+	}
+
+	if len(listers) > 0 && failed == len(listers) {
+		return nil, fmt.Errorf("failed to list any AWS resources")
+	}
+
+	return resources, nil
+}
+
+// getResourceListers returns the listers for the account, IAM roles,
+// permission sets and the types in resource_types
+func (p *awsProvider) getResourceListers() []resourceLister {
+
+	listers := []resourceLister{
+		{ResourceTypeAccount, p.listAccountResources},
+		{ResourceTypeIAMRole, p.listIAMRoleResources},
+		{ResourceTypePermissionSet, p.listPermissionSetResources},
+	}
+
+	resourceTypes, _ := p.GetConfig().GetStringSlice("resource_types")
+
+	for _, resourceType := range resourceTypes {
+		switch strings.ToLower(resourceType) {
+		case ResourceTypeS3Bucket:
+			listers = append(listers, resourceLister{ResourceTypeS3Bucket, p.listS3BucketResources})
+		case ResourceTypeEKSCluster:
+			listers = append(listers, resourceLister{ResourceTypeEKSCluster, p.listEKSClusterResources})
+		}
+	}
+
+	return listers
+}
+
+// listAccountResources returns the account the provider is configured for
+func (p *awsProvider) listAccountResources(ctx context.Context) ([]models.ProviderResource, error) {
+
+	accountId := p.GetAccountID()
+
+	return []models.ProviderResource{{
+		ID:          accountId,
+		Type:        ResourceTypeAccount,
+		Name:        p.GetConfig().GetStringWithDefault("account_name", accountId),
+		Description: fmt.Sprintf("AWS account %s", accountId),
+	}}, nil
+}
+
+// listIAMRoleResources pages through the IAM roles under role_path_prefix
+func (p *awsProvider) listIAMRoleResources(ctx context.Context) ([]models.ProviderResource, error) {
+
+	input := &iam.ListRolesInput{}
+
+	if pathPrefix, found := p.GetConfig().GetString("role_path_prefix"); found && len(pathPrefix) > 0 {
+		input.PathPrefix = aws.String(pathPrefix)
+	}
+
+	resources := []models.ProviderResource{}
+
+	paginator := iam.NewListRolesPaginator(p.service, input)
+
+	for paginator.HasMorePages() {
+
+		page, err := paginator.NextPage(ctx)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list IAM roles: %w", err)
+		}
+
+		for _, role := range page.Roles {
+			resources = append(resources, models.ProviderResource{
+				ID:          aws.ToString(role.Arn),
+				Type:        ResourceTypeIAMRole,
+				Name:        aws.ToString(role.RoleName),
+				Description: aws.ToString(role.Description),
+				Resource:    role,
+			})
+		}
+	}
+
+	return resources, nil
+}
+
+// listPermissionSetResources pages through the permission sets of the
+// Identity Center instance. Accounts without Identity Center have none.
+func (p *awsProvider) listPermissionSetResources(ctx context.Context) ([]models.ProviderResource, error) {
+
+	instances, err := p.ssoAdminService.ListInstances(ctx, &ssoadmin.ListInstancesInput{})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Identity Center instances: %w", err)
+	}
+
+	if len(instances.Instances) == 0 {
+		return []models.ProviderResource{}, nil
+	}
+
+	instanceArn := instances.Instances[0].InstanceArn
+
+	resources := []models.ProviderResource{}
+
+	paginator := ssoadmin.NewListPermissionSetsPaginator(p.ssoAdminService, &ssoadmin.ListPermissionSetsInput{
+		InstanceArn: instanceArn,
+	})
+
+	for paginator.HasMorePages() {
+
+		page, err := paginator.NextPage(ctx)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list permission sets: %w", err)
+		}
+
+		for _, permissionSetArn := range page.PermissionSets {
+
+			desc, err := p.ssoAdminService.DescribePermissionSet(ctx, &ssoadmin.DescribePermissionSetInput{
+				InstanceArn:      instanceArn,
+				PermissionSetArn: aws.String(permissionSetArn),
+			})
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe permission set %s: %w", permissionSetArn, err)
+			}
+
+			resources = append(resources, models.ProviderResource{
+				ID:          permissionSetArn,
+				Type:        ResourceTypePermissionSet,
+				Name:        aws.ToString(desc.PermissionSet.Name),
+				Description: aws.ToString(desc.PermissionSet.Description),
+				Resource:    desc.PermissionSet,
+			})
+		}
+	}
+
+	return resources, nil
+}
+
+// s3ListBucketsResult is the ListBuckets response
+type s3ListBucketsResult struct {
+	Buckets []struct {
+		Name         string `xml:"Name"`
+		BucketRegion string `xml:"BucketRegion"`
+	} `xml:"Buckets>Bucket"`
+	ContinuationToken string `xml:"ContinuationToken"`
+}
+
+// listS3BucketResources pages through the account's S3 buckets
+func (p *awsProvider) listS3BucketResources(ctx context.Context) ([]models.ProviderResource, error) {
+
+	resources := []models.ProviderResource{}
+	token := ""
+
+	for {
+
+		query := url.Values{"max-buckets": {"1000"}}
+		if len(token) > 0 {
+			query.Set("continuation-token", token)
+		}
+
+		body, err := p.signedGet(ctx, "s3", "/", query)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list S3 buckets: %w", err)
+		}
+
+		var result s3ListBucketsResult
+
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse S3 buckets: %w", err)
+		}
+
+		for _, bucket := range result.Buckets {
+			resources = append(resources, models.ProviderResource{
+				ID:          fmt.Sprintf("arn:aws:s3:::%s", bucket.Name),
+				Type:        ResourceTypeS3Bucket,
+				Name:        bucket.Name,
+				Description: fmt.Sprintf("S3 bucket %s", bucket.Name),
+				Metadata: map[string]any{
+					"region": bucket.BucketRegion,
+				},
+			})
+		}
+
+		if len(result.ContinuationToken) == 0 {
+			return resources, nil
+		}
+
+		token = result.ContinuationToken
+	}
+}
+
+// eksListClustersResult is the ListClusters response
+type eksListClustersResult struct {
+	Clusters  []string `json:"clusters"`
+	NextToken *string  `json:"nextToken"`
+}
+
+// listEKSClusterResources pages through the EKS clusters in the region
+func (p *awsProvider) listEKSClusterResources(ctx context.Context) ([]models.ProviderResource, error) {
+
+	resources := []models.ProviderResource{}
+	token := ""
+
+	for {
+
+		query := url.Values{"maxResults": {"100"}}
+		if len(token) > 0 {
+			query.Set("nextToken", token)
+		}
+
+		body, err := p.signedGet(ctx, "eks", "/clusters", query)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list EKS clusters: %w", err)
+		}
+
+		var result eksListClustersResult
+
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse EKS clusters: %w", err)
+		}
+
+		for _, cluster := range result.Clusters {
+			resources = append(resources, models.ProviderResource{
+				ID:          fmt.Sprintf("arn:aws:eks:%s:%s:cluster/%s", p.GetRegion(), p.GetAccountID(), cluster),
+				Type:        ResourceTypeEKSCluster,
+				Name:        cluster,
+				Description: fmt.Sprintf("EKS cluster %s in %s", cluster, p.GetRegion()),
+			})
+		}
+
+		if result.NextToken == nil || len(*result.NextToken) == 0 {
+			return resources, nil
+		}
+
+		token = *result.NextToken
+	}
+}
+
+// signedGet makes a SigV4 signed GET to the service's regional endpoint,
+// or the configured endpoint. S3 and EKS are called directly as listing is
+// the only call the agent makes to them.
+func (p *awsProvider) signedGet(ctx context.Context, service string, path string, query url.Values) ([]byte, error) {
+
+	endpoint := fmt.Sprintf("https://%s.%s.amazonaws.com", service, p.GetRegion())
+
+	if configured, found := p.GetConfig().GetString("endpoint"); found && len(configured) > 0 {
+		endpoint = strings.TrimSuffix(configured, "/")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+path+"?"+query.Encode(), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := p.sdkConfig.Credentials.Retrieve(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+
+	// S3 requires the payload hash as a header
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+
+	if err := v4.NewSigner().SignHTTP(ctx, credentials, req, emptyPayloadHash, service, p.GetRegion(), time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	httpClient := p.sdkConfig.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %d: %s", service, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return body, nil
+}
@@ -0,0 +1,177 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func newTestResourceProvider(t *testing.T, config models.BasicConfig) *awsProvider {
+	t.Helper()
+
+	cache, err := newResourceCache(&config)
+	require.NoError(t, err)
+
+	return &awsProvider{
+		BaseProvider: models.NewBaseProvider("aws", models.Provider{
+			Name:     "aws",
+			Provider: AwsProviderName,
+			Config:   &config,
+		}, models.ProviderCapabilityRBAC, models.ProviderCapabilityResourceDiscovery),
+		region:    "us-east-1",
+		accountID: "123456789012",
+		sdkConfig: aws.Config{
+			Region:      "us-east-1",
+			Credentials: credentials.NewStaticCredentialsProvider("test", "test", ""),
+		},
+		resources: cache,
+	}
+}
+
+func TestLoadResources(t *testing.T) {
+	provider := newTestResourceProvider(t, models.BasicConfig{})
+	ctx := context.Background()
+
+	listed := func(resourceType string, names ...string) resourceLister {
+		return resourceLister{resourceType, func(ctx context.Context) ([]models.ProviderResource, error) {
+			resources := []models.ProviderResource{}
+			for _, name := range names {
+				resources = append(resources, models.ProviderResource{ID: name, Name: name, Type: resourceType})
+			}
+			return resources, nil
+		}}
+	}
+
+	denied := func(resourceType string) resourceLister {
+		return resourceLister{resourceType, func(ctx context.Context) ([]models.ProviderResource, error) {
+			return nil, errors.New("AccessDenied")
+		}}
+	}
+
+	t.Run("a failing type is skipped", func(t *testing.T) {
+		resources, err := provider.loadResources(ctx, []resourceLister{
+			listed(ResourceTypeIAMRole, "admin", "reader"),
+			denied(ResourceTypeS3Bucket),
+			listed(ResourceTypeEKSCluster, "prod"),
+		})
+		require.NoError(t, err)
+		assert.Len(t, resources, 3)
+	})
+
+	t.Run("every type failing is an error", func(t *testing.T) {
+		_, err := provider.loadResources(ctx, []resourceLister{
+			denied(ResourceTypeIAMRole),
+			denied(ResourceTypeS3Bucket),
+		})
+		assert.EqualError(t, err, "failed to list any AWS resources")
+	})
+}
+
+func TestListResources(t *testing.T) {
+	provider := newTestResourceProvider(t, models.BasicConfig{})
+	ctx := context.Background()
+
+	// Fill the cache so AWS isn't called
+	provider.SetResources([]models.ProviderResource{
+		{ID: "arn:aws:iam::123456789012:role/Admin", Name: "Admin", Type: ResourceTypeIAMRole},
+		{ID: "arn:aws:iam::123456789012:role/ReadOnly", Name: "ReadOnly", Type: ResourceTypeIAMRole},
+		{ID: "arn:aws:s3:::audit-logs", Name: "audit-logs", Type: ResourceTypeS3Bucket},
+	})
+	provider.resources.markFetched()
+
+	t.Run("all resources", func(t *testing.T) {
+		resources, err := provider.ListResources(ctx, &models.SearchRequest{})
+		require.NoError(t, err)
+		assert.Len(t, resources, 3)
+	})
+
+	t.Run("substring match", func(t *testing.T) {
+		resources, err := provider.ListResources(ctx, &models.SearchRequest{Terms: []string{"read"}})
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.Equal(t, "ReadOnly", resources[0].Result.Name)
+
+		resources, err = provider.ListResources(ctx, &models.SearchRequest{Terms: []string{"s3"}})
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.Equal(t, "audit-logs", resources[0].Result.Name)
+	})
+
+	t.Run("get by arn or name", func(t *testing.T) {
+		resource, err := provider.GetResource(ctx, "arn:aws:s3:::audit-logs")
+		require.NoError(t, err)
+		assert.Equal(t, ResourceTypeS3Bucket, resource.Type)
+
+		resource, err = provider.GetResource(ctx, "admin")
+		require.NoError(t, err)
+		assert.Equal(t, "arn:aws:iam::123456789012:role/Admin", resource.ID)
+	})
+}
+
+func TestListSignedResources(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=test/"))
+
+		switch {
+		case r.URL.Path == "/" && len(r.URL.Query().Get("continuation-token")) == 0:
+			w.Write([]byte(`<ListAllMyBucketsResult><Buckets><Bucket><Name>audit-logs</Name><BucketRegion>us-east-1</BucketRegion></Bucket></Buckets><ContinuationToken>next</ContinuationToken></ListAllMyBucketsResult>`))
+		case r.URL.Path == "/":
+			w.Write([]byte(`<ListAllMyBucketsResult><Buckets><Bucket><Name>backups</Name></Bucket></Buckets></ListAllMyBucketsResult>`))
+		case r.URL.Path == "/clusters":
+			w.Write([]byte(`{"clusters":["prod","staging"],"nextToken":null}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := newTestResourceProvider(t, models.BasicConfig{"endpoint": server.URL})
+	ctx := context.Background()
+
+	t.Run("s3 buckets", func(t *testing.T) {
+		resources, err := provider.listS3BucketResources(ctx)
+		require.NoError(t, err)
+		require.Len(t, resources, 2)
+		assert.Equal(t, "arn:aws:s3:::audit-logs", resources[0].ID)
+		assert.Equal(t, "backups", resources[1].Name)
+	})
+
+	t.Run("eks clusters", func(t *testing.T) {
+		resources, err := provider.listEKSClusterResources(ctx)
+		require.NoError(t, err)
+		require.Len(t, resources, 2)
+		assert.Equal(t, "arn:aws:eks:us-east-1:123456789012:cluster/prod", resources[0].ID)
+	})
+}
+
+func TestGetResourceListers(t *testing.T) {
+	provider := newTestResourceProvider(t, models.BasicConfig{
+		"resource_types": []any{"s3", "EKS"},
+	})
+
+	types := []string{}
+	for _, lister := range provider.getResourceListers() {
+		types = append(types, lister.resourceType)
+	}
+
+	assert.Equal(t, []string{
+		ResourceTypeAccount,
+		ResourceTypeIAMRole,
+		ResourceTypePermissionSet,
+		ResourceTypeS3Bucket,
+		ResourceTypeEKSCluster,
+	}, types)
+
+	err := provider.ValidateConfig(&models.BasicConfig{"resource_types": []any{"s3", "rds"}})
+	assert.EqualError(t, err, `invalid aws provider config: resource_types must be one of s3, eks, got "rds"`)
+}
@@ -0,0 +1,199 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/sirupsen/logrus"
+)
+
+// CheckSCPCompliance simulates the actions allowed by the role's inline
+// policies and returns those denied by a service control policy. Granting
+// the role would otherwise give access that silently doesn't work.
+// Wildcard actions can't be simulated and are skipped.
+func (p *awsProvider) CheckSCPCompliance(ctx context.Context, roleARN string) ([]string, error) {
+
+	roleName, err := getRoleNameFromArn(roleARN)
+
+	if err != nil {
+		return nil, err
+	}
+
+	actions, err := p.getRoleInlineActions(ctx, roleName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(actions) == 0 {
+		return nil, nil
+	}
+
+	denied := []string{}
+
+	paginator := iam.NewSimulatePrincipalPolicyPaginator(p.service, &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(roleARN),
+		ActionNames:     actions,
+	})
+
+	for paginator.HasMorePages() {
+
+		page, err := paginator.NextPage(ctx)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate the policies of %s: %w", roleARN, err)
+		}
+
+		for _, result := range page.EvaluationResults {
+			// Only the organization's decision matters, the role's own
+			// policies were just written to allow the action
+			if result.OrganizationsDecisionDetail != nil &&
+				!result.OrganizationsDecisionDetail.AllowedByOrganizations {
+				denied = append(denied, aws.ToString(result.EvalActionName))
+			}
+		}
+	}
+
+	slices.Sort(denied)
+
+	return denied, nil
+}
+
+// checkSCPCompliance runs the SCP check for a grant when scp_check or
+// strict_scp_check is enabled. Denied actions are logged, and fail the
+// grant with strict_scp_check.
+func (p *awsProvider) checkSCPCompliance(ctx context.Context, roleARN string) error {
+
+	config := p.GetConfig()
+
+	strict, _ := config.GetBool("strict_scp_check")
+	enabled, _ := config.GetBool("scp_check")
+
+	if !enabled && !strict {
+		return nil
+	}
+
+	log := p.GetLogger(ctx).WithField("role_arn", roleARN)
+
+	denied, err := p.CheckSCPCompliance(ctx, roleARN)
+
+	if err != nil {
+		if strict {
+			return fmt.Errorf("failed to check service control policies: %w", err)
+		}
+		log.WithError(err).Warn("Failed to check service control policies, granting anyway")
+		return nil
+	}
+
+	if len(denied) == 0 {
+		return nil
+	}
+
+	log.WithFields(logrus.Fields{
+		"denied_actions": denied,
+		"strict":         strict,
+	}).Warn("Role grants actions denied by service control policies")
+
+	if strict {
+		return fmt.Errorf("actions denied by service control policies: %s",
+			strings.Join(denied, ", "))
+	}
+
+	return nil
+}
+
+// getRoleInlineActions returns the actions the role's inline policies allow
+func (p *awsProvider) getRoleInlineActions(ctx context.Context, roleName string) ([]string, error) {
+
+	actions := []string{}
+
+	paginator := iam.NewListRolePoliciesPaginator(p.service, &iam.ListRolePoliciesInput{
+		RoleName: aws.String(roleName),
+	})
+
+	for paginator.HasMorePages() {
+
+		page, err := paginator.NextPage(ctx)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list policies of role %s: %w", roleName, err)
+		}
+
+		for _, policyName := range page.PolicyNames {
+
+			policyOutput, err := p.service.GetRolePolicy(ctx, &iam.GetRolePolicyInput{
+				RoleName:   aws.String(roleName),
+				PolicyName: aws.String(policyName),
+			})
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to get policy %s of role %s: %w", policyName, roleName, err)
+			}
+
+			// IAM returns policy documents URL encoded
+			document, err := url.QueryUnescape(aws.ToString(policyOutput.PolicyDocument))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode policy %s: %w", policyName, err)
+			}
+
+			var policy PolicyDocument
+			if err := json.Unmarshal([]byte(document), &policy); err != nil {
+				return nil, fmt.Errorf("failed to parse policy %s: %w", policyName, err)
+			}
+
+			for _, stmt := range policy.Statement {
+				if stmt.Effect != "Allow" {
+					continue
+				}
+				for _, action := range getStatementActions(stmt) {
+					if !strings.Contains(action, "*") && !slices.Contains(actions, action) {
+						actions = append(actions, action)
+					}
+				}
+			}
+		}
+	}
+
+	return actions, nil
+}
+
+// getStatementActions returns the statement's actions, which may be a
+// single string or a list
+func getStatementActions(stmt Statement) []string {
+	switch action := stmt.Action.(type) {
+	case string:
+		return []string{action}
+	case []string:
+		return action
+	case []any:
+		actions := make([]string, 0, len(action))
+		for _, value := range action {
+			if str, ok := value.(string); ok {
+				actions = append(actions, str)
+			}
+		}
+		return actions
+	}
+	return nil
+}
+
+// getRoleNameFromArn returns the role name, without its path, from an IAM
+// role ARN e.g. arn:aws:iam::123456789012:role/thand/admin
+func getRoleNameFromArn(roleARN string) (string, error) {
+
+	parts := strings.SplitN(roleARN, ":", 6)
+
+	if len(parts) < 6 || !strings.HasPrefix(parts[5], "role/") {
+		return "", fmt.Errorf("invalid IAM role ARN: %s", roleARN)
+	}
+
+	path := strings.Split(parts[5], "/")
+
+	return path[len(path)-1], nil
+}
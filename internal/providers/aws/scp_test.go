@@ -0,0 +1,157 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// fakeSCP serves a role's inline policy and simulates it against an SCP
+// denying the listed actions
+type fakeSCP struct {
+	policy    string
+	denied    []string
+	simulated []string
+}
+
+func (f *fakeSCP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Form.Get("Action") {
+	case "ListRolePolicies":
+		fmt.Fprint(w, `<ListRolePoliciesResponse><ListRolePoliciesResult>`+
+			`<PolicyNames><member>thand-admin-policy</member></PolicyNames>`+
+			`<IsTruncated>false</IsTruncated></ListRolePoliciesResult></ListRolePoliciesResponse>`)
+	case "GetRolePolicy":
+		fmt.Fprintf(w, `<GetRolePolicyResponse><GetRolePolicyResult>`+
+			`<RoleName>admin</RoleName><PolicyName>thand-admin-policy</PolicyName>`+
+			`<PolicyDocument>%s</PolicyDocument></GetRolePolicyResult></GetRolePolicyResponse>`,
+			url.QueryEscape(f.policy))
+	case "SimulatePrincipalPolicy":
+		results := strings.Builder{}
+		for key, values := range r.Form {
+			if !strings.HasPrefix(key, "ActionNames.member.") {
+				continue
+			}
+			action := values[0]
+			f.simulated = append(f.simulated, action)
+			allowed := !slices.Contains(f.denied, action)
+			fmt.Fprintf(&results, `<member><EvalActionName>%s</EvalActionName>`+
+				`<EvalResourceName>*</EvalResourceName><EvalDecision>%s</EvalDecision>`+
+				`<OrganizationsDecisionDetail><AllowedByOrganizations>%t</AllowedByOrganizations>`+
+				`</OrganizationsDecisionDetail></member>`,
+				action, map[bool]string{true: "allowed", false: "explicitDeny"}[allowed], allowed)
+		}
+		fmt.Fprintf(w, `<SimulatePrincipalPolicyResponse><SimulatePrincipalPolicyResult>`+
+			`<EvaluationResults>%s</EvaluationResults><IsTruncated>false</IsTruncated>`+
+			`</SimulatePrincipalPolicyResult></SimulatePrincipalPolicyResponse>`, results.String())
+	default:
+		http.Error(w, "unsupported action", http.StatusBadRequest)
+	}
+}
+
+func newSCPProvider(t *testing.T, fake *fakeSCP, config models.BasicConfig) *awsProvider {
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return &awsProvider{
+		BaseProvider: models.NewBaseProvider("aws", models.Provider{
+			Name:     "aws",
+			Provider: AwsProviderName,
+			Config:   &config,
+		}, models.ProviderCapabilityRBAC),
+		service: iam.New(iam.Options{
+			Region:       "us-east-1",
+			Credentials:  credentials.NewStaticCredentialsProvider("test", "test", ""),
+			BaseEndpoint: aws.String(server.URL),
+		}),
+	}
+}
+
+func TestCheckSCPCompliance(t *testing.T) {
+
+	ctx := context.Background()
+	roleARN := "arn:aws:iam::000000000000:role/thand/admin"
+
+	newFake := func() *fakeSCP {
+		return &fakeSCP{
+			policy: `{"Version":"2012-10-17","Statement":[` +
+				`{"Effect":"Allow","Action":["s3:GetObject","ec2:RunInstances","s3:*"],"Resource":"*"},` +
+				`{"Effect":"Allow","Action":"iam:CreateUser","Resource":"*"},` +
+				`{"Effect":"Deny","Action":"s3:DeleteBucket","Resource":"*"}]}`,
+			denied: []string{"ec2:RunInstances", "iam:CreateUser"},
+		}
+	}
+
+	t.Run("returns actions denied by SCPs", func(t *testing.T) {
+		fake := newFake()
+		provider := newSCPProvider(t, fake, models.BasicConfig{})
+
+		denied, err := provider.CheckSCPCompliance(ctx, roleARN)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ec2:RunInstances", "iam:CreateUser"}, denied)
+
+		// Wildcards and denied statements aren't simulated
+		assert.ElementsMatch(t, []string{"s3:GetObject", "ec2:RunInstances", "iam:CreateUser"}, fake.simulated)
+	})
+
+	t.Run("invalid ARN", func(t *testing.T) {
+		provider := newSCPProvider(t, newFake(), models.BasicConfig{})
+		_, err := provider.CheckSCPCompliance(ctx, "arn:aws:iam::000000000000:user/alice")
+		assert.ErrorContains(t, err, "invalid IAM role ARN")
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		fake := newFake()
+		provider := newSCPProvider(t, fake, models.BasicConfig{})
+		require.NoError(t, provider.checkSCPCompliance(ctx, roleARN))
+		assert.Empty(t, fake.simulated)
+	})
+
+	t.Run("warns without strict", func(t *testing.T) {
+		provider := newSCPProvider(t, newFake(), models.BasicConfig{"scp_check": true})
+		assert.NoError(t, provider.checkSCPCompliance(ctx, roleARN))
+	})
+
+	t.Run("strict blocks the grant", func(t *testing.T) {
+		provider := newSCPProvider(t, newFake(), models.BasicConfig{"strict_scp_check": true})
+		err := provider.checkSCPCompliance(ctx, roleARN)
+		assert.ErrorContains(t, err, "ec2:RunInstances, iam:CreateUser")
+
+		fake := newFake()
+		fake.denied = nil
+		provider = newSCPProvider(t, fake, models.BasicConfig{"strict_scp_check": true})
+		assert.NoError(t, provider.checkSCPCompliance(ctx, roleARN))
+	})
+}
+
+func TestGetRoleNameFromArn(t *testing.T) {
+
+	name, err := getRoleNameFromArn("arn:aws:iam::123456789012:role/admin")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", name)
+
+	name, err = getRoleNameFromArn("arn:aws:iam::123456789012:role/thand/ops/admin")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", name)
+
+	_, err = getRoleNameFromArn("admin")
+	assert.Error(t, err)
+}
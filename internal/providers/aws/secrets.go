@@ -0,0 +1,161 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// SecretsManagerScheme is the URI scheme used to reference AWS Secrets
+// Manager secrets from config values e.g. awssm://my-secret#password
+const SecretsManagerScheme = "awssm"
+
+var (
+	secretsManagerClients   = map[string]*secretsmanager.Client{}
+	secretsManagerClientsMu sync.Mutex
+)
+
+// parseSecretsManagerReference splits an awssm:// reference into the
+// secret id and the optional JSON field to extract
+func parseSecretsManagerReference(reference string) (string, string, error) {
+	prefix := SecretsManagerScheme + "://"
+	if !strings.HasPrefix(strings.ToLower(reference), prefix) {
+		return "", "", fmt.Errorf("invalid secrets manager reference: %s", reference)
+	}
+
+	secretId, field, _ := strings.Cut(reference[len(prefix):], "#")
+
+	if len(secretId) == 0 {
+		return "", "", fmt.Errorf("secrets manager reference is missing a secret name: %s", reference)
+	}
+
+	return secretId, field, nil
+}
+
+// getSecretsManagerClient lazily creates a Secrets Manager client for the
+// credentials in the given config. Clients are cached so each distinct set
+// of credentials is only initialized once.
+func getSecretsManagerClient(config *models.BasicConfig) (*secretsmanager.Client, error) {
+
+	// Strip any other secret references so we don't recurse when
+	// resolving the credentials used to fetch the secret itself
+	awsConfig := config.WithoutSecretReferences()
+
+	cacheKey, err := getSecretsManagerClientKey(awsConfig)
+
+	if err != nil {
+		return nil, err
+	}
+
+	secretsManagerClientsMu.Lock()
+	defer secretsManagerClientsMu.Unlock()
+
+	if client, found := secretsManagerClients[cacheKey]; found {
+		return client, nil
+	}
+
+	sdkConfig, err := CreateAwsConfig(awsConfig)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS config: %w", err)
+	}
+
+	client := secretsmanager.NewFromConfig(sdkConfig.Config)
+	secretsManagerClients[cacheKey] = client
+
+	return client, nil
+}
+
+// getSecretsManagerClientKey hashes the whole config, encoded with sorted
+// keys, so configs that differ in any credential get their own client
+func getSecretsManagerClientKey(awsConfig *models.BasicConfig) (string, error) {
+
+	encoded, err := json.Marshal(awsConfig)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to encode AWS config: %w", err)
+	}
+
+	sum := sha256.Sum256(encoded)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ResolveSecretsManagerReference fetches the secret referenced by an
+// awssm://name or awssm://name#field value. When a field is provided the
+// secret is expected to be a JSON object and only that key is returned.
+func ResolveSecretsManagerReference(config *models.BasicConfig, reference string) (string, error) {
+
+	secretId, field, err := parseSecretsManagerReference(reference)
+
+	if err != nil {
+		return "", err
+	}
+
+	client, err := getSecretsManagerClient(config)
+
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretId),
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s: %w", secretId, err)
+	}
+
+	if result.SecretString == nil {
+		return "", fmt.Errorf("secret %s has no string value", secretId)
+	}
+
+	return extractSecretField(secretId, *result.SecretString, field)
+}
+
+func extractSecretField(secretId string, secretValue string, field string) (string, error) {
+
+	if len(field) == 0 {
+		return secretValue, nil
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal([]byte(secretValue), &values); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object: %w", secretId, err)
+	}
+
+	value, found := values[field]
+
+	if !found {
+		return "", fmt.Errorf("secret %s does not contain field %s", secretId, field)
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode field %s of secret %s: %w", field, secretId, err)
+		}
+		return string(encoded), nil
+	}
+}
+
+func init() {
+	models.RegisterSecretResolver(SecretsManagerScheme, ResolveSecretsManagerReference)
+}
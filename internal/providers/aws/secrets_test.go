@@ -0,0 +1,80 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestParseSecretsManagerReference(t *testing.T) {
+	secretId, field, err := parseSecretsManagerReference("awssm://prod/okta#token")
+	require.NoError(t, err)
+	assert.Equal(t, "prod/okta", secretId)
+	assert.Equal(t, "token", field)
+
+	secretId, field, err = parseSecretsManagerReference("awssm://plain-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "plain-secret", secretId)
+	assert.Empty(t, field)
+
+	_, _, err = parseSecretsManagerReference("awssm://#field")
+	assert.Error(t, err)
+}
+
+func TestExtractSecretField(t *testing.T) {
+	value, err := extractSecretField("s", "raw-value", "")
+	require.NoError(t, err)
+	assert.Equal(t, "raw-value", value)
+
+	value, err = extractSecretField("s", `{"user":"admin","port":5432}`, "user")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", value)
+
+	value, err = extractSecretField("s", `{"user":"admin","port":5432}`, "port")
+	require.NoError(t, err)
+	assert.Equal(t, "5432", value)
+
+	_, err = extractSecretField("s", `{"user":"admin"}`, "password")
+	assert.Error(t, err)
+
+	_, err = extractSecretField("s", "not-json", "user")
+	assert.Error(t, err)
+}
+
+func TestGetSecretsManagerClientKey(t *testing.T) {
+	base := &models.BasicConfig{
+		"access_key_id":     "AKIA",
+		"secret_access_key": "first",
+		"region":            "us-east-1",
+	}
+
+	baseKey, err := getSecretsManagerClientKey(base)
+	require.NoError(t, err)
+
+	sameKey, err := getSecretsManagerClientKey(&models.BasicConfig{
+		"region":            "us-east-1",
+		"secret_access_key": "first",
+		"access_key_id":     "AKIA",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, baseKey, sameKey)
+
+	rotatedKey, err := getSecretsManagerClientKey(&models.BasicConfig{
+		"access_key_id":     "AKIA",
+		"secret_access_key": "second",
+		"region":            "us-east-1",
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, baseKey, rotatedKey)
+
+	roleKey, err := getSecretsManagerClientKey(&models.BasicConfig{
+		"access_key_id":     "AKIA",
+		"secret_access_key": "first",
+		"region":            "us-east-1",
+		"role_arn":          "arn:aws:iam::123456789012:role/secrets",
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, baseKey, roleKey)
+}
@@ -2,12 +2,12 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/identitystore"
-	"github.com/aws/aws-sdk-go-v2/service/ssoadmin"
 	"github.com/sirupsen/logrus"
 	"github.com/thand-io/agent/internal/models"
 	"go.temporal.io/sdk/temporal"
@@ -18,15 +18,21 @@ func (p *awsProvider) CanSynchronizeUsers() bool {
 }
 
 func (p *awsProvider) SynchronizeUsers(ctx context.Context, req *models.SynchronizeUsersRequest) (*models.SynchronizeUsersResponse, error) {
+	log := p.GetLogger(ctx)
+
 	startTime := time.Now()
 	defer func() {
 		elapsed := time.Since(startTime)
-		logrus.Debugf("Refreshed AWS user identities in %s", elapsed)
+		log.Debugf("Refreshed AWS user identities in %s", elapsed)
 	}()
 
 	// 1. Get Identity Store ID
-	resp, err := p.ssoAdminService.ListInstances(ctx, &ssoadmin.ListInstancesInput{})
-	if err != nil {
+	identityStoreId, err := p.getIdentityStoreId(ctx)
+
+	if errors.Is(err, errNoIdentityCenter) {
+		log.Warn("No SSO instances found, skipping user synchronization")
+		return &models.SynchronizeUsersResponse{}, nil
+	} else if err != nil {
 
 		if req.Pagination == nil {
 
@@ -40,21 +46,7 @@ func (p *awsProvider) SynchronizeUsers(ctx context.Context, req *models.Synchron
 			)
 		}
 
-		return nil, fmt.Errorf("failed to list SSO instances: %w", err)
-	}
-
-	if len(resp.Instances) == 0 {
-		logrus.Warn("No SSO instances found, skipping user synchronization")
-		return &models.SynchronizeUsersResponse{}, nil
-	}
-
-	identityStoreId := resp.Instances[0].IdentityStoreId
-	if identityStoreId == nil {
-		return nil, temporal.NewNonRetryableApplicationError(
-			"identity store ID not found in SSO instance",
-			"IdentityCenterRequest",
-			fmt.Errorf("identity store ID is nil"),
-		)
+		return nil, err
 	}
 
 	if req.Pagination == nil {
@@ -64,7 +56,7 @@ func (p *awsProvider) SynchronizeUsers(ctx context.Context, req *models.Synchron
 	}
 
 	input := &identitystore.ListUsersInput{
-		IdentityStoreId: identityStoreId,
+		IdentityStoreId: aws.String(identityStoreId),
 		MaxResults:      aws.Int32(int32(req.Pagination.PageSize)),
 	}
 
@@ -79,45 +71,9 @@ func (p *awsProvider) SynchronizeUsers(ctx context.Context, req *models.Synchron
 
 	var identities []models.Identity
 	for _, user := range usersResp.Users {
-
-		var userId string
-		var userName string
-		var email string
-
-		if user.UserId != nil && len(*user.UserId) > 0 {
-			userId = *user.UserId
-		} else {
-			continue
-		}
-
-		if user.UserName != nil && len(*user.UserName) > 0 {
-			userName = *user.UserName
-		}
-
-		// Emails is a list of Email objects
-		if len(user.Emails) > 0 && user.Emails[0].Value != nil {
-			email = *user.Emails[0].Value
-		}
-
-		var displayName string
-		if user.DisplayName != nil {
-			displayName = *user.DisplayName
-		} else {
-			displayName = userName
-		}
-
-		identity := models.Identity{
-			ID:    userId,
-			Label: displayName,
-			User: &models.User{
-				ID:       userId,
-				Username: userName,
-				Email:    email,
-				Name:     displayName,
-				Source:   "aws-identity-center",
-			},
+		if identity := newIdentityCenterUserIdentity(user); identity != nil {
+			identities = append(identities, *identity)
 		}
-		identities = append(identities, identity)
 	}
 
 	response := &models.SynchronizeUsersResponse{
@@ -131,7 +87,7 @@ func (p *awsProvider) SynchronizeUsers(ctx context.Context, req *models.Synchron
 		}
 	}
 
-	logrus.WithFields(logrus.Fields{
+	log.WithFields(logrus.Fields{
 		"count": len(identities),
 	}).Debug("Refreshed AWS user identities")
 
@@ -29,7 +29,7 @@ func PreSynchronizeActivities(
 	req *models.SynchronizeRequest,
 ) error {
 
-	azureData, err := getSharedData()
+	azureData, err := getSharedData(ctx)
 
 	if err != nil {
 		return err
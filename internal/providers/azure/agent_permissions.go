@@ -0,0 +1,145 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// agentActions are the actions used to create role definitions and
+// assignments
+var agentActions = []string{
+	"Microsoft.Authorization/roleAssignments/read",
+	"Microsoft.Authorization/roleAssignments/write",
+	"Microsoft.Authorization/roleAssignments/delete",
+	"Microsoft.Authorization/roleDefinitions/read",
+	"Microsoft.Authorization/roleDefinitions/write",
+}
+
+// ValidateAgentPermissions lists the agent's effective permissions at the
+// scope roles are assigned at and checks each action is allowed
+func (p *azureProvider) ValidateAgentPermissions(ctx context.Context) ([]models.AgentPermission, error) {
+
+	client, err := arm.NewClient("thand-agent", "v1", p.cred.Token, nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure client: %w", err)
+	}
+
+	scope := p.getScope()
+	nextLink := runtime.JoinPaths(client.Endpoint(), scope,
+		"providers/Microsoft.Authorization/permissions") + "?api-version=2022-04-01"
+
+	grants := []*armauthorization.Permission{}
+
+	for len(nextLink) > 0 {
+
+		req, err := runtime.NewRequest(ctx, http.MethodGet, nextLink)
+
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Pipeline().Do(req)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list permissions on %s: %w", scope, err)
+		}
+
+		if !runtime.HasStatusCode(resp, http.StatusOK) {
+			return nil, fmt.Errorf("failed to list permissions on %s: %w", scope, runtime.NewResponseError(resp))
+		}
+
+		var result armauthorization.PermissionGetResult
+
+		if err := runtime.UnmarshalAsJSON(resp, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse permissions on %s: %w", scope, err)
+		}
+
+		grants = append(grants, result.Value...)
+
+		nextLink = ""
+		if result.NextLink != nil {
+			nextLink = *result.NextLink
+		}
+	}
+
+	permissions := make([]models.AgentPermission, 0, len(agentActions))
+
+	for _, action := range agentActions {
+
+		permission := models.AgentPermission{
+			Permission: action,
+			Resource:   scope,
+			Allowed:    isActionAllowed(grants, action),
+		}
+
+		if !permission.Allowed {
+			permission.Reason = "not granted by any role assignment"
+		}
+
+		permissions = append(permissions, permission)
+	}
+
+	return permissions, nil
+}
+
+// isActionAllowed returns true if a permission allows the action and
+// doesn't exclude it. Permissions from each role assignment are evaluated
+// separately, as Azure does.
+func isActionAllowed(grants []*armauthorization.Permission, action string) bool {
+	for _, grant := range grants {
+		if grant == nil {
+			continue
+		}
+		if matchesAnyAction(grant.Actions, action) && !matchesAnyAction(grant.NotActions, action) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyAction(patterns []*string, action string) bool {
+	for _, pattern := range patterns {
+		if pattern != nil && matchesAction(*pattern, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAction matches an action against a pattern where * matches any
+// characters e.g. Microsoft.Authorization/*/write
+func matchesAction(pattern string, action string) bool {
+
+	pattern = strings.ToLower(pattern)
+	action = strings.ToLower(action)
+
+	parts := strings.Split(pattern, "*")
+
+	if len(parts) == 1 {
+		return pattern == action
+	}
+
+	if !strings.HasPrefix(action, parts[0]) {
+		return false
+	}
+
+	action = action[len(parts[0]):]
+
+	for _, part := range parts[1 : len(parts)-1] {
+		index := strings.Index(action, part)
+		if index < 0 {
+			return false
+		}
+		action = action[index+len(part):]
+	}
+
+	return strings.HasSuffix(action, parts[len(parts)-1])
+}
@@ -0,0 +1,54 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesAction(t *testing.T) {
+
+	tests := []struct {
+		pattern string
+		action  string
+		want    bool
+	}{
+		{"*", "Microsoft.Authorization/roleAssignments/write", true},
+		{"Microsoft.Authorization/*", "Microsoft.Authorization/roleAssignments/write", true},
+		{"microsoft.authorization/*/write", "Microsoft.Authorization/roleAssignments/write", true},
+		{"Microsoft.Authorization/*/read", "Microsoft.Authorization/roleAssignments/write", false},
+		{"*/read", "Microsoft.Authorization/roleDefinitions/read", true},
+		{"Microsoft.Authorization/roleAssignments/write", "Microsoft.Authorization/roleAssignments/write", true},
+		{"Microsoft.Compute/*", "Microsoft.Authorization/roleAssignments/write", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchesAction(tt.pattern, tt.action), "%s matching %s", tt.pattern, tt.action)
+	}
+}
+
+func TestIsActionAllowed(t *testing.T) {
+
+	// Contributor allows everything except authorization writes
+	contributor := &armauthorization.Permission{
+		Actions:    []*string{to.Ptr("*")},
+		NotActions: []*string{to.Ptr("Microsoft.Authorization/*/Write"), to.Ptr("Microsoft.Authorization/*/Delete")},
+	}
+
+	grants := []*armauthorization.Permission{contributor}
+
+	assert.True(t, isActionAllowed(grants, "Microsoft.Authorization/roleAssignments/read"))
+	assert.False(t, isActionAllowed(grants, "Microsoft.Authorization/roleAssignments/write"))
+
+	// Another role assignment can grant what the first excludes
+	userAccessAdministrator := &armauthorization.Permission{
+		Actions: []*string{to.Ptr("Microsoft.Authorization/*")},
+	}
+
+	grants = append(grants, userAccessAdministrator)
+
+	assert.True(t, isActionAllowed(grants, "Microsoft.Authorization/roleAssignments/write"))
+	assert.False(t, isActionAllowed(nil, "Microsoft.Authorization/roleAssignments/read"))
+}
@@ -0,0 +1,71 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/google/uuid"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// ListManagedGrants lists the role assignments at the provider scope that
+// thand created. Their names are derived from the grant reference, see
+// getRoleAssignmentName, so they are the only name-based (version 5)
+// UUIDs. The reference can't be recovered from the name, and grants from
+// several elevations share an assignment, so they're matched by ID.
+func (p *azureProvider) ListManagedGrants(ctx context.Context) ([]models.ManagedGrant, error) {
+
+	grants := []models.ManagedGrant{}
+
+	pager := p.authClient.NewListForScopePager(p.getScope(), &armauthorization.RoleAssignmentsClientListForScopeOptions{
+		Filter: &[]string{"atScope()"}[0],
+	})
+
+	for pager.More() {
+
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list role assignments: %w", err)
+		}
+
+		for _, assignment := range page.Value {
+
+			if assignment.ID == nil || assignment.Name == nil || assignment.Properties == nil {
+				continue
+			}
+
+			if !isGrantRoleAssignmentName(*assignment.Name) {
+				continue
+			}
+
+			managed := models.ManagedGrant{
+				Grant: &models.GrantRef{
+					Provider: p.GetIdentifier(),
+					Kind:     GrantKindRoleAssignment,
+					IDs:      []string{*assignment.ID},
+				},
+				Shared: true,
+			}
+
+			if assignment.Properties.PrincipalID != nil {
+				managed.User = &models.User{ID: *assignment.Properties.PrincipalID}
+			}
+
+			if assignment.Properties.RoleDefinitionID != nil {
+				managed.Role = *assignment.Properties.RoleDefinitionID
+			}
+
+			grants = append(grants, managed)
+		}
+	}
+
+	return grants, nil
+}
+
+// isGrantRoleAssignmentName returns true if the assignment was named after
+// a grant reference
+func isGrantRoleAssignmentName(name string) bool {
+	id, err := uuid.Parse(name)
+	return err == nil && id.Version() == 5
+}
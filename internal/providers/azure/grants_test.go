@@ -0,0 +1,227 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/testing/contract"
+)
+
+const fakeRoleDefinitionID = "/subscriptions/test-subscription/providers/Microsoft.Authorization/roleDefinitions/reader"
+
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// fakeAuthorizationAPI serves role definitions and role assignments from
+// memory. Like Azure it rejects a second assignment of the same role to
+// the same principal.
+type fakeAuthorizationAPI struct {
+	mu          sync.Mutex
+	assignments map[string]armauthorization.RoleAssignment
+}
+
+func (f *fakeAuthorizationAPI) Do(req *http.Request) (*http.Response, error) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := req.URL.Path
+
+	switch {
+	case strings.Contains(path, "/roleDefinitions"):
+		return newFakeResponse(req, http.StatusOK, map[string]any{
+			"value": []map[string]any{{
+				"id":         fakeRoleDefinitionID,
+				"properties": map[string]any{"roleName": "Reader"},
+			}},
+		})
+	case strings.HasSuffix(path, "/roleAssignments"):
+		assignments := []armauthorization.RoleAssignment{}
+		for _, assignment := range f.assignments {
+			assignments = append(assignments, assignment)
+		}
+		return newFakeResponse(req, http.StatusOK, map[string]any{"value": assignments})
+	}
+
+	_, name, found := strings.Cut(path, "/roleAssignments/")
+	if !found {
+		return newFakeResponse(req, http.StatusNotFound, nil)
+	}
+
+	assignment, exists := f.assignments[name]
+
+	switch req.Method {
+	case http.MethodGet:
+		if !exists {
+			return newFakeError(req, http.StatusNotFound, "RoleAssignmentNotFound")
+		}
+		return newFakeResponse(req, http.StatusOK, assignment)
+	case http.MethodPut:
+		var params armauthorization.RoleAssignmentCreateParameters
+		body, _ := io.ReadAll(req.Body)
+		if err := json.Unmarshal(body, &params); err != nil {
+			return newFakeResponse(req, http.StatusBadRequest, nil)
+		}
+		for _, other := range f.assignments {
+			if *other.Properties.PrincipalID == *params.Properties.PrincipalID &&
+				*other.Properties.RoleDefinitionID == *params.Properties.RoleDefinitionID {
+				return newFakeError(req, http.StatusConflict, "RoleAssignmentExists")
+			}
+		}
+		id := fmt.Sprintf("/subscriptions/test-subscription/providers/Microsoft.Authorization/roleAssignments/%s", name)
+		assignment = armauthorization.RoleAssignment{
+			ID:   &id,
+			Name: &name,
+			Properties: &armauthorization.RoleAssignmentProperties{
+				PrincipalID:      params.Properties.PrincipalID,
+				RoleDefinitionID: params.Properties.RoleDefinitionID,
+				Description:      params.Properties.Description,
+			},
+		}
+		f.assignments[name] = assignment
+		return newFakeResponse(req, http.StatusCreated, assignment)
+	case http.MethodDelete:
+		if !exists {
+			return newFakeResponse(req, http.StatusNoContent, nil)
+		}
+		delete(f.assignments, name)
+		return newFakeResponse(req, http.StatusOK, assignment)
+	}
+
+	return newFakeResponse(req, http.StatusMethodNotAllowed, nil)
+}
+
+func (f *fakeAuthorizationAPI) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.assignments)
+}
+
+func newFakeResponse(req *http.Request, status int, body any) (*http.Response, error) {
+	data := []byte{}
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(string(data))),
+		Request:    req,
+	}, nil
+}
+
+func newFakeError(req *http.Request, status int, code string) (*http.Response, error) {
+	return newFakeResponse(req, status, map[string]any{
+		"error": map[string]any{"code": code, "message": code},
+	})
+}
+
+// newFakeProvider returns a provider whose role assignment and definition
+// clients call the fake API
+func newFakeProvider(t *testing.T, fake *fakeAuthorizationAPI) *azureProvider {
+	t.Helper()
+
+	options := &arm.ClientOptions{
+		ClientOptions:         policy.ClientOptions{Transport: fake},
+		DisableRPRegistration: true,
+	}
+
+	authClient, err := armauthorization.NewRoleAssignmentsClient("test-subscription", fakeCredential{}, options)
+	require.NoError(t, err)
+
+	roleDefClient, err := armauthorization.NewRoleDefinitionsClient(fakeCredential{}, options)
+	require.NoError(t, err)
+
+	return &azureProvider{
+		BaseProvider: models.NewBaseProvider("azure", models.Provider{
+			Name:     "azure",
+			Provider: AzureProviderName,
+		}, models.ProviderCapabilityRBAC),
+		authClient:     authClient,
+		roleDefClient:  roleDefClient,
+		subscriptionID: "test-subscription",
+	}
+}
+
+func newFakeRoleRequest(workflowID string) *models.RoleRequest {
+	return &models.RoleRequest{
+		User: &models.User{
+			// An object ID avoids the Microsoft Graph lookup
+			ID:    "0b7a4c55-7d5c-4c1a-9c43-5f3f1f0f2a11",
+			Email: "alice@example.com",
+		},
+		Role: &models.Role{Name: "Reader"},
+		Audit: &models.AuditContext{
+			WorkflowID: workflowID,
+		},
+	}
+}
+
+func TestAzureGrantContract(t *testing.T) {
+
+	fake := &fakeAuthorizationAPI{
+		assignments: map[string]armauthorization.RoleAssignment{},
+	}
+
+	contract.RunGrantContract(t, contract.GrantContract{
+		Provider:     newFakeProvider(t, fake),
+		NewRequest:   newFakeRoleRequest,
+		CountGrants:  fake.count,
+		SharedGrants: true,
+	})
+}
+
+func TestAuthorizeRoleDescription(t *testing.T) {
+
+	fake := &fakeAuthorizationAPI{
+		assignments: map[string]armauthorization.RoleAssignment{},
+	}
+	provider := newFakeProvider(t, fake)
+
+	req := newFakeRoleRequest("wf-1")
+	req.Audit.Requester = "alice@example.com"
+
+	authorized, err := provider.AuthorizeRole(context.Background(), &models.AuthorizeRoleRequest{
+		RoleRequest: req,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, authorized.Metadata)
+
+	require.Len(t, fake.assignments, 1)
+	for _, assignment := range fake.assignments {
+		require.NotNil(t, assignment.Properties.Description)
+		assert.Equal(t,
+			"This role assignment is managed by thand. "+req.Audit.GetJustification(),
+			*assignment.Properties.Description)
+		assert.Contains(t, *assignment.Properties.Description, "alice@example.com")
+	}
+}
+
+func TestNewRoleAssignmentDescription(t *testing.T) {
+	assert.Equal(t, "This role assignment is managed by thand", newRoleAssignmentDescription(nil))
+
+	// Multi-byte characters in the justification aren't split at the limit
+	description := newRoleAssignmentDescription(&models.AuditContext{
+		Reason: strings.Repeat("Zugriff für Störungsbehebung ", 100),
+	})
+	assert.True(t, utf8.ValidString(description))
+	assert.LessOrEqual(t, len(description), maxRoleAssignmentDescriptionLength)
+}
@@ -1,12 +1,14 @@
 package azure
 
 import (
+	"context"
+
 	_ "embed"
 	"fmt"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
 	"github.com/sirupsen/logrus"
 
@@ -30,7 +32,18 @@ type azureProvider struct {
 	resourceGroupName   string
 }
 
-func (p *azureProvider) Initialize(identifier string, provider models.Provider) error {
+// ValidateConfig checks the subscription is set and client credentials,
+// if used, are complete
+func (p *azureProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(AzureProviderName)
+	validation.Require(config, "subscription_id")
+	validation.RequireTogether(config, "client_id", "client_secret", "tenant_id")
+
+	return validation.ErrorOrNil()
+}
+
+func (p *azureProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	// Set the provider to the base provider
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
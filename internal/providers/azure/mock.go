@@ -19,7 +19,7 @@ func NewMockAzureProvider() *azureProviderMock {
 }
 
 // Initialize loads permissions and roles without connecting to Azure
-func (p *azureProviderMock) Initialize(identifier string, provider models.Provider) error {
+func (p *azureProviderMock) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	// Initialize the embedded azureProvider struct
 	p.azureProvider = &azureProvider{}
 
@@ -31,7 +31,7 @@ func (p *azureProviderMock) Initialize(identifier string, provider models.Provid
 	)
 
 	// Load Azure Permissions and Roles from shared singleton
-	if err := p.Synchronize(context.Background(), nil, nil); err != nil {
+	if err := p.Synchronize(ctx, nil, nil); err != nil {
 		return err
 	}
 
@@ -45,3 +45,15 @@ func (p *azureProviderMock) Synchronize(
 ) error {
 	return PreSynchronizeActivities(ctx, temporalService, p, req)
 }
+
+// ValidateConfig accepts any config as the mock doesn't connect to Azure.
+// Providers are created with reflection so the embedded provider is nil
+// until Initialize.
+func (p *azureProviderMock) ValidateConfig(config *models.BasicConfig) error {
+	return nil
+}
+
+// ValidateAgentPermissions skips the check as the mock has no Azure credentials
+func (p *azureProviderMock) ValidateAgentPermissions(ctx context.Context) ([]models.AgentPermission, error) {
+	return nil, models.ErrNotImplemented
+}
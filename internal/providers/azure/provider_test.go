@@ -29,7 +29,7 @@ func TestAzureProviderPermissions(t *testing.T) {
 
 	// Initialize the provider
 	provider := NewMockAzureProvider()
-	err := provider.Initialize("azure", testConfig)
+	err := provider.Initialize(context.Background(), "azure", testConfig)
 	require.NoError(t, err, "Failed to initialize Azure provider")
 
 	ctx := context.Background()
@@ -115,7 +115,7 @@ func TestAzureProviderRoles(t *testing.T) {
 
 	// Initialize the provider
 	provider := NewMockAzureProvider()
-	err := provider.Initialize("azure", testConfig)
+	err := provider.Initialize(context.Background(), "azure", testConfig)
 	require.NoError(t, err, "Failed to initialize Azure provider")
 
 	ctx := context.Background()
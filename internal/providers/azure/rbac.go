@@ -7,6 +7,10 @@ import (
 	"github.com/thand-io/agent/internal/models"
 )
 
+// GrantKindRoleAssignment is the grant kind for Azure role assignments, the
+// grant IDs are the role assignment IDs
+const GrantKindRoleAssignment = "role_assignment"
+
 // Authorize grants access for a user to a role
 func (p *azureProvider) AuthorizeRole(
 	ctx context.Context,
@@ -30,13 +34,22 @@ func (p *azureProvider) AuthorizeRole(
 		}
 	}
 
-	// Create role assignment for the user
-	err = p.createRoleAssignment(ctx, user, *existingRole.ID)
+	grant := models.NewGrantRef(p.GetIdentifier(), GrantKindRoleAssignment, req.RoleRequest)
+
+	// Create role assignment for the user. The requester and workflow are
+	// recorded in its description so the grant can be attributed in the
+	// Azure activity log.
+	roleAssignmentID, err := p.createRoleAssignment(ctx, user, *existingRole.ID, grant.Reference,
+		newRoleAssignmentDescription(req.GetAudit()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create role assignment: %w", err)
 	}
 
-	return nil, nil
+	grant.AddID(roleAssignmentID)
+
+	return &models.AuthorizeRoleResponse{
+		GrantRef: grant,
+	}, nil
 }
 
 // Revoke removes access for a user from a role
@@ -49,6 +62,16 @@ func (p *azureProvider) RevokeRole(
 		return nil, fmt.Errorf("user and role must be provided to revoke azure role")
 	}
 
+	// Remove exactly the role assignment recorded by the grant
+	if grant := req.GetGrantRef(); grant.HasIDs() {
+		for _, roleAssignmentID := range grant.IDs {
+			if err := p.deleteRoleAssignmentByID(ctx, roleAssignmentID); err != nil {
+				return nil, fmt.Errorf("failed to delete role assignment: %w", err)
+			}
+		}
+		return nil, nil
+	}
+
 	user := req.GetUser()
 	role := req.GetRole()
 
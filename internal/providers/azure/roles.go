@@ -2,13 +2,17 @@ package azure
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
 	"github.com/google/uuid"
 	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
 	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/models"
 )
 
@@ -70,30 +74,107 @@ func (p *azureProvider) createRoleDefinition(ctx context.Context, roleName, desc
 	return &result.RoleDefinition, nil
 }
 
-// createRoleAssignment assigns a role to a user
-func (p *azureProvider) createRoleAssignment(ctx context.Context, user *models.User, roleDefinitionID string) error {
+// maxRoleAssignmentDescriptionLength caps the bytes recorded in a role
+// assignment description so a long reason doesn't fail the grant
+const maxRoleAssignmentDescriptionLength = 1024
+
+// newRoleAssignmentDescription describes a role assignment created by thand,
+// with the requester and workflow when an audit context is provided
+func newRoleAssignmentDescription(audit *models.AuditContext) string {
+	description := "This role assignment is managed by thand"
+	if justification := audit.GetJustification(); len(justification) > 0 {
+		description = fmt.Sprintf("%s. %s", description, justification)
+	}
+	return common.TruncateBytes(description, maxRoleAssignmentDescriptionLength)
+}
+
+// createRoleAssignment assigns a role to a user. The assignment is named from
+// the grant reference so a retry finds the assignment it already created.
+// Returns the ID of the role assignment.
+func (p *azureProvider) createRoleAssignment(ctx context.Context, user *models.User, roleDefinitionID string, reference string, description string) (string, error) {
 	scope := p.getScope()
 
 	// Get the principal ID for the user
 	principalID, err := p.getUserPrincipalID(ctx, user)
 	if err != nil {
-		return fmt.Errorf("failed to get user principal ID: %w", err)
+		return "", fmt.Errorf("failed to get user principal ID: %w", err)
+	}
+
+	roleAssignmentID := getRoleAssignmentName(reference)
+
+	existing, err := p.authClient.Get(ctx, scope, roleAssignmentID, nil)
+	if err == nil && existing.ID != nil {
+		return *existing.ID, nil
 	}
 
-	roleAssignmentID := uuid.New().String()
 	roleAssignment := armauthorization.RoleAssignmentCreateParameters{
 		Properties: &armauthorization.RoleAssignmentProperties{
 			RoleDefinitionID: &roleDefinitionID,
 			PrincipalID:      &principalID,
+			Description:      &description,
 		},
 	}
 
-	_, err = p.authClient.Create(ctx, scope, roleAssignmentID, roleAssignment, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create role assignment: %w", err)
+	created, err := p.authClient.Create(ctx, scope, roleAssignmentID, roleAssignment, nil)
+	if err == nil {
+		return *created.ID, nil
 	}
 
-	return nil
+	// Azure only allows one assignment of a role to a principal at a scope,
+	// so another grant may already have assigned it
+	var responseErr *azcore.ResponseError
+	if errors.As(err, &responseErr) && responseErr.ErrorCode == "RoleAssignmentExists" {
+		return p.findRoleAssignment(ctx, principalID, roleDefinitionID)
+	}
+
+	return "", fmt.Errorf("failed to create role assignment: %w", err)
+}
+
+// findRoleAssignment returns the ID of the assignment of the role to the
+// principal at the provider scope
+func (p *azureProvider) findRoleAssignment(ctx context.Context, principalID string, roleDefinitionID string) (string, error) {
+	scope := p.getScope()
+
+	pager := p.authClient.NewListForScopePager(scope, &armauthorization.RoleAssignmentsClientListForScopeOptions{
+		Filter: &[]string{fmt.Sprintf("principalId eq '%s'", principalID)}[0],
+	})
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to list role assignments: %w", err)
+		}
+
+		for _, assignment := range page.Value {
+			if assignment.ID != nil && assignment.Properties != nil &&
+				assignment.Properties.RoleDefinitionID != nil &&
+				*assignment.Properties.RoleDefinitionID == roleDefinitionID {
+				return *assignment.ID, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("role assignment for principal %s not found", principalID)
+}
+
+// deleteRoleAssignmentByID removes the role assignment recorded by a grant.
+// An assignment that no longer exists is considered already revoked.
+func (p *azureProvider) deleteRoleAssignmentByID(ctx context.Context, roleAssignmentID string) error {
+
+	_, err := p.authClient.DeleteByID(ctx, roleAssignmentID, nil)
+
+	var responseErr *azcore.ResponseError
+	if errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	return err
+}
+
+// getRoleAssignmentName returns the role assignment name for a grant.
+// Assignment names must be GUIDs so one is derived from the reference.
+func getRoleAssignmentName(reference string) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(reference)).String()
 }
 
 // deleteRoleAssignment removes a role assignment for a user
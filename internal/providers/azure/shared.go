@@ -1,6 +1,7 @@
 package azure
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -19,35 +20,46 @@ type azureData struct {
 }
 
 var (
-	sharedData     *azureData
-	sharedDataOnce sync.Once
-	sharedDataErr  error
+	sharedData    *azureData
+	sharedDataMu  sync.Mutex
+	sharedDataErr error
 )
 
-func getSharedData() (*azureData, error) {
-	sharedDataOnce.Do(func() {
-		sharedData = &azureData{
-			indexReady: make(chan struct{}),
-		}
-		var err error
+// loadBatchSize is how many entries are parsed between cancellation checks
+const loadBatchSize = 1000
 
-		sharedData.permissions, err = loadPermissions()
-		if err != nil {
-			sharedDataErr = err
-			return
-		}
+func getSharedData(ctx context.Context) (*azureData, error) {
+	sharedDataMu.Lock()
+	defer sharedDataMu.Unlock()
+
+	if sharedData != nil || sharedDataErr != nil {
+		return sharedData, sharedDataErr
+	}
+
+	data := &azureData{
+		indexReady: make(chan struct{}),
+	}
+	var err error
+
+	data.permissions, err = loadPermissions(ctx)
+	if err == nil {
+		data.roles, err = loadRoles(ctx)
+	}
 
-		sharedData.roles, err = loadRoles()
-		if err != nil {
+	if err != nil {
+		// A cancelled load isn't cached so the next caller can retry it
+		if ctx.Err() == nil {
 			sharedDataErr = err
-			return
 		}
+		return nil, err
+	}
+
+	sharedData = data
 
-	})
-	return sharedData, sharedDataErr
+	return sharedData, nil
 }
 
-func loadPermissions() ([]models.ProviderPermission, error) {
+func loadPermissions(ctx context.Context) ([]models.ProviderPermission, error) {
 
 	startTime := time.Now()
 	defer func() {
@@ -63,7 +75,14 @@ func loadPermissions() ([]models.ProviderPermission, error) {
 
 	var permissions []models.ProviderPermission
 
-	for _, operation := range azureOperations {
+	for i, operation := range azureOperations {
+
+		if i%loadBatchSize == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("loading Azure permissions cancelled: %w", err)
+			}
+		}
+
 		permission := models.ProviderPermission{
 			ID:          strings.ToLower(operation.Name),
 			Name:        operation.Name,
@@ -75,7 +94,7 @@ func loadPermissions() ([]models.ProviderPermission, error) {
 	return permissions, nil
 }
 
-func loadRoles() ([]models.ProviderRole, error) {
+func loadRoles(ctx context.Context) ([]models.ProviderRole, error) {
 
 	startTime := time.Now()
 	defer func() {
@@ -91,7 +110,14 @@ func loadRoles() ([]models.ProviderRole, error) {
 
 	var roles []models.ProviderRole
 
-	for _, role := range azureRoles {
+	for i, role := range azureRoles {
+
+		if i%loadBatchSize == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("loading Azure roles cancelled: %w", err)
+			}
+		}
+
 		r := models.ProviderRole{
 			Name:        role.Name,
 			Description: role.Description,
@@ -20,18 +20,37 @@ type cloudflareProvider struct {
 	accountID string
 }
 
-func (p *cloudflareProvider) Initialize(identifier string, provider models.Provider) error {
+// ValidateConfig checks the account is set along with an API token or an
+// API key and email
+func (p *cloudflareProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(CloudflareProviderName)
+	validation.Require(config, "account_id")
+
+	if !config.HasString("api_token") {
+		if !config.HasString("api_key") && !config.HasString("email") {
+			validation.Add("api_token", "is required unless api_key and email are set")
+		} else {
+			validation.RequireTogether(config, "api_key", "email")
+		}
+	}
+
+	return validation.ErrorOrNil()
+}
+
+func (p *cloudflareProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
 		provider,
 		models.ProviderCapabilityRBAC,
+		models.ProviderCapabilityResourceDiscovery,
 	)
 
 	// Get configuration
 	cfConfig := p.GetConfig()
 
 	// Create Cloudflare API client
-	client, accountID, err := CreateCloudflareClient(cfConfig)
+	client, accountID, err := CreateCloudflareClient(ctx, cfConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create Cloudflare client: %w", err)
 	}
@@ -54,7 +73,7 @@ func (p *cloudflareProvider) Initialize(identifier string, provider models.Provi
 }
 
 // CreateCloudflareClient creates a Cloudflare API client from configuration
-func CreateCloudflareClient(cfConfig *models.BasicConfig) (*cloudflare.API, string, error) {
+func CreateCloudflareClient(ctx context.Context, cfConfig *models.BasicConfig) (*cloudflare.API, string, error) {
 	// Check for API token (recommended method)
 	apiToken, foundToken := cfConfig.GetString("api_token")
 
@@ -86,7 +105,6 @@ func CreateCloudflareClient(cfConfig *models.BasicConfig) (*cloudflare.API, stri
 	}
 
 	// Verify the client works by making a test call
-	ctx := context.Background()
 	_, _, err = api.Accounts(ctx, cloudflare.AccountsListParams{})
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to verify Cloudflare credentials: %w", err)
@@ -2,6 +2,7 @@ package cloudflare
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -16,6 +17,10 @@ import (
 const CloudflareAllow = "allow"
 const CloudflareDeny = "deny"
 
+// GrantKindAccountMember is the grant kind for Cloudflare, the grant IDs are
+// the account member IDs
+const GrantKindAccountMember = "account_member"
+
 // AuthorizeRole grants access for a user to a role in Cloudflare
 // Supports both account-wide roles and resource-scoped policies
 func (p *cloudflareProvider) AuthorizeRole(
@@ -85,12 +90,16 @@ func (p *cloudflareProvider) AuthorizeRole(
 			"member_id": updatedMember.ID,
 		}).Info("Successfully updated Cloudflare role")
 
+		grant := models.NewGrantRef(p.GetIdentifier(), GrantKindAccountMember, req.RoleRequest)
+		grant.AddID(updatedMember.ID)
+
 		return &models.AuthorizeRoleResponse{
 			Metadata: map[string]any{
 				"member_id": updatedMember.ID,
 				"status":    updatedMember.Status,
 				"updated":   true,
 			},
+			GrantRef: grant,
 		}, nil
 	}
 
@@ -116,12 +125,16 @@ func (p *cloudflareProvider) AuthorizeRole(
 		"member_id": member.ID,
 	}).Info("Successfully authorized Cloudflare role")
 
+	grant := models.NewGrantRef(p.GetIdentifier(), GrantKindAccountMember, req.RoleRequest)
+	grant.AddID(member.ID)
+
 	// Return metadata about the authorization
 	return &models.AuthorizeRoleResponse{
 		Metadata: map[string]any{
 			"member_id": member.ID,
 			"status":    member.Status,
 		},
+		GrantRef: grant,
 	}, nil
 }
 
@@ -145,6 +158,17 @@ func (p *cloudflareProvider) RevokeRole(
 		"role": role.Name,
 	}).Info("Revoking Cloudflare role")
 
+	// Remove exactly the members recorded by the grant
+	if grant := req.GetGrantRef(); grant.HasIDs() {
+		for _, memberID := range grant.IDs {
+			err := p.deleteAccountMember(ctx, memberID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return &models.RevokeRoleResponse{}, nil
+	}
+
 	// Get the member ID from the authorization metadata if available
 	var memberID string
 	if req.AuthorizeRoleResponse != nil && req.AuthorizeRoleResponse.Metadata != nil {
@@ -193,6 +217,24 @@ func (p *cloudflareProvider) RevokeRole(
 	return &models.RevokeRoleResponse{}, nil
 }
 
+// deleteAccountMember removes the member from the account. A member that no
+// longer exists is considered already revoked.
+func (p *cloudflareProvider) deleteAccountMember(ctx context.Context, memberID string) error {
+
+	err := p.client.DeleteAccountMember(ctx, p.GetAccountID(), memberID)
+
+	var notFound *cloudflare.NotFoundError
+	if errors.As(err, &notFound) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to delete account member: %w", err)
+	}
+
+	return nil
+}
+
 // findAccountMember finds an existing account member by email
 func (p *cloudflareProvider) findAccountMember(ctx context.Context, email string) (*cloudflare.AccountMember, error) {
 	accountID := p.GetAccountID()
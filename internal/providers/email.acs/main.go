@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/thand-io/agent/internal/common"
@@ -25,7 +26,18 @@ type emailAcsProvider struct {
 	credential         *azureProvider.AzureConfigurationProvider
 }
 
-func (p *emailAcsProvider) Initialize(identifier string, provider models.Provider) error {
+// ValidateConfig checks the endpoint and sender are set and client
+// credentials, if used, are complete
+func (p *emailAcsProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(EmailAcsProviderName)
+	validation.Require(config, "endpoint", "from")
+	validation.RequireTogether(config, "client_id", "client_secret", "tenant_id")
+
+	return validation.ErrorOrNil()
+}
+
+func (p *emailAcsProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
@@ -66,39 +78,43 @@ func (p *emailAcsProvider) Initialize(identifier string, provider models.Provide
 
 func (p *emailAcsProvider) SendNotification(
 	ctx context.Context, notification models.NotificationRequest,
-) error {
+) (*models.NotificationReceipt, error) {
 
 	// Convert NotificationRequest to EmailNotificationRequest
 	emailRequest := &models.EmailNotificationRequest{}
 	common.ConvertMapToInterface(notification, emailRequest)
 
-	// Validate recipients
-	if len(emailRequest.To) == 0 {
-		return fmt.Errorf("at least one recipient is required")
-	}
-
 	// Determine from address
 	fromAddress := p.defaultFromAddress
 	if len(emailRequest.From) > 0 {
 		fromAddress = emailRequest.From
 	}
 
+	return models.SendEmailMessages(ctx, emailRequest, func(ctx context.Context, message models.EmailMessage) (*models.NotificationReceipt, error) {
+		return p.sendMessage(ctx, fromAddress, message)
+	})
+}
+
+func (p *emailAcsProvider) sendMessage(
+	ctx context.Context, fromAddress string, message models.EmailMessage,
+) (*models.NotificationReceipt, error) {
+
 	// Build the email message for Azure Communication Services API
-	recipients := make([]map[string]string, len(emailRequest.To))
-	for i, to := range emailRequest.To {
+	recipients := make([]map[string]string, len(message.To))
+	for i, to := range message.To {
 		recipients[i] = map[string]string{"address": to}
 	}
 
 	// Build content based on what's available
 	content := map[string]string{
-		"subject": emailRequest.Subject,
+		"subject": message.Subject,
 	}
 
-	if len(emailRequest.Body.HTML) > 0 {
-		content["html"] = emailRequest.Body.HTML
+	if len(message.Body.HTML) > 0 {
+		content["html"] = message.Body.HTML
 	}
-	if len(emailRequest.Body.Text) > 0 {
-		content["plainText"] = emailRequest.Body.Text
+	if len(message.Body.Text) > 0 {
+		content["plainText"] = message.Body.Text
 	}
 
 	emailMessage := map[string]any{
@@ -112,7 +128,7 @@ func (p *emailAcsProvider) SendNotification(
 	// Marshal the request body
 	requestBody, err := json.Marshal(emailMessage)
 	if err != nil {
-		return fmt.Errorf("failed to marshal email request: %w", err)
+		return nil, fmt.Errorf("failed to marshal email request: %w", err)
 	}
 
 	// Get access token
@@ -120,14 +136,14 @@ func (p *emailAcsProvider) SendNotification(
 		Scopes: []string{"https://communication.azure.com/.default"},
 	})
 	if err != nil {
-		return fmt.Errorf("failed to get access token: %w", err)
+		return nil, fmt.Errorf("failed to get access token: %w", err)
 	}
 
 	// Send email using Azure Communication Services REST API
 	url := fmt.Sprintf("%s/emails:send?api-version=2023-03-31", p.endpoint)
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
 	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %w", err)
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -136,16 +152,36 @@ func (p *emailAcsProvider) SendNotification(
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send email via Azure Communication Services: %w", err)
+		return nil, fmt.Errorf("failed to send email via Azure Communication Services: %w", err)
 	}
 	defer resp.Body.Close()
 
+	bodyBytes, _ := io.ReadAll(resp.Body)
+
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to send email via Azure Communication Services: status %d, body: %s", resp.StatusCode, string(bodyBytes))
+		return nil, fmt.Errorf("failed to send email via Azure Communication Services: status %d, body: %s", resp.StatusCode, string(bodyBytes))
 	}
 
-	return nil
+	return &models.NotificationReceipt{
+		Target:    strings.Join(message.To, ", "),
+		MessageID: getOperationID(resp, bodyBytes),
+		Response:  resp.Status,
+	}, nil
+}
+
+// getOperationID returns the ID of the send operation from the 202 response
+// body, or the Operation-Location header if the body doesn't have it
+func getOperationID(resp *http.Response, body []byte) string {
+
+	var operation struct {
+		ID string `json:"id"`
+	}
+
+	if err := json.Unmarshal(body, &operation); err == nil && len(operation.ID) > 0 {
+		return operation.ID
+	}
+
+	return resp.Header.Get("Operation-Location")
 }
 
 func NewEmailAcsProvider() models.ProviderImpl {
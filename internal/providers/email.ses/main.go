@@ -3,6 +3,7 @@ package email_ses
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sesv2"
@@ -22,7 +23,18 @@ type emailSesProvider struct {
 	defaultFromAddress string
 }
 
-func (p *emailSesProvider) Initialize(identifier string, provider models.Provider) error {
+// ValidateConfig checks the sender is set and static AWS credentials, if
+// used, are complete
+func (p *emailSesProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(EmailSesProviderName)
+	validation.Require(config, "from")
+	validation.RequireTogether(config, "access_key_id", "secret_access_key")
+
+	return validation.ErrorOrNil()
+}
+
+func (p *emailSesProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
@@ -59,70 +71,68 @@ func (p *emailSesProvider) Initialize(identifier string, provider models.Provide
 
 func (p *emailSesProvider) SendNotification(
 	ctx context.Context, notification models.NotificationRequest,
-) error {
+) (*models.NotificationReceipt, error) {
 
 	// Convert NotificationRequest to EmailNotificationRequest
 	emailRequest := &models.EmailNotificationRequest{}
 	common.ConvertMapToInterface(notification, emailRequest)
 
-	// Validate recipients
-	if len(emailRequest.To) == 0 {
-		return fmt.Errorf("at least one recipient is required")
+	// Determine from address
+	fromAddress := p.defaultFromAddress
+	if len(emailRequest.From) > 0 {
+		fromAddress = emailRequest.From
 	}
 
-	// Prepare email content
-	emailContent := &types.EmailContent{}
+	return models.SendEmailMessages(ctx, emailRequest, func(ctx context.Context, message models.EmailMessage) (*models.NotificationReceipt, error) {
+		return p.sendMessage(ctx, fromAddress, message)
+	})
+}
+
+func (p *emailSesProvider) sendMessage(
+	ctx context.Context, fromAddress string, message models.EmailMessage,
+) (*models.NotificationReceipt, error) {
 
 	// Build the body
 	body := &types.Body{}
-	if len(emailRequest.Body.Text) > 0 {
+	if len(message.Body.Text) > 0 {
 		body.Text = &types.Content{
-			Data:    aws.String(emailRequest.Body.Text),
+			Data:    aws.String(message.Body.Text),
 			Charset: aws.String("UTF-8"),
 		}
 	}
-	if len(emailRequest.Body.HTML) > 0 {
+	if len(message.Body.HTML) > 0 {
 		body.Html = &types.Content{
-			Data:    aws.String(emailRequest.Body.HTML),
+			Data:    aws.String(message.Body.HTML),
 			Charset: aws.String("UTF-8"),
 		}
 	}
 
-	// Build the message
-	message := &types.Message{
-		Subject: &types.Content{
-			Data:    aws.String(emailRequest.Subject),
-			Charset: aws.String("UTF-8"),
-		},
-		Body: body,
-	}
-
-	emailContent.Simple = message
-
-	// Prepare destination
-	destination := &types.Destination{
-		ToAddresses: emailRequest.To,
-	}
-
-	// Determine from address
-	fromAddress := p.defaultFromAddress
-	if len(emailRequest.From) > 0 {
-		fromAddress = emailRequest.From
-	}
-
 	// Send email using SES
 	input := &sesv2.SendEmailInput{
 		FromEmailAddress: aws.String(fromAddress),
-		Destination:      destination,
-		Content:          emailContent,
+		Destination: &types.Destination{
+			ToAddresses: message.To,
+		},
+		Content: &types.EmailContent{
+			Simple: &types.Message{
+				Subject: &types.Content{
+					Data:    aws.String(message.Subject),
+					Charset: aws.String("UTF-8"),
+				},
+				Body: body,
+			},
+		},
 	}
 
-	_, err := p.sesClient.SendEmail(ctx, input)
+	output, err := p.sesClient.SendEmail(ctx, input)
 	if err != nil {
-		return fmt.Errorf("failed to send email via SES: %w", err)
+		return nil, fmt.Errorf("failed to send email via SES: %w", err)
 	}
 
-	return nil
+	return &models.NotificationReceipt{
+		Target:    strings.Join(message.To, ", "),
+		MessageID: aws.ToString(output.MessageId),
+	}, nil
 }
 
 func NewEmailSesProvider() models.ProviderImpl {
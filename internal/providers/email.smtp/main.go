@@ -3,6 +3,7 @@ package email_smtp
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"crypto/tls"
 
@@ -22,7 +23,27 @@ type emailSmtpProvider struct {
 	defaultFromAddress string
 }
 
-func (p *emailSmtpProvider) Initialize(identifier string, provider models.Provider) error {
+// ValidateConfig checks the SMTP server, login and sender are set
+func (p *emailSmtpProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(EmailSmtpProviderName)
+	validation.Require(config, "host", "port", "user", "pass", "from")
+
+	if config.HasString("port") {
+		if _, found := config.GetInt("port"); !found {
+			validation.Add("port", "must be a number")
+		}
+	}
+
+	return validation.ErrorOrNil()
+}
+
+// GetSecretKeys marks the SMTP password as a secret
+func (p *emailSmtpProvider) GetSecretKeys() []string {
+	return []string{"pass"}
+}
+
+func (p *emailSmtpProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
@@ -80,27 +101,54 @@ func (p *emailSmtpProvider) Initialize(identifier string, provider models.Provid
 
 func (p *emailSmtpProvider) SendNotification(
 	ctx context.Context, notification models.NotificationRequest,
-) error {
+) (*models.NotificationReceipt, error) {
 
 	// Lets convert NotificationRequest to EmailNotificationRequest
 	emailRequest := &models.EmailNotificationRequest{}
 	common.ConvertMapToInterface(notification, emailRequest)
 
+	notificationID := notification.GetNotificationID()
+
+	return models.SendEmailMessages(ctx, emailRequest, func(ctx context.Context, message models.EmailMessage) (*models.NotificationReceipt, error) {
+
+		messageID := notificationID
+
+		// Each individual message needs its own Message-ID
+		if emailRequest.Individual && len(notificationID) > 0 {
+			messageID = models.NewNotificationID(notificationID, strings.Join(message.To, ","))
+		}
+
+		return p.sendMessage(emailRequest, message, messageID)
+	})
+}
+
+func (p *emailSmtpProvider) sendMessage(
+	emailRequest *models.EmailNotificationRequest,
+	message models.EmailMessage,
+	messageID string,
+) (*models.NotificationReceipt, error) {
+
 	m := gomail.NewMessage()
 
-	if len(emailRequest.Body.Text) > 0 {
-		m.SetBody("text/plain", emailRequest.Body.Text, gomail.SetPartEncoding(gomail.Unencoded))
+	if len(message.Body.Text) > 0 {
+		m.SetBody("text/plain", message.Body.Text, gomail.SetPartEncoding(gomail.Unencoded))
 	}
-	if len(emailRequest.Body.HTML) > 0 {
-		m.SetBody("text/html", emailRequest.Body.HTML, gomail.SetPartEncoding(gomail.Unencoded))
+	if len(message.Body.HTML) > 0 {
+		m.SetBody("text/html", message.Body.HTML, gomail.SetPartEncoding(gomail.Unencoded))
 	}
 
 	if len(emailRequest.Headers) > 0 {
 		m.SetHeaders(emailRequest.Headers)
 	}
 
-	if len(emailRequest.Subject) > 0 {
-		m.SetHeader("Subject", emailRequest.Subject)
+	if len(message.Subject) > 0 {
+		m.SetHeader("Subject", message.Subject)
+	}
+
+	// A stable Message-ID lets mail servers and clients drop duplicates
+	// if a notification is retried
+	if len(messageID) > 0 && len(m.GetHeader("Message-ID")) == 0 {
+		m.SetHeader("Message-ID", fmt.Sprintf("<%s@thand.io>", messageID))
 	}
 
 	// From field is required
@@ -110,19 +158,22 @@ func (p *emailSmtpProvider) SendNotification(
 		m.SetAddressHeader("From", p.defaultFromAddress, "")
 	}
 
-	// Set multiple recipients
-	if len(emailRequest.To) == 0 {
-		return fmt.Errorf("at least one recipient is required")
-	}
-	m.SetHeader("To", emailRequest.To...)
+	m.SetHeader("To", message.To...)
 
 	err := p.mailer.DialAndSend(m)
 
 	if err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
+		return nil, fmt.Errorf("failed to send email: %w", err)
 	}
 
-	return nil
+	// The mailer only returns once the server accepts the message with a
+	// 250 reply. The reply text e.g. the queue ID isn't exposed, so the
+	// Message-ID header identifies the message instead
+	return &models.NotificationReceipt{
+		Target:    strings.Join(message.To, ", "),
+		MessageID: strings.Join(m.GetHeader("Message-ID"), ", "),
+		Response:  "250",
+	}, nil
 }
 
 func NewEmailSmtpProvider() models.ProviderImpl {
@@ -19,7 +19,23 @@ type emailProvider struct {
 	proxy models.ProviderImpl
 }
 
-func (p *emailProvider) Initialize(identifier string, provider models.Provider) error {
+// ValidateConfig checks the platform is supported and validates the
+// config with the platform's provider
+func (p *emailProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(EmailProviderName)
+	validation.OneOf(config, "platform", "smtp", "ses", "acs")
+
+	if err := validation.ErrorOrNil(); err != nil {
+		return err
+	}
+
+	platformType := config.GetStringWithDefault("platform", "smtp")
+
+	return newEmailPlatformProvider(platformType).ValidateConfig(config)
+}
+
+func (p *emailProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
@@ -35,32 +51,44 @@ func (p *emailProvider) Initialize(identifier string, provider models.Provider)
 	// Get platform specific configuration
 	platformType := emailerConfig.GetStringWithDefault("platform", "smtp")
 
-	switch platformType {
-	case "ses":
-		p.proxy = ses.NewEmailSesProvider()
-	case "acs":
-		p.proxy = emailacs.NewEmailAcsProvider()
-	case "smtp":
-		fallthrough
-	default:
-		p.proxy = smtp.NewEmailSmtpProvider()
-	}
+	p.proxy = newEmailPlatformProvider(platformType)
 
 	if p.proxy == nil {
 		return fmt.Errorf("failed to initialize email proxy for platform: %s", platformType)
 	}
 
-	return p.proxy.Initialize(identifier, provider)
+	return p.proxy.Initialize(ctx, identifier, provider)
 }
 func (p *emailProvider) SendNotification(
 	ctx context.Context, notification models.NotificationRequest,
-) error {
+) (*models.NotificationReceipt, error) {
 
 	if p.proxy == nil {
-		return fmt.Errorf("email provider proxy is not initialized")
+		return nil, fmt.Errorf("email provider proxy is not initialized")
+	}
+
+	notifier, err := models.AsNotifier(p.proxy)
+
+	if err != nil {
+		return nil, err
 	}
 
-	return p.proxy.SendNotification(ctx, notification)
+	return notifier.SendNotification(ctx, notification)
+}
+
+// newEmailPlatformProvider returns the provider that sends email for the
+// platform, SMTP by default
+func newEmailPlatformProvider(platformType string) models.ProviderImpl {
+	switch platformType {
+	case "ses":
+		return ses.NewEmailSesProvider()
+	case "acs":
+		return emailacs.NewEmailAcsProvider()
+	case "smtp":
+		fallthrough
+	default:
+		return smtp.NewEmailSmtpProvider()
+	}
 }
 
 func init() {
@@ -2,6 +2,7 @@ package email
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"time"
 
@@ -167,7 +168,7 @@ func NewMockEmailProviderWithInterceptor(interceptor *MockEmailInterceptor) mode
 }
 
 // Initialize sets up the mock email provider
-func (p *emailProviderMock) Initialize(identifier string, provider models.Provider) error {
+func (p *emailProviderMock) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
 		provider,
@@ -188,7 +189,7 @@ func (p *emailProviderMock) Initialize(identifier string, provider models.Provid
 // SendNotification captures the email instead of sending it
 func (p *emailProviderMock) SendNotification(
 	ctx context.Context, notification models.NotificationRequest,
-) error {
+) (*models.NotificationReceipt, error) {
 	// Convert NotificationRequest to EmailNotificationRequest
 	emailRequest := &models.EmailNotificationRequest{}
 	common.ConvertMapToInterface(notification, emailRequest)
@@ -199,27 +200,32 @@ func (p *emailProviderMock) SendNotification(
 		fromAddress = emailRequest.From
 	}
 
-	// Create the mock message
-	msg := MockEmailMessage{
-		From:      fromAddress,
-		To:        emailRequest.To,
-		Subject:   emailRequest.Subject,
-		Body:      emailRequest.Body.Text,
-		HTML:      emailRequest.Body.HTML,
-		Timestamp: time.Now(),
-		Request:   notification,
-	}
+	return models.SendEmailMessages(ctx, emailRequest, func(ctx context.Context, message models.EmailMessage) (*models.NotificationReceipt, error) {
+
+		// Create the mock message
+		msg := MockEmailMessage{
+			From:      fromAddress,
+			To:        message.To,
+			Subject:   message.Subject,
+			Body:      message.Body.Text,
+			HTML:      message.Body.HTML,
+			Timestamp: time.Now(),
+			Request:   notification,
+		}
 
-	// Add to interceptor
-	p.interceptor.AddMessage(msg)
+		// Add to interceptor
+		p.interceptor.AddMessage(msg)
 
-	logrus.WithFields(logrus.Fields{
-		"from":    msg.From,
-		"to":      msg.To,
-		"subject": msg.Subject,
-	}).Info("Mock email provider captured notification")
+		logrus.WithFields(logrus.Fields{
+			"from":    msg.From,
+			"to":      msg.To,
+			"subject": msg.Subject,
+		}).Info("Mock email provider captured notification")
 
-	return nil
+		return &models.NotificationReceipt{
+			Target: strings.Join(msg.To, ", "),
+		}, nil
+	})
 }
 
 // GetInterceptor returns the email interceptor for test assertions
@@ -38,7 +38,7 @@ type exampleProvider struct {
 	*models.BaseProvider
 }
 
-func (p *exampleProvider) Initialize(identifier string, provider models.Provider) error {
+func (p *exampleProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
 		provider,
@@ -2,6 +2,7 @@ package gcp
 
 import (
 	"context"
+	"slices"
 
 	"github.com/thand-io/agent/internal/models"
 )
@@ -18,8 +19,18 @@ func (p *gcpProvider) Synchronize(
 	req *models.SynchronizeRequest,
 ) error {
 
+	log := p.GetLogger(ctx)
+
+	// Google Groups matching the group_query are listed alongside the
+	// predefined roles so they can be picked in the wizard
+	groupRoles, err := p.listGroupRoles(ctx)
+
+	if err != nil {
+		log.WithError(err).Warn("Failed to list GCP groups as roles")
+	}
+
 	// Before we kick off the synchronize lets update the static roles and permissions
-	return PreSynchronizeActivities(ctx, temporalService, p, req)
+	return PreSynchronizeActivities(ctx, temporalService, p, req, groupRoles...)
 }
 
 func PreSynchronizeActivities(
@@ -27,18 +38,19 @@ func PreSynchronizeActivities(
 	temporalService models.TemporalImpl,
 	provider models.ProviderImpl,
 	req *models.SynchronizeRequest,
+	extraRoles ...models.ProviderRole,
 ) error {
 
 	config := provider.GetConfig()
 	stage := config.GetStringWithDefault("stage", "GA")
 
-	gcpData, err := getSharedData(stage)
+	gcpData, err := getSharedData(ctx, stage)
 
 	if err != nil {
 		return err
 	}
 
-	provider.SetRoles(gcpData.roles)
+	provider.SetRoles(append(slices.Clone(gcpData.roles), extraRoles...))
 	provider.SetPermissions(gcpData.permissions)
 
 	return models.Synchronize(ctx, temporalService, provider, req)
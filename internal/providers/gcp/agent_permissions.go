@@ -0,0 +1,106 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/thand-io/agent/internal/models"
+	cloudresourcemanager "google.golang.org/api/cloudresourcemanager/v1"
+	iam "google.golang.org/api/iam/v1"
+)
+
+// iamBindingPermissions are used to create custom roles and bind users to
+// them in the project IAM policy
+var iamBindingPermissions = []string{
+	"resourcemanager.projects.getIamPolicy",
+	"resourcemanager.projects.setIamPolicy",
+	"iam.roles.get",
+	"iam.roles.create",
+}
+
+// serviceAccountKeyPermissions are used to issue temporary service account
+// keys
+var serviceAccountKeyPermissions = []string{
+	"iam.serviceAccountKeys.create",
+	"iam.serviceAccountKeys.delete",
+}
+
+// ValidateAgentPermissions asks GCP which of the permissions the grant type
+// needs the agent holds. Groups are managed through Cloud Identity, which
+// has no equivalent check.
+func (p *gcpProvider) ValidateAgentPermissions(ctx context.Context) ([]models.AgentPermission, error) {
+
+	grantType, err := p.getGrantType()
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch grantType {
+	case GrantTypeIamBinding:
+		return p.testProjectPermissions(ctx)
+	case GrantTypeServiceAccountKey:
+		return p.testServiceAccountPermissions(ctx)
+	}
+
+	return nil, fmt.Errorf("%w: permissions for grant_type %s can't be tested", models.ErrNotImplemented, grantType)
+}
+
+func (p *gcpProvider) testProjectPermissions(ctx context.Context) ([]models.AgentPermission, error) {
+
+	projectID := p.GetProjectId()
+
+	resp, err := p.crmClient.Projects.TestIamPermissions(projectID, &cloudresourcemanager.TestIamPermissionsRequest{
+		Permissions: iamBindingPermissions,
+	}).Context(ctx).Do()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to test permissions on project %s: %w", projectID, err)
+	}
+
+	return newAgentPermissions(iamBindingPermissions, resp.Permissions, "projects/"+projectID), nil
+}
+
+func (p *gcpProvider) testServiceAccountPermissions(ctx context.Context) ([]models.AgentPermission, error) {
+
+	resource, err := p.getServiceAccountResource()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.GetIamClient().Projects.ServiceAccounts.TestIamPermissions(resource, &iam.TestIamPermissionsRequest{
+		Permissions: serviceAccountKeyPermissions,
+	}).Context(ctx).Do()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to test permissions on %s: %w", resource, err)
+	}
+
+	return newAgentPermissions(serviceAccountKeyPermissions, resp.Permissions, resource), nil
+}
+
+// newAgentPermissions marks the permissions GCP returned as allowed.
+// TestIamPermissions only returns the permissions the caller holds.
+func newAgentPermissions(requested []string, granted []string, resource string) []models.AgentPermission {
+
+	permissions := make([]models.AgentPermission, 0, len(requested))
+
+	for _, permission := range requested {
+
+		agentPermission := models.AgentPermission{
+			Permission: permission,
+			Resource:   resource,
+			Allowed:    slices.Contains(granted, permission),
+		}
+
+		if !agentPermission.Allowed {
+			agentPermission.Reason = "not granted"
+		}
+
+		permissions = append(permissions, agentPermission)
+	}
+
+	return permissions
+}
@@ -0,0 +1,68 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/thand-io/agent/internal/models"
+	"google.golang.org/api/cloudresourcemanager/v1"
+)
+
+// grantMarkerPattern finds the grant reference in a condition description,
+// see getGrantMarker
+var grantMarkerPattern = regexp.MustCompile(`\(grant (` + models.GrantReferencePrefix + `[0-9a-f]+)\)`)
+
+// ListManagedGrants lists the members of the project's thand managed IAM
+// bindings, found by the grant reference in their condition description.
+// Group memberships and bindings made before grant references carry no
+// reference so they aren't listed.
+func (p *gcpProvider) ListManagedGrants(ctx context.Context) ([]models.ManagedGrant, error) {
+
+	policy, err := p.crmClient.Projects.GetIamPolicy(p.GetProjectId(), &cloudresourcemanager.GetIamPolicyRequest{
+		Options: &cloudresourcemanager.GetPolicyOptions{
+			RequestedPolicyVersion: 3,
+		},
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IAM policy: %w", err)
+	}
+
+	grants := []models.ManagedGrant{}
+
+	for _, binding := range policy.Bindings {
+
+		if !isThandManagedBinding(binding) {
+			continue
+		}
+
+		match := grantMarkerPattern.FindStringSubmatch(binding.Condition.Description)
+
+		if match == nil {
+			continue
+		}
+
+		for _, member := range binding.Members {
+
+			email, found := strings.CutPrefix(member, "user:")
+
+			if !found {
+				continue
+			}
+
+			grants = append(grants, models.ManagedGrant{
+				Grant: &models.GrantRef{
+					Provider:  p.GetIdentifier(),
+					Reference: match[1],
+					Kind:      GrantTypeIamBinding,
+					IDs:       []string{binding.Role},
+				},
+				User: &models.User{Email: email},
+				Role: binding.Role,
+			})
+		}
+	}
+
+	return grants, nil
+}
@@ -0,0 +1,111 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/testing/contract"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/option"
+)
+
+// fakeResourceManager serves the project IAM policy from memory
+type fakeResourceManager struct {
+	mu     sync.Mutex
+	policy *cloudresourcemanager.Policy
+}
+
+func (f *fakeResourceManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, ":getIamPolicy"):
+	case strings.HasSuffix(r.URL.Path, ":setIamPolicy"):
+		var req cloudresourcemanager.SetIamPolicyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.policy = req.Policy
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(f.policy)
+}
+
+// countMembers returns the number of members across the thand bindings
+func (f *fakeResourceManager) countMembers() int {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	count := 0
+	for _, binding := range f.policy.Bindings {
+		if isThandManagedBinding(binding) {
+			count += len(binding.Members)
+		}
+	}
+	return count
+}
+
+func TestGcpGrantContract(t *testing.T) {
+
+	fake := &fakeResourceManager{
+		policy: &cloudresourcemanager.Policy{
+			Bindings: []*cloudresourcemanager.Binding{{
+				Role:    "roles/owner",
+				Members: []string{"user:owner@example.com"},
+			}},
+		},
+	}
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	provider := NewMockGcpProvider()
+	require.NoError(t, provider.Initialize(context.Background(), "gcp", models.Provider{
+		Name:     "gcp",
+		Provider: GcpProviderName,
+		Config: &models.BasicConfig{
+			"project_id": "test-project",
+		},
+	}))
+
+	crmClient, err := cloudresourcemanager.NewService(context.Background(),
+		option.WithEndpoint(server.URL+"/"),
+		option.WithoutAuthentication(),
+	)
+	require.NoError(t, err)
+
+	provider.client = &GcpConfigurationProvider{ProjectID: "test-project"}
+	provider.crmClient = crmClient
+
+	contract.RunGrantContract(t, contract.GrantContract{
+		Provider: provider,
+		NewRequest: func(workflowID string) *models.RoleRequest {
+			return &models.RoleRequest{
+				User: &models.User{Email: "alice@example.com"},
+				Role: &models.Role{
+					Name:     "Viewer",
+					Inherits: []string{"roles/viewer"},
+				},
+				Audit: &models.AuditContext{
+					WorkflowID: workflowID,
+				},
+			}
+		},
+		CountGrants: fake.countMembers,
+	})
+}
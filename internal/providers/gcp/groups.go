@@ -0,0 +1,321 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	"go.temporal.io/sdk/temporal"
+	"google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+// GrantTypeGroup adds the user to Google Groups with Cloud Identity
+const GrantTypeGroup = "group"
+
+// GroupRolePrefix marks inherited roles that are Google Groups e.g.
+// group:grp-prod-breakglass@example.com
+const GroupRolePrefix = "group:"
+
+// MetadataGroupMembershipsKey maps each group email to the membership
+// created or extended for the grant
+const MetadataGroupMembershipsKey = "group_memberships"
+
+const groupMemberRole = "MEMBER"
+
+// newCloudIdentityService creates the Cloud Identity client. Groups can be
+// managed by the service account directly if it has a Groups Admin role, or
+// through domain-wide delegation by setting admin_email.
+func newCloudIdentityService(
+	ctx context.Context,
+	gcpClient *GcpConfigurationProvider,
+	adminEmail string,
+) (*cloudidentity.Service, error) {
+
+	if len(adminEmail) == 0 {
+		return cloudidentity.NewService(ctx, gcpClient.WithScopes(cloudidentity.CloudIdentityGroupsScope)...)
+	}
+
+	conf, err := gcpClient.CreateJWTConfig(cloudidentity.CloudIdentityGroupsScope)
+	if err != nil {
+		return nil, fmt.Errorf("admin_email requires service account credentials: %w", err)
+	}
+
+	conf.Subject = adminEmail
+
+	return cloudidentity.NewService(ctx, option.WithTokenSource(conf.TokenSource(ctx)))
+}
+
+// isGroupRole returns true if the inherited role should be granted as a
+// group membership
+func isGroupRole(role string, grantType string) bool {
+	return grantType == GrantTypeGroup || strings.HasPrefix(role, GroupRolePrefix)
+}
+
+// getGroupEmail strips the group: prefix from an inherited role
+func getGroupEmail(role string) (string, error) {
+
+	groupEmail := strings.TrimPrefix(role, GroupRolePrefix)
+
+	if !common.IsValidEmail(groupEmail) {
+		return "", fmt.Errorf("invalid group email: %s", groupEmail)
+	}
+
+	return groupEmail, nil
+}
+
+// GetRole returns groups that haven't been synchronized as roles so they
+// can still be inherited. The group is checked when access is granted.
+func (p *gcpProvider) GetRole(ctx context.Context, role string) (*models.ProviderRole, error) {
+
+	found, err := p.BaseProvider.GetRole(ctx, role)
+
+	if err == nil || !strings.HasPrefix(role, GroupRolePrefix) {
+		return found, err
+	}
+
+	if _, err := getGroupEmail(role); err != nil {
+		return nil, err
+	}
+
+	return &models.ProviderRole{
+		ID:   role,
+		Name: role,
+	}, nil
+}
+
+// getGroupQuery returns the Cloud Identity search query used to list groups
+// as roles. Without a group_query or customer_id groups aren't listed.
+func (p *gcpProvider) getGroupQuery() string {
+
+	config := p.GetConfig()
+
+	if query, found := config.GetString("group_query"); found && len(query) > 0 {
+		return query
+	}
+
+	if customerId, found := config.GetString("customer_id"); found && len(customerId) > 0 {
+		return fmt.Sprintf(
+			"parent == 'customers/%s' && 'cloudidentity.googleapis.com/groups.discussion_forum' in labels",
+			customerId,
+		)
+	}
+
+	return ""
+}
+
+// listGroupRoles lists the groups matching the configured query as roles
+func (p *gcpProvider) listGroupRoles(ctx context.Context) ([]models.ProviderRole, error) {
+
+	query := p.getGroupQuery()
+
+	if len(query) == 0 || p.cloudIdentityClient == nil {
+		return nil, nil
+	}
+
+	var roles []models.ProviderRole
+
+	err := p.cloudIdentityClient.Groups.Search().Query(query).Pages(ctx, func(resp *cloudidentity.SearchGroupsResponse) error {
+		for _, group := range resp.Groups {
+
+			if group.GroupKey == nil || len(group.GroupKey.Id) == 0 {
+				continue
+			}
+
+			roles = append(roles, models.ProviderRole{
+				ID:          group.Name,
+				Name:        GroupRolePrefix + group.GroupKey.Id,
+				Title:       group.DisplayName,
+				Description: group.Description,
+			})
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search groups: %w", err)
+	}
+
+	return roles, nil
+}
+
+// authorizeGroupMembership adds the user to the group, expiring after the
+// requested duration. Existing memberships that expire sooner are extended.
+// Returns the membership name, or an empty name if the user was already a
+// permanent member and the membership must be left alone on revoke.
+func (p *gcpProvider) authorizeGroupMembership(
+	ctx context.Context,
+	user *models.User,
+	groupEmail string,
+	duration *time.Duration,
+) (string, error) {
+
+	log := p.GetLogger(ctx)
+
+	if _, err := validateAndFormatMember(user); err != nil {
+		return "", err
+	}
+
+	group, err := p.cloudIdentityClient.Groups.Lookup().GroupKeyId(groupEmail).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to find group %s: %w", groupEmail, err)
+	}
+
+	memberRole := &cloudidentity.MembershipRole{
+		Name: groupMemberRole,
+	}
+
+	var expireTime time.Time
+
+	if duration != nil && *duration > 0 {
+		expireTime = time.Now().UTC().Add(*duration)
+		memberRole.ExpiryDetail = &cloudidentity.ExpiryDetail{
+			ExpireTime: expireTime.Format(time.RFC3339),
+		}
+	}
+
+	op, err := p.cloudIdentityClient.Groups.Memberships.Create(group.Name, &cloudidentity.Membership{
+		PreferredMemberKey: &cloudidentity.EntityKey{Id: user.Email},
+		Roles:              []*cloudidentity.MembershipRole{memberRole},
+	}).Context(ctx).Do()
+
+	if err == nil {
+		return p.getCreatedMembershipName(ctx, op, group.Name, user.Email)
+	}
+
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusConflict {
+		return "", fmt.Errorf("failed to add %s to group %s: %w", user.Email, groupEmail, err)
+	}
+
+	// The user is already a member so extend the membership instead
+	lookup, err := p.cloudIdentityClient.Groups.Memberships.Lookup(group.Name).
+		MemberKeyId(user.Email).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to find membership of %s in group %s: %w", user.Email, groupEmail, err)
+	}
+
+	membership, err := p.cloudIdentityClient.Groups.Memberships.Get(lookup.Name).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to get membership %s: %w", lookup.Name, err)
+	}
+
+	currentExpiry, expires := getMembershipExpiry(membership)
+
+	if !expires {
+		log.WithFields(logrus.Fields{
+			"user_email": user.Email,
+			"group":      groupEmail,
+		}).Info("User is already a permanent member of the group, leaving membership unchanged")
+		return "", nil
+	}
+
+	if expireTime.IsZero() || currentExpiry.Before(expireTime) {
+
+		_, err = p.cloudIdentityClient.Groups.Memberships.ModifyMembershipRoles(lookup.Name, &cloudidentity.ModifyMembershipRolesRequest{
+			UpdateRolesParams: []*cloudidentity.UpdateMembershipRolesParams{{
+				FieldMask:      "expiry_detail.expire_time",
+				MembershipRole: memberRole,
+			}},
+		}).Context(ctx).Do()
+
+		if err != nil {
+			return "", fmt.Errorf("failed to extend membership of %s in group %s: %w", user.Email, groupEmail, err)
+		}
+	}
+
+	return lookup.Name, nil
+}
+
+func (p *gcpProvider) getCreatedMembershipName(
+	ctx context.Context,
+	op *cloudidentity.Operation,
+	groupName string,
+	memberEmail string,
+) (string, error) {
+
+	if op != nil && op.Done && len(op.Response) > 0 {
+		var membership cloudidentity.Membership
+		if err := json.Unmarshal(op.Response, &membership); err == nil && len(membership.Name) > 0 {
+			return membership.Name, nil
+		}
+	}
+
+	lookup, err := p.cloudIdentityClient.Groups.Memberships.Lookup(groupName).
+		MemberKeyId(memberEmail).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to find membership of %s in %s: %w", memberEmail, groupName, err)
+	}
+
+	return lookup.Name, nil
+}
+
+// getMembershipExpiry returns when the membership's MEMBER role expires
+func getMembershipExpiry(membership *cloudidentity.Membership) (time.Time, bool) {
+
+	for _, role := range membership.Roles {
+
+		if role.Name != groupMemberRole || role.ExpiryDetail == nil {
+			continue
+		}
+
+		expireTime, err := time.Parse(time.RFC3339, role.ExpiryDetail.ExpireTime)
+		if err != nil {
+			continue
+		}
+
+		return expireTime, true
+	}
+
+	return time.Time{}, false
+}
+
+// revokeGroupMembership deletes the membership created for the grant. If
+// the membership has already expired there is nothing to do.
+func (p *gcpProvider) revokeGroupMembership(ctx context.Context, membershipName string) error {
+
+	_, err := p.cloudIdentityClient.Groups.Memberships.Delete(membershipName).Context(ctx).Do()
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return nil
+	}
+
+	return err
+}
+
+// getGroupMemberships returns the memberships recorded in the authorization
+// response. After a Temporal round trip the metadata is a plain map.
+func getGroupMemberships(resp *models.AuthorizeRoleResponse) map[string]string {
+
+	memberships := map[string]string{}
+
+	if resp == nil || resp.Metadata == nil {
+		return memberships
+	}
+
+	if err := common.ConvertInterfaceToInterface(resp.Metadata[MetadataGroupMembershipsKey], &memberships); err != nil {
+		logrus.WithError(err).Warn("Failed to read group memberships from authorization response")
+	}
+
+	return memberships
+}
+
+func newGroupMembershipError(action string, groupEmail string, err error) error {
+	return temporal.NewApplicationErrorWithOptions(
+		fmt.Sprintf("failed to %s group membership for %s: %v", action, groupEmail, err),
+		"GcpGroupMembershipError",
+		temporal.ApplicationErrorOptions{
+			NextRetryDelay: 3 * time.Second,
+			Cause:          err,
+		},
+	)
+}
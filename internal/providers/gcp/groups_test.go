@@ -0,0 +1,212 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+	"google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/option"
+)
+
+const testGroupName = "groups/abc123"
+
+// fakeCloudIdentity serves the Cloud Identity group and membership calls
+// used when granting group access
+type fakeCloudIdentity struct {
+	existing *cloudidentity.Membership
+	created  *cloudidentity.Membership
+	modified *cloudidentity.ModifyMembershipRolesRequest
+	deleted  string
+}
+
+func (f *fakeCloudIdentity) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+
+	switch {
+	case path == "groups:lookup":
+		json.NewEncoder(w).Encode(cloudidentity.LookupGroupNameResponse{Name: testGroupName})
+	case path == "groups:search":
+		json.NewEncoder(w).Encode(cloudidentity.SearchGroupsResponse{Groups: []*cloudidentity.Group{{
+			Name:        testGroupName,
+			DisplayName: "Prod Breakglass",
+			GroupKey:    &cloudidentity.EntityKey{Id: "grp-prod-breakglass@example.com"},
+		}}})
+	case path == testGroupName+"/memberships" && r.Method == http.MethodPost:
+		if f.existing != nil {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error":{"code":409,"message":"Membership already exists"}}`))
+			return
+		}
+		f.created = &cloudidentity.Membership{}
+		json.NewDecoder(r.Body).Decode(f.created)
+		response, _ := json.Marshal(cloudidentity.Membership{Name: testGroupName + "/memberships/new"})
+		json.NewEncoder(w).Encode(cloudidentity.Operation{Done: true, Response: response})
+	case path == testGroupName+"/memberships:lookup":
+		json.NewEncoder(w).Encode(cloudidentity.LookupMembershipNameResponse{Name: f.existing.Name})
+	case strings.HasSuffix(path, ":modifyMembershipRoles"):
+		f.modified = &cloudidentity.ModifyMembershipRolesRequest{}
+		json.NewDecoder(r.Body).Decode(f.modified)
+		json.NewEncoder(w).Encode(cloudidentity.ModifyMembershipRolesResponse{})
+	case r.Method == http.MethodGet:
+		json.NewEncoder(w).Encode(f.existing)
+	case r.Method == http.MethodDelete:
+		f.deleted = path
+		json.NewEncoder(w).Encode(cloudidentity.Operation{Done: true})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestGroupProvider(t *testing.T, config models.BasicConfig, handler http.Handler) *gcpProvider {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cloudIdentityService, err := cloudidentity.NewService(
+		context.Background(),
+		option.WithEndpoint(server.URL+"/"),
+		option.WithoutAuthentication(),
+	)
+	require.NoError(t, err)
+
+	return &gcpProvider{
+		BaseProvider: models.NewBaseProvider("gcp", models.Provider{
+			Name:     "gcp",
+			Provider: GcpProviderName,
+			Config:   &config,
+		}, models.ProviderCapabilityRBAC),
+		client:              &GcpConfigurationProvider{ProjectID: "test-project"},
+		cloudIdentityClient: cloudIdentityService,
+	}
+}
+
+func TestGroupMembershipGrant(t *testing.T) {
+
+	user := &models.User{Email: "alice@example.com"}
+	role := &models.Role{
+		Name:     "breakglass",
+		Inherits: []string{"group:grp-prod-breakglass@example.com"},
+	}
+	duration := 2 * time.Hour
+
+	t.Run("creates an expiring membership", func(t *testing.T) {
+		fake := &fakeCloudIdentity{}
+		provider := newTestGroupProvider(t, models.BasicConfig{}, fake)
+
+		resp, err := provider.AuthorizeRole(context.Background(), &models.AuthorizeRoleRequest{
+			RoleRequest: &models.RoleRequest{User: user, Role: role, Duration: &duration},
+		})
+		require.NoError(t, err)
+
+		require.NotNil(t, fake.created)
+		assert.Equal(t, "alice@example.com", fake.created.PreferredMemberKey.Id)
+		require.Len(t, fake.created.Roles, 1)
+		expireTime, err := time.Parse(time.RFC3339, fake.created.Roles[0].ExpiryDetail.ExpireTime)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(duration), expireTime, time.Minute)
+
+		assert.Equal(t, []string{"group:grp-prod-breakglass@example.com"}, resp.Roles)
+
+		_, err = provider.RevokeRole(context.Background(), &models.RevokeRoleRequest{
+			RoleRequest:           &models.RoleRequest{User: user, Role: role},
+			AuthorizeRoleResponse: resp,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, testGroupName+"/memberships/new", fake.deleted)
+	})
+
+	t.Run("extends an expiring membership", func(t *testing.T) {
+		fake := &fakeCloudIdentity{existing: &cloudidentity.Membership{
+			Name: testGroupName + "/memberships/existing",
+			Roles: []*cloudidentity.MembershipRole{{
+				Name: "MEMBER",
+				ExpiryDetail: &cloudidentity.ExpiryDetail{
+					ExpireTime: time.Now().Add(10 * time.Minute).UTC().Format(time.RFC3339),
+				},
+			}},
+		}}
+		provider := newTestGroupProvider(t, models.BasicConfig{}, fake)
+
+		resp, err := provider.AuthorizeRole(context.Background(), &models.AuthorizeRoleRequest{
+			RoleRequest: &models.RoleRequest{User: user, Role: role, Duration: &duration},
+		})
+		require.NoError(t, err)
+
+		require.NotNil(t, fake.modified)
+		require.Len(t, fake.modified.UpdateRolesParams, 1)
+		assert.Equal(t, "expiry_detail.expire_time", fake.modified.UpdateRolesParams[0].FieldMask)
+		assert.Equal(t, map[string]string{
+			"grp-prod-breakglass@example.com": testGroupName + "/memberships/existing",
+		}, getGroupMemberships(resp))
+	})
+
+	t.Run("leaves permanent memberships alone", func(t *testing.T) {
+		fake := &fakeCloudIdentity{existing: &cloudidentity.Membership{
+			Name:  testGroupName + "/memberships/permanent",
+			Roles: []*cloudidentity.MembershipRole{{Name: "MEMBER"}},
+		}}
+		provider := newTestGroupProvider(t, models.BasicConfig{}, fake)
+
+		resp, err := provider.AuthorizeRole(context.Background(), &models.AuthorizeRoleRequest{
+			RoleRequest: &models.RoleRequest{User: user, Role: role, Duration: &duration},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, fake.modified)
+
+		_, err = provider.RevokeRole(context.Background(), &models.RevokeRoleRequest{
+			RoleRequest:           &models.RoleRequest{User: user, Role: role},
+			AuthorizeRoleResponse: resp,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, fake.deleted)
+	})
+
+	t.Run("grant_type group treats inherits as groups", func(t *testing.T) {
+		fake := &fakeCloudIdentity{}
+		provider := newTestGroupProvider(t, models.BasicConfig{"grant_type": GrantTypeGroup}, fake)
+
+		resp, err := provider.AuthorizeRole(context.Background(), &models.AuthorizeRoleRequest{
+			RoleRequest: &models.RoleRequest{User: user, Role: &models.Role{
+				Name:     "breakglass",
+				Inherits: []string{"grp-prod-breakglass@example.com"},
+			}},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, fake.created)
+		assert.Nil(t, fake.created.Roles[0].ExpiryDetail)
+		assert.Equal(t, []string{"group:grp-prod-breakglass@example.com"}, resp.Roles)
+	})
+}
+
+func TestListGroupRoles(t *testing.T) {
+
+	t.Run("groups are not listed without a query", func(t *testing.T) {
+		provider := newTestGroupProvider(t, models.BasicConfig{}, &fakeCloudIdentity{})
+
+		roles, err := provider.listGroupRoles(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, roles)
+	})
+
+	t.Run("customer_id builds the query", func(t *testing.T) {
+		provider := newTestGroupProvider(t, models.BasicConfig{"customer_id": "C0123"}, &fakeCloudIdentity{})
+
+		assert.Contains(t, provider.getGroupQuery(), "parent == 'customers/C0123'")
+
+		roles, err := provider.listGroupRoles(context.Background())
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		assert.Equal(t, "group:grp-prod-breakglass@example.com", roles[0].Name)
+		assert.Equal(t, "Prod Breakglass", roles[0].Title)
+	})
+}
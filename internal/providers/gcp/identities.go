@@ -17,10 +17,12 @@ func (p *gcpProvider) CanSynchronizeIdentities() bool {
 
 // SynchronizeIdentities fetches and caches user and group identities from GCP IAM
 func (p *gcpProvider) SynchronizeIdentities(ctx context.Context, req *models.SynchronizeIdentitiesRequest) (*models.SynchronizeIdentitiesResponse, error) {
+	log := p.GetLogger(ctx)
+
 	startTime := time.Now()
 	defer func() {
 		elapsed := time.Since(startTime)
-		logrus.Debugf("Refreshed GCP identities in %s", elapsed)
+		log.Debugf("Refreshed GCP identities in %s", elapsed)
 	}()
 
 	projectId := p.GetProjectId()
@@ -65,7 +67,7 @@ func (p *gcpProvider) SynchronizeIdentities(ctx context.Context, req *models.Syn
 		}
 	}
 
-	logrus.WithFields(logrus.Fields{
+	log.WithFields(logrus.Fields{
 		"users":      userCount,
 		"groups":     groupCount,
 		"total":      len(identities),
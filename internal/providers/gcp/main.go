@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/sirupsen/logrus"
 	"golang.org/x/oauth2/google"
@@ -15,6 +16,7 @@ import (
 	"github.com/thand-io/agent/internal/models"
 	"github.com/thand-io/agent/internal/providers"
 
+	"google.golang.org/api/cloudidentity/v1"
 	"google.golang.org/api/cloudresourcemanager/v1"
 	iam "google.golang.org/api/iam/v1"
 	"google.golang.org/api/option"
@@ -31,9 +33,34 @@ type gcpProvider struct {
 	client    *GcpConfigurationProvider
 	iamClient *iam.Service
 	crmClient *cloudresourcemanager.Service
+
+	cloudIdentityClient *cloudidentity.Service
+}
+
+// ValidateConfig checks the stage and grant type are supported and that
+// domain-wide delegation has service account credentials to use
+func (p *gcpProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(GcpProviderName)
+	validation.OneOf(config, "stage", "GA", "BETA", "ALPHA", "DEPRECATED", "DISABLED", "EAP")
+	validation.OneOf(config, "grant_type", GrantTypeIamBinding, GrantTypeServiceAccountKey, GrantTypeGroup)
+
+	if config.GetStringWithDefault("grant_type", GrantTypeIamBinding) == GrantTypeServiceAccountKey {
+		validation.Require(config, "service_account")
+	}
+
+	if adminEmail, found := config.GetString("admin_email"); found && len(adminEmail) > 0 {
+		if !config.HasString("service_account_key_path") &&
+			!config.HasString("service_account_key") &&
+			!config.HasString("credentials") {
+			validation.Add("admin_email", "requires service_account_key_path, service_account_key or credentials")
+		}
+	}
+
+	return validation.ErrorOrNil()
 }
 
-func (p *gcpProvider) Initialize(identifier string, provider models.Provider) error {
+func (p *gcpProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	// Set the provider to the base provider
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
@@ -42,7 +69,9 @@ func (p *gcpProvider) Initialize(identifier string, provider models.Provider) er
 		models.ProviderCapabilityIdentities,
 	)
 
-	ctx := context.Background()
+	// The clients hold on to the context for refreshing tokens so they
+	// mustn't be cancelled when initialization finishes
+	clientCtx := context.WithoutCancel(ctx)
 
 	// Configure GCP client options based on available credentials
 	gcpConfig := p.GetConfig()
@@ -56,18 +85,25 @@ func (p *gcpProvider) Initialize(identifier string, provider models.Provider) er
 
 	clientOptions := gcpClient.ClientOptions
 
-	iamService, err := iam.NewService(ctx, clientOptions...)
+	iamService, err := iam.NewService(clientCtx, clientOptions...)
 	if err != nil {
 		return fmt.Errorf("failed to create IAM client: %w", err)
 	}
 	p.iamClient = iamService
 
-	crmService, err := cloudresourcemanager.NewService(ctx, clientOptions...)
+	crmService, err := cloudresourcemanager.NewService(clientCtx, clientOptions...)
 	if err != nil {
 		return fmt.Errorf("failed to create Resource Manager client: %w", err)
 	}
 	p.crmClient = crmService
 
+	cloudIdentityService, err := newCloudIdentityService(
+		clientCtx, gcpClient, gcpConfig.GetStringWithDefault("admin_email", ""))
+	if err != nil {
+		return fmt.Errorf("failed to create Cloud Identity client: %w", err)
+	}
+	p.cloudIdentityClient = cloudIdentityService
+
 	return nil
 }
 
@@ -117,7 +153,7 @@ func CreateGcpConfig(gcpConfig *models.BasicConfig) (*GcpConfigurationProvider,
 	credentials, foundCredentials := gcpConfig.GetMap("credentials")
 
 	if foundKeyPath {
-		logrus.Info("Using GCP service account key file")
+		logrus.Debug("Using GCP service account key file")
 
 		// Read service account credentials
 		var err error
@@ -128,11 +164,11 @@ func CreateGcpConfig(gcpConfig *models.BasicConfig) (*GcpConfigurationProvider,
 
 		clientOptions = append(clientOptions, option.WithCredentialsFile(serviceAccountKeyPath))
 	} else if foundKey {
-		logrus.Info("Using GCP service account key from config")
+		logrus.Debug("Using GCP service account key from config")
 		credentialsData = []byte(serviceAccountKey)
 		clientOptions = append(clientOptions, option.WithCredentialsJSON(credentialsData))
 	} else if foundCredentials {
-		logrus.Info("Using GCP service account credentials from config")
+		logrus.Debug("Using GCP service account credentials from config")
 		// Convert the credentials map to JSON
 		var err error
 		credentialsData, err = json.Marshal(credentials)
@@ -141,7 +177,7 @@ func CreateGcpConfig(gcpConfig *models.BasicConfig) (*GcpConfigurationProvider,
 		}
 		clientOptions = append(clientOptions, option.WithCredentialsJSON(credentialsData))
 	} else {
-		logrus.Info("No GCP credentials provided, using Application Default Credentials (ADC)")
+		logrus.Debug("No GCP credentials provided, using Application Default Credentials (ADC)")
 		// No explicit credentials provided, will use Application Default Credentials
 		// This includes:
 		// - GOOGLE_APPLICATION_CREDENTIALS environment variable
@@ -150,6 +186,17 @@ func CreateGcpConfig(gcpConfig *models.BasicConfig) (*GcpConfigurationProvider,
 		// - Cloud Shell credentials
 	}
 
+	// Support custom endpoint for testing (e.g., an emulator or fake server)
+	if endpoint, found := gcpConfig.GetString("endpoint"); found {
+		logrus.WithField("endpoint", endpoint).Debug("Using custom GCP endpoint")
+		clientOptions = append(clientOptions, option.WithEndpoint(endpoint))
+
+		// Emulators don't check credentials so don't look for ADC
+		if credentialsData == nil && !foundKeyPath {
+			clientOptions = append(clientOptions, option.WithoutAuthentication())
+		}
+	}
+
 	return &GcpConfigurationProvider{
 		ProjectID:       projectId,
 		Stage:           projectStage,
@@ -173,6 +220,11 @@ func (g *GcpConfigurationProvider) CreateJWTConfig(scopes ...string) (*jwt.Confi
 	return conf, nil
 }
 
+// WithScopes returns the client options limited to the given OAuth scopes
+func (g *GcpConfigurationProvider) WithScopes(scopes ...string) []option.ClientOption {
+	return append(slices.Clone(g.ClientOptions), option.WithScopes(scopes...))
+}
+
 type GcpConfigurationProvider struct {
 	ProjectID     string
 	Stage         string
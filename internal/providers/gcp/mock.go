@@ -19,7 +19,7 @@ func NewMockGcpProvider() *gcpProviderMock {
 }
 
 // Initialize loads permissions and roles without connecting to GCP
-func (p *gcpProviderMock) Initialize(identifier string, provider models.Provider) error {
+func (p *gcpProviderMock) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	// Initialize the embedded gcpProvider struct
 	p.gcpProvider = &gcpProvider{}
 
@@ -31,7 +31,7 @@ func (p *gcpProviderMock) Initialize(identifier string, provider models.Provider
 	)
 
 	// Load GCP Permissions and Roles from shared singleton
-	if err := p.Synchronize(context.Background(), nil, nil); err != nil {
+	if err := p.Synchronize(ctx, nil, nil); err != nil {
 		return err
 	}
 
@@ -45,3 +45,15 @@ func (p *gcpProviderMock) Synchronize(
 ) error {
 	return PreSynchronizeActivities(ctx, temporalService, p, req)
 }
+
+// ValidateConfig accepts any config as the mock doesn't connect to GCP.
+// Providers are created with reflection so the embedded provider is nil
+// until Initialize.
+func (p *gcpProviderMock) ValidateConfig(config *models.BasicConfig) error {
+	return nil
+}
+
+// ValidateAgentPermissions skips the check as the mock has no GCP clients
+func (p *gcpProviderMock) ValidateAgentPermissions(ctx context.Context) ([]models.AgentPermission, error) {
+	return nil, models.ErrNotImplemented
+}
@@ -27,7 +27,7 @@ func TestGCPProviderPermissions(t *testing.T) {
 
 	// Initialize the provider
 	provider := NewMockGcpProvider()
-	err := provider.Initialize("gcp", testConfig)
+	err := provider.Initialize(context.Background(), "gcp", testConfig)
 	require.NoError(t, err, "Failed to initialize GCP provider")
 
 	ctx := context.Background()
@@ -128,7 +128,7 @@ func TestGCPProviderRoles(t *testing.T) {
 
 	// Initialize the provider
 	provider := NewMockGcpProvider()
-	err := provider.Initialize("gcp", testConfig)
+	err := provider.Initialize(context.Background(), "gcp", testConfig)
 	require.NoError(t, err, "Failed to initialize GCP provider")
 
 	ctx := context.Background()
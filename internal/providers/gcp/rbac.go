@@ -16,27 +16,61 @@ import (
 )
 
 // newThandCondition creates a new IAM condition used to tag bindings managed by thand
-// We create a fresh copy each time to avoid shared state mutation
-func newThandCondition() *cloudresourcemanager.Expr {
+// We create a fresh copy each time to avoid shared state mutation. When an audit
+// context is provided the requester and workflow are recorded in the condition
+// description so the grant can be attributed in the GCP audit logs. The grant
+// reference gives each grant its own binding so revoke only removes that grant.
+func newThandCondition(reference string, audit *models.AuditContext) *cloudresourcemanager.Expr {
+	description := "This binding is managed by thand"
+	if len(reference) > 0 {
+		description = fmt.Sprintf("%s %s", description, getGrantMarker(reference))
+	}
+	if justification := audit.GetJustification(); len(justification) > 0 {
+		description = fmt.Sprintf("%s. %s", description, justification)
+	}
 	return &cloudresourcemanager.Expr{
 		Title:       "managed-by-thand",
-		Description: "This binding is managed by thand",
+		Description: truncateConditionDescription(description),
 		Expression:  "true", // Always evaluates to true, used as a tag
 	}
 }
 
+// maxConditionDescriptionLength is the most bytes IAM accepts in a
+// condition description
+const maxConditionDescriptionLength = 256
+
+// truncateConditionDescription shortens the description to the IAM limit
+// without splitting a multi-byte character, which IAM rejects as invalid
+// UTF-8
+func truncateConditionDescription(description string) string {
+	return common.TruncateBytes(description, maxConditionDescriptionLength)
+}
+
 // Authorize grants access for a user to a role
 func (p *gcpProvider) AuthorizeRole(
 	ctx context.Context,
 	req *models.AuthorizeRoleRequest,
 ) (*models.AuthorizeRoleResponse, error) {
 
+	log := p.GetLogger(ctx)
+
 	if !req.IsValid() {
 		return nil, fmt.Errorf("user and role must be provided to authorize gcp role")
 	}
 
+	grantType, err := p.getGrantType()
+	if err != nil {
+		return nil, err
+	}
+
+	if grantType == GrantTypeServiceAccountKey {
+		return p.authorizeServiceAccountKey(ctx, req)
+	}
+
 	user := req.GetUser()
 	role := req.GetRole()
+	audit := req.GetAudit()
+	grant := models.NewGrantRef(p.GetIdentifier(), GrantTypeIamBinding, req.RoleRequest)
 
 	if len(role.Inherits) == 0 && len(role.Permissions.Allow) == 0 {
 		return nil, fmt.Errorf("role %s has no inherits or permissions defined", role.Name)
@@ -47,10 +81,40 @@ func (p *gcpProvider) AuthorizeRole(
 	stage := config.GetStringWithDefault("stage", "GA")
 
 	var assignedRoles []string
+	groupMemberships := map[string]string{}
 
 	// If inherits is specified, validate and bind predefined GCP roles
 	if len(role.Inherits) > 0 {
 		for _, inheritedRole := range role.Inherits {
+
+			// Google Groups are granted as an expiring group membership
+			if isGroupRole(inheritedRole, grantType) {
+
+				groupEmail, err := getGroupEmail(inheritedRole)
+				if err != nil {
+					return nil, err
+				}
+
+				membership, err := p.authorizeGroupMembership(ctx, user, groupEmail, req.GetDuration())
+				if err != nil {
+					return nil, newGroupMembershipError("create", groupEmail, err)
+				}
+
+				log.WithFields(logrus.Fields{
+					"user_email": user.Email,
+					"group":      groupEmail,
+					"membership": membership,
+				}).Info("Successfully added user to GCP group")
+
+				if len(membership) > 0 {
+					groupMemberships[groupEmail] = membership
+					grant.AddID(membership)
+				}
+
+				assignedRoles = append(assignedRoles, GroupRolePrefix+groupEmail)
+				continue
+			}
+
 			// Validate that the role is a valid GCP predefined role
 			predefinedRole, err := p.GetRole(ctx, inheritedRole)
 			if err != nil {
@@ -58,7 +122,7 @@ func (p *gcpProvider) AuthorizeRole(
 			}
 
 			// Bind the user to the predefined role via IAM policy
-			err = p.bindUserToPredefinedRole(projectId, user, predefinedRole.Name)
+			err = p.bindUserToPredefinedRole(projectId, user, predefinedRole.Name, grant.Reference, audit)
 			if err != nil {
 				return nil, temporal.NewApplicationErrorWithOptions(
 					fmt.Sprintf("failed to bind user to role %s: %v", predefinedRole.Name, err),
@@ -70,13 +134,14 @@ func (p *gcpProvider) AuthorizeRole(
 				)
 			}
 
-			logrus.WithFields(logrus.Fields{
+			log.WithFields(logrus.Fields{
 				"user_email": user.Email,
 				"role":       predefinedRole.Name,
 				"project_id": projectId,
 			}).Info("Successfully bound user to predefined GCP role")
 
 			assignedRoles = append(assignedRoles, predefinedRole.Name)
+			grant.AddID(predefinedRole.Name)
 		}
 	}
 
@@ -106,7 +171,7 @@ func (p *gcpProvider) AuthorizeRole(
 				)
 			}
 
-			logrus.WithFields(logrus.Fields{
+			log.WithFields(logrus.Fields{
 				"role_name":   customRoleName,
 				"project_id":  projectId,
 				"permissions": role.Permissions.Allow,
@@ -114,7 +179,7 @@ func (p *gcpProvider) AuthorizeRole(
 		}
 
 		// Bind the user to the custom role via IAM policy
-		err = p.bindUserToRole(projectId, user, existingRole)
+		err = p.bindUserToRole(projectId, user, existingRole, grant.Reference, audit)
 		if err != nil {
 			return nil, temporal.NewApplicationErrorWithOptions(
 				fmt.Sprintf("failed to bind user to custom role %s: %v", existingRole.Name, err),
@@ -126,19 +191,29 @@ func (p *gcpProvider) AuthorizeRole(
 			)
 		}
 
-		logrus.WithFields(logrus.Fields{
+		log.WithFields(logrus.Fields{
 			"user_email": user.Email,
 			"role":       existingRole.Name,
 			"project_id": projectId,
 		}).Info("Successfully bound user to custom GCP role")
 
 		assignedRoles = append(assignedRoles, existingRole.Name)
+		grant.AddID(existingRole.Name)
+	}
+
+	response := &models.AuthorizeRoleResponse{
+		UserId:   user.Email,
+		Roles:    assignedRoles,
+		GrantRef: grant,
 	}
 
-	return &models.AuthorizeRoleResponse{
-		UserId: user.Email,
-		Roles:  assignedRoles,
-	}, nil
+	if len(groupMemberships) > 0 {
+		response.Metadata = map[string]any{
+			MetadataGroupMembershipsKey: groupMemberships,
+		}
+	}
+
+	return response, nil
 }
 
 // Revoke removes access for a user from a role
@@ -147,6 +222,8 @@ func (p *gcpProvider) RevokeRole(
 	req *models.RevokeRoleRequest,
 ) (*models.RevokeRoleResponse, error) {
 
+	log := p.GetLogger(ctx)
+
 	if !req.IsValid() {
 		return nil, fmt.Errorf("user and role must be provided to revoke gcp role")
 	}
@@ -158,6 +235,15 @@ func (p *gcpProvider) RevokeRole(
 		return nil, fmt.Errorf("no authorize role response found for revocation")
 	}
 
+	if isServiceAccountKeyGrant(req.AuthorizeRoleResponse) {
+		return p.revokeServiceAccountKey(ctx, req)
+	}
+
+	// Remove exactly the bindings and memberships recorded by the grant
+	if grant := req.GetGrantRef(); grant.HasIDs() {
+		return p.revokeGrant(ctx, user, grant)
+	}
+
 	// Get the roles that were assigned during authorization
 	metadata := req.AuthorizeRoleResponse
 
@@ -165,12 +251,37 @@ func (p *gcpProvider) RevokeRole(
 		return nil, fmt.Errorf("no roles found in authorization response for revocation")
 	}
 
+	groupMemberships := getGroupMemberships(metadata)
+
 	// Revoke each role that was assigned
 	for _, roleName := range metadata.Roles {
-		// Check if this is a predefined role (starts with "roles/") or custom role (starts with "projects/")
-		if strings.HasPrefix(roleName, "roles/") {
+		// Check if this is a group, a predefined role (starts with "roles/") or custom role (starts with "projects/")
+		if strings.HasPrefix(roleName, GroupRolePrefix) {
+
+			groupEmail := strings.TrimPrefix(roleName, GroupRolePrefix)
+			membership, found := groupMemberships[groupEmail]
+
+			// Memberships that existed before the grant are left alone
+			if !found {
+				log.WithFields(logrus.Fields{
+					"user_email": user.Email,
+					"group":      groupEmail,
+				}).Info("GCP group membership was not created by this grant, skipping")
+				continue
+			}
+
+			if err := p.revokeGroupMembership(ctx, membership); err != nil {
+				return nil, newGroupMembershipError("delete", groupEmail, err)
+			}
+
+			log.WithFields(logrus.Fields{
+				"user_email": user.Email,
+				"group":      groupEmail,
+				"membership": membership,
+			}).Info("Successfully removed user from GCP group")
+		} else if strings.HasPrefix(roleName, "roles/") {
 			// Predefined role - unbind directly by role name
-			err := p.unbindUserFromPredefinedRole(projectId, user, roleName)
+			err := p.unbindUserFromPredefinedRole(projectId, user, roleName, "")
 			if err != nil {
 				return nil, temporal.NewApplicationErrorWithOptions(
 					fmt.Sprintf("failed to unbind user from predefined role %s: %v", roleName, err),
@@ -182,7 +293,7 @@ func (p *gcpProvider) RevokeRole(
 				)
 			}
 
-			logrus.WithFields(logrus.Fields{
+			log.WithFields(logrus.Fields{
 				"user_email": user.Email,
 				"role":       roleName,
 				"project_id": projectId,
@@ -209,7 +320,7 @@ func (p *gcpProvider) RevokeRole(
 				)
 			}
 
-			err = p.unbindUserFromRole(projectId, user, existingRole)
+			err = p.unbindUserFromRole(projectId, user, existingRole, "")
 			if err != nil {
 				return nil, temporal.NewApplicationErrorWithOptions(
 					fmt.Sprintf("failed to unbind user from custom role %s: %v", roleName, err),
@@ -221,7 +332,7 @@ func (p *gcpProvider) RevokeRole(
 				)
 			}
 
-			logrus.WithFields(logrus.Fields{
+			log.WithFields(logrus.Fields{
 				"user_email": user.Email,
 				"role":       roleName,
 				"project_id": projectId,
@@ -232,6 +343,54 @@ func (p *gcpProvider) RevokeRole(
 	return &models.RevokeRoleResponse{}, nil
 }
 
+// revokeGrant removes the user from the bindings and group memberships
+// recorded by the grant. Anything already removed is skipped.
+func (p *gcpProvider) revokeGrant(
+	ctx context.Context,
+	user *models.User,
+	grant *models.GrantRef,
+) (*models.RevokeRoleResponse, error) {
+
+	log := p.GetLogger(ctx)
+	projectId := p.GetProjectId()
+
+	for _, id := range grant.IDs {
+
+		logFields := logrus.Fields{
+			"user_email": user.Email,
+			"grant":      grant.Reference,
+			"project_id": projectId,
+		}
+
+		if strings.HasPrefix(id, "groups/") {
+
+			if err := p.revokeGroupMembership(ctx, id); err != nil {
+				return nil, newGroupMembershipError("delete", id, err)
+			}
+
+			log.WithFields(logFields).WithField("membership", id).
+				Info("Successfully removed user from GCP group")
+			continue
+		}
+
+		if err := p.unbindUserFromRoleByName(projectId, user, id, grant.Reference); err != nil {
+			return nil, temporal.NewApplicationErrorWithOptions(
+				fmt.Sprintf("failed to unbind user from role %s: %v", id, err),
+				"GcpRoleUnbindingError",
+				temporal.ApplicationErrorOptions{
+					NextRetryDelay: 3 * time.Second,
+					Cause:          err,
+				},
+			)
+		}
+
+		log.WithFields(logFields).WithField("role", id).
+			Info("Successfully unbound user from GCP role")
+	}
+
+	return &models.RevokeRoleResponse{}, nil
+}
+
 func (p *gcpProvider) GetAuthorizedAccessUrl(
 	ctx context.Context,
 	req *models.AuthorizeRoleRequest,
@@ -276,13 +435,13 @@ func (p *gcpProvider) getRole(projectID, roleName string) (*iam.Role, error) {
 }
 
 // bindUserToPredefinedRole binds a user to a predefined GCP role (e.g., roles/viewer)
-func (p *gcpProvider) bindUserToPredefinedRole(projectID string, user *models.User, roleName string) error {
-	return p.bindUserToRoleByName(projectID, user, roleName)
+func (p *gcpProvider) bindUserToPredefinedRole(projectID string, user *models.User, roleName string, reference string, audit *models.AuditContext) error {
+	return p.bindUserToRoleByName(projectID, user, roleName, reference, audit)
 }
 
 // unbindUserFromPredefinedRole removes a user from a predefined GCP role
-func (p *gcpProvider) unbindUserFromPredefinedRole(projectID string, user *models.User, roleName string) error {
-	return p.unbindUserFromRoleByName(projectID, user, roleName)
+func (p *gcpProvider) unbindUserFromPredefinedRole(projectID string, user *models.User, roleName string, reference string) error {
+	return p.unbindUserFromRoleByName(projectID, user, roleName, reference)
 }
 
 // isThandManagedBinding checks if a binding has the thand condition tag
@@ -305,11 +464,14 @@ func validateAndFormatMember(user *models.User) (string, error) {
 }
 
 // addMemberToPolicy adds a member to a role binding in the policy, creating a new binding if necessary
+// Bindings are shared between members only when their conditions match, so grants carrying
+// different audit justifications end up in separate bindings.
 // Returns true if the policy was modified
-func addMemberToPolicy(policy *cloudresourcemanager.Policy, roleName, member string) bool {
+func addMemberToPolicy(policy *cloudresourcemanager.Policy, roleName, member string, condition *cloudresourcemanager.Expr) bool {
 	// Check if binding already exists with our thand condition
 	for _, binding := range policy.Bindings {
-		if binding.Role == roleName && isThandManagedBinding(binding) {
+		if binding.Role == roleName && isThandManagedBinding(binding) &&
+			binding.Condition.Description == condition.Description {
 			if slices.Contains(binding.Members, member) {
 				return false // Already bound, no modification needed
 			}
@@ -323,17 +485,37 @@ func addMemberToPolicy(policy *cloudresourcemanager.Policy, roleName, member str
 	newBinding := &cloudresourcemanager.Binding{
 		Role:      roleName,
 		Members:   []string{member},
-		Condition: newThandCondition(),
+		Condition: condition,
 	}
 	policy.Bindings = append(policy.Bindings, newBinding)
 	return true
 }
 
+// getGrantMarker returns the text recorded in the condition description of
+// bindings created for the grant
+func getGrantMarker(reference string) string {
+	return fmt.Sprintf("(grant %s)", reference)
+}
+
+// isGrantBinding checks if a thand managed binding was created for the grant
+func isGrantBinding(binding *cloudresourcemanager.Binding, reference string) bool {
+	return isThandManagedBinding(binding) &&
+		strings.Contains(binding.Condition.Description, getGrantMarker(reference))
+}
+
 // removeMemberFromPolicy removes a member from a role binding in the policy
 // Returns true if the member was found and removed, false otherwise
 func removeMemberFromPolicy(policy *cloudresourcemanager.Policy, roleName, member string) bool {
+	return removeGrantFromPolicy(policy, roleName, member, "")
+}
+
+// removeGrantFromPolicy removes a member from the binding created for the
+// grant, or from any thand managed binding when no reference is given
+// Returns true if the member was found and removed, false otherwise
+func removeGrantFromPolicy(policy *cloudresourcemanager.Policy, roleName, member, reference string) bool {
 	for i, binding := range policy.Bindings {
-		if binding.Role == roleName && isThandManagedBinding(binding) {
+		if binding.Role == roleName && isThandManagedBinding(binding) &&
+			(len(reference) == 0 || isGrantBinding(binding, reference)) {
 			// Find the member index first, then remove outside the loop
 			memberIndex := -1
 			for j, bindingMember := range binding.Members {
@@ -343,7 +525,7 @@ func removeMemberFromPolicy(policy *cloudresourcemanager.Policy, roleName, membe
 				}
 			}
 			if memberIndex == -1 {
-				return false // Member not found in binding
+				continue // Member not found in this binding, check the others
 			}
 			// Remove the member from the slice (outside the iteration loop)
 			binding.Members = append(binding.Members[:memberIndex], binding.Members[memberIndex+1:]...)
@@ -357,16 +539,16 @@ func removeMemberFromPolicy(policy *cloudresourcemanager.Policy, roleName, membe
 	return false // Binding not found
 }
 
-func (p *gcpProvider) bindUserToRole(projectID string, user *models.User, iamRole *iam.Role) error {
-	return p.bindUserToRoleByName(projectID, user, iamRole.Name)
+func (p *gcpProvider) bindUserToRole(projectID string, user *models.User, iamRole *iam.Role, reference string, audit *models.AuditContext) error {
+	return p.bindUserToRoleByName(projectID, user, iamRole.Name, reference, audit)
 }
 
-func (p *gcpProvider) unbindUserFromRole(projectID string, user *models.User, iamRole *iam.Role) error {
-	return p.unbindUserFromRoleByName(projectID, user, iamRole.Name)
+func (p *gcpProvider) unbindUserFromRole(projectID string, user *models.User, iamRole *iam.Role, reference string) error {
+	return p.unbindUserFromRoleByName(projectID, user, iamRole.Name, reference)
 }
 
 // bindUserToRoleByName is the core implementation for binding a user to any role
-func (p *gcpProvider) bindUserToRoleByName(projectID string, user *models.User, roleName string) error {
+func (p *gcpProvider) bindUserToRoleByName(projectID string, user *models.User, roleName string, reference string, audit *models.AuditContext) error {
 	member, err := validateAndFormatMember(user)
 	if err != nil {
 		return err
@@ -388,7 +570,7 @@ func (p *gcpProvider) bindUserToRoleByName(projectID string, user *models.User,
 	policy.Version = 3
 
 	// Add member to the policy (handles both existing and new bindings)
-	if !addMemberToPolicy(policy, roleName, member) {
+	if !addMemberToPolicy(policy, roleName, member, newThandCondition(reference, audit)) {
 		// Member already bound, nothing to do
 		return nil
 	}
@@ -404,8 +586,10 @@ func (p *gcpProvider) bindUserToRoleByName(projectID string, user *models.User,
 	return nil
 }
 
-// unbindUserFromRoleByName is the core implementation for unbinding a user from any role
-func (p *gcpProvider) unbindUserFromRoleByName(projectID string, user *models.User, roleName string) error {
+// unbindUserFromRoleByName is the core implementation for unbinding a user from any role.
+// With a grant reference only the grant's binding is removed, and a missing binding is
+// treated as already revoked.
+func (p *gcpProvider) unbindUserFromRoleByName(projectID string, user *models.User, roleName string, reference string) error {
 	member, err := validateAndFormatMember(user)
 	if err != nil {
 		return err
@@ -427,7 +611,10 @@ func (p *gcpProvider) unbindUserFromRoleByName(projectID string, user *models.Us
 	policy.Version = 3
 
 	// Remove member from the policy
-	if !removeMemberFromPolicy(policy, roleName, member) {
+	if !removeGrantFromPolicy(policy, roleName, member, reference) {
+		if len(reference) > 0 {
+			return nil
+		}
 		return fmt.Errorf("thand-managed role binding not found for role %s", roleName)
 	}
 
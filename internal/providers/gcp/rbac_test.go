@@ -0,0 +1,71 @@
+package gcp
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thand-io/agent/internal/models"
+	"google.golang.org/api/cloudresourcemanager/v1"
+)
+
+func TestPolicyBindingsWithAuditContext(t *testing.T) {
+
+	policy := &cloudresourcemanager.Policy{}
+
+	aliceAudit := &models.AuditContext{
+		Requester:  "alice@example.com",
+		WorkflowID: "wf-1",
+		Reason:     "incident",
+	}
+
+	condition := newThandCondition("", aliceAudit)
+	assert.Equal(t, "managed-by-thand", condition.Title)
+	assert.Contains(t, condition.Description, "alice@example.com")
+	assert.Contains(t, condition.Description, "wf-1")
+
+	assert.True(t, addMemberToPolicy(policy, "roles/viewer", "user:alice@example.com", newThandCondition("", aliceAudit)))
+	assert.False(t, addMemberToPolicy(policy, "roles/viewer", "user:alice@example.com", newThandCondition("", aliceAudit)))
+
+	// A different requester gets its own binding
+	assert.True(t, addMemberToPolicy(policy, "roles/viewer", "user:bob@example.com", newThandCondition("", &models.AuditContext{
+		Requester:  "bob@example.com",
+		WorkflowID: "wf-2",
+	})))
+	assert.Len(t, policy.Bindings, 2)
+
+	// Removal finds the member regardless of which binding holds it
+	assert.True(t, removeMemberFromPolicy(policy, "roles/viewer", "user:bob@example.com"))
+	assert.Len(t, policy.Bindings, 1)
+	assert.False(t, removeMemberFromPolicy(policy, "roles/viewer", "user:bob@example.com"))
+}
+
+func TestNewThandConditionTruncatesDescription(t *testing.T) {
+	reason := make([]byte, 500)
+	for i := range reason {
+		reason[i] = 'a'
+	}
+	condition := newThandCondition("", &models.AuditContext{Reason: string(reason)})
+	assert.Len(t, condition.Description, 256)
+
+	condition = newThandCondition("", nil)
+	assert.Equal(t, "This binding is managed by thand", condition.Description)
+
+	// Multi-byte characters in the justification aren't split at the limit
+	condition = newThandCondition("", &models.AuditContext{Reason: strings.Repeat("Zugriff für Störungsbehebung ", 20)})
+	assert.True(t, utf8.ValidString(condition.Description))
+	assert.LessOrEqual(t, len(condition.Description), 256)
+}
+
+func TestTruncateConditionDescription(t *testing.T) {
+	// One byte then two byte characters so the limit falls mid character
+	description := "a" + strings.Repeat("ü", 200)
+
+	truncated := truncateConditionDescription(description)
+	assert.True(t, utf8.ValidString(truncated))
+	assert.Equal(t, "a"+strings.Repeat("ü", 127), truncated)
+	assert.Len(t, truncated, 255)
+
+	assert.Equal(t, "short", truncateConditionDescription("short"))
+}
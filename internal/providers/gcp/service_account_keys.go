@@ -0,0 +1,218 @@
+package gcp
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+	"go.temporal.io/sdk/temporal"
+	"google.golang.org/api/googleapi"
+	iam "google.golang.org/api/iam/v1"
+)
+
+const (
+	// GrantTypeIamBinding binds the user to the role in the project IAM policy
+	GrantTypeIamBinding = "iam_binding"
+	// GrantTypeServiceAccountKey creates a temporary service account key
+	GrantTypeServiceAccountKey = "service_account_key"
+)
+
+const (
+	MetadataGrantTypeKey      = "grant_type"
+	MetadataServiceAccountKey = "service_account"
+	MetadataKeyNameKey        = "key_name"
+)
+
+// getGrantType returns how access is granted, defaulting to IAM bindings
+func (p *gcpProvider) getGrantType() (string, error) {
+
+	grantType := p.GetConfig().GetStringWithDefault("grant_type", GrantTypeIamBinding)
+
+	switch grantType {
+	case GrantTypeIamBinding, GrantTypeServiceAccountKey, GrantTypeGroup:
+		return grantType, nil
+	}
+
+	return "", fmt.Errorf("unsupported gcp grant_type: %s, must be %s, %s or %s",
+		grantType, GrantTypeIamBinding, GrantTypeServiceAccountKey, GrantTypeGroup)
+}
+
+// getServiceAccountResource returns the full resource name of the service
+// account to create keys for
+func (p *gcpProvider) getServiceAccountResource() (string, error) {
+
+	serviceAccount, found := p.GetConfig().GetString("service_account")
+
+	if !found || len(serviceAccount) == 0 {
+		return "", fmt.Errorf("service_account must be configured to use grant_type %s", GrantTypeServiceAccountKey)
+	}
+
+	if strings.HasPrefix(serviceAccount, "projects/") {
+		return serviceAccount, nil
+	}
+
+	return fmt.Sprintf("projects/%s/serviceAccounts/%s", p.GetProjectId(), serviceAccount), nil
+}
+
+// authorizeServiceAccountKey creates a key for the configured service
+// account. The JSON key file is only returned encrypted for the requester's
+// public key so it never appears in the workflow history.
+func (p *gcpProvider) authorizeServiceAccountKey(
+	ctx context.Context,
+	req *models.AuthorizeRoleRequest,
+) (*models.AuthorizeRoleResponse, error) {
+
+	log := p.GetLogger(ctx)
+
+	user := req.GetUser()
+
+	serviceAccount, err := p.getServiceAccountResource()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the key before creating anything that couldn't be handed over
+	if len(req.GetPublicKey()) == 0 {
+		return nil, fmt.Errorf("%s returns a service account key to the requester, the request must include a public key",
+			req.GetRole().Name)
+	}
+
+	if err := models.ValidatePublicKey(req.GetPublicKey()); err != nil {
+		return nil, err
+	}
+
+	key, err := p.GetIamClient().Projects.ServiceAccounts.Keys.Create(
+		serviceAccount,
+		&iam.CreateServiceAccountKeyRequest{},
+	).Context(ctx).Do()
+	if err != nil {
+		return nil, temporal.NewApplicationErrorWithOptions(
+			fmt.Sprintf("failed to create key for service account %s: %v", serviceAccount, err),
+			"GcpServiceAccountKeyCreationError",
+			temporal.ApplicationErrorOptions{
+				NextRetryDelay: 3 * time.Second,
+				Cause:          err,
+			},
+		)
+	}
+
+	keyFile, err := base64.StdEncoding.DecodeString(key.PrivateKeyData)
+	if err != nil {
+		p.deleteServiceAccountKey(ctx, key.Name)
+		return nil, fmt.Errorf("failed to decode key for service account %s: %w", serviceAccount, err)
+	}
+
+	expiresAt := time.Time{}
+	if duration := req.GetDuration(); duration != nil {
+		expiresAt = time.Now().Add(*duration)
+	}
+
+	artifact, err := models.NewCredentialArtifact(
+		p.GetIdentifier(), req.GetRole().Name, req.GetPublicKey(),
+		map[string]any{
+			path.Base(serviceAccount): map[string]any{
+				"data": map[string]any{
+					"key_file": string(keyFile),
+				},
+			},
+		}, expiresAt)
+
+	if err != nil {
+		// Don't leave a key behind that the requester never received
+		p.deleteServiceAccountKey(ctx, key.Name)
+		return nil, err
+	}
+
+	log.WithFields(logrus.Fields{
+		"user_email":      user.Email,
+		"service_account": serviceAccount,
+		"key_name":        key.Name,
+	}).Info("Created GCP service account key")
+
+	// Keys can't be named or labelled so the grant records the key name to
+	// delete on revoke
+	grant := models.NewGrantRef(p.GetIdentifier(), GrantTypeServiceAccountKey, req.RoleRequest)
+	grant.AddID(key.Name)
+
+	return &models.AuthorizeRoleResponse{
+		UserId:      user.Email,
+		GrantRef:    grant,
+		Credentials: artifact,
+		Metadata: map[string]any{
+			MetadataGrantTypeKey:      GrantTypeServiceAccountKey,
+			MetadataServiceAccountKey: serviceAccount,
+			MetadataKeyNameKey:        key.Name,
+		},
+	}, nil
+}
+
+// deleteServiceAccountKey makes a best effort to delete a key that couldn't
+// be returned to the requester
+func (p *gcpProvider) deleteServiceAccountKey(ctx context.Context, keyName string) {
+
+	if _, err := p.GetIamClient().Projects.ServiceAccounts.Keys.Delete(keyName).Context(ctx).Do(); err != nil {
+		p.GetLogger(ctx).WithError(err).WithField("key_name", keyName).Warn("Failed to delete GCP service account key")
+	}
+}
+
+// revokeServiceAccountKey deletes the key created during authorization
+func (p *gcpProvider) revokeServiceAccountKey(
+	ctx context.Context,
+	req *models.RevokeRoleRequest,
+) (*models.RevokeRoleResponse, error) {
+
+	log := p.GetLogger(ctx)
+
+	keyName, ok := req.AuthorizeRoleResponse.Metadata[MetadataKeyNameKey].(string)
+
+	if grant := req.GetGrantRef(); grant.HasIDs() {
+		keyName, ok = grant.IDs[0], true
+	}
+
+	if !ok || len(keyName) == 0 {
+		return nil, fmt.Errorf("no service account key found in authorization response for revocation")
+	}
+
+	_, err := p.GetIamClient().Projects.ServiceAccounts.Keys.Delete(keyName).Context(ctx).Do()
+
+	// The key was already deleted by an earlier attempt
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		err = nil
+	}
+
+	if err != nil {
+		return nil, temporal.NewApplicationErrorWithOptions(
+			fmt.Sprintf("failed to delete service account key %s: %v", keyName, err),
+			"GcpServiceAccountKeyDeletionError",
+			temporal.ApplicationErrorOptions{
+				NextRetryDelay: 3 * time.Second,
+				Cause:          err,
+			},
+		)
+	}
+
+	log.WithFields(logrus.Fields{
+		"user_email": req.GetUser().Email,
+		"key_name":   keyName,
+	}).Info("Deleted GCP service account key")
+
+	return &models.RevokeRoleResponse{}, nil
+}
+
+// isServiceAccountKeyGrant returns true if the authorization created a
+// service account key
+func isServiceAccountKeyGrant(resp *models.AuthorizeRoleResponse) bool {
+	if resp == nil {
+		return false
+	}
+	grantType, _ := resp.Metadata[MetadataGrantTypeKey].(string)
+	return grantType == GrantTypeServiceAccountKey
+}
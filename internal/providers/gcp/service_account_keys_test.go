@@ -0,0 +1,172 @@
+package gcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+	iam "google.golang.org/api/iam/v1"
+	"google.golang.org/api/option"
+)
+
+func newTestServiceAccountKeyProvider(t *testing.T, config models.BasicConfig, handler http.HandlerFunc) *gcpProvider {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	iamService, err := iam.NewService(
+		context.Background(),
+		option.WithEndpoint(server.URL+"/"),
+		option.WithoutAuthentication(),
+	)
+	require.NoError(t, err)
+
+	return &gcpProvider{
+		BaseProvider: models.NewBaseProvider("gcp", models.Provider{
+			Name:     "gcp",
+			Provider: GcpProviderName,
+			Config:   &config,
+		}, models.ProviderCapabilityRBAC),
+		client:    &GcpConfigurationProvider{ProjectID: "test-project"},
+		iamClient: iamService,
+	}
+}
+
+func TestServiceAccountKeyGrant(t *testing.T) {
+
+	const keyName = "projects/test-project/serviceAccounts/deployer@test-project.iam.gserviceaccount.com/keys/abc123"
+	keyFile := `{"type":"service_account","private_key_id":"abc123"}`
+
+	var deleted string
+
+	provider := newTestServiceAccountKeyProvider(t, models.BasicConfig{
+		"grant_type":      GrantTypeServiceAccountKey,
+		"service_account": "deployer@test-project.iam.gserviceaccount.com",
+	}, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			assert.Equal(t, "/v1/projects/test-project/serviceAccounts/deployer@test-project.iam.gserviceaccount.com/keys", r.URL.Path)
+			json.NewEncoder(w).Encode(iam.ServiceAccountKey{
+				Name:           keyName,
+				PrivateKeyData: base64.StdEncoding.EncodeToString([]byte(keyFile)),
+			})
+		case http.MethodDelete:
+			deleted = r.URL.Path
+			w.Write([]byte("{}"))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	user := &models.User{Email: "alice@example.com"}
+	role := &models.Role{Name: "deployer"}
+	duration := time.Hour
+
+	resp, err := provider.AuthorizeRole(context.Background(), &models.AuthorizeRoleRequest{
+		RoleRequest: &models.RoleRequest{
+			User:      user,
+			Role:      role,
+			Duration:  &duration,
+			PublicKey: identity.Recipient().String(),
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, GrantTypeServiceAccountKey, resp.Metadata[MetadataGrantTypeKey])
+	assert.Equal(t, keyName, resp.Metadata[MetadataKeyNameKey])
+
+	// The key file is only returned encrypted for the requester
+	encoded, err := json.Marshal(resp)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "private_key_id")
+
+	require.NotNil(t, resp.Credentials)
+	assert.Equal(t, "deployer", resp.Credentials.Role)
+	assert.WithinDuration(t, time.Now().Add(duration), resp.Credentials.ExpiresAt, time.Minute)
+
+	credentials, err := resp.Credentials.Decrypt(identity)
+	require.NoError(t, err)
+	secret, _ := credentials["deployer@test-project.iam.gserviceaccount.com"].(map[string]any)
+	data, _ := secret["data"].(map[string]any)
+	assert.JSONEq(t, keyFile, data["key_file"].(string))
+
+	_, err = provider.RevokeRole(context.Background(), &models.RevokeRoleRequest{
+		RoleRequest:           &models.RoleRequest{User: user, Role: role},
+		AuthorizeRoleResponse: resp,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "/v1/"+keyName, deleted)
+}
+
+func TestServiceAccountKeyGrantRequiresPublicKey(t *testing.T) {
+
+	created := false
+
+	provider := newTestServiceAccountKeyProvider(t, models.BasicConfig{
+		"grant_type":      GrantTypeServiceAccountKey,
+		"service_account": "deployer@test-project.iam.gserviceaccount.com",
+	}, func(w http.ResponseWriter, r *http.Request) {
+		created = true
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	_, err := provider.AuthorizeRole(context.Background(), &models.AuthorizeRoleRequest{
+		RoleRequest: &models.RoleRequest{
+			User: &models.User{Email: "alice@example.com"},
+			Role: &models.Role{Name: "deployer"},
+		},
+	})
+	assert.ErrorContains(t, err, "must include a public key")
+	assert.False(t, created, "no key should be created that can't be handed over")
+}
+
+func TestServiceAccountKeyGrantConfig(t *testing.T) {
+
+	t.Run("requires a service account", func(t *testing.T) {
+		provider := newTestServiceAccountKeyProvider(t, models.BasicConfig{
+			"grant_type": GrantTypeServiceAccountKey,
+		}, nil)
+
+		_, err := provider.getServiceAccountResource()
+		assert.ErrorContains(t, err, "service_account must be configured")
+	})
+
+	t.Run("accepts a full resource name", func(t *testing.T) {
+		provider := newTestServiceAccountKeyProvider(t, models.BasicConfig{
+			"service_account": "projects/other/serviceAccounts/sa@other.iam.gserviceaccount.com",
+		}, nil)
+
+		resource, err := provider.getServiceAccountResource()
+		require.NoError(t, err)
+		assert.Equal(t, "projects/other/serviceAccounts/sa@other.iam.gserviceaccount.com", resource)
+	})
+
+	t.Run("rejects unknown grant types", func(t *testing.T) {
+		provider := newTestServiceAccountKeyProvider(t, models.BasicConfig{
+			"grant_type": "magic",
+		}, nil)
+
+		_, err := provider.getGrantType()
+		assert.ErrorContains(t, err, "unsupported gcp grant_type")
+	})
+
+	t.Run("defaults to iam bindings", func(t *testing.T) {
+		provider := newTestServiceAccountKeyProvider(t, models.BasicConfig{}, nil)
+
+		grantType, err := provider.getGrantType()
+		require.NoError(t, err)
+		assert.Equal(t, GrantTypeIamBinding, grantType)
+	})
+}
@@ -1,6 +1,7 @@
 package gcp
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -27,9 +28,9 @@ type gcpData struct {
 }
 
 type gcpSingleton struct {
+	mu   sync.Mutex
 	data *gcpData
 	err  error
-	once sync.Once
 }
 
 var (
@@ -37,7 +38,10 @@ var (
 	sharedDataMu  sync.Mutex
 )
 
-func getSharedData(stage string) (*gcpData, error) {
+// loadBatchSize is how many entries are parsed between cancellation checks
+const loadBatchSize = 1000
+
+func getSharedData(ctx context.Context, stage string) (*gcpData, error) {
 	sharedDataMu.Lock()
 	singleton, ok := sharedDataMap[stage]
 	if !ok {
@@ -46,26 +50,32 @@ func getSharedData(stage string) (*gcpData, error) {
 	}
 	sharedDataMu.Unlock()
 
-	singleton.once.Do(func() {
-		data := &gcpData{}
-		var err error
+	singleton.mu.Lock()
+	defer singleton.mu.Unlock()
 
-		data.permissions, err = loadPermissions(stage)
-		if err != nil {
-			singleton.err = err
-			return
-		}
+	if singleton.data != nil || singleton.err != nil {
+		return singleton.data, singleton.err
+	}
+
+	data := &gcpData{}
+	var err error
+
+	data.permissions, err = loadPermissions(ctx, stage)
+	if err == nil {
+		data.roles, err = loadRoles(ctx, stage)
+	}
 
-		data.roles, err = loadRoles(stage)
-		if err != nil {
+	if err != nil {
+		// A cancelled load isn't cached so the next caller can retry it
+		if ctx.Err() == nil {
 			singleton.err = err
-			return
 		}
+		return nil, err
+	}
 
-		singleton.data = data
-	})
+	singleton.data = data
 
-	return singleton.data, singleton.err
+	return singleton.data, nil
 }
 
 type gcpPermissionMap []struct {
@@ -77,7 +87,7 @@ type gcpPermissionMap []struct {
 	OnlyInPredefinedRoles bool   `json:"onlyInPredefinedRoles,omitempty"`
 }
 
-func loadPermissions(stage string) ([]models.ProviderPermission, error) {
+func loadPermissions(ctx context.Context, stage string) ([]models.ProviderPermission, error) {
 	var permissionMap gcpPermissionMap
 
 	startTime := time.Now()
@@ -97,7 +107,13 @@ func loadPermissions(stage string) ([]models.ProviderPermission, error) {
 		stage = DefaultStage
 	}
 
-	for _, perm := range permissionMap {
+	for i, perm := range permissionMap {
+
+		if i%loadBatchSize == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("loading GCP permissions cancelled: %w", err)
+			}
+		}
 
 		if perm.OnlyInPredefinedRoles {
 			continue
@@ -120,7 +136,7 @@ func loadPermissions(stage string) ([]models.ProviderPermission, error) {
 	return permissions, nil
 }
 
-func loadRoles(stage string) ([]models.ProviderRole, error) {
+func loadRoles(ctx context.Context, stage string) ([]models.ProviderRole, error) {
 
 	startTime := time.Now()
 	defer func() {
@@ -140,7 +156,13 @@ func loadRoles(stage string) ([]models.ProviderRole, error) {
 		stage = DefaultStage
 	}
 
-	for _, gcpRole := range predefinedRoles {
+	for i, gcpRole := range predefinedRoles {
+
+		if i%loadBatchSize == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("loading GCP roles cancelled: %w", err)
+			}
+		}
 
 		if !strings.EqualFold(gcpRole.Stage, stage) {
 			continue
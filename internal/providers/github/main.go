@@ -1,8 +1,8 @@
 package github
 
 import (
-	"strings"
 	"context"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 	"github.com/thand-io/agent/internal/models"
@@ -17,8 +17,8 @@ var GithubProviderName = "github"
 // githubProvider implements the ProviderImpl interface for GitHub
 type githubProvider struct {
 	*models.BaseProvider
-	client      *github.Client
-	oauthClient *oauth2.Config
+	client           *github.Client
+	oauthClient      *oauth2.Config
 	organizationName string
 }
 
@@ -38,7 +38,16 @@ type GitHubTokenResponse struct {
 	Scope       string `json:"scope"`
 }
 
-func (p *githubProvider) Initialize(identifier string, provider models.Provider) error {
+// ValidateConfig checks the OAuth app credentials, if used, are complete
+func (p *githubProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(GithubProviderName)
+	validation.RequireTogether(config, "client_id", "client_secret")
+
+	return validation.ErrorOrNil()
+}
+
+func (p *githubProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
@@ -51,7 +60,6 @@ func (p *githubProvider) Initialize(identifier string, provider models.Provider)
 	// Right lets figure out how to initialize the GitHub SDK
 	githubConfig := p.GetConfig()
 
-
 	githubToken, foundToken := githubConfig.GetString("token")
 
 	if foundToken && len(strings.TrimSpace(githubToken)) > 0 {
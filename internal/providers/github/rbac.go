@@ -13,6 +13,11 @@ import (
 	"github.com/thand-io/agent/internal/models"
 )
 
+// GrantKindResourceAccess is the grant kind for GitHub. Memberships and
+// collaborators have no ID of their own, so the grant IDs are the resources
+// the user was added to.
+const GrantKindResourceAccess = "resource_access"
+
 // Authorize grants access for a user to a role
 func (p *githubProvider) AuthorizeRole(
 	ctx context.Context,
@@ -28,14 +33,20 @@ func (p *githubProvider) AuthorizeRole(
 
 	username := user.Name
 
+	grant := models.NewGrantRef(p.GetIdentifier(), GrantKindResourceAccess, req.RoleRequest)
+	grant.SetAttribute("username", username)
+
 	// Process each resource in the role
 	for _, resource := range role.Resources.Allow {
 		if err := p.authorizeResource(ctx, username, resource, role); err != nil {
 			return nil, fmt.Errorf("failed to authorize resource %s: %w", resource, err)
 		}
+		grant.AddID(resource)
 	}
 
-	return nil, nil
+	return &models.AuthorizeRoleResponse{
+		GrantRef: grant,
+	}, nil
 }
 
 // Revoke removes access for a user from a role
@@ -52,9 +63,16 @@ func (p *githubProvider) RevokeRole(
 	role := req.GetRole()
 
 	username := user.Name
+	resources := role.Resources.Allow
+
+	// Revoke exactly the resources recorded by the grant
+	if grant := req.GetGrantRef(); grant.HasIDs() {
+		username = grant.GetAttribute("username")
+		resources = grant.IDs
+	}
 
 	// Process each resource in the role
-	for _, resource := range role.Resources.Allow {
+	for _, resource := range resources {
 		if err := p.revokeResource(ctx, username, resource); err != nil {
 			return nil, fmt.Errorf("failed to revoke resource %s: %w", resource, err)
 		}
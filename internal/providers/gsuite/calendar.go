@@ -0,0 +1,102 @@
+package gsuite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	calendar "google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+
+	"github.com/thand-io/agent/internal/models"
+)
+
+// ListCalendarEvents reads the events of a Google Calendar, e.g. a change
+// freeze calendar, as the admin. Recurring events are expanded into their
+// occurrences.
+func (p *gsuiteProvider) ListCalendarEvents(
+	ctx context.Context,
+	calendarID string,
+	start time.Time,
+	end time.Time,
+) ([]models.CalendarEvent, error) {
+
+	conf, err := p.gcpConfig.CreateJWTConfig(calendar.CalendarReadonlyScope)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWT config: %w", err)
+	}
+
+	conf.Subject = p.adminEmail
+
+	calendarService, err := calendar.NewService(ctx, option.WithTokenSource(conf.TokenSource(ctx)))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create calendar service: %w", err)
+	}
+
+	events := []models.CalendarEvent{}
+
+	err = calendarService.Events.List(calendarID).
+		TimeMin(start.Format(time.RFC3339)).
+		TimeMax(end.Format(time.RFC3339)).
+		SingleEvents(true).
+		ShowDeleted(false).
+		Pages(ctx, func(page *calendar.Events) error {
+			for _, item := range page.Items {
+
+				event, err := newCalendarEvent(item, page.TimeZone)
+
+				if err != nil {
+					return err
+				}
+
+				events = append(events, event)
+			}
+			return nil
+		})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events of calendar %s: %w", calendarID, err)
+	}
+
+	return events, nil
+}
+
+// newCalendarEvent converts a Google Calendar event. All day events have
+// dates in the calendar's zone rather than times.
+func newCalendarEvent(item *calendar.Event, timeZone string) (models.CalendarEvent, error) {
+
+	event := models.CalendarEvent{
+		Summary:  item.Summary,
+		TimeZone: timeZone,
+	}
+
+	if item.Start == nil || item.End == nil {
+		return event, fmt.Errorf("event %s has no start or end", item.Id)
+	}
+
+	if len(item.Start.Date) > 0 {
+		event.AllDay = true
+		event.StartDate = item.Start.Date
+		event.EndDate = item.End.Date
+		return event, nil
+	}
+
+	start, err := time.Parse(time.RFC3339, item.Start.DateTime)
+
+	if err != nil {
+		return event, fmt.Errorf("event %s has an invalid start: %w", item.Id, err)
+	}
+
+	end, err := time.Parse(time.RFC3339, item.End.DateTime)
+
+	if err != nil {
+		return event, fmt.Errorf("event %s has an invalid end: %w", item.Id, err)
+	}
+
+	event.Start = start
+	event.End = end
+
+	return event, nil
+}
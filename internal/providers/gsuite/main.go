@@ -19,11 +19,22 @@ type gsuiteProvider struct {
 	*models.BaseProvider
 
 	adminService *admin.Service
+	gcpConfig    *gcp.GcpConfigurationProvider
 	domain       string
 	adminEmail   string
 }
 
-func (p *gsuiteProvider) Initialize(identifier string, provider models.Provider) error {
+// ValidateConfig checks the service account, domain and admin to
+// impersonate are set
+func (p *gsuiteProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(GsuiteProviderName)
+	validation.Require(config, "service_account_key_path", "domain", "admin_email")
+
+	return validation.ErrorOrNil()
+}
+
+func (p *gsuiteProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
 		provider,
@@ -52,8 +63,10 @@ func (p *gsuiteProvider) Initialize(identifier string, provider models.Provider)
 	p.domain = domain
 	p.adminEmail = adminEmail
 
-	// Create admin service with domain-wide delegation
-	ctx := context.Background()
+	// Create admin service with domain-wide delegation. The token source
+	// holds on to the context so it mustn't be cancelled when
+	// initialization finishes
+	clientCtx := context.WithoutCancel(ctx)
 
 	// Use shared GCP configuration to handle credentials
 	gcpClient, err := gcp.CreateGcpConfig(config)
@@ -74,12 +87,13 @@ func (p *gsuiteProvider) Initialize(identifier string, provider models.Provider)
 	conf.Subject = adminEmail
 
 	// Create the admin service with the JWT config
-	adminService, err := admin.NewService(ctx, option.WithTokenSource(conf.TokenSource(ctx)))
+	adminService, err := admin.NewService(clientCtx, option.WithTokenSource(conf.TokenSource(clientCtx)))
 	if err != nil {
 		return fmt.Errorf("failed to create admin service: %w", err)
 	}
 
 	p.adminService = adminService
+	p.gcpConfig = gcpClient
 
 	return nil
 }
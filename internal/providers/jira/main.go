@@ -0,0 +1,401 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira/v2/onpremise"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/providers"
+)
+
+const JiraProviderName = "jira"
+
+const (
+	DefaultIssueType      = "Task"
+	DefaultApprovedStatus = "Approved"
+)
+
+// Jira rejects summaries longer than this
+const maxSummaryLength = 255
+
+// issueKeyPattern matches issue keys e.g. SEC-123 in a request's reason
+var issueKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9_]+-[0-9]+\b`)
+
+// JiraNotificationRequest creates an issue, or comments on an existing
+// issue when TicketID is set. Either can then move the issue through a
+// transition e.g. "Start Review".
+type JiraNotificationRequest struct {
+	TicketID    string   `json:"ticket_id,omitempty"` // Comment on this issue rather than creating one
+	Project     string   `json:"project,omitempty"`   // Defaults to the configured project
+	IssueType   string   `json:"issue_type,omitempty"`
+	Summary     string   `json:"summary"`
+	Description string   `json:"description,omitempty"`
+	Comment     string   `json:"comment,omitempty"` // Comment for an existing issue, defaults to the description
+	Priority    string   `json:"priority,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	Transition  string   `json:"transition,omitempty"` // The name of the transition to apply
+}
+
+// jiraProvider implements the ProviderImpl interface for Jira. Access
+// requests are tracked as issues and, when approval is required, a role
+// is only authorized once the issue in the request's reason is approved.
+type jiraProvider struct {
+	*models.BaseProvider
+	client         *jira.Client
+	endpoint       string
+	project        string
+	issueType      string
+	approvedStatus string
+}
+
+// ValidateConfig checks the site is set along with an API token and the
+// email it belongs to, or a personal access token
+func (p *jiraProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(JiraProviderName)
+	validation.Require(config, "endpoint")
+
+	if !config.HasString("token") {
+		validation.Require(config, "email", "api_token")
+	}
+
+	if requireApproval, _ := config.GetBool("require_approval"); requireApproval {
+		validation.Require(config, "project")
+	}
+
+	return validation.ErrorOrNil()
+}
+
+func (p *jiraProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
+
+	jiraConfig := provider.Config
+
+	capabilities := []models.ProviderCapability{
+		models.ProviderCapabilityNotifier,
+	}
+
+	// Gate access on the issue linked in the request
+	if requireApproval, _ := jiraConfig.GetBool("require_approval"); requireApproval {
+		capabilities = append(capabilities, models.ProviderCapabilityRBAC)
+	}
+
+	p.BaseProvider = models.NewBaseProvider(
+		identifier,
+		provider,
+		capabilities...,
+	)
+
+	endpoint, foundEndpoint := jiraConfig.GetString("endpoint")
+
+	if !foundEndpoint || len(endpoint) == 0 {
+		return fmt.Errorf("missing Jira endpoint configuration")
+	}
+
+	var httpClient *http.Client
+
+	// Personal access tokens are used by Jira Data Center, Jira Cloud uses
+	// an API token with the account's email. Both support version 2 of the
+	// REST API used by the onpremise client.
+	if token, found := jiraConfig.GetString("token"); found && len(token) > 0 {
+		httpClient = (&jira.PATAuthTransport{Token: token}).Client()
+	} else {
+		email, foundEmail := jiraConfig.GetString("email")
+		apiToken, foundApiToken := jiraConfig.GetString("api_token")
+
+		if !foundEmail || !foundApiToken {
+			return fmt.Errorf("missing Jira credentials, either token or email and api_token are required")
+		}
+
+		httpClient = (&jira.BasicAuthTransport{Username: email, Password: apiToken}).Client()
+	}
+
+	p.endpoint = strings.TrimSuffix(endpoint, "/")
+
+	client, err := jira.NewClient(p.endpoint, httpClient)
+
+	if err != nil {
+		return fmt.Errorf("failed to create Jira client: %w", err)
+	}
+
+	p.client = client
+	p.project = jiraConfig.GetStringWithDefault("project", "")
+	p.issueType = jiraConfig.GetStringWithDefault("issue_type", DefaultIssueType)
+	p.approvedStatus = jiraConfig.GetStringWithDefault("approved_status", DefaultApprovedStatus)
+
+	return nil
+}
+
+// TestConnection fetches the account the provider authenticates as
+func (p *jiraProvider) TestConnection(ctx context.Context, capability models.ProviderCapability) error {
+	switch capability {
+	case models.ProviderCapabilityNotifier, models.ProviderCapabilityRBAC:
+		if _, resp, err := p.client.User.GetSelf(ctx); err != nil {
+			return fmt.Errorf("failed to get the Jira account: %w", getError(resp, err))
+		}
+		return nil
+	}
+	return models.ErrNotImplemented
+}
+
+func (p *jiraProvider) SendNotification(
+	ctx context.Context, notification models.NotificationRequest,
+) (*models.NotificationReceipt, error) {
+
+	var jiraRequest JiraNotificationRequest
+	if err := common.ConvertMapToInterface(notification, &jiraRequest); err != nil {
+		return nil, fmt.Errorf("failed to convert Jira notification: %w", err)
+	}
+
+	var receipt *models.NotificationReceipt
+	var err error
+
+	if len(jiraRequest.TicketID) > 0 {
+		receipt, err = p.addComment(ctx, &jiraRequest)
+	} else {
+		receipt, err = p.createIssue(ctx, &jiraRequest, notification.GetNotificationID())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(jiraRequest.Transition) > 0 {
+		if err := p.transitionIssue(ctx, receipt.Target, jiraRequest.Transition); err != nil {
+			return nil, err
+		}
+	}
+
+	return receipt, nil
+}
+
+// createIssue opens an issue for the request. The notification ID is added
+// as a label so a retried notification finds the issue rather than
+// opening a second one.
+func (p *jiraProvider) createIssue(
+	ctx context.Context,
+	jiraRequest *JiraNotificationRequest,
+	notificationID string,
+) (*models.NotificationReceipt, error) {
+
+	if len(jiraRequest.Summary) == 0 {
+		return nil, fmt.Errorf("a summary is required to create a Jira issue")
+	}
+
+	project := jiraRequest.Project
+
+	if len(project) == 0 {
+		project = p.project
+	}
+
+	if len(project) == 0 {
+		return nil, fmt.Errorf("a project is required to create a Jira issue")
+	}
+
+	labels := jiraRequest.Labels
+
+	if len(notificationID) > 0 {
+		label := "thand-" + notificationID
+
+		existing, err := p.findIssueByLabel(ctx, project, label)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(existing) > 0 {
+			return &models.NotificationReceipt{
+				Target:    existing,
+				MessageID: existing,
+				Response:  "Issue already created",
+			}, nil
+		}
+
+		labels = append(labels, label)
+	}
+
+	issueType := jiraRequest.IssueType
+
+	if len(issueType) == 0 {
+		issueType = p.issueType
+	}
+
+	fields := &jira.IssueFields{
+		Project:     jira.Project{Key: project},
+		Type:        jira.IssueType{Name: issueType},
+		Summary:     truncate(jiraRequest.Summary, maxSummaryLength),
+		Description: jiraRequest.Description,
+		Labels:      labels,
+	}
+
+	if len(jiraRequest.Priority) > 0 {
+		fields.Priority = &jira.Priority{Name: getIssuePriority(jiraRequest.Priority)}
+	}
+
+	created, resp, err := p.client.Issue.Create(ctx, &jira.Issue{Fields: fields})
+
+	if err != nil {
+		// Create leaves the error response for the caller to read
+		return nil, fmt.Errorf("failed to create Jira issue: %w", getError(resp, jira.NewJiraError(resp, err)))
+	}
+
+	return &models.NotificationReceipt{
+		Target:    created.Key,
+		MessageID: created.Key,
+		Response:  created.Self,
+	}, nil
+}
+
+// addComment posts the notification as a comment on an existing issue
+func (p *jiraProvider) addComment(
+	ctx context.Context,
+	jiraRequest *JiraNotificationRequest,
+) (*models.NotificationReceipt, error) {
+
+	comment := jiraRequest.Comment
+
+	if len(comment) == 0 {
+		comment = jiraRequest.Description
+	}
+
+	if len(comment) == 0 {
+		comment = jiraRequest.Summary
+	}
+
+	if len(comment) == 0 {
+		return nil, fmt.Errorf("a comment, description or summary is required to comment on a Jira issue")
+	}
+
+	created, resp, err := p.client.Issue.AddComment(ctx, jiraRequest.TicketID, &jira.Comment{Body: comment})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to comment on Jira issue %s: %w", jiraRequest.TicketID, getError(resp, err))
+	}
+
+	return &models.NotificationReceipt{
+		Target:    jiraRequest.TicketID,
+		MessageID: created.ID,
+		Response:  created.Self,
+	}, nil
+}
+
+// transitionIssue moves the issue through the named transition. The name
+// of the status the transition leads to is also accepted.
+func (p *jiraProvider) transitionIssue(ctx context.Context, issueKey string, name string) error {
+
+	transitions, resp, err := p.client.Issue.GetTransitions(ctx, issueKey)
+
+	if err != nil {
+		return fmt.Errorf("failed to get transitions for Jira issue %s: %w", issueKey, getError(resp, err))
+	}
+
+	for _, transition := range transitions {
+		if !strings.EqualFold(transition.Name, name) && !strings.EqualFold(transition.To.Name, name) {
+			continue
+		}
+
+		resp, err := p.client.Issue.DoTransition(ctx, issueKey, transition.ID)
+
+		if err != nil {
+			return fmt.Errorf("failed to transition Jira issue %s to %s: %w", issueKey, name, getError(resp, err))
+		}
+
+		resp.Body.Close()
+
+		return nil
+	}
+
+	return fmt.Errorf("jira issue %s has no transition %s", issueKey, name)
+}
+
+// findIssueByLabel returns the key of the issue in the project with the
+// label, if there is one
+func (p *jiraProvider) findIssueByLabel(ctx context.Context, project string, label string) (string, error) {
+
+	issues, resp, err := p.client.Issue.Search(ctx,
+		fmt.Sprintf(`project = "%s" AND labels = "%s"`, project, label),
+		&jira.SearchOptions{Fields: []string{"key"}, MaxResults: 1})
+
+	if err != nil {
+		return "", fmt.Errorf("failed to search Jira issues: %w", getError(resp, err))
+	}
+
+	if len(issues) == 0 {
+		return "", nil
+	}
+
+	return issues[0].Key, nil
+}
+
+// getIssueStatus returns the name of the issue's status
+func (p *jiraProvider) getIssueStatus(ctx context.Context, issueKey string) (string, error) {
+
+	issue, resp, err := p.client.Issue.Get(ctx, issueKey, &jira.GetQueryOptions{Fields: "status"})
+
+	if err != nil {
+		return "", fmt.Errorf("failed to get Jira issue %s: %w", issueKey, getError(resp, err))
+	}
+
+	if issue.Fields == nil || issue.Fields.Status == nil {
+		return "", fmt.Errorf("jira issue %s has no status", issueKey)
+	}
+
+	return issue.Fields.Status.Name, nil
+}
+
+// IsIssueKey returns true if the value is an issue key e.g. SEC-123 rather
+// than a project key
+func IsIssueKey(value string) bool {
+	return len(value) > 0 && issueKeyPattern.FindString(value) == value
+}
+
+// getError returns the messages from a Jira error response as the client
+// only reports the first of them, or err if it isn't one
+func getError(resp *jira.Response, err error) error {
+
+	var jiraError *jira.Error
+
+	if resp == nil || !errors.As(err, &jiraError) {
+		return err
+	}
+
+	messages := slices.Clone(jiraError.ErrorMessages)
+
+	for _, field := range slices.Sorted(maps.Keys(jiraError.Errors)) {
+		messages = append(messages, fmt.Sprintf("%s: %s", field, jiraError.Errors[field]))
+	}
+
+	if len(messages) == 0 {
+		return err
+	}
+
+	return fmt.Errorf("jira returned status %d: %s", resp.StatusCode, strings.Join(messages, ", "))
+}
+
+// getIssuePriority maps high priority notifications e.g. break-glass
+// requests to Jira's Highest priority, anything else is used as is
+func getIssuePriority(priority string) string {
+	if strings.EqualFold(priority, models.NotificationPriorityHigh) {
+		return "Highest"
+	}
+	return priority
+}
+
+// truncate shortens the value to the number of characters
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+	return string(runes[:length])
+}
+
+func init() {
+	providers.Register(JiraProviderName, &jiraProvider{})
+}
@@ -0,0 +1,252 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// fakeJira serves the parts of the Jira REST API the provider uses
+type fakeJira struct {
+	issues      map[string]map[string]any // Fields of each issue, by key
+	comments    map[string][]string
+	transitions map[string][]string // Transition IDs applied to each issue
+}
+
+func newFakeJira() *fakeJira {
+	return &fakeJira{
+		issues: map[string]map[string]any{
+			"SEC-1": {"status": map[string]any{"name": "Approved"}},
+			"SEC-2": {"status": map[string]any{"name": "In Review"}},
+		},
+		comments:    map[string][]string{},
+		transitions: map[string][]string{},
+	}
+}
+
+func (f *fakeJira) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	var body map[string]any
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/rest/api/2/")
+	parts := strings.Split(path, "/")
+
+	switch {
+	case path == "myself":
+		writeJSON(w, http.StatusOK, map[string]any{"accountId": "bot"})
+
+	case path == "search":
+		issues := []map[string]any{}
+		jql := r.URL.Query().Get("jql")
+		for key, fields := range f.issues {
+			labels, _ := fields["labels"].([]any)
+			for _, label := range labels {
+				if strings.Contains(jql, `labels = "`+label.(string)+`"`) {
+					issues = append(issues, map[string]any{"key": key})
+				}
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"issues": issues})
+
+	case path == "issue" && r.Method == http.MethodPost:
+		fields := body["fields"].(map[string]any)
+		if _, found := fields["summary"]; !found {
+			writeJSON(w, http.StatusBadRequest, map[string]any{
+				"errors": map[string]string{"summary": "You must specify a summary of the issue."},
+			})
+			return
+		}
+		key := "SEC-" + string(rune('0'+len(f.issues)+1))
+		f.issues[key] = fields
+		writeJSON(w, http.StatusCreated, map[string]any{"id": "1000", "key": key, "self": "https://jira/" + key})
+
+	case len(parts) == 2 && parts[0] == "issue":
+		fields, found := f.issues[parts[1]]
+		if !found {
+			writeJSON(w, http.StatusNotFound, map[string]any{"errorMessages": []string{"Issue does not exist"}})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"key": parts[1], "fields": fields})
+
+	case len(parts) == 3 && f.issues[parts[1]] == nil:
+		writeJSON(w, http.StatusNotFound, map[string]any{"errorMessages": []string{"Issue does not exist"}})
+
+	case len(parts) == 3 && parts[2] == "comment":
+		f.comments[parts[1]] = append(f.comments[parts[1]], body["body"].(string))
+		writeJSON(w, http.StatusCreated, map[string]any{"id": "2000"})
+
+	case len(parts) == 3 && parts[2] == "transitions" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"transitions": []map[string]any{
+			{"id": "11", "name": "Start Review", "to": map[string]any{"name": "In Review"}},
+			{"id": "21", "name": "Approve", "to": map[string]any{"name": "Approved"}},
+		}})
+
+	case len(parts) == 3 && parts[2] == "transitions":
+		transition := body["transition"].(map[string]any)
+		f.transitions[parts[1]] = append(f.transitions[parts[1]], transition["id"].(string))
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func newTestProvider(t *testing.T, fake *fakeJira, config models.BasicConfig) *jiraProvider {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	config["endpoint"] = server.URL
+	config["token"] = "personal-access-token"
+
+	provider := &jiraProvider{}
+	require.NoError(t, provider.ValidateConfig(&config))
+	require.NoError(t, provider.Initialize(context.Background(), JiraProviderName, models.Provider{
+		Name:     JiraProviderName,
+		Provider: JiraProviderName,
+		Config:   &config,
+	}))
+
+	return provider
+}
+
+func TestSendNotification(t *testing.T) {
+
+	t.Run("creates an issue", func(t *testing.T) {
+		fake := newFakeJira()
+		provider := newTestProvider(t, fake, models.BasicConfig{"project": "SEC"})
+
+		receipt, err := provider.SendNotification(context.Background(), models.NotificationRequest{
+			"summary":                "Access requested",
+			"description":            "alice requested admin",
+			"priority":               "high",
+			models.NotificationIDKey: "abc",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "SEC-3", receipt.Target)
+
+		fields := fake.issues["SEC-3"]
+		assert.Equal(t, map[string]any{"key": "SEC"}, fields["project"])
+		assert.Equal(t, map[string]any{"name": "Task"}, fields["issuetype"])
+		assert.Equal(t, map[string]any{"name": "Highest"}, fields["priority"])
+		assert.Equal(t, []any{"thand-abc"}, fields["labels"])
+
+		// A retried notification finds the issue it created
+		receipt, err = provider.SendNotification(context.Background(), models.NotificationRequest{
+			"summary":                "Access requested",
+			models.NotificationIDKey: "abc",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "SEC-3", receipt.Target)
+		assert.Len(t, fake.issues, 3)
+	})
+
+	t.Run("comments on an existing issue and transitions it", func(t *testing.T) {
+		fake := newFakeJira()
+		provider := newTestProvider(t, fake, models.BasicConfig{})
+
+		receipt, err := provider.SendNotification(context.Background(), models.NotificationRequest{
+			"ticket_id":   "SEC-2",
+			"description": "alice requested admin",
+			"transition":  "approved",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "SEC-2", receipt.Target)
+		assert.Equal(t, []string{"alice requested admin"}, fake.comments["SEC-2"])
+		assert.Equal(t, []string{"21"}, fake.transitions["SEC-2"])
+	})
+
+	t.Run("unknown transition", func(t *testing.T) {
+		provider := newTestProvider(t, newFakeJira(), models.BasicConfig{})
+
+		_, err := provider.SendNotification(context.Background(), models.NotificationRequest{
+			"ticket_id":  "SEC-2",
+			"comment":    "done",
+			"transition": "Close",
+		})
+		assert.EqualError(t, err, "jira issue SEC-2 has no transition Close")
+	})
+
+	t.Run("jira errors are reported", func(t *testing.T) {
+		provider := newTestProvider(t, newFakeJira(), models.BasicConfig{})
+
+		_, err := provider.SendNotification(context.Background(), models.NotificationRequest{
+			"ticket_id": "SEC-9",
+			"comment":   "done",
+		})
+		assert.EqualError(t, err, "failed to comment on Jira issue SEC-9: jira returned status 404: Issue does not exist")
+	})
+}
+
+func TestAuthorizeRole(t *testing.T) {
+
+	provider := newTestProvider(t, newFakeJira(), models.BasicConfig{
+		"project":          "SEC",
+		"require_approval": true,
+	})
+	assert.True(t, provider.HasCapability(models.ProviderCapabilityRBAC))
+
+	authorize := func(reason string) (*models.AuthorizeRoleResponse, error) {
+		return provider.AuthorizeRole(context.Background(), &models.AuthorizeRoleRequest{
+			RoleRequest: &models.RoleRequest{
+				User:  &models.User{Email: "alice@example.com"},
+				Role:  &models.Role{Name: "admin"},
+				Audit: &models.AuditContext{Reason: reason},
+			},
+		})
+	}
+
+	t.Run("approved issue", func(t *testing.T) {
+		resp, err := authorize("Deploying the fix for OPS-9 tracked in SEC-1")
+		require.NoError(t, err)
+		assert.Equal(t, "SEC-1", resp.Metadata[MetadataIssueKey])
+		assert.Equal(t, provider.endpoint+"/browse/SEC-1", provider.GetAuthorizedAccessUrl(context.Background(), nil, resp))
+	})
+
+	t.Run("issue not approved", func(t *testing.T) {
+		_, err := authorize("SEC-2")
+		assert.EqualError(t, err, "jira issue SEC-2 is In Review, it must be Approved before admin can be granted")
+	})
+
+	t.Run("no issue", func(t *testing.T) {
+		_, err := authorize("fixing prod, see OPS-9")
+		assert.EqualError(t, err, "a SEC issue must be referenced in the reason to request admin")
+	})
+}
+
+func TestValidateConfig(t *testing.T) {
+	provider := &jiraProvider{}
+
+	err := provider.ValidateConfig(&models.BasicConfig{"require_approval": true})
+	assert.EqualError(t, err, "invalid jira provider config: endpoint is required; email is required; "+
+		"api_token is required; project is required")
+
+	assert.NoError(t, provider.ValidateConfig(&models.BasicConfig{
+		"endpoint": "https://jira.example.com",
+		"token":    "pat",
+	}))
+}
+
+func TestIsIssueKey(t *testing.T) {
+	assert.True(t, IsIssueKey("SEC-123"))
+	assert.False(t, IsIssueKey("SEC"))
+	assert.False(t, IsIssueKey("see SEC-123"))
+	assert.False(t, IsIssueKey(""))
+}
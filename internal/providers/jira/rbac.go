@@ -0,0 +1,112 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// Metadata keys recording the issue that approved the request
+const (
+	MetadataIssueKey    = "jira_issue"
+	MetadataIssueStatus = "jira_status"
+)
+
+// AuthorizeRole grants nothing itself, it only succeeds once the issue
+// referenced in the request's reason has been approved. Workflows use it
+// ahead of the provider that grants the role.
+func (p *jiraProvider) AuthorizeRole(
+	ctx context.Context,
+	req *models.AuthorizeRoleRequest,
+) (*models.AuthorizeRoleResponse, error) {
+
+	if !req.IsValid() {
+		return nil, fmt.Errorf("user and role must be provided to authorize jira role")
+	}
+
+	user := req.GetUser()
+	role := req.GetRole()
+
+	issueKey := p.getLinkedIssue(req.GetAudit())
+
+	if len(issueKey) == 0 {
+		project := p.project
+		if len(project) == 0 {
+			project = "Jira"
+		}
+		return nil, fmt.Errorf("a %s issue must be referenced in the reason to request %s", project, role.Name)
+	}
+
+	status, err := p.getIssueStatus(ctx, issueKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(status, p.approvedStatus) {
+		return nil, fmt.Errorf("jira issue %s is %s, it must be %s before %s can be granted",
+			issueKey, status, p.approvedStatus, role.Name)
+	}
+
+	p.GetLogger(ctx).WithFields(logrus.Fields{
+		"issue": issueKey,
+		"role":  role.Name,
+	}).Info("Jira issue is approved")
+
+	return &models.AuthorizeRoleResponse{
+		UserId: user.Email,
+		Roles:  []string{role.Name},
+		Metadata: map[string]any{
+			MetadataIssueKey:    issueKey,
+			MetadataIssueStatus: status,
+		},
+	}, nil
+}
+
+// RevokeRole has nothing to remove as authorizing only checked the issue
+func (p *jiraProvider) RevokeRole(
+	ctx context.Context,
+	req *models.RevokeRoleRequest,
+) (*models.RevokeRoleResponse, error) {
+	return &models.RevokeRoleResponse{}, nil
+}
+
+// GetAuthorizedAccessUrl links to the approved issue
+func (p *jiraProvider) GetAuthorizedAccessUrl(
+	ctx context.Context,
+	req *models.AuthorizeRoleRequest,
+	resp *models.AuthorizeRoleResponse,
+) string {
+
+	if resp == nil {
+		return ""
+	}
+
+	issueKey, ok := resp.Metadata[MetadataIssueKey].(string)
+
+	if !ok || len(issueKey) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/browse/%s", p.endpoint, issueKey)
+}
+
+// getLinkedIssue returns the first issue key in the reason from the
+// configured project e.g. SEC-123
+func (p *jiraProvider) getLinkedIssue(audit *models.AuditContext) string {
+
+	if audit == nil {
+		return ""
+	}
+
+	for _, issueKey := range issueKeyPattern.FindAllString(audit.Reason, -1) {
+		if len(p.project) == 0 || strings.HasPrefix(issueKey, p.project+"-") {
+			return issueKey
+		}
+	}
+
+	return ""
+}
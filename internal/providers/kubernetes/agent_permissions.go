@@ -0,0 +1,67 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thand-io/agent/internal/models"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// agentAccess is the RBAC access used to create roles and bind users to
+// them. Bind and escalate let the agent grant permissions it doesn't hold
+// itself.
+var agentAccess = map[string][]string{
+	"roles":               {"get", "create", "update", "delete", "bind", "escalate"},
+	"rolebindings":        {"get", "create", "update", "delete"},
+	"clusterroles":        {"get", "create", "update", "delete", "bind", "escalate"},
+	"clusterrolebindings": {"get", "create", "update", "delete"},
+}
+
+// agentResources orders the resources checked
+var agentResources = []string{"roles", "rolebindings", "clusterroles", "clusterrolebindings"}
+
+// ValidateAgentPermissions asks the API server whether the agent can
+// manage roles and bindings with self subject access reviews
+func (p *kubernetesProvider) ValidateAgentPermissions(ctx context.Context) ([]models.AgentPermission, error) {
+
+	permissions := []models.AgentPermission{}
+
+	for _, resource := range agentResources {
+		for _, verb := range agentAccess[resource] {
+
+			review, err := p.client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx,
+				&authorizationv1.SelfSubjectAccessReview{
+					Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+						ResourceAttributes: &authorizationv1.ResourceAttributes{
+							Group:    "rbac.authorization.k8s.io",
+							Resource: resource,
+							Verb:     verb,
+						},
+					},
+				}, metav1.CreateOptions{})
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to review access to %s %s: %w", verb, resource, err)
+			}
+
+			permission := models.AgentPermission{
+				Permission: verb,
+				Resource:   resource,
+				Allowed:    review.Status.Allowed,
+			}
+
+			if !permission.Allowed {
+				permission.Reason = review.Status.Reason
+				if len(permission.Reason) == 0 {
+					permission.Reason = "denied"
+				}
+			}
+
+			permissions = append(permissions, permission)
+		}
+	}
+
+	return permissions, nil
+}
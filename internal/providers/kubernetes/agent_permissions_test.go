@@ -0,0 +1,42 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestValidateAgentPermissions(t *testing.T) {
+
+	provider := newFakeKubernetesProvider()
+	client := provider.client.(*fake.Clientset)
+
+	// Allow everything but escalating cluster roles
+	client.PrependReactor("create", "selfsubjectaccessreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			attributes := review.Spec.ResourceAttributes
+			review.Status.Allowed = attributes.Resource != "clusterroles" || attributes.Verb != "escalate"
+			return true, review, nil
+		})
+
+	permissions, err := provider.ValidateAgentPermissions(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, permissions, 20)
+
+	denied := []string{}
+	for _, permission := range permissions {
+		if !permission.Allowed {
+			denied = append(denied, permission.String())
+			assert.Equal(t, "denied", permission.Reason)
+		}
+	}
+
+	assert.Equal(t, []string{"escalate on clusterroles"}, denied)
+}
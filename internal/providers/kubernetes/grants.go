@@ -0,0 +1,79 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/thand-io/agent/internal/models"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListManagedGrants lists the role bindings and cluster role bindings
+// labelled with a grant reference. Grants from several elevations share a
+// binding, the label only holds the latest, so they're also matched by
+// binding name.
+func (p *kubernetesProvider) ListManagedGrants(ctx context.Context) ([]models.ManagedGrant, error) {
+
+	client := p.GetClient()
+	options := metav1.ListOptions{LabelSelector: GrantLabel}
+
+	grants := []models.ManagedGrant{}
+
+	roleBindings, err := client.RbacV1().RoleBindings(metav1.NamespaceAll).List(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role bindings: %w", err)
+	}
+
+	for _, binding := range roleBindings.Items {
+		managed := p.newManagedGrant(GrantKindRoleBinding, binding.ObjectMeta, binding.Subjects, binding.RoleRef)
+		managed.Grant.SetAttribute("namespace", binding.Namespace)
+		grants = append(grants, managed)
+	}
+
+	clusterRoleBindings, err := client.RbacV1().ClusterRoleBindings().List(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster role bindings: %w", err)
+	}
+
+	for _, binding := range clusterRoleBindings.Items {
+		grants = append(grants, p.newManagedGrant(GrantKindClusterRoleBinding, binding.ObjectMeta, binding.Subjects, binding.RoleRef))
+	}
+
+	return grants, nil
+}
+
+func (p *kubernetesProvider) newManagedGrant(
+	kind string,
+	meta metav1.ObjectMeta,
+	subjects []rbacv1.Subject,
+	roleRef rbacv1.RoleRef,
+) models.ManagedGrant {
+
+	managed := models.ManagedGrant{
+		Grant: &models.GrantRef{
+			Provider:  p.GetIdentifier(),
+			Reference: meta.Labels[GrantLabel],
+			Kind:      kind,
+			IDs:       []string{meta.Name},
+		},
+		Role:   roleRef.Name,
+		Shared: true,
+	}
+
+	// Bindings are made to a single user, see getUserIdentifier
+	for _, subject := range subjects {
+		if subject.Kind != rbacv1.UserKind {
+			continue
+		}
+		if strings.Contains(subject.Name, "@") {
+			managed.User = &models.User{Email: subject.Name}
+		} else {
+			managed.User = &models.User{Username: subject.Name}
+		}
+		break
+	}
+
+	return managed
+}
@@ -0,0 +1,78 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/testing/contract"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newFakeKubernetesProvider() *kubernetesProvider {
+	return &kubernetesProvider{
+		BaseProvider: models.NewBaseProvider("kubernetes", models.Provider{
+			Name:     "kubernetes",
+			Provider: KubernetesProviderName,
+		}, models.ProviderCapabilityRBAC),
+		client: fake.NewSimpleClientset(),
+	}
+}
+
+func newGrantRequest(namespace string) func(workflowID string) *models.RoleRequest {
+	return func(workflowID string) *models.RoleRequest {
+		role := &models.Role{
+			Name: "Pod Reader",
+			Permissions: models.Permissions{
+				Allow: []string{"k8s:pods:get,list"},
+			},
+		}
+		if len(namespace) > 0 {
+			role.Resources.Allow = []string{"namespace:" + namespace}
+		}
+		return &models.RoleRequest{
+			User: &models.User{Email: "alice@example.com"},
+			Role: role,
+			Audit: &models.AuditContext{
+				WorkflowID: workflowID,
+			},
+		}
+	}
+}
+
+func TestKubernetesGrantContract(t *testing.T) {
+
+	t.Run("namespaced", func(t *testing.T) {
+		provider := newFakeKubernetesProvider()
+
+		contract.RunGrantContract(t, contract.GrantContract{
+			Provider:   provider,
+			NewRequest: newGrantRequest("team-a"),
+			CountGrants: func() int {
+				bindings, err := provider.client.RbacV1().RoleBindings("team-a").
+					List(context.Background(), metav1.ListOptions{})
+				require.NoError(t, err)
+				return len(bindings.Items)
+			},
+			SharedGrants: true,
+		})
+	})
+
+	t.Run("cluster", func(t *testing.T) {
+		provider := newFakeKubernetesProvider()
+
+		contract.RunGrantContract(t, contract.GrantContract{
+			Provider:   provider,
+			NewRequest: newGrantRequest(""),
+			CountGrants: func() int {
+				bindings, err := provider.client.RbacV1().ClusterRoleBindings().
+					List(context.Background(), metav1.ListOptions{})
+				require.NoError(t, err)
+				return len(bindings.Items)
+			},
+			SharedGrants: true,
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"github.com/thand-io/agent/internal/models"
 	"github.com/thand-io/agent/internal/providers"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -23,7 +25,7 @@ type kubernetesProvider struct {
 	client kubernetes.Interface
 }
 
-func (p *kubernetesProvider) Initialize(identifier string, provider models.Provider) error {
+func (p *kubernetesProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
 		provider,
@@ -46,6 +48,20 @@ func (p *kubernetesProvider) Initialize(identifier string, provider models.Provi
 	return nil
 }
 
+// TestConnection lists a single cluster role to verify access
+func (p *kubernetesProvider) TestConnection(ctx context.Context, capability models.ProviderCapability) error {
+	if capability != models.ProviderCapabilityRBAC {
+		return models.ErrNotImplemented
+	}
+
+	_, err := p.client.RbacV1().ClusterRoles().List(ctx, metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return fmt.Errorf("failed to list cluster roles: %w", err)
+	}
+
+	return nil
+}
+
 func (p *kubernetesProvider) GetClient() kubernetes.Interface {
 	return p.client
 }
@@ -1,6 +1,8 @@
 package kubernetes
 
 import (
+	"context"
+
 	"github.com/thand-io/agent/internal/models"
 )
 
@@ -17,7 +19,7 @@ func NewMockKubernetesProvider() models.ProviderImpl {
 }
 
 // Initialize loads permissions and roles without connecting to Kubernetes
-func (p *kubernetesProviderMock) Initialize(identifier string, provider models.Provider) error {
+func (p *kubernetesProviderMock) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	// Initialize the embedded kubernetesProvider struct
 	p.kubernetesProvider = &kubernetesProvider{}
 
@@ -33,3 +35,20 @@ func (p *kubernetesProviderMock) Initialize(identifier string, provider models.P
 
 	return nil
 }
+
+// ValidateConfig accepts any config as the mock doesn't connect to
+// Kubernetes. Providers are created with reflection so the embedded
+// provider is nil until Initialize.
+func (p *kubernetesProviderMock) ValidateConfig(config *models.BasicConfig) error {
+	return nil
+}
+
+// TestConnection skips the smoke check as the mock has no Kubernetes client
+func (p *kubernetesProviderMock) TestConnection(ctx context.Context, capability models.ProviderCapability) error {
+	return models.ErrNotImplemented
+}
+
+// ValidateAgentPermissions skips the check as the mock has no Kubernetes client
+func (p *kubernetesProviderMock) ValidateAgentPermissions(ctx context.Context) ([]models.AgentPermission, error) {
+	return nil, models.ErrNotImplemented
+}
@@ -12,6 +12,15 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	GrantKindRoleBinding        = "role_binding"
+	GrantKindClusterRoleBinding = "cluster_role_binding"
+
+	// GrantLabel holds the grant reference of the workflow that last
+	// created or updated the binding
+	GrantLabel = "thand.io/grant"
+)
+
 // AuthorizeRole grants access for a user to a role
 func (p *kubernetesProvider) AuthorizeRole(
 	ctx context.Context,
@@ -30,10 +39,12 @@ func (p *kubernetesProvider) AuthorizeRole(
 
 	if len(namespace) > 0 {
 		// Create namespaced Role and RoleBinding
-		return p.authorizeNamespacedRole(ctx, user, role, namespace)
+		grant := models.NewGrantRef(p.GetIdentifier(), GrantKindRoleBinding, req.RoleRequest)
+		return p.authorizeNamespacedRole(ctx, user, role, namespace, grant)
 	} else {
 		// Create cluster-wide ClusterRole and ClusterRoleBinding
-		return p.authorizeClusterRole(ctx, user, role)
+		grant := models.NewGrantRef(p.GetIdentifier(), GrantKindClusterRoleBinding, req.RoleRequest)
+		return p.authorizeClusterRole(ctx, user, role, grant)
 	}
 }
 
@@ -50,6 +61,11 @@ func (p *kubernetesProvider) RevokeRole(
 	user := req.GetUser()
 	role := req.GetRole()
 
+	// Remove exactly the binding recorded when the role was authorized
+	if grant := req.GetGrantRef(); grant.HasIDs() {
+		return p.revokeGrant(ctx, grant)
+	}
+
 	namespace := p.getNamespaceFromRole(role)
 
 	if len(namespace) > 0 {
@@ -78,6 +94,7 @@ func (p *kubernetesProvider) authorizeNamespacedRole(
 	user *models.User,
 	role *models.Role,
 	namespace string,
+	grant *models.GrantRef,
 ) (*models.AuthorizeRoleResponse, error) {
 
 	client := p.GetClient()
@@ -119,6 +136,7 @@ func (p *kubernetesProvider) authorizeNamespacedRole(
 				"thand.io/managed": "true",
 				"thand.io/role":    roleName,
 				"thand.io/user":    p.sanitizeUserIdentifier(user),
+				GrantLabel:         grant.Reference,
 			},
 		},
 		Subjects: []rbacv1.Subject{
@@ -141,6 +159,29 @@ func (p *kubernetesProvider) authorizeNamespacedRole(
 		"binding":   bindingName,
 	}
 
+	grant.AddID(bindingName)
+	grant.SetAttribute("namespace", namespace)
+
+	response := &models.AuthorizeRoleResponse{
+		Metadata: map[string]any{
+			"roleName":    roleName,
+			"bindingName": bindingName,
+			"namespace":   namespace,
+			"scope":       "namespaced",
+		},
+		GrantRef: grant,
+	}
+
+	// A retry of the same grant finds the binding it already created
+	existing, err := client.RbacV1().
+		RoleBindings(namespace).
+		Get(ctx, bindingName, metav1.GetOptions{})
+	if err == nil && existing.Labels[GrantLabel] == grant.Reference {
+		logrus.WithFields(logFields).
+			Info("Role binding already exists for this grant")
+		return response, nil
+	}
+
 	_, err = client.RbacV1().
 		RoleBindings(namespace).
 		Create(ctx, roleBinding, metav1.CreateOptions{})
@@ -168,14 +209,7 @@ func (p *kubernetesProvider) authorizeNamespacedRole(
 	logrus.WithFields(logFields).
 		Info("Successfully authorized user to namespaced role")
 
-	return &models.AuthorizeRoleResponse{
-		Metadata: map[string]any{
-			"roleName":    roleName,
-			"bindingName": bindingName,
-			"namespace":   namespace,
-			"scope":       "namespaced",
-		},
-	}, nil
+	return response, nil
 }
 
 // authorizeClusterRole creates ClusterRole and ClusterRoleBinding for cluster-wide access
@@ -183,6 +217,7 @@ func (p *kubernetesProvider) authorizeClusterRole(
 	ctx context.Context,
 	user *models.User,
 	role *models.Role,
+	grant *models.GrantRef,
 ) (*models.AuthorizeRoleResponse, error) {
 
 	client := p.GetClient()
@@ -225,6 +260,7 @@ func (p *kubernetesProvider) authorizeClusterRole(
 				"thand.io/managed": "true",
 				"thand.io/role":    roleName,
 				"thand.io/user":    p.sanitizeUserIdentifier(user),
+				GrantLabel:         grant.Reference,
 			},
 		},
 		Subjects: []rbacv1.Subject{
@@ -246,6 +282,27 @@ func (p *kubernetesProvider) authorizeClusterRole(
 		"binding": bindingName,
 	}
 
+	grant.AddID(bindingName)
+
+	response := &models.AuthorizeRoleResponse{
+		Metadata: map[string]any{
+			"roleName":    roleName,
+			"bindingName": bindingName,
+			"scope":       "cluster",
+		},
+		GrantRef: grant,
+	}
+
+	// A retry of the same grant finds the binding it already created
+	existing, err := client.RbacV1().
+		ClusterRoleBindings().
+		Get(ctx, bindingName, metav1.GetOptions{})
+	if err == nil && existing.Labels[GrantLabel] == grant.Reference {
+		logrus.WithFields(logFields).
+			Info("Cluster role binding already exists for this grant")
+		return response, nil
+	}
+
 	_, err = client.RbacV1().
 		ClusterRoleBindings().
 		Create(ctx, clusterRoleBinding, metav1.CreateOptions{})
@@ -273,13 +330,7 @@ func (p *kubernetesProvider) authorizeClusterRole(
 	logrus.WithFields(logFields).
 		Info("Successfully authorized user to cluster role")
 
-	return &models.AuthorizeRoleResponse{
-		Metadata: map[string]any{
-			"roleName":    roleName,
-			"bindingName": bindingName,
-			"scope":       "cluster",
-		},
-	}, nil
+	return response, nil
 }
 
 // convertPermissionsToRules converts thand permissions to Kubernetes RBAC rules
@@ -532,3 +583,42 @@ func (p *kubernetesProvider) revokeClusterRole(
 
 	return &models.RevokeRoleResponse{}, nil
 }
+
+// revokeGrant deletes the bindings recorded in the grant. Bindings that no
+// longer exist are considered already revoked.
+func (p *kubernetesProvider) revokeGrant(
+	ctx context.Context,
+	grant *models.GrantRef,
+) (*models.RevokeRoleResponse, error) {
+
+	client := p.GetClient()
+	namespace := grant.GetAttribute("namespace")
+
+	for _, bindingName := range grant.IDs {
+
+		var err error
+
+		if grant.Kind == GrantKindClusterRoleBinding {
+			err = client.RbacV1().
+				ClusterRoleBindings().
+				Delete(ctx, bindingName, metav1.DeleteOptions{})
+		} else {
+			err = client.RbacV1().
+				RoleBindings(namespace).
+				Delete(ctx, bindingName, metav1.DeleteOptions{})
+		}
+
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to delete %s %s: %w", grant.Kind, bindingName, err)
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"binding":   bindingName,
+			"namespace": namespace,
+			"kind":      grant.Kind,
+			"grant":     grant.Reference,
+		}).Info("Successfully revoked kubernetes grant")
+	}
+
+	return &models.RevokeRoleResponse{}, nil
+}
@@ -26,7 +26,16 @@ type oauth2Provider struct {
 	OauthConfig *oauth2.Config
 }
 
-func (p *oauth2Provider) Initialize(identifier string, provider models.Provider) error {
+// ValidateConfig checks the OAuth client credentials are set
+func (p *oauth2Provider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(Oauth2GoogleProviderName)
+	validation.Require(config, "client_id", "client_secret")
+
+	return validation.ErrorOrNil()
+}
+
+func (p *oauth2Provider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
 		provider,
@@ -65,6 +74,33 @@ func (p *oauth2Provider) Initialize(identifier string, provider models.Provider)
 	return nil
 }
 
+// googleDiscoveryUrl is the OpenID Connect discovery document for Google
+const googleDiscoveryUrl = "https://accounts.google.com/.well-known/openid-configuration"
+
+// TestConnection checks the Google issuer metadata is reachable
+func (p *oauth2Provider) TestConnection(ctx context.Context, capability models.ProviderCapability) error {
+	if capability != models.ProviderCapabilityAuthorizer {
+		return models.ErrNotImplemented
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, googleDiscoveryUrl, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create discovery request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch Google discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("google discovery document returned status: %s", resp.Status)
+	}
+
+	return nil
+}
+
 func (p *oauth2Provider) AuthorizeSession(ctx context.Context, authRequest *models.AuthorizeUser) (*models.AuthorizeSessionResponse, error) {
 
 	googleConfig := p.OauthConfig
@@ -173,8 +209,33 @@ func (p *oauth2Provider) ValidateSession(ctx context.Context, session *models.Se
 }
 
 func (p *oauth2Provider) RenewSession(ctx context.Context, session *models.Session) (*models.Session, error) {
-	// TODO: Implement OAuth2 session renewal logic
-	return session, nil
+
+	if len(session.RefreshToken) == 0 {
+		return nil, fmt.Errorf("session has no refresh token")
+	}
+
+	// Exchange the refresh token for a new access token
+	token, err := p.OauthConfig.TokenSource(ctx, &oauth2.Token{
+		RefreshToken: session.RefreshToken,
+	}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	refreshToken := token.RefreshToken
+
+	// Google doesn't always rotate the refresh token
+	if len(refreshToken) == 0 {
+		refreshToken = session.RefreshToken
+	}
+
+	return &models.Session{
+		UUID:         session.UUID,
+		User:         session.User,
+		AccessToken:  token.AccessToken,
+		RefreshToken: refreshToken,
+		Expiry:       token.Expiry,
+	}, nil
 }
 
 func init() {
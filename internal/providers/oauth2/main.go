@@ -15,7 +15,7 @@ type oauth2Provider struct {
 	*models.BaseProvider
 }
 
-func (p *oauth2Provider) Initialize(identifier string, provider models.Provider) error {
+func (p *oauth2Provider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
 		provider,
@@ -42,29 +42,7 @@ func (p *oauth2Provider) ValidateSession(ctx context.Context, session *models.Se
 
 func (p *oauth2Provider) RenewSession(ctx context.Context, session *models.Session) (*models.Session, error) {
 	// TODO: Implement OAuth2 session renewal logic
-	return nil, fmt.Errorf("RenewSession not implemented for OAuth2 provider")
-}
-
-// Authorize grants access for a user to a role
-func (p *oauth2Provider) AuthorizeRole(
-	ctx context.Context,
-	req *models.AuthorizeRoleRequest,
-) (*models.AuthorizeRoleResponse, error) {
-	if !req.IsValid() {
-		return nil, fmt.Errorf("user and role must be provided to authorize oauth2 role")
-	}
-
-	// TODO: Implement OAuth2 authorization logic
-	return nil, nil
-}
-
-// Revoke removes access for a user from a role
-func (p *oauth2Provider) RevokeRole(
-	ctx context.Context,
-	req *models.RevokeRoleRequest,
-) (*models.RevokeRoleResponse, error) {
-	// TODO: Implement OAuth2 revocation logic
-	return nil, nil
+	return nil, fmt.Errorf("RenewSession not implemented for OAuth2 provider: %w", models.ErrNotImplemented)
 }
 
 func (p *oauth2Provider) GetPermission(ctx context.Context, permission string) (*models.ProviderPermission, error) {
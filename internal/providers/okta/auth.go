@@ -0,0 +1,273 @@
+package okta
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/okta/okta-sdk-golang/v2/okta"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// oktaServiceApp holds the credentials of an OAuth 2.0 service app
+// that authenticates with a private key JWT
+type oktaServiceApp struct {
+	clientId string
+	scopes   []string
+	signer   jose.Signer
+}
+
+// getOktaServiceApp reads the service app credentials from the config. The
+// private key can be given inline with private_key or as a file with
+// private_key_path, either as a PEM or a JWKS.
+func getOktaServiceApp(oktaConfig *models.BasicConfig) (*oktaServiceApp, error) {
+
+	clientId, foundClientId := oktaConfig.GetString("client_id")
+
+	if !foundClientId {
+		return nil, fmt.Errorf("client_id is required for Okta provider when using oauth2")
+	}
+
+	var privateKey []byte
+
+	if inline, found := oktaConfig.GetString("private_key"); found && len(inline) > 0 {
+		privateKey = []byte(inline)
+	} else if privateKeyPath, found := oktaConfig.GetString("private_key_path"); found {
+		// Read the key here as the SDK exits the process if it can't
+		content, err := os.ReadFile(privateKeyPath)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Okta private key: %w", err)
+		}
+
+		privateKey = content
+	} else {
+		return nil, fmt.Errorf("private_key or private_key_path is required for Okta provider when using oauth2")
+	}
+
+	signer, err := parseOktaPrivateKey(privateKey, oktaConfig.GetStringWithDefault("private_key_id", ""))
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid Okta private key: %w", err)
+	}
+
+	scopes, foundScopes := oktaConfig.GetStringSlice("scopes")
+
+	if !foundScopes || len(scopes) == 0 {
+		scopes = OktaDefaultScopes
+	}
+
+	return &oktaServiceApp{
+		clientId: clientId,
+		scopes:   scopes,
+		signer:   signer,
+	}, nil
+}
+
+// parseOktaPrivateKey creates the signer for client assertions from a PEM
+// or JWKS private key. Okta accepts RSA and EC keys. The key ID of a JWK is
+// used if no key ID is configured.
+func parseOktaPrivateKey(privateKey []byte, keyId string) (jose.Signer, error) {
+
+	privateKey = bytes.TrimSpace(privateKey)
+
+	var key any
+
+	if bytes.HasPrefix(privateKey, []byte("{")) {
+
+		jwk, err := parseOktaJWK(privateKey, keyId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		key = jwk.Key
+
+		if len(keyId) == 0 {
+			keyId = jwk.KeyID
+		}
+
+	} else {
+
+		// Keys set in environment variables often have escaped new lines
+		block, _ := pem.Decode(bytes.ReplaceAll(privateKey, []byte(`\n`), []byte("\n")))
+
+		if block == nil {
+			return nil, fmt.Errorf("private key must be a PEM or a JWKS")
+		}
+
+		var err error
+
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		default:
+			return nil, fmt.Errorf("unsupported PEM block %q, must be a RSA or EC private key", block.Type)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", strings.ToLower(block.Type), err)
+		}
+	}
+
+	algorithm, err := getOktaSigningAlgorithm(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	options := &jose.SignerOptions{}
+
+	if len(keyId) > 0 {
+		options = options.WithHeader("kid", keyId)
+	}
+
+	return jose.NewSigner(jose.SigningKey{Algorithm: algorithm, Key: key}, options)
+}
+
+// parseOktaJWK returns the private key from a JWKS or a single JWK. If a key
+// ID is given the JWKS key with that ID is used, otherwise the first key.
+func parseOktaJWK(privateKey []byte, keyId string) (*jose.JSONWebKey, error) {
+
+	var keySet jose.JSONWebKeySet
+
+	if err := json.Unmarshal(privateKey, &keySet); err == nil && len(keySet.Keys) > 0 {
+
+		for _, jwk := range keySet.Keys {
+			if len(keyId) > 0 && jwk.KeyID != keyId {
+				continue
+			}
+			if !jwk.IsPublic() {
+				return &jwk, nil
+			}
+		}
+
+		if len(keyId) > 0 {
+			return nil, fmt.Errorf("JWKS has no private key with the key ID %s", keyId)
+		}
+
+		return nil, fmt.Errorf("JWKS has no private keys, only public keys")
+	}
+
+	var jwk jose.JSONWebKey
+
+	if err := json.Unmarshal(privateKey, &jwk); err != nil {
+		return nil, fmt.Errorf("failed to parse JWK: %w", err)
+	}
+
+	if jwk.IsPublic() {
+		return nil, fmt.Errorf("JWK is a public key, the private key is required")
+	}
+
+	return &jwk, nil
+}
+
+// getOktaSigningAlgorithm returns the client assertion algorithm for the key
+func getOktaSigningAlgorithm(key any) (jose.SignatureAlgorithm, error) {
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return jose.RS256, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jose.ES256, nil
+		case elliptic.P384():
+			return jose.ES384, nil
+		case elliptic.P521():
+			return jose.ES512, nil
+		}
+		return "", fmt.Errorf("unsupported EC curve %s", k.Curve.Params().Name)
+	}
+
+	return "", fmt.Errorf("unsupported private key type %T, must be a RSA or EC key", key)
+}
+
+// oktaTokenError is the error returned by the token endpoint. OAuth errors
+// use error and error_description, other Okta API errors use errorCode.
+type oktaTokenError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	ErrorCode        string `json:"errorCode"`
+	ErrorSummary     string `json:"errorSummary"`
+}
+
+func (e *oktaTokenError) String() string {
+	if len(e.ErrorCode) > 0 {
+		return fmt.Sprintf("%s: %s", e.ErrorCode, e.ErrorSummary)
+	}
+	return fmt.Sprintf("%s: %s", e.Error, e.ErrorDescription)
+}
+
+// requestOktaAccessToken requests an access token for the service app so
+// bad credentials or missing scope grants fail at startup, rather than on
+// the first request made by the SDK
+func requestOktaAccessToken(ctx context.Context, httpClient *http.Client, orgUrl string, app *oktaServiceApp) error {
+
+	orgUrl = strings.TrimSuffix(orgUrl, "/")
+
+	clientAssertion, err := okta.CreateClientAssertion(orgUrl, app.clientId, app.signer)
+
+	if err != nil {
+		return fmt.Errorf("failed to sign client assertion: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("scope", strings.Join(app.scopes, " "))
+	form.Set("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+	form.Set("client_assertion", clientAssertion)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		orgUrl+"/oauth2/v1/token", strings.NewReader(form.Encode()))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+
+	if err != nil {
+		return fmt.Errorf("failed to request access token: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var tokenError oktaTokenError
+
+	if err := json.Unmarshal(body, &tokenError); err != nil ||
+		(len(tokenError.Error) == 0 && len(tokenError.ErrorCode) == 0) {
+		return fmt.Errorf("token request failed with status %d", resp.StatusCode)
+	}
+
+	return fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, tokenError.String())
+}
@@ -0,0 +1,161 @@
+package okta
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestParseOktaPrivateKey(t *testing.T) {
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: &rsaKey.PublicKey, KeyID: "public", Algorithm: "RS256"},
+		{Key: rsaKey, KeyID: "private", Algorithm: "RS256"},
+	}})
+	require.NoError(t, err)
+
+	signedKeyId := func(t *testing.T, signer jose.Signer) string {
+		t.Helper()
+		signature, err := signer.Sign([]byte("payload"))
+		require.NoError(t, err)
+		// The protected header is only populated once parsed
+		compact, err := signature.CompactSerialize()
+		require.NoError(t, err)
+		parsed, err := jose.ParseSigned(compact)
+		require.NoError(t, err)
+		return parsed.Signatures[0].Protected.KeyID
+	}
+
+	t.Run("pkcs1 rsa pem", func(t *testing.T) {
+		signer, err := parseOktaPrivateKey(pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(rsaKey),
+		}), "kid-1")
+		require.NoError(t, err)
+		assert.Equal(t, "kid-1", signedKeyId(t, signer))
+	})
+
+	t.Run("pkcs8 ec pem with escaped new lines", func(t *testing.T) {
+		key := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+		signer, err := parseOktaPrivateKey([]byte(strings.ReplaceAll(key, "\n", `\n`)), "")
+		require.NoError(t, err)
+		assert.Empty(t, signedKeyId(t, signer))
+	})
+
+	t.Run("jwks uses the private key id", func(t *testing.T) {
+		signer, err := parseOktaPrivateKey(jwks, "")
+		require.NoError(t, err)
+		assert.Equal(t, "private", signedKeyId(t, signer))
+	})
+
+	t.Run("jwks without a matching private key", func(t *testing.T) {
+		_, err := parseOktaPrivateKey(jwks, "public")
+		assert.ErrorContains(t, err, "no private key with the key ID public")
+	})
+
+	t.Run("public jwk", func(t *testing.T) {
+		jwk, err := json.Marshal(jose.JSONWebKey{Key: &rsaKey.PublicKey, Algorithm: "RS256"})
+		require.NoError(t, err)
+
+		_, err = parseOktaPrivateKey(jwk, "")
+		assert.ErrorContains(t, err, "JWK is a public key")
+	})
+
+	t.Run("not a key", func(t *testing.T) {
+		_, err := parseOktaPrivateKey([]byte("not a key"), "")
+		assert.ErrorContains(t, err, "must be a PEM or a JWKS")
+	})
+
+	t.Run("unsupported pem", func(t *testing.T) {
+		_, err := parseOktaPrivateKey(pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: []byte("certificate"),
+		}), "")
+		assert.ErrorContains(t, err, `unsupported PEM block "CERTIFICATE"`)
+	})
+}
+
+func TestGetOktaAuthMethod(t *testing.T) {
+	assert.Equal(t, OktaAuthMethodToken, getOktaAuthMethod(&models.BasicConfig{"token": "token"}))
+	assert.Equal(t, OktaAuthMethodOAuth2, getOktaAuthMethod(&models.BasicConfig{"client_id": "0oa123"}))
+	assert.Equal(t, OktaAuthMethodToken, getOktaAuthMethod(&models.BasicConfig{"client_id": "0oa123", "token": "token"}))
+	assert.Equal(t, OktaAuthMethodOAuth2, getOktaAuthMethod(&models.BasicConfig{"auth_method": "oauth2", "token": "token"}))
+}
+
+func TestRequestOktaAccessToken(t *testing.T) {
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, nil)
+	require.NoError(t, err)
+
+	app := &oktaServiceApp{
+		clientId: "0oa123",
+		scopes:   []string{"okta.users.read", "okta.groups.read"},
+		signer:   signer,
+	}
+
+	newServer := func(status int, body string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/oauth2/v1/token", r.URL.Path)
+			assert.NoError(t, r.ParseForm())
+			assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "okta.users.read okta.groups.read", r.PostForm.Get("scope"))
+			assert.NotEmpty(t, r.PostForm.Get("client_assertion"))
+
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	t.Run("success", func(t *testing.T) {
+		server := newServer(http.StatusOK, `{"access_token":"token","expires_in":3600}`)
+		assert.NoError(t, requestOktaAccessToken(context.Background(), server.Client(), server.URL+"/", app))
+	})
+
+	t.Run("oauth error", func(t *testing.T) {
+		server := newServer(http.StatusBadRequest,
+			`{"error":"invalid_scope","error_description":"The requested scope is invalid."}`)
+		err := requestOktaAccessToken(context.Background(), server.Client(), server.URL, app)
+		assert.ErrorContains(t, err, "status 400: invalid_scope: The requested scope is invalid.")
+	})
+
+	t.Run("api error code", func(t *testing.T) {
+		server := newServer(http.StatusUnauthorized,
+			`{"errorCode":"E0000011","errorSummary":"Invalid token provided"}`)
+		err := requestOktaAccessToken(context.Background(), server.Client(), server.URL, app)
+		assert.ErrorContains(t, err, "E0000011: Invalid token provided")
+	})
+
+	t.Run("unexpected body", func(t *testing.T) {
+		server := newServer(http.StatusBadGateway, "bad gateway")
+		err := requestOktaAccessToken(context.Background(), server.Client(), server.URL, app)
+		assert.EqualError(t, err, "token request failed with status 502")
+	})
+}
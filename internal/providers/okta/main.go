@@ -3,6 +3,7 @@ package okta
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/okta/okta-sdk-golang/v2/okta"
 	"github.com/sirupsen/logrus"
@@ -13,21 +14,60 @@ import (
 
 const OktaProviderName = "okta"
 
+const (
+	OktaAuthMethodToken  = "token"
+	OktaAuthMethodOAuth2 = "oauth2"
+)
+
+// OktaDefaultScopes are requested by OAuth 2.0 clients when no scopes are
+// configured. The service app must be granted these scopes in Okta.
+var OktaDefaultScopes = []string{
+	"okta.users.read",
+	"okta.users.manage",
+	"okta.groups.read",
+	"okta.groups.manage",
+	"okta.apps.read",
+	"okta.apps.manage",
+	"okta.roles.read",
+	"okta.roles.manage",
+}
+
 // oktaProvider implements the ProviderImpl interface for Okta
 type oktaProvider struct {
 	*models.BaseProvider
 
-	client   *okta.Client
-	orgUrl   string
-	apiToken string
+	client *okta.Client
+	orgUrl string
+}
+
+// ValidateConfig checks the org URL is set along with the credentials the
+// auth method needs
+func (p *oktaProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(OktaProviderName)
+	validation.Require(config, "endpoint")
+	validation.OneOf(config, "auth_method", OktaAuthMethodToken, OktaAuthMethodOAuth2)
+
+	switch getOktaAuthMethod(config) {
+	case OktaAuthMethodToken:
+		validation.Require(config, "token")
+	case OktaAuthMethodOAuth2:
+		validation.Require(config, "client_id")
+		if !config.HasString("private_key") && !config.HasString("private_key_path") {
+			validation.Add("private_key", "or private_key_path is required when using oauth2")
+		}
+	}
+
+	return validation.ErrorOrNil()
 }
 
-func (p *oktaProvider) Initialize(identifier string, provider models.Provider) error {
+func (p *oktaProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
 		provider,
 		models.ProviderCapabilityRBAC,
 		models.ProviderCapabilityIdentities,
+		models.ProviderCapabilityResourceDiscovery,
 	)
 
 	// Get Okta configuration
@@ -48,13 +88,22 @@ func (p *oktaProvider) Initialize(identifier string, provider models.Provider) e
 	}
 	p.orgUrl = orgUrl
 
-	apiToken, found := oktaConfig.GetString("token")
-	if !found {
-		return fmt.Errorf("token is required for Okta provider")
+	if getOktaAuthMethod(oktaConfig) == OktaAuthMethodOAuth2 {
+
+		serviceApp, err := getOktaServiceApp(oktaConfig)
+		if err != nil {
+			return err
+		}
+
+		// The SDK only gets a token on the first request, check the
+		// service app can get one now
+		err = requestOktaAccessToken(ctx, http.DefaultClient, p.orgUrl, serviceApp)
+		if err != nil {
+			return fmt.Errorf("failed to get Okta access token: %w", err)
+		}
 	}
-	p.apiToken = apiToken
 
-	logrus.WithField("org_url", p.orgUrl).Info("Initialized Okta provider")
+	p.GetLogger(ctx).Debug("Initialized Okta provider")
 	return nil
 }
 
@@ -62,32 +111,97 @@ func (p *oktaProvider) Initialize(identifier string, provider models.Provider) e
 func CreateOktaClient(oktaConfig *models.BasicConfig) (*okta.Client, error) {
 	ctx := context.Background()
 
+	options, err := getOktaClientOptions(oktaConfig)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Configure Okta client
+	_, client, err := okta.NewClient(ctx, options...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Okta client: %w", err)
+	}
+
+	logrus.WithField("auth_method", getOktaAuthMethod(oktaConfig)).
+		Debug("Created Okta client")
+
+	return client, nil
+}
+
+// getOktaClientOptions returns the client options for the configured
+// authentication method
+func getOktaClientOptions(oktaConfig *models.BasicConfig) ([]okta.ConfigSetter, error) {
+
 	// Get required configuration
 	orgUrl, foundOrgUrl := oktaConfig.GetString("endpoint")
-	apiToken, foundApiToken := oktaConfig.GetString("token")
 
 	if !foundOrgUrl {
 		return nil, fmt.Errorf("endpoint is required for Okta provider")
 	}
 
-	if !foundApiToken {
-		return nil, fmt.Errorf("token is required for Okta provider")
-	}
-
-	// Configure Okta client
-	_, client, err := okta.NewClient(
-		ctx,
+	options := []okta.ConfigSetter{
 		okta.WithOrgUrl(orgUrl),
-		okta.WithToken(apiToken),
 		okta.WithCache(true),
-	)
+	}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize Okta client: %w", err)
+	authMethod := getOktaAuthMethod(oktaConfig)
+
+	switch authMethod {
+	case OktaAuthMethodToken:
+
+		apiToken, foundApiToken := oktaConfig.GetString("token")
+
+		if !foundApiToken {
+			return nil, fmt.Errorf("token is required for Okta provider")
+		}
+
+		logrus.Warn("Okta API tokens are deprecated, use auth_method oauth2 with an API service app instead")
+
+		options = append(options, okta.WithToken(apiToken))
+
+	case OktaAuthMethodOAuth2:
+
+		serviceApp, err := getOktaServiceApp(oktaConfig)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Client credentials with a private key JWT. The signer is passed
+		// rather than the key as the SDK only parses PKCS1 RSA keys.
+		options = append(options,
+			okta.WithAuthorizationMode("PrivateKey"),
+			okta.WithClientId(serviceApp.clientId),
+			okta.WithScopes(serviceApp.scopes),
+			okta.WithPrivateKeySigner(serviceApp.signer),
+		)
+
+	default:
+		return nil, fmt.Errorf("unsupported auth_method for Okta provider: %s, must be %s or %s",
+			authMethod, OktaAuthMethodToken, OktaAuthMethodOAuth2)
 	}
 
-	logrus.WithField("org_url", orgUrl).Info("Created Okta client")
-	return client, nil
+	return options, nil
+}
+
+// getOktaAuthMethod returns the configured auth_method. Without one,
+// OAuth 2.0 is used if a client_id is configured and no API token is.
+func getOktaAuthMethod(oktaConfig *models.BasicConfig) string {
+
+	if authMethod, found := oktaConfig.GetString("auth_method"); found && len(authMethod) > 0 {
+		return authMethod
+	}
+
+	_, foundClientId := oktaConfig.GetString("client_id")
+	_, foundToken := oktaConfig.GetString("token")
+
+	if foundClientId && !foundToken {
+		return OktaAuthMethodOAuth2
+	}
+
+	return OktaAuthMethodToken
 }
 
 // GetClient returns the Okta API client
@@ -100,11 +214,6 @@ func (p *oktaProvider) GetOrgUrl() string {
 	return p.orgUrl
 }
 
-// GetApiToken returns the Okta API token (use with caution)
-func (p *oktaProvider) GetApiToken() string {
-	return p.apiToken
-}
-
 func init() {
 	providers.Register(OktaProviderName, &oktaProvider{})
 }
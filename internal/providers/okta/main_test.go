@@ -0,0 +1,127 @@
+package okta
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func writeTestPrivateKey(t *testing.T) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "okta.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}), 0600))
+
+	return path
+}
+
+func TestCreateOktaClient(t *testing.T) {
+	privateKeyPath := writeTestPrivateKey(t)
+
+	t.Run("api token", func(t *testing.T) {
+		client, err := CreateOktaClient(&models.BasicConfig{
+			"endpoint": "https://example.okta.com",
+			"token":    "token",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "SSWS", client.GetConfig().Okta.Client.AuthorizationMode)
+	})
+
+	t.Run("oauth2 private key", func(t *testing.T) {
+		client, err := CreateOktaClient(&models.BasicConfig{
+			"endpoint":         "https://example.okta.com",
+			"auth_method":      "oauth2",
+			"client_id":        "0oa123",
+			"private_key_path": privateKeyPath,
+		})
+		require.NoError(t, err)
+
+		clientConfig := client.GetConfig().Okta.Client
+		assert.Equal(t, "PrivateKey", clientConfig.AuthorizationMode)
+		assert.Equal(t, "0oa123", clientConfig.ClientId)
+		assert.Equal(t, OktaDefaultScopes, clientConfig.Scopes)
+		assert.Empty(t, clientConfig.Token)
+	})
+
+	t.Run("oauth2 inline private key", func(t *testing.T) {
+		privateKey, err := os.ReadFile(privateKeyPath)
+		require.NoError(t, err)
+
+		client, err := CreateOktaClient(&models.BasicConfig{
+			"endpoint":    "https://example.okta.com",
+			"client_id":   "0oa123",
+			"private_key": string(privateKey),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "PrivateKey", client.GetConfig().Okta.Client.AuthorizationMode)
+		assert.NotNil(t, client.GetConfig().PrivateKeySigner)
+	})
+
+	t.Run("oauth2 custom scopes", func(t *testing.T) {
+		client, err := CreateOktaClient(&models.BasicConfig{
+			"endpoint":         "https://example.okta.com",
+			"auth_method":      "oauth2",
+			"client_id":        "0oa123",
+			"private_key_path": privateKeyPath,
+			"scopes":           []any{"okta.users.read"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"okta.users.read"}, client.GetConfig().Okta.Client.Scopes)
+	})
+}
+
+func TestCreateOktaClientErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config models.BasicConfig
+		err    string
+	}{
+		{"missing endpoint", models.BasicConfig{"token": "token"}, "endpoint is required"},
+		{"missing token", models.BasicConfig{"endpoint": "https://example.okta.com"}, "token is required"},
+		{"missing client id", models.BasicConfig{
+			"endpoint":    "https://example.okta.com",
+			"auth_method": "oauth2",
+		}, "client_id is required"},
+		{"missing private key", models.BasicConfig{
+			"endpoint":    "https://example.okta.com",
+			"auth_method": "oauth2",
+			"client_id":   "0oa123",
+		}, "private_key or private_key_path is required"},
+		{"unreadable private key", models.BasicConfig{
+			"endpoint":         "https://example.okta.com",
+			"auth_method":      "oauth2",
+			"client_id":        "0oa123",
+			"private_key_path": "/does/not/exist.pem",
+		}, "failed to read Okta private key"},
+		{"invalid private key", models.BasicConfig{
+			"endpoint":    "https://example.okta.com",
+			"client_id":   "0oa123",
+			"private_key": "not a key",
+		}, "invalid Okta private key: private key must be a PEM or a JWKS"},
+		{"unknown auth method", models.BasicConfig{
+			"endpoint":    "https://example.okta.com",
+			"auth_method": "basic",
+		}, "unsupported auth_method"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateOktaClient(&tt.config)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
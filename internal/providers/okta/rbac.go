@@ -47,6 +47,18 @@ type ResourceSetAssignmentRequest struct {
 	Remove []ResourceSetAssignment `json:"remove,omitempty"`
 }
 
+// GrantKindAssignment is the grant kind for Okta, the grant IDs are the role
+// assignment IDs, groups and applications prefixed with their type e.g.
+// role:<id>, group:<id>, application:<id>
+const GrantKindAssignment = "assignment"
+
+// Grant ID prefixes for the artifacts recorded by an Okta grant
+const (
+	grantIDPrefixRole        = "role:"
+	grantIDPrefixGroup       = "group:"
+	grantIDPrefixApplication = "application:"
+)
+
 // AuthorizeRole assigns a role to a user in Okta
 func (p *oktaProvider) AuthorizeRole(
 	ctx context.Context,
@@ -253,11 +265,26 @@ func (p *oktaProvider) AuthorizeRole(
 		}
 	}
 
+	grant := models.NewGrantRef(p.GetIdentifier(), GrantKindAssignment, req.RoleRequest)
+	grant.SetAttribute("user_id", oktaUser.Id)
+
+	for _, roleId := range assignedRoles {
+		grant.AddID(grantIDPrefixRole + roleId)
+	}
+	for _, groupId := range assignedGroups {
+		grant.AddID(grantIDPrefixGroup + groupId)
+	}
+	// Resources are already prefixed with application:
+	for _, resourceId := range assignedResources {
+		grant.AddID(resourceId)
+	}
+
 	return &models.AuthorizeRoleResponse{
 		UserId:    oktaUser.Id,
 		Roles:     assignedRoles,
 		Groups:    assignedGroups,
 		Resources: assignedResources,
+		GrantRef:  grant,
 	}, nil
 }
 
@@ -286,6 +313,11 @@ func (p *oktaProvider) RevokeRole(
 	// Convert metadata to strongly typed structure
 	metadata := req.AuthorizeRoleResponse
 
+	// Revoke exactly what the grant recorded
+	if grant := req.GetGrantRef(); grant.HasIDs() {
+		metadata = getGrantAssignments(grant)
+	}
+
 	// Revoke roles
 	if len(metadata.Roles) > 0 {
 		if err := p.revokeRoles(ctx, metadata.Roles, oktaUser.Id, user.Email); err != nil {
@@ -338,6 +370,11 @@ func (p *oktaProvider) revokeRoles(ctx context.Context, roleIds []string, userId
 		// This is a standard role, remove via Assignments API
 		_, err := p.client.User.RemoveRoleFromUser(ctx, userId, roleId)
 
+		if isOktaNotFound(err) {
+			// The role was already removed
+			continue
+		}
+
 		if err != nil {
 			return fmt.Errorf("failed to revoke role %s from user: %w", roleId, err)
 		}
@@ -392,6 +429,11 @@ func (p *oktaProvider) revokeResources(ctx context.Context, resourceIds []string
 
 			_, err := p.client.Application.DeleteApplicationUser(ctx, after, userId, nil)
 
+			if isOktaNotFound(err) {
+				// The user was already removed from the application
+				continue
+			}
+
 			if err != nil {
 				return fmt.Errorf("failed to remove user from resource %s: %w", resourceId, err)
 			}
@@ -414,6 +456,33 @@ func (p *oktaProvider) revokeResources(ctx context.Context, resourceIds []string
 	return nil
 }
 
+// getGrantAssignments splits the grant IDs back into the roles, groups and
+// applications they were recorded from
+func getGrantAssignments(grant *models.GrantRef) *models.AuthorizeRoleResponse {
+
+	assignments := &models.AuthorizeRoleResponse{}
+
+	for _, id := range grant.IDs {
+		if roleId, ok := strings.CutPrefix(id, grantIDPrefixRole); ok {
+			assignments.Roles = append(assignments.Roles, roleId)
+		} else if groupId, ok := strings.CutPrefix(id, grantIDPrefixGroup); ok {
+			assignments.Groups = append(assignments.Groups, groupId)
+		} else if strings.HasPrefix(id, grantIDPrefixApplication) {
+			assignments.Resources = append(assignments.Resources, id)
+		}
+	}
+
+	return assignments
+}
+
+// isOktaNotFound returns true when Okta reports the resource does not exist
+func isOktaNotFound(err error) bool {
+	if oktaErr, ok := err.(*okta.Error); ok {
+		return strings.ToUpper(oktaErr.ErrorCode) == "E0000007"
+	}
+	return false
+}
+
 // GetAuthorizedAccessUrl returns the URL where the user can access their Okta dashboard
 func (p *oktaProvider) GetAuthorizedAccessUrl(
 	ctx context.Context,
@@ -0,0 +1,70 @@
+package opsgenie
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/schedule"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+const IdentitySourceOpsGenie = "opsgenie"
+
+// SynchronizeIdentities lists the on-call schedules as identities so
+// notifications can be sent to whoever is on call e.g. to: platform-oncall
+func (p *opsGenieProvider) SynchronizeIdentities(ctx context.Context, req *models.SynchronizeIdentitiesRequest) (*models.SynchronizeIdentitiesResponse, error) {
+
+	log := p.GetLogger(ctx)
+
+	startTime := time.Now()
+
+	result, err := p.schedules.List(ctx, &schedule.ListRequest{})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OpsGenie schedules: %w", err)
+	}
+
+	identities := make([]models.Identity, 0, len(result.Schedule))
+
+	for _, found := range result.Schedule {
+		if identity := newScheduleIdentity(found); identity != nil {
+			identities = append(identities, *identity)
+		}
+	}
+
+	log.WithFields(logrus.Fields{
+		"provider":  p.GetIdentifier(),
+		"schedules": len(identities),
+	}).Debugf("Listed OpsGenie schedules in %s", time.Since(startTime))
+
+	return &models.SynchronizeIdentitiesResponse{
+		Identities: identities,
+	}, nil
+}
+
+// newScheduleIdentity maps a schedule to a group identity. The schedule
+// name is the ID as it is what alerts are routed with. Disabled schedules
+// have nobody on call so aren't identities.
+func newScheduleIdentity(found schedule.Schedule) *models.Identity {
+
+	if len(found.Name) == 0 || !found.Enabled {
+		return nil
+	}
+
+	label := found.Name + " (on-call)"
+
+	if found.OwnerTeam != nil && len(found.OwnerTeam.Name) > 0 {
+		label = fmt.Sprintf("%s (%s on-call)", found.Name, found.OwnerTeam.Name)
+	}
+
+	return &models.Identity{
+		ID:    found.Name,
+		Label: label,
+		Group: &models.Group{
+			ID:   found.Id,
+			Name: found.Name,
+		},
+	}
+}
@@ -0,0 +1,298 @@
+package opsgenie
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"github.com/opsgenie/opsgenie-go-sdk-v2/client"
+	"github.com/opsgenie/opsgenie-go-sdk-v2/schedule"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/providers"
+)
+
+const OpsGenieProviderName = "opsgenie"
+
+const (
+	// OpsGenie truncates longer alert messages and descriptions
+	maxAlertMessageLength     = 130
+	maxAlertDescriptionLength = 15000
+)
+
+// OpsGenieNotificationRequest creates an alert, or adds a note to an
+// existing alert when AlertID is set
+type OpsGenieNotificationRequest struct {
+	AlertID     string            `json:"alert_id,omitempty"` // Add a note to this alert rather than creating one
+	Message     string            `json:"message"`
+	Description string            `json:"description,omitempty"`
+	Note        string            `json:"note,omitempty"`     // Note for an existing alert, defaults to the message
+	Alias       string            `json:"alias,omitempty"`    // Deduplicates alerts, defaults to the notification ID
+	Priority    string            `json:"priority,omitempty"` // P1 to P5, or high
+	Responders  []string          `json:"responders,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// alertClient is the part of the alert API the provider uses
+type alertClient interface {
+	Create(ctx context.Context, req *alert.CreateAlertRequest) (*alert.AsyncAlertResult, error)
+	AddNote(ctx context.Context, req *alert.AddNoteRequest) (*alert.AsyncAlertResult, error)
+}
+
+// scheduleClient is the part of the schedule API the provider uses
+type scheduleClient interface {
+	List(ctx context.Context, req *schedule.ListRequest) (*schedule.ListResult, error)
+}
+
+// opsGenieProvider implements the ProviderImpl interface for OpsGenie
+type opsGenieProvider struct {
+	*models.BaseProvider
+	alerts    alertClient
+	schedules scheduleClient
+	source    string
+}
+
+// ValidateConfig checks the API key is set and the region is supported
+func (p *opsGenieProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(OpsGenieProviderName)
+	validation.Require(config, "api_key")
+
+	if region, found := config.GetString("region"); found {
+		if _, err := getOpsGenieApiUrl(region); err != nil {
+			validation.Add("region", "must be us, eu or sandbox, got %q", region)
+		}
+	}
+
+	return validation.ErrorOrNil()
+}
+
+func (p *opsGenieProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
+
+	p.BaseProvider = models.NewBaseProvider(
+		identifier,
+		provider,
+		models.ProviderCapabilityNotifier,
+		models.ProviderCapabilityIdentities,
+	)
+
+	opsGenieConfig := p.GetConfig()
+
+	apiKey, foundApiKey := opsGenieConfig.GetString("api_key")
+
+	if !foundApiKey || len(apiKey) == 0 {
+		return fmt.Errorf("missing OpsGenie api_key configuration")
+	}
+
+	apiUrl, err := getOpsGenieApiUrl(opsGenieConfig.GetStringWithDefault("region", "us"))
+
+	if err != nil {
+		return err
+	}
+
+	clientConfig := &client.Config{
+		ApiKey:         apiKey,
+		OpsGenieAPIURL: apiUrl,
+	}
+
+	alerts, err := alert.NewClient(clientConfig)
+
+	if err != nil {
+		return fmt.Errorf("failed to create OpsGenie alert client: %w", err)
+	}
+
+	schedules, err := schedule.NewClient(clientConfig)
+
+	if err != nil {
+		return fmt.Errorf("failed to create OpsGenie schedule client: %w", err)
+	}
+
+	p.alerts = alerts
+	p.schedules = schedules
+	p.source = opsGenieConfig.GetStringWithDefault("source", "Thand")
+
+	return nil
+}
+
+// getOpsGenieApiUrl returns the API for the region the account is hosted in
+func getOpsGenieApiUrl(region string) (client.ApiUrl, error) {
+	switch strings.ToLower(region) {
+	case "", "us":
+		return client.API_URL, nil
+	case "eu":
+		return client.API_URL_EU, nil
+	case "sandbox":
+		return client.API_URL_SANDBOX, nil
+	}
+	return "", fmt.Errorf("invalid OpsGenie region %q, must be us, eu or sandbox", region)
+}
+
+// TestConnection lists the schedules. Sending a notification would page
+// someone, so the notifier can't be checked without side effects.
+func (p *opsGenieProvider) TestConnection(ctx context.Context, capability models.ProviderCapability) error {
+	switch capability {
+	case models.ProviderCapabilityIdentities:
+		if _, err := p.schedules.List(ctx, &schedule.ListRequest{}); err != nil {
+			return fmt.Errorf("failed to list OpsGenie schedules: %w", err)
+		}
+		return nil
+	}
+	return models.ErrNotImplemented
+}
+
+func (p *opsGenieProvider) SendNotification(
+	ctx context.Context, notification models.NotificationRequest,
+) (*models.NotificationReceipt, error) {
+
+	var opsGenieRequest OpsGenieNotificationRequest
+	if err := common.ConvertMapToInterface(notification, &opsGenieRequest); err != nil {
+		return nil, fmt.Errorf("failed to convert OpsGenie notification: %w", err)
+	}
+
+	if len(opsGenieRequest.AlertID) > 0 {
+		return p.addAlertNote(ctx, &opsGenieRequest)
+	}
+
+	return p.createAlert(ctx, &opsGenieRequest, notification.GetNotificationID())
+}
+
+// createAlert opens an alert for the responders. The notification ID is
+// used as the alias so a retried notification doesn't open a second alert.
+func (p *opsGenieProvider) createAlert(
+	ctx context.Context,
+	opsGenieRequest *OpsGenieNotificationRequest,
+	notificationID string,
+) (*models.NotificationReceipt, error) {
+
+	if len(opsGenieRequest.Message) == 0 {
+		return nil, fmt.Errorf("a message is required to create an OpsGenie alert")
+	}
+
+	alias := opsGenieRequest.Alias
+
+	if len(alias) == 0 {
+		alias = notificationID
+	}
+
+	responders := make([]alert.Responder, 0, len(opsGenieRequest.Responders))
+
+	for _, responder := range opsGenieRequest.Responders {
+		responders = append(responders, newAlertResponder(responder))
+	}
+
+	result, err := p.alerts.Create(ctx, &alert.CreateAlertRequest{
+		Message:     truncate(opsGenieRequest.Message, maxAlertMessageLength),
+		Description: truncate(opsGenieRequest.Description, maxAlertDescriptionLength),
+		Alias:       alias,
+		Responders:  responders,
+		Tags:        opsGenieRequest.Tags,
+		Details:     opsGenieRequest.Details,
+		Priority:    getAlertPriority(opsGenieRequest.Priority),
+		Source:      p.source,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpsGenie alert: %w", err)
+	}
+
+	return &models.NotificationReceipt{
+		Target:    strings.Join(opsGenieRequest.Responders, ", "),
+		MessageID: result.RequestId,
+		Response:  result.Result,
+	}, nil
+}
+
+// addAlertNote posts the notification as a note on an existing alert
+func (p *opsGenieProvider) addAlertNote(
+	ctx context.Context,
+	opsGenieRequest *OpsGenieNotificationRequest,
+) (*models.NotificationReceipt, error) {
+
+	note := opsGenieRequest.Note
+
+	if len(note) == 0 {
+		note = opsGenieRequest.Message
+	}
+
+	if len(note) == 0 {
+		return nil, fmt.Errorf("a note or message is required to add a note to an OpsGenie alert")
+	}
+
+	result, err := p.alerts.AddNote(ctx, &alert.AddNoteRequest{
+		IdentifierType:  alert.ALERTID,
+		IdentifierValue: opsGenieRequest.AlertID,
+		Note:            note,
+		Source:          p.source,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to add note to OpsGenie alert %s: %w", opsGenieRequest.AlertID, err)
+	}
+
+	return &models.NotificationReceipt{
+		Target:    opsGenieRequest.AlertID,
+		MessageID: result.RequestId,
+		Response:  result.Result,
+	}, nil
+}
+
+// newAlertResponder parses a responder e.g. schedule:platform-oncall,
+// team:security or user:alice@example.com. Without a type email addresses
+// are users and anything else is a schedule.
+func newAlertResponder(responder string) alert.Responder {
+
+	responderType, name, found := strings.Cut(responder, ":")
+
+	if !found {
+		name = responder
+		if strings.Contains(responder, "@") {
+			responderType = string(alert.UserResponder)
+		} else {
+			responderType = string(alert.ScheduleResponder)
+		}
+	}
+
+	switch alert.ResponderType(strings.ToLower(responderType)) {
+	case alert.UserResponder:
+		return alert.Responder{Type: alert.UserResponder, Username: name}
+	case alert.TeamResponder:
+		return alert.Responder{Type: alert.TeamResponder, Name: name}
+	case alert.EscalationResponder:
+		return alert.Responder{Type: alert.EscalationResponder, Name: name}
+	}
+
+	return alert.Responder{Type: alert.ScheduleResponder, Name: name}
+}
+
+// getAlertPriority maps the priority to P1 to P5. High priority
+// notifications e.g. break-glass requests are P1, the default is P3.
+func getAlertPriority(priority string) alert.Priority {
+
+	switch strings.ToUpper(priority) {
+	case "P1", strings.ToUpper(models.NotificationPriorityHigh):
+		return alert.P1
+	case "P2":
+		return alert.P2
+	case "P4":
+		return alert.P4
+	case "P5":
+		return alert.P5
+	}
+
+	return alert.P3
+}
+
+// truncate shortens the value to the number of characters
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+	return string(runes[:length])
+}
+
+func init() {
+	providers.Register(OpsGenieProviderName, &opsGenieProvider{})
+}
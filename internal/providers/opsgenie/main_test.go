@@ -0,0 +1,157 @@
+package opsgenie
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"github.com/opsgenie/opsgenie-go-sdk-v2/client"
+	"github.com/opsgenie/opsgenie-go-sdk-v2/og"
+	"github.com/opsgenie/opsgenie-go-sdk-v2/schedule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+type fakeAlerts struct {
+	created []*alert.CreateAlertRequest
+	notes   []*alert.AddNoteRequest
+	err     error
+}
+
+func (f *fakeAlerts) Create(ctx context.Context, req *alert.CreateAlertRequest) (*alert.AsyncAlertResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.created = append(f.created, req)
+	return &alert.AsyncAlertResult{
+		ResultMetadata: client.ResultMetadata{RequestId: "request-1"},
+		Result:         "Request will be processed",
+	}, nil
+}
+
+func (f *fakeAlerts) AddNote(ctx context.Context, req *alert.AddNoteRequest) (*alert.AsyncAlertResult, error) {
+	f.notes = append(f.notes, req)
+	return &alert.AsyncAlertResult{
+		ResultMetadata: client.ResultMetadata{RequestId: "request-2"},
+	}, nil
+}
+
+type fakeSchedules struct {
+	schedules []schedule.Schedule
+}
+
+func (f *fakeSchedules) List(ctx context.Context, req *schedule.ListRequest) (*schedule.ListResult, error) {
+	return &schedule.ListResult{Schedule: f.schedules}, nil
+}
+
+func newTestProvider(alerts *fakeAlerts, schedules *fakeSchedules) *opsGenieProvider {
+	return &opsGenieProvider{
+		BaseProvider: models.NewBaseProvider(OpsGenieProviderName, models.Provider{
+			Name:     OpsGenieProviderName,
+			Provider: OpsGenieProviderName,
+		}, models.ProviderCapabilityNotifier, models.ProviderCapabilityIdentities),
+		alerts:    alerts,
+		schedules: schedules,
+		source:    "Thand",
+	}
+}
+
+func TestSendNotification(t *testing.T) {
+
+	t.Run("creates an alert", func(t *testing.T) {
+		alerts := &fakeAlerts{}
+		provider := newTestProvider(alerts, nil)
+
+		receipt, err := provider.SendNotification(context.Background(), models.NotificationRequest{
+			"message":                "Access requested by Alice",
+			"description":            "Alice requested admin for 1h",
+			"priority":               "high",
+			"responders":             []any{"platform-oncall", "team:security", "alice@example.com"},
+			models.NotificationIDKey: "notification-1",
+		})
+		require.NoError(t, err)
+
+		require.Len(t, alerts.created, 1)
+		created := alerts.created[0]
+		assert.Equal(t, "notification-1", created.Alias, "the notification ID deduplicates retries")
+		assert.Equal(t, alert.P1, created.Priority)
+		assert.Equal(t, "Thand", created.Source)
+		assert.Equal(t, []alert.Responder{
+			{Type: alert.ScheduleResponder, Name: "platform-oncall"},
+			{Type: alert.TeamResponder, Name: "security"},
+			{Type: alert.UserResponder, Username: "alice@example.com"},
+		}, created.Responders)
+
+		assert.Equal(t, "request-1", receipt.MessageID)
+		assert.Equal(t, "platform-oncall, team:security, alice@example.com", receipt.Target)
+	})
+
+	t.Run("adds a note to an existing alert", func(t *testing.T) {
+		alerts := &fakeAlerts{}
+		provider := newTestProvider(alerts, nil)
+
+		receipt, err := provider.SendNotification(context.Background(), models.NotificationRequest{
+			"alert_id": "alert-123",
+			"message":  "Access granted to Alice",
+		})
+		require.NoError(t, err)
+
+		assert.Empty(t, alerts.created)
+		require.Len(t, alerts.notes, 1)
+		assert.Equal(t, alert.ALERTID, alerts.notes[0].IdentifierType)
+		assert.Equal(t, "alert-123", alerts.notes[0].IdentifierValue)
+		assert.Equal(t, "Access granted to Alice", alerts.notes[0].Note)
+		assert.Equal(t, "alert-123", receipt.Target)
+	})
+
+	t.Run("long messages are truncated", func(t *testing.T) {
+		alerts := &fakeAlerts{}
+		provider := newTestProvider(alerts, nil)
+
+		_, err := provider.SendNotification(context.Background(), models.NotificationRequest{
+			"message": string(make([]rune, 200)),
+		})
+		require.NoError(t, err)
+		assert.Len(t, []rune(alerts.created[0].Message), maxAlertMessageLength)
+		assert.Equal(t, alert.P3, alerts.created[0].Priority)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		provider := newTestProvider(&fakeAlerts{err: errors.New("unauthorized")}, nil)
+
+		_, err := provider.SendNotification(context.Background(), models.NotificationRequest{"message": "hi"})
+		assert.ErrorContains(t, err, "failed to create OpsGenie alert: unauthorized")
+
+		_, err = provider.SendNotification(context.Background(), models.NotificationRequest{})
+		assert.ErrorContains(t, err, "a message is required")
+	})
+}
+
+func TestSynchronizeIdentities(t *testing.T) {
+
+	provider := newTestProvider(nil, &fakeSchedules{schedules: []schedule.Schedule{
+		{Id: "s1", Name: "platform-oncall", Enabled: true, OwnerTeam: &og.OwnerTeam{Name: "Platform"}},
+		{Id: "s2", Name: "security-oncall", Enabled: true},
+		{Id: "s3", Name: "retired", Enabled: false},
+	}})
+
+	response, err := provider.SynchronizeIdentities(context.Background(), &models.SynchronizeIdentitiesRequest{})
+	require.NoError(t, err)
+	require.Len(t, response.Identities, 2, "disabled schedules are skipped")
+
+	assert.Equal(t, "platform-oncall", response.Identities[0].ID)
+	assert.Equal(t, "platform-oncall (Platform on-call)", response.Identities[0].Label)
+	assert.Equal(t, "s1", response.Identities[0].Group.ID)
+	assert.Equal(t, "security-oncall (on-call)", response.Identities[1].Label)
+}
+
+func TestGetOpsGenieApiUrl(t *testing.T) {
+	url, err := getOpsGenieApiUrl("EU")
+	require.NoError(t, err)
+	assert.Equal(t, client.API_URL_EU, url)
+
+	_, err = getOpsGenieApiUrl("apac")
+	assert.ErrorContains(t, err, "invalid OpsGenie region")
+}
@@ -27,7 +27,7 @@ func NewRemoteProviderProxy(providerKey, endpoint string) models.ProviderImpl {
 	}
 }
 
-func (p *remoteProviderProxy) Initialize(identifier string, provider models.Provider) error {
+func (p *remoteProviderProxy) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
@@ -77,3 +77,18 @@ func (p *remoteProviderProxy) AuthorizeSession(ctx context.Context, user *models
 
 	return &authResponse, nil
 }
+
+// Sessions are created, validated and renewed by the server, the proxy only
+// starts the authorization
+
+func (p *remoteProviderProxy) CreateSession(ctx context.Context, user *models.AuthorizeUser) (*models.Session, error) {
+	return nil, fmt.Errorf("provider %s creates sessions on the server: %w", p.providerKey, models.ErrNotImplemented)
+}
+
+func (p *remoteProviderProxy) ValidateSession(ctx context.Context, session *models.Session) error {
+	return fmt.Errorf("provider %s validates sessions on the server: %w", p.providerKey, models.ErrNotImplemented)
+}
+
+func (p *remoteProviderProxy) RenewSession(ctx context.Context, session *models.Session) (*models.Session, error) {
+	return nil, fmt.Errorf("provider %s renews sessions on the server: %w", p.providerKey, models.ErrNotImplemented)
+}
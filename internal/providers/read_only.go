@@ -0,0 +1,200 @@
+package providers
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// GrantKindSimulated is the kind of grant recorded by read only providers
+const GrantKindSimulated = "simulated"
+
+// readOnlyProvider wraps a provider so grants and revocations are logged
+// rather than made. Everything else, including synchronization and role
+// validation, is passed through to the real provider so new integrations
+// can be piloted end to end.
+type readOnlyProvider struct {
+	models.ProviderImpl
+}
+
+// NewReadOnlyProvider wraps the provider so it only simulates grants
+func NewReadOnlyProvider(provider models.ProviderImpl) models.ProviderImpl {
+	return &readOnlyProvider{
+		ProviderImpl: provider,
+	}
+}
+
+// IsReadOnly returns true if the provider was wrapped as read only
+func IsReadOnly(provider models.ProviderImpl) bool {
+	_, ok := provider.(*readOnlyProvider)
+	return ok
+}
+
+func (p *readOnlyProvider) AuthorizeRole(
+	ctx context.Context,
+	req *models.AuthorizeRoleRequest,
+) (*models.AuthorizeRoleResponse, error) {
+
+	grantRef := models.NewGrantRef(p.GetIdentifier(), GrantKindSimulated, req.RoleRequest)
+	grantRef.Simulated = true
+
+	p.auditLog(req.RoleRequest).Info("Simulated role authorization for read only provider")
+
+	response := &models.AuthorizeRoleResponse{
+		GrantRef: grantRef,
+	}
+
+	if req.User != nil {
+		response.UserId = req.User.GetIdentity()
+	}
+
+	if req.Role != nil {
+		response.Roles = []string{req.Role.GetName()}
+	}
+
+	return response, nil
+}
+
+func (p *readOnlyProvider) RevokeRole(
+	ctx context.Context,
+	req *models.RevokeRoleRequest,
+) (*models.RevokeRoleResponse, error) {
+
+	p.auditLog(req.RoleRequest).WithField("grant_ref", req.GetGrantRef()).
+		Info("Simulated role revocation for read only provider")
+
+	return &models.RevokeRoleResponse{}, nil
+}
+
+// RegisterActivities registers the activities against the wrapper, rather
+// than the wrapped provider, so grants made by temporal are simulated too
+func (p *readOnlyProvider) RegisterActivities(temporalClient models.TemporalImpl) error {
+	return models.RegisterActivities(temporalClient, models.NewProviderActivities(p))
+}
+
+// Unwrap returns the wrapped provider so capability lookups check what
+// the real provider supports
+func (p *readOnlyProvider) Unwrap() models.ProviderImpl {
+	return p.ProviderImpl
+}
+
+// Signing users in and sending notifications don't grant anything, so they
+// are passed through to the wrapped provider
+
+func (p *readOnlyProvider) AuthorizeSession(ctx context.Context, user *models.AuthorizeUser) (*models.AuthorizeSessionResponse, error) {
+	authorizor, err := models.AsAuthorizor(p.ProviderImpl)
+	if err != nil {
+		return nil, err
+	}
+	return authorizor.AuthorizeSession(ctx, user)
+}
+
+func (p *readOnlyProvider) CreateSession(ctx context.Context, user *models.AuthorizeUser) (*models.Session, error) {
+	authorizor, err := models.AsAuthorizor(p.ProviderImpl)
+	if err != nil {
+		return nil, err
+	}
+	return authorizor.CreateSession(ctx, user)
+}
+
+func (p *readOnlyProvider) ValidateSession(ctx context.Context, session *models.Session) error {
+	authorizor, err := models.AsAuthorizor(p.ProviderImpl)
+	if err != nil {
+		return err
+	}
+	return authorizor.ValidateSession(ctx, session)
+}
+
+func (p *readOnlyProvider) RenewSession(ctx context.Context, session *models.Session) (*models.Session, error) {
+	authorizor, err := models.AsAuthorizor(p.ProviderImpl)
+	if err != nil {
+		return nil, err
+	}
+	return authorizor.RenewSession(ctx, session)
+}
+
+func (p *readOnlyProvider) SendNotification(ctx context.Context, notification models.NotificationRequest) (*models.NotificationReceipt, error) {
+	notifier, err := models.AsNotifier(p.ProviderImpl)
+	if err != nil {
+		return nil, err
+	}
+	return notifier.SendNotification(ctx, notification)
+}
+
+// SetSelfServiceHandler passes the handler through to the wrapped provider
+func (p *readOnlyProvider) SetSelfServiceHandler(handler models.SelfServiceHandler) error {
+	if selfService, ok := p.ProviderImpl.(models.ProviderSelfService); ok {
+		return selfService.SetSelfServiceHandler(handler)
+	}
+	return models.ErrNotImplemented
+}
+
+// GetAuditPrincipal passes through to the wrapped provider
+func (p *readOnlyProvider) GetAuditPrincipal(user *models.User) (string, error) {
+	if auditTrail, ok := p.ProviderImpl.(models.ProviderAuditTrail); ok {
+		return auditTrail.GetAuditPrincipal(user)
+	}
+	return "", models.ErrNotImplemented
+}
+
+// GetAccessAuditTrail passes through to the wrapped provider, looking up
+// the audit trail doesn't change anything
+func (p *readOnlyProvider) GetAccessAuditTrail(
+	ctx context.Context,
+	principal string,
+	startTime time.Time,
+	endTime time.Time,
+) ([]models.AuditEvent, error) {
+	if auditTrail, ok := p.ProviderImpl.(models.ProviderAuditTrail); ok {
+		return auditTrail.GetAccessAuditTrail(ctx, principal, startTime, endTime)
+	}
+	return nil, models.ErrNotImplemented
+}
+
+// ListManagedGrants passes through to the wrapped provider, listing the
+// grants doesn't change anything
+func (p *readOnlyProvider) ListManagedGrants(ctx context.Context) ([]models.ManagedGrant, error) {
+	if reconciler, ok := p.ProviderImpl.(models.ProviderGrantReconciler); ok {
+		return reconciler.ListManagedGrants(ctx)
+	}
+	return nil, models.ErrNotImplemented
+}
+
+// auditLog records what the provider would have been asked to do
+func (p *readOnlyProvider) auditLog(req *models.RoleRequest) *logrus.Entry {
+
+	fields := logrus.Fields{
+		"audit":     true,
+		"simulated": true,
+		"provider":  p.GetIdentifier(),
+	}
+
+	if req == nil {
+		return logrus.WithFields(fields)
+	}
+
+	if req.User != nil {
+		fields["user"] = req.User.GetIdentity()
+	}
+
+	if req.Role != nil {
+		fields["role"] = req.Role.GetName()
+		fields["permissions"] = req.Role.Permissions.Allow
+		fields["inherits"] = req.Role.Inherits
+		fields["resources"] = req.Role.Resources.Allow
+	}
+
+	if req.Duration != nil {
+		fields["duration"] = req.Duration.String()
+	}
+
+	if req.Audit != nil {
+		fields["workflow"] = req.Audit.WorkflowID
+		fields["requester"] = req.Audit.Requester
+		fields["reason"] = req.Audit.Reason
+	}
+
+	return logrus.WithFields(fields)
+}
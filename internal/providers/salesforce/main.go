@@ -1,6 +1,8 @@
 package salesforce
 
 import (
+	"context"
+
 	"fmt"
 	"strings"
 
@@ -17,7 +19,16 @@ type salesForceProvider struct {
 	client *simpleforce.Client
 }
 
-func (p *salesForceProvider) Initialize(identifier string, provider models.Provider) error {
+// ValidateConfig checks the login credentials are set
+func (p *salesForceProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError("salesforce")
+	validation.Require(config, "username", "password", "token")
+
+	return validation.ErrorOrNil()
+}
+
+func (p *salesForceProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
 		provider,
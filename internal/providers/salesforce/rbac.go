@@ -14,6 +14,11 @@ import (
 
 const MetadataPriorProfileKey = "prior_profile"
 
+// GrantKindProfile is the grant kind for Salesforce. Profiles have no
+// assignment of their own, so the grant ID is the user ID and the granted and
+// prior profiles are recorded as attributes.
+const GrantKindProfile = "profile"
+
 func (p *salesForceProvider) AuthorizeRole(
 	ctx context.Context,
 	req *models.AuthorizeRoleRequest,
@@ -93,6 +98,7 @@ func (p *salesForceProvider) AuthorizeRole(
 			Metadata: map[string]any{
 				MetadataPriorProfileKey: currentProfileId,
 			},
+			GrantRef: p.newProfileGrant(req, salesforceUserId, profileResult.ID, currentProfileId),
 		}, nil
 	}
 
@@ -125,9 +131,24 @@ func (p *salesForceProvider) AuthorizeRole(
 		Metadata: map[string]any{
 			MetadataPriorProfileKey: currentProfileId,
 		},
+		GrantRef: p.newProfileGrant(req, salesforceUserId, profileResult.ID, currentProfileId),
 	}, nil
 }
 
+// newProfileGrant records the profile change made for the user
+func (p *salesForceProvider) newProfileGrant(
+	req *models.AuthorizeRoleRequest,
+	userId string,
+	profileId string,
+	priorProfileId string,
+) *models.GrantRef {
+	grant := models.NewGrantRef(p.GetIdentifier(), GrantKindProfile, req.RoleRequest)
+	grant.AddID(userId)
+	grant.SetAttribute("profile_id", profileId)
+	grant.SetAttribute(MetadataPriorProfileKey, priorProfileId)
+	return grant
+}
+
 // Revoke removes access for a user from a role by reverting to the prior profile
 func (p *salesForceProvider) RevokeRole(
 	ctx context.Context,
@@ -142,10 +163,17 @@ func (p *salesForceProvider) RevokeRole(
 	}
 
 	metadata := req.AuthorizeRoleResponse
+	grant := req.GetGrantRef()
 
-	// First find the user by their email
+	// Find the user recorded by the grant, otherwise by their email
 	userQuery := "SELECT Id, Name, ProfileId FROM User WHERE Email = ?"
-	userResult, err := p.queryWithParams(userQuery, user.Email)
+	userKey := user.Email
+	if grant.HasIDs() {
+		userQuery = "SELECT Id, Name, ProfileId FROM User WHERE Id = ?"
+		userKey = grant.IDs[0]
+	}
+
+	userResult, err := p.queryWithParams(userQuery, userKey)
 	if err != nil {
 		return nil, temporal.NewApplicationErrorWithOptions(
 			fmt.Sprintf("failed to query user: %v", err),
@@ -164,9 +192,25 @@ func (p *salesForceProvider) RevokeRole(
 	salesforceUserId := userResult.Records[0].StringField("Id")
 	currentProfileId := userResult.Records[0].StringField("ProfileId")
 
-	// Get the profile to revert to from metadata
-	priorProfileId, ok := metadata.Metadata[MetadataPriorProfileKey].(string)
-	if !ok || len(priorProfileId) == 0 {
+	// The profile has since been changed by someone else, so there is
+	// nothing left of the grant to revoke
+	if grantedProfileId := grant.GetAttribute("profile_id"); len(grantedProfileId) > 0 &&
+		currentProfileId != grantedProfileId {
+		logrus.WithFields(logrus.Fields{
+			"user_id":    salesforceUserId,
+			"user_email": user.Email,
+			"profile_id": currentProfileId,
+		}).Info("User no longer has the granted profile in Salesforce, nothing to revoke")
+		return &models.RevokeRoleResponse{}, nil
+	}
+
+	// Get the profile to revert to from the grant or metadata
+	priorProfileId := grant.GetAttribute(MetadataPriorProfileKey)
+	if len(priorProfileId) == 0 {
+		priorProfileId, _ = metadata.Metadata[MetadataPriorProfileKey].(string)
+	}
+
+	if len(priorProfileId) == 0 {
 		// If no prior profile stored, use a default profile
 		defaultProfiles := []string{"Standard User", "Minimum Access - Salesforce"}
 
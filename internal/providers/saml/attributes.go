@@ -0,0 +1,206 @@
+package saml
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/crewjam/saml"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// AttributeMapping sets the SAML attribute names for the user fields.
+// Unset fields, or attributes missing from the assertion, fall back to
+// the well known attribute names.
+type AttributeMapping struct {
+	Email    string `yaml:"email" json:"email"`
+	Name     string `yaml:"name" json:"name"`
+	Username string `yaml:"username" json:"username"`
+	Groups   string `yaml:"groups" json:"groups"`
+}
+
+// Well known attribute names used by common IdPs e.g. Okta, Entra ID,
+// ADFS and Google, as names or friendly names
+var (
+	defaultEmailAttributes = []string{
+		"email",
+		"Email",
+		"emailAddress",
+		"mail",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress",
+		"urn:oid:0.9.2342.19200300.100.1.3",
+	}
+	defaultNameAttributes = []string{
+		"displayName",
+		"name",
+		"cn",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/name",
+		"http://schemas.microsoft.com/identity/claims/displayname",
+		"urn:oid:2.16.840.1.113730.3.1.241",
+	}
+	defaultUsernameAttributes = []string{
+		"username",
+		"uid",
+		"sAMAccountName",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/upn",
+		"urn:oid:0.9.2342.19200300.100.1.1",
+	}
+	defaultGroupsAttributes = []string{
+		"groups",
+		"Groups",
+		"memberOf",
+		"http://schemas.microsoft.com/ws/2008/06/identity/claims/groups",
+		"http://schemas.xmlsoap.org/claims/Group",
+	}
+)
+
+// parseAttributeMapping reads the attribute_mapping block from the
+// provider config
+func parseAttributeMapping(config any) (AttributeMapping, error) {
+
+	mapping := AttributeMapping{}
+
+	if config == nil {
+		return mapping, nil
+	}
+
+	values, ok := config.(map[string]any)
+
+	if !ok {
+		return mapping, fmt.Errorf("attribute_mapping must be a map of user fields to attribute names")
+	}
+
+	fields := map[string]*string{
+		"email":    &mapping.Email,
+		"name":     &mapping.Name,
+		"username": &mapping.Username,
+		"groups":   &mapping.Groups,
+	}
+
+	for key, value := range values {
+
+		field, found := fields[key]
+
+		if !found {
+			return mapping, fmt.Errorf("unknown attribute_mapping field: %s", key)
+		}
+
+		attribute, ok := value.(string)
+
+		if !ok {
+			return mapping, fmt.Errorf("attribute_mapping.%s must be a string", key)
+		}
+
+		*field = attribute
+	}
+
+	return mapping, nil
+}
+
+// extractUserFromAssertion builds the user from the assertion attributes
+// using the configured mapping first, then the well known attribute names.
+// The subject NameID is used when no email or username attribute is found.
+func extractUserFromAssertion(assertion *saml.Assertion, mapping AttributeMapping) (*models.User, error) {
+
+	if assertion == nil {
+		return nil, fmt.Errorf("assertion is nil")
+	}
+
+	attributes := getAssertionAttributes(assertion)
+
+	nameID := ""
+	if assertion.Subject != nil && assertion.Subject.NameID != nil {
+		nameID = strings.TrimSpace(assertion.Subject.NameID.Value)
+	}
+
+	user := &models.User{
+		ID:       nameID,
+		Email:    getFirstAttribute(attributes, mapping.Email, defaultEmailAttributes),
+		Name:     getFirstAttribute(attributes, mapping.Name, defaultNameAttributes),
+		Username: getFirstAttribute(attributes, mapping.Username, defaultUsernameAttributes),
+		Groups:   getAttributeValues(attributes, mapping.Groups, defaultGroupsAttributes),
+		Source:   SamlProviderName,
+	}
+
+	if len(user.Email) == 0 && strings.Contains(nameID, "@") {
+		user.Email = nameID
+	}
+
+	if len(user.Username) == 0 {
+		user.Username = nameID
+	}
+
+	if len(user.ID) == 0 {
+		user.ID = user.GetIdentity()
+	}
+
+	if len(user.Email) == 0 && len(user.Username) == 0 {
+		return nil, fmt.Errorf("assertion has no email, username or NameID")
+	}
+
+	if user.Groups == nil {
+		user.Groups = []string{}
+	}
+
+	return user, nil
+}
+
+// getAssertionAttributes returns the attribute values keyed by both the
+// attribute name and friendly name
+func getAssertionAttributes(assertion *saml.Assertion) map[string][]string {
+
+	attributes := map[string][]string{}
+
+	for _, statement := range assertion.AttributeStatements {
+		for _, attribute := range statement.Attributes {
+
+			values := []string{}
+			for _, value := range attribute.Values {
+				if trimmed := strings.TrimSpace(value.Value); len(trimmed) > 0 {
+					values = append(values, trimmed)
+				}
+			}
+
+			for _, key := range []string{attribute.Name, attribute.FriendlyName} {
+				if len(key) > 0 {
+					attributes[key] = append(attributes[key], values...)
+				}
+			}
+		}
+	}
+
+	return attributes
+}
+
+// getAttributeValues returns the values of the mapped attribute, or the
+// first well known attribute that has values
+func getAttributeValues(attributes map[string][]string, mapped string, defaults []string) []string {
+
+	names := defaults
+	if len(mapped) > 0 {
+		names = append([]string{mapped}, defaults...)
+	}
+
+	for _, name := range names {
+		if values := attributes[name]; len(values) > 0 {
+			return values
+		}
+	}
+
+	// IdPs differ in case e.g. EmailAddress
+	for _, name := range names {
+		for key, values := range attributes {
+			if strings.EqualFold(key, name) && len(values) > 0 {
+				return values
+			}
+		}
+	}
+
+	return nil
+}
+
+func getFirstAttribute(attributes map[string][]string, mapped string, defaults []string) string {
+	if values := getAttributeValues(attributes, mapped, defaults); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
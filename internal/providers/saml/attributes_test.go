@@ -0,0 +1,147 @@
+package saml
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/crewjam/saml"
+)
+
+func newTestAssertion(nameID string, attributes map[string][]string) *saml.Assertion {
+
+	statement := saml.AttributeStatement{}
+
+	for name, values := range attributes {
+		attribute := saml.Attribute{Name: name}
+		for _, value := range values {
+			attribute.Values = append(attribute.Values, saml.AttributeValue{Value: value})
+		}
+		statement.Attributes = append(statement.Attributes, attribute)
+	}
+
+	return &saml.Assertion{
+		Subject:             &saml.Subject{NameID: &saml.NameID{Value: nameID}},
+		AttributeStatements: []saml.AttributeStatement{statement},
+	}
+}
+
+func TestExtractUserFromAssertion_DefaultAttributes(t *testing.T) {
+
+	// Okta style claim URIs
+	assertion := newTestAssertion("00u1abcd", map[string][]string{
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress": {"alice@example.com"},
+		"displayName": {"Alice Smith"},
+		"groups":      {"admins", "developers"},
+	})
+
+	user, err := extractUserFromAssertion(assertion, AttributeMapping{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if user.Email != "alice@example.com" {
+		t.Errorf("Expected email from claim URI, got %q", user.Email)
+	}
+
+	if user.Name != "Alice Smith" {
+		t.Errorf("Expected name from displayName, got %q", user.Name)
+	}
+
+	if user.ID != "00u1abcd" || user.Username != "00u1abcd" {
+		t.Errorf("Expected NameID for ID and username, got %q and %q", user.ID, user.Username)
+	}
+
+	if !slices.Equal(user.Groups, []string{"admins", "developers"}) {
+		t.Errorf("Unexpected groups: %v", user.Groups)
+	}
+}
+
+func TestExtractUserFromAssertion_Mapping(t *testing.T) {
+
+	assertion := newTestAssertion("alice@corp.example.com", map[string][]string{
+		"mail":           {"alice@example.com"},
+		"workEmail":      {"alice@work.example.com"},
+		"sAMAccountName": {"asmith"},
+		"fullName":       {"Alice Smith"},
+		"memberOf":       {"CN=Admins,OU=Groups"},
+		"groups":         {"ignored"},
+	})
+
+	mapping := AttributeMapping{
+		Email:  "workEmail",
+		Name:   "fullName",
+		Groups: "memberOf",
+	}
+
+	user, err := extractUserFromAssertion(assertion, mapping)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if user.Email != "alice@work.example.com" {
+		t.Errorf("Expected mapped email, got %q", user.Email)
+	}
+
+	if user.Name != "Alice Smith" {
+		t.Errorf("Expected mapped name, got %q", user.Name)
+	}
+
+	if user.Username != "asmith" {
+		t.Errorf("Expected username from sAMAccountName, got %q", user.Username)
+	}
+
+	if !slices.Equal(user.Groups, []string{"CN=Admins,OU=Groups"}) {
+		t.Errorf("Expected mapped groups, got %v", user.Groups)
+	}
+
+	// A mapped attribute missing from the assertion falls back to the defaults
+	user, err = extractUserFromAssertion(assertion, AttributeMapping{Email: "missing"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if user.Email != "alice@example.com" {
+		t.Errorf("Expected fallback email, got %q", user.Email)
+	}
+}
+
+func TestExtractUserFromAssertion_NameID(t *testing.T) {
+
+	user, err := extractUserFromAssertion(newTestAssertion("bob@example.com", nil), AttributeMapping{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if user.Email != "bob@example.com" {
+		t.Errorf("Expected email from NameID, got %q", user.Email)
+	}
+
+	if _, err := extractUserFromAssertion(newTestAssertion("", nil), AttributeMapping{}); err == nil {
+		t.Error("Expected error for assertion without a user")
+	}
+}
+
+func TestParseAttributeMapping(t *testing.T) {
+
+	mapping, err := parseAttributeMapping(map[string]any{
+		"email":    "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress",
+		"name":     "displayName",
+		"username": "sAMAccountName",
+		"groups":   "memberOf",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if mapping.Username != "sAMAccountName" || mapping.Groups != "memberOf" {
+		t.Errorf("Mapping not parsed correctly: %+v", mapping)
+	}
+
+	if _, err := parseAttributeMapping(map[string]any{"phone": "mobile"}); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+
+	if _, err := parseAttributeMapping("email"); err == nil {
+		t.Error("Expected error for invalid mapping")
+	}
+}
@@ -0,0 +1,217 @@
+package saml
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"encoding/xml"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlidp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// newTestKeyPair generates an RSA key and a self-signed certificate
+func newTestKeyPair(t *testing.T, commonName string) (*rsa.PrivateKey, *x509.Certificate) {
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return key, certificate
+}
+
+// writeTestKeyPair writes the key pair as PEM files for the provider config
+func writeTestKeyPair(t *testing.T, key *rsa.PrivateKey, certificate *x509.Certificate) (string, string) {
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "sp.crt")
+	keyFile := filepath.Join(dir, "sp.key")
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: certificate.Raw,
+	}), 0600))
+
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}), 0600))
+
+	return certFile, keyFile
+}
+
+// newMockIdP starts an in-process IdP serving its metadata on localhost
+func newMockIdP(t *testing.T) (*samlidp.Server, *httptest.Server) {
+
+	key, certificate := newTestKeyPair(t, "idp.example.com")
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	idpURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	idp, err := samlidp.New(samlidp.Options{
+		URL:         *idpURL,
+		Key:         key,
+		Certificate: certificate,
+		Store:       &samlidp.MemoryStore{},
+	})
+	require.NoError(t, err)
+
+	server.Config.Handler = idp
+
+	return idp, server
+}
+
+func TestSAMLProvider_Integration(t *testing.T) {
+
+	idp, idpServer := newMockIdP(t)
+
+	spKey, spCertificate := newTestKeyPair(t, "thand.example.com")
+	certFile, keyFile := writeTestKeyPair(t, spKey, spCertificate)
+
+	provider := &samlProvider{}
+	err := provider.Initialize(context.Background(), "saml", models.Provider{
+		Name:     "saml",
+		Provider: SamlProviderName,
+		Config: &models.BasicConfig{
+			"idp_metadata_url": idpServer.URL + "/metadata",
+			"entity_id":        "https://thand.example.com/saml/metadata",
+			"root_url":         "https://thand.example.com",
+			"cert_file":        certFile,
+			"key_file":         keyFile,
+			"session_duration": "8h",
+			"attribute_mapping": map[string]any{
+				"groups": "eduPersonAffiliation",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	// Register the service provider with the IdP
+	spMetadata, err := xml.Marshal(provider.middleware.ServiceProvider.Metadata())
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPut, idpServer.URL+"/services/thand", bytes.NewReader(spMetadata))
+	require.NoError(t, err)
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusNoContent, response.StatusCode)
+
+	authResponse, err := provider.AuthorizeSession(context.Background(), &models.AuthorizeUser{
+		State: "test-state",
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, authResponse.Url)
+
+	authURL, err := url.Parse(authResponse.Url)
+	require.NoError(t, err)
+	assert.Equal(t, idpServer.URL+"/sso", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	assert.Equal(t, "test-state", authURL.Query().Get("RelayState"))
+
+	// Sign a response to the authentication request with the IdP's key
+	newSAMLResponse := func(t *testing.T) string {
+
+		idpRequest, err := saml.NewIdpAuthnRequest(&idp.IDP, httptest.NewRequest(http.MethodGet, authResponse.Url, nil))
+		require.NoError(t, err)
+		require.NoError(t, idpRequest.Validate())
+
+		err = saml.DefaultAssertionMaker{}.MakeAssertion(idpRequest, &saml.Session{
+			ID:             "session-1",
+			NameID:         "alice@example.com",
+			CreateTime:     saml.TimeNow(),
+			ExpireTime:     saml.TimeNow().Add(time.Hour),
+			Index:          "1",
+			UserName:       "alice",
+			UserEmail:      "alice@example.com",
+			UserCommonName: "Alice Smith",
+			Groups:         []string{"engineering", "admins"},
+		})
+		require.NoError(t, err)
+
+		form, err := idpRequest.PostBinding()
+		require.NoError(t, err)
+
+		return form.SAMLResponse
+	}
+
+	t.Run("creates a session from the assertion", func(t *testing.T) {
+
+		session, err := provider.CreateSession(context.Background(), &models.AuthorizeUser{
+			State: "test-state",
+			Code:  newSAMLResponse(t),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, session.User)
+
+		assert.Equal(t, "alice@example.com", session.User.Email)
+		assert.Equal(t, "alice", session.User.Username)
+		assert.Equal(t, "Alice Smith", session.User.Name)
+		assert.ElementsMatch(t, []string{"engineering", "admins"}, session.User.Groups)
+		assert.WithinDuration(t, time.Now().Add(8*time.Hour), session.Expiry, time.Minute)
+
+		assert.NoError(t, provider.ValidateSession(context.Background(), session))
+	})
+
+	t.Run("rejects a response signed with another key", func(t *testing.T) {
+
+		otherKey, otherCertificate := newTestKeyPair(t, "idp.example.com")
+
+		otherIdP := idp.IDP
+		otherIdP.Key = otherKey
+		otherIdP.Certificate = otherCertificate
+
+		idpRequest, err := saml.NewIdpAuthnRequest(&otherIdP, httptest.NewRequest(http.MethodGet, authResponse.Url, nil))
+		require.NoError(t, err)
+		require.NoError(t, idpRequest.Validate())
+
+		err = saml.DefaultAssertionMaker{}.MakeAssertion(idpRequest, &saml.Session{
+			ID:         "session-2",
+			NameID:     "mallory@example.com",
+			CreateTime: saml.TimeNow(),
+			ExpireTime: saml.TimeNow().Add(time.Hour),
+			Index:      "2",
+			UserEmail:  "mallory@example.com",
+		})
+		require.NoError(t, err)
+
+		form, err := idpRequest.PostBinding()
+		require.NoError(t, err)
+
+		_, err = provider.CreateSession(context.Background(), &models.AuthorizeUser{
+			Code: form.SAMLResponse,
+		})
+		assert.Error(t, err)
+	})
+}
@@ -5,27 +5,40 @@ import (
 	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/crewjam/saml"
 	"github.com/crewjam/saml/samlsp"
 	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/models"
 	"github.com/thand-io/agent/internal/providers"
 )
 
 const SamlProviderName = "saml"
 
+// DefaultSessionDuration is used when no session_duration is configured
+const DefaultSessionDuration = 24 * time.Hour
+
 // samlProvider implements the ProviderImpl interface for SAML
 type samlProvider struct {
 	*models.BaseProvider
 	middleware   *samlsp.Middleware
 	idpMetadata  *saml.EntityDescriptor
 	certificates []tls.Certificate
+
+	sessionDuration  time.Duration
+	attributeMapping AttributeMapping
+
+	// IDs of the authentication requests sent to the IdP, so responses
+	// can be matched to a request
+	requestMu  sync.Mutex
+	requestIDs map[string]time.Time
 }
 
 // SAMLConfig represents the SAML provider configuration
@@ -36,9 +49,48 @@ type SAMLConfig struct {
 	CertFile       string `yaml:"cert_file" json:"cert_file"`
 	KeyFile        string `yaml:"key_file" json:"key_file"`
 	SignRequests   bool   `yaml:"sign_requests" json:"sign_requests"`
+	// SessionDuration accepts any format supported by common.ParseDuration
+	// or a number of seconds
+	SessionDuration time.Duration `yaml:"session_duration" json:"session_duration"`
+
+	// AttributeMapping overrides the attribute names used for the user
+	AttributeMapping AttributeMapping `yaml:"attribute_mapping" json:"attribute_mapping"`
+}
+
+// requestIDTTL is how long the IdP has to respond to a request
+const requestIDTTL = 10 * time.Minute
+
+// ValidateConfig checks the IdP, service provider and certificate are
+// configured and the optional fields can be parsed
+func (p *samlProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(SamlProviderName)
+	validation.Require(config, "idp_metadata_url", "entity_id", "root_url", "cert_file", "key_file")
+
+	for _, field := range []string{"idp_metadata_url", "root_url"} {
+		if value, found := config.GetString(field); found && len(value) > 0 {
+			if parsed, err := url.Parse(value); err != nil || len(parsed.Scheme) == 0 || len(parsed.Host) == 0 {
+				validation.Add(field, "must be an absolute URL, got %q", value)
+			}
+		}
+	}
+
+	if config.HasString("sign_requests") {
+		if _, found := config.GetBool("sign_requests"); !found {
+			validation.Add("sign_requests", "must be true or false")
+		}
+	}
+
+	if sessionDuration, found := config.GetString("session_duration"); found {
+		if _, err := common.ParseDuration(sessionDuration); err != nil {
+			validation.Add("session_duration", "is not a valid duration: %v", err)
+		}
+	}
+
+	return validation.ErrorOrNil()
 }
 
-func (p *samlProvider) Initialize(identifier string, provider models.Provider) error {
+func (p *samlProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
 		provider,
@@ -69,7 +121,7 @@ func (p *samlProvider) Initialize(identifier string, provider models.Provider) e
 		return fmt.Errorf("invalid IdP metadata URL: %w", err)
 	}
 
-	idpMetadata, err := samlsp.FetchMetadata(context.Background(), http.DefaultClient, *idpMetadataURL)
+	idpMetadata, err := samlsp.FetchMetadata(ctx, http.DefaultClient, *idpMetadataURL)
 	if err != nil {
 		return fmt.Errorf("failed to fetch IdP metadata: %w", err)
 	}
@@ -96,8 +148,10 @@ func (p *samlProvider) Initialize(identifier string, provider models.Provider) e
 	p.middleware = samlSP
 	p.idpMetadata = idpMetadata
 	p.certificates = []tls.Certificate{keyPair}
+	p.sessionDuration = config.SessionDuration
+	p.attributeMapping = config.AttributeMapping
 
-	logrus.Infof("SAML provider %s initialized successfully", provider.Name)
+	p.GetLogger(ctx).Info("SAML provider initialized successfully")
 	return nil
 }
 
@@ -106,13 +160,26 @@ func (p *samlProvider) AuthorizeSession(ctx context.Context, authRequest *models
 		return nil, fmt.Errorf("SAML provider not initialized")
 	}
 
-	// Generate a SAML authentication request URL using the correct API
-	// Use MakeRedirectAuthenticationRequest for redirect binding
-	authURL, err := p.middleware.ServiceProvider.MakeRedirectAuthenticationRequest(authRequest.State)
+	serviceProvider := &p.middleware.ServiceProvider
+
+	// Generate a SAML authentication request for the redirect binding. The
+	// request ID is kept to validate the response's InResponseTo
+	request, err := serviceProvider.MakeAuthenticationRequest(
+		serviceProvider.GetSSOBindingLocation(saml.HTTPRedirectBinding),
+		saml.HTTPRedirectBinding,
+		saml.HTTPPostBinding,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create SAML authentication request: %w", err)
 	}
 
+	authURL, err := request.Redirect(authRequest.State, serviceProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SAML authentication request: %w", err)
+	}
+
+	p.addRequestID(request.ID)
+
 	return &models.AuthorizeSessionResponse{
 		Url: authURL.String(),
 	}, nil
@@ -123,21 +190,28 @@ func (p *samlProvider) CreateSession(ctx context.Context, authRequest *models.Au
 		return nil, fmt.Errorf("SAML provider not initialized")
 	}
 
-	// In a real implementation, this would parse the SAML response from the authorization code
-	// For now, we'll create a basic session structure
-	// The authRequest.Code should contain the SAML response or a reference to it
+	// The authRequest.Code contains the base64 encoded SAML response
 
 	if len(authRequest.Code) == 0 {
 		return nil, fmt.Errorf("no SAML response code provided")
 	}
 
-	// Extract user information from SAML assertion
-	// This is a simplified implementation - in practice you'd parse the actual SAML response
-	user := &models.User{
-		Username: "saml_user",        // Extract from SAML assertion
-		Email:    "user@example.com", // Extract from SAML assertion
-		Source:   "saml",
-		Groups:   []string{}, // Extract groups from SAML assertion
+	responseXML, err := base64.StdEncoding.DecodeString(authRequest.Code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode SAML response: %w", err)
+	}
+
+	serviceProvider := &p.middleware.ServiceProvider
+
+	assertion, err := serviceProvider.ParseXMLResponse(
+		responseXML, p.getRequestIDs(), serviceProvider.AcsURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate SAML response: %w", err)
+	}
+
+	user, err := extractUserFromAssertion(assertion, p.attributeMapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user from SAML assertion: %w", err)
 	}
 
 	// Create session
@@ -146,10 +220,10 @@ func (p *samlProvider) CreateSession(ctx context.Context, authRequest *models.Au
 		User:         user,
 		AccessToken:  uuid.New().String(), // Generate or extract from SAML
 		RefreshToken: uuid.New().String(),
-		Expiry:       time.Now().Add(24 * time.Hour), // Configurable session duration
+		Expiry:       time.Now().Add(p.getSessionDuration()),
 	}
 
-	logrus.Infof("Created SAML session for user: %s", user.Username)
+	p.GetLogger(ctx).WithField("user", user.Username).Info("Created SAML session")
 	return session, nil
 }
 
@@ -173,7 +247,7 @@ func (p *samlProvider) ValidateSession(ctx context.Context, session *models.Sess
 		return fmt.Errorf("session user is nil")
 	}
 
-	logrus.Debugf("SAML session validated for user: %s", session.User.Username)
+	p.GetLogger(ctx).WithField("user", session.User.Username).Debug("SAML session validated")
 	return nil
 }
 
@@ -194,54 +268,13 @@ func (p *samlProvider) RenewSession(ctx context.Context, session *models.Session
 		User:         session.User,
 		AccessToken:  uuid.New().String(),
 		RefreshToken: uuid.New().String(),
-		Expiry:       time.Now().Add(24 * time.Hour),
+		Expiry:       time.Now().Add(p.getSessionDuration()),
 	}
 
-	logrus.Infof("Renewed SAML session for user: %s", session.User.Username)
+	p.GetLogger(ctx).WithField("user", session.User.Username).Info("Renewed SAML session")
 	return newSession, nil
 }
 
-// Authorize grants access for a user to a role
-func (p *samlProvider) AuthorizeRole(
-	ctx context.Context,
-	req *models.AuthorizeRoleRequest,
-) (*models.AuthorizeRoleResponse, error) {
-
-	if !req.IsValid() {
-		return nil, fmt.Errorf("user and role must be provided to authorize azure role")
-	}
-
-	user := req.GetUser()
-	role := req.GetRole()
-
-	// Check if user has permission for the role
-	if !role.HasPermission(user) {
-		return nil, fmt.Errorf("user %s does not have permission for role %s", user.Username, role.Name)
-	}
-
-	logrus.Infof("SAML authorization granted for user %s to role %s", user.Username, role.Name)
-	return nil, nil
-}
-
-// Revoke removes access for a user from a role
-func (p *samlProvider) RevokeRole(
-	ctx context.Context,
-	req *models.RevokeRoleRequest,
-) (*models.RevokeRoleResponse, error) {
-
-	if !req.IsValid() {
-		return nil, fmt.Errorf("user and role must be provided to authorize azure role")
-	}
-
-	user := req.GetUser()
-	role := req.GetRole()
-
-	// In SAML, revocation typically happens at the IdP level
-	// This is a placeholder for any local cleanup
-	logrus.Infof("SAML access revoked for user %s from role %s", user.Username, role.Name)
-	return nil, nil
-}
-
 func (p *samlProvider) GetPermission(ctx context.Context, permission string) (*models.ProviderPermission, error) {
 	// SAML permissions are typically defined at the IdP level
 	// This would require integration with the IdP's permission system
@@ -283,11 +316,6 @@ func (p *samlProvider) ValidateRole(
 	return nil, nil
 }
 
-// SendNotification is required by ProviderNotifier interface
-func (p *samlProvider) SendNotification(ctx context.Context, notification models.NotificationRequest) error {
-	return fmt.Errorf("SendNotification not implemented for SAML provider")
-}
-
 // parseSAMLConfig parses the SAML configuration from the provider config
 func (p *samlProvider) parseSAMLConfig(config *models.BasicConfig) (*SAMLConfig, error) {
 	if config == nil {
@@ -334,9 +362,74 @@ func (p *samlProvider) parseSAMLConfig(config *models.BasicConfig) (*SAMLConfig,
 		samlConfig.SignRequests = false // Default to false
 	}
 
+	samlConfig.SessionDuration = DefaultSessionDuration
+
+	switch sessionDuration := (*config)["session_duration"].(type) {
+	case nil:
+	case string:
+		duration, err := common.ParseDuration(sessionDuration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid session_duration: %w", err)
+		}
+		samlConfig.SessionDuration = duration
+	case int:
+		samlConfig.SessionDuration = time.Duration(sessionDuration) * time.Second
+	case float64:
+		samlConfig.SessionDuration = time.Duration(sessionDuration * float64(time.Second))
+	default:
+		return nil, fmt.Errorf("invalid session_duration: %v", sessionDuration)
+	}
+
+	if samlConfig.SessionDuration <= 0 {
+		return nil, fmt.Errorf("session_duration must be greater than zero")
+	}
+
+	attributeMapping, err := parseAttributeMapping((*config)["attribute_mapping"])
+	if err != nil {
+		return nil, err
+	}
+	samlConfig.AttributeMapping = attributeMapping
+
 	return samlConfig, nil
 }
 
+func (p *samlProvider) getSessionDuration() time.Duration {
+	if p.sessionDuration > 0 {
+		return p.sessionDuration
+	}
+	return DefaultSessionDuration
+}
+
+func (p *samlProvider) addRequestID(id string) {
+	p.requestMu.Lock()
+	defer p.requestMu.Unlock()
+
+	if p.requestIDs == nil {
+		p.requestIDs = map[string]time.Time{}
+	}
+
+	p.requestIDs[id] = time.Now().Add(requestIDTTL)
+}
+
+// getRequestIDs returns the IDs of requests that haven't expired
+func (p *samlProvider) getRequestIDs() []string {
+	p.requestMu.Lock()
+	defer p.requestMu.Unlock()
+
+	now := time.Now()
+	ids := make([]string, 0, len(p.requestIDs))
+
+	for id, expiresAt := range p.requestIDs {
+		if now.After(expiresAt) {
+			delete(p.requestIDs, id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
 func init() {
 	providers.Register(SamlProviderName, &samlProvider{})
 }
@@ -2,6 +2,8 @@ package saml
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -55,6 +57,96 @@ func TestSAMLProvider_BasicFunctionality(t *testing.T) {
 	if !samlConfig.SignRequests {
 		t.Error("SignRequests not parsed correctly")
 	}
+
+	if samlConfig.SessionDuration != DefaultSessionDuration {
+		t.Errorf("Expected default session duration, got %v", samlConfig.SessionDuration)
+	}
+}
+
+func TestSAMLProvider_ValidateConfig(t *testing.T) {
+	provider := &samlProvider{}
+
+	err := provider.ValidateConfig(&models.BasicConfig{
+		"idp_metadata_url": "example.com/metadata",
+		"session_duration": "forever",
+	})
+
+	var validationErr *models.ConfigValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a config validation error, got %v", err)
+	}
+
+	fields := []string{}
+	for _, field := range validationErr.Fields {
+		fields = append(fields, field.Field)
+	}
+
+	// Every problem is reported, not just the first
+	expected := []string{"entity_id", "root_url", "cert_file", "key_file", "idp_metadata_url", "session_duration"}
+	if !slices.Equal(fields, expected) {
+		t.Errorf("Expected fields %v, got %v", expected, fields)
+	}
+
+	err = provider.ValidateConfig(&models.BasicConfig{
+		"idp_metadata_url": "https://example.com/metadata",
+		"entity_id":        "https://myapp.com/saml",
+		"root_url":         "https://myapp.com",
+		"cert_file":        "/path/to/cert.pem",
+		"key_file":         "/path/to/key.pem",
+		"session_duration": "8h",
+	})
+	if err != nil {
+		t.Errorf("Unexpected error for valid config: %v", err)
+	}
+}
+
+func TestSAMLProvider_SessionDuration(t *testing.T) {
+	provider := &samlProvider{}
+
+	tests := []struct {
+		value    any
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"8h", 8 * time.Hour, false},
+		{"PT12H", 12 * time.Hour, false},
+		{"2 days", 48 * time.Hour, false},
+		{3600, time.Hour, false},
+		{float64(7200), 2 * time.Hour, false},
+		{"0", 0, true},
+		{"-1h", 0, true},
+		{"forever", 0, true},
+		{0, 0, true},
+	}
+
+	for _, tt := range tests {
+		config := &models.BasicConfig{
+			"idp_metadata_url": "https://example.com/metadata",
+			"entity_id":        "https://myapp.com/saml",
+			"root_url":         "https://myapp.com",
+			"cert_file":        "/path/to/cert.pem",
+			"key_file":         "/path/to/key.pem",
+			"session_duration": tt.value,
+		}
+
+		samlConfig, err := provider.parseSAMLConfig(config)
+
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Expected error for session_duration %v", tt.value)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Unexpected error for session_duration %v: %v", tt.value, err)
+			continue
+		}
+
+		if samlConfig.SessionDuration != tt.expected {
+			t.Errorf("session_duration %v = %v, expected %v", tt.value, samlConfig.SessionDuration, tt.expected)
+		}
+	}
 }
 
 func TestSAMLProvider_SessionValidation(t *testing.T) {
@@ -103,52 +195,23 @@ func TestSAMLProvider_SessionValidation(t *testing.T) {
 	}
 }
 
-func TestSAMLProvider_Authorization(t *testing.T) {
-	provider := &samlProvider{}
-	ctx := context.Background()
-
-	user := &models.User{
-		Username: "testuser",
-		Email:    "test@example.com",
+func TestSAMLProvider_Capabilities(t *testing.T) {
+	provider := &samlProvider{
+		BaseProvider: models.NewBaseProvider("saml", models.Provider{Name: "saml"},
+			models.ProviderCapabilityAuthorizer),
 	}
 
-	// Create a mock role that has permission for the user
-	role := &models.Role{
-		Name: "test-role",
-		// Note: In a real test, you'd need to set up proper role permissions
+	// SAML only signs users in, access is granted at the IdP
+	if _, err := models.AsAuthorizor(provider); err != nil {
+		t.Errorf("Expected SAML provider to support authentication: %v", err)
 	}
 
-	// Test authorization with nil user
-	_, err := provider.AuthorizeRole(ctx, &models.AuthorizeRoleRequest{
-		RoleRequest: &models.RoleRequest{
-			User: nil,
-			Role: role,
-		},
-	})
-	if err == nil {
-		t.Error("Expected error for nil user")
+	if _, err := models.AsRoleBasedAccessControl(provider); !errors.Is(err, models.ErrCapabilityNotSupported) {
+		t.Errorf("Expected capability error for role management, got %v", err)
 	}
 
-	// Test authorization with nil role
-	_, err = provider.AuthorizeRole(ctx, &models.AuthorizeRoleRequest{
-		RoleRequest: &models.RoleRequest{
-			User: user,
-			Role: nil,
-		},
-	})
-	if err == nil {
-		t.Error("Expected error for nil role")
-	}
-
-	// Test revocation
-	_, err = provider.RevokeRole(ctx, &models.RevokeRoleRequest{
-		RoleRequest: &models.RoleRequest{
-			User: user,
-			Role: role,
-		},
-	})
-	if err != nil {
-		t.Errorf("Unexpected error in revocation: %v", err)
+	if _, err := models.AsNotifier(provider); !errors.Is(err, models.ErrCapabilityNotSupported) {
+		t.Errorf("Expected capability error for notifications, got %v", err)
 	}
 }
 
@@ -195,9 +258,4 @@ func TestSAMLProvider_NotImplementedMethods(t *testing.T) {
 	if len(resources) != 0 {
 		t.Error("ListResources should return empty list")
 	}
-
-	err = provider.SendNotification(ctx, models.NotificationRequest{})
-	if err == nil {
-		t.Error("Expected not implemented error for SendNotification")
-	}
 }
@@ -0,0 +1,140 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// publishHome renders the app home tab with the user's active and pending
+// elevations
+func (r *socketModeRunner) publishHome(ctx context.Context, slackUserID string) {
+
+	log := r.provider.GetLogger(ctx)
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(newPlainText("Your access")),
+		slack.NewActionBlock("",
+			slack.NewButtonBlockElement(requestActionID, "", newPlainText("Request access")).
+				WithStyle(slack.StylePrimary),
+		),
+		slack.NewDividerBlock(),
+	}
+
+	user, err := r.resolveUser(ctx, slackUserID)
+
+	if err != nil {
+		log.WithError(err).WithField("slack_user", slackUserID).
+			Warnln("Failed to resolve Slack user for app home")
+
+		blocks = append(blocks, newMarkdownSection(
+			"We couldn't match your Slack account to a Thand identity. Check your Slack email matches your identity provider."))
+
+	} else {
+
+		elevations, err := r.handler.ListElevations(ctx, user)
+
+		if err != nil {
+			log.WithError(err).WithField("user", user.Email).
+				Warnln("Failed to list elevations for app home")
+
+			blocks = append(blocks, newMarkdownSection(
+				"Your elevations could not be loaded right now, please try again later."))
+
+		} else {
+			blocks = append(blocks, buildElevationBlocks(elevations)...)
+		}
+	}
+
+	_, err = r.provider.client.PublishViewContext(ctx, slack.PublishViewContextRequest{
+		UserID: slackUserID,
+		View: slack.HomeTabViewRequest{
+			Type:   slack.VTHomeTab,
+			Blocks: slack.Blocks{BlockSet: blocks},
+		},
+	})
+
+	if err != nil {
+		log.WithError(err).WithField("slack_user", slackUserID).
+			Errorln("Failed to publish Slack app home")
+	}
+}
+
+// buildElevationBlocks splits running elevations into active ones that have
+// been approved and pending ones still waiting on a decision
+func buildElevationBlocks(elevations []*models.WorkflowExecutionInfo) []slack.Block {
+
+	active := []*models.WorkflowExecutionInfo{}
+	pending := []*models.WorkflowExecutionInfo{}
+
+	for _, elevation := range elevations {
+		if elevation.Approved != nil && *elevation.Approved {
+			active = append(active, elevation)
+		} else if elevation.Approved == nil {
+			pending = append(pending, elevation)
+		}
+	}
+
+	blocks := []slack.Block{
+		newMarkdownSection("*Active grants*"),
+	}
+
+	if len(active) == 0 {
+		blocks = append(blocks, newContextBlock("You have no active grants."))
+	}
+
+	for _, elevation := range active {
+		blocks = append(blocks, newElevationSection(elevation))
+	}
+
+	blocks = append(blocks, slack.NewDividerBlock(), newMarkdownSection("*Pending requests*"))
+
+	if len(pending) == 0 {
+		blocks = append(blocks, newContextBlock("You have no pending requests."))
+	}
+
+	for _, elevation := range pending {
+		blocks = append(blocks, newElevationSection(elevation))
+	}
+
+	return blocks
+}
+
+func newElevationSection(elevation *models.WorkflowExecutionInfo) slack.Block {
+
+	var text strings.Builder
+
+	text.WriteString(fmt.Sprintf("*%s*", elevation.Role))
+
+	if len(elevation.Providers) > 0 {
+		text.WriteString(fmt.Sprintf(" on %s", strings.Join(elevation.Providers, ", ")))
+	}
+
+	text.WriteString(fmt.Sprintf("\nRequested <!date^%d^{date_short_pretty} at {time}|%s>",
+		elevation.StartTime.Unix(), elevation.StartTime.UTC().Format(time.RFC822)))
+
+	if elevation.Duration > 0 {
+		text.WriteString(fmt.Sprintf(" for %s", common.FormatDuration(time.Duration(elevation.Duration)*time.Second)))
+	}
+
+	if len(elevation.Reason) > 0 {
+		text.WriteString(fmt.Sprintf("\n_%s_", elevation.Reason))
+	}
+
+	return newMarkdownSection(text.String())
+}
+
+func newMarkdownSection(text string) *slack.SectionBlock {
+	return slack.NewSectionBlock(
+		slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil)
+}
+
+func newContextBlock(text string) *slack.ContextBlock {
+	return slack.NewContextBlock("",
+		slack.NewTextBlockObject(slack.MarkdownType, text, false, false))
+}
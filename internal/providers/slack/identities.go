@@ -0,0 +1,256 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+)
+
+const (
+	IdentitySourceSlack = "slack"
+
+	// DefaultIdentitiesCacheTTL is how long the workspace members are cached
+	// before users.list is called again
+	DefaultIdentitiesCacheTTL = time.Hour
+
+	// usersPageSize is the users.list page size Slack recommends
+	usersPageSize = 200
+)
+
+// identityCache tracks when the workspace members were last listed
+type identityCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	fetchedAt time.Time
+}
+
+func (c *identityCache) isFresh() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < c.ttl
+}
+
+// initializeIdentities reads the identities configuration. Identities are
+// opt in as listing every workspace member is expensive.
+func (p *slackProvider) initializeIdentities(slackConfig *models.BasicConfig) (bool, error) {
+
+	enabled, found := slackConfig.GetBool("identities")
+
+	if !found || !enabled {
+		return false, nil
+	}
+
+	ttl := DefaultIdentitiesCacheTTL
+
+	if configuredTTL, found := slackConfig.GetString("identities_cache_ttl"); found && len(configuredTTL) > 0 {
+		parsed, err := common.ValidateDuration(configuredTTL)
+		if err != nil {
+			return false, fmt.Errorf("invalid Slack identities_cache_ttl configuration: %w", err)
+		}
+		ttl = parsed
+	}
+
+	p.identities = &identityCache{ttl: ttl}
+
+	return true, nil
+}
+
+// ListIdentities returns the workspace members. The members are listed from
+// Slack when the cache has expired and the search is matched locally.
+func (p *slackProvider) ListIdentities(ctx context.Context, searchRequest *models.SearchRequest) ([]models.SearchResult[models.Identity], error) {
+
+	if p.identities == nil {
+		return p.BaseProvider.ListIdentities(ctx, searchRequest)
+	}
+
+	if err := p.refreshIdentities(ctx); err != nil {
+		return nil, err
+	}
+
+	return p.BaseProvider.ListIdentities(ctx, searchRequest)
+}
+
+// GetIdentity returns a member by Slack user ID or email. Members that
+// aren't cached are looked up with users.info or users.lookupByEmail.
+func (p *slackProvider) GetIdentity(ctx context.Context, identity string) (*models.Identity, error) {
+
+	if found, err := p.BaseProvider.GetIdentity(ctx, identity); err == nil {
+		return found, nil
+	} else if p.identities == nil {
+		return nil, err
+	}
+
+	found, err := p.lookupIdentity(ctx, strings.TrimSpace(identity))
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.AddIdentities(*found)
+
+	return found, nil
+}
+
+func (p *slackProvider) lookupIdentity(ctx context.Context, identity string) (*models.Identity, error) {
+
+	var user *slack.User
+	var err error
+
+	switch {
+	case strings.Contains(identity, "@"):
+		user, err = p.client.GetUserByEmailContext(ctx, identity)
+	case isSlackUserID(identity):
+		user, err = p.client.GetUserInfoContext(ctx, identity)
+	default:
+		return nil, fmt.Errorf("identity not found: %s", identity)
+	}
+
+	if err != nil {
+		if isSlackNotFoundError(err) {
+			return nil, fmt.Errorf("identity not found: %s", identity)
+		}
+		return nil, fmt.Errorf("failed to get Slack user %s: %w", identity, err)
+	}
+
+	found := newSlackUserIdentity(*user)
+
+	if found == nil {
+		return nil, fmt.Errorf("identity not found: %s", identity)
+	}
+
+	return found, nil
+}
+
+// refreshIdentities replaces the cached members if the cache has expired
+func (p *slackProvider) refreshIdentities(ctx context.Context) error {
+
+	log := p.GetLogger(ctx)
+
+	if p.identities.isFresh() {
+		return nil
+	}
+
+	startTime := time.Now()
+
+	identities, err := p.listUserIdentities(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	p.SetIdentities(identities)
+
+	p.identities.mu.Lock()
+	p.identities.fetchedAt = time.Now()
+	p.identities.mu.Unlock()
+
+	log.WithFields(logrus.Fields{
+		"provider":   p.GetIdentifier(),
+		"identities": len(identities),
+	}).Debugf("Listed Slack members in %s", time.Since(startTime))
+
+	return nil
+}
+
+// listUserIdentities pages through users.list. Rate limited pages are
+// retried after the delay Slack asks for.
+func (p *slackProvider) listUserIdentities(ctx context.Context) ([]models.Identity, error) {
+
+	log := p.GetLogger(ctx)
+
+	var identities []models.Identity
+
+	page := p.client.GetUsersPaginated(slack.GetUsersOptionLimit(usersPageSize))
+
+	for {
+
+		next, err := page.Next(ctx)
+
+		if page.Done(err) {
+			return identities, nil
+		}
+
+		var rateLimitedError *slack.RateLimitedError
+
+		if errors.As(err, &rateLimitedError) {
+
+			log.WithField("retry_after", rateLimitedError.RetryAfter).
+				Debug("Slack users.list rate limited, retrying")
+
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(rateLimitedError.RetryAfter):
+				continue
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Slack users: %w", err)
+		}
+
+		for _, user := range next.Users {
+			if identity := newSlackUserIdentity(user); identity != nil {
+				identities = append(identities, *identity)
+			}
+		}
+
+		page = next
+	}
+}
+
+// newSlackUserIdentity maps a member to an identity. Deleted members and
+// bots aren't identities.
+func newSlackUserIdentity(user slack.User) *models.Identity {
+
+	if len(user.ID) == 0 || user.Deleted || user.IsBot || user.ID == "USLACKBOT" {
+		return nil
+	}
+
+	name := user.RealName
+
+	if len(name) == 0 {
+		name = user.Profile.RealName
+	}
+
+	if len(name) == 0 {
+		name = user.Name
+	}
+
+	return &models.Identity{
+		ID:    user.ID,
+		Label: name,
+		User: &models.User{
+			ID:       user.ID,
+			Username: user.Name,
+			Email:    user.Profile.Email,
+			Name:     name,
+			Source:   IdentitySourceSlack,
+		},
+	}
+}
+
+// isSlackUserID returns true for user IDs e.g. U0123ABCD, or W0123ABCD
+// for Enterprise Grid
+func isSlackUserID(identity string) bool {
+	return len(identity) > 1 &&
+		(identity[0] == 'U' || identity[0] == 'W') &&
+		strings.ToUpper(identity) == identity
+}
+
+func isSlackNotFoundError(err error) bool {
+	var slackErr slack.SlackErrorResponse
+	if errors.As(err, &slackErr) {
+		return slackErr.Err == "users_not_found" || slackErr.Err == "user_not_found"
+	}
+	return false
+}
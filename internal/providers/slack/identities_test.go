@@ -0,0 +1,244 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func newSlackMember(id, name, realName, email string) map[string]any {
+	return map[string]any{
+		"id":        id,
+		"name":      name,
+		"real_name": realName,
+		"profile": map[string]any{
+			"real_name": realName,
+			"email":     email,
+		},
+	}
+}
+
+// newTestSlackAPI serves users.list in two pages, rate limiting the first
+// request, and the users.info and users.lookupByEmail lookups
+func newTestSlackAPI(t *testing.T, listCalls *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	rateLimited := atomic.Bool{}
+
+	deleted := newSlackMember("U0000DEL", "gone", "Gone User", "gone@example.com")
+	deleted["deleted"] = true
+
+	bot := newSlackMember("B0000BOT", "deploybot", "Deploy Bot", "")
+	bot["is_bot"] = true
+
+	writeJSON := func(w http.ResponseWriter, body map[string]any) {
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(body))
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+
+		if !rateLimited.Swap(true) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		listCalls.Add(1)
+
+		if r.Form.Get("cursor") == "" {
+			writeJSON(w, map[string]any{
+				"ok": true,
+				"members": []any{
+					newSlackMember("U0000ALICE", "alice", "Alice Smith", "alice@example.com"),
+					bot,
+				},
+				"response_metadata": map[string]any{"next_cursor": "page2"},
+			})
+			return
+		}
+
+		writeJSON(w, map[string]any{
+			"ok": true,
+			"members": []any{
+				newSlackMember("U0000BOB", "bob", "Bob Jones", "bob@example.com"),
+				deleted,
+			},
+			"response_metadata": map[string]any{"next_cursor": ""},
+		})
+	})
+
+	mux.HandleFunc("/users.info", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+
+		if r.Form.Get("user") != "U0000CAROL" {
+			writeJSON(w, map[string]any{"ok": false, "error": "user_not_found"})
+			return
+		}
+
+		writeJSON(w, map[string]any{
+			"ok":   true,
+			"user": newSlackMember("U0000CAROL", "carol", "Carol White", "carol@example.com"),
+		})
+	})
+
+	mux.HandleFunc("/users.lookupByEmail", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+
+		if r.Form.Get("email") != "dave@example.com" {
+			writeJSON(w, map[string]any{"ok": false, "error": "users_not_found"})
+			return
+		}
+
+		writeJSON(w, map[string]any{
+			"ok":   true,
+			"user": newSlackMember("U0000DAVE", "dave", "Dave Brown", "dave@example.com"),
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestSlackIdentityProvider(t *testing.T, server *httptest.Server) *slackProvider {
+	t.Helper()
+
+	provider := &slackProvider{
+		BaseProvider: models.NewBaseProvider("slack", models.Provider{
+			Name:     "Slack",
+			Provider: SlackProviderName,
+		}, models.ProviderCapabilityNotifier, models.ProviderCapabilityIdentities),
+		client:     slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/")),
+		identities: &identityCache{ttl: time.Hour},
+	}
+
+	return provider
+}
+
+func TestSlackListIdentities(t *testing.T) {
+	var listCalls atomic.Int32
+	server := newTestSlackAPI(t, &listCalls)
+	provider := newTestSlackIdentityProvider(t, server)
+	ctx := context.Background()
+
+	results, err := provider.ListIdentities(ctx, nil)
+	require.NoError(t, err)
+
+	var ids []string
+	for _, result := range results {
+		ids = append(ids, result.Result.ID)
+	}
+
+	// Deleted members and bots are filtered out
+	assert.ElementsMatch(t, []string{"U0000ALICE", "U0000BOB"}, ids)
+	assert.Equal(t, int32(2), listCalls.Load())
+
+	alice, err := provider.GetIdentity(ctx, "alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "U0000ALICE", alice.ID)
+	assert.Equal(t, "Alice Smith", alice.Label)
+	require.NotNil(t, alice.User)
+	assert.Equal(t, "alice", alice.User.Username)
+	assert.Equal(t, IdentitySourceSlack, alice.User.Source)
+
+	t.Run("search is matched locally", func(t *testing.T) {
+		results, err := provider.ListIdentities(ctx, &models.SearchRequest{
+			Terms: []string{"bob"},
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.Equal(t, "U0000BOB", results[0].Result.ID)
+
+		// The listing is cached
+		assert.Equal(t, int32(2), listCalls.Load())
+	})
+
+	t.Run("expired cache is refreshed", func(t *testing.T) {
+		provider.identities.mu.Lock()
+		provider.identities.fetchedAt = time.Now().Add(-2 * time.Hour)
+		provider.identities.mu.Unlock()
+
+		_, err := provider.ListIdentities(ctx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(4), listCalls.Load())
+	})
+}
+
+func TestSlackGetIdentity(t *testing.T) {
+	var listCalls atomic.Int32
+	server := newTestSlackAPI(t, &listCalls)
+	provider := newTestSlackIdentityProvider(t, server)
+	ctx := context.Background()
+
+	t.Run("user ID uses users.info", func(t *testing.T) {
+		identity, err := provider.GetIdentity(ctx, "U0000CAROL")
+		require.NoError(t, err)
+		assert.Equal(t, "Carol White", identity.Label)
+		assert.Equal(t, "carol@example.com", identity.User.Email)
+	})
+
+	t.Run("email uses users.lookupByEmail", func(t *testing.T) {
+		identity, err := provider.GetIdentity(ctx, "dave@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "U0000DAVE", identity.ID)
+	})
+
+	t.Run("unknown identities are not found", func(t *testing.T) {
+		_, err := provider.GetIdentity(ctx, "nobody@example.com")
+		assert.ErrorContains(t, err, "identity not found")
+
+		_, err = provider.GetIdentity(ctx, "U0000NOBODY")
+		assert.ErrorContains(t, err, "identity not found")
+
+		_, err = provider.GetIdentity(ctx, "not-an-id")
+		assert.ErrorContains(t, err, "identity not found")
+	})
+
+	// Lookups don't enumerate the workspace
+	assert.Equal(t, int32(0), listCalls.Load())
+}
+
+func TestSlackInitializeIdentities(t *testing.T) {
+
+	t.Run("disabled by default", func(t *testing.T) {
+		provider := &slackProvider{}
+		enabled, err := provider.initializeIdentities(&models.BasicConfig{})
+		require.NoError(t, err)
+		assert.False(t, enabled)
+		assert.Nil(t, provider.identities)
+	})
+
+	t.Run("enabled with cache TTL", func(t *testing.T) {
+		provider := &slackProvider{}
+		enabled, err := provider.initializeIdentities(&models.BasicConfig{
+			"identities":           true,
+			"identities_cache_ttl": "15m",
+		})
+		require.NoError(t, err)
+		assert.True(t, enabled)
+		assert.Equal(t, 15*time.Minute, provider.identities.ttl)
+	})
+
+	t.Run("invalid cache TTL", func(t *testing.T) {
+		provider := &slackProvider{}
+		_, err := provider.initializeIdentities(&models.BasicConfig{
+			"identities":           true,
+			"identities_cache_ttl": "soon",
+		})
+		assert.Error(t, err)
+	})
+}
@@ -2,8 +2,10 @@ package slack
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/slack-go/slack"
@@ -11,6 +13,7 @@ import (
 	"github.com/thand-io/agent/internal/models"
 	"github.com/thand-io/agent/internal/providers"
 	"go.temporal.io/sdk/temporal"
+	"golang.org/x/sync/errgroup"
 )
 
 const SlackProviderName = "slack"
@@ -19,13 +22,50 @@ const SlackProviderName = "slack"
 type slackProvider struct {
 	*models.BaseProvider
 	client *slack.Client
+
+	// Optional socket mode for the app home and /thand command
+	appToken string
+	socket   *socketModeRunner
+
+	// Set when the workspace members are used as identities
+	identities *identityCache
 }
 
-func (p *slackProvider) Initialize(identifier string, provider models.Provider) error {
+// ValidateConfig checks the bot token is set and the app token, if set, is
+// an app-level token
+func (p *slackProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(SlackProviderName)
+	validation.Require(config, "bot_token")
+
+	if appToken, found := config.GetString("app_token"); found && len(appToken) > 0 {
+		if !strings.HasPrefix(appToken, "xapp-") {
+			validation.Add("app_token", "must be an app-level token (xapp-...)")
+		}
+	}
+
+	return validation.ErrorOrNil()
+}
+
+func (p *slackProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
+
+	capabilities := []models.ProviderCapability{
+		models.ProviderCapabilityNotifier,
+	}
+
+	identitiesEnabled, err := p.initializeIdentities(provider.Config)
+	if err != nil {
+		return err
+	}
+
+	if identitiesEnabled {
+		capabilities = append(capabilities, models.ProviderCapabilityIdentities)
+	}
+
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
 		provider,
-		models.ProviderCapabilityNotifier,
+		capabilities...,
 	)
 
 	slackConfig := p.GetConfig()
@@ -35,16 +75,46 @@ func (p *slackProvider) Initialize(identifier string, provider models.Provider)
 		return fmt.Errorf("missing Slack bot_token configuration")
 	}
 
+	options := []slack.Option{}
+
+	// The app token is only required for socket mode
+	appToken, foundAppToken := slackConfig.GetString("app_token")
+	if foundAppToken && len(appToken) > 0 {
+		if !strings.HasPrefix(appToken, "xapp-") {
+			return fmt.Errorf("invalid Slack app_token configuration, expected an app-level token (xapp-...)")
+		}
+		p.appToken = appToken
+		options = append(options, slack.OptionAppLevelToken(appToken))
+	}
+
 	// Initialize Slack client
-	p.client = slack.New(token)
+	p.client = slack.New(token, options...)
 
-	// Optional: Test the connection
-	_, err := p.client.AuthTest()
-	if err != nil {
-		return fmt.Errorf("failed to authenticate with Slack: %w", err)
+	return p.TestConnection(ctx, models.ProviderCapabilityNotifier)
+}
+
+// TestConnection verifies the bot token without sending anything, or
+// fetches one page of members for identities
+func (p *slackProvider) TestConnection(ctx context.Context, capability models.ProviderCapability) error {
+	switch capability {
+	case models.ProviderCapabilityNotifier:
+		_, err := p.client.AuthTestContext(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to authenticate with Slack: %w", err)
+		}
+		return nil
+	case models.ProviderCapabilityIdentities:
+		if p.identities == nil {
+			return models.ErrNotImplemented
+		}
+		_, err := p.client.GetUsersPaginated(slack.GetUsersOptionLimit(1)).Next(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list Slack users: %w", err)
+		}
+		return nil
 	}
 
-	return nil
+	return models.ErrNotImplemented
 }
 
 type SlackNotificationRequest struct {
@@ -53,42 +123,40 @@ type SlackNotificationRequest struct {
 	Blocks slack.Blocks `json:"blocks"`
 
 	Attachments []slack.Attachment `json:"attachments,omitempty"`
+
+	// Recipients are sent the same message alongside the channel e.g. a
+	// team channel and the requestor's DM
+	Recipients []string `json:"recipients,omitempty"`
 }
 
-func (p *slackProvider) SendNotification(ctx context.Context, notification models.NotificationRequest) error {
-	// Convert NotificationRequest to SlackNotificationRequest
-	slackRequest := &SlackNotificationRequest{}
-	common.ConvertMapToInterface(notification, slackRequest)
+// GetRecipients returns the channel and recipients without duplicates
+func (r *SlackNotificationRequest) GetRecipients() []string {
 
-	// Validate required fields
-	if len(slackRequest.To) == 0 {
-		return fmt.Errorf("to is required for Slack notification")
-	}
+	recipients := []string{}
+	seen := map[string]bool{}
 
-	if strings.HasPrefix(slackRequest.To, "#") {
-		// Lookup channel ID using the channel name via the API
-		return fmt.Errorf("channel name lookup not implemented, please provide a Channel ID (C...)")
-	} else if strings.HasPrefix(slackRequest.To, "@") {
-		// Lookup user ID using the user name via the API
-		username := strings.TrimPrefix(slackRequest.To, "@")
-		userID, err := p.getUserIDByUsername(ctx, username)
-		if err != nil {
-			return fmt.Errorf("failed to get user ID for user %s: %w", slackRequest.To, err)
-		}
-		slackRequest.To = userID
-	} else if strings.Contains(slackRequest.To, "@") {
-		// Is an email address
-		email := strings.TrimSpace(slackRequest.To)
-		user, err := p.client.GetUserByEmail(email)
-		if err != nil {
-			return fmt.Errorf("failed to get user by email: %w", err)
+	for _, recipient := range append([]string{r.To}, r.Recipients...) {
+		recipient = strings.TrimSpace(recipient)
+		if len(recipient) == 0 || seen[recipient] {
+			continue
 		}
-		slackRequest.To = user.ID
+		seen[recipient] = true
+		recipients = append(recipients, recipient)
 	}
 
-	// Now lets double check we hav a valid Channel Id or User Id for our request
-	if !strings.HasPrefix(slackRequest.To, "C") && !strings.HasPrefix(slackRequest.To, "U") {
-		return fmt.Errorf("invalid to field for Slack notification: %s expects a Channel ID (C...) or User ID (U...)", slackRequest.To)
+	return recipients
+}
+
+func (p *slackProvider) SendNotification(ctx context.Context, notification models.NotificationRequest) (*models.NotificationReceipt, error) {
+	// Convert NotificationRequest to SlackNotificationRequest
+	slackRequest := &SlackNotificationRequest{}
+	common.ConvertMapToInterface(notification, slackRequest)
+
+	recipients := slackRequest.GetRecipients()
+
+	// Validate required fields
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("to is required for Slack notification")
 	}
 
 	// Build message options
@@ -109,11 +177,78 @@ func (p *slackProvider) SendNotification(ctx context.Context, notification model
 		options = append(options, slack.MsgOptionBlocks(slackRequest.Blocks.BlockSet...))
 	}
 
+	if len(recipients) == 1 {
+		channelID, timestamp, err := p.sendMessage(ctx, recipients[0], options...)
+		if err != nil {
+			return nil, err
+		}
+		return &models.NotificationReceipt{
+			Target:    channelID,
+			MessageID: formatMessageID(channelID, timestamp),
+		}, nil
+	}
+
+	// Send to every recipient even if some fail, the failures are returned
+	// together once all the sends complete
+	var mu sync.Mutex
+	var sendErrors []error
+
+	channelIDs := make([]string, len(recipients))
+	messageIDs := make([]string, len(recipients))
+
+	group, groupCtx := errgroup.WithContext(ctx)
+
+	for index, recipient := range recipients {
+		group.Go(func() error {
+			channelID, timestamp, err := p.sendMessage(groupCtx, recipient, options...)
+			if err != nil {
+				mu.Lock()
+				sendErrors = append(sendErrors, err)
+				mu.Unlock()
+				return nil
+			}
+			channelIDs[index] = channelID
+			messageIDs[index] = formatMessageID(channelID, timestamp)
+			return nil
+		})
+	}
+
+	group.Wait()
+
+	if len(sendErrors) > 0 {
+		err := errors.Join(sendErrors...)
+		return nil, temporal.NewApplicationErrorWithOptions(
+			fmt.Sprintf("failed to send Slack message to %d of %d recipients: %v",
+				len(sendErrors), len(recipients), err),
+			"SlackNotificationError",
+			temporal.ApplicationErrorOptions{
+				NextRetryDelay: 3 * time.Second,
+				Cause:          err,
+			},
+		)
+	}
+
+	return &models.NotificationReceipt{
+		Target:    strings.Join(channelIDs, ", "),
+		MessageID: strings.Join(messageIDs, ", "),
+	}, nil
+}
+
+// sendMessage resolves the recipient to a channel or user ID and posts the
+// message to it. Returns the channel and timestamp that identify the message.
+func (p *slackProvider) sendMessage(ctx context.Context, to string, options ...slack.MsgOption) (string, string, error) {
+
+	channelID, err := p.resolveRecipient(ctx, to)
+
+	if err != nil {
+		return "", "", err
+	}
+
 	// Send the message
-	_, _, err := p.client.PostMessageContext(ctx, slackRequest.To, options...)
+	channel, timestamp, err := p.client.PostMessageContext(ctx, channelID, options...)
 	if err != nil {
-		return temporal.NewApplicationErrorWithOptions(
-			fmt.Sprintf("failed to send Slack message to %s: %v", slackRequest.To, err),
+		return "", "", temporal.NewApplicationErrorWithOptions(
+			fmt.Sprintf("failed to send Slack message to %s: %v", channelID, err),
 			"SlackNotificationError",
 			temporal.ApplicationErrorOptions{
 				NextRetryDelay: 3 * time.Second,
@@ -122,7 +257,51 @@ func (p *slackProvider) SendNotification(ctx context.Context, notification model
 		)
 	}
 
-	return nil
+	// Messages to a user are posted to their DM channel
+	if len(channel) == 0 {
+		channel = channelID
+	}
+
+	return channel, timestamp, nil
+}
+
+// formatMessageID identifies a Slack message by its channel and timestamp
+// e.g. C0123456789:1700000000.000100
+func formatMessageID(channelID string, timestamp string) string {
+	return fmt.Sprintf("%s:%s", channelID, timestamp)
+}
+
+// resolveRecipient converts a username (@name) or email address to a user
+// ID. Channel and user IDs are returned as is.
+func (p *slackProvider) resolveRecipient(ctx context.Context, to string) (string, error) {
+
+	if strings.HasPrefix(to, "#") {
+		// Lookup channel ID using the channel name via the API
+		return "", fmt.Errorf("channel name lookup not implemented, please provide a Channel ID (C...)")
+	} else if strings.HasPrefix(to, "@") {
+		// Lookup user ID using the user name via the API
+		username := strings.TrimPrefix(to, "@")
+		userID, err := p.getUserIDByUsername(ctx, username)
+		if err != nil {
+			return "", fmt.Errorf("failed to get user ID for user %s: %w", to, err)
+		}
+		to = userID
+	} else if strings.Contains(to, "@") {
+		// Is an email address
+		email := strings.TrimSpace(to)
+		user, err := p.client.GetUserByEmailContext(ctx, email)
+		if err != nil {
+			return "", fmt.Errorf("failed to get user by email: %w", err)
+		}
+		to = user.ID
+	}
+
+	// Now lets double check we hav a valid Channel Id or User Id for our request
+	if !strings.HasPrefix(to, "C") && !strings.HasPrefix(to, "U") {
+		return "", fmt.Errorf("invalid to field for Slack notification: %s expects a Channel ID (C...) or User ID (U...)", to)
+	}
+
+	return to, nil
 }
 
 // getUserIDByUsername searches for a user by username and returns their ID
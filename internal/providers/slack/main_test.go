@@ -0,0 +1,130 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// newTestSlackMessageAPI records the channels messages are posted to.
+// Posting to CFAILED fails.
+func newTestSlackMessageAPI(t *testing.T) (*slackProvider, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var channels []string
+
+	writeJSON := func(w http.ResponseWriter, body map[string]any) {
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(body))
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+
+		channel := r.Form.Get("channel")
+
+		if channel == "CFAILED" {
+			writeJSON(w, map[string]any{"ok": false, "error": "channel_not_found"})
+			return
+		}
+
+		mu.Lock()
+		channels = append(channels, channel)
+		mu.Unlock()
+
+		writeJSON(w, map[string]any{"ok": true, "channel": channel, "ts": "1.0"})
+	})
+
+	mux.HandleFunc("/users.lookupByEmail", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"ok":   true,
+			"user": newSlackMember("U0000ALICE", "alice", "Alice Smith", "alice@example.com"),
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	provider := &slackProvider{
+		client: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/")),
+	}
+
+	return provider, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, channels...)
+	}
+}
+
+func TestSlackSendNotification(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("single channel", func(t *testing.T) {
+		provider, sent := newTestSlackMessageAPI(t)
+
+		receipt, err := provider.SendNotification(ctx, models.NotificationRequest{
+			"channel": "CTEAM",
+			"text":    "hello",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"CTEAM"}, sent())
+		assert.Equal(t, "CTEAM", receipt.Target)
+		assert.Equal(t, "CTEAM:1.0", receipt.MessageID)
+	})
+
+	t.Run("channel and recipients", func(t *testing.T) {
+		provider, sent := newTestSlackMessageAPI(t)
+
+		receipt, err := provider.SendNotification(ctx, models.NotificationRequest{
+			"channel":    "CTEAM",
+			"text":       "hello",
+			"recipients": []any{"alice@example.com", "CTEAM", "UBOB"},
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"CTEAM", "U0000ALICE", "UBOB"}, sent())
+		assert.Equal(t, "CTEAM:1.0, U0000ALICE:1.0, UBOB:1.0", receipt.MessageID)
+	})
+
+	t.Run("failed recipients don't stop the others", func(t *testing.T) {
+		provider, sent := newTestSlackMessageAPI(t)
+
+		_, err := provider.SendNotification(ctx, models.NotificationRequest{
+			"text":       "hello",
+			"recipients": []any{"CFAILED", "#general", "CTEAM"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2 of 3 recipients")
+		assert.Contains(t, err.Error(), "channel_not_found")
+		assert.Contains(t, err.Error(), "channel name lookup not implemented")
+		assert.Equal(t, []string{"CTEAM"}, sent())
+	})
+
+	t.Run("no recipients", func(t *testing.T) {
+		provider, _ := newTestSlackMessageAPI(t)
+
+		_, err := provider.SendNotification(ctx, models.NotificationRequest{
+			"text": "hello",
+		})
+		assert.ErrorContains(t, err, "to is required")
+	})
+}
+
+func TestSlackNotificationRequestGetRecipients(t *testing.T) {
+	request := SlackNotificationRequest{
+		To:         "CTEAM",
+		Recipients: []string{"UALICE", " CTEAM ", "", "UALICE", "UBOB"},
+	}
+
+	assert.Equal(t, []string{"CTEAM", "UALICE", "UBOB"}, request.GetRecipients())
+}
@@ -0,0 +1,251 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+)
+
+const (
+	// Used for the global shortcut and the request modal
+	requestCallbackID = "thand_request"
+	// Used for the "Request access" button on the app home
+	requestActionID = "thand_request_access"
+
+	requestRoleBlockID     = "role"
+	requestDurationBlockID = "duration"
+	requestReasonBlockID   = "reason"
+
+	// Slack limits select menus to 100 options
+	maxRoleOptions = 100
+)
+
+// Mirrors the durations offered by the CLI request wizard
+var requestDurations = []struct {
+	Label string
+	Value string
+}{
+	{"15 minutes", "PT15M"},
+	{"30 minutes", "PT30M"},
+	{"1 hour", "PT1H"},
+	{"2 hours", "PT2H"},
+	{"4 hours", "PT4H"},
+	{"8 hours", "PT8H"},
+	{"1 day", "P1D"},
+}
+
+type requestSubmission struct {
+	Role     string
+	Duration string
+	Reason   string
+}
+
+// openRequestModal opens the request form with the roles the user can
+// request
+func (r *socketModeRunner) openRequestModal(ctx context.Context, slackUserID string, triggerID string) {
+
+	log := r.provider.GetLogger(ctx)
+
+	user, err := r.resolveUser(ctx, slackUserID)
+
+	if err != nil {
+		log.WithError(err).WithField("slack_user", slackUserID).
+			Warnln("Failed to resolve Slack user for request modal")
+		r.notifyUser(ctx, slackUserID, "We couldn't match your Slack account to a Thand identity, so you can't request access from Slack yet.")
+		return
+	}
+
+	roles := r.handler.ListRoles(user)
+
+	if len(roles) == 0 {
+		r.notifyUser(ctx, slackUserID, "There are no roles available for you to request.")
+		return
+	}
+
+	_, err = r.provider.client.OpenViewContext(ctx, triggerID, buildRequestModal(roles))
+
+	if err != nil {
+		log.WithError(err).WithField("slack_user", slackUserID).
+			Errorln("Failed to open Slack request modal")
+	}
+}
+
+func buildRequestModal(roles map[string]models.Role) slack.ModalViewRequest {
+
+	roleKeys := make([]string, 0, len(roles))
+	for roleKey := range roles {
+		roleKeys = append(roleKeys, roleKey)
+	}
+	slices.Sort(roleKeys)
+
+	if len(roleKeys) > maxRoleOptions {
+		roleKeys = roleKeys[:maxRoleOptions]
+	}
+
+	roleOptions := make([]*slack.OptionBlockObject, 0, len(roleKeys))
+	for _, roleKey := range roleKeys {
+		role := roles[roleKey]
+
+		label := role.Name
+		if len(label) == 0 {
+			label = roleKey
+		}
+
+		var description *slack.TextBlockObject
+		if len(role.Description) > 0 {
+			description = newPlainText(truncate(role.Description, 75))
+		}
+
+		roleOptions = append(roleOptions, slack.NewOptionBlockObject(
+			roleKey, newPlainText(truncate(label, 75)), description))
+	}
+
+	durationOptions := make([]*slack.OptionBlockObject, 0, len(requestDurations))
+	var defaultDuration *slack.OptionBlockObject
+	for _, duration := range requestDurations {
+		option := slack.NewOptionBlockObject(duration.Value, newPlainText(duration.Label), nil)
+		if duration.Value == "PT1H" {
+			defaultDuration = option
+		}
+		durationOptions = append(durationOptions, option)
+	}
+
+	durationSelect := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeStatic, newPlainText("Select a duration"), requestDurationBlockID, durationOptions...)
+	durationSelect.InitialOption = defaultDuration
+
+	reasonInput := slack.NewPlainTextInputBlockElement(
+		newPlainText("Provide specific justification"), requestReasonBlockID)
+	reasonInput.Multiline = true
+	reasonInput.MinLength = 10
+
+	return slack.ModalViewRequest{
+		Type:       slack.VTModal,
+		CallbackID: requestCallbackID,
+		Title:      newPlainText("Request access"),
+		Submit:     newPlainText("Request"),
+		Close:      newPlainText("Cancel"),
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewInputBlock(requestRoleBlockID, newPlainText("Role"), nil,
+				slack.NewOptionsSelectBlockElement(
+					slack.OptTypeStatic, newPlainText("Select a role"), requestRoleBlockID, roleOptions...)),
+			slack.NewInputBlock(requestDurationBlockID, newPlainText("Duration"), nil, durationSelect),
+			slack.NewInputBlock(requestReasonBlockID, newPlainText("Reason"), nil, reasonInput),
+		}},
+	}
+}
+
+// parseRequestSubmission reads the modal state. Any errors are keyed by
+// block id so Slack can show them against the field.
+func parseRequestSubmission(callback slack.InteractionCallback) (*requestSubmission, map[string]string) {
+
+	values := callback.View.State.Values
+
+	submission := &requestSubmission{
+		Role:     values[requestRoleBlockID][requestRoleBlockID].SelectedOption.Value,
+		Duration: values[requestDurationBlockID][requestDurationBlockID].SelectedOption.Value,
+		Reason:   strings.TrimSpace(values[requestReasonBlockID][requestReasonBlockID].Value),
+	}
+
+	validationErrors := map[string]string{}
+
+	if len(submission.Role) == 0 {
+		validationErrors[requestRoleBlockID] = "Select a role"
+	}
+
+	if _, err := common.ValidateDuration(submission.Duration); err != nil {
+		validationErrors[requestDurationBlockID] = err.Error()
+	}
+
+	if len(submission.Reason) < 10 {
+		validationErrors[requestReasonBlockID] = "Reason must be at least 10 characters long"
+	}
+
+	return submission, validationErrors
+}
+
+// submitRequest sends the elevation request to the server and messages the
+// user a link to continue it
+func (r *socketModeRunner) submitRequest(ctx context.Context, slackUserID string, submission *requestSubmission) {
+
+	log := r.provider.GetLogger(ctx)
+
+	user, err := r.resolveUser(ctx, slackUserID)
+
+	if err != nil {
+		log.WithError(err).WithField("slack_user", slackUserID).
+			Warnln("Failed to resolve Slack user for request")
+		r.notifyUser(ctx, slackUserID, "We couldn't match your Slack account to a Thand identity.")
+		return
+	}
+
+	role, found := r.handler.ListRoles(user)[submission.Role]
+
+	if !found {
+		r.notifyUser(ctx, slackUserID, fmt.Sprintf("The role %s is not available to you.", submission.Role))
+		return
+	}
+
+	workflowRequest, err := r.handler.Elevate(ctx, user, models.ElevateRequest{
+		Role:      &role,
+		Providers: role.Providers,
+		Reason:    submission.Reason,
+		Duration:  submission.Duration,
+	})
+
+	if err != nil {
+		log.WithError(err).WithField("user", user.Email).
+			Errorln("Failed to submit elevation request from Slack")
+		r.notifyUser(ctx, slackUserID, fmt.Sprintf("Your request for %s could not be submitted: %s", role.GetName(), err.Error()))
+		return
+	}
+
+	text := fmt.Sprintf("Your request for *%s* has been created. Continue to sign in and submit it for approval.", role.GetName())
+
+	_, _, err = r.provider.client.PostMessageContext(ctx, slackUserID,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionBlocks(
+			newMarkdownSection(text),
+			slack.NewActionBlock("",
+				slack.NewButtonBlockElement("", "",
+					newPlainText("Continue request"),
+				).WithURL(workflowRequest.GetRedirectURL()).WithStyle(slack.StylePrimary),
+			),
+		),
+	)
+
+	if err != nil {
+		log.WithError(err).WithField("slack_user", slackUserID).
+			Errorln("Failed to send Slack request confirmation")
+	}
+
+	r.publishHome(ctx, slackUserID)
+}
+
+// notifyUser sends a plain message to the user from the app
+func (r *socketModeRunner) notifyUser(ctx context.Context, slackUserID string, text string) {
+	log := r.provider.GetLogger(ctx)
+
+	_, _, err := r.provider.client.PostMessageContext(ctx, slackUserID, slack.MsgOptionText(text, false))
+	if err != nil {
+		log.WithError(err).WithField("slack_user", slackUserID).
+			Errorln("Failed to send Slack message")
+	}
+}
+
+func newPlainText(text string) *slack.TextBlockObject {
+	return slack.NewTextBlockObject(slack.PlainTextType, text, false, false)
+}
+
+func truncate(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length-3]) + "..."
+}
@@ -0,0 +1,99 @@
+package slack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func newSubmissionCallback(role, duration, reason string) slack.InteractionCallback {
+	callback := slack.InteractionCallback{
+		Type: slack.InteractionTypeViewSubmission,
+	}
+	callback.View.CallbackID = requestCallbackID
+	callback.View.State = &slack.ViewState{
+		Values: map[string]map[string]slack.BlockAction{
+			requestRoleBlockID: {
+				requestRoleBlockID: {SelectedOption: slack.OptionBlockObject{Value: role}},
+			},
+			requestDurationBlockID: {
+				requestDurationBlockID: {SelectedOption: slack.OptionBlockObject{Value: duration}},
+			},
+			requestReasonBlockID: {
+				requestReasonBlockID: {Value: reason},
+			},
+		},
+	}
+	return callback
+}
+
+func TestParseRequestSubmission(t *testing.T) {
+
+	t.Run("valid submission", func(t *testing.T) {
+		submission, validationErrors := parseRequestSubmission(
+			newSubmissionCallback("admin", "PT1H", "  investigating prod outage  "))
+
+		assert.Empty(t, validationErrors)
+		assert.Equal(t, "admin", submission.Role)
+		assert.Equal(t, "PT1H", submission.Duration)
+		assert.Equal(t, "investigating prod outage", submission.Reason)
+	})
+
+	t.Run("invalid submission", func(t *testing.T) {
+		_, validationErrors := parseRequestSubmission(
+			newSubmissionCallback("", "soon", "fix"))
+
+		assert.Contains(t, validationErrors, requestRoleBlockID)
+		assert.Contains(t, validationErrors, requestDurationBlockID)
+		assert.Contains(t, validationErrors, requestReasonBlockID)
+	})
+}
+
+func TestBuildRequestModal(t *testing.T) {
+	modal := buildRequestModal(map[string]models.Role{
+		"viewer": {Name: "Viewer"},
+		"admin":  {Name: "Admin", Description: "Full access"},
+	})
+
+	assert.Equal(t, requestCallbackID, modal.CallbackID)
+	require.Len(t, modal.Blocks.BlockSet, 3)
+	assert.True(t, slack.ValidateUniqueBlockID(modal))
+
+	roleInput, ok := modal.Blocks.BlockSet[0].(*slack.InputBlock)
+	require.True(t, ok)
+
+	roleSelect, ok := roleInput.Element.(*slack.SelectBlockElement)
+	require.True(t, ok)
+	require.Len(t, roleSelect.Options, 2)
+
+	// Roles are sorted by key
+	assert.Equal(t, "admin", roleSelect.Options[0].Value)
+	assert.Equal(t, "viewer", roleSelect.Options[1].Value)
+}
+
+func TestBuildElevationBlocks(t *testing.T) {
+	approved := true
+	denied := false
+
+	blocks := buildElevationBlocks([]*models.WorkflowExecutionInfo{
+		{Role: "admin", Providers: []string{"aws-prod"}, StartTime: time.Now(), Approved: &approved},
+		{Role: "viewer", StartTime: time.Now()},
+		{Role: "denied", StartTime: time.Now(), Approved: &denied},
+	})
+
+	sections := []string{}
+	for _, block := range blocks {
+		if section, ok := block.(*slack.SectionBlock); ok {
+			sections = append(sections, section.Text.Text)
+		}
+	}
+
+	// Active header, active grant, pending header and pending request
+	require.Len(t, sections, 4)
+	assert.Contains(t, sections[1], "*admin* on aws-prod")
+	assert.Contains(t, sections[3], "*viewer*")
+}
@@ -0,0 +1,223 @@
+package slack
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+)
+
+const (
+	socketModeInitialBackoff = 1 * time.Second
+	socketModeMaxBackoff     = 1 * time.Minute
+)
+
+// socketModeRunner keeps a socket mode connection open to Slack and
+// dispatches app home, shortcut and slash command events
+type socketModeRunner struct {
+	provider *slackProvider
+	client   *socketmode.Client
+	handler  models.SelfServiceHandler
+
+	connected atomic.Bool
+	cancel    context.CancelFunc
+}
+
+// SetSelfServiceHandler starts socket mode when an app token is configured.
+// Without one the provider only sends notifications.
+func (p *slackProvider) SetSelfServiceHandler(handler models.SelfServiceHandler) error {
+
+	if len(p.appToken) == 0 {
+		return models.ErrNotImplemented
+	}
+
+	// Replace any previous connection e.g. on config reload
+	if p.socket != nil {
+		p.socket.Stop()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p.socket = &socketModeRunner{
+		provider: p,
+		client:   socketmode.New(p.client),
+		handler:  handler,
+		cancel:   cancel,
+	}
+
+	go p.socket.handleEvents(ctx)
+	go p.socket.run(ctx)
+
+	return nil
+}
+
+// Stop closes the socket mode connection
+func (r *socketModeRunner) Stop() {
+	r.cancel()
+}
+
+// run connects to Slack and reconnects with an exponential backoff when the
+// websocket drops. The backoff resets once a connection is established.
+func (r *socketModeRunner) run(ctx context.Context) {
+
+	log := r.provider.GetLogger(ctx)
+
+	backoff := socketModeInitialBackoff
+
+	for {
+		err := r.client.RunContext(ctx)
+
+		if ctx.Err() != nil {
+			log.Infoln("Slack socket mode stopped")
+			return
+		}
+
+		if r.connected.Swap(false) {
+			backoff = socketModeInitialBackoff
+		}
+
+		log.WithError(err).WithField("backoff", backoff).
+			Warnln("Slack socket mode disconnected, reconnecting")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, socketModeMaxBackoff)
+	}
+}
+
+func (r *socketModeRunner) handleEvents(ctx context.Context) {
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-r.client.Events:
+			if !ok {
+				return
+			}
+			r.handleEvent(ctx, event)
+		}
+	}
+}
+
+func (r *socketModeRunner) handleEvent(ctx context.Context, event socketmode.Event) {
+
+	// Everything logged while handling the event shares a request ID
+	ctx = common.WithLogContext(ctx, "", common.NewRequestID())
+	log := r.provider.GetLogger(ctx)
+
+	switch event.Type {
+
+	case socketmode.EventTypeConnected:
+		r.connected.Store(true)
+		log.Infoln("Slack socket mode connected")
+
+	case socketmode.EventTypeConnectionError:
+		log.Warnln("Slack socket mode connection error")
+
+	case socketmode.EventTypeEventsAPI:
+		eventsAPIEvent, ok := event.Data.(slackevents.EventsAPIEvent)
+		if !ok {
+			return
+		}
+
+		r.client.Ack(*event.Request)
+
+		if eventsAPIEvent.Type != slackevents.CallbackEvent {
+			return
+		}
+
+		if homeEvent, ok := eventsAPIEvent.InnerEvent.Data.(*slackevents.AppHomeOpenedEvent); ok {
+			if homeEvent.Tab == "home" {
+				go r.publishHome(ctx, homeEvent.User)
+			}
+		}
+
+	case socketmode.EventTypeSlashCommand:
+		command, ok := event.Data.(slack.SlashCommand)
+		if !ok {
+			return
+		}
+
+		r.client.Ack(*event.Request)
+
+		go r.openRequestModal(ctx, command.UserID, command.TriggerID)
+
+	case socketmode.EventTypeInteractive:
+		callback, ok := event.Data.(slack.InteractionCallback)
+		if !ok {
+			return
+		}
+
+		r.handleInteraction(ctx, event.Request, callback)
+	}
+}
+
+func (r *socketModeRunner) handleInteraction(
+	ctx context.Context,
+	request *socketmode.Request,
+	callback slack.InteractionCallback,
+) {
+
+	switch callback.Type {
+
+	case slack.InteractionTypeShortcut:
+		r.client.Ack(*request)
+
+		if callback.CallbackID == requestCallbackID {
+			go r.openRequestModal(ctx, callback.User.ID, callback.TriggerID)
+		}
+
+	case slack.InteractionTypeBlockActions:
+		r.client.Ack(*request)
+
+		for _, action := range callback.ActionCallback.BlockActions {
+			if action.ActionID == requestActionID {
+				go r.openRequestModal(ctx, callback.User.ID, callback.TriggerID)
+			}
+		}
+
+	case slack.InteractionTypeViewSubmission:
+
+		if callback.View.CallbackID != requestCallbackID {
+			r.client.Ack(*request)
+			return
+		}
+
+		// Validation errors are shown inline on the modal
+		submission, validationErrors := parseRequestSubmission(callback)
+
+		if len(validationErrors) > 0 {
+			r.client.Ack(*request, slack.NewErrorsViewSubmissionResponse(validationErrors))
+			return
+		}
+
+		r.client.Ack(*request)
+
+		go r.submitRequest(ctx, callback.User.ID, submission)
+
+	default:
+		r.client.Ack(*request)
+	}
+}
+
+// resolveUser maps a Slack user to a thand identity using their email
+func (r *socketModeRunner) resolveUser(ctx context.Context, slackUserID string) (*models.User, error) {
+
+	slackUser, err := r.provider.client.GetUserInfoContext(ctx, slackUserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.handler.GetUserByEmail(ctx, slackUser.Profile.Email)
+}
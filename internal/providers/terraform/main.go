@@ -1,6 +1,8 @@
 package terraform
 
 import (
+	"context"
+
 	"fmt"
 
 	"github.com/hashicorp/go-tfe"
@@ -17,7 +19,16 @@ type terraformProvider struct {
 	permissions []models.ProviderPermission
 }
 
-func (p *terraformProvider) Initialize(identifier string, provider models.Provider) error {
+// ValidateConfig checks the API token is set
+func (p *terraformProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(TerraformProviderName)
+	validation.Require(config, "token")
+
+	return validation.ErrorOrNil()
+}
+
+func (p *terraformProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
 		provider,
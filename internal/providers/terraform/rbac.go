@@ -2,12 +2,17 @@ package terraform
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/hashicorp/go-tfe"
 	"github.com/thand-io/agent/internal/models"
 )
 
+// GrantKindTeamAccess is the grant kind for Terraform, the grant IDs are the
+// team access IDs
+const GrantKindTeamAccess = "team_access"
+
 // Authorize grants access for a user to a role
 func (p *terraformProvider) AuthorizeRole(
 	ctx context.Context,
@@ -26,8 +31,22 @@ func (p *terraformProvider) AuthorizeRole(
 		return nil, fmt.Errorf("no workspace IDs found in role.Resources.Allow")
 	}
 
+	grant := models.NewGrantRef(p.GetIdentifier(), GrantKindTeamAccess, req.RoleRequest)
+
 	// Authorize user for each workspace
 	for _, workspaceID := range role.Resources.Allow {
+
+		// A retry finds the team access it already added
+		existing, err := p.findTeamAccess(ctx, workspaceID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if existing != nil {
+			grant.AddID(existing.ID)
+			continue
+		}
+
 		// Create team access for the user on the specified workspace
 		teamAccess := &tfe.TeamAccessAddOptions{
 			Access:    tfe.Access(tfe.AccessType(role.Name)), // Use role name as access level
@@ -35,14 +54,18 @@ func (p *terraformProvider) AuthorizeRole(
 			Workspace: &tfe.Workspace{ID: workspaceID},
 		}
 
-		_, err := p.client.TeamAccess.Add(ctx, *teamAccess)
+		access, err := p.client.TeamAccess.Add(ctx, *teamAccess)
 		if err != nil {
 			return nil, fmt.Errorf("failed to authorize user %s for role %s on workspace %s: %w",
 				user.ID, role.Name, workspaceID, err)
 		}
+
+		grant.AddID(access.ID)
 	}
 
-	return nil, nil
+	return &models.AuthorizeRoleResponse{
+		GrantRef: grant,
+	}, nil
 }
 
 // Revoke removes access for a user from a role
@@ -54,6 +77,17 @@ func (p *terraformProvider) RevokeRole(
 	user := req.GetUser()
 	role := req.GetRole()
 
+	// Remove exactly the team accesses recorded by the grant
+	if grant := req.GetGrantRef(); grant.HasIDs() {
+		for _, teamAccessID := range grant.IDs {
+			err := p.client.TeamAccess.Remove(ctx, teamAccessID)
+			if err != nil && !errors.Is(err, tfe.ErrResourceNotFound) {
+				return nil, fmt.Errorf("failed to revoke team access %s: %w", teamAccessID, err)
+			}
+		}
+		return nil, nil
+	}
+
 	// Loop over all resources in role.Resources.Allow as workspace IDs
 	if len(role.Resources.Allow) == 0 {
 		return nil, fmt.Errorf("no workspace IDs found in role.Resources.Allow")
@@ -92,3 +126,23 @@ func (p *terraformProvider) RevokeRole(
 
 	return nil, nil
 }
+
+// findTeamAccess returns the team's access to the workspace, or nil if the
+// team has no access
+func (p *terraformProvider) findTeamAccess(ctx context.Context, workspaceID string, teamID string) (*tfe.TeamAccess, error) {
+
+	teamAccesses, err := p.client.TeamAccess.List(ctx, &tfe.TeamAccessListOptions{
+		WorkspaceID: workspaceID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team accesses for workspace %s: %w", workspaceID, err)
+	}
+
+	for _, ta := range teamAccesses.Items {
+		if ta.Team != nil && ta.Team.ID == teamID {
+			return ta, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -39,7 +39,7 @@ type UserInfoResponse struct {
 	Groups            []string `json:"groups,omitempty"` // User groups/roles
 }
 
-func (p *thandProvider) Initialize(identifier string, provider models.Provider) error {
+func (p *thandProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
 	p.BaseProvider = models.NewBaseProvider(
 		identifier,
 		provider,
@@ -194,7 +194,7 @@ func (p *thandProvider) RenewSession(ctx context.Context, session *models.Sessio
 		return nil, fmt.Errorf("session is nil")
 	}
 
-	return nil, fmt.Errorf("session renewal not implemented")
+	return nil, fmt.Errorf("session renewal not implemented: %w", models.ErrNotImplemented)
 }
 
 func init() {
@@ -0,0 +1,105 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/providers"
+)
+
+const VaultProviderName = "vault"
+
+// vaultProvider implements the ProviderImpl interface for HashiCorp Vault
+// dynamic secrets engines e.g. database and aws. Vault roles under the
+// mount are its roles and authorizing a role mints credentials for the
+// requester, revoking the role revokes their lease.
+type vaultProvider struct {
+	*models.BaseProvider
+	client    *api.Client
+	mount     string
+	credsPath string
+}
+
+// ValidateConfig checks the Vault address and the secrets engine mount are set
+func (p *vaultProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(VaultProviderName)
+	validation.Require(config, "vault_url", "mount")
+
+	return validation.ErrorOrNil()
+}
+
+func (p *vaultProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
+
+	p.BaseProvider = models.NewBaseProvider(
+		identifier,
+		provider,
+		models.ProviderCapabilityRBAC,
+	)
+
+	vaultConfig := provider.Config
+
+	vaultURL, foundVaultURL := vaultConfig.GetString("vault_url")
+	mount, foundMount := vaultConfig.GetString("mount")
+
+	if !foundVaultURL || !foundMount {
+		return fmt.Errorf("missing Vault configuration, vault_url and mount are required")
+	}
+
+	config := api.DefaultConfig()
+	config.Address = vaultURL
+
+	if timeout, foundTimeout := vaultConfig.GetString("timeout"); foundTimeout {
+		duration, err := common.ParseDuration(timeout)
+		if err != nil {
+			return fmt.Errorf("invalid vault timeout: %w", err)
+		}
+		config.Timeout = duration
+	}
+
+	client, err := api.NewClient(config)
+
+	if err != nil {
+		return fmt.Errorf("failed to create Vault client: %w", err)
+	}
+
+	// The client reads VAULT_TOKEN when no token is configured
+	if token, foundToken := vaultConfig.GetString("token"); foundToken {
+		client.SetToken(token)
+	} else if len(client.Token()) == 0 {
+		return fmt.Errorf("vault token not found in config or environment (VAULT_TOKEN)")
+	}
+
+	if namespace, foundNamespace := vaultConfig.GetString("namespace"); foundNamespace {
+		client.SetNamespace(namespace)
+	}
+
+	p.client = client
+	p.mount = strings.Trim(mount, "/")
+
+	// The database engine issues credentials from creds, the aws engine
+	// from creds or sts
+	p.credsPath = strings.Trim(vaultConfig.GetStringWithDefault("creds_path", "creds"), "/")
+
+	return nil
+}
+
+// TestConnection looks up the token the provider authenticates with
+func (p *vaultProvider) TestConnection(ctx context.Context, capability models.ProviderCapability) error {
+	switch capability {
+	case models.ProviderCapabilityRBAC:
+		if _, err := p.client.Auth().Token().LookupSelfWithContext(ctx); err != nil {
+			return fmt.Errorf("failed to look up the Vault token: %w", err)
+		}
+		return nil
+	}
+	return models.ErrNotImplemented
+}
+
+func init() {
+	providers.Register(VaultProviderName, &vaultProvider{})
+}
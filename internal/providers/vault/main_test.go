@@ -0,0 +1,210 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// fakeVault serves the parts of the Vault API the provider uses with a
+// database secrets engine mounted at database
+type fakeVault struct {
+	mu      sync.Mutex
+	ttls    map[string]string // requested ttl by role
+	revoked []string
+	issued  int
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	if r.Header.Get("X-Vault-Token") != "root" {
+		writeJSON(w, http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+
+	switch {
+	case path == "auth/token/lookup-self":
+		writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{"display_name": "thand"}})
+
+	case path == "database/roles" && (r.Method == "LIST" || r.URL.Query().Get("list") == "true"):
+		writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{"keys": []string{"readonly", "readwrite"}}})
+
+	case path == "database/creds/readonly" || path == "database/creds/readwrite":
+		role := strings.TrimPrefix(path, "database/creds/")
+		f.ttls[role] = r.URL.Query().Get("ttl")
+		f.issued++
+		writeJSON(w, http.StatusOK, map[string]any{
+			"lease_id":       "database/creds/" + role + "/lease" + string(rune('0'+f.issued)),
+			"lease_duration": 3600,
+			"renewable":      true,
+			"data":           map[string]any{"username": "v-" + role, "password": "hunter2"},
+		})
+
+	case path == "sys/leases/revoke" && r.Method == http.MethodPut:
+		var body struct {
+			LeaseID string `json:"lease_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		f.revoked = append(f.revoked, body.LeaseID)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]any{"errors": []string{"unknown role"}})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func newTestProvider(t *testing.T, fake *fakeVault) *vaultProvider {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	config := models.BasicConfig{
+		"vault_url": server.URL,
+		"token":     "root",
+		"mount":     "database",
+	}
+
+	provider := &vaultProvider{}
+	require.NoError(t, provider.ValidateConfig(&config))
+	require.NoError(t, provider.Initialize(context.Background(), VaultProviderName, models.Provider{
+		Name:     VaultProviderName,
+		Provider: VaultProviderName,
+		Config:   &config,
+	}))
+
+	return provider
+}
+
+func newFakeVault() *fakeVault {
+	return &fakeVault{ttls: map[string]string{}}
+}
+
+func TestTestConnection(t *testing.T) {
+	provider := newTestProvider(t, newFakeVault())
+
+	assert.NoError(t, provider.TestConnection(context.Background(), models.ProviderCapabilityRBAC))
+
+	provider.client.SetToken("wrong")
+	assert.Error(t, provider.TestConnection(context.Background(), models.ProviderCapabilityRBAC))
+}
+
+func TestSynchronizeRoles(t *testing.T) {
+	provider := newTestProvider(t, newFakeVault())
+
+	resp, err := provider.SynchronizeRoles(context.Background(), &models.SynchronizeRolesRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Roles, 2)
+	assert.Equal(t, "readonly", resp.Roles[0].Name)
+	assert.Equal(t, "Credentials from database/creds/readonly", resp.Roles[0].Description)
+	assert.Equal(t, "readwrite", resp.Roles[1].Name)
+}
+
+func TestAuthorizeAndRevokeRole(t *testing.T) {
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	duration := 2 * time.Hour
+
+	newRequest := func(role *models.Role, publicKey string) *models.AuthorizeRoleRequest {
+		return &models.AuthorizeRoleRequest{
+			RoleRequest: &models.RoleRequest{
+				User:      &models.User{Email: "alice@example.com"},
+				Role:      role,
+				Duration:  &duration,
+				PublicKey: publicKey,
+			},
+		}
+	}
+
+	t.Run("mints credentials encrypted for the requester", func(t *testing.T) {
+		fake := newFakeVault()
+		provider := newTestProvider(t, fake)
+
+		role := &models.Role{Name: "db-readonly", Inherits: []string{"readonly"}}
+
+		resp, err := provider.AuthorizeRole(context.Background(), newRequest(role, identity.Recipient().String()))
+		require.NoError(t, err)
+
+		assert.Equal(t, "7200s", fake.ttls["readonly"])
+		require.True(t, resp.GrantRef.HasIDs())
+		assert.Equal(t, []string{"database/creds/readonly/lease1"}, resp.GrantRef.IDs)
+
+		// Only the ciphertext leaves the provider
+		require.NotNil(t, resp.Credentials)
+		assert.NotContains(t, resp.Credentials.Encrypted, "hunter2")
+		assert.Equal(t, "db-readonly", resp.Credentials.Role)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), resp.Credentials.ExpiresAt, time.Minute)
+
+		credentials, err := resp.Credentials.Decrypt(identity)
+		require.NoError(t, err)
+		readonly := credentials["readonly"].(map[string]any)
+		assert.Equal(t, "database/creds/readonly/lease1", readonly["lease_id"])
+		assert.Equal(t, map[string]any{"username": "v-readonly", "password": "hunter2"}, readonly["data"])
+
+		_, err = provider.RevokeRole(context.Background(), &models.RevokeRoleRequest{
+			RoleRequest:           newRequest(role, "").RoleRequest,
+			AuthorizeRoleResponse: resp,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"database/creds/readonly/lease1"}, fake.revoked)
+	})
+
+	t.Run("requires a public key", func(t *testing.T) {
+		fake := newFakeVault()
+		provider := newTestProvider(t, fake)
+
+		role := &models.Role{Name: "db-readonly", Inherits: []string{"readonly"}}
+
+		_, err := provider.AuthorizeRole(context.Background(), newRequest(role, ""))
+		assert.ErrorContains(t, err, "must include a public key")
+
+		_, err = provider.AuthorizeRole(context.Background(), newRequest(role, "not-a-key"))
+		assert.ErrorContains(t, err, "invalid public key")
+
+		assert.Zero(t, fake.issued)
+	})
+
+	t.Run("revokes minted credentials when a later role fails", func(t *testing.T) {
+		fake := newFakeVault()
+		provider := newTestProvider(t, fake)
+
+		role := &models.Role{Name: "db", Inherits: []string{"readonly", "missing"}}
+
+		_, err := provider.AuthorizeRole(context.Background(), newRequest(role, identity.Recipient().String()))
+		assert.ErrorContains(t, err, "failed to read Vault credentials from database/creds/missing")
+		assert.Equal(t, []string{"database/creds/readonly/lease1"}, fake.revoked)
+	})
+
+	t.Run("revoke without leases", func(t *testing.T) {
+		provider := newTestProvider(t, newFakeVault())
+
+		_, err := provider.RevokeRole(context.Background(), &models.RevokeRoleRequest{
+			RoleRequest:           newRequest(&models.Role{Name: "db"}, "").RoleRequest,
+			AuthorizeRoleResponse: &models.AuthorizeRoleResponse{},
+		})
+		assert.ErrorContains(t, err, "no Vault leases")
+	})
+}
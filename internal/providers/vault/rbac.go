@@ -0,0 +1,189 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+	"go.temporal.io/sdk/temporal"
+)
+
+// GrantTypeLease is the grant kind recorded for the leases of minted credentials
+const GrantTypeLease = "lease"
+
+// Metadata key recording the Vault roles credentials were minted from
+const MetadataVaultRoles = "vault_roles"
+
+// AuthorizeRole mints credentials for each of the role's Vault roles with
+// a TTL matching the grant duration. The credentials are only returned
+// encrypted for the requester's public key and the leases are recorded in
+// the grant so revoking the role revokes them.
+func (p *vaultProvider) AuthorizeRole(
+	ctx context.Context,
+	req *models.AuthorizeRoleRequest,
+) (*models.AuthorizeRoleResponse, error) {
+
+	if !req.IsValid() {
+		return nil, fmt.Errorf("user and role must be provided to authorize vault role")
+	}
+
+	user := req.GetUser()
+	role := req.GetRole()
+
+	if len(role.Inherits) == 0 {
+		return nil, fmt.Errorf("role %s must inherit a Vault role under %s", role.Name, p.mount)
+	}
+
+	// Check the key before minting anything that couldn't be handed over
+	if len(req.GetPublicKey()) == 0 {
+		return nil, fmt.Errorf("%s returns credentials to the requester, the request must include a public key", role.Name)
+	}
+
+	if err := models.ValidatePublicKey(req.GetPublicKey()); err != nil {
+		return nil, err
+	}
+
+	grant := models.NewGrantRef(p.GetIdentifier(), GrantTypeLease, req.RoleRequest)
+	credentials := map[string]any{}
+	expiresAt := time.Time{}
+
+	for _, vaultRole := range role.Inherits {
+
+		secret, err := p.readCredentials(ctx, vaultRole, req.GetDuration())
+
+		if err != nil {
+			// Don't leave credentials behind that the requester never received
+			p.revokeLeases(ctx, grant.IDs)
+			return nil, err
+		}
+
+		grant.AddID(secret.LeaseID)
+
+		leaseExpiry := time.Now().Add(time.Duration(secret.LeaseDuration) * time.Second)
+
+		if expiresAt.IsZero() || leaseExpiry.Before(expiresAt) {
+			expiresAt = leaseExpiry
+		}
+
+		credentials[vaultRole] = map[string]any{
+			"data":           secret.Data,
+			"lease_id":       secret.LeaseID,
+			"lease_duration": secret.LeaseDuration,
+			"expires_at":     leaseExpiry.UTC().Format(time.RFC3339),
+		}
+	}
+
+	artifact, err := models.NewCredentialArtifact(
+		p.GetIdentifier(), role.Name, req.GetPublicKey(), credentials, expiresAt)
+
+	if err != nil {
+		p.revokeLeases(ctx, grant.IDs)
+		return nil, err
+	}
+
+	p.GetLogger(ctx).WithFields(logrus.Fields{
+		"user_email":  user.Email,
+		"role":        role.Name,
+		"vault_roles": role.Inherits,
+		"expires_at":  expiresAt,
+	}).Info("Minted Vault credentials")
+
+	return &models.AuthorizeRoleResponse{
+		UserId:      user.Email,
+		Roles:       []string{role.Name},
+		GrantRef:    grant,
+		Credentials: artifact,
+		Metadata: map[string]any{
+			MetadataVaultRoles: strings.Join(role.Inherits, ","),
+		},
+	}, nil
+}
+
+// RevokeRole revokes the leases of the credentials minted for the role
+func (p *vaultProvider) RevokeRole(
+	ctx context.Context,
+	req *models.RevokeRoleRequest,
+) (*models.RevokeRoleResponse, error) {
+
+	grant := req.GetGrantRef()
+
+	if !grant.HasIDs() {
+		return nil, fmt.Errorf("no Vault leases found in authorization response for revocation")
+	}
+
+	for _, leaseID := range grant.IDs {
+
+		// Revoking a lease that has already expired or been revoked succeeds
+		if err := p.client.Sys().RevokeWithContext(ctx, leaseID); err != nil {
+			return nil, temporal.NewApplicationErrorWithOptions(
+				fmt.Sprintf("failed to revoke Vault lease %s: %v", leaseID, err),
+				"VaultLeaseRevocationError",
+				temporal.ApplicationErrorOptions{
+					NextRetryDelay: 3 * time.Second,
+					Cause:          err,
+				},
+			)
+		}
+	}
+
+	p.GetLogger(ctx).WithFields(logrus.Fields{
+		"user_email": req.GetUser().Email,
+		"leases":     len(grant.IDs),
+	}).Info("Revoked Vault leases")
+
+	return &models.RevokeRoleResponse{}, nil
+}
+
+// readCredentials reads the credential endpoint of the Vault role e.g.
+// database/creds/readonly, asking for a TTL of the grant duration
+func (p *vaultProvider) readCredentials(
+	ctx context.Context,
+	vaultRole string,
+	duration *time.Duration,
+) (*api.Secret, error) {
+
+	credsPath := path.Join(p.mount, p.credsPath, vaultRole)
+
+	data := map[string][]string{}
+
+	if duration != nil && *duration > 0 {
+		data["ttl"] = []string{fmt.Sprintf("%ds", int64(duration.Seconds()))}
+	}
+
+	secret, err := p.client.Logical().ReadWithDataWithContext(ctx, credsPath, data)
+
+	if err != nil {
+		return nil, temporal.NewApplicationErrorWithOptions(
+			fmt.Sprintf("failed to read Vault credentials from %s: %v", credsPath, err),
+			"VaultCredentialsError",
+			temporal.ApplicationErrorOptions{
+				NextRetryDelay: 3 * time.Second,
+				Cause:          err,
+			},
+		)
+	}
+
+	if secret == nil || len(secret.Data) == 0 {
+		return nil, fmt.Errorf("vault returned no credentials from %s", credsPath)
+	}
+
+	if len(secret.LeaseID) == 0 {
+		return nil, fmt.Errorf("vault credentials from %s have no lease and can't be revoked", credsPath)
+	}
+
+	return secret, nil
+}
+
+// revokeLeases revokes the leases of credentials minted by a failed authorize
+func (p *vaultProvider) revokeLeases(ctx context.Context, leaseIDs []string) {
+	for _, leaseID := range leaseIDs {
+		if err := p.client.Sys().RevokeWithContext(ctx, leaseID); err != nil {
+			p.GetLogger(ctx).WithError(err).WithField("lease_id", leaseID).Warn("Failed to revoke Vault lease")
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func (p *vaultProvider) CanSynchronizeRoles() bool {
+	return true
+}
+
+// SynchronizeRoles lists the Vault roles under the mount, the database and
+// aws engines both keep them under roles
+func (p *vaultProvider) SynchronizeRoles(ctx context.Context, req *models.SynchronizeRolesRequest) (*models.SynchronizeRolesResponse, error) {
+
+	startTime := time.Now()
+
+	rolesPath := path.Join(p.mount, "roles")
+
+	secret, err := p.client.Logical().ListWithContext(ctx, rolesPath)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Vault roles under %s: %w", rolesPath, err)
+	}
+
+	roles := []models.ProviderRole{}
+
+	// Listing a mount without any roles returns nothing
+	if secret != nil {
+		keys, _ := secret.Data["keys"].([]any)
+
+		for _, key := range keys {
+			name, ok := key.(string)
+			if !ok {
+				continue
+			}
+			roles = append(roles, models.ProviderRole{
+				ID:          name,
+				Name:        name,
+				Description: fmt.Sprintf("Credentials from %s", path.Join(p.mount, p.credsPath, name)),
+				Role:        name,
+			})
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"roles": len(roles),
+	}).Debugf("Refreshed Vault roles in %s", time.Since(startTime))
+
+	return &models.SynchronizeRolesResponse{
+		Roles: roles,
+	}, nil
+}
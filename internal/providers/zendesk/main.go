@@ -0,0 +1,189 @@
+package zendesk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nukosuke/go-zendesk/zendesk"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/providers"
+)
+
+const ZendeskProviderName = "zendesk"
+
+// Zendesk caps cursor pages at 100 records
+const defaultPageSize = 100
+
+// zendeskProvider implements the ProviderImpl interface for Zendesk. Agent
+// groups are its roles and authorizing a role only succeeds while the
+// ticket referenced in the request is open in one of the role's groups.
+type zendeskProvider struct {
+	*models.BaseProvider
+	client          *zendesk.Client
+	endpoint        string
+	group           string
+	requireAssignee bool
+}
+
+// ValidateConfig checks the subdomain is set along with an API token and
+// the email of the agent it belongs to
+func (p *zendeskProvider) ValidateConfig(config *models.BasicConfig) error {
+
+	validation := models.NewConfigValidationError(ZendeskProviderName)
+	validation.Require(config, "subdomain", "email", "api_token")
+
+	return validation.ErrorOrNil()
+}
+
+func (p *zendeskProvider) Initialize(ctx context.Context, identifier string, provider models.Provider) error {
+
+	p.BaseProvider = models.NewBaseProvider(
+		identifier,
+		provider,
+		models.ProviderCapabilityRBAC,
+	)
+
+	zendeskConfig := provider.Config
+
+	subdomain, foundSubdomain := zendeskConfig.GetString("subdomain")
+	email, foundEmail := zendeskConfig.GetString("email")
+	apiToken, foundApiToken := zendeskConfig.GetString("api_token")
+
+	if !foundSubdomain || !foundEmail || !foundApiToken {
+		return fmt.Errorf("missing Zendesk configuration, subdomain, email and api_token are required")
+	}
+
+	// The endpoint can be overridden for a host mapped domain
+	endpoint := zendeskConfig.GetStringWithDefault(
+		"endpoint", fmt.Sprintf("https://%s.zendesk.com", subdomain))
+
+	p.endpoint = strings.TrimSuffix(endpoint, "/")
+
+	client, err := zendesk.NewClient(nil)
+
+	if err != nil {
+		return fmt.Errorf("failed to create Zendesk client: %w", err)
+	}
+
+	if err := client.SetEndpointURL(p.endpoint + "/api/v2"); err != nil {
+		return fmt.Errorf("invalid Zendesk endpoint %s: %w", p.endpoint, err)
+	}
+
+	// API tokens authenticate as the agent's email with a /token suffix
+	client.SetCredential(zendesk.NewAPITokenCredential(email, apiToken))
+
+	p.client = client
+
+	p.group = zendeskConfig.GetStringWithDefault("group", "")
+	p.requireAssignee, _ = zendeskConfig.GetBool("require_assignee")
+
+	return nil
+}
+
+// TestConnection fetches the agent the provider authenticates as
+func (p *zendeskProvider) TestConnection(ctx context.Context, capability models.ProviderCapability) error {
+	switch capability {
+	case models.ProviderCapabilityRBAC:
+		if _, err := p.client.Get(ctx, "/users/me.json"); err != nil {
+			return fmt.Errorf("failed to get the Zendesk agent: %w", getError(err))
+		}
+		return nil
+	}
+	return models.ErrNotImplemented
+}
+
+// getError returns the message from a Zendesk error response as the client
+// only reports the body, or err if it isn't one
+func getError(err error) error {
+
+	var zendeskError zendesk.Error
+
+	if !errors.As(err, &zendeskError) {
+		return err
+	}
+
+	body, readErr := io.ReadAll(zendeskError.Body())
+
+	if readErr != nil {
+		return err
+	}
+
+	return fmt.Errorf("zendesk returned status %d: %s", zendeskError.Status(), getErrorMessage(body))
+}
+
+// getErrorMessage returns the message from a Zendesk error response, or
+// the body if it isn't one. Errors are either a title and message or an
+// error code with a description.
+func getErrorMessage(body []byte) string {
+
+	var zendeskError struct {
+		Error       json.RawMessage `json:"error"`
+		Description string          `json:"description"`
+	}
+
+	if err := json.Unmarshal(body, &zendeskError); err != nil || len(zendeskError.Error) == 0 {
+		return string(body)
+	}
+
+	var detailed struct {
+		Title   string `json:"title"`
+		Message string `json:"message"`
+	}
+
+	if err := json.Unmarshal(zendeskError.Error, &detailed); err == nil {
+		return strings.TrimSuffix(fmt.Sprintf("%s: %s", detailed.Title, detailed.Message), ": ")
+	}
+
+	var code string
+
+	if err := json.Unmarshal(zendeskError.Error, &code); err != nil {
+		return string(body)
+	}
+
+	if len(zendeskError.Description) > 0 {
+		return fmt.Sprintf("%s: %s", code, zendeskError.Description)
+	}
+
+	return code
+}
+
+// getCursorPagination returns the cursor for the page the pagination
+// points at
+func getCursorPagination(pagination *models.PaginationOptions) zendesk.CursorPagination {
+
+	cursor := zendesk.CursorPagination{PageSize: defaultPageSize}
+
+	if pagination != nil && pagination.PageSize > 0 {
+		cursor.PageSize = min(pagination.PageSize, defaultPageSize)
+	}
+
+	if pagination != nil {
+		cursor.PageAfter = pagination.Token
+	}
+
+	return cursor
+}
+
+// getNextPage returns the pagination for the next page, or nil on the last
+func getNextPage(meta zendesk.CursorPaginationMeta, pagination *models.PaginationOptions) *models.PaginationOptions {
+
+	if !meta.HasMore || len(meta.AfterCursor) == 0 {
+		return nil
+	}
+
+	next := &models.PaginationOptions{Token: meta.AfterCursor}
+
+	if pagination != nil {
+		next.PageSize = pagination.PageSize
+	}
+
+	return next
+}
+
+func init() {
+	providers.Register(ZendeskProviderName, &zendeskProvider{})
+}
@@ -0,0 +1,298 @@
+package zendesk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nukosuke/go-zendesk/zendesk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// fakeZendesk serves the parts of the Zendesk API the provider uses
+type fakeZendesk struct {
+	tickets map[string]map[string]any
+	groups  map[string]map[string]any
+	users   map[string]map[string]any
+}
+
+func newFakeZendesk() *fakeZendesk {
+	return &fakeZendesk{
+		tickets: map[string]map[string]any{
+			"100": {"id": 100, "status": "open", "group_id": 1, "assignee_id": 10},
+			"101": {"id": 101, "status": "solved", "group_id": 1, "assignee_id": 10},
+			"102": {"id": 102, "status": "pending", "group_id": 2, "assignee_id": 11},
+			"103": {"id": 103, "status": "new", "group_id": nil, "assignee_id": nil},
+		},
+		groups: map[string]map[string]any{
+			"1": {"id": 1, "name": "Tier 2"},
+			"2": {"id": 2, "name": "Billing"},
+			"3": {"id": 3, "name": "Retired", "deleted": true},
+		},
+		users: map[string]map[string]any{
+			"10": {"id": 10, "name": "Alice", "email": "alice@example.com", "role": "agent", "active": true},
+			"11": {"id": 11, "name": "Bob", "email": "bob@example.com", "role": "admin", "active": true},
+			"12": {"id": 12, "name": "Carol", "email": "carol@example.com", "role": "agent", "active": true, "suspended": true},
+		},
+	}
+}
+
+func (f *fakeZendesk) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	if username, password, ok := r.BasicAuth(); !ok || username != "bot@example.com/token" || password != "secret" {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "Couldn't authenticate you"})
+		return
+	}
+
+	path := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v2/"), ".json")
+	parts := strings.Split(path, "/")
+
+	switch {
+	case path == "users/me":
+		writeJSON(w, http.StatusOK, map[string]any{"user": f.users["11"]})
+
+	case path == "groups":
+		// Two pages of one and two groups
+		if r.URL.Query().Get("page[after]") == "" {
+			writeJSON(w, http.StatusOK, map[string]any{
+				"groups": []any{f.groups["1"]},
+				"meta":   map[string]any{"has_more": true, "after_cursor": "next"},
+			})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"groups": []any{f.groups["2"], f.groups["3"]},
+			"meta":   map[string]any{"has_more": false},
+		})
+
+	case path == "users":
+		if got := r.URL.Query()["role[]"]; len(got) != 2 {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "InvalidValue", "description": "role[] is required"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"users": []any{f.users["10"], f.users["11"], f.users["12"]},
+			"meta":  map[string]any{"has_more": false},
+		})
+
+	case len(parts) == 2:
+		var records map[string]map[string]any
+		var name string
+
+		switch parts[0] {
+		case "tickets":
+			records, name = f.tickets, "ticket"
+		case "groups":
+			records, name = f.groups, "group"
+		case "users":
+			records, name = f.users, "user"
+		}
+
+		record, found := records[parts[1]]
+		if !found {
+			writeJSON(w, http.StatusNotFound, map[string]any{
+				"error": map[string]any{"title": "No help desk at this address", "message": ""},
+			})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{name: record})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func newTestProvider(t *testing.T, fake *fakeZendesk, config models.BasicConfig) *zendeskProvider {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	config["subdomain"] = "acme"
+	config["endpoint"] = server.URL
+	config["email"] = "bot@example.com"
+	config["api_token"] = "secret"
+
+	provider := &zendeskProvider{}
+	require.NoError(t, provider.ValidateConfig(&config))
+	require.NoError(t, provider.Initialize(context.Background(), ZendeskProviderName, models.Provider{
+		Name:     ZendeskProviderName,
+		Provider: ZendeskProviderName,
+		Config:   &config,
+	}))
+
+	return provider
+}
+
+func TestTestConnection(t *testing.T) {
+	provider := newTestProvider(t, newFakeZendesk(), models.BasicConfig{})
+
+	assert.NoError(t, provider.TestConnection(context.Background(), models.ProviderCapabilityRBAC))
+
+	provider.client.SetCredential(zendesk.NewAPITokenCredential("bot@example.com", "wrong"))
+	assert.EqualError(t, provider.TestConnection(context.Background(), models.ProviderCapabilityRBAC),
+		"failed to get the Zendesk agent: zendesk returned status 401: Couldn't authenticate you")
+}
+
+func TestSynchronizeRoles(t *testing.T) {
+	provider := newTestProvider(t, newFakeZendesk(), models.BasicConfig{})
+
+	resp, err := provider.SynchronizeRoles(context.Background(), &models.SynchronizeRolesRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Roles, 1)
+	assert.Equal(t, "Tier 2", resp.Roles[0].Name)
+	assert.Equal(t, "1", resp.Roles[0].ID)
+	require.NotNil(t, resp.Pagination)
+	assert.Equal(t, "next", resp.Pagination.Token)
+
+	// Deleted groups aren't roles
+	resp, err = provider.SynchronizeRoles(context.Background(), &models.SynchronizeRolesRequest{
+		Pagination: resp.Pagination,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Roles, 1)
+	assert.Equal(t, "Billing", resp.Roles[0].Name)
+	assert.Nil(t, resp.Pagination)
+}
+
+func TestSynchronizeUsers(t *testing.T) {
+	provider := newTestProvider(t, newFakeZendesk(), models.BasicConfig{})
+
+	resp, err := provider.SynchronizeUsers(context.Background(), &models.SynchronizeUsersRequest{})
+	require.NoError(t, err)
+	assert.Nil(t, resp.Pagination)
+
+	// Suspended agents aren't identities
+	require.Len(t, resp.Identities, 2)
+	assert.Equal(t, "alice@example.com", resp.Identities[0].ID)
+	assert.Equal(t, "10", resp.Identities[0].User.ID)
+	assert.Equal(t, "bob@example.com", resp.Identities[1].ID)
+}
+
+func TestAuthorizeRole(t *testing.T) {
+
+	authorize := func(provider *zendeskProvider, role *models.Role, email string, reason string) (*models.AuthorizeRoleResponse, error) {
+		return provider.AuthorizeRole(context.Background(), &models.AuthorizeRoleRequest{
+			RoleRequest: &models.RoleRequest{
+				User:  &models.User{Email: email},
+				Role:  role,
+				Audit: &models.AuditContext{Reason: reason},
+			},
+		})
+	}
+
+	tier2 := &models.Role{Name: "tier2-support", Inherits: []string{"Tier 2"}}
+
+	t.Run("open ticket in the group", func(t *testing.T) {
+		provider := newTestProvider(t, newFakeZendesk(), models.BasicConfig{})
+
+		resp, err := authorize(provider, tier2, "alice@example.com", "Investigating #100 for a customer")
+		require.NoError(t, err)
+		assert.Equal(t, "100", resp.Metadata[MetadataTicketID])
+		assert.Equal(t, "Tier 2", resp.Metadata[MetadataTicketGroup])
+		assert.Equal(t, provider.endpoint+"/agent/tickets/100",
+			provider.GetAuthorizedAccessUrl(context.Background(), nil, resp))
+	})
+
+	t.Run("ticket links and group IDs", func(t *testing.T) {
+		provider := newTestProvider(t, newFakeZendesk(), models.BasicConfig{"group": "2"})
+
+		resp, err := authorize(provider, &models.Role{Name: "billing"}, "bob@example.com",
+			"https://acme.zendesk.com/agent/tickets/102")
+		require.NoError(t, err)
+		assert.Equal(t, "102", resp.Metadata[MetadataTicketID])
+	})
+
+	t.Run("solved ticket", func(t *testing.T) {
+		provider := newTestProvider(t, newFakeZendesk(), models.BasicConfig{})
+
+		_, err := authorize(provider, tier2, "alice@example.com", "ticket 101")
+		assert.EqualError(t, err, "zendesk ticket 101 is solved, it must be open before tier2-support can be granted")
+	})
+
+	t.Run("ticket in another group", func(t *testing.T) {
+		provider := newTestProvider(t, newFakeZendesk(), models.BasicConfig{})
+
+		_, err := authorize(provider, tier2, "alice@example.com", "#102")
+		assert.EqualError(t, err, "zendesk ticket 102 is in the Billing group, tier2-support can only be granted for tickets in Tier 2")
+	})
+
+	t.Run("ticket without a group", func(t *testing.T) {
+		provider := newTestProvider(t, newFakeZendesk(), models.BasicConfig{})
+
+		_, err := authorize(provider, tier2, "alice@example.com", "#103")
+		assert.EqualError(t, err, "zendesk ticket 103 isn't assigned to a group")
+	})
+
+	t.Run("no ticket", func(t *testing.T) {
+		provider := newTestProvider(t, newFakeZendesk(), models.BasicConfig{})
+
+		_, err := authorize(provider, tier2, "alice@example.com", "customer escalation")
+		assert.EqualError(t, err, "a Zendesk ticket must be referenced in the reason to request tier2-support")
+	})
+
+	t.Run("missing ticket", func(t *testing.T) {
+		provider := newTestProvider(t, newFakeZendesk(), models.BasicConfig{})
+
+		_, err := authorize(provider, tier2, "alice@example.com", "#999")
+		assert.EqualError(t, err, "failed to get Zendesk ticket 999: zendesk returned status 404: No help desk at this address")
+	})
+
+	t.Run("role without a group", func(t *testing.T) {
+		provider := newTestProvider(t, newFakeZendesk(), models.BasicConfig{})
+
+		_, err := authorize(provider, &models.Role{Name: "support"}, "alice@example.com", "#100")
+		assert.EqualError(t, err, "role support must inherit a Zendesk group, or a default group must be configured")
+	})
+
+	t.Run("requires the assignee", func(t *testing.T) {
+		provider := newTestProvider(t, newFakeZendesk(), models.BasicConfig{"require_assignee": true})
+
+		_, err := authorize(provider, tier2, "alice@example.com", "#100")
+		assert.NoError(t, err)
+
+		_, err = authorize(provider, tier2, "bob@example.com", "#100")
+		assert.EqualError(t, err, "zendesk ticket 100 is assigned to alice@example.com, not bob@example.com")
+	})
+}
+
+func TestValidateConfig(t *testing.T) {
+	provider := &zendeskProvider{}
+
+	err := provider.ValidateConfig(&models.BasicConfig{"subdomain": "acme"})
+	assert.EqualError(t, err, "invalid zendesk provider config: email is required; api_token is required")
+
+	assert.NoError(t, provider.ValidateConfig(&models.BasicConfig{
+		"subdomain": "acme",
+		"email":     "bot@example.com",
+		"api_token": "secret",
+	}))
+}
+
+func TestGetLinkedTicket(t *testing.T) {
+	tests := map[string]int64{
+		"Working on #12345": 12345,
+		"ticket 42 and #43": 42,
+		"Ticket #7":         7,
+		"https://acme.zendesk.com/agent/tickets/555": 555,
+		"no ticket here": 0,
+		"version 2":      0,
+	}
+
+	for reason, expected := range tests {
+		assert.Equal(t, expected, getLinkedTicket(&models.AuditContext{Reason: reason}), reason)
+	}
+
+	assert.Zero(t, getLinkedTicket(nil))
+}
@@ -0,0 +1,205 @@
+package zendesk
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nukosuke/go-zendesk/zendesk"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// Metadata keys recording the ticket that authorized the request
+const (
+	MetadataTicketID    = "zendesk_ticket"
+	MetadataTicketGroup = "zendesk_group"
+)
+
+// ticketPattern matches a ticket referenced in a request's reason e.g.
+// #12345, ticket 12345 or a link to /agent/tickets/12345
+var ticketPattern = regexp.MustCompile(`(?i)(?:#|\btickets?[ /:#-]*)(\d+)\b`)
+
+// Tickets that are solved or closed no longer need an agent
+var closedStatuses = []string{"solved", "closed"}
+
+// AuthorizeRole grants nothing itself, it only succeeds while the ticket
+// referenced in the request's reason is open in one of the role's groups.
+// Workflows use it ahead of the provider that grants the role.
+func (p *zendeskProvider) AuthorizeRole(
+	ctx context.Context,
+	req *models.AuthorizeRoleRequest,
+) (*models.AuthorizeRoleResponse, error) {
+
+	if !req.IsValid() {
+		return nil, fmt.Errorf("user and role must be provided to authorize zendesk role")
+	}
+
+	user := req.GetUser()
+	role := req.GetRole()
+
+	groups := p.getRoleGroups(role)
+
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("role %s must inherit a Zendesk group, or a default group must be configured", role.Name)
+	}
+
+	ticketID := getLinkedTicket(req.GetAudit())
+
+	if ticketID == 0 {
+		return nil, fmt.Errorf("a Zendesk ticket must be referenced in the reason to request %s", role.Name)
+	}
+
+	ticket, err := p.getTicket(ctx, ticketID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Contains(closedStatuses, strings.ToLower(ticket.Status)) {
+		return nil, fmt.Errorf("zendesk ticket %d is %s, it must be open before %s can be granted",
+			ticket.ID, ticket.Status, role.Name)
+	}
+
+	// Tickets without a group have no group ID rather than zero
+	groupID, err := ticket.GroupID.Int64()
+
+	if err != nil {
+		return nil, fmt.Errorf("zendesk ticket %d isn't assigned to a group", ticket.ID)
+	}
+
+	group, err := p.getGroup(ctx, groupID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.ContainsFunc(groups, func(allowed string) bool {
+		return allowed == strconv.FormatInt(group.ID, 10) || strings.EqualFold(allowed, group.Name)
+	}) {
+		return nil, fmt.Errorf("zendesk ticket %d is in the %s group, %s can only be granted for tickets in %s",
+			ticket.ID, group.Name, role.Name, strings.Join(groups, ", "))
+	}
+
+	if p.requireAssignee {
+		if err := p.checkAssignee(ctx, ticket, user); err != nil {
+			return nil, err
+		}
+	}
+
+	p.GetLogger(ctx).WithFields(logrus.Fields{
+		"ticket": ticket.ID,
+		"group":  group.Name,
+		"role":   role.Name,
+	}).Info("Zendesk ticket is open")
+
+	return &models.AuthorizeRoleResponse{
+		UserId: user.Email,
+		Roles:  []string{role.Name},
+		Metadata: map[string]any{
+			MetadataTicketID:    strconv.FormatInt(ticket.ID, 10),
+			MetadataTicketGroup: group.Name,
+		},
+	}, nil
+}
+
+// RevokeRole has nothing to remove as authorizing only checked the ticket
+func (p *zendeskProvider) RevokeRole(
+	ctx context.Context,
+	req *models.RevokeRoleRequest,
+) (*models.RevokeRoleResponse, error) {
+	return &models.RevokeRoleResponse{}, nil
+}
+
+// GetAuthorizedAccessUrl links to the ticket in the agent workspace
+func (p *zendeskProvider) GetAuthorizedAccessUrl(
+	ctx context.Context,
+	req *models.AuthorizeRoleRequest,
+	resp *models.AuthorizeRoleResponse,
+) string {
+
+	if resp == nil {
+		return ""
+	}
+
+	ticketID, ok := resp.Metadata[MetadataTicketID].(string)
+
+	if !ok || len(ticketID) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/agent/tickets/%s", p.endpoint, ticketID)
+}
+
+// getRoleGroups returns the groups, by name or ID, whose tickets the role
+// can be granted for. Roles inherit groups, otherwise the configured
+// group is used.
+func (p *zendeskProvider) getRoleGroups(role *models.Role) []string {
+
+	if len(role.Inherits) > 0 {
+		return role.Inherits
+	}
+
+	if len(p.group) > 0 {
+		return []string{p.group}
+	}
+
+	return nil
+}
+
+// checkAssignee checks the ticket is assigned to the agent requesting access
+func (p *zendeskProvider) checkAssignee(ctx context.Context, ticket *zendesk.Ticket, user *models.User) error {
+
+	if ticket.AssigneeID == 0 {
+		return fmt.Errorf("zendesk ticket %d must be assigned to %s", ticket.ID, user.Email)
+	}
+
+	assignee, err := p.getUser(ctx, ticket.AssigneeID)
+
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(assignee.Email, user.Email) {
+		return fmt.Errorf("zendesk ticket %d is assigned to %s, not %s", ticket.ID, assignee.Email, user.Email)
+	}
+
+	return nil
+}
+
+// getTicket returns the ticket with the ID
+func (p *zendeskProvider) getTicket(ctx context.Context, ticketID int64) (*zendesk.Ticket, error) {
+
+	ticket, err := p.client.GetTicket(ctx, ticketID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Zendesk ticket %d: %w", ticketID, getError(err))
+	}
+
+	return &ticket, nil
+}
+
+// getLinkedTicket returns the first ticket referenced in the reason, or 0
+func getLinkedTicket(audit *models.AuditContext) int64 {
+
+	if audit == nil {
+		return 0
+	}
+
+	match := ticketPattern.FindStringSubmatch(audit.Reason)
+
+	if match == nil {
+		return 0
+	}
+
+	ticketID, err := strconv.ParseInt(match[1], 10, 64)
+
+	if err != nil {
+		return 0
+	}
+
+	return ticketID
+}
@@ -0,0 +1,66 @@
+package zendesk
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nukosuke/go-zendesk/zendesk"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func (p *zendeskProvider) CanSynchronizeRoles() bool {
+	return true
+}
+
+// SynchronizeRoles lists the agent groups as roles, a role is authorized
+// for tickets in its group
+func (p *zendeskProvider) SynchronizeRoles(ctx context.Context, req *models.SynchronizeRolesRequest) (*models.SynchronizeRolesResponse, error) {
+
+	startTime := time.Now()
+
+	groups, meta, err := p.client.GetGroupsCBP(ctx, &zendesk.CBPOptions{
+		CursorPagination: getCursorPagination(req.Pagination),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Zendesk groups: %w", getError(err))
+	}
+
+	roles := make([]models.ProviderRole, 0, len(groups))
+
+	for _, group := range groups {
+		if group.Deleted {
+			continue
+		}
+		roles = append(roles, models.ProviderRole{
+			ID:          strconv.FormatInt(group.ID, 10),
+			Name:        group.Name,
+			Description: group.Description,
+			Role:        group,
+		})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"roles": len(roles),
+	}).Debugf("Refreshed Zendesk groups in %s", time.Since(startTime))
+
+	return &models.SynchronizeRolesResponse{
+		Pagination: getNextPage(meta, req.Pagination),
+		Roles:      roles,
+	}, nil
+}
+
+// getGroup returns the group with the ID
+func (p *zendeskProvider) getGroup(ctx context.Context, groupID int64) (*zendesk.Group, error) {
+
+	group, err := p.client.GetGroup(ctx, groupID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Zendesk group %d: %w", groupID, getError(err))
+	}
+
+	return &group, nil
+}
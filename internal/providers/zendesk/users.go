@@ -0,0 +1,73 @@
+package zendesk
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nukosuke/go-zendesk/zendesk"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func (p *zendeskProvider) CanSynchronizeUsers() bool {
+	return true
+}
+
+// SynchronizeUsers lists the agents and admins as identities, end users
+// aren't listed
+func (p *zendeskProvider) SynchronizeUsers(ctx context.Context, req *models.SynchronizeUsersRequest) (*models.SynchronizeUsersResponse, error) {
+
+	startTime := time.Now()
+
+	users, meta, err := p.client.GetUsersCBP(ctx, &zendesk.CBPOptions{
+		CursorPagination: getCursorPagination(req.Pagination),
+		CommonOptions: zendesk.CommonOptions{
+			Roles: []string{"agent", "admin"},
+		},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Zendesk agents: %w", getError(err))
+	}
+
+	identities := make([]models.Identity, 0, len(users))
+
+	for _, user := range users {
+		if !user.Active || user.Suspended || len(user.Email) == 0 {
+			continue
+		}
+		identities = append(identities, models.Identity{
+			ID:    user.Email,
+			Label: user.Name,
+			User: &models.User{
+				ID:     strconv.FormatInt(user.ID, 10),
+				Email:  user.Email,
+				Name:   user.Name,
+				Source: ZendeskProviderName,
+			},
+		})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"identities": len(identities),
+	}).Debugf("Refreshed Zendesk agents in %s", time.Since(startTime))
+
+	return &models.SynchronizeUsersResponse{
+		Pagination: getNextPage(meta, req.Pagination),
+		Identities: identities,
+	}, nil
+}
+
+// getUser returns the user with the ID
+func (p *zendeskProvider) getUser(ctx context.Context, userID int64) (*zendesk.User, error) {
+
+	user, err := p.client.GetUser(ctx, userID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Zendesk user %d: %w", userID, getError(err))
+	}
+
+	return &user, nil
+}
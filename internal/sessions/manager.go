@@ -32,6 +32,8 @@ type LoginServer struct {
 	Version   string                         `json:"version" yaml:"version" default:"1.0"`
 	Timestamp time.Time                      `json:"timestamp" yaml:"timestamp"`
 	Sessions  map[string]models.LocalSession `json:"sessions" yaml:"sessions"`
+	// Provider is the auth provider last chosen to log in with
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty"`
 }
 
 func (l LoginServer) GetSessions() map[string]models.LocalSession {
@@ -61,6 +63,74 @@ func (l LoginServer) GetFirstActiveSession(providers ...string) (string, *models
 	return "", nil, fmt.Errorf("error")
 }
 
+// Session is a cached local session and the auth provider that issued it
+type Session struct {
+	Provider string `json:"provider" yaml:"provider"`
+	models.LocalSession
+}
+
+// SessionFilter decides whether a cached session is included in ListSessions
+type SessionFilter func(provider string, session *models.LocalSession) bool
+
+// FilterByExpiry excludes sessions that have already expired
+func FilterByExpiry() SessionFilter {
+	return func(_ string, session *models.LocalSession) bool {
+		return !session.IsExpired()
+	}
+}
+
+// FilterByProvider only includes sessions from the given auth providers
+func FilterByProvider(providers ...string) SessionFilter {
+	return func(provider string, _ *models.LocalSession) bool {
+		return len(providers) == 0 || slices.Contains(providers, provider)
+	}
+}
+
+// ListSessions returns the cached sessions for a login server sorted by
+// provider. Only sessions matching every filter are returned.
+func (m *SessionManager) ListSessions(loginServer string, filters ...SessionFilter) ([]*Session, error) {
+
+	loginServer = normalizeHostname(loginServer)
+
+	logrus.WithFields(logrus.Fields{
+		"loginServer": loginServer,
+	}).Debugln("Listing provider sessions")
+
+	server, err := m.GetLoginServer(loginServer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []*Session{}
+
+	for providerName, session := range server.GetSessions() {
+
+		included := true
+		for _, filter := range filters {
+			if !filter(providerName, &session) {
+				included = false
+				break
+			}
+		}
+
+		if !included {
+			continue
+		}
+
+		sessions = append(sessions, &Session{
+			Provider:     providerName,
+			LocalSession: session,
+		})
+	}
+
+	slices.SortFunc(sessions, func(a, b *Session) int {
+		return strings.Compare(a.Provider, b.Provider)
+	})
+
+	return sessions, nil
+}
+
 func (m *SessionManager) AddSession(loginServer string, provider string, session models.LocalSession) error {
 
 	loginServer = normalizeHostname(loginServer)
@@ -94,6 +164,39 @@ func (m *SessionManager) RemoveSession(loginServer string, provider string) erro
 	return m.Commit(loginServer)
 }
 
+// SetLoginProvider remembers the auth provider chosen to log in to the
+// login server so the next login can reuse it
+func (m *SessionManager) SetLoginProvider(loginServer string, provider string) error {
+
+	loginServer = normalizeHostname(loginServer)
+
+	logrus.WithFields(logrus.Fields{
+		"loginServer": loginServer,
+		"provider":    provider,
+	}).Debugln("Setting login provider")
+
+	m.createLoginServer(loginServer)
+
+	server := m.Servers[loginServer]
+	server.Provider = provider
+	m.Servers[loginServer] = server
+
+	return m.Commit(loginServer)
+}
+
+// GetLoginProvider returns the auth provider last chosen to log in to the
+// login server, or an empty string if none has been chosen
+func (m *SessionManager) GetLoginProvider(loginServer string) string {
+
+	server, err := m.GetLoginServer(loginServer)
+
+	if err != nil {
+		return ""
+	}
+
+	return server.Provider
+}
+
 func (m *SessionManager) GetFirstActiveSession(loginServer string, providers ...string) (string, *models.LocalSession, error) {
 
 	loginServer = normalizeHostname(loginServer)
@@ -374,6 +477,23 @@ func GetSessionManager() *SessionManager {
 
 func loadSessionFile(logonServerHostName string) *os.File {
 
+	sessionPath := getSessionPath()
+
+	logonServer := fmt.Sprintf("%s.yaml", logonServerHostName)
+
+	// Only allow read/write access to the owner
+	file, err := os.OpenFile(
+		filepath.Join(sessionPath, logonServer), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		logrus.Fatalf("Failed to open session manager file: %v", err)
+	}
+	return file
+}
+
+// getSessionPath returns the directory sessions are stored in, creating it
+// if needed
+func getSessionPath() string {
+
 	// Determine the session path
 	var sessionPath string
 	if strings.HasPrefix(SESSION_MANAGER_PATH, "~") {
@@ -396,15 +516,7 @@ func loadSessionFile(logonServerHostName string) *os.File {
 		}
 	}
 
-	logonServer := fmt.Sprintf("%s.yaml", logonServerHostName)
-
-	// Only allow read/write access to the owner
-	file, err := os.OpenFile(
-		filepath.Join(sessionPath, logonServer), os.O_RDWR|os.O_CREATE, 0600)
-	if err != nil {
-		logrus.Fatalf("Failed to open session manager file: %v", err)
-	}
-	return file
+	return sessionPath
 }
 
 func (m *SessionManager) createLoginServer(loginServer string) {
@@ -340,6 +340,37 @@ func TestSessionManager_LoadAndCommit(t *testing.T) {
 	}
 }
 
+func TestSessionManager_LoginProvider(t *testing.T) {
+	setupTempSessionDir(t)
+
+	loginServer := "https://test.example.com"
+
+	manager1 := &SessionManager{
+		Servers: make(map[string]LoginServer),
+	}
+
+	if provider := manager1.GetLoginProvider(loginServer); provider != "" {
+		t.Errorf("Expected no login provider, got %s", provider)
+	}
+
+	if err := manager1.SetLoginProvider(loginServer, "okta"); err != nil {
+		t.Fatalf("Failed to set login provider: %v", err)
+	}
+
+	// The provider is kept alongside the sessions
+	manager2 := &SessionManager{
+		Servers: make(map[string]LoginServer),
+	}
+
+	if err := manager2.Load(loginServer); err != nil {
+		t.Fatalf("Failed to load sessions: %v", err)
+	}
+
+	if provider := manager2.GetLoginProvider("test.example.com"); provider != "okta" {
+		t.Errorf("Expected login provider okta, got %s", provider)
+	}
+}
+
 func TestSessionManager_Load_NonExistentFile(t *testing.T) {
 	setupTempSessionDir(t)
 
@@ -622,3 +653,61 @@ func setupTempSessionDir(t *testing.T) string {
 
 	return tmpDir
 }
+
+func TestSessionManager_ListSessions(t *testing.T) {
+	sm := &SessionManager{
+		Servers: map[string]LoginServer{
+			"test.example.com": {
+				Version:   "1.0",
+				Timestamp: time.Now(),
+				Sessions: map[string]models.LocalSession{
+					"okta":   {Version: 1, Expiry: time.Now().Add(1 * time.Hour)},
+					"google": {Version: 1, Expiry: time.Now().Add(-1 * time.Hour)},
+					"github": {Version: 1, Expiry: time.Now().Add(2 * time.Hour)},
+				},
+			},
+		},
+	}
+
+	all, err := sm.ListSessions("https://test.example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(all) != 3 {
+		t.Fatalf("Expected 3 sessions, got %d", len(all))
+	}
+
+	// Sessions are sorted by provider
+	if all[0].Provider != "github" || all[1].Provider != "google" || all[2].Provider != "okta" {
+		t.Errorf("Expected sessions sorted by provider, got %s, %s, %s",
+			all[0].Provider, all[1].Provider, all[2].Provider)
+	}
+
+	active, err := sm.ListSessions("test.example.com", FilterByExpiry())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(active) != 2 {
+		t.Errorf("Expected 2 active sessions, got %d", len(active))
+	}
+
+	filtered, err := sm.ListSessions("test.example.com", FilterByExpiry(), FilterByProvider("google", "okta"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(filtered) != 1 || filtered[0].Provider != "okta" {
+		t.Errorf("Expected only the active okta session, got %v", filtered)
+	}
+
+	empty, err := sm.ListSessions("unknown.example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(empty) != 0 {
+		t.Errorf("Expected no sessions for unknown login server, got %d", len(empty))
+	}
+}
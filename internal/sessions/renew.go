@@ -0,0 +1,128 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// LOCK_STALE_TIME is how old a session lock can be before it's treated as
+// left behind by a process that exited without removing it
+var LOCK_STALE_TIME = 30 * time.Second
+var LOCK_RETRY_TIME = 50 * time.Millisecond
+
+// SessionRenewer renews a session with the login server
+type SessionRenewer func(ctx context.Context, provider string, session *models.LocalSession) (*models.LocalSession, error)
+
+// ExpiresWithin returns true if the session has expired or expires within
+// the threshold
+func ExpiresWithin(session *models.LocalSession, threshold time.Duration) bool {
+	return time.Until(session.Expiry) < threshold
+}
+
+// RenewSession renews the session of a provider if it expires within the
+// threshold and stores the renewed session. The session file is locked
+// while renewing so when several CLI invocations race to renew the same
+// session only the first renews it and the others use the renewed session.
+func (m *SessionManager) RenewSession(
+	ctx context.Context,
+	loginServer string,
+	provider string,
+	threshold time.Duration,
+	renew SessionRenewer,
+) (*models.LocalSession, error) {
+
+	loginServer = normalizeHostname(loginServer)
+
+	unlock, err := lockSessionFile(ctx, loginServer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer unlock()
+
+	// Another invocation may have renewed the session while we waited
+	if err := m.Load(loginServer); err != nil {
+		return nil, fmt.Errorf("failed to load sessions: %w", err)
+	}
+
+	session, err := m.GetSession(loginServer, provider)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ExpiresWithin(session, threshold) {
+		return session, nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"loginServer":   loginServer,
+		"provider":      provider,
+		"sessionExpiry": session.Expiry,
+	}).Debugln("Renewing provider session")
+
+	renewed, err := renew(ctx, provider, session)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(renewed.Endpoint) == 0 {
+		renewed.Endpoint = session.Endpoint
+	}
+
+	m.Servers[loginServer].Sessions[provider] = *renewed
+
+	if err := m.Commit(loginServer); err != nil {
+		return nil, fmt.Errorf("failed to store renewed session: %w", err)
+	}
+
+	return renewed, nil
+}
+
+// lockSessionFile takes the lock for a login server's session file,
+// waiting until it's released or the context is done. The lock is a file
+// next to the sessions so it works across processes and platforms.
+func lockSessionFile(ctx context.Context, loginServer string) (func(), error) {
+
+	lockPath := filepath.Join(getSessionPath(), fmt.Sprintf("%s.lock", loginServer))
+
+	for {
+
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+
+		if err == nil {
+			file.Close()
+			return func() {
+				if err := os.Remove(lockPath); err != nil {
+					logrus.WithError(err).Warnln("Failed to release session lock")
+				}
+			}, nil
+		}
+
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("failed to lock sessions: %w", err)
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > LOCK_STALE_TIME {
+			logrus.WithField("lock", lockPath).Warnln("Removing stale session lock")
+			os.Remove(lockPath)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for session lock: %w", ctx.Err())
+		case <-time.After(LOCK_RETRY_TIME):
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func newRenewTestManager(t *testing.T, loginServer string, expiry time.Time) *SessionManager {
+	t.Helper()
+
+	sm := &SessionManager{Servers: make(map[string]LoginServer)}
+	require.NoError(t, sm.AddSession(loginServer, "google", models.LocalSession{
+		Version:  1,
+		Expiry:   expiry,
+		Session:  "original",
+		Endpoint: "https://" + loginServer,
+	}))
+
+	return sm
+}
+
+func renewFor(duration time.Duration, calls *atomic.Int32) SessionRenewer {
+	return func(_ context.Context, _ string, _ *models.LocalSession) (*models.LocalSession, error) {
+		calls.Add(1)
+		// Give racing invocations a chance to try to renew as well
+		time.Sleep(20 * time.Millisecond)
+		return &models.LocalSession{
+			Version: 1,
+			Expiry:  time.Now().Add(duration),
+			Session: "renewed",
+		}, nil
+	}
+}
+
+func TestSessionManager_RenewSession(t *testing.T) {
+	loginServer := "test.example.com"
+
+	t.Run("renews a session near expiry", func(t *testing.T) {
+		setupTempSessionDir(t)
+		sm := newRenewTestManager(t, loginServer, time.Now().Add(5*time.Minute))
+
+		var calls atomic.Int32
+		session, err := sm.RenewSession(context.Background(), loginServer, "google", 10*time.Minute, renewFor(time.Hour, &calls))
+		require.NoError(t, err)
+		assert.Equal(t, "renewed", session.Session)
+		assert.Equal(t, "https://"+loginServer, session.Endpoint)
+		assert.EqualValues(t, 1, calls.Load())
+
+		// The renewed session is persisted
+		other := &SessionManager{Servers: make(map[string]LoginServer)}
+		require.NoError(t, other.Load(loginServer))
+		stored, err := other.GetSession(loginServer, "google")
+		require.NoError(t, err)
+		assert.Equal(t, "renewed", stored.Session)
+
+		_, err = os.Stat(filepath.Join(getSessionPath(), loginServer+".lock"))
+		assert.True(t, os.IsNotExist(err), "lock should be released")
+	})
+
+	t.Run("leaves sessions that aren't near expiry", func(t *testing.T) {
+		setupTempSessionDir(t)
+		sm := newRenewTestManager(t, loginServer, time.Now().Add(time.Hour))
+
+		var calls atomic.Int32
+		session, err := sm.RenewSession(context.Background(), loginServer, "google", 10*time.Minute, renewFor(time.Hour, &calls))
+		require.NoError(t, err)
+		assert.Equal(t, "original", session.Session)
+		assert.Zero(t, calls.Load())
+	})
+
+	t.Run("failed renewal keeps the session", func(t *testing.T) {
+		setupTempSessionDir(t)
+		sm := newRenewTestManager(t, loginServer, time.Now().Add(5*time.Minute))
+
+		renewErr := errors.New("refresh token revoked")
+		_, err := sm.RenewSession(context.Background(), loginServer, "google", 10*time.Minute,
+			func(context.Context, string, *models.LocalSession) (*models.LocalSession, error) {
+				return nil, renewErr
+			})
+		assert.ErrorIs(t, err, renewErr)
+
+		stored, err := sm.GetSession(loginServer, "google")
+		require.NoError(t, err)
+		assert.Equal(t, "original", stored.Session)
+	})
+
+	t.Run("concurrent invocations renew once", func(t *testing.T) {
+		setupTempSessionDir(t)
+		newRenewTestManager(t, loginServer, time.Now().Add(5*time.Minute))
+
+		var calls atomic.Int32
+		var wg sync.WaitGroup
+
+		for range 5 {
+			// Each manager stands in for a separate CLI process
+			sm := &SessionManager{Servers: make(map[string]LoginServer)}
+			wg.Go(func() {
+				session, err := sm.RenewSession(context.Background(), loginServer, "google", 10*time.Minute, renewFor(time.Hour, &calls))
+				assert.NoError(t, err)
+				assert.Equal(t, "renewed", session.Session)
+			})
+		}
+
+		wg.Wait()
+		assert.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("stale locks are removed", func(t *testing.T) {
+		setupTempSessionDir(t)
+		sm := newRenewTestManager(t, loginServer, time.Now().Add(5*time.Minute))
+
+		lockPath := filepath.Join(getSessionPath(), loginServer+".lock")
+		require.NoError(t, os.WriteFile(lockPath, nil, 0600))
+		stale := time.Now().Add(-2 * LOCK_STALE_TIME)
+		require.NoError(t, os.Chtimes(lockPath, stale, stale))
+
+		var calls atomic.Int32
+		_, err := sm.RenewSession(context.Background(), loginServer, "google", 10*time.Minute, renewFor(time.Hour, &calls))
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("waiting for the lock respects the context", func(t *testing.T) {
+		setupTempSessionDir(t)
+		sm := newRenewTestManager(t, loginServer, time.Now().Add(5*time.Minute))
+
+		require.NoError(t, os.WriteFile(filepath.Join(getSessionPath(), loginServer+".lock"), nil, 0600))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		var calls atomic.Int32
+		_, err := sm.RenewSession(ctx, loginServer, "google", 10*time.Minute, renewFor(time.Hour, &calls))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Zero(t, calls.Load())
+	})
+}
@@ -0,0 +1,175 @@
+package sessions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/thand-io/agent/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+const templatesFileName = "templates.yaml"
+
+// ErrTemplateNotFound is returned when no template has the name
+var ErrTemplateNotFound = errors.New("template not found")
+
+type templatesFile struct {
+	Version   string                            `yaml:"version"`
+	Templates map[string]models.RequestTemplate `yaml:"templates"`
+}
+
+// TemplateStore keeps the user's saved request templates next to their
+// sessions
+type TemplateStore struct {
+	lock sync.Mutex
+	path string
+}
+
+// NewTemplateStore returns the store in the user's config directory
+func NewTemplateStore() *TemplateStore {
+	return &TemplateStore{
+		path: filepath.Join(getSessionPath(), templatesFileName),
+	}
+}
+
+// List returns the saved templates sorted by name
+func (s *TemplateStore) List() ([]models.RequestTemplate, error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	saved, err := s.load()
+
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make([]models.RequestTemplate, 0, len(saved.Templates))
+
+	for _, template := range saved.Templates {
+		templates = append(templates, template)
+	}
+
+	slices.SortFunc(templates, func(a, b models.RequestTemplate) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return templates, nil
+}
+
+// Get returns the template with the name
+func (s *TemplateStore) Get(name string) (*models.RequestTemplate, error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	saved, err := s.load()
+
+	if err != nil {
+		return nil, err
+	}
+
+	template, found := saved.Templates[name]
+
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	return &template, nil
+}
+
+// Save adds the template, replacing any template with the same name
+func (s *TemplateStore) Save(template models.RequestTemplate) error {
+
+	if err := models.ValidateRequestTemplateName(template.Name); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	saved, err := s.load()
+
+	if err != nil {
+		return err
+	}
+
+	template.Source = models.RequestTemplateSourceLocal
+	saved.Templates[template.Name] = template
+
+	return s.commit(saved)
+}
+
+// Delete removes the template with the name
+func (s *TemplateStore) Delete(name string) error {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	saved, err := s.load()
+
+	if err != nil {
+		return err
+	}
+
+	if _, found := saved.Templates[name]; !found {
+		return fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	delete(saved.Templates, name)
+
+	return s.commit(saved)
+}
+
+func (s *TemplateStore) load() (*templatesFile, error) {
+
+	saved := &templatesFile{
+		Version:   "1.0",
+		Templates: map[string]models.RequestTemplate{},
+	}
+
+	data, err := os.ReadFile(s.path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return saved, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read templates: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, saved); err != nil {
+		return nil, fmt.Errorf("failed to parse templates %s: %w", s.path, err)
+	}
+
+	if saved.Templates == nil {
+		saved.Templates = map[string]models.RequestTemplate{}
+	}
+
+	for name, template := range saved.Templates {
+		template.Name = name
+		template.Source = models.RequestTemplateSourceLocal
+		saved.Templates[name] = template
+	}
+
+	return saved, nil
+}
+
+func (s *TemplateStore) commit(saved *templatesFile) error {
+
+	data, err := yaml.Marshal(saved)
+
+	if err != nil {
+		return fmt.Errorf("failed to encode templates: %w", err)
+	}
+
+	// Only allow read/write access to the owner
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write templates: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package sessions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestTemplateStore(t *testing.T) {
+	store := &TemplateStore{path: filepath.Join(t.TempDir(), templatesFileName)}
+
+	templates, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, templates)
+
+	require.NoError(t, store.Save(models.RequestTemplate{
+		Name:         "prod-ir",
+		Role:         "incident-responder",
+		Providers:    []string{"aws-prod"},
+		Duration:     "2h",
+		ReasonPrefix: "Incident response:",
+	}))
+	require.NoError(t, store.Save(models.RequestTemplate{
+		Name:      "dev-admin",
+		Role:      "admin",
+		Providers: []string{"aws-dev"},
+	}))
+
+	t.Run("list is sorted", func(t *testing.T) {
+		templates, err := store.List()
+		require.NoError(t, err)
+		require.Len(t, templates, 2)
+		assert.Equal(t, "dev-admin", templates[0].Name)
+		assert.Equal(t, "prod-ir", templates[1].Name)
+		assert.Equal(t, models.RequestTemplateSourceLocal, templates[1].Source)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		template, err := store.Get("prod-ir")
+		require.NoError(t, err)
+		assert.Equal(t, "incident-responder", template.Role)
+		assert.Equal(t, "Incident response: INC-42", template.GetReason("INC-42"))
+
+		_, err = store.Get("missing")
+		assert.ErrorIs(t, err, ErrTemplateNotFound)
+	})
+
+	t.Run("only the owner can read the file", func(t *testing.T) {
+		info, err := os.Stat(store.path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, store.Delete("dev-admin"))
+		assert.ErrorIs(t, store.Delete("dev-admin"), ErrTemplateNotFound)
+
+		templates, err := store.List()
+		require.NoError(t, err)
+		require.Len(t, templates, 1)
+		assert.Equal(t, "prod-ir", templates[0].Name)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		assert.Error(t, store.Save(models.RequestTemplate{Name: "../prod"}))
+	})
+}
@@ -0,0 +1,123 @@
+package siem
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	cefVendor  = "Thand"
+	cefProduct = "Agent"
+)
+
+// cefEventNames are the CEF names of the event types
+var cefEventNames = map[EventType]string{
+	EventElevationRequested: "Elevation requested",
+	EventElevationApproved:  "Elevation approved",
+	EventElevationDenied:    "Elevation denied",
+	EventRoleGranted:        "Role granted",
+	EventRoleRevoked:        "Role revoked",
+	EventGrantOrphaned:      "Orphaned grant",
+}
+
+// EncodeCEF encodes the event as CEF. The fields are mapped as:
+//
+//	signature ID  event type e.g. role.granted
+//	name          event name e.g. Role granted
+//	severity      3, 5 when denied or orphaned or 7 when the step failed
+//	rt            event time in milliseconds since the epoch
+//	act           event type
+//	outcome       success or failure
+//	suser         requester
+//	duser         identity access was granted to or revoked from
+//	cs1           role, labelled role
+//	cs2           provider, labelled provider
+//	cs3           workflow ID, labelled workflowId
+//	reason        reason given for the request
+//	msg           error when the step failed
+func EncodeCEF(event Event, version string) string {
+
+	name, found := cefEventNames[event.Type]
+
+	if !found {
+		name = string(event.Type)
+	}
+
+	header := strings.Join([]string{
+		"CEF:0",
+		escapeCEFHeader(cefVendor),
+		escapeCEFHeader(cefProduct),
+		escapeCEFHeader(version),
+		escapeCEFHeader(string(event.Type)),
+		escapeCEFHeader(name),
+		strconv.Itoa(getCEFSeverity(event)),
+	}, "|")
+
+	extensions := []string{
+		fmt.Sprintf("rt=%d", event.Timestamp.UnixMilli()),
+		"act=" + escapeCEFExtension(string(event.Type)),
+		"outcome=" + escapeCEFExtension(event.Outcome),
+	}
+
+	add := func(key string, value string) {
+		if len(value) > 0 {
+			extensions = append(extensions, key+"="+escapeCEFExtension(value))
+		}
+	}
+
+	add("suser", event.Requester)
+	add("duser", event.Identity)
+
+	if len(event.Role) > 0 {
+		extensions = append(extensions, "cs1Label=role")
+		add("cs1", event.Role)
+	}
+
+	if len(event.Provider) > 0 {
+		extensions = append(extensions, "cs2Label=provider")
+		add("cs2", event.Provider)
+	}
+
+	if len(event.WorkflowID) > 0 {
+		extensions = append(extensions, "cs3Label=workflowId")
+		add("cs3", event.WorkflowID)
+	}
+
+	add("reason", event.Reason)
+	add("msg", event.Error)
+
+	return header + "|" + strings.Join(extensions, " ")
+}
+
+func getCEFSeverity(event Event) int {
+	switch {
+	case event.Outcome == OutcomeFailure:
+		return 7
+	case event.Type == EventElevationDenied, event.Type == EventGrantOrphaned:
+		return 5
+	}
+	return 3
+}
+
+var cefHeaderEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`|`, `\|`,
+	"\r", " ",
+	"\n", " ",
+)
+
+var cefExtensionEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`=`, `\=`,
+	"\r", `\r`,
+	"\n", `\n`,
+)
+
+func escapeCEFHeader(value string) string {
+	return cefHeaderEscaper.Replace(value)
+}
+
+func escapeCEFExtension(value string) string {
+	return cefExtensionEscaper.Replace(value)
+}
@@ -0,0 +1,70 @@
+package siem
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+func newTestEvent(eventType EventType) Event {
+	return Event{
+		Type:       eventType,
+		Timestamp:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		WorkflowID: "wf-123",
+		Requester:  "alice@example.com",
+		Role:       "prod-admin",
+		Outcome:    OutcomeSuccess,
+		Reason:     "Investigate INC-42 | db=primary",
+	}
+}
+
+func TestEncodeCEF(t *testing.T) {
+	tests := []struct {
+		golden string
+		event  Event
+	}{
+		{"elevation.requested", newTestEvent(EventElevationRequested)},
+		{"elevation.approved", newTestEvent(EventElevationApproved)},
+		{"elevation.denied", newTestEvent(EventElevationDenied)},
+		{"role.granted", newTestEvent(EventRoleGranted).WithProvider("aws-prod", "alice@example.com")},
+		{"role.revoked", newTestEvent(EventRoleRevoked).WithProvider("aws-prod", "alice@example.com")},
+		{"grant.orphaned", newTestEvent(EventGrantOrphaned).WithProvider("aws-prod", "alice@example.com")},
+		{"role.granted.failure", newTestEvent(EventRoleGranted).
+			WithProvider("aws-prod", "alice@example.com").
+			WithError(errors.New("AccessDenied: not authorized\nretry later"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			encoded := EncodeCEF(tt.event, "1.0.0")
+			path := filepath.Join("testdata", "cef", tt.golden+".golden")
+
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(path, []byte(encoded+"\n"), 0644))
+			}
+
+			golden, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, strings.TrimSuffix(string(golden), "\n"), encoded)
+		})
+	}
+}
+
+func TestEncodeCEFEscaping(t *testing.T) {
+	event := newTestEvent(EventRoleGranted)
+	event.Role = `admin\ops`
+
+	encoded := EncodeCEF(event, "1.0|beta")
+
+	assert.True(t, strings.HasPrefix(encoded, `CEF:0|Thand|Agent|1.0\|beta|role.granted|`))
+	assert.Contains(t, encoded, `cs1=admin\\ops`)
+}
@@ -0,0 +1,96 @@
+// Package siem exports elevation events to a SIEM over syslog, either as
+// RFC 5424 messages with a JSON payload or as CEF. Security events e.g.
+// failed logins go to a separate audit log.
+package siem
+
+import (
+	"time"
+
+	"github.com/thand-io/agent/internal/models"
+)
+
+type EventType string
+
+const (
+	EventElevationRequested EventType = "elevation.requested"
+	EventElevationApproved  EventType = "elevation.approved"
+	EventElevationDenied    EventType = "elevation.denied"
+	EventRoleGranted        EventType = "role.granted"
+	EventRoleRevoked        EventType = "role.revoked"
+	EventGrantOrphaned      EventType = "grant.orphaned" // Found by the reconciliation sweep
+)
+
+// Event outcomes
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Event is a step in the lifecycle of an elevation
+type Event struct {
+	Type       EventType `json:"type"`
+	Timestamp  time.Time `json:"timestamp"`
+	WorkflowID string    `json:"workflow_id,omitempty"`
+	Requester  string    `json:"requester,omitempty"`
+	Role       string    `json:"role,omitempty"`
+	Provider   string    `json:"provider,omitempty"`
+	Identity   string    `json:"identity,omitempty"` // The identity access was granted to or revoked from
+	Outcome    string    `json:"outcome"`
+	Reason     string    `json:"reason,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// NewEvent creates an event for the elevation the workflow is handling
+func NewEvent(eventType EventType, workflowTask *models.WorkflowTask) Event {
+
+	event := Event{
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		Outcome:   OutcomeSuccess,
+	}
+
+	if workflowTask == nil {
+		return event
+	}
+
+	event.WorkflowID = workflowTask.WorkflowID
+
+	request, err := workflowTask.GetContextAsElevationRequest()
+
+	if err != nil || request == nil {
+		return event
+	}
+
+	if request.User != nil {
+		event.Requester = request.User.GetIdentity()
+	}
+
+	if request.Role != nil {
+		event.Role = request.Role.Name
+	}
+
+	event.Reason = request.Reason
+
+	return event
+}
+
+// WithProvider sets the provider and the identity the event applies to
+func (e Event) WithProvider(provider string, identity string) Event {
+	e.Provider = provider
+	e.Identity = identity
+	return e
+}
+
+// WithError marks the event as failed when there is an error
+func (e Event) WithError(err error) Event {
+	if err != nil {
+		e.Outcome = OutcomeFailure
+		e.Error = err.Error()
+	}
+	return e
+}
+
+// IsFailure returns true if the step failed or access was denied
+func (e Event) IsFailure() bool {
+	return e.Outcome == OutcomeFailure || e.Type == EventElevationDenied
+}
@@ -0,0 +1,158 @@
+package siem
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/thand-io/agent/internal/models"
+)
+
+type SecurityEventType string
+
+const (
+	SecurityEventAuthFailed        SecurityEventType = "auth.failed"
+	SecurityEventSAMLInvalid       SecurityEventType = "auth.saml_invalid"
+	SecurityEventIPBlocked         SecurityEventType = "access.ip_blocked"
+	SecurityEventRoleLimitExceeded SecurityEventType = "role.limit_exceeded"
+)
+
+// OutcomeDenied is the outcome of a request that was refused
+const OutcomeDenied = "denied"
+
+// SecurityEvent is something the security team should know about e.g. a
+// failed login, kept apart from the elevation events and access logs
+type SecurityEvent struct {
+	EventType     SecurityEventType `json:"event_type"`
+	ActorIP       string            `json:"actor_ip"`
+	ActorIdentity string            `json:"actor_identity"`
+	Resource      string            `json:"resource"` // What was acted on e.g. the request path or role
+	Outcome       string            `json:"outcome"`
+	Timestamp     time.Time         `json:"timestamp"`
+	Details       map[string]any    `json:"details"`
+}
+
+// NewSecurityEvent creates a security event that happened now
+func NewSecurityEvent(eventType SecurityEventType, outcome string) SecurityEvent {
+	return SecurityEvent{
+		EventType: eventType,
+		Outcome:   outcome,
+		Timestamp: time.Now().UTC(),
+		Details:   map[string]any{},
+	}
+}
+
+// WithDetail adds a detail to the event, empty values are skipped
+func (e SecurityEvent) WithDetail(key string, value any) SecurityEvent {
+	if value == nil || value == "" {
+		return e
+	}
+	if e.Details == nil {
+		e.Details = map[string]any{}
+	}
+	e.Details[key] = value
+	return e
+}
+
+// IsFailure returns true unless the outcome is a success
+func (e SecurityEvent) IsFailure() bool {
+	return e.Outcome != OutcomeSuccess
+}
+
+// SecurityAuditLogger writes security events to the audit log
+type SecurityAuditLogger interface {
+	Log(event SecurityEvent) error
+}
+
+// NewSecurityAuditLogger returns the audit logger for the config. The
+// syslog stream sends events to the logging.syslog server. Nothing is
+// logged when the audit log isn't configured.
+func NewSecurityAuditLogger(config *models.AuditLogConfig, syslog *models.SyslogConfig) (SecurityAuditLogger, error) {
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch config.GetStream() {
+	case models.AuditLogStreamStdout:
+		return NewJSONAuditLogger(os.Stdout), nil
+	case models.AuditLogStreamFile:
+		file, err := os.OpenFile(config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log: %w", err)
+		}
+		return NewJSONAuditLogger(file), nil
+	case models.AuditLogStreamSyslog:
+		sink, err := NewSyslogSink(syslog)
+		if err != nil {
+			return nil, fmt.Errorf("invalid logging.syslog for the audit log: %w", err)
+		}
+		return &syslogAuditLogger{sink: sink}, nil
+	}
+
+	return nopAuditLogger{}, nil
+}
+
+// JSONAuditLogger writes each event as a line of JSON
+type JSONAuditLogger struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewJSONAuditLogger writes events to out, which is closed with the logger
+// if it's a closer
+func NewJSONAuditLogger(out io.Writer) *JSONAuditLogger {
+	return &JSONAuditLogger{out: out}
+}
+
+func (l *JSONAuditLogger) Log(event SecurityEvent) error {
+
+	data, err := json.Marshal(event)
+
+	if err != nil {
+		return fmt.Errorf("failed to encode security event: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, err = l.out.Write(append(data, '\n'))
+
+	return err
+}
+
+func (l *JSONAuditLogger) Close() error {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Never close stdout
+	if closer, ok := l.out.(io.Closer); ok && l.out != os.Stdout {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// syslogAuditLogger queues events for the syslog server, it never blocks
+type syslogAuditLogger struct {
+	sink *SyslogSink
+}
+
+func (l *syslogAuditLogger) Log(event SecurityEvent) error {
+	l.sink.SendSecurityEvent(event)
+	return nil
+}
+
+func (l *syslogAuditLogger) Close() error {
+	return l.sink.Close()
+}
+
+type nopAuditLogger struct{}
+
+func (nopAuditLogger) Log(event SecurityEvent) error {
+	return nil
+}
@@ -0,0 +1,109 @@
+package siem
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func newTestSecurityEvent() SecurityEvent {
+	event := NewSecurityEvent(SecurityEventIPBlocked, OutcomeDenied).
+		WithDetail("allowlist", "admin_allowlist").
+		WithDetail("empty", "")
+	event.ActorIP = "203.0.113.5"
+	event.Resource = "POST /api/v1/execution/wf-1/revoke"
+	event.Timestamp = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return event
+}
+
+func TestJSONAuditLogger(t *testing.T) {
+
+	var out bytes.Buffer
+	logger := NewJSONAuditLogger(&out)
+
+	require.NoError(t, logger.Log(newTestSecurityEvent()))
+	require.NoError(t, logger.Log(NewSecurityEvent(SecurityEventAuthFailed, OutcomeFailure)))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &decoded))
+
+	assert.Equal(t, map[string]any{
+		"event_type":     "access.ip_blocked",
+		"actor_ip":       "203.0.113.5",
+		"actor_identity": "",
+		"resource":       "POST /api/v1/execution/wf-1/revoke",
+		"outcome":        "denied",
+		"timestamp":      "2026-01-02T03:04:05Z",
+		"details":        map[string]any{"allowlist": "admin_allowlist"},
+	}, decoded)
+}
+
+func TestNewSecurityAuditLogger(t *testing.T) {
+
+	t.Run("nothing is logged by default", func(t *testing.T) {
+		logger, err := NewSecurityAuditLogger(&models.AuditLogConfig{}, &models.SyslogConfig{})
+		require.NoError(t, err)
+		assert.IsType(t, nopAuditLogger{}, logger)
+	})
+
+	t.Run("a path appends to the file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.log")
+		require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0600))
+
+		logger, err := NewSecurityAuditLogger(&models.AuditLogConfig{Path: path}, &models.SyslogConfig{})
+		require.NoError(t, err)
+		require.NoError(t, logger.Log(newTestSecurityEvent()))
+		require.NoError(t, logger.(*JSONAuditLogger).Close())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[1], `"event_type":"access.ip_blocked"`)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := NewSecurityAuditLogger(&models.AuditLogConfig{Stream: "kafka"}, &models.SyslogConfig{})
+		assert.ErrorContains(t, err, "unknown audit_log.stream")
+
+		_, err = NewSecurityAuditLogger(&models.AuditLogConfig{Stream: "file"}, &models.SyslogConfig{})
+		assert.ErrorContains(t, err, "audit_log.path is required")
+
+		_, err = NewSecurityAuditLogger(&models.AuditLogConfig{Stream: "syslog"}, &models.SyslogConfig{})
+		assert.ErrorContains(t, err, "invalid logging.syslog")
+	})
+}
+
+func TestSyslogFormatterSecurityEvent(t *testing.T) {
+
+	// Security events are always JSON, even when elevation events use CEF
+	formatter, err := newSyslogFormatter(&models.SyslogConfig{Facility: "authpriv", Format: "cef", AppName: "thand"})
+	require.NoError(t, err)
+	formatter.hostname = "agent-1"
+	formatter.procID = "42"
+
+	event := newTestSecurityEvent()
+
+	message, err := formatter.FormatSecurityEvent(event)
+	require.NoError(t, err)
+
+	header, payload, found := strings.Cut(string(message), " - ")
+	require.True(t, found)
+	assert.Equal(t, "<84>1 2026-01-02T03:04:05.000000Z agent-1 thand 42 access.ip_blocked", header)
+
+	var decoded SecurityEvent
+	require.NoError(t, json.Unmarshal([]byte(payload), &decoded))
+	assert.Equal(t, event, decoded)
+}
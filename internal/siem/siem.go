@@ -0,0 +1,57 @@
+package siem
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/thand-io/agent/internal/models"
+)
+
+var (
+	mu          sync.RWMutex
+	defaultSink *SyslogSink
+)
+
+// Configure starts the syslog sink when it's enabled, replacing any
+// previous sink
+func Configure(config *models.SyslogConfig) error {
+
+	var sink *SyslogSink
+
+	if config != nil && config.Enabled {
+		created, err := NewSyslogSink(config)
+		if err != nil {
+			return fmt.Errorf("invalid logging.syslog: %w", err)
+		}
+		sink = created
+	}
+
+	mu.Lock()
+	previous := defaultSink
+	defaultSink = sink
+	mu.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+
+	return nil
+}
+
+// Record sends the event to the syslog sink, if there is one. It never
+// blocks.
+func Record(event Event) {
+
+	mu.RLock()
+	sink := defaultSink
+	mu.RUnlock()
+
+	if sink != nil {
+		sink.Send(event)
+	}
+}
+
+// Close sends any queued events and stops the sink
+func Close() {
+	Configure(nil)
+}
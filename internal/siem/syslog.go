@@ -0,0 +1,518 @@
+package siem
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/metrics"
+	"github.com/thand-io/agent/internal/models"
+)
+
+const (
+	defaultSyslogQueueSize = 1000
+	syslogDialTimeout      = 10 * time.Second
+	syslogWriteTimeout     = 10 * time.Second
+	syslogMinBackoff       = 500 * time.Millisecond
+	syslogMaxBackoff       = 30 * time.Second
+
+	// Log every nth dropped event so a full queue doesn't flood the logs
+	syslogDropLogInterval = 100
+)
+
+// Syslog severities
+const (
+	syslogSeverityWarning = 4
+	syslogSeverityNotice  = 5
+)
+
+var syslogFacilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+// syslogFormatter builds RFC 5424 messages for events
+type syslogFormatter struct {
+	facility int
+	format   string
+	appName  string
+	hostname string
+	procID   string
+	version  string
+}
+
+func newSyslogFormatter(config *models.SyslogConfig) (*syslogFormatter, error) {
+
+	facility, found := syslogFacilities[strings.ToLower(config.Facility)]
+
+	if !found {
+		return nil, fmt.Errorf("unknown facility %q", config.Facility)
+	}
+
+	format := strings.ToLower(config.Format)
+
+	switch format {
+	case "":
+		format = models.SyslogFormatRFC5424
+	case models.SyslogFormatRFC5424, models.SyslogFormatCEF:
+	default:
+		return nil, fmt.Errorf("unknown format %q, must be rfc5424 or cef", config.Format)
+	}
+
+	hostname, err := os.Hostname()
+
+	if err != nil || len(hostname) == 0 {
+		hostname = "-"
+	}
+
+	appName := config.AppName
+
+	if len(appName) == 0 {
+		appName = "thand"
+	}
+
+	return &syslogFormatter{
+		facility: facility,
+		format:   format,
+		appName:  appName,
+		hostname: hostname,
+		procID:   strconv.Itoa(os.Getpid()),
+		version:  common.GetVersion(),
+	}, nil
+}
+
+// Format returns the event as an RFC 5424 message. The event type is the
+// message ID and the payload is JSON or CEF.
+func (f *syslogFormatter) Format(event Event) ([]byte, error) {
+
+	var payload string
+
+	switch f.format {
+	case models.SyslogFormatCEF:
+		payload = EncodeCEF(event, f.version)
+	default:
+		encoded, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode event: %w", err)
+		}
+		payload = string(encoded)
+	}
+
+	return f.formatMessage(string(event.Type), event.Timestamp, event.IsFailure(), payload), nil
+}
+
+// FormatSecurityEvent returns the security event as an RFC 5424 message
+// with a JSON payload, whatever the configured format
+func (f *syslogFormatter) FormatSecurityEvent(event SecurityEvent) ([]byte, error) {
+
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode security event: %w", err)
+	}
+
+	return f.formatMessage(string(event.EventType), event.Timestamp, event.IsFailure(), string(encoded)), nil
+}
+
+func (f *syslogFormatter) formatMessage(messageID string, timestamp time.Time, failure bool, payload string) []byte {
+
+	severity := syslogSeverityNotice
+
+	if failure {
+		severity = syslogSeverityWarning
+	}
+
+	return fmt.Appendf(nil, "<%d>1 %s %s %s %s %s - %s",
+		f.facility*8+severity,
+		timestamp.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		f.hostname,
+		f.appName,
+		f.procID,
+		messageID,
+		payload,
+	)
+}
+
+// SyslogSink sends events to a syslog server from a background goroutine
+// so recording an event never blocks grant processing. TCP connections are
+// kept open and reconnected with backoff. Events are queued while the
+// server is unreachable and dropped once the queue is full.
+type SyslogSink struct {
+	protocol  string
+	address   string
+	tlsConfig *tls.Config
+	formatter *syslogFormatter
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	queue     chan syslogMessage
+	dropped   atomic.Int64
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+
+	conn net.Conn // Only used by the run goroutine
+}
+
+// syslogMessage is a formatted message waiting to be sent
+type syslogMessage struct {
+	messageID string
+	data      []byte
+}
+
+// NewSyslogSink validates the config and starts sending events
+func NewSyslogSink(config *models.SyslogConfig) (*SyslogSink, error) {
+
+	sink, err := newSyslogSink(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	go sink.run()
+
+	return sink, nil
+}
+
+func newSyslogSink(config *models.SyslogConfig) (*SyslogSink, error) {
+
+	if len(config.Address) == 0 {
+		return nil, errors.New("address is required")
+	}
+
+	protocol := strings.ToLower(config.Protocol)
+
+	if len(protocol) == 0 {
+		protocol = models.SyslogProtocolUDP
+	}
+
+	var tlsConfig *tls.Config
+
+	switch protocol {
+	case models.SyslogProtocolUDP, models.SyslogProtocolTCP:
+	case models.SyslogProtocolTCPTLS:
+		loaded, err := newSyslogTLSConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig = loaded
+	default:
+		return nil, fmt.Errorf("unknown protocol %q, must be udp, tcp or tcp+tls", config.Protocol)
+	}
+
+	formatter, err := newSyslogFormatter(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	queueSize := config.QueueSize
+
+	if queueSize <= 0 {
+		queueSize = defaultSyslogQueueSize
+	}
+
+	return &SyslogSink{
+		protocol:   protocol,
+		address:    config.Address,
+		tlsConfig:  tlsConfig,
+		formatter:  formatter,
+		minBackoff: syslogMinBackoff,
+		maxBackoff: syslogMaxBackoff,
+		queue:      make(chan syslogMessage, queueSize),
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}, nil
+}
+
+// newSyslogTLSConfig verifies the server against the CA file, or the
+// system roots when there isn't one
+func newSyslogTLSConfig(config *models.SyslogConfig) (*tls.Config, error) {
+
+	serverName := config.TLS.ServerName
+
+	if len(serverName) == 0 {
+		host, _, err := net.SplitHostPort(config.Address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", config.Address, err)
+		}
+		serverName = host
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if len(config.TLS.CAFile) > 0 {
+
+		caCert, err := os.ReadFile(config.TLS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", config.TLS.CAFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(config.TLS.CertFile) > 0 || len(config.TLS.KeyFile) > 0 {
+
+		cert, err := tls.LoadX509KeyPair(config.TLS.CertFile, config.TLS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// Send queues the event without blocking. The event is dropped if the
+// queue is full.
+func (s *SyslogSink) Send(event Event) {
+
+	data, err := s.formatter.Format(event)
+
+	if err != nil {
+		logrus.WithError(err).Warnln("Failed to format event for syslog")
+		return
+	}
+
+	s.enqueue(syslogMessage{messageID: string(event.Type), data: data})
+}
+
+// SendSecurityEvent queues the security event without blocking, see Send
+func (s *SyslogSink) SendSecurityEvent(event SecurityEvent) {
+
+	data, err := s.formatter.FormatSecurityEvent(event)
+
+	if err != nil {
+		logrus.WithError(err).Warnln("Failed to format security event for syslog")
+		return
+	}
+
+	s.enqueue(syslogMessage{messageID: string(event.EventType), data: data})
+}
+
+func (s *SyslogSink) enqueue(message syslogMessage) {
+	select {
+	case s.queue <- message:
+	default:
+		s.drop(message)
+	}
+}
+
+// Dropped returns the number of events dropped
+func (s *SyslogSink) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close sends the queued events, without waiting for an unreachable
+// server, and closes the connection
+func (s *SyslogSink) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	<-s.stopped
+	return nil
+}
+
+func (s *SyslogSink) drop(message syslogMessage) {
+
+	dropped := s.dropped.Add(1)
+
+	metrics.RecordSyslogDropped()
+
+	if dropped == 1 || dropped%syslogDropLogInterval == 0 {
+		logrus.WithFields(logrus.Fields{
+			"address": s.address,
+			"event":   message.messageID,
+			"dropped": dropped,
+		}).Warnln("Dropped event for syslog")
+	}
+}
+
+func (s *SyslogSink) run() {
+
+	defer close(s.stopped)
+	defer s.disconnect()
+
+	for {
+		select {
+		case <-s.done:
+			s.flush()
+			return
+		case message := <-s.queue:
+			s.deliver(message)
+		}
+	}
+}
+
+// deliver sends the message, reconnecting with backoff until it's sent or
+// the sink is closed
+func (s *SyslogSink) deliver(message syslogMessage) {
+
+	backoff := s.minBackoff
+
+	for {
+
+		err := s.write(message.data)
+
+		if err == nil {
+			return
+		}
+
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"address": s.address,
+			"retry":   backoff,
+		}).Warnln("Failed to send event to syslog")
+
+		select {
+		case <-s.done:
+			s.drop(message)
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, s.maxBackoff)
+	}
+}
+
+// flush makes a single attempt to send each queued event
+func (s *SyslogSink) flush() {
+	for {
+		select {
+		case message := <-s.queue:
+			if err := s.write(message.data); err != nil {
+				s.drop(message)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (s *SyslogSink) write(message []byte) error {
+
+	// Servers never write to us, so a readable connection has been closed
+	// by the server. Reconnect rather than losing the event.
+	if s.conn != nil && s.isStream() && isConnClosed(s.conn) {
+		s.disconnect()
+	}
+
+	if s.conn == nil {
+		conn, err := s.dial()
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	if err := s.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout)); err != nil {
+		s.disconnect()
+		return err
+	}
+
+	if _, err := s.conn.Write(s.frame(message)); err != nil {
+		s.disconnect()
+		return fmt.Errorf("failed to write to %s: %w", s.address, err)
+	}
+
+	return nil
+}
+
+// frame uses octet counting for streams (RFC 6587 and RFC 5425)
+func (s *SyslogSink) frame(message []byte) []byte {
+	if !s.isStream() {
+		return message
+	}
+	return fmt.Appendf(nil, "%d %s", len(message), message)
+}
+
+func (s *SyslogSink) dial() (net.Conn, error) {
+
+	dialer := &net.Dialer{Timeout: syslogDialTimeout}
+
+	var conn net.Conn
+	var err error
+
+	switch s.protocol {
+	case models.SyslogProtocolTCPTLS:
+		conn, err = tls.DialWithDialer(dialer, "tcp", s.address, s.tlsConfig)
+	case models.SyslogProtocolTCP:
+		conn, err = dialer.Dial("tcp", s.address)
+	default:
+		conn, err = dialer.Dial("udp", s.address)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", s.address, err)
+	}
+
+	return conn, nil
+}
+
+func (s *SyslogSink) disconnect() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+func (s *SyslogSink) isStream() bool {
+	return s.protocol != models.SyslogProtocolUDP
+}
+
+// isConnClosed returns true if the server has closed the connection
+func isConnClosed(conn net.Conn) bool {
+
+	if err := conn.SetReadDeadline(time.Now().Add(time.Millisecond)); err != nil {
+		return true
+	}
+
+	defer conn.SetReadDeadline(time.Time{})
+
+	var buf [1]byte
+	_, err := conn.Read(buf[:])
+
+	var netErr net.Error
+	if err == nil || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return false
+	}
+
+	return true
+}
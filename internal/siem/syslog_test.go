@@ -0,0 +1,343 @@
+package siem
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestSyslogFormatter(t *testing.T) {
+	event := newTestEvent(EventRoleGranted).WithProvider("aws-prod", "alice@example.com")
+
+	t.Run("rfc5424", func(t *testing.T) {
+		formatter, err := newSyslogFormatter(&models.SyslogConfig{Facility: "local0", Format: "rfc5424", AppName: "thand"})
+		require.NoError(t, err)
+		formatter.hostname = "agent-1"
+		formatter.procID = "42"
+
+		message, err := formatter.Format(event)
+		require.NoError(t, err)
+
+		header, payload, found := strings.Cut(string(message), " - ")
+		require.True(t, found)
+		assert.Equal(t, "<133>1 2026-01-02T03:04:05.000000Z agent-1 thand 42 role.granted", header)
+
+		var decoded Event
+		require.NoError(t, json.Unmarshal([]byte(payload), &decoded))
+		assert.Equal(t, event, decoded)
+	})
+
+	t.Run("cef", func(t *testing.T) {
+		formatter, err := newSyslogFormatter(&models.SyslogConfig{Facility: "authpriv", Format: "cef"})
+		require.NoError(t, err)
+
+		message, err := formatter.Format(newTestEvent(EventElevationDenied))
+		require.NoError(t, err)
+
+		// authpriv is 10 and denied events are warnings
+		assert.True(t, strings.HasPrefix(string(message), "<84>1 "))
+		assert.Contains(t, string(message), " - CEF:0|Thand|Agent|")
+	})
+}
+
+func TestNewSyslogSinkErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config models.SyslogConfig
+		err    string
+	}{
+		{"missing address", models.SyslogConfig{Facility: "local0"}, "address is required"},
+		{"unknown protocol", models.SyslogConfig{Address: "localhost:514", Protocol: "http", Facility: "local0"}, "unknown protocol"},
+		{"unknown facility", models.SyslogConfig{Address: "localhost:514", Facility: "local9"}, "unknown facility"},
+		{"unknown format", models.SyslogConfig{Address: "localhost:514", Facility: "local0", Format: "leef"}, "unknown format"},
+		{"missing CA file", models.SyslogConfig{
+			Address:  "localhost:6514",
+			Protocol: "tcp+tls",
+			Facility: "local0",
+			TLS:      models.SyslogTLSConfig{CAFile: "/does/not/exist.pem"},
+		}, "failed to read CA file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSyslogSink(&tt.config)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestSyslogSinkDropsWhenQueueFull(t *testing.T) {
+	// Not started so nothing drains the queue
+	sink, err := newSyslogSink(&models.SyslogConfig{
+		Address:   "localhost:514",
+		Facility:  "local0",
+		QueueSize: 1,
+	})
+	require.NoError(t, err)
+
+	for range 3 {
+		sink.Send(newTestEvent(EventRoleGranted))
+	}
+
+	assert.Equal(t, int64(2), sink.Dropped())
+}
+
+// syslogListener receives octet counted syslog messages
+type syslogListener struct {
+	net.Listener
+	messages chan string
+
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func newSyslogListener(t *testing.T, listener net.Listener) *syslogListener {
+	t.Helper()
+
+	l := &syslogListener{Listener: listener, messages: make(chan string, 100)}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			l.mu.Lock()
+			l.conns = append(l.conns, conn)
+			l.mu.Unlock()
+			go l.read(conn)
+		}
+	}()
+
+	return l
+}
+
+// Close stops listening and closes the accepted connections, as a
+// restarting server would
+func (l *syslogListener) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, conn := range l.conns {
+		conn.Close()
+	}
+
+	return l.Listener.Close()
+}
+
+func (l *syslogListener) read(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+
+	for {
+		length, err := reader.ReadString(' ')
+		if err != nil {
+			return
+		}
+
+		size, err := strconv.Atoi(strings.TrimSpace(length))
+		if err != nil {
+			return
+		}
+
+		message := make([]byte, size)
+		if _, err := io.ReadFull(reader, message); err != nil {
+			return
+		}
+
+		l.messages <- string(message)
+	}
+}
+
+func (l *syslogListener) receive(t *testing.T) string {
+	t.Helper()
+
+	select {
+	case message := <-l.messages:
+		return message
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for syslog message")
+		return ""
+	}
+}
+
+func startTestSink(t *testing.T, config *models.SyslogConfig) *SyslogSink {
+	t.Helper()
+
+	sink, err := newSyslogSink(config)
+	require.NoError(t, err)
+
+	sink.minBackoff = 10 * time.Millisecond
+	sink.maxBackoff = 50 * time.Millisecond
+
+	go sink.run()
+	t.Cleanup(func() { sink.Close() })
+
+	return sink
+}
+
+func TestSyslogSinkReconnects(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	address := listener.Addr().String()
+	first := newSyslogListener(t, listener)
+
+	sink := startTestSink(t, &models.SyslogConfig{
+		Protocol: "tcp",
+		Address:  address,
+		Facility: "local0",
+	})
+
+	sink.Send(newTestEvent(EventElevationRequested))
+	assert.Contains(t, first.receive(t), "elevation.requested")
+
+	// Restart the listener, closing the sink's connection
+	require.NoError(t, first.Close())
+
+	restarted, err := net.Listen("tcp", address)
+	require.NoError(t, err)
+	defer restarted.Close()
+
+	second := newSyslogListener(t, restarted)
+
+	sink.Send(newTestEvent(EventRoleGranted))
+	assert.Contains(t, second.receive(t), "role.granted")
+	assert.Zero(t, sink.Dropped())
+}
+
+func TestSyslogSinkQueuesWhileUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	sink := startTestSink(t, &models.SyslogConfig{
+		Protocol: "tcp",
+		Address:  address,
+		Facility: "local0",
+	})
+
+	sink.Send(newTestEvent(EventRoleRevoked))
+
+	// Give the sink time to fail to connect and back off
+	time.Sleep(50 * time.Millisecond)
+
+	restarted, err := net.Listen("tcp", address)
+	require.NoError(t, err)
+	defer restarted.Close()
+
+	assert.Contains(t, newSyslogListener(t, restarted).receive(t), "role.revoked")
+}
+
+func TestSyslogSinkTLS(t *testing.T) {
+	cert, caFile := newTestCertificate(t)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := newSyslogListener(t, listener)
+
+	sink := startTestSink(t, &models.SyslogConfig{
+		Protocol: "tcp+tls",
+		Address:  listener.Addr().String(),
+		Facility: "local0",
+		Format:   "cef",
+		TLS:      models.SyslogTLSConfig{CAFile: caFile},
+	})
+
+	sink.Send(newTestEvent(EventElevationApproved))
+	assert.Contains(t, received.receive(t), "CEF:0|Thand|Agent|")
+}
+
+func TestSyslogSinkTLSVerifiesServer(t *testing.T) {
+	cert, _ := newTestCertificate(t)
+	_, otherCAFile := newTestCertificate(t)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := newSyslogListener(t, listener)
+
+	sink := startTestSink(t, &models.SyslogConfig{
+		Protocol: "tcp+tls",
+		Address:  listener.Addr().String(),
+		Facility: "local0",
+		TLS:      models.SyslogTLSConfig{CAFile: otherCAFile},
+	})
+
+	sink.Send(newTestEvent(EventElevationApproved))
+
+	select {
+	case message := <-received.messages:
+		t.Fatalf("message sent to an untrusted server: %s", message)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// newTestCertificate creates a self signed certificate for 127.0.0.1 and
+// writes it as the CA file
+func newTestCertificate(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "syslog.test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	cert, err := tls.X509KeyPair(certPEM, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	require.NoError(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, certPEM, 0600))
+
+	return cert, caFile
+}
@@ -0,0 +1,169 @@
+package contract
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// GrantContract describes a provider backed by a fake client for the
+// grant-state contract tests
+type GrantContract struct {
+	Provider models.ProviderImpl
+
+	// NewRequest returns the role request made by the given workflow. Every
+	// call must grant the same role to the same user.
+	NewRequest func(workflowID string) *models.RoleRequest
+
+	// CountGrants returns the number of grant artifacts held by the fake
+	// client e.g. role assignments or policy bindings
+	CountGrants func() int
+
+	// SharedGrants is set when the provider only allows one artifact per
+	// user and role, so grants from different workflows share it
+	SharedGrants bool
+}
+
+// RunGrantContract checks a provider's AuthorizeRole and RevokeRole follow
+// the grant-state contract:
+//
+//   - the response records a GrantRef with the deterministic reference
+//   - authorizing the same grant again finds the existing grant
+//   - revoke removes exactly what the GrantRef records, and can be retried
+//   - providers listing their managed grants find the grant, and revoke
+//     it from what they found
+func RunGrantContract(t *testing.T, contract GrantContract) {
+
+	t.Helper()
+
+	ctx := context.Background()
+	provider := contract.Provider
+	baseline := contract.CountGrants()
+
+	rbac, err := models.AsRoleBasedAccessControl(provider)
+	require.NoError(t, err)
+
+	authorize := func(t *testing.T, workflowID string) *models.AuthorizeRoleResponse {
+		resp, err := rbac.AuthorizeRole(ctx, &models.AuthorizeRoleRequest{
+			RoleRequest: contract.NewRequest(workflowID),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		require.NotNil(t, resp.GrantRef, "authorize must record a grant ref")
+		return resp
+	}
+
+	revoke := func(t *testing.T, workflowID string, resp *models.AuthorizeRoleResponse) {
+		req := contract.NewRequest(workflowID)
+
+		// Revoke must rely on the grant ref rather than the role
+		role := *req.Role
+		role.Name = role.Name + "-renamed"
+		req.Role = &role
+
+		_, err := rbac.RevokeRole(ctx, &models.RevokeRoleRequest{
+			RoleRequest:           req,
+			AuthorizeRoleResponse: resp,
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("authorize records the grant", func(t *testing.T) {
+		req := contract.NewRequest("wf-record")
+		resp := authorize(t, "wf-record")
+
+		assert.Equal(t, provider.GetIdentifier(), resp.GrantRef.Provider)
+		assert.Equal(t, req.GetGrantReference(), resp.GrantRef.Reference)
+		assert.True(t, resp.GrantRef.HasIDs(), "grant ref must identify what was created")
+
+		revoke(t, "wf-record", resp)
+		assert.Equal(t, baseline, contract.CountGrants())
+	})
+
+	t.Run("authorize is idempotent", func(t *testing.T) {
+		first := authorize(t, "wf-retry")
+		created := contract.CountGrants()
+		assert.Greater(t, created, baseline)
+
+		second := authorize(t, "wf-retry")
+		assert.Equal(t, first.GrantRef, second.GrantRef)
+		assert.Equal(t, created, contract.CountGrants(), "a retry must not create a second grant")
+
+		revoke(t, "wf-retry", second)
+		assert.Equal(t, baseline, contract.CountGrants())
+	})
+
+	t.Run("revoke is idempotent", func(t *testing.T) {
+		resp := authorize(t, "wf-revoke")
+
+		revoke(t, "wf-revoke", resp)
+		assert.Equal(t, baseline, contract.CountGrants())
+
+		revoke(t, "wf-revoke", resp)
+		assert.Equal(t, baseline, contract.CountGrants())
+	})
+
+	if reconciler, ok := provider.(models.ProviderGrantReconciler); ok {
+		t.Run("managed grants are listed", func(t *testing.T) {
+			resp := authorize(t, "wf-orphan")
+
+			findHeld := func() *models.ManagedGrant {
+				managedGrants, err := reconciler.ListManagedGrants(ctx)
+				require.NoError(t, err)
+				for _, managed := range managedGrants {
+					if managed.IsHeldBy(resp.GrantRef) {
+						return &managed
+					}
+				}
+				return nil
+			}
+
+			managed := findHeld()
+			require.NotNil(t, managed, "the grant must be listed")
+			assert.Equal(t, resp.GrantRef.Kind, managed.Grant.Kind)
+
+			// The sweep only has what the provider found to revoke with
+			_, err := rbac.RevokeRole(ctx, managed.NewRevokeRoleRequest())
+			require.NoError(t, err)
+			assert.Equal(t, baseline, contract.CountGrants())
+			assert.Nil(t, findHeld(), "a revoked grant must not be listed")
+		})
+	}
+
+	if contract.SharedGrants {
+		t.Run("workflows share the grant", func(t *testing.T) {
+			first := authorize(t, "wf-first")
+			created := contract.CountGrants()
+
+			second := authorize(t, "wf-second")
+			assert.NotEqual(t, first.GrantRef.Reference, second.GrantRef.Reference)
+			assert.Equal(t, first.GrantRef.IDs, second.GrantRef.IDs)
+			assert.Equal(t, created, contract.CountGrants())
+
+			revoke(t, "wf-second", second)
+			assert.Equal(t, baseline, contract.CountGrants())
+
+			revoke(t, "wf-first", first)
+			assert.Equal(t, baseline, contract.CountGrants())
+		})
+		return
+	}
+
+	t.Run("revoke removes only its grant", func(t *testing.T) {
+		first := authorize(t, "wf-first")
+		perGrant := contract.CountGrants() - baseline
+
+		second := authorize(t, "wf-second")
+		assert.NotEqual(t, first.GrantRef.Reference, second.GrantRef.Reference)
+		assert.Equal(t, baseline+2*perGrant, contract.CountGrants())
+
+		revoke(t, "wf-first", first)
+		assert.Equal(t, baseline+perGrant, contract.CountGrants())
+
+		revoke(t, "wf-second", second)
+		assert.Equal(t, baseline, contract.CountGrants())
+	})
+}
@@ -1,9 +1,15 @@
 package updater
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net/http"
+	"path"
 	"runtime"
 	"strings"
 	"time"
@@ -12,8 +18,13 @@ import (
 	"github.com/google/go-github/v57/github"
 	"github.com/inconshreveable/go-update"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/mod/semver"
 )
 
+// ChecksumsAssetName is the release asset listing the SHA256 checksum of
+// every other asset in the sha256sum format
+const ChecksumsAssetName = "checksums.txt"
+
 type Updater struct {
 	owner   string
 	repo    string
@@ -36,37 +47,73 @@ func (u *Updater) CheckForUpdate(ctx context.Context) (*github.RepositoryRelease
 		return nil, fmt.Errorf("failed to get latest release: %w", err)
 	}
 
-	if release.GetTagName() == u.current {
+	if !IsNewerVersion(release.GetTagName(), u.current) {
 		return nil, nil // No update available
 	}
 
 	return release, nil
 }
 
-func (u *Updater) Update(ctx context.Context, release *github.RepositoryRelease) error {
-	assetName := fmt.Sprintf("%s-%s-%s", u.repo, runtime.GOOS, runtime.GOARCH)
+// IsNewerVersion reports whether latest is newer than current. Development
+// builds without a semantic version treat any different release as newer.
+func IsNewerVersion(latest, current string) bool {
+	latest = canonicalVersion(latest)
+	current = canonicalVersion(current)
 
-	var downloadURL string
-	for _, asset := range release.Assets {
-		if strings.Contains(asset.GetName(), assetName) {
-			downloadURL = asset.GetBrowserDownloadURL()
-			break
-		}
+	if !semver.IsValid(latest) || !semver.IsValid(current) {
+		return latest != current
+	}
+
+	return semver.Compare(latest, current) > 0
+}
+
+func canonicalVersion(version string) string {
+	if len(version) > 0 && !strings.HasPrefix(version, "v") {
+		return "v" + version
 	}
+	return version
+}
+
+// BinaryAssetName returns the release asset holding the raw binary for
+// the current platform e.g. agent-linux-amd64
+func (u *Updater) BinaryAssetName() string {
+	name := fmt.Sprintf("%s-%s-%s", u.repo, runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
 
-	if len(downloadURL) == 0 {
+func (u *Updater) Update(ctx context.Context, release *github.RepositoryRelease) error {
+	assetName := u.BinaryAssetName()
+
+	asset := findAsset(release, assetName)
+
+	if asset == nil {
 		return fmt.Errorf("no suitable asset found for %s", assetName)
 	}
 
-	client := resty.New()
-	resp, err := client.R().
-		SetContext(ctx).
-		Get(downloadURL)
+	expected, err := u.getExpectedChecksum(ctx, release, assetName)
+	if err != nil {
+		return err
+	}
+
+	binary, err := download(ctx, asset.GetBrowserDownloadURL())
 	if err != nil {
 		return fmt.Errorf("failed to download update: %w", err)
 	}
 
-	err = update.Apply(bytes.NewReader(resp.Body()), update.Options{})
+	actual := sha256.Sum256(binary)
+
+	if !bytes.Equal(actual[:], expected) {
+		return fmt.Errorf("checksum mismatch for %s: expected %x, got %x",
+			assetName, expected, actual)
+	}
+
+	err = update.Apply(bytes.NewReader(binary), update.Options{
+		Hash:     crypto.SHA256,
+		Checksum: expected,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to apply update: %w", err)
 	}
@@ -75,6 +122,85 @@ func (u *Updater) Update(ctx context.Context, release *github.RepositoryRelease)
 	return nil
 }
 
+// getExpectedChecksum finds the published checksum for an asset either in
+// the release checksums file or in a <asset>.sha256 file
+func (u *Updater) getExpectedChecksum(ctx context.Context, release *github.RepositoryRelease, assetName string) ([]byte, error) {
+
+	for _, checksumAsset := range []string{ChecksumsAssetName, assetName + ".sha256"} {
+
+		asset := findAsset(release, checksumAsset)
+
+		if asset == nil {
+			continue
+		}
+
+		content, err := download(ctx, asset.GetBrowserDownloadURL())
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", checksumAsset, err)
+		}
+
+		return ParseChecksum(content, assetName)
+	}
+
+	return nil, fmt.Errorf("release %s does not publish a checksum for %s",
+		release.GetTagName(), assetName)
+}
+
+// ParseChecksum extracts the checksum for an asset from sha256sum output.
+// A file with a single bare checksum is also accepted.
+func ParseChecksum(content []byte, assetName string) ([]byte, error) {
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		// Binary mode entries are prefixed with a '*'
+		if len(fields) == 1 || path.Base(strings.TrimPrefix(fields[1], "*")) == assetName {
+			checksum, err := hex.DecodeString(fields[0])
+			if err != nil || len(checksum) != sha256.Size {
+				return nil, fmt.Errorf("invalid checksum for %s: %s", assetName, fields[0])
+			}
+			return checksum, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checksums: %w", err)
+	}
+
+	return nil, fmt.Errorf("no checksum found for %s", assetName)
+}
+
+func findAsset(release *github.RepositoryRelease, name string) *github.ReleaseAsset {
+	for _, asset := range release.Assets {
+		if asset.GetName() == name {
+			return asset
+		}
+	}
+	return nil
+}
+
+func download(ctx context.Context, url string) ([]byte, error) {
+	client := resty.New()
+	resp, err := client.R().
+		SetContext(ctx).
+		Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode(), url)
+	}
+
+	return resp.Body(), nil
+}
+
 func (u *Updater) AutoUpdate(ctx context.Context, interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
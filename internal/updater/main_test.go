@@ -0,0 +1,58 @@
+package updater
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsNewerVersion(t *testing.T) {
+	tests := []struct {
+		latest   string
+		current  string
+		expected bool
+	}{
+		{"v1.2.0", "v1.1.9", true},
+		{"v1.10.0", "v1.9.0", true},
+		{"1.2.0", "v1.2.0", false},
+		{"v1.2.0", "v1.2.0", false},
+		{"v1.1.0", "v1.2.0", false},
+		{"v1.2.0", "dev", true},
+		{"v1.2.0", "(devel)", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s_%s", tt.latest, tt.current), func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsNewerVersion(tt.latest, tt.current))
+		})
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	linux := sha256.Sum256([]byte("linux"))
+	darwin := sha256.Sum256([]byte("darwin"))
+
+	checksums := fmt.Sprintf("%x  agent-linux-amd64\n%x *dist/agent-darwin-arm64\n", linux, darwin)
+
+	checksum, err := ParseChecksum([]byte(checksums), "agent-linux-amd64")
+	require.NoError(t, err)
+	assert.Equal(t, linux[:], checksum)
+
+	checksum, err = ParseChecksum([]byte(checksums), "agent-darwin-arm64")
+	require.NoError(t, err)
+	assert.Equal(t, darwin[:], checksum)
+
+	_, err = ParseChecksum([]byte(checksums), "agent-windows-amd64.exe")
+	assert.ErrorContains(t, err, "no checksum found")
+
+	// A per asset checksum file only holds the hash
+	checksum, err = ParseChecksum(fmt.Appendf(nil, "%x\n", linux), "agent-linux-amd64")
+	require.NoError(t, err)
+	assert.Equal(t, linux[:], checksum)
+
+	_, err = ParseChecksum([]byte("nothex  agent-linux-amd64"), "agent-linux-amd64")
+	assert.ErrorContains(t, err, "invalid checksum")
+}
@@ -8,8 +8,12 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/metrics"
 	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/siem"
 	"github.com/thand-io/agent/internal/workflows/functions"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
 )
 
 const ThandAuthorizeFunction = "thand.authorize"
@@ -62,6 +66,45 @@ func (r *ThandAuthorizeRequest) IsValid() bool {
 	return r.Role != nil && r.User != nil && len(r.Provider) > 0
 }
 
+// GetAuthorizeRoleRequest returns the request passed to the authorization
+// hook and the provider. Calls that don't set an audit context get the
+// same one the thand authorize task builds from the elevation request.
+func (r *ThandAuthorizeRequest) GetAuthorizeRoleRequest(
+	workflowTask *models.WorkflowTask,
+	elevationRequest *models.ElevateRequestInternal,
+) *models.AuthorizeRoleRequest {
+
+	if r.RoleRequest == nil {
+		return &models.AuthorizeRoleRequest{}
+	}
+
+	roleRequest := *r.RoleRequest
+
+	if roleRequest.Audit == nil {
+		roleRequest.Audit = NewAuditContext(workflowTask, elevationRequest)
+	}
+
+	return &models.AuthorizeRoleRequest{
+		RoleRequest: &roleRequest,
+	}
+}
+
+// NewAuditContext builds the audit context passed to providers so grants
+// can be traced back to the requester in the cloud provider's own logs
+func NewAuditContext(
+	workflowTask *models.WorkflowTask,
+	elevationRequest *models.ElevateRequestInternal,
+) *models.AuditContext {
+	audit := &models.AuditContext{
+		WorkflowID: workflowTask.WorkflowID,
+		Reason:     elevationRequest.Reason,
+	}
+	if elevationRequest.User != nil {
+		audit.Requester = elevationRequest.User.GetIdentity()
+	}
+	return audit
+}
+
 // Execute performs the authorization logic
 func (t *authorizeFunction) Execute(
 	workflowTask *models.WorkflowTask,
@@ -69,13 +112,13 @@ func (t *authorizeFunction) Execute(
 	input any,
 ) (any, error) {
 
-	elevateRequest, err := t.validateAndParseRequests(workflowTask, call, input)
+	elevationRequest, elevateRequest, err := t.validateAndParseRequests(workflowTask, call, input)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return t.executeAuthorization(workflowTask, elevateRequest)
+	return t.executeAuthorization(workflowTask, elevationRequest, elevateRequest)
 }
 
 // validateAndParseRequests validates and parses the incoming requests
@@ -83,37 +126,38 @@ func (t *authorizeFunction) validateAndParseRequests(
 	workflowTask *models.WorkflowTask,
 	call *model.CallFunction,
 	input any,
-) (*ThandAuthorizeRequest, error) {
+) (*models.ElevateRequestInternal, *ThandAuthorizeRequest, error) {
 
 	elevationRequest, err := workflowTask.GetContextAsElevationRequest()
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get elevation request from context: %w", err)
+		return nil, nil, fmt.Errorf("failed to get elevation request from context: %w", err)
 	}
 
 	if !elevationRequest.IsValid() {
-		return nil, fmt.Errorf("invalid elevate request in context")
+		return nil, nil, fmt.Errorf("invalid elevate request in context")
 	}
 
 	var authRequest ThandAuthorizeRequest
 	if err := common.ConvertInterfaceToInterface(input, &authRequest); err != nil {
-		return nil, fmt.Errorf("failed to convert auth request: %w", err)
+		return nil, nil, fmt.Errorf("failed to convert auth request: %w", err)
 	}
 
 	if err := common.ConvertInterfaceToInterface(call.With, &authRequest); err != nil {
-		return nil, fmt.Errorf("failed to convert call request: %w", err)
+		return nil, nil, fmt.Errorf("failed to convert call request: %w", err)
 	}
 
 	if !authRequest.IsValid() {
 		logrus.Infoln("No revocation state provided. Just handling via the cleanup state")
 	}
 
-	return &authRequest, nil
+	return elevationRequest, &authRequest, nil
 }
 
 // executeAuthorization performs the main authorization workflow
 func (t *authorizeFunction) executeAuthorization(
 	workflowTask *models.WorkflowTask,
+	elevationRequest *models.ElevateRequestInternal,
 	elevateRequest *ThandAuthorizeRequest,
 ) (any, error) {
 
@@ -126,6 +170,28 @@ func (t *authorizeFunction) executeAuthorization(
 		return nil, fmt.Errorf("failed to get provider: %w", err)
 	}
 
+	rbac, err := providerCall.GetRBAC()
+
+	if err != nil {
+		return nil, err
+	}
+
+	authRequest := elevateRequest.GetAuthorizeRoleRequest(workflowTask, elevationRequest)
+
+	if err := t.config.RunAuthorizationHook(workflowTask.GetContext(), elevateRequest.Provider, authRequest); err != nil {
+
+		// The hook's decision won't change on a retry
+		if activity.IsActivity(workflowTask.GetContext()) {
+			return nil, temporal.NewNonRetryableApplicationError(
+				err.Error(),
+				"AuthorizationHookDenied",
+				err,
+			)
+		}
+
+		return nil, err
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"provider":      elevateRequest.Provider,
 		"provider_type": fmt.Sprintf("%T", providerCall.GetClient()),
@@ -134,12 +200,24 @@ func (t *authorizeFunction) executeAuthorization(
 		"user_username": elevateRequest.RoleRequest.User.Username,
 	}).Info("About to call AuthorizeRole on provider")
 
-	authOut, err := providerCall.GetClient().AuthorizeRole(
-		workflowTask.GetContext(), &models.AuthorizeRoleRequest{
-			RoleRequest: elevateRequest.RoleRequest,
-		},
-	)
+	authOut, err := rbac.AuthorizeRole(workflowTask.GetContext(), authRequest)
+
+	metrics.RecordRoleGrant(elevateRequest.Provider, err == nil)
+	siem.Record(siem.NewEvent(siem.EventRoleGranted, workflowTask).
+		WithProvider(elevateRequest.Provider, elevateRequest.RoleRequest.User.GetIdentity()).
+		WithError(err))
+
 	if err != nil {
+
+		// Temporal retries the activity with the task's retry policy
+		if ctx := workflowTask.GetContext(); activity.IsActivity(ctx) {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"provider": elevateRequest.Provider,
+				"user":     elevateRequest.RoleRequest.User.GetIdentity(),
+				"attempt":  activity.GetInfo(ctx).Attempt,
+			}).Warn("Authorization attempt failed")
+		}
+
 		return nil, fmt.Errorf("failed to authorize user: %w", err)
 	}
 
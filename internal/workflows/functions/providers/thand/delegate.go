@@ -0,0 +1,111 @@
+package thand
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/workflows/functions"
+)
+
+const ThandDelegateFunction = "thand.delegate"
+
+// delegateFunction looks up the delegations of the approvers. Under
+// Temporal this runs as an activity so the delegations and the time they
+// were read at are recorded in the history and replays are deterministic.
+type delegateFunction struct {
+	config *config.Config
+	*functions.BaseFunction
+}
+
+// DelegateRequest is the input to the delegate function
+type DelegateRequest struct {
+	Approvers []string `json:"approvers"`
+}
+
+// NewDelegateFunction creates a new approver delegation Function
+func NewDelegateFunction(config *config.Config) *delegateFunction {
+	return &delegateFunction{
+		config: config,
+		BaseFunction: functions.NewBaseFunction(
+			ThandDelegateFunction,
+			"Looks up the delegations of approvers that are away",
+			"1.0.0",
+		),
+	}
+}
+
+// GetRequiredParameters returns the required parameters for delegation
+func (t *delegateFunction) GetRequiredParameters() []string {
+	return []string{"approvers"}
+}
+
+// GetOptionalParameters returns optional parameters with defaults
+func (t *delegateFunction) GetOptionalParameters() map[string]any {
+	return map[string]any{}
+}
+
+// ValidateRequest validates the input parameters
+func (t *delegateFunction) ValidateRequest(
+	workflowTask *models.WorkflowTask,
+	call *model.CallFunction,
+	input any,
+) error {
+	return nil
+}
+
+// Execute returns the delegations of the approvers
+func (t *delegateFunction) Execute(
+	workflowTask *models.WorkflowTask,
+	call *model.CallFunction,
+	input any,
+) (any, error) {
+
+	var request DelegateRequest
+
+	if err := common.ConvertInterfaceToInterface(call.With, &request); err != nil {
+		return nil, fmt.Errorf("failed to parse delegate request: %w", err)
+	}
+
+	return DelegateApprovers(context.Background(), t.config, &request, time.Now())
+}
+
+// DelegateApprovers returns the delegations of the approvers that haven't
+// expired at the given time
+func DelegateApprovers(
+	ctx context.Context,
+	cfg *config.Config,
+	request *DelegateRequest,
+	now time.Time,
+) (*models.ApprovalDelegations, error) {
+
+	store, err := cfg.GetDelegationStore()
+
+	if err != nil {
+		return nil, err
+	}
+
+	delegations, err := store.List(ctx, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	approvalDelegations := models.NewApprovalDelegations(
+		request.Approvers, delegations, cfg.GetDelegationConfig().GetMode(), now)
+
+	if approvalDelegations.HasDelegations() {
+		logrus.WithFields(logrus.Fields{
+			"approvers":   request.Approvers,
+			"delegations": len(approvalDelegations.Delegations),
+			"mode":        approvalDelegations.Mode,
+		}).Info("Found approver delegations")
+	}
+
+	return approvalDelegations, nil
+}
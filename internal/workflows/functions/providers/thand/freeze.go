@@ -0,0 +1,88 @@
+package thand
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/workflows/functions"
+)
+
+const ThandFreezeFunction = "thand.freeze"
+
+// freezeFunction evaluates the change freeze calendars for the elevation
+// request. Under Temporal this runs as an activity so calendar fetches,
+// and the current time, are recorded in the history.
+type freezeFunction struct {
+	config *config.Config
+	*functions.BaseFunction
+}
+
+// NewFreezeFunction creates a new change freeze evaluation Function
+func NewFreezeFunction(config *config.Config) *freezeFunction {
+	return &freezeFunction{
+		config: config,
+		BaseFunction: functions.NewBaseFunction(
+			ThandFreezeFunction,
+			"Evaluates change freeze calendars for the elevation request",
+			"1.0.0",
+		),
+	}
+}
+
+// GetRequiredParameters returns the required parameters for freeze evaluation
+func (t *freezeFunction) GetRequiredParameters() []string {
+	return []string{}
+}
+
+// GetOptionalParameters returns optional parameters with defaults
+func (t *freezeFunction) GetOptionalParameters() map[string]any {
+	return map[string]any{}
+}
+
+// ValidateRequest validates the input parameters
+func (t *freezeFunction) ValidateRequest(
+	workflowTask *models.WorkflowTask,
+	call *model.CallFunction,
+	input any,
+) error {
+	return nil
+}
+
+// Execute evaluates the freeze calendars and returns the freeze status
+func (t *freezeFunction) Execute(
+	workflowTask *models.WorkflowTask,
+	call *model.CallFunction,
+	input any,
+) (any, error) {
+
+	elevationRequest, err := workflowTask.GetContextAsElevationRequest()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get elevation request from context: %w", err)
+	}
+
+	freeze, err := t.config.EvaluateFreeze(
+		workflowTask.GetContext(),
+		elevationRequest.User,
+		elevationRequest.Role,
+		elevationRequest.GetWorkflow(),
+		time.Now(),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"workflow": workflowTask.WorkflowID,
+		"active":   freeze.Active,
+		"labels":   freeze.Labels,
+		"action":   freeze.Action,
+	}).Info("Evaluated change freeze")
+
+	return freeze, nil
+}
@@ -23,6 +23,10 @@ func (c *thandCollection) RegisterFunctions(r *functions.FunctionRegistry) {
 		NewNotifyFunction(c.config),
 		NewAuthorizeFunction(c.config),
 		NewRevokeFunction(c.config),
+		NewPolicyFunction(c.config),
+		NewFreezeFunction(c.config),
+		NewRouteFunction(c.config),
+		NewDelegateFunction(c.config),
 	)
 
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/serverlessworkflow/sdk-go/v3/model"
 	"github.com/sirupsen/logrus"
@@ -95,21 +96,53 @@ func (t *notifyFunction) ValidateRequest(
 provider: slack # or slack, email
 to: "#access-requests"  # Can be a string or array of strings
 message: "Workflow validation passed for user ${ $.user.name }"
+subject: "Access for {{.user.name}}" # Optional, the email subject
+template: "{{.user.name}} was granted {{.role.name}} for {{.duration}}" # Optional, replaces the message
+template_file: templates/granted.tmpl # Optional, a file with the template
 approvals: true
+digest: # Optional, batch notifications to this target
+
+	window: 5m
+	min_batch: 3
+
+priority: high # Optional, always send immediately e.g. break-glass
+individual: true # Optional, render the message for each recipient
+locale: en # Optional, the default locale for recipients without one
+recipients: # Optional, data merged into the template for each recipient
+
+	alice@example.com:
+	  locale: de
+	  team: Platform
 */
 type NotifierRequest struct {
-	Provider string   `json:"provider"`
-	To       []string `json:"-"`       // Email, channel Id, username etc. - handled by custom marshal/unmarshal
-	Message  string   `json:"message"` // Message body
+	Provider     string                     `json:"provider"`
+	To           []string                   `json:"-"`                       // Email, channel Id, username etc. - handled by custom marshal/unmarshal
+	Message      string                     `json:"message"`                 // Message body
+	Subject      string                     `json:"subject,omitempty"`       // Subject for providers that have one e.g. email
+	Template     string                     `json:"template,omitempty"`      // Go template rendered into the message body
+	TemplateFile string                     `json:"template_file,omitempty"` // Path to a file holding the message template
+	Digest       *models.NotificationDigest `json:"digest,omitempty"`        // Buffer notifications and send them as one message
+	Priority     string                     `json:"priority,omitempty"`      // High priority notifications skip the digest
+	Individual   bool                       `json:"individual,omitempty"`    // Render the message for each recipient with their own data
+	Locale       string                     `json:"locale,omitempty"`        // Default locale for picking a template variant
+	Recipients   map[string]map[string]any  `json:"recipients,omitempty"`    // Template data for each recipient, keyed by the to entry
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling to handle both string and []string for To field
 func (r *NotifierRequest) UnmarshalJSON(data []byte) error {
 	// Create a temporary struct with the same fields but To as any
 	type Alias struct {
-		Provider string `json:"provider"`
-		To       any    `json:"to"`
-		Message  string `json:"message"`
+		Provider     string                     `json:"provider"`
+		To           any                        `json:"to"`
+		Message      string                     `json:"message"`
+		Subject      string                     `json:"subject"`
+		Template     string                     `json:"template"`
+		TemplateFile string                     `json:"template_file"`
+		Digest       *models.NotificationDigest `json:"digest"`
+		Priority     string                     `json:"priority"`
+		Individual   bool                       `json:"individual"`
+		Locale       string                     `json:"locale"`
+		Recipients   map[string]map[string]any  `json:"recipients"`
 	}
 
 	var temp Alias
@@ -119,6 +152,14 @@ func (r *NotifierRequest) UnmarshalJSON(data []byte) error {
 
 	r.Provider = temp.Provider
 	r.Message = temp.Message
+	r.Subject = temp.Subject
+	r.Template = temp.Template
+	r.TemplateFile = temp.TemplateFile
+	r.Digest = temp.Digest
+	r.Priority = temp.Priority
+	r.Individual = temp.Individual
+	r.Locale = temp.Locale
+	r.Recipients = temp.Recipients
 
 	// Handle To field - can be string or []string
 	switch v := temp.To.(type) {
@@ -155,13 +196,42 @@ func (r *NotifierRequest) IsValid() bool {
 	return len(r.Provider) > 0 && len(r.To) > 0
 }
 
+// HasTemplate returns true if the message is rendered from a template
+func (r *NotifierRequest) HasTemplate() bool {
+	return len(r.Template) > 0 || len(r.TemplateFile) > 0
+}
+
+// IsHighPriority returns true if the notification must be sent immediately
+func (r *NotifierRequest) IsHighPriority() bool {
+	return strings.EqualFold(r.Priority, models.NotificationPriorityHigh)
+}
+
+// GetDigest returns the digest settings, or nil if notifications are sent
+// immediately
+func (r *NotifierRequest) GetDigest() *models.NotificationDigest {
+	if r.IsHighPriority() {
+		return nil
+	}
+	return r.Digest
+}
+
 func (r *NotifierRequest) AsMap() map[string]any {
 	// Return 'to' as array for consistency
-	return map[string]any{
+	callMap := map[string]any{
 		"provider": r.Provider,
 		"to":       r.To,
 		"message":  r.Message,
 	}
+	if len(r.Subject) > 0 {
+		callMap["subject"] = r.Subject
+	}
+	if r.Digest != nil {
+		callMap["digest"] = r.Digest
+	}
+	if len(r.Priority) > 0 {
+		callMap["priority"] = r.Priority
+	}
+	return callMap
 }
 
 // Execute performs the validation logic
@@ -210,6 +280,11 @@ func (t *notifyFunction) Execute(
 		return nil, fmt.Errorf("failed to get provider config: %w", err)
 	}
 
+	notifier, err := providerConfig.GetNotifier()
+	if err != nil {
+		return nil, err
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"provider": providerConfig.Name,
 	}).Info("Executing notification")
@@ -229,12 +304,44 @@ func (t *notifyFunction) Execute(
 		return nil, fmt.Errorf("failed to convert notification payload: %w", err)
 	}
 
-	err = providerConfig.GetClient().SendNotification(
+	// Secret references from the workflow vars are only resolved here so
+	// they never appear in the workflow history
+	notificationPayload, err = resolveNotificationSecrets(notificationPayload)
+
+	if err != nil {
+		return nil, err
+	}
+
+	notificationID := notificationPayload.GetNotificationID()
+
+	// Targets in digest mode are sent later as a single message
+	if t.config.GetNotificationDigests().Add(foundProvider, notificationPayload) {
+		return models.NewNotificationReceipt(notificationID, foundProvider, "", &models.NotificationReceipt{
+			Status: models.NotificationDeliveryDigested,
+		}, nil, time.Now()), nil
+	}
+
+	receipt, err := notifier.SendNotification(
 		workflowTask.GetContext(), notificationPayload)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to send notification: %w", err)
 	}
 
-	return nil, err
+	// The receipt is the activity result so the workflow can record it
+	return models.NewNotificationReceipt(
+		notificationID, foundProvider, "", receipt, nil, time.Now()), nil
+}
+
+// resolveNotificationSecrets returns a copy of the payload with any secret
+// references resolved
+func resolveNotificationSecrets(payload models.NotificationRequest) (models.NotificationRequest, error) {
+
+	resolved, err := models.ResolveSecretReferences(payload)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve notification payload: %w", err)
+	}
+
+	return resolved.(models.NotificationRequest), nil
 }
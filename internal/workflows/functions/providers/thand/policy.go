@@ -0,0 +1,84 @@
+package thand
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/workflows/functions"
+)
+
+const ThandPolicyFunction = "thand.policy"
+
+// policyFunction evaluates the configured rego policies against the
+// elevation request. Under Temporal this runs as an activity so the
+// evaluation, including the current time, is recorded in the history.
+type policyFunction struct {
+	config *config.Config
+	*functions.BaseFunction
+}
+
+// NewPolicyFunction creates a new policy evaluation Function
+func NewPolicyFunction(config *config.Config) *policyFunction {
+	return &policyFunction{
+		config: config,
+		BaseFunction: functions.NewBaseFunction(
+			ThandPolicyFunction,
+			"Evaluates guardrail policies against the elevation request",
+			"1.0.0",
+		),
+	}
+}
+
+// GetRequiredParameters returns the required parameters for policy evaluation
+func (t *policyFunction) GetRequiredParameters() []string {
+	return []string{}
+}
+
+// GetOptionalParameters returns optional parameters with defaults
+func (t *policyFunction) GetOptionalParameters() map[string]any {
+	return map[string]any{}
+}
+
+// ValidateRequest validates the input parameters
+func (t *policyFunction) ValidateRequest(
+	workflowTask *models.WorkflowTask,
+	call *model.CallFunction,
+	input any,
+) error {
+	return nil
+}
+
+// Execute evaluates the policies and returns the combined decision
+func (t *policyFunction) Execute(
+	workflowTask *models.WorkflowTask,
+	call *model.CallFunction,
+	input any,
+) (any, error) {
+
+	elevationRequest, err := workflowTask.GetContextAsElevationRequest()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get elevation request from context: %w", err)
+	}
+
+	decision, err := t.config.EvaluatePolicies(
+		workflowTask.GetContext(),
+		models.NewPolicyInput(elevationRequest, time.Now()),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"workflow": workflowTask.WorkflowID,
+		"result":   decision.Result,
+		"messages": decision.Messages,
+	}).Info("Evaluated elevation policies")
+
+	return decision, nil
+}
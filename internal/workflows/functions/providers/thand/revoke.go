@@ -8,7 +8,9 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/metrics"
 	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/siem"
 	"github.com/thand-io/agent/internal/workflows/functions"
 )
 
@@ -117,18 +119,24 @@ func (t *revokeFunction) executeRevocation(
 	revokeRequest *ThandRevokeRequest,
 ) (any, error) {
 
-	providerCall, err := t.config.GetProviderByName(revokeRequest.Provider)
+	rbac, err := t.config.GetRBAC(revokeRequest.Provider)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get provider: %w", err)
 	}
 
-	revokeOut, err := providerCall.GetClient().RevokeRole(
+	revokeOut, err := rbac.RevokeRole(
 		workflowTask.GetContext(), &models.RevokeRoleRequest{
 			RoleRequest:           revokeRequest.RoleRequest,
 			AuthorizeRoleResponse: revokeRequest.AuthorizeRoleResponse,
 		},
 	)
+
+	metrics.RecordRoleRevoke(revokeRequest.Provider, err == nil)
+	siem.Record(siem.NewEvent(siem.EventRoleRevoked, workflowTask).
+		WithProvider(revokeRequest.Provider, revokeRequest.RoleRequest.User.GetIdentity()).
+		WithError(err))
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to revoke user: %w", err)
 	}
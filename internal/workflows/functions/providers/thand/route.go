@@ -0,0 +1,103 @@
+package thand
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/workflows/functions"
+)
+
+const ThandRouteFunction = "thand.route"
+
+// routeFunction decides which approvers to notify based on their working
+// hours. Under Temporal this runs as an activity so the current time is
+// recorded in the history and replays are deterministic.
+type routeFunction struct {
+	config *config.Config
+	*functions.BaseFunction
+}
+
+// RouteRequest is the input to the route function
+type RouteRequest struct {
+	Mode       string   `json:"mode"`
+	Recipients []string `json:"recipients"`
+}
+
+// NewRouteFunction creates a new approval routing Function
+func NewRouteFunction(config *config.Config) *routeFunction {
+	return &routeFunction{
+		config: config,
+		BaseFunction: functions.NewBaseFunction(
+			ThandRouteFunction,
+			"Routes approval notifications to approvers within their working hours",
+			"1.0.0",
+		),
+	}
+}
+
+// GetRequiredParameters returns the required parameters for routing
+func (t *routeFunction) GetRequiredParameters() []string {
+	return []string{"mode", "recipients"}
+}
+
+// GetOptionalParameters returns optional parameters with defaults
+func (t *routeFunction) GetOptionalParameters() map[string]any {
+	return map[string]any{}
+}
+
+// ValidateRequest validates the input parameters
+func (t *routeFunction) ValidateRequest(
+	workflowTask *models.WorkflowTask,
+	call *model.CallFunction,
+	input any,
+) error {
+	return nil
+}
+
+// Execute evaluates the approver schedules and returns the routing decision
+func (t *routeFunction) Execute(
+	workflowTask *models.WorkflowTask,
+	call *model.CallFunction,
+	input any,
+) (any, error) {
+
+	var request RouteRequest
+
+	if err := common.ConvertInterfaceToInterface(call.With, &request); err != nil {
+		return nil, fmt.Errorf("failed to parse route request: %w", err)
+	}
+
+	return RouteApprovers(t.config, &request, time.Now())
+}
+
+// RouteApprovers returns the routing decision for the recipients at the
+// given time
+func RouteApprovers(cfg *config.Config, request *RouteRequest, now time.Time) (*models.ApprovalRouting, error) {
+
+	if request.Mode != models.ApprovalRoutingFollowTheSun {
+		return nil, fmt.Errorf("unsupported approval routing: %s", request.Mode)
+	}
+
+	routing := models.RouteFollowTheSun(
+		request.Recipients, cfg.GetApproverSchedules(), now)
+
+	logrus.WithFields(logrus.Fields{
+		"mode":       routing.Mode,
+		"recipients": routing.Recipients,
+		"onDuty":     routing.OnDuty,
+		"offDuty":    routing.OffDuty,
+		"fallback":   routing.Fallback,
+	}).Info("Routed approval notifications")
+
+	if len(routing.Errors) > 0 {
+		logrus.WithField("errors", routing.Errors).
+			Warn("Some approver schedules could not be evaluated")
+	}
+
+	return routing, nil
+}
@@ -0,0 +1,137 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	models "github.com/thand-io/agent/internal/models"
+	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
+	thandModel "github.com/thand-io/agent/internal/workflows/tasks/model"
+)
+
+// notifierFunctions are the call functions that send through a notifier
+// provider set by their provider parameter
+var notifierFunctions = []string{
+	thandFunction.ThandNotifyFunction,
+	"slack.postMessage",
+}
+
+// providerRequirement is a provider capability a workflow task depends on
+type providerRequirement struct {
+	Task       string
+	Provider   string
+	Capability models.ProviderCapability
+}
+
+// validateProviderCapabilities checks the providers referenced by the
+// workflow are configured and support the capabilities they're used for,
+// so a missing notifier fails the request rather than part way through
+// the workflow.
+func (m *WorkflowManager) validateProviderCapabilities(workflowDsl *model.Workflow) error {
+
+	if workflowDsl == nil {
+		return nil
+	}
+
+	var errs []error
+
+	for _, requirement := range getProviderRequirements(workflowDsl.Do) {
+		if err := m.config.ValidateProviderCapability(
+			requirement.Provider, requirement.Capability); err != nil {
+			errs = append(errs, fmt.Errorf("task %s: %w", requirement.Task, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("workflow %s requires providers that are not available: %w",
+			workflowDsl.Document.Name, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// getProviderRequirements walks the task list, and any nested task lists,
+// for the providers each task uses
+func getProviderRequirements(taskList *model.TaskList) []providerRequirement {
+
+	if taskList == nil {
+		return nil
+	}
+
+	requirements := []providerRequirement{}
+
+	for _, item := range *taskList {
+
+		if item == nil {
+			continue
+		}
+
+		switch task := item.Task.(type) {
+		case *thandModel.ThandTask:
+			if task.With != nil {
+				requirements = append(requirements,
+					getNotifierRequirements(item.Key, (*task.With)["notifiers"])...)
+			}
+		case *model.CallFunction:
+			for _, function := range notifierFunctions {
+				if task.Call == function {
+					requirements = append(requirements,
+						getNotifierRequirement(item.Key, task.With["provider"])...)
+				}
+			}
+		case *model.DoTask:
+			requirements = append(requirements, getProviderRequirements(task.Do)...)
+		case *model.ForTask:
+			requirements = append(requirements, getProviderRequirements(task.Do)...)
+		case *model.ForkTask:
+			requirements = append(requirements, getProviderRequirements(task.Fork.Branches)...)
+		case *model.TryTask:
+			requirements = append(requirements, getProviderRequirements(task.Try)...)
+			if task.Catch != nil {
+				requirements = append(requirements, getProviderRequirements(task.Catch.Do)...)
+			}
+		}
+	}
+
+	return requirements
+}
+
+// getNotifierRequirements returns the providers of a notifiers block e.g.
+// notifiers: { slack: { provider: slack, to: ... } }
+func getNotifierRequirements(taskName string, notifiers any) []providerRequirement {
+
+	notifierMap, ok := notifiers.(map[string]any)
+
+	if !ok {
+		return nil
+	}
+
+	requirements := []providerRequirement{}
+
+	for _, notifier := range notifierMap {
+		if notifierConfig, ok := notifier.(map[string]any); ok {
+			requirements = append(requirements,
+				getNotifierRequirement(taskName, notifierConfig["provider"])...)
+		}
+	}
+
+	return requirements
+}
+
+// getNotifierRequirement skips runtime expressions as the provider is only
+// known when the task runs
+func getNotifierRequirement(taskName string, provider any) []providerRequirement {
+
+	providerName, ok := provider.(string)
+
+	if !ok || len(providerName) == 0 || model.IsStrictExpr(providerName) {
+		return nil
+	}
+
+	return []providerRequirement{{
+		Task:       taskName,
+		Provider:   providerName,
+		Capability: models.ProviderCapabilityNotifier,
+	}}
+}
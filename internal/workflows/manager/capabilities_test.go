@@ -0,0 +1,93 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+)
+
+type capabilityProvider struct {
+	*models.BaseProvider
+}
+
+func TestValidateProviderCapabilities(t *testing.T) {
+
+	// The SDK parser rejects the custom thand tasks so decode directly
+	workflowDsl, err := common.ReadDataToInterface([]byte(`
+document:
+  dsl: '1.0.0'
+  namespace: test
+  name: approval
+  version: '1.0.0'
+do:
+  - approvals:
+      thand: approvals
+      with:
+        notifiers:
+          slack:
+            provider: slack
+            to: "#access"
+  - attempt:
+      try:
+        - notify:
+            call: thand.notify
+            with:
+              provider: teams
+      catch:
+        do:
+          - fallback:
+              call: thand.notify
+              with:
+                provider: ${ $context.provider }
+  - denied:
+      thand: notify
+      with:
+        notifiers:
+          aws:
+            provider: aws
+`), model.Workflow{})
+	require.NoError(t, err)
+
+	requirements := getProviderRequirements(workflowDsl.Do)
+
+	assert.ElementsMatch(t, []providerRequirement{
+		{Task: "approvals", Provider: "slack", Capability: models.ProviderCapabilityNotifier},
+		{Task: "notify", Provider: "teams", Capability: models.ProviderCapabilityNotifier},
+		{Task: "denied", Provider: "aws", Capability: models.ProviderCapabilityNotifier},
+	}, requirements)
+
+	newProvider := func(name string, capabilities ...models.ProviderCapability) models.Provider {
+		provider := models.Provider{Name: name, Provider: name, Enabled: true}
+		provider.SetClient(&capabilityProvider{
+			BaseProvider: models.NewBaseProvider(name, provider, capabilities...),
+		})
+		return provider
+	}
+
+	manager := &WorkflowManager{config: &config.Config{
+		Providers: config.ProviderConfig{
+			Definitions: map[string]models.Provider{
+				"slack": newProvider("slack", models.ProviderCapabilityNotifier),
+				"aws":   newProvider("aws", models.ProviderCapabilityRBAC),
+			},
+		},
+	}}
+
+	err = manager.validateProviderCapabilities(workflowDsl)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "workflow approval requires providers that are not available")
+	assert.Contains(t, err.Error(), "task notify: provider not configured: teams")
+	assert.Contains(t, err.Error(), "task denied: provider aws does not support the notifier capability")
+	assert.NotContains(t, err.Error(), "slack")
+
+	manager.config.Providers.Definitions["teams"] = newProvider("teams", models.ProviderCapabilityNotifier)
+	manager.config.Providers.Definitions["aws"] = newProvider("aws", models.ProviderCapabilityNotifier)
+
+	assert.NoError(t, manager.validateProviderCapabilities(workflowDsl))
+}
@@ -2,6 +2,7 @@ package manager
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/metrics"
 	models "github.com/thand-io/agent/internal/models"
 	"github.com/thand-io/agent/internal/workflows/functions"
 	providerAws "github.com/thand-io/agent/internal/workflows/functions/providers/aws"
@@ -20,6 +22,8 @@ import (
 	"github.com/thand-io/agent/internal/workflows/runner"
 	"github.com/thand-io/agent/internal/workflows/tasks"
 	taskThand "github.com/thand-io/agent/internal/workflows/tasks/providers/thand"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/worker"
@@ -159,28 +163,51 @@ func (m *WorkflowManager) executeWorkflow(
 		)
 	}
 
+	if err := m.validateProviderCapabilities(workflowDsl); err != nil {
+		return nil, err
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"workflow_name": workflowDsl.Document.Name,
 		"request":       request,
 	}).Info("Starting workflow execution")
 
-	authProvider, foundAuthProvider := m.config.GetProviderByName(request.Authenticator)
+	authProvider, err := m.config.GetAuthorizor(request.Authenticator)
 
-	if foundAuthProvider != nil {
-		return nil, fmt.Errorf("authentication provider not found: %s", request.Authenticator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authentication provider: %w", err)
 	}
 
 	// Convert input to map
 	internalContext := request.AsMap()
 
+	// Vars are merged once so config reloads don't affect this elevation
+	internalContext[models.VarsContextVars] = m.config.GetWorkflowVars(
+		request.Role, request.Providers)
+
 	workflowTask, err := models.NewWorkflowContext(workflow)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create workflow context: %w", err)
 	}
 
+	// Retries of the same request start the same workflow
+	if workflowID := request.GetIdempotentWorkflowID(m.getRequester(request)); len(workflowID) > 0 {
+		workflowTask.WorkflowID = workflowID
+	}
+
+	// Pin the definition so config reloads don't affect this elevation
+	snapshot, err := models.NewWorkflowSnapshot(workflowDsl)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot workflow definition: %w", err)
+	}
+
+	workflowTask.Snapshot = snapshot
 	workflowTask.SetContext(internalContext)
 
+	metrics.RecordWorkflowStarted(workflowTask.WorkflowName)
+
 	existingSession := request.Session
 
 	if existingSession != nil {
@@ -200,7 +227,7 @@ func (m *WorkflowManager) executeWorkflow(
 
 		if existingSession.Expiry.UTC().After(time.Now().UTC()) {
 
-			err = authProvider.GetClient().ValidateSession(ctx, decodedSession.Session)
+			err = authProvider.ValidateSession(ctx, decodedSession.Session)
 
 			if err == nil {
 
@@ -238,7 +265,7 @@ func (m *WorkflowManager) executeWorkflow(
 		}
 	}
 
-	sessionResponse, err := authProvider.GetClient().AuthorizeSession(ctx, &models.AuthorizeUser{
+	sessionResponse, err := authProvider.AuthorizeSession(ctx, &models.AuthorizeUser{
 		State:       workflowTask.GetEncodedTask(m.config.GetServices().GetEncryption()),
 		RedirectUri: m.config.GetAuthCallbackUrl(request.Authenticator),
 	})
@@ -256,6 +283,67 @@ func (m *WorkflowManager) executeWorkflow(
 
 }
 
+// getRequester returns the identity of the user who made the request from
+// its session, or an empty string if there isn't one
+func (m *WorkflowManager) getRequester(request models.ElevateRequest) string {
+
+	if request.Session == nil {
+		return ""
+	}
+
+	decodedSession, err := request.Session.GetDecodedSession(
+		m.config.GetServices().GetEncryption())
+
+	if err != nil || decodedSession == nil || decodedSession.Session == nil || decodedSession.User == nil {
+		return ""
+	}
+
+	return decodedSession.User.GetIdentity()
+}
+
+// FindIdempotentWorkflow returns the running workflow started by an earlier
+// request with the same idempotency key, or nil if there isn't one. Without
+// Temporal there is nothing to look up so nil is always returned.
+func (m *WorkflowManager) FindIdempotentWorkflow(
+	ctx context.Context,
+	request models.ElevateRequest,
+) (*models.ElevateResponse, error) {
+
+	workflowID := request.GetIdempotentWorkflowID(m.getRequester(request))
+
+	serviceClient := m.config.GetServices()
+
+	if len(workflowID) == 0 || !serviceClient.HasTemporal() {
+		return nil, nil
+	}
+
+	temporalClient := serviceClient.GetTemporal().GetClient()
+
+	execution, err := temporalClient.DescribeWorkflowExecution(
+		ctx, workflowID, models.TemporalEmptyRunId)
+
+	var notFound *serviceerror.NotFound
+
+	if errors.As(err, &notFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to describe workflow %s: %w", workflowID, err)
+	}
+
+	if execution.GetWorkflowExecutionInfo().GetStatus() != enums.WORKFLOW_EXECUTION_STATUS_RUNNING {
+		return nil, nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"workflow_id": workflowID,
+	}).Info("Found running workflow for duplicate elevation request")
+
+	return &models.ElevateResponse{
+		WorkflowId: workflowID,
+		Status:     swctx.RunningStatus,
+	}, nil
+}
+
 // ResumeWorkflow resumes workflow execution from client-provided state
 func (m *WorkflowManager) ResumeWorkflow(
 	result *models.WorkflowTask,
@@ -343,6 +431,11 @@ func (m *WorkflowManager) ResumeWorkflowTask(
 	// Resume from saved state
 	_, err = runner.Run(result.GetInput())
 
+	// Under temporal the workflow handler records the outcome
+	if !result.HasTemporalContext() && (err != nil || isWorkflowFinished(result)) {
+		recordWorkflowOutcome(result, err)
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to resume workflow: %w", err)
 	}
@@ -358,6 +451,12 @@ func (m *WorkflowManager) createCustomRunner(workflow *models.WorkflowTask) (*ru
 	return runner.NewResumableRunner(m.config, m.functions, m.tasks, workflow), nil
 }
 
+// SetTaskLayer places a layer in front of the task handlers, see
+// tasks.TaskLayer
+func (m *WorkflowManager) SetTaskLayer(layer tasks.TaskLayer) {
+	m.tasks.SetLayer(layer)
+}
+
 // RegisterCustomFunction allows external code to register additional functions
 func (m *WorkflowManager) RegisterCustomFunction(handler functions.Function) {
 	m.functions.RegisterFunction(handler)
@@ -414,6 +513,10 @@ func (m *WorkflowManager) createTemporalWorkflow(workflowTask *models.WorkflowTa
 	workflowOptions := client.StartWorkflowOptions{
 		ID:        workflowTask.WorkflowID,
 		TaskQueue: temporalService.GetTaskQueue(),
+		// A duplicate request racing this one gets the running workflow
+		// rather than an error
+		WorkflowIDConflictPolicy:                 enums.WORKFLOW_ID_CONFLICT_POLICY_USE_EXISTING,
+		WorkflowExecutionErrorWhenAlreadyStarted: false,
 		TypedSearchAttributes: temporal.NewSearchAttributes(
 			models.TypedSearchAttributeUser.ValueSet(userEmail),
 			models.TypedSearchAttributeRole.ValueSet(roleName),
@@ -423,9 +526,25 @@ func (m *WorkflowManager) createTemporalWorkflow(workflowTask *models.WorkflowTa
 			models.TypedSearchAttributeDuration.ValueSet(int64(duration.Seconds())),
 			models.TypedSearchAttributeReason.ValueSet(elevationRequest.Reason),
 			models.TypedSearchAttributeIdentities.ValueSet(elevationRequest.Identities),
+			models.TypedSearchAttributeRiskFlags.ValueSet(elevationRequest.RiskFlags.GetRaised()),
 		),
 	}
 
+	memo := map[string]any{}
+
+	if workflowTask.Snapshot != nil {
+		memo[models.TemporalMemoWorkflowSnapshot] = workflowTask.Snapshot
+	}
+
+	// The memo keeps the country so the next request can be compared
+	if elevationRequest.RiskFlags != nil {
+		memo[models.VarsContextRiskFlags] = elevationRequest.RiskFlags
+	}
+
+	if len(memo) > 0 {
+		workflowOptions.Memo = memo
+	}
+
 	// Only add versioning override if versioning is enabled
 	if !temporalService.IsVersioningDisabled() {
 		workflowOptions.VersioningOverride = &client.PinnedVersioningOverride{
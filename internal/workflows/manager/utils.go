@@ -1,10 +1,16 @@
 package manager
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 
+	swctx "github.com/serverlessworkflow/sdk-go/v3/impl/ctx"
 	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/metrics"
 	models "github.com/thand-io/agent/internal/models"
+	"go.temporal.io/sdk/temporal"
 )
 
 func CreateWorkflowFromEncodedTask(
@@ -29,9 +35,56 @@ func CreateWorkflowFromEncodedTask(
 	return &result, nil
 }
 
+// recordWorkflowOutcome records the final status of a workflow, counting
+// timeouts separately from other failures
+func recordWorkflowOutcome(workflowTask *models.WorkflowTask, err error) {
+
+	if workflowTask == nil {
+		return
+	}
+
+	workflowName := workflowTask.WorkflowName
+
+	if err != nil && (errors.Is(err, context.DeadlineExceeded) || temporal.IsTimeoutError(err)) {
+		metrics.RecordWorkflowTimeout(workflowName)
+		return
+	}
+
+	status := workflowTask.GetStatus()
+
+	if err != nil && status != swctx.CancelledStatus {
+		status = swctx.FaultedStatus
+	}
+
+	metrics.RecordWorkflowCompleted(workflowName, strings.ToLower(string(status)))
+}
+
+// isWorkflowFinished returns true once the workflow has reached a final status
+func isWorkflowFinished(workflowTask *models.WorkflowTask) bool {
+	switch workflowTask.GetStatus() {
+	case swctx.CompletedStatus, swctx.FaultedStatus, swctx.CancelledStatus:
+		return true
+	}
+	return false
+}
+
 // Hydrate populates the workflow task with necessary data
 func (m *WorkflowManager) Hydrate(workflowTask *models.WorkflowTask) error {
 
+	if workflowTask.GetWorkflowDef() == nil && workflowTask.Snapshot != nil {
+
+		// Prefer the definition the workflow was started with
+		workflowDsl, err := workflowTask.Snapshot.GetWorkflow()
+
+		if err != nil {
+			return fmt.Errorf("failed to load workflow snapshot: %w", err)
+		}
+
+		workflowTask.SetWorkflowDsl(workflowDsl)
+
+	}
+
+	// Older workflows have no snapshot so fall back to the current config
 	if workflowTask.GetWorkflowDef() == nil {
 
 		elevationRequest, err := workflowTask.GetContextAsElevationRequest()
@@ -67,5 +120,9 @@ func (m *WorkflowManager) Hydrate(workflowTask *models.WorkflowTask) error {
 		workflowTask.ClearTaskContext()
 	}
 
+	// Snapshot the request under $context.request so workflows can route
+	// on the resolved role, requester and duration
+	workflowTask.SetRequestContext()
+
 	return nil
 }
@@ -0,0 +1,103 @@
+package manager
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	"github.com/serverlessworkflow/sdk-go/v3/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func parseTestWorkflow(t *testing.T, taskKey string) *model.Workflow {
+	workflow, err := parser.FromYAMLSource([]byte(`
+document:
+  dsl: '1.0.0'
+  namespace: test
+  name: approval
+  version: '1.0.0'
+do:
+  - ` + taskKey + `:
+      set:
+        approved: true
+`))
+	require.NoError(t, err)
+	return workflow
+}
+
+func TestHydrate_WorkflowSnapshot(t *testing.T) {
+
+	original := parseTestWorkflow(t, "approve")
+
+	cfg := &config.Config{
+		Workflows: config.WorkflowConfig{
+			Definitions: map[string]models.Workflow{
+				"approval": {Name: "approval", Workflow: original, Enabled: true},
+			},
+		},
+	}
+
+	manager := &WorkflowManager{config: cfg}
+
+	newTask := func(t *testing.T, snapshot *models.WorkflowSnapshot) *models.WorkflowTask {
+		workflowTask, err := models.NewWorkflowContext(&models.Workflow{
+			Name:     "approval",
+			Workflow: original,
+		})
+		require.NoError(t, err)
+
+		workflowTask.Snapshot = snapshot
+		workflowTask.SetContext((&models.ElevateRequest{
+			Role:      &models.Role{Name: "admin"},
+			Providers: []string{"aws"},
+			Workflow:  "approval",
+			Reason:    "testing",
+		}).AsMap())
+
+		// Round trip the task as it would be through temporal or an
+		// encoded task, which drops the live workflow definition
+		data, err := json.Marshal(workflowTask)
+		require.NoError(t, err)
+
+		var restored models.WorkflowTask
+		require.NoError(t, json.Unmarshal(data, &restored))
+		require.Nil(t, restored.GetWorkflowDef())
+
+		return &restored
+	}
+
+	snapshot, err := models.NewWorkflowSnapshot(original)
+	require.NoError(t, err)
+
+	// Rename the task key mid-flight by reloading the config
+	cfg.Workflows.Definitions["approval"] = models.Workflow{
+		Name:     "approval",
+		Workflow: parseTestWorkflow(t, "review"),
+		Enabled:  true,
+	}
+
+	t.Run("snapshot pins the original definition", func(t *testing.T) {
+		workflowTask := newTask(t, snapshot)
+
+		require.NoError(t, manager.Hydrate(workflowTask))
+
+		workflowDef := workflowTask.GetWorkflowDef()
+		require.NotNil(t, workflowDef)
+		assert.NotNil(t, workflowDef.Do.Key("approve"))
+		assert.Nil(t, workflowDef.Do.Key("review"))
+	})
+
+	t.Run("falls back to config without a snapshot", func(t *testing.T) {
+		workflowTask := newTask(t, nil)
+
+		require.NoError(t, manager.Hydrate(workflowTask))
+
+		workflowDef := workflowTask.GetWorkflowDef()
+		require.NotNil(t, workflowDef)
+		assert.Nil(t, workflowDef.Do.Key("approve"))
+		assert.NotNil(t, workflowDef.Do.Key("review"))
+	})
+}
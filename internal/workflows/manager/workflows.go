@@ -9,6 +9,7 @@ import (
 	swctx "github.com/serverlessworkflow/sdk-go/v3/impl/ctx"
 	"github.com/serverlessworkflow/sdk-go/v3/model"
 	models "github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/workflows/runner"
 	thandModel "github.com/thand-io/agent/internal/workflows/tasks/model"
 	thandTask "github.com/thand-io/agent/internal/workflows/tasks/providers/thand"
 	"go.temporal.io/sdk/temporal"
@@ -61,6 +62,8 @@ func (m *WorkflowManager) createPrimaryWorkflowHandler() func(workflow.Context,
 			"BuildID", workflowInfo.GetCurrentBuildID(),
 		)
 
+		m.captureContinueAsNewThresholds(rootCtx, workflowTask)
+
 		cancelCtx, cancelHandler := workflow.WithCancel(rootCtx)
 
 		// Variable to store termination request, accessible to both goroutine and defer
@@ -75,6 +78,12 @@ func (m *WorkflowManager) createPrimaryWorkflowHandler() func(workflow.Context,
 				return
 			}
 
+			// The elevation carries on in the new run so don't clean up
+			if workflow.IsContinueAsNewError(outputError) {
+				log.Info("Continuing as new, skipping cleanup.")
+				return
+			}
+
 			cleanupErr := m.runCleanup(rootCtx, workflowTask, terminationRequest)
 
 			outputTask = workflowTask
@@ -88,6 +97,11 @@ func (m *WorkflowManager) createPrimaryWorkflowHandler() func(workflow.Context,
 			}
 			log.Info("Workflow cleanup completed.")
 
+			// Only record the outcome once, not when replaying history
+			if !workflow.IsReplaying(rootCtx) {
+				recordWorkflowOutcome(workflowTask, outputError)
+			}
+
 		}()
 
 		// Setup query handler
@@ -110,10 +124,17 @@ func (m *WorkflowManager) createPrimaryWorkflowHandler() func(workflow.Context,
 			cancelCtx, resumeSignal, workflowTask)
 		workflowSelector.Select(cancelCtx)
 
-		log.Info("Starting main workflow execution loop")
+		// A run continued by the runner carries on from the task it stopped
+		// at rather than waiting to be resumed. Only runs with captured
+		// thresholds can have been continued by the runner.
+		continued := workflowTask.GetContinueAsNewThresholds() != nil &&
+			len(workflowInfo.ContinuedExecutionRunID) > 0 &&
+			workflowTask.HasEntrypoint()
+
+		log.Info("Starting main workflow execution loop", "Continued", continued)
 
 		// Execute main workflow loop
-		return m.executeWorkflowLoop(cancelCtx, workflowSelector, workflowTask)
+		return m.executeWorkflowLoop(cancelCtx, workflowSelector, workflowTask, continued)
 	}
 }
 
@@ -334,72 +355,143 @@ func (m *WorkflowManager) setupWorkflowSelector(
 	return workflowSelector
 }
 
+// captureContinueAsNewThresholds records the configured thresholds on the
+// workflow task once, when the workflow starts, so replays and workers with
+// a different config make the same decisions. Runs continued as new carry
+// the thresholds over, and runs started before they were captured are left
+// without them.
+func (m *WorkflowManager) captureContinueAsNewThresholds(
+	ctx workflow.Context,
+	workflowTask *models.WorkflowTask,
+) {
+
+	version := workflow.GetVersion(ctx, models.TemporalContinueAsNewChangeID, workflow.DefaultVersion, 1)
+
+	if version == workflow.DefaultVersion || workflowTask.GetContinueAsNewThresholds() != nil {
+		return
+	}
+
+	var thresholds *models.ContinueAsNewThresholds
+
+	err := workflow.SideEffect(ctx, func(ctx workflow.Context) any {
+		return m.config.Services.GetTemporalConfig().GetContinueAsNewThresholds()
+	}).Get(&thresholds)
+
+	if err != nil {
+		workflow.GetLogger(ctx).Error("Failed to capture Continue-As-New thresholds", "Error", err)
+		return
+	}
+
+	workflowTask.SetContinueAsNewThresholds(thresholds)
+}
+
 // shouldContinueAsNew checks if the workflow should perform Continue-As-New
 // This allows upgrading to new worker versions and prevents event history size issues
-func (m *WorkflowManager) shouldContinueAsNew(ctx workflow.Context) bool {
+func (m *WorkflowManager) shouldContinueAsNew(ctx workflow.Context, workflowTask *models.WorkflowTask) bool {
 
 	log := workflow.GetLogger(ctx)
 
-	// Check Temporal's built-in suggestion for Continue-As-New
-	// This is triggered when event history approaches size limits
-	if workflow.GetInfo(ctx).GetContinueAsNewSuggested() {
-		log.Info("Continue-As-New suggested by Temporal (event history size)")
-		return true
+	workflowInfo := workflow.GetInfo(ctx)
+	thresholds := workflowTask.GetContinueAsNewThresholds()
+
+	// Runs started before the thresholds were captured only continue when
+	// Temporal suggests it
+	if thresholds == nil {
+		if workflowInfo.GetContinueAsNewSuggested() {
+			log.Info("Continue-As-New suggested by Temporal (event history size)")
+			return true
+		}
+		return false
+	}
+
+	// Signals received in this run would be lost
+	if runner.HasPendingSignals(ctx) {
+		return false
 	}
 
-	// TODO(hugh): Add custom Continue-As-New triggers here
+	// Temporal suggests Continue-As-New as the history approaches its
+	// limits, otherwise use the thresholds captured when the workflow
+	// started
+	if thresholds.ShouldContinueAsNew(workflowInfo) {
+		log.Info("Continue-As-New threshold reached",
+			"HistoryLength", workflowInfo.GetCurrentHistoryLength(),
+			"HistorySize", workflowInfo.GetCurrentHistorySize(),
+			"Suggested", workflowInfo.GetContinueAsNewSuggested(),
+		)
+		return true
+	}
 
 	return false
 }
 
+// continueAsNew starts a new run of the workflow with the same workflow ID
+// carrying over the workflow task
+func (m *WorkflowManager) continueAsNew(
+	ctx workflow.Context,
+	workflowTask *models.WorkflowTask,
+) (*models.WorkflowTask, error) {
+
+	log := workflow.GetLogger(ctx)
+
+	log.Info("Continuing workflow as new",
+		"WorkflowID", workflowTask.WorkflowID,
+		"Entrypoint", workflowTask.GetEntrypoint(),
+		"CurrentBuildID", workflow.GetInfo(ctx).GetCurrentBuildID(),
+	)
+
+	return workflowTask, workflow.NewContinueAsNewError(
+		ctx,
+		models.TemporalExecuteElevationWorkflowName,
+		workflowTask,
+	)
+}
+
 // executeWorkflowLoop executes the main workflow execution loop
 func (m *WorkflowManager) executeWorkflowLoop(
 	cancelCtx workflow.Context,
 	workflowSelector workflow.Selector,
 	workflowTask *models.WorkflowTask,
+	resume bool,
 ) (*models.WorkflowTask, error) {
 
 	log := workflow.GetLogger(cancelCtx)
 
 	for {
 
-		log.Info("Waiting for signal...")
+		// Resume straight away when carrying on from a task, otherwise
+		// wait for the resume signal
+		if !resume {
 
-		// Check if we should Continue-As-New before waiting for signal
-		// This allows upgrading to new worker versions at safe checkpoints
-		if m.shouldContinueAsNew(cancelCtx) {
-			currentBuildID := workflow.GetInfo(cancelCtx).GetCurrentBuildID()
-			log.Info("Continue-As-New suggested, upgrading workflow to latest version",
-				"WorkflowID", workflowTask.WorkflowID,
-				"CurrentBuildID", currentBuildID,
-			)
+			log.Info("Waiting for signal...")
 
-			return workflowTask, workflow.NewContinueAsNewError(
-				cancelCtx,
-				models.TemporalExecuteElevationWorkflowName,
-				workflowTask,
-			)
-		}
+			// Check if we should Continue-As-New before waiting for signal
+			// This allows upgrading to new worker versions at safe checkpoints
+			if m.shouldContinueAsNew(cancelCtx, workflowTask) {
+				return m.continueAsNew(cancelCtx, workflowTask)
+			}
 
-		if err := m.waitForSignal(cancelCtx, workflowSelector); err != nil {
-			return nil, err
-		}
+			if err := m.waitForSignal(cancelCtx, workflowSelector); err != nil {
+				return nil, err
+			}
 
-		if cancelCtx.Err() != nil {
-			if errors.Is(cancelCtx.Err(), context.Canceled) {
-				log.Info("Workflow context cancelled, exiting main loop")
-				break
+			if cancelCtx.Err() != nil {
+				if errors.Is(cancelCtx.Err(), context.Canceled) {
+					log.Info("Workflow context cancelled, exiting main loop")
+					break
+				}
+				log.Error("Error while waiting for signal", "Error", cancelCtx.Err())
+				return nil, cancelCtx.Err()
 			}
-			log.Error("Error while waiting for signal", "Error", cancelCtx.Err())
-			return nil, cancelCtx.Err()
-		}
 
-		workflowSelector.Select(cancelCtx)
+			workflowSelector.Select(cancelCtx)
 
-		if workflowTask == nil {
-			continue
+			if workflowTask == nil {
+				continue
+			}
 		}
 
+		resume = false
+
 		log.Info("Resuming ...",
 			"WorkflowID", workflowTask.WorkflowID,
 			"Status", workflowTask.GetStatus(),
@@ -421,6 +513,17 @@ func (m *WorkflowManager) executeWorkflowLoop(
 			return result, cancelCtx.Err()
 		}
 
+		// The runner stopped at a task so the workflow can continue as new.
+		// Any signals that arrived since are handled by resuming the task.
+		if errors.Is(err, runner.ErrorContinueAsNew) {
+			if runner.HasPendingSignals(cancelCtx) {
+				workflowTask = result
+				resume = true
+				continue
+			}
+			return m.continueAsNew(cancelCtx, result)
+		}
+
 		// If execution completed or failed, return the result
 		if err != nil || (result != nil && result.GetStatus() != swctx.RunningStatus) {
 			return result, err
@@ -466,6 +569,11 @@ func (m *WorkflowManager) executeWorkflowStep(
 	f := m.StartWorkflow(ctx, workflowTask)
 	err := f.Get(ctx, &workflowTask)
 
+	if errors.Is(err, runner.ErrorContinueAsNew) {
+		log.Info("Workflow execution stopped to continue as new", "Entrypoint", workflowTask.GetEntrypoint())
+		return workflowTask, err
+	}
+
 	if err != nil {
 		log.Error("Workflow execution failed", "Error", err)
 		workflowTask.SetStatus(swctx.FaultedStatus)
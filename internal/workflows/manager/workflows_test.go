@@ -0,0 +1,221 @@
+package manager
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	thandTask "github.com/thand-io/agent/internal/workflows/tasks/providers/thand"
+	"go.temporal.io/sdk/converter"
+	temporalLog "go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+const continueAsNewWorkflow = `
+version: "1.0"
+workflows:
+  approval:
+    description: Approval workflow
+    enabled: true
+    workflow:
+      document:
+        dsl: "1.0.0-alpha5"
+        namespace: "thand"
+        name: "approval"
+        version: "1.0.0"
+      do:
+        - approvals:
+            thand: approvals
+            on:
+              approved: approved
+              denied: denied
+            with:
+              approvals: 3
+        - approved:
+            set:
+              outcome: approved
+            then: end
+        - denied:
+            set:
+              outcome: denied
+            then: end
+`
+
+func newContinueAsNewManager(t *testing.T) (*WorkflowManager, *models.WorkflowTask) {
+	t.Helper()
+
+	cfg := config.DefaultConfig()
+	cfg.Services.Temporal = &models.TemporalConfig{
+		ContinueAsNewHistoryLength: 100,
+	}
+
+	definitions, err := common.ReadDataToInterface([]byte(continueAsNewWorkflow), models.WorkflowDefinitions{})
+	require.NoError(t, err)
+
+	workflows, err := cfg.ApplyWorkflows([]*models.WorkflowDefinitions{definitions})
+	require.NoError(t, err)
+	cfg.Workflows.Definitions = workflows
+
+	workflowDefinition, err := cfg.GetWorkflowByName("approval")
+	require.NoError(t, err)
+
+	workflowTask, err := models.NewWorkflowContext(workflowDefinition)
+	require.NoError(t, err)
+
+	user := &models.User{Email: "requester@example.com"}
+	request := models.ElevateRequestInternal{
+		ElevateRequest: models.ElevateRequest{
+			Workflow:   "approval",
+			Role:       &models.Role{Name: "admin"},
+			Providers:  []string{"aws"},
+			Reason:     "Investigating an incident",
+			Duration:   "1h",
+			Identities: []string{user.GetIdentity()},
+		},
+		User: user,
+	}
+
+	workflowTask.SetContext(request.AsMap())
+	workflowTask.SetUser(user)
+
+	return NewWorkflowManager(cfg), workflowTask
+}
+
+func newContinueAsNewEnv(m *WorkflowManager) *testsuite.TestWorkflowEnvironment {
+
+	var suite testsuite.WorkflowTestSuite
+	suite.SetLogger(temporalLog.NewStructuredLogger(
+		slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflowWithOptions(m.createPrimaryWorkflowHandler(), workflow.RegisterOptions{
+		Name: models.TemporalExecuteElevationWorkflowName,
+	})
+
+	return env
+}
+
+func newApprovalEvent(approver string) cloudevents.Event {
+
+	event := cloudevents.NewEvent()
+	event.SetID(fmt.Sprintf("approval-%s", approver))
+	event.SetSource("urn:thand:test")
+	event.SetType(thandTask.ThandApprovalEventType)
+	event.SetExtension(models.VarsContextUser, approver)
+	event.SetData(cloudevents.ApplicationJSON, map[string]any{"approved": true})
+
+	return event
+}
+
+func TestWorkflowContinueAsNew(t *testing.T) {
+	m, workflowTask := newContinueAsNewManager(t)
+
+	// The first run is resumed and receives two approvals, the second once
+	// the history has reached the threshold
+	first := newContinueAsNewEnv(m)
+
+	first.RegisterDelayedCallback(func() {
+		first.SignalWorkflow(models.TemporalResumeSignalName, workflowTask)
+	}, time.Second)
+
+	first.RegisterDelayedCallback(func() {
+		first.SignalWorkflow(models.TemporalEventSignalName, newApprovalEvent("alice@example.com"))
+	}, 30*time.Second)
+
+	first.RegisterDelayedCallback(func() {
+		first.SetCurrentHistoryLength(200)
+		first.SignalWorkflow(models.TemporalEventSignalName, newApprovalEvent("bob@example.com"))
+	}, time.Minute)
+
+	first.ExecuteWorkflow(models.TemporalExecuteElevationWorkflowName, workflowTask)
+
+	require.True(t, first.IsWorkflowCompleted())
+
+	var continueAsNewErr *workflow.ContinueAsNewError
+	require.ErrorAs(t, first.GetWorkflowError(), &continueAsNewErr)
+
+	var continuedTask *models.WorkflowTask
+	require.NoError(t, converter.GetDefaultDataConverter().FromPayloads(continueAsNewErr.Input, &continuedTask))
+
+	assert.Equal(t, "approvals", continuedTask.GetEntrypoint())
+
+	// The thresholds captured by the first run are carried over
+	assert.Equal(t, &models.ContinueAsNewThresholds{
+		HistoryLength: 100,
+		HistorySize:   models.DefaultContinueAsNewHistorySize,
+	}, continuedTask.GetContinueAsNewThresholds())
+
+	approvals, err := models.GetContextAs[map[string]any](continuedTask, "approvals")
+	require.NoError(t, err)
+	assert.Len(t, approvals, 2)
+
+	// The new run carries on listening without being resumed and the
+	// approval after continuing completes the quorum. A config change
+	// doesn't affect it as it uses the thresholds it was started with.
+	m.config.Services.Temporal.ContinueAsNewHistoryLength = 1
+
+	second := newContinueAsNewEnv(m)
+	second.SetContinuedExecutionRunID("first-run")
+
+	second.RegisterDelayedCallback(func() {
+		second.SignalWorkflow(models.TemporalEventSignalName, newApprovalEvent("carol@example.com"))
+	}, time.Minute)
+
+	second.ExecuteWorkflow(models.TemporalExecuteElevationWorkflowName, continuedTask)
+
+	require.True(t, second.IsWorkflowCompleted())
+	require.NoError(t, second.GetWorkflowError())
+
+	var result *models.WorkflowTask
+	require.NoError(t, second.GetWorkflowResult(&result))
+
+	assert.Equal(t, map[string]any{"outcome": "approved"}, result.Output)
+
+	approvals, err = models.GetContextAs[map[string]any](result, "approvals")
+	require.NoError(t, err)
+	assert.Len(t, approvals, 3)
+	assert.Contains(t, approvals, "alice@example.com")
+	assert.Contains(t, approvals, "bob@example.com")
+	assert.Contains(t, approvals, "carol@example.com")
+}
+
+func TestWorkflowContinueAsNewBeforeThresholds(t *testing.T) {
+	m, workflowTask := newContinueAsNewManager(t)
+
+	// Runs started before the thresholds were captured only continue as
+	// new when Temporal suggests it, so this run completes in one go
+	env := newContinueAsNewEnv(m)
+	env.OnGetVersion(models.TemporalContinueAsNewChangeID, workflow.DefaultVersion, 1).
+		Return(workflow.DefaultVersion)
+
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(models.TemporalResumeSignalName, workflowTask)
+	}, time.Second)
+
+	for i, approver := range []string{"alice@example.com", "bob@example.com", "carol@example.com"} {
+		env.RegisterDelayedCallback(func() {
+			env.SetCurrentHistoryLength(200)
+			env.SignalWorkflow(models.TemporalEventSignalName, newApprovalEvent(approver))
+		}, time.Duration(i+1)*time.Minute)
+	}
+
+	env.ExecuteWorkflow(models.TemporalExecuteElevationWorkflowName, workflowTask)
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+
+	var result *models.WorkflowTask
+	require.NoError(t, env.GetWorkflowResult(&result))
+
+	assert.Nil(t, result.GetContinueAsNewThresholds())
+	assert.Equal(t, map[string]any{"outcome": "approved"}, result.Output)
+}
@@ -4,6 +4,60 @@ import (
 	"errors"
 
 	swctx "github.com/serverlessworkflow/sdk-go/v3/impl/ctx"
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	"github.com/thand-io/agent/internal/models"
+	"go.temporal.io/sdk/workflow"
 )
 
 var ErrorAwaitSignal = errors.New(string(swctx.PendingStatus))
+
+// ErrorContinueAsNew is returned when the workflow stopped at a task so
+// it can continue as new. The workflow task's entrypoint and input are
+// set to resume from that task.
+var ErrorContinueAsNew = errors.New("continue as new")
+
+// HasPendingSignals returns true if the workflow has received signals it
+// hasn't handled yet. These would be lost if the workflow continued as new.
+func HasPendingSignals(ctx workflow.Context) bool {
+
+	for _, signalName := range []string{
+		models.TemporalResumeSignalName,
+		models.TemporalEventSignalName,
+		models.TemporalTerminateSignalName,
+	} {
+		if workflow.GetSignalChannel(ctx, signalName).Len() > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shouldContinueAsNew returns true if the workflow can continue as new
+// before running the next task in the list. Only root level tasks can be
+// resumed from an entrypoint.
+func (r *ResumableWorkflowRunner) shouldContinueAsNew(taskList *model.TaskList) bool {
+
+	workflowTask := r.GetWorkflowTask()
+
+	if !workflowTask.HasTemporalContext() || taskList != r.GetTaskList() {
+		return false
+	}
+
+	// The thresholds are captured when the workflow starts so replays don't
+	// depend on the config. Runs started before then never stop at a task.
+	thresholds := workflowTask.GetContinueAsNewThresholds()
+
+	if thresholds == nil {
+		return false
+	}
+
+	ctx := workflowTask.GetTemporalContext()
+
+	// Handle any signals first, e.g. approvals, so they aren't lost
+	if ctx.Err() != nil || HasPendingSignals(ctx) {
+		return false
+	}
+
+	return thresholds.ShouldContinueAsNew(workflow.GetInfo(ctx))
+}
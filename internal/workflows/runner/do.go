@@ -105,6 +105,21 @@ func (d *ResumableWorkflowRunner) resumeTasks(
 				return nil, fmt.Errorf(
 					"flow directive target '%s' not found", flowDirective.Value)
 			}
+
+			// Tasks that loop, e.g. monitoring, grow the history so use
+			// the jump as a checkpoint to continue as new
+			if d.shouldContinueAsNew(taskList) {
+
+				log.WithFields(models.Fields{
+					"task": currentTask.Key,
+				}).Info("Continuing as new before task")
+
+				taskSupport.SetEntrypoint(currentTask.Key)
+				taskSupport.SetInput(input)
+
+				return output, ErrorContinueAsNew
+			}
+
 			continue
 		}
 
@@ -99,6 +99,11 @@ func (wr *ResumableWorkflowRunner) Run(input any) (output any, err error) {
 			workflowTask.SetStatus(swctx.WaitingStatus)
 			err = nil
 
+		} else if err != nil && errors.Is(err, ErrorContinueAsNew) {
+
+			// The workflow is still running, it'll resume in the new run
+			workflowTask.SetStatus(swctx.RunningStatus)
+
 		} else if err != nil {
 
 			// Wrap the error to ensure it has a proper instance reference
@@ -279,7 +279,7 @@ func (r *ResumableWorkflowRunner) validateRetryPolicy(taskName string, retryPoli
 	}
 
 	if retryPolicy.Limit.Duration != nil {
-		if duration, err := common.ValidateDuration(retryPolicy.Limit.Duration.AsExpression()); err == nil {
+		if duration, err := common.ParseDuration(retryPolicy.Limit.Duration.AsExpression()); err == nil {
 			config.maxDuration = duration
 		}
 	}
@@ -433,7 +433,7 @@ func (r *ResumableWorkflowRunner) calculateRetryDelay(retryPolicy *model.RetryPo
 
 	// Get base delay
 	if retryPolicy.Delay != nil {
-		if duration, err := common.ValidateDuration(retryPolicy.Delay.AsExpression()); err == nil {
+		if duration, err := common.ParseDuration(retryPolicy.Delay.AsExpression()); err == nil {
 			baseDelay = duration
 		}
 	}
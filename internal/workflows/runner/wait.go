@@ -44,7 +44,7 @@ func (r *ResumableWorkflowRunner) executeWaitTask(
 	switch v := interpolatedWith.(type) {
 	case string:
 		// Assume it's a duration string like "PT5M" (ISO 8601 duration)
-		du, err := common.ValidateDuration(v)
+		du, err := common.ParseDuration(v)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse wait duration: %w", err)
@@ -54,7 +54,7 @@ func (r *ResumableWorkflowRunner) executeWaitTask(
 
 	case model.DurationExpression:
 
-		du, err := common.ValidateDuration(v.Expression)
+		du, err := common.ParseDuration(v.Expression)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse wait duration expression: %w", err)
@@ -64,7 +64,7 @@ func (r *ResumableWorkflowRunner) executeWaitTask(
 
 	case model.Duration:
 
-		du, err := common.ValidateDuration(v.AsExpression())
+		du, err := common.ParseDuration(v.AsExpression())
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse wait duration: %w", err)
@@ -3,13 +3,17 @@ package thand
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/serverlessworkflow/sdk-go/v3/model"
 	"github.com/sirupsen/logrus"
 	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/metrics"
 	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/siem"
 	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
 	runner "github.com/thand-io/agent/internal/workflows/runner"
 	taskModel "github.com/thand-io/agent/internal/workflows/tasks/model"
@@ -21,10 +25,20 @@ type ApprovalsTask struct {
 	Approvals   int                                      `json:"approvals" default:"1"`
 	SelfApprove bool                                     `json:"selfApprove" default:"false"`
 	Notifiers   map[string]thandFunction.NotifierRequest `json:"notifiers"`
+	// Routing limits notifications to approvers within their working
+	// hours e.g. follow_the_sun
+	Routing string `json:"routing,omitempty"`
+	// Quorum requires approvals from sets of approvers instead of a
+	// simple count
+	Quorum *models.ApprovalQuorum `json:"quorum,omitempty"`
 }
 
 func (n *ApprovalsTask) IsValid() bool {
-	return n.Approvals != 0
+	return n.Approvals != 0 || n.HasQuorum()
+}
+
+func (n *ApprovalsTask) HasQuorum() bool {
+	return n.Quorum != nil && len(n.Quorum.Requirements) > 0
 }
 
 func (t *ApprovalsTask) HasNotifiers() bool {
@@ -45,10 +59,12 @@ func (t *thandTask) executeApprovalsTask(
 	call *taskModel.ThandTask,
 	input any) (any, error) {
 
+	log := getLogger(workflowTask)
+
 	elevationRequest, err := workflowTask.GetContextAsElevationRequest()
 
 	if err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
+		log.WithError(err).WithFields(logrus.Fields{
 			"taskName": taskName,
 		}).Error("Failed to get elevation request from context")
 
@@ -59,7 +75,7 @@ func (t *thandTask) executeApprovalsTask(
 	err = common.ConvertInterfaceToInterface(call.With, &approvalsTask)
 
 	// Debug logging for integration test
-	logrus.WithFields(logrus.Fields{
+	log.WithFields(logrus.Fields{
 		"taskName":       taskName,
 		"callWith":       fmt.Sprintf("%+v", call.With),
 		"approvalsTask":  fmt.Sprintf("%+v", approvalsTask),
@@ -68,7 +84,7 @@ func (t *thandTask) executeApprovalsTask(
 	}).Info("Parsed approvals task")
 
 	if err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
+		log.WithError(err).WithFields(logrus.Fields{
 			"taskName": taskName,
 		}).Error("Failed to parse notification request")
 
@@ -79,6 +95,16 @@ func (t *thandTask) executeApprovalsTask(
 		return nil, errors.New("invalid notification request")
 	}
 
+	if len(approvalsTask.Routing) > 0 && approvalsTask.Routing != models.ApprovalRoutingFollowTheSun {
+		return nil, fmt.Errorf("unsupported approval routing: %s", approvalsTask.Routing)
+	}
+
+	if approvalsTask.HasQuorum() {
+		if err := approvalsTask.Quorum.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid approval quorum: %w", err)
+		}
+	}
+
 	availableIdentities := elevationRequest.ResolveIdentities(
 		workflowTask.GetContext(),
 		t.config.GetProvidersByCapability(
@@ -88,7 +114,23 @@ func (t *thandTask) executeApprovalsTask(
 
 	if common.IsNilOrZero(input) {
 
-		logrus.Infof("Starting Thand approvals task: %s", taskName)
+		log.Infof("Starting Thand approvals task: %s", taskName)
+
+		// Policies that require approval, and active change freezes, can
+		// add extra approvers
+		addPolicyApprovers(&approvalsTask, getPolicyApprovers(workflowTask))
+		addPolicyApprovers(&approvalsTask, getFreezeApprovers(workflowTask))
+
+		// Start the quorum with every requirement unmet so it can be shown
+		// before the first approval arrives
+		if approvalsTask.HasQuorum() {
+			status, err := common.ConvertInterfaceToMap(
+				approvalsTask.Quorum.Evaluate(map[string][]string{}))
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert approval quorum: %w", err)
+			}
+			workflowTask.SetContextKeyValue(models.VarsContextApprovalQuorum, status)
+		}
 
 		newConfig := &models.BasicConfig{}
 		newConfig.Update(approvalsTask.AsMap())
@@ -105,7 +147,7 @@ func (t *thandTask) executeApprovalsTask(
 			)
 
 			if err != nil {
-				logrus.WithError(err).WithFields(logrus.Fields{
+				log.WithError(err).WithFields(logrus.Fields{
 					"taskName": taskName,
 				}).Error("Failed to create approval notifications")
 
@@ -114,10 +156,10 @@ func (t *thandTask) executeApprovalsTask(
 		}
 
 	} else {
-		logrus.Infof("Resuming Thand approvals task: %s", taskName)
+		log.Infof("Resuming Thand approvals task: %s", taskName)
 	}
 
-	logrus.Infof("Executing Thand monitor task: %s", taskName)
+	log.Infof("Executing Thand monitor task: %s", taskName)
 
 	approval, err := runner.ListenTaskHandler(
 		workflowTask, fmt.Sprintf("%s.listen", taskName), &model.ListenTask{
@@ -136,7 +178,7 @@ func (t *thandTask) executeApprovalsTask(
 
 	if err != nil {
 
-		logrus.WithError(err).WithFields(logrus.Fields{
+		log.WithError(err).WithFields(logrus.Fields{
 			"taskName": taskName,
 		}).Error("Failed to listen for approval event")
 
@@ -160,11 +202,9 @@ func (t *thandTask) executeApprovalsTask(
 		then: check_approval
 	*/
 
-	workflowContext := workflowTask.GetContextAsMap()
-
-	approvals, ok := workflowContext["approvals"].(map[string]any)
+	approvals, err := models.GetContextAs[map[string]any](workflowTask, "approvals")
 
-	if !ok {
+	if err != nil {
 		approvals = map[string]any{}
 	}
 
@@ -178,17 +218,33 @@ func (t *thandTask) executeApprovalsTask(
 		userIdentity, userExists := extensions[models.VarsContextUser].(string)
 
 		if !userExists {
-			logrus.Warn("Approval event missing user extension")
+			log.Warn("Approval event missing user extension")
 			return &defaultFlowState, nil
 		}
 
+		// Delegates approve on behalf of the approver that delegated to
+		// them while the delegation is active
+		onBehalfOf := getApprovalDelegations(workflowTask).GetOnBehalfOf(
+			userIdentity, getWorkflowTime(workflowTask))
+
 		// Check if self-approval is disabled and the approver is the requester or one of the elevated identities
 		if !approvalsTask.SelfApprove {
 			requesterIdentity := elevationRequest.User.GetIdentity()
 
+			// Delegation can't be used to approve your own request
+			if len(onBehalfOf) > 0 && (onBehalfOf == requesterIdentity || availableIdentities[onBehalfOf] != nil) {
+				log.WithFields(logrus.Fields{
+					"taskName":     taskName,
+					"userIdentity": userIdentity,
+					"onBehalfOf":   onBehalfOf,
+				}).Warn("Self-approval is disabled; ignoring approval on behalf of the requester or an identity being elevated")
+
+				return &defaultFlowState, nil
+			}
+
 			// Check if approver is the requester
 			if userIdentity == requesterIdentity {
-				logrus.WithFields(logrus.Fields{
+				log.WithFields(logrus.Fields{
 					"taskName":          taskName,
 					"userIdentity":      userIdentity,
 					"requesterIdentity": requesterIdentity,
@@ -200,7 +256,7 @@ func (t *thandTask) executeApprovalsTask(
 
 			// Check if approver is one of the identities being elevated
 			if availableIdentities[userIdentity] != nil {
-				logrus.WithFields(logrus.Fields{
+				log.WithFields(logrus.Fields{
 					"taskName":     taskName,
 					"userIdentity": userIdentity,
 				}).Warn("Self-approval is disabled; ignoring approval from identity being elevated")
@@ -217,22 +273,86 @@ func (t *thandTask) executeApprovalsTask(
 			approved, ok := approvedVal.(bool)
 
 			if !ok {
-				logrus.WithFields(logrus.Fields{
+				log.WithFields(logrus.Fields{
 					"taskName":     taskName,
 					"userIdentity": userIdentity,
 				}).Warn("Approval value is not a boolean; ignoring this approval")
 				return &defaultFlowState, nil
 			}
 
-			approvals[userIdentity] = map[string]any{
-				"approved":  approved,
-				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			// Retries of the same decision share a key so they're ignored
+			idempotencyKey, _ := approvalData[models.ApprovalIdempotencyKeyField].(string)
+
+			if len(idempotencyKey) == 0 {
+				idempotencyKey = models.NewApprovalIdempotencyKey(
+					userIdentity, workflowTask.WorkflowID, approved)
+			}
+
+			approvalRecord := map[string]any{
+				"approved":        approved,
+				"timestamp":       time.Now().UTC().Format(time.RFC3339),
+				"idempotency_key": idempotencyKey,
+			}
+
+			if len(onBehalfOf) > 0 {
+				approvalRecord["on_behalf_of"] = onBehalfOf
+
+				log.WithFields(logrus.Fields{
+					"taskName":     taskName,
+					"userIdentity": userIdentity,
+					"onBehalfOf":   onBehalfOf,
+				}).Infof("Approval decision by %s on behalf of %s", userIdentity, onBehalfOf)
+			}
+
+			// Record which requirements of the quorum the approver
+			// satisfies using their group membership. Delegates also
+			// satisfy the requirements of the approver they act for.
+			if approved && approvalsTask.HasQuorum() {
+				approver := t.resolveIdentity(userIdentity)
+				requirements := approvalsTask.Quorum.MatchRequirements(
+					userIdentity, approver.User)
+
+				if len(onBehalfOf) > 0 {
+					delegator := t.resolveIdentity(onBehalfOf)
+					for _, requirement := range approvalsTask.Quorum.MatchRequirements(onBehalfOf, delegator.User) {
+						if !slices.Contains(requirements, requirement) {
+							requirements = append(requirements, requirement)
+						}
+					}
+				}
+
+				approvalRecord["requirements"] = requirements
+			}
+
+			change, recorded := mergeApproval(approvals, userIdentity, approvalRecord)
+
+			if !recorded {
+				log.WithFields(logrus.Fields{
+					"taskName":       taskName,
+					"userIdentity":   userIdentity,
+					"idempotencyKey": idempotencyKey,
+				}).Info("Ignoring replayed approval decision")
+
+				return &defaultFlowState, nil
+			}
+
+			if change != nil {
+				log.WithFields(logrus.Fields{
+					"taskName":     taskName,
+					"userIdentity": userIdentity,
+					"previous":     change.Previous,
+					"approved":     change.Approved,
+				}).Warn("Approver changed their decision; the latest decision replaces the earlier one")
+
+				if err := recordApprovalChange(workflowTask, change); err != nil {
+					return nil, err
+				}
 			}
 
 			// If the approval was denied then mark the approval as denied
 			if !approved {
 
-				logrus.WithFields(logrus.Fields{
+				log.WithFields(logrus.Fields{
 					"taskName":     taskName,
 					"userIdentity": userIdentity,
 				}).Info("Approval denied by user")
@@ -271,37 +391,166 @@ func (t *thandTask) executeApprovalsTask(
 		taskName,
 		approvals,
 		approvalsTask.Approvals,
+		approvalsTask.Quorum,
 		approvedState,
 		deniedState,
 	)
 
 	if err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
+		log.WithError(err).WithFields(logrus.Fields{
 			"taskName": taskName,
 		}).Error("Failed to execute switch task for approval logic")
 
 		return nil, err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	log.WithFields(logrus.Fields{
 		"taskName":      taskName,
 		"flowDirective": flowDirective.Value,
 	}).Info("Completed Thand approvals task")
 
+	recordApprovalDecision(workflowTask, flowDirective.Value, approvedState, deniedState)
+
 	return flowDirective, nil
 }
 
+// addPolicyApprovers adds approvers required by policy to every notifier
+func addPolicyApprovers(approvalsTask *ApprovalsTask, approvers []string) {
+
+	if len(approvers) == 0 {
+		return
+	}
+
+	for providerKey, notifier := range approvalsTask.Notifiers {
+		for _, approver := range approvers {
+			if !slices.Contains(notifier.To, approver) {
+				notifier.To = append(notifier.To, approver)
+			}
+		}
+		approvalsTask.Notifiers[providerKey] = notifier
+	}
+}
+
+// mergeApproval records the decision of an approver, replacing any earlier
+// decision from them including ones recorded under a variation of their
+// identity. Returns false if the decision repeats the idempotency key of
+// their current decision and was ignored. A change is returned when the
+// approver reverses their decision.
+func mergeApproval(
+	approvals map[string]any,
+	approver string,
+	record map[string]any,
+) (*models.ApprovalChange, bool) {
+
+	normalized := models.NormalizeApproverIdentity(approver)
+	idempotencyKey, _ := record["idempotency_key"].(string)
+
+	var previous map[string]any
+	var variations []string
+
+	// Sorted so the same previous decision is found on replay
+	for _, identity := range slices.Sorted(maps.Keys(approvals)) {
+
+		if models.NormalizeApproverIdentity(identity) != normalized {
+			continue
+		}
+
+		variations = append(variations, identity)
+
+		existing, ok := approvals[identity].(map[string]any)
+
+		if !ok {
+			continue
+		}
+
+		if key, _ := existing["idempotency_key"].(string); len(idempotencyKey) > 0 && key == idempotencyKey {
+			return nil, false
+		}
+
+		if previous == nil || identity == approver {
+			previous = existing
+		}
+	}
+
+	for _, identity := range variations {
+		delete(approvals, identity)
+	}
+
+	approvals[approver] = record
+
+	if previous == nil {
+		return nil, true
+	}
+
+	previousApproved, hasPrevious := previous["approved"].(bool)
+	approved, _ := record["approved"].(bool)
+
+	if !hasPrevious || previousApproved == approved {
+		return nil, true
+	}
+
+	timestamp, _ := record["timestamp"].(string)
+
+	return &models.ApprovalChange{
+		Approver:       approver,
+		Previous:       previousApproved,
+		Approved:       approved,
+		IdempotencyKey: idempotencyKey,
+		Timestamp:      timestamp,
+	}, true
+}
+
+// recordApprovalChange adds the change of decision to the audit trail in
+// the workflow context
+func recordApprovalChange(workflowTask *models.WorkflowTask, change *models.ApprovalChange) error {
+
+	changes, err := models.GetContextAs[[]any](workflowTask, models.VarsContextApprovalChanges)
+
+	if err != nil {
+		changes = []any{}
+	}
+
+	changeMap, err := common.ConvertInterfaceToMap(change)
+
+	if err != nil {
+		return fmt.Errorf("failed to convert approval change: %w", err)
+	}
+
+	workflowTask.SetContextKeyValue(models.VarsContextApprovalChanges, append(changes, changeMap))
+
+	return nil
+}
+
 // evaluateApprovalSwitch evaluates the approval logic using a switch task
-// to determine if the request should be approved, denied, or loop back for more approvals
+// to determine if the request should be approved, denied, or loop back for more approvals.
+// With a quorum the approvals must meet its requirements instead of the
+// required count. Any denial still denies the request.
 func (t *thandTask) evaluateApprovalSwitch(
 	workflowTask *models.WorkflowTask,
 	taskName string,
 	approvals map[string]any,
 	requiredApprovals int,
+	quorum *models.ApprovalQuorum,
 	approvedState string,
 	deniedState string,
 ) (*model.FlowDirective, error) {
 
+	approvedWhen := fmt.Sprintf("[$context.approvals | to_entries[] | select(.value.approved == true)] | length >= %d", requiredApprovals)
+
+	if quorum != nil && len(quorum.Requirements) > 0 {
+
+		status, err := common.ConvertInterfaceToMap(
+			quorum.Evaluate(getApprovedRequirements(approvals)))
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert approval quorum: %w", err)
+		}
+
+		workflowTask.SetContextKeyValue(models.VarsContextApprovalQuorum, status)
+
+		approvedWhen = fmt.Sprintf("$context.%s.satisfied == true", models.VarsContextApprovalQuorum)
+	}
+
 	return runner.SwitchTaskHandler(
 		workflowTask,
 		map[string]any{
@@ -321,7 +570,7 @@ func (t *thandTask) evaluateApprovalSwitch(
 			}, {
 				"case2": model.SwitchCase{
 					When: &model.RuntimeExpression{
-						Value: fmt.Sprintf("[$context.approvals | to_entries[] | select(.value.approved == true)] | length >= %d", requiredApprovals),
+						Value: approvedWhen,
 					},
 					Then: &model.FlowDirective{
 						Value: approvedState, // proceed to the next state
@@ -338,6 +587,42 @@ func (t *thandTask) evaluateApprovalSwitch(
 		})
 }
 
+// getApprovedRequirements returns the requirements recorded against each
+// approval that was approved
+func getApprovedRequirements(approvals map[string]any) map[string][]string {
+
+	approvers := map[string][]string{}
+
+	for identity, value := range approvals {
+
+		approval, ok := value.(map[string]any)
+
+		if !ok {
+			continue
+		}
+
+		if approved, ok := approval["approved"].(bool); !ok || !approved {
+			continue
+		}
+
+		var requirements []string
+		switch recorded := approval["requirements"].(type) {
+		case []string:
+			requirements = recorded
+		case []any:
+			for _, requirement := range recorded {
+				if name, ok := requirement.(string); ok {
+					requirements = append(requirements, name)
+				}
+			}
+		}
+
+		approvers[identity] = requirements
+	}
+
+	return approvers
+}
+
 func (t *thandTask) makeApprovalNotifications(
 	workflowTask *models.WorkflowTask,
 	taskName string,
@@ -345,10 +630,20 @@ func (t *thandTask) makeApprovalNotifications(
 	elevationRequest *models.ElevateRequestInternal,
 ) error {
 
-	// In parallel create a notifier for each of the notifiers
-	// Build notification tasks for each provider
-	var notifyTasks []notifyTask
-	for providerKey, notifierRequest := range approvalsTask.Notifiers {
+	log := getLogger(workflowTask)
+
+	type approvalRecipients struct {
+		providerKey string
+		notifier    NotifierImpl
+		recipients  []string
+	}
+
+	// Get recipients for each notifier
+	var notifiers []approvalRecipients
+	var approvers []string
+	// Sorted so the routing input is the same on replay
+	for _, providerKey := range slices.Sorted(maps.Keys(approvalsTask.Notifiers)) {
+		notifierRequest := approvalsTask.Notifiers[providerKey]
 		// Create an ApprovalNotifier for each provider
 		approvalNotifier := NewApprovalsNotifier(
 			t.config,
@@ -356,31 +651,98 @@ func (t *thandTask) makeApprovalNotifications(
 			elevationRequest,
 			&ApprovalNotifier{
 				Approvals:   approvalsTask.Approvals,
+				Quorum:      approvalsTask.Quorum,
 				SelfApprove: approvalsTask.SelfApprove,
 				Notifier:    notifierRequest,
 				Entrypoint:  taskName,
 			},
 		)
 
-		// Get recipients for this notifier
 		recipients := approvalNotifier.GetRecipients()
 
-		logrus.WithFields(logrus.Fields{
+		log.WithFields(logrus.Fields{
 			"providerKey": providerKey,
 			"recipients":  recipients,
 		}).Info("Processing approval notifier")
 
-		// Build notification tasks for each recipient
 		for _, recipientId := range recipients {
+			if !slices.Contains(approvers, recipientId) {
+				approvers = append(approvers, recipientId)
+			}
+		}
+
+		notifiers = append(notifiers, approvalRecipients{
+			providerKey: providerKey,
+			notifier:    approvalNotifier,
+			recipients:  recipients,
+		})
+	}
+
+	// Approvers that are away hand their approvals to their delegates.
+	// Failing to look them up shouldn't hold up the request.
+	delegations, delegateErr := t.delegateApprovers(workflowTask, taskName, approvers)
+
+	if delegateErr != nil {
+		log.WithError(delegateErr).WithFields(logrus.Fields{
+			"taskName": taskName,
+		}).Warn("Failed to look up approver delegations; notifying the approvers")
+	} else {
+
+		delegationsMap, err := common.ConvertInterfaceToMap(delegations)
+
+		if err != nil {
+			return fmt.Errorf("failed to convert approver delegations: %w", err)
+		}
+
+		workflowTask.SetContextKeyValue(models.VarsContextApprovalDelegations, delegationsMap)
+
+		approvers = delegations.Apply(approvers, delegations.EvaluatedAt)
+
+		for i := range notifiers {
+			notifiers[i].recipients = delegations.Apply(
+				notifiers[i].recipients, delegations.EvaluatedAt)
+		}
+	}
+
+	// Only notify the approvers that are currently working
+	if len(approvalsTask.Routing) > 0 {
+
+		routing, err := t.routeApprovers(workflowTask, taskName, approvalsTask.Routing, approvers)
+
+		if err != nil {
+			return fmt.Errorf("failed to route approval notifications: %w", err)
+		}
+
+		routingMap, err := common.ConvertInterfaceToMap(routing)
+
+		if err != nil {
+			return fmt.Errorf("failed to convert approval routing: %w", err)
+		}
+
+		workflowTask.SetContextKeyValue(models.VarsContextApprovalRouting, routingMap)
+
+		for i := range notifiers {
+			notifiers[i].recipients = filterRoutedRecipients(
+				notifiers[i].recipients, routing.Recipients)
+		}
+	}
+
+	// Build notification tasks for each recipient
+	var notifyTasks []notifyTask
+	for _, notifier := range notifiers {
+
+		approvalNotifier := notifier.notifier
+
+		for _, recipientId := range notifier.recipients {
 
 			recipientIdentity := t.resolveIdentity(
 				recipientId,
 			)
 
 			if recipientIdentity == nil {
-				logrus.WithFields(logrus.Fields{
+				log.WithFields(logrus.Fields{
 					"recipient":   recipientId,
-					"providerKey": providerKey,
+					"providerKey": notifier.providerKey,
 				}).Warn("Failed to resolve recipient identity; skipping notification for this recipient")
 				continue
 			}
@@ -395,14 +757,17 @@ func (t *thandTask) makeApprovalNotifications(
 				Provider:  approvalNotifier.GetProviderName(),
 			})
 
-			logrus.WithFields(logrus.Fields{
+			log.WithFields(logrus.Fields{
 				"recipient":   recipientId,
 				"provider":    approvalNotifier.GetProviderName(),
-				"providerKey": providerKey,
+				"providerKey": notifier.providerKey,
 			}).Debug("Prepared approval notification task")
 		}
 	}
 
+	// Record who was asked to approve so they can follow the request
+	workflowTask.SetContextKeyValue(models.VarsContextApprovers, approvers)
+
 	// Execute all notifications in parallel
 
 	var err error
@@ -415,7 +780,7 @@ func (t *thandTask) makeApprovalNotifications(
 	}
 
 	if err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
+		log.WithError(err).WithFields(logrus.Fields{
 			"taskName": taskName,
 		}).Error("Failed to execute approval notifications")
 
@@ -423,9 +788,9 @@ func (t *thandTask) makeApprovalNotifications(
 	}
 
 	// Process results using shared function
-	if err := processNotificationResults(notifyResults, "Approval notification"); err != nil {
+	if err := processNotificationResults(workflowTask, notifyResults, "Approval notification"); err != nil {
 
-		logrus.WithError(err).WithFields(logrus.Fields{
+		log.WithError(err).WithFields(logrus.Fields{
 			"taskName": taskName,
 		}).Error("Failed to process approval notification results")
 
@@ -434,3 +799,44 @@ func (t *thandTask) makeApprovalNotifications(
 
 	return nil
 }
+
+// recordApprovalDecision observes the time from request to decision once the
+// approvals task routes to the approved or denied state, and sends the
+// decision to the SIEM
+func recordApprovalDecision(
+	workflowTask *models.WorkflowTask,
+	nextState string,
+	approvedState string,
+	deniedState string,
+) {
+
+	if workflowTask.IsReplaying() {
+		return
+	}
+
+	var decision string
+	var eventType siem.EventType
+	switch nextState {
+	case approvedState:
+		decision = "approved"
+		eventType = siem.EventElevationApproved
+	case deniedState:
+		decision = "denied"
+		eventType = siem.EventElevationDenied
+	default:
+		// Still waiting on more approvals
+		return
+	}
+
+	siem.Record(siem.NewEvent(eventType, workflowTask))
+
+	if workflowTask.CreatedAt.IsZero() {
+		return
+	}
+
+	metrics.ObserveApprovalLatency(
+		workflowTask.WorkflowName,
+		decision,
+		time.Since(workflowTask.CreatedAt),
+	)
+}
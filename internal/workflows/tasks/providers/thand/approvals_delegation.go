@@ -0,0 +1,88 @@
+package thand
+
+import (
+	"context"
+	"time"
+
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	"github.com/thand-io/agent/internal/models"
+	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// delegateApprovers looks up the delegations of the approvers. They're read
+// from the storage service so under Temporal they're read in an activity.
+func (t *thandTask) delegateApprovers(
+	workflowTask *models.WorkflowTask,
+	taskName string,
+	approvers []string,
+) (*models.ApprovalDelegations, error) {
+
+	request := &thandFunction.DelegateRequest{
+		Approvers: approvers,
+	}
+
+	if !workflowTask.HasTemporalContext() {
+		return thandFunction.DelegateApprovers(context.Background(), t.config, request, time.Now())
+	}
+
+	serviceClient := t.config.GetServices()
+
+	aoctx := workflow.WithActivityOptions(workflowTask.GetTemporalContext(), workflow.ActivityOptions{
+		TaskQueue:           serviceClient.GetTemporal().GetTaskQueue(),
+		StartToCloseTimeout: time.Minute,
+		// Approvals carry on without delegations rather than waiting for
+		// the storage service
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 3,
+		},
+	})
+
+	var delegations models.ApprovalDelegations
+
+	err := workflow.ExecuteActivity(
+		aoctx,
+		thandFunction.ThandDelegateFunction,
+		workflowTask,
+		taskName,
+		model.CallFunction{
+			Call: thandFunction.ThandDelegateFunction,
+			With: map[string]any{
+				"approvers": request.Approvers,
+			},
+		},
+		nil,
+	).Get(aoctx, &delegations)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &delegations, nil
+}
+
+// getApprovalDelegations returns the delegations recorded when the
+// approvals task started
+func getApprovalDelegations(workflowTask *models.WorkflowTask) *models.ApprovalDelegations {
+
+	delegations, err := models.GetContextAs[models.ApprovalDelegations](
+		workflowTask, models.VarsContextApprovalDelegations)
+
+	if err != nil {
+		return nil
+	}
+
+	return &delegations
+}
+
+// getWorkflowTime returns the current time, from the workflow under
+// Temporal so replays see the same time
+func getWorkflowTime(workflowTask *models.WorkflowTask) time.Time {
+
+	if ctx := workflowTask.GetTemporalContext(); ctx != nil {
+		return workflow.Now(ctx)
+	}
+
+	return time.Now()
+}
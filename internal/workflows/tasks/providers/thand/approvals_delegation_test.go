@@ -0,0 +1,99 @@
+package thand
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// TestApprovalDelegationsAcrossWindow follows a request that is still
+// waiting for approval when a delegation starts and when it ends
+func TestApprovalDelegationsAcrossWindow(t *testing.T) {
+
+	start := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(14 * 24 * time.Hour)
+
+	stored := []models.Delegation{{
+		ID:       "delegation-1",
+		Approver: "alice@example.com",
+		Delegate: "bob@example.com",
+		Start:    start,
+		End:      end,
+	}}
+
+	tests := []struct {
+		name string
+		// When the approvals task started and notified the approvers
+		requested time.Time
+		notified  []string
+		// When bob approved and who he approved for
+		approved   time.Time
+		onBehalfOf string
+	}{
+		{
+			name:       "requested before the delegation starts",
+			requested:  start.Add(-time.Hour),
+			notified:   []string{"alice@example.com"},
+			approved:   start,
+			onBehalfOf: "alice@example.com",
+		},
+		{
+			name:       "approved before the delegation starts",
+			requested:  start.Add(-time.Hour),
+			notified:   []string{"alice@example.com"},
+			approved:   start.Add(-time.Second),
+			onBehalfOf: "",
+		},
+		{
+			name:       "requested during the delegation",
+			requested:  start.Add(time.Hour),
+			notified:   []string{"bob@example.com"},
+			approved:   end.Add(-time.Second),
+			onBehalfOf: "alice@example.com",
+		},
+		{
+			name:       "approved after the delegation ends",
+			requested:  end.Add(-time.Hour),
+			notified:   []string{"bob@example.com"},
+			approved:   end,
+			onBehalfOf: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			delegations := models.NewApprovalDelegations(
+				[]string{"alice@example.com"}, stored, models.DelegationModeSubstitute, tt.requested)
+
+			assert.Equal(t, tt.notified,
+				delegations.Apply([]string{"alice@example.com"}, delegations.EvaluatedAt))
+
+			// The delegations are kept in the workflow context until the
+			// approvals arrive
+			delegationsMap, err := common.ConvertInterfaceToMap(delegations)
+			require.NoError(t, err)
+
+			workflowTask := &models.WorkflowTask{WorkflowID: "test-workflow"}
+			workflowTask.SetContextKeyValue(models.VarsContextApprovalDelegations, delegationsMap)
+
+			restored := getApprovalDelegations(workflowTask)
+			require.NotNil(t, restored)
+
+			assert.Equal(t, tt.onBehalfOf, restored.GetOnBehalfOf("bob@example.com", tt.approved))
+		})
+	}
+}
+
+func TestGetApprovalDelegationsMissing(t *testing.T) {
+	workflowTask := &models.WorkflowTask{WorkflowID: "test-workflow"}
+
+	delegations := getApprovalDelegations(workflowTask)
+
+	assert.Nil(t, delegations)
+	assert.Empty(t, delegations.GetOnBehalfOf("bob@example.com", time.Now()))
+}
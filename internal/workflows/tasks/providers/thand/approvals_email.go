@@ -5,12 +5,13 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/sirupsen/logrus"
 	"github.com/thand-io/agent/internal/models"
 )
 
 // createApprovalEmailBody creates the email body for approval requests
 func (a *approvalsNotifier) createApprovalEmailBody() (string, string) {
+	log := getLogger(a.workflowTask)
+
 	elevationReq := a.elevationReq
 	notifyReq := a.req
 	workflowTask := a.workflowTask
@@ -19,6 +20,14 @@ func (a *approvalsNotifier) createApprovalEmailBody() (string, string) {
 	var plainText strings.Builder
 	plainText.WriteString("A user has requested elevated access and requires your approval.\n\n")
 
+	if elevationReq.RiskFlags.IsRaised() {
+		plainText.WriteString("Warning, this request is unusual:\n")
+		for _, description := range elevationReq.RiskFlags.GetDescriptions() {
+			plainText.WriteString(fmt.Sprintf("- %s\n", description))
+		}
+		plainText.WriteString("\n")
+	}
+
 	if elevationReq.User != nil {
 		plainText.WriteString(fmt.Sprintf("Requested by: %s", elevationReq.User.Name))
 		if len(elevationReq.User.Email) > 0 {
@@ -39,7 +48,7 @@ func (a *approvalsNotifier) createApprovalEmailBody() (string, string) {
 	}
 
 	if len(elevationReq.Duration) > 0 {
-		plainText.WriteString(fmt.Sprintf("Duration: %s\n", elevationReq.Duration))
+		plainText.WriteString(fmt.Sprintf("Duration: %s\n", elevationReq.GetFormattedDuration()))
 	}
 
 	if len(elevationReq.Reason) > 0 {
@@ -119,7 +128,7 @@ func (a *approvalsNotifier) createApprovalEmailBody() (string, string) {
 	// Build data map for template
 	data := map[string]any{
 		"Providers":  strings.Join(elevationReq.Providers, ", "),
-		"Duration":   elevationReq.Duration,
+		"Duration":   elevationReq.GetFormattedDuration(),
 		"Reason":     elevationReq.Reason,
 		"Identities": elevationReq.Identities,
 	}
@@ -128,6 +137,10 @@ func (a *approvalsNotifier) createApprovalEmailBody() (string, string) {
 		data["Message"] = notifyReq.Notifier.Message
 	}
 
+	if elevationReq.RiskFlags.IsRaised() {
+		data["RiskFlags"] = elevationReq.RiskFlags.GetDescriptions()
+	}
+
 	if elevationReq.User != nil {
 		data["User"] = map[string]any{
 			"Name":  elevationReq.User.Name,
@@ -167,44 +180,51 @@ func (a *approvalsNotifier) createApprovalEmailBody() (string, string) {
 	}
 
 	// Add approval action section with approval tracking logic
-	if notifyReq.Approvals > 0 {
-		// Get current approvals from workflow context
-		workflowContext := workflowTask.GetContextAsMap()
-		approvals, ok := workflowContext["approvals"].([]any)
-		if !ok {
-			approvals = []any{}
-		}
-
-		// Count existing approved approvals
-		approvedCount := 0
-		for _, approval := range approvals {
-			if approvalMap, ok := approval.(map[string]any); ok {
-				if approved, exists := approvalMap["approved"]; exists {
-					if approvedBool, ok := approved.(bool); ok && approvedBool {
-						approvedCount++
-					}
+	if notifyReq.RequiresApproval() {
+
+		var actionMessage string
+		var showActions bool
+
+		if notifyReq.HasQuorum() {
+
+			// Show which requirements of the quorum are still unmet
+			status := a.getQuorumStatus()
+			actionMessage = fmt.Sprintf("Action Required:\n%s", getQuorumActionMessage(status))
+			showActions = !status.Satisfied
+
+		} else {
+
+			// Get current approvals from workflow context
+			approvals, _ := models.GetContextAs[[]map[string]any](workflowTask, "approvals")
+
+			// Count existing approved approvals
+			approvedCount := 0
+			for _, approval := range approvals {
+				if approved, ok := approval["approved"].(bool); ok && approved {
+					approvedCount++
 				}
 			}
-		}
 
-		remainingApprovals := notifyReq.Approvals - approvedCount
+			remainingApprovals := notifyReq.Approvals - approvedCount
+
+			// Create dynamic message based on approval requirements
+			if notifyReq.Approvals == 1 {
+				actionMessage = "Action Required:\nOne approval is required. Please review the request and choose an action."
+			} else if remainingApprovals <= 0 {
+				actionMessage = "Action Required:\nSufficient approvals have been received. Please review the request and choose an action."
+			} else if remainingApprovals == 1 {
+				actionMessage = fmt.Sprintf("Action Required:\n%d more approval is needed (%d of %d received). Please review the request and choose an action.", remainingApprovals, approvedCount, notifyReq.Approvals)
+			} else {
+				actionMessage = fmt.Sprintf("Action Required:\n%d more approvals are needed (%d of %d received). Please review the request and choose an action.", remainingApprovals, approvedCount, notifyReq.Approvals)
+			}
 
-		// Create dynamic message based on approval requirements
-		var actionMessage string
-		if notifyReq.Approvals == 1 {
-			actionMessage = "Action Required:\nOne approval is required. Please review the request and choose an action."
-		} else if remainingApprovals <= 0 {
-			actionMessage = "Action Required:\nSufficient approvals have been received. Please review the request and choose an action."
-		} else if remainingApprovals == 1 {
-			actionMessage = fmt.Sprintf("Action Required:\n%d more approval is needed (%d of %d received). Please review the request and choose an action.", remainingApprovals, approvedCount, notifyReq.Approvals)
-		} else {
-			actionMessage = fmt.Sprintf("Action Required:\n%d more approvals are needed (%d of %d received). Please review the request and choose an action.", remainingApprovals, approvedCount, notifyReq.Approvals)
+			showActions = remainingApprovals > 0
 		}
 
 		plainText.WriteString(fmt.Sprintf("\n%s\n\n", actionMessage))
 
 		// Add action buttons with URLs
-		if remainingApprovals > 0 {
+		if showActions {
 			approveURL := a.createCallbackUrl(workflowTask, notifyReq, true)
 			denyURL := a.createCallbackUrl(workflowTask, notifyReq, false)
 			viewRequestURL := a.createViewRequestUrl(workflowTask)
@@ -232,7 +252,7 @@ func (a *approvalsNotifier) createApprovalEmailBody() (string, string) {
 	// Render HTML email using template
 	html, err := RenderEmailWithTemplate("Access Request - Approval Required", GetApprovalContentTemplate(), data)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to render approval email")
+		log.WithError(err).Error("Failed to render approval email")
 		return plainText.String(), ""
 	}
 
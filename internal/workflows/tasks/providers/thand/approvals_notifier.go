@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/serverlessworkflow/sdk-go/v3/model"
-	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config"
@@ -17,11 +16,22 @@ import (
 
 type ApprovalNotifier struct {
 	Approvals   int                           `json:"approvals" default:"1"`
+	Quorum      *models.ApprovalQuorum        `json:"quorum,omitempty"`
 	SelfApprove bool                          `json:"selfApprove" default:"false"`
 	Notifier    thandFunction.NotifierRequest `json:"notifier"`
 	Entrypoint  string                        `json:"entrypoint"`
 }
 
+func (n *ApprovalNotifier) HasQuorum() bool {
+	return n.Quorum != nil && len(n.Quorum.Requirements) > 0
+}
+
+// RequiresApproval returns true if the recipients are asked to approve
+// rather than just being notified
+func (n *ApprovalNotifier) RequiresApproval() bool {
+	return n.Approvals > 0 || n.HasQuorum()
+}
+
 type approvalsNotifier struct {
 	config       *config.Config
 	workflowTask *models.WorkflowTask
@@ -63,8 +73,59 @@ func (a *approvalsNotifier) GetProviderName() string {
 	return a.req.Notifier.Provider
 }
 
+func (a *approvalsNotifier) GetDigest() *models.NotificationDigest {
+	return a.req.Notifier.GetDigest()
+}
+
+// GetDigestEntry summarises the request for a digest. The approval links
+// signal this workflow, so each request in the digest is approved on its own.
+func (a *approvalsNotifier) GetDigestEntry(toIdentity *models.Identity) *models.NotificationDigestEntry {
+
+	elevationReq := a.elevationReq
+
+	var summary strings.Builder
+
+	if elevationReq.User != nil {
+		summary.WriteString(fmt.Sprintf("*%s* requested", elevationReq.User.Name))
+	} else {
+		summary.WriteString("Requested")
+	}
+
+	if elevationReq.Role != nil {
+		summary.WriteString(fmt.Sprintf(" *%s*", elevationReq.Role.Name))
+	}
+
+	if len(elevationReq.Duration) > 0 {
+		summary.WriteString(fmt.Sprintf(" for %s", elevationReq.GetFormattedDuration()))
+	}
+
+	if len(elevationReq.Reason) > 0 {
+		summary.WriteString(fmt.Sprintf(": %s", elevationReq.Reason))
+	}
+
+	entry := &models.NotificationDigestEntry{
+		Summary: summary.String(),
+		ViewURL: a.createViewRequestUrl(a.workflowTask),
+	}
+
+	if a.req.HasQuorum() {
+		if status := a.getQuorumStatus(); !status.Satisfied {
+			entry.Summary = fmt.Sprintf("%s (still needs %s)", entry.Summary, status.String())
+		}
+	}
+
+	if a.req.RequiresApproval() {
+		entry.ApproveURL = a.createCallbackUrl(a.workflowTask, a.req, true)
+		entry.DenyURL = a.createCallbackUrl(a.workflowTask, a.req, false)
+	}
+
+	return entry
+}
+
 func (a *approvalsNotifier) GetPayload(toIdentity *models.Identity) models.NotificationRequest {
 
+	log := getLogger(a.workflowTask)
+
 	elevationReq := a.elevationReq
 	var notificationPayload models.NotificationRequest
 
@@ -85,7 +146,7 @@ func (a *approvalsNotifier) GetPayload(toIdentity *models.Identity) models.Notif
 		}
 		err := common.ConvertInterfaceToInterface(slackReq, &notificationPayload)
 		if err != nil {
-			logrus.WithError(err).Error("Failed to convert slack request")
+			log.WithError(err).Error("Failed to convert slack request")
 			return models.NotificationRequest{}
 		}
 	} else if strings.HasPrefix(a.GetProviderName(), emailProvider.EmailProviderName) {
@@ -100,13 +161,27 @@ func (a *approvalsNotifier) GetPayload(toIdentity *models.Identity) models.Notif
 		}
 		err := common.ConvertInterfaceToInterface(emailReq, &notificationPayload)
 		if err != nil {
-			logrus.WithError(err).Error("Failed to convert email request")
+			log.WithError(err).Error("Failed to convert email request")
 			return models.NotificationRequest{}
 		}
 	} else {
-		logrus.WithField("provider", a.GetProviderName()).Error("Unsupported provider type")
+		log.WithField("provider", a.GetProviderName()).Error("Unsupported provider type")
 		return models.NotificationRequest{}
 	}
 
 	return notificationPayload
 }
+
+// getQuorumStatus evaluates the quorum against the approvals received so far
+func (a *approvalsNotifier) getQuorumStatus() *models.ApprovalQuorumStatus {
+	approvals, _ := models.GetContextAs[map[string]any](a.workflowTask, "approvals")
+	return a.req.Quorum.Evaluate(getApprovedRequirements(approvals))
+}
+
+// getQuorumActionMessage describes the requirements still to be met
+func getQuorumActionMessage(status *models.ApprovalQuorumStatus) string {
+	if status.Satisfied {
+		return "Sufficient approvals have been received. Please review the request and choose an action."
+	}
+	return fmt.Sprintf("Approvals are still needed from: %s. Please review the request and choose an action.", status.String())
+}
@@ -0,0 +1,75 @@
+package thand
+
+import (
+	"slices"
+	"time"
+
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	"github.com/thand-io/agent/internal/models"
+	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
+	"go.temporal.io/sdk/workflow"
+)
+
+// routeApprovers decides which approvers to notify. The schedules depend
+// on the current time so under Temporal they are evaluated in an activity.
+func (t *thandTask) routeApprovers(
+	workflowTask *models.WorkflowTask,
+	taskName string,
+	mode string,
+	recipients []string,
+) (*models.ApprovalRouting, error) {
+
+	request := &thandFunction.RouteRequest{
+		Mode:       mode,
+		Recipients: recipients,
+	}
+
+	if !workflowTask.HasTemporalContext() {
+		return thandFunction.RouteApprovers(t.config, request, time.Now())
+	}
+
+	serviceClient := t.config.GetServices()
+
+	aoctx := workflow.WithActivityOptions(workflowTask.GetTemporalContext(), workflow.ActivityOptions{
+		TaskQueue:           serviceClient.GetTemporal().GetTaskQueue(),
+		StartToCloseTimeout: time.Minute,
+	})
+
+	var routing models.ApprovalRouting
+
+	err := workflow.ExecuteActivity(
+		aoctx,
+		thandFunction.ThandRouteFunction,
+		workflowTask,
+		taskName,
+		model.CallFunction{
+			Call: thandFunction.ThandRouteFunction,
+			With: map[string]any{
+				"mode":       request.Mode,
+				"recipients": request.Recipients,
+			},
+		},
+		nil,
+	).Get(aoctx, &routing)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &routing, nil
+}
+
+// filterRoutedRecipients returns the recipients that were routed to, in
+// routing order so approvers on duty come first
+func filterRoutedRecipients(recipients []string, routed []string) []string {
+
+	filtered := []string{}
+
+	for _, recipient := range routed {
+		if slices.Contains(recipients, recipient) {
+			filtered = append(filtered, recipient)
+		}
+	}
+
+	return filtered
+}
@@ -24,6 +24,9 @@ func (a *approvalsNotifier) createApprovalSlackBlocks() []slack.Block {
 	// Add the user message section
 	a.addUserMessageSection(&blocks, notifyReq)
 
+	// Add risk flags section, above the details so it isn't missed
+	a.addRiskFlagsSection(&blocks, elevateRequest)
+
 	// Add divider
 	blocks = append(blocks, slack.NewDividerBlock())
 
@@ -73,6 +76,31 @@ func (a *approvalsNotifier) addUserMessageSection(blocks *[]slack.Block, approva
 	}
 }
 
+// addRiskFlagsSection adds the raised risk flags if there are any
+func (a *approvalsNotifier) addRiskFlagsSection(blocks *[]slack.Block, elevateRequest *models.ElevateRequestInternal) {
+	if !elevateRequest.RiskFlags.IsRaised() {
+		return
+	}
+
+	var riskText strings.Builder
+	riskText.WriteString(":warning: *Unusual Request:*\n")
+
+	for _, description := range elevateRequest.RiskFlags.GetDescriptions() {
+		riskText.WriteString(fmt.Sprintf("- %s\n", description))
+	}
+
+	*blocks = append(*blocks, slack.NewSectionBlock(
+		slack.NewTextBlockObject(
+			slack.MarkdownType,
+			riskText.String(),
+			false,
+			false,
+		),
+		nil,
+		nil,
+	))
+}
+
 // addRequestDetailsSection builds and adds the request details section
 func (a *approvalsNotifier) addRequestDetailsSection(blocks *[]slack.Block, elevateRequest *models.ElevateRequestInternal) {
 	var requestDetailsText strings.Builder
@@ -94,7 +122,7 @@ func (a *approvalsNotifier) addRequestDetailsSection(blocks *[]slack.Block, elev
 	}
 
 	if len(elevateRequest.Duration) > 0 {
-		requestDetailsText.WriteString(fmt.Sprintf("- *Duration:* %s\n", elevateRequest.Duration))
+		requestDetailsText.WriteString(fmt.Sprintf("- *Duration:* %s\n", elevateRequest.GetFormattedDuration()))
 	}
 
 	*blocks = append(*blocks, slack.NewSectionBlock(
@@ -296,23 +324,34 @@ func (a *approvalsNotifier) addActionSection(
 	workflowTask *models.WorkflowTask,
 	approvalNotifier *ApprovalNotifier,
 ) {
-	if approvalNotifier.Approvals > 0 {
-		// Get current approvals from workflow context
-		workflowContext := workflowTask.GetContextAsMap()
-		approvals, ok := workflowContext["approvals"].([]any)
-		if !ok {
-			approvals = []any{}
+	if approvalNotifier.HasQuorum() {
+
+		status := a.getQuorumStatus()
+
+		*blocks = append(*blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(
+				slack.MarkdownType,
+				fmt.Sprintf("*Action Required:*\n*%s*", getQuorumActionMessage(status)),
+				false,
+				false,
+			),
+			nil,
+			nil,
+		))
+
+		if !status.Satisfied {
+			*blocks = append(*blocks, a.createActionButtons(workflowTask, approvalNotifier))
 		}
 
+	} else if approvalNotifier.Approvals > 0 {
+		// Get current approvals from workflow context
+		approvals, _ := models.GetContextAs[[]map[string]any](workflowTask, "approvals")
+
 		// Count existing approved approvals
 		approvedCount := 0
 		for _, approval := range approvals {
-			if approvalMap, ok := approval.(map[string]any); ok {
-				if approved, exists := approvalMap["approved"]; exists {
-					if approvedBool, ok := approved.(bool); ok && approvedBool {
-						approvedCount++
-					}
-				}
+			if approved, ok := approval["approved"].(bool); ok && approved {
+				approvedCount++
 			}
 		}
 
@@ -342,56 +381,7 @@ func (a *approvalsNotifier) addActionSection(
 		))
 
 		if remainingApprovals > 0 {
-
-			*blocks = append(*blocks, slack.NewActionBlock(
-				"",
-				slack.NewButtonBlockElement(
-					fmt.Sprintf(
-						"%s-%s-%s",
-						a.workflowTask.WorkflowID,
-						a.workflowTask.GetTaskName(),
-						"approve",
-					),
-					"Approve",
-					slack.NewTextBlockObject(
-						slack.PlainTextType,
-						"Approve",
-						false,
-						false,
-					),
-				).WithURL(a.createCallbackUrl(workflowTask, approvalNotifier, true)).WithStyle(slack.StylePrimary),
-				slack.NewButtonBlockElement(
-					fmt.Sprintf(
-						"%s-%s-%s",
-						a.workflowTask.WorkflowID,
-						a.workflowTask.GetTaskName(),
-						"deny",
-					),
-					"Deny",
-					slack.NewTextBlockObject(
-						slack.PlainTextType,
-						"Deny",
-						false,
-						false,
-					),
-				).WithURL(a.createCallbackUrl(workflowTask, approvalNotifier, false)).WithStyle(slack.StyleDanger),
-				slack.NewButtonBlockElement(
-					fmt.Sprintf(
-						"%s-%s-%s",
-						a.workflowTask.WorkflowID,
-						a.workflowTask.GetTaskName(),
-						"view_request",
-					),
-					"View Request",
-					slack.NewTextBlockObject(
-						slack.PlainTextType,
-						"View Request",
-						false,
-						false,
-					),
-				).WithURL(a.createViewRequestUrl(workflowTask)),
-			))
-
+			*blocks = append(*blocks, a.createActionButtons(workflowTask, approvalNotifier))
 		}
 	} else {
 		*blocks = append(*blocks, slack.NewSectionBlock(
@@ -407,6 +397,61 @@ func (a *approvalsNotifier) addActionSection(
 	}
 }
 
+// createActionButtons creates the approve, deny and view request buttons
+func (a *approvalsNotifier) createActionButtons(
+	workflowTask *models.WorkflowTask,
+	approvalNotifier *ApprovalNotifier,
+) *slack.ActionBlock {
+	return slack.NewActionBlock(
+		"",
+		slack.NewButtonBlockElement(
+			fmt.Sprintf(
+				"%s-%s-%s",
+				a.workflowTask.WorkflowID,
+				a.workflowTask.GetTaskName(),
+				"approve",
+			),
+			"Approve",
+			slack.NewTextBlockObject(
+				slack.PlainTextType,
+				"Approve",
+				false,
+				false,
+			),
+		).WithURL(a.createCallbackUrl(workflowTask, approvalNotifier, true)).WithStyle(slack.StylePrimary),
+		slack.NewButtonBlockElement(
+			fmt.Sprintf(
+				"%s-%s-%s",
+				a.workflowTask.WorkflowID,
+				a.workflowTask.GetTaskName(),
+				"deny",
+			),
+			"Deny",
+			slack.NewTextBlockObject(
+				slack.PlainTextType,
+				"Deny",
+				false,
+				false,
+			),
+		).WithURL(a.createCallbackUrl(workflowTask, approvalNotifier, false)).WithStyle(slack.StyleDanger),
+		slack.NewButtonBlockElement(
+			fmt.Sprintf(
+				"%s-%s-%s",
+				a.workflowTask.WorkflowID,
+				a.workflowTask.GetTaskName(),
+				"view_request",
+			),
+			"View Request",
+			slack.NewTextBlockObject(
+				slack.PlainTextType,
+				"View Request",
+				false,
+				false,
+			),
+		).WithURL(a.createViewRequestUrl(workflowTask)),
+	)
+}
+
 func (a *approvalsNotifier) createViewRequestUrl(workflowTask *models.WorkflowTask) string {
 	return fmt.Sprintf("%s/execution/%s", a.config.GetLoginServerUrl(), workflowTask.WorkflowID)
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/thand-io/agent/internal/models"
+	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
 )
 
 // TestEvaluateApprovalSwitch tests the approval switch logic with various scenarios
@@ -181,6 +182,7 @@ func TestEvaluateApprovalSwitch(t *testing.T) {
 				tt.taskName,
 				tt.approvals,
 				tt.requiredApprovals,
+				nil,
 				tt.approvedState,
 				tt.deniedState,
 			) // Assert no error occurred
@@ -217,6 +219,7 @@ func TestEvaluateApprovalSwitchProgression(t *testing.T) {
 		taskName,
 		approvals,
 		requiredApprovals,
+		nil,
 		approvedState,
 		deniedState,
 	)
@@ -237,6 +240,7 @@ func TestEvaluateApprovalSwitchProgression(t *testing.T) {
 		taskName,
 		approvals,
 		requiredApprovals,
+		nil,
 		approvedState,
 		deniedState,
 	)
@@ -257,6 +261,7 @@ func TestEvaluateApprovalSwitchProgression(t *testing.T) {
 		taskName,
 		approvals,
 		requiredApprovals,
+		nil,
 		approvedState,
 		deniedState,
 	)
@@ -293,6 +298,7 @@ func TestEvaluateApprovalSwitchDenialProgression(t *testing.T) {
 		taskName,
 		approvals,
 		requiredApprovals,
+		nil,
 		approvedState,
 		deniedState,
 	)
@@ -313,6 +319,7 @@ func TestEvaluateApprovalSwitchDenialProgression(t *testing.T) {
 		taskName,
 		approvals,
 		requiredApprovals,
+		nil,
 		approvedState,
 		deniedState,
 	)
@@ -333,6 +340,7 @@ func TestEvaluateApprovalSwitchDenialProgression(t *testing.T) {
 		taskName,
 		approvals,
 		requiredApprovals,
+		nil,
 		approvedState,
 		deniedState,
 	)
@@ -342,6 +350,138 @@ func TestEvaluateApprovalSwitchDenialProgression(t *testing.T) {
 	assert.Equal(t, deniedState, flowDirective.Value, "Should remain denied even after additional approvals")
 }
 
+// TestEvaluateApprovalSwitchQuorum tests approvals against a quorum of
+// approvers from distinct groups
+func TestEvaluateApprovalSwitchQuorum(t *testing.T) {
+
+	newApproval := func(approved bool, requirements ...string) map[string]any {
+		return map[string]any{
+			"approved":     approved,
+			"timestamp":    time.Now().UTC().Format(time.RFC3339),
+			"requirements": requirements,
+		}
+	}
+
+	newQuorum := func(distinct bool) *models.ApprovalQuorum {
+		return &models.ApprovalQuorum{
+			DistinctApprovers: distinct,
+			Requirements: []models.ApprovalRequirement{
+				{Name: "security", Groups: []string{"security"}},
+				{Name: "owners", Groups: []string{"payments"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		approvals     map[string]any
+		quorum        *models.ApprovalQuorum
+		expectedState string
+		expectedUnmet []string
+	}{
+		{
+			name: "one requirement met - should loop",
+			approvals: map[string]any{
+				"security@example.com": newApproval(true, "security"),
+			},
+			quorum:        newQuorum(false),
+			expectedState: "approval_task",
+			expectedUnmet: []string{"owners"},
+		},
+		{
+			name: "count alone isn't enough",
+			approvals: map[string]any{
+				"security1@example.com": newApproval(true, "security"),
+				"security2@example.com": newApproval(true, "security"),
+			},
+			quorum:        newQuorum(false),
+			expectedState: "approval_task",
+			expectedUnmet: []string{"owners"},
+		},
+		{
+			name: "every requirement met - should approve",
+			approvals: map[string]any{
+				"security@example.com": newApproval(true, "security"),
+				"owner@example.com":    newApproval(true, "owners"),
+			},
+			quorum:        newQuorum(false),
+			expectedState: "authorize",
+		},
+		{
+			name: "overlapping approver satisfies both",
+			approvals: map[string]any{
+				"both@example.com": newApproval(true, "security", "owners"),
+			},
+			quorum:        newQuorum(false),
+			expectedState: "authorize",
+		},
+		{
+			name: "overlapping approver satisfies one with distinct approvers",
+			approvals: map[string]any{
+				"both@example.com": newApproval(true, "security", "owners"),
+			},
+			quorum:        newQuorum(true),
+			expectedState: "approval_task",
+			expectedUnmet: []string{"owners"},
+		},
+		{
+			name: "overlapping approver with another distinct approver",
+			approvals: map[string]any{
+				"both@example.com":     newApproval(true, "security", "owners"),
+				"security@example.com": newApproval(true, "security"),
+			},
+			quorum:        newQuorum(true),
+			expectedState: "authorize",
+		},
+		{
+			name: "denial wins over a met quorum",
+			approvals: map[string]any{
+				"security@example.com": newApproval(true, "security"),
+				"owner@example.com":    newApproval(true, "owners"),
+				"other@example.com":    newApproval(false),
+			},
+			quorum:        newQuorum(false),
+			expectedState: "denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflowTask := &models.WorkflowTask{
+				WorkflowID:   "test-workflow",
+				WorkflowName: "Test Workflow",
+			}
+			workflowTask.SetContextKeyValue("approvals", tt.approvals)
+
+			task := &thandTask{}
+
+			flowDirective, err := task.evaluateApprovalSwitch(
+				workflowTask,
+				"approval_task",
+				tt.approvals,
+				1,
+				tt.quorum,
+				"authorize",
+				"denied",
+			)
+			require.NoError(t, err)
+			require.NotNil(t, flowDirective)
+			assert.Equal(t, tt.expectedState, flowDirective.Value)
+
+			// The progress is recorded so the unmet requirements can be shown
+			status, err := models.GetContextAs[models.ApprovalQuorumStatus](
+				workflowTask, models.VarsContextApprovalQuorum)
+			require.NoError(t, err)
+
+			var unmet []string
+			for _, requirement := range status.GetUnmet() {
+				unmet = append(unmet, requirement.Name)
+			}
+			assert.Equal(t, tt.expectedUnmet, unmet)
+		})
+	}
+}
+
 // TestSelfApprovalLogic tests the self-approval validation logic
 func TestSelfApprovalLogic(t *testing.T) {
 	tests := []struct {
@@ -736,3 +876,143 @@ func TestGetIdentityVariations(t *testing.T) {
 		})
 	}
 }
+
+// TestAddPolicyApprovers tests that approvers required by policy are added to each notifier
+func TestAddPolicyApprovers(t *testing.T) {
+	approvalsTask := &ApprovalsTask{
+		Approvals: 1,
+		Notifiers: map[string]thandFunction.NotifierRequest{
+			"slack": {Provider: "slack", To: []string{"#approvals"}},
+			"email": {Provider: "email", To: []string{"security@example.com"}},
+		},
+	}
+
+	addPolicyApprovers(approvalsTask, []string{"security@example.com", "cto@example.com"})
+
+	assert.Equal(t, []string{"#approvals", "security@example.com", "cto@example.com"}, approvalsTask.Notifiers["slack"].To)
+	assert.Equal(t, []string{"security@example.com", "cto@example.com"}, approvalsTask.Notifiers["email"].To)
+}
+
+// TestMergeApproval tests that approvals are deduped on the approver
+func TestMergeApproval(t *testing.T) {
+
+	newRecord := func(approver string, approved bool, at string) map[string]any {
+		return map[string]any{
+			"approved":        approved,
+			"timestamp":       at,
+			"idempotency_key": models.NewApprovalIdempotencyKey(approver, "test-workflow", approved),
+		}
+	}
+
+	t.Run("double submit is ignored", func(t *testing.T) {
+		approvals := map[string]any{}
+
+		change, recorded := mergeApproval(approvals, "user1@example.com",
+			newRecord("user1@example.com", true, "2026-01-02T03:04:05Z"))
+		require.True(t, recorded)
+		assert.Nil(t, change)
+
+		change, recorded = mergeApproval(approvals, "user1@example.com",
+			newRecord("user1@example.com", true, "2026-01-02T03:04:09Z"))
+		assert.False(t, recorded, "Retrying the same decision should be ignored")
+		assert.Nil(t, change)
+
+		require.Len(t, approvals, 1)
+		assert.Equal(t, "2026-01-02T03:04:05Z", approvals["user1@example.com"].(map[string]any)["timestamp"],
+			"The original decision should be kept")
+	})
+
+	t.Run("variations of an identity are one approver", func(t *testing.T) {
+		approvals := map[string]any{}
+
+		mergeApproval(approvals, "User1@Example.com",
+			newRecord("User1@Example.com", true, "2026-01-02T03:04:05Z"))
+
+		_, recorded := mergeApproval(approvals, "user1@example.com",
+			newRecord("user1@example.com", true, "2026-01-02T03:04:09Z"))
+		assert.False(t, recorded, "The default key should match regardless of case")
+
+		// A different key from the same approver replaces their entry
+		record := newRecord("user1@example.com", true, "2026-01-02T03:04:09Z")
+		record["idempotency_key"] = "incident-bot-retry"
+
+		change, recorded := mergeApproval(approvals, " user1@example.com", record)
+		require.True(t, recorded)
+		assert.Nil(t, change, "The same decision isn't a change")
+
+		assert.Equal(t, map[string]any{" user1@example.com": record}, approvals)
+	})
+
+	t.Run("approve then deny by the same approver", func(t *testing.T) {
+		approvals := map[string]any{
+			"user2@example.com": newRecord("user2@example.com", true, "2026-01-02T03:04:00Z"),
+		}
+
+		mergeApproval(approvals, "user1@example.com",
+			newRecord("user1@example.com", true, "2026-01-02T03:04:05Z"))
+
+		change, recorded := mergeApproval(approvals, "user1@example.com",
+			newRecord("user1@example.com", false, "2026-01-02T03:05:00Z"))
+		require.True(t, recorded)
+		require.NotNil(t, change)
+
+		assert.Equal(t, &models.ApprovalChange{
+			Approver:       "user1@example.com",
+			Previous:       true,
+			Approved:       false,
+			IdempotencyKey: models.NewApprovalIdempotencyKey("user1@example.com", "test-workflow", false),
+			Timestamp:      "2026-01-02T03:05:00Z",
+		}, change)
+
+		require.Len(t, approvals, 2)
+		assert.Equal(t, false, approvals["user1@example.com"].(map[string]any)["approved"],
+			"The latest decision should win")
+	})
+}
+
+// TestEvaluateApprovalSwitchDeduped tests that the switch only counts each
+// approver once
+func TestEvaluateApprovalSwitchDeduped(t *testing.T) {
+	workflowTask := &models.WorkflowTask{
+		WorkflowID:   "test-workflow",
+		WorkflowName: "Test Workflow",
+	}
+
+	task := &thandTask{}
+	approvals := map[string]any{}
+
+	evaluate := func() string {
+		workflowTask.SetContextKeyValue("approvals", approvals)
+
+		flowDirective, err := task.evaluateApprovalSwitch(
+			workflowTask, "approval_task", approvals, 2, nil, "authorize", "denied")
+
+		require.NoError(t, err)
+		return flowDirective.Value
+	}
+
+	for _, approver := range []string{"user1@example.com", "USER1@example.com"} {
+		mergeApproval(approvals, approver, map[string]any{
+			"approved":        true,
+			"timestamp":       time.Now().UTC().Format(time.RFC3339),
+			"idempotency_key": models.NewApprovalIdempotencyKey(approver, workflowTask.WorkflowID, true),
+		})
+	}
+
+	assert.Equal(t, "approval_task", evaluate(), "A double submit should only count once")
+
+	change, recorded := mergeApproval(approvals, "user1@example.com", map[string]any{
+		"approved":        false,
+		"timestamp":       time.Now().UTC().Format(time.RFC3339),
+		"idempotency_key": models.NewApprovalIdempotencyKey("user1@example.com", workflowTask.WorkflowID, false),
+	})
+	require.True(t, recorded)
+	require.NoError(t, recordApprovalChange(workflowTask, change))
+
+	assert.Equal(t, "denied", evaluate(), "Changing to a denial should deny the request")
+
+	changes, err := models.GetContextAs[[]any](workflowTask, models.VarsContextApprovalChanges)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "user1@example.com", changes[0].(map[string]any)["approver"])
+}
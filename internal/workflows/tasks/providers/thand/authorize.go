@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"maps"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 
@@ -15,15 +16,27 @@ import (
 
 	"github.com/serverlessworkflow/sdk-go/v3/model"
 	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/metrics"
+	"github.com/thand-io/agent/internal/siem"
 	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
 	taskModel "github.com/thand-io/agent/internal/workflows/tasks/model"
 )
 
 const ThandAuthorizeTask = "authorize"
 
+const (
+	defaultAuthorizeMaxRetries     = 4
+	defaultAuthorizeInitialBackoff = 3 * time.Second
+)
+
 type AuthorizeTask struct {
 	Revocation string                                   `json:"revocation"` // This is the state to request the revocation
 	Notifiers  map[string]thandFunction.NotifierRequest `json:"notifiers"`  // Notifier configurations for sending authorization notifications
+
+	// Failed grants are retried with a backoff that doubles each retry
+	MaxRetries            int    `json:"max_retries,omitempty"`
+	InitialBackoffSeconds int    `json:"initial_backoff_seconds,omitempty"`
+	FailureState          string `json:"failure_state,omitempty"` // State to transition to when every grant failed
 }
 
 func (t *AuthorizeTask) HasRevocation() bool {
@@ -34,6 +47,33 @@ func (t *AuthorizeTask) HasNotifiers() bool {
 	return len(t.Notifiers) > 0
 }
 
+func (t *AuthorizeTask) HasFailureState() bool {
+	return len(t.FailureState) > 0
+}
+
+func (t *AuthorizeTask) GetMaxRetries() int {
+	if t.MaxRetries <= 0 {
+		return defaultAuthorizeMaxRetries
+	}
+	return t.MaxRetries
+}
+
+func (t *AuthorizeTask) GetInitialBackoff() time.Duration {
+	if t.InitialBackoffSeconds <= 0 {
+		return defaultAuthorizeInitialBackoff
+	}
+	return time.Duration(t.InitialBackoffSeconds) * time.Second
+}
+
+// GetRetryPolicy returns the retry policy for the grant activities
+func (t *AuthorizeTask) GetRetryPolicy() *temporal.RetryPolicy {
+	return &temporal.RetryPolicy{
+		InitialInterval:    t.GetInitialBackoff(),
+		BackoffCoefficient: 2.0,
+		MaximumAttempts:    int32(t.GetMaxRetries() + 1),
+	}
+}
+
 func (t *thandTask) executeAuthorizeTask(
 	workflowTask *models.WorkflowTask,
 	taskName string,
@@ -69,6 +109,7 @@ func (t *thandTask) buildBasicModelOutput(elevateRequest *models.ElevateRequestI
 
 // authResult holds the result of an authorization operation
 type authResult struct {
+	ProviderName string
 	Identity     string
 	AuthRequest  *models.AuthorizeRoleRequest
 	AuthResponse *models.AuthorizeRoleResponse
@@ -86,6 +127,7 @@ type authTask struct {
 // temporalAuthResult represents the result of an authorization operation for temporal communication
 type temporalAuthResult struct {
 	Index        int
+	ProviderName string
 	Identity     string
 	AuthRequest  *models.AuthorizeRoleRequest
 	AuthResponse *models.AuthorizeRoleResponse
@@ -155,23 +197,18 @@ func (t *thandTask) executeAuthorization(
 			identityObj := t.resolveIdentity(identityId)
 
 			if identityObj == nil {
-				logrus.Warnf("failed to resolve identity: %s", identityId)
+				getLogger(workflowTask).WithField("identity", identityId).Warn("Failed to resolve identity")
 				continue
 			}
 
 			if identityObj.GetUser() == nil {
-				logrus.Warnf("resolved identity has no user: %s", identityId)
+				getLogger(workflowTask).WithField("identity", identityId).Warn("Resolved identity has no user")
 				continue
 			}
 
 			identityObj.ID = identityId
-			authReq := models.AuthorizeRoleRequest{
-				RoleRequest: &models.RoleRequest{
-					User:     identityObj.GetUser(),
-					Role:     elevateRequest.Role,
-					Duration: &duration,
-				},
-			}
+			authReq := newAuthorizeRoleRequest(
+				workflowTask, elevateRequest, identityObj.GetUser(), duration)
 
 			thandAuthReq := thandFunction.ThandAuthorizeRequest{
 				AuthorizeRoleRequest: authReq,
@@ -199,9 +236,9 @@ func (t *thandTask) executeAuthorization(
 	var authResults []authResult
 
 	if workflowTask.HasTemporalContext() {
-		authResults, err = t.executeTemporalParallel(workflowTask, taskName, call, authTasks)
+		authResults, err = t.executeTemporalParallel(workflowTask, taskName, call, &authorizeCallTask, authTasks)
 	} else {
-		authResults, err = t.executeGoParallel(workflowTask, authTasks)
+		authResults, err = t.executeGoParallel(workflowTask, &authorizeCallTask, authTasks)
 	}
 
 	if err != nil {
@@ -212,35 +249,67 @@ func (t *thandTask) executeAuthorization(
 	}
 
 	// Process results
-	requests := make(map[string]*models.AuthorizeRoleRequest)
-	authorizations := make(map[string]*models.AuthorizeRoleResponse)
 	returnedErrors := []error{}
 
+	// Track the outcome for each provider so partial failures can be
+	// reported and only the successful grants are revoked later. The same
+	// identity is granted on every provider so results are keyed by both.
+	providerRequests := getProviderRequests(authTasks)
+	providerAuthorizations := make(map[string]map[string]*models.AuthorizeRoleResponse)
+	providerFailures := make(map[string]map[string]string)
+
 	if len(authResults) == 0 {
 		return nil, fmt.Errorf("no authorization results returned")
 	}
 
 	for _, result := range authResults {
 		if result.Error != nil {
-			log.WithError(result.Error).WithField("identity", result.Identity).Error("Authorization failed")
+			log.WithError(result.Error).WithFields(models.Fields{
+				"identity": result.Identity,
+				"provider": result.ProviderName,
+			}).Error("Authorization failed")
 
 			foundError := unwrapTemporalError(result.Error)
 
+			if _, ok := providerFailures[result.ProviderName]; !ok {
+				providerFailures[result.ProviderName] = make(map[string]string)
+			}
+			providerFailures[result.ProviderName][result.Identity] = foundError.Error()
+
 			returnedErrors = append(returnedErrors, fmt.Errorf(
-				"authorization error, failed to authorize: %s - returned with the error: %s", result.Identity, foundError.Error()))
+				"authorization error, failed to authorize: %s on %s - returned with the error: %s",
+				result.Identity, result.ProviderName, foundError.Error()))
 			continue
 		}
-		authorizations[result.Identity] = result.AuthResponse
-	}
 
-	for _, req := range authTasks {
-		requests[req.Identity] = &req.AuthRequest
+		if _, ok := providerAuthorizations[result.ProviderName]; !ok {
+			providerAuthorizations[result.ProviderName] = make(map[string]*models.AuthorizeRoleResponse)
+		}
+		providerAuthorizations[result.ProviderName][result.Identity] = result.AuthResponse
 	}
 
-	if len(returnedErrors) > 0 && len(authorizations) == 0 {
+	modelOutput["providers"] = buildProviderOutcomes(elevateRequest.Providers, providerAuthorizations, providerFailures)
+
+	// Read only providers only simulate the grant, record which so the
+	// outcome isn't mistaken for real access
+	simulatedProviders := getSimulatedProviders(providerAuthorizations)
+	modelOutput["simulated"] = len(simulatedProviders) > 0
+
+	if len(returnedErrors) > 0 && len(providerAuthorizations) == 0 {
+
+		if authorizeCallTask.HasFailureState() {
+
+			log.WithError(errors.Join(returnedErrors...)).WithFields(models.Fields{
+				"failure_state": authorizeCallTask.FailureState,
+			}).Error("Every authorization failed after retrying, moving to the failure state")
+
+			workflowTask.SetContextKeyValue(models.VarsContextAuthorizationFailures, providerFailures)
+
+			return &model.FlowDirective{Value: authorizeCallTask.FailureState}, nil
+		}
 
 		return nil, temporal.NewApplicationErrorWithCause(
-			fmt.Sprintf("One or more authorizations failed: %d errors, %d authorizations", len(returnedErrors), len(authorizations)),
+			fmt.Sprintf("One or more authorizations failed: %d errors, %d authorizations", len(returnedErrors), len(authResults)-len(returnedErrors)),
 			"AuthorizationError",
 			errors.Join(returnedErrors...),
 		)
@@ -253,7 +322,15 @@ func (t *thandTask) executeAuthorization(
 	}
 
 	workflowTask.SetContextKeyValue(models.VarsContextApproved, true)
-	workflowTask.SetContextKeyValue("authorizations", authorizations)
+	workflowTask.SetContextKeyValue(models.VarsContextProviderAuthorizations, providerAuthorizations)
+	workflowTask.SetContextKeyValue(models.VarsContextAuthorizationFailures, providerFailures)
+	workflowTask.SetContextKeyValue(models.VarsContextSimulatedProviders, simulatedProviders)
+
+	if workflowTask.HasTemporalContext() {
+		if err := recordGrant(workflowTask, authorizedAt, revocationDate, providerAuthorizations, len(simulatedProviders) > 0); err != nil {
+			log.WithError(err).Warn("Failed to record grant, continuing anyway")
+		}
+	}
 
 	if authorizeCallTask.HasNotifiers() {
 
@@ -262,8 +339,9 @@ func (t *thandTask) executeAuthorization(
 			taskName,
 			&authorizeCallTask,
 			elevateRequest,
-			requests,
-			authorizations,
+			providerRequests,
+			providerAuthorizations,
+			simulatedProviders,
 		)
 
 		if err != nil {
@@ -275,20 +353,191 @@ func (t *thandTask) executeAuthorization(
 	return modelOutput, nil
 }
 
+// newAuthorizeRoleRequest builds the request to grant the role to the user.
+// The authorization hook is passed the same request whether the grant is
+// made by this task or by the thand.authorize function.
+func newAuthorizeRoleRequest(
+	workflowTask *models.WorkflowTask,
+	elevateRequest *models.ElevateRequestInternal,
+	user *models.User,
+	duration time.Duration,
+) models.AuthorizeRoleRequest {
+	return models.AuthorizeRoleRequest{
+		RoleRequest: &models.RoleRequest{
+			User:      user,
+			Role:      elevateRequest.Role,
+			Duration:  &duration,
+			Audit:     thandFunction.NewAuditContext(workflowTask, elevateRequest),
+			PublicKey: elevateRequest.PublicKey,
+		},
+	}
+}
+
+// recordGrant stores the grant in the workflow memo so the active grants
+// inventory can list it from visibility
+func recordGrant(
+	workflowTask *models.WorkflowTask,
+	authorizedAt time.Time,
+	revocationAt time.Time,
+	providerAuthorizations map[string]map[string]*models.AuthorizeRoleResponse,
+	simulated bool,
+) error {
+	return workflow.UpsertMemo(workflowTask.GetTemporalContext(), map[string]any{
+		models.TemporalMemoGrant: models.GrantMemo{
+			AuthorizedAt: authorizedAt,
+			RevocationAt: revocationAt,
+			Approvers:    getApprovers(workflowTask),
+			Simulated:    simulated,
+			GrantRefs:    getGrantRefs(providerAuthorizations),
+		},
+	})
+}
+
+// getGrantRefs returns what the providers created for the grant, sorted so
+// the memo is the same on replay
+func getGrantRefs(providerAuthorizations map[string]map[string]*models.AuthorizeRoleResponse) []*models.GrantRef {
+
+	grantRefs := []*models.GrantRef{}
+
+	for _, providerName := range slices.Sorted(maps.Keys(providerAuthorizations)) {
+		authorizations := providerAuthorizations[providerName]
+		for _, identity := range slices.Sorted(maps.Keys(authorizations)) {
+			if grant := authorizations[identity].GetGrantRef(); grant != nil && !grant.IsSimulated() {
+				grantRefs = append(grantRefs, grant)
+			}
+		}
+	}
+
+	return grantRefs
+}
+
+// getApprovers returns the identities that approved the request
+func getApprovers(workflowTask *models.WorkflowTask) []string {
+
+	approvals, err := models.GetContextAs[map[string]any](workflowTask, "approvals")
+
+	if err != nil {
+		return nil
+	}
+
+	approvers := []string{}
+
+	for identity, approval := range approvals {
+		if approvalData, ok := approval.(map[string]any); ok {
+			if approved, ok := approvalData["approved"].(bool); ok && approved {
+				approvers = append(approvers, identity)
+			}
+		}
+	}
+
+	slices.Sort(approvers)
+
+	return approvers
+}
+
+// buildProviderOutcomes summarises the grant status for each requested provider
+// e.g. {"aws-prod": {"status": "authorized"}, "gcp-prod": {"status": "partial", ...}}
+func buildProviderOutcomes(
+	providers []string,
+	authorizations map[string]map[string]*models.AuthorizeRoleResponse,
+	failures map[string]map[string]string,
+) map[string]any {
+
+	outcomes := make(map[string]any)
+
+	for _, providerName := range providers {
+
+		succeeded := []string{}
+		for identity := range authorizations[providerName] {
+			succeeded = append(succeeded, identity)
+		}
+		slices.Sort(succeeded)
+
+		failed := failures[providerName]
+
+		status := "authorized"
+		if len(failed) > 0 && len(succeeded) == 0 {
+			status = "failed"
+		} else if len(failed) > 0 {
+			status = "partial"
+		}
+
+		outcome := map[string]any{
+			"status":     status,
+			"identities": succeeded,
+		}
+
+		if len(failed) > 0 {
+			outcome["errors"] = failed
+		}
+
+		for _, response := range authorizations[providerName] {
+			if response.IsSimulated() {
+				outcome["simulated"] = true
+				break
+			}
+		}
+
+		outcomes[providerName] = outcome
+	}
+
+	return outcomes
+}
+
+// getProviderRequests groups the authorization requests by provider and
+// then identity
+func getProviderRequests(authTasks []authTask) map[string]map[string]*models.AuthorizeRoleRequest {
+
+	requests := make(map[string]map[string]*models.AuthorizeRoleRequest)
+
+	for _, task := range authTasks {
+		if _, ok := requests[task.ProviderName]; !ok {
+			requests[task.ProviderName] = make(map[string]*models.AuthorizeRoleRequest)
+		}
+		requests[task.ProviderName][task.Identity] = &task.AuthRequest
+	}
+
+	return requests
+}
+
+// getSimulatedProviders returns the providers that only simulated their
+// grants as they are read only
+func getSimulatedProviders(authorizations map[string]map[string]*models.AuthorizeRoleResponse) []string {
+
+	simulated := []string{}
+
+	for providerName, responses := range authorizations {
+		for _, response := range responses {
+			if response.IsSimulated() {
+				simulated = append(simulated, providerName)
+				break
+			}
+		}
+	}
+
+	slices.Sort(simulated)
+
+	return simulated
+}
+
 // executeTemporalParallel executes authorization tasks in parallel using Temporal
 func (t *thandTask) executeTemporalParallel(
 	workflowTask *models.WorkflowTask,
 	taskName string,
 	call *taskModel.ThandTask,
+	authorizeCallTask *AuthorizeTask,
 	authTasks []authTask,
 ) ([]authResult, error) {
 
 	temporalContext := workflowTask.GetTemporalContext()
 	serviceClient := t.config.GetServices()
 
+	// Each provider grant is its own activity so they retry independently
+	// and one failing provider doesn't hold up the others
 	ao := workflow.ActivityOptions{
 		TaskQueue:           serviceClient.GetTemporal().GetTaskQueue(),
 		StartToCloseTimeout: time.Minute * 5,
+		RetryPolicy:         authorizeCallTask.GetRetryPolicy(),
 	}
 	aoctx := workflow.WithActivityOptions(temporalContext, ao)
 
@@ -302,9 +551,14 @@ func (t *thandTask) executeTemporalParallel(
 		authTask := task
 
 		workflow.Go(temporalContext, func(ctx workflow.Context) {
+			// Grant on the agents polling the provider's task queue, e.g.
+			// its region, while the workflow stays on the agent's own
+			taskCtx := workflow.WithTaskQueue(aoctx,
+				t.config.GetProviderTaskQueue(authTask.ProviderName))
+
 			var authOut models.AuthorizeRoleResponse
 			err := workflow.ExecuteActivity(
-				aoctx,
+				taskCtx,
 				// TODO(hugh): Replace with direct call to AuthorizeActivity
 				thandFunction.ThandAuthorizeFunction,
 				workflowTask,
@@ -319,6 +573,7 @@ func (t *thandTask) executeTemporalParallel(
 			// Send result through channel
 			resultCh.Send(ctx, temporalAuthResult{
 				Index:        taskIndex,
+				ProviderName: authTask.ProviderName,
 				Identity:     authTask.Identity,
 				AuthRequest:  &authTask.AuthRequest,
 				AuthResponse: &authOut,
@@ -332,6 +587,7 @@ func (t *thandTask) executeTemporalParallel(
 		var result temporalAuthResult
 		resultCh.Receive(temporalContext, &result)
 		results[result.Index] = authResult{
+			ProviderName: result.ProviderName,
 			Identity:     result.Identity,
 			AuthRequest:  result.AuthRequest,
 			AuthResponse: result.AuthResponse,
@@ -345,6 +601,7 @@ func (t *thandTask) executeTemporalParallel(
 // executeGoParallel executes authorization tasks in parallel using Go routines and WaitGroup
 func (t *thandTask) executeGoParallel(
 	workflowTask *models.WorkflowTask,
+	authorizeCallTask *AuthorizeTask,
 	authTasks []authTask,
 ) ([]authResult, error) {
 
@@ -359,6 +616,7 @@ func (t *thandTask) executeGoParallel(
 			providerCall, err := t.config.GetProviderByName(authTask.ProviderName)
 			if err != nil {
 				results[index] = authResult{
+					ProviderName: authTask.ProviderName,
 					Identity:     authTask.Identity,
 					AuthRequest:  &authTask.AuthRequest,
 					AuthResponse: nil,
@@ -367,11 +625,23 @@ func (t *thandTask) executeGoParallel(
 				return
 			}
 
-			authOut, err := providerCall.GetClient().AuthorizeRole(
-				workflowTask.GetContext(), &authTask.AuthRequest,
-			)
+			// The hook can deny the grant before the provider is called
+			if err := t.config.RunAuthorizationHook(
+				workflowTask.GetContext(), authTask.ProviderName, &authTask.AuthRequest); err != nil {
+				results[index] = authResult{
+					ProviderName: authTask.ProviderName,
+					Identity:     authTask.Identity,
+					AuthRequest:  &authTask.AuthRequest,
+					AuthResponse: nil,
+					Error:        err,
+				}
+				return
+			}
+
+			authOut, err := authorizeWithRetry(workflowTask, providerCall, authorizeCallTask, authTask)
 
 			results[index] = authResult{
+				ProviderName: authTask.ProviderName,
 				Identity:     authTask.Identity,
 				AuthRequest:  &authTask.AuthRequest,
 				AuthResponse: authOut,
@@ -385,11 +655,67 @@ func (t *thandTask) executeGoParallel(
 	return results, nil
 }
 
+// authorizeWithRetry grants the role, retrying failures with the same
+// backoff as the Temporal retry policy
+func authorizeWithRetry(
+	workflowTask *models.WorkflowTask,
+	providerCall *models.Provider,
+	authorizeCallTask *AuthorizeTask,
+	authTask authTask,
+) (*models.AuthorizeRoleResponse, error) {
+
+	rbac, err := providerCall.GetRBAC()
+
+	if err != nil {
+		return nil, err
+	}
+
+	maxRetries := authorizeCallTask.GetMaxRetries()
+	backoff := authorizeCallTask.GetInitialBackoff()
+
+	for retry := 0; ; retry++ {
+
+		authOut, err := rbac.AuthorizeRole(
+			workflowTask.GetContext(), &authTask.AuthRequest,
+		)
+
+		metrics.RecordRoleGrant(authTask.ProviderName, err == nil)
+
+		if err == nil || retry >= maxRetries {
+			siem.Record(siem.NewEvent(siem.EventRoleGranted, workflowTask).
+				WithProvider(authTask.ProviderName, authTask.Identity).
+				WithError(err))
+			return authOut, err
+		}
+
+		getLogger(workflowTask).WithError(err).WithFields(models.Fields{
+			"provider": authTask.ProviderName,
+			"identity": authTask.Identity,
+			"retry":    retry + 1,
+			"retries":  maxRetries,
+			"backoff":  backoff,
+		}).Warn("Authorization failed, retrying")
+
+		select {
+		case <-workflowTask.GetContext().Done():
+			return nil, workflowTask.GetContext().Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
 // validateRoleAndBuildOutput validates the role and builds the initial model output
 func (t *thandTask) validateRoleAndBuildOutput(
 	providerCall *models.Provider,
 	elevateRequest models.ElevateRequestInternal,
 ) (map[string]any, error) {
+	// Roles that deny by default can only grant the actions they allow
+	if err := t.config.CheckPermissions(elevateRequest.User, elevateRequest.Role); err != nil {
+		return nil, err
+	}
+
 	modelOutput := map[string]any{}
 
 	validateOut, err := models.ValidateRole(providerCall.GetClient(), elevateRequest)
@@ -474,7 +800,7 @@ func (t *thandTask) scheduleRevocation(
 					// Make call to revoke the user
 					callingUrl := t.config.GetResumeCallbackUrl(newTask)
 
-					logrus.WithFields(logrus.Fields{
+					getLogger(workflowTask).WithFields(logrus.Fields{
 						"task": newTask.GetTaskName(),
 						"url":  callingUrl,
 					}).Info("Executing scheduled revocation")
@@ -530,8 +856,9 @@ func (t *thandTask) makeAuthorizationNotifications(
 	taskName string,
 	authorizeTask *AuthorizeTask,
 	elevateRequest *models.ElevateRequestInternal,
-	authRequests map[string]*models.AuthorizeRoleRequest,
-	authorizations map[string]*models.AuthorizeRoleResponse,
+	authRequests map[string]map[string]*models.AuthorizeRoleRequest,
+	authorizations map[string]map[string]*models.AuthorizeRoleResponse,
+	simulatedProviders []string,
 ) error {
 
 	log := workflowTask.GetLogger()
@@ -550,6 +877,7 @@ func (t *thandTask) makeAuthorizationNotifications(
 			providerKey,
 			authRequests,
 			authorizations,
+			simulatedProviders,
 		)
 
 		// Get recipients for this notifier
@@ -603,7 +931,7 @@ func (t *thandTask) makeAuthorizationNotifications(
 	}
 
 	// Process results using shared helper
-	if err := processNotificationResults(notifyResults, "Authorization notification"); err != nil {
+	if err := processNotificationResults(workflowTask, notifyResults, "Authorization notification"); err != nil {
 
 		log.WithError(err).WithFields(models.Fields{
 			"taskName": taskName,
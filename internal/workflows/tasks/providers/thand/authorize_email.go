@@ -4,12 +4,12 @@ import (
 	"context"
 	"fmt"
 	"strings"
-
-	"github.com/sirupsen/logrus"
 )
 
 // createAuthorizeEmailBody creates the email body for authorization confirmation
 func (a *authorizerNotifier) createAuthorizeEmailBody() (string, string) {
+	log := getLogger(a.workflowTask)
+
 	elevationReq := a.elevationReq
 	notifyReq := a.req
 
@@ -29,15 +29,31 @@ func (a *authorizerNotifier) createAuthorizeEmailBody() (string, string) {
 	}
 
 	if len(elevationReq.Duration) > 0 {
-		plainText.WriteString(fmt.Sprintf("Duration: %s\n", elevationReq.Duration))
+		plainText.WriteString(fmt.Sprintf("Duration: %s\n", elevationReq.GetFormattedDuration()))
 	}
 
-	plainText.WriteString("\nYour access is now active. Please use it responsibly.")
+	simulated := a.isSimulated()
+
+	if simulated {
+		plainText.WriteString("\nThis grant was simulated as the provider is read only. No access was granted.")
+	} else if a.isPartiallySimulated() {
+		plainText.WriteString(fmt.Sprintf(
+			"\nThe grant on %s was simulated as the provider is read only. No access was granted there.",
+			strings.Join(a.simulated, ", ")))
+		plainText.WriteString("\nYour access to the other providers is now active. Please use it responsibly.")
+	} else {
+		plainText.WriteString("\nYour access is now active. Please use it responsibly.")
+	}
 
 	// Build data map for template
 	data := map[string]any{
 		"Providers": strings.Join(elevationReq.Providers, ", "),
-		"Duration":  elevationReq.Duration,
+		"Duration":  elevationReq.GetFormattedDuration(),
+		"Simulated": simulated,
+	}
+
+	if a.isPartiallySimulated() {
+		data["SimulatedProviders"] = strings.Join(a.simulated, ", ")
 	}
 
 	if len(notifyReq.Message) > 0 {
@@ -62,7 +78,7 @@ func (a *authorizerNotifier) createAuthorizeEmailBody() (string, string) {
 	// Render HTML email using template
 	html, err := RenderEmailWithTemplate("Access Request Approved", GetAuthorizeContentTemplate(), data)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to render authorization email")
+		log.WithError(err).Error("Failed to render authorization email")
 		return plainText.String(), ""
 	}
 
@@ -71,6 +87,8 @@ func (a *authorizerNotifier) createAuthorizeEmailBody() (string, string) {
 
 // addProviderAccessButtons adds provider access button data to the template
 func (a *authorizerNotifier) addProviderAccessButtons(ctx context.Context, data map[string]any) {
+	log := getLogger(a.workflowTask)
+
 	elevationReq := a.elevationReq
 
 	if len(elevationReq.Providers) == 0 || len(a.authRequests) == 0 || len(a.authResponses) == 0 {
@@ -80,7 +98,7 @@ func (a *authorizerNotifier) addProviderAccessButtons(ctx context.Context, data
 	identities := a.req.To
 
 	if len(identities) == 0 {
-		logrus.Error("No identity found for access URL generation")
+		log.Error("No identity found for access URL generation")
 		return
 	}
 
@@ -92,31 +110,37 @@ func (a *authorizerNotifier) addProviderAccessButtons(ctx context.Context, data
 	var providerButtons []ProviderButton
 
 	for _, providerName := range elevationReq.Providers {
+
+		// Simulated grants have nothing to access
+		if a.isProviderSimulated(providerName) {
+			continue
+		}
+
 		// Get provider configuration
 		provider, err := a.config.GetProviderByName(providerName)
 
 		if err != nil {
-			logrus.Errorf("Failed to get provider '%s' for access URL: %v", providerName, err)
+			log.Errorf("Failed to get provider '%s' for access URL: %v", providerName, err)
 			continue
 		}
 
-		providerClient := provider.GetClient()
+		rbac, err := provider.GetRBAC()
 
-		if providerClient == nil {
-			logrus.Errorf("Provider '%s' has no client defined for access URL", providerName)
+		if err != nil {
+			log.Errorf("Provider '%s' has no access URL: %v", providerName, err)
 			continue
 		}
 
 		for _, identity := range identities {
-			authRequest, foundReq := a.authRequests[identity]
-			authResponse, foundAuth := a.authResponses[identity]
+			authRequest, foundReq := a.authRequests[providerName][identity]
+			authResponse, foundAuth := a.authResponses[providerName][identity]
 
 			if !foundAuth || !foundReq {
-				logrus.Errorf("No authorization found for identity '%s' and provider '%s'", identity, providerName)
+				log.Errorf("No authorization found for identity '%s' and provider '%s'", identity, providerName)
 				continue
 			}
 
-			accessURL := providerClient.GetAuthorizedAccessUrl(
+			accessURL := rbac.GetAuthorizedAccessUrl(
 				ctx,
 				authRequest,
 				authResponse,
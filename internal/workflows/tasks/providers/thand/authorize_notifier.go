@@ -2,10 +2,10 @@ package thand
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/serverlessworkflow/sdk-go/v3/model"
-	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config"
@@ -22,8 +22,9 @@ type authorizerNotifier struct {
 	elevationReq  *models.ElevateRequestInternal
 	req           *thandFunction.NotifierRequest
 	providerKey   string
-	authRequests  map[string]*models.AuthorizeRoleRequest
-	authResponses map[string]*models.AuthorizeRoleResponse
+	authRequests  map[string]map[string]*models.AuthorizeRoleRequest
+	authResponses map[string]map[string]*models.AuthorizeRoleResponse
+	simulated     []string
 }
 
 // NewAuthorizerNotifier creates a new notifier for sending approval confirmation notifications
@@ -33,8 +34,9 @@ func NewAuthorizerNotifier(
 	elevationReq *models.ElevateRequestInternal,
 	req *thandFunction.NotifierRequest,
 	providerKey string,
-	requests map[string]*models.AuthorizeRoleRequest,
-	authorizations map[string]*models.AuthorizeRoleResponse,
+	requests map[string]map[string]*models.AuthorizeRoleRequest,
+	authorizations map[string]map[string]*models.AuthorizeRoleResponse,
+	simulatedProviders []string,
 ) NotifierImpl {
 	return &authorizerNotifier{
 		config:        config,
@@ -44,9 +46,27 @@ func NewAuthorizerNotifier(
 		providerKey:   providerKey,
 		authRequests:  requests,
 		authResponses: authorizations,
+		simulated:     simulatedProviders,
 	}
 }
 
+// isSimulated returns true when every provider only simulated its grant as
+// it is read only, so the requester isn't told they have access
+func (a *authorizerNotifier) isSimulated() bool {
+	return len(a.simulated) > 0 && len(a.simulated) >= len(a.authResponses)
+}
+
+// isPartiallySimulated returns true when some providers granted access and
+// others only simulated it
+func (a *authorizerNotifier) isPartiallySimulated() bool {
+	return len(a.simulated) > 0 && !a.isSimulated()
+}
+
+// isProviderSimulated returns true when the provider only simulated its grant
+func (a *authorizerNotifier) isProviderSimulated(providerName string) bool {
+	return slices.Contains(a.simulated, providerName)
+}
+
 func (a *authorizerNotifier) GetRecipients() []string {
 	return a.req.To
 }
@@ -70,6 +90,8 @@ func (a *authorizerNotifier) GetProviderName() string {
 
 func (a *authorizerNotifier) GetPayload(toIdentity *models.Identity) models.NotificationRequest {
 
+	log := getLogger(a.workflowTask)
+
 	elevationReq := a.elevationReq
 	var notificationPayload models.NotificationRequest
 
@@ -77,28 +99,43 @@ func (a *authorizerNotifier) GetPayload(toIdentity *models.Identity) models.Noti
 
 		blocks := a.createAuthorizeSlackBlocks(toIdentity)
 
+		text := fmt.Sprintf("Your access request for role %s has been approved", func() string {
+			if elevationReq.Role != nil {
+				return elevationReq.Role.Name
+			}
+			return "unknown"
+		}())
+
+		if a.isSimulated() {
+			text = fmt.Sprintf("%s (simulated, no access was granted)", text)
+		} else if a.isPartiallySimulated() {
+			text = fmt.Sprintf("%s (simulated on %s, no access was granted there)",
+				text, strings.Join(a.simulated, ", "))
+		}
+
 		slackReq := slackProvider.SlackNotificationRequest{
-			To: toIdentity.GetEmail(),
-			Text: fmt.Sprintf("Your access request for role %s has been approved", func() string {
-				if elevationReq.Role != nil {
-					return elevationReq.Role.Name
-				}
-				return "unknown"
-			}()),
+			To:   toIdentity.GetEmail(),
+			Text: text,
 			Blocks: slack.Blocks{
 				BlockSet: blocks,
 			},
 		}
 		err := common.ConvertInterfaceToInterface(slackReq, &notificationPayload)
 		if err != nil {
-			logrus.WithError(err).Error("Failed to convert slack request")
+			log.WithError(err).Error("Failed to convert slack request")
 			return models.NotificationRequest{}
 		}
 	} else if strings.HasPrefix(a.GetProviderName(), emailProvider.EmailProviderName) {
 		plainText, html := a.createAuthorizeEmailBody()
+		subject := "Access Request Approved"
+		if a.isSimulated() {
+			subject = "Access Request Approved (Simulated)"
+		} else if a.isPartiallySimulated() {
+			subject = "Access Request Approved (Partially Simulated)"
+		}
 		emailReq := models.EmailNotificationRequest{
 			To:      []string{toIdentity.GetEmail()},
-			Subject: "Access Request Approved",
+			Subject: subject,
 			Body: models.EmailNotificationBody{
 				Text: plainText,
 				HTML: html,
@@ -106,11 +143,11 @@ func (a *authorizerNotifier) GetPayload(toIdentity *models.Identity) models.Noti
 		}
 		err := common.ConvertInterfaceToInterface(emailReq, &notificationPayload)
 		if err != nil {
-			logrus.WithError(err).Error("Failed to convert email request")
+			log.WithError(err).Error("Failed to convert email request")
 			return models.NotificationRequest{}
 		}
 	} else {
-		logrus.WithField("provider", a.GetProviderName()).Error("Unsupported provider type")
+		log.WithField("provider", a.GetProviderName()).Error("Unsupported provider type")
 		return models.NotificationRequest{}
 	}
 
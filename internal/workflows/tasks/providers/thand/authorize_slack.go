@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 	"github.com/thand-io/agent/internal/models"
 	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
@@ -82,7 +81,7 @@ func (a *authorizerNotifier) addAuthorizeRequestDetailsSection(blocks *[]slack.B
 	}
 
 	if len(elevateRequest.Duration) > 0 {
-		requestDetailsText.WriteString(fmt.Sprintf("- *Duration:* %s\n", elevateRequest.Duration))
+		requestDetailsText.WriteString(fmt.Sprintf("- *Duration:* %s\n", elevateRequest.GetFormattedDuration()))
 	}
 
 	*blocks = append(*blocks, slack.NewSectionBlock(
@@ -127,6 +126,8 @@ func (a *authorizerNotifier) addProviderAccessButton(
 	toIdentity *models.Identity,
 ) {
 
+	log := getLogger(a.workflowTask)
+
 	if len(elevateRequest.Providers) == 0 || len(a.authRequests) == 0 || len(a.authResponses) == 0 {
 		return
 	}
@@ -134,32 +135,38 @@ func (a *authorizerNotifier) addProviderAccessButton(
 	buttonElements := []slack.BlockElement{}
 
 	for _, providerName := range elevateRequest.Providers {
+
+		// Simulated grants have nothing to access
+		if a.isProviderSimulated(providerName) {
+			continue
+		}
+
 		// Customize URL based on your provider setup
 		provider, err := a.config.GetProviderByName(providerName)
 
 		if err != nil {
-			logrus.Errorf("Failed to get provider '%s' for access URL: %v", providerName, err)
+			log.Errorf("Failed to get provider '%s' for access URL: %v", providerName, err)
 			continue
 		}
 
-		providerClient := provider.GetClient()
+		rbac, err := provider.GetRBAC()
 
-		if providerClient == nil {
-			logrus.Errorf("Provider '%s' has no client defined for access URL", providerName)
+		if err != nil {
+			log.Errorf("Provider '%s' has no access URL: %v", providerName, err)
 			continue
 		}
 
 		// See if the user ids or emails match in the auth requests/responses
 
-		authRequest, foundReq := a.authRequests[toIdentity.GetId()]
-		authResponse, foundAuth := a.authResponses[toIdentity.GetId()]
+		authRequest, foundReq := a.authRequests[providerName][toIdentity.GetId()]
+		authResponse, foundAuth := a.authResponses[providerName][toIdentity.GetId()]
 
 		if !foundAuth || !foundReq {
-			logrus.Errorf("No authorization found for identity '%s' and provider '%s'", toIdentity.GetId(), providerName)
+			log.Errorf("No authorization found for identity '%s' and provider '%s'", toIdentity.GetId(), providerName)
 			continue
 		}
 
-		accessUrl := providerClient.GetAuthorizedAccessUrl(
+		accessUrl := rbac.GetAuthorizedAccessUrl(
 			context.TODO(),
 			authRequest,
 			authResponse,
@@ -195,10 +202,20 @@ func (a *authorizerNotifier) addProviderAccessButton(
 
 // addClosingMessageSection adds a closing message
 func (a *authorizerNotifier) addClosingMessageSection(blocks *[]slack.Block) {
+	message := "_Your access is now active. Please use it responsibly._"
+
+	if a.isSimulated() {
+		message = "*This grant was simulated as the provider is read only. No access was granted.*"
+	} else if a.isPartiallySimulated() {
+		message = fmt.Sprintf(
+			"*The grant on %s was simulated as the provider is read only. No access was granted there.*\n%s",
+			strings.Join(a.simulated, ", "), message)
+	}
+
 	*blocks = append(*blocks, slack.NewSectionBlock(
 		slack.NewTextBlockObject(
 			slack.MarkdownType,
-			"_Your access is now active. Please use it responsibly._",
+			message,
 			false,
 			false,
 		),
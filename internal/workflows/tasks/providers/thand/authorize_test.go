@@ -0,0 +1,254 @@
+package thand
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
+)
+
+func TestBuildProviderOutcomes(t *testing.T) {
+	outcomes := buildProviderOutcomes(
+		[]string{"aws-prod", "gcp-prod", "kubernetes"},
+		map[string]map[string]*models.AuthorizeRoleResponse{
+			"aws-prod": {
+				"alice@example.com": {},
+				"bob@example.com":   {},
+			},
+			"gcp-prod": {
+				"alice@example.com": {},
+			},
+		},
+		map[string]map[string]string{
+			"gcp-prod": {
+				"bob@example.com": "permission denied",
+			},
+			"kubernetes": {
+				"alice@example.com": "timeout",
+			},
+		},
+	)
+
+	aws := outcomes["aws-prod"].(map[string]any)
+	assert.Equal(t, "authorized", aws["status"])
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com"}, aws["identities"])
+	assert.NotContains(t, aws, "errors")
+
+	gcp := outcomes["gcp-prod"].(map[string]any)
+	assert.Equal(t, "partial", gcp["status"])
+	assert.Equal(t, map[string]string{"bob@example.com": "permission denied"}, gcp["errors"])
+
+	k8s := outcomes["kubernetes"].(map[string]any)
+	assert.Equal(t, "failed", k8s["status"])
+}
+
+func TestGetSimulatedProviders(t *testing.T) {
+	authorizations := map[string]map[string]*models.AuthorizeRoleResponse{
+		"azure-pilot": {
+			"alice@example.com": {GrantRef: &models.GrantRef{Simulated: true}},
+		},
+		"aws-prod": {
+			"alice@example.com": {},
+		},
+	}
+
+	assert.Equal(t, []string{"azure-pilot"}, getSimulatedProviders(authorizations))
+
+	outcomes := buildProviderOutcomes([]string{"azure-pilot", "aws-prod"}, authorizations, nil)
+	assert.Equal(t, true, outcomes["azure-pilot"].(map[string]any)["simulated"])
+	assert.NotContains(t, outcomes["aws-prod"], "simulated")
+}
+
+func TestGetProviderRequests(t *testing.T) {
+	newTask := func(providerName string) authTask {
+		return authTask{
+			ProviderName: providerName,
+			Identity:     "alice@example.com",
+			AuthRequest: models.AuthorizeRoleRequest{
+				RoleRequest: &models.RoleRequest{
+					Role: &models.Role{Name: providerName},
+				},
+			},
+		}
+	}
+
+	// The same identity granted on several providers keeps a request for each
+	requests := getProviderRequests([]authTask{
+		newTask("aws-prod"),
+		newTask("gcp-prod"),
+		newTask("kubernetes"),
+	})
+
+	require.Len(t, requests, 3)
+	for _, providerName := range []string{"aws-prod", "gcp-prod", "kubernetes"} {
+		require.Contains(t, requests[providerName], "alice@example.com")
+		assert.Equal(t, providerName, requests[providerName]["alice@example.com"].Role.Name)
+	}
+}
+
+func TestGetProviderAuthorization(t *testing.T) {
+
+	response := &models.AuthorizeRoleResponse{UserId: "alice"}
+
+	typed := map[string]map[string]*models.AuthorizeRoleResponse{
+		"aws-prod": {"alice@example.com": response},
+	}
+
+	authResp, granted := getProviderAuthorization(typed, "aws-prod", "alice@example.com")
+	assert.True(t, granted)
+	assert.Equal(t, response, authResp)
+
+	_, granted = getProviderAuthorization(typed, "gcp-prod", "alice@example.com")
+	assert.False(t, granted)
+
+	// Round-tripped through JSON the context holds generic maps
+	untyped := map[string]any{
+		"aws-prod": map[string]any{
+			"alice@example.com": map[string]any{"user_id": "alice"},
+			"bob@example.com":   nil,
+		},
+	}
+
+	authResp, granted = getProviderAuthorization(untyped, "aws-prod", "alice@example.com")
+	assert.True(t, granted)
+	assert.Equal(t, "alice", authResp.UserId)
+
+	authResp, granted = getProviderAuthorization(untyped, "aws-prod", "bob@example.com")
+	assert.True(t, granted)
+	assert.Nil(t, authResp)
+
+	_, granted = getProviderAuthorization(untyped, "gcp-prod", "alice@example.com")
+	assert.False(t, granted)
+}
+
+func TestAuthorizeTaskRetryPolicy(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		policy := (&AuthorizeTask{}).GetRetryPolicy()
+
+		assert.Equal(t, 3*time.Second, policy.InitialInterval)
+		assert.Equal(t, 2.0, policy.BackoffCoefficient)
+		assert.Equal(t, int32(5), policy.MaximumAttempts)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		task := &AuthorizeTask{MaxRetries: 2, InitialBackoffSeconds: 10, FailureState: "grant-failed"}
+		policy := task.GetRetryPolicy()
+
+		assert.Equal(t, 10*time.Second, policy.InitialInterval)
+		assert.Equal(t, int32(3), policy.MaximumAttempts)
+		assert.True(t, task.HasFailureState())
+	})
+}
+
+func TestAuthorizationHookRequest(t *testing.T) {
+
+	// The hook denies every grant with the request fields it was passed
+	path := filepath.Join(t.TempDir(), "hook.lua")
+	require.NoError(t, os.WriteFile(path, []byte(`
+function authorize(user, role, request)
+  return false, table.concat({
+    request.identity, request.requester or "", request.reason or "",
+    request.workflow_id or "", tostring(request.duration),
+  }, "|")
+end
+`), 0o600))
+
+	cfg := config.DefaultConfig()
+	cfg.AuthorizationHook = path
+	require.NoError(t, cfg.ReloadConfig())
+
+	workflowTask := &models.WorkflowTask{WorkflowID: "wf-1"}
+
+	user := &models.User{Email: "bob@example.com"}
+	role := &models.Role{Name: "prod-admin"}
+
+	elevateRequest := &models.ElevateRequestInternal{
+		ElevateRequest: models.ElevateRequest{
+			Role:   role,
+			Reason: "INC-42 database failover",
+		},
+		User: &models.User{Email: "alice@example.com"},
+	}
+
+	duration := time.Hour
+
+	// Granted by the thand authorize task
+	taskRequest := newAuthorizeRoleRequest(workflowTask, elevateRequest, user, duration)
+
+	// Granted by a thand.authorize function call, which is only passed
+	// the user, role and duration
+	functionRequest := (&thandFunction.ThandAuthorizeRequest{
+		Provider: "aws-prod",
+		AuthorizeRoleRequest: models.AuthorizeRoleRequest{
+			RoleRequest: &models.RoleRequest{
+				User:     user,
+				Role:     role,
+				Duration: &duration,
+			},
+		},
+	}).GetAuthorizeRoleRequest(workflowTask, elevateRequest)
+
+	taskErr := cfg.RunAuthorizationHook(context.Background(), "aws-prod", &taskRequest)
+	functionErr := cfg.RunAuthorizationHook(context.Background(), "aws-prod", functionRequest)
+
+	require.ErrorIs(t, taskErr, config.ErrAuthorizationHookDenied)
+	assert.ErrorContains(t, taskErr, "bob@example.com|alice@example.com|INC-42 database failover|wf-1|3600")
+	assert.EqualError(t, functionErr, taskErr.Error())
+}
+
+func TestAuthorizerNotifierSimulated(t *testing.T) {
+	newNotifier := func(simulatedProviders []string) *authorizerNotifier {
+		return NewAuthorizerNotifier(
+			nil,
+			&models.WorkflowTask{WorkflowID: "wf-1"},
+			&models.ElevateRequestInternal{},
+			&thandFunction.NotifierRequest{},
+			"email",
+			map[string]map[string]*models.AuthorizeRoleRequest{},
+			map[string]map[string]*models.AuthorizeRoleResponse{
+				"aws-prod":    {"alice@example.com": {}},
+				"azure-pilot": {"alice@example.com": {GrantRef: &models.GrantRef{Simulated: true}}},
+			},
+			simulatedProviders,
+		).(*authorizerNotifier)
+	}
+
+	t.Run("real grant", func(t *testing.T) {
+		notifier := newNotifier(nil)
+		assert.False(t, notifier.isSimulated())
+		assert.False(t, notifier.isPartiallySimulated())
+
+		plainText, _ := notifier.createAuthorizeEmailBody()
+		assert.Contains(t, plainText, "Your access is now active")
+	})
+
+	t.Run("one provider simulated", func(t *testing.T) {
+		notifier := newNotifier([]string{"azure-pilot"})
+		assert.False(t, notifier.isSimulated())
+		assert.True(t, notifier.isPartiallySimulated())
+		assert.True(t, notifier.isProviderSimulated("azure-pilot"))
+		assert.False(t, notifier.isProviderSimulated("aws-prod"))
+
+		plainText, html := notifier.createAuthorizeEmailBody()
+		assert.Contains(t, plainText, "The grant on azure-pilot was simulated")
+		assert.Contains(t, plainText, "Your access to the other providers is now active")
+		assert.Contains(t, html, "The grant on azure-pilot was simulated")
+	})
+
+	t.Run("every provider simulated", func(t *testing.T) {
+		notifier := newNotifier([]string{"aws-prod", "azure-pilot"})
+		assert.True(t, notifier.isSimulated())
+		assert.False(t, notifier.isPartiallySimulated())
+
+		plainText, _ := notifier.createAuthorizeEmailBody()
+		assert.Contains(t, plainText, "No access was granted.")
+		assert.NotContains(t, plainText, "now active")
+	})
+}
@@ -0,0 +1,107 @@
+package thand
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	taskModel "github.com/thand-io/agent/internal/workflows/tasks/model"
+	"go.temporal.io/sdk/workflow"
+)
+
+const ThandAutoRevokeTask = "auto_revoke"
+
+// AutoRevokeTask waits for the granted access to expire and then revokes it
+type AutoRevokeTask struct {
+	RevokeTask
+	Duration string `json:"duration,omitempty"` // Overrides the requested duration e.g. 1h
+}
+
+// ThandAutoRevokeTask is the canonical way to implement time bounded
+// access. It sleeps until the access expires and then revokes the roles
+// granted by the authorize task.
+func (t *thandTask) executeAutoRevokeTask(
+	workflowTask *models.WorkflowTask,
+	taskName string,
+	call *taskModel.ThandTask) (any, error) {
+
+	if !workflowTask.HasTemporalContext() {
+		// Only supported within Temporal workflows
+		return nil, fmt.Errorf("auto revocation is only supported with temporal for task: %s", taskName)
+	}
+
+	log := workflowTask.GetLogger()
+
+	elevateRequest, err := workflowTask.GetContextAsElevationRequest()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var autoRevokeTask AutoRevokeTask
+	err = common.ConvertInterfaceToInterface(call.With, &autoRevokeTask)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse auto revoke request: %w", err)
+	}
+
+	duration, err := getAutoRevokeDuration(elevateRequest, autoRevokeTask.Duration)
+
+	if err != nil {
+		return nil, err
+	}
+
+	temporalContext := workflowTask.GetTemporalContext()
+
+	// Count the time already spent since the roles were granted
+	revocationAt := workflow.Now(temporalContext).Add(duration)
+	if elevateRequest.AuthorizedAt != nil {
+		revocationAt = elevateRequest.AuthorizedAt.Add(duration)
+	}
+
+	log.WithFields(models.Fields{
+		"task_name":     taskName,
+		"duration":      duration.String(),
+		"revocation_at": revocationAt.Format(time.RFC3339),
+	}).Info("Waiting for access to expire")
+
+	if remaining := revocationAt.Sub(workflow.Now(temporalContext)); remaining > 0 {
+		if err := workflow.Sleep(temporalContext, remaining); err != nil {
+			return nil, err
+		}
+	}
+
+	return t.executeRevocationTask(workflowTask, taskName, call, elevateRequest, &autoRevokeTask.RevokeTask)
+}
+
+// getAutoRevokeDuration returns how long access is granted for. The
+// duration is capped to the role's max duration when one is set.
+func getAutoRevokeDuration(elevateRequest *models.ElevateRequestInternal, override string) (time.Duration, error) {
+
+	var duration time.Duration
+	var err error
+
+	if len(override) > 0 {
+		duration, err = common.ValidateDuration(override)
+	} else {
+		duration, err = elevateRequest.AsDuration()
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to get duration: %w", err)
+	}
+
+	if elevateRequest.Role != nil && len(elevateRequest.Role.MaxDuration) > 0 {
+
+		maxDuration, err := common.ValidateDuration(elevateRequest.Role.MaxDuration)
+
+		if err != nil {
+			return 0, fmt.Errorf("invalid max duration for role %s: %w", elevateRequest.Role.Name, err)
+		}
+
+		duration = min(duration, maxDuration)
+	}
+
+	return duration, nil
+}
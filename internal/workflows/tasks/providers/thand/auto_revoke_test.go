@@ -0,0 +1,45 @@
+package thand
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func TestGetAutoRevokeDuration(t *testing.T) {
+
+	newRequest := func(duration string, maxDuration string) *models.ElevateRequestInternal {
+		return &models.ElevateRequestInternal{
+			ElevateRequest: models.ElevateRequest{
+				Role:     &models.Role{Name: "admin", MaxDuration: maxDuration},
+				Duration: duration,
+			},
+		}
+	}
+
+	t.Run("uses the requested duration", func(t *testing.T) {
+		duration, err := getAutoRevokeDuration(newRequest("2h", ""), "")
+		require.NoError(t, err)
+		assert.Equal(t, 2*time.Hour, duration)
+	})
+
+	t.Run("task duration overrides the request", func(t *testing.T) {
+		duration, err := getAutoRevokeDuration(newRequest("2h", ""), "30m")
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Minute, duration)
+	})
+
+	t.Run("capped to the role max duration", func(t *testing.T) {
+		duration, err := getAutoRevokeDuration(newRequest("8h", "4h"), "")
+		require.NoError(t, err)
+		assert.Equal(t, 4*time.Hour, duration)
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		_, err := getAutoRevokeDuration(newRequest("soon", ""), "")
+		assert.Error(t, err)
+	})
+}
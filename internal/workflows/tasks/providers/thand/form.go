@@ -232,6 +232,8 @@ func (t *thandTask) makeFormNotifications(
 	elevationRequest *models.ElevateRequestInternal,
 ) error {
 
+	log := getLogger(workflowTask)
+
 	var notifyTasks []notifyTask
 
 	for providerKey, notifierRequest := range formTask.Notifiers {
@@ -259,7 +261,7 @@ func (t *thandTask) makeFormNotifications(
 			recipientIdentity := t.resolveIdentity(recipientId)
 
 			if recipientIdentity == nil {
-				logrus.WithField("recipient", recipientId).Warn("Skipping form notification, identity not found")
+				log.WithField("recipient", recipientId).Warn("Skipping form notification, identity not found")
 				continue
 			}
 
@@ -273,7 +275,7 @@ func (t *thandTask) makeFormNotifications(
 				Provider:  formNotifier.GetProviderName(),
 			})
 
-			logrus.WithFields(logrus.Fields{
+			log.WithFields(logrus.Fields{
 				"recipient":   recipientId,
 				"provider":    formNotifier.GetProviderName(),
 				"providerKey": providerKey,
@@ -292,15 +294,15 @@ func (t *thandTask) makeFormNotifications(
 	}
 
 	if err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
+		log.WithError(err).WithFields(logrus.Fields{
 			"taskName": taskName,
 		}).Error("Failed to execute form notifications")
 		return err
 	}
 
 	// Process results
-	if err := processNotificationResults(notifyResults, "Form notification"); err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
+	if err := processNotificationResults(workflowTask, notifyResults, "Form notification"); err != nil {
+		log.WithError(err).WithFields(logrus.Fields{
 			"taskName": taskName,
 		}).Error("Failed to process form notification results")
 		return err
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 	"github.com/thand-io/agent/internal/models"
 )
@@ -12,6 +11,8 @@ import (
 // createFormEmailBody creates the email body for form requests
 // For email, we send a link to the HTML form page
 func (f *formNotifier) createFormEmailBody(toIdentity *models.Identity) (string, string) {
+	log := getLogger(f.workflowTask)
+
 	workflowTask := f.workflowTask
 
 	// Create the form URL
@@ -80,7 +81,7 @@ func (f *formNotifier) createFormEmailBody(toIdentity *models.Identity) (string,
 	// Render HTML email using template
 	html, err := RenderEmailWithTemplate(f.getEmailSubject(), GetFormContentTemplate(), data)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to render form email")
+		log.WithError(err).Error("Failed to render form email")
 		return plainText.String(), ""
 	}
 
@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/serverlessworkflow/sdk-go/v3/model"
-	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config"
@@ -68,6 +67,8 @@ func (f *formNotifier) GetProviderName() string {
 }
 
 func (f *formNotifier) GetPayload(toIdentity *models.Identity) models.NotificationRequest {
+	log := getLogger(f.workflowTask)
+
 	var notificationPayload models.NotificationRequest
 
 	if strings.Compare(f.GetProviderName(), slackProvider.SlackProviderName) == 0 {
@@ -87,7 +88,7 @@ func (f *formNotifier) GetPayload(toIdentity *models.Identity) models.Notificati
 		}
 		err := common.ConvertInterfaceToInterface(slackReq, &notificationPayload)
 		if err != nil {
-			logrus.WithError(err).Error("Failed to convert slack request")
+			log.WithError(err).Error("Failed to convert slack request")
 			return models.NotificationRequest{}
 		}
 	} else if strings.HasPrefix(f.GetProviderName(), emailProvider.EmailProviderName) {
@@ -103,11 +104,11 @@ func (f *formNotifier) GetPayload(toIdentity *models.Identity) models.Notificati
 		}
 		err := common.ConvertInterfaceToInterface(emailReq, &notificationPayload)
 		if err != nil {
-			logrus.WithError(err).Error("Failed to convert email request")
+			log.WithError(err).Error("Failed to convert email request")
 			return models.NotificationRequest{}
 		}
 	} else {
-		logrus.WithField("provider", f.GetProviderName()).Error("Unsupported provider type for form notification")
+		log.WithField("provider", f.GetProviderName()).Error("Unsupported provider type for form notification")
 		return models.NotificationRequest{}
 	}
 
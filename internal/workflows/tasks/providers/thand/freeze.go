@@ -0,0 +1,115 @@
+package thand
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
+	"go.temporal.io/sdk/workflow"
+)
+
+// evaluateFreeze evaluates the change freeze calendars, as an activity
+// under Temporal, and records the status in the context so route
+// conditions can use ${ $context.freeze.active }. Nothing is recorded
+// when no calendars are configured.
+func (t *thandTask) evaluateFreeze(
+	workflowTask *models.WorkflowTask,
+	taskName string,
+) (*models.FreezeStatus, error) {
+
+	if !t.config.Freeze.IsEnabled() {
+		return nil, nil
+	}
+
+	var freeze *models.FreezeStatus
+
+	if !workflowTask.HasTemporalContext() {
+
+		elevationRequest, err := workflowTask.GetContextAsElevationRequest()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get elevation request from context: %w", err)
+		}
+
+		freeze, err = t.config.EvaluateFreeze(
+			workflowTask.GetContext(),
+			elevationRequest.User,
+			elevationRequest.Role,
+			elevationRequest.GetWorkflow(),
+			time.Now(),
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+	} else {
+
+		serviceClient := t.config.GetServices()
+
+		aoctx := workflow.WithActivityOptions(workflowTask.GetTemporalContext(), workflow.ActivityOptions{
+			TaskQueue:           serviceClient.GetTemporal().GetTaskQueue(),
+			StartToCloseTimeout: time.Minute,
+		})
+
+		freeze = &models.FreezeStatus{}
+
+		err := workflow.ExecuteActivity(
+			aoctx,
+			thandFunction.ThandFreezeFunction,
+			workflowTask,
+			taskName,
+			model.CallFunction{
+				Call: thandFunction.ThandFreezeFunction,
+			},
+			nil,
+		).Get(aoctx, freeze)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	setFreezeContext(workflowTask, freeze)
+
+	return freeze, nil
+}
+
+// setFreezeContext records the freeze status in the workflow context
+func setFreezeContext(workflowTask *models.WorkflowTask, freeze *models.FreezeStatus) {
+
+	if freeze == nil {
+		return
+	}
+
+	freezeMap, err := common.ConvertInterfaceToMap(freeze)
+
+	if err != nil {
+		workflowTask.GetLogger().WithError(err).Warn("Failed to record the change freeze")
+		return
+	}
+
+	workflowTask.SetContextKeyValue(models.VarsContextFreeze, freezeMap)
+}
+
+// getFreezeApprovers returns the extra approvers required by an active
+// freeze recorded by a previous task
+func getFreezeApprovers(workflowTask *models.WorkflowTask) []string {
+
+	freeze, err := models.GetContextAs[models.FreezeStatus](workflowTask, models.VarsContextFreeze)
+
+	if err != nil || !freeze.RequiresApproval() {
+		return nil
+	}
+
+	return freeze.Approvers
+}
+
+// newFreezeDeniedError is returned when the role or workflow is denied
+// during an active freeze
+func newFreezeDeniedError(freeze *models.FreezeStatus) error {
+	return fmt.Errorf("elevation request blocked during the change freeze: %s", freeze.GetDescription())
+}
@@ -5,8 +5,10 @@ import (
 
 	"github.com/serverlessworkflow/sdk-go/v3/model"
 	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config"
 	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/siem"
 	"github.com/thand-io/agent/internal/workflows/tasks"
 	taskModel "github.com/thand-io/agent/internal/workflows/tasks/model"
 )
@@ -73,6 +75,15 @@ func (t *thandTask) resolveIdentity(identity string) *models.Identity {
 	}
 }
 
+// getLogger returns the log entry for the task. It carries the workflow
+// and request IDs set when the task started, the same IDs the providers
+// log with.
+func getLogger(workflowTask *models.WorkflowTask) *logrus.Entry {
+	workflowID, requestID := models.GetLogContext(workflowTask.GetContext())
+	return common.WithProviderContext(
+		logrus.StandardLogger(), "", workflowID, requestID)
+}
+
 func (f *thandTask) GetVersion() string {
 	return "1.0.0"
 }
@@ -91,6 +102,11 @@ func (t *thandTask) Execute(
 	taskName := task.Key
 	thandTask, ok := task.Task.(*taskModel.ThandTask)
 
+	// Everything logged for the task, including the provider calls made
+	// with the task's context, shares a request ID
+	workflowTask.SetInternalContext(common.WithLogContext(
+		workflowTask.GetContext(), workflowTask.WorkflowID, common.NewRequestID()))
+
 	if !ok {
 		return nil, fmt.Errorf("invalid task type for ServerlessThandTask")
 	}
@@ -98,7 +114,9 @@ func (t *thandTask) Execute(
 	// Create a copy to preserve the original workflow intent
 	interpolatedTask := *thandTask
 
-	if thandTask.With != nil {
+	// Route conditions are evaluated one at a time by the task so a
+	// condition that fails to evaluate doesn't fail the others
+	if thandTask.With != nil && thandTask.Thand != ThandRouteTask {
 
 		interpolatedWith, err := workflowTask.TraverseAndEvaluate(
 			thandTask.With.AsMap(), input)
@@ -125,15 +143,25 @@ func (t *thandTask) Execute(
 	case ThandAuthorizeTask:
 		return t.executeAuthorizeTask(workflowTask, taskName, &interpolatedTask)
 	case ThandValidateTask:
-		return t.executeValidateTask(workflowTask, &interpolatedTask, input)
+		output, err := t.executeValidateTask(workflowTask, taskName, &interpolatedTask, input)
+		if !workflowTask.IsReplaying() {
+			siem.Record(siem.NewEvent(siem.EventElevationRequested, workflowTask).WithError(err))
+		}
+		return output, err
 	case ThandNotifyTask:
 		return t.executeNotifyTask(workflowTask, taskName, &interpolatedTask)
 	case ThandRevokeTask:
 		return t.executeRevokeTask(workflowTask, taskName, &interpolatedTask)
+	case ThandAutoRevokeTask:
+		return t.executeAutoRevokeTask(workflowTask, taskName, &interpolatedTask)
 	case ThandMonitorTask:
 		return t.executeMonitorTask(workflowTask, taskName, &interpolatedTask, input)
 	case ThandFormTask:
 		return t.executeFormTask(workflowTask, taskName, &interpolatedTask)
+	case ThandPolicyTask:
+		return t.executePolicyTask(workflowTask, taskName, &interpolatedTask)
+	case ThandRouteTask:
+		return t.executeRouteTask(workflowTask, taskName, &interpolatedTask, input)
 	default:
 		return nil, fmt.Errorf("unknown thand task type: %s", interpolatedTask.Thand)
 	}
@@ -23,23 +23,28 @@ const ThandFormEventType = "com.thand.form"
 
 // notifyResult holds the result of a notification operation
 type notifyResult struct {
-	Recipient string
-	Error     error
+	NotificationID string
+	Recipient      string
+	Receipt        models.NotificationReceipt
+	Error          error
 }
 
 // notifyTask represents a notification task with all necessary context
 type notifyTask struct {
-	Recipient string
-	CallFunc  model.CallFunction
-	Payload   models.NotificationRequest
-	Provider  string
+	NotificationID string
+	Recipient      string
+	CallFunc       model.CallFunction
+	Payload        models.NotificationRequest
+	Provider       string
 }
 
 // temporalNotifyResult represents the result of a notification operation for temporal communication
 type temporalNotifyResult struct {
-	Index     int
-	Recipient string
-	Err       error
+	Index          int
+	NotificationID string
+	Recipient      string
+	Receipt        models.NotificationReceipt
+	Err            error
 }
 
 func (t *thandTask) executeNotifyTask(
@@ -82,7 +87,26 @@ func (t *thandTask) executeNotifyTask(
 		return nil, errors.New("elevation request is not valid")
 	}
 
-	notifyImpl := NewDefaultNotifierImpl(notifyReq)
+	templateData, err := newNotificationTemplateData(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Render once with the default locale. Individual notifications are
+	// rendered again for each recipient, this catches template errors before
+	// anything is sent and is the fallback if a recipient's render fails.
+	rendered := notifyReq
+	if err := renderNotificationTemplateData(&rendered, templateData, notifyReq.Locale); err != nil {
+		return nil, err
+	}
+
+	var notifyImpl NotifierImpl
+
+	if notifyReq.Individual {
+		notifyImpl = NewIndividualNotifierImpl(notifyReq, templateData, rendered)
+	} else {
+		notifyImpl = NewDefaultNotifierImpl(rendered)
+	}
 
 	return t.executeNotify(workflowTask, taskName, notifyImpl)
 
@@ -108,10 +132,25 @@ func (t *thandTask) executeNotify(
 		"count":      len(recipients),
 	}).Info("Preparing to send notifications")
 
+	// Deliveries from previous attempts of this task. Targets that were
+	// already delivered are skipped so retries don't double send.
+	deliveries := getNotificationDeliveries(workflowTask)
+
 	// Build notification tasks for each recipient
 	var notifyTasks []notifyTask
 	for _, recipientId := range recipients {
 
+		notificationID := models.NewNotificationID(
+			workflowTask.WorkflowID, taskName, notify.GetProviderName(), recipientId)
+
+		if delivery, found := deliveries[notificationID]; found && delivery.IsDelivered() {
+			log.WithFields(models.Fields{
+				"recipient":       recipientId,
+				"notification_id": notificationID,
+			}).Info("Notification already delivered, skipping")
+			continue
+		}
+
 		recipientIdentity := t.resolveIdentity(recipientId)
 
 		if recipientIdentity == nil {
@@ -123,19 +162,37 @@ func (t *thandTask) executeNotify(
 		recipientIdentity.ID = recipientId
 		recipientPayload := notify.GetPayload(recipientIdentity)
 
+		if recipientPayload == nil {
+			recipientPayload = models.NotificationRequest{}
+		}
+
+		recipientPayload[models.NotificationIDKey] = notificationID
+
+		// Targets in digest mode get a line in the digest instead
+		if digestNotify, ok := notify.(DigestNotifierImpl); ok {
+			setNotificationDigest(recipientPayload, digestNotify, recipientIdentity, workflowTask.WorkflowID)
+		}
+
 		notifyTasks = append(notifyTasks, notifyTask{
-			Recipient: recipientId,
-			CallFunc:  notify.GetCallFunction(recipientIdentity),
-			Payload:   recipientPayload,
-			Provider:  notify.GetProviderName(),
+			NotificationID: notificationID,
+			Recipient:      recipientId,
+			CallFunc:       notify.GetCallFunction(recipientIdentity),
+			Payload:        recipientPayload,
+			Provider:       notify.GetProviderName(),
 		})
 
 		log.WithFields(models.Fields{
-			"recipient": recipientId,
-			"provider":  notify.GetProviderName(),
+			"recipient":       recipientId,
+			"provider":        notify.GetProviderName(),
+			"notification_id": notificationID,
 		}).Debug("Prepared notification task")
 	}
 
+	if len(notifyTasks) == 0 {
+		log.Info("All notifications already delivered")
+		return nil, nil
+	}
+
 	// Execute notifications in parallel
 	var notifyResults []notifyResult
 	var err error
@@ -151,37 +208,103 @@ func (t *thandTask) executeNotify(
 		return nil, err
 	}
 
-	// Process results
-	hasErrors := false
-	successCount := 0
+	// Record the outcome for each target
+	var failedRecipients []string
 
 	for _, result := range notifyResults {
+
+		delivery := deliveries[result.NotificationID]
+		delivery.Provider = notify.GetProviderName()
+		delivery.Recipient = result.Recipient
+		delivery.Attempts++
+		delivery.Receipts = append(delivery.Receipts, result.Receipt)
+
 		if result.Error != nil {
 			log.WithError(result.Error).
 				WithField("recipient", result.Recipient).
 				Error("Notification failed")
-			hasErrors = true
+			delivery.Status = models.NotificationDeliveryFailed
+			delivery.Error = result.Error.Error()
+			failedRecipients = append(failedRecipients, result.Recipient)
 		} else {
-			successCount++
 			log.WithField("recipient", result.Recipient).
 				Info("Notification sent successfully")
+			delivery.Status = models.NotificationDeliveryDelivered
+			delivery.Error = ""
 		}
-	}
 
-	if hasErrors && successCount == 0 {
-		return nil, fmt.Errorf("all notification requests failed")
+		deliveries[result.NotificationID] = delivery
 	}
 
-	if hasErrors {
+	workflowTask.SetContextKeyValue(models.VarsContextNotifications, deliveries)
+
+	if len(failedRecipients) > 0 {
 		log.WithFields(models.Fields{
-			"success": successCount,
-			"total":   len(notifyResults),
+			"failed": len(failedRecipients),
+			"total":  len(notifyResults),
 		}).Warn("Some notifications failed")
+
+		return nil, newNotificationDeliveryError(failedRecipients)
 	}
 
 	return nil, nil
 }
 
+// setNotificationDigest adds the target's digest settings and entry to the
+// payload so the notification can be buffered when it is delivered
+func setNotificationDigest(
+	payload models.NotificationRequest,
+	notify DigestNotifierImpl,
+	toIdentity *models.Identity,
+	workflowID string,
+) {
+
+	digest := notify.GetDigest()
+
+	if digest == nil {
+		return
+	}
+
+	entry := notify.GetDigestEntry(toIdentity)
+
+	if entry == nil {
+		return
+	}
+
+	entry.WorkflowID = workflowID
+	entry.Recipient = toIdentity.ID
+
+	payload[models.NotificationDigestKey] = digest
+	payload[models.NotificationDigestEntryKey] = entry
+}
+
+// getNotificationDeliveries returns the delivery status recorded in the
+// workflow context. After a Temporal round trip these are plain maps.
+func getNotificationDeliveries(workflowTask *models.WorkflowTask) map[string]models.NotificationDelivery {
+
+	deliveries, err := models.GetContextAs[map[string]models.NotificationDelivery](
+		workflowTask, models.VarsContextNotifications)
+
+	if errors.Is(err, models.ErrContextKeyNotFound) {
+		return map[string]models.NotificationDelivery{}
+	} else if err != nil {
+		getLogger(workflowTask).WithError(err).Warn("Failed to read notification deliveries from context")
+		return map[string]models.NotificationDelivery{}
+	}
+
+	return deliveries
+}
+
+// newNotificationDeliveryError returns a retryable error for the targets
+// that were not delivered. Delivered targets are skipped on retry.
+func newNotificationDeliveryError(failedRecipients []string) error {
+	return temporal.NewApplicationError(
+		fmt.Sprintf("failed to deliver notifications to: %s", strings.Join(failedRecipients, ", ")),
+		"NotificationDeliveryError",
+		failedRecipients,
+	)
+}
+
 func hasMatchingProvider(notificationReq thandFunction.NotifierRequest, notifierProviders map[string]models.Provider) bool {
 
 	// filter out providers to see if the name matches
@@ -203,7 +326,7 @@ func (t *thandTask) executeNotifyTemporalParallel(
 	notifyTasks []notifyTask,
 ) ([]notifyResult, error) {
 
-	logrus.WithFields(logrus.Fields{
+	getLogger(workflowTask).WithFields(logrus.Fields{
 		"taskName":  taskName,
 		"taskCount": len(notifyTasks),
 	}).Info("Starting executeNotifyTemporalParallel")
@@ -231,7 +354,7 @@ func (t *thandTask) executeNotifyTemporalParallel(
 		taskIndex := i
 		notifyTask := task
 
-		logrus.WithFields(logrus.Fields{
+		getLogger(workflowTask).WithFields(logrus.Fields{
 			"taskIndex": taskIndex,
 			"recipient": notifyTask.Recipient,
 			"provider":  notifyTask.Provider,
@@ -244,6 +367,8 @@ func (t *thandTask) executeNotifyTemporalParallel(
 				"activityName", thandFunction.ThandNotifyFunction,
 			)
 
+			var receipt models.NotificationReceipt
+
 			err := workflow.ExecuteActivity(
 				aoctx,
 				thandFunction.ThandNotifyFunction,
@@ -251,7 +376,7 @@ func (t *thandTask) executeNotifyTemporalParallel(
 				taskName,
 				notifyTask.CallFunc,
 				notifyTask.Payload,
-			).Get(ctx, nil)
+			).Get(ctx, &receipt)
 
 			log.Info("Activity completed",
 				"recipient", notifyTask.Recipient,
@@ -260,9 +385,13 @@ func (t *thandTask) executeNotifyTemporalParallel(
 
 			// Send result through channel
 			resultCh.Send(ctx, temporalNotifyResult{
-				Index:     taskIndex,
-				Recipient: notifyTask.Recipient,
-				Err:       err,
+				Index:          taskIndex,
+				NotificationID: notifyTask.NotificationID,
+				Recipient:      notifyTask.Recipient,
+				Receipt: models.NewNotificationReceipt(
+					notifyTask.NotificationID, notifyTask.Provider, notifyTask.Recipient,
+					&receipt, err, workflow.Now(ctx)),
+				Err: err,
 			})
 		})
 	}
@@ -272,8 +401,10 @@ func (t *thandTask) executeNotifyTemporalParallel(
 		var result temporalNotifyResult
 		resultCh.Receive(temporalContext, &result)
 		results[result.Index] = notifyResult{
-			Recipient: result.Recipient,
-			Error:     result.Err,
+			NotificationID: result.NotificationID,
+			Recipient:      result.Recipient,
+			Receipt:        result.Receipt,
+			Error:          result.Err,
 		}
 	}
 
@@ -295,25 +426,45 @@ func (t *thandTask) executeNotifyGoParallel(
 		go func(index int, notifyTask notifyTask) {
 			defer wg.Done()
 
+			var receipt *models.NotificationReceipt
+
 			// Get provider config
 			providerConfig, err := t.config.Providers.GetProviderByName(notifyTask.Provider)
+
+			var notifier models.ProviderNotifier
+
+			if err == nil {
+				notifier, err = providerConfig.GetNotifier()
+			}
+
+			var payload any
+
 			if err != nil {
-				results[index] = notifyResult{
-					Recipient: notifyTask.Recipient,
-					Error:     fmt.Errorf("failed to get provider: %w", err),
+				err = fmt.Errorf("failed to get provider: %w", err)
+			} else if payload, err = models.ResolveSecretReferences(notifyTask.Payload); err != nil {
+				// Secret references from the workflow vars are only
+				// resolved when sending, as the activity does
+				err = fmt.Errorf("failed to resolve notification payload: %w", err)
+			} else if t.config.GetNotificationDigests().Add(notifyTask.Provider, payload.(models.NotificationRequest)) {
+				// Targets in digest mode are sent later as a single message
+				receipt = &models.NotificationReceipt{
+					Status: models.NotificationDeliveryDigested,
 				}
-				return
+			} else {
+				// Send notification
+				receipt, err = notifier.SendNotification(
+					workflowTask.GetContext(),
+					payload.(models.NotificationRequest),
+				)
 			}
 
-			// Send notification
-			err = providerConfig.GetClient().SendNotification(
-				workflowTask.GetContext(),
-				notifyTask.Payload,
-			)
-
 			results[index] = notifyResult{
-				Recipient: notifyTask.Recipient,
-				Error:     err,
+				NotificationID: notifyTask.NotificationID,
+				Recipient:      notifyTask.Recipient,
+				Receipt: models.NewNotificationReceipt(
+					notifyTask.NotificationID, notifyTask.Provider, notifyTask.Recipient,
+					receipt, err, time.Now()),
+				Error: err,
 			}
 		}(i, task)
 	}
@@ -325,19 +476,20 @@ func (t *thandTask) executeNotifyGoParallel(
 
 // processNotificationResults processes notification results and logs errors/successes.
 // Returns an error if all notifications failed, otherwise logs warnings for partial failures.
-func processNotificationResults(results []notifyResult, notificationType string) error {
+func processNotificationResults(workflowTask *models.WorkflowTask, results []notifyResult, notificationType string) error {
+	log := getLogger(workflowTask)
 	hasErrors := false
 	successCount := 0
 
 	for _, result := range results {
 		if result.Error != nil {
-			logrus.WithError(result.Error).
+			log.WithError(result.Error).
 				WithField("recipient", result.Recipient).
 				Error(fmt.Sprintf("%s failed", notificationType))
 			hasErrors = true
 		} else {
 			successCount++
-			logrus.WithField("recipient", result.Recipient).
+			log.WithField("recipient", result.Recipient).
 				Info(fmt.Sprintf("%s sent successfully", notificationType))
 		}
 	}
@@ -347,7 +499,7 @@ func processNotificationResults(results []notifyResult, notificationType string)
 	}
 
 	if hasErrors {
-		logrus.WithFields(logrus.Fields{
+		log.WithFields(logrus.Fields{
 			"success": successCount,
 			"total":   len(results),
 		}).Warn(fmt.Sprintf("Some %s requests failed", notificationType))
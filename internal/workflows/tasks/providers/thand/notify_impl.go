@@ -9,6 +9,8 @@ import (
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/models"
 	emailProvider "github.com/thand-io/agent/internal/providers/email"
+	jiraProvider "github.com/thand-io/agent/internal/providers/jira"
+	opsgenieProvider "github.com/thand-io/agent/internal/providers/opsgenie"
 	slackProvider "github.com/thand-io/agent/internal/providers/slack"
 	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
 )
@@ -20,6 +22,13 @@ type NotifierImpl interface {
 	GetPayload(toIdentity *models.Identity) models.NotificationRequest
 }
 
+// DigestNotifierImpl is implemented by notifiers whose notifications can be
+// batched into a digest for targets in digest mode
+type DigestNotifierImpl interface {
+	GetDigest() *models.NotificationDigest
+	GetDigestEntry(toIdentity *models.Identity) *models.NotificationDigestEntry
+}
+
 type defaultNotifierImpl struct {
 	req thandFunction.NotifierRequest
 }
@@ -30,6 +39,52 @@ func NewDefaultNotifierImpl(req thandFunction.NotifierRequest) NotifierImpl {
 	}
 }
 
+// individualNotifierImpl renders the notification for each recipient with
+// their own data and locale
+type individualNotifierImpl struct {
+	*defaultNotifierImpl
+	data     map[string]any
+	fallback thandFunction.NotifierRequest
+}
+
+// NewIndividualNotifierImpl creates a notifier that renders the request's
+// templates for each recipient against the template data. The fallback is
+// sent if a recipient's message fails to render.
+func NewIndividualNotifierImpl(
+	req thandFunction.NotifierRequest,
+	data map[string]any,
+	fallback thandFunction.NotifierRequest,
+) NotifierImpl {
+	return &individualNotifierImpl{
+		defaultNotifierImpl: &defaultNotifierImpl{req: req},
+		data:                data,
+		fallback:            fallback,
+	}
+}
+
+// getRecipientNotifier returns the notifier with the request rendered for
+// the recipient
+func (d *individualNotifierImpl) getRecipientNotifier(toIdentity *models.Identity) *defaultNotifierImpl {
+
+	req, err := renderRecipientNotificationTemplate(d.req, d.data, toIdentity)
+
+	if err != nil {
+		logrus.WithError(err).WithField("recipient", toIdentity.ID).
+			Error("Failed to render notification for recipient, sending the default message")
+		req = d.fallback
+	}
+
+	return &defaultNotifierImpl{req: req}
+}
+
+func (d *individualNotifierImpl) GetPayload(toIdentity *models.Identity) models.NotificationRequest {
+	return d.getRecipientNotifier(toIdentity).GetPayload(toIdentity)
+}
+
+func (d *individualNotifierImpl) GetDigestEntry(toIdentity *models.Identity) *models.NotificationDigestEntry {
+	return d.getRecipientNotifier(toIdentity).GetDigestEntry(toIdentity)
+}
+
 func (d *defaultNotifierImpl) GetRecipients() []string {
 	return d.req.To
 }
@@ -51,12 +106,26 @@ func (d *defaultNotifierImpl) GetProviderName() string {
 	return d.req.Provider
 }
 
+func (d *defaultNotifierImpl) GetDigest() *models.NotificationDigest {
+	return d.req.GetDigest()
+}
+
+func (d *defaultNotifierImpl) GetDigestEntry(toIdentity *models.Identity) *models.NotificationDigestEntry {
+	return &models.NotificationDigestEntry{
+		Summary: d.req.Message,
+	}
+}
+
 func (d *defaultNotifierImpl) GetPayload(toIdentity *models.Identity) models.NotificationRequest {
 
 	if strings.Compare(d.GetProviderName(), slackProvider.SlackProviderName) == 0 {
 		return d.GetSlackPayload(toIdentity)
 	} else if strings.HasPrefix(d.GetProviderName(), emailProvider.EmailProviderName) {
 		return d.GetEmailPayload(toIdentity)
+	} else if strings.Compare(d.GetProviderName(), opsgenieProvider.OpsGenieProviderName) == 0 {
+		return d.GetOpsGeniePayload(toIdentity)
+	} else if strings.Compare(d.GetProviderName(), jiraProvider.JiraProviderName) == 0 {
+		return d.GetJiraPayload(toIdentity)
 	} else {
 		return models.NotificationRequest{}
 	}
@@ -67,8 +136,13 @@ func (d *defaultNotifierImpl) GetEmailPayload(toIdentity *models.Identity) model
 
 	notificationReq := d.req
 
+	subject := notificationReq.Subject
+	if len(subject) == 0 {
+		subject = "Workflow Notification"
+	}
+
 	// Render HTML email using template
-	html, err := RenderEmail("Workflow Notification", notificationReq.Message)
+	html, err := RenderEmail(subject, notificationReq.Message)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to render email template")
 		// Fallback to plain message if template fails
@@ -78,7 +152,7 @@ func (d *defaultNotifierImpl) GetEmailPayload(toIdentity *models.Identity) model
 
 	emailReq := models.EmailNotificationRequest{
 		To:      []string{toIdentity.GetEmail()},
-		Subject: "Workflow Notification",
+		Subject: subject,
 		Body: models.EmailNotificationBody{
 			Text: notificationReq.Message,
 			HTML: html,
@@ -96,6 +170,73 @@ func (d *defaultNotifierImpl) GetEmailPayload(toIdentity *models.Identity) model
 	return notificationPayload
 }
 
+// GetOpsGeniePayload opens an alert for the recipient e.g. an on-call
+// schedule or a user's email
+func (d *defaultNotifierImpl) GetOpsGeniePayload(toIdentity *models.Identity) models.NotificationRequest {
+
+	notificationReq := d.req
+
+	message := notificationReq.Subject
+	if len(message) == 0 {
+		message = notificationReq.Message
+	}
+
+	opsGenieReq := opsgenieProvider.OpsGenieNotificationRequest{
+		Message:     message,
+		Description: notificationReq.Message,
+		Priority:    notificationReq.Priority,
+		Responders:  []string{toIdentity.ID},
+	}
+
+	var notificationPayload models.NotificationRequest
+	err := common.ConvertInterfaceToInterface(opsGenieReq, &notificationPayload)
+
+	if err != nil {
+		logrus.WithError(err).Error("Failed to convert OpsGenie request")
+		return models.NotificationRequest{}
+	}
+
+	return notificationPayload
+}
+
+// GetJiraPayload comments on the recipient when it is an issue e.g.
+// SEC-123, otherwise an issue is created in the recipient project e.g. SEC
+func (d *defaultNotifierImpl) GetJiraPayload(toIdentity *models.Identity) models.NotificationRequest {
+
+	notificationReq := d.req
+
+	summary := notificationReq.Subject
+	if len(summary) == 0 {
+		summary = notificationReq.Message
+	}
+
+	jiraReq := jiraProvider.JiraNotificationRequest{
+		Summary:     summary,
+		Description: notificationReq.Message,
+	}
+
+	// Jira's priorities are configurable so only high priority is mapped
+	if strings.EqualFold(notificationReq.Priority, models.NotificationPriorityHigh) {
+		jiraReq.Priority = notificationReq.Priority
+	}
+
+	if jiraProvider.IsIssueKey(toIdentity.ID) {
+		jiraReq.TicketID = toIdentity.ID
+	} else {
+		jiraReq.Project = toIdentity.ID
+	}
+
+	var notificationPayload models.NotificationRequest
+	err := common.ConvertInterfaceToInterface(jiraReq, &notificationPayload)
+
+	if err != nil {
+		logrus.WithError(err).Error("Failed to convert Jira request")
+		return models.NotificationRequest{}
+	}
+
+	return notificationPayload
+}
+
 func (d *defaultNotifierImpl) GetSlackPayload(toIdentity *models.Identity) models.NotificationRequest {
 
 	notificationReq := d.req
@@ -0,0 +1,215 @@
+package thand
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
+)
+
+// renderNotificationTemplate renders the message and subject of the
+// notification against the workflow context so definitions can use
+// e.g. {{.user.name}}, {{.role.name}} and {{.duration}}. The message comes
+// from the inline template, or the template file, when either is set.
+func renderNotificationTemplate(notifyReq *thandFunction.NotifierRequest, workflowContext any) error {
+
+	data, err := newNotificationTemplateData(workflowContext)
+	if err != nil {
+		return err
+	}
+
+	return renderNotificationTemplateData(notifyReq, data, notifyReq.Locale)
+}
+
+// newNotificationTemplateData converts the workflow context into the data
+// the templates are rendered against
+func newNotificationTemplateData(workflowContext any) (map[string]any, error) {
+
+	// The context holds structs as well as maps so round trip it to get
+	// the json field names the templates use
+	var data map[string]any
+	if err := common.ConvertInterfaceToInterface(workflowContext, &data); err != nil {
+		return nil, fmt.Errorf("failed to convert workflow context for template: %w", err)
+	}
+
+	return data, nil
+}
+
+func renderNotificationTemplateData(notifyReq *thandFunction.NotifierRequest, data map[string]any, locale string) error {
+
+	if notifyReq.HasTemplate() {
+
+		body, err := getNotificationTemplate(notifyReq, locale)
+		if err != nil {
+			return err
+		}
+
+		message, err := executeNotificationTemplate("message", body, data)
+		if err != nil {
+			return err
+		}
+
+		notifyReq.Message = message
+	}
+
+	if strings.Contains(notifyReq.Subject, "{{") {
+
+		subject, err := executeNotificationTemplate("subject", notifyReq.Subject, data)
+		if err != nil {
+			return err
+		}
+
+		notifyReq.Subject = subject
+	}
+
+	return nil
+}
+
+// renderRecipientNotificationTemplate renders the notification for a single
+// recipient. The recipient is available to the template as {{.recipient}}
+// and their data from recipients is merged over the workflow context. The
+// template variant for the recipient's locale is used when there is one.
+func renderRecipientNotificationTemplate(
+	notifyReq thandFunction.NotifierRequest,
+	data map[string]any,
+	recipient *models.Identity,
+) (thandFunction.NotifierRequest, error) {
+
+	recipientData := getRecipientTemplateData(&notifyReq, recipient)
+
+	merged := make(map[string]any, len(data)+len(recipientData)+1)
+	maps.Copy(merged, data)
+
+	if recipient != nil && recipient.User != nil {
+		var user map[string]any
+		if err := common.ConvertInterfaceToInterface(recipient.User, &user); err == nil {
+			merged["recipient"] = user
+		}
+	}
+
+	maps.Copy(merged, recipientData)
+
+	locale := getRecipientLocale(&notifyReq, recipientData, recipient)
+
+	if err := renderNotificationTemplateData(&notifyReq, merged, locale); err != nil {
+		return notifyReq, err
+	}
+
+	return notifyReq, nil
+}
+
+// getRecipientTemplateData returns the data set for the recipient, keyed by
+// their to entry or their email address
+func getRecipientTemplateData(notifyReq *thandFunction.NotifierRequest, recipient *models.Identity) map[string]any {
+
+	if recipient == nil || len(notifyReq.Recipients) == 0 {
+		return nil
+	}
+
+	if data, found := notifyReq.Recipients[recipient.ID]; found {
+		return data
+	}
+
+	if data, found := notifyReq.Recipients[recipient.GetEmail()]; found {
+		return data
+	}
+
+	return nil
+}
+
+// getRecipientLocale returns the locale set in the recipient's data, then
+// the locale of their user and finally the notification's default locale
+func getRecipientLocale(
+	notifyReq *thandFunction.NotifierRequest,
+	recipientData map[string]any,
+	recipient *models.Identity,
+) string {
+
+	if locale, ok := recipientData["locale"].(string); ok && len(locale) > 0 {
+		return locale
+	}
+
+	if recipient != nil && recipient.User != nil && len(recipient.User.Locale) > 0 {
+		return recipient.User.Locale
+	}
+
+	return notifyReq.Locale
+}
+
+// getNotificationTemplate returns the inline template, or the contents of
+// the template file. For template files the variant for the locale is used
+// when it exists e.g. access-granted.de.tmpl for de, falling back to
+// access-granted.tmpl.
+func getNotificationTemplate(notifyReq *thandFunction.NotifierRequest, locale string) (string, error) {
+
+	if len(notifyReq.Template) > 0 {
+		return notifyReq.Template, nil
+	}
+
+	for _, path := range getLocalizedTemplateFiles(notifyReq.TemplateFile, locale) {
+
+		contents, err := os.ReadFile(path)
+
+		if errors.Is(err, fs.ErrNotExist) && path != notifyReq.TemplateFile {
+			continue
+		} else if err != nil {
+			return "", fmt.Errorf("failed to read template file %s: %w", path, err)
+		}
+
+		return string(contents), nil
+	}
+
+	return "", fmt.Errorf("failed to read template file %s", notifyReq.TemplateFile)
+}
+
+// getLocalizedTemplateFiles returns the template files to try for the
+// locale, most specific first e.g. for de-AT access-granted.de-AT.tmpl,
+// access-granted.de.tmpl and then access-granted.tmpl
+func getLocalizedTemplateFiles(path string, locale string) []string {
+
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+
+	if len(locale) == 0 {
+		return []string{path}
+	}
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+
+	locales := []string{locale}
+
+	if language, _, found := strings.Cut(locale, "-"); found && len(language) > 0 {
+		locales = append(locales, language)
+	}
+
+	files := make([]string, 0, len(locales)+1)
+
+	for _, variant := range locales {
+		files = append(files, fmt.Sprintf("%s.%s%s", base, variant, ext))
+	}
+
+	return append(files, path)
+}
+
+func executeNotificationTemplate(name string, body string, data map[string]any) (string, error) {
+
+	tmpl, err := template.New(name).Parse(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse notification %s template: %w", name, err)
+	}
+
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("failed to render notification %s template: %w", name, err)
+	}
+
+	return rendered.String(), nil
+}
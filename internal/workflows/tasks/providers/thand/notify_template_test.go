@@ -0,0 +1,140 @@
+package thand
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
+)
+
+func TestRenderNotificationTemplate(t *testing.T) {
+
+	workflowContext := map[string]any{
+		"user": &models.User{
+			Name:  "Alice",
+			Email: "alice@example.com",
+		},
+		"role": map[string]any{
+			"name": "admin",
+		},
+		"duration": "PT4H",
+	}
+
+	t.Run("inline template", func(t *testing.T) {
+		notifyReq := thandFunction.NotifierRequest{
+			Message:  "ignored",
+			Subject:  "Access for {{.user.name}}",
+			Template: "{{.user.name}} was granted {{.role.name}} for {{.duration}}",
+		}
+
+		require.NoError(t, renderNotificationTemplate(&notifyReq, workflowContext))
+		assert.Equal(t, "Alice was granted admin for PT4H", notifyReq.Message)
+		assert.Equal(t, "Access for Alice", notifyReq.Subject)
+	})
+
+	t.Run("template file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "granted.tmpl")
+		require.NoError(t, os.WriteFile(path, []byte("Hello {{.user.email}}"), 0o600))
+
+		notifyReq := thandFunction.NotifierRequest{
+			TemplateFile: path,
+		}
+
+		require.NoError(t, renderNotificationTemplate(&notifyReq, workflowContext))
+		assert.Equal(t, "Hello alice@example.com", notifyReq.Message)
+	})
+
+	t.Run("static message", func(t *testing.T) {
+		notifyReq := thandFunction.NotifierRequest{
+			Message: "Access granted",
+			Subject: "Access Granted",
+		}
+
+		require.NoError(t, renderNotificationTemplate(&notifyReq, workflowContext))
+		assert.Equal(t, "Access granted", notifyReq.Message)
+		assert.Equal(t, "Access Granted", notifyReq.Subject)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		notifyReq := thandFunction.NotifierRequest{
+			Template: "{{.user.name",
+		}
+
+		assert.Error(t, renderNotificationTemplate(&notifyReq, workflowContext))
+	})
+
+	t.Run("missing template file", func(t *testing.T) {
+		notifyReq := thandFunction.NotifierRequest{
+			TemplateFile: filepath.Join(t.TempDir(), "missing.tmpl"),
+		}
+
+		assert.Error(t, renderNotificationTemplate(&notifyReq, workflowContext))
+	})
+}
+
+func TestRenderRecipientNotificationTemplate(t *testing.T) {
+
+	dir := t.TempDir()
+	templateFile := filepath.Join(dir, "access-granted.tmpl")
+
+	require.NoError(t, os.WriteFile(templateFile, []byte("Hi {{.recipient.name}}, {{.role.name}} for {{.team}}"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "access-granted.de.tmpl"), []byte("Hallo {{.recipient.name}}, {{.role.name}} für {{.team}}"), 0o600))
+
+	data := map[string]any{
+		"role": map[string]any{"name": "admin"},
+		"team": "Everyone",
+	}
+
+	notifyReq := thandFunction.NotifierRequest{
+		TemplateFile: templateFile,
+		Subject:      "Access for {{.recipient.email}}",
+		Individual:   true,
+		Recipients: map[string]map[string]any{
+			"alice": {"locale": "de-AT", "team": "Platform"},
+		},
+	}
+
+	t.Run("recipient data and locale variant", func(t *testing.T) {
+		rendered, err := renderRecipientNotificationTemplate(notifyReq, data, &models.Identity{
+			ID:   "alice",
+			User: &models.User{Name: "Alice", Email: "alice@example.com"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Hallo Alice, admin für Platform", rendered.Message)
+		assert.Equal(t, "Access for alice@example.com", rendered.Subject)
+	})
+
+	t.Run("user locale", func(t *testing.T) {
+		rendered, err := renderRecipientNotificationTemplate(notifyReq, data, &models.Identity{
+			ID:   "bob@example.com",
+			User: &models.User{Name: "Bob", Email: "bob@example.com", Locale: "de"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Hallo Bob, admin für Everyone", rendered.Message)
+	})
+
+	t.Run("falls back to the default template", func(t *testing.T) {
+		rendered, err := renderRecipientNotificationTemplate(notifyReq, data, &models.Identity{
+			ID:   "carol@example.com",
+			User: &models.User{Name: "Carol", Email: "carol@example.com", Locale: "fr"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Hi Carol, admin for Everyone", rendered.Message)
+	})
+
+	t.Run("leaves the request untouched", func(t *testing.T) {
+		assert.Empty(t, notifyReq.Message)
+		assert.Equal(t, "Access for {{.recipient.email}}", notifyReq.Subject)
+	})
+}
+
+func TestGetLocalizedTemplateFiles(t *testing.T) {
+	assert.Equal(t, []string{"granted.tmpl"}, getLocalizedTemplateFiles("granted.tmpl", ""))
+	assert.Equal(t, []string{"t/granted.de.tmpl", "t/granted.tmpl"}, getLocalizedTemplateFiles("t/granted.tmpl", "de"))
+	assert.Equal(t, []string{"granted.de-AT.tmpl", "granted.de.tmpl", "granted.tmpl"}, getLocalizedTemplateFiles("granted.tmpl", "de_AT"))
+	assert.Equal(t, []string{"access-granted.de", "access-granted"}, getLocalizedTemplateFiles("access-granted", "de"))
+}
@@ -0,0 +1,258 @@
+package thand
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
+)
+
+// flakyNotifier fails for a recipient a set number of times before it
+// succeeds and counts every send attempt
+type flakyNotifier struct {
+	*models.BaseProvider
+
+	mu       sync.Mutex
+	failures map[string]int
+	attempts map[string]int
+	ids      map[string]string
+	texts    map[string]string
+}
+
+func (n *flakyNotifier) SendNotification(ctx context.Context, notification models.NotificationRequest) (*models.NotificationReceipt, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	recipient, _ := notification["channel"].(string)
+
+	n.attempts[recipient]++
+	n.ids[recipient] = notification.GetNotificationID()
+
+	if n.texts != nil {
+		n.texts[recipient], _ = notification["text"].(string)
+	}
+
+	if n.failures[recipient] > 0 {
+		n.failures[recipient]--
+		return nil, errors.New("temporary failure")
+	}
+
+	return &models.NotificationReceipt{
+		Target:    recipient,
+		MessageID: fmt.Sprintf("%s:%d", recipient, n.attempts[recipient]),
+	}, nil
+}
+
+func newNotifyTestTask(t *testing.T, notifier *flakyNotifier) *thandTask {
+	t.Helper()
+
+	provider := models.Provider{
+		Name:     "slack",
+		Provider: "slack",
+		Enabled:  true,
+	}
+
+	notifier.BaseProvider = models.NewBaseProvider(
+		"slack", provider, models.ProviderCapabilityNotifier)
+	provider.SetClient(notifier)
+
+	cfg := &config.Config{
+		Providers: config.ProviderConfig{
+			Definitions: map[string]models.Provider{
+				"slack": provider,
+			},
+		},
+	}
+
+	return NewThandTask(cfg)
+}
+
+func TestExecuteNotify_RetriesOnlyUndelivered(t *testing.T) {
+
+	notifier := &flakyNotifier{
+		failures: map[string]int{"flaky@example.com": 2},
+		attempts: map[string]int{},
+		ids:      map[string]string{},
+	}
+
+	task := newNotifyTestTask(t, notifier)
+
+	workflowTask := &models.WorkflowTask{
+		WorkflowID: "workflow-123",
+		Context:    map[string]any{},
+	}
+
+	notify := NewDefaultNotifierImpl(thandFunction.NotifierRequest{
+		Provider: "slack",
+		To:       []string{"approver@example.com", "flaky@example.com", "requester@example.com"},
+		Message:  "Access requested",
+	})
+
+	// The first two attempts fail for the flaky recipient only
+	for attempt := 1; attempt <= 2; attempt++ {
+		_, err := task.executeNotify(workflowTask, "notify_approvers", notify)
+		require.Error(t, err, "attempt %d", attempt)
+		assert.Contains(t, err.Error(), "flaky@example.com")
+		assert.NotContains(t, err.Error(), "approver@example.com")
+		assert.NotContains(t, err.Error(), "requester@example.com")
+	}
+
+	// The retry succeeds and only resends to the flaky recipient
+	_, err := task.executeNotify(workflowTask, "notify_approvers", notify)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, notifier.attempts["approver@example.com"])
+	assert.Equal(t, 1, notifier.attempts["requester@example.com"])
+	assert.Equal(t, 3, notifier.attempts["flaky@example.com"])
+
+	// Once everything is delivered nothing else is sent
+	_, err = task.executeNotify(workflowTask, "notify_approvers", notify)
+	require.NoError(t, err)
+	assert.Equal(t, 3, notifier.attempts["flaky@example.com"])
+
+	deliveries := getNotificationDeliveries(workflowTask)
+	require.Len(t, deliveries, 3)
+
+	flakyID := models.NewNotificationID("workflow-123", "notify_approvers", "slack", "flaky@example.com")
+	assert.Equal(t, flakyID, notifier.ids["flaky@example.com"])
+
+	flakyDelivery := deliveries[flakyID]
+	assert.True(t, flakyDelivery.IsDelivered())
+	assert.Equal(t, 3, flakyDelivery.Attempts)
+	assert.Empty(t, flakyDelivery.Error)
+
+	// Every attempt has a receipt, failures record the error
+	require.Len(t, flakyDelivery.Receipts, 3)
+	assert.Equal(t, models.NotificationDeliveryFailed, flakyDelivery.Receipts[0].Status)
+	assert.Equal(t, "temporary failure", flakyDelivery.Receipts[0].Error)
+	assert.Equal(t, models.NotificationDeliveryDelivered, flakyDelivery.Receipts[2].Status)
+	assert.Equal(t, "flaky@example.com:3", flakyDelivery.Receipts[2].MessageID)
+	assert.Equal(t, flakyID, flakyDelivery.Receipts[2].NotificationID)
+	assert.Equal(t, "slack", flakyDelivery.Receipts[2].Provider)
+	assert.False(t, flakyDelivery.Receipts[2].Timestamp.IsZero())
+
+	assert.Len(t, workflowTask.GetNotificationReceipts(), 5)
+
+	for _, delivery := range deliveries {
+		assert.True(t, delivery.IsDelivered(), delivery.Recipient)
+	}
+}
+
+func TestExecuteNotify_Individual(t *testing.T) {
+
+	notifier := &flakyNotifier{
+		failures: map[string]int{"flaky@example.com": 1},
+		attempts: map[string]int{},
+		ids:      map[string]string{},
+		texts:    map[string]string{},
+	}
+
+	task := newNotifyTestTask(t, notifier)
+
+	workflowTask := &models.WorkflowTask{
+		WorkflowID: "workflow-123",
+		Context:    map[string]any{},
+	}
+
+	notifyReq := thandFunction.NotifierRequest{
+		Provider:   "slack",
+		To:         []string{"alice@example.com", "flaky@example.com", "bob@example.com"},
+		Template:   "Hi {{.recipient.email}}, you are on {{.team}}",
+		Individual: true,
+		Recipients: map[string]map[string]any{
+			"alice@example.com": {"team": "Platform"},
+		},
+	}
+
+	templateData := map[string]any{"team": "Security"}
+
+	notify := NewIndividualNotifierImpl(notifyReq, templateData, notifyReq)
+
+	// A failed recipient doesn't stop the others being sent
+	_, err := task.executeNotify(workflowTask, "notify_team", notify)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "flaky@example.com")
+
+	assert.Equal(t, "Hi alice@example.com, you are on Platform", notifier.texts["alice@example.com"])
+	assert.Equal(t, "Hi bob@example.com, you are on Security", notifier.texts["bob@example.com"])
+
+	// The results are recorded for each recipient
+	deliveries := getNotificationDeliveries(workflowTask)
+	require.Len(t, deliveries, 3)
+
+	for _, delivery := range deliveries {
+		if delivery.Recipient == "flaky@example.com" {
+			assert.Equal(t, models.NotificationDeliveryFailed, delivery.Status)
+		} else {
+			assert.True(t, delivery.IsDelivered(), delivery.Recipient)
+		}
+	}
+}
+
+func TestNewNotificationID(t *testing.T) {
+	id := models.NewNotificationID("workflow-123", "notify", "slack", "user@example.com")
+
+	assert.Equal(t, id, models.NewNotificationID("workflow-123", "notify", "slack", "user@example.com"))
+	assert.NotEqual(t, id, models.NewNotificationID("workflow-123", "notify", "slack", "other@example.com"))
+	assert.NotEqual(t, id, models.NewNotificationID("workflow-456", "notify", "slack", "user@example.com"))
+}
+
+func TestExecuteNotify_SecretVars(t *testing.T) {
+
+	models.RegisterSecretResolver("notifytest", func(config *models.BasicConfig, reference string) (string, error) {
+		return "s3cr3t-token", nil
+	})
+
+	notifier := &flakyNotifier{
+		attempts: map[string]int{},
+		ids:      map[string]string{},
+		texts:    map[string]string{},
+	}
+
+	task := newNotifyTestTask(t, notifier)
+
+	workflowTask := &models.WorkflowTask{
+		WorkflowID: "workflow-123",
+		Context: map[string]any{
+			models.VarsContextVars: map[string]any{
+				"approvals_channel": "#access",
+				"webhook_token":     "notifytest://slack#token",
+			},
+		},
+	}
+
+	with, err := workflowTask.TraverseAndEvaluate(map[string]any{
+		"to":      "${ $context.vars.approvals_channel }",
+		"message": "${ $context.vars.webhook_token }",
+	}, nil)
+	require.NoError(t, err)
+
+	withMap := with.(map[string]any)
+	assert.Equal(t, "notifytest://slack#token", withMap["message"])
+
+	notify := NewDefaultNotifierImpl(thandFunction.NotifierRequest{
+		Provider: "slack",
+		To:       []string{withMap["to"].(string)},
+		Message:  withMap["message"].(string),
+	})
+
+	_, err = task.executeNotify(workflowTask, "notify_approvers", notify)
+	require.NoError(t, err)
+
+	// The secret is resolved when sending
+	assert.Equal(t, "s3cr3t-token", notifier.texts["#access"])
+
+	// but never recorded in the workflow
+	recorded, err := json.Marshal(workflowTask)
+	require.NoError(t, err)
+	assert.NotContains(t, string(recorded), "s3cr3t-token")
+	assert.Contains(t, string(recorded), "notifytest://slack#token")
+}
@@ -0,0 +1,150 @@
+package thand
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
+	taskModel "github.com/thand-io/agent/internal/workflows/tasks/model"
+	"go.temporal.io/sdk/workflow"
+)
+
+const ThandPolicyTask = "policy"
+
+// executePolicyTask evaluates the guardrail policies and routes the
+// workflow based on the decision
+//
+//	policy:
+//	  thand: policy
+//	  on:
+//	    denied: denied
+//	    approval: approvals
+//	    allowed: authorize
+//
+// A deny without a denied state fails the workflow with the policy
+// messages. require_approval without an approval state continues as
+// allowed, any approvers are still added to later approvals tasks.
+func (t *thandTask) executePolicyTask(
+	workflowTask *models.WorkflowTask,
+	taskName string,
+	call *taskModel.ThandTask,
+) (any, error) {
+
+	log := workflowTask.GetLogger()
+
+	decision, err := t.evaluatePolicies(workflowTask, taskName, call)
+
+	if err != nil {
+		log.WithError(err).WithFields(models.Fields{
+			"taskName": taskName,
+		}).Error("Failed to evaluate policies")
+
+		return nil, err
+	}
+
+	log.WithFields(models.Fields{
+		"taskName":  taskName,
+		"result":    decision.Result,
+		"messages":  decision.Messages,
+		"approvers": decision.Approvers,
+	}).Info("Policy decision")
+
+	decisionMap, err := common.ConvertInterfaceToMap(decision)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert policy decision: %w", err)
+	}
+
+	workflowTask.SetContextKeyValue(models.VarsContextPolicy, decisionMap)
+	setFreezeContext(workflowTask, decision.Freeze)
+
+	deniedState, foundDeniedState := call.On.GetString("denied")
+	approvalState, foundApprovalState := call.On.GetString("approval")
+	allowedState, foundAllowedState := call.On.GetString("allowed")
+
+	switch {
+	case decision.IsDenied():
+		if !foundDeniedState {
+			return nil, fmt.Errorf("elevation request denied by policy: %s",
+				strings.Join(decision.Messages, "; "))
+		}
+		return &model.FlowDirective{Value: deniedState}, nil
+	case decision.RequiresApproval() && foundApprovalState:
+		return &model.FlowDirective{Value: approvalState}, nil
+	case foundAllowedState:
+		return &model.FlowDirective{Value: allowedState}, nil
+	}
+
+	return decisionMap, nil
+}
+
+// evaluatePolicies runs the policy function as an activity under Temporal,
+// or directly otherwise
+func (t *thandTask) evaluatePolicies(
+	workflowTask *models.WorkflowTask,
+	taskName string,
+	call *taskModel.ThandTask,
+) (*models.PolicyDecision, error) {
+
+	if !workflowTask.HasTemporalContext() {
+
+		elevationRequest, err := workflowTask.GetContextAsElevationRequest()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get elevation request from context: %w", err)
+		}
+
+		return t.config.EvaluatePolicies(
+			workflowTask.GetContext(),
+			models.NewPolicyInput(elevationRequest, time.Now()),
+		)
+	}
+
+	serviceClient := t.config.GetServices()
+
+	aoctx := workflow.WithActivityOptions(workflowTask.GetTemporalContext(), workflow.ActivityOptions{
+		TaskQueue:           serviceClient.GetTemporal().GetTaskQueue(),
+		StartToCloseTimeout: time.Minute,
+	})
+
+	var decision models.PolicyDecision
+
+	err := workflow.ExecuteActivity(
+		aoctx,
+		thandFunction.ThandPolicyFunction,
+		workflowTask,
+		taskName,
+		model.CallFunction{
+			Call: thandFunction.ThandPolicyFunction,
+			With: call.With.AsMap(),
+		},
+		nil,
+	).Get(aoctx, &decision)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &decision, nil
+}
+
+// getPolicyApprovers returns any extra approvers added by a previous
+// policy task that required approval
+func getPolicyApprovers(workflowTask *models.WorkflowTask) []string {
+
+	decision, err := models.GetContextAs[models.PolicyDecision](workflowTask, models.VarsContextPolicy)
+
+	if err != nil {
+		return nil
+	}
+
+	if !decision.RequiresApproval() {
+		return nil
+	}
+
+	return decision.Approvers
+}
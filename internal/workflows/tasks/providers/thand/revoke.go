@@ -6,9 +6,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/metrics"
 	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/siem"
 	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
 	taskModel "github.com/thand-io/agent/internal/workflows/tasks/model"
 	"go.temporal.io/sdk/temporal"
@@ -50,6 +53,54 @@ func (t *thandTask) executeRevokeTask(
 	return t.executeRevocationTask(workflowTask, taskName, call, elevateRequest, &revokeCallTask)
 }
 
+// getProviderAuthorization looks up the authorize response for an identity on a
+// provider from the provider_authorizations context. The context may either
+// hold the typed map or its JSON round-tripped form.
+func getProviderAuthorization(
+	providerAuthorizations any,
+	providerName string,
+	identity string,
+) (*models.AuthorizeRoleResponse, bool) {
+
+	switch authzMap := providerAuthorizations.(type) {
+	case map[string]map[string]*models.AuthorizeRoleResponse:
+		authResp, ok := authzMap[providerName][identity]
+		return authResp, ok
+	case map[string]any:
+		return getIdentityAuthorization(authzMap[providerName], identity)
+	}
+
+	return nil, false
+}
+
+// getIdentityAuthorization looks up the authorize response for an identity
+// from a map keyed by identity
+func getIdentityAuthorization(authorizations any, identity string) (*models.AuthorizeRoleResponse, bool) {
+
+	switch authzMap := authorizations.(type) {
+	case map[string]*models.AuthorizeRoleResponse:
+		authResp, ok := authzMap[identity]
+		return authResp, ok
+	case map[string]any:
+		identityValue, ok := authzMap[identity]
+		if !ok {
+			return nil, false
+		}
+		identityMap, ok := identityValue.(map[string]any)
+		if !ok {
+			// A nil response still means the grant succeeded
+			return nil, true
+		}
+		localResponse := models.AuthorizeRoleResponse{}
+		if err := common.ConvertMapToInterface(identityMap, &localResponse); err != nil {
+			logrus.WithError(err).WithField("identity", identity).Warn("Failed to convert authorize response")
+		}
+		return &localResponse, true
+	}
+
+	return nil, false
+}
+
 // revokeResult holds the result of a revocation operation
 type revokeResult struct {
 	Identity string
@@ -110,25 +161,28 @@ func (t *thandTask) executeRevocationTask(
 			req := workflowTask.GetContextAsMap()
 			if req != nil {
 
-				authorizationsMap, ok := req["authorizations"]
+				// Multi-provider grants record the outcome per provider. Only
+				// revoke the grants that actually succeeded.
+				if providerAuthorizations, ok := req[models.VarsContextProviderAuthorizations]; ok {
+					authResp, granted := getProviderAuthorization(providerAuthorizations, providerName, identity)
+					if !granted {
+						log.WithFields(models.Fields{
+							"identity": identity,
+							"provider": providerName,
+						}).Debug("No successful authorization for provider, skipping revocation")
+						continue
+					}
+					authorizeResponse = authResp
+				} else {
 
-				if !ok {
-					log.WithField("identity", identity).Debug("No authorizations found in context for revocation")
-					continue
-				}
+					authorizationsMap, ok := req["authorizations"]
 
-				if objectMap, ok := authorizationsMap.(map[string]any); ok {
-					if identityMap, ok := objectMap[identity].(map[string]any); ok {
-						localResponse := models.AuthorizeRoleResponse{}
-						if err := common.ConvertMapToInterface(identityMap, &localResponse); err != nil {
-							log.WithError(err).WithField("identity", identity).Warn("Failed to convert authorize response")
-						}
-						authorizeResponse = &localResponse
-					}
-				} else if authzMap, ok := authorizationsMap.(map[string]*models.AuthorizeRoleResponse); ok {
-					if authResp, ok := authzMap[identity]; ok {
-						authorizeResponse = authResp
+					if !ok {
+						log.WithField("identity", identity).Debug("No authorizations found in context for revocation")
+						continue
 					}
+
+					authorizeResponse, _ = getIdentityAuthorization(authorizationsMap, identity)
 				}
 			}
 
@@ -151,6 +205,7 @@ func (t *thandTask) executeRevocationTask(
 					User:     user,
 					Role:     elevateRequest.Role,
 					Duration: &duration,
+					Audit:    thandFunction.NewAuditContext(workflowTask, elevateRequest),
 				},
 				AuthorizeRoleResponse: authorizeResponse,
 			}
@@ -174,7 +229,7 @@ func (t *thandTask) executeRevocationTask(
 	var revokeResults []revokeResult
 
 	if workflowTask.HasTemporalContext() {
-		revokeResults, err = executeTemporalRevokeParallel(workflowTask, taskName, call, revokeTasks)
+		revokeResults, err = executeTemporalRevokeParallel(t.config, workflowTask, taskName, call, revokeTasks)
 	} else {
 		revokeResults, err = executeGoRevokeParallel(t.config, workflowTask, revokeTasks)
 	}
@@ -232,6 +287,7 @@ func (t *thandTask) executeRevocationTask(
 
 // executeTemporalRevokeParallel executes revocation tasks in parallel using Temporal
 func executeTemporalRevokeParallel(
+	config *config.Config,
 	workflowTask *models.WorkflowTask,
 	taskName string,
 	call *taskModel.ThandTask,
@@ -262,8 +318,12 @@ func executeTemporalRevokeParallel(
 				RevokeRoleRequest: revokeTask.RevokeReq,
 			}
 
+			// Revoke on the agents polling the provider's task queue
+			taskCtx := workflow.WithTaskQueue(aoctx,
+				config.GetProviderTaskQueue(revokeTask.ProviderName))
+
 			err := workflow.ExecuteActivity(
-				aoctx,
+				taskCtx,
 				thandFunction.ThandRevokeFunction,
 				workflowTask,
 				taskName,
@@ -310,7 +370,7 @@ func executeGoRevokeParallel(
 		go func(index int, revokeTask revokeTask) {
 			defer wg.Done()
 
-			providerCall, err := config.GetProviderByName(revokeTask.ProviderName)
+			rbac, err := config.GetRBAC(revokeTask.ProviderName)
 			if err != nil {
 				results[index] = revokeResult{
 					Identity: revokeTask.Identity,
@@ -320,10 +380,15 @@ func executeGoRevokeParallel(
 				return
 			}
 
-			revokeOut, err := providerCall.GetClient().RevokeRole(
+			revokeOut, err := rbac.RevokeRole(
 				workflowTask.GetContext(), &revokeTask.RevokeReq,
 			)
 
+			metrics.RecordRoleRevoke(revokeTask.ProviderName, err == nil)
+			siem.Record(siem.NewEvent(siem.EventRoleRevoked, workflowTask).
+				WithProvider(revokeTask.ProviderName, revokeTask.Identity).
+				WithError(err))
+
 			results[index] = revokeResult{
 				Identity: revokeTask.Identity,
 				Output:   revokeOut,
@@ -413,7 +478,7 @@ func (t *thandTask) makeRevocationNotifications(
 	}
 
 	// Process results using shared helper
-	if err := processNotificationResults(notifyResults, "Revocation notification"); err != nil {
+	if err := processNotificationResults(workflowTask, notifyResults, "Revocation notification"); err != nil {
 
 		log.WithError(err).WithFields(models.Fields{
 			"taskName": taskName,
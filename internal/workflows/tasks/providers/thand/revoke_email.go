@@ -3,12 +3,12 @@ package thand
 import (
 	"fmt"
 	"strings"
-
-	"github.com/sirupsen/logrus"
 )
 
 // createRevokeEmailBody creates the email body for revocation notification
 func (r *revokeNotifier) createRevokeEmailBody() (string, string) {
+	log := getLogger(r.workflowTask)
+
 	elevationReq := r.elevationReq
 	notifyReq := r.req
 
@@ -28,7 +28,7 @@ func (r *revokeNotifier) createRevokeEmailBody() (string, string) {
 	}
 
 	if len(elevationReq.Duration) > 0 {
-		plainText.WriteString(fmt.Sprintf("Duration: %s\n", elevationReq.Duration))
+		plainText.WriteString(fmt.Sprintf("Duration: %s\n", elevationReq.GetFormattedDuration()))
 	}
 
 	plainText.WriteString("\nYour access has been successfully revoked. If you need access again, please submit a new request.")
@@ -36,7 +36,7 @@ func (r *revokeNotifier) createRevokeEmailBody() (string, string) {
 	// Build data map for template
 	data := map[string]any{
 		"Providers": strings.Join(elevationReq.Providers, ", "),
-		"Duration":  elevationReq.Duration,
+		"Duration":  elevationReq.GetFormattedDuration(),
 	}
 
 	if len(notifyReq.Message) > 0 {
@@ -58,7 +58,7 @@ func (r *revokeNotifier) createRevokeEmailBody() (string, string) {
 	// Render HTML email using template
 	html, err := RenderEmailWithTemplate("Access Revoked", GetRevokeContentTemplate(), data)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to render revocation email")
+		log.WithError(err).Error("Failed to render revocation email")
 		return plainText.String(), ""
 	}
 
@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/serverlessworkflow/sdk-go/v3/model"
-	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 	"github.com/thand-io/agent/internal/common"
 	"github.com/thand-io/agent/internal/config"
@@ -67,6 +66,8 @@ func (r *revokeNotifier) GetProviderName() string {
 
 func (r *revokeNotifier) GetPayload(toIdentity *models.Identity) models.NotificationRequest {
 
+	log := getLogger(r.workflowTask)
+
 	elevationReq := r.elevationReq
 	var notificationPayload models.NotificationRequest
 
@@ -88,7 +89,7 @@ func (r *revokeNotifier) GetPayload(toIdentity *models.Identity) models.Notifica
 		}
 		err := common.ConvertInterfaceToInterface(slackReq, &notificationPayload)
 		if err != nil {
-			logrus.WithError(err).Error("Failed to convert slack request")
+			log.WithError(err).Error("Failed to convert slack request")
 			return models.NotificationRequest{}
 		}
 	} else if strings.HasPrefix(r.GetProviderName(), emailProvider.EmailProviderName) {
@@ -104,11 +105,11 @@ func (r *revokeNotifier) GetPayload(toIdentity *models.Identity) models.Notifica
 		}
 		err := common.ConvertInterfaceToInterface(emailReq, &notificationPayload)
 		if err != nil {
-			logrus.WithError(err).Error("Failed to convert email request")
+			log.WithError(err).Error("Failed to convert email request")
 			return models.NotificationRequest{}
 		}
 	} else {
-		logrus.WithField("provider", r.GetProviderName()).Error("Unsupported provider type")
+		log.WithField("provider", r.GetProviderName()).Error("Unsupported provider type")
 		return models.NotificationRequest{}
 	}
 
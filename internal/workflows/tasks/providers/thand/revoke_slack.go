@@ -71,7 +71,7 @@ func (r *revokeNotifier) addRevokeDetailsSection(blocks *[]slack.Block, elevateR
 	}
 
 	if len(elevateRequest.Duration) > 0 {
-		revokeDetailsText.WriteString(fmt.Sprintf("- *Duration:* %s\n", elevateRequest.Duration))
+		revokeDetailsText.WriteString(fmt.Sprintf("- *Duration:* %s\n", elevateRequest.GetFormattedDuration()))
 	}
 
 	*blocks = append(*blocks, slack.NewSectionBlock(
@@ -0,0 +1,126 @@
+package thand
+
+import (
+	"fmt"
+
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	taskModel "github.com/thand-io/agent/internal/workflows/tasks/model"
+)
+
+const ThandRouteTask = "route"
+
+// RouteRule sends the workflow to a task when its condition is true
+type RouteRule struct {
+	When string `json:"when"` // runtime expression e.g. ${ $context.request.duration.seconds > 14400 }
+	Then string `json:"then"` // task to continue from
+}
+
+// RouteTaskRequest is the with block of the route task
+type RouteTaskRequest struct {
+	Rules   []RouteRule `json:"rules"`
+	Default string      `json:"default,omitempty"` // task to continue from when no rule matches
+}
+
+// executeRouteTask evaluates the rules in order and routes the workflow to
+// the first whose condition is true
+//
+//	route:
+//	  thand: route
+//	  with:
+//	    rules:
+//	      - when: ${ $context.request.role.permissions | any(startswith("iam:")) }
+//	        then: security-approval
+//	      - when: ${ $context.request.duration.seconds > 14400 }
+//	        then: manager-approval
+//	    default: approvals
+//
+// Conditions that fail to evaluate, e.g. they reference a missing key, or
+// that aren't true don't match. Without a matching rule or default the
+// workflow continues with the next task. When freeze calendars are
+// configured the freeze is evaluated first so conditions can use
+// ${ $context.freeze.active } and ${ $context.freeze.labels }.
+func (t *thandTask) executeRouteTask(
+	workflowTask *models.WorkflowTask,
+	taskName string,
+	call *taskModel.ThandTask,
+	input any,
+) (any, error) {
+
+	log := workflowTask.GetLogger()
+
+	var routeReq RouteTaskRequest
+
+	if call.With != nil {
+		if err := common.ConvertInterfaceToInterface(call.With.AsMap(), &routeReq); err != nil {
+			return nil, fmt.Errorf("failed to parse route request: %w", err)
+		}
+	}
+
+	if len(routeReq.Rules) == 0 && len(routeReq.Default) == 0 {
+		return nil, fmt.Errorf("route task %s has no rules", taskName)
+	}
+
+	if _, err := t.evaluateFreeze(workflowTask, taskName); err != nil {
+		return nil, fmt.Errorf("failed to evaluate the change freeze: %w", err)
+	}
+
+	for index, rule := range routeReq.Rules {
+
+		if len(rule.When) == 0 || len(rule.Then) == 0 {
+			return nil, fmt.Errorf("route rule %d must have a when and a then", index)
+		}
+
+		if matchRouteRule(workflowTask, rule, input) {
+
+			log.WithFields(models.Fields{
+				"taskName": taskName,
+				"rule":     index,
+				"then":     rule.Then,
+			}).Info("Route rule matched")
+
+			return &model.FlowDirective{Value: rule.Then}, nil
+		}
+	}
+
+	if len(routeReq.Default) > 0 {
+
+		log.WithFields(models.Fields{
+			"taskName": taskName,
+			"then":     routeReq.Default,
+		}).Info("No route rule matched, using the default")
+
+		return &model.FlowDirective{Value: routeReq.Default}, nil
+	}
+
+	log.WithField("taskName", taskName).Info("No route rule matched, continuing")
+
+	return input, nil
+}
+
+// matchRouteRule returns true if the rule's condition evaluates to true
+func matchRouteRule(workflowTask *models.WorkflowTask, rule RouteRule, input any) bool {
+
+	log := workflowTask.GetLogger()
+
+	result, err := workflowTask.TraverseAndEvaluate(rule.When, input)
+
+	if err != nil {
+		log.WithError(err).WithField("when", rule.When).
+			Debug("Route condition failed to evaluate, treating as no match")
+		return false
+	}
+
+	matched, ok := result.(bool)
+
+	if !ok {
+		log.WithFields(models.Fields{
+			"when":   rule.When,
+			"result": result,
+		}).Debug("Route condition is not a boolean, treating as no match")
+		return false
+	}
+
+	return matched
+}
@@ -0,0 +1,175 @@
+package thand
+
+import (
+	"testing"
+
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	taskModel "github.com/thand-io/agent/internal/workflows/tasks/model"
+)
+
+func TestExecuteRouteTask(t *testing.T) {
+
+	routeTask := &taskModel.ThandTask{
+		Thand: ThandRouteTask,
+		With: &models.BasicConfig{
+			"rules": []any{
+				map[string]any{
+					"when": `${ $context.request.role.permissions | any(startswith("iam:")) }`,
+					"then": "security-approval",
+				},
+				map[string]any{
+					"when": `${ $context.request.duration.seconds > 14400 }`,
+					"then": "manager-approval",
+				},
+			},
+			"default": "approvals",
+		},
+	}
+
+	newWorkflowTask := func(permissions []string, duration string) *models.WorkflowTask {
+		workflowTask := &models.WorkflowTask{
+			WorkflowID: "workflow-123",
+		}
+		workflowTask.SetContext((&models.ElevateRequest{
+			Role: &models.Role{
+				Name: "admin",
+				Permissions: models.Permissions{
+					Allow: permissions,
+				},
+			},
+			Providers: []string{"aws-prod"},
+			Reason:    "testing",
+			Duration:  duration,
+		}).AsMap())
+		workflowTask.SetUser(&models.User{Email: "alice@example.com"})
+		workflowTask.SetRequestContext()
+		return workflowTask
+	}
+
+	tests := []struct {
+		name        string
+		permissions []string
+		duration    string
+		expected    string
+	}{
+		{
+			name:        "permission pattern",
+			permissions: []string{"s3:GetObject", "iam:PassRole"},
+			duration:    "PT1H",
+			expected:    "security-approval",
+		},
+		{
+			name:        "duration threshold",
+			permissions: []string{"s3:GetObject"},
+			duration:    "PT8H",
+			expected:    "manager-approval",
+		},
+		{
+			name:        "first matching rule wins",
+			permissions: []string{"iam:CreateUser"},
+			duration:    "PT8H",
+			expected:    "security-approval",
+		},
+		{
+			name:        "default when nothing matches",
+			permissions: []string{"s3:GetObject"},
+			duration:    "PT4H",
+			expected:    "approvals",
+		},
+		{
+			name:     "no permissions",
+			duration: "PT1H",
+			expected: "approvals",
+		},
+	}
+
+	task := &thandTask{config: &config.Config{}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := task.executeRouteTask(
+				newWorkflowTask(tt.permissions, tt.duration), "route", routeTask, nil)
+			require.NoError(t, err)
+
+			flowDirective, ok := output.(*model.FlowDirective)
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, flowDirective.Value)
+		})
+	}
+
+	t.Run("missing keys don't match", func(t *testing.T) {
+		missingTask := &taskModel.ThandTask{
+			Thand: ThandRouteTask,
+			With: &models.BasicConfig{
+				"rules": []any{
+					map[string]any{
+						"when": `${ $context.request.missing.values | any(startswith("iam:")) }`,
+						"then": "security-approval",
+					},
+					map[string]any{
+						"when": `${ $context.request.missing > 1 }`,
+						"then": "manager-approval",
+					},
+				},
+			},
+		}
+
+		input := map[string]any{"approved": true}
+
+		output, err := task.executeRouteTask(
+			newWorkflowTask([]string{"iam:PassRole"}, "PT8H"), "route", missingTask, input)
+		require.NoError(t, err)
+		assert.Equal(t, input, output)
+	})
+
+	t.Run("rules are required", func(t *testing.T) {
+		_, err := task.executeRouteTask(
+			newWorkflowTask(nil, "PT1H"), "route", &taskModel.ThandTask{Thand: ThandRouteTask}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("freeze", func(t *testing.T) {
+		freezeTask := &thandTask{config: &config.Config{
+			Freeze: models.FreezeConfig{Calendars: map[string]models.FreezeCalendar{
+				"release": {Windows: []models.FreezeWindow{
+					{Label: "Always", Start: "2000-01-01", End: "2999-12-31"},
+				}},
+			}},
+		}}
+
+		freezeRoute := &taskModel.ThandTask{
+			Thand: ThandRouteTask,
+			With: &models.BasicConfig{
+				"rules": []any{
+					map[string]any{
+						"when": `${ $context.freeze.active }`,
+						"then": "change-advisory-board",
+					},
+				},
+				"default": "approvals",
+			},
+		}
+
+		workflowTask := newWorkflowTask([]string{"s3:GetObject"}, "PT1H")
+
+		output, err := freezeTask.executeRouteTask(workflowTask, "route", freezeRoute, nil)
+		require.NoError(t, err)
+
+		flowDirective, ok := output.(*model.FlowDirective)
+		require.True(t, ok)
+		assert.Equal(t, "change-advisory-board", flowDirective.Value)
+
+		freeze, err := models.GetContextAs[models.FreezeStatus](workflowTask, models.VarsContextFreeze)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Always"}, freeze.Labels)
+
+		output, err = task.executeRouteTask(
+			newWorkflowTask([]string{"s3:GetObject"}, "PT1H"), "route", freezeRoute, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "approvals", output.(*model.FlowDirective).Value)
+	})
+}
@@ -20,6 +20,7 @@ var VALIDATOR_LLM = "llm"
 // ThandValidateTask represents a custom task for Thand validation
 func (t *thandTask) executeValidateTask(
 	workflowTask *models.WorkflowTask,
+	taskName string,
 	call *taskModel.ThandTask,
 	input any) (any, error) {
 
@@ -31,6 +32,17 @@ func (t *thandTask) executeValidateTask(
 
 	log := workflowTask.GetLogger()
 
+	// Validate the workflow input against a JSON Schema before anything
+	// else so malformed input never reaches the rest of the workflow
+	if call.With.HasString("schema") {
+
+		if err := validateAgainstSchema((*call.With)["schema"], input); err != nil {
+			return nil, err
+		}
+
+		log.Info("Input validated against schema")
+	}
+
 	// The request should always map to an Elevate Request object
 	var elevateRequest models.ElevateRequestInternal
 	if err := common.ConvertMapToInterface(req, &elevateRequest); err != nil {
@@ -45,8 +57,28 @@ func (t *thandTask) executeValidateTask(
 		return nil, errors.New("role must be provided")
 	}
 
-	if len(reason) == 0 {
-		return nil, errors.New("reason must be provided")
+	if err := t.config.CheckReason(elevateRequest.User, role, reason); err != nil {
+		return nil, err
+	}
+
+	if err := t.config.CheckDuration(elevateRequest.User, role, elevateRequest.Duration); err != nil {
+		return nil, err
+	}
+
+	if err := t.config.CheckPermissions(elevateRequest.User, role); err != nil {
+		return nil, err
+	}
+
+	// Roles and workflows can be blocked during a change freeze. Extra
+	// approvers are added by the approvals task.
+	freeze, err := t.evaluateFreeze(workflowTask, taskName)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate the change freeze: %w", err)
+	}
+
+	if freeze.IsDenied() {
+		return nil, newFreezeDeniedError(freeze)
 	}
 
 	if len(duration) == 0 {
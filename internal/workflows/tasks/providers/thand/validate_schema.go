@@ -0,0 +1,123 @@
+package thand
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+const inlineSchemaURL = "thand://validate/schema.json"
+
+// compileValidationSchema compiles the schema from the validate task. The
+// schema is either an inline JSON Schema object or a reference to a file
+// e.g. { "$ref": "file://schemas/request.json" }
+func compileValidationSchema(schema any) (*jsonschema.Schema, error) {
+
+	schemaMap, ok := schema.(map[string]any)
+
+	if !ok {
+		return nil, fmt.Errorf("schema must be an object, got: %T", schema)
+	}
+
+	// A lone $ref to a file is loaded from disk
+	if ref, ok := schemaMap["$ref"].(string); ok && len(schemaMap) == 1 && strings.HasPrefix(ref, "file://") {
+
+		path, err := filepath.Abs(strings.TrimPrefix(ref, "file://"))
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema path %s: %w", ref, err)
+		}
+
+		compiled, err := jsonschema.Compile(path)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile schema %s: %w", ref, err)
+		}
+
+		return compiled, nil
+	}
+
+	data, err := json.Marshal(schemaMap)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+
+	if err := compiler.AddResource(inlineSchemaURL, bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("failed to load schema: %w", err)
+	}
+
+	compiled, err := compiler.Compile(inlineSchemaURL)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema: %w", err)
+	}
+
+	return compiled, nil
+}
+
+// validateAgainstSchema validates the input against the schema and returns
+// an error listing every violation
+func validateAgainstSchema(schema any, input any) error {
+
+	compiled, err := compileValidationSchema(schema)
+
+	if err != nil {
+		return err
+	}
+
+	// The validator expects plain JSON values so round trip the input
+	data, err := json.Marshal(input)
+
+	if err != nil {
+		return fmt.Errorf("failed to marshal input for schema validation: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("failed to decode input for schema validation: %w", err)
+	}
+
+	err = compiled.Validate(value)
+
+	var validationErr *jsonschema.ValidationError
+	if errors.As(err, &validationErr) {
+		violations := collectSchemaViolations(validationErr)
+		return fmt.Errorf("input failed schema validation with %d violation(s):\n  - %s",
+			len(violations), strings.Join(violations, "\n  - "))
+	} else if err != nil {
+		return fmt.Errorf("failed to validate input against schema: %w", err)
+	}
+
+	return nil
+}
+
+// collectSchemaViolations flattens the validation error tree into one
+// message per failing keyword
+func collectSchemaViolations(err *jsonschema.ValidationError) []string {
+
+	if len(err.Causes) == 0 {
+		location := err.InstanceLocation
+		if len(location) == 0 {
+			location = "/"
+		}
+		return []string{fmt.Sprintf("%s: %s", location, err.Message)}
+	}
+
+	violations := []string{}
+	for _, cause := range err.Causes {
+		violations = append(violations, collectSchemaViolations(cause)...)
+	}
+
+	return violations
+}
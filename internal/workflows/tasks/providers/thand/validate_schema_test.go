@@ -0,0 +1,71 @@
+package thand
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAgainstSchema(t *testing.T) {
+
+	schema := map[string]any{
+		"type":     "object",
+		"required": []any{"ticket", "reason"},
+		"properties": map[string]any{
+			"ticket": map[string]any{
+				"type":    "string",
+				"pattern": "^INC-[0-9]+$",
+			},
+			"hours": map[string]any{
+				"type":    "integer",
+				"maximum": 8,
+			},
+		},
+	}
+
+	t.Run("valid input", func(t *testing.T) {
+		err := validateAgainstSchema(schema, map[string]any{
+			"ticket": "INC-1234",
+			"reason": "outage",
+			"hours":  4,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid input lists every violation", func(t *testing.T) {
+		err := validateAgainstSchema(schema, map[string]any{
+			"ticket": "1234",
+			"hours":  12,
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "3 violation(s)")
+		assert.Contains(t, err.Error(), "/ticket")
+		assert.Contains(t, err.Error(), "/hours")
+		assert.Contains(t, err.Error(), "reason")
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		err := validateAgainstSchema("object", map[string]any{})
+		assert.ErrorContains(t, err, "schema must be an object")
+	})
+}
+
+func TestValidateAgainstSchema_FileRef(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"type": "object",
+		"required": ["ticket"]
+	}`), 0600))
+
+	schema := map[string]any{"$ref": "file://" + path}
+
+	assert.NoError(t, validateAgainstSchema(schema, map[string]any{"ticket": "INC-1"}))
+	assert.ErrorContains(t, validateAgainstSchema(schema, map[string]any{}), "missing properties")
+
+	err := validateAgainstSchema(map[string]any{"$ref": "file://" + filepath.Join(t.TempDir(), "missing.json")}, nil)
+	assert.ErrorContains(t, err, "failed to compile schema")
+}
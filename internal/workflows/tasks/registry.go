@@ -21,9 +21,29 @@ func NewTaskRegistry(config *config.Config) *TaskRegistry {
 type TaskRegistry struct {
 	config   *config.Config
 	handlers map[string]Task
+	layer    TaskLayer
 	mu       sync.RWMutex
 }
 
+// TaskLayer sits in front of the registered handlers. It sees every task
+// the runner dispatches and can replace any of them e.g. to swap provider
+// backed tasks for mocks when simulating a workflow.
+type TaskLayer interface {
+	// GetTaskHandler returns the handler to execute the task with, given
+	// the registered handler if there is one. Returning false leaves the
+	// task to the runner.
+	GetTaskHandler(task *model.TaskItem, handler Task, exists bool) (Task, bool)
+}
+
+// SetLayer places a layer in front of the registered handlers. A nil
+// layer removes it.
+func (r *TaskRegistry) SetLayer(layer TaskLayer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.layer = layer
+}
+
 func (r *TaskRegistry) RegisterTasks(handlers ...Task) {
 	for _, handler := range handlers {
 		r.RegisterTask(handler)
@@ -50,11 +70,15 @@ func (r *TaskRegistry) RegisterTask(handler Task) {
 // GetTaskHandler retrieves a handler for a specific task type
 func (r *TaskRegistry) GetTaskHandler(taskType *model.TaskItem) (Task, bool) {
 	r.mu.RLock()
-	defer r.mu.RUnlock()
-
 	taskName := getTaskName(taskType.Task)
-
 	handler, exists := r.handlers[taskName]
+	layer := r.layer
+	r.mu.RUnlock()
+
+	if layer != nil {
+		return layer.GetTaskHandler(taskType, handler, exists)
+	}
+
 	return handler, exists
 }
 
@@ -0,0 +1,51 @@
+package workflowtesting
+
+import (
+	"testing"
+
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// LoadWorkflows parses workflow definitions in JSON or YAML and adds them
+// to the configuration, replacing any existing definitions
+func LoadWorkflows(t testing.TB, cfg *config.Config, data []byte) {
+	t.Helper()
+
+	definitions, err := common.ReadDataToInterface(data, models.WorkflowDefinitions{})
+
+	if err != nil {
+		t.Fatalf("failed to parse workflow definitions: %v", err)
+	}
+
+	cfg.Workflows.Definitions = nil
+
+	workflows, err := cfg.ApplyWorkflows([]*models.WorkflowDefinitions{definitions})
+
+	if err != nil {
+		t.Fatalf("failed to apply workflow definitions: %v", err)
+	}
+
+	cfg.Workflows.Definitions = workflows
+}
+
+// RunWorkflow simulates the named workflow for the request and fails the
+// test if the simulation can't run
+func RunWorkflow(
+	t testing.TB,
+	cfg *config.Config,
+	workflowName string,
+	request *models.ElevateRequestInternal,
+	options Options,
+) *Result {
+	t.Helper()
+
+	result, err := NewSimulator(cfg, options).Run(workflowName, request)
+
+	if err != nil {
+		t.Fatalf("failed to simulate workflow %s: %v", workflowName, err)
+	}
+
+	return result
+}
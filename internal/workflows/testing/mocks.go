@@ -0,0 +1,651 @@
+package workflowtesting
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/serverlessworkflow/sdk-go/v3/model"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	thandFunction "github.com/thand-io/agent/internal/workflows/functions/providers/thand"
+	"github.com/thand-io/agent/internal/workflows/runner"
+	"github.com/thand-io/agent/internal/workflows/tasks"
+	taskModel "github.com/thand-io/agent/internal/workflows/tasks/model"
+	thand "github.com/thand-io/agent/internal/workflows/tasks/providers/thand"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	// ErrorTypeDenied is the application error type of tasks failed by
+	// Options.DenyAt
+	ErrorTypeDenied = "SimulatedDenial"
+	// ErrorTypeTimerExpired is the application error type of tasks failed
+	// by Options.ExpireAt
+	ErrorTypeTimerExpired = "SimulatedTimerExpired"
+)
+
+// SimulatedApproverDomain is the email domain of the simulated approvers
+const SimulatedApproverDomain = "simulated.thand.io"
+
+// inProcessFunctions are the thand functions that don't call providers so
+// run as normal. Every other function call is mocked.
+var inProcessFunctions = []string{
+	thandFunction.ThandPolicyFunction,
+	thandFunction.ThandFreezeFunction,
+	thandFunction.ThandRouteFunction,
+}
+
+// mockLayer replaces the provider backed thand tasks and function calls
+// with mocks and records every task the runner dispatches
+type mockLayer struct {
+	config   *config.Config
+	options  Options
+	recorder *recorder
+
+	// approvalEvents counts the simulated approval events so each one
+	// comes from a different approver
+	approvalEvents int
+}
+
+func (l *mockLayer) GetTaskHandler(task *model.TaskItem, handler tasks.Task, exists bool) (tasks.Task, bool) {
+
+	switch t := task.Task.(type) {
+	case *taskModel.ThandTask:
+		l.recorder.startStep(task.Key, fmt.Sprintf("%s.%s", taskModel.ThandTaskName, t.Thand))
+	case *model.CallFunction:
+		l.recorder.startStep(task.Key, fmt.Sprintf("call.%s", t.Call))
+		if slices.Contains(inProcessFunctions, t.Call) {
+			return handler, exists
+		}
+	case *model.ListenTask:
+		l.recorder.startStep(task.Key, getTaskType(task.Task))
+		if !listensForApprovals(t) {
+			return handler, exists
+		}
+	default:
+		l.recorder.startStep(task.Key, getTaskType(task.Task))
+		return handler, exists
+	}
+
+	return &mockTask{
+		layer: l,
+		next:  handler,
+	}, true
+}
+
+// listensForApprovals returns true if the listen task waits for the
+// approval events sent by the approval notifications
+func listensForApprovals(listen *model.ListenTask) bool {
+
+	to := listen.Listen.To
+
+	if to == nil {
+		return false
+	}
+
+	filters := slices.Concat(to.All, to.Any)
+
+	if to.One != nil {
+		filters = append(filters, to.One)
+	}
+
+	for _, filter := range filters {
+		if filter != nil && filter.With != nil && filter.With.Type == thand.ThandApprovalEventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getTaskType returns the task type the same way the registry names them
+// e.g. set, switch or callfunction
+func getTaskType(task model.Task) string {
+
+	t := reflect.TypeOf(task)
+
+	if t == nil {
+		return "unknown"
+	}
+
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return strings.TrimSuffix(strings.ToLower(t.Name()), "task")
+}
+
+// mockTask executes thand tasks and function calls without calling any
+// providers. Approvals are approved after Options.ApprovalDelay, notifiers
+// are recorded instead of sent and authorize and revoke only log. Validate
+// checks the request against the role without asking the provider, and
+// policy evaluates the configured policies as normal.
+type mockTask struct {
+	layer *mockLayer
+	next  tasks.Task
+}
+
+func (m *mockTask) GetName() string {
+	return "mock"
+}
+
+func (m *mockTask) GetDescription() string {
+	return "Simulates the Thand tasks and function calls without calling any providers."
+}
+
+func (m *mockTask) GetVersion() string {
+	return "1.0.0"
+}
+
+func (m *mockTask) Execute(
+	workflowTask *models.WorkflowTask,
+	task *model.TaskItem,
+	input any,
+) (any, error) {
+
+	switch call := task.Task.(type) {
+	case *taskModel.ThandTask:
+		return m.executeThandTask(workflowTask, task, call, input)
+	case *model.CallFunction:
+		return m.executeCallFunction(workflowTask, task.Key, call, input)
+	case *model.ListenTask:
+		return m.executeApprovalListen(workflowTask, task.Key, call)
+	}
+
+	return nil, fmt.Errorf("invalid task type for mock task: %s", task.Key)
+}
+
+func (m *mockTask) executeThandTask(
+	workflowTask *models.WorkflowTask,
+	task *model.TaskItem,
+	call *taskModel.ThandTask,
+	input any,
+) (any, error) {
+
+	taskName := task.Key
+	options := m.layer.options
+
+	// Route tasks only read the workflow context and evaluate their own
+	// conditions so run them as they are
+	if call.Thand == thand.ThandRouteTask {
+		return m.executeInProcess(workflowTask, task, input)
+	}
+
+	var rawWith map[string]any
+	if call.With != nil {
+		rawWith = call.With.AsMap()
+	}
+
+	with, err := interpolateWith(workflowTask, rawWith, input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if options.ExpireAt == taskName && call.Thand != thand.ThandMonitorTask {
+		return nil, m.expire(taskName)
+	}
+
+	if options.DenyAt == taskName &&
+		call.Thand != thand.ThandApprovalsTask &&
+		call.Thand != thand.ThandPolicyTask {
+		return nil, m.deny(taskName)
+	}
+
+	switch call.Thand {
+	case thand.ThandApprovalsTask:
+		return m.executeApprovals(workflowTask, taskName, call, with)
+	case thand.ThandAuthorizeTask:
+		return m.executeAuthorize(workflowTask, taskName, with, input)
+	case thand.ThandRevokeTask, thand.ThandAutoRevokeTask:
+		m.recordNotifiers(taskName, "revoke", with)
+		m.layer.recorder.markMocked()
+		logrus.WithField("task", taskName).Info("Simulated revocation")
+		return input, nil
+	case thand.ThandNotifyTask:
+		return m.executeNotify(taskName, with, input)
+	case thand.ThandFormTask:
+		m.recordNotifiers(taskName, "form", with)
+		m.layer.recorder.markMocked()
+		return input, nil
+	case thand.ThandMonitorTask:
+		return m.executeMonitor(workflowTask, taskName, input)
+	case thand.ThandValidateTask:
+		return m.executeValidate(workflowTask, input)
+	case thand.ThandPolicyTask:
+		if options.DenyAt == taskName {
+			return m.executePolicyDenial(workflowTask, taskName, call)
+		}
+	}
+
+	return m.executeInProcess(workflowTask, task, input)
+}
+
+// executeCallFunction simulates the thand functions and records or logs
+// calls to the provider functions e.g. slack.postMessage
+func (m *mockTask) executeCallFunction(
+	workflowTask *models.WorkflowTask,
+	taskName string,
+	call *model.CallFunction,
+	input any,
+) (any, error) {
+
+	with, err := interpolateWith(workflowTask, call.With, input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if m.layer.options.ExpireAt == taskName {
+		return nil, m.expire(taskName)
+	}
+
+	if m.layer.options.DenyAt == taskName {
+		return nil, m.deny(taskName)
+	}
+
+	switch call.Call {
+	case thandFunction.ThandNotifyFunction:
+		return m.executeNotify(taskName, with, input)
+	case thandFunction.ThandAuthorizeFunction:
+		return m.executeAuthorize(workflowTask, taskName, with, input)
+	case thandFunction.ThandRevokeFunction:
+		m.recordNotifiers(taskName, "revoke", with)
+		m.layer.recorder.markMocked()
+		logrus.WithField("task", taskName).Info("Simulated revocation")
+		return input, nil
+	}
+
+	m.layer.recorder.markMocked()
+
+	logrus.WithFields(logrus.Fields{
+		"task":     taskName,
+		"function": call.Call,
+	}).Info("Simulated function call")
+
+	return input, nil
+}
+
+// executeApprovalListen delivers an approval event after
+// Options.ApprovalDelay to a listen task waiting for approvals
+func (m *mockTask) executeApprovalListen(
+	workflowTask *models.WorkflowTask,
+	taskName string,
+	listen *model.ListenTask,
+) (any, error) {
+
+	if m.layer.options.ExpireAt == taskName {
+		return nil, m.expire(taskName)
+	}
+
+	m.layer.recorder.markMocked()
+
+	if err := m.sleep(workflowTask, m.layer.options.ApprovalDelay); err != nil {
+		return nil, err
+	}
+
+	m.layer.approvalEvents++
+
+	approver := fmt.Sprintf("approver-%d@%s", m.layer.approvalEvents, SimulatedApproverDomain)
+
+	event := cloudevents.NewEvent()
+	event.SetSpecVersion("1.0")
+	event.SetID(fmt.Sprintf("simulated-approval-%d", m.layer.approvalEvents))
+	event.SetTime(m.now(workflowTask))
+	event.SetSource("urn:thand:simulation")
+	event.SetType(thand.ThandApprovalEventType)
+	event.SetExtension("user", approver)
+
+	if err := event.SetData(cloudevents.ApplicationJSON, map[string]any{
+		"approved": m.layer.options.DenyAt != taskName,
+		"user":     approver,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create approval event: %w", err)
+	}
+
+	previous := workflowTask.GetContext()
+	defer workflowTask.SetInternalContext(previous)
+
+	return runner.ListenTaskHandler(workflowTask.WithoutTemporalContext(), taskName, listen, &event)
+}
+
+func (m *mockTask) expire(taskName string) error {
+	m.layer.recorder.markMocked()
+	return temporal.NewNonRetryableApplicationError(
+		fmt.Sprintf("timer expired at task %s", taskName), ErrorTypeTimerExpired, nil)
+}
+
+func (m *mockTask) deny(taskName string) error {
+	m.layer.recorder.markMocked()
+	return temporal.NewNonRetryableApplicationError(
+		fmt.Sprintf("task %s denied by simulation", taskName), ErrorTypeDenied, nil)
+}
+
+// executeInProcess runs the registered handler detached from temporal so
+// it doesn't schedule activities on a worker
+func (m *mockTask) executeInProcess(
+	workflowTask *models.WorkflowTask,
+	task *model.TaskItem,
+	input any,
+) (any, error) {
+
+	if m.next == nil {
+		return nil, fmt.Errorf("no handler registered for task: %s", task.Key)
+	}
+
+	previous := workflowTask.GetContext()
+	defer workflowTask.SetInternalContext(previous)
+
+	return m.next.Execute(workflowTask.WithoutTemporalContext(), task, input)
+}
+
+func (m *mockTask) executeApprovals(
+	workflowTask *models.WorkflowTask,
+	taskName string,
+	call *taskModel.ThandTask,
+	with map[string]any,
+) (any, error) {
+
+	var approvalsTask thand.ApprovalsTask
+
+	if err := common.ConvertInterfaceToInterface(with, &approvalsTask); err != nil {
+		return nil, fmt.Errorf("failed to parse approvals task: %w", err)
+	}
+
+	approvedState, foundApprovedState := call.On.GetString("approved")
+	deniedState, foundDeniedState := call.On.GetString("denied")
+
+	if !foundApprovedState || !foundDeniedState {
+		return nil, fmt.Errorf("both approved and denied states must be specified in the on block")
+	}
+
+	m.layer.recorder.markMocked()
+	m.recordNotifierRequests(taskName, "approval", approvalsTask.Notifiers)
+
+	if err := m.sleep(workflowTask, m.layer.options.ApprovalDelay); err != nil {
+		return nil, err
+	}
+
+	approved := m.layer.options.DenyAt != taskName
+	approvers := 1
+
+	if approved {
+		approvers = max(approvalsTask.Approvals, 1)
+	}
+
+	approvals, err := models.GetContextAs[map[string]any](workflowTask, "approvals")
+
+	if err != nil {
+		approvals = map[string]any{}
+	}
+
+	for i := range approvers {
+		approvals[fmt.Sprintf("approver-%d@%s", i+1, SimulatedApproverDomain)] = map[string]any{
+			"approved":  approved,
+			"timestamp": m.now(workflowTask).UTC().Format(time.RFC3339),
+		}
+	}
+
+	workflowTask.SetContextKeyValue("approvals", approvals)
+
+	if !approved {
+		workflowTask.SetContextKeyValue(models.VarsContextApproved, false)
+		return &model.FlowDirective{Value: deniedState}, nil
+	}
+
+	return &model.FlowDirective{Value: approvedState}, nil
+}
+
+func (m *mockTask) executeAuthorize(
+	workflowTask *models.WorkflowTask,
+	taskName string,
+	with map[string]any,
+	input any,
+) (any, error) {
+
+	elevationRequest, err := workflowTask.GetContextAsElevationRequest()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get elevation request from context: %w", err)
+	}
+
+	identities := elevationRequest.Identities
+
+	if len(identities) == 0 && elevationRequest.User != nil {
+		identities = []string{elevationRequest.User.GetIdentity()}
+	}
+
+	// Record a simulated grant for each provider and identity, the same
+	// shape the authorize task records
+	authorizations := map[string]map[string]*models.AuthorizeRoleResponse{}
+
+	for _, providerName := range elevationRequest.Providers {
+		authorizations[providerName] = map[string]*models.AuthorizeRoleResponse{}
+		for _, identity := range identities {
+			authorizations[providerName][identity] = &models.AuthorizeRoleResponse{
+				GrantRef: &models.GrantRef{
+					Provider:  providerName,
+					Simulated: true,
+				},
+			}
+		}
+	}
+
+	m.layer.recorder.markMocked()
+	m.recordNotifiers(taskName, "authorize", with)
+
+	logrus.WithFields(logrus.Fields{
+		"task":       taskName,
+		"identities": identities,
+		"providers":  elevationRequest.Providers,
+	}).Info("Simulated authorization")
+
+	workflowTask.SetContextKeyValue(models.VarsContextApproved, true)
+	workflowTask.SetContextKeyValue(models.VarsContextProviderAuthorizations, authorizations)
+
+	return input, nil
+}
+
+func (m *mockTask) executeNotify(taskName string, with map[string]any, input any) (any, error) {
+
+	var notifyReq thandFunction.NotifierRequest
+
+	if err := common.ConvertInterfaceToInterface(with, &notifyReq); err != nil {
+		return nil, fmt.Errorf("failed to parse notification request: %w", err)
+	}
+
+	m.layer.recorder.markMocked()
+	m.layer.recorder.notify(Notification{
+		Task:     taskName,
+		Kind:     "notify",
+		Provider: notifyReq.Provider,
+		To:       notifyReq.To,
+		Message:  notifyReq.Message,
+	})
+
+	return input, nil
+}
+
+// executeMonitor waits until the elevation expires, or returns straight
+// away if the task's timer is set to expire
+func (m *mockTask) executeMonitor(
+	workflowTask *models.WorkflowTask,
+	taskName string,
+	input any,
+) (any, error) {
+
+	m.layer.recorder.markMocked()
+
+	if m.layer.options.ExpireAt == taskName {
+		return input, nil
+	}
+
+	duration := time.Hour
+
+	if elevationRequest, err := workflowTask.GetContextAsElevationRequest(); err == nil {
+		if requested, err := elevationRequest.AsDuration(); err == nil {
+			duration = requested
+		}
+	}
+
+	if err := m.sleep(workflowTask, duration); err != nil {
+		return nil, err
+	}
+
+	return input, nil
+}
+
+// executeValidate checks the request against the role's reason policy and
+// max duration. Providers aren't asked to validate the role.
+func (m *mockTask) executeValidate(workflowTask *models.WorkflowTask, input any) (any, error) {
+
+	elevationRequest, err := workflowTask.GetContextAsElevationRequest()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get elevation request from context: %w", err)
+	}
+
+	if elevationRequest.Role == nil {
+		return nil, fmt.Errorf("role must be provided")
+	}
+
+	cfg := m.layer.config
+
+	if err := cfg.CheckReason(elevationRequest.User, elevationRequest.Role, elevationRequest.Reason); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.CheckDuration(elevationRequest.User, elevationRequest.Role, elevationRequest.Duration); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.CheckPermissions(elevationRequest.User, elevationRequest.Role); err != nil {
+		return nil, err
+	}
+
+	m.layer.recorder.markMocked()
+
+	return input, nil
+}
+
+// executePolicyDenial routes the workflow as if the policies denied the
+// request
+func (m *mockTask) executePolicyDenial(
+	workflowTask *models.WorkflowTask,
+	taskName string,
+	call *taskModel.ThandTask,
+) (any, error) {
+
+	m.layer.recorder.markMocked()
+
+	decision := &models.PolicyDecision{
+		Result:   models.PolicyResultDeny,
+		Messages: []string{fmt.Sprintf("denied by simulation at task %s", taskName)},
+	}
+
+	decisionMap, err := common.ConvertInterfaceToMap(decision)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert policy decision: %w", err)
+	}
+
+	workflowTask.SetContextKeyValue(models.VarsContextPolicy, decisionMap)
+
+	deniedState, foundDeniedState := call.On.GetString("denied")
+
+	if !foundDeniedState {
+		return nil, fmt.Errorf("elevation request denied by policy: %s",
+			strings.Join(decision.Messages, "; "))
+	}
+
+	return &model.FlowDirective{Value: deniedState}, nil
+}
+
+// recordNotifiers records the notifiers configured in with.notifiers
+func (m *mockTask) recordNotifiers(taskName string, kind string, with map[string]any) {
+
+	var notifiers struct {
+		Notifiers map[string]thandFunction.NotifierRequest `json:"notifiers"`
+	}
+
+	if err := common.ConvertInterfaceToInterface(with, &notifiers); err != nil {
+		logrus.WithError(err).WithField("task", taskName).Warn("Failed to parse notifiers")
+		return
+	}
+
+	m.recordNotifierRequests(taskName, kind, notifiers.Notifiers)
+}
+
+func (m *mockTask) recordNotifierRequests(
+	taskName string,
+	kind string,
+	notifiers map[string]thandFunction.NotifierRequest,
+) {
+	for _, providerKey := range slices.Sorted(maps.Keys(notifiers)) {
+
+		notifier := notifiers[providerKey]
+		provider := notifier.Provider
+
+		if len(provider) == 0 {
+			provider = providerKey
+		}
+
+		m.layer.recorder.notify(Notification{
+			Task:     taskName,
+			Kind:     kind,
+			Provider: provider,
+			To:       notifier.To,
+			Message:  notifier.Message,
+		})
+	}
+}
+
+func (m *mockTask) sleep(workflowTask *models.WorkflowTask, duration time.Duration) error {
+
+	ctx := workflowTask.GetTemporalContext()
+
+	if ctx == nil || duration <= 0 {
+		return nil
+	}
+
+	return workflow.Sleep(ctx, duration)
+}
+
+func (m *mockTask) now(workflowTask *models.WorkflowTask) time.Time {
+
+	if ctx := workflowTask.GetTemporalContext(); ctx != nil {
+		return workflow.Now(ctx)
+	}
+
+	return time.Now()
+}
+
+// interpolateWith evaluates the expressions in call.with
+func interpolateWith(workflowTask *models.WorkflowTask, with map[string]any, input any) (map[string]any, error) {
+
+	if with == nil {
+		return map[string]any{}, nil
+	}
+
+	interpolated, err := workflowTask.TraverseAndEvaluate(with, input)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to interpolate call.with: %w", err)
+	}
+
+	with, ok := interpolated.(map[string]any)
+
+	if !ok {
+		return nil, fmt.Errorf("interpolated call.with is not a map[string]any")
+	}
+
+	return with, nil
+}
@@ -0,0 +1,55 @@
+package workflowtesting
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	coreProviders "github.com/thand-io/agent/internal/providers"
+	"github.com/thand-io/agent/internal/providers/aws"
+	"github.com/thand-io/agent/internal/providers/azure"
+	"github.com/thand-io/agent/internal/providers/email"
+	"github.com/thand-io/agent/internal/providers/gcp"
+	"github.com/thand-io/agent/internal/providers/kubernetes"
+)
+
+// mockProviders load their roles and permissions from the embedded data
+// without calling the provider
+var mockProviders = map[string]func() models.ProviderImpl{
+	aws.AwsProviderName:               func() models.ProviderImpl { return aws.NewMockAwsProvider() },
+	azure.AzureProviderName:           func() models.ProviderImpl { return azure.NewMockAzureProvider() },
+	email.EmailProviderName:           email.NewMockEmailProvider,
+	gcp.GcpProviderName:               func() models.ProviderImpl { return gcp.NewMockGcpProvider() },
+	kubernetes.KubernetesProviderName: kubernetes.NewMockKubernetesProvider,
+}
+
+// InitializeMockProviders replaces the registered provider implementations
+// with mocks and initializes the configured providers that have one, so
+// roles inheriting provider roles can be resolved. Providers without a
+// mock are removed from the configuration.
+//
+// The mocks replace the real providers for the rest of the process.
+func InitializeMockProviders(cfg *config.Config) error {
+
+	for name, newProvider := range mockProviders {
+		coreProviders.Set(name, newProvider())
+	}
+
+	definitions := map[string]models.Provider{}
+
+	for providerKey, provider := range cfg.Providers.Definitions {
+
+		if _, exists := mockProviders[strings.ToLower(provider.Provider)]; !exists {
+			logrus.WithField("provider", providerKey).Debug("No mock for provider, skipping")
+			continue
+		}
+
+		definitions[providerKey] = provider
+	}
+
+	cfg.Providers.Definitions = definitions
+
+	return cfg.InitializeProviders(context.Background())
+}
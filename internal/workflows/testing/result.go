@@ -0,0 +1,231 @@
+package workflowtesting
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	swctx "github.com/serverlessworkflow/sdk-go/v3/impl/ctx"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	ContextChangeAdded   = "added"
+	ContextChangeChanged = "changed"
+	ContextChangeRemoved = "removed"
+)
+
+// Result is the outcome of a simulated workflow run
+type Result struct {
+	Workflow string            `json:"workflow"`
+	Status   swctx.StatusPhase `json:"status"`
+	Error    string            `json:"error,omitempty"`
+	Approved *bool             `json:"approved,omitempty"`
+
+	// Elapsed is the simulated time the workflow took
+	Elapsed time.Duration `json:"elapsed"`
+
+	Steps         []Step         `json:"steps"`
+	Notifications []Notification `json:"notifications,omitempty"`
+	Context       map[string]any `json:"context,omitempty"`
+}
+
+// Succeeded returns true if the workflow completed without an error
+func (r *Result) Succeeded() bool {
+	return r != nil && len(r.Error) == 0 && r.Status == swctx.CompletedStatus
+}
+
+// GetTaskOrder returns the names of the tasks in the order they executed
+func (r *Result) GetTaskOrder() []string {
+	order := make([]string, 0, len(r.Steps))
+	for _, step := range r.Steps {
+		order = append(order, step.Task)
+	}
+	return order
+}
+
+// Step is a single task execution
+type Step struct {
+	Task   string        `json:"task"`
+	Type   string        `json:"type"`             // e.g. set, switch or thand.approvals
+	At     time.Duration `json:"at"`               // simulated time since the workflow started
+	Mocked bool          `json:"mocked,omitempty"` // executed by a mock instead of a provider
+
+	// Changes are the context keys the task added, changed or removed
+	Changes []ContextChange `json:"changes,omitempty"`
+}
+
+type ContextChange struct {
+	Key    string `json:"key"`
+	Action string `json:"action"`
+	Value  any    `json:"value,omitempty"`
+}
+
+// Notification is a message a mocked task would have sent
+type Notification struct {
+	Task     string        `json:"task"`
+	Kind     string        `json:"kind"` // approval, authorize, form, notify or revoke
+	Provider string        `json:"provider,omitempty"`
+	To       []string      `json:"to,omitempty"`
+	Message  string        `json:"message,omitempty"`
+	At       time.Duration `json:"at"`
+}
+
+// recorder tracks the tasks executed against a workflow task and the
+// changes each one makes to the workflow context
+type recorder struct {
+	mu            sync.Mutex
+	workflowTask  *models.WorkflowTask
+	started       time.Time
+	steps         []Step
+	notifications []Notification
+	lastContext   map[string]any
+}
+
+func newRecorder(workflowTask *models.WorkflowTask) *recorder {
+	return &recorder{
+		workflowTask: workflowTask,
+		lastContext:  snapshotContext(workflowTask),
+	}
+}
+
+// start marks the start of the simulation in workflow time
+func (r *recorder) start(ctx workflow.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.started = workflow.Now(ctx)
+}
+
+// elapsed returns the simulated time since the workflow started
+func (r *recorder) elapsed() time.Duration {
+
+	ctx := r.workflowTask.GetTemporalContext()
+
+	if ctx == nil || r.started.IsZero() {
+		return 0
+	}
+
+	return workflow.Now(ctx).Sub(r.started)
+}
+
+// elapsedSince returns the simulated time between the workflow starting
+// and now, for use once the workflow has finished
+func (r *recorder) elapsedSince(now time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started.IsZero() {
+		return 0
+	}
+
+	return now.Sub(r.started)
+}
+
+// startStep records a task starting. Context changes made since the last
+// step started are attributed to the last step.
+func (r *recorder) startStep(task string, taskType string) {
+
+	at := r.elapsed()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.flushChanges()
+
+	r.steps = append(r.steps, Step{
+		Task: task,
+		Type: taskType,
+		At:   at,
+	})
+}
+
+// markMocked flags the current step as executed by a mock
+func (r *recorder) markMocked() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.steps) > 0 {
+		r.steps[len(r.steps)-1].Mocked = true
+	}
+}
+
+func (r *recorder) notify(notification Notification) {
+
+	notification.At = r.elapsed()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.notifications = append(r.notifications, notification)
+}
+
+// finish attributes any remaining context changes to the last step and
+// returns the recorded steps and notifications
+func (r *recorder) finish() ([]Step, []Notification) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.flushChanges()
+
+	return slices.Clone(r.steps), slices.Clone(r.notifications)
+}
+
+func (r *recorder) flushChanges() {
+
+	current := snapshotContext(r.workflowTask)
+
+	if len(r.steps) > 0 {
+		r.steps[len(r.steps)-1].Changes = append(
+			r.steps[len(r.steps)-1].Changes, diffContext(r.lastContext, current)...)
+	}
+
+	r.lastContext = current
+}
+
+// snapshotContext copies the workflow context so later changes can be
+// compared against it
+func snapshotContext(workflowTask *models.WorkflowTask) map[string]any {
+
+	snapshot, err := common.ConvertInterfaceToMap(workflowTask.GetContextAsMap())
+
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to snapshot workflow context")
+		return map[string]any{}
+	}
+
+	return snapshot
+}
+
+// diffContext returns the top level keys that differ between two snapshots
+func diffContext(before map[string]any, after map[string]any) []ContextChange {
+
+	var changes []ContextChange
+
+	for key, value := range after {
+		previous, existed := before[key]
+		switch {
+		case !existed:
+			changes = append(changes, ContextChange{Key: key, Action: ContextChangeAdded, Value: value})
+		case !reflect.DeepEqual(previous, value):
+			changes = append(changes, ContextChange{Key: key, Action: ContextChangeChanged, Value: value})
+		}
+	}
+
+	for key := range before {
+		if _, exists := after[key]; !exists {
+			changes = append(changes, ContextChange{Key: key, Action: ContextChangeRemoved})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
@@ -0,0 +1,267 @@
+// Package workflowtesting simulates workflows locally. A workflow runs
+// through the temporal test environment, so timers fire in simulated
+// time, with the provider backed thand tasks replaced by mocks.
+package workflowtesting
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	swctx "github.com/serverlessworkflow/sdk-go/v3/impl/ctx"
+	"github.com/sirupsen/logrus"
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/workflows/manager"
+	temporalLog "go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	DefaultApprovalDelay = 5 * time.Minute
+	DefaultTimeout       = 7 * 24 * time.Hour
+
+	simulationWorkflowName = "thand-workflow-simulation"
+)
+
+// DefaultSimulatedUser requests the elevation when the request doesn't
+// include a user
+var DefaultSimulatedUser = models.User{
+	Username: "requester",
+	Email:    "requester@example.com",
+	Name:     "Simulated Requester",
+}
+
+// Options configure how the mocked tasks behave
+type Options struct {
+	// ApprovalDelay is how long approvals take to arrive
+	ApprovalDelay time.Duration
+	// DenyAt denies the request at the named task. Approvals are denied,
+	// policies deny and any other thand task fails.
+	DenyAt string
+	// ExpireAt expires the timer of the named task. Monitors end straight
+	// away as if the elevation expired, any other thand task fails with a
+	// timeout.
+	ExpireAt string
+	// Timeout is the simulated time after which a workflow that is still
+	// running e.g. waiting on an event, is cancelled
+	Timeout time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.ApprovalDelay == 0 {
+		o.ApprovalDelay = DefaultApprovalDelay
+	}
+	if o.Timeout == 0 {
+		o.Timeout = DefaultTimeout
+	}
+	return o
+}
+
+// Simulator runs workflows from the configuration against mock providers
+type Simulator struct {
+	config  *config.Config
+	options Options
+}
+
+func NewSimulator(cfg *config.Config, options Options) *Simulator {
+	return &Simulator{
+		config:  cfg,
+		options: options.withDefaults(),
+	}
+}
+
+// LoadRequest reads an elevation request fixture from a JSON or YAML file
+func LoadRequest(path string) (*models.ElevateRequestInternal, error) {
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request fixture: %w", err)
+	}
+
+	request, err := common.ReadDataToInterface(data, models.ElevateRequestInternal{})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse request fixture: %w", err)
+	}
+
+	return request, nil
+}
+
+// Run simulates the named workflow for the request. An error is only
+// returned if the simulation can't run, failures of the workflow itself
+// are reported in the result.
+func (s *Simulator) Run(workflowName string, request *models.ElevateRequestInternal) (*Result, error) {
+
+	workflowTask, err := s.newWorkflowTask(workflowName, request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := newRecorder(workflowTask)
+
+	workflowManager := manager.NewWorkflowManager(s.config)
+	workflowManager.SetTaskLayer(&mockLayer{
+		config:   s.config,
+		options:  s.options,
+		recorder: recorder,
+	})
+
+	var suite testsuite.WorkflowTestSuite
+	suite.SetLogger(temporalLog.NewStructuredLogger(
+		slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	env := suite.NewTestWorkflowEnvironment()
+
+	// The workflow task is shared with the workflow rather than passed as
+	// an argument so the recorder sees the same task
+	env.RegisterWorkflowWithOptions(func(ctx workflow.Context) error {
+
+		recorder.start(ctx)
+
+		_, err := workflowManager.ResumeWorkflowTask(
+			workflowTask.WithTemporalContext(ctx))
+
+		return err
+
+	}, workflow.RegisterOptions{Name: simulationWorkflowName})
+
+	timedOut := false
+
+	env.RegisterDelayedCallback(func() {
+		timedOut = true
+		env.CancelWorkflow()
+	}, s.options.Timeout)
+
+	env.ExecuteWorkflow(simulationWorkflowName)
+
+	steps, notifications := recorder.finish()
+
+	result := &Result{
+		Workflow:      workflowName,
+		Status:        getWorkflowStatus(workflowTask),
+		Approved:      workflowTask.IsApproved(),
+		Elapsed:       recorder.elapsedSince(env.Now()),
+		Steps:         steps,
+		Notifications: notifications,
+		Context:       snapshotContext(workflowTask),
+	}
+
+	if err := env.GetWorkflowError(); err != nil && !timedOut {
+		result.Error = unwrapWorkflowError(err).Error()
+		result.Status = swctx.FaultedStatus
+	}
+
+	if timedOut {
+		result.Error = fmt.Sprintf("workflow still %s at task %s after %s",
+			getWorkflowStatus(workflowTask), workflowTask.GetTaskName(), s.options.Timeout)
+		result.Status = swctx.CancelledStatus
+	}
+
+	return result, nil
+}
+
+// newWorkflowTask creates the workflow task as if the requester had
+// authenticated and the workflow was resumed
+func (s *Simulator) newWorkflowTask(
+	workflowName string,
+	request *models.ElevateRequestInternal,
+) (*models.WorkflowTask, error) {
+
+	if request == nil {
+		return nil, errors.New("request cannot be nil")
+	}
+
+	workflowDefinition, err := s.config.GetWorkflowByName(workflowName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if workflowDefinition.GetWorkflow() == nil {
+		return nil, fmt.Errorf("workflow %s has no definition", workflowName)
+	}
+
+	// Don't modify the caller's request
+	elevateRequest := *request
+	elevateRequest.Workflow = workflowName
+
+	if elevateRequest.User == nil {
+		user := DefaultSimulatedUser
+		elevateRequest.User = &user
+	}
+
+	if elevateRequest.Role == nil {
+		return nil, errors.New("request must include a role")
+	}
+
+	// Use the configured role when the fixture only names it
+	if configuredRole, err := s.config.GetRoleByName(elevateRequest.Role.Name); err == nil {
+		elevateRequest.Role = configuredRole
+	}
+
+	if len(elevateRequest.Identities) == 0 {
+		elevateRequest.Identities = []string{elevateRequest.User.GetIdentity()}
+	}
+
+	workflowTask, err := models.NewWorkflowContext(workflowDefinition)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workflow context: %w", err)
+	}
+
+	workflowTask.SetWorkflowDsl(workflowDefinition.GetWorkflowClone())
+	simulatedContext := elevateRequest.AsMap()
+	simulatedContext[models.VarsContextVars] = s.config.GetWorkflowVars(
+		elevateRequest.Role, elevateRequest.Providers)
+
+	workflowTask.SetContext(simulatedContext)
+	workflowTask.SetUser(elevateRequest.User)
+
+	compositeRole, err := s.config.GetCompositeRole(&models.Identity{
+		ID:    elevateRequest.User.GetIdentity(),
+		Label: elevateRequest.User.GetName(),
+		User:  elevateRequest.User,
+	}, elevateRequest.Role)
+
+	// Provider roles can't be resolved without initializing the providers
+	// so fall back to the role as configured
+	if err != nil {
+		logrus.WithError(err).WithField("role", elevateRequest.Role.Name).
+			Warn("Failed to evaluate composite role, using the configured role")
+		compositeRole = elevateRequest.Role
+	}
+
+	workflowTask.SetRole(compositeRole)
+
+	return workflowTask, nil
+}
+
+// getWorkflowStatus returns the latest status the runner logged
+func getWorkflowStatus(workflowTask *models.WorkflowTask) swctx.StatusPhase {
+
+	if phases := workflowTask.StatusPhase; len(phases) > 0 {
+		return phases[len(phases)-1].Status
+	}
+
+	return workflowTask.GetStatus()
+}
+
+// unwrapWorkflowError strips the temporal workflow execution error so the
+// task error is reported
+func unwrapWorkflowError(err error) error {
+	for {
+		unwrapped := errors.Unwrap(err)
+		if unwrapped == nil {
+			return err
+		}
+		err = unwrapped
+	}
+}
@@ -0,0 +1,244 @@
+package workflowtesting
+
+import (
+	"testing"
+	"time"
+
+	swctx "github.com/serverlessworkflow/sdk-go/v3/impl/ctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/config"
+	"github.com/thand-io/agent/internal/models"
+)
+
+const approvalWorkflow = `
+version: "1.0"
+workflows:
+  approval:
+    description: Approval workflow
+    enabled: true
+    workflow:
+      document:
+        dsl: "1.0.0-alpha5"
+        namespace: "thand"
+        name: "approval"
+        version: "1.0.0"
+      do:
+        - validate:
+            thand: validate
+            then: approvals
+        - approvals:
+            thand: approvals
+            on:
+              approved: authorize
+              denied: denied
+            with:
+              approvals: 2
+              notifiers:
+                slack:
+                  provider: slack
+                  to: C0123456789
+                  message: "Access requested"
+        - authorize:
+            thand: authorize
+            then: monitor
+        - monitor:
+            thand: monitor
+            then: revoke
+        - revoke:
+            thand: revoke
+            then: end
+        - denied:
+            thand: notify
+            with:
+              provider: email
+              to: ${ $context.user.email }
+              message: "Your access request has been denied"
+            then: end
+`
+
+const listenWorkflow = `
+version: "1.0"
+workflows:
+  listen:
+    description: Listens for approval events
+    enabled: true
+    workflow:
+      document:
+        dsl: "1.0.0-alpha5"
+        namespace: "thand"
+        name: "listen"
+        version: "1.0.0"
+      do:
+        - notify:
+            call: thand.notify
+            with:
+              provider: slack
+              to: C0123456789
+              message: "Access requested"
+            then: approvals
+        - approvals:
+            listen:
+              to:
+                one:
+                  with:
+                    type: com.thand.approval
+            then: authorize
+        - authorize:
+            call: thand.authorize
+            then: end
+`
+
+func newSimulationConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	cfg := config.DefaultConfig()
+	cfg.Roles.Definitions = map[string]models.Role{
+		"admin": {
+			Name:        "admin",
+			Description: "Administrator",
+			Enabled:     true,
+		},
+		"limited": {
+			Name:        "limited",
+			Description: "Short lived access",
+			Enabled:     true,
+			MaxDuration: "1h",
+		},
+	}
+
+	LoadWorkflows(t, cfg, []byte(approvalWorkflow))
+
+	return cfg
+}
+
+func newSimulationRequest() *models.ElevateRequestInternal {
+	return &models.ElevateRequestInternal{
+		ElevateRequest: models.ElevateRequest{
+			Role:     &models.Role{Name: "admin"},
+			Reason:   "Investigating an incident",
+			Duration: "2h",
+		},
+	}
+}
+
+func TestSimulatorApprovedWorkflow(t *testing.T) {
+	cfg := newSimulationConfig(t)
+
+	result := RunWorkflow(t, cfg, "approval", newSimulationRequest(), Options{
+		ApprovalDelay: 10 * time.Minute,
+	})
+
+	require.True(t, result.Succeeded(), result.Error)
+	assert.Equal(t, []string{"validate", "approvals", "authorize", "monitor", "revoke"}, result.GetTaskOrder())
+	require.NotNil(t, result.Approved)
+	assert.True(t, *result.Approved)
+
+	// Approval delay plus the requested duration
+	assert.Equal(t, 2*time.Hour+10*time.Minute, result.Elapsed)
+
+	require.Len(t, result.Notifications, 1)
+	assert.Equal(t, "approval", result.Notifications[0].Kind)
+	assert.Equal(t, "slack", result.Notifications[0].Provider)
+
+	approvals := result.Steps[1]
+	assert.True(t, approvals.Mocked)
+	assert.Equal(t, 10*time.Minute, result.Steps[2].At)
+
+	var changedKeys []string
+	for _, change := range approvals.Changes {
+		changedKeys = append(changedKeys, change.Key)
+	}
+	assert.Contains(t, changedKeys, "approvals")
+
+	approvers, ok := result.Context["approvals"].(map[string]any)
+	require.True(t, ok)
+	assert.Len(t, approvers, 2)
+}
+
+func TestSimulatorDenyAtApprovals(t *testing.T) {
+	cfg := newSimulationConfig(t)
+
+	result := RunWorkflow(t, cfg, "approval", newSimulationRequest(), Options{
+		DenyAt: "approvals",
+	})
+
+	require.True(t, result.Succeeded(), result.Error)
+	assert.Equal(t, []string{"validate", "approvals", "denied"}, result.GetTaskOrder())
+	require.NotNil(t, result.Approved)
+	assert.False(t, *result.Approved)
+
+	require.Len(t, result.Notifications, 2)
+	assert.Equal(t, "notify", result.Notifications[1].Kind)
+	assert.Equal(t, []string{DefaultSimulatedUser.Email}, result.Notifications[1].To)
+}
+
+func TestSimulatorExpireAtTask(t *testing.T) {
+	cfg := newSimulationConfig(t)
+
+	t.Run("monitor ends early", func(t *testing.T) {
+		result := RunWorkflow(t, cfg, "approval", newSimulationRequest(), Options{
+			ExpireAt: "monitor",
+		})
+
+		require.True(t, result.Succeeded(), result.Error)
+		assert.Equal(t, DefaultApprovalDelay, result.Elapsed)
+		assert.Equal(t, "revoke", result.GetTaskOrder()[len(result.Steps)-1])
+	})
+
+	t.Run("other tasks fail", func(t *testing.T) {
+		result := RunWorkflow(t, cfg, "approval", newSimulationRequest(), Options{
+			ExpireAt: "authorize",
+		})
+
+		assert.False(t, result.Succeeded())
+		assert.Equal(t, swctx.FaultedStatus, result.Status)
+		assert.Contains(t, result.Error, "timer expired at task authorize")
+		assert.Equal(t, []string{"validate", "approvals", "authorize"}, result.GetTaskOrder())
+	})
+}
+
+func TestSimulatorRejectsInvalidRequest(t *testing.T) {
+	cfg := newSimulationConfig(t)
+
+	result := RunWorkflow(t, cfg, "approval", &models.ElevateRequestInternal{
+		ElevateRequest: models.ElevateRequest{
+			Role:     &models.Role{Name: "limited"},
+			Reason:   "Investigating an incident",
+			Duration: "2h",
+		},
+	}, Options{})
+
+	assert.False(t, result.Succeeded())
+	assert.Contains(t, result.Error, "duration must not be longer than")
+	assert.Equal(t, []string{"validate"}, result.GetTaskOrder())
+}
+
+func TestSimulatorUnknownWorkflow(t *testing.T) {
+	cfg := newSimulationConfig(t)
+
+	_, err := NewSimulator(cfg, Options{}).Run("missing", newSimulationRequest())
+
+	assert.Error(t, err)
+}
+
+func TestSimulatorListenForApprovals(t *testing.T) {
+	cfg := newSimulationConfig(t)
+	LoadWorkflows(t, cfg, []byte(listenWorkflow))
+
+	result := RunWorkflow(t, cfg, "listen", newSimulationRequest(), Options{
+		ApprovalDelay: 15 * time.Minute,
+	})
+
+	require.True(t, result.Succeeded(), result.Error)
+	assert.Equal(t, []string{"notify", "approvals", "authorize"}, result.GetTaskOrder())
+	assert.Equal(t, "call.thand.notify", result.Steps[0].Type)
+	assert.True(t, result.Steps[1].Mocked)
+	assert.Equal(t, 15*time.Minute, result.Steps[2].At)
+
+	require.Len(t, result.Notifications, 1)
+	assert.Equal(t, "slack", result.Notifications[0].Provider)
+
+	require.NotNil(t, result.Approved)
+	assert.True(t, *result.Approved)
+}
@@ -0,0 +1,297 @@
+// Package client is a Go client for the Thand elevation API. The API is
+// described by the OpenAPI spec served at /api/v1/openapi.json in server
+// mode.
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/thand-io/agent/internal/models"
+)
+
+// The request and response types are shared with the server so the client
+// always matches the handlers the spec is generated from
+type (
+	Role                = models.Role
+	ElevateRequest      = models.ElevateRequest
+	ElevateResponse     = models.ElevateResponse
+	ElevatePlanResponse = models.ElevatePlanResponse
+	PolicyDecision      = models.PolicyDecision
+	Execution           = models.WorkflowExecutionInfo
+	ErrorResponse       = models.ErrorResponse
+	ElevationEvent      = models.ElevationEvent
+	ElevationStatus     = models.ElevationStatus
+)
+
+// Client calls the elevation API of a Thand server
+type Client struct {
+	baseURL string
+	client  *resty.Client
+}
+
+// NewClient creates a client for the API at baseURL
+// e.g. https://thand.example.com/api/v1. The token is the encoded session
+// sent as a bearer token.
+func NewClient(baseURL string, token string) *Client {
+
+	client := resty.New().
+		SetHeader("Accept", "application/json")
+
+	if len(token) > 0 {
+		client.SetAuthToken(token)
+	}
+
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+}
+
+// SetRedirectPolicy controls how redirects are followed. Elevation requests
+// redirect through each workflow step until the workflow pauses or ends.
+func (c *Client) SetRedirectPolicy(policies ...resty.RedirectPolicy) *Client {
+	redirectPolicies := make([]any, 0, len(policies))
+	for _, policy := range policies {
+		redirectPolicies = append(redirectPolicies, policy)
+	}
+	c.client.SetRedirectPolicy(redirectPolicies...)
+	return c
+}
+
+// ErrRenewalUnsupported is returned by RenewSession when the provider of
+// the session doesn't support renewal
+var ErrRenewalUnsupported = errors.New("session renewal is not supported")
+
+// APIError is returned when the API responds with an error status
+type APIError struct {
+	StatusCode int
+	Response   *ErrorResponse
+}
+
+func (e *APIError) Error() string {
+	if e.Response != nil && len(e.Response.Title) > 0 {
+		return fmt.Sprintf("%s (%d): %s", e.Response.Title, e.StatusCode, e.Response.Message)
+	}
+	return fmt.Sprintf("unexpected status %d", e.StatusCode)
+}
+
+// Elevate submits an elevation request and starts its workflow
+func (c *Client) Elevate(ctx context.Context, request *ElevateRequest) (*ElevateResponse, error) {
+	var response ElevateResponse
+	if err := c.do(ctx, http.MethodPost, "/elevate", request, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Plan validates an elevation request and evaluates policies without
+// starting the workflow
+func (c *Client) Plan(ctx context.Context, request *ElevateRequest) (*ElevatePlanResponse, error) {
+	var response ElevatePlanResponse
+	if err := c.do(ctx, http.MethodPost, "/elevate/plan", request, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetExecution returns the status of an elevation workflow
+func (c *Client) GetExecution(ctx context.Context, id string) (*Execution, error) {
+	var response struct {
+		Execution *Execution `json:"execution"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/execution/"+url.PathEscape(id), nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Execution, nil
+}
+
+// ListExecutions returns the elevation workflows requested by the user
+func (c *Client) ListExecutions(ctx context.Context) ([]*Execution, error) {
+	var response models.WorkflowExecutionsResponse
+	if err := c.do(ctx, http.MethodGet, "/executions", nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Executions, nil
+}
+
+// Signal sends an encoded signal to a workflow e.g. the approve or deny
+// token from an approval notification
+func (c *Client) Signal(ctx context.Context, id string, input string) (*Execution, error) {
+	var response struct {
+		Execution *Execution `json:"execution"`
+	}
+	err := c.do(ctx, http.MethodPost, "/execution/"+url.PathEscape(id)+"/signal",
+		&models.WorkflowSignalRequest{Input: input}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Execution, nil
+}
+
+// RenewSession renews the session the client was created with before it
+// expires. Returns ErrRenewalUnsupported when the provider can't renew
+// sessions and the user must login again.
+func (c *Client) RenewSession(ctx context.Context, provider string) (*models.AuthRenewResponse, error) {
+	var response models.AuthRenewResponse
+	err := c.do(ctx, http.MethodPost, "/auth/renew",
+		&models.AuthRenewRequest{Provider: provider}, &response)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotImplemented {
+		return nil, fmt.Errorf("%w: %w", ErrRenewalUnsupported, err)
+	} else if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Revoke ends an elevation early and removes any granted access
+func (c *Client) Revoke(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/execution/"+url.PathEscape(id)+"/revoke", nil, nil)
+}
+
+// StreamEvents subscribes to the status events of an elevation. Events are
+// sent on the returned channel, which is closed once the elevation reaches
+// a terminal state, the context is cancelled or the connection drops. If
+// the last event was not terminal the caller should fall back to polling
+// GetExecution.
+func (c *Client) StreamEvents(ctx context.Context, id string) (<-chan *ElevationEvent, error) {
+
+	path := "/elevate/" + url.PathEscape(id) + "/events"
+
+	res, err := c.client.R().
+		SetContext(ctx).
+		SetHeader("Accept", "text/event-stream").
+		SetDoNotParseResponse(true).
+		Get(c.baseURL + path)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to call GET %s: %w", path, err)
+	}
+
+	body := res.RawBody()
+
+	if isErrorStatus(res.StatusCode()) {
+		defer body.Close()
+		data, _ := io.ReadAll(body)
+		return nil, newAPIError(res.StatusCode(), data)
+	}
+
+	events := make(chan *ElevationEvent)
+
+	go func() {
+		defer close(events)
+		defer body.Close()
+
+		readServerSentEvents(body, func(name string, data string) bool {
+
+			if name != "status" {
+				// Heartbeats only keep the connection open
+				return true
+			}
+
+			var event ElevationEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				return true
+			}
+
+			select {
+			case events <- &event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	return events, nil
+}
+
+// readServerSentEvents calls handle for each event in the stream until it
+// returns false or the stream ends
+func readServerSentEvents(reader io.Reader, handle func(name string, data string) bool) {
+
+	scanner := bufio.NewScanner(reader)
+	// Status events include the execution so can be larger than a line
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	var name string
+	var data []string
+
+	for scanner.Scan() {
+
+		line := scanner.Text()
+
+		if len(line) == 0 {
+			if len(data) > 0 && !handle(name, strings.Join(data, "\n")) {
+				return
+			}
+			name, data = "", nil
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			name = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, body any, result any) error {
+
+	request := c.client.R().SetContext(ctx)
+
+	if body != nil {
+		request.SetBody(body)
+	}
+
+	res, err := request.Execute(method, c.baseURL+path)
+
+	if err != nil {
+		return fmt.Errorf("failed to call %s %s: %w", method, path, err)
+	}
+
+	if isErrorStatus(res.StatusCode()) {
+		return newAPIError(res.StatusCode(), res.Body())
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(res.Body(), result); err != nil {
+		return fmt.Errorf("failed to parse response from %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
+
+func isErrorStatus(statusCode int) bool {
+	return statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices
+}
+
+func newAPIError(statusCode int, body []byte) *APIError {
+
+	apiErr := &APIError{StatusCode: statusCode}
+
+	var errorResponse ErrorResponse
+	if err := json.Unmarshal(body, &errorResponse); err == nil {
+		apiErr.Response = &errorResponse
+	}
+
+	return apiErr
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/serverlessworkflow/sdk-go/v3/impl/ctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /api/v1/elevate", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		var request ElevateRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "admin", request.Role.Name)
+
+		// Workflows redirect through their steps before responding
+		http.Redirect(w, r, "/api/v1/elevate/resume?taskName=approvals", http.StatusTemporaryRedirect)
+	})
+
+	mux.HandleFunc("POST /api/v1/elevate/resume", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ElevateResponse{
+			WorkflowId: "workflow-123",
+			Status:     ctx.WaitingStatus,
+		})
+	})
+
+	mux.HandleFunc("GET /api/v1/execution/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "workflow-123" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Code:    http.StatusNotFound,
+				Title:   "Not Found",
+				Message: "workflow not found",
+			})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"execution": Execution{WorkflowID: "workflow-123", Status: "Running", Role: "admin"},
+		})
+	})
+
+	mux.HandleFunc("GET /api/v1/executions", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.WorkflowExecutionsResponse{
+			Executions: []*Execution{{WorkflowID: "workflow-123"}},
+		})
+	})
+
+	mux.HandleFunc("POST /api/v1/auth/renew", func(w http.ResponseWriter, r *http.Request) {
+		var request models.AuthRenewRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		if request.Provider != "google" {
+			w.WriteHeader(http.StatusNotImplemented)
+			json.NewEncoder(w).Encode(ErrorResponse{Code: http.StatusNotImplemented, Title: "Session renewal unsupported"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(models.AuthRenewResponse{Provider: "google", Session: "renewed"})
+	})
+
+	mux.HandleFunc("POST /api/v1/execution/{id}/revoke", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+	})
+
+	mux.HandleFunc("GET /api/v1/elevate/{id}/events", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "workflow-123" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(ErrorResponse{Code: http.StatusForbidden, Title: "Forbidden"})
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		for _, status := range []models.ElevationStatus{
+			models.ElevationStatusPending, models.ElevationStatusGranted, models.ElevationStatusRevoked,
+		} {
+			data, _ := json.Marshal(ElevationEvent{WorkflowID: "workflow-123", Status: status})
+			fmt.Fprintf(w, "event:heartbeat\ndata:\"2025-01-01T00:00:00Z\"\n\n")
+			fmt.Fprintf(w, "event:status\ndata:%s\n\n", data)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestClient(t *testing.T) {
+	server := newTestServer(t)
+	client := NewClient(server.URL+"/api/v1/", "token")
+
+	t.Run("elevate follows workflow redirects", func(t *testing.T) {
+		response, err := client.Elevate(context.Background(), &ElevateRequest{
+			Role:      &Role{Name: "admin"},
+			Providers: []string{"aws-prod"},
+			Reason:    "investigating outage",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "workflow-123", response.WorkflowId)
+		assert.Equal(t, ctx.WaitingStatus, response.Status)
+	})
+
+	t.Run("get execution", func(t *testing.T) {
+		execution, err := client.GetExecution(context.Background(), "workflow-123")
+		require.NoError(t, err)
+		assert.Equal(t, "admin", execution.Role)
+	})
+
+	t.Run("list executions", func(t *testing.T) {
+		executions, err := client.ListExecutions(context.Background())
+		require.NoError(t, err)
+		require.Len(t, executions, 1)
+	})
+
+	t.Run("renew session", func(t *testing.T) {
+		response, err := client.RenewSession(context.Background(), "google")
+		require.NoError(t, err)
+		assert.Equal(t, "renewed", response.Session)
+	})
+
+	t.Run("renewal unsupported", func(t *testing.T) {
+		_, err := client.RenewSession(context.Background(), "saml")
+		assert.ErrorIs(t, err, ErrRenewalUnsupported)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		assert.NoError(t, client.Revoke(context.Background(), "workflow-123"))
+	})
+
+	t.Run("stream events", func(t *testing.T) {
+		events, err := client.StreamEvents(context.Background(), "workflow-123")
+		require.NoError(t, err)
+
+		var statuses []ElevationStatus
+		for event := range events {
+			assert.Equal(t, "workflow-123", event.WorkflowID)
+			statuses = append(statuses, event.Status)
+		}
+
+		assert.Equal(t, []ElevationStatus{
+			models.ElevationStatusPending, models.ElevationStatusGranted, models.ElevationStatusRevoked,
+		}, statuses)
+	})
+
+	t.Run("stream events requires permission", func(t *testing.T) {
+		_, err := client.StreamEvents(context.Background(), "someone-else")
+
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+	})
+
+	t.Run("errors include the server response", func(t *testing.T) {
+		_, err := client.GetExecution(context.Background(), "missing")
+
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.Equal(t, "workflow not found", apiErr.Response.Message)
+	})
+}
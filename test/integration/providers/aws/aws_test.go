@@ -0,0 +1,280 @@
+//go:build integration
+
+package aws_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/localstack"
+
+	"github.com/thand-io/agent/internal/common"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/providers"
+	awsProvider "github.com/thand-io/agent/internal/providers/aws"
+	"github.com/thand-io/agent/test/integration/providers/providertest"
+)
+
+const (
+	localStackImage = "docker.io/localstack/localstack:4.4"
+	accountID       = "000000000000"
+)
+
+// startLocalStack starts LocalStack with IAM and STS and returns its
+// endpoint. The test is skipped when Docker isn't available.
+func startLocalStack(t *testing.T) string {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("skipping provider integration test in short mode")
+	}
+
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+
+	container, err := localstack.Run(ctx, localStackImage,
+		testcontainers.WithEnv(map[string]string{
+			"SERVICES": "iam,sts",
+		}),
+	)
+	testcontainers.CleanupContainer(t, container)
+	require.NoError(t, err)
+
+	host, err := container.Host(ctx)
+	require.NoError(t, err)
+
+	port, err := container.MappedPort(ctx, "4566/tcp")
+	require.NoError(t, err)
+
+	return fmt.Sprintf("http://%s:%s", host, port.Port())
+}
+
+// getTrustPolicy returns the role's assume role policy, or false if the
+// role doesn't exist
+func getTrustPolicy(t *testing.T, client *iam.Client, roleName string) (*awsProvider.PolicyDocument, bool) {
+	t.Helper()
+
+	output, err := client.GetRole(context.Background(), &iam.GetRoleInput{
+		RoleName: aws.String(roleName),
+	})
+
+	var notFound *types.NoSuchEntityException
+	if errors.As(err, &notFound) {
+		return nil, false
+	}
+	require.NoError(t, err)
+
+	document, err := url.QueryUnescape(aws.ToString(output.Role.AssumeRolePolicyDocument))
+	require.NoError(t, err)
+
+	var policy awsProvider.PolicyDocument
+	require.NoError(t, json.Unmarshal([]byte(document), &policy))
+
+	return &policy, true
+}
+
+func TestAwsProvider(t *testing.T) {
+
+	endpoint := startLocalStack(t)
+	ctx := context.Background()
+
+	// The provider's requests go through the recorder so they are dumped
+	// on failure, the checks below go straight to LocalStack
+	api := providertest.NewProxy(t, endpoint)
+
+	provider, err := providers.CreateInstance(awsProvider.AwsProviderName)
+	require.NoError(t, err)
+
+	require.NoError(t, provider.Initialize(ctx, "aws", models.Provider{
+		Name:     "aws",
+		Provider: awsProvider.AwsProviderName,
+		Config: &models.BasicConfig{
+			"endpoint":          api.URL,
+			"region":            "us-east-1",
+			"access_key_id":     "test",
+			"secret_access_key": "test",
+			"imds_disable":      true,
+		},
+	}))
+
+	t.Run("account id", func(t *testing.T) {
+		// The account is resolved through STS when it isn't configured
+		accountProvider, ok := provider.(interface{ GetAccountID() string })
+		require.True(t, ok)
+		assert.Equal(t, accountID, accountProvider.GetAccountID())
+	})
+
+	client := iam.New(iam.Options{
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("test", "test", ""),
+		BaseEndpoint: aws.String(endpoint),
+	})
+
+	_, err = client.CreateUser(ctx, &iam.CreateUserInput{
+		UserName: aws.String("alice"),
+	})
+	require.NoError(t, err)
+
+	role := models.Role{
+		Name: "Read Only",
+		Permissions: models.Permissions{
+			Allow: []string{"s3:GetObject"},
+		},
+	}
+	roleName := role.GetSnakeCaseName()
+
+	providertest.Run(t, providertest.Suite{
+		Provider: provider,
+		Role:     "ReadOnlyAccess",
+		NewRequest: func(workflowID string) *models.RoleRequest {
+			requestRole := role
+			return &models.RoleRequest{
+				// No source uses traditional IAM rather than Identity Center
+				User: &models.User{
+					Email:    "alice@example.com",
+					Username: "alice",
+				},
+				Role: &requestRole,
+				Audit: &models.AuditContext{
+					WorkflowID: workflowID,
+				},
+			}
+		},
+		HasGrant: func(t *testing.T, grant *models.GrantRef) bool {
+			require.NotEmpty(t, grant.IDs, "the grant ref must record the roles")
+
+			for _, id := range grant.IDs {
+				policy, found := getTrustPolicy(t, client, id)
+				if !found {
+					return false
+				}
+
+				granted := false
+				for _, stmt := range policy.Statement {
+					if stmt.Sid == grant.Reference {
+						assert.Equal(t, "Allow", stmt.Effect)
+						assert.Equal(t, map[string]any{
+							"AWS": fmt.Sprintf("arn:aws:iam::%s:user/alice", accountID),
+						}, stmt.Principal)
+						granted = true
+					}
+				}
+				if !granted {
+					return false
+				}
+			}
+			return true
+		},
+		RemoveGrant: func(t *testing.T, grant *models.GrantRef) {
+			for _, id := range grant.IDs {
+				policy, found := getTrustPolicy(t, client, id)
+				require.True(t, found)
+
+				statements := []awsProvider.Statement{}
+				for _, stmt := range policy.Statement {
+					if stmt.Sid != grant.Reference {
+						statements = append(statements, stmt)
+					}
+				}
+				policy.Statement = statements
+
+				document, err := json.Marshal(policy)
+				require.NoError(t, err)
+
+				_, err = client.UpdateAssumeRolePolicy(ctx, &iam.UpdateAssumeRolePolicyInput{
+					RoleName:       aws.String(id),
+					PolicyDocument: aws.String(string(document)),
+				})
+				require.NoError(t, err)
+			}
+		},
+		CountGrants: func() int {
+			policy, found := getTrustPolicy(t, client, roleName)
+			if !found {
+				return 0
+			}
+
+			count := 0
+			for _, stmt := range policy.Statement {
+				if strings.HasPrefix(stmt.Sid, models.GrantReferencePrefix) {
+					count++
+				}
+			}
+			return count
+		},
+	})
+
+	// The request's path: authorize, check the trust policy through the IAM
+	// API, then revoke and check the statement is gone
+	t.Run("authorize and revoke", func(t *testing.T) {
+		requestRole := role
+		req := &models.RoleRequest{
+			User: &models.User{
+				Email:    "alice@example.com",
+				Username: "alice",
+			},
+			Role: &requestRole,
+			Audit: &models.AuditContext{
+				WorkflowID: "wf-authorize-revoke",
+			},
+		}
+
+		authorized, err := provider.AuthorizeRole(ctx, &models.AuthorizeRoleRequest{
+			RoleRequest: req,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, authorized.GrantRef)
+		assert.Equal(t, []string{roleName}, authorized.GrantRef.IDs)
+
+		findStatement := func() *awsProvider.Statement {
+			policy, found := getTrustPolicy(t, client, roleName)
+			require.True(t, found, "the role must exist once authorized")
+
+			for _, stmt := range policy.Statement {
+				if stmt.Sid == authorized.GrantRef.Reference {
+					return &stmt
+				}
+			}
+			return nil
+		}
+
+		stmt := findStatement()
+		require.NotNil(t, stmt, "authorize must add a trust policy statement for the grant")
+		assert.Equal(t, "Allow", stmt.Effect)
+		assert.Equal(t, map[string]any{
+			"AWS": fmt.Sprintf("arn:aws:iam::%s:user/alice", accountID),
+		}, stmt.Principal)
+
+		_, err = provider.RevokeRole(ctx, &models.RevokeRoleRequest{
+			RoleRequest:           req,
+			AuthorizeRoleResponse: authorized,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, findStatement(), "revoke must remove the grant's trust policy statement")
+	})
+
+	t.Run("role permissions", func(t *testing.T) {
+		output, err := client.GetRolePolicy(ctx, &iam.GetRolePolicyInput{
+			RoleName:   aws.String(roleName),
+			PolicyName: aws.String(fmt.Sprintf("thand-%s-policy", common.ConvertToSnakeCase(roleName))),
+		})
+		require.NoError(t, err)
+
+		document, err := url.QueryUnescape(aws.ToString(output.PolicyDocument))
+		require.NoError(t, err)
+		assert.Contains(t, document, "s3:GetObject")
+	})
+}
@@ -0,0 +1,272 @@
+//go:build integration
+
+package gcp_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/iam/v1"
+
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/providers"
+	gcpProvider "github.com/thand-io/agent/internal/providers/gcp"
+	"github.com/thand-io/agent/test/integration/providers/providertest"
+)
+
+const (
+	projectID = "test-project"
+	member    = "user:alice@example.com"
+)
+
+// fakeGcp serves the project IAM policy from Resource Manager and the
+// project's custom roles from IAM. Writes must send the current etag, as
+// the real API requires.
+type fakeGcp struct {
+	mu      sync.Mutex
+	policy  *cloudresourcemanager.Policy
+	version int
+	roles   map[string]*iam.Role
+}
+
+func newFakeGcp() *fakeGcp {
+	return &fakeGcp{
+		policy: &cloudresourcemanager.Policy{
+			Bindings: []*cloudresourcemanager.Binding{{
+				Role:    "roles/owner",
+				Members: []string{"user:owner@example.com"},
+			}},
+			Etag: "BwAAAAE=",
+		},
+		version: 1,
+		roles:   map[string]*iam.Role{},
+	}
+}
+
+func (f *fakeGcp) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	projectPath := "/v1/projects/" + projectID
+	rolesPath := projectPath + "/roles"
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == projectPath+":getIamPolicy":
+		writeJSON(w, http.StatusOK, f.policy)
+
+	case r.Method == http.MethodPost && r.URL.Path == projectPath+":setIamPolicy":
+		var req cloudresourcemanager.SetIamPolicyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Policy == nil {
+			writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "invalid policy")
+			return
+		}
+		if req.Policy.Etag != f.policy.Etag {
+			writeError(w, http.StatusConflict, "ABORTED", "the policy was changed concurrently")
+			return
+		}
+		f.setPolicy(req.Policy)
+		writeJSON(w, http.StatusOK, f.policy)
+
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, rolesPath+"/"):
+		role, found := f.roles[strings.TrimPrefix(r.URL.Path, "/v1/")]
+		if !found {
+			writeError(w, http.StatusNotFound, "NOT_FOUND", "role not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, role)
+
+	case r.Method == http.MethodPost && r.URL.Path == rolesPath:
+		var req iam.CreateRoleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Role == nil || len(req.RoleId) == 0 {
+			writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "invalid role")
+			return
+		}
+		name := fmt.Sprintf("projects/%s/roles/%s", projectID, req.RoleId)
+		if _, found := f.roles[name]; found {
+			writeError(w, http.StatusConflict, "ALREADY_EXISTS", "role already exists")
+			return
+		}
+		role := *req.Role
+		role.Name = name
+		f.roles[name] = &role
+		writeJSON(w, http.StatusOK, &role)
+
+	default:
+		// Cloud Identity and anything else the provider calls while
+		// synchronizing isn't faked
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "not implemented by the fake")
+	}
+}
+
+// setPolicy stores the policy with a new etag
+func (f *fakeGcp) setPolicy(policy *cloudresourcemanager.Policy) {
+	f.version++
+	policy.Etag = fmt.Sprintf("BwAAAA%d=", f.version)
+	f.policy = policy
+}
+
+// managedBindings returns the thand bindings granting the role to alice
+// for the grant, or for any grant when no reference is given
+func (f *fakeGcp) managedBindings(role, reference string) []*cloudresourcemanager.Binding {
+
+	bindings := []*cloudresourcemanager.Binding{}
+
+	for _, binding := range f.policy.Bindings {
+		if binding.Condition == nil || binding.Condition.Title != "managed-by-thand" {
+			continue
+		}
+		if len(role) > 0 && binding.Role != role {
+			continue
+		}
+		if len(reference) > 0 && !strings.Contains(binding.Condition.Description, fmt.Sprintf("(grant %s)", reference)) {
+			continue
+		}
+		bindings = append(bindings, binding)
+	}
+
+	return bindings
+}
+
+func (f *fakeGcp) hasGrant(grant *models.GrantRef) bool {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, id := range grant.IDs {
+		granted := false
+		for _, binding := range f.managedBindings(id, grant.Reference) {
+			for _, bindingMember := range binding.Members {
+				if bindingMember == member {
+					granted = true
+				}
+			}
+		}
+		if !granted {
+			return false
+		}
+	}
+
+	return true
+}
+
+// removeGrant removes alice from the grant's bindings the way an admin
+// editing the policy in the console would
+func (f *fakeGcp) removeGrant(grant *models.GrantRef) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	policy := &cloudresourcemanager.Policy{}
+
+	for _, binding := range f.policy.Bindings {
+		if binding.Condition != nil && strings.Contains(binding.Condition.Description, fmt.Sprintf("(grant %s)", grant.Reference)) {
+			continue
+		}
+		policy.Bindings = append(policy.Bindings, binding)
+	}
+
+	f.setPolicy(policy)
+}
+
+// countMembers returns the number of members across the thand bindings
+func (f *fakeGcp) countMembers() int {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	count := 0
+	for _, binding := range f.managedBindings("", "") {
+		count += len(binding.Members)
+	}
+	return count
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes an error in the format Google APIs use so the client
+// library parses it into a googleapi.Error
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	writeJSON(w, status, map[string]any{
+		"error": map[string]any{
+			"code":    status,
+			"message": message,
+			"status":  code,
+		},
+	})
+}
+
+func TestGcpProvider(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("skipping provider integration test in short mode")
+	}
+
+	fake := newFakeGcp()
+	api := providertest.NewRecorder(t, fake)
+
+	provider, err := providers.CreateInstance(gcpProvider.GcpProviderName)
+	require.NoError(t, err)
+
+	require.NoError(t, provider.Initialize(t.Context(), "gcp", models.Provider{
+		Name:     "gcp",
+		Provider: gcpProvider.GcpProviderName,
+		Config: &models.BasicConfig{
+			"endpoint":   api.URL + "/",
+			"project_id": projectID,
+		},
+	}))
+
+	role := models.Role{
+		Name:     "Bucket Reader",
+		Inherits: []string{"roles/viewer"},
+		Permissions: models.Permissions{
+			Allow: []string{"storage.objects.get"},
+		},
+	}
+
+	providertest.Run(t, providertest.Suite{
+		Provider: provider,
+		Role:     "roles/viewer",
+		NewRequest: func(workflowID string) *models.RoleRequest {
+			requestRole := role
+			return &models.RoleRequest{
+				User: &models.User{
+					Email: "alice@example.com",
+				},
+				Role: &requestRole,
+				Audit: &models.AuditContext{
+					WorkflowID: workflowID,
+				},
+			}
+		},
+		HasGrant: func(t *testing.T, grant *models.GrantRef) bool {
+			require.NotEmpty(t, grant.IDs, "the grant ref must record the bound roles")
+			return fake.hasGrant(grant)
+		},
+		RemoveGrant: func(t *testing.T, grant *models.GrantRef) {
+			fake.removeGrant(grant)
+		},
+		CountGrants: fake.countMembers,
+	})
+
+	t.Run("custom role", func(t *testing.T) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		customRole, found := fake.roles[fmt.Sprintf("projects/%s/roles/%s", projectID, role.GetSnakeCaseName())]
+		require.True(t, found, "authorize must create the custom role")
+		assert.Equal(t, []string{"storage.objects.get"}, customRole.IncludedPermissions)
+	})
+}
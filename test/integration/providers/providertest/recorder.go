@@ -0,0 +1,100 @@
+package providertest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// maxDumpSize truncates each logged request and response so a large list
+// response doesn't hide the rest of the exchanges
+const maxDumpSize = 4096
+
+// Recorder serves a cloud API, or proxies to an emulator, recording every
+// request the provider makes. The exchanges are logged when the test fails
+// so the failure shows what was sent and what came back.
+type Recorder struct {
+	URL string
+
+	mu        sync.Mutex
+	exchanges []string
+}
+
+// NewRecorder serves the handler, usually an in-process fake of the API
+func NewRecorder(t *testing.T, handler http.Handler) *Recorder {
+	t.Helper()
+
+	recorder := &Recorder{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		request, _ := httputil.DumpRequest(r, true)
+
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, r)
+
+		dumped, _ := httputil.DumpResponse(response.Result(), true)
+		recorder.record(request, dumped)
+
+		for key, values := range response.Header() {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(response.Code)
+		w.Write(response.Body.Bytes())
+	}))
+
+	recorder.URL = server.URL
+
+	t.Cleanup(func() {
+		if t.Failed() {
+			recorder.Dump(t)
+		}
+		server.Close()
+	})
+
+	return recorder
+}
+
+// NewProxy proxies to the emulator at the target URL e.g. LocalStack
+func NewProxy(t *testing.T, target string) *Recorder {
+	t.Helper()
+
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		t.Fatalf("invalid proxy target %s: %v", target, err)
+	}
+
+	return NewRecorder(t, httputil.NewSingleHostReverseProxy(targetURL))
+}
+
+// Dump logs every recorded exchange
+func (r *Recorder) Dump(t *testing.T) {
+	t.Helper()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t.Logf("%d requests were made to %s", len(r.exchanges), r.URL)
+
+	for i, exchange := range r.exchanges {
+		t.Logf("--- request %d ---\n%s", i+1, exchange)
+	}
+}
+
+func (r *Recorder) record(request []byte, response []byte) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.exchanges = append(r.exchanges, truncate(request)+"\n--- response ---\n"+truncate(response))
+}
+
+func truncate(dump []byte) string {
+	if len(dump) > maxDumpSize {
+		return string(dump[:maxDumpSize]) + "\n... truncated"
+	}
+	return string(dump)
+}
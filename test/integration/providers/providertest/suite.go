@@ -0,0 +1,104 @@
+// Package providertest runs providers through the grant lifecycle against
+// emulators or in-process fakes of the cloud APIs, so provider changes can
+// be checked without cloud credentials.
+package providertest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thand-io/agent/internal/models"
+	"github.com/thand-io/agent/internal/testing/contract"
+)
+
+// Suite describes an initialized provider and how to inspect the API it
+// grants access in
+type Suite struct {
+	Provider models.ProviderImpl
+
+	// Role is a role ListRoles must return once the provider has synchronized
+	Role string
+
+	// NewRequest returns the role request made by the given workflow. Every
+	// call must grant the same role to the same user.
+	NewRequest func(workflowID string) *models.RoleRequest
+
+	// HasGrant checks the API for what the grant ref records
+	HasGrant func(t *testing.T, grant *models.GrantRef) bool
+
+	// RemoveGrant removes the grant outside of the provider, the way an
+	// admin cleaning up by hand would
+	RemoveGrant func(t *testing.T, grant *models.GrantRef)
+
+	// CountGrants returns the number of grant artifacts in the API e.g.
+	// trust policy statements or policy binding members
+	CountGrants func() int
+}
+
+// Run checks the provider lists roles, grants and revokes access, restores
+// a grant that drifted and follows the grant-state contract
+func Run(t *testing.T, suite Suite) {
+
+	t.Helper()
+
+	ctx := context.Background()
+	provider := suite.Provider
+
+	t.Run("list roles", func(t *testing.T) {
+
+		// Emulators don't implement every API synchronizing calls e.g.
+		// Identity Center, the static roles are loaded regardless
+		if err := provider.Synchronize(ctx, nil, nil); err != nil {
+			t.Logf("synchronize failed: %v", err)
+		}
+
+		roles, err := provider.ListRoles(ctx, nil)
+		require.NoError(t, err)
+		assert.NotEmpty(t, roles)
+
+		role, err := provider.GetRole(ctx, suite.Role)
+		require.NoError(t, err)
+		assert.True(t, strings.EqualFold(suite.Role, role.Name), "expected role %s, got %s", suite.Role, role.Name)
+	})
+
+	t.Run("grant lifecycle", func(t *testing.T) {
+
+		req := suite.NewRequest("wf-lifecycle")
+
+		authorized, err := provider.AuthorizeRole(ctx, &models.AuthorizeRoleRequest{
+			RoleRequest: req,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, authorized.GrantRef, "authorize must record a grant ref")
+		assert.True(t, suite.HasGrant(t, authorized.GrantRef), "the grant must exist after authorize")
+
+		// The grant is removed by hand so authorizing again must restore it
+		suite.RemoveGrant(t, authorized.GrantRef)
+		require.False(t, suite.HasGrant(t, authorized.GrantRef), "the grant must be gone once removed")
+
+		restored, err := provider.AuthorizeRole(ctx, &models.AuthorizeRoleRequest{
+			RoleRequest: req,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, authorized.GrantRef, restored.GrantRef)
+		assert.True(t, suite.HasGrant(t, restored.GrantRef), "authorize must restore a grant that drifted")
+
+		_, err = provider.RevokeRole(ctx, &models.RevokeRoleRequest{
+			RoleRequest:           req,
+			AuthorizeRoleResponse: restored,
+		})
+		require.NoError(t, err)
+		assert.False(t, suite.HasGrant(t, restored.GrantRef), "the grant must be gone after revoke")
+	})
+
+	t.Run("grant contract", func(t *testing.T) {
+		contract.RunGrantContract(t, contract.GrantContract{
+			Provider:    provider,
+			NewRequest:  suite.NewRequest,
+			CountGrants: suite.CountGrants,
+		})
+	})
+}
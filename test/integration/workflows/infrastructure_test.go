@@ -465,6 +465,7 @@ func (infra *TestInfrastructure) registerNamespaceWithSearchAttributes(ctx conte
 		models.TypedSearchAttributeDuration,
 		models.TypedSearchAttributeIdentities,
 		models.TypedSearchAttributeApproved,
+		models.TypedSearchAttributeRiskFlags,
 	}
 
 	searchAttributes := make(map[string]enums.IndexedValueType, len(searchAttributeTypes))
@@ -1,6 +1,7 @@
 package workflows_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -207,7 +208,7 @@ func (l *TestCaseLoader) CreateConfigFromTestCase(tc *TestCase) (*config.Config,
 
 	// Initialize providers (this creates the actual provider implementations)
 	// This must be done after setting mode so the correct implementation is used
-	err = cfg.InitializeProviders()
+	err = cfg.InitializeProviders(context.Background())
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize providers: %w", err)
 	}